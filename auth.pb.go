@@ -785,6 +785,53 @@ func (m *MembersRes) GetMembers() []string {
 	return nil
 }
 
+type ChannelIDs struct {
+	Value                []string `protobuf:"bytes,1,rep,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChannelIDs) Reset()         { *m = ChannelIDs{} }
+func (m *ChannelIDs) String() string { return proto.CompactTextString(m) }
+func (*ChannelIDs) ProtoMessage()    {}
+func (*ChannelIDs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8bbd6f3875b0e874, []int{13}
+}
+func (m *ChannelIDs) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ChannelIDs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ChannelIDs.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ChannelIDs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChannelIDs.Merge(m, src)
+}
+func (m *ChannelIDs) XXX_Size() int {
+	return m.Size()
+}
+func (m *ChannelIDs) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChannelIDs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChannelIDs proto.InternalMessageInfo
+
+func (m *ChannelIDs) GetValue() []string {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*AccessByKeyReq)(nil), "mainflux.AccessByKeyReq")
 	proto.RegisterType((*ChannelOwnerReq)(nil), "mainflux.ChannelOwnerReq")
@@ -799,52 +846,54 @@ func init() {
 	proto.RegisterType((*Assignment)(nil), "mainflux.Assignment")
 	proto.RegisterType((*MembersReq)(nil), "mainflux.MembersReq")
 	proto.RegisterType((*MembersRes)(nil), "mainflux.MembersRes")
+	proto.RegisterType((*ChannelIDs)(nil), "mainflux.ChannelIDs")
 }
 
 func init() { proto.RegisterFile("auth.proto", fileDescriptor_8bbd6f3875b0e874) }
 
 var fileDescriptor_8bbd6f3875b0e874 = []byte{
-	// 634 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcd, 0x6e, 0xd3, 0x4c,
-	0x14, 0x75, 0xfe, 0x93, 0xfb, 0x35, 0x69, 0xbf, 0x51, 0x15, 0x8c, 0x11, 0xa1, 0xcc, 0xaa, 0x2b,
-	0x17, 0x15, 0x10, 0x6c, 0xa0, 0x4a, 0xeb, 0x2e, 0x2c, 0x84, 0x90, 0x4c, 0x91, 0xd8, 0x3a, 0xe9,
-	0x24, 0x31, 0xf8, 0x27, 0x78, 0xc6, 0x05, 0xb3, 0xe0, 0x0d, 0xd8, 0xf3, 0x04, 0x3c, 0x0b, 0x4b,
-	0x1e, 0x01, 0x95, 0x17, 0x41, 0xf3, 0xe3, 0x78, 0x5a, 0xec, 0x8a, 0xdd, 0x3d, 0x37, 0x77, 0xce,
-	0xb9, 0xf7, 0xe6, 0x1e, 0x03, 0xf8, 0x19, 0x5b, 0xd9, 0xeb, 0x34, 0x61, 0x09, 0xea, 0x47, 0x7e,
-	0x10, 0x2f, 0xc2, 0xec, 0x93, 0x75, 0x67, 0x99, 0x24, 0xcb, 0x90, 0x1c, 0x88, 0xfc, 0x2c, 0x5b,
-	0x1c, 0x90, 0x68, 0xcd, 0x72, 0x59, 0x86, 0x9f, 0xc3, 0x68, 0x3a, 0x9f, 0x13, 0x4a, 0x8f, 0xf3,
-	0x17, 0x24, 0xf7, 0xc8, 0x07, 0xb4, 0x0b, 0x1d, 0x96, 0xbc, 0x27, 0xb1, 0xd9, 0xd8, 0x6b, 0xec,
-	0x0f, 0x3c, 0x09, 0xd0, 0x18, 0xba, 0xf3, 0x95, 0x1f, 0xbb, 0x8e, 0xd9, 0x14, 0x69, 0x85, 0xf0,
-	0x11, 0x6c, 0x9f, 0xac, 0xfc, 0x38, 0x26, 0xe1, 0xab, 0x8f, 0x31, 0x49, 0x15, 0x41, 0xc2, 0xe3,
-	0x82, 0x40, 0x80, 0x5a, 0x82, 0x7b, 0xd0, 0x3b, 0x5b, 0x05, 0xf1, 0xd2, 0x75, 0xf8, 0xc3, 0x0b,
-	0x3f, 0xcc, 0x48, 0xf1, 0x50, 0x00, 0x7c, 0x1f, 0x06, 0x4a, 0xa1, 0xb6, 0x64, 0x0a, 0xc3, 0x62,
-	0x08, 0xd7, 0xe1, 0x2d, 0x98, 0xd0, 0x63, 0x92, 0x54, 0x15, 0x16, 0xb0, 0xb6, 0x8d, 0xbb, 0xd0,
-	0x39, 0x13, 0x83, 0x56, 0x2b, 0x3c, 0x82, 0xad, 0x37, 0x94, 0xa4, 0xee, 0x39, 0x89, 0x59, 0xc0,
-	0x72, 0x34, 0x82, 0x66, 0x70, 0xae, 0x4a, 0x9a, 0xc1, 0x39, 0x7f, 0x45, 0x22, 0x3f, 0x08, 0x15,
-	0xab, 0x04, 0xd8, 0x81, 0xbe, 0x4b, 0x69, 0x46, 0x78, 0x4b, 0xff, 0xf4, 0x02, 0x21, 0x68, 0xb3,
-	0x7c, 0x4d, 0xcc, 0xd6, 0x5e, 0x63, 0x7f, 0xe8, 0x89, 0x18, 0x3b, 0xb0, 0x35, 0xcd, 0xd8, 0x2a,
-	0x49, 0x83, 0xcf, 0x82, 0x69, 0x07, 0x5a, 0x34, 0x9b, 0x29, 0x2a, 0x1e, 0xf2, 0x4c, 0x32, 0x7b,
-	0xa7, 0x98, 0x78, 0xc8, 0x33, 0xfe, 0x9c, 0x09, 0x9a, 0x81, 0xc7, 0x43, 0x6c, 0x5f, 0x61, 0xa1,
-	0x68, 0x22, 0xaf, 0x45, 0x60, 0xd9, 0x57, 0xdf, 0xd3, 0x32, 0xf8, 0x2d, 0xc0, 0x94, 0xd2, 0x60,
-	0x19, 0x47, 0x24, 0x66, 0x35, 0x47, 0x61, 0x42, 0x6f, 0x99, 0x26, 0xd9, 0x7a, 0xb3, 0xcd, 0x02,
-	0x22, 0x0b, 0xfa, 0x11, 0x89, 0x66, 0x24, 0x75, 0x1d, 0xd5, 0xc4, 0x06, 0xe3, 0x2f, 0x00, 0x2f,
-	0x45, 0x4c, 0xeb, 0xcf, 0xad, 0x9e, 0x79, 0x0c, 0xdd, 0x64, 0xb1, 0xa0, 0x44, 0x0e, 0xd7, 0xf6,
-	0x14, 0xe2, 0x3c, 0x61, 0x10, 0x05, 0xcc, 0x6c, 0x8b, 0xb4, 0x04, 0x9b, 0x7d, 0x76, 0x04, 0x89,
-	0xdc, 0xa7, 0xae, 0x4f, 0xa5, 0x3e, 0xf3, 0x43, 0xa1, 0xdf, 0xf6, 0x24, 0xd0, 0x54, 0x9a, 0xd5,
-	0x2a, 0xad, 0x2a, 0x95, 0x76, 0xa9, 0xc2, 0x27, 0x90, 0x13, 0x53, 0xb3, 0xb3, 0xd7, 0xe2, 0x13,
-	0x28, 0x78, 0xf8, 0xb5, 0x09, 0x43, 0x71, 0xf2, 0xf4, 0x35, 0x49, 0x2f, 0x82, 0x39, 0x41, 0x47,
-	0x30, 0x3a, 0xf1, 0x63, 0xcd, 0x87, 0xc8, 0xb4, 0x0b, 0xfb, 0xda, 0x57, 0xed, 0x69, 0xfd, 0x5f,
-	0xfe, 0xa2, 0x7c, 0x83, 0x0d, 0x74, 0x0a, 0x23, 0x97, 0xea, 0x3e, 0x44, 0xb7, 0xcb, 0xb2, 0x6b,
-	0xfe, 0xb4, 0xc6, 0xb6, 0xfc, 0x20, 0xd8, 0xc5, 0x07, 0xc1, 0x3e, 0xe5, 0x1f, 0x04, 0x6c, 0xa0,
-	0x63, 0x18, 0x6a, 0x7d, 0xb8, 0x0e, 0xba, 0xf5, 0x77, 0x1b, 0xc2, 0x60, 0x37, 0x70, 0x3c, 0x80,
-	0xbe, 0x74, 0xc9, 0x22, 0x47, 0xdb, 0x5a, 0xaf, 0xfc, 0x6f, 0xad, 0x6c, 0xfe, 0xf0, 0x7b, 0x13,
-	0xfe, 0xe3, 0xa7, 0x59, 0x6c, 0xc3, 0x86, 0x8e, 0x70, 0x0d, 0x42, 0x65, 0x75, 0x61, 0x23, 0xeb,
-	0x3a, 0x25, 0x36, 0xd0, 0xe3, 0x9b, 0x14, 0xc7, 0x65, 0x42, 0x37, 0x30, 0x36, 0xd0, 0x33, 0x18,
-	0x6c, 0x0c, 0x81, 0xb4, 0x32, 0xdd, 0x6b, 0x56, 0x75, 0x9e, 0x62, 0x03, 0x3d, 0x85, 0xae, 0xf4,
-	0x07, 0xda, 0xd5, 0x6a, 0x36, 0x8e, 0xb9, 0x61, 0x43, 0x4f, 0xa0, 0xa7, 0xee, 0x4f, 0x7f, 0x5a,
-	0x5a, 0xc2, 0xaa, 0xca, 0x52, 0x6c, 0x1c, 0xef, 0xfc, 0xb8, 0x9c, 0x34, 0x7e, 0x5e, 0x4e, 0x1a,
-	0xbf, 0x2e, 0x27, 0x8d, 0x6f, 0xbf, 0x27, 0xc6, 0xac, 0x2b, 0xc8, 0x1f, 0xfe, 0x09, 0x00, 0x00,
-	0xff, 0xff, 0x14, 0xf9, 0x10, 0x64, 0xf9, 0x05, 0x00, 0x00,
+	// 652 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0x76, 0xfe, 0x93, 0xa1, 0x49, 0xcb, 0xaa, 0x0a, 0xc6, 0x88, 0x50, 0xf6, 0xd4, 0x93, 0x8b,
+	0x0a, 0x08, 0x2e, 0x50, 0xa5, 0x75, 0x0f, 0x16, 0x42, 0x48, 0xa6, 0x48, 0x5c, 0x9d, 0x74, 0x93,
+	0x18, 0xfc, 0x13, 0xbc, 0xeb, 0x42, 0x38, 0xf0, 0x1c, 0x3c, 0x01, 0xaf, 0x02, 0x47, 0x1e, 0x01,
+	0x95, 0x17, 0x41, 0xfb, 0xe3, 0x78, 0xdb, 0xda, 0x11, 0xb7, 0xf9, 0x26, 0x33, 0xdf, 0x7c, 0xb3,
+	0x99, 0xcf, 0x00, 0x7e, 0xc6, 0x16, 0xf6, 0x32, 0x4d, 0x58, 0x82, 0xba, 0x91, 0x1f, 0xc4, 0xb3,
+	0x30, 0xfb, 0x62, 0xdd, 0x9b, 0x27, 0xc9, 0x3c, 0x24, 0x07, 0x22, 0x3f, 0xc9, 0x66, 0x07, 0x24,
+	0x5a, 0xb2, 0x95, 0x2c, 0xc3, 0x2f, 0x61, 0x30, 0x9e, 0x4e, 0x09, 0xa5, 0xc7, 0xab, 0x57, 0x64,
+	0xe5, 0x91, 0x4f, 0x68, 0x17, 0x5a, 0x2c, 0xf9, 0x48, 0x62, 0xb3, 0xb6, 0x57, 0xdb, 0xef, 0x79,
+	0x12, 0xa0, 0x21, 0xb4, 0xa7, 0x0b, 0x3f, 0x76, 0x1d, 0xb3, 0x2e, 0xd2, 0x0a, 0xe1, 0x23, 0xd8,
+	0x3e, 0x59, 0xf8, 0x71, 0x4c, 0xc2, 0x37, 0x9f, 0x63, 0x92, 0x2a, 0x82, 0x84, 0xc7, 0x39, 0x81,
+	0x00, 0x95, 0x04, 0x0f, 0xa0, 0x73, 0xb6, 0x08, 0xe2, 0xb9, 0xeb, 0xf0, 0xc6, 0x0b, 0x3f, 0xcc,
+	0x48, 0xde, 0x28, 0x00, 0x7e, 0x08, 0x3d, 0x35, 0xa1, 0xb2, 0x64, 0x0c, 0xfd, 0x7c, 0x09, 0xd7,
+	0xe1, 0x12, 0x4c, 0xe8, 0x30, 0x49, 0xaa, 0x0a, 0x73, 0x58, 0x29, 0xe3, 0x3e, 0xb4, 0xce, 0xc4,
+	0xa2, 0xe5, 0x13, 0x9e, 0xc0, 0xd6, 0x3b, 0x4a, 0x52, 0xf7, 0x9c, 0xc4, 0x2c, 0x60, 0x2b, 0x34,
+	0x80, 0x7a, 0x70, 0xae, 0x4a, 0xea, 0xc1, 0x39, 0xef, 0x22, 0x91, 0x1f, 0x84, 0x8a, 0x55, 0x02,
+	0xec, 0x40, 0xd7, 0xa5, 0x34, 0x23, 0x5c, 0xd2, 0x7f, 0x75, 0x20, 0x04, 0x4d, 0xb6, 0x5a, 0x12,
+	0xb3, 0xb1, 0x57, 0xdb, 0xef, 0x7b, 0x22, 0xc6, 0x0e, 0x6c, 0x8d, 0x33, 0xb6, 0x48, 0xd2, 0xe0,
+	0xab, 0x60, 0xda, 0x81, 0x06, 0xcd, 0x26, 0x8a, 0x8a, 0x87, 0x3c, 0x93, 0x4c, 0x3e, 0x28, 0x26,
+	0x1e, 0xf2, 0x8c, 0x3f, 0x65, 0x82, 0xa6, 0xe7, 0xf1, 0x10, 0xdb, 0x57, 0x58, 0x28, 0x1a, 0xc9,
+	0x6b, 0x11, 0x58, 0xea, 0xea, 0x7a, 0x5a, 0x06, 0xbf, 0x07, 0x18, 0x53, 0x1a, 0xcc, 0xe3, 0x88,
+	0xc4, 0xac, 0xe2, 0x28, 0x4c, 0xe8, 0xcc, 0xd3, 0x24, 0x5b, 0xae, 0x5f, 0x33, 0x87, 0xc8, 0x82,
+	0x6e, 0x44, 0xa2, 0x09, 0x49, 0x5d, 0x47, 0x89, 0x58, 0x63, 0xfc, 0x0d, 0xe0, 0xb5, 0x88, 0x69,
+	0xf5, 0xb9, 0x55, 0x33, 0x0f, 0xa1, 0x9d, 0xcc, 0x66, 0x94, 0xc8, 0xe5, 0x9a, 0x9e, 0x42, 0x9c,
+	0x27, 0x0c, 0xa2, 0x80, 0x99, 0x4d, 0x91, 0x96, 0x60, 0xfd, 0x9e, 0x2d, 0x41, 0x22, 0xdf, 0x53,
+	0x9f, 0x4f, 0xe5, 0x7c, 0xe6, 0x87, 0x62, 0x7e, 0xd3, 0x93, 0x40, 0x9b, 0x52, 0x2f, 0x9f, 0xd2,
+	0x28, 0x9b, 0xd2, 0x2c, 0xa6, 0xf0, 0x0d, 0xe4, 0xc6, 0xd4, 0x6c, 0xed, 0x35, 0xf8, 0x06, 0x0a,
+	0x62, 0x0c, 0xb0, 0x3e, 0x68, 0xaa, 0xdf, 0x5b, 0x63, 0x7d, 0x6f, 0x87, 0x3f, 0xeb, 0xd0, 0x17,
+	0xb6, 0xa0, 0x6f, 0x49, 0x7a, 0x11, 0x4c, 0x09, 0x3a, 0x82, 0xc1, 0x89, 0x1f, 0x6b, 0x5e, 0x45,
+	0xa6, 0x9d, 0x5b, 0xdc, 0xbe, 0x6a, 0x61, 0xeb, 0x76, 0xf1, 0x8b, 0xf2, 0x16, 0x36, 0xd0, 0x29,
+	0x0c, 0x5c, 0xaa, 0x7b, 0x15, 0xdd, 0x2d, 0xca, 0xae, 0x79, 0xd8, 0x1a, 0xda, 0xf2, 0xa3, 0x61,
+	0xe7, 0x1f, 0x0d, 0xfb, 0x94, 0x7f, 0x34, 0xb0, 0x81, 0x8e, 0xa1, 0xaf, 0xe9, 0x70, 0x1d, 0x74,
+	0xe7, 0xa6, 0x0c, 0xd7, 0xd9, 0xcc, 0xf1, 0x08, 0xba, 0xd2, 0x49, 0xb3, 0x15, 0xda, 0xd6, 0xb4,
+	0xf2, 0xbf, 0xbe, 0x5c, 0xfc, 0x73, 0xe8, 0x2b, 0x89, 0x6a, 0xf9, 0x1b, 0x6d, 0xbb, 0x37, 0x96,
+	0x71, 0x1d, 0x8a, 0x8d, 0xc3, 0x1f, 0x75, 0xb8, 0xc5, 0x0f, 0x3f, 0x7f, 0x47, 0x1b, 0x5a, 0xc2,
+	0x93, 0x08, 0x15, 0x0d, 0xb9, 0x49, 0xad, 0xeb, 0xac, 0xd8, 0x40, 0x4f, 0x37, 0x69, 0x1d, 0x16,
+	0x09, 0xfd, 0xf3, 0x80, 0x0d, 0xf4, 0x02, 0x7a, 0x6b, 0xbb, 0x21, 0xad, 0x4c, 0x77, 0xb2, 0x55,
+	0x9e, 0xa7, 0x62, 0xdf, 0xb6, 0x74, 0x1f, 0xd2, 0xf6, 0x2a, 0xfc, 0xb8, 0xe1, 0x6d, 0x9f, 0x41,
+	0x47, 0x5d, 0xb7, 0xde, 0x5a, 0x18, 0xce, 0x2a, 0xcb, 0x52, 0x6c, 0x1c, 0xef, 0xfc, 0xba, 0x1c,
+	0xd5, 0x7e, 0x5f, 0x8e, 0x6a, 0x7f, 0x2e, 0x47, 0xb5, 0xef, 0x7f, 0x47, 0xc6, 0xa4, 0x2d, 0xc8,
+	0x1f, 0xff, 0x1b, 0x00, 0x62, 0x1f, 0x40, 0xd8, 0x57, 0x06, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -863,6 +912,7 @@ type ThingsServiceClient interface {
 	IsChannelOwner(ctx context.Context, in *ChannelOwnerReq, opts ...grpc.CallOption) (*empty.Empty, error)
 	CanAccessByID(ctx context.Context, in *AccessByIDReq, opts ...grpc.CallOption) (*empty.Empty, error)
 	Identify(ctx context.Context, in *Token, opts ...grpc.CallOption) (*ThingID, error)
+	ChannelsByKey(ctx context.Context, in *Token, opts ...grpc.CallOption) (*ChannelIDs, error)
 }
 
 type thingsServiceClient struct {
@@ -909,12 +959,22 @@ func (c *thingsServiceClient) Identify(ctx context.Context, in *Token, opts ...g
 	return out, nil
 }
 
+func (c *thingsServiceClient) ChannelsByKey(ctx context.Context, in *Token, opts ...grpc.CallOption) (*ChannelIDs, error) {
+	out := new(ChannelIDs)
+	err := c.cc.Invoke(ctx, "/mainflux.ThingsService/ChannelsByKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ThingsServiceServer is the server API for ThingsService service.
 type ThingsServiceServer interface {
 	CanAccessByKey(context.Context, *AccessByKeyReq) (*ThingID, error)
 	IsChannelOwner(context.Context, *ChannelOwnerReq) (*empty.Empty, error)
 	CanAccessByID(context.Context, *AccessByIDReq) (*empty.Empty, error)
 	Identify(context.Context, *Token) (*ThingID, error)
+	ChannelsByKey(context.Context, *Token) (*ChannelIDs, error)
 }
 
 // UnimplementedThingsServiceServer can be embedded to have forward compatible implementations.
@@ -933,6 +993,9 @@ func (*UnimplementedThingsServiceServer) CanAccessByID(ctx context.Context, req
 func (*UnimplementedThingsServiceServer) Identify(ctx context.Context, req *Token) (*ThingID, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Identify not implemented")
 }
+func (*UnimplementedThingsServiceServer) ChannelsByKey(ctx context.Context, req *Token) (*ChannelIDs, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChannelsByKey not implemented")
+}
 
 func RegisterThingsServiceServer(s *grpc.Server, srv ThingsServiceServer) {
 	s.RegisterService(&_ThingsService_serviceDesc, srv)
@@ -1010,6 +1073,24 @@ func _ThingsService_Identify_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ThingsService_ChannelsByKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Token)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThingsServiceServer).ChannelsByKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mainflux.ThingsService/ChannelsByKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThingsServiceServer).ChannelsByKey(ctx, req.(*Token))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ThingsService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "mainflux.ThingsService",
 	HandlerType: (*ThingsServiceServer)(nil),
@@ -1030,6 +1111,10 @@ var _ThingsService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Identify",
 			Handler:    _ThingsService_Identify_Handler,
 		},
+		{
+			MethodName: "ChannelsByKey",
+			Handler:    _ThingsService_ChannelsByKey_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "auth.proto",
@@ -1812,6 +1897,42 @@ func (m *MembersRes) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *ChannelIDs) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ChannelIDs) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ChannelIDs) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Value) > 0 {
+		for iNdEx := len(m.Value) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Value[iNdEx])
+			copy(dAtA[i:], m.Value[iNdEx])
+			i = encodeVarintAuth(dAtA, i, uint64(len(m.Value[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintAuth(dAtA []byte, offset int, v uint64) int {
 	offset -= sovAuth(v)
 	base := offset
@@ -2098,6 +2219,24 @@ func (m *MembersRes) Size() (n int) {
 	return n
 }
 
+func (m *ChannelIDs) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Value) > 0 {
+		for _, s := range m.Value {
+			l = len(s)
+			n += 1 + l + sovAuth(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func sovAuth(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -3708,6 +3847,92 @@ func (m *MembersRes) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *ChannelIDs) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAuth
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ChannelIDs: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ChannelIDs: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = append(m.Value, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAuth(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipAuth(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
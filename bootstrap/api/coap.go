@@ -0,0 +1,155 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/mainflux/mainflux/bootstrap"
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/plgd-dev/go-coap/v2/message"
+	"github.com/plgd-dev/go-coap/v2/message/codes"
+	"github.com/plgd-dev/go-coap/v2/mux"
+)
+
+const (
+	authQuery         = "auth"
+	coapBootstrapPath = "things/bootstrap/"
+	coapSecurePath    = "things/bootstrap/secure/"
+	coapUnknownError  = "unexpected error"
+)
+
+// MakeCoAPHandler returns a CoAP handler for the bootstrap retrieval
+// endpoints, at the same paths as the HTTP ones. The external key is sent
+// as the `auth` URI query option (e.g. `things/bootstrap/{external_id}?auth={external_key}`).
+// The configuration is returned as JSON, or as the encrypted configuration
+// for the secure endpoint.
+func MakeCoAPHandler(svc bootstrap.Service, reader bootstrap.ConfigReader, logger log.Logger) mux.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc(coapBootstrapPath, coapBootstrapHandler(svc, reader, false, logger))
+	r.HandleFunc(coapSecurePath, coapBootstrapHandler(svc, reader, true, logger))
+
+	return r
+}
+
+func coapBootstrapHandler(svc bootstrap.Service, reader bootstrap.ConfigReader, secure bool, logger log.Logger) mux.HandlerFunc {
+	prefix := coapBootstrapPath
+	if secure {
+		prefix = coapSecurePath
+	}
+
+	return func(w mux.ResponseWriter, m *mux.Message) {
+		if m.Code != codes.GET {
+			setCoAPResponse(w, codes.MethodNotAllowed, message.TextPlain, nil, logger)
+			return
+		}
+
+		path, err := m.Options.Path()
+		if err != nil {
+			setCoAPResponse(w, codes.BadOption, message.TextPlain, nil, logger)
+			return
+		}
+
+		req := bootstrapReq{
+			id:  strings.TrimPrefix(path, prefix),
+			key: parseCoAPKey(m),
+			ip:  coapRemoteIP(w),
+		}
+		if strings.Contains(req.id, "/") {
+			setCoAPResponse(w, codes.NotFound, message.TextPlain, nil, logger)
+			return
+		}
+		if err := req.validate(); err != nil {
+			setCoAPError(w, err, logger)
+			return
+		}
+
+		cfg, err := svc.Bootstrap(m.Context, req.key, req.id, req.ip, secure)
+		if err != nil {
+			setCoAPError(w, err, logger)
+			return
+		}
+
+		res, err := reader.ReadConfig(cfg, secure)
+		if err != nil {
+			setCoAPError(w, err, logger)
+			return
+		}
+
+		if b, ok := res.([]byte); ok {
+			setCoAPResponse(w, codes.Content, message.AppOctets, bytes.NewReader(b), logger)
+			return
+		}
+
+		body, err := json.Marshal(res)
+		if err != nil {
+			setCoAPError(w, err, logger)
+			return
+		}
+		setCoAPResponse(w, codes.Content, message.AppJSON, bytes.NewReader(body), logger)
+	}
+}
+
+// coapRemoteIP returns the address the request came from.
+func coapRemoteIP(w mux.ResponseWriter) string {
+	addr := w.Client().RemoteAddr().String()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
+
+// parseCoAPKey returns the external key sent as the `auth` URI query option.
+func parseCoAPKey(m *mux.Message) string {
+	queries, err := m.Options.Queries()
+	if err != nil {
+		return ""
+	}
+
+	for _, q := range queries {
+		vars := strings.SplitN(q, "=", 2)
+		if len(vars) == 2 && vars[0] == authQuery {
+			return vars[1]
+		}
+	}
+
+	return ""
+}
+
+// setCoAPError maps the error to the response code in the same manner as
+// encodeError, sending the error message as the response body.
+func setCoAPError(w mux.ResponseWriter, err error, logger log.Logger) {
+	code := codes.InternalServerError
+	msg := coapUnknownError
+
+	if e, ok := err.(errors.Error); ok {
+		msg = e.Msg()
+		switch {
+		case errors.Contains(e, bootstrap.ErrMalformedEntity):
+			code = codes.BadRequest
+		case errors.Contains(e, bootstrap.ErrNotFound):
+			code = codes.NotFound
+		case errors.Contains(e, bootstrap.ErrUnauthorizedAccess):
+			code = codes.Unauthorized
+		case errors.Contains(e, bootstrap.ErrThings):
+			code = codes.ServiceUnavailable
+		}
+	}
+
+	setCoAPResponse(w, code, message.TextPlain, strings.NewReader(msg), logger)
+}
+
+func setCoAPResponse(w mux.ResponseWriter, code codes.Code, cf message.MediaType, body io.ReadSeeker, logger log.Logger) {
+	if err := w.SetResponse(code, cf, body); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to set CoAP response: %s", err))
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/bootstrap"
+	"github.com/mainflux/mainflux/bootstrap/api"
+	"github.com/mainflux/mainflux/bootstrap/mocks"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/plgd-dev/go-coap/v2/message"
+	"github.com/plgd-dev/go-coap/v2/message/codes"
+	coapnet "github.com/plgd-dev/go-coap/v2/net"
+	"github.com/plgd-dev/go-coap/v2/udp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCoAPServer(t *testing.T, svc bootstrap.Service) (string, func()) {
+	l, err := coapnet.NewListenUDP("udp", "localhost:0")
+	require.Nil(t, err, fmt.Sprintf("Creating CoAP listener expected to succeed: %s.\n", err))
+
+	logger, err := logger.New(os.Stdout, logger.Error.String())
+	require.Nil(t, err, fmt.Sprintf("Creating logger expected to succeed: %s.\n", err))
+
+	s := udp.NewServer(udp.WithMux(api.MakeCoAPHandler(svc, bootstrap.NewConfigReader(encKey), logger)))
+	go s.Serve(l)
+
+	return l.LocalAddr().String(), func() {
+		s.Stop()
+		l.Close()
+	}
+}
+
+func TestCoAPBootstrap(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	ts := newThingsServer(newThingsService(users))
+	svc := newService(users, ts.URL)
+	addr, stop := newCoAPServer(t, svc)
+	defer stop()
+
+	c := newConfig([]bootstrap.Channel{bootstrap.Channel{ID: "1"}})
+
+	saved, err := svc.Add(context.Background(), validToken, c)
+	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+
+	encExternKey, err := enc([]byte(c.ExternalKey))
+	require.Nil(t, err, fmt.Sprintf("Encrypting config expected to succeed: %s.\n", err))
+
+	var channels []channel
+	for _, ch := range saved.MFChannels {
+		channels = append(channels, channel{ID: ch.ID, Name: ch.Name, Metadata: ch.Metadata})
+	}
+
+	s := struct {
+		MFThing    string    `json:"mainflux_id"`
+		MFKey      string    `json:"mainflux_key"`
+		MFChannels []channel `json:"mainflux_channels"`
+		Content    string    `json:"content"`
+		ClientCert string    `json:"client_cert"`
+		ClientKey  string    `json:"client_key"`
+		CACert     string    `json:"ca_cert"`
+	}{
+		MFThing:    saved.MFThing,
+		MFKey:      saved.MFKey,
+		MFChannels: channels,
+		Content:    saved.Content,
+		ClientCert: saved.ClientCert,
+		ClientKey:  saved.ClientKey,
+		CACert:     saved.CACert,
+	}
+
+	data := toJSON(s)
+
+	cases := []struct {
+		desc        string
+		externalID  string
+		externalKey string
+		code        codes.Code
+		res         string
+		secure      bool
+	}{
+		{
+			desc:        "bootstrap a Thing with unknown ID",
+			externalID:  unknown,
+			externalKey: c.ExternalKey,
+			code:        codes.NotFound,
+			res:         bootstrap.ErrBootstrap.Error(),
+			secure:      false,
+		},
+		{
+			desc:        "bootstrap a Thing with an empty ID",
+			externalID:  "",
+			externalKey: c.ExternalKey,
+			code:        codes.NotFound,
+			res:         "",
+			secure:      false,
+		},
+		{
+			desc:        "bootstrap a Thing with unknown key",
+			externalID:  c.ExternalID,
+			externalKey: unknown,
+			code:        codes.NotFound,
+			res:         bootstrap.ErrExternalKeyNotFound.Error(),
+			secure:      false,
+		},
+		{
+			desc:        "bootstrap a Thing with an empty key",
+			externalID:  c.ExternalID,
+			externalKey: "",
+			code:        codes.Unauthorized,
+			res:         bootstrap.ErrUnauthorizedAccess.Error(),
+			secure:      false,
+		},
+		{
+			desc:        "bootstrap known Thing",
+			externalID:  c.ExternalID,
+			externalKey: c.ExternalKey,
+			code:        codes.Content,
+			res:         data,
+			secure:      false,
+		},
+		{
+			desc:        "bootstrap secure",
+			externalID:  fmt.Sprintf("secure/%s", c.ExternalID),
+			externalKey: hex.EncodeToString(encExternKey),
+			code:        codes.Content,
+			res:         data,
+			secure:      true,
+		},
+		{
+			desc:        "bootstrap secure with unencrypted key",
+			externalID:  fmt.Sprintf("secure/%s", c.ExternalID),
+			externalKey: c.ExternalKey,
+			code:        codes.NotFound,
+			res:         bootstrap.ErrSecureBootstrap.Error(),
+			secure:      true,
+		},
+	}
+
+	cc, err := udp.Dial(addr)
+	require.Nil(t, err, fmt.Sprintf("Dialing CoAP server expected to succeed: %s.\n", err))
+	defer cc.Close()
+
+	for _, tc := range cases {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		var opts []message.Option
+		if tc.externalKey != "" {
+			opts = append(opts, message.Option{ID: message.URIQuery, Value: []byte("auth=" + tc.externalKey)})
+		}
+		res, err := cc.Get(ctx, fmt.Sprintf("/things/bootstrap/%s", tc.externalID), opts...)
+		cancel()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		assert.Equal(t, tc.code, res.Code(), fmt.Sprintf("%s: expected code %s got %s", tc.desc, tc.code, res.Code()))
+		body, err := res.ReadBody()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		if tc.secure && tc.code == codes.Content {
+			body, err = dec(body)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		}
+
+		assert.Equal(t, tc.res, string(body), fmt.Sprintf("%s: expected response '%s' got '%s'", tc.desc, tc.res, string(body)))
+	}
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/mainflux/mainflux/bootstrap"
 )
 
-func addEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+func addEndpoint(svc bootstrap.Service, active bool) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(addReq)
 		if err := req.validate(); err != nil {
@@ -32,6 +32,7 @@ func addEndpoint(svc bootstrap.Service) endpoint.Endpoint {
 			ClientKey:   req.ClientKey,
 			CACert:      req.CACert,
 			Content:     req.Content,
+			State:       req.state(active),
 		}
 
 		saved, err := svc.Add(ctx, req.token, config)
@@ -48,6 +49,68 @@ func addEndpoint(svc bootstrap.Service) endpoint.Endpoint {
 	}
 }
 
+func importEndpoint(svc bootstrap.Service, active bool) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(importReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		configs := make([]bootstrap.Config, len(req.configs))
+		for i, r := range req.configs {
+			channels := []bootstrap.Channel{}
+			for _, c := range r.Channels {
+				channels = append(channels, bootstrap.Channel{ID: c})
+			}
+
+			configs[i] = bootstrap.Config{
+				MFThing:     r.ThingID,
+				ExternalID:  r.ExternalID,
+				ExternalKey: r.ExternalKey,
+				MFChannels:  channels,
+				Name:        r.Name,
+				ClientCert:  r.ClientCert,
+				ClientKey:   r.ClientKey,
+				CACert:      r.CACert,
+				Content:     r.Content,
+				State:       r.state(active),
+			}
+		}
+
+		results, err := svc.Import(ctx, req.token, configs)
+		if err != nil {
+			return nil, err
+		}
+
+		res := importRes{
+			created: true,
+			Configs: []importItemRes{},
+		}
+		for i, r := range results {
+			item := importItemRes{ExternalID: configs[i].ExternalID}
+			if r.Err != nil {
+				item.Error = r.Err.Error()
+				res.created = false
+				res.Configs = append(res.Configs, item)
+				continue
+			}
+
+			item.MFThing = r.Config.MFThing
+			item.MFKey = r.Config.MFKey
+			for _, ch := range r.Config.MFChannels {
+				item.Channels = append(item.Channels, channelRes{
+					ID:       ch.ID,
+					Name:     ch.Name,
+					Metadata: ch.Metadata,
+				})
+			}
+			res.Configs = append(res.Configs, item)
+		}
+
+		return res, nil
+	}
+}
+
 func updateCertEndpoint(svc bootstrap.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(updateCertReq)
@@ -150,6 +213,100 @@ func updateConnEndpoint(svc bootstrap.Service) endpoint.Endpoint {
 	}
 }
 
+func listVersionsEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listVersionsReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page, err := svc.ListVersions(ctx, req.key, req.id, req.offset, req.limit)
+		if err != nil {
+			return nil, err
+		}
+
+		res := listVersionsRes{
+			Total:    page.Total,
+			Offset:   page.Offset,
+			Limit:    page.Limit,
+			Versions: []versionRes{},
+		}
+
+		for _, v := range page.Versions {
+			channels := v.Channels
+			if channels == nil {
+				channels = []string{}
+			}
+
+			res.Versions = append(res.Versions, versionRes{
+				Version:    v.Version,
+				Name:       v.Name,
+				Content:    v.Content,
+				ClientCert: v.ClientCert,
+				CACert:     v.CACert,
+				Channels:   channels,
+				Created:    v.Created,
+			})
+		}
+
+		return res, nil
+	}
+}
+
+func listAttemptsEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listAttemptsReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page, err := svc.ListAttempts(ctx, req.key, req.id, req.offset, req.limit)
+		if err != nil {
+			return nil, err
+		}
+
+		res := listAttemptsRes{
+			Total:    page.Total,
+			Offset:   page.Offset,
+			Limit:    page.Limit,
+			Attempts: []attemptRes{},
+		}
+
+		for _, a := range page.Attempts {
+			res.Attempts = append(res.Attempts, attemptRes{
+				IP:        a.IP,
+				Timestamp: a.Timestamp,
+				Result:    string(a.Result),
+			})
+		}
+
+		return res, nil
+	}
+}
+
+func rollbackEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(rollbackReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.Rollback(ctx, req.key, req.id, req.Version); err != nil {
+			return nil, err
+		}
+
+		res := configRes{
+			id:      req.id,
+			created: false,
+		}
+
+		return res, nil
+	}
+}
+
 func listEndpoint(svc bootstrap.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(listReq)
@@ -212,6 +369,257 @@ func removeEndpoint(svc bootstrap.Service) endpoint.Endpoint {
 	}
 }
 
+func addTemplateEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(addTemplateReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		t := bootstrap.Template{
+			Name:     req.Name,
+			Channels: req.Channels,
+			Content:  req.Content,
+		}
+
+		saved, err := svc.AddTemplate(ctx, req.token, t)
+		if err != nil {
+			return nil, err
+		}
+
+		res := templateRes{
+			id:      saved.ID,
+			created: true,
+		}
+
+		return res, nil
+	}
+}
+
+func viewTemplateEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(entityReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		t, err := svc.ViewTemplate(ctx, req.key, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		return toTemplateRes(t), nil
+	}
+}
+
+func listTemplatesEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listTemplatesReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page, err := svc.ListTemplates(ctx, req.key, req.offset, req.limit)
+		if err != nil {
+			return nil, err
+		}
+
+		res := listTemplatesRes{
+			Total:     page.Total,
+			Offset:    page.Offset,
+			Limit:     page.Limit,
+			Templates: []viewTemplateRes{},
+		}
+
+		for _, t := range page.Templates {
+			res.Templates = append(res.Templates, toTemplateRes(t))
+		}
+
+		return res, nil
+	}
+}
+
+func updateTemplateEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(updateTemplateReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		t := bootstrap.Template{
+			ID:       req.id,
+			Name:     req.Name,
+			Channels: req.Channels,
+			Content:  req.Content,
+		}
+
+		if err := svc.UpdateTemplate(ctx, req.key, t); err != nil {
+			return nil, err
+		}
+
+		res := templateRes{
+			id:      t.ID,
+			created: false,
+		}
+
+		return res, nil
+	}
+}
+
+func removeTemplateEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(entityReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.RemoveTemplate(ctx, req.key, req.id); err != nil {
+			return nil, err
+		}
+
+		return removeRes{}, nil
+	}
+}
+
+func addPatternEndpoint(svc bootstrap.Service, active bool) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(addPatternReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		p := bootstrap.Pattern{
+			Type:        bootstrap.PatternType(req.Type),
+			Expression:  req.Expression,
+			ExternalKey: req.ExternalKey,
+			TemplateID:  req.TemplateID,
+			State:       initialState(req.Active, active),
+		}
+
+		saved, err := svc.AddPattern(ctx, req.token, p)
+		if err != nil {
+			return nil, err
+		}
+
+		return patternRes{id: saved.ID}, nil
+	}
+}
+
+func viewPatternEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(entityReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		p, err := svc.ViewPattern(ctx, req.key, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		return toPatternRes(p), nil
+	}
+}
+
+func listPatternsEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listPatternsReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page, err := svc.ListPatterns(ctx, req.key, req.offset, req.limit)
+		if err != nil {
+			return nil, err
+		}
+
+		res := listPatternsRes{
+			Total:    page.Total,
+			Offset:   page.Offset,
+			Limit:    page.Limit,
+			Patterns: []viewPatternRes{},
+		}
+
+		for _, p := range page.Patterns {
+			res.Patterns = append(res.Patterns, toPatternRes(p))
+		}
+
+		return res, nil
+	}
+}
+
+func removePatternEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(entityReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.RemovePattern(ctx, req.key, req.id); err != nil {
+			return nil, err
+		}
+
+		return removeRes{}, nil
+	}
+}
+
+func toPatternRes(p bootstrap.Pattern) viewPatternRes {
+	return viewPatternRes{
+		ID:          p.ID,
+		Type:        string(p.Type),
+		Expression:  p.Expression,
+		ExternalKey: p.ExternalKey,
+		TemplateID:  p.TemplateID,
+		Active:      p.State == bootstrap.Active,
+	}
+}
+
+func instantiateEndpoint(svc bootstrap.Service, active bool) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(instantiateReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		config := bootstrap.Config{
+			MFThing:     req.ThingID,
+			ExternalID:  req.ExternalID,
+			ExternalKey: req.ExternalKey,
+			Name:        req.Name,
+			ClientCert:  req.ClientCert,
+			ClientKey:   req.ClientKey,
+			CACert:      req.CACert,
+			State:       initialState(req.Active, active),
+		}
+
+		saved, err := svc.Instantiate(ctx, req.token, req.id, config, req.Vars)
+		if err != nil {
+			return nil, err
+		}
+
+		res := configRes{
+			id:      saved.MFThing,
+			created: true,
+		}
+
+		return res, nil
+	}
+}
+
+func toTemplateRes(t bootstrap.Template) viewTemplateRes {
+	channels := t.Channels
+	if channels == nil {
+		channels = []string{}
+	}
+
+	return viewTemplateRes{
+		ID:       t.ID,
+		Name:     t.Name,
+		Channels: channels,
+		Content:  t.Content,
+	}
+}
+
 func bootstrapEndpoint(svc bootstrap.Service, reader bootstrap.ConfigReader, secure bool) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(bootstrapReq)
@@ -219,7 +627,7 @@ func bootstrapEndpoint(svc bootstrap.Service, reader bootstrap.ConfigReader, sec
 			return nil, err
 		}
 
-		cfg, err := svc.Bootstrap(ctx, req.key, req.id, secure)
+		cfg, err := svc.Bootstrap(ctx, req.key, req.id, req.ip, secure)
 		if err != nil {
 			return nil, err
 		}
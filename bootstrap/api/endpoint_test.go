@@ -24,6 +24,7 @@ import (
 	bsapi "github.com/mainflux/mainflux/bootstrap/api"
 	"github.com/mainflux/mainflux/bootstrap/mocks"
 	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/pkg/uuid"
 	"github.com/mainflux/mainflux/things"
 	thingsapi "github.com/mainflux/mainflux/things/api/things/http"
 	"github.com/opentracing/opentracing-go/mocktracer"
@@ -47,6 +48,7 @@ const (
 
 var (
 	encKey      = []byte("1234567891011121")
+	masterKey   = []byte("12345678910111213141516171819202")
 	addChannels = []string{"1"}
 	metadata    = map[string]interface{}{"meta": "data"}
 	addReq      = struct {
@@ -56,6 +58,7 @@ var (
 		Channels    []string `json:"channels"`
 		Name        string   `json:"name"`
 		Content     string   `json:"content"`
+		Active      bool     `json:"active,omitempty"`
 	}{
 		ExternalID:  "external-id",
 		ExternalKey: "external-key",
@@ -64,6 +67,16 @@ var (
 		Content:     "config",
 	}
 
+	templateReq = struct {
+		Name     string   `json:"name"`
+		Channels []string `json:"channels"`
+		Content  string   `json:"content"`
+	}{
+		Name:     "template",
+		Channels: []string{"1"},
+		Content:  `{"id": "{{externalID}}", "region": "{{region}}"}`,
+	}
+
 	updateReq = struct {
 		Channels   []string        `json:"channels,omitempty"`
 		Content    string          `json:"content,omitempty"`
@@ -164,7 +177,8 @@ func newService(auth mainflux.AuthServiceClient, url string) bootstrap.Service {
 	}
 
 	sdk := mfsdk.NewSDK(config)
-	return bootstrap.New(auth, things, sdk, encKey)
+	keyring, _ := bootstrap.NewKeyring(map[uint64][]byte{1: masterKey})
+	return bootstrap.New(auth, things, mocks.NewTemplatesRepository(), mocks.NewPatternsRepository(), sdk, uuid.NewMock(), keyring, encKey)
 }
 
 func generateChannels() map[string]things.Channel {
@@ -190,7 +204,7 @@ func newThingsServer(svc things.Service) *httptest.Server {
 }
 
 func newBootstrapServer(svc bootstrap.Service) *httptest.Server {
-	mux := bsapi.MakeHandler(svc, bootstrap.NewConfigReader(encKey))
+	mux := bsapi.MakeHandler(svc, bootstrap.NewConfigReader(encKey), false)
 	return httptest.NewServer(mux)
 }
 
@@ -216,6 +230,11 @@ func TestAdd(t *testing.T) {
 	invalidChannels.Channels = []string{wrongID}
 	wrongData := toJSON(invalidChannels)
 
+	active := addReq
+	active.ExternalID = "active"
+	active.Active = true
+	activeData := toJSON(active)
+
 	cases := []struct {
 		desc        string
 		req         string
@@ -240,6 +259,14 @@ func TestAdd(t *testing.T) {
 			status:      http.StatusCreated,
 			location:    "/things/configs/1",
 		},
+		{
+			desc:        "add a valid active config",
+			req:         activeData,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusCreated,
+			location:    "/things/configs/2",
+		},
 		{
 			desc:        "add a config with wring content type",
 			req:         data,
@@ -321,6 +348,160 @@ func TestAdd(t *testing.T) {
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
 		assert.Equal(t, tc.location, location, fmt.Sprintf("%s: expected location '%s' got '%s'", tc.desc, tc.location, location))
 	}
+
+	cfg, err := svc.View(context.Background(), validToken, "2")
+	require.Nil(t, err, fmt.Sprintf("Retrieving config expected to succeed: %s.\n", err))
+	assert.Equal(t, bootstrap.Active, cfg.State, fmt.Sprintf("add a valid active config: expected state %s got %s", bootstrap.Active, cfg.State))
+}
+
+func TestImport(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	ts := newThingsServer(newThingsService(users))
+	svc := newService(users, ts.URL)
+	bs := newBootstrapServer(svc)
+
+	first, second := addReq, addReq
+	first.ExternalID = "import-1"
+	second.ExternalID = "import-2"
+	data := toJSON([]interface{}{first, second})
+
+	dup := addReq
+	dup.ExternalID = "duplicate"
+	duplicate := toJSON([]interface{}{dup, dup})
+
+	invalidChannels := addReq
+	invalidChannels.ExternalID = "import-3"
+	invalidChannels.Channels = []string{wrongID}
+	wrongData := toJSON([]interface{}{invalidChannels})
+
+	csvData := "external_id,external_key,channels,name\nimport-4,key-4,1 2,name-4\nimport-5,key-5,,name-5\n"
+	wrongCSV := "external_id,external_key,unknown\nimport-6,key-6,value\n"
+
+	cases := []struct {
+		desc        string
+		req         string
+		auth        string
+		contentType string
+		status      int
+		errs        []string
+	}{
+		{
+			desc:        "import configs unauthorized",
+			req:         data,
+			auth:        invalidToken,
+			contentType: contentType,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:        "import valid configs",
+			req:         data,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusCreated,
+			errs:        []string{"", ""},
+		},
+		{
+			desc:        "import existing configs",
+			req:         data,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusUnprocessableEntity,
+			errs:        []string{bootstrap.ErrConflict.Error(), bootstrap.ErrImportAborted.Error()},
+		},
+		{
+			desc:        "import configs with duplicate external ID",
+			req:         duplicate,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusUnprocessableEntity,
+			errs:        []string{bootstrap.ErrImportAborted.Error(), bootstrap.ErrConflict.Error()},
+		},
+		{
+			desc:        "import configs with invalid channels",
+			req:         wrongData,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusUnprocessableEntity,
+			errs:        []string{bootstrap.ErrMalformedEntity.Error()},
+		},
+		{
+			desc:        "import configs from CSV",
+			req:         csvData,
+			auth:        validToken,
+			contentType: "text/csv",
+			status:      http.StatusCreated,
+			errs:        []string{"", ""},
+		},
+		{
+			desc:        "import configs from CSV with unknown column",
+			req:         wrongCSV,
+			auth:        validToken,
+			contentType: "text/csv",
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "import configs with wrong content type",
+			req:         data,
+			auth:        validToken,
+			contentType: "",
+			status:      http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:        "import empty list of configs",
+			req:         "[]",
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "import configs without external key",
+			req:         "[{\"external_id\": \"import-7\"}]",
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "import configs with invalid request format",
+			req:         "}",
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      bs.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/things/configs/bulk", bs.URL),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.errs == nil {
+			continue
+		}
+
+		var body struct {
+			Configs []struct {
+				MFThing string `json:"mainflux_id"`
+				Error   string `json:"error"`
+			} `json:"configs"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		require.Len(t, body.Configs, len(tc.errs), fmt.Sprintf("%s: expected %d results got %d", tc.desc, len(tc.errs), len(body.Configs)))
+		for i, c := range body.Configs {
+			assert.Contains(t, c.Error, tc.errs[i], fmt.Sprintf("%s: expected error '%s' got '%s'", tc.desc, tc.errs[i], c.Error))
+			if tc.errs[i] == "" {
+				assert.NotEmpty(t, c.MFThing, fmt.Sprintf("%s: expected Mainflux ID of imported config", tc.desc))
+			}
+		}
+	}
 }
 
 func TestView(t *testing.T) {
@@ -412,15 +593,497 @@ func TestView(t *testing.T) {
 			assert.Nil(t, err, fmt.Sprintf("Decoding expected to succeed %s: %s", tc.desc, err))
 		}
 
-		assert.ElementsMatch(t, tc.res.Channels, view.Channels, fmt.Sprintf("%s: expected response '%s' got '%s'", tc.desc, tc.res.Channels, view.Channels))
-		// Empty channels to prevent order mismatch.
-		tc.res.Channels = []channel{}
-		view.Channels = []channel{}
-		assert.Equal(t, tc.res, view, fmt.Sprintf("%s: expected response '%s' got '%s'", tc.desc, tc.res, view))
+		assert.ElementsMatch(t, tc.res.Channels, view.Channels, fmt.Sprintf("%s: expected response '%s' got '%s'", tc.desc, tc.res.Channels, view.Channels))
+		// Empty channels to prevent order mismatch.
+		tc.res.Channels = []channel{}
+		view.Channels = []channel{}
+		assert.Equal(t, tc.res, view, fmt.Sprintf("%s: expected response '%s' got '%s'", tc.desc, tc.res, view))
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	ts := newThingsServer(newThingsService(users))
+	svc := newService(users, ts.URL)
+	bs := newBootstrapServer(svc)
+
+	c := newConfig([]bootstrap.Channel{bootstrap.Channel{ID: "1"}})
+
+	saved, err := svc.Add(context.Background(), validToken, c)
+	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+
+	data := toJSON(updateReq)
+
+	cases := []struct {
+		desc        string
+		req         string
+		id          string
+		auth        string
+		contentType string
+		status      int
+	}{
+		{
+			desc:        "update unauthorized",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        invalidToken,
+			contentType: contentType,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:        "update with an empty token",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        "",
+			contentType: contentType,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:        "update a valid config",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "update a config with wrong content type",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        validToken,
+			contentType: "",
+			status:      http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:        "update a non-existing config",
+			req:         data,
+			id:          wrongID,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusNotFound,
+		},
+		{
+			desc:        "update a config with invalid request format",
+			req:         "}",
+			id:          saved.MFThing,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "update a config with an empty request",
+			id:          saved.MFThing,
+			req:         "",
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      bs.Client(),
+			method:      http.MethodPut,
+			url:         fmt.Sprintf("%s/things/configs/%s", bs.URL, tc.id),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+func TestUpdateCert(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	ts := newThingsServer(newThingsService(users))
+	svc := newService(users, ts.URL)
+	bs := newBootstrapServer(svc)
+
+	c := newConfig([]bootstrap.Channel{bootstrap.Channel{ID: "1"}})
+
+	saved, err := svc.Add(context.Background(), validToken, c)
+	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+
+	data := toJSON(updateReq)
+
+	cases := []struct {
+		desc        string
+		req         string
+		id          string
+		auth        string
+		contentType string
+		status      int
+	}{
+		{
+			desc:        "update unauthorized",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        invalidToken,
+			contentType: contentType,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:        "update with an empty token",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        "",
+			contentType: contentType,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:        "update a valid config",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "update a config with wrong content type",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        validToken,
+			contentType: "",
+			status:      http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:        "update a non-existing config",
+			req:         data,
+			id:          wrongID,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusNotFound,
+		},
+		{
+			desc:        "update a config with invalid request format",
+			req:         "}",
+			id:          saved.MFKey,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "update a config with an empty request",
+			id:          saved.MFThing,
+			req:         "",
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      bs.Client(),
+			method:      http.MethodPatch,
+			url:         fmt.Sprintf("%s/things/configs/certs/%s", bs.URL, tc.id),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestUpdateConnections(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	ts := newThingsServer(newThingsService(users))
+	svc := newService(users, ts.URL)
+	bs := newBootstrapServer(svc)
+
+	c := newConfig([]bootstrap.Channel{bootstrap.Channel{ID: "1"}})
+
+	saved, err := svc.Add(context.Background(), validToken, c)
+	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+
+	data := toJSON(updateReq)
+
+	invalidChannels := updateReq
+	invalidChannels.Channels = []string{wrongID}
+
+	wrongData := toJSON(invalidChannels)
+
+	cases := []struct {
+		desc        string
+		req         string
+		id          string
+		auth        string
+		contentType string
+		status      int
+	}{
+		{
+			desc:        "update connections unauthorized",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        invalidToken,
+			contentType: contentType,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:        "update connections with an empty token",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        "",
+			contentType: contentType,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:        "update connections valid config",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "update connections with wrong content type",
+			req:         data,
+			id:          saved.MFThing,
+			auth:        validToken,
+			contentType: "",
+			status:      http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:        "update connections for a non-existing config",
+			req:         data,
+			id:          wrongID,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusNotFound,
+		},
+		{
+			desc:        "update connections with invalid channels",
+			req:         wrongData,
+			id:          saved.MFThing,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "update a config with invalid request format",
+			req:         "}",
+			id:          saved.MFThing,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "update a config with an empty request",
+			id:          saved.MFThing,
+			req:         "",
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      bs.Client(),
+			method:      http.MethodPut,
+			url:         fmt.Sprintf("%s/things/configs/connections/%s", bs.URL, tc.id),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestListVersions(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	ts := newThingsServer(newThingsService(users))
+	svc := newService(users, ts.URL)
+	bs := newBootstrapServer(svc)
+
+	c := newConfig([]bootstrap.Channel{bootstrap.Channel{ID: "1"}})
+
+	saved, err := svc.Add(context.Background(), validToken, c)
+	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+	saved.Content = "updated"
+	err = svc.Update(context.Background(), validToken, saved)
+	require.Nil(t, err, fmt.Sprintf("Updating config expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc     string
+		url      string
+		auth     string
+		status   int
+		versions []versionRes
+	}{
+		{
+			desc:   "list versions",
+			url:    fmt.Sprintf("%s/things/configs/versions/%s", bs.URL, saved.MFThing),
+			auth:   validToken,
+			status: http.StatusOK,
+			versions: []versionRes{
+				{Version: 2, Content: "updated", Channels: []string{"1"}},
+				{Version: 1, Content: addContent, Channels: []string{"1"}},
+			},
+		},
+		{
+			desc:   "list versions with offset and limit",
+			url:    fmt.Sprintf("%s/things/configs/versions/%s?offset=1&limit=1", bs.URL, saved.MFThing),
+			auth:   validToken,
+			status: http.StatusOK,
+			versions: []versionRes{
+				{Version: 1, Content: addContent, Channels: []string{"1"}},
+			},
+		},
+		{
+			desc:     "list versions with invalid offset",
+			url:      fmt.Sprintf("%s/things/configs/versions/%s?offset=wrong", bs.URL, saved.MFThing),
+			auth:     validToken,
+			status:   http.StatusBadRequest,
+			versions: nil,
+		},
+		{
+			desc:     "list versions of non-existing config",
+			url:      fmt.Sprintf("%s/things/configs/versions/%s", bs.URL, wrongID),
+			auth:     validToken,
+			status:   http.StatusNotFound,
+			versions: nil,
+		},
+		{
+			desc:     "list versions unauthorized",
+			url:      fmt.Sprintf("%s/things/configs/versions/%s", bs.URL, saved.MFThing),
+			auth:     invalidToken,
+			status:   http.StatusForbidden,
+			versions: nil,
+		},
+		{
+			desc:     "list versions with an empty token",
+			url:      fmt.Sprintf("%s/things/configs/versions/%s", bs.URL, saved.MFThing),
+			auth:     "",
+			status:   http.StatusForbidden,
+			versions: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: bs.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var page versionsPage
+		json.NewDecoder(res.Body).Decode(&page)
+		var versions []versionRes
+		for _, v := range page.Versions {
+			versions = append(versions, versionRes{Version: v.Version, Content: v.Content, Channels: v.Channels})
+		}
+		assert.Equal(t, tc.versions, versions, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.versions, versions))
+	}
+}
+
+func TestListAttempts(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	ts := newThingsServer(newThingsService(users))
+	svc := newService(users, ts.URL)
+	bs := newBootstrapServer(svc)
+
+	c := newConfig([]bootstrap.Channel{bootstrap.Channel{ID: "1"}})
+
+	saved, err := svc.Add(context.Background(), validToken, c)
+	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+
+	for _, key := range []string{"invalid", saved.ExternalKey} {
+		req := testRequest{
+			client: bs.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/things/bootstrap/%s", bs.URL, saved.ExternalID),
+			token:  key,
+		}
+		_, err := req.make()
+		require.Nil(t, err, fmt.Sprintf("Bootstrap request expected to succeed: %s.\n", err))
+	}
+
+	cases := []struct {
+		desc     string
+		url      string
+		auth     string
+		status   int
+		attempts []attemptRes
+	}{
+		{
+			desc:   "list attempts",
+			url:    fmt.Sprintf("%s/things/configs/attempts/%s", bs.URL, saved.MFThing),
+			auth:   validToken,
+			status: http.StatusOK,
+			attempts: []attemptRes{
+				{IP: "127.0.0.1", Result: string(bootstrap.AttemptSuccess)},
+				{IP: "127.0.0.1", Result: string(bootstrap.AttemptInvalidKey)},
+			},
+		},
+		{
+			desc:   "list attempts with offset and limit",
+			url:    fmt.Sprintf("%s/things/configs/attempts/%s?offset=1&limit=1", bs.URL, saved.MFThing),
+			auth:   validToken,
+			status: http.StatusOK,
+			attempts: []attemptRes{
+				{IP: "127.0.0.1", Result: string(bootstrap.AttemptInvalidKey)},
+			},
+		},
+		{
+			desc:     "list attempts with invalid limit",
+			url:      fmt.Sprintf("%s/things/configs/attempts/%s?limit=wrong", bs.URL, saved.MFThing),
+			auth:     validToken,
+			status:   http.StatusBadRequest,
+			attempts: nil,
+		},
+		{
+			desc:     "list attempts of non-existing config",
+			url:      fmt.Sprintf("%s/things/configs/attempts/%s", bs.URL, wrongID),
+			auth:     validToken,
+			status:   http.StatusNotFound,
+			attempts: nil,
+		},
+		{
+			desc:     "list attempts unauthorized",
+			url:      fmt.Sprintf("%s/things/configs/attempts/%s", bs.URL, saved.MFThing),
+			auth:     invalidToken,
+			status:   http.StatusForbidden,
+			attempts: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: bs.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var page attemptsPage
+		json.NewDecoder(res.Body).Decode(&page)
+		var attempts []attemptRes
+		for _, a := range page.Attempts {
+			attempts = append(attempts, attemptRes{IP: a.IP, Result: a.Result})
+		}
+		assert.Equal(t, tc.attempts, attempts, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.attempts, attempts))
 	}
 }
 
-func TestUpdate(t *testing.T) {
+func TestRollback(t *testing.T) {
 	users := mocks.NewUsersService(map[string]string{validToken: email})
 
 	ts := newThingsServer(newThingsService(users))
@@ -431,8 +1094,11 @@ func TestUpdate(t *testing.T) {
 
 	saved, err := svc.Add(context.Background(), validToken, c)
 	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+	saved.Content = "updated"
+	err = svc.Update(context.Background(), validToken, saved)
+	require.Nil(t, err, fmt.Sprintf("Updating config expected to succeed: %s.\n", err))
 
-	data := toJSON(updateReq)
+	data := toJSON(map[string]uint64{"version": 1})
 
 	cases := []struct {
 		desc        string
@@ -443,7 +1109,7 @@ func TestUpdate(t *testing.T) {
 		status      int
 	}{
 		{
-			desc:        "update unauthorized",
+			desc:        "roll back config unauthorized",
 			req:         data,
 			id:          saved.MFThing,
 			auth:        invalidToken,
@@ -451,7 +1117,7 @@ func TestUpdate(t *testing.T) {
 			status:      http.StatusForbidden,
 		},
 		{
-			desc:        "update with an empty token",
+			desc:        "roll back config with an empty token",
 			req:         data,
 			id:          saved.MFThing,
 			auth:        "",
@@ -459,7 +1125,7 @@ func TestUpdate(t *testing.T) {
 			status:      http.StatusForbidden,
 		},
 		{
-			desc:        "update a valid config",
+			desc:        "roll back config",
 			req:         data,
 			id:          saved.MFThing,
 			auth:        validToken,
@@ -467,7 +1133,7 @@ func TestUpdate(t *testing.T) {
 			status:      http.StatusOK,
 		},
 		{
-			desc:        "update a config with wrong content type",
+			desc:        "roll back config with wrong content type",
 			req:         data,
 			id:          saved.MFThing,
 			auth:        validToken,
@@ -475,7 +1141,7 @@ func TestUpdate(t *testing.T) {
 			status:      http.StatusUnsupportedMediaType,
 		},
 		{
-			desc:        "update a non-existing config",
+			desc:        "roll back non-existing config",
 			req:         data,
 			id:          wrongID,
 			auth:        validToken,
@@ -483,17 +1149,25 @@ func TestUpdate(t *testing.T) {
 			status:      http.StatusNotFound,
 		},
 		{
-			desc:        "update a config with invalid request format",
-			req:         "}",
+			desc:        "roll back config to non-existing version",
+			req:         toJSON(map[string]uint64{"version": 100}),
+			id:          saved.MFThing,
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusNotFound,
+		},
+		{
+			desc:        "roll back config without version",
+			req:         "{}",
 			id:          saved.MFThing,
 			auth:        validToken,
 			contentType: contentType,
 			status:      http.StatusBadRequest,
 		},
 		{
-			desc:        "update a config with an empty request",
+			desc:        "roll back config with invalid request format",
+			req:         "}",
 			id:          saved.MFThing,
-			req:         "",
 			auth:        validToken,
 			contentType: contentType,
 			status:      http.StatusBadRequest,
@@ -504,7 +1178,7 @@ func TestUpdate(t *testing.T) {
 		req := testRequest{
 			client:      bs.Client(),
 			method:      http.MethodPut,
-			url:         fmt.Sprintf("%s/things/configs/%s", bs.URL, tc.id),
+			url:         fmt.Sprintf("%s/things/configs/rollback/%s", bs.URL, tc.id),
 			contentType: tc.contentType,
 			token:       tc.auth,
 			body:        strings.NewReader(tc.req),
@@ -513,208 +1187,390 @@ func TestUpdate(t *testing.T) {
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
 	}
+
+	cfg, err := svc.View(context.Background(), validToken, saved.MFThing)
+	require.Nil(t, err, fmt.Sprintf("Viewing config expected to succeed: %s.\n", err))
+	assert.Equal(t, addContent, cfg.Content, fmt.Sprintf("roll back config: expected content %s got %s", addContent, cfg.Content))
 }
-func TestUpdateCert(t *testing.T) {
+
+func TestAddTemplate(t *testing.T) {
 	users := mocks.NewUsersService(map[string]string{validToken: email})
 
 	ts := newThingsServer(newThingsService(users))
 	svc := newService(users, ts.URL)
 	bs := newBootstrapServer(svc)
 
-	c := newConfig([]bootstrap.Channel{bootstrap.Channel{ID: "1"}})
-
-	saved, err := svc.Add(context.Background(), validToken, c)
-	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
-
-	data := toJSON(updateReq)
+	data := toJSON(templateReq)
 
 	cases := []struct {
 		desc        string
 		req         string
-		id          string
 		auth        string
 		contentType string
 		status      int
+		location    string
 	}{
 		{
-			desc:        "update unauthorized",
+			desc:        "add a template unauthorized",
 			req:         data,
-			id:          saved.MFThing,
 			auth:        invalidToken,
 			contentType: contentType,
 			status:      http.StatusForbidden,
+			location:    "",
 		},
 		{
-			desc:        "update with an empty token",
-			req:         data,
-			id:          saved.MFThing,
-			auth:        "",
-			contentType: contentType,
-			status:      http.StatusForbidden,
-		},
-		{
-			desc:        "update a valid config",
+			desc:        "add a valid template",
 			req:         data,
-			id:          saved.MFThing,
 			auth:        validToken,
 			contentType: contentType,
-			status:      http.StatusOK,
+			status:      http.StatusCreated,
+			location:    fmt.Sprintf("/things/templates/%s%012d", uuid.Prefix, 1),
 		},
 		{
-			desc:        "update a config with wrong content type",
+			desc:        "add a template with wrong content type",
 			req:         data,
-			id:          saved.MFThing,
 			auth:        validToken,
 			contentType: "",
 			status:      http.StatusUnsupportedMediaType,
+			location:    "",
 		},
 		{
-			desc:        "update a non-existing config",
-			req:         data,
-			id:          wrongID,
-			auth:        validToken,
-			contentType: contentType,
-			status:      http.StatusNotFound,
-		},
-		{
-			desc:        "update a config with invalid request format",
-			req:         "}",
-			id:          saved.MFKey,
+			desc:        "add a template without name",
+			req:         "{\"content\": \"content\"}",
 			auth:        validToken,
 			contentType: contentType,
 			status:      http.StatusBadRequest,
+			location:    "",
 		},
 		{
-			desc:        "update a config with an empty request",
-			id:          saved.MFThing,
-			req:         "",
+			desc:        "add a template with invalid request format",
+			req:         "}",
 			auth:        validToken,
 			contentType: contentType,
 			status:      http.StatusBadRequest,
+			location:    "",
 		},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
 			client:      bs.Client(),
-			method:      http.MethodPatch,
-			url:         fmt.Sprintf("%s/things/configs/certs/%s", bs.URL, tc.id),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/things/templates", bs.URL),
 			contentType: tc.contentType,
 			token:       tc.auth,
 			body:        strings.NewReader(tc.req),
 		}
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		location := res.Header.Get("Location")
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.location, location, fmt.Sprintf("%s: expected location '%s' got '%s'", tc.desc, tc.location, location))
 	}
 }
 
-func TestUpdateConnections(t *testing.T) {
+func TestViewTemplate(t *testing.T) {
 	users := mocks.NewUsersService(map[string]string{validToken: email})
 
 	ts := newThingsServer(newThingsService(users))
 	svc := newService(users, ts.URL)
 	bs := newBootstrapServer(svc)
 
-	c := newConfig([]bootstrap.Channel{bootstrap.Channel{ID: "1"}})
+	saved, err := svc.AddTemplate(context.Background(), validToken, bootstrap.Template{
+		Name:     templateReq.Name,
+		Channels: templateReq.Channels,
+		Content:  templateReq.Content,
+	})
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	data := template{
+		ID:       saved.ID,
+		Name:     saved.Name,
+		Channels: saved.Channels,
+		Content:  saved.Content,
+	}
 
-	saved, err := svc.Add(context.Background(), validToken, c)
-	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+	cases := []struct {
+		desc   string
+		auth   string
+		id     string
+		status int
+		res    template
+	}{
+		{
+			desc:   "view a template unauthorized",
+			auth:   invalidToken,
+			id:     saved.ID,
+			status: http.StatusForbidden,
+			res:    template{},
+		},
+		{
+			desc:   "view a template",
+			auth:   validToken,
+			id:     saved.ID,
+			status: http.StatusOK,
+			res:    data,
+		},
+		{
+			desc:   "view a non-existing template",
+			auth:   validToken,
+			id:     wrongID,
+			status: http.StatusNotFound,
+			res:    template{},
+		},
+	}
 
-	data := toJSON(updateReq)
+	for _, tc := range cases {
+		req := testRequest{
+			client: bs.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/things/templates/%s", bs.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
 
-	invalidChannels := updateReq
-	invalidChannels.Channels = []string{wrongID}
+		var view template
+		json.NewDecoder(res.Body).Decode(&view)
+		assert.Equal(t, tc.res, view, fmt.Sprintf("%s: expected response '%v' got '%v'", tc.desc, tc.res, view))
+	}
+}
 
-	wrongData := toJSON(invalidChannels)
+func TestAddPattern(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	ts := newThingsServer(newThingsService(users))
+	svc := newService(users, ts.URL)
+	bs := newBootstrapServer(svc)
+
+	tmpl, err := svc.AddTemplate(context.Background(), validToken, bootstrap.Template{
+		Name:     templateReq.Name,
+		Channels: templateReq.Channels,
+		Content:  `{"id": "{{externalID}}"}`,
+	})
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	data := toJSON(struct {
+		Type        string `json:"type"`
+		Expression  string `json:"expression"`
+		ExternalKey string `json:"external_key"`
+		TemplateID  string `json:"template_id"`
+		Active      bool   `json:"active"`
+	}{
+		Type:        string(bootstrap.PrefixPattern),
+		Expression:  "SN-",
+		ExternalKey: "batch_key",
+		TemplateID:  tmpl.ID,
+		Active:      true,
+	})
 
 	cases := []struct {
 		desc        string
 		req         string
-		id          string
 		auth        string
 		contentType string
 		status      int
+		location    string
 	}{
 		{
-			desc:        "update connections unauthorized",
+			desc:        "add a pattern unauthorized",
 			req:         data,
-			id:          saved.MFThing,
 			auth:        invalidToken,
 			contentType: contentType,
 			status:      http.StatusForbidden,
+			location:    "",
 		},
 		{
-			desc:        "update connections with an empty token",
+			desc:        "add a valid pattern",
 			req:         data,
-			id:          saved.MFThing,
-			auth:        "",
+			auth:        validToken,
 			contentType: contentType,
-			status:      http.StatusForbidden,
+			status:      http.StatusCreated,
+			location:    fmt.Sprintf("/things/patterns/%s%012d", uuid.Prefix, 2),
 		},
 		{
-			desc:        "update connections valid config",
+			desc:        "add a pattern with wrong content type",
 			req:         data,
-			id:          saved.MFThing,
+			auth:        validToken,
+			contentType: "",
+			status:      http.StatusUnsupportedMediaType,
+			location:    "",
+		},
+		{
+			desc:        "add a pattern of unknown type",
+			req:         fmt.Sprintf(`{"type": "glob", "expression": "SN-*", "external_key": "key", "template_id": "%s"}`, tmpl.ID),
 			auth:        validToken,
 			contentType: contentType,
-			status:      http.StatusOK,
+			status:      http.StatusBadRequest,
+			location:    "",
 		},
 		{
-			desc:        "update connections with wrong content type",
-			req:         data,
-			id:          saved.MFThing,
+			desc:        "add a pattern without template",
+			req:         `{"type": "prefix", "expression": "SN-", "external_key": "key"}`,
 			auth:        validToken,
-			contentType: "",
-			status:      http.StatusUnsupportedMediaType,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+			location:    "",
 		},
 		{
-			desc:        "update connections for a non-existing config",
+			desc:        "add a pattern with invalid request format",
+			req:         "}",
+			auth:        validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+			location:    "",
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      bs.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/things/patterns", bs.URL),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		location := res.Header.Get("Location")
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.location, location, fmt.Sprintf("%s: expected location '%s' got '%s'", tc.desc, tc.location, location))
+	}
+
+	// The Thing matching the Pattern is provisioned on its first bootstrap.
+	req := testRequest{
+		client: bs.Client(),
+		method: http.MethodGet,
+		url:    fmt.Sprintf("%s/things/bootstrap/%s", bs.URL, "SN-1"),
+		token:  "batch_key",
+	}
+	res, err := req.make()
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("expected status code %d got %d", http.StatusOK, res.StatusCode))
+}
+
+func TestInstantiate(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	ts := newThingsServer(newThingsService(users))
+	svc := newService(users, ts.URL)
+	bs := newBootstrapServer(svc)
+
+	saved, err := svc.AddTemplate(context.Background(), validToken, bootstrap.Template{
+		Name:     templateReq.Name,
+		Channels: templateReq.Channels,
+		Content:  templateReq.Content,
+	})
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	instantiateReq := struct {
+		ExternalID  string            `json:"external_id"`
+		ExternalKey string            `json:"external_key"`
+		Name        string            `json:"name"`
+		Vars        map[string]string `json:"vars"`
+	}{
+		ExternalID:  "instantiated",
+		ExternalKey: "external-key",
+		Name:        "name",
+		Vars:        map[string]string{"region": "eu"},
+	}
+	data := toJSON(instantiateReq)
+
+	missingVar := instantiateReq
+	missingVar.ExternalID = "missing"
+	missingVar.Vars = nil
+	missingData := toJSON(missingVar)
+
+	cases := []struct {
+		desc        string
+		req         string
+		id          string
+		auth        string
+		contentType string
+		status      int
+		location    string
+	}{
+		{
+			desc:        "instantiate a template unauthorized",
+			req:         data,
+			id:          saved.ID,
+			auth:        invalidToken,
+			contentType: contentType,
+			status:      http.StatusForbidden,
+			location:    "",
+		},
+		{
+			desc:        "instantiate a non-existing template",
 			req:         data,
 			id:          wrongID,
 			auth:        validToken,
 			contentType: contentType,
 			status:      http.StatusNotFound,
+			location:    "",
 		},
 		{
-			desc:        "update connections with invalid channels",
-			req:         wrongData,
-			id:          saved.MFThing,
+			desc:        "instantiate a template with a missing variable",
+			req:         missingData,
+			id:          saved.ID,
 			auth:        validToken,
 			contentType: contentType,
 			status:      http.StatusBadRequest,
+			location:    "",
 		},
 		{
-			desc:        "update a config with invalid request format",
-			req:         "}",
-			id:          saved.MFThing,
+			desc:        "instantiate a template with wrong content type",
+			req:         data,
+			id:          saved.ID,
+			auth:        validToken,
+			contentType: "",
+			status:      http.StatusUnsupportedMediaType,
+			location:    "",
+		},
+		{
+			desc:        "instantiate a template without external key",
+			req:         "{\"external_id\": \"external-id\"}",
+			id:          saved.ID,
 			auth:        validToken,
 			contentType: contentType,
 			status:      http.StatusBadRequest,
+			location:    "",
 		},
 		{
-			desc:        "update a config with an empty request",
-			id:          saved.MFThing,
-			req:         "",
+			desc:        "instantiate a template",
+			req:         data,
+			id:          saved.ID,
 			auth:        validToken,
 			contentType: contentType,
-			status:      http.StatusBadRequest,
+			status:      http.StatusCreated,
+			location:    "/things/configs/1",
 		},
 	}
 
 	for _, tc := range cases {
 		req := testRequest{
 			client:      bs.Client(),
-			method:      http.MethodPut,
-			url:         fmt.Sprintf("%s/things/configs/connections/%s", bs.URL, tc.id),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/things/templates/%s/configs", bs.URL, tc.id),
 			contentType: tc.contentType,
 			token:       tc.auth,
 			body:        strings.NewReader(tc.req),
 		}
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		location := res.Header.Get("Location")
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.location, location, fmt.Sprintf("%s: expected location '%s' got '%s'", tc.desc, tc.location, location))
 	}
+
+	cfg, err := svc.View(context.Background(), validToken, "1")
+	require.Nil(t, err, fmt.Sprintf("Retrieving config expected to succeed: %s.\n", err))
+	content := `{"id": "instantiated", "region": "eu"}`
+	assert.Equal(t, content, cfg.Content, fmt.Sprintf("instantiate a template: expected content %s got %s", content, cfg.Content))
 }
 
 func TestList(t *testing.T) {
@@ -1288,6 +2144,38 @@ type configPage struct {
 	Configs []config `json:"configs"`
 }
 
+type versionRes struct {
+	Version  uint64   `json:"version"`
+	Content  string   `json:"content,omitempty"`
+	Channels []string `json:"mainflux_channels"`
+}
+
+type versionsPage struct {
+	Total    uint64       `json:"total"`
+	Offset   uint64       `json:"offset"`
+	Limit    uint64       `json:"limit"`
+	Versions []versionRes `json:"versions"`
+}
+
+type attemptRes struct {
+	IP     string `json:"ip,omitempty"`
+	Result string `json:"result"`
+}
+
+type attemptsPage struct {
+	Total    uint64       `json:"total"`
+	Offset   uint64       `json:"offset"`
+	Limit    uint64       `json:"limit"`
+	Attempts []attemptRes `json:"attempts"`
+}
+
+type template struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Channels []string `json:"channels"`
+	Content  string   `json:"content,omitempty"`
+}
+
 type errorRes struct {
 	Err string `json:"error"`
 }
@@ -39,6 +39,19 @@ func (lm *loggingMiddleware) Add(ctx context.Context, token string, cfg bootstra
 	return lm.svc.Add(ctx, token, cfg)
 }
 
+func (lm *loggingMiddleware) Import(ctx context.Context, token string, cfgs []bootstrap.Config) (res []bootstrap.ImportResult, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method import for token %s and %d configs took %s to complete", token, len(cfgs), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Import(ctx, token, cfgs)
+}
+
 func (lm *loggingMiddleware) View(ctx context.Context, token, id string) (saved bootstrap.Config, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method view for token %s and thing %s took %s to complete", token, saved.MFThing, time.Since(begin))
@@ -91,6 +104,175 @@ func (lm *loggingMiddleware) UpdateConnections(ctx context.Context, token, id st
 	return lm.svc.UpdateConnections(ctx, token, id, connections)
 }
 
+func (lm *loggingMiddleware) ListVersions(ctx context.Context, token, id string, offset, limit uint64) (res bootstrap.VersionsPage, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method list_versions for token %s and thing %s took %s to complete", token, id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ListVersions(ctx, token, id, offset, limit)
+}
+
+func (lm *loggingMiddleware) Rollback(ctx context.Context, token, id string, version uint64) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method rollback for token %s and thing %s to version %d took %s to complete", token, id, version, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Rollback(ctx, token, id, version)
+}
+
+func (lm *loggingMiddleware) AddTemplate(ctx context.Context, token string, t bootstrap.Template) (saved bootstrap.Template, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method add_template for token %s and template %s took %s to complete", token, saved.ID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.AddTemplate(ctx, token, t)
+}
+
+func (lm *loggingMiddleware) ViewTemplate(ctx context.Context, token, id string) (saved bootstrap.Template, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method view_template for token %s and template %s took %s to complete", token, id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ViewTemplate(ctx, token, id)
+}
+
+func (lm *loggingMiddleware) ListTemplates(ctx context.Context, token string, offset, limit uint64) (res bootstrap.TemplatesPage, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method list_templates for token %s and offset %d and limit %d took %s to complete", token, offset, limit, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ListTemplates(ctx, token, offset, limit)
+}
+
+func (lm *loggingMiddleware) UpdateTemplate(ctx context.Context, token string, t bootstrap.Template) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method update_template for token %s and template %s took %s to complete", token, t.ID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.UpdateTemplate(ctx, token, t)
+}
+
+func (lm *loggingMiddleware) RemoveTemplate(ctx context.Context, token, id string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method remove_template for token %s and template %s took %s to complete", token, id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.RemoveTemplate(ctx, token, id)
+}
+
+func (lm *loggingMiddleware) Instantiate(ctx context.Context, token, id string, cfg bootstrap.Config, vars map[string]string) (saved bootstrap.Config, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method instantiate for token %s and template %s took %s to complete", token, id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Instantiate(ctx, token, id, cfg, vars)
+}
+
+func (lm *loggingMiddleware) AddPattern(ctx context.Context, token string, p bootstrap.Pattern) (saved bootstrap.Pattern, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method add_pattern for token %s took %s to complete", token, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.AddPattern(ctx, token, p)
+}
+
+func (lm *loggingMiddleware) ViewPattern(ctx context.Context, token, id string) (p bootstrap.Pattern, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method view_pattern for token %s and pattern %s took %s to complete", token, id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ViewPattern(ctx, token, id)
+}
+
+func (lm *loggingMiddleware) ListPatterns(ctx context.Context, token string, offset, limit uint64) (res bootstrap.PatternsPage, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method list_patterns for token %s and offset %d and limit %d took %s to complete", token, offset, limit, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ListPatterns(ctx, token, offset, limit)
+}
+
+func (lm *loggingMiddleware) RemovePattern(ctx context.Context, token, id string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method remove_pattern for token %s and pattern %s took %s to complete", token, id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.RemovePattern(ctx, token, id)
+}
+
+func (lm *loggingMiddleware) RewrapKeys(ctx context.Context) (count uint64, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method rewrap_keys re-encrypted %d keys and took %s to complete", count, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.RewrapKeys(ctx)
+}
+
 func (lm *loggingMiddleware) List(ctx context.Context, token string, filter bootstrap.Filter, offset, limit uint64) (res bootstrap.ConfigsPage, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method list for token %s and offset %d and limit %d took %s to complete", token, offset, limit, time.Since(begin))
@@ -117,9 +299,22 @@ func (lm *loggingMiddleware) Remove(ctx context.Context, token, id string) (err
 	return lm.svc.Remove(ctx, token, id)
 }
 
-func (lm *loggingMiddleware) Bootstrap(ctx context.Context, externalKey, externalID string, secure bool) (cfg bootstrap.Config, err error) {
+func (lm *loggingMiddleware) Bootstrap(ctx context.Context, externalKey, externalID, ip string, secure bool) (cfg bootstrap.Config, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method bootstrap for thing with external id %s from %s took %s to complete", externalID, ip, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Bootstrap(ctx, externalKey, externalID, ip, secure)
+}
+
+func (lm *loggingMiddleware) ListAttempts(ctx context.Context, token, id string, offset, limit uint64) (res bootstrap.AttemptsPage, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method bootstrap for thing with external id %s took %s to complete", externalID, time.Since(begin))
+		message := fmt.Sprintf("Method list_attempts for token %s and thing %s took %s to complete", token, id, time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -127,7 +322,7 @@ func (lm *loggingMiddleware) Bootstrap(ctx context.Context, externalKey, externa
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.Bootstrap(ctx, externalKey, externalID, secure)
+	return lm.svc.ListAttempts(ctx, token, id, offset, limit)
 }
 
 func (lm *loggingMiddleware) ChangeState(ctx context.Context, token, id string, state bootstrap.State) (err error) {
@@ -39,6 +39,15 @@ func (mm *metricsMiddleware) Add(ctx context.Context, token string, cfg bootstra
 	return mm.svc.Add(ctx, token, cfg)
 }
 
+func (mm *metricsMiddleware) Import(ctx context.Context, token string, cfgs []bootstrap.Config) (res []bootstrap.ImportResult, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "import").Add(1)
+		mm.latency.With("method", "import").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Import(ctx, token, cfgs)
+}
+
 func (mm *metricsMiddleware) View(ctx context.Context, token, id string) (saved bootstrap.Config, err error) {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "view").Add(1)
@@ -75,6 +84,123 @@ func (mm *metricsMiddleware) UpdateConnections(ctx context.Context, token, id st
 	return mm.svc.UpdateConnections(ctx, token, id, connections)
 }
 
+func (mm *metricsMiddleware) ListVersions(ctx context.Context, token, id string, offset, limit uint64) (page bootstrap.VersionsPage, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "list_versions").Add(1)
+		mm.latency.With("method", "list_versions").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.ListVersions(ctx, token, id, offset, limit)
+}
+
+func (mm *metricsMiddleware) Rollback(ctx context.Context, token, id string, version uint64) (err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "rollback").Add(1)
+		mm.latency.With("method", "rollback").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Rollback(ctx, token, id, version)
+}
+
+func (mm *metricsMiddleware) AddTemplate(ctx context.Context, token string, t bootstrap.Template) (saved bootstrap.Template, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "add_template").Add(1)
+		mm.latency.With("method", "add_template").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.AddTemplate(ctx, token, t)
+}
+
+func (mm *metricsMiddleware) ViewTemplate(ctx context.Context, token, id string) (saved bootstrap.Template, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "view_template").Add(1)
+		mm.latency.With("method", "view_template").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.ViewTemplate(ctx, token, id)
+}
+
+func (mm *metricsMiddleware) ListTemplates(ctx context.Context, token string, offset, limit uint64) (saved bootstrap.TemplatesPage, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "list_templates").Add(1)
+		mm.latency.With("method", "list_templates").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.ListTemplates(ctx, token, offset, limit)
+}
+
+func (mm *metricsMiddleware) UpdateTemplate(ctx context.Context, token string, t bootstrap.Template) (err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "update_template").Add(1)
+		mm.latency.With("method", "update_template").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.UpdateTemplate(ctx, token, t)
+}
+
+func (mm *metricsMiddleware) RemoveTemplate(ctx context.Context, token, id string) (err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "remove_template").Add(1)
+		mm.latency.With("method", "remove_template").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.RemoveTemplate(ctx, token, id)
+}
+
+func (mm *metricsMiddleware) Instantiate(ctx context.Context, token, id string, cfg bootstrap.Config, vars map[string]string) (saved bootstrap.Config, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "instantiate").Add(1)
+		mm.latency.With("method", "instantiate").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Instantiate(ctx, token, id, cfg, vars)
+}
+
+func (mm *metricsMiddleware) AddPattern(ctx context.Context, token string, p bootstrap.Pattern) (saved bootstrap.Pattern, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "add_pattern").Add(1)
+		mm.latency.With("method", "add_pattern").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.AddPattern(ctx, token, p)
+}
+
+func (mm *metricsMiddleware) ViewPattern(ctx context.Context, token, id string) (p bootstrap.Pattern, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "view_pattern").Add(1)
+		mm.latency.With("method", "view_pattern").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.ViewPattern(ctx, token, id)
+}
+
+func (mm *metricsMiddleware) ListPatterns(ctx context.Context, token string, offset, limit uint64) (res bootstrap.PatternsPage, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "list_patterns").Add(1)
+		mm.latency.With("method", "list_patterns").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.ListPatterns(ctx, token, offset, limit)
+}
+
+func (mm *metricsMiddleware) RemovePattern(ctx context.Context, token, id string) (err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "remove_pattern").Add(1)
+		mm.latency.With("method", "remove_pattern").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.RemovePattern(ctx, token, id)
+}
+
+func (mm *metricsMiddleware) RewrapKeys(ctx context.Context) (count uint64, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "rewrap_keys").Add(1)
+		mm.latency.With("method", "rewrap_keys").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.RewrapKeys(ctx)
+}
+
 func (mm *metricsMiddleware) List(ctx context.Context, token string, filter bootstrap.Filter, offset, limit uint64) (saved bootstrap.ConfigsPage, err error) {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "list").Add(1)
@@ -93,13 +219,22 @@ func (mm *metricsMiddleware) Remove(ctx context.Context, token, id string) (err
 	return mm.svc.Remove(ctx, token, id)
 }
 
-func (mm *metricsMiddleware) Bootstrap(ctx context.Context, externalKey, externalID string, secure bool) (cfg bootstrap.Config, err error) {
+func (mm *metricsMiddleware) Bootstrap(ctx context.Context, externalKey, externalID, ip string, secure bool) (cfg bootstrap.Config, err error) {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "bootstrap").Add(1)
 		mm.latency.With("method", "bootstrap").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return mm.svc.Bootstrap(ctx, externalKey, externalID, secure)
+	return mm.svc.Bootstrap(ctx, externalKey, externalID, ip, secure)
+}
+
+func (mm *metricsMiddleware) ListAttempts(ctx context.Context, token, id string, offset, limit uint64) (page bootstrap.AttemptsPage, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "list_attempts").Add(1)
+		mm.latency.With("method", "list_attempts").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.ListAttempts(ctx, token, id, offset, limit)
 }
 
 func (mm *metricsMiddleware) ChangeState(ctx context.Context, token, id string, state bootstrap.State) (err error) {
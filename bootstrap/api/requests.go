@@ -5,6 +5,8 @@ package api
 
 import "github.com/mainflux/mainflux/bootstrap"
 
+const maxImportSize = 10000
+
 type apiReq interface {
 	validate() error
 }
@@ -20,6 +22,13 @@ type addReq struct {
 	ClientCert  string   `json:"client_cert"`
 	ClientKey   string   `json:"client_key"`
 	CACert      string   `json:"ca_cert"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// state returns the initial Config state, falling back to the provided
+// default if the request doesn't specify it.
+func (req addReq) state(active bool) bootstrap.State {
+	return initialState(req.Active, active)
 }
 
 func (req addReq) validate() error {
@@ -34,6 +43,151 @@ func (req addReq) validate() error {
 	return nil
 }
 
+type importReq struct {
+	token   string
+	configs []addReq
+}
+
+func (req importReq) validate() error {
+	if req.token == "" {
+		return bootstrap.ErrUnauthorizedAccess
+	}
+
+	if len(req.configs) == 0 || len(req.configs) > maxImportSize {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	for _, cfg := range req.configs {
+		if cfg.ExternalID == "" || cfg.ExternalKey == "" {
+			return bootstrap.ErrMalformedEntity
+		}
+	}
+
+	return nil
+}
+
+type addTemplateReq struct {
+	token    string
+	Name     string   `json:"name"`
+	Channels []string `json:"channels"`
+	Content  string   `json:"content"`
+}
+
+func (req addTemplateReq) validate() error {
+	if req.token == "" {
+		return bootstrap.ErrUnauthorizedAccess
+	}
+
+	if req.Name == "" {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type updateTemplateReq struct {
+	key      string
+	id       string
+	Name     string   `json:"name"`
+	Channels []string `json:"channels"`
+	Content  string   `json:"content"`
+}
+
+func (req updateTemplateReq) validate() error {
+	if req.key == "" {
+		return bootstrap.ErrUnauthorizedAccess
+	}
+
+	if req.id == "" || req.Name == "" {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type listTemplatesReq struct {
+	key    string
+	offset uint64
+	limit  uint64
+}
+
+func (req listTemplatesReq) validate() error {
+	if req.key == "" {
+		return bootstrap.ErrUnauthorizedAccess
+	}
+
+	if req.limit == 0 || req.limit > maxLimit {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type instantiateReq struct {
+	token       string
+	id          string
+	ThingID     string            `json:"thing_id"`
+	ExternalID  string            `json:"external_id"`
+	ExternalKey string            `json:"external_key"`
+	Name        string            `json:"name"`
+	ClientCert  string            `json:"client_cert"`
+	ClientKey   string            `json:"client_key"`
+	CACert      string            `json:"ca_cert"`
+	Active      *bool             `json:"active,omitempty"`
+	Vars        map[string]string `json:"vars"`
+}
+
+func (req instantiateReq) validate() error {
+	if req.token == "" {
+		return bootstrap.ErrUnauthorizedAccess
+	}
+
+	if req.id == "" || req.ExternalID == "" || req.ExternalKey == "" {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type addPatternReq struct {
+	token       string
+	Type        string `json:"type"`
+	Expression  string `json:"expression"`
+	ExternalKey string `json:"external_key"`
+	TemplateID  string `json:"template_id"`
+	Active      *bool  `json:"active,omitempty"`
+}
+
+func (req addPatternReq) validate() error {
+	if req.token == "" {
+		return bootstrap.ErrUnauthorizedAccess
+	}
+
+	if req.Expression == "" || req.ExternalKey == "" || req.TemplateID == "" {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type listPatternsReq struct {
+	key    string
+	offset uint64
+	limit  uint64
+}
+
+func (req listPatternsReq) validate() error {
+	if req.key == "" {
+		return bootstrap.ErrUnauthorizedAccess
+	}
+
+	if req.limit == 0 || req.limit > maxLimit {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	return nil
+}
+
 type entityReq struct {
 	key string
 	id  string
@@ -127,9 +281,74 @@ func (req listReq) validate() error {
 	return nil
 }
 
+type listVersionsReq struct {
+	key    string
+	id     string
+	offset uint64
+	limit  uint64
+}
+
+func (req listVersionsReq) validate() error {
+	if req.key == "" {
+		return bootstrap.ErrUnauthorizedAccess
+	}
+
+	if req.id == "" {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	if req.limit == 0 || req.limit > maxLimit {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type rollbackReq struct {
+	key     string
+	id      string
+	Version uint64 `json:"version"`
+}
+
+func (req rollbackReq) validate() error {
+	if req.key == "" {
+		return bootstrap.ErrUnauthorizedAccess
+	}
+
+	if req.id == "" || req.Version == 0 {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type listAttemptsReq struct {
+	key    string
+	id     string
+	offset uint64
+	limit  uint64
+}
+
+func (req listAttemptsReq) validate() error {
+	if req.key == "" {
+		return bootstrap.ErrUnauthorizedAccess
+	}
+
+	if req.id == "" {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	if req.limit == 0 || req.limit > maxLimit {
+		return bootstrap.ErrMalformedEntity
+	}
+
+	return nil
+}
+
 type bootstrapReq struct {
 	key string
 	id  string
+	ip  string
 }
 
 func (req bootstrapReq) validate() error {
@@ -166,3 +385,15 @@ func (req changeStateReq) validate() error {
 
 	return nil
 }
+
+// initialState returns the initial Config state, which is the requested one
+// if set, and the default one otherwise.
+func initialState(requested *bool, active bool) bootstrap.State {
+	if requested != nil {
+		active = *requested
+	}
+	if active {
+		return bootstrap.Active
+	}
+	return bootstrap.Inactive
+}
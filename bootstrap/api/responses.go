@@ -6,6 +6,7 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/bootstrap"
@@ -14,9 +15,18 @@ import (
 var (
 	_ mainflux.Response = (*removeRes)(nil)
 	_ mainflux.Response = (*configRes)(nil)
+	_ mainflux.Response = (*importRes)(nil)
 	_ mainflux.Response = (*stateRes)(nil)
 	_ mainflux.Response = (*viewRes)(nil)
 	_ mainflux.Response = (*listRes)(nil)
+	_ mainflux.Response = (*listVersionsRes)(nil)
+	_ mainflux.Response = (*listAttemptsRes)(nil)
+	_ mainflux.Response = (*templateRes)(nil)
+	_ mainflux.Response = (*viewTemplateRes)(nil)
+	_ mainflux.Response = (*listTemplatesRes)(nil)
+	_ mainflux.Response = (*patternRes)(nil)
+	_ mainflux.Response = (*viewPatternRes)(nil)
+	_ mainflux.Response = (*listPatternsRes)(nil)
 )
 
 type removeRes struct{}
@@ -60,6 +70,35 @@ func (res configRes) Empty() bool {
 	return true
 }
 
+type importItemRes struct {
+	MFThing    string       `json:"mainflux_id,omitempty"`
+	MFKey      string       `json:"mainflux_key,omitempty"`
+	Channels   []channelRes `json:"mainflux_channels,omitempty"`
+	ExternalID string       `json:"external_id"`
+	Error      string       `json:"error,omitempty"`
+}
+
+type importRes struct {
+	created bool
+	Configs []importItemRes `json:"configs"`
+}
+
+func (res importRes) Code() int {
+	if res.created {
+		return http.StatusCreated
+	}
+
+	return http.StatusUnprocessableEntity
+}
+
+func (res importRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res importRes) Empty() bool {
+	return false
+}
+
 type channelRes struct {
 	ID       string      `json:"id"`
 	Name     string      `json:"name,omitempty"`
@@ -108,6 +147,183 @@ func (res listRes) Empty() bool {
 	return false
 }
 
+type versionRes struct {
+	Version    uint64    `json:"version"`
+	Name       string    `json:"name,omitempty"`
+	Content    string    `json:"content,omitempty"`
+	ClientCert string    `json:"client_cert,omitempty"`
+	CACert     string    `json:"ca_cert,omitempty"`
+	Channels   []string  `json:"mainflux_channels"`
+	Created    time.Time `json:"created"`
+}
+
+type listVersionsRes struct {
+	Total    uint64       `json:"total"`
+	Offset   uint64       `json:"offset"`
+	Limit    uint64       `json:"limit"`
+	Versions []versionRes `json:"versions"`
+}
+
+func (res listVersionsRes) Code() int {
+	return http.StatusOK
+}
+
+func (res listVersionsRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res listVersionsRes) Empty() bool {
+	return false
+}
+
+type attemptRes struct {
+	IP        string    `json:"ip,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Result    string    `json:"result"`
+}
+
+type listAttemptsRes struct {
+	Total    uint64       `json:"total"`
+	Offset   uint64       `json:"offset"`
+	Limit    uint64       `json:"limit"`
+	Attempts []attemptRes `json:"attempts"`
+}
+
+func (res listAttemptsRes) Code() int {
+	return http.StatusOK
+}
+
+func (res listAttemptsRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res listAttemptsRes) Empty() bool {
+	return false
+}
+
+type templateRes struct {
+	id      string
+	created bool
+}
+
+func (res templateRes) Code() int {
+	if res.created {
+		return http.StatusCreated
+	}
+
+	return http.StatusOK
+}
+
+func (res templateRes) Headers() map[string]string {
+	if res.created {
+		return map[string]string{
+			"Location": fmt.Sprintf("/things/templates/%s", res.id),
+		}
+	}
+
+	return map[string]string{}
+}
+
+func (res templateRes) Empty() bool {
+	return true
+}
+
+type viewTemplateRes struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Channels []string `json:"channels"`
+	Content  string   `json:"content,omitempty"`
+}
+
+func (res viewTemplateRes) Code() int {
+	return http.StatusOK
+}
+
+func (res viewTemplateRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res viewTemplateRes) Empty() bool {
+	return false
+}
+
+type listTemplatesRes struct {
+	Total     uint64            `json:"total"`
+	Offset    uint64            `json:"offset"`
+	Limit     uint64            `json:"limit"`
+	Templates []viewTemplateRes `json:"templates"`
+}
+
+func (res listTemplatesRes) Code() int {
+	return http.StatusOK
+}
+
+func (res listTemplatesRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res listTemplatesRes) Empty() bool {
+	return false
+}
+
+type patternRes struct {
+	id string
+}
+
+func (res patternRes) Code() int {
+	return http.StatusCreated
+}
+
+func (res patternRes) Headers() map[string]string {
+	return map[string]string{
+		"Location": fmt.Sprintf("/things/patterns/%s", res.id),
+	}
+}
+
+func (res patternRes) Empty() bool {
+	return true
+}
+
+type viewPatternRes struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Expression  string `json:"expression"`
+	ExternalKey string `json:"external_key"`
+	TemplateID  string `json:"template_id"`
+	Active      bool   `json:"active"`
+}
+
+func (res viewPatternRes) Code() int {
+	return http.StatusOK
+}
+
+func (res viewPatternRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res viewPatternRes) Empty() bool {
+	return false
+}
+
+type listPatternsRes struct {
+	Total    uint64           `json:"total"`
+	Offset   uint64           `json:"offset"`
+	Limit    uint64           `json:"limit"`
+	Patterns []viewPatternRes `json:"patterns"`
+}
+
+func (res listPatternsRes) Code() int {
+	return http.StatusOK
+}
+
+func (res listPatternsRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res listPatternsRes) Empty() bool {
+	return false
+}
+
 type stateRes struct{}
 
 func (res stateRes) Code() int {
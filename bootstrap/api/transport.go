@@ -5,8 +5,10 @@ package api
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -21,31 +23,100 @@ import (
 )
 
 const (
-	contentType  = "application/json"
-	maxLimit     = 100
-	defaultLimit = 10
+	contentType    = "application/json"
+	csvContentType = "text/csv"
+	maxLimit       = 100
+	defaultLimit   = 10
 )
 
 var (
 	errInvalidLimitParam  = errors.New("invalid limit query param")
 	errInvalidOffsetParam = errors.New("invalid offset query param")
+	errMalformedCSV       = errors.New("malformed CSV")
 	fullMatch             = []string{"state", "external_id", "mainflux_id", "mainflux_key"}
 	partialMatch          = []string{"name"}
 )
 
-// MakeHandler returns a HTTP handler for API endpoints.
-func MakeHandler(svc bootstrap.Service, reader bootstrap.ConfigReader) http.Handler {
+// MakeHandler returns a HTTP handler for API endpoints. The active flag is
+// the default state of the created Configs, used when the request omits it.
+func MakeHandler(svc bootstrap.Service, reader bootstrap.ConfigReader, active bool) http.Handler {
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(encodeError),
 	}
 	r := bone.New()
 
 	r.Post("/things/configs", kithttp.NewServer(
-		addEndpoint(svc),
+		addEndpoint(svc, active),
 		decodeAddRequest,
 		encodeResponse,
 		opts...))
 
+	r.Post("/things/configs/bulk", kithttp.NewServer(
+		importEndpoint(svc, active),
+		decodeImportRequest,
+		encodeResponse,
+		opts...))
+
+	r.Post("/things/templates", kithttp.NewServer(
+		addTemplateEndpoint(svc),
+		decodeAddTemplateRequest,
+		encodeResponse,
+		opts...))
+
+	r.Get("/things/templates", kithttp.NewServer(
+		listTemplatesEndpoint(svc),
+		decodeListTemplatesRequest,
+		encodeResponse,
+		opts...))
+
+	r.Get("/things/templates/:id", kithttp.NewServer(
+		viewTemplateEndpoint(svc),
+		decodeEntityRequest,
+		encodeResponse,
+		opts...))
+
+	r.Put("/things/templates/:id", kithttp.NewServer(
+		updateTemplateEndpoint(svc),
+		decodeUpdateTemplateRequest,
+		encodeResponse,
+		opts...))
+
+	r.Delete("/things/templates/:id", kithttp.NewServer(
+		removeTemplateEndpoint(svc),
+		decodeEntityRequest,
+		encodeResponse,
+		opts...))
+
+	r.Post("/things/templates/:id/configs", kithttp.NewServer(
+		instantiateEndpoint(svc, active),
+		decodeInstantiateRequest,
+		encodeResponse,
+		opts...))
+
+	r.Post("/things/patterns", kithttp.NewServer(
+		addPatternEndpoint(svc, active),
+		decodeAddPatternRequest,
+		encodeResponse,
+		opts...))
+
+	r.Get("/things/patterns", kithttp.NewServer(
+		listPatternsEndpoint(svc),
+		decodeListPatternsRequest,
+		encodeResponse,
+		opts...))
+
+	r.Get("/things/patterns/:id", kithttp.NewServer(
+		viewPatternEndpoint(svc),
+		decodeEntityRequest,
+		encodeResponse,
+		opts...))
+
+	r.Delete("/things/patterns/:id", kithttp.NewServer(
+		removePatternEndpoint(svc),
+		decodeEntityRequest,
+		encodeResponse,
+		opts...))
+
 	r.Get("/things/configs/:id", kithttp.NewServer(
 		viewEndpoint(svc),
 		decodeEntityRequest,
@@ -70,6 +141,24 @@ func MakeHandler(svc bootstrap.Service, reader bootstrap.ConfigReader) http.Hand
 		encodeResponse,
 		opts...))
 
+	r.Get("/things/configs/versions/:id", kithttp.NewServer(
+		listVersionsEndpoint(svc),
+		decodeListVersionsRequest,
+		encodeResponse,
+		opts...))
+
+	r.Get("/things/configs/attempts/:id", kithttp.NewServer(
+		listAttemptsEndpoint(svc),
+		decodeListAttemptsRequest,
+		encodeResponse,
+		opts...))
+
+	r.Put("/things/configs/rollback/:id", kithttp.NewServer(
+		rollbackEndpoint(svc),
+		decodeRollbackRequest,
+		encodeResponse,
+		opts...))
+
 	r.Get("/things/configs", kithttp.NewServer(
 		listEndpoint(svc),
 		decodeListRequest,
@@ -119,6 +208,88 @@ func decodeAddRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	return req, nil
 }
 
+func decodeImportRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := importReq{token: r.Header.Get("Authorization")}
+
+	switch ct := r.Header.Get("Content-Type"); {
+	case strings.Contains(ct, contentType):
+		if err := json.NewDecoder(r.Body).Decode(&req.configs); err != nil {
+			return nil, errors.Wrap(bootstrap.ErrMalformedEntity, err)
+		}
+	case strings.Contains(ct, csvContentType):
+		configs, err := decodeCSVConfigs(r.Body)
+		if err != nil {
+			return nil, errors.Wrap(bootstrap.ErrMalformedEntity, err)
+		}
+		req.configs = configs
+	default:
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	return req, nil
+}
+
+// decodeCSVConfigs decodes the CSV with the header row naming the columns,
+// which are the fields of the add request. Channel IDs are separated by
+// whitespace.
+func decodeCSVConfigs(body io.Reader) ([]addReq, error) {
+	reader := csv.NewReader(body)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(errMalformedCSV, err)
+	}
+
+	var configs []addReq
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(errMalformedCSV, err)
+		}
+
+		var cfg addReq
+		for i, col := range header {
+			val := record[i]
+			switch strings.TrimSpace(col) {
+			case "thing_id":
+				cfg.ThingID = val
+			case "external_id":
+				cfg.ExternalID = val
+			case "external_key":
+				cfg.ExternalKey = val
+			case "channels":
+				cfg.Channels = strings.Fields(val)
+			case "name":
+				cfg.Name = val
+			case "content":
+				cfg.Content = val
+			case "client_cert":
+				cfg.ClientCert = val
+			case "client_key":
+				cfg.ClientKey = val
+			case "ca_cert":
+				cfg.CACert = val
+			case "active":
+				if val == "" {
+					continue
+				}
+				active, err := strconv.ParseBool(val)
+				if err != nil {
+					return nil, errors.Wrap(errMalformedCSV, err)
+				}
+				cfg.Active = &active
+			default:
+				return nil, errMalformedCSV
+			}
+		}
+		configs = append(configs, cfg)
+	}
+
+	return configs, nil
+}
+
 func decodeUpdateRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
 		return nil, errors.ErrUnsupportedContentType
@@ -187,15 +358,87 @@ func decodeListRequest(_ context.Context, r *http.Request) (interface{}, error)
 	return req, nil
 }
 
+func decodeListVersionsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		return nil, errors.ErrInvalidQueryParams
+	}
+
+	offset, limit, err := parsePagePrams(q)
+	if err != nil {
+		return nil, err
+	}
+
+	req := listVersionsReq{
+		key:    r.Header.Get("Authorization"),
+		id:     bone.GetValue(r, "id"),
+		offset: offset,
+		limit:  limit,
+	}
+
+	return req, nil
+}
+
+func decodeListAttemptsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		return nil, errors.ErrInvalidQueryParams
+	}
+
+	offset, limit, err := parsePagePrams(q)
+	if err != nil {
+		return nil, err
+	}
+
+	req := listAttemptsReq{
+		key:    r.Header.Get("Authorization"),
+		id:     bone.GetValue(r, "id"),
+		offset: offset,
+		limit:  limit,
+	}
+
+	return req, nil
+}
+
+func decodeRollbackRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := rollbackReq{key: r.Header.Get("Authorization")}
+	req.id = bone.GetValue(r, "id")
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(bootstrap.ErrMalformedEntity, err)
+	}
+
+	return req, nil
+}
+
 func decodeBootstrapRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	req := bootstrapReq{
 		id:  bone.GetValue(r, "external_id"),
 		key: r.Header.Get("Authorization"),
+		ip:  remoteIP(r),
 	}
 
 	return req, nil
 }
 
+// remoteIP returns the address the request came from. Requests forwarded by
+// the reverse proxy carry the original address in the X-Real-IP header.
+func remoteIP(r *http.Request) string {
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
 func decodeStateRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
 		return nil, errors.ErrUnsupportedContentType
@@ -210,6 +453,104 @@ func decodeStateRequest(_ context.Context, r *http.Request) (interface{}, error)
 	return req, nil
 }
 
+func decodeAddTemplateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := addTemplateReq{token: r.Header.Get("Authorization")}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(bootstrap.ErrMalformedEntity, err)
+	}
+
+	return req, nil
+}
+
+func decodeListTemplatesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		return nil, errors.ErrInvalidQueryParams
+	}
+
+	offset, limit, err := parsePagePrams(q)
+	if err != nil {
+		return nil, err
+	}
+
+	req := listTemplatesReq{
+		key:    r.Header.Get("Authorization"),
+		offset: offset,
+		limit:  limit,
+	}
+
+	return req, nil
+}
+
+func decodeUpdateTemplateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := updateTemplateReq{
+		key: r.Header.Get("Authorization"),
+		id:  bone.GetValue(r, "id"),
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(bootstrap.ErrMalformedEntity, err)
+	}
+
+	return req, nil
+}
+
+func decodeInstantiateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := instantiateReq{
+		token: r.Header.Get("Authorization"),
+		id:    bone.GetValue(r, "id"),
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(bootstrap.ErrMalformedEntity, err)
+	}
+
+	return req, nil
+}
+
+func decodeAddPatternRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := addPatternReq{token: r.Header.Get("Authorization")}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(bootstrap.ErrMalformedEntity, err)
+	}
+
+	return req, nil
+}
+
+func decodeListPatternsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		return nil, errors.ErrInvalidQueryParams
+	}
+
+	offset, limit, err := parsePagePrams(q)
+	if err != nil {
+		return nil, err
+	}
+
+	req := listPatternsReq{
+		key:    r.Header.Get("Authorization"),
+		offset: offset,
+		limit:  limit,
+	}
+
+	return req, nil
+}
+
 func decodeEntityRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	req := entityReq{
 		key: r.Header.Get("Authorization"),
@@ -3,11 +3,16 @@
 
 package bootstrap
 
+import "time"
+
 // Config represents Configuration entity. It wraps information about external entity
 // as well as info about corresponding Mainflux entities.
 // MFThing represents corresponding Mainflux Thing ID.
 // MFKey is key of corresponding Mainflux Thing.
 // MFChannels is a list of Mainflux Channels corresponding Mainflux Thing connects to.
+// EncKey is the key used for the secure bootstrap of the Thing, wrapped by
+// the master key of KeyVersion. Zero KeyVersion indicates that EncKey isn't
+// wrapped, and empty EncKey that the default key is used.
 type Config struct {
 	MFThing     string
 	Owner       string
@@ -21,6 +26,8 @@ type Config struct {
 	ExternalKey string
 	Content     string
 	State       State
+	EncKey      []byte
+	KeyVersion  uint64
 }
 
 // Channel represents Mainflux channel corresponding Mainflux Thing is connected to.
@@ -45,12 +52,82 @@ type ConfigsPage struct {
 	Configs []Config
 }
 
+// ConfigVersion represents the state of the editable fields of the Config
+// after one of its changes. Versions are numbered consecutively, starting
+// from 1 for the Config creation. Channels contains the IDs of the Channels
+// the Config is connected to.
+type ConfigVersion struct {
+	Version    uint64
+	Name       string
+	Content    string
+	ClientCert string
+	ClientKey  string
+	CACert     string
+	Channels   []string
+	Created    time.Time
+}
+
+// VersionsPage contains page related metadata as well as list of Config
+// versions that belong to this page, the most recent version first.
+type VersionsPage struct {
+	Total    uint64
+	Offset   uint64
+	Limit    uint64
+	Versions []ConfigVersion
+}
+
+// AttemptResult represents the outcome of the bootstrap attempt.
+type AttemptResult string
+
+const (
+	// AttemptSuccess indicates the Config is retrieved.
+	AttemptSuccess AttemptResult = "success"
+	// AttemptNotFound indicates there is no Config with the external ID.
+	AttemptNotFound AttemptResult = "not_found"
+	// AttemptInvalidKey indicates the external key doesn't match.
+	AttemptInvalidKey AttemptResult = "invalid_key"
+	// AttemptError indicates the Config couldn't be retrieved.
+	AttemptError AttemptResult = "error"
+)
+
+// Attempt represents a single attempt to retrieve the Config having the
+// external ID. IP is the address the attempt came from.
+type Attempt struct {
+	ExternalID string
+	IP         string
+	Timestamp  time.Time
+	Result     AttemptResult
+}
+
+// AttemptsPage contains page related metadata as well as list of bootstrap
+// attempts that belong to this page, the most recent attempt first.
+type AttemptsPage struct {
+	Total    uint64
+	Offset   uint64
+	Limit    uint64
+	Attempts []Attempt
+}
+
+// ImportResult represents the outcome of the import of a single Config.
+// Err is nil if the Config is imported.
+type ImportResult struct {
+	Config Config
+	Err    error
+}
+
 // ConfigRepository specifies a Config persistence API.
 type ConfigRepository interface {
 	// Save persists the Config. Successful operation is indicated by non-nil
-	// error response.
+	// error response. Each operation changing the Config, including Save,
+	// records the resulting state of the Config as its new version.
 	Save(cfg Config, chsConnIDs []string) (string, error)
 
+	// SaveAll persists the Configs in a single transaction, connecting each
+	// Config to the Channels listed at the same position of chsConnIDs. If
+	// any Config can't be saved, none is saved and the error is returned at
+	// the position of that Config. Nil list indicates successful operation.
+	SaveAll(cfgs []Config, chsConnIDs [][]string) []error
+
 	// RetrieveByID retrieves the Config having the provided identifier, that is owned
 	// by the specified user.
 	RetrieveByID(owner, id string) (Config, error)
@@ -81,9 +158,40 @@ type ConfigRepository interface {
 	// ChangeState changes of the Config, that is owned by the specific user.
 	ChangeState(owner, id string, state State) error
 
+	// RetrieveVersions retrieves a subset of the versions of the Config
+	// having the provided identifier, that is owned by the specified user.
+	RetrieveVersions(owner, id string, offset, limit uint64) (VersionsPage, error)
+
+	// RetrieveVersion retrieves the given version of the Config having the
+	// provided identifier, that is owned by the specified user.
+	RetrieveVersion(owner, id string, version uint64) (ConfigVersion, error)
+
+	// Restore sets the editable fields and the connections of the Config to
+	// the ones of the provided version, adding new Channels if needed. The
+	// restored state is recorded as the new version of the Config.
+	Restore(owner, id string, version ConfigVersion, channels []Channel) error
+
+	// SaveAttempt records the bootstrap attempt.
+	SaveAttempt(attempt Attempt) error
+
+	// RetrieveAttempts retrieves a subset of the bootstrap attempts using
+	// the provided external ID.
+	RetrieveAttempts(externalID string, offset, limit uint64) (AttemptsPage, error)
+
 	// ListExisting retrieves those channels from the given list that exist in DB.
 	ListExisting(owner string, ids []string) ([]Channel, error)
 
+	// RetrieveOutdatedKeys retrieves at most limit Configs, having the
+	// identifier greater than the provided one, whose keys aren't wrapped by
+	// the master key of the given version. Configs are ordered by identifier.
+	// The method surpasses ownership check, as it's used for key rotation.
+	RetrieveOutdatedKeys(version uint64, after string, limit uint64) ([]Config, error)
+
+	// UpdateKey updates the wrapped key of the Config having the provided
+	// identifier, along with the version of the master key wrapping it.
+	// The method surpasses ownership check, as it's used for key rotation.
+	UpdateKey(id string, key []byte, version uint64) error
+
 	// Methods RemoveThing, UpdateChannel, and RemoveChannel are related to
 	// event sourcing. That's why these methods surpass ownership check.
 
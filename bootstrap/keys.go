@@ -0,0 +1,116 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package bootstrap
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"strconv"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var (
+	// ErrKeyVersion indicates the use of the master key version missing
+	// from the keyring.
+	ErrKeyVersion = errors.New("unknown master key version")
+
+	errWrapKey   = errors.New("failed to wrap key")
+	errUnwrapKey = errors.New("failed to unwrap key")
+)
+
+// Keyring holds the versioned master keys used for the envelope encryption
+// of the Config keys. The current master key wraps the keys, while the
+// others are kept to unwrap the keys wrapped before the rotation. Version 0
+// is reserved for the keys that aren't wrapped.
+type Keyring struct {
+	current uint64
+	keys    map[uint64][]byte
+}
+
+// NewKeyring returns the keyring holding the given master keys, the one with
+// the highest version being the current one. Keys have to be valid AES keys.
+func NewKeyring(keys map[uint64][]byte) (Keyring, error) {
+	kr := Keyring{keys: map[uint64][]byte{}}
+	for v, k := range keys {
+		if v == 0 {
+			return Keyring{}, ErrKeyVersion
+		}
+		if _, err := aes.NewCipher(k); err != nil {
+			return Keyring{}, errors.Wrap(ErrMalformedEntity, err)
+		}
+		kr.keys[v] = k
+		if v > kr.current {
+			kr.current = v
+		}
+	}
+
+	if kr.current == 0 {
+		return Keyring{}, ErrKeyVersion
+	}
+
+	return kr, nil
+}
+
+// Current returns the version of the current master key.
+func (kr Keyring) Current() uint64 {
+	return kr.current
+}
+
+// Wrap encrypts the key using the current master key.
+func (kr Keyring) Wrap(key []byte) ([]byte, error) {
+	gcm, err := kr.cipher(kr.current)
+	if err != nil {
+		return nil, errors.Wrap(errWrapKey, err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(errWrapKey, err)
+	}
+
+	return gcm.Seal(nonce, nonce, key, version(kr.current)), nil
+}
+
+// Unwrap decrypts the key wrapped using the master key of the given version.
+func (kr Keyring) Unwrap(v uint64, wrapped []byte) ([]byte, error) {
+	gcm, err := kr.cipher(v)
+	if err != nil {
+		return nil, errors.Wrap(errUnwrapKey, err)
+	}
+
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, errors.Wrap(errUnwrapKey, ErrMalformedEntity)
+	}
+
+	nonce, ciphertext := wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():]
+	key, err := gcm.Open(nil, nonce, ciphertext, version(v))
+	if err != nil {
+		return nil, errors.Wrap(errUnwrapKey, err)
+	}
+
+	return key, nil
+}
+
+func (kr Keyring) cipher(v uint64) (cipher.AEAD, error) {
+	key, ok := kr.keys[v]
+	if !ok {
+		return nil, ErrKeyVersion
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// version returns the additional data binding the wrapped key to the
+// version of the master key.
+func version(v uint64) []byte {
+	return []byte(strconv.FormatUint(v, 10))
+}
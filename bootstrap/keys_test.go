@@ -0,0 +1,105 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package bootstrap_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/bootstrap"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKeyring(t *testing.T) {
+	cases := []struct {
+		desc    string
+		keys    map[uint64][]byte
+		current uint64
+		err     error
+	}{
+		{
+			desc:    "create keyring",
+			keys:    map[uint64][]byte{1: masterKey, 3: masterKey},
+			current: 3,
+			err:     nil,
+		},
+		{
+			desc: "create keyring without keys",
+			keys: map[uint64][]byte{},
+			err:  bootstrap.ErrKeyVersion,
+		},
+		{
+			desc: "create keyring with reserved version",
+			keys: map[uint64][]byte{0: masterKey},
+			err:  bootstrap.ErrKeyVersion,
+		},
+		{
+			desc: "create keyring with invalid key",
+			keys: map[uint64][]byte{1: []byte("invalid")},
+			err:  bootstrap.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		kr, err := bootstrap.NewKeyring(tc.keys)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.current, kr.Current(), fmt.Sprintf("%s: expected current version %d got %d\n", tc.desc, tc.current, kr.Current()))
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	old, err := bootstrap.NewKeyring(map[uint64][]byte{1: masterKey})
+	require.Nil(t, err, fmt.Sprintf("Creating keyring expected to succeed: %s.\n", err))
+
+	wrapped, err := old.Wrap(encKey)
+	require.Nil(t, err, fmt.Sprintf("Wrapping key expected to succeed: %s.\n", err))
+
+	kr, err := bootstrap.NewKeyring(map[uint64][]byte{1: masterKey, 2: []byte("22345678910111213141516171819202")})
+	require.Nil(t, err, fmt.Sprintf("Creating keyring expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc    string
+		version uint64
+		wrapped []byte
+		key     []byte
+		err     error
+	}{
+		{
+			desc:    "unwrap key wrapped by an outdated master key",
+			version: 1,
+			wrapped: wrapped,
+			key:     encKey,
+			err:     nil,
+		},
+		{
+			desc:    "unwrap key with wrong master key version",
+			version: 2,
+			wrapped: wrapped,
+			key:     nil,
+			err:     errors.New("cipher: message authentication failed"),
+		},
+		{
+			desc:    "unwrap key with unknown master key version",
+			version: 3,
+			wrapped: wrapped,
+			key:     nil,
+			err:     bootstrap.ErrKeyVersion,
+		},
+		{
+			desc:    "unwrap malformed key",
+			version: 1,
+			wrapped: []byte("key"),
+			key:     nil,
+			err:     bootstrap.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		key, err := kr.Unwrap(tc.version, tc.wrapped)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.key, key, fmt.Sprintf("%s: expected key %v got %v\n", tc.desc, tc.key, key))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/mainflux/mainflux/bootstrap"
 )
@@ -24,6 +25,8 @@ type configRepositoryMock struct {
 	counter  uint64
 	configs  map[string]bootstrap.Config
 	channels map[string]bootstrap.Channel
+	versions map[string][]bootstrap.ConfigVersion
+	attempts map[string][]bootstrap.Attempt
 }
 
 // NewConfigsRepository creates in-memory config repository.
@@ -31,6 +34,8 @@ func NewConfigsRepository() bootstrap.ConfigRepository {
 	return &configRepositoryMock{
 		configs:  make(map[string]bootstrap.Config),
 		channels: make(map[string]bootstrap.Channel),
+		versions: make(map[string][]bootstrap.ConfigVersion),
+		attempts: make(map[string][]bootstrap.Attempt),
 	}
 }
 
@@ -59,10 +64,48 @@ func (crm *configRepositoryMock) Save(config bootstrap.Config, connections []str
 	}
 
 	crm.configs[config.MFThing] = config
+	crm.saveVersion(config)
 
 	return config.MFThing, nil
 }
 
+func (crm *configRepositoryMock) SaveAll(configs []bootstrap.Config, connections [][]string) []error {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	errs := make([]error, len(configs))
+	for i, config := range configs {
+		for _, v := range crm.configs {
+			if v.MFThing == config.MFThing || v.ExternalID == config.ExternalID {
+				errs[i] = bootstrap.ErrConflict
+				return errs
+			}
+		}
+		for _, v := range configs[:i] {
+			if v.MFThing == config.MFThing || v.ExternalID == config.ExternalID {
+				errs[i] = bootstrap.ErrConflict
+				return errs
+			}
+		}
+	}
+
+	for i, config := range configs {
+		for _, ch := range config.MFChannels {
+			crm.channels[ch.ID] = ch
+		}
+
+		config.MFChannels = []bootstrap.Channel{}
+		for _, ch := range connections[i] {
+			config.MFChannels = append(config.MFChannels, crm.channels[ch])
+		}
+
+		crm.configs[config.MFThing] = config
+		crm.saveVersion(config)
+	}
+
+	return nil
+}
+
 func (crm *configRepositoryMock) RetrieveByID(token, id string) (bootstrap.Config, error) {
 	crm.mu.Lock()
 	defer crm.mu.Unlock()
@@ -152,6 +195,7 @@ func (crm *configRepositoryMock) Update(config bootstrap.Config) error {
 	cfg.Name = config.Name
 	cfg.Content = config.Content
 	crm.configs[config.MFThing] = cfg
+	crm.saveVersion(cfg)
 
 	return nil
 }
@@ -173,6 +217,7 @@ func (crm *configRepositoryMock) UpdateCert(owner, thingID, clientCert, clientKe
 	forUpdate.ClientKey = clientKey
 	forUpdate.CACert = caCert
 	crm.configs[forUpdate.MFThing] = forUpdate
+	crm.saveVersion(forUpdate)
 
 	return nil
 }
@@ -199,6 +244,109 @@ func (crm *configRepositoryMock) UpdateConnections(token, id string, channels []
 		config.MFChannels = append(config.MFChannels, ch)
 	}
 	crm.configs[id] = config
+	crm.saveVersion(config)
+
+	return nil
+}
+
+func (crm *configRepositoryMock) RetrieveVersions(owner, id string, offset, limit uint64) (bootstrap.VersionsPage, error) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	page := bootstrap.VersionsPage{
+		Offset:   offset,
+		Limit:    limit,
+		Versions: []bootstrap.ConfigVersion{},
+	}
+
+	config, ok := crm.configs[id]
+	if !ok || config.Owner != owner {
+		return page, nil
+	}
+
+	versions := crm.versions[id]
+	page.Total = uint64(len(versions))
+	for i := len(versions) - 1 - int(offset); i >= 0 && uint64(len(page.Versions)) < limit; i-- {
+		page.Versions = append(page.Versions, versions[i])
+	}
+
+	return page, nil
+}
+
+func (crm *configRepositoryMock) RetrieveVersion(owner, id string, version uint64) (bootstrap.ConfigVersion, error) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	config, ok := crm.configs[id]
+	if !ok || config.Owner != owner {
+		return bootstrap.ConfigVersion{}, bootstrap.ErrNotFound
+	}
+
+	versions := crm.versions[id]
+	if version == 0 || version > uint64(len(versions)) {
+		return bootstrap.ConfigVersion{}, bootstrap.ErrNotFound
+	}
+
+	return versions[version-1], nil
+}
+
+func (crm *configRepositoryMock) SaveAttempt(attempt bootstrap.Attempt) error {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	crm.attempts[attempt.ExternalID] = append(crm.attempts[attempt.ExternalID], attempt)
+
+	return nil
+}
+
+func (crm *configRepositoryMock) RetrieveAttempts(externalID string, offset, limit uint64) (bootstrap.AttemptsPage, error) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	attempts := crm.attempts[externalID]
+	page := bootstrap.AttemptsPage{
+		Total:    uint64(len(attempts)),
+		Offset:   offset,
+		Limit:    limit,
+		Attempts: []bootstrap.Attempt{},
+	}
+
+	for i := len(attempts) - 1 - int(offset); i >= 0 && uint64(len(page.Attempts)) < limit; i-- {
+		page.Attempts = append(page.Attempts, attempts[i])
+	}
+
+	return page, nil
+}
+
+func (crm *configRepositoryMock) Restore(owner, id string, version bootstrap.ConfigVersion, channels []bootstrap.Channel) error {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	config, ok := crm.configs[id]
+	if !ok || config.Owner != owner {
+		return bootstrap.ErrNotFound
+	}
+
+	for _, ch := range channels {
+		crm.channels[ch.ID] = ch
+	}
+
+	config.MFChannels = []bootstrap.Channel{}
+	for _, conn := range version.Channels {
+		ch, ok := crm.channels[conn]
+		if !ok {
+			return bootstrap.ErrNotFound
+		}
+		config.MFChannels = append(config.MFChannels, ch)
+	}
+
+	config.Name = version.Name
+	config.Content = version.Content
+	config.ClientCert = version.ClientCert
+	config.ClientKey = version.ClientKey
+	config.CACert = version.CACert
+	crm.configs[id] = config
+	crm.saveVersion(config)
 
 	return nil
 }
@@ -210,6 +358,7 @@ func (crm *configRepositoryMock) Remove(token, id string) error {
 	for k, v := range crm.configs {
 		if v.Owner == token && k == id {
 			delete(crm.configs, k)
+			delete(crm.versions, k)
 			break
 		}
 	}
@@ -252,11 +401,51 @@ func (crm *configRepositoryMock) ListExisting(token string, connections []string
 	return ret, nil
 }
 
+func (crm *configRepositoryMock) RetrieveOutdatedKeys(version uint64, after string, limit uint64) ([]bootstrap.Config, error) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	var ids []string
+	for id, c := range crm.configs {
+		if c.KeyVersion != version && id > after {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	configs := []bootstrap.Config{}
+	for _, id := range ids {
+		if uint64(len(configs)) == limit {
+			break
+		}
+		configs = append(configs, crm.configs[id])
+	}
+
+	return configs, nil
+}
+
+func (crm *configRepositoryMock) UpdateKey(id string, key []byte, version uint64) error {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	config, ok := crm.configs[id]
+	if !ok {
+		return bootstrap.ErrNotFound
+	}
+
+	config.EncKey = key
+	config.KeyVersion = version
+	crm.configs[id] = config
+
+	return nil
+}
+
 func (crm *configRepositoryMock) RemoveThing(id string) error {
 	crm.mu.Lock()
 	defer crm.mu.Unlock()
 
 	delete(crm.configs, id)
+	delete(crm.versions, id)
 	return nil
 }
 
@@ -306,3 +495,25 @@ func (crm *configRepositoryMock) DisconnectThing(channelID, thingID string) erro
 
 	return nil
 }
+
+// saveVersion records the current state of the Config. The caller has to
+// hold the lock.
+func (crm *configRepositoryMock) saveVersion(config bootstrap.Config) {
+	var channels []string
+	for _, ch := range config.MFChannels {
+		channels = append(channels, ch.ID)
+	}
+	sort.Strings(channels)
+
+	versions := crm.versions[config.MFThing]
+	crm.versions[config.MFThing] = append(versions, bootstrap.ConfigVersion{
+		Version:    uint64(len(versions) + 1),
+		Name:       config.Name,
+		Content:    config.Content,
+		ClientCert: config.ClientCert,
+		ClientKey:  config.ClientKey,
+		CACert:     config.CACert,
+		Channels:   channels,
+		Created:    time.Now(),
+	})
+}
@@ -0,0 +1,148 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/mainflux/mainflux/bootstrap"
+)
+
+var _ bootstrap.PatternRepository = (*patternRepositoryMock)(nil)
+
+type patternRepositoryMock struct {
+	mu       sync.Mutex
+	patterns map[string]bootstrap.Pattern
+}
+
+// NewPatternsRepository creates in-memory pattern repository.
+func NewPatternsRepository() bootstrap.PatternRepository {
+	return &patternRepositoryMock{
+		patterns: make(map[string]bootstrap.Pattern),
+	}
+}
+
+func (prm *patternRepositoryMock) Save(p bootstrap.Pattern) (string, error) {
+	prm.mu.Lock()
+	defer prm.mu.Unlock()
+
+	if _, ok := prm.patterns[p.ID]; ok {
+		return "", bootstrap.ErrConflict
+	}
+
+	prm.patterns[p.ID] = p
+
+	return p.ID, nil
+}
+
+func (prm *patternRepositoryMock) RetrieveByID(owner, id string) (bootstrap.Pattern, error) {
+	prm.mu.Lock()
+	defer prm.mu.Unlock()
+
+	p, ok := prm.patterns[id]
+	if !ok || p.Owner != owner {
+		return bootstrap.Pattern{}, bootstrap.ErrNotFound
+	}
+
+	return p, nil
+}
+
+func (prm *patternRepositoryMock) RetrieveAll(owner string, offset, limit uint64) (bootstrap.PatternsPage, error) {
+	prm.mu.Lock()
+	defer prm.mu.Unlock()
+
+	patterns := []bootstrap.Pattern{}
+	for _, p := range prm.patterns {
+		if p.Owner == owner {
+			patterns = append(patterns, p)
+		}
+	}
+
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].ID < patterns[j].ID
+	})
+
+	total := uint64(len(patterns))
+	page := bootstrap.PatternsPage{
+		Total:    total,
+		Offset:   offset,
+		Limit:    limit,
+		Patterns: []bootstrap.Pattern{},
+	}
+
+	if offset < total {
+		end := offset + limit
+		if end > total {
+			end = total
+		}
+		page.Patterns = patterns[offset:end]
+	}
+
+	return page, nil
+}
+
+func (prm *patternRepositoryMock) RetrieveByExternalID(externalID string) ([]bootstrap.Pattern, error) {
+	prm.mu.Lock()
+	defer prm.mu.Unlock()
+
+	patterns := []bootstrap.Pattern{}
+	for _, p := range prm.patterns {
+		if p.Match(externalID) {
+			patterns = append(patterns, p)
+		}
+	}
+
+	return patterns, nil
+}
+
+func (prm *patternRepositoryMock) RetrieveOutdatedKeys(version uint64, after string, limit uint64) ([]bootstrap.Pattern, error) {
+	prm.mu.Lock()
+	defer prm.mu.Unlock()
+
+	var ids []string
+	for id, p := range prm.patterns {
+		if p.KeyVersion != version && id > after {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	patterns := []bootstrap.Pattern{}
+	for _, id := range ids {
+		if uint64(len(patterns)) == limit {
+			break
+		}
+		patterns = append(patterns, prm.patterns[id])
+	}
+
+	return patterns, nil
+}
+
+func (prm *patternRepositoryMock) UpdateKey(id string, key []byte, version uint64) error {
+	prm.mu.Lock()
+	defer prm.mu.Unlock()
+
+	p, ok := prm.patterns[id]
+	if !ok {
+		return bootstrap.ErrNotFound
+	}
+
+	p.EncKey = key
+	p.KeyVersion = version
+	prm.patterns[id] = p
+
+	return nil
+}
+
+func (prm *patternRepositoryMock) Remove(owner, id string) error {
+	prm.mu.Lock()
+	defer prm.mu.Unlock()
+
+	if p, ok := prm.patterns[id]; ok && p.Owner == owner {
+		delete(prm.patterns, id)
+	}
+
+	return nil
+}
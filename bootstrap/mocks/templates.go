@@ -0,0 +1,112 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/mainflux/mainflux/bootstrap"
+)
+
+var _ bootstrap.TemplateRepository = (*templateRepositoryMock)(nil)
+
+type templateRepositoryMock struct {
+	mu        sync.Mutex
+	templates map[string]bootstrap.Template
+}
+
+// NewTemplatesRepository creates in-memory template repository.
+func NewTemplatesRepository() bootstrap.TemplateRepository {
+	return &templateRepositoryMock{
+		templates: make(map[string]bootstrap.Template),
+	}
+}
+
+func (trm *templateRepositoryMock) Save(t bootstrap.Template) (string, error) {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	if _, ok := trm.templates[t.ID]; ok {
+		return "", bootstrap.ErrConflict
+	}
+
+	trm.templates[t.ID] = t
+
+	return t.ID, nil
+}
+
+func (trm *templateRepositoryMock) RetrieveByID(owner, id string) (bootstrap.Template, error) {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	t, ok := trm.templates[id]
+	if !ok || t.Owner != owner {
+		return bootstrap.Template{}, bootstrap.ErrNotFound
+	}
+
+	return t, nil
+}
+
+func (trm *templateRepositoryMock) RetrieveAll(owner string, offset, limit uint64) (bootstrap.TemplatesPage, error) {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	templates := []bootstrap.Template{}
+	for _, t := range trm.templates {
+		if t.Owner == owner {
+			templates = append(templates, t)
+		}
+	}
+
+	sort.SliceStable(templates, func(i, j int) bool {
+		return templates[i].ID < templates[j].ID
+	})
+
+	total := uint64(len(templates))
+	page := bootstrap.TemplatesPage{
+		Total:     total,
+		Offset:    offset,
+		Limit:     limit,
+		Templates: []bootstrap.Template{},
+	}
+
+	if offset < total {
+		end := offset + limit
+		if end > total {
+			end = total
+		}
+		page.Templates = templates[offset:end]
+	}
+
+	return page, nil
+}
+
+func (trm *templateRepositoryMock) Update(t bootstrap.Template) error {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	saved, ok := trm.templates[t.ID]
+	if !ok || saved.Owner != t.Owner {
+		return bootstrap.ErrNotFound
+	}
+
+	saved.Name = t.Name
+	saved.Channels = t.Channels
+	saved.Content = t.Content
+	trm.templates[t.ID] = saved
+
+	return nil
+}
+
+func (trm *templateRepositoryMock) Remove(owner, id string) error {
+	trm.mu.Lock()
+	defer trm.mu.Unlock()
+
+	if t, ok := trm.templates[id]; ok && t.Owner == owner {
+		delete(trm.templates, id)
+	}
+
+	return nil
+}
@@ -31,10 +31,9 @@ func (svc serviceMock) Identify(ctx context.Context, in *mainflux.Token, opts ..
 }
 
 func (svc serviceMock) Issue(ctx context.Context, in *mainflux.IssueReq, opts ...grpc.CallOption) (*mainflux.Token, error) {
-	if id, ok := svc.users[in.GetEmail()]; ok {
-		switch in.Type {
-		default:
-			return &mainflux.Token{Value: id}, nil
+	for token, email := range svc.users {
+		if email == in.GetEmail() {
+			return &mainflux.Token{Value: token}, nil
 		}
 	}
 	return nil, users.ErrUnauthorizedAccess
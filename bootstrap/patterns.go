@@ -0,0 +1,150 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package bootstrap
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// PrefixPattern matches the external IDs starting with the expression.
+	PrefixPattern PatternType = "prefix"
+	// RegexPattern matches the external IDs matching the regular expression
+	// as a whole.
+	RegexPattern PatternType = "regex"
+)
+
+// PatternType represents the way the Pattern expression is matched.
+type PatternType string
+
+// Pattern represents the bootstrap configuration registered for a batch of
+// Things whose external IDs match the expression, such as the serial numbers
+// of a production run. The Things share the external key, and the Config of
+// each Thing is instantiated from the Template with given ID on its first
+// bootstrap, using the external ID as the Config name. State is the State of
+// the instantiated Configs. EncKey and KeyVersion hold the secure bootstrap
+// key shared by the Things, the same way as they do in the Config.
+type Pattern struct {
+	ID          string
+	Owner       string
+	Type        PatternType
+	Expression  string
+	ExternalKey string
+	TemplateID  string
+	State       State
+	EncKey      []byte
+	KeyVersion  uint64
+}
+
+// Validate returns ErrMalformedEntity if the Pattern type is unknown or the
+// expression can't be matched.
+func (p Pattern) Validate() error {
+	switch p.Type {
+	case PrefixPattern:
+		if p.Expression == "" {
+			return ErrMalformedEntity
+		}
+	case RegexPattern:
+		if _, err := p.regexp(); err != nil {
+			return ErrMalformedEntity
+		}
+	default:
+		return ErrMalformedEntity
+	}
+
+	return nil
+}
+
+// Match returns true if the external ID matches the Pattern.
+func (p Pattern) Match(externalID string) bool {
+	switch p.Type {
+	case PrefixPattern:
+		return strings.HasPrefix(externalID, p.Expression)
+	case RegexPattern:
+		re, err := p.regexp()
+		return err == nil && re.MatchString(externalID)
+	default:
+		return false
+	}
+}
+
+// regexp compiles the expression anchored to match the whole external ID.
+func (p Pattern) regexp() (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + p.Expression + ")$")
+}
+
+// matchPattern returns the most specific of the Patterns the external ID
+// matches. The longest prefix takes precedence over the shorter ones and
+// over the regular expressions, which are tried in the order of their IDs.
+func matchPattern(patterns []Pattern, externalID string) (Pattern, bool) {
+	var match Pattern
+	found := false
+	for _, p := range patterns {
+		if !p.Match(externalID) {
+			continue
+		}
+		if !found || morePrecise(p, match) {
+			match = p
+			found = true
+		}
+	}
+
+	return match, found
+}
+
+func morePrecise(p, other Pattern) bool {
+	if p.Type != other.Type {
+		return p.Type == PrefixPattern
+	}
+	if p.Type == PrefixPattern && len(p.Expression) != len(other.Expression) {
+		return len(p.Expression) > len(other.Expression)
+	}
+
+	return p.ID < other.ID
+}
+
+// PatternsPage contains page related metadata as well as list of Patterns
+// that belong to this page.
+type PatternsPage struct {
+	Total    uint64
+	Offset   uint64
+	Limit    uint64
+	Patterns []Pattern
+}
+
+// PatternRepository specifies a Pattern persistence API.
+type PatternRepository interface {
+	// Save persists the Pattern. Successful operation is indicated by
+	// non-nil error response.
+	Save(p Pattern) (string, error)
+
+	// RetrieveByID retrieves the Pattern having the provided identifier,
+	// that is owned by the specified user.
+	RetrieveByID(owner, id string) (Pattern, error)
+
+	// RetrieveAll retrieves a subset of Patterns that are owned by the
+	// specified user.
+	RetrieveAll(owner string, offset, limit uint64) (PatternsPage, error)
+
+	// RetrieveByExternalID retrieves the Patterns of all the users that may
+	// match the external ID. The caller is responsible for the matching.
+	RetrieveByExternalID(externalID string) ([]Pattern, error)
+
+	// RetrieveOutdatedKeys retrieves at most limit Patterns, having the
+	// identifier greater than the provided one, whose keys aren't wrapped by
+	// the master key of the given version. Patterns are ordered by
+	// identifier. The method surpasses ownership check, as it's used for key
+	// rotation.
+	RetrieveOutdatedKeys(version uint64, after string, limit uint64) ([]Pattern, error)
+
+	// UpdateKey updates the wrapped key of the Pattern having the provided
+	// identifier, along with the version of the master key wrapping it.
+	// The method surpasses ownership check, as it's used for key rotation.
+	UpdateKey(id string, key []byte, version uint64) error
+
+	// Remove removes the Pattern having the provided identifier, that is
+	// owned by the specified user.
+	Remove(owner, id string) error
+}
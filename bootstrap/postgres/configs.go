@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -26,6 +27,14 @@ const (
 	connFieldsNum     = 2
 	cleanupQuery      = `DELETE FROM channels ch WHERE NOT EXISTS (
 						 SELECT channel_id FROM connections c WHERE ch.mainflux_channel = c.channel_id);`
+	versionQuery = `INSERT INTO config_versions (config_id, config_owner, version, name, content, client_cert, client_key, ca_cert, channels, created)
+					SELECT c.mainflux_thing, c.owner,
+					COALESCE((SELECT MAX(version) FROM config_versions v
+					WHERE v.config_id = c.mainflux_thing AND v.config_owner = c.owner), 0) + 1,
+					c.name, c.content, c.client_cert, c.client_key, c.ca_cert,
+					ARRAY(SELECT channel_id FROM connections conn
+					WHERE conn.config_id = c.mainflux_thing AND conn.config_owner = c.owner ORDER BY channel_id), CAST($3 AS TIMESTAMPTZ)
+					FROM configs c WHERE c.mainflux_thing = $1 AND c.owner = $2`
 )
 
 var (
@@ -40,6 +49,11 @@ var (
 	errUpdateChannels   = errors.New("failed to update channels in bootstrap configuration database")
 	errRemoveChannels   = errors.New("failed to remove channels from bootstrap configuration in database")
 	errDisconnectThing  = errors.New("failed to disconnect thing in bootstrap configuration in database")
+	errSaveVersion      = errors.New("failed to save bootstrap configuration version to database")
+	errRetrieveVersions = errors.New("failed to retrieve bootstrap configuration versions from database")
+	errRestore          = errors.New("failed to restore bootstrap configuration version in database")
+	errSaveAttempt      = errors.New("failed to save bootstrap attempt to database")
+	errRetrieveAttempts = errors.New("failed to retrieve bootstrap attempts from database")
 )
 
 var _ bootstrap.ConfigRepository = (*configRepository)(nil)
@@ -56,14 +70,57 @@ func NewConfigRepository(db *sqlx.DB, log logger.Logger) bootstrap.ConfigReposit
 }
 
 func (cr configRepository) Save(cfg bootstrap.Config, chsConnIDs []string) (string, error) {
-	q := `INSERT INTO configs (mainflux_thing, owner, name, client_cert, client_key, ca_cert, mainflux_key, external_id, external_key, content, state)
-		  VALUES (:mainflux_thing, :owner, :name, :client_cert, :client_key, :ca_cert, :mainflux_key, :external_id, :external_key, :content, :state)`
-
 	tx, err := cr.db.Beginx()
 	if err != nil {
 		return "", errors.Wrap(errSaveDB, err)
 	}
 
+	if err := cr.save(tx, cfg, chsConnIDs); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		cr.rollback("Failed to commit Config save", tx, err)
+	}
+
+	return cfg.MFThing, nil
+}
+
+func (cr configRepository) SaveAll(cfgs []bootstrap.Config, chsConnIDs [][]string) []error {
+	errs := make([]error, len(cfgs))
+
+	tx, err := cr.db.Beginx()
+	if err != nil {
+		for i := range errs {
+			errs[i] = errors.Wrap(errSaveDB, err)
+		}
+		return errs
+	}
+
+	for i, cfg := range cfgs {
+		if err := cr.save(tx, cfg, chsConnIDs[i]); err != nil {
+			errs[i] = err
+			return errs
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		cr.rollback("Failed to commit Configs save", tx, err)
+		for i := range errs {
+			errs[i] = errors.Wrap(errSaveDB, err)
+		}
+		return errs
+	}
+
+	return nil
+}
+
+// save inserts the Config along with its Channels and connections, rolling
+// back the transaction on failure.
+func (cr configRepository) save(tx *sqlx.Tx, cfg bootstrap.Config, chsConnIDs []string) error {
+	q := `INSERT INTO configs (mainflux_thing, owner, name, client_cert, client_key, ca_cert, mainflux_key, external_id, external_key, content, state, enc_key, key_version)
+		  VALUES (:mainflux_thing, :owner, :name, :client_cert, :client_key, :ca_cert, :mainflux_key, :external_id, :external_key, :content, :state, :enc_key, :key_version)`
+
 	dbcfg := toDBConfig(cfg)
 
 	if _, err := tx.NamedExec(q, dbcfg); err != nil {
@@ -74,26 +131,28 @@ func (cr configRepository) Save(cfg bootstrap.Config, chsConnIDs []string) (stri
 
 		cr.rollback("Failed to insert a Config", tx, err)
 
-		return "", errors.Wrap(errSaveDB, e)
+		return errors.Wrap(errSaveDB, e)
 	}
 
 	if err := insertChannels(cfg.Owner, cfg.MFChannels, tx); err != nil {
 		cr.rollback("Failed to insert Channels", tx, err)
 
-		return "", errors.Wrap(errSaveChannels, err)
+		return errors.Wrap(errSaveChannels, err)
 	}
 
 	if err := insertConnections(cfg, chsConnIDs, tx); err != nil {
 		cr.rollback("Failed to insert connections", tx, err)
 
-		return "", errors.Wrap(errSaveConnections, err)
+		return errors.Wrap(errSaveConnections, err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		cr.rollback("Failed to commit Config save", tx, err)
+	if err := saveVersion(cfg.Owner, cfg.MFThing, tx); err != nil {
+		cr.rollback("Failed to insert Config version", tx, err)
+
+		return errors.Wrap(errSaveVersion, err)
 	}
 
-	return cfg.MFThing, nil
+	return nil
 }
 
 func (cr configRepository) RetrieveByID(owner, id string) (bootstrap.Config, error) {
@@ -196,7 +255,7 @@ func (cr configRepository) RetrieveAll(owner string, filter bootstrap.Filter, of
 }
 
 func (cr configRepository) RetrieveByExternalID(externalID string) (bootstrap.Config, error) {
-	q := `SELECT mainflux_thing, mainflux_key, external_key, owner, name, client_cert, client_key, ca_cert, content, state
+	q := `SELECT mainflux_thing, mainflux_key, external_key, owner, name, client_cert, client_key, ca_cert, content, state, enc_key, key_version
 		  FROM configs
 		  WHERE external_id = $1`
 	dbcfg := dbConfig{
@@ -252,18 +311,18 @@ func (cr configRepository) Update(cfg bootstrap.Config) error {
 	content := nullString(cfg.Content)
 	name := nullString(cfg.Name)
 
-	res, err := cr.db.Exec(q, name, content, cfg.MFThing, cfg.Owner)
+	tx, err := cr.db.Beginx()
 	if err != nil {
 		return errors.Wrap(errUpdate, err)
 	}
 
-	cnt, err := res.RowsAffected()
-	if err != nil {
+	if err := cr.update(tx, cfg.Owner, cfg.MFThing, q, name, content, cfg.MFThing, cfg.Owner); err != nil {
 		return errors.Wrap(errUpdate, err)
 	}
 
-	if cnt == 0 {
-		return bootstrap.ErrNotFound
+	if err := tx.Commit(); err != nil {
+		cr.rollback("Failed to commit Config update", tx, err)
+		return errors.Wrap(errUpdate, err)
 	}
 
 	return nil
@@ -272,20 +331,48 @@ func (cr configRepository) Update(cfg bootstrap.Config) error {
 func (cr configRepository) UpdateCert(owner, thingID, clientCert, clientKey, caCert string) error {
 	q := `UPDATE configs SET client_cert = $1, client_key = $2, ca_cert = $3 WHERE mainflux_thing = $4 AND owner = $5`
 
-	res, err := cr.db.Exec(q, clientCert, clientKey, caCert, thingID, owner)
+	tx, err := cr.db.Beginx()
 	if err != nil {
 		return err
 	}
 
+	if err := cr.update(tx, owner, thingID, q, clientCert, clientKey, caCert, thingID, owner); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		cr.rollback("Failed to commit Config certificates update", tx, err)
+		return err
+	}
+
+	return nil
+}
+
+// update executes the update query of the Config and records its new
+// version, rolling back the transaction on failure.
+func (cr configRepository) update(tx *sqlx.Tx, owner, id, q string, args ...interface{}) error {
+	res, err := tx.Exec(q, args...)
+	if err != nil {
+		cr.rollback("Failed to update Config", tx, err)
+		return err
+	}
+
 	cnt, err := res.RowsAffected()
 	if err != nil {
+		cr.rollback("Failed to update Config", tx, err)
 		return err
 	}
 
 	if cnt == 0 {
+		cr.rollback("Failed to update Config", tx, bootstrap.ErrNotFound)
 		return bootstrap.ErrNotFound
 	}
 
+	if err := saveVersion(owner, id, tx); err != nil {
+		cr.rollback("Failed to insert Config version", tx, err)
+		return errors.Wrap(errSaveVersion, err)
+	}
+
 	return nil
 }
 
@@ -312,6 +399,12 @@ func (cr configRepository) UpdateConnections(owner, id string, channels []bootst
 		return err
 	}
 
+	if err := saveVersion(owner, id, tx); err != nil {
+		cr.rollback("Failed to insert Config version during the update", tx, err)
+
+		return errors.Wrap(errSaveVersion, err)
+	}
+
 	if err := tx.Commit(); err != nil {
 		cr.rollback("Failed to commit Config update", tx, err)
 	}
@@ -319,6 +412,165 @@ func (cr configRepository) UpdateConnections(owner, id string, channels []bootst
 	return nil
 }
 
+func (cr configRepository) RetrieveVersions(owner, id string, offset, limit uint64) (bootstrap.VersionsPage, error) {
+	q := `SELECT version, name, content, client_cert, client_key, ca_cert, channels, created
+		  FROM config_versions WHERE config_id = $1 AND config_owner = $2
+		  ORDER BY version DESC LIMIT $3 OFFSET $4`
+
+	rows, err := cr.db.Queryx(q, id, owner, limit, offset)
+	if err != nil {
+		return bootstrap.VersionsPage{}, errors.Wrap(errRetrieveVersions, err)
+	}
+	defer rows.Close()
+
+	versions := []bootstrap.ConfigVersion{}
+	for rows.Next() {
+		dbv := dbVersion{}
+		if err := rows.StructScan(&dbv); err != nil {
+			cr.log.Error(fmt.Sprintf("Failed to read retrieved version due to %s", err))
+			return bootstrap.VersionsPage{}, errors.Wrap(errRetrieveVersions, err)
+		}
+		versions = append(versions, toVersion(dbv))
+	}
+
+	q = `SELECT COUNT(*) FROM config_versions WHERE config_id = $1 AND config_owner = $2`
+
+	var total uint64
+	if err := cr.db.QueryRow(q, id, owner).Scan(&total); err != nil {
+		return bootstrap.VersionsPage{}, errors.Wrap(errRetrieveVersions, err)
+	}
+
+	return bootstrap.VersionsPage{
+		Total:    total,
+		Offset:   offset,
+		Limit:    limit,
+		Versions: versions,
+	}, nil
+}
+
+func (cr configRepository) RetrieveVersion(owner, id string, version uint64) (bootstrap.ConfigVersion, error) {
+	q := `SELECT version, name, content, client_cert, client_key, ca_cert, channels, created
+		  FROM config_versions WHERE config_id = $1 AND config_owner = $2 AND version = $3`
+
+	dbv := dbVersion{}
+	if err := cr.db.QueryRowx(q, id, owner, version).StructScan(&dbv); err != nil {
+		if err == sql.ErrNoRows {
+			return bootstrap.ConfigVersion{}, errors.Wrap(bootstrap.ErrNotFound, err)
+		}
+		return bootstrap.ConfigVersion{}, errors.Wrap(errRetrieveVersions, err)
+	}
+
+	return toVersion(dbv), nil
+}
+
+func (cr configRepository) SaveAttempt(attempt bootstrap.Attempt) error {
+	q := `INSERT INTO bootstrap_attempts (external_id, ip, timestamp, result)
+		  VALUES (:external_id, :ip, :timestamp, :result)`
+
+	if _, err := cr.db.NamedExec(q, toDBAttempt(attempt)); err != nil {
+		return errors.Wrap(errSaveAttempt, err)
+	}
+
+	return nil
+}
+
+func (cr configRepository) RetrieveAttempts(externalID string, offset, limit uint64) (bootstrap.AttemptsPage, error) {
+	q := `SELECT external_id, ip, timestamp, result FROM bootstrap_attempts WHERE external_id = $1
+		  ORDER BY timestamp DESC, id DESC LIMIT $2 OFFSET $3`
+
+	rows, err := cr.db.Queryx(q, externalID, limit, offset)
+	if err != nil {
+		return bootstrap.AttemptsPage{}, errors.Wrap(errRetrieveAttempts, err)
+	}
+	defer rows.Close()
+
+	attempts := []bootstrap.Attempt{}
+	for rows.Next() {
+		dba := dbAttempt{}
+		if err := rows.StructScan(&dba); err != nil {
+			cr.log.Error(fmt.Sprintf("Failed to read retrieved attempt due to %s", err))
+			return bootstrap.AttemptsPage{}, errors.Wrap(errRetrieveAttempts, err)
+		}
+		attempts = append(attempts, toAttempt(dba))
+	}
+
+	q = `SELECT COUNT(*) FROM bootstrap_attempts WHERE external_id = $1`
+
+	var total uint64
+	if err := cr.db.QueryRow(q, externalID).Scan(&total); err != nil {
+		return bootstrap.AttemptsPage{}, errors.Wrap(errRetrieveAttempts, err)
+	}
+
+	return bootstrap.AttemptsPage{
+		Total:    total,
+		Offset:   offset,
+		Limit:    limit,
+		Attempts: attempts,
+	}, nil
+}
+
+func (cr configRepository) Restore(owner, id string, version bootstrap.ConfigVersion, channels []bootstrap.Channel) error {
+	tx, err := cr.db.Beginx()
+	if err != nil {
+		return errors.Wrap(errRestore, err)
+	}
+
+	q := `UPDATE configs SET name = $1, content = $2, client_cert = $3, client_key = $4, ca_cert = $5
+		  WHERE mainflux_thing = $6 AND owner = $7`
+
+	res, err := tx.Exec(q, nullString(version.Name), nullString(version.Content), nullString(version.ClientCert),
+		nullString(version.ClientKey), nullString(version.CACert), id, owner)
+	if err != nil {
+		cr.rollback("Failed to restore Config", tx, err)
+		return errors.Wrap(errRestore, err)
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		cr.rollback("Failed to restore Config", tx, err)
+		return errors.Wrap(errRestore, err)
+	}
+
+	if cnt == 0 {
+		cr.rollback("Failed to restore Config", tx, bootstrap.ErrNotFound)
+		return bootstrap.ErrNotFound
+	}
+
+	if err := insertChannels(owner, channels, tx); err != nil {
+		cr.rollback("Failed to insert Channels during the restore", tx, err)
+		return errors.Wrap(errRestore, err)
+	}
+
+	q = `DELETE FROM connections WHERE config_id = $1 AND config_owner = $2`
+	if _, err := tx.Exec(q, id, owner); err != nil {
+		cr.rollback("Failed to remove connections during the restore", tx, err)
+		return errors.Wrap(errRestore, err)
+	}
+
+	cfg := bootstrap.Config{MFThing: id, Owner: owner}
+	if err := insertConnections(cfg, version.Channels, tx); err != nil {
+		cr.rollback("Failed to insert connections during the restore", tx, err)
+		return errors.Wrap(errRestore, err)
+	}
+
+	if _, err := tx.Exec(cleanupQuery); err != nil {
+		cr.rollback("Failed to clean dangling channels during the restore", tx, err)
+		return errors.Wrap(errRestore, err)
+	}
+
+	if err := saveVersion(owner, id, tx); err != nil {
+		cr.rollback("Failed to insert Config version during the restore", tx, err)
+		return errors.Wrap(errSaveVersion, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		cr.rollback("Failed to commit Config restore", tx, err)
+		return errors.Wrap(errRestore, err)
+	}
+
+	return nil
+}
+
 func (cr configRepository) Remove(owner, id string) error {
 	q := `DELETE FROM configs WHERE mainflux_thing = $1 AND owner = $2`
 	if _, err := cr.db.Exec(q, id, owner); err != nil {
@@ -383,6 +635,50 @@ func (cr configRepository) ListExisting(owner string, ids []string) ([]bootstrap
 	return channels, nil
 }
 
+func (cr configRepository) RetrieveOutdatedKeys(version uint64, after string, limit uint64) ([]bootstrap.Config, error) {
+	q := `SELECT mainflux_thing, owner, enc_key, key_version FROM configs
+		  WHERE key_version <> $1 AND mainflux_thing > $2
+		  ORDER BY mainflux_thing LIMIT $3`
+
+	rows, err := cr.db.Queryx(q, version, after, limit)
+	if err != nil {
+		return nil, errors.Wrap(errRetrieve, err)
+	}
+	defer rows.Close()
+
+	configs := []bootstrap.Config{}
+	for rows.Next() {
+		dbcfg := dbConfig{}
+		if err := rows.StructScan(&dbcfg); err != nil {
+			cr.log.Error(fmt.Sprintf("Failed to read retrieved config due to %s", err))
+			return nil, errors.Wrap(errRetrieve, err)
+		}
+		configs = append(configs, toConfig(dbcfg))
+	}
+
+	return configs, nil
+}
+
+func (cr configRepository) UpdateKey(id string, key []byte, version uint64) error {
+	q := `UPDATE configs SET enc_key = $1, key_version = $2 WHERE mainflux_thing = $3`
+
+	res, err := cr.db.Exec(q, key, version, id)
+	if err != nil {
+		return errors.Wrap(errUpdate, err)
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(errUpdate, err)
+	}
+
+	if cnt == 0 {
+		return bootstrap.ErrNotFound
+	}
+
+	return nil
+}
+
 func (cr configRepository) RemoveThing(id string) error {
 	q := `DELETE FROM configs WHERE mainflux_thing = $1`
 	_, err := cr.db.Exec(q, id)
@@ -552,6 +848,19 @@ func updateConnections(owner, id string, connections []string, tx *sqlx.Tx) erro
 	return err
 }
 
+// saveVersion records the current state of the Config as its new version.
+// The Config row is locked first, so that concurrent changes of the same
+// Config get consecutive version numbers.
+func saveVersion(owner, id string, tx *sqlx.Tx) error {
+	q := `SELECT 1 FROM configs WHERE mainflux_thing = $1 AND owner = $2 FOR UPDATE`
+	if _, err := tx.Exec(q, id, owner); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(versionQuery, id, owner, time.Now().UTC())
+	return err
+}
+
 func nullString(s string) sql.NullString {
 	if s == "" {
 		return sql.NullString{}
@@ -575,6 +884,8 @@ type dbConfig struct {
 	ExternalKey string          `db:"external_key"`
 	Content     sql.NullString  `db:"content"`
 	State       bootstrap.State `db:"state"`
+	EncKey      []byte          `db:"enc_key"`
+	KeyVersion  uint64          `db:"key_version"`
 }
 
 func toDBConfig(cfg bootstrap.Config) dbConfig {
@@ -590,6 +901,8 @@ func toDBConfig(cfg bootstrap.Config) dbConfig {
 		ExternalKey: cfg.ExternalKey,
 		Content:     nullString(cfg.Content),
 		State:       cfg.State,
+		EncKey:      cfg.EncKey,
+		KeyVersion:  cfg.KeyVersion,
 	}
 }
 
@@ -601,6 +914,8 @@ func toConfig(dbcfg dbConfig) bootstrap.Config {
 		ExternalID:  dbcfg.ExternalID,
 		ExternalKey: dbcfg.ExternalKey,
 		State:       dbcfg.State,
+		EncKey:      dbcfg.EncKey,
+		KeyVersion:  dbcfg.KeyVersion,
 	}
 
 	if dbcfg.Name.Valid {
@@ -670,3 +985,52 @@ type dbConnection struct {
 	ConfigOwner  string `db:"config_owner"`
 	ChannelOwner string `db:"channel_owner"`
 }
+
+type dbVersion struct {
+	Version    uint64         `db:"version"`
+	Name       sql.NullString `db:"name"`
+	Content    sql.NullString `db:"content"`
+	ClientCert sql.NullString `db:"client_cert"`
+	ClientKey  sql.NullString `db:"client_key"`
+	CaCert     sql.NullString `db:"ca_cert"`
+	Channels   pq.StringArray `db:"channels"`
+	Created    time.Time      `db:"created"`
+}
+
+func toVersion(dbv dbVersion) bootstrap.ConfigVersion {
+	return bootstrap.ConfigVersion{
+		Version:    dbv.Version,
+		Name:       dbv.Name.String,
+		Content:    dbv.Content.String,
+		ClientCert: dbv.ClientCert.String,
+		ClientKey:  dbv.ClientKey.String,
+		CACert:     dbv.CaCert.String,
+		Channels:   []string(dbv.Channels),
+		Created:    dbv.Created,
+	}
+}
+
+type dbAttempt struct {
+	ExternalID string         `db:"external_id"`
+	IP         sql.NullString `db:"ip"`
+	Timestamp  time.Time      `db:"timestamp"`
+	Result     string         `db:"result"`
+}
+
+func toDBAttempt(attempt bootstrap.Attempt) dbAttempt {
+	return dbAttempt{
+		ExternalID: attempt.ExternalID,
+		IP:         nullString(attempt.IP),
+		Timestamp:  attempt.Timestamp,
+		Result:     string(attempt.Result),
+	}
+}
+
+func toAttempt(dba dbAttempt) bootstrap.Attempt {
+	return bootstrap.Attempt{
+		ExternalID: dba.ExternalID,
+		IP:         dba.IP.String,
+		Timestamp:  dba.Timestamp,
+		Result:     bootstrap.AttemptResult(dba.Result),
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/mainflux/mainflux/bootstrap"
@@ -95,6 +96,65 @@ func TestSave(t *testing.T) {
 	}
 }
 
+func TestSaveAll(t *testing.T) {
+	repo := postgres.NewConfigRepository(db, testLog)
+	err := deleteChannels(repo)
+	require.Nil(t, err, "Channels cleanup expected to succeed.")
+
+	newConfig := func() bootstrap.Config {
+		c := config
+		// Use UUID to prevent conflicts.
+		uid, err := uuid.NewV4()
+		require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+		c.MFKey = uid.String()
+		c.MFThing = uid.String()
+		c.ExternalID = uid.String()
+		c.ExternalKey = uid.String()
+		c.MFChannels = []bootstrap.Channel{}
+		return c
+	}
+
+	first := newConfig()
+	first.MFChannels = config.MFChannels
+	second := newConfig()
+
+	duplicate := newConfig()
+	duplicate.ExternalID = first.ExternalID
+
+	cases := []struct {
+		desc        string
+		configs     []bootstrap.Config
+		connections [][]string
+		errs        []error
+		saved       bool
+	}{
+		{
+			desc:        "save configs",
+			configs:     []bootstrap.Config{first, second},
+			connections: [][]string{channels, channels},
+			errs:        nil,
+			saved:       true,
+		},
+		{
+			desc:        "save configs with existing external ID",
+			configs:     []bootstrap.Config{newConfig(), duplicate},
+			connections: [][]string{nil, nil},
+			errs:        []error{nil, bootstrap.ErrConflict},
+			saved:       false,
+		},
+	}
+	for _, tc := range cases {
+		errs := repo.SaveAll(tc.configs, tc.connections)
+		require.Len(t, errs, len(tc.errs), fmt.Sprintf("%s: expected %d errors got %d\n", tc.desc, len(tc.errs), len(errs)))
+		for i, err := range errs {
+			assert.True(t, errors.Contains(err, tc.errs[i]), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.errs[i], err))
+		}
+
+		_, err := repo.RetrieveByID(tc.configs[0].Owner, tc.configs[0].MFThing)
+		assert.Equal(t, tc.saved, err == nil, fmt.Sprintf("%s: expected config saved to be %t got error %s\n", tc.desc, tc.saved, err))
+	}
+}
+
 func TestRetrieveByID(t *testing.T) {
 	repo := postgres.NewConfigRepository(db, testLog)
 	err := deleteChannels(repo)
@@ -444,6 +504,240 @@ func TestUpdateConnections(t *testing.T) {
 	}
 }
 
+func TestRetrieveVersions(t *testing.T) {
+	repo := postgres.NewConfigRepository(db, testLog)
+	err := deleteChannels(repo)
+	require.Nil(t, err, "Channels cleanup expected to succeed.")
+
+	c := config
+	// Use UUID to prevent conflicts.
+	uid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+	c.MFKey = uid.String()
+	c.MFThing = uid.String()
+	c.ExternalID = uid.String()
+	c.ExternalKey = uid.String()
+	_, err = repo.Save(c, channels)
+	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+
+	c.Content = "updated content"
+	err = repo.Update(c)
+	require.Nil(t, err, fmt.Sprintf("Updating config expected to succeed: %s.\n", err))
+	err = repo.UpdateCert(c.Owner, c.MFThing, "cert", "key", "ca")
+	require.Nil(t, err, fmt.Sprintf("Updating config certs expected to succeed: %s.\n", err))
+	err = repo.UpdateConnections(c.Owner, c.MFThing, nil, []string{channels[0]})
+	require.Nil(t, err, fmt.Sprintf("Updating config connections expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc     string
+		owner    string
+		id       string
+		offset   uint64
+		limit    uint64
+		total    uint64
+		versions []uint64
+	}{
+		{
+			desc:     "retrieve all versions",
+			owner:    c.Owner,
+			id:       c.MFThing,
+			offset:   0,
+			limit:    10,
+			total:    4,
+			versions: []uint64{4, 3, 2, 1},
+		},
+		{
+			desc:     "retrieve versions with offset and limit",
+			owner:    c.Owner,
+			id:       c.MFThing,
+			offset:   1,
+			limit:    2,
+			total:    4,
+			versions: []uint64{3, 2},
+		},
+		{
+			desc:     "retrieve versions of non-existing config",
+			owner:    c.Owner,
+			id:       "unknown",
+			offset:   0,
+			limit:    10,
+			total:    0,
+			versions: nil,
+		},
+		{
+			desc:     "retrieve versions with wrong owner",
+			owner:    "2",
+			id:       c.MFThing,
+			offset:   0,
+			limit:    10,
+			total:    0,
+			versions: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := repo.RetrieveVersions(tc.owner, tc.id, tc.offset, tc.limit)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		var versions []uint64
+		for _, v := range page.Versions {
+			versions = append(versions, v.Version)
+		}
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", tc.desc, tc.total, page.Total))
+		assert.Equal(t, tc.versions, versions, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.versions, versions))
+	}
+
+	ver, err := repo.RetrieveVersion(c.Owner, c.MFThing, 3)
+	require.Nil(t, err, fmt.Sprintf("Retrieving version expected to succeed: %s.\n", err))
+	assert.Equal(t, "updated content", ver.Content, fmt.Sprintf("expected content %s got %s\n", "updated content", ver.Content))
+	assert.Equal(t, "cert", ver.ClientCert, fmt.Sprintf("expected client cert %s got %s\n", "cert", ver.ClientCert))
+	assert.Equal(t, channels, ver.Channels, fmt.Sprintf("expected channels %v got %v\n", channels, ver.Channels))
+
+	_, err = repo.RetrieveVersion(c.Owner, c.MFThing, 5)
+	assert.True(t, errors.Contains(err, bootstrap.ErrNotFound), fmt.Sprintf("expected %s got %s\n", bootstrap.ErrNotFound, err))
+}
+
+func TestRetrieveAttempts(t *testing.T) {
+	repo := postgres.NewConfigRepository(db, testLog)
+
+	// Use UUID to prevent conflicts.
+	uid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+	externalID := uid.String()
+
+	results := []bootstrap.AttemptResult{bootstrap.AttemptInvalidKey, bootstrap.AttemptSuccess}
+	for i, res := range results {
+		attempt := bootstrap.Attempt{
+			ExternalID: externalID,
+			IP:         "127.0.0.1",
+			Timestamp:  time.Now().Add(time.Duration(i) * time.Second),
+			Result:     res,
+		}
+		err := repo.SaveAttempt(attempt)
+		require.Nil(t, err, fmt.Sprintf("Saving attempt expected to succeed: %s.\n", err))
+	}
+
+	cases := []struct {
+		desc       string
+		externalID string
+		offset     uint64
+		limit      uint64
+		total      uint64
+		results    []bootstrap.AttemptResult
+	}{
+		{
+			desc:       "retrieve all attempts",
+			externalID: externalID,
+			offset:     0,
+			limit:      10,
+			total:      2,
+			results:    []bootstrap.AttemptResult{bootstrap.AttemptSuccess, bootstrap.AttemptInvalidKey},
+		},
+		{
+			desc:       "retrieve attempts with offset and limit",
+			externalID: externalID,
+			offset:     1,
+			limit:      1,
+			total:      2,
+			results:    []bootstrap.AttemptResult{bootstrap.AttemptInvalidKey},
+		},
+		{
+			desc:       "retrieve attempts of unknown external ID",
+			externalID: "unknown",
+			offset:     0,
+			limit:      10,
+			total:      0,
+			results:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := repo.RetrieveAttempts(tc.externalID, tc.offset, tc.limit)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		var results []bootstrap.AttemptResult
+		for _, a := range page.Attempts {
+			results = append(results, a.Result)
+		}
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", tc.desc, tc.total, page.Total))
+		assert.Equal(t, tc.results, results, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.results, results))
+	}
+}
+
+func TestRestore(t *testing.T) {
+	repo := postgres.NewConfigRepository(db, testLog)
+	err := deleteChannels(repo)
+	require.Nil(t, err, "Channels cleanup expected to succeed.")
+
+	c := config
+	// Use UUID to prevent conflicts.
+	uid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+	c.MFKey = uid.String()
+	c.MFThing = uid.String()
+	c.ExternalID = uid.String()
+	c.ExternalKey = uid.String()
+	_, err = repo.Save(c, channels)
+	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+
+	newChannel := bootstrap.Channel{ID: "3", Name: "name 3", Metadata: map[string]interface{}{"meta": 3.0}}
+	version := bootstrap.ConfigVersion{
+		Name:       "restored",
+		Content:    "restored content",
+		ClientCert: "cert",
+		ClientKey:  "key",
+		CACert:     "ca",
+		Channels:   []string{channels[0], newChannel.ID},
+	}
+
+	cases := []struct {
+		desc     string
+		owner    string
+		id       string
+		channels []bootstrap.Channel
+		err      error
+	}{
+		{
+			desc:     "restore non-existing config",
+			owner:    c.Owner,
+			id:       "unknown",
+			channels: []bootstrap.Channel{newChannel},
+			err:      bootstrap.ErrNotFound,
+		},
+		{
+			desc:     "restore config with wrong owner",
+			owner:    "2",
+			id:       c.MFThing,
+			channels: []bootstrap.Channel{newChannel},
+			err:      bootstrap.ErrNotFound,
+		},
+		{
+			desc:     "restore config",
+			owner:    c.Owner,
+			id:       c.MFThing,
+			channels: []bootstrap.Channel{newChannel},
+			err:      nil,
+		},
+	}
+
+	for _, tc := range cases {
+		err := repo.Restore(tc.owner, tc.id, version, tc.channels)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	cfg, err := repo.RetrieveByID(c.Owner, c.MFThing)
+	require.Nil(t, err, fmt.Sprintf("Retrieving config expected to succeed: %s.\n", err))
+	assert.Equal(t, version.Content, cfg.Content, fmt.Sprintf("expected content %s got %s\n", version.Content, cfg.Content))
+	assert.Equal(t, version.ClientKey, cfg.ClientKey, fmt.Sprintf("expected client key %s got %s\n", version.ClientKey, cfg.ClientKey))
+	var ids []string
+	for _, ch := range cfg.MFChannels {
+		ids = append(ids, ch.ID)
+	}
+	assert.ElementsMatch(t, version.Channels, ids, fmt.Sprintf("expected channels %v got %v\n", version.Channels, ids))
+
+	page, err := repo.RetrieveVersions(c.Owner, c.MFThing, 0, 10)
+	require.Nil(t, err, fmt.Sprintf("Retrieving versions expected to succeed: %s.\n", err))
+	assert.Equal(t, uint64(2), page.Total, fmt.Sprintf("expected %d versions got %d\n", 2, page.Total))
+}
+
 func TestRemove(t *testing.T) {
 	repo := postgres.NewConfigRepository(db, testLog)
 	err := deleteChannels(repo)
@@ -683,7 +977,7 @@ func TestDisconnectThing(t *testing.T) {
 
 	cfg, err := repo.RetrieveByID(c.Owner, c.MFThing)
 	require.Nil(t, err, fmt.Sprintf("Retrieving config expected to succeed: %s.\n", err))
-	assert.Equal(t, cfg.State, bootstrap.Inactive, fmt.Sprintf("expected ti be inactive when a connection is removed from %s", cfg))
+	assert.Equal(t, cfg.State, bootstrap.Inactive, fmt.Sprintf("expected ti be inactive when a connection is removed from %s", cfg.MFThing))
 }
 
 func deleteChannels(repo bootstrap.ConfigRepository) error {
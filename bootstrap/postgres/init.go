@@ -100,6 +100,106 @@ func migrateDB(db *sqlx.DB) error {
 					"CREATE TABLE IF NOT EXISTS unknown_configs",
 				},
 			},
+			{
+				Id: "configs_3",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS config_versions (
+						config_id    TEXT,
+						config_owner VARCHAR(256),
+						version      BIGINT NOT NULL,
+						name         TEXT,
+						content      TEXT,
+						client_cert  TEXT,
+						client_key   TEXT,
+						ca_cert      TEXT,
+						channels     TEXT[] NOT NULL,
+						created      TIMESTAMPTZ NOT NULL,
+						FOREIGN KEY (config_id, config_owner) REFERENCES configs (mainflux_thing, owner) ON DELETE CASCADE ON UPDATE CASCADE,
+						PRIMARY KEY (config_id, config_owner, version)
+					)`,
+					`INSERT INTO config_versions (config_id, config_owner, version, name, content, client_cert, client_key, ca_cert, channels, created)
+					 SELECT c.mainflux_thing, c.owner, 1, c.name, c.content, c.client_cert, c.client_key, c.ca_cert,
+					 ARRAY(SELECT channel_id FROM connections conn
+					 WHERE conn.config_id = c.mainflux_thing AND conn.config_owner = c.owner ORDER BY channel_id), now()
+					 FROM configs c`,
+				},
+				Down: []string{
+					"DROP TABLE config_versions",
+				},
+			},
+			{
+				Id: "configs_4",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS templates (
+						id       TEXT NOT NULL,
+						owner    VARCHAR(254) NOT NULL,
+						name     TEXT NOT NULL,
+						channels TEXT[] NOT NULL,
+						content  TEXT,
+						PRIMARY KEY (id, owner)
+					)`,
+				},
+				Down: []string{
+					"DROP TABLE templates",
+				},
+			},
+			{
+				Id: "configs_5",
+				Up: []string{
+					`ALTER TABLE configs ADD COLUMN IF NOT EXISTS enc_key BYTEA`,
+					`ALTER TABLE configs ADD COLUMN IF NOT EXISTS key_version BIGINT NOT NULL DEFAULT 0`,
+				},
+				Down: []string{
+					"ALTER TABLE configs DROP COLUMN enc_key",
+					"ALTER TABLE configs DROP COLUMN key_version",
+				},
+			},
+			{
+				Id: "configs_6",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS bootstrap_attempts (
+						id          BIGSERIAL PRIMARY KEY,
+						external_id TEXT NOT NULL,
+						ip          TEXT,
+						timestamp   TIMESTAMPTZ NOT NULL,
+						result      VARCHAR(32) NOT NULL
+					)`,
+					`CREATE INDEX IF NOT EXISTS bootstrap_attempts_external_id ON bootstrap_attempts (external_id, timestamp)`,
+				},
+				Down: []string{
+					"DROP TABLE bootstrap_attempts",
+				},
+			},
+			{
+				Id: "configs_7",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS patterns (
+						id           TEXT NOT NULL,
+						owner        VARCHAR(254) NOT NULL,
+						type         VARCHAR(16) NOT NULL,
+						expression   TEXT NOT NULL,
+						external_key TEXT NOT NULL,
+						template_id  TEXT NOT NULL,
+						state        BIGINT NOT NULL,
+						FOREIGN KEY (template_id, owner) REFERENCES templates (id, owner) ON DELETE CASCADE ON UPDATE CASCADE,
+						PRIMARY KEY (id, owner)
+					)`,
+				},
+				Down: []string{
+					"DROP TABLE patterns",
+				},
+			},
+			{
+				Id: "configs_8",
+				Up: []string{
+					`ALTER TABLE patterns ADD COLUMN IF NOT EXISTS enc_key BYTEA`,
+					`ALTER TABLE patterns ADD COLUMN IF NOT EXISTS key_version BIGINT NOT NULL DEFAULT 0`,
+				},
+				Down: []string{
+					"ALTER TABLE patterns DROP COLUMN enc_key",
+					"ALTER TABLE patterns DROP COLUMN key_version",
+				},
+			},
 		},
 	}
 
@@ -0,0 +1,200 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/bootstrap"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var (
+	errSavePattern      = errors.New("failed to save bootstrap configuration pattern to database")
+	errRetrievePattern  = errors.New("failed to retrieve bootstrap configuration pattern from database")
+	errRetrievePatterns = errors.New("failed to retrieve bootstrap configuration patterns from database")
+	errUpdatePattern    = errors.New("failed to update bootstrap configuration pattern in database")
+	errRemovePattern    = errors.New("failed to remove bootstrap configuration pattern from database")
+)
+
+var _ bootstrap.PatternRepository = (*patternRepository)(nil)
+
+type patternRepository struct {
+	db  *sqlx.DB
+	log logger.Logger
+}
+
+// NewPatternRepository instantiates a PostgreSQL implementation of pattern
+// repository.
+func NewPatternRepository(db *sqlx.DB, log logger.Logger) bootstrap.PatternRepository {
+	return &patternRepository{db: db, log: log}
+}
+
+func (pr patternRepository) Save(p bootstrap.Pattern) (string, error) {
+	q := `INSERT INTO patterns (id, owner, type, expression, external_key, template_id, state, enc_key, key_version)
+		  VALUES (:id, :owner, :type, :expression, :external_key, :template_id, :state, :enc_key, :key_version)`
+
+	if _, err := pr.db.NamedExec(q, toDBPattern(p)); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case duplicateErr:
+				return "", errors.Wrap(errSavePattern, bootstrap.ErrConflict)
+			case fkViolation:
+				return "", errors.Wrap(errSavePattern, bootstrap.ErrNotFound)
+			}
+		}
+		return "", errors.Wrap(errSavePattern, err)
+	}
+
+	return p.ID, nil
+}
+
+func (pr patternRepository) RetrieveByID(owner, id string) (bootstrap.Pattern, error) {
+	q := `SELECT id, owner, type, expression, external_key, template_id, state, enc_key, key_version
+		  FROM patterns WHERE id = $1 AND owner = $2`
+
+	dbp := dbPattern{}
+	if err := pr.db.QueryRowx(q, id, owner).StructScan(&dbp); err != nil {
+		if err == sql.ErrNoRows {
+			return bootstrap.Pattern{}, errors.Wrap(bootstrap.ErrNotFound, err)
+		}
+		return bootstrap.Pattern{}, errors.Wrap(errRetrievePattern, err)
+	}
+
+	return toPattern(dbp), nil
+}
+
+func (pr patternRepository) RetrieveAll(owner string, offset, limit uint64) (bootstrap.PatternsPage, error) {
+	q := `SELECT id, owner, type, expression, external_key, template_id, state, enc_key, key_version
+		  FROM patterns WHERE owner = $1 ORDER BY id LIMIT $2 OFFSET $3`
+
+	patterns, err := pr.retrieve(q, owner, limit, offset)
+	if err != nil {
+		return bootstrap.PatternsPage{}, err
+	}
+
+	q = `SELECT COUNT(*) FROM patterns WHERE owner = $1`
+
+	var total uint64
+	if err := pr.db.QueryRow(q, owner).Scan(&total); err != nil {
+		return bootstrap.PatternsPage{}, errors.Wrap(errRetrievePatterns, err)
+	}
+
+	return bootstrap.PatternsPage{
+		Total:    total,
+		Offset:   offset,
+		Limit:    limit,
+		Patterns: patterns,
+	}, nil
+}
+
+func (pr patternRepository) RetrieveByExternalID(externalID string) ([]bootstrap.Pattern, error) {
+	// Regular expressions are matched by the caller, since their syntax
+	// differs from the PostgreSQL one.
+	q := `SELECT id, owner, type, expression, external_key, template_id, state, enc_key, key_version FROM patterns
+		  WHERE (type = $2 AND left($1, length(expression)) = expression) OR type = $3`
+
+	return pr.retrieve(q, externalID, bootstrap.PrefixPattern, bootstrap.RegexPattern)
+}
+
+func (pr patternRepository) RetrieveOutdatedKeys(version uint64, after string, limit uint64) ([]bootstrap.Pattern, error) {
+	q := `SELECT id, owner, type, expression, external_key, template_id, state, enc_key, key_version FROM patterns
+		  WHERE key_version <> $1 AND id > $2
+		  ORDER BY id LIMIT $3`
+
+	return pr.retrieve(q, version, after, limit)
+}
+
+func (pr patternRepository) UpdateKey(id string, key []byte, version uint64) error {
+	q := `UPDATE patterns SET enc_key = $1, key_version = $2 WHERE id = $3`
+
+	res, err := pr.db.Exec(q, key, version, id)
+	if err != nil {
+		return errors.Wrap(errUpdatePattern, err)
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(errUpdatePattern, err)
+	}
+
+	if cnt == 0 {
+		return bootstrap.ErrNotFound
+	}
+
+	return nil
+}
+
+func (pr patternRepository) Remove(owner, id string) error {
+	q := `DELETE FROM patterns WHERE id = $1 AND owner = $2`
+	if _, err := pr.db.Exec(q, id, owner); err != nil {
+		return errors.Wrap(errRemovePattern, err)
+	}
+
+	return nil
+}
+
+func (pr patternRepository) retrieve(query string, params ...interface{}) ([]bootstrap.Pattern, error) {
+	rows, err := pr.db.Queryx(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(errRetrievePatterns, err)
+	}
+	defer rows.Close()
+
+	patterns := []bootstrap.Pattern{}
+	for rows.Next() {
+		dbp := dbPattern{}
+		if err := rows.StructScan(&dbp); err != nil {
+			pr.log.Error(fmt.Sprintf("Failed to read retrieved pattern due to %s", err))
+			return nil, errors.Wrap(errRetrievePatterns, err)
+		}
+		patterns = append(patterns, toPattern(dbp))
+	}
+
+	return patterns, nil
+}
+
+type dbPattern struct {
+	ID          string          `db:"id"`
+	Owner       string          `db:"owner"`
+	Type        string          `db:"type"`
+	Expression  string          `db:"expression"`
+	ExternalKey string          `db:"external_key"`
+	TemplateID  string          `db:"template_id"`
+	State       bootstrap.State `db:"state"`
+	EncKey      []byte          `db:"enc_key"`
+	KeyVersion  uint64          `db:"key_version"`
+}
+
+func toDBPattern(p bootstrap.Pattern) dbPattern {
+	return dbPattern{
+		ID:          p.ID,
+		Owner:       p.Owner,
+		Type:        string(p.Type),
+		Expression:  p.Expression,
+		ExternalKey: p.ExternalKey,
+		TemplateID:  p.TemplateID,
+		State:       p.State,
+		EncKey:      p.EncKey,
+		KeyVersion:  p.KeyVersion,
+	}
+}
+
+func toPattern(dbp dbPattern) bootstrap.Pattern {
+	return bootstrap.Pattern{
+		ID:          dbp.ID,
+		Owner:       dbp.Owner,
+		Type:        bootstrap.PatternType(dbp.Type),
+		Expression:  dbp.Expression,
+		ExternalKey: dbp.ExternalKey,
+		TemplateID:  dbp.TemplateID,
+		State:       dbp.State,
+		EncKey:      dbp.EncKey,
+		KeyVersion:  dbp.KeyVersion,
+	}
+}
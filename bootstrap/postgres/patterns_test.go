@@ -0,0 +1,172 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/mainflux/mainflux/bootstrap"
+	"github.com/mainflux/mainflux/bootstrap/postgres"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavePattern(t *testing.T) {
+	repo := postgres.NewPatternRepository(db, testLog)
+	tmpl := saveTemplate(t)
+
+	uid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+
+	p := bootstrap.Pattern{
+		ID:          uid.String(),
+		Owner:       tmpl.Owner,
+		Type:        bootstrap.PrefixPattern,
+		Expression:  "SN-",
+		ExternalKey: "batch_key",
+		TemplateID:  tmpl.ID,
+		State:       bootstrap.Active,
+	}
+
+	unknownTemplate := p
+	unknownTemplate.ID = wrongID
+	unknownTemplate.TemplateID = wrongID
+
+	cases := []struct {
+		desc    string
+		pattern bootstrap.Pattern
+		err     error
+	}{
+		{
+			desc:    "save a pattern",
+			pattern: p,
+			err:     nil,
+		},
+		{
+			desc:    "save a pattern with the same ID",
+			pattern: p,
+			err:     bootstrap.ErrConflict,
+		},
+		{
+			desc:    "save a pattern with a non-existing template",
+			pattern: unknownTemplate,
+			err:     bootstrap.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := repo.Save(tc.pattern)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	saved, err := repo.RetrieveByID(p.Owner, p.ID)
+	require.Nil(t, err, fmt.Sprintf("Retrieving pattern expected to succeed: %s.\n", err))
+	assert.Equal(t, p, saved, fmt.Sprintf("expected %v got %v\n", p, saved))
+}
+
+func TestRetrievePatternsByExternalID(t *testing.T) {
+	repo := postgres.NewPatternRepository(db, testLog)
+	tmpl := saveTemplate(t)
+
+	// Use UUID to prevent conflicts with the Patterns of other tests.
+	uid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+	prefix := uid.String()
+
+	patterns := map[string]bootstrap.PatternType{
+		prefix + "-1": bootstrap.PrefixPattern,
+		prefix + "-2": bootstrap.PrefixPattern,
+		prefix + ".*": bootstrap.RegexPattern,
+	}
+	for expr, typ := range patterns {
+		uid, err := uuid.NewV4()
+		require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+
+		_, err = repo.Save(bootstrap.Pattern{
+			ID:          uid.String(),
+			Owner:       tmpl.Owner,
+			Type:        typ,
+			Expression:  expr,
+			ExternalKey: "batch_key",
+			TemplateID:  tmpl.ID,
+		})
+		require.Nil(t, err, fmt.Sprintf("Saving pattern expected to succeed: %s.\n", err))
+	}
+
+	cases := []struct {
+		desc       string
+		externalID string
+		prefixes   []string
+	}{
+		{
+			desc:       "retrieve patterns matching the prefix",
+			externalID: prefix + "-10",
+			prefixes:   []string{prefix + "-1"},
+		},
+		{
+			desc:       "retrieve patterns matching no prefix",
+			externalID: prefix + "-30",
+			prefixes:   nil,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := repo.RetrieveByExternalID(tc.externalID)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+
+		var prefixes []string
+		regex := false
+		for _, p := range res {
+			switch {
+			case p.Type == bootstrap.PrefixPattern:
+				prefixes = append(prefixes, p.Expression)
+			case p.Expression == prefix+".*":
+				regex = true
+			}
+		}
+		assert.Equal(t, tc.prefixes, prefixes, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.prefixes, prefixes))
+		assert.True(t, regex, fmt.Sprintf("%s: expected regex patterns to be retrieved\n", tc.desc))
+	}
+}
+
+func TestRemovePattern(t *testing.T) {
+	repo := postgres.NewPatternRepository(db, testLog)
+	tmpl := saveTemplate(t)
+
+	uid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+
+	p := bootstrap.Pattern{
+		ID:          uid.String(),
+		Owner:       tmpl.Owner,
+		Type:        bootstrap.PrefixPattern,
+		Expression:  "SN-",
+		ExternalKey: "batch_key",
+		TemplateID:  tmpl.ID,
+	}
+	_, err = repo.Save(p)
+	require.Nil(t, err, fmt.Sprintf("Saving pattern expected to succeed: %s.\n", err))
+
+	// Patterns are removed along with their Template.
+	err = postgres.NewTemplateRepository(db, testLog).Remove(tmpl.Owner, tmpl.ID)
+	require.Nil(t, err, fmt.Sprintf("Removing template expected to succeed: %s.\n", err))
+
+	_, err = repo.RetrieveByID(p.Owner, p.ID)
+	assert.True(t, errors.Contains(err, bootstrap.ErrNotFound), fmt.Sprintf("expected %s got %s", bootstrap.ErrNotFound, err))
+}
+
+func saveTemplate(t *testing.T) bootstrap.Template {
+	uid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+
+	tmpl := template
+	tmpl.ID = uid.String()
+	_, err = postgres.NewTemplateRepository(db, testLog).Save(tmpl)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	return tmpl
+}
@@ -0,0 +1,162 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/bootstrap"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var (
+	errSaveTemplate      = errors.New("failed to save bootstrap configuration template to database")
+	errRetrieveTemplate  = errors.New("failed to retrieve bootstrap configuration template from database")
+	errRetrieveTemplates = errors.New("failed to retrieve bootstrap configuration templates from database")
+	errUpdateTemplate    = errors.New("failed to update bootstrap configuration template in database")
+	errRemoveTemplate    = errors.New("failed to remove bootstrap configuration template from database")
+)
+
+var _ bootstrap.TemplateRepository = (*templateRepository)(nil)
+
+type templateRepository struct {
+	db  *sqlx.DB
+	log logger.Logger
+}
+
+// NewTemplateRepository instantiates a PostgreSQL implementation of
+// template repository.
+func NewTemplateRepository(db *sqlx.DB, log logger.Logger) bootstrap.TemplateRepository {
+	return &templateRepository{db: db, log: log}
+}
+
+func (tr templateRepository) Save(t bootstrap.Template) (string, error) {
+	q := `INSERT INTO templates (id, owner, name, channels, content)
+		  VALUES (:id, :owner, :name, :channels, :content)`
+
+	if _, err := tr.db.NamedExec(q, toDBTemplate(t)); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == duplicateErr {
+			return "", errors.Wrap(errSaveTemplate, bootstrap.ErrConflict)
+		}
+		return "", errors.Wrap(errSaveTemplate, err)
+	}
+
+	return t.ID, nil
+}
+
+func (tr templateRepository) RetrieveByID(owner, id string) (bootstrap.Template, error) {
+	q := `SELECT id, owner, name, channels, content FROM templates WHERE id = $1 AND owner = $2`
+
+	dbt := dbTemplate{}
+	if err := tr.db.QueryRowx(q, id, owner).StructScan(&dbt); err != nil {
+		if err == sql.ErrNoRows {
+			return bootstrap.Template{}, errors.Wrap(bootstrap.ErrNotFound, err)
+		}
+		return bootstrap.Template{}, errors.Wrap(errRetrieveTemplate, err)
+	}
+
+	return toTemplate(dbt), nil
+}
+
+func (tr templateRepository) RetrieveAll(owner string, offset, limit uint64) (bootstrap.TemplatesPage, error) {
+	q := `SELECT id, owner, name, channels, content FROM templates WHERE owner = $1
+		  ORDER BY id LIMIT $2 OFFSET $3`
+
+	rows, err := tr.db.Queryx(q, owner, limit, offset)
+	if err != nil {
+		return bootstrap.TemplatesPage{}, errors.Wrap(errRetrieveTemplates, err)
+	}
+	defer rows.Close()
+
+	templates := []bootstrap.Template{}
+	for rows.Next() {
+		dbt := dbTemplate{}
+		if err := rows.StructScan(&dbt); err != nil {
+			tr.log.Error(fmt.Sprintf("Failed to read retrieved template due to %s", err))
+			return bootstrap.TemplatesPage{}, errors.Wrap(errRetrieveTemplates, err)
+		}
+		templates = append(templates, toTemplate(dbt))
+	}
+
+	q = `SELECT COUNT(*) FROM templates WHERE owner = $1`
+
+	var total uint64
+	if err := tr.db.QueryRow(q, owner).Scan(&total); err != nil {
+		return bootstrap.TemplatesPage{}, errors.Wrap(errRetrieveTemplates, err)
+	}
+
+	return bootstrap.TemplatesPage{
+		Total:     total,
+		Offset:    offset,
+		Limit:     limit,
+		Templates: templates,
+	}, nil
+}
+
+func (tr templateRepository) Update(t bootstrap.Template) error {
+	q := `UPDATE templates SET name = :name, channels = :channels, content = :content
+		  WHERE id = :id AND owner = :owner`
+
+	res, err := tr.db.NamedExec(q, toDBTemplate(t))
+	if err != nil {
+		return errors.Wrap(errUpdateTemplate, err)
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(errUpdateTemplate, err)
+	}
+
+	if cnt == 0 {
+		return bootstrap.ErrNotFound
+	}
+
+	return nil
+}
+
+func (tr templateRepository) Remove(owner, id string) error {
+	q := `DELETE FROM templates WHERE id = $1 AND owner = $2`
+	if _, err := tr.db.Exec(q, id, owner); err != nil {
+		return errors.Wrap(errRemoveTemplate, err)
+	}
+
+	return nil
+}
+
+type dbTemplate struct {
+	ID       string         `db:"id"`
+	Owner    string         `db:"owner"`
+	Name     string         `db:"name"`
+	Channels pq.StringArray `db:"channels"`
+	Content  sql.NullString `db:"content"`
+}
+
+func toDBTemplate(t bootstrap.Template) dbTemplate {
+	channels := t.Channels
+	if channels == nil {
+		channels = []string{}
+	}
+
+	return dbTemplate{
+		ID:       t.ID,
+		Owner:    t.Owner,
+		Name:     t.Name,
+		Channels: pq.StringArray(channels),
+		Content:  nullString(t.Content),
+	}
+}
+
+func toTemplate(dbt dbTemplate) bootstrap.Template {
+	return bootstrap.Template{
+		ID:       dbt.ID,
+		Owner:    dbt.Owner,
+		Name:     dbt.Name,
+		Channels: []string(dbt.Channels),
+		Content:  dbt.Content.String,
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/mainflux/mainflux/bootstrap"
+	"github.com/mainflux/mainflux/bootstrap/postgres"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const numTemplates = 10
+
+var template = bootstrap.Template{
+	Owner:    "user@email.com",
+	Name:     "template",
+	Channels: []string{"1", "2"},
+	Content:  "{\"id\": \"{{externalID}}\"}",
+}
+
+func TestSaveTemplate(t *testing.T) {
+	repo := postgres.NewTemplateRepository(db, testLog)
+
+	uid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+
+	tmpl := template
+	tmpl.ID = uid.String()
+
+	cases := []struct {
+		desc     string
+		template bootstrap.Template
+		err      error
+	}{
+		{
+			desc:     "save a template",
+			template: tmpl,
+			err:      nil,
+		},
+		{
+			desc:     "save a template with the same ID",
+			template: tmpl,
+			err:      bootstrap.ErrConflict,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := repo.Save(tc.template)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestRetrieveTemplateByID(t *testing.T) {
+	repo := postgres.NewTemplateRepository(db, testLog)
+
+	uid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+
+	tmpl := template
+	tmpl.ID = uid.String()
+	id, err := repo.Save(tmpl)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc  string
+		owner string
+		id    string
+		err   error
+	}{
+		{
+			desc:  "retrieve template",
+			owner: tmpl.Owner,
+			id:    id,
+			err:   nil,
+		},
+		{
+			desc:  "retrieve template with wrong owner",
+			owner: "2",
+			id:    id,
+			err:   bootstrap.ErrNotFound,
+		},
+		{
+			desc:  "retrieve a non-existing template",
+			owner: tmpl.Owner,
+			id:    wrongID,
+			err:   bootstrap.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := repo.RetrieveByID(tc.owner, tc.id)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, tmpl, res, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tmpl, res))
+		}
+	}
+}
+
+func TestRetrieveAllTemplates(t *testing.T) {
+	repo := postgres.NewTemplateRepository(db, testLog)
+
+	owner := "all-templates@email.com"
+	for i := 0; i < numTemplates; i++ {
+		uid, err := uuid.NewV4()
+		require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+
+		tmpl := template
+		tmpl.ID = uid.String()
+		tmpl.Owner = owner
+		_, err = repo.Save(tmpl)
+		require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+	}
+
+	cases := []struct {
+		desc   string
+		owner  string
+		offset uint64
+		limit  uint64
+		size   int
+	}{
+		{
+			desc:   "retrieve all templates",
+			owner:  owner,
+			offset: 0,
+			limit:  uint64(numTemplates),
+			size:   numTemplates,
+		},
+		{
+			desc:   "retrieve a subset of templates",
+			owner:  owner,
+			offset: 5,
+			limit:  uint64(numTemplates - 5),
+			size:   numTemplates - 5,
+		},
+		{
+			desc:   "retrieve templates with wrong owner",
+			owner:  wrongValue,
+			offset: 0,
+			limit:  uint64(numTemplates),
+			size:   0,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := repo.RetrieveAll(tc.owner, tc.offset, tc.limit)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+		size := len(page.Templates)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", tc.desc, tc.size, size))
+	}
+}
+
+func TestUpdateTemplate(t *testing.T) {
+	repo := postgres.NewTemplateRepository(db, testLog)
+
+	uid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+
+	tmpl := template
+	tmpl.ID = uid.String()
+	_, err = repo.Save(tmpl)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	updated := tmpl
+	updated.Name = "updated"
+	updated.Channels = []string{"3"}
+	updated.Content = "updated content"
+
+	wrongOwner := updated
+	wrongOwner.Owner = wrongValue
+
+	cases := []struct {
+		desc     string
+		template bootstrap.Template
+		err      error
+	}{
+		{
+			desc:     "update a template",
+			template: updated,
+			err:      nil,
+		},
+		{
+			desc:     "update a template with wrong owner",
+			template: wrongOwner,
+			err:      bootstrap.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		err := repo.Update(tc.template)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	res, err := repo.RetrieveByID(tmpl.Owner, tmpl.ID)
+	require.Nil(t, err, fmt.Sprintf("Retrieving template expected to succeed: %s.\n", err))
+	assert.Equal(t, updated, res, fmt.Sprintf("expected %v got %v\n", updated, res))
+}
+
+func TestRemoveTemplate(t *testing.T) {
+	repo := postgres.NewTemplateRepository(db, testLog)
+
+	uid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("Got unexpected error: %s.\n", err))
+
+	tmpl := template
+	tmpl.ID = uid.String()
+	_, err = repo.Save(tmpl)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	// Removal works the same for the existing and the non-existing template.
+	for i := 0; i < 2; i++ {
+		err := repo.Remove(tmpl.Owner, tmpl.ID)
+		assert.Nil(t, err, fmt.Sprintf("%d: failed to remove template due to: %s", i, err))
+
+		_, err = repo.RetrieveByID(tmpl.Owner, tmpl.ID)
+		assert.True(t, errors.Contains(err, bootstrap.ErrNotFound), fmt.Sprintf("%d: expected %s got %s", i, bootstrap.ErrNotFound, err))
+	}
+}
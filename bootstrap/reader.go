@@ -73,14 +73,20 @@ func (r reader) ReadConfig(cfg Config, secure bool) (interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
-		return r.encrypt(b)
+		// The response is encrypted using the key of the Config, unless
+		// the Config uses the default key.
+		key := r.encKey
+		if cfg.KeyVersion == 0 && len(cfg.EncKey) > 0 {
+			key = cfg.EncKey
+		}
+		return encrypt(key, b)
 	}
 
 	return res, nil
 }
 
-func (r reader) encrypt(in []byte) ([]byte, error) {
-	block, err := aes.NewCipher(r.encKey)
+func encrypt(key, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
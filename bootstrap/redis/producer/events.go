@@ -11,10 +11,11 @@ import (
 )
 
 const (
-	configPrefix = "config."
-	configCreate = configPrefix + "create"
-	configUpdate = configPrefix + "update"
-	configRemove = configPrefix + "remove"
+	configPrefix   = "config."
+	configCreate   = configPrefix + "create"
+	configUpdate   = configPrefix + "update"
+	configRemove   = configPrefix + "remove"
+	configRollback = configPrefix + "rollback"
 
 	thingPrefix            = "thing."
 	thingBootstrap         = thingPrefix + "bootstrap"
@@ -30,6 +31,7 @@ var (
 	_ event = (*createConfigEvent)(nil)
 	_ event = (*updateConfigEvent)(nil)
 	_ event = (*removeConfigEvent)(nil)
+	_ event = (*rollbackConfigEvent)(nil)
 	_ event = (*bootstrapEvent)(nil)
 	_ event = (*changeStateEvent)(nil)
 	_ event = (*updateConnectionsEvent)(nil)
@@ -88,6 +90,21 @@ func (rce removeConfigEvent) encode() map[string]interface{} {
 	}
 }
 
+type rollbackConfigEvent struct {
+	mfThing   string
+	version   uint64
+	timestamp time.Time
+}
+
+func (rce rollbackConfigEvent) encode() map[string]interface{} {
+	return map[string]interface{}{
+		"thing_id":  rce.mfThing,
+		"version":   rce.version,
+		"timestamp": rce.timestamp.Unix(),
+		"operation": configRollback,
+	}
+}
+
 type bootstrapEvent struct {
 	externalID string
 	success    bool
@@ -58,6 +58,38 @@ func (es eventStore) Add(ctx context.Context, token string, cfg bootstrap.Config
 	return saved, err
 }
 
+func (es eventStore) Import(ctx context.Context, token string, cfgs []bootstrap.Config) ([]bootstrap.ImportResult, error) {
+	res, err := es.svc.Import(ctx, token, cfgs)
+	if err != nil {
+		return res, err
+	}
+
+	for _, r := range res {
+		if r.Err != nil {
+			continue
+		}
+
+		var channels []string
+		for _, ch := range r.Config.MFChannels {
+			channels = append(channels, ch.ID)
+		}
+
+		ev := createConfigEvent{
+			mfThing:    r.Config.MFThing,
+			owner:      r.Config.Owner,
+			name:       r.Config.Name,
+			mfChannels: channels,
+			externalID: r.Config.ExternalID,
+			content:    r.Config.Content,
+			timestamp:  time.Now(),
+		}
+
+		es.add(ctx, ev)
+	}
+
+	return res, nil
+}
+
 func (es eventStore) View(ctx context.Context, token, id string) (bootstrap.Config, error) {
 	return es.svc.View(ctx, token, id)
 }
@@ -99,6 +131,92 @@ func (es eventStore) UpdateConnections(ctx context.Context, token, id string, co
 	return nil
 }
 
+func (es eventStore) ListVersions(ctx context.Context, token, id string, offset, limit uint64) (bootstrap.VersionsPage, error) {
+	return es.svc.ListVersions(ctx, token, id, offset, limit)
+}
+
+func (es eventStore) Rollback(ctx context.Context, token, id string, version uint64) error {
+	if err := es.svc.Rollback(ctx, token, id, version); err != nil {
+		return err
+	}
+
+	ev := rollbackConfigEvent{
+		mfThing:   id,
+		version:   version,
+		timestamp: time.Now(),
+	}
+
+	es.add(ctx, ev)
+
+	return nil
+}
+
+func (es eventStore) AddTemplate(ctx context.Context, token string, t bootstrap.Template) (bootstrap.Template, error) {
+	return es.svc.AddTemplate(ctx, token, t)
+}
+
+func (es eventStore) ViewTemplate(ctx context.Context, token, id string) (bootstrap.Template, error) {
+	return es.svc.ViewTemplate(ctx, token, id)
+}
+
+func (es eventStore) ListTemplates(ctx context.Context, token string, offset, limit uint64) (bootstrap.TemplatesPage, error) {
+	return es.svc.ListTemplates(ctx, token, offset, limit)
+}
+
+func (es eventStore) UpdateTemplate(ctx context.Context, token string, t bootstrap.Template) error {
+	return es.svc.UpdateTemplate(ctx, token, t)
+}
+
+func (es eventStore) RemoveTemplate(ctx context.Context, token, id string) error {
+	return es.svc.RemoveTemplate(ctx, token, id)
+}
+
+func (es eventStore) Instantiate(ctx context.Context, token, id string, cfg bootstrap.Config, vars map[string]string) (bootstrap.Config, error) {
+	saved, err := es.svc.Instantiate(ctx, token, id, cfg, vars)
+	if err != nil {
+		return saved, err
+	}
+
+	var channels []string
+	for _, ch := range saved.MFChannels {
+		channels = append(channels, ch.ID)
+	}
+
+	ev := createConfigEvent{
+		mfThing:    saved.MFThing,
+		owner:      saved.Owner,
+		name:       saved.Name,
+		mfChannels: channels,
+		externalID: saved.ExternalID,
+		content:    saved.Content,
+		timestamp:  time.Now(),
+	}
+
+	es.add(ctx, ev)
+
+	return saved, nil
+}
+
+func (es eventStore) AddPattern(ctx context.Context, token string, p bootstrap.Pattern) (bootstrap.Pattern, error) {
+	return es.svc.AddPattern(ctx, token, p)
+}
+
+func (es eventStore) ViewPattern(ctx context.Context, token, id string) (bootstrap.Pattern, error) {
+	return es.svc.ViewPattern(ctx, token, id)
+}
+
+func (es eventStore) ListPatterns(ctx context.Context, token string, offset, limit uint64) (bootstrap.PatternsPage, error) {
+	return es.svc.ListPatterns(ctx, token, offset, limit)
+}
+
+func (es eventStore) RemovePattern(ctx context.Context, token, id string) error {
+	return es.svc.RemovePattern(ctx, token, id)
+}
+
+func (es eventStore) RewrapKeys(ctx context.Context) (uint64, error) {
+	return es.svc.RewrapKeys(ctx)
+}
+
 func (es eventStore) List(ctx context.Context, token string, filter bootstrap.Filter, offset, limit uint64) (bootstrap.ConfigsPage, error) {
 	return es.svc.List(ctx, token, filter, offset, limit)
 }
@@ -118,8 +236,8 @@ func (es eventStore) Remove(ctx context.Context, token, id string) error {
 	return nil
 }
 
-func (es eventStore) Bootstrap(ctx context.Context, externalKey, externalID string, secure bool) (bootstrap.Config, error) {
-	cfg, err := es.svc.Bootstrap(ctx, externalKey, externalID, secure)
+func (es eventStore) Bootstrap(ctx context.Context, externalKey, externalID, ip string, secure bool) (bootstrap.Config, error) {
+	cfg, err := es.svc.Bootstrap(ctx, externalKey, externalID, ip, secure)
 
 	ev := bootstrapEvent{
 		externalID: externalID,
@@ -136,6 +254,10 @@ func (es eventStore) Bootstrap(ctx context.Context, externalKey, externalID stri
 	return cfg, err
 }
 
+func (es eventStore) ListAttempts(ctx context.Context, token, id string, offset, limit uint64) (bootstrap.AttemptsPage, error) {
+	return es.svc.ListAttempts(ctx, token, id, offset, limit)
+}
+
 func (es eventStore) ChangeState(ctx context.Context, token, id string, state bootstrap.State) error {
 	if err := es.svc.ChangeState(ctx, token, id, state); err != nil {
 		return err
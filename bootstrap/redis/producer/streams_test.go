@@ -21,6 +21,7 @@ import (
 	"github.com/mainflux/mainflux/bootstrap/mocks"
 	"github.com/mainflux/mainflux/bootstrap/redis/producer"
 	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/pkg/uuid"
 	"github.com/mainflux/mainflux/things"
 	httpapi "github.com/mainflux/mainflux/things/api/things/http"
 	"github.com/stretchr/testify/assert"
@@ -46,7 +47,8 @@ const (
 )
 
 var (
-	encKey = []byte("1234567891011121")
+	encKey    = []byte("1234567891011121")
+	masterKey = []byte("12345678910111213141516171819202")
 
 	channel = bootstrap.Channel{
 		ID:       "1",
@@ -69,7 +71,8 @@ func newService(auth mainflux.AuthServiceClient, url string) bootstrap.Service {
 	}
 
 	sdk := mfsdk.NewSDK(config)
-	return bootstrap.New(auth, configs, sdk, encKey)
+	keyring, _ := bootstrap.NewKeyring(map[uint64][]byte{1: masterKey})
+	return bootstrap.New(auth, configs, mocks.NewTemplatesRepository(), mocks.NewPatternsRepository(), sdk, uuid.NewMock(), keyring, encKey)
 }
 
 func newThingsService(auth mainflux.AuthServiceClient) things.Service {
@@ -447,7 +450,7 @@ func TestBootstrap(t *testing.T) {
 
 	lastID := "0"
 	for _, tc := range cases {
-		_, err := svc.Bootstrap(context.Background(), tc.externalKey, tc.externalID, false)
+		_, err := svc.Bootstrap(context.Background(), tc.externalKey, tc.externalID, "127.0.0.1", false)
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 
 		streams := redisClient.XRead(context.Background(), &redis.XReadArgs{
@@ -42,6 +42,10 @@ var (
 	// ErrBootstrap indicates error in getting bootstrap configuration.
 	ErrBootstrap = errors.New("failed to read bootstrap configuration")
 
+	// ErrImportAborted indicates that the Config isn't imported due to the
+	// failure of another Config imported in the same batch.
+	ErrImportAborted = errors.New("import aborted due to failure of another configuration")
+
 	errAddBootstrap       = errors.New("failed to add bootstrap configuration")
 	errUpdateConnections  = errors.New("failed to update connections")
 	errRemoveBootstrap    = errors.New("failed to remove bootstrap configuration")
@@ -55,6 +59,31 @@ var (
 	errCheckChannels      = errors.New("failed to check if channels exists")
 	errConnectionChannels = errors.New("failed to check channels connections")
 	errUpdateCert         = errors.New("failed to update cert")
+	errListVersions       = errors.New("failed to list bootstrap configuration versions")
+	errRollback           = errors.New("failed to roll back bootstrap configuration")
+	errListAttempts       = errors.New("failed to list bootstrap attempts")
+	errAddTemplate        = errors.New("failed to add bootstrap configuration template")
+	errViewTemplate       = errors.New("failed to view bootstrap configuration template")
+	errListTemplates      = errors.New("failed to list bootstrap configuration templates")
+	errUpdateTemplate     = errors.New("failed to update bootstrap configuration template")
+	errRemoveTemplate     = errors.New("failed to remove bootstrap configuration template")
+	errInstantiate        = errors.New("failed to instantiate bootstrap configuration template")
+	errAddPattern         = errors.New("failed to add bootstrap configuration pattern")
+	errViewPattern        = errors.New("failed to view bootstrap configuration pattern")
+	errListPatterns       = errors.New("failed to list bootstrap configuration patterns")
+	errRemovePattern      = errors.New("failed to remove bootstrap configuration pattern")
+	errProvision          = errors.New("failed to provision thing matching bootstrap configuration pattern")
+	errRewrapKeys         = errors.New("failed to re-encrypt bootstrap configuration keys")
+)
+
+const (
+	// rewrapBatchSize is the number of Configs whose keys are re-encrypted
+	// at once.
+	rewrapBatchSize = 100
+
+	// userKey is the type of the key issued to the user on login, used to
+	// provision the Things on behalf of the Pattern owner.
+	userKey uint32 = 0
 )
 
 var _ Service = (*bootstrapService)(nil)
@@ -63,8 +92,15 @@ var _ Service = (*bootstrapService)(nil)
 // implementation, and all of its decorators (e.g. logging & metrics).
 type Service interface {
 	// Add adds new Thing Config to the user identified by the provided token.
+	// The Config is saved as inactive, unless its state is Active, in which
+	// case the Thing is connected to the Config Channels right away.
 	Add(ctx context.Context, token string, cfg Config) (Config, error)
 
+	// Import adds the Configs to the user identified by the provided token,
+	// either all or none of them, and returns the result for each Config.
+	// The state of the Configs is treated the same way as in Add.
+	Import(ctx context.Context, token string, cfgs []Config) ([]ImportResult, error)
+
 	// View returns Thing Config with given ID belonging to the user identified by the given token.
 	View(ctx context.Context, token, id string) (Config, error)
 
@@ -78,6 +114,58 @@ type Service interface {
 	// UpdateConnections updates list of Channels related to given Config.
 	UpdateConnections(ctx context.Context, token, id string, connections []string) error
 
+	// ListVersions returns subset of the versions of the Config with given
+	// ID, the most recent version first.
+	ListVersions(ctx context.Context, token, id string, offset, limit uint64) (VersionsPage, error)
+
+	// Rollback restores the content, certificates and connections of the
+	// Config with given ID to the ones of the given version. The rollback is
+	// recorded as the new version of the Config.
+	Rollback(ctx context.Context, token, id string, version uint64) error
+
+	// AddTemplate adds new Template to the user identified by the provided token.
+	AddTemplate(ctx context.Context, token string, t Template) (Template, error)
+
+	// ViewTemplate returns Template with given ID belonging to the user
+	// identified by the given token.
+	ViewTemplate(ctx context.Context, token, id string) (Template, error)
+
+	// ListTemplates returns subset of Templates that belong to the user
+	// identified by the given token.
+	ListTemplates(ctx context.Context, token string, offset, limit uint64) (TemplatesPage, error)
+
+	// UpdateTemplate updates the name, Channels and content of the provided
+	// Template. The Configs already instantiated from it are left intact.
+	UpdateTemplate(ctx context.Context, token string, t Template) error
+
+	// RemoveTemplate removes Template with given ID belonging to the user
+	// identified by the given token.
+	RemoveTemplate(ctx context.Context, token, id string) error
+
+	// Instantiate adds new Config the same way as Add, taking its Channels
+	// from the Template with given ID and its content from the Template
+	// content rendered using the provided variables, along with the external
+	// ID and the name of the Config.
+	Instantiate(ctx context.Context, token, id string, cfg Config, vars map[string]string) (Config, error)
+
+	// AddPattern adds new Pattern to the user identified by the provided
+	// token. The Pattern Template has to belong to the same user, and its
+	// content may reference only the built-in variables.
+	AddPattern(ctx context.Context, token string, p Pattern) (Pattern, error)
+
+	// ViewPattern returns Pattern with given ID belonging to the user
+	// identified by the given token.
+	ViewPattern(ctx context.Context, token, id string) (Pattern, error)
+
+	// ListPatterns returns subset of Patterns that belong to the user
+	// identified by the given token.
+	ListPatterns(ctx context.Context, token string, offset, limit uint64) (PatternsPage, error)
+
+	// RemovePattern removes Pattern with given ID belonging to the user
+	// identified by the given token. The Configs already instantiated using
+	// the Pattern are left intact.
+	RemovePattern(ctx context.Context, token, id string) error
+
 	// List returns subset of Configs with given search params that belong to the
 	// user identified by the given token.
 	List(ctx context.Context, token string, filter Filter, offset, limit uint64) (ConfigsPage, error)
@@ -86,11 +174,24 @@ type Service interface {
 	Remove(ctx context.Context, token, id string) error
 
 	// Bootstrap returns Config to the Thing with provided external ID using external key.
-	Bootstrap(ctx context.Context, externalKey, externalID string, secure bool) (Config, error)
+	// If there is no such Config, the Thing matching one of the Patterns is
+	// created along with its Config. Each attempt is recorded along with the
+	// IP address it came from.
+	Bootstrap(ctx context.Context, externalKey, externalID, ip string, secure bool) (Config, error)
+
+	// ListAttempts returns subset of the bootstrap attempts of the Config
+	// with given ID, the most recent attempt first.
+	ListAttempts(ctx context.Context, token, id string, offset, limit uint64) (AttemptsPage, error)
 
 	// ChangeState changes state of the Thing with given ID and owner.
 	ChangeState(ctx context.Context, token, id string, state State) error
 
+	// RewrapKeys re-encrypts the keys of the Configs and Patterns, which are
+	// wrapped by the outdated master keys or not wrapped at all, using the
+	// current master key, and returns the number of re-encrypted keys. The
+	// method is used for key rotation, so it surpasses ownership check.
+	RewrapKeys(ctx context.Context) (uint64, error)
+
 	// Methods RemoveConfig, UpdateChannel, and RemoveChannel are used as
 	// handlers for events. That's why these methods surpass ownership check.
 
@@ -116,20 +217,29 @@ type ConfigReader interface {
 }
 
 type bootstrapService struct {
-	auth    mainflux.AuthServiceClient
-	configs ConfigRepository
-	sdk     mfsdk.SDK
-	encKey  []byte
-	reader  ConfigReader
+	auth       mainflux.AuthServiceClient
+	configs    ConfigRepository
+	templates  TemplateRepository
+	patterns   PatternRepository
+	sdk        mfsdk.SDK
+	idProvider mainflux.IDProvider
+	keyring    Keyring
+	encKey     []byte
+	reader     ConfigReader
 }
 
-// New returns new Bootstrap service.
-func New(auth mainflux.AuthServiceClient, configs ConfigRepository, sdk mfsdk.SDK, encKey []byte) Service {
+// New returns new Bootstrap service. The encryption key is the key used for
+// the secure bootstrap of the new Configs, which is wrapped using the keyring.
+func New(auth mainflux.AuthServiceClient, configs ConfigRepository, templates TemplateRepository, patterns PatternRepository, sdk mfsdk.SDK, idProvider mainflux.IDProvider, keyring Keyring, encKey []byte) Service {
 	return &bootstrapService{
-		configs: configs,
-		sdk:     sdk,
-		auth:    auth,
-		encKey:  encKey,
+		configs:    configs,
+		templates:  templates,
+		patterns:   patterns,
+		sdk:        sdk,
+		idProvider: idProvider,
+		keyring:    keyring,
+		auth:       auth,
+		encKey:     encKey,
 	}
 }
 
@@ -161,8 +271,18 @@ func (bs bootstrapService) Add(ctx context.Context, token string, cfg Config) (C
 
 	cfg.MFThing = mfThing.ID
 	cfg.Owner = owner
-	cfg.State = Inactive
+	cfg.State = initialState(cfg.State)
 	cfg.MFKey = mfThing.Key
+	cfg.KeyVersion = bs.keyring.Current()
+	cfg.EncKey, err = bs.keyring.Wrap(bs.encKey)
+	if err != nil {
+		if id == "" {
+			if errT := bs.sdk.DeleteThing(cfg.MFThing, token); errT != nil {
+				err = errors.Wrap(err, errT)
+			}
+		}
+		return Config{}, errors.Wrap(errAddBootstrap, err)
+	}
 
 	saved, err := bs.configs.Save(cfg, toConnect)
 	if err != nil {
@@ -177,9 +297,145 @@ func (bs bootstrapService) Add(ctx context.Context, token string, cfg Config) (C
 	cfg.MFThing = saved
 	cfg.MFChannels = append(cfg.MFChannels, existing...)
 
+	if cfg.State == Active {
+		if err := bs.activate(token, cfg); err != nil {
+			bs.revert(token, cfg, id == "")
+			return Config{}, errors.Wrap(errAddBootstrap, err)
+		}
+	}
+
 	return cfg, nil
 }
 
+func (bs bootstrapService) Import(ctx context.Context, token string, cfgs []Config) ([]ImportResult, error) {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ImportResult, len(cfgs))
+	saved := make([]Config, len(cfgs))
+	connections := make([][]string, len(cfgs))
+	// Channels fetched for the previous Configs of the batch are saved along
+	// with those Configs, so they are treated as existing.
+	fetched := map[string]Channel{}
+	// Things created by the import are removed if the import fails.
+	var created []string
+	failed := false
+
+	externalIDs := make(map[string]bool, len(cfgs))
+	for i, cfg := range cfgs {
+		if externalIDs[cfg.ExternalID] {
+			results[i].Err = errors.Wrap(errAddBootstrap, ErrConflict)
+			failed = true
+			continue
+		}
+		externalIDs[cfg.ExternalID] = true
+
+		toConnect := bs.toIDList(cfg.MFChannels)
+		existing, err := bs.configs.ListExisting(owner, toConnect)
+		if err != nil {
+			results[i].Err = errors.Wrap(errCheckChannels, err)
+			failed = true
+			continue
+		}
+		for _, id := range toConnect {
+			if ch, ok := fetched[id]; ok {
+				existing = append(existing, ch)
+			}
+		}
+
+		cfg.MFChannels, err = bs.connectionChannels(toConnect, bs.toIDList(existing), token)
+		if err != nil {
+			results[i].Err = errors.Wrap(errConnectionChannels, err)
+			failed = true
+			continue
+		}
+		for _, ch := range cfg.MFChannels {
+			fetched[ch.ID] = ch
+		}
+
+		mfThing, err := bs.thing(token, cfg.MFThing)
+		if err != nil {
+			results[i].Err = errors.Wrap(errAddBootstrap, err)
+			failed = true
+			continue
+		}
+		if cfg.MFThing == "" {
+			created = append(created, mfThing.ID)
+		}
+
+		cfg.MFThing = mfThing.ID
+		cfg.Owner = owner
+		cfg.State = initialState(cfg.State)
+		cfg.MFKey = mfThing.Key
+		cfg.KeyVersion = bs.keyring.Current()
+		cfg.EncKey, err = bs.keyring.Wrap(bs.encKey)
+		if err != nil {
+			results[i].Err = errors.Wrap(errAddBootstrap, err)
+			failed = true
+			continue
+		}
+
+		results[i].Config = cfg
+		results[i].Config.MFChannels = append(cfg.MFChannels, existing...)
+		saved[i] = cfg
+		connections[i] = toConnect
+	}
+
+	if !failed {
+		if errs := bs.configs.SaveAll(saved, connections); errs != nil {
+			for i, err := range errs {
+				if err != nil {
+					results[i].Err = errors.Wrap(errAddBootstrap, err)
+				}
+			}
+			failed = true
+		}
+	}
+
+	// Active Configs are connected only once all the Configs are saved, so
+	// that the failed import leaves no connections behind.
+	var activated []Config
+	for i := range results {
+		if failed {
+			break
+		}
+		if results[i].Config.State != Active {
+			continue
+		}
+		activated = append(activated, results[i].Config)
+		if err := bs.activate(token, results[i].Config); err != nil {
+			results[i].Err = errors.Wrap(errAddBootstrap, err)
+			failed = true
+		}
+	}
+
+	if failed {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = ErrImportAborted
+			}
+		}
+		// Removal of the created Things is the best effort, since the
+		// import failure is already reported.
+		// The Configs are saved only if the activation is attempted.
+		if len(activated) > 0 {
+			for _, cfg := range saved {
+				bs.configs.Remove(owner, cfg.MFThing)
+			}
+		}
+		for _, cfg := range activated {
+			bs.deactivate(token, cfg)
+		}
+		for _, id := range created {
+			bs.sdk.DeleteThing(id, token)
+		}
+	}
+
+	return results, nil
+}
+
 func (bs bootstrapService) View(ctx context.Context, token, id string) (Config, error) {
 	owner, err := bs.identify(token)
 	if err != nil {
@@ -222,8 +478,6 @@ func (bs bootstrapService) UpdateConnections(ctx context.Context, token, id stri
 		return errors.Wrap(errUpdateConnections, err)
 	}
 
-	add, remove := bs.updateList(cfg, connections)
-
 	// Check if channels exist. This is the way to prevent fetching channels that already exist.
 	existing, err := bs.configs.ListExisting(owner, connections)
 	if err != nil {
@@ -235,7 +489,74 @@ func (bs bootstrapService) UpdateConnections(ctx context.Context, token, id stri
 		return errors.Wrap(errUpdateConnections, err)
 	}
 
-	cfg.MFChannels = channels
+	if err := bs.reconnect(token, cfg, connections); err != nil {
+		return err
+	}
+
+	return bs.configs.UpdateConnections(owner, id, channels, connections)
+}
+
+func (bs bootstrapService) ListVersions(ctx context.Context, token, id string, offset, limit uint64) (VersionsPage, error) {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return VersionsPage{}, err
+	}
+
+	if _, err := bs.configs.RetrieveByID(owner, id); err != nil {
+		return VersionsPage{}, errors.Wrap(errListVersions, err)
+	}
+
+	page, err := bs.configs.RetrieveVersions(owner, id, offset, limit)
+	if err != nil {
+		return VersionsPage{}, errors.Wrap(errListVersions, err)
+	}
+
+	return page, nil
+}
+
+func (bs bootstrapService) Rollback(ctx context.Context, token, id string, version uint64) error {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := bs.configs.RetrieveByID(owner, id)
+	if err != nil {
+		return errors.Wrap(errRollback, err)
+	}
+
+	ver, err := bs.configs.RetrieveVersion(owner, id, version)
+	if err != nil {
+		return errors.Wrap(errRollback, err)
+	}
+
+	existing, err := bs.configs.ListExisting(owner, ver.Channels)
+	if err != nil {
+		return errors.Wrap(errRollback, err)
+	}
+
+	// Channels removed in the meantime can't be restored.
+	channels, err := bs.connectionChannels(ver.Channels, bs.toIDList(existing), token)
+	if err != nil {
+		return errors.Wrap(errRollback, err)
+	}
+
+	if err := bs.reconnect(token, cfg, ver.Channels); err != nil {
+		return errors.Wrap(errRollback, err)
+	}
+
+	if err := bs.configs.Restore(owner, id, ver, channels); err != nil {
+		return errors.Wrap(errRollback, err)
+	}
+
+	return nil
+}
+
+// reconnect connects the Thing of the active Config to the Channels from
+// the given list and disconnects it from the other Channels of the Config.
+func (bs bootstrapService) reconnect(token string, cfg Config, connections []string) error {
+	add, remove := bs.updateList(cfg, connections)
+
 	var connect, disconnect []string
 
 	if cfg.State == Active {
@@ -244,7 +565,7 @@ func (bs bootstrapService) UpdateConnections(ctx context.Context, token, id stri
 	}
 
 	for _, c := range disconnect {
-		if err := bs.sdk.DisconnectThing(id, c, token); err != nil {
+		if err := bs.sdk.DisconnectThing(cfg.MFThing, c, token); err != nil {
 			if errors.Contains(err, mfsdk.ErrFailedDisconnect) {
 				continue
 			}
@@ -255,7 +576,7 @@ func (bs bootstrapService) UpdateConnections(ctx context.Context, token, id stri
 	for _, c := range connect {
 		conIDs := mfsdk.ConnectionIDs{
 			ChannelIDs: []string{c},
-			ThingIDs:   []string{id},
+			ThingIDs:   []string{cfg.MFThing},
 		}
 		if err := bs.sdk.Connect(conIDs, token); err != nil {
 			if errors.Contains(err, mfsdk.ErrFailedConnect) {
@@ -265,7 +586,7 @@ func (bs bootstrapService) UpdateConnections(ctx context.Context, token, id stri
 		}
 	}
 
-	return bs.configs.UpdateConnections(owner, id, channels, connections)
+	return nil
 }
 
 func (bs bootstrapService) List(ctx context.Context, token string, filter Filter, offset, limit uint64) (ConfigsPage, error) {
@@ -288,27 +609,434 @@ func (bs bootstrapService) Remove(ctx context.Context, token, id string) error {
 	return nil
 }
 
-func (bs bootstrapService) Bootstrap(ctx context.Context, externalKey, externalID string, secure bool) (Config, error) {
+func (bs bootstrapService) AddTemplate(ctx context.Context, token string, t Template) (Template, error) {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return Template{}, err
+	}
+
+	t.ID, err = bs.idProvider.ID()
+	if err != nil {
+		return Template{}, errors.Wrap(errAddTemplate, err)
+	}
+	t.Owner = owner
+
+	if _, err := bs.templates.Save(t); err != nil {
+		return Template{}, errors.Wrap(errAddTemplate, err)
+	}
+
+	return t, nil
+}
+
+func (bs bootstrapService) ViewTemplate(ctx context.Context, token, id string) (Template, error) {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return Template{}, err
+	}
+
+	t, err := bs.templates.RetrieveByID(owner, id)
+	if err != nil {
+		return Template{}, errors.Wrap(errViewTemplate, err)
+	}
+
+	return t, nil
+}
+
+func (bs bootstrapService) ListTemplates(ctx context.Context, token string, offset, limit uint64) (TemplatesPage, error) {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return TemplatesPage{}, err
+	}
+
+	page, err := bs.templates.RetrieveAll(owner, offset, limit)
+	if err != nil {
+		return TemplatesPage{}, errors.Wrap(errListTemplates, err)
+	}
+
+	return page, nil
+}
+
+func (bs bootstrapService) UpdateTemplate(ctx context.Context, token string, t Template) error {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return err
+	}
+
+	t.Owner = owner
+	if err := bs.templates.Update(t); err != nil {
+		return errors.Wrap(errUpdateTemplate, err)
+	}
+
+	return nil
+}
+
+func (bs bootstrapService) RemoveTemplate(ctx context.Context, token, id string) error {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return err
+	}
+
+	if err := bs.templates.Remove(owner, id); err != nil {
+		return errors.Wrap(errRemoveTemplate, err)
+	}
+
+	return nil
+}
+
+func (bs bootstrapService) Instantiate(ctx context.Context, token, id string, cfg Config, vars map[string]string) (Config, error) {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return Config{}, err
+	}
+
+	t, err := bs.templates.RetrieveByID(owner, id)
+	if err != nil {
+		return Config{}, errors.Wrap(errInstantiate, err)
+	}
+
+	values := map[string]string{}
+	for k, v := range vars {
+		values[k] = v
+	}
+	values[VarExternalID] = cfg.ExternalID
+	values[VarName] = cfg.Name
+
+	cfg.Content, err = t.Render(values)
+	if err != nil {
+		return Config{}, errors.Wrap(errInstantiate, err)
+	}
+
+	cfg.MFChannels = []Channel{}
+	for _, ch := range t.Channels {
+		cfg.MFChannels = append(cfg.MFChannels, Channel{ID: ch})
+	}
+
+	return bs.Add(ctx, token, cfg)
+}
+
+func (bs bootstrapService) AddPattern(ctx context.Context, token string, p Pattern) (Pattern, error) {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return Pattern{}, err
+	}
+
+	if err := p.Validate(); err != nil {
+		return Pattern{}, errors.Wrap(errAddPattern, err)
+	}
+
+	t, err := bs.templates.RetrieveByID(owner, p.TemplateID)
+	if err != nil {
+		return Pattern{}, errors.Wrap(errAddPattern, err)
+	}
+
+	// There is no one to provide the values of other variables when the
+	// Things are provisioned.
+	if _, err := t.Render(map[string]string{VarExternalID: "", VarName: ""}); err != nil {
+		return Pattern{}, errors.Wrap(errAddPattern, err)
+	}
+
+	p.ID, err = bs.idProvider.ID()
+	if err != nil {
+		return Pattern{}, errors.Wrap(errAddPattern, err)
+	}
+	p.Owner = owner
+	p.State = initialState(p.State)
+	p.KeyVersion = bs.keyring.Current()
+	p.EncKey, err = bs.keyring.Wrap(bs.encKey)
+	if err != nil {
+		return Pattern{}, errors.Wrap(errAddPattern, err)
+	}
+
+	if _, err := bs.patterns.Save(p); err != nil {
+		return Pattern{}, errors.Wrap(errAddPattern, err)
+	}
+
+	return p, nil
+}
+
+func (bs bootstrapService) ViewPattern(ctx context.Context, token, id string) (Pattern, error) {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return Pattern{}, err
+	}
+
+	p, err := bs.patterns.RetrieveByID(owner, id)
+	if err != nil {
+		return Pattern{}, errors.Wrap(errViewPattern, err)
+	}
+
+	return p, nil
+}
+
+func (bs bootstrapService) ListPatterns(ctx context.Context, token string, offset, limit uint64) (PatternsPage, error) {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return PatternsPage{}, err
+	}
+
+	page, err := bs.patterns.RetrieveAll(owner, offset, limit)
+	if err != nil {
+		return PatternsPage{}, errors.Wrap(errListPatterns, err)
+	}
+
+	return page, nil
+}
+
+func (bs bootstrapService) RemovePattern(ctx context.Context, token, id string) error {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return err
+	}
+
+	if err := bs.patterns.Remove(owner, id); err != nil {
+		return errors.Wrap(errRemovePattern, err)
+	}
+
+	return nil
+}
+
+func (bs bootstrapService) Bootstrap(ctx context.Context, externalKey, externalID, ip string, secure bool) (Config, error) {
 	cfg, err := bs.configs.RetrieveByExternalID(externalID)
+	if errors.Contains(err, ErrNotFound) {
+		cfg, err = bs.provision(ctx, externalKey, externalID, secure)
+	}
+	if err != nil {
+		bs.saveAttempt(externalID, ip, attemptResult(err))
+		return Config{}, errors.Wrap(ErrBootstrap, err)
+	}
+
+	// The unwrapped key is returned, so that the response can be encrypted
+	// using the same key.
+	cfg.EncKey, err = bs.configKey(cfg)
 	if err != nil {
-		return cfg, errors.Wrap(ErrBootstrap, err)
+		bs.saveAttempt(externalID, ip, AttemptError)
+		return Config{}, errors.Wrap(ErrBootstrap, err)
 	}
+	cfg.KeyVersion = 0
 
 	if secure {
-		dec, err := bs.dec(externalKey)
+		dec, err := bs.dec(cfg.EncKey, externalKey)
 		if err != nil {
+			bs.saveAttempt(externalID, ip, AttemptInvalidKey)
 			return Config{}, errors.Wrap(ErrSecureBootstrap, err)
 		}
 		externalKey = dec
 	}
 
 	if cfg.ExternalKey != externalKey {
+		bs.saveAttempt(externalID, ip, AttemptInvalidKey)
 		return Config{}, errors.Wrap(ErrExternalKeyNotFound, ErrNotFound)
 	}
 
+	bs.saveAttempt(externalID, ip, AttemptSuccess)
 	return cfg, nil
 }
 
+func (bs bootstrapService) ListAttempts(ctx context.Context, token, id string, offset, limit uint64) (AttemptsPage, error) {
+	owner, err := bs.identify(token)
+	if err != nil {
+		return AttemptsPage{}, err
+	}
+
+	cfg, err := bs.configs.RetrieveByID(owner, id)
+	if err != nil {
+		return AttemptsPage{}, errors.Wrap(errListAttempts, err)
+	}
+
+	page, err := bs.configs.RetrieveAttempts(cfg.ExternalID, offset, limit)
+	if err != nil {
+		return AttemptsPage{}, errors.Wrap(errListAttempts, err)
+	}
+
+	return page, nil
+}
+
+// provision creates the Thing matching one of the Patterns, along with its
+// Config, on behalf of the Pattern owner. The external key is checked before
+// anything is created, and the created Config inherits the Pattern key.
+func (bs bootstrapService) provision(ctx context.Context, externalKey, externalID string, secure bool) (Config, error) {
+	patterns, err := bs.patterns.RetrieveByExternalID(externalID)
+	if err != nil {
+		return Config{}, errors.Wrap(errProvision, err)
+	}
+
+	p, ok := matchPattern(patterns, externalID)
+	if !ok {
+		return Config{}, ErrNotFound
+	}
+
+	key, err := bs.patternKey(p)
+	if err != nil {
+		return Config{}, errors.Wrap(errProvision, err)
+	}
+
+	if secure {
+		dec, err := bs.dec(key, externalKey)
+		if err != nil {
+			return Config{}, errors.Wrap(ErrSecureBootstrap, err)
+		}
+		externalKey = dec
+	}
+
+	if p.ExternalKey != externalKey {
+		return Config{}, errors.Wrap(ErrExternalKeyNotFound, ErrNotFound)
+	}
+
+	token, err := bs.auth.Issue(ctx, &mainflux.IssueReq{Email: p.Owner, Type: userKey})
+	if err != nil {
+		return Config{}, errors.Wrap(errProvision, err)
+	}
+
+	cfg := Config{
+		ExternalID:  externalID,
+		ExternalKey: p.ExternalKey,
+		Name:        externalID,
+		State:       p.State,
+	}
+
+	// The Thing bootstrapping concurrently may have been provisioned in
+	// the meantime, in which case its Config is returned.
+	cfg, err = bs.Instantiate(ctx, token.GetValue(), p.TemplateID, cfg, nil)
+	switch {
+	case errors.Contains(err, ErrConflict):
+		return bs.configs.RetrieveByExternalID(externalID)
+	case err != nil:
+		return Config{}, errors.Wrap(errProvision, err)
+	}
+
+	wrapped, err := bs.keyring.Wrap(key)
+	if err != nil {
+		return Config{}, errors.Wrap(errProvision, err)
+	}
+	if err := bs.configs.UpdateKey(cfg.MFThing, wrapped, bs.keyring.Current()); err != nil {
+		return Config{}, errors.Wrap(errProvision, err)
+	}
+
+	return bs.configs.RetrieveByExternalID(externalID)
+}
+
+// attemptResult returns the result of the failed bootstrap attempt.
+func attemptResult(err error) AttemptResult {
+	switch {
+	case errors.Contains(err, ErrExternalKeyNotFound), errors.Contains(err, ErrSecureBootstrap):
+		return AttemptInvalidKey
+	case errors.Contains(err, ErrNotFound):
+		return AttemptNotFound
+	default:
+		return AttemptError
+	}
+}
+
+// saveAttempt records the bootstrap attempt. Bootstrapping doesn't depend on
+// the attempt being recorded, so the error is ignored.
+func (bs bootstrapService) saveAttempt(externalID, ip string, result AttemptResult) {
+	_ = bs.configs.SaveAttempt(Attempt{
+		ExternalID: externalID,
+		IP:         ip,
+		Timestamp:  time.Now(),
+		Result:     result,
+	})
+}
+
+func (bs bootstrapService) RewrapKeys(ctx context.Context) (uint64, error) {
+	count, err := bs.rewrapConfigKeys()
+	if err != nil {
+		return count, err
+	}
+
+	n, err := bs.rewrapPatternKeys()
+	return count + n, err
+}
+
+func (bs bootstrapService) rewrapConfigKeys() (uint64, error) {
+	current := bs.keyring.Current()
+
+	var count uint64
+	after := ""
+	for {
+		cfgs, err := bs.configs.RetrieveOutdatedKeys(current, after, rewrapBatchSize)
+		if err != nil {
+			return count, errors.Wrap(errRewrapKeys, err)
+		}
+
+		for _, cfg := range cfgs {
+			key, err := bs.configKey(cfg)
+			if err != nil {
+				return count, errors.Wrap(errRewrapKeys, err)
+			}
+
+			wrapped, err := bs.keyring.Wrap(key)
+			if err != nil {
+				return count, errors.Wrap(errRewrapKeys, err)
+			}
+
+			if err := bs.configs.UpdateKey(cfg.MFThing, wrapped, current); err != nil {
+				return count, errors.Wrap(errRewrapKeys, err)
+			}
+			count++
+		}
+
+		if uint64(len(cfgs)) < rewrapBatchSize {
+			return count, nil
+		}
+		after = cfgs[len(cfgs)-1].MFThing
+	}
+}
+
+func (bs bootstrapService) rewrapPatternKeys() (uint64, error) {
+	current := bs.keyring.Current()
+
+	var count uint64
+	after := ""
+	for {
+		patterns, err := bs.patterns.RetrieveOutdatedKeys(current, after, rewrapBatchSize)
+		if err != nil {
+			return count, errors.Wrap(errRewrapKeys, err)
+		}
+
+		for _, p := range patterns {
+			key, err := bs.patternKey(p)
+			if err != nil {
+				return count, errors.Wrap(errRewrapKeys, err)
+			}
+
+			wrapped, err := bs.keyring.Wrap(key)
+			if err != nil {
+				return count, errors.Wrap(errRewrapKeys, err)
+			}
+
+			if err := bs.patterns.UpdateKey(p.ID, wrapped, current); err != nil {
+				return count, errors.Wrap(errRewrapKeys, err)
+			}
+			count++
+		}
+
+		if uint64(len(patterns)) < rewrapBatchSize {
+			return count, nil
+		}
+		after = patterns[len(patterns)-1].ID
+	}
+}
+
+// configKey returns the unwrapped key of the Config. The Configs saved
+// before the introduction of the key wrapping use the default key.
+func (bs bootstrapService) configKey(cfg Config) ([]byte, error) {
+	if cfg.KeyVersion == 0 {
+		if len(cfg.EncKey) == 0 {
+			return bs.encKey, nil
+		}
+		return cfg.EncKey, nil
+	}
+
+	return bs.keyring.Unwrap(cfg.KeyVersion, cfg.EncKey)
+}
+
+// patternKey returns the unwrapped key of the Pattern. The Patterns saved
+// before the introduction of the Pattern keys use the default key.
+func (bs bootstrapService) patternKey(p Pattern) ([]byte, error) {
+	return bs.configKey(Config{EncKey: p.EncKey, KeyVersion: p.KeyVersion})
+}
+
 func (bs bootstrapService) ChangeState(ctx context.Context, token, id string, state State) error {
 	owner, err := bs.identify(token)
 	if err != nil {
@@ -326,23 +1054,12 @@ func (bs bootstrapService) ChangeState(ctx context.Context, token, id string, st
 
 	switch state {
 	case Active:
-		for _, c := range cfg.MFChannels {
-			conIDs := mfsdk.ConnectionIDs{
-				ChannelIDs: []string{c.ID},
-				ThingIDs:   []string{cfg.MFThing},
-			}
-			if err := bs.sdk.Connect(conIDs, token); err != nil {
-				return ErrThings
-			}
+		if err := bs.activate(token, cfg); err != nil {
+			return err
 		}
 	case Inactive:
-		for _, c := range cfg.MFChannels {
-			if err := bs.sdk.DisconnectThing(cfg.MFThing, c.ID, token); err != nil {
-				if errors.Contains(err, mfsdk.ErrFailedDisconnect) {
-					continue
-				}
-				return ErrThings
-			}
+		if err := bs.deactivate(token, cfg); err != nil {
+			return err
 		}
 	}
 	if err := bs.configs.ChangeState(owner, id, state); err != nil {
@@ -351,6 +1068,45 @@ func (bs bootstrapService) ChangeState(ctx context.Context, token, id string, st
 	return nil
 }
 
+// activate connects the Config Thing to all the Config Channels.
+func (bs bootstrapService) activate(token string, cfg Config) error {
+	for _, c := range cfg.MFChannels {
+		conIDs := mfsdk.ConnectionIDs{
+			ChannelIDs: []string{c.ID},
+			ThingIDs:   []string{cfg.MFThing},
+		}
+		if err := bs.sdk.Connect(conIDs, token); err != nil {
+			return ErrThings
+		}
+	}
+	return nil
+}
+
+// deactivate disconnects the Config Thing from all the Config Channels.
+func (bs bootstrapService) deactivate(token string, cfg Config) error {
+	for _, c := range cfg.MFChannels {
+		if err := bs.sdk.DisconnectThing(cfg.MFThing, c.ID, token); err != nil {
+			if errors.Contains(err, mfsdk.ErrFailedDisconnect) {
+				continue
+			}
+			return ErrThings
+		}
+	}
+	return nil
+}
+
+// revert removes the Config which failed to activate on creation, along with
+// the Thing if it's created for the Config. The removal is the best effort,
+// since the creation failure is already reported.
+func (bs bootstrapService) revert(token string, cfg Config, created bool) {
+	bs.configs.Remove(cfg.Owner, cfg.MFThing)
+	if created {
+		bs.sdk.DeleteThing(cfg.MFThing, token)
+		return
+	}
+	bs.deactivate(token, cfg)
+}
+
 func (bs bootstrapService) UpdateChannelHandler(ctx context.Context, channel Channel) error {
 	if err := bs.configs.UpdateChannel(channel); err != nil {
 		return errors.Wrap(errUpdateChannel, err)
@@ -487,12 +1243,12 @@ func (bs bootstrapService) toIDList(channels []Channel) []string {
 	return ret
 }
 
-func (bs bootstrapService) dec(in string) (string, error) {
+func (bs bootstrapService) dec(key []byte, in string) (string, error) {
 	ciphertext, err := hex.DecodeString(in)
 	if err != nil {
 		return "", ErrNotFound
 	}
-	block, err := aes.NewCipher(bs.encKey)
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", err
 	}
@@ -23,6 +23,7 @@ import (
 	"github.com/mainflux/mainflux/bootstrap/mocks"
 	"github.com/mainflux/mainflux/pkg/errors"
 	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	mfuuid "github.com/mainflux/mainflux/pkg/uuid"
 	"github.com/mainflux/mainflux/things"
 	httpapi "github.com/mainflux/mainflux/things/api/things/http"
 	"github.com/stretchr/testify/assert"
@@ -35,10 +36,12 @@ const (
 	email        = "test@example.com"
 	unknown      = "unknown"
 	channelsNum  = 3
+	remoteIP     = "127.0.0.1"
 )
 
 var (
-	encKey = []byte("1234567891011121")
+	encKey    = []byte("1234567891011121")
+	masterKey = []byte("12345678910111213141516171819202")
 
 	channel = bootstrap.Channel{
 		ID:       "1",
@@ -52,6 +55,12 @@ var (
 		MFChannels:  []bootstrap.Channel{channel},
 		Content:     "config",
 	}
+
+	template = bootstrap.Template{
+		Name:     "template",
+		Channels: []string{"1", "2"},
+		Content:  `{"id": "{{externalID}}", "name": "{{ name }}", "region": "{{region}}"}`,
+	}
 )
 
 func newService(auth mainflux.AuthServiceClient, url string) bootstrap.Service {
@@ -61,7 +70,8 @@ func newService(auth mainflux.AuthServiceClient, url string) bootstrap.Service {
 	}
 
 	sdk := mfsdk.NewSDK(config)
-	return bootstrap.New(auth, things, sdk, encKey)
+	keyring, _ := bootstrap.NewKeyring(map[uint64][]byte{1: masterKey})
+	return bootstrap.New(auth, things, mocks.NewTemplatesRepository(), mocks.NewPatternsRepository(), sdk, mfuuid.NewMock(), keyring, encKey)
 }
 
 func newThingsService(auth mainflux.AuthServiceClient) things.Service {
@@ -112,6 +122,10 @@ func TestAdd(t *testing.T) {
 	ch.ID = "invalid"
 	wrongChannels.MFChannels = append(wrongChannels.MFChannels, ch)
 
+	active := config
+	active.ExternalID = "active"
+	active.State = bootstrap.Active
+
 	cases := []struct {
 		desc   string
 		config bootstrap.Config
@@ -124,6 +138,12 @@ func TestAdd(t *testing.T) {
 			token:  validToken,
 			err:    nil,
 		},
+		{
+			desc:   "add a new active config",
+			config: active,
+			token:  validToken,
+			err:    nil,
+		},
 		{
 			desc:   "add a config with an invalid ID",
 			config: neID,
@@ -145,11 +165,92 @@ func TestAdd(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		_, err := svc.Add(context.Background(), tc.token, tc.config)
+		saved, err := svc.Add(context.Background(), tc.token, tc.config)
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, tc.config.State, saved.State, fmt.Sprintf("%s: expected state %s got %s\n", tc.desc, tc.config.State, saved.State))
+		}
 	}
 }
 
+func TestImport(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	configs := func(ids ...string) []bootstrap.Config {
+		var cfgs []bootstrap.Config
+		for _, id := range ids {
+			cfg := config
+			cfg.ExternalID = id
+			cfgs = append(cfgs, cfg)
+		}
+		return cfgs
+	}
+
+	wrongChannels := configs("wrong-1", "wrong-2")
+	ch := channel
+	ch.ID = "invalid"
+	wrongChannels[1].MFChannels = append(wrongChannels[1].MFChannels, ch)
+
+	cases := []struct {
+		desc    string
+		configs []bootstrap.Config
+		token   string
+		errs    []error
+		err     error
+	}{
+		{
+			desc:    "import new configs",
+			configs: configs("import-1", "import-2"),
+			token:   validToken,
+			errs:    []error{nil, nil},
+			err:     nil,
+		},
+		{
+			desc:    "import configs with wrong credentials",
+			configs: configs("unauthorized-1"),
+			token:   invalidToken,
+			err:     bootstrap.ErrUnauthorizedAccess,
+		},
+		{
+			desc:    "import configs with duplicate external ID",
+			configs: configs("duplicate", "duplicate"),
+			token:   validToken,
+			errs:    []error{bootstrap.ErrImportAborted, bootstrap.ErrConflict},
+			err:     nil,
+		},
+		{
+			desc:    "import configs with existing external ID",
+			configs: configs("existing", "import-1"),
+			token:   validToken,
+			errs:    []error{bootstrap.ErrImportAborted, bootstrap.ErrConflict},
+			err:     nil,
+		},
+		{
+			desc:    "import configs with invalid list of channels",
+			configs: wrongChannels,
+			token:   validToken,
+			errs:    []error{bootstrap.ErrImportAborted, bootstrap.ErrMalformedEntity},
+			err:     nil,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := svc.Import(context.Background(), tc.token, tc.configs)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		require.Len(t, res, len(tc.errs), fmt.Sprintf("%s: expected %d results got %d\n", tc.desc, len(tc.errs), len(res)))
+		for i, r := range res {
+			assert.True(t, errors.Contains(r.Err, tc.errs[i]), fmt.Sprintf("%s: expected %s got %s for config %d\n", tc.desc, tc.errs[i], r.Err, i))
+		}
+	}
+
+	page, err := svc.List(context.Background(), validToken, bootstrap.Filter{}, 0, 10)
+	require.Nil(t, err, fmt.Sprintf("Listing configs expected to succeed: %s.\n", err))
+	assert.Equal(t, uint64(2), page.Total, fmt.Sprintf("expected 2 imported configs got %d\n", page.Total))
+}
+
 func TestView(t *testing.T) {
 	users := mocks.NewUsersService(map[string]string{validToken: email})
 
@@ -375,6 +476,175 @@ func TestUpdateConnections(t *testing.T) {
 	}
 }
 
+func TestListVersions(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	saved, err := svc.Add(context.Background(), validToken, config)
+	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+	saved.Content = "updated"
+	err = svc.Update(context.Background(), validToken, saved)
+	require.Nil(t, err, fmt.Sprintf("Updating config expected to succeed: %s.\n", err))
+	err = svc.UpdateConnections(context.Background(), validToken, saved.MFThing, []string{"1", "2"})
+	require.Nil(t, err, fmt.Sprintf("Updating connections expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc     string
+		token    string
+		id       string
+		offset   uint64
+		limit    uint64
+		versions []uint64
+		err      error
+	}{
+		{
+			desc:     "list all versions",
+			token:    validToken,
+			id:       saved.MFThing,
+			offset:   0,
+			limit:    10,
+			versions: []uint64{3, 2, 1},
+			err:      nil,
+		},
+		{
+			desc:     "list versions with offset and limit",
+			token:    validToken,
+			id:       saved.MFThing,
+			offset:   1,
+			limit:    1,
+			versions: []uint64{2},
+			err:      nil,
+		},
+		{
+			desc:     "list versions of non-existing config",
+			token:    validToken,
+			id:       unknown,
+			offset:   0,
+			limit:    10,
+			versions: nil,
+			err:      bootstrap.ErrNotFound,
+		},
+		{
+			desc:     "list versions with invalid credentials",
+			token:    invalidToken,
+			id:       saved.MFThing,
+			offset:   0,
+			limit:    10,
+			versions: nil,
+			err:      bootstrap.ErrUnauthorizedAccess,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := svc.ListVersions(context.Background(), tc.token, tc.id, tc.offset, tc.limit)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		var versions []uint64
+		for _, v := range page.Versions {
+			versions = append(versions, v.Version)
+		}
+		assert.Equal(t, tc.versions, versions, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.versions, versions))
+	}
+}
+
+func TestRollback(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	saved, err := svc.Add(context.Background(), validToken, config)
+	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+	err = svc.ChangeState(context.Background(), validToken, saved.MFThing, bootstrap.Active)
+	require.Nil(t, err, fmt.Sprintf("Changing state expected to succeed: %s.\n", err))
+	updated := saved
+	updated.Content = "updated"
+	err = svc.Update(context.Background(), validToken, updated)
+	require.Nil(t, err, fmt.Sprintf("Updating config expected to succeed: %s.\n", err))
+	err = svc.UpdateConnections(context.Background(), validToken, saved.MFThing, []string{"2", "3"})
+	require.Nil(t, err, fmt.Sprintf("Updating connections expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc     string
+		token    string
+		id       string
+		version  uint64
+		content  string
+		channels []string
+		err      error
+	}{
+		{
+			desc:     "roll back to the previous version",
+			token:    validToken,
+			id:       saved.MFThing,
+			version:  2,
+			content:  "updated",
+			channels: []string{"1"},
+			err:      nil,
+		},
+		{
+			desc:     "roll back to the first version",
+			token:    validToken,
+			id:       saved.MFThing,
+			version:  1,
+			content:  config.Content,
+			channels: []string{"1"},
+			err:      nil,
+		},
+		{
+			desc:     "roll back to the version created by the rollback",
+			token:    validToken,
+			id:       saved.MFThing,
+			version:  3,
+			content:  "updated",
+			channels: []string{"2", "3"},
+			err:      nil,
+		},
+		{
+			desc:     "roll back to non-existing version",
+			token:    validToken,
+			id:       saved.MFThing,
+			version:  100,
+			content:  "updated",
+			channels: []string{"2", "3"},
+			err:      bootstrap.ErrNotFound,
+		},
+		{
+			desc:     "roll back non-existing config",
+			token:    validToken,
+			id:       unknown,
+			version:  1,
+			content:  "updated",
+			channels: []string{"2", "3"},
+			err:      bootstrap.ErrNotFound,
+		},
+		{
+			desc:     "roll back with invalid credentials",
+			token:    invalidToken,
+			id:       saved.MFThing,
+			version:  1,
+			content:  "updated",
+			channels: []string{"2", "3"},
+			err:      bootstrap.ErrUnauthorizedAccess,
+		},
+	}
+
+	for _, tc := range cases {
+		err := svc.Rollback(context.Background(), tc.token, tc.id, tc.version)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+
+		cfg, err := svc.View(context.Background(), validToken, saved.MFThing)
+		require.Nil(t, err, fmt.Sprintf("%s: viewing config expected to succeed: %s.\n", tc.desc, err))
+		var channels []string
+		for _, ch := range cfg.MFChannels {
+			channels = append(channels, ch.ID)
+		}
+		assert.Equal(t, tc.content, cfg.Content, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.content, cfg.Content))
+		assert.ElementsMatch(t, tc.channels, channels, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.channels, channels))
+	}
+}
+
 func TestList(t *testing.T) {
 	users := mocks.NewUsersService(map[string]string{validToken: email})
 
@@ -542,6 +812,11 @@ func TestBootstrap(t *testing.T) {
 	e, err := enc([]byte(saved.ExternalKey))
 	require.Nil(t, err, fmt.Sprintf("Encrypting external key expected to succeed: %s.\n", err))
 
+	// Bootstrap returns the unwrapped key of the Config.
+	bootstrapped := saved
+	bootstrapped.EncKey = encKey
+	bootstrapped.KeyVersion = 0
+
 	cases := []struct {
 		desc        string
 		config      bootstrap.Config
@@ -568,7 +843,7 @@ func TestBootstrap(t *testing.T) {
 		},
 		{
 			desc:        "bootstrap an existing config",
-			config:      saved,
+			config:      bootstrapped,
 			externalID:  saved.ExternalID,
 			externalKey: saved.ExternalKey,
 			err:         nil,
@@ -576,7 +851,7 @@ func TestBootstrap(t *testing.T) {
 		},
 		{
 			desc:        "bootstrap encrypted",
-			config:      saved,
+			config:      bootstrapped,
 			externalID:  saved.ExternalID,
 			externalKey: hex.EncodeToString(e),
 			err:         nil,
@@ -585,12 +860,168 @@ func TestBootstrap(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		config, err := svc.Bootstrap(context.Background(), tc.externalKey, tc.externalID, tc.encrypted)
+		config, err := svc.Bootstrap(context.Background(), tc.externalKey, tc.externalID, remoteIP, tc.encrypted)
 		assert.Equal(t, tc.config, config, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.config, config))
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 	}
 }
 
+func TestListAttempts(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	saved, err := svc.Add(context.Background(), validToken, config)
+	require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+
+	_, err = svc.Bootstrap(context.Background(), "invalid", saved.ExternalID, remoteIP, false)
+	require.True(t, errors.Contains(err, bootstrap.ErrNotFound), fmt.Sprintf("Bootstrap with invalid key expected to fail: %s.\n", err))
+	_, err = svc.Bootstrap(context.Background(), saved.ExternalKey, saved.ExternalID, remoteIP, false)
+	require.Nil(t, err, fmt.Sprintf("Bootstrap expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc    string
+		id      string
+		token   string
+		offset  uint64
+		limit   uint64
+		results []bootstrap.AttemptResult
+		err     error
+	}{
+		{
+			desc:    "list all attempts",
+			id:      saved.MFThing,
+			token:   validToken,
+			offset:  0,
+			limit:   10,
+			results: []bootstrap.AttemptResult{bootstrap.AttemptSuccess, bootstrap.AttemptInvalidKey},
+			err:     nil,
+		},
+		{
+			desc:    "list a subset of attempts",
+			id:      saved.MFThing,
+			token:   validToken,
+			offset:  1,
+			limit:   10,
+			results: []bootstrap.AttemptResult{bootstrap.AttemptInvalidKey},
+			err:     nil,
+		},
+		{
+			desc:    "list attempts with invalid token",
+			id:      saved.MFThing,
+			token:   invalidToken,
+			offset:  0,
+			limit:   10,
+			results: nil,
+			err:     bootstrap.ErrUnauthorizedAccess,
+		},
+		{
+			desc:    "list attempts of a non-existing config",
+			id:      unknown,
+			token:   validToken,
+			offset:  0,
+			limit:   10,
+			results: nil,
+			err:     bootstrap.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := svc.ListAttempts(context.Background(), tc.token, tc.id, tc.offset, tc.limit)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+
+		var results []bootstrap.AttemptResult
+		for _, a := range page.Attempts {
+			assert.Equal(t, remoteIP, a.IP, fmt.Sprintf("%s: expected IP %s got %s\n", tc.desc, remoteIP, a.IP))
+			results = append(results, a.Result)
+		}
+		assert.Equal(t, tc.results, results, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.results, results))
+	}
+}
+
+func TestRewrapKeys(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	configs := mocks.NewConfigsRepository()
+	templates := mocks.NewTemplatesRepository()
+	patterns := mocks.NewPatternsRepository()
+	sdk := mfsdk.NewSDK(mfsdk.Config{BaseURL: server.URL})
+	idp := mfuuid.NewMock()
+
+	keyring, err := bootstrap.NewKeyring(map[uint64][]byte{1: masterKey})
+	require.Nil(t, err, fmt.Sprintf("Creating keyring expected to succeed: %s.\n", err))
+	svc := bootstrap.New(users, configs, templates, patterns, sdk, idp, keyring, encKey)
+
+	// More Configs than fit a single batch are saved.
+	numConfigs := 105
+	for i := 0; i < numConfigs; i++ {
+		c := config
+		c.ExternalID = fmt.Sprintf("rewrap-%d", i)
+		c.MFChannels = nil
+		_, err := svc.Add(context.Background(), validToken, c)
+		require.Nil(t, err, fmt.Sprintf("Saving config expected to succeed: %s.\n", err))
+	}
+
+	batch := template
+	batch.Content = `{"id": "{{externalID}}"}`
+	tmpl, err := svc.AddTemplate(context.Background(), validToken, batch)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+	p := bootstrap.Pattern{
+		Type:        bootstrap.PrefixPattern,
+		Expression:  "SN-",
+		ExternalKey: config.ExternalKey,
+		TemplateID:  tmpl.ID,
+	}
+	_, err = svc.AddPattern(context.Background(), validToken, p)
+	require.Nil(t, err, fmt.Sprintf("Saving pattern expected to succeed: %s.\n", err))
+
+	// Both the master key and the key of the new Configs are rotated.
+	rotated, err := bootstrap.NewKeyring(map[uint64][]byte{1: masterKey, 2: []byte("22345678910111213141516171819202")})
+	require.Nil(t, err, fmt.Sprintf("Creating keyring expected to succeed: %s.\n", err))
+	svc = bootstrap.New(users, configs, templates, patterns, sdk, idp, rotated, []byte("2234567891011121"))
+
+	cases := []struct {
+		desc  string
+		count uint64
+	}{
+		{
+			desc:  "re-encrypt keys wrapped by the outdated master key",
+			count: uint64(numConfigs + 1),
+		},
+		{
+			desc:  "re-encrypt keys wrapped by the current master key",
+			count: 0,
+		},
+	}
+
+	for _, tc := range cases {
+		count, err := svc.RewrapKeys(context.Background())
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d\n", tc.desc, tc.count, count))
+	}
+
+	// The outdated master key is no longer needed, while the existing
+	// Things and the Things matching the existing Patterns keep using their
+	// keys.
+	current, err := bootstrap.NewKeyring(map[uint64][]byte{2: []byte("22345678910111213141516171819202")})
+	require.Nil(t, err, fmt.Sprintf("Creating keyring expected to succeed: %s.\n", err))
+	svc = bootstrap.New(users, configs, templates, patterns, sdk, idp, current, []byte("2234567891011121"))
+
+	e, err := enc([]byte(config.ExternalKey))
+	require.Nil(t, err, fmt.Sprintf("Encrypting external key expected to succeed: %s.\n", err))
+	cfg, err := svc.Bootstrap(context.Background(), hex.EncodeToString(e), "rewrap-0", remoteIP, true)
+	assert.Nil(t, err, fmt.Sprintf("Bootstrap with the key of the existing Thing expected to succeed: %s.\n", err))
+	assert.Equal(t, encKey, cfg.EncKey, fmt.Sprintf("expected key %v got %v\n", encKey, cfg.EncKey))
+
+	for i := 0; i < 2; i++ {
+		cfg, err = svc.Bootstrap(context.Background(), hex.EncodeToString(e), "SN-1", remoteIP, true)
+		assert.Nil(t, err, fmt.Sprintf("Bootstrap with the key of the existing Pattern expected to succeed: %s.\n", err))
+		assert.Equal(t, encKey, cfg.EncKey, fmt.Sprintf("expected key %v got %v\n", encKey, cfg.EncKey))
+	}
+}
+
 func TestChangeState(t *testing.T) {
 	users := mocks.NewUsersService(map[string]string{validToken: email})
 
@@ -650,6 +1081,311 @@ func TestChangeState(t *testing.T) {
 	}
 }
 
+func TestAddTemplate(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	cases := []struct {
+		desc     string
+		template bootstrap.Template
+		token    string
+		err      error
+	}{
+		{
+			desc:     "add a new template",
+			template: template,
+			token:    validToken,
+			err:      nil,
+		},
+		{
+			desc:     "add a template with wrong credentials",
+			template: template,
+			token:    invalidToken,
+			err:      bootstrap.ErrUnauthorizedAccess,
+		},
+	}
+
+	for _, tc := range cases {
+		saved, err := svc.AddTemplate(context.Background(), tc.token, tc.template)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.NotEmpty(t, saved.ID, fmt.Sprintf("%s: expected non-empty template ID\n", tc.desc))
+			assert.Equal(t, email, saved.Owner, fmt.Sprintf("%s: expected owner %s got %s\n", tc.desc, email, saved.Owner))
+		}
+	}
+}
+
+func TestViewTemplate(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	saved, err := svc.AddTemplate(context.Background(), validToken, template)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc  string
+		id    string
+		token string
+		err   error
+	}{
+		{
+			desc:  "view an existing template",
+			id:    saved.ID,
+			token: validToken,
+			err:   nil,
+		},
+		{
+			desc:  "view a non-existing template",
+			id:    unknown,
+			token: validToken,
+			err:   bootstrap.ErrNotFound,
+		},
+		{
+			desc:  "view a template with wrong credentials",
+			id:    saved.ID,
+			token: invalidToken,
+			err:   bootstrap.ErrUnauthorizedAccess,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := svc.ViewTemplate(context.Background(), tc.token, tc.id)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, saved, res, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, saved, res))
+		}
+	}
+}
+
+func TestListTemplates(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	numTemplates := 10
+	for i := 0; i < numTemplates; i++ {
+		_, err := svc.AddTemplate(context.Background(), validToken, template)
+		require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+	}
+
+	cases := []struct {
+		desc   string
+		token  string
+		offset uint64
+		limit  uint64
+		size   int
+		err    error
+	}{
+		{
+			desc:   "list all templates",
+			token:  validToken,
+			offset: 0,
+			limit:  uint64(numTemplates),
+			size:   numTemplates,
+			err:    nil,
+		},
+		{
+			desc:   "list the last page of templates",
+			token:  validToken,
+			offset: 8,
+			limit:  5,
+			size:   2,
+			err:    nil,
+		},
+		{
+			desc:   "list templates with wrong credentials",
+			token:  invalidToken,
+			offset: 0,
+			limit:  uint64(numTemplates),
+			size:   0,
+			err:    bootstrap.ErrUnauthorizedAccess,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := svc.ListTemplates(context.Background(), tc.token, tc.offset, tc.limit)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		size := len(page.Templates)
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", tc.desc, tc.size, size))
+	}
+}
+
+func TestUpdateTemplate(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	saved, err := svc.AddTemplate(context.Background(), validToken, template)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	modified := saved
+	modified.Name = "modified"
+	modified.Channels = []string{"2"}
+	modified.Content = "modified {{externalID}}"
+
+	nonExisting := modified
+	nonExisting.ID = unknown
+
+	cases := []struct {
+		desc     string
+		template bootstrap.Template
+		token    string
+		err      error
+	}{
+		{
+			desc:     "update a template with wrong credentials",
+			template: modified,
+			token:    invalidToken,
+			err:      bootstrap.ErrUnauthorizedAccess,
+		},
+		{
+			desc:     "update a non-existing template",
+			template: nonExisting,
+			token:    validToken,
+			err:      bootstrap.ErrNotFound,
+		},
+		{
+			desc:     "update a template",
+			template: modified,
+			token:    validToken,
+			err:      nil,
+		},
+	}
+
+	for _, tc := range cases {
+		err := svc.UpdateTemplate(context.Background(), tc.token, tc.template)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	res, err := svc.ViewTemplate(context.Background(), validToken, saved.ID)
+	require.Nil(t, err, fmt.Sprintf("Retrieving template expected to succeed: %s.\n", err))
+	assert.Equal(t, modified, res, fmt.Sprintf("expected %v got %v\n", modified, res))
+}
+
+func TestRemoveTemplate(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	saved, err := svc.AddTemplate(context.Background(), validToken, template)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc  string
+		id    string
+		token string
+		err   error
+	}{
+		{
+			desc:  "remove a template with wrong credentials",
+			id:    saved.ID,
+			token: invalidToken,
+			err:   bootstrap.ErrUnauthorizedAccess,
+		},
+		{
+			desc:  "remove an existing template",
+			id:    saved.ID,
+			token: validToken,
+			err:   nil,
+		},
+		{
+			desc:  "remove removed template",
+			id:    saved.ID,
+			token: validToken,
+			err:   nil,
+		},
+	}
+
+	for _, tc := range cases {
+		err := svc.RemoveTemplate(context.Background(), tc.token, tc.id)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	saved, err := svc.AddTemplate(context.Background(), validToken, template)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	cfg := bootstrap.Config{
+		ExternalID:  "instantiated",
+		ExternalKey: "external_key",
+		Name:        "name",
+	}
+
+	missingVar := cfg
+	missingVar.ExternalID = "missing"
+
+	cases := []struct {
+		desc    string
+		id      string
+		config  bootstrap.Config
+		vars    map[string]string
+		token   string
+		content string
+		err     error
+	}{
+		{
+			desc:   "instantiate a template with wrong credentials",
+			id:     saved.ID,
+			config: cfg,
+			vars:   map[string]string{"region": "eu"},
+			token:  invalidToken,
+			err:    bootstrap.ErrUnauthorizedAccess,
+		},
+		{
+			desc:   "instantiate a non-existing template",
+			id:     unknown,
+			config: cfg,
+			vars:   map[string]string{"region": "eu"},
+			token:  validToken,
+			err:    bootstrap.ErrNotFound,
+		},
+		{
+			desc:   "instantiate a template with a missing variable",
+			id:     saved.ID,
+			config: missingVar,
+			vars:   map[string]string{},
+			token:  validToken,
+			err:    bootstrap.ErrMalformedEntity,
+		},
+		{
+			desc:    "instantiate a template",
+			id:      saved.ID,
+			config:  cfg,
+			vars:    map[string]string{"region": "eu", bootstrap.VarExternalID: "overridden"},
+			token:   validToken,
+			content: `{"id": "instantiated", "name": "name", "region": "eu"}`,
+			err:     nil,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := svc.Instantiate(context.Background(), tc.token, tc.id, tc.config, tc.vars)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, tc.content, res.Content, fmt.Sprintf("%s: expected content %s got %s\n", tc.desc, tc.content, res.Content))
+		var channels []string
+		for _, ch := range res.MFChannels {
+			channels = append(channels, ch.ID)
+		}
+		assert.ElementsMatch(t, template.Channels, channels, fmt.Sprintf("%s: expected channels %v got %v\n", tc.desc, template.Channels, channels))
+	}
+}
+
 func TestUpdateChannelHandler(t *testing.T) {
 	users := mocks.NewUsersService(map[string]string{validToken: email})
 
@@ -785,3 +1521,212 @@ func TestDisconnectThingsHandler(t *testing.T) {
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 	}
 }
+
+func TestAddPattern(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	batch := template
+	batch.Content = `{"id": "{{externalID}}"}`
+	savedBatch, err := svc.AddTemplate(context.Background(), validToken, batch)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+	savedTmpl, err := svc.AddTemplate(context.Background(), validToken, template)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	p := bootstrap.Pattern{
+		Type:        bootstrap.PrefixPattern,
+		Expression:  "SN-",
+		ExternalKey: "batch_key",
+		TemplateID:  savedBatch.ID,
+	}
+
+	regex := p
+	regex.Type = bootstrap.RegexPattern
+	regex.Expression = "SN-[0-9]+"
+
+	invalidRegex := regex
+	invalidRegex.Expression = "SN-[0-9"
+
+	unknownType := p
+	unknownType.Type = "glob"
+
+	unknownTemplate := p
+	unknownTemplate.TemplateID = unknown
+
+	customVars := p
+	customVars.TemplateID = savedTmpl.ID
+
+	cases := []struct {
+		desc    string
+		pattern bootstrap.Pattern
+		token   string
+		err     error
+	}{
+		{
+			desc:    "add a prefix pattern",
+			pattern: p,
+			token:   validToken,
+			err:     nil,
+		},
+		{
+			desc:    "add a regex pattern",
+			pattern: regex,
+			token:   validToken,
+			err:     nil,
+		},
+		{
+			desc:    "add a pattern with wrong credentials",
+			pattern: p,
+			token:   invalidToken,
+			err:     bootstrap.ErrUnauthorizedAccess,
+		},
+		{
+			desc:    "add a pattern with invalid regex",
+			pattern: invalidRegex,
+			token:   validToken,
+			err:     bootstrap.ErrMalformedEntity,
+		},
+		{
+			desc:    "add a pattern of unknown type",
+			pattern: unknownType,
+			token:   validToken,
+			err:     bootstrap.ErrMalformedEntity,
+		},
+		{
+			desc:    "add a pattern with a non-existing template",
+			pattern: unknownTemplate,
+			token:   validToken,
+			err:     bootstrap.ErrNotFound,
+		},
+		{
+			desc:    "add a pattern with a template using custom variables",
+			pattern: customVars,
+			token:   validToken,
+			err:     bootstrap.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		saved, err := svc.AddPattern(context.Background(), tc.token, tc.pattern)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.NotEmpty(t, saved.ID, fmt.Sprintf("%s: expected non-empty pattern ID\n", tc.desc))
+			assert.Equal(t, email, saved.Owner, fmt.Sprintf("%s: expected owner %s got %s\n", tc.desc, email, saved.Owner))
+		}
+	}
+}
+
+func TestBootstrapPattern(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	server := newThingsServer(newThingsService(users))
+	svc := newService(users, server.URL)
+
+	batch := template
+	batch.Content = `{"id": "{{externalID}}"}`
+	tmpl, err := svc.AddTemplate(context.Background(), validToken, batch)
+	require.Nil(t, err, fmt.Sprintf("Saving template expected to succeed: %s.\n", err))
+
+	patterns := []bootstrap.Pattern{
+		{
+			Type:        bootstrap.RegexPattern,
+			Expression:  "SN-[0-9]+",
+			ExternalKey: "regex_key",
+			TemplateID:  tmpl.ID,
+		},
+		{
+			Type:        bootstrap.PrefixPattern,
+			Expression:  "SN-1",
+			ExternalKey: "prefix_key",
+			TemplateID:  tmpl.ID,
+			State:       bootstrap.Active,
+		},
+	}
+	for _, p := range patterns {
+		_, err := svc.AddPattern(context.Background(), validToken, p)
+		require.Nil(t, err, fmt.Sprintf("Saving pattern expected to succeed: %s.\n", err))
+	}
+
+	e, err := enc([]byte("regex_key"))
+	require.Nil(t, err, fmt.Sprintf("Encrypting external key expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc        string
+		externalID  string
+		externalKey string
+		encrypted   bool
+		state       bootstrap.State
+		err         error
+	}{
+		{
+			desc:        "bootstrap a thing matching no pattern",
+			externalID:  "unmatched",
+			externalKey: "regex_key",
+			state:       bootstrap.Inactive,
+			err:         bootstrap.ErrNotFound,
+		},
+		{
+			desc:        "bootstrap a thing matching the regex only partially",
+			externalID:  "SN-2a",
+			externalKey: "regex_key",
+			state:       bootstrap.Inactive,
+			err:         bootstrap.ErrNotFound,
+		},
+		{
+			desc:        "bootstrap a thing matching a pattern with invalid key",
+			externalID:  "SN-2",
+			externalKey: "invalid",
+			state:       bootstrap.Inactive,
+			err:         bootstrap.ErrNotFound,
+		},
+		{
+			desc:        "bootstrap a thing matching a regex",
+			externalID:  "SN-2",
+			externalKey: "regex_key",
+			state:       bootstrap.Inactive,
+			err:         nil,
+		},
+		{
+			desc:        "bootstrap a thing matching a regex once again",
+			externalID:  "SN-2",
+			externalKey: "regex_key",
+			state:       bootstrap.Inactive,
+			err:         nil,
+		},
+		{
+			desc:        "bootstrap a thing matching a regex using encrypted key",
+			externalID:  "SN-3",
+			externalKey: hex.EncodeToString(e),
+			encrypted:   true,
+			state:       bootstrap.Inactive,
+			err:         nil,
+		},
+		{
+			desc:        "bootstrap a thing matching both a prefix and a regex",
+			externalID:  "SN-10",
+			externalKey: "prefix_key",
+			state:       bootstrap.Active,
+			err:         nil,
+		},
+	}
+
+	for _, tc := range cases {
+		cfg, err := svc.Bootstrap(context.Background(), tc.externalKey, tc.externalID, remoteIP, tc.encrypted)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err != nil {
+			continue
+		}
+
+		content := fmt.Sprintf(`{"id": "%s"}`, tc.externalID)
+		assert.Equal(t, tc.externalID, cfg.Name, fmt.Sprintf("%s: expected name %s got %s\n", tc.desc, tc.externalID, cfg.Name))
+		assert.Equal(t, content, cfg.Content, fmt.Sprintf("%s: expected content %s got %s\n", tc.desc, content, cfg.Content))
+		assert.Equal(t, tc.state, cfg.State, fmt.Sprintf("%s: expected state %d got %d\n", tc.desc, tc.state, cfg.State))
+		assert.Len(t, cfg.MFChannels, len(batch.Channels), fmt.Sprintf("%s: expected %d channels got %d\n", tc.desc, len(batch.Channels), len(cfg.MFChannels)))
+	}
+
+	page, err := svc.List(context.Background(), validToken, bootstrap.Filter{}, 0, 10)
+	require.Nil(t, err, fmt.Sprintf("Listing configs expected to succeed: %s.\n", err))
+	assert.Equal(t, uint64(3), page.Total, fmt.Sprintf("expected %d provisioned configs got %d\n", 3, page.Total))
+}
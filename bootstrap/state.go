@@ -24,3 +24,12 @@ type State int
 func (s State) String() string {
 	return strconv.Itoa(int(s))
 }
+
+// initialState returns the State of the newly created Config. Any State
+// other than Active falls back to Inactive.
+func initialState(s State) State {
+	if s == Active {
+		return Active
+	}
+	return Inactive
+}
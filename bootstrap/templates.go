@@ -0,0 +1,81 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package bootstrap
+
+import "regexp"
+
+// Variables that are always available when the Template is instantiated.
+const (
+	// VarExternalID is replaced by the external ID of the instantiated Config.
+	VarExternalID = "externalID"
+	// VarName is replaced by the name of the instantiated Config.
+	VarName = "name"
+)
+
+var templateVar = regexp.MustCompile(`{{\s*([A-Za-z0-9_]+)\s*}}`)
+
+// Template represents a reusable blueprint for the Configs of homogeneous
+// Things. Channels contains the IDs of the Channels the instantiated Configs
+// are connected to. Content may reference variables written as {{variable}},
+// which are replaced by their values on the instantiation.
+type Template struct {
+	ID       string
+	Owner    string
+	Name     string
+	Channels []string
+	Content  string
+}
+
+// Render returns the Template content with the variables replaced by the
+// provided values. ErrMalformedEntity is returned if the value of any of the
+// referenced variables is missing.
+func (t Template) Render(vars map[string]string) (string, error) {
+	missing := false
+	content := templateVar.ReplaceAllStringFunc(t.Content, func(v string) string {
+		val, ok := vars[templateVar.FindStringSubmatch(v)[1]]
+		if !ok {
+			missing = true
+			return v
+		}
+		return val
+	})
+
+	if missing {
+		return "", ErrMalformedEntity
+	}
+
+	return content, nil
+}
+
+// TemplatesPage contains page related metadata as well as list of Templates
+// that belong to this page.
+type TemplatesPage struct {
+	Total     uint64
+	Offset    uint64
+	Limit     uint64
+	Templates []Template
+}
+
+// TemplateRepository specifies a Template persistence API.
+type TemplateRepository interface {
+	// Save persists the Template. Successful operation is indicated by
+	// non-nil error response.
+	Save(t Template) (string, error)
+
+	// RetrieveByID retrieves the Template having the provided identifier,
+	// that is owned by the specified user.
+	RetrieveByID(owner, id string) (Template, error)
+
+	// RetrieveAll retrieves a subset of Templates that are owned by the
+	// specified user.
+	RetrieveAll(owner string, offset, limit uint64) (TemplatesPage, error)
+
+	// Update updates an existing Template. A non-nil error is returned to
+	// indicate operation failure.
+	Update(t Template) error
+
+	// Remove removes the Template having the provided identifier, that is
+	// owned by the specified user.
+	Remove(owner, id string) error
+}
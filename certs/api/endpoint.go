@@ -5,9 +5,12 @@ package api
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/mainflux/mainflux/certs"
+	"github.com/mainflux/mainflux/pkg/errors"
 )
 
 func issueCert(svc certs.Service) endpoint.Endpoint {
@@ -26,6 +29,7 @@ func issueCert(svc certs.Service) endpoint.Endpoint {
 			CertKey:    res.ClientKey,
 			Cert:       res.ClientCert,
 			CACert:     res.IssuingCA,
+			Expire:     res.Expire,
 		}, nil
 	}
 }
@@ -37,7 +41,15 @@ func listCerts(svc certs.Service) endpoint.Endpoint {
 			return nil, err
 		}
 
-		page, err := svc.ListCerts(ctx, req.token, req.thingID, req.offset, req.limit)
+		pm := certs.PageMetadata{
+			Offset:        req.offset,
+			Limit:         req.limit,
+			ThingID:       req.thingID,
+			ExpiresAfter:  req.expiresAfter,
+			ExpiresBefore: req.expiresBefore,
+			Status:        req.status,
+		}
+		page, err := svc.ListCerts(ctx, req.token, pm)
 		if err != nil {
 			return certsPageRes{}, err
 		}
@@ -57,6 +69,12 @@ func listCerts(svc certs.Service) endpoint.Endpoint {
 				CertKey:    cert.ClientKey,
 				Cert:       cert.ClientCert,
 				CACert:     cert.IssuingCA,
+				Expire:     cert.Expire,
+				Revoked:    !cert.RevocationTime.IsZero(),
+			}
+			if view.Revoked {
+				revoked := cert.RevocationTime
+				view.RevocationTime = &revoked
 			}
 			res.Certs = append(res.Certs, view)
 		}
@@ -73,3 +91,97 @@ func revokeCert(svc certs.Service) endpoint.Endpoint {
 		return svc.RevokeCert(ctx, req.token, req.certID)
 	}
 }
+
+func crl(svc certs.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		crl, err := svc.CRL(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return derRes{contentType: crlContentType, body: crl}, nil
+	}
+}
+
+func ocsp(svc certs.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ocspReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		res, err := svc.OCSP(ctx, req.request)
+		if err != nil {
+			return nil, err
+		}
+
+		return derRes{contentType: ocspResContentType, body: res}, nil
+	}
+}
+
+func caCerts(svc certs.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		ca, err := svc.CACert(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := certsOnly(ca)
+		if err != nil {
+			return nil, err
+		}
+
+		return estRes{contentType: pkcs7ContentType, body: body}, nil
+	}
+}
+
+func enroll(svc certs.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(enrollReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		c, err := svc.Enroll(ctx, req.token, req.csr)
+		if err != nil {
+			return nil, err
+		}
+
+		return enrolledRes(c)
+	}
+}
+
+func reenroll(svc certs.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(enrollReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		c, err := svc.Reenroll(ctx, req.token, req.csr)
+		if err != nil {
+			return nil, err
+		}
+
+		return enrolledRes(c)
+	}
+}
+
+func enrolledRes(c certs.Cert) (estRes, error) {
+	block, _ := pem.Decode([]byte(c.ClientCert))
+	if block == nil {
+		return estRes{}, errMalformedCert
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return estRes{}, errors.Wrap(errMalformedCert, err)
+	}
+
+	body, err := certsOnly(cert)
+	if err != nil {
+		return estRes{}, err
+	}
+
+	return estRes{contentType: pkcs7CertsContentType, body: body}, nil
+}
@@ -5,6 +5,7 @@ package api
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"time"
 
@@ -37,9 +38,9 @@ func (lm *loggingMiddleware) IssueCert(ctx context.Context, token, thingID, days
 	return lm.svc.IssueCert(ctx, token, thingID, daysValid, keyBits, keyType)
 }
 
-func (lm *loggingMiddleware) ListCerts(ctx context.Context, token, thingID string, offset, limit uint64) (cp certs.Page, err error) {
+func (lm *loggingMiddleware) ListCerts(ctx context.Context, token string, pm certs.PageMetadata) (cp certs.Page, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("Method list_certs for token: %s and thing id: %s took %s to complete", token, thingID, time.Since(begin))
+		message := fmt.Sprintf("Method list_certs for token: %s and thing id: %s took %s to complete", token, pm.ThingID, time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -47,7 +48,7 @@ func (lm *loggingMiddleware) ListCerts(ctx context.Context, token, thingID strin
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.ListCerts(ctx, token, thingID, offset, limit)
+	return lm.svc.ListCerts(ctx, token, pm)
 }
 
 func (lm *loggingMiddleware) RevokeCert(ctx context.Context, token, thingID string) (c certs.Revoke, err error) {
@@ -62,3 +63,94 @@ func (lm *loggingMiddleware) RevokeCert(ctx context.Context, token, thingID stri
 
 	return lm.svc.RevokeCert(ctx, token, thingID)
 }
+
+func (lm *loggingMiddleware) RenewCerts(ctx context.Context) (r []certs.Renewal, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method renew_certs renewed %d certs and took %s to complete", len(r), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.RenewCerts(ctx)
+}
+
+func (lm *loggingMiddleware) NotifyCerts(ctx context.Context) (n []certs.Notification, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method notify_certs sent %d notifications and took %s to complete", len(n), time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.NotifyCerts(ctx)
+}
+
+func (lm *loggingMiddleware) CRL(ctx context.Context) (crl []byte, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method crl took %s to complete", time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.CRL(ctx)
+}
+
+func (lm *loggingMiddleware) OCSP(ctx context.Context, request []byte) (res []byte, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method ocsp took %s to complete", time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.OCSP(ctx, request)
+}
+
+func (lm *loggingMiddleware) CACert(ctx context.Context) (ca *x509.Certificate, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method ca_cert took %s to complete", time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.CACert(ctx)
+}
+
+func (lm *loggingMiddleware) Enroll(ctx context.Context, token string, csr []byte) (c certs.Cert, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method enroll for token: %s and thing: %s took %s to complete", token, c.ThingID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Enroll(ctx, token, csr)
+}
+
+func (lm *loggingMiddleware) Reenroll(ctx context.Context, token string, csr []byte) (c certs.Cert, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method reenroll for token: %s and thing: %s took %s to complete", token, c.ThingID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Reenroll(ctx, token, csr)
+}
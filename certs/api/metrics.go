@@ -5,6 +5,7 @@ package api
 
 import (
 	"context"
+	"crypto/x509"
 	"time"
 
 	"github.com/go-kit/kit/metrics"
@@ -37,13 +38,13 @@ func (ms *metricsMiddleware) IssueCert(ctx context.Context, token, thingID strin
 	return ms.svc.IssueCert(ctx, token, thingID, daysValid, keyBits, keyType)
 }
 
-func (ms *metricsMiddleware) ListCerts(ctx context.Context, token, thingID string, offset, limit uint64) (certs.Page, error) {
+func (ms *metricsMiddleware) ListCerts(ctx context.Context, token string, pm certs.PageMetadata) (certs.Page, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "list_certs").Add(1)
 		ms.latency.With("method", "list_certs").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.ListCerts(ctx, token, thingID, offset, limit)
+	return ms.svc.ListCerts(ctx, token, pm)
 }
 
 func (ms *metricsMiddleware) RevokeCert(ctx context.Context, token, thingID string) (certs.Revoke, error) {
@@ -54,3 +55,66 @@ func (ms *metricsMiddleware) RevokeCert(ctx context.Context, token, thingID stri
 
 	return ms.svc.RevokeCert(ctx, token, thingID)
 }
+
+func (ms *metricsMiddleware) RenewCerts(ctx context.Context) ([]certs.Renewal, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "renew_certs").Add(1)
+		ms.latency.With("method", "renew_certs").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.RenewCerts(ctx)
+}
+
+func (ms *metricsMiddleware) NotifyCerts(ctx context.Context) ([]certs.Notification, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "notify_certs").Add(1)
+		ms.latency.With("method", "notify_certs").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.NotifyCerts(ctx)
+}
+
+func (ms *metricsMiddleware) CRL(ctx context.Context) ([]byte, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "crl").Add(1)
+		ms.latency.With("method", "crl").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.CRL(ctx)
+}
+
+func (ms *metricsMiddleware) OCSP(ctx context.Context, request []byte) ([]byte, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "ocsp").Add(1)
+		ms.latency.With("method", "ocsp").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.OCSP(ctx, request)
+}
+
+func (ms *metricsMiddleware) CACert(ctx context.Context) (*x509.Certificate, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "ca_cert").Add(1)
+		ms.latency.With("method", "ca_cert").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.CACert(ctx)
+}
+
+func (ms *metricsMiddleware) Enroll(ctx context.Context, token string, csr []byte) (certs.Cert, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "enroll").Add(1)
+		ms.latency.With("method", "enroll").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.Enroll(ctx, token, csr)
+}
+
+func (ms *metricsMiddleware) Reenroll(ctx context.Context, token string, csr []byte) (certs.Cert, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "reenroll").Add(1)
+		ms.latency.With("method", "reenroll").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.Reenroll(ctx, token, csr)
+}
@@ -3,7 +3,12 @@
 
 package api
 
-import "github.com/mainflux/mainflux/certs"
+import (
+	"time"
+
+	"github.com/mainflux/mainflux/certs"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
 
 const maxLimitSize = 100
 
@@ -19,14 +24,35 @@ func (req addCertsReq) validate() error {
 	if req.ThingID == "" && req.token == "" {
 		return errUnauthorized
 	}
+	if req.KeyBits < 0 {
+		return errors.ErrMalformedEntity
+	}
+
+	switch req.KeyType {
+	case "", "rsa":
+	case "ec":
+		if req.KeyBits != 0 && req.KeyBits != 256 && req.KeyBits != 384 {
+			return errors.ErrMalformedEntity
+		}
+	case "ed25519":
+		if req.KeyBits != 0 {
+			return errors.ErrMalformedEntity
+		}
+	default:
+		return errors.ErrMalformedEntity
+	}
+
 	return nil
 }
 
 type listReq struct {
-	thingID string
-	token   string
-	offset  uint64
-	limit   uint64
+	token         string
+	thingID       string
+	expiresAfter  time.Time
+	expiresBefore time.Time
+	status        string
+	offset        uint64
+	limit         uint64
 }
 
 func (req *listReq) validate() error {
@@ -36,6 +62,17 @@ func (req *listReq) validate() error {
 	if req.limit == 0 || req.limit > maxLimitSize {
 		return certs.ErrMalformedEntity
 	}
+
+	switch req.status {
+	case "", certs.ActiveStatus, certs.RevokedStatus, certs.AllStatus:
+	default:
+		return certs.ErrMalformedEntity
+	}
+
+	if !req.expiresAfter.IsZero() && !req.expiresBefore.IsZero() && !req.expiresAfter.Before(req.expiresBefore) {
+		return certs.ErrMalformedEntity
+	}
+
 	return nil
 }
 
@@ -51,3 +88,35 @@ func (req *revokeReq) validate() error {
 
 	return nil
 }
+
+type crlReq struct{}
+
+type ocspReq struct {
+	request []byte
+}
+
+func (req ocspReq) validate() error {
+	if len(req.request) == 0 {
+		return errors.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type caCertsReq struct{}
+
+type enrollReq struct {
+	token string
+	csr   []byte
+}
+
+func (req enrollReq) validate() error {
+	if req.token == "" {
+		return certs.ErrUnauthorizedAccess
+	}
+	if len(req.csr) == 0 {
+		return errors.ErrMalformedEntity
+	}
+
+	return nil
+}
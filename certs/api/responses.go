@@ -5,6 +5,7 @@ package api
 
 import (
 	"net/http"
+	"time"
 )
 
 type pageRes struct {
@@ -19,15 +20,18 @@ type certsPageRes struct {
 }
 
 type certsRes struct {
-	ThingID    string `json:"thing_id"`
-	Cert       string `json:"cert"`
-	CertKey    string `json:"cert_key"`
-	CertSerial string `json:"cert_serial"`
-	CACert     string `json:"ca_cert"`
+	ThingID        string     `json:"thing_id"`
+	Cert           string     `json:"cert"`
+	CertKey        string     `json:"cert_key"`
+	CertSerial     string     `json:"cert_serial"`
+	CACert         string     `json:"ca_cert"`
+	Expire         time.Time  `json:"expire"`
+	Revoked        bool       `json:"revoked"`
+	RevocationTime *time.Time `json:"revocation_time,omitempty"`
 }
 
 func (res certsPageRes) Code() int {
-	return http.StatusCreated
+	return http.StatusOK
 }
 
 func (res certsPageRes) Headers() map[string]string {
@@ -49,3 +53,15 @@ func (res certsRes) Headers() map[string]string {
 func (res certsRes) Empty() bool {
 	return false
 }
+
+// derRes is DER encoded CRL or OCSP response, sent as is.
+type derRes struct {
+	contentType string
+	body        []byte
+}
+
+// estRes is DER encoded PKCS#7 EST response, sent base64 encoded.
+type estRes struct {
+	contentType string
+	body        []byte
+}
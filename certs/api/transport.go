@@ -5,9 +5,13 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
 
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/go-zoo/bone"
@@ -24,11 +28,27 @@ const (
 	limitKey    = "limit"
 	defOffset   = 0
 	defLimit    = 10
+
+	thingKey         = "thing_id"
+	expiresAfterKey  = "expires_after"
+	expiresBeforeKey = "expires_before"
+	statusKey        = "status"
+
+	crlContentType     = "application/pkix-crl"
+	ocspReqContentType = "application/ocsp-request"
+	ocspResContentType = "application/ocsp-response"
+	maxOCSPReqSize     = 10000
+
+	pkcs10ContentType     = "application/pkcs10"
+	pkcs7ContentType      = "application/pkcs7-mime"
+	pkcs7CertsContentType = "application/pkcs7-mime; smime-type=certs-only"
+	maxCSRSize            = 100000
 )
 
 var (
-	errUnauthorized = errors.New("missing or invalid credentials provided")
-	errConflict     = errors.New("entity already exists")
+	errUnauthorized  = errors.New("missing or invalid credentials provided")
+	errConflict      = errors.New("entity already exists")
+	errMalformedCert = errors.New("malformed certificate")
 )
 
 // MakeHandler returns a HTTP handler for API endpoints.
@@ -46,6 +66,13 @@ func MakeHandler(svc certs.Service) http.Handler {
 		opts...,
 	))
 
+	r.Get("/certs", kithttp.NewServer(
+		listCerts(svc),
+		decodeListCerts,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Get("/certs/:thingId", kithttp.NewServer(
 		listCerts(svc),
 		decodeListCerts,
@@ -60,6 +87,52 @@ func MakeHandler(svc certs.Service) http.Handler {
 		opts...,
 	))
 
+	r.Get("/crl", kithttp.NewServer(
+		crl(svc),
+		decodeCRL,
+		encodeDER,
+		opts...,
+	))
+
+	r.Post("/ocsp", kithttp.NewServer(
+		ocsp(svc),
+		decodeOCSP,
+		encodeDER,
+		opts...,
+	))
+
+	r.Get("/ocsp/:request", kithttp.NewServer(
+		ocsp(svc),
+		decodeOCSPURL,
+		encodeDER,
+		opts...,
+	))
+
+	estOpts := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(encodeESTError),
+	}
+
+	r.Get("/.well-known/est/cacerts", kithttp.NewServer(
+		caCerts(svc),
+		decodeCACerts,
+		encodeEST,
+		estOpts...,
+	))
+
+	r.Post("/.well-known/est/simpleenroll", kithttp.NewServer(
+		enroll(svc),
+		decodeEnroll,
+		encodeEST,
+		estOpts...,
+	))
+
+	r.Post("/.well-known/est/simplereenroll", kithttp.NewServer(
+		reenroll(svc),
+		decodeEnroll,
+		encodeEST,
+		estOpts...,
+	))
+
 	r.Handle("/metrics", promhttp.Handler())
 	r.GetFunc("/version", mainflux.Version("certs"))
 
@@ -84,6 +157,21 @@ func encodeResponse(_ context.Context, w http.ResponseWriter, response interface
 	return json.NewEncoder(w).Encode(response)
 }
 
+func encodeDER(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(derRes)
+	w.Header().Set("Content-Type", res.contentType)
+	_, err := w.Write(res.body)
+	return err
+}
+
+func encodeEST(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(estRes)
+	w.Header().Set("Content-Type", res.contentType)
+	w.Header().Set("Content-Transfer-Encoding", "base64")
+	_, err := w.Write([]byte(base64.StdEncoding.EncodeToString(res.body)))
+	return err
+}
+
 func decodeListCerts(_ context.Context, r *http.Request) (interface{}, error) {
 	l, err := httputil.ReadUintQuery(r, limitKey, defLimit)
 	if err != nil {
@@ -93,15 +181,53 @@ func decodeListCerts(_ context.Context, r *http.Request) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	thingID := bone.GetValue(r, "thingId")
+	if thingID == "" {
+		if thingID, err = httputil.ReadStringQuery(r, thingKey, ""); err != nil {
+			return nil, err
+		}
+	}
+	after, err := readTimeQuery(r, expiresAfterKey)
+	if err != nil {
+		return nil, err
+	}
+	before, err := readTimeQuery(r, expiresBeforeKey)
+	if err != nil {
+		return nil, err
+	}
+	status, err := httputil.ReadStringQuery(r, statusKey, "")
+	if err != nil {
+		return nil, err
+	}
+
 	req := listReq{
-		token:   r.Header.Get("Authorization"),
-		thingID: bone.GetValue(r, "thingId"),
-		limit:   l,
-		offset:  o,
+		token:         r.Header.Get("Authorization"),
+		thingID:       thingID,
+		expiresAfter:  after,
+		expiresBefore: before,
+		status:        status,
+		limit:         l,
+		offset:        o,
 	}
 	return req, nil
 }
 
+// readTimeQuery reads RFC3339 formatted time query parameter, returning zero
+// time if it's missing.
+func readTimeQuery(r *http.Request, key string) (time.Time, error) {
+	s, err := httputil.ReadStringQuery(r, key, "")
+	if err != nil || s == "" {
+		return time.Time{}, err
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, errors.ErrInvalidQueryParams
+	}
+
+	return t, nil
+}
+
 func decodeCerts(_ context.Context, r *http.Request) (interface{}, error) {
 	if r.Header.Get("Content-Type") != contentType {
 		return nil, errors.ErrUnsupportedContentType
@@ -124,13 +250,90 @@ func decodeRevokeCerts(_ context.Context, r *http.Request) (interface{}, error)
 	return req, nil
 }
 
+func decodeCRL(_ context.Context, r *http.Request) (interface{}, error) {
+	return crlReq{}, nil
+}
+
+func decodeOCSP(_ context.Context, r *http.Request) (interface{}, error) {
+	if r.Header.Get("Content-Type") != ocspReqContentType {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxOCSPReqSize))
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrMalformedEntity, err)
+	}
+
+	return ocspReq{request: body}, nil
+}
+
+// decodeOCSPURL decodes base64 encoded OCSP request from the URL. Path
+// value is unescaped as a query, so the unescaped pluses are restored.
+func decodeOCSPURL(_ context.Context, r *http.Request) (interface{}, error) {
+	value := strings.Replace(bone.GetValue(r, "request"), " ", "+", -1)
+	req, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.ErrMalformedEntity
+	}
+
+	return ocspReq{request: req}, nil
+}
+
+func decodeCACerts(_ context.Context, r *http.Request) (interface{}, error) {
+	return caCertsReq{}, nil
+}
+
+// decodeEnroll decodes base64 encoded certificate request. EST clients
+// authenticate using HTTP basic authentication, with the token as password.
+func decodeEnroll(_ context.Context, r *http.Request) (interface{}, error) {
+	if r.Header.Get("Content-Type") != pkcs10ContentType {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	token := r.Header.Get("Authorization")
+	if _, pass, ok := r.BasicAuth(); ok {
+		token = pass
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxCSRSize))
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrMalformedEntity, err)
+	}
+
+	csr, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(body)), ""))
+	if err != nil {
+		return nil, errors.ErrMalformedEntity
+	}
+
+	return enrollReq{token: token, csr: csr}, nil
+}
+
+func encodeESTError(_ context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain")
+
+	switch {
+	case errors.Contains(err, certs.ErrUnauthorizedAccess):
+		w.Header().Set("WWW-Authenticate", `Basic realm="mainflux"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	case errors.Contains(err, errors.ErrUnsupportedContentType):
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	case errors.Contains(err, errors.ErrMalformedEntity),
+		errors.Contains(err, certs.ErrMalformedEntity):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Contains(err, certs.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", contentType)
 
 	switch err {
 	case errors.ErrUnsupportedContentType:
 		w.WriteHeader(http.StatusUnsupportedMediaType)
-	case io.EOF, errors.ErrMalformedEntity,
+	case io.EOF, errors.ErrMalformedEntity, certs.ErrMalformedEntity,
 		errors.ErrInvalidQueryParams:
 		w.WriteHeader(http.StatusBadRequest)
 	case errConflict:
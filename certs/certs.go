@@ -3,7 +3,31 @@
 
 package certs
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+const (
+	// ActiveStatus filters the certificates that aren't revoked.
+	ActiveStatus = "active"
+	// RevokedStatus filters the revoked certificates.
+	RevokedStatus = "revoked"
+	// AllStatus filters both active and revoked certificates.
+	AllStatus = "all"
+)
+
+// PageMetadata contains the page and the filters of the listed certificates.
+// Empty thing ID and zero expiry bounds don't filter the certificates, while
+// empty status stands for the active ones.
+type PageMetadata struct {
+	Offset        uint64
+	Limit         uint64
+	ThingID       string
+	ExpiresAfter  time.Time
+	ExpiresBefore time.Time
+	Status        string
+}
 
 // ConfigsPage contains page related metadata as well as list
 type Page struct {
@@ -18,12 +42,31 @@ type Repository interface {
 	// Save  saves cert for thing into database
 	Save(ctx context.Context, cert Cert) (string, error)
 
-	// RetrieveAll retrieve all issued certificates for given owner and thing id
-	RetrieveAll(ctx context.Context, ownerID, thingID string, offset, limit uint64) (Page, error)
+	// RetrieveAll retrieves the subset of issued certificates of given owner
+	// matching the filters, the ones expiring first coming first
+	RetrieveAll(ctx context.Context, ownerID string, pm PageMetadata) (Page, error)
 
 	// Remove certificate from DB for given thing
 	Remove(ctx context.Context, thingID string) error
 
 	// RetrieveByThing certificate by given thing
 	RetrieveByThing(ctx context.Context, thingID string) (Cert, error)
+
+	// RetrieveExpiring retrieves a subset of certificates expiring before the
+	// given time, the ones expiring first coming first
+	RetrieveExpiring(ctx context.Context, before time.Time, offset, limit uint64) ([]Cert, error)
+
+	// UpdateNotified updates the lowest threshold the owner of the certificate
+	// with given serial was notified about
+	UpdateNotified(ctx context.Context, serial string, threshold time.Duration) error
+
+	// SaveRevocation saves revoked certificate
+	SaveRevocation(ctx context.Context, r Revocation) error
+
+	// RetrieveRevocation retrieves revoked certificate by given serial
+	RetrieveRevocation(ctx context.Context, serial string) (Revocation, error)
+
+	// RetrieveRevocations retrieves revoked certificates that haven't
+	// expired yet
+	RetrieveRevocations(ctx context.Context) ([]Revocation, error)
 }
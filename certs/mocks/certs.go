@@ -5,7 +5,9 @@ package mocks
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/mainflux/mainflux/certs"
 )
@@ -14,9 +16,9 @@ var _ certs.Repository = (*certsRepoMock)(nil)
 
 type certsRepoMock struct {
 	mu             sync.Mutex
-	counter        uint64
 	certs          map[string]certs.Cert
 	certsByThingID map[string]certs.Cert
+	revocations    map[string]certs.Revocation
 }
 
 // NewCertsRepository creates in-memory certs repository.
@@ -24,6 +26,7 @@ func NewCertsRepository() certs.Repository {
 	return &certsRepoMock{
 		certs:          make(map[string]certs.Cert),
 		certsByThingID: make(map[string]certs.Cert),
+		revocations:    make(map[string]certs.Revocation),
 	}
 }
 
@@ -32,38 +35,74 @@ func (c *certsRepoMock) Save(ctx context.Context, cert certs.Cert) (string, erro
 	defer c.mu.Unlock()
 	c.certs[cert.Serial] = cert
 	c.certsByThingID[cert.ThingID] = cert
-	c.counter++
 	return cert.Serial, nil
 }
 
-func (c *certsRepoMock) RetrieveAll(ctx context.Context, ownerID, thingID string, offset, limit uint64) (certs.Page, error) {
+func (c *certsRepoMock) RetrieveAll(ctx context.Context, ownerID string, pm certs.PageMetadata) (certs.Page, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if limit <= 0 {
-		return certs.Page{}, nil
-	}
-
-	first := offset + 1
-	last := first + limit
 
-	var crts []certs.Cert
-	i := uint64(1)
-	for _, v := range c.certs {
-		if i >= first && i < last {
-			crts = append(crts, v)
+	crts := []certs.Cert{}
+	if pm.Status != certs.RevokedStatus {
+		for _, crt := range c.certs {
+			if crt.OwnerID == ownerID && match(crt, pm) {
+				crts = append(crts, crt)
+			}
+		}
+	}
+	if pm.Status == certs.RevokedStatus || pm.Status == certs.AllStatus {
+		for _, r := range c.revocations {
+			crt := certs.Cert{
+				ThingID:        r.ThingID,
+				OwnerID:        r.OwnerID,
+				Serial:         r.Serial,
+				Expire:         r.Expire,
+				RevocationTime: r.RevocationTime,
+			}
+			if crt.OwnerID == ownerID && match(crt, pm) {
+				crts = append(crts, crt)
+			}
 		}
-		i++
 	}
 
+	sort.SliceStable(crts, func(i, j int) bool {
+		if crts[i].Expire.Equal(crts[j].Expire) {
+			return crts[i].Serial < crts[j].Serial
+		}
+		return crts[i].Expire.Before(crts[j].Expire)
+	})
+
+	total := uint64(len(crts))
 	page := certs.Page{
-		Certs:  crts,
-		Total:  c.counter,
-		Offset: offset,
-		Limit:  limit,
+		Certs:  []certs.Cert{},
+		Total:  total,
+		Offset: pm.Offset,
+		Limit:  pm.Limit,
+	}
+	if pm.Offset < total {
+		end := pm.Offset + pm.Limit
+		if end > total {
+			end = total
+		}
+		page.Certs = crts[pm.Offset:end]
 	}
+
 	return page, nil
 }
 
+func match(c certs.Cert, pm certs.PageMetadata) bool {
+	switch {
+	case pm.ThingID != "" && c.ThingID != pm.ThingID:
+		return false
+	case !pm.ExpiresAfter.IsZero() && c.Expire.Before(pm.ExpiresAfter):
+		return false
+	case !pm.ExpiresBefore.IsZero() && !c.Expire.Before(pm.ExpiresBefore):
+		return false
+	default:
+		return true
+	}
+}
+
 func (c *certsRepoMock) Remove(ctx context.Context, serial string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -72,7 +111,9 @@ func (c *certsRepoMock) Remove(ctx context.Context, serial string) error {
 		return certs.ErrNotFound
 	}
 	delete(c.certs, crt.Serial)
-	delete(c.certsByThingID, crt.ThingID)
+	if c.certsByThingID[crt.ThingID].Serial == crt.Serial {
+		delete(c.certsByThingID, crt.ThingID)
+	}
 	return nil
 }
 
@@ -85,3 +126,82 @@ func (c *certsRepoMock) RetrieveByThing(ctx context.Context, thingID string) (ce
 	}
 	return crt, nil
 }
+
+func (c *certsRepoMock) RetrieveExpiring(ctx context.Context, before time.Time, offset, limit uint64) ([]certs.Cert, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	crts := []certs.Cert{}
+	for _, crt := range c.certs {
+		if crt.Expire.Before(before) {
+			crts = append(crts, crt)
+		}
+	}
+
+	sort.SliceStable(crts, func(i, j int) bool {
+		if crts[i].Expire.Equal(crts[j].Expire) {
+			return crts[i].Serial < crts[j].Serial
+		}
+		return crts[i].Expire.Before(crts[j].Expire)
+	})
+
+	total := uint64(len(crts))
+	if offset >= total {
+		return []certs.Cert{}, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	return crts[offset:end], nil
+}
+
+func (c *certsRepoMock) UpdateNotified(ctx context.Context, serial string, threshold time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	crt, ok := c.certs[serial]
+	if !ok {
+		return certs.ErrNotFound
+	}
+	crt.Notified = threshold
+	c.certs[serial] = crt
+	if byThing, ok := c.certsByThingID[crt.ThingID]; ok && byThing.Serial == serial {
+		c.certsByThingID[crt.ThingID] = crt
+	}
+
+	return nil
+}
+
+func (c *certsRepoMock) SaveRevocation(ctx context.Context, r certs.Revocation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.revocations[r.Serial]; !ok {
+		c.revocations[r.Serial] = r
+	}
+	return nil
+}
+
+func (c *certsRepoMock) RetrieveRevocation(ctx context.Context, serial string) (certs.Revocation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.revocations[serial]
+	if !ok {
+		return certs.Revocation{}, certs.ErrNotFound
+	}
+	return r, nil
+}
+
+func (c *certsRepoMock) RetrieveRevocations(ctx context.Context) ([]certs.Revocation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	revs := []certs.Revocation{}
+	for _, r := range c.revocations {
+		if r.Expire.After(now) {
+			revs = append(revs, r)
+		}
+	}
+	return revs, nil
+}
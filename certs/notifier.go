@@ -0,0 +1,18 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package certs
+
+import "time"
+
+// Notification defines the certificate expiring within the threshold.
+type Notification struct {
+	Cert      Cert
+	Threshold time.Duration
+}
+
+// Notifier represents an API for notifying the certificate owners.
+type Notifier interface {
+	// Notify notifies the owner of the certificate about its expiry.
+	Notify(n Notification) error
+}
@@ -0,0 +1,6 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package smtp contains the notifier e-mailing the owners about the
+// expiry of their certificates.
+package smtp
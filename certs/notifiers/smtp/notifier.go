@@ -0,0 +1,36 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package smtp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mainflux/mainflux/certs"
+	"github.com/mainflux/mainflux/internal/email"
+)
+
+const (
+	subject         = "Certificate expiry"
+	footer          = "Sent by Mainflux Certs Service"
+	contentTemplate = "Certificate %s of the thing %s expires on %s, in less than %s."
+)
+
+var _ certs.Notifier = (*notifier)(nil)
+
+type notifier struct {
+	agent *email.Agent
+}
+
+// New instantiates SMTP certificate expiry notifier.
+func New(agent *email.Agent) certs.Notifier {
+	return &notifier{agent: agent}
+}
+
+func (n *notifier) Notify(nt certs.Notification) error {
+	c := nt.Cert
+	content := fmt.Sprintf(contentTemplate, c.Serial, c.ThingID, c.Expire.Format(time.RFC1123), nt.Threshold)
+
+	return n.agent.Send([]string{c.OwnerID}, "", subject, "", content, footer)
+}
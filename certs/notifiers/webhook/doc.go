@@ -0,0 +1,6 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package webhook contains the notifier posting the expiry of the
+// certificates to a webhook.
+package webhook
@@ -0,0 +1,68 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mainflux/mainflux/certs"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const contentType = "application/json"
+
+var errFailedNotification = errors.New("failed to post notification to webhook")
+
+var _ certs.Notifier = (*notifier)(nil)
+
+type notifier struct {
+	url    string
+	client *http.Client
+}
+
+// New instantiates the notifier posting certificate expiry to the webhook
+// with given URL.
+func New(url string, timeout time.Duration) certs.Notifier {
+	return &notifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type notification struct {
+	ThingID   string    `json:"thing_id"`
+	Owner     string    `json:"owner"`
+	Serial    string    `json:"serial"`
+	Expire    time.Time `json:"expire"`
+	Threshold string    `json:"threshold"`
+}
+
+func (n *notifier) Notify(nt certs.Notification) error {
+	body, err := json.Marshal(notification{
+		ThingID:   nt.Cert.ThingID,
+		Owner:     nt.Cert.OwnerID,
+		Serial:    nt.Cert.Serial,
+		Expire:    nt.Cert.Expire,
+		Threshold: nt.Threshold.String(),
+	})
+	if err != nil {
+		return errors.Wrap(errFailedNotification, err)
+	}
+
+	resp, err := n.client.Post(n.url, contentType, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(errFailedNotification, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Wrap(errFailedNotification, fmt.Errorf("unexpected status %d", resp.StatusCode))
+	}
+
+	return nil
+}
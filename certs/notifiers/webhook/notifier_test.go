@@ -0,0 +1,80 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package webhook_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/certs"
+	"github.com/mainflux/mainflux/certs/notifiers/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+const timeout = time.Second
+
+func TestNotify(t *testing.T) {
+	expire := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	n := certs.Notification{
+		Cert: certs.Cert{
+			ThingID: "1",
+			OwnerID: "user@example.com",
+			Serial:  "serial",
+			Expire:  expire,
+		},
+		Threshold: 24 * time.Hour,
+	}
+
+	var received map[string]interface{}
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ok.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	cases := []struct {
+		desc string
+		url  string
+		err  bool
+	}{
+		{
+			desc: "notify webhook",
+			url:  ok.URL,
+			err:  false,
+		},
+		{
+			desc: "notify failing webhook",
+			url:  failing.URL,
+			err:  true,
+		},
+		{
+			desc: "notify unreachable webhook",
+			url:  "http://localhost:0",
+			err:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := webhook.New(tc.url, timeout).Notify(n)
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+	}
+
+	expected := map[string]interface{}{
+		"thing_id":  n.Cert.ThingID,
+		"owner":     n.Cert.OwnerID,
+		"serial":    n.Cert.Serial,
+		"expire":    expire.Format(time.RFC3339),
+		"threshold": "24h0m0s",
+	}
+	assert.Equal(t, expected, received, fmt.Sprintf("expected %v got %v\n", expected, received))
+}
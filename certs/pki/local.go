@@ -0,0 +1,205 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package pki
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	rsaKey     = "rsa"
+	ecKey      = "ec"
+	ed25519Key = "ed25519"
+
+	defECKeyBits = 256
+)
+
+var (
+	// ErrUnsupportedKey indicates the key type or size the agent can't generate
+	ErrUnsupportedKey = errors.New("unsupported private key type or size")
+
+	errMissingCAKey = errors.New("missing CA private key for certificate signing")
+)
+
+var _ Agent = (*localAgent)(nil)
+
+type localAgent struct {
+	tlsCert    tls.Certificate
+	caCert     *x509.Certificate
+	rsaBits    int
+	hoursValid string
+}
+
+// NewLocalAgent returns the agent issuing certificates signed by the given
+// CA, for the deployments without 3rd party PKI. Certificates are valid for
+// the given duration by default, and RSA keys have given number of bits by
+// default. Revocation isn't kept by the agent, but only in certs repository.
+func NewLocalAgent(tlsCert tls.Certificate, caCert *x509.Certificate, rsaBits int, hoursValid string) Agent {
+	return &localAgent{
+		tlsCert:    tlsCert,
+		caCert:     caCert,
+		rsaBits:    rsaBits,
+		hoursValid: hoursValid,
+	}
+}
+
+func (a *localAgent) IssueCert(cn string, ttl, keyType string, keyBits int) (Cert, error) {
+	priv, err := a.privateKey(keyType, keyBits)
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrFailedCertCreation, err)
+	}
+
+	cert, err := a.sign(cn, ttl, priv.Public())
+	if err != nil {
+		return Cert{}, err
+	}
+
+	keyBlock, err := pemBlockForKey(priv)
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrFailedCertCreation, err)
+	}
+	cert.ClientKey = string(pem.EncodeToMemory(keyBlock))
+	cert.PrivateKeyType = keyType
+
+	return cert, nil
+}
+
+func (a *localAgent) Sign(cn string, ttl string, csr *x509.CertificateRequest) (Cert, error) {
+	return a.sign(cn, ttl, csr.PublicKey)
+}
+
+func (a *localAgent) CACert() (*x509.Certificate, error) {
+	if a.caCert == nil {
+		return nil, ErrMissingCACertificate
+	}
+
+	return a.caCert, nil
+}
+
+// sign issues certificate for the public key, signed by CA.
+func (a *localAgent) sign(cn string, ttl string, pub crypto.PublicKey) (Cert, error) {
+	if a.caCert == nil {
+		return Cert{}, errors.Wrap(ErrFailedCertCreation, ErrMissingCACertificate)
+	}
+	signer, ok := a.tlsCert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return Cert{}, errors.Wrap(ErrFailedCertCreation, errMissingCAKey)
+	}
+
+	if ttl == "" {
+		ttl = a.hoursValid
+	}
+	validFor, err := time.ParseDuration(ttl)
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrFailedCertCreation, err)
+	}
+
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrFailedCertCreation, err)
+	}
+
+	notBefore := time.Now()
+	tmpl := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization:       []string{"Mainflux"},
+			CommonName:         cn,
+			OrganizationalUnit: []string{"mainflux"},
+		},
+		NotBefore: notBefore,
+		NotAfter:  notBefore.Add(validFor),
+
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		SubjectKeyId: []byte{1, 2, 3, 4, 6},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, a.caCert, pub, signer)
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrFailedCertCreation, err)
+	}
+
+	issuingCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: a.caCert.Raw}))
+
+	return Cert{
+		ClientCert: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		IssuingCA:  issuingCA,
+		CAChain:    []string{issuingCA},
+		Serial:     serialNumber.String(),
+		Expire:     tmpl.NotAfter,
+	}, nil
+}
+
+func (a *localAgent) Revoke(serial string) (time.Time, error) {
+	return time.Now(), nil
+}
+
+func (a *localAgent) privateKey(keyType string, keyBits int) (crypto.Signer, error) {
+	switch keyType {
+	case "", rsaKey:
+		if keyBits == 0 {
+			keyBits = a.rsaBits
+		}
+		return rsa.GenerateKey(rand.Reader, keyBits)
+	case ecKey:
+		var curve elliptic.Curve
+		switch keyBits {
+		case 224:
+			curve = elliptic.P224()
+		case 0, defECKeyBits:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, ErrUnsupportedKey
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	case ed25519Key:
+		if keyBits != 0 {
+			return nil, ErrUnsupportedKey
+		}
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+func pemBlockForKey(priv crypto.Signer) (*pem.Block, error) {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}, nil
+	case *ecdsa.PrivateKey:
+		b, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: "EC PRIVATE KEY", Bytes: b}, nil
+	case ed25519.PrivateKey:
+		b, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: b}, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package pki_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/certs/pki"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	caPath     = "../../docker/ssl/certs/ca.crt"
+	caKeyPath  = "../../docker/ssl/certs/ca.key"
+	cn         = "thingKey"
+	hoursValid = "24h"
+	rsaBits    = 2048
+)
+
+func TestIssueCert(t *testing.T) {
+	tlsCert, err := tls.LoadX509KeyPair(caPath, caKeyPath)
+	require.Nil(t, err, fmt.Sprintf("unexpected CA loading error: %s\n", err))
+	caCert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	require.Nil(t, err, fmt.Sprintf("unexpected CA parsing error: %s\n", err))
+
+	agent := pki.NewLocalAgent(tlsCert, caCert, rsaBits, hoursValid)
+
+	cases := []struct {
+		desc    string
+		agent   pki.Agent
+		ttl     string
+		keyType string
+		keyBits int
+		key     interface{}
+		err     error
+	}{
+		{
+			desc:    "issue cert with default key",
+			agent:   agent,
+			keyType: "",
+			keyBits: 0,
+			key:     &rsa.PublicKey{},
+			err:     nil,
+		},
+		{
+			desc:    "issue cert with RSA key",
+			agent:   agent,
+			ttl:     "1h",
+			keyType: "rsa",
+			keyBits: 4096,
+			key:     &rsa.PublicKey{},
+			err:     nil,
+		},
+		{
+			desc:    "issue cert with EC key",
+			agent:   agent,
+			keyType: "ec",
+			keyBits: 384,
+			key:     &ecdsa.PublicKey{},
+			err:     nil,
+		},
+		{
+			desc:    "issue cert with Ed25519 key",
+			agent:   agent,
+			keyType: "ed25519",
+			keyBits: 0,
+			key:     ed25519.PublicKey{},
+			err:     nil,
+		},
+		{
+			desc:    "issue cert with Ed25519 key with key size",
+			agent:   agent,
+			keyType: "ed25519",
+			keyBits: 256,
+			err:     pki.ErrUnsupportedKey,
+		},
+		{
+			desc:    "issue cert with unsupported EC key size",
+			agent:   agent,
+			keyType: "ec",
+			keyBits: 2048,
+			err:     pki.ErrUnsupportedKey,
+		},
+		{
+			desc:    "issue cert with unsupported key type",
+			agent:   agent,
+			keyType: "dsa",
+			err:     pki.ErrUnsupportedKey,
+		},
+		{
+			desc:    "issue cert with invalid TTL",
+			agent:   agent,
+			ttl:     "invalid",
+			keyType: "rsa",
+			err:     pki.ErrFailedCertCreation,
+		},
+		{
+			desc:    "issue cert without CA",
+			agent:   pki.NewLocalAgent(tls.Certificate{}, nil, rsaBits, hoursValid),
+			keyType: "rsa",
+			err:     pki.ErrMissingCACertificate,
+		},
+	}
+
+	for _, tc := range cases {
+		c, err := tc.agent.IssueCert(cn, tc.ttl, tc.keyType, tc.keyBits)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err != nil {
+			continue
+		}
+
+		block, _ := pem.Decode([]byte(c.ClientCert))
+		require.NotNil(t, block, fmt.Sprintf("%s: expected PEM encoded cert\n", tc.desc))
+		cert, err := x509.ParseCertificate(block.Bytes)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected cert parsing error: %s\n", tc.desc, err))
+
+		err = cert.CheckSignatureFrom(caCert)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected signature error: %s\n", tc.desc, err))
+		assert.Equal(t, cn, cert.Subject.CommonName, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, cn, cert.Subject.CommonName))
+		assert.Equal(t, cert.SerialNumber.String(), c.Serial, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, cert.SerialNumber, c.Serial))
+		assert.IsType(t, tc.key, cert.PublicKey, fmt.Sprintf("%s: expected %T got %T\n", tc.desc, tc.key, cert.PublicKey))
+	}
+}
@@ -5,7 +5,9 @@
 package pki
 
 import (
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"io/ioutil"
 	"net/http"
 	"time"
@@ -17,7 +19,9 @@ import (
 
 const (
 	issue  = "issue"
+	sign   = "sign"
 	revoke = "revoke"
+	ca     = "ca/pem"
 	apiVer = "v1"
 )
 
@@ -36,6 +40,7 @@ var (
 
 	errFailedVaultCertIssue = errors.New("failed to issue vault certificate")
 	errFailedCertDecoding   = errors.New("failed to decode response from vault service")
+	errFailedCAFetch        = errors.New("failed to fetch vault CA certificate")
 )
 
 type Cert struct {
@@ -52,8 +57,14 @@ type Agent interface {
 	// IssueCert issues certificate on PKI
 	IssueCert(cn string, ttl, keyType string, keyBits int) (Cert, error)
 
+	// Sign issues certificate on PKI for the key of certificate request
+	Sign(cn string, ttl string, csr *x509.CertificateRequest) (Cert, error)
+
 	// Revoke revokes certificate from PKI
 	Revoke(serial string) (time.Time, error)
+
+	// CACert returns the certificate of CA issuing certificates
+	CACert() (*x509.Certificate, error)
 }
 
 type pkiAgent struct {
@@ -62,7 +73,9 @@ type pkiAgent struct {
 	role      string
 	host      string
 	issueURL  string
+	signURL   string
 	revokeURL string
+	caURL     string
 	client    *api.Client
 }
 
@@ -73,6 +86,12 @@ type certReq struct {
 	KeyType    string `json:"key_type"`
 }
 
+type signReq struct {
+	CommonName string `json:"common_name"`
+	TTL        string `json:"ttl"`
+	CSR        string `json:"csr"`
+}
+
 type certRevokeReq struct {
 	SerialNumber string `json:"serial_number"`
 }
@@ -94,7 +113,9 @@ func NewVaultClient(token, host, path, role string) (Agent, error) {
 		path:      path,
 		client:    client,
 		issueURL:  "/" + apiVer + "/" + path + "/" + issue + "/" + role,
+		signURL:   "/" + apiVer + "/" + path + "/" + sign + "/" + role,
 		revokeURL: "/" + apiVer + "/" + path + "/" + revoke,
+		caURL:     "/" + apiVer + "/" + path + "/" + ca,
 	}
 	return &p, nil
 }
@@ -107,8 +128,23 @@ func (p *pkiAgent) IssueCert(cn string, ttl, keyType string, keyBits int) (Cert,
 		KeyType:    keyType,
 	}
 
-	r := p.client.NewRequest("POST", p.issueURL)
-	if err := r.SetJSONBody(cReq); err != nil {
+	return p.certs(p.issueURL, cReq)
+}
+
+func (p *pkiAgent) Sign(cn string, ttl string, csr *x509.CertificateRequest) (Cert, error) {
+	sReq := signReq{
+		CommonName: cn,
+		TTL:        ttl,
+		CSR:        string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr.Raw})),
+	}
+
+	return p.certs(p.signURL, sReq)
+}
+
+// certs sends the request for issuing certificate to the given URL.
+func (p *pkiAgent) certs(url string, req interface{}) (Cert, error) {
+	r := p.client.NewRequest("POST", url)
+	if err := r.SetJSONBody(req); err != nil {
 		return Cert{}, err
 	}
 
@@ -147,6 +183,35 @@ func (p *pkiAgent) IssueCert(cn string, ttl, keyType string, keyBits int) (Cert,
 
 }
 
+func (p *pkiAgent) CACert() (*x509.Certificate, error) {
+	r := p.client.NewRequest("GET", p.caURL)
+	resp, err := p.client.RawRequest(r)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(errFailedCAFetch, err)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(errFailedCAFetch, err)
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errFailedCAFetch
+	}
+
+	caCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(errFailedCAFetch, err)
+	}
+
+	return caCert, nil
+}
+
 func (p *pkiAgent) Revoke(serial string) (time.Time, error) {
 	cReq := certRevokeReq{
 		SerialNumber: serial,
@@ -7,6 +7,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -23,7 +24,11 @@ var (
 	errSaveDB     = errors.New("failed to save certificate to database")
 	errRetrieveDB = errors.New("failed to retrieve certificate from db")
 	errRemove     = errors.New("failed to remove certificate from database")
+	errUpdateDB   = errors.New("failed to update certificate in database")
 	errInvalid    = "invalid_text_representation"
+
+	errSaveRevocation      = errors.New("failed to save certificate revocation to database")
+	errRetrieveRevocations = errors.New("failed to retrieve certificate revocations from database")
 )
 
 var _ certs.Repository = (*certsRepository)(nil)
@@ -45,44 +50,76 @@ func NewRepository(db *sqlx.DB, log logger.Logger) certs.Repository {
 	return &certsRepository{db: db, log: log}
 }
 
-func (cr certsRepository) RetrieveAll(ctx context.Context, ownerID, thingID string, offset, limit uint64) (certs.Page, error) {
-	q := `SELECT thing_id, owner_id, serial, expire FROM certs WHERE owner_id = $1 ORDER BY expire LIMIT $2 OFFSET $3;`
-	rows, err := cr.db.Query(q, ownerID, limit, offset)
+func (cr certsRepository) RetrieveAll(ctx context.Context, ownerID string, pm certs.PageMetadata) (certs.Page, error) {
+	conds := []string{"owner_id = :owner_id"}
+	if pm.ThingID != "" {
+		conds = append(conds, "thing_id = :thing_id")
+	}
+	if !pm.ExpiresAfter.IsZero() {
+		conds = append(conds, "expire >= :expires_after")
+	}
+	if !pm.ExpiresBefore.IsZero() {
+		conds = append(conds, "expire < :expires_before")
+	}
+	where := strings.Join(conds, " AND ")
+
+	active := fmt.Sprintf(`SELECT thing_id, owner_id, serial, expire, NULL::TIMESTAMPTZ AS revoked_at FROM certs WHERE %s`, where)
+	revoked := fmt.Sprintf(`SELECT thing_id, owner_id, serial, expire, revoked_at FROM revocations WHERE %s`, where)
+
+	var selected string
+	switch pm.Status {
+	case certs.RevokedStatus:
+		selected = revoked
+	case certs.AllStatus:
+		selected = fmt.Sprintf("%s UNION ALL %s", active, revoked)
+	default:
+		selected = active
+	}
+
+	params := map[string]interface{}{
+		"owner_id":       ownerID,
+		"thing_id":       pm.ThingID,
+		"expires_after":  pm.ExpiresAfter,
+		"expires_before": pm.ExpiresBefore,
+		"limit":          pm.Limit,
+		"offset":         pm.Offset,
+	}
+
+	q := fmt.Sprintf(`SELECT thing_id, owner_id, serial, expire, revoked_at FROM (%s) AS c
+		ORDER BY expire, serial LIMIT :limit OFFSET :offset`, selected)
+	rows, err := cr.db.NamedQueryContext(ctx, q, params)
 	if err != nil {
-		cr.log.Error(fmt.Sprintf("Failed to retrieve configs due to %s", err))
-		return certs.Page{}, err
+		return certs.Page{}, errors.Wrap(errRetrieveDB, err)
 	}
 	defer rows.Close()
 
 	certificates := []certs.Cert{}
-
 	for rows.Next() {
-		c := certs.Cert{}
-		if err := rows.Scan(&c.ThingID, &c.OwnerID, &c.Serial, &c.Expire); err != nil {
-			cr.log.Error(fmt.Sprintf("Failed to read retrieved config due to %s", err))
-			return certs.Page{}, err
-
+		var dbc dbListedCert
+		if err := rows.StructScan(&dbc); err != nil {
+			cr.log.Error(fmt.Sprintf("Failed to read retrieved cert due to %s", err))
+			return certs.Page{}, errors.Wrap(errRetrieveDB, err)
 		}
-		certificates = append(certificates, c)
+		certificates = append(certificates, toListedCert(dbc))
 	}
 
-	q = `SELECT COUNT(*) FROM certs WHERE owner_id = $1`
-	var total uint64
-	if err := cr.db.QueryRow(q, ownerID).Scan(&total); err != nil {
-		cr.log.Error(fmt.Sprintf("Failed to count certs due to %s", err))
-		return certs.Page{}, err
+	q = fmt.Sprintf(`SELECT COUNT(*) FROM (%s) AS c`, selected)
+	total, err := total(ctx, cr.db, q, params)
+	if err != nil {
+		return certs.Page{}, errors.Wrap(errRetrieveDB, err)
 	}
 
 	return certs.Page{
 		Total:  total,
-		Limit:  limit,
-		Offset: offset,
+		Limit:  pm.Limit,
+		Offset: pm.Offset,
 		Certs:  certificates,
 	}, nil
 }
 
 func (cr certsRepository) Save(ctx context.Context, cert certs.Cert) (string, error) {
-	q := `INSERT INTO certs (thing_id, owner_id, serial, expire) VALUES (:thing_id, :owner_id, :serial, :expire)`
+	q := `INSERT INTO certs (thing_id, owner_id, serial, expire, key_type, key_bits)
+		  VALUES (:thing_id, :owner_id, :serial, :expire, :key_type, :key_bits)`
 
 	tx, err := cr.db.Beginx()
 	if err != nil {
@@ -124,7 +161,7 @@ func (cr certsRepository) Remove(ctx context.Context, serial string) error {
 }
 
 func (cr certsRepository) RetrieveByThing(ctx context.Context, thingID string) (certs.Cert, error) {
-	q := `SELECT thing_id, owner_id, serial, expire FROM certs WHERE thing_id = $1`
+	q := `SELECT thing_id, owner_id, serial, expire, key_type, key_bits FROM certs WHERE thing_id = $1`
 	var dbcrt dbCert
 	var c certs.Cert
 
@@ -142,8 +179,99 @@ func (cr certsRepository) RetrieveByThing(ctx context.Context, thingID string) (
 	return c, nil
 }
 
+func (cr certsRepository) RetrieveExpiring(ctx context.Context, before time.Time, offset, limit uint64) ([]certs.Cert, error) {
+	q := `SELECT thing_id, owner_id, serial, expire, key_type, key_bits, notified FROM certs WHERE expire < $1
+		  ORDER BY expire, serial LIMIT $2 OFFSET $3`
+
+	rows, err := cr.db.QueryxContext(ctx, q, before, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(errRetrieveDB, err)
+	}
+	defer rows.Close()
+
+	certificates := []certs.Cert{}
+	for rows.Next() {
+		var dbcrt dbCert
+		if err := rows.StructScan(&dbcrt); err != nil {
+			cr.log.Error(fmt.Sprintf("Failed to read retrieved cert due to %s", err))
+			return nil, errors.Wrap(errRetrieveDB, err)
+		}
+		certificates = append(certificates, toCert(dbcrt))
+	}
+
+	return certificates, nil
+}
+
+func (cr certsRepository) UpdateNotified(ctx context.Context, serial string, threshold time.Duration) error {
+	q := `UPDATE certs SET notified = $1 WHERE serial = $2`
+
+	res, err := cr.db.ExecContext(ctx, q, int64(threshold), serial)
+	if err != nil {
+		return errors.Wrap(errUpdateDB, err)
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(errUpdateDB, err)
+	}
+	if cnt == 0 {
+		return certs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (cr certsRepository) SaveRevocation(ctx context.Context, r certs.Revocation) error {
+	q := `INSERT INTO revocations (serial, thing_id, owner_id, expire, revoked_at)
+		  VALUES (:serial, :thing_id, :owner_id, :expire, :revoked_at)
+		  ON CONFLICT (serial) DO NOTHING`
+
+	if _, err := cr.db.NamedExecContext(ctx, q, toDBRevocation(r)); err != nil {
+		return errors.Wrap(errSaveRevocation, err)
+	}
+
+	return nil
+}
+
+func (cr certsRepository) RetrieveRevocation(ctx context.Context, serial string) (certs.Revocation, error) {
+	q := `SELECT serial, thing_id, owner_id, expire, revoked_at FROM revocations WHERE serial = $1`
+
+	var dbr dbRevocation
+	if err := cr.db.QueryRowxContext(ctx, q, serial).StructScan(&dbr); err != nil {
+		if err == sql.ErrNoRows {
+			return certs.Revocation{}, errors.Wrap(certs.ErrNotFound, err)
+		}
+		return certs.Revocation{}, errors.Wrap(errRetrieveRevocations, err)
+	}
+
+	return toRevocation(dbr), nil
+}
+
+func (cr certsRepository) RetrieveRevocations(ctx context.Context) ([]certs.Revocation, error) {
+	q := `SELECT serial, thing_id, owner_id, expire, revoked_at FROM revocations WHERE expire > $1
+		  ORDER BY revoked_at`
+
+	rows, err := cr.db.QueryxContext(ctx, q, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(errRetrieveRevocations, err)
+	}
+	defer rows.Close()
+
+	revs := []certs.Revocation{}
+	for rows.Next() {
+		var dbr dbRevocation
+		if err := rows.StructScan(&dbr); err != nil {
+			cr.log.Error(fmt.Sprintf("Failed to read retrieved revocation due to %s", err))
+			return nil, errors.Wrap(errRetrieveRevocations, err)
+		}
+		revs = append(revs, toRevocation(dbr))
+	}
+
+	return revs, nil
+}
+
 func (cr certsRepository) retrieveBySerial(ctx context.Context, serial string) (certs.Cert, error) {
-	q := `SELECT thing_id, owner_id, serial, expire FROM certs WHERE serial = $1`
+	q := `SELECT thing_id, owner_id, serial, expire, key_type, key_bits FROM certs WHERE serial = $1`
 	var dbcrt dbCert
 	var c certs.Cert
 
@@ -161,6 +289,23 @@ func (cr certsRepository) retrieveBySerial(ctx context.Context, serial string) (
 	return c, nil
 }
 
+func total(ctx context.Context, db *sqlx.DB, query string, params interface{}) (uint64, error) {
+	rows, err := db.NamedQueryContext(ctx, query, params)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var total uint64
+	if rows.Next() {
+		if err := rows.Scan(&total); err != nil {
+			return 0, err
+		}
+	}
+
+	return total, nil
+}
+
 func (cr certsRepository) rollback(content string, tx *sqlx.Tx, err error) {
 	cr.log.Error(fmt.Sprintf("%s %s", content, err))
 
@@ -170,10 +315,13 @@ func (cr certsRepository) rollback(content string, tx *sqlx.Tx, err error) {
 }
 
 type dbCert struct {
-	ThingID string    `db:"thing_id"`
-	Serial  string    `db:"serial"`
-	Expire  time.Time `db:"expire"`
-	OwnerID string    `db:"owner_id"`
+	ThingID  string    `db:"thing_id"`
+	Serial   string    `db:"serial"`
+	Expire   time.Time `db:"expire"`
+	OwnerID  string    `db:"owner_id"`
+	KeyType  string    `db:"key_type"`
+	KeyBits  int       `db:"key_bits"`
+	Notified int64     `db:"notified"`
 }
 
 func toDBCert(c certs.Cert) dbCert {
@@ -182,6 +330,8 @@ func toDBCert(c certs.Cert) dbCert {
 		OwnerID: c.OwnerID,
 		Serial:  c.Serial,
 		Expire:  c.Expire,
+		KeyType: c.PrivateKeyType,
+		KeyBits: c.KeyBits,
 	}
 }
 
@@ -191,5 +341,54 @@ func toCert(cdb dbCert) certs.Cert {
 	c.ThingID = cdb.ThingID
 	c.Serial = cdb.Serial
 	c.Expire = cdb.Expire
+	c.PrivateKeyType = cdb.KeyType
+	c.KeyBits = cdb.KeyBits
+	c.Notified = time.Duration(cdb.Notified)
 	return c
 }
+
+type dbListedCert struct {
+	ThingID   string       `db:"thing_id"`
+	OwnerID   string       `db:"owner_id"`
+	Serial    string       `db:"serial"`
+	Expire    time.Time    `db:"expire"`
+	RevokedAt sql.NullTime `db:"revoked_at"`
+}
+
+func toListedCert(dbc dbListedCert) certs.Cert {
+	return certs.Cert{
+		ThingID:        dbc.ThingID,
+		OwnerID:        dbc.OwnerID,
+		Serial:         dbc.Serial,
+		Expire:         dbc.Expire,
+		RevocationTime: dbc.RevokedAt.Time,
+	}
+}
+
+type dbRevocation struct {
+	Serial    string    `db:"serial"`
+	ThingID   string    `db:"thing_id"`
+	OwnerID   string    `db:"owner_id"`
+	Expire    time.Time `db:"expire"`
+	RevokedAt time.Time `db:"revoked_at"`
+}
+
+func toDBRevocation(r certs.Revocation) dbRevocation {
+	return dbRevocation{
+		Serial:    r.Serial,
+		ThingID:   r.ThingID,
+		OwnerID:   r.OwnerID,
+		Expire:    r.Expire,
+		RevokedAt: r.RevocationTime,
+	}
+}
+
+func toRevocation(dbr dbRevocation) certs.Revocation {
+	return certs.Revocation{
+		Serial:         dbr.Serial,
+		ThingID:        dbr.ThingID,
+		OwnerID:        dbr.OwnerID,
+		Expire:         dbr.Expire,
+		RevocationTime: dbr.RevokedAt,
+	}
+}
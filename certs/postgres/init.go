@@ -71,6 +71,44 @@ func migrateDB(db *sqlx.DB) error {
 					"DROP TABLE IF EXISTS certs;",
 				},
 			},
+			{
+				Id: "certs_2",
+				Up: []string{
+					`ALTER TABLE IF EXISTS certs ADD COLUMN IF NOT EXISTS key_type TEXT NOT NULL DEFAULT ''`,
+					`ALTER TABLE IF EXISTS certs ADD COLUMN IF NOT EXISTS key_bits INTEGER NOT NULL DEFAULT 0`,
+					`CREATE INDEX IF NOT EXISTS certs_expire_idx ON certs (expire)`,
+				},
+				Down: []string{
+					`DROP INDEX IF EXISTS certs_expire_idx`,
+					`ALTER TABLE IF EXISTS certs DROP COLUMN IF EXISTS key_bits`,
+					`ALTER TABLE IF EXISTS certs DROP COLUMN IF EXISTS key_type`,
+				},
+			},
+			{
+				Id: "certs_3",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS revocations (
+						serial       TEXT NOT NULL,
+						thing_id     TEXT NOT NULL,
+						owner_id     TEXT NOT NULL,
+						expire       TIMESTAMPTZ NOT NULL,
+						revoked_at   TIMESTAMPTZ NOT NULL,
+						PRIMARY KEY  (serial)
+					);`,
+				},
+				Down: []string{
+					"DROP TABLE IF EXISTS revocations;",
+				},
+			},
+			{
+				Id: "certs_4",
+				Up: []string{
+					`ALTER TABLE certs ADD COLUMN IF NOT EXISTS notified BIGINT NOT NULL DEFAULT 0`,
+				},
+				Down: []string{
+					`ALTER TABLE certs DROP COLUMN IF EXISTS notified`,
+				},
+			},
 		},
 	}
 
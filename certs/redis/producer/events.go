@@ -0,0 +1,40 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package producer
+
+import "time"
+
+const (
+	certPrefix = "cert."
+	certRenew  = certPrefix + "renew"
+)
+
+type event interface {
+	encode() map[string]interface{}
+}
+
+var (
+	_ event = (*renewCertEvent)(nil)
+)
+
+type renewCertEvent struct {
+	thingID    string
+	owner      string
+	serial     string
+	prevSerial string
+	expire     time.Time
+	timestamp  time.Time
+}
+
+func (rce renewCertEvent) encode() map[string]interface{} {
+	return map[string]interface{}{
+		"thing_id":    rce.thingID,
+		"owner":       rce.owner,
+		"serial":      rce.serial,
+		"prev_serial": rce.prevSerial,
+		"expire":      rce.expire.Unix(),
+		"timestamp":   rce.timestamp.Unix(),
+		"operation":   certRenew,
+	}
+}
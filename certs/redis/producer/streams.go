@@ -0,0 +1,100 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package producer
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/certs"
+)
+
+const (
+	streamID  = "mainflux.certs"
+	streamLen = 1000
+)
+
+var _ certs.Service = (*eventStore)(nil)
+
+type eventStore struct {
+	svc    certs.Service
+	client *redis.Client
+}
+
+// NewEventStoreMiddleware returns wrapper around certs service that sends
+// events to event store.
+func NewEventStoreMiddleware(svc certs.Service, client *redis.Client) certs.Service {
+	return eventStore{
+		svc:    svc,
+		client: client,
+	}
+}
+
+func (es eventStore) IssueCert(ctx context.Context, token, thingID, daysValid string, keyBits int, keyType string) (certs.Cert, error) {
+	return es.svc.IssueCert(ctx, token, thingID, daysValid, keyBits, keyType)
+}
+
+func (es eventStore) ListCerts(ctx context.Context, token string, pm certs.PageMetadata) (certs.Page, error) {
+	return es.svc.ListCerts(ctx, token, pm)
+}
+
+func (es eventStore) RevokeCert(ctx context.Context, token, thingID string) (certs.Revoke, error) {
+	return es.svc.RevokeCert(ctx, token, thingID)
+}
+
+func (es eventStore) RenewCerts(ctx context.Context) ([]certs.Renewal, error) {
+	renewals, err := es.svc.RenewCerts(ctx)
+
+	// Renewals that succeeded are published even if some others failed.
+	for _, r := range renewals {
+		ev := renewCertEvent{
+			thingID:    r.Cert.ThingID,
+			owner:      r.Cert.OwnerID,
+			serial:     r.Cert.Serial,
+			prevSerial: r.PrevSerial,
+			expire:     r.Cert.Expire,
+			timestamp:  time.Now(),
+		}
+
+		es.add(ctx, ev)
+	}
+
+	return renewals, err
+}
+
+func (es eventStore) NotifyCerts(ctx context.Context) ([]certs.Notification, error) {
+	return es.svc.NotifyCerts(ctx)
+}
+
+func (es eventStore) CRL(ctx context.Context) ([]byte, error) {
+	return es.svc.CRL(ctx)
+}
+
+func (es eventStore) OCSP(ctx context.Context, request []byte) ([]byte, error) {
+	return es.svc.OCSP(ctx, request)
+}
+
+func (es eventStore) CACert(ctx context.Context) (*x509.Certificate, error) {
+	return es.svc.CACert(ctx)
+}
+
+func (es eventStore) Enroll(ctx context.Context, token string, csr []byte) (certs.Cert, error) {
+	return es.svc.Enroll(ctx, token, csr)
+}
+
+func (es eventStore) Reenroll(ctx context.Context, token string, csr []byte) (certs.Cert, error) {
+	return es.svc.Reenroll(ctx, token, csr)
+}
+
+func (es eventStore) add(ctx context.Context, ev event) error {
+	record := &redis.XAddArgs{
+		Stream:       streamID,
+		MaxLenApprox: streamLen,
+		Values:       ev.encode(),
+	}
+
+	return es.client.XAdd(ctx, record).Err()
+}
@@ -0,0 +1,168 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package producer_test
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/certs"
+	"github.com/mainflux/mainflux/certs/redis/producer"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	streamID      = "mainflux.certs"
+	email         = "user@example.com"
+	defaultTimout = 5
+
+	certPrefix = "cert."
+	certRenew  = certPrefix + "renew"
+)
+
+var errRenewal = errors.New("renewal failed")
+
+var _ certs.Service = (*serviceMock)(nil)
+
+// serviceMock renews the certificates it was created with.
+type serviceMock struct {
+	renewals []certs.Renewal
+	err      error
+}
+
+func (svc serviceMock) IssueCert(ctx context.Context, token, thingID, daysValid string, keyBits int, keyType string) (certs.Cert, error) {
+	return certs.Cert{}, nil
+}
+
+func (svc serviceMock) ListCerts(ctx context.Context, token string, pm certs.PageMetadata) (certs.Page, error) {
+	return certs.Page{}, nil
+}
+
+func (svc serviceMock) RevokeCert(ctx context.Context, token, thingID string) (certs.Revoke, error) {
+	return certs.Revoke{}, nil
+}
+
+func (svc serviceMock) RenewCerts(ctx context.Context) ([]certs.Renewal, error) {
+	return svc.renewals, svc.err
+}
+
+func (svc serviceMock) NotifyCerts(ctx context.Context) ([]certs.Notification, error) {
+	return nil, nil
+}
+
+func (svc serviceMock) CRL(ctx context.Context) ([]byte, error) {
+	return nil, nil
+}
+
+func (svc serviceMock) OCSP(ctx context.Context, request []byte) ([]byte, error) {
+	return nil, nil
+}
+
+func (svc serviceMock) CACert(ctx context.Context) (*x509.Certificate, error) {
+	return nil, nil
+}
+
+func (svc serviceMock) Enroll(ctx context.Context, token string, csr []byte) (certs.Cert, error) {
+	return certs.Cert{}, nil
+}
+
+func (svc serviceMock) Reenroll(ctx context.Context, token string, csr []byte) (certs.Cert, error) {
+	return certs.Cert{}, nil
+}
+
+func TestRenewCerts(t *testing.T) {
+	redisClient.FlushAll(context.Background()).Err()
+
+	renewal := certs.Renewal{
+		Cert: certs.Cert{
+			ThingID: "1",
+			OwnerID: email,
+			Serial:  "2",
+			Expire:  time.Now().Add(time.Hour),
+		},
+		PrevSerial: "1",
+	}
+
+	// Event is created for each case since test removes its timestamp.
+	event := func() map[string]interface{} {
+		return map[string]interface{}{
+			"thing_id":    renewal.Cert.ThingID,
+			"owner":       renewal.Cert.OwnerID,
+			"serial":      renewal.Cert.Serial,
+			"prev_serial": renewal.PrevSerial,
+			"expire":      strconv.FormatInt(renewal.Cert.Expire.Unix(), 10),
+			"timestamp":   time.Now().Unix(),
+			"operation":   certRenew,
+		}
+	}
+
+	cases := []struct {
+		desc  string
+		svc   certs.Service
+		err   error
+		event map[string]interface{}
+	}{
+		{
+			desc:  "renew certs successfully",
+			svc:   serviceMock{renewals: []certs.Renewal{renewal}},
+			err:   nil,
+			event: event(),
+		},
+		{
+			desc:  "renew certs with some renewals failed",
+			svc:   serviceMock{renewals: []certs.Renewal{renewal}, err: errRenewal},
+			err:   errRenewal,
+			event: event(),
+		},
+		{
+			desc:  "renew certs with all renewals failed",
+			svc:   serviceMock{err: errRenewal},
+			err:   errRenewal,
+			event: nil,
+		},
+	}
+
+	lastID := "0"
+	for _, tc := range cases {
+		svc := producer.NewEventStoreMiddleware(tc.svc, redisClient)
+		_, err := svc.RenewCerts(context.Background())
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+
+		streams := redisClient.XRead(context.Background(), &redis.XReadArgs{
+			Streams: []string{streamID, lastID},
+			Count:   1,
+			Block:   time.Second,
+		}).Val()
+
+		var event map[string]interface{}
+		if len(streams) > 0 && len(streams[0].Messages) > 0 {
+			msg := streams[0].Messages[0]
+			event = msg.Values
+			lastID = msg.ID
+		}
+
+		test(t, tc.event, event, tc.desc)
+	}
+}
+
+func test(t *testing.T, expected, actual map[string]interface{}, description string) {
+	if expected != nil && actual != nil {
+		ts1 := expected["timestamp"].(int64)
+		ts2, err := strconv.ParseInt(actual["timestamp"].(string), 10, 64)
+		require.Nil(t, err, fmt.Sprintf("%s: expected to get a valid timestamp, got %s", description, err))
+		val := ts1 == ts2 || ts2 <= ts1+defaultTimout
+		assert.True(t, val, fmt.Sprintf("%s: timestamp is not in valid range", description))
+		delete(expected, "timestamp")
+		delete(actual, "timestamp")
+		assert.Equal(t, expected, actual, fmt.Sprintf("%s: expected %v got %v\n", description, expected, actual))
+	}
+	assert.Equal(t, expected == nil, actual == nil, fmt.Sprintf("%s: expected event %v got %v\n", description, expected, actual))
+}
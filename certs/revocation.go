@@ -0,0 +1,42 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package certs
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Revocation defines the revoked certificate, kept until it expires to be
+// listed in CRL and reported by OCSP responder
+type Revocation struct {
+	ThingID        string
+	OwnerID        string
+	Serial         string
+	Expire         time.Time
+	RevocationTime time.Time
+}
+
+// serialNumber parses the serial number of the certificate. Serial numbers
+// are formatted as decimal numbers by the development mode PKI and as colon
+// separated hex bytes by Vault.
+func serialNumber(serial string) (*big.Int, bool) {
+	if strings.Contains(serial, ":") {
+		return new(big.Int).SetString(strings.Replace(serial, ":", "", -1), 16)
+	}
+
+	return new(big.Int).SetString(serial, 10)
+}
+
+// serialFormats returns the ways the serial number may be saved.
+func serialFormats(n *big.Int) []string {
+	var hex []string
+	for _, b := range n.Bytes() {
+		hex = append(hex, fmt.Sprintf("%02x", b))
+	}
+
+	return []string{n.String(), strings.Join(hex, ":")}
+}
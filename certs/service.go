@@ -4,15 +4,24 @@
 package certs
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"time"
 
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/certs/pki"
 	"github.com/mainflux/mainflux/pkg/errors"
 	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"golang.org/x/crypto/ocsp"
 )
 
 var (
@@ -32,7 +41,36 @@ var (
 	// ErrFailedCertRevocation failed to revoke certificate
 	ErrFailedCertRevocation = errors.New("failed to revoke certificate")
 
+	// ErrFailedCertRenewal failed to renew certificate
+	ErrFailedCertRenewal = errors.New("failed to renew certificate")
+
+	// ErrFailedCRLCreation failed to create certificate revocation list
+	ErrFailedCRLCreation = errors.New("failed to create certificate revocation list")
+
+	// ErrFailedOCSPResponse failed to respond to OCSP request
+	ErrFailedOCSPResponse = errors.New("failed to respond to OCSP request")
+
+	// ErrFailedCertNotification failed to notify about certificate expiry
+	ErrFailedCertNotification = errors.New("failed to notify about certificate expiry")
+
 	errFailedToRemoveCertFromDB = errors.New("failed to remove cert serial from db")
+	errMissingSigner            = errors.New("missing CA certificate or key for signing")
+	errFailedCAFetch            = errors.New("failed to fetch CA certificate")
+	errRenewalExpiring          = errors.New("renewed certificate expires within the renewal lead time")
+)
+
+const (
+	renewBatchSize = 100
+
+	notifyBatchSize = 100
+
+	// statusValidity is the time CRL and OCSP responses are valid for,
+	// i.e. the longest it takes clients to learn about the revocation.
+	statusValidity = time.Hour
+
+	// loginKey is the type of the key issued to the certificate owner
+	// for the renewal.
+	loginKey uint32 = 0
 )
 
 var _ Service = (*certsService)(nil)
@@ -40,14 +78,41 @@ var _ Service = (*certsService)(nil)
 // Service specifies an API that must be fulfilled by the domain service
 // implementation, and all of its decorators (e.g. logging & metrics).
 type Service interface {
-	// IssueCert issues certificate for given thing id if access is granted with token
+	// IssueCert issues certificate for given thing id if access is granted with token.
+	// The configured key type and size are used unless requested otherwise.
 	IssueCert(ctx context.Context, token, thingID, daysValid string, keyBits int, keyType string) (Cert, error)
 
-	// ListCerts lists all certificates issued for given owner
-	ListCerts(ctx context.Context, token, thingID string, offset, limit uint64) (Page, error)
+	// ListCerts lists the certificates issued for given owner matching the
+	// filters
+	ListCerts(ctx context.Context, token string, pm PageMetadata) (Page, error)
 
 	// RevokeCert revokes certificate for given thing
 	RevokeCert(ctx context.Context, token, thingID string) (Revoke, error)
+
+	// RenewCerts renews certificates expiring within the renewal lead time
+	// and updates bootstrap configs of their things with the renewed ones
+	RenewCerts(ctx context.Context) ([]Renewal, error)
+
+	// NotifyCerts notifies the owners of certificates expiring within the
+	// notification thresholds, once per threshold
+	NotifyCerts(ctx context.Context) ([]Notification, error)
+
+	// CRL returns DER encoded list of revoked certificates, signed by CA
+	CRL(ctx context.Context) ([]byte, error)
+
+	// OCSP returns DER encoded OCSP response to DER encoded OCSP request
+	OCSP(ctx context.Context, request []byte) ([]byte, error)
+
+	// CACert returns the certificate of CA issuing certificates
+	CACert(ctx context.Context) (*x509.Certificate, error)
+
+	// Enroll issues certificate for the key of DER encoded certificate
+	// request, for the thing which ID is request subject common name
+	Enroll(ctx context.Context, token string, csr []byte) (Cert, error)
+
+	// Reenroll issues certificate like Enroll, revoking the thing's
+	// current certificate
+	Reenroll(ctx context.Context, token string, csr []byte) (Cert, error)
 }
 
 // Config defines the service parameters
@@ -71,6 +136,10 @@ type Config struct {
 	PKIPath        string
 	PKIRole        string
 	PKIToken       string
+	RenewLeadTime  time.Duration
+	KeyType        string
+	KeyBits        int
+	NotifyBefore   []time.Duration
 }
 
 type certsService struct {
@@ -79,16 +148,18 @@ type certsService struct {
 	sdk       mfsdk.SDK
 	conf      Config
 	pki       pki.Agent
+	notifiers []Notifier
 }
 
 // New returns new Certs service.
-func New(auth mainflux.AuthServiceClient, certs Repository, sdk mfsdk.SDK, config Config, pki pki.Agent) Service {
+func New(auth mainflux.AuthServiceClient, certs Repository, sdk mfsdk.SDK, config Config, pki pki.Agent, notifiers []Notifier) Service {
 	return &certsService{
 		certsRepo: certs,
 		sdk:       sdk,
 		auth:      auth,
 		conf:      config,
 		pki:       pki,
+		notifiers: notifiers,
 	}
 }
 
@@ -108,6 +179,17 @@ type Cert struct {
 	PrivateKeyType string    `json:"private_key_type" mapstructure:"private_key_type"`
 	Serial         string    `json:"serial" mapstructure:"serial_number"`
 	Expire         time.Time `json:"expire" mapstructure:"-"`
+	KeyBits        int       `json:"key_bits" mapstructure:"-"`
+	// Notified is the lowest threshold the owner was notified about.
+	Notified time.Duration `json:"-" mapstructure:"-"`
+	// RevocationTime is set for the revoked certificates only.
+	RevocationTime time.Time `json:"-" mapstructure:"-"`
+}
+
+// Renewal defines the certificate issued in place of the expiring one
+type Renewal struct {
+	Cert       Cert
+	PrevSerial string
 }
 
 func (cs *certsService) IssueCert(ctx context.Context, token, thingID string, daysValid string, keyBits int, keyType string) (Cert, error) {
@@ -121,6 +203,13 @@ func (cs *certsService) IssueCert(ctx context.Context, token, thingID string, da
 		return Cert{}, errors.Wrap(ErrFailedCertCreation, err)
 	}
 
+	if keyType == "" {
+		keyType = cs.conf.KeyType
+		if keyBits == 0 {
+			keyBits = cs.conf.KeyBits
+		}
+	}
+
 	cert, err := cs.pki.IssueCert(thing.Key, daysValid, keyType, keyBits)
 	if err != nil {
 		return Cert{}, errors.Wrap(ErrFailedCertCreation, err)
@@ -136,6 +225,10 @@ func (cs *certsService) IssueCert(ctx context.Context, token, thingID string, da
 		PrivateKeyType: cert.PrivateKeyType,
 		Serial:         cert.Serial,
 		Expire:         cert.Expire,
+		KeyBits:        keyBits,
+	}
+	if c.PrivateKeyType == "" {
+		c.PrivateKeyType = keyType
 	}
 
 	_, err = cs.certsRepo.Save(context.Background(), c)
@@ -163,17 +256,442 @@ func (cs *certsService) RevokeCert(ctx context.Context, token, thingID string) (
 		return revoke, errors.Wrap(ErrFailedCertRevocation, err)
 	}
 	revoke.RevocationTime = revTime
+	if err := cs.certsRepo.SaveRevocation(ctx, revocation(cert, revTime)); err != nil {
+		return revoke, errors.Wrap(ErrFailedCertRevocation, err)
+	}
 	if err = cs.certsRepo.Remove(context.Background(), cert.Serial); err != nil {
 		return revoke, errors.Wrap(errFailedToRemoveCertFromDB, err)
 	}
 	return revoke, nil
 }
 
-func (cs *certsService) ListCerts(ctx context.Context, token, thingID string, offset, limit uint64) (Page, error) {
+func (cs *certsService) ListCerts(ctx context.Context, token string, pm PageMetadata) (Page, error) {
 	u, err := cs.auth.Identify(ctx, &mainflux.Token{Value: token})
 	if err != nil {
 		return Page{}, errors.Wrap(ErrUnauthorizedAccess, err)
 	}
 
-	return cs.certsRepo.RetrieveAll(ctx, u.GetEmail(), thingID, offset, limit)
+	if pm.Status == "" {
+		pm.Status = ActiveStatus
+	}
+
+	return cs.certsRepo.RetrieveAll(ctx, u.GetEmail(), pm)
+}
+
+func (cs *certsService) RenewCerts(ctx context.Context) ([]Renewal, error) {
+	before := time.Now().Add(cs.conf.RenewLeadTime)
+
+	renewals := []Renewal{}
+	var offset uint64
+	var renewErr error
+	for {
+		crts, err := cs.certsRepo.RetrieveExpiring(ctx, before, offset, renewBatchSize)
+		if err != nil {
+			return renewals, errors.Wrap(ErrFailedCertRenewal, err)
+		}
+
+		for _, c := range crts {
+			r, err := cs.renew(ctx, c, before)
+			if err != nil {
+				// Certificate that failed to renew stays in place to be
+				// renewed next time, so the following batches skip it.
+				renewErr = err
+				offset++
+				continue
+			}
+			renewals = append(renewals, r)
+		}
+
+		if uint64(len(crts)) < renewBatchSize {
+			break
+		}
+	}
+
+	if renewErr != nil {
+		return renewals, errors.Wrap(ErrFailedCertRenewal, renewErr)
+	}
+
+	return renewals, nil
+}
+
+func (cs *certsService) NotifyCerts(ctx context.Context) ([]Notification, error) {
+	notifications := []Notification{}
+	if len(cs.notifiers) == 0 || len(cs.conf.NotifyBefore) == 0 {
+		return notifications, nil
+	}
+
+	now := time.Now()
+	var longest time.Duration
+	for _, t := range cs.conf.NotifyBefore {
+		if t > longest {
+			longest = t
+		}
+	}
+
+	var offset uint64
+	var notifyErr error
+	for {
+		crts, err := cs.certsRepo.RetrieveExpiring(ctx, now.Add(longest), offset, notifyBatchSize)
+		if err != nil {
+			return notifications, errors.Wrap(ErrFailedCertNotification, err)
+		}
+
+		for _, c := range crts {
+			threshold, ok := cs.threshold(c, now)
+			if !ok {
+				continue
+			}
+			n := Notification{Cert: c, Threshold: threshold}
+			if err := cs.notify(ctx, n); err != nil {
+				// Owner is notified again next time.
+				notifyErr = err
+				continue
+			}
+			notifications = append(notifications, n)
+		}
+
+		offset += uint64(len(crts))
+		if uint64(len(crts)) < notifyBatchSize {
+			break
+		}
+	}
+
+	if notifyErr != nil {
+		return notifications, errors.Wrap(ErrFailedCertNotification, notifyErr)
+	}
+
+	return notifications, nil
+}
+
+// threshold returns the lowest threshold the certificate expires within,
+// unless the owner was already notified about it or it has expired.
+func (cs *certsService) threshold(c Cert, now time.Time) (time.Duration, bool) {
+	left := c.Expire.Sub(now)
+	if left <= 0 {
+		return 0, false
+	}
+
+	var threshold time.Duration
+	for _, t := range cs.conf.NotifyBefore {
+		if left <= t && (threshold == 0 || t < threshold) {
+			threshold = t
+		}
+	}
+
+	if threshold == 0 || c.Notified != 0 && c.Notified <= threshold {
+		return 0, false
+	}
+
+	return threshold, true
+}
+
+func (cs *certsService) notify(ctx context.Context, n Notification) error {
+	for _, notifier := range cs.notifiers {
+		if err := notifier.Notify(n); err != nil {
+			return err
+		}
+	}
+
+	return cs.certsRepo.UpdateNotified(ctx, n.Cert.Serial, n.Threshold)
+}
+
+// renew issues the certificate replacing the expiring one and hands it over
+// to bootstrap. Expiring certificate isn't revoked, so the thing can keep
+// using it until it fetches the renewed one, but it's no longer tracked.
+func (cs *certsService) renew(ctx context.Context, c Cert, before time.Time) (Renewal, error) {
+	key, err := cs.auth.Issue(ctx, &mainflux.IssueReq{Email: c.OwnerID, Type: loginKey})
+	if err != nil {
+		return Renewal{}, errors.Wrap(ErrUnauthorizedAccess, err)
+	}
+	token := key.GetValue()
+
+	thing, err := cs.sdk.Thing(c.ThingID, token)
+	if err != nil {
+		return Renewal{}, errors.Wrap(ErrFailedCertCreation, err)
+	}
+
+	cert, err := cs.pki.IssueCert(thing.Key, "", c.PrivateKeyType, c.KeyBits)
+	if err != nil {
+		return Renewal{}, errors.Wrap(ErrFailedCertCreation, err)
+	}
+
+	renewed := Cert{
+		ThingID:        c.ThingID,
+		OwnerID:        c.OwnerID,
+		ClientCert:     cert.ClientCert,
+		IssuingCA:      cert.IssuingCA,
+		CAChain:        cert.CAChain,
+		ClientKey:      cert.ClientKey,
+		PrivateKeyType: cert.PrivateKeyType,
+		Serial:         cert.Serial,
+		Expire:         cert.Expire,
+		KeyBits:        c.KeyBits,
+	}
+	if renewed.PrivateKeyType == "" {
+		renewed.PrivateKeyType = c.PrivateKeyType
+	}
+
+	if !renewed.Expire.After(before) {
+		cs.discard(ctx, renewed)
+		return Renewal{}, errRenewalExpiring
+	}
+
+	if _, err := cs.certsRepo.Save(ctx, renewed); err != nil {
+		cs.discard(ctx, renewed)
+		return Renewal{}, err
+	}
+
+	if err := cs.sdk.UpdateBootstrapCerts(token, c.ThingID, renewed.ClientCert, renewed.ClientKey, renewed.IssuingCA); err != nil {
+		cs.discard(ctx, renewed)
+		return Renewal{}, err
+	}
+
+	if err := cs.certsRepo.Remove(ctx, c.Serial); err != nil {
+		return Renewal{}, errors.Wrap(errFailedToRemoveCertFromDB, err)
+	}
+
+	return Renewal{Cert: renewed, PrevSerial: c.Serial}, nil
+}
+
+// discard revokes the renewed certificate that failed to replace the
+// expiring one.
+func (cs *certsService) discard(ctx context.Context, c Cert) {
+	if revTime, err := cs.pki.Revoke(c.Serial); err == nil {
+		cs.certsRepo.SaveRevocation(ctx, revocation(c, revTime))
+	}
+	cs.certsRepo.Remove(ctx, c.Serial)
+}
+
+func (cs *certsService) CRL(ctx context.Context) ([]byte, error) {
+	ca, key, err := cs.signer()
+	if err != nil {
+		return nil, errors.Wrap(ErrFailedCRLCreation, err)
+	}
+
+	revs, err := cs.certsRepo.RetrieveRevocations(ctx)
+	if err != nil {
+		return nil, errors.Wrap(ErrFailedCRLCreation, err)
+	}
+
+	revoked := []pkix.RevokedCertificate{}
+	for _, r := range revs {
+		n, ok := serialNumber(r.Serial)
+		if !ok {
+			return nil, errors.Wrap(ErrFailedCRLCreation, ErrMalformedEntity)
+		}
+		revoked = append(revoked, pkix.RevokedCertificate{
+			SerialNumber:   n,
+			RevocationTime: r.RevocationTime,
+		})
+	}
+
+	now := time.Now()
+	crl, err := ca.CreateCRL(rand.Reader, key, revoked, now, now.Add(statusValidity))
+	if err != nil {
+		return nil, errors.Wrap(ErrFailedCRLCreation, err)
+	}
+
+	return crl, nil
+}
+
+// OCSP reports certificates that aren't revoked as good, since CA signs
+// only the certificates issued by the service.
+func (cs *certsService) OCSP(ctx context.Context, request []byte) ([]byte, error) {
+	ca, key, err := cs.signer()
+	if err != nil {
+		return nil, errors.Wrap(ErrFailedOCSPResponse, err)
+	}
+
+	req, err := ocsp.ParseRequest(request)
+	if err != nil || !req.HashAlgorithm.Available() {
+		return ocsp.MalformedRequestErrorResponse, nil
+	}
+
+	if !issuedBy(req, ca) {
+		return ocsp.UnauthorizedErrorResponse, nil
+	}
+
+	now := time.Now()
+	tmpl := ocsp.Response{
+		Status:       ocsp.Good,
+		SerialNumber: req.SerialNumber,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(statusValidity),
+		IssuerHash:   req.HashAlgorithm,
+	}
+
+	for _, serial := range serialFormats(req.SerialNumber) {
+		rev, err := cs.certsRepo.RetrieveRevocation(ctx, serial)
+		if err == nil {
+			tmpl.Status = ocsp.Revoked
+			tmpl.RevokedAt = rev.RevocationTime
+			tmpl.RevocationReason = ocsp.Unspecified
+			break
+		}
+		if !errors.Contains(err, ErrNotFound) {
+			return nil, errors.Wrap(ErrFailedOCSPResponse, err)
+		}
+	}
+
+	res, err := ocsp.CreateResponse(ca, ca, tmpl, key)
+	if err != nil {
+		return nil, errors.Wrap(ErrFailedOCSPResponse, err)
+	}
+
+	return res, nil
+}
+
+func (cs *certsService) CACert(ctx context.Context) (*x509.Certificate, error) {
+	ca, err := cs.pki.CACert()
+	if err != nil {
+		return nil, errors.Wrap(errFailedCAFetch, err)
+	}
+
+	return ca, nil
+}
+
+func (cs *certsService) Enroll(ctx context.Context, token string, csr []byte) (Cert, error) {
+	owner, err := cs.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrUnauthorizedAccess, err)
+	}
+
+	return cs.enroll(ctx, token, owner.GetEmail(), csr)
+}
+
+func (cs *certsService) Reenroll(ctx context.Context, token string, csr []byte) (Cert, error) {
+	owner, err := cs.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrUnauthorizedAccess, err)
+	}
+
+	req, err := parseCSR(csr)
+	if err != nil {
+		return Cert{}, err
+	}
+
+	prev, err := cs.certsRepo.RetrieveByThing(ctx, req.Subject.CommonName)
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrNotFound, err)
+	}
+	if prev.OwnerID != owner.GetEmail() {
+		return Cert{}, ErrNotFound
+	}
+
+	// Replaced certificate is revoked before the new one is issued, since
+	// re-enrollment is also used to replace the compromised key.
+	revTime, err := cs.pki.Revoke(prev.Serial)
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrFailedCertRevocation, err)
+	}
+	if err := cs.certsRepo.SaveRevocation(ctx, revocation(prev, revTime)); err != nil {
+		return Cert{}, errors.Wrap(ErrFailedCertRevocation, err)
+	}
+	if err := cs.certsRepo.Remove(ctx, prev.Serial); err != nil {
+		return Cert{}, errors.Wrap(errFailedToRemoveCertFromDB, err)
+	}
+
+	return cs.enroll(ctx, token, owner.GetEmail(), csr)
+}
+
+func (cs *certsService) enroll(ctx context.Context, token, owner string, csr []byte) (Cert, error) {
+	req, err := parseCSR(csr)
+	if err != nil {
+		return Cert{}, err
+	}
+
+	thing, err := cs.sdk.Thing(req.Subject.CommonName, token)
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrFailedCertCreation, err)
+	}
+
+	cert, err := cs.pki.Sign(thing.Key, "", req)
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrFailedCertCreation, err)
+	}
+
+	keyType, keyBits := publicKeyType(req.PublicKey)
+	c := Cert{
+		ThingID:        thing.ID,
+		OwnerID:        owner,
+		ClientCert:     cert.ClientCert,
+		IssuingCA:      cert.IssuingCA,
+		CAChain:        cert.CAChain,
+		PrivateKeyType: keyType,
+		Serial:         cert.Serial,
+		Expire:         cert.Expire,
+		KeyBits:        keyBits,
+	}
+
+	if _, err := cs.certsRepo.Save(ctx, c); err != nil {
+		return Cert{}, err
+	}
+
+	return c, nil
+}
+
+// parseCSR parses DER encoded certificate request, checking its signature.
+func parseCSR(csr []byte) (*x509.CertificateRequest, error) {
+	req, err := x509.ParseCertificateRequest(csr)
+	if err != nil {
+		return nil, errors.Wrap(ErrMalformedEntity, err)
+	}
+	if err := req.CheckSignature(); err != nil {
+		return nil, errors.Wrap(ErrMalformedEntity, err)
+	}
+
+	return req, nil
+}
+
+// publicKeyType returns the type and the size of the key, the way they're
+// requested from PKI.
+func publicKeyType(pub interface{}) (string, int) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return "rsa", k.N.BitLen()
+	case *ecdsa.PublicKey:
+		return "ec", k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return "ed25519", 0
+	default:
+		return "", 0
+	}
+}
+
+// signer returns CA certificate and key used to sign CRL and OCSP
+// responses.
+func (cs *certsService) signer() (*x509.Certificate, crypto.Signer, error) {
+	key, ok := cs.conf.SignTLSCert.PrivateKey.(crypto.Signer)
+	if cs.conf.SignX509Cert == nil || !ok {
+		return nil, nil, errMissingSigner
+	}
+
+	return cs.conf.SignX509Cert, key, nil
+}
+
+// issuedBy checks whether OCSP request refers to the certificate issued
+// by the CA.
+func issuedBy(req *ocsp.Request, ca *x509.Certificate) bool {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(ca.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return false
+	}
+
+	h := req.HashAlgorithm.New()
+	h.Write(spki.PublicKey.RightAlign())
+
+	return bytes.Equal(h.Sum(nil), req.IssuerKeyHash)
+}
+
+func revocation(c Cert, revTime time.Time) Revocation {
+	return Revocation{
+		ThingID:        c.ThingID,
+		OwnerID:        c.OwnerID,
+		Serial:         c.Serial,
+		Expire:         c.Expire,
+		RevocationTime: revTime,
+	}
 }
@@ -5,11 +5,15 @@ package certs_test
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"strconv"
@@ -21,14 +25,15 @@ import (
 	bsmocks "github.com/mainflux/mainflux/bootstrap/mocks"
 	"github.com/mainflux/mainflux/certs"
 	"github.com/mainflux/mainflux/certs/mocks"
+	"github.com/mainflux/mainflux/certs/pki"
 	"github.com/mainflux/mainflux/pkg/errors"
 	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/mainflux/mainflux/things"
 	httpapi "github.com/mainflux/mainflux/things/api/things/http"
-	thmocks "github.com/mainflux/mainflux/things/mocks"
 	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
 )
 
 const (
@@ -57,15 +62,25 @@ const (
 	caKeyPath         = "../docker/ssl/certs/ca.key"
 	cfgSignHoursValid = "24h"
 	cfgSignRSABits    = 2048
+	cfgKeyType        = "ec"
 )
 
 func newService(tokens map[string]string) (certs.Service, error) {
+	return newRenewalService(tokens, "", 0)
+}
+
+func newRenewalService(tokens map[string]string, bootstrapURL string, renewLeadTime time.Duration) (certs.Service, error) {
+	return newConfiguredService(tokens, bootstrapURL, func(c *certs.Config) { c.RenewLeadTime = renewLeadTime }, nil)
+}
+
+func newConfiguredService(tokens map[string]string, bootstrapURL string, configure func(*certs.Config), notifiers []certs.Notifier) (certs.Service, error) {
 	users := bsmocks.NewUsersService(map[string]string{token: email})
 	server := newThingsServer(newThingsService(users))
 
-	auth := thmocks.NewAuthService(tokens)
+	auth := bsmocks.NewUsersService(tokens)
 	config := mfsdk.Config{
-		BaseURL: server.URL,
+		BaseURL:      server.URL,
+		BootstrapURL: bootstrapURL,
 	}
 
 	sdk := mfsdk.NewSDK(config)
@@ -90,15 +105,18 @@ func newService(tokens map[string]string) (certs.Service, error) {
 		ThingsPrefix:   cfgThingsPrefix,
 		JaegerURL:      cfgJaegerURL,
 		AuthURL:        cfgAuthURL,
+		AuthTimeout:    authTimeout,
 		SignTLSCert:    tlsCert,
 		SignX509Cert:   caCert,
 		SignHoursValid: cfgSignHoursValid,
 		SignRSABits:    cfgSignRSABits,
+		KeyType:        cfgKeyType,
 	}
+	configure(&c)
 
-	pki := mocks.NewPkiAgent(tlsCert, caCert, cfgSignRSABits, cfgSignHoursValid, authTimeout)
+	agent := pki.NewLocalAgent(tlsCert, caCert, cfgSignRSABits, cfgSignHoursValid)
 
-	return certs.New(auth, repo, sdk, c, pki), nil
+	return certs.New(auth, repo, sdk, c, agent, notifiers), nil
 }
 
 func newThingsService(auth mainflux.AuthServiceClient) things.Service {
@@ -126,6 +144,7 @@ func TestIssueCert(t *testing.T) {
 		daysValid string
 		key       string
 		keyBits   int
+		keyType   string
 		err       error
 	}{
 		{
@@ -135,6 +154,37 @@ func TestIssueCert(t *testing.T) {
 			daysValid: daysValid,
 			key:       key,
 			keyBits:   2048,
+			keyType:   key,
+			err:       nil,
+		},
+		{
+			desc:      "issue new cert with EC key",
+			token:     token,
+			thingID:   thingID,
+			daysValid: daysValid,
+			key:       "ec",
+			keyBits:   384,
+			keyType:   "ec",
+			err:       nil,
+		},
+		{
+			desc:      "issue new cert with Ed25519 key",
+			token:     token,
+			thingID:   thingID,
+			daysValid: daysValid,
+			key:       "ed25519",
+			keyBits:   0,
+			keyType:   "ed25519",
+			err:       nil,
+		},
+		{
+			desc:      "issue new cert with default key",
+			token:     token,
+			thingID:   thingID,
+			daysValid: daysValid,
+			key:       "",
+			keyBits:   0,
+			keyType:   cfgKeyType,
 			err:       nil,
 		},
 		{
@@ -181,6 +231,7 @@ func TestIssueCert(t *testing.T) {
 		cert, _ := readCert([]byte(c.ClientCert))
 		if cert != nil {
 			assert.True(t, strings.Contains(cert.Subject.CommonName, thingKey), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.keyType, c.PrivateKeyType, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.keyType, c.PrivateKeyType))
 		}
 	}
 
@@ -235,55 +286,87 @@ func TestListCerts(t *testing.T) {
 		require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
 	}
 
+	_, err = svc.IssueCert(context.Background(), token, thingID, cfgSignHoursValid, keyBits, key)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
+	_, err = svc.RevokeCert(context.Background(), token, thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert revocation error: %s\n", err))
+
+	window := time.Now().Add(2 * time.Hour)
+
 	cases := []struct {
-		token   string
-		desc    string
-		thingID string
-		offset  uint64
-		limit   uint64
-		size    uint64
-		err     error
+		token string
+		desc  string
+		pm    certs.PageMetadata
+		size  uint64
+		err   error
 	}{
 		{
-			desc:    "list all certs with valid token",
-			token:   token,
-			thingID: thingID,
-			offset:  0,
-			limit:   certNum,
-			size:    certNum,
-			err:     nil,
+			desc:  "list all certs with valid token",
+			token: token,
+			pm:    certs.PageMetadata{ThingID: thingID, Offset: 0, Limit: certNum},
+			size:  certNum,
+			err:   nil,
 		},
 		{
-			desc:    "list all certs with invalid token",
-			token:   wrongValue,
-			thingID: thingID,
-			offset:  0,
-			limit:   certNum,
-			size:    0,
-			err:     certs.ErrUnauthorizedAccess,
+			desc:  "list all certs with invalid token",
+			token: wrongValue,
+			pm:    certs.PageMetadata{ThingID: thingID, Offset: 0, Limit: certNum},
+			size:  0,
+			err:   certs.ErrUnauthorizedAccess,
 		},
 		{
-			desc:    "list half certs with invalid token",
-			token:   token,
-			thingID: thingID,
-			offset:  certNum / 2,
-			limit:   certNum,
-			size:    certNum / 2,
-			err:     nil,
+			desc:  "list half certs with invalid token",
+			token: token,
+			pm:    certs.PageMetadata{ThingID: thingID, Offset: certNum / 2, Limit: certNum},
+			size:  certNum / 2,
+			err:   nil,
 		},
 		{
-			desc:    "list last certs with invalid token",
-			token:   token,
-			thingID: thingID,
-			offset:  certNum - 1,
-			limit:   certNum,
-			size:    1,
-			err:     nil,
+			desc:  "list last certs with invalid token",
+			token: token,
+			pm:    certs.PageMetadata{ThingID: thingID, Offset: certNum - 1, Limit: certNum},
+			size:  1,
+			err:   nil,
+		},
+		{
+			desc:  "list revoked certs",
+			token: token,
+			pm:    certs.PageMetadata{Status: certs.RevokedStatus, Offset: 0, Limit: certNum},
+			size:  1,
+			err:   nil,
+		},
+		{
+			desc:  "list active and revoked certs",
+			token: token,
+			pm:    certs.PageMetadata{Status: certs.AllStatus, Offset: 0, Limit: certNum + 1},
+			size:  certNum + 1,
+			err:   nil,
+		},
+		{
+			desc:  "list certs expiring before given time",
+			token: token,
+			pm:    certs.PageMetadata{ExpiresBefore: window, Status: certs.AllStatus, Offset: 0, Limit: certNum + 1},
+			size:  certNum,
+			err:   nil,
+		},
+		{
+			desc:  "list certs expiring after given time",
+			token: token,
+			pm:    certs.PageMetadata{ExpiresAfter: window, Status: certs.AllStatus, Offset: 0, Limit: certNum + 1},
+			size:  1,
+			err:   nil,
+		},
+		{
+			desc:  "list certs of thing without certs",
+			token: token,
+			pm:    certs.PageMetadata{ThingID: wrongValue, Status: certs.AllStatus, Offset: 0, Limit: certNum + 1},
+			size:  0,
+			err:   nil,
 		},
 	}
 
 	for _, tc := range cases {
-		page, err := svc.ListCerts(context.Background(), tc.token, tc.thingID, tc.offset, tc.limit)
+		page, err := svc.ListCerts(context.Background(), tc.token, tc.pm)
 		size := uint64(len(page.Certs))
 		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", tc.desc, tc.size, size))
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
@@ -291,6 +374,389 @@ func TestListCerts(t *testing.T) {
 
 }
 
+func TestRenewCerts(t *testing.T) {
+	cases := []struct {
+		desc     string
+		leadTime time.Duration
+		status   int
+		renewed  int
+		updated  int
+		err      error
+	}{
+		{
+			desc:     "renew expiring cert",
+			leadTime: 2 * time.Hour,
+			status:   http.StatusOK,
+			renewed:  1,
+			updated:  1,
+			err:      nil,
+		},
+		{
+			desc:     "renew expiring cert with failed bootstrap update",
+			leadTime: 2 * time.Hour,
+			status:   http.StatusNotFound,
+			renewed:  0,
+			updated:  1,
+			err:      certs.ErrFailedCertRenewal,
+		},
+		{
+			desc:     "renew expiring cert with lead time exceeding validity",
+			leadTime: 48 * time.Hour,
+			status:   http.StatusOK,
+			renewed:  0,
+			updated:  0,
+			err:      certs.ErrFailedCertRenewal,
+		},
+		{
+			desc:     "renew with no expiring certs",
+			leadTime: 0,
+			status:   http.StatusOK,
+			renewed:  0,
+			updated:  0,
+			err:      nil,
+		},
+	}
+
+	for _, tc := range cases {
+		var updated []string
+		bs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			updated = append(updated, r.URL.Path)
+			w.WriteHeader(tc.status)
+		}))
+
+		svc, err := newRenewalService(map[string]string{token: email}, bs.URL, tc.leadTime)
+		require.Nil(t, err, fmt.Sprintf("unexpected service creation error: %s\n", err))
+
+		expiring, err := svc.IssueCert(context.Background(), token, thingID, daysValid, keyBits, key)
+		require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
+		_, err = svc.IssueCert(context.Background(), token, thingID, cfgSignHoursValid, keyBits, key)
+		require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
+
+		renewals, err := svc.RenewCerts(context.Background())
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.renewed, len(renewals), fmt.Sprintf("%s: expected %d got %d\n", tc.desc, tc.renewed, len(renewals)))
+		assert.Equal(t, tc.updated, len(updated), fmt.Sprintf("%s: expected %d got %d\n", tc.desc, tc.updated, len(updated)))
+		for _, r := range renewals {
+			assert.Equal(t, expiring.Serial, r.PrevSerial, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, expiring.Serial, r.PrevSerial))
+			assert.Equal(t, "/configs/certs/"+thingID, updated[0], fmt.Sprintf("%s: expected %s got %s\n", tc.desc, "/configs/certs/"+thingID, updated[0]))
+		}
+
+		page, err := svc.ListCerts(context.Background(), token, certs.PageMetadata{ThingID: thingID, Offset: 0, Limit: certNum})
+		require.Nil(t, err, fmt.Sprintf("unexpected cert listing error: %s\n", err))
+		assert.Equal(t, uint64(2), uint64(len(page.Certs)), fmt.Sprintf("%s: expected %d got %d\n", tc.desc, 2, len(page.Certs)))
+
+		bs.Close()
+	}
+}
+
+type notifierMock struct {
+	notifications []certs.Notification
+	err           error
+}
+
+func (n *notifierMock) Notify(nt certs.Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.notifications = append(n.notifications, nt)
+	return nil
+}
+
+func TestNotifyCerts(t *testing.T) {
+	notifier := &notifierMock{}
+	thresholds := []time.Duration{48 * time.Hour, 2 * time.Hour}
+	svc, err := newConfiguredService(map[string]string{token: email}, "", func(c *certs.Config) { c.NotifyBefore = thresholds }, []certs.Notifier{notifier})
+	require.Nil(t, err, fmt.Sprintf("unexpected service creation error: %s\n", err))
+
+	expiring, err := svc.IssueCert(context.Background(), token, thingID, daysValid, keyBits, key)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
+	valid, err := svc.IssueCert(context.Background(), token, thingID, cfgSignHoursValid, keyBits, key)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
+
+	cases := []struct {
+		desc          string
+		notifyErr     error
+		notifications []certs.Notification
+		err           error
+	}{
+		{
+			desc:          "notify expiring certs with failing notifier",
+			notifyErr:     errors.New("notification failed"),
+			notifications: []certs.Notification{},
+			err:           certs.ErrFailedCertNotification,
+		},
+		{
+			desc:      "notify expiring certs",
+			notifyErr: nil,
+			notifications: []certs.Notification{
+				{Cert: expiring, Threshold: 2 * time.Hour},
+				{Cert: valid, Threshold: 48 * time.Hour},
+			},
+			err: nil,
+		},
+		{
+			desc:          "notify already notified certs",
+			notifyErr:     nil,
+			notifications: []certs.Notification{},
+			err:           nil,
+		},
+	}
+
+	for _, tc := range cases {
+		notifier.err = tc.notifyErr
+		ns, err := svc.NotifyCerts(context.Background())
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		require.Equal(t, len(tc.notifications), len(ns), fmt.Sprintf("%s: expected %d got %d\n", tc.desc, len(tc.notifications), len(ns)))
+		for i, n := range ns {
+			assert.Equal(t, tc.notifications[i].Cert.Serial, n.Cert.Serial, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.notifications[i].Cert.Serial, n.Cert.Serial))
+			assert.Equal(t, tc.notifications[i].Threshold, n.Threshold, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.notifications[i].Threshold, n.Threshold))
+		}
+	}
+}
+
+func TestCRL(t *testing.T) {
+	svc, err := newService(map[string]string{token: email})
+	require.Nil(t, err, fmt.Sprintf("unexpected service creation error: %s\n", err))
+
+	_, caCert, err := loadCertificates(caPath, caKeyPath)
+	require.Nil(t, err, fmt.Sprintf("unexpected CA loading error: %s\n", err))
+
+	c, err := svc.IssueCert(context.Background(), token, thingID, daysValid, keyBits, key)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
+
+	cases := []struct {
+		desc    string
+		revoke  bool
+		revoked []string
+	}{
+		{
+			desc:    "create CRL without revoked certs",
+			revoke:  false,
+			revoked: []string{},
+		},
+		{
+			desc:    "create CRL with revoked cert",
+			revoke:  true,
+			revoked: []string{c.Serial},
+		},
+	}
+
+	for _, tc := range cases {
+		if tc.revoke {
+			_, err := svc.RevokeCert(context.Background(), token, thingID)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected cert revocation error: %s\n", tc.desc, err))
+		}
+
+		der, err := svc.CRL(context.Background())
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+
+		crl, err := x509.ParseCRL(der)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected CRL parsing error: %s\n", tc.desc, err))
+		err = caCert.CheckCRLSignature(crl)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected CRL signature error: %s\n", tc.desc, err))
+
+		revoked := []string{}
+		for _, rc := range crl.TBSCertList.RevokedCertificates {
+			revoked = append(revoked, rc.SerialNumber.String())
+		}
+		assert.Equal(t, tc.revoked, revoked, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.revoked, revoked))
+	}
+}
+
+func TestOCSP(t *testing.T) {
+	svc, err := newService(map[string]string{token: email})
+	require.Nil(t, err, fmt.Sprintf("unexpected service creation error: %s\n", err))
+
+	_, caCert, err := loadCertificates(caPath, caKeyPath)
+	require.Nil(t, err, fmt.Sprintf("unexpected CA loading error: %s\n", err))
+
+	good, err := svc.IssueCert(context.Background(), token, thingID, daysValid, keyBits, key)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
+	revoked, err := svc.IssueCert(context.Background(), token, thingID, daysValid, keyBits, key)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
+	_, err = svc.RevokeCert(context.Background(), token, thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert revocation error: %s\n", err))
+
+	goodCert, err := readCert([]byte(good.ClientCert))
+	require.Nil(t, err, fmt.Sprintf("unexpected cert parsing error: %s\n", err))
+	revokedCert, err := readCert([]byte(revoked.ClientCert))
+	require.Nil(t, err, fmt.Sprintf("unexpected cert parsing error: %s\n", err))
+
+	goodReq, err := ocsp.CreateRequest(goodCert, caCert, nil)
+	require.Nil(t, err, fmt.Sprintf("unexpected OCSP request error: %s\n", err))
+	revokedReq, err := ocsp.CreateRequest(revokedCert, caCert, nil)
+	require.Nil(t, err, fmt.Sprintf("unexpected OCSP request error: %s\n", err))
+	// The request for the cert claimed to be issued by another cert.
+	foreignReq, err := ocsp.CreateRequest(revokedCert, goodCert, nil)
+	require.Nil(t, err, fmt.Sprintf("unexpected OCSP request error: %s\n", err))
+
+	cases := []struct {
+		desc     string
+		request  []byte
+		status   int
+		response []byte
+	}{
+		{
+			desc:    "check status of good cert",
+			request: goodReq,
+			status:  ocsp.Good,
+		},
+		{
+			desc:    "check status of revoked cert",
+			request: revokedReq,
+			status:  ocsp.Revoked,
+		},
+		{
+			desc:     "check status of cert issued by another CA",
+			request:  foreignReq,
+			response: ocsp.UnauthorizedErrorResponse,
+		},
+		{
+			desc:     "check status with malformed request",
+			request:  []byte(wrongValue),
+			response: ocsp.MalformedRequestErrorResponse,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := svc.OCSP(context.Background(), tc.request)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+
+		if tc.response != nil {
+			assert.Equal(t, tc.response, res, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.response, res))
+			continue
+		}
+
+		resp, err := ocsp.ParseResponse(res, caCert)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected OCSP response parsing error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.status, resp.Status, fmt.Sprintf("%s: expected %d got %d\n", tc.desc, tc.status, resp.Status))
+	}
+}
+
+func TestEnroll(t *testing.T) {
+	svc, err := newService(map[string]string{token: email})
+	require.Nil(t, err, fmt.Sprintf("unexpected service creation error: %s\n", err))
+
+	_, caCert, err := loadCertificates(caPath, caKeyPath)
+	require.Nil(t, err, fmt.Sprintf("unexpected CA loading error: %s\n", err))
+
+	csr, err := newCSR(thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected CSR creation error: %s\n", err))
+	wrongCSR, err := newCSR("2")
+	require.Nil(t, err, fmt.Sprintf("unexpected CSR creation error: %s\n", err))
+
+	cases := []struct {
+		desc  string
+		token string
+		csr   []byte
+		err   error
+	}{
+		{
+			desc:  "enroll cert",
+			token: token,
+			csr:   csr,
+			err:   nil,
+		},
+		{
+			desc:  "enroll cert with invalid token",
+			token: wrongValue,
+			csr:   csr,
+			err:   certs.ErrUnauthorizedAccess,
+		},
+		{
+			desc:  "enroll cert for non existing thing",
+			token: token,
+			csr:   wrongCSR,
+			err:   certs.ErrFailedCertCreation,
+		},
+		{
+			desc:  "enroll cert with malformed CSR",
+			token: token,
+			csr:   []byte(wrongValue),
+			err:   certs.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		c, err := svc.Enroll(context.Background(), tc.token, tc.csr)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err != nil {
+			continue
+		}
+
+		cert, err := readCert([]byte(c.ClientCert))
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected cert parsing error: %s\n", tc.desc, err))
+		err = cert.CheckSignatureFrom(caCert)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected signature error: %s\n", tc.desc, err))
+		assert.Equal(t, thingKey, cert.Subject.CommonName, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, thingKey, cert.Subject.CommonName))
+		assert.Equal(t, thingID, c.ThingID, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, thingID, c.ThingID))
+	}
+}
+
+func TestReenroll(t *testing.T) {
+	svc, err := newService(map[string]string{token: email, wrongValue: wrongValue})
+	require.Nil(t, err, fmt.Sprintf("unexpected service creation error: %s\n", err))
+
+	csr, err := newCSR(thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected CSR creation error: %s\n", err))
+
+	_, err = svc.Reenroll(context.Background(), token, csr)
+	assert.True(t, errors.Contains(err, certs.ErrNotFound), fmt.Sprintf("reenroll cert without current cert: expected %s got %s\n", certs.ErrNotFound, err))
+
+	prev, err := svc.Enroll(context.Background(), token, csr)
+	require.Nil(t, err, fmt.Sprintf("unexpected enrollment error: %s\n", err))
+
+	cases := []struct {
+		desc  string
+		token string
+		err   error
+	}{
+		{
+			desc:  "reenroll cert of another user's thing",
+			token: wrongValue,
+			err:   certs.ErrNotFound,
+		},
+		{
+			desc:  "reenroll cert",
+			token: token,
+			err:   nil,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := svc.Reenroll(context.Background(), tc.token, csr)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	page, err := svc.ListCerts(context.Background(), token, certs.PageMetadata{ThingID: thingID, Offset: 0, Limit: certNum})
+	require.Nil(t, err, fmt.Sprintf("unexpected cert listing error: %s\n", err))
+	require.Equal(t, 1, len(page.Certs), fmt.Sprintf("expected %d certs got %d\n", 1, len(page.Certs)))
+	assert.NotEqual(t, prev.Serial, page.Certs[0].Serial, fmt.Sprintf("expected cert %s to be replaced\n", prev.Serial))
+
+	der, err := svc.CRL(context.Background())
+	require.Nil(t, err, fmt.Sprintf("unexpected CRL creation error: %s\n", err))
+	crl, err := x509.ParseCRL(der)
+	require.Nil(t, err, fmt.Sprintf("unexpected CRL parsing error: %s\n", err))
+	revoked := []string{}
+	for _, rc := range crl.TBSCertList.RevokedCertificates {
+		revoked = append(revoked, rc.SerialNumber.String())
+	}
+	assert.Equal(t, []string{prev.Serial}, revoked, fmt.Sprintf("expected cert %s to be revoked got %v\n", prev.Serial, revoked))
+}
+
+func newCSR(cn string) ([]byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl := x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: cn},
+	}
+
+	return x509.CreateCertificateRequest(rand.Reader, &tmpl, key)
+}
+
 func newThingsServer(svc things.Service) *httptest.Server {
 	mux := httpapi.MakeHandler(mocktracer.New(), svc)
 	return httptest.NewServer(mux)
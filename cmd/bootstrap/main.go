@@ -5,6 +5,7 @@ package main
 import (
 	"context"
 	"crypto/aes"
+	"crypto/tls"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -14,6 +15,7 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -32,6 +34,9 @@ import (
 	"github.com/mainflux/mainflux/bootstrap/postgres"
 	mflog "github.com/mainflux/mainflux/logger"
 	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/pkg/uuid"
+	piondtls "github.com/pion/dtls/v2"
+	gocoap "github.com/plgd-dev/go-coap/v2"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	jconfig "github.com/uber/jaeger-client-go/config"
 	"google.golang.org/grpc"
@@ -50,11 +55,16 @@ const (
 	defDBSSLKey       = ""
 	defDBSSLRootCert  = ""
 	defEncryptKey     = "12345678910111213141516171819202"
+	defMasterKeys     = ""
 	defClientTLS      = "false"
 	defCACerts        = ""
 	defPort           = "8180"
 	defServerCert     = ""
 	defServerKey      = ""
+	defCoAPPort       = ""
+	defCoAPCert       = ""
+	defCoAPKey        = ""
+	defAutoWhitelist  = "false"
 	defBaseURL        = "http://localhost"
 	defThingsPrefix   = ""
 	defThingsESURL    = "localhost:6379"
@@ -79,11 +89,16 @@ const (
 	envDBSSLKey       = "MF_BOOTSTRAP_DB_SSL_KEY"
 	envDBSSLRootCert  = "MF_BOOTSTRAP_DB_SSL_ROOT_CERT"
 	envEncryptKey     = "MF_BOOTSTRAP_ENCRYPT_KEY"
+	envMasterKeys     = "MF_BOOTSTRAP_MASTER_KEYS"
 	envClientTLS      = "MF_BOOTSTRAP_CLIENT_TLS"
 	envCACerts        = "MF_BOOTSTRAP_CA_CERTS"
 	envPort           = "MF_BOOTSTRAP_PORT"
 	envServerCert     = "MF_BOOTSTRAP_SERVER_CERT"
 	envServerKey      = "MF_BOOTSTRAP_SERVER_KEY"
+	envCoAPPort       = "MF_BOOTSTRAP_COAP_PORT"
+	envCoAPCert       = "MF_BOOTSTRAP_COAP_CERT"
+	envCoAPKey        = "MF_BOOTSTRAP_COAP_KEY"
+	envAutoWhitelist  = "MF_BOOTSTRAP_AUTO_WHITELIST"
 	envBaseURL        = "MF_SDK_BASE_URL"
 	envThingsPrefix   = "MF_SDK_THINGS_PREFIX"
 	envThingsESURL    = "MF_THINGS_ES_URL"
@@ -103,10 +118,15 @@ type config struct {
 	dbConfig       postgres.Config
 	clientTLS      bool
 	encKey         []byte
+	masterKeys     map[uint64][]byte
 	caCerts        string
 	httpPort       string
 	serverCert     string
 	serverKey      string
+	coapPort       string
+	coapCert       string
+	coapKey        string
+	autoWhitelist  bool
 	baseURL        string
 	thingsPrefix   string
 	esThingsURL    string
@@ -147,9 +167,14 @@ func main() {
 	auth := authapi.NewClient(authTracer, authConn, cfg.authTimeout)
 
 	svc := newService(auth, db, logger, esClient, cfg)
-	errs := make(chan error, 2)
+	errs := make(chan error, 3)
+
+	go rewrapKeys(svc, logger)
 
 	go startHTTPServer(svc, cfg, logger, errs)
+	if cfg.coapPort != "" {
+		go startCoAPServer(svc, cfg, logger, errs)
+	}
 	go subscribeToThingsES(svc, thingsESConn, cfg.esConsumerName, logger)
 
 	go func() {
@@ -179,6 +204,11 @@ func loadConfig() config {
 		SSLRootCert: mainflux.Env(envDBSSLRootCert, defDBSSLRootCert),
 	}
 
+	autoWhitelist, err := strconv.ParseBool(mainflux.Env(envAutoWhitelist, defAutoWhitelist))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAutoWhitelist, err.Error())
+	}
+
 	authTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
 	if err != nil {
 		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
@@ -193,16 +223,32 @@ func loadConfig() config {
 	if _, err := aes.NewCipher(encKey); err != nil {
 		log.Fatalf("Invalid %s value: %s", envEncryptKey, err.Error())
 	}
+	masterKeys, err := parseMasterKeys(mainflux.Env(envMasterKeys, defMasterKeys))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envMasterKeys, err.Error())
+	}
+	if err := os.Unsetenv(envMasterKeys); err != nil {
+		log.Fatalf("Unable to unset %s value: %s", envMasterKeys, err.Error())
+	}
+	// Encryption key is used as the master key unless master keys are set.
+	if len(masterKeys) == 0 {
+		masterKeys = map[uint64][]byte{1: encKey}
+	}
 
 	return config{
 		logLevel:       mainflux.Env(envLogLevel, defLogLevel),
 		dbConfig:       dbConfig,
 		clientTLS:      tls,
 		encKey:         encKey,
+		masterKeys:     masterKeys,
 		caCerts:        mainflux.Env(envCACerts, defCACerts),
 		httpPort:       mainflux.Env(envPort, defPort),
 		serverCert:     mainflux.Env(envServerCert, defServerCert),
 		serverKey:      mainflux.Env(envServerKey, defServerKey),
+		coapPort:       mainflux.Env(envCoAPPort, defCoAPPort),
+		coapCert:       mainflux.Env(envCoAPCert, defCoAPCert),
+		coapKey:        mainflux.Env(envCoAPKey, defCoAPKey),
+		autoWhitelist:  autoWhitelist,
 		baseURL:        mainflux.Env(envBaseURL, defBaseURL),
 		thingsPrefix:   mainflux.Env(envThingsPrefix, defThingsPrefix),
 		esThingsURL:    mainflux.Env(envThingsESURL, defThingsESURL),
@@ -267,6 +313,8 @@ func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer,
 
 func newService(auth mainflux.AuthServiceClient, db *sqlx.DB, logger mflog.Logger, esClient *r.Client, cfg config) bootstrap.Service {
 	thingsRepo := postgres.NewConfigRepository(db, logger)
+	templatesRepo := postgres.NewTemplateRepository(db, logger)
+	patternsRepo := postgres.NewPatternRepository(db, logger)
 
 	config := mfsdk.Config{
 		BaseURL:      cfg.baseURL,
@@ -275,7 +323,13 @@ func newService(auth mainflux.AuthServiceClient, db *sqlx.DB, logger mflog.Logge
 
 	sdk := mfsdk.NewSDK(config)
 
-	svc := bootstrap.New(auth, thingsRepo, sdk, cfg.encKey)
+	keyring, err := bootstrap.NewKeyring(cfg.masterKeys)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Invalid %s value: %s", envMasterKeys, err))
+		os.Exit(1)
+	}
+
+	svc := bootstrap.New(auth, thingsRepo, templatesRepo, patternsRepo, sdk, uuid.New(), keyring, cfg.encKey)
 	svc = redisprod.NewEventStoreMiddleware(svc, esClient)
 	svc = api.NewLoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
@@ -296,6 +350,44 @@ func newService(auth mainflux.AuthServiceClient, db *sqlx.DB, logger mflog.Logge
 	return svc
 }
 
+// parseMasterKeys parses the comma-separated list of the master keys, each
+// given as the version followed by the hex encoded key, e.g. "1:abcd,2:ef01".
+func parseMasterKeys(val string) (map[uint64][]byte, error) {
+	keys := map[uint64][]byte{}
+	if val == "" {
+		return keys, nil
+	}
+
+	for _, entry := range strings.Split(val, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("malformed master key %q", entry)
+		}
+
+		v, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := hex.DecodeString(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		keys[v] = key
+	}
+
+	return keys, nil
+}
+
+func rewrapKeys(svc bootstrap.Service, logger mflog.Logger) {
+	count, err := svc.RewrapKeys(context.Background())
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to re-encrypt bootstrap keys: %s", err))
+		return
+	}
+	logger.Info(fmt.Sprintf("Re-encrypted %d bootstrap keys with the current master key", count))
+}
+
 func connectToAuth(cfg config, logger logger.Logger) *grpc.ClientConn {
 	var opts []grpc.DialOption
 	if cfg.clientTLS {
@@ -326,11 +418,33 @@ func startHTTPServer(svc bootstrap.Service, cfg config, logger mflog.Logger, err
 	if cfg.serverCert != "" || cfg.serverKey != "" {
 		logger.Info(fmt.Sprintf("Bootstrap service started using https on port %s with cert %s key %s",
 			cfg.httpPort, cfg.serverCert, cfg.serverKey))
-		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(svc, bootstrap.NewConfigReader(cfg.encKey)))
+		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(svc, bootstrap.NewConfigReader(cfg.encKey), cfg.autoWhitelist))
 		return
 	}
 	logger.Info(fmt.Sprintf("Bootstrap service started using http on port %s", cfg.httpPort))
-	errs <- http.ListenAndServe(p, api.MakeHandler(svc, bootstrap.NewConfigReader(cfg.encKey)))
+	errs <- http.ListenAndServe(p, api.MakeHandler(svc, bootstrap.NewConfigReader(cfg.encKey), cfg.autoWhitelist))
+}
+
+func startCoAPServer(svc bootstrap.Service, cfg config, logger mflog.Logger, errs chan error) {
+	p := fmt.Sprintf(":%s", cfg.coapPort)
+	handler := api.MakeCoAPHandler(svc, bootstrap.NewConfigReader(cfg.encKey), logger)
+	if cfg.coapCert != "" || cfg.coapKey != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.coapCert, cfg.coapKey)
+		if err != nil {
+			errs <- err
+			return
+		}
+		dtlsCfg := &piondtls.Config{
+			Certificates:         []tls.Certificate{cert},
+			ExtendedMasterSecret: piondtls.RequireExtendedMasterSecret,
+		}
+		logger.Info(fmt.Sprintf("Bootstrap service started using CoAP over DTLS on port %s with cert %s key %s",
+			cfg.coapPort, cfg.coapCert, cfg.coapKey))
+		errs <- gocoap.ListenAndServeDTLS("udp", p, dtlsCfg, handler)
+		return
+	}
+	logger.Info(fmt.Sprintf("Bootstrap service started using CoAP on port %s", cfg.coapPort))
+	errs <- gocoap.ListenAndServe("udp", p, handler)
 }
 
 func subscribeToThingsES(svc bootstrap.Service, client *r.Client, consumer string, logger mflog.Logger) {
@@ -8,6 +8,7 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,12 +18,17 @@ import (
 	"time"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	r "github.com/go-redis/redis/v8"
 	"github.com/gocql/gocql"
 	"github.com/mainflux/mainflux"
+	authapi "github.com/mainflux/mainflux/auth/api/grpc"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/readers"
 	"github.com/mainflux/mainflux/readers/api"
+	grpcapi "github.com/mainflux/mainflux/readers/api/grpc"
 	"github.com/mainflux/mainflux/readers/cassandra"
+	"github.com/mainflux/mainflux/readers/redis"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -35,7 +41,9 @@ const (
 	sep = ","
 
 	defLogLevel          = "error"
+	defNatsURL           = "nats://localhost:4222"
 	defPort              = "8180"
+	defGRPCPort          = "8191"
 	defCluster           = "127.0.0.1"
 	defKeyspace          = "mainflux"
 	defDBUser            = "mainflux"
@@ -48,9 +56,16 @@ const (
 	defJaegerURL         = ""
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
+	defAuthURL           = "localhost:8181"
+	defAuthTimeout       = "1s"
+	defESURL             = ""
+	defESPass            = ""
+	defESDB              = "0"
 
 	envLogLevel          = "MF_CASSANDRA_READER_LOG_LEVEL"
+	envNatsURL           = "MF_NATS_URL"
 	envPort              = "MF_CASSANDRA_READER_PORT"
+	envGRPCPort          = "MF_CASSANDRA_READER_GRPC_PORT"
 	envCluster           = "MF_CASSANDRA_READER_DB_CLUSTER"
 	envKeyspace          = "MF_CASSANDRA_READER_DB_KEYSPACE"
 	envDBUser            = "MF_CASSANDRA_READER_DB_USER"
@@ -63,11 +78,18 @@ const (
 	envJaegerURL         = "MF_JAEGER_URL"
 	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
 	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envAuthURL           = "MF_AUTH_GRPC_URL"
+	envAuthTimeout       = "MF_AUTH_GRPC_TIMEOUT"
+	envESURL             = "MF_CASSANDRA_READER_ES_URL"
+	envESPass            = "MF_CASSANDRA_READER_ES_PASS"
+	envESDB              = "MF_CASSANDRA_READER_ES_DB"
 )
 
 type config struct {
 	logLevel          string
+	natsURL           string
 	port              string
+	grpcPort          string
 	dbCfg             cassandra.DBConfig
 	clientTLS         bool
 	caCerts           string
@@ -76,6 +98,11 @@ type config struct {
 	jaegerURL         string
 	thingsAuthURL     string
 	thingsAuthTimeout time.Duration
+	authURL           string
+	authTimeout       time.Duration
+	esURL             string
+	esPass            string
+	esDB              string
 }
 
 func main() {
@@ -96,11 +123,39 @@ func main() {
 	defer thingsCloser.Close()
 
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
-	repo := newService(session, logger)
+
+	authConn := connectToAuth(cfg, logger)
+	defer authConn.Close()
+
+	authTracer, authCloser := initJaeger("auth", cfg.jaegerURL, logger)
+	defer authCloser.Close()
+
+	ac := authapi.NewClient(authTracer, authConn, cfg.authTimeout)
+	var esClient *r.Client
+	if cfg.esURL != "" {
+		esClient = connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
+		defer esClient.Close()
+	}
+
+	repo := newService(session, esClient, logger)
+
+	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
+	}
+	defer pubSub.Close()
+
+	stream := readers.NewLiveStream()
+	if err := pubSub.Subscribe(nats.SubjectAllChannels, stream.Handle); err != nil {
+		logger.Error(fmt.Sprintf("Failed to subscribe to live messages: %s", err))
+		os.Exit(1)
+	}
 
 	errs := make(chan error, 2)
 
-	go startHTTPServer(repo, tc, cfg, errs, logger)
+	go startHTTPServer(repo, tc, ac, stream, cfg, errs, logger)
+	go startGRPCServer(repo, tc, ac, cfg, errs, logger)
 
 	go func() {
 		c := make(chan os.Signal)
@@ -131,14 +186,21 @@ func loadConfig() config {
 		log.Fatalf("Invalid value passed for %s\n", envClientTLS)
 	}
 
-	authTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
+	thingsAuthTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
 	if err != nil {
 		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
 	}
 
+	authTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
+	}
+
 	return config{
 		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
+		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
 		port:              mainflux.Env(envPort, defPort),
+		grpcPort:          mainflux.Env(envGRPCPort, defGRPCPort),
 		dbCfg:             dbCfg,
 		clientTLS:         tls,
 		caCerts:           mainflux.Env(envCACerts, defCACerts),
@@ -146,7 +208,12 @@ func loadConfig() config {
 		serverKey:         mainflux.Env(envServerKey, defServerKey),
 		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
-		thingsAuthTimeout: authTimeout,
+		thingsAuthTimeout: thingsAuthTimeout,
+		authURL:           mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout:       authTimeout,
+		esURL:             mainflux.Env(envESURL, defESURL),
+		esPass:            mainflux.Env(envESPass, defESPass),
+		esDB:              mainflux.Env(envESDB, defESDB),
 	}
 }
 
@@ -184,6 +251,29 @@ func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
 	return conn
 }
 
+func connectToAuth(cfg config, logger logger.Logger) *grpc.ClientConn {
+	var opts []grpc.DialOption
+	if cfg.clientTLS {
+		if cfg.caCerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.caCerts, "")
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to load certs: %s", err))
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.WithTransportCredentials(tpc))
+		}
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(cfg.authURL, opts...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to auth service: %s", err))
+		os.Exit(1)
+	}
+	return conn
+}
+
 func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer, io.Closer) {
 	if url == "" {
 		return opentracing.NoopTracer{}, ioutil.NopCloser(nil)
@@ -208,8 +298,25 @@ func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer,
 	return tracer, closer
 }
 
-func newService(session *gocql.Session, logger logger.Logger) readers.MessageRepository {
+func connectToRedis(redisURL, redisPass, redisDB string, logger logger.Logger) *r.Client {
+	db, err := strconv.Atoi(redisDB)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to redis: %s", err))
+		os.Exit(1)
+	}
+
+	return r.NewClient(&r.Options{
+		Addr:     redisURL,
+		Password: redisPass,
+		DB:       db,
+	})
+}
+
+func newService(session *gocql.Session, esClient *r.Client, logger logger.Logger) readers.MessageRepository {
 	repo := cassandra.New(session)
+	if esClient != nil {
+		repo = redis.NewEventStoreMiddleware(repo, esClient)
+	}
 	repo = api.LoggingMiddleware(repo, logger)
 	repo = api.MetricsMiddleware(
 		repo,
@@ -230,14 +337,41 @@ func newService(session *gocql.Session, logger logger.Logger) readers.MessageRep
 	return repo
 }
 
-func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, cfg config, errs chan error, logger logger.Logger) {
+func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient, stream readers.LiveStream, cfg config, errs chan error, logger logger.Logger) {
 	p := fmt.Sprintf(":%s", cfg.port)
 	if cfg.serverCert != "" || cfg.serverKey != "" {
 		logger.Info(fmt.Sprintf("Cassandra reader service started using https on port %s with cert %s key %s",
 			cfg.port, cfg.serverCert, cfg.serverKey))
-		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tc, "cassandra-reader"))
+		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tc, ac, stream, "cassandra-reader"))
 		return
 	}
 	logger.Info(fmt.Sprintf("Cassandra reader service started, exposed port %s", cfg.port))
-	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, "cassandra-reader"))
+	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, ac, stream, "cassandra-reader"))
+}
+
+func startGRPCServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient, cfg config, errs chan error, logger logger.Logger) {
+	p := fmt.Sprintf(":%s", cfg.grpcPort)
+	listener, err := net.Listen("tcp", p)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to listen on port %s: %s", cfg.grpcPort, err))
+		os.Exit(1)
+	}
+
+	var server *grpc.Server
+	if cfg.serverCert != "" || cfg.serverKey != "" {
+		creds, err := credentials.NewServerTLSFromFile(cfg.serverCert, cfg.serverKey)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to load cassandra reader certificates: %s", err))
+			os.Exit(1)
+		}
+		logger.Info(fmt.Sprintf("Cassandra reader gRPC service started using https on port %s with cert %s key %s",
+			cfg.grpcPort, cfg.serverCert, cfg.serverKey))
+		server = grpc.NewServer(grpc.Creds(creds))
+	} else {
+		logger.Info(fmt.Sprintf("Cassandra reader gRPC service started using http on port %s", cfg.grpcPort))
+		server = grpc.NewServer()
+	}
+
+	mainflux.RegisterReadersServiceServer(server, grpcapi.NewServer(repo, tc, ac))
+	errs <- server.Serve(listener)
 }
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,58 +13,146 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
-	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	r "github.com/go-redis/redis/v8"
 	"github.com/gocql/gocql"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/writers"
 	"github.com/mainflux/mainflux/consumers/writers/api"
 	"github.com/mainflux/mainflux/consumers/writers/cassandra"
+	"github.com/mainflux/mainflux/consumers/writers/redis"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/avro"
 	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/lpp"
+	"github.com/mainflux/mainflux/pkg/transformers/lua"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
-	stdprometheus "github.com/prometheus/client_golang/prometheus"
 )
 
 const (
 	svcName = "cassandra-writer"
 	sep     = ","
 
-	defNatsURL     = "nats://localhost:4222"
-	defLogLevel    = "error"
-	defPort        = "8180"
-	defCluster     = "127.0.0.1"
-	defKeyspace    = "mainflux"
-	defDBUser      = "mainflux"
-	defDBPass      = "mainflux"
-	defDBPort      = "9042"
-	defConfigPath  = "/config.toml"
-	defContentType = "application/senml+json"
-	defTransformer = "senml"
-
-	envNatsURL     = "MF_NATS_URL"
-	envLogLevel    = "MF_CASSANDRA_WRITER_LOG_LEVEL"
-	envPort        = "MF_CASSANDRA_WRITER_PORT"
-	envCluster     = "MF_CASSANDRA_WRITER_DB_CLUSTER"
-	envKeyspace    = "MF_CASSANDRA_WRITER_DB_KEYSPACE"
-	envDBUser      = "MF_CASSANDRA_WRITER_DB_USER"
-	envDBPass      = "MF_CASSANDRA_WRITER_DB_PASS"
-	envDBPort      = "MF_CASSANDRA_WRITER_DB_PORT"
-	envConfigPath  = "MF_CASSANDRA_WRITER_CONFIG_PATH"
-	envContentType = "MF_CASSANDRA_WRITER_CONTENT_TYPE"
-	envTransformer = "MF_CASSANDRA_WRITER_TRANSFORMER"
+	defNatsURL               = "nats://localhost:4222"
+	defLogLevel              = "error"
+	defPort                  = "8180"
+	defCluster               = "127.0.0.1"
+	defKeyspace              = "mainflux"
+	defDBUser                = "mainflux"
+	defDBPass                = "mainflux"
+	defDBPort                = "9042"
+	defConfigPath            = "/config.toml"
+	defHookPath              = ""
+	defQueueSize             = "0"
+	defDedupWindow           = "0s"
+	defDedupSize             = "100000"
+	defDedupIDField          = ""
+	defDedupRedisURL         = ""
+	defDedupRedisPass        = ""
+	defDedupRedisDB          = "0"
+	defESURL                 = ""
+	defESPass                = ""
+	defESDB                  = "0"
+	defESConsumerName        = svcName
+	defContentType           = "application/senml+json"
+	defTransformer           = "senml"
+	defSenMLStrict           = "false"
+	defJSONNamePath          = ""
+	defJSONValuePath         = ""
+	defJSONTimePath          = ""
+	defSchemasRedisURL       = ""
+	defSchemasRedisPass      = ""
+	defSchemasRedisDB        = "0"
+	defSchemaRegistryURL     = ""
+	defSchemaRegistryTimeout = "5s"
+	defAdminToken            = ""
+	defJSONNested            = "false"
+	defBatchSize             = "1"
+	defFlushInterval         = "1s"
+	defRetryInterval         = "500ms"
+	defRetryMaxTime          = "0s"
+	defDeadLetterSubject     = ""
+
+	envNatsURL               = "MF_NATS_URL"
+	envLogLevel              = "MF_CASSANDRA_WRITER_LOG_LEVEL"
+	envPort                  = "MF_CASSANDRA_WRITER_PORT"
+	envCluster               = "MF_CASSANDRA_WRITER_DB_CLUSTER"
+	envKeyspace              = "MF_CASSANDRA_WRITER_DB_KEYSPACE"
+	envDBUser                = "MF_CASSANDRA_WRITER_DB_USER"
+	envDBPass                = "MF_CASSANDRA_WRITER_DB_PASS"
+	envDBPort                = "MF_CASSANDRA_WRITER_DB_PORT"
+	envConfigPath            = "MF_CASSANDRA_WRITER_CONFIG_PATH"
+	envHookPath              = "MF_CASSANDRA_WRITER_HOOK_PATH"
+	envQueueSize             = "MF_CASSANDRA_WRITER_QUEUE_SIZE"
+	envDedupWindow           = "MF_CASSANDRA_WRITER_DEDUP_WINDOW"
+	envDedupSize             = "MF_CASSANDRA_WRITER_DEDUP_SIZE"
+	envDedupIDField          = "MF_CASSANDRA_WRITER_DEDUP_ID_FIELD"
+	envDedupRedisURL         = "MF_CASSANDRA_WRITER_DEDUP_REDIS_URL"
+	envDedupRedisPass        = "MF_CASSANDRA_WRITER_DEDUP_REDIS_PASS"
+	envDedupRedisDB          = "MF_CASSANDRA_WRITER_DEDUP_REDIS_DB"
+	envESURL                 = "MF_THINGS_ES_URL"
+	envESPass                = "MF_THINGS_ES_PASS"
+	envESDB                  = "MF_THINGS_ES_DB"
+	envESConsumerName        = "MF_CASSANDRA_WRITER_EVENT_CONSUMER"
+	envContentType           = "MF_CASSANDRA_WRITER_CONTENT_TYPE"
+	envTransformer           = "MF_CASSANDRA_WRITER_TRANSFORMER"
+	envSenMLStrict           = "MF_CASSANDRA_WRITER_SENML_STRICT"
+	envJSONNamePath          = "MF_CASSANDRA_WRITER_JSON_NAME_PATH"
+	envJSONValuePath         = "MF_CASSANDRA_WRITER_JSON_VALUE_PATH"
+	envJSONTimePath          = "MF_CASSANDRA_WRITER_JSON_TIME_PATH"
+	envSchemasRedisURL       = "MF_CASSANDRA_WRITER_SCHEMAS_REDIS_URL"
+	envSchemasRedisPass      = "MF_CASSANDRA_WRITER_SCHEMAS_REDIS_PASS"
+	envSchemasRedisDB        = "MF_CASSANDRA_WRITER_SCHEMAS_REDIS_DB"
+	envSchemaRegistryURL     = "MF_CASSANDRA_WRITER_SCHEMA_REGISTRY_URL"
+	envSchemaRegistryTimeout = "MF_CASSANDRA_WRITER_SCHEMA_REGISTRY_TIMEOUT"
+	envAdminToken            = "MF_CASSANDRA_WRITER_ADMIN_TOKEN"
+	envJSONNested            = "MF_CASSANDRA_WRITER_JSON_NESTED"
+	envBatchSize             = "MF_CASSANDRA_WRITER_BATCH_SIZE"
+	envFlushInterval         = "MF_CASSANDRA_WRITER_FLUSH_INTERVAL"
+	envRetryInterval         = "MF_CASSANDRA_WRITER_RETRY_INTERVAL"
+	envRetryMaxTime          = "MF_CASSANDRA_WRITER_RETRY_MAX_TIME"
+	envDeadLetterSubject     = "MF_CASSANDRA_WRITER_DEAD_LETTER_SUBJECT"
 )
 
 type config struct {
-	natsURL     string
-	logLevel    string
-	port        string
-	configPath  string
-	contentType string
-	transformer string
-	dbCfg       cassandra.DBConfig
+	natsURL               string
+	logLevel              string
+	port                  string
+	configPath            string
+	hookPath              string
+	queueSize             int
+	dedupWindow           time.Duration
+	dedupSize             int
+	dedupIDField          string
+	dedupRedisURL         string
+	dedupRedisPass        string
+	dedupRedisDB          string
+	esURL                 string
+	esPass                string
+	esDB                  string
+	esConsumerName        string
+	contentType           string
+	transformer           string
+	senmlStrict           bool
+	jsonMapping           json.Mapping
+	schemasRedisURL       string
+	schemasRedisPass      string
+	schemasRedisDB        string
+	schemaRegistryURL     string
+	schemaRegistryTimeout time.Duration
+	adminToken            string
+	jsonNested            bool
+	batchSize             int
+	flushInterval         time.Duration
+	retryInterval         time.Duration
+	retryMaxTime          time.Duration
+	deadLetterSubject     string
+	dbCfg                 cassandra.DBConfig
 }
 
 func main() {
@@ -84,21 +173,62 @@ func main() {
 	session := connectToCassandra(cfg.dbCfg, logger)
 	defer session.Close()
 
-	repo := newService(session, logger)
-	t := makeTransformer(cfg, logger)
+	ttl, err := cassandra.LoadTTL(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to load TTL: %s", err))
+	}
+
+	metrics := api.MakeMetrics("cassandra", pubSub.Pending)
+	repo := newService(session, newRouter(cfg, logger), cfg.dbCfg, ttl, metrics, logger)
+	deadLetter := connectToDeadLetter(cfg, logger)
+	if deadLetter != nil {
+		defer deadLetter.Close()
+	}
+	repo = writers.NewRetryConsumer(repo, deadLetter, cfg.retryInterval, cfg.retryMaxTime, logger)
+	repo = writers.NewBatchConsumer(repo, cfg.batchSize, cfg.flushInterval, logger)
+	queue := writers.NewQueueConsumer(repo, cfg.queueSize, metrics.QueueDepth, metrics.Dropped)
+	repo = writers.NewDedupConsumer(queue, newDedupCache(cfg, logger), cfg.dedupIDField, logger)
+	filter, err := writers.LoadFilter(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to load filter: %s", err))
+	}
+	repo = writers.NewFilterConsumer(repo, filter)
+	repo = writers.NewHookConsumer(repo, loadHook(cfg, logger))
+	schemas := newSchemaRepository(cfg, logger)
+	t := makeTransformer(cfg, schemas, logger)
+
+	subjects, subErr := consumers.StartReloadable(pubSub, repo, t, cfg.configPath, logger)
+	if subErr != nil {
+		logger.Error(fmt.Sprintf("Failed to create Cassandra writer: %s", subErr))
+	}
+	go reloadSubjects(subjects, logger)
 
-	if err := consumers.Start(pubSub, repo, t, cfg.configPath, logger); err != nil {
-		logger.Error(fmt.Sprintf("Failed to create Cassandra writer: %s", err))
+	checks := map[string]func() error{
+		"nats": func() error {
+			if subErr != nil {
+				return subErr
+			}
+			return pubSub.Status()
+		},
+		"database": func() error {
+			return session.Query("SELECT now() FROM system.local").Exec()
+		},
 	}
 
 	errs := make(chan error, 2)
 
-	go startHTTPServer(cfg.port, errs, logger)
+	go startHTTPServer(cfg.port, checks, schemas, cfg.adminToken, errs, logger)
 
 	go func() {
-		c := make(chan os.Signal)
-		signal.Notify(c, syscall.SIGINT)
-		errs <- fmt.Errorf("%s", <-c)
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-c
+		// The queued and buffered messages are saved before the service
+		// terminates.
+		if err := writers.Close(queue); err != nil {
+			logger.Error(fmt.Sprintf("Failed to save queued messages: %s", err))
+		}
+		errs <- fmt.Errorf("%s", sig)
 	}()
 
 	err = <-errs
@@ -106,6 +236,21 @@ func main() {
 }
 
 func loadConfig() config {
+	queueSize, err := strconv.Atoi(mainflux.Env(envQueueSize, defQueueSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envQueueSize, err.Error())
+	}
+
+	dedupWindow, err := time.ParseDuration(mainflux.Env(envDedupWindow, defDedupWindow))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envDedupWindow, err.Error())
+	}
+
+	dedupSize, err := strconv.Atoi(mainflux.Env(envDedupSize, defDedupSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envDedupSize, err.Error())
+	}
+
 	dbPort, err := strconv.Atoi(mainflux.Env(envDBPort, defDBPort))
 	if err != nil {
 		log.Fatal(err)
@@ -119,14 +264,79 @@ func loadConfig() config {
 		Port:     dbPort,
 	}
 
+	batchSize, err := strconv.Atoi(mainflux.Env(envBatchSize, defBatchSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envBatchSize, err.Error())
+	}
+
+	flushInterval, err := time.ParseDuration(mainflux.Env(envFlushInterval, defFlushInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envFlushInterval, err.Error())
+	}
+
+	retryInterval, err := time.ParseDuration(mainflux.Env(envRetryInterval, defRetryInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetryInterval, err.Error())
+	}
+
+	retryMaxTime, err := time.ParseDuration(mainflux.Env(envRetryMaxTime, defRetryMaxTime))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetryMaxTime, err.Error())
+	}
+
+	jsonNested, err := strconv.ParseBool(mainflux.Env(envJSONNested, defJSONNested))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envJSONNested, err.Error())
+	}
+
+	senmlStrict, err := strconv.ParseBool(mainflux.Env(envSenMLStrict, defSenMLStrict))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envSenMLStrict, err.Error())
+	}
+
+	registryTimeout, err := time.ParseDuration(mainflux.Env(envSchemaRegistryTimeout, defSchemaRegistryTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envSchemaRegistryTimeout, err.Error())
+	}
+
 	return config{
-		natsURL:     mainflux.Env(envNatsURL, defNatsURL),
-		logLevel:    mainflux.Env(envLogLevel, defLogLevel),
-		port:        mainflux.Env(envPort, defPort),
-		configPath:  mainflux.Env(envConfigPath, defConfigPath),
-		contentType: mainflux.Env(envContentType, defContentType),
-		transformer: mainflux.Env(envTransformer, defTransformer),
-		dbCfg:       dbCfg,
+		natsURL:        mainflux.Env(envNatsURL, defNatsURL),
+		logLevel:       mainflux.Env(envLogLevel, defLogLevel),
+		port:           mainflux.Env(envPort, defPort),
+		configPath:     mainflux.Env(envConfigPath, defConfigPath),
+		hookPath:       mainflux.Env(envHookPath, defHookPath),
+		queueSize:      queueSize,
+		dedupWindow:    dedupWindow,
+		dedupSize:      dedupSize,
+		dedupIDField:   mainflux.Env(envDedupIDField, defDedupIDField),
+		dedupRedisURL:  mainflux.Env(envDedupRedisURL, defDedupRedisURL),
+		dedupRedisPass: mainflux.Env(envDedupRedisPass, defDedupRedisPass),
+		dedupRedisDB:   mainflux.Env(envDedupRedisDB, defDedupRedisDB),
+		esURL:          mainflux.Env(envESURL, defESURL),
+		esPass:         mainflux.Env(envESPass, defESPass),
+		esDB:           mainflux.Env(envESDB, defESDB),
+		esConsumerName: mainflux.Env(envESConsumerName, defESConsumerName),
+		contentType:    mainflux.Env(envContentType, defContentType),
+		transformer:    mainflux.Env(envTransformer, defTransformer),
+		senmlStrict:    senmlStrict,
+		jsonMapping: json.Mapping{
+			Name:  mainflux.Env(envJSONNamePath, defJSONNamePath),
+			Value: mainflux.Env(envJSONValuePath, defJSONValuePath),
+			Time:  mainflux.Env(envJSONTimePath, defJSONTimePath),
+		},
+		schemasRedisURL:       mainflux.Env(envSchemasRedisURL, defSchemasRedisURL),
+		schemasRedisPass:      mainflux.Env(envSchemasRedisPass, defSchemasRedisPass),
+		schemasRedisDB:        mainflux.Env(envSchemasRedisDB, defSchemasRedisDB),
+		schemaRegistryURL:     mainflux.Env(envSchemaRegistryURL, defSchemaRegistryURL),
+		schemaRegistryTimeout: registryTimeout,
+		adminToken:            mainflux.Env(envAdminToken, defAdminToken),
+		jsonNested:            jsonNested,
+		batchSize:             batchSize,
+		flushInterval:         flushInterval,
+		retryInterval:         retryInterval,
+		retryMaxTime:          retryMaxTime,
+		deadLetterSubject:     mainflux.Env(envDeadLetterSubject, defDeadLetterSubject),
+		dbCfg:                 dbCfg,
 	}
 }
 
@@ -140,36 +350,93 @@ func connectToCassandra(dbCfg cassandra.DBConfig, logger logger.Logger) *gocql.S
 	return session
 }
 
-func newService(session *gocql.Session, logger logger.Logger) consumers.Consumer {
-	repo := cassandra.New(session)
+func newService(session *gocql.Session, router writers.Router, dbCfg cassandra.DBConfig, ttl cassandra.TTLConfig, metrics api.Metrics, logger logger.Logger) consumers.Consumer {
+	repo := cassandra.New(session, dbCfg.Keyspace, ttl)
+	repo = writers.NewRouterConsumer(repo, router, func(target string) (consumers.Consumer, error) {
+		cfg := dbCfg
+		cfg.Keyspace = target
+		session, err := cassandra.Connect(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return cassandra.New(session, cfg.Keyspace, ttl), nil
+	})
 	repo = api.LoggingMiddleware(repo, logger)
-	repo = api.MetricsMiddleware(
-		repo,
-		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
-			Namespace: "cassandra",
-			Subsystem: "message_writer",
-			Name:      "request_count",
-			Help:      "Number of requests received.",
-		}, []string{"method"}),
-		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
-			Namespace: "cassandra",
-			Subsystem: "message_writer",
-			Name:      "request_latency_microseconds",
-			Help:      "Total duration of requests in microseconds.",
-		}, []string{"method"}),
-	)
+	repo = api.MetricsMiddleware(repo, metrics)
 
 	return repo
 }
 
-func makeTransformer(cfg config, logger logger.Logger) transformers.Transformer {
+func makeTransformer(cfg config, schemas protobuf.SchemaRepository, logger logger.Logger) transformers.Transformer {
 	switch strings.ToUpper(cfg.transformer) {
 	case "SENML":
+		units, err := writers.LoadUnits(cfg.configPath)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Failed to load unit conversions: %s", err))
+		}
+		if cfg.senmlStrict {
+			logger.Info("Using strict SenML transformer")
+			return senml.NewStrict(cfg.contentType, units)
+		}
+		if len(units) > 0 {
+			logger.Info("Using SenML transformer with unit conversions")
+			return senml.NewNormalized(cfg.contentType, units)
+		}
 		logger.Info("Using SenML transformer")
 		return senml.New(cfg.contentType)
 	case "JSON":
+		if cfg.jsonNested {
+			logger.Info("Using nested JSON transformer")
+			return json.NewNested()
+		}
 		logger.Info("Using JSON transformer")
 		return json.New()
+	case "MAPPED":
+		if cfg.jsonMapping.Value == "" {
+			logger.Error(fmt.Sprintf("Can't create mapped JSON transformer: %s is not set", envJSONValuePath))
+			os.Exit(1)
+		}
+		logger.Info("Using mapped JSON transformer")
+		return json.NewMapped(cfg.jsonMapping)
+	case "LPP":
+		logger.Info("Using Cayenne LPP transformer")
+		return lpp.New()
+	case "LUA":
+		scripts, err := writers.LoadScripts(cfg.configPath)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Can't create Lua transformer: %s", err))
+			os.Exit(1)
+		}
+		t, err := lua.New(scripts)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Can't create Lua transformer: %s", err))
+			os.Exit(1)
+		}
+		logger.Info("Using Lua transformer")
+		return t
+	case "PROTOBUF":
+		if schemas == nil {
+			logger.Error(fmt.Sprintf("Can't create protobuf transformer: %s is not set", envSchemasRedisURL))
+			os.Exit(1)
+		}
+		if cfg.jsonNested {
+			logger.Info("Using nested protobuf transformer")
+			return protobuf.NewNested(schemas)
+		}
+		logger.Info("Using protobuf transformer")
+		return protobuf.New(schemas)
+	case "AVRO":
+		if cfg.schemaRegistryURL == "" {
+			logger.Error(fmt.Sprintf("Can't create Avro transformer: %s is not set", envSchemaRegistryURL))
+			os.Exit(1)
+		}
+		registry := avro.NewRegistry(cfg.schemaRegistryURL, cfg.schemaRegistryTimeout)
+		if cfg.jsonNested {
+			logger.Info("Using nested Avro transformer")
+			return avro.NewNested(registry)
+		}
+		logger.Info("Using Avro transformer")
+		return avro.New(registry)
 	default:
 		logger.Error(fmt.Sprintf("Can't create transformer: unknown transformer type %s", cfg.transformer))
 		os.Exit(1)
@@ -177,8 +444,114 @@ func makeTransformer(cfg config, logger logger.Logger) transformers.Transformer
 	}
 }
 
-func startHTTPServer(port string, errs chan error, logger logger.Logger) {
+func loadHook(cfg config, logger logger.Logger) writers.Hook {
+	if cfg.hookPath == "" {
+		return writers.Hook{}
+	}
+
+	hook, err := writers.LoadHook(cfg.hookPath)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load hook: %s", err))
+		os.Exit(1)
+	}
+	return hook
+}
+
+func newRouter(cfg config, logger logger.Logger) writers.Router {
+	routes, err := writers.LoadRoutes(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to load routes: %s", err))
+	}
+
+	if cfg.esURL == "" {
+		if len(routes) == 0 {
+			return nil
+		}
+		router, _ := writers.NewRouter(routes, nil)
+		return router
+	}
+
+	esClient := connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
+	router, err := writers.NewRouter(routes, redis.NewChannelRepository(esClient))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load routed channels: %s", err))
+		os.Exit(1)
+	}
+	go subscribeToThingsES(router, esClient, cfg.esConsumerName, logger)
+
+	return router
+}
+
+func connectToRedis(redisURL, redisPass, redisDB string, logger logger.Logger) *r.Client {
+	db, err := strconv.Atoi(redisDB)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to redis: %s", err))
+		os.Exit(1)
+	}
+
+	return r.NewClient(&r.Options{
+		Addr:     redisURL,
+		Password: redisPass,
+		DB:       db,
+	})
+}
+
+func newDedupCache(cfg config, logger logger.Logger) writers.DedupCache {
+	if cfg.dedupWindow <= 0 {
+		return nil
+	}
+	if cfg.dedupRedisURL == "" {
+		return writers.NewLRUCache(cfg.dedupSize, cfg.dedupWindow)
+	}
+
+	client := connectToRedis(cfg.dedupRedisURL, cfg.dedupRedisPass, cfg.dedupRedisDB, logger)
+	return redis.NewDedupCache(client, svcName, cfg.dedupWindow)
+}
+
+func newSchemaRepository(cfg config, logger logger.Logger) protobuf.SchemaRepository {
+	if cfg.schemasRedisURL == "" {
+		return nil
+	}
+
+	client := connectToRedis(cfg.schemasRedisURL, cfg.schemasRedisPass, cfg.schemasRedisDB, logger)
+	return redis.NewSchemaRepository(client)
+}
+
+func subscribeToThingsES(router writers.Router, client *r.Client, consumer string, logger logger.Logger) {
+	eventStore := redis.NewEventStore(router, client, consumer, logger)
+	logger.Info("Subscribed to Redis Event Store")
+	if err := eventStore.Subscribe(context.Background()); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to subscribe to Redis event source: %s", err))
+	}
+}
+
+func connectToDeadLetter(cfg config, logger logger.Logger) writers.DeadLetter {
+	if cfg.deadLetterSubject == "" {
+		return nil
+	}
+
+	dl, err := writers.NewDeadLetter(cfg.natsURL, cfg.deadLetterSubject)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
+	}
+	return dl
+}
+
+func reloadSubjects(subjects consumers.Subjects, logger logger.Logger) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	for range c {
+		if err := subjects.Reload(); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to reload subjects: %s", err))
+			continue
+		}
+		logger.Info("Reloaded subjects")
+	}
+}
+
+func startHTTPServer(port string, checks map[string]func() error, schemas protobuf.SchemaRepository, adminToken string, errs chan error, logger logger.Logger) {
 	p := fmt.Sprintf(":%s", port)
 	logger.Info(fmt.Sprintf("Cassandra writer service started, exposed port %s", port))
-	errs <- http.ListenAndServe(p, api.MakeHandler(svcName))
+	errs <- http.ListenAndServe(p, api.MakeHandler(svcName, checks, schemas, adminToken))
 }
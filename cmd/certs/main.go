@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
@@ -13,6 +14,7 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -22,8 +24,12 @@ import (
 	authapi "github.com/mainflux/mainflux/auth/api/grpc"
 	"github.com/mainflux/mainflux/certs"
 	"github.com/mainflux/mainflux/certs/api"
-	vault "github.com/mainflux/mainflux/certs/pki"
+	"github.com/mainflux/mainflux/certs/notifiers/smtp"
+	"github.com/mainflux/mainflux/certs/notifiers/webhook"
+	"github.com/mainflux/mainflux/certs/pki"
 	"github.com/mainflux/mainflux/certs/postgres"
+	"github.com/mainflux/mainflux/certs/redis/producer"
+	"github.com/mainflux/mainflux/internal/email"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -55,20 +61,50 @@ const (
 	defServerKey     = ""
 	defBaseURL       = "http://localhost"
 	defThingsPrefix  = ""
+	defBootstrapURL  = "http://localhost:8202"
 	defJaegerURL     = ""
 	defAuthURL       = "localhost:8181"
 	defAuthTimeout   = "1s"
+	defESURL         = "localhost:6379"
+	defESPass        = ""
+	defESDB          = "0"
 
 	defSignCAPath     = "ca.crt"
 	defSignCAKeyPath  = "ca.key"
 	defSignHoursValid = "2048h"
-	defSignRSABits    = ""
+	defSignRSABits    = "2048"
+
+	defKeyType = "rsa"
+	defKeyBits = "0"
+
+	defPKIBackend = ""
 
 	defVaultHost       = ""
 	defVaultRole       = "mainflux"
 	defVaultToken      = ""
 	defVaultPKIIntPath = "pki_int"
 
+	vaultPKI = "vault"
+	localPKI = "local"
+
+	defRenewLeadTime = "168h"
+	defRenewInterval = "1h"
+
+	defNotifyBefore         = "72h,24h"
+	defNotifyInterval       = "1h"
+	defNotifyEmail          = "false"
+	defNotifyWebhookURL     = ""
+	defNotifyWebhookTimeout = "5s"
+
+	defEmailHost        = "localhost"
+	defEmailPort        = "25"
+	defEmailUsername    = "root"
+	defEmailPassword    = ""
+	defEmailSecret      = ""
+	defEmailFromAddress = ""
+	defEmailFromName    = ""
+	defEmailTemplate    = "email.tmpl"
+
 	envPort          = "MF_CERTS_HTTP_PORT"
 	envLogLevel      = "MF_CERTS_LOG_LEVEL"
 	envDBHost        = "MF_CERTS_DB_HOST"
@@ -87,19 +123,46 @@ const (
 	envServerKey     = "MF_CERTS_SERVER_KEY"
 	envBaseURL       = "MF_SDK_BASE_URL"
 	envThingsPrefix  = "MF_SDK_THINGS_PREFIX"
+	envBootstrapURL  = "MF_SDK_BOOTSTRAP_URL"
 	envJaegerURL     = "MF_JAEGER_URL"
 	envAuthURL       = "MF_AUTH_GRPC_URL"
 	envAuthTimeout   = "MF_AUTH_GRPC_TIMEOUT"
+	envESURL         = "MF_CERTS_ES_URL"
+	envESPass        = "MF_CERTS_ES_PASS"
+	envESDB          = "MF_CERTS_ES_DB"
 
 	envSignCAPath     = "MF_CERTS_SIGN_CA_PATH"
 	envSignCAKey      = "MF_CERTS_SIGN_CA_KEY_PATH"
 	envSignHoursValid = "MF_CERTS_SIGN_HOURS_VALID"
 	envSignRSABits    = "MF_CERTS_SIGN_RSA_BITS"
 
+	envKeyType = "MF_CERTS_KEY_TYPE"
+	envKeyBits = "MF_CERTS_KEY_BITS"
+
+	envPKIBackend = "MF_CERTS_PKI_BACKEND"
+
 	envVaultHost       = "MF_CERTS_VAULT_HOST"
 	envVaultPKIIntPath = "MF_VAULT_PKI_INT_PATH"
 	envVaultRole       = "MF_VAULT_CA_ROLE_NAME"
 	envVaultToken      = "MF_VAULT_TOKEN"
+
+	envRenewLeadTime = "MF_CERTS_RENEW_LEAD_TIME"
+	envRenewInterval = "MF_CERTS_RENEW_INTERVAL"
+
+	envNotifyBefore         = "MF_CERTS_NOTIFY_BEFORE"
+	envNotifyInterval       = "MF_CERTS_NOTIFY_INTERVAL"
+	envNotifyEmail          = "MF_CERTS_NOTIFY_EMAIL"
+	envNotifyWebhookURL     = "MF_CERTS_NOTIFY_WEBHOOK_URL"
+	envNotifyWebhookTimeout = "MF_CERTS_NOTIFY_WEBHOOK_TIMEOUT"
+
+	envEmailHost        = "MF_EMAIL_HOST"
+	envEmailPort        = "MF_EMAIL_PORT"
+	envEmailUsername    = "MF_EMAIL_USERNAME"
+	envEmailPassword    = "MF_EMAIL_PASSWORD"
+	envEmailSecret      = "MF_EMAIL_SECRET"
+	envEmailFromAddress = "MF_EMAIL_FROM_ADDRESS"
+	envEmailFromName    = "MF_EMAIL_FROM_NAME"
+	envEmailTemplate    = "MF_EMAIL_TEMPLATE"
 )
 
 var (
@@ -109,8 +172,11 @@ var (
 	errPrivateKeyEmpty           = errors.New("private key empty")
 	errPrivateKeyUnsupportedType = errors.New("private key unsupported type")
 	errCertsRemove               = errors.New("failed to remove certificate")
+	errNonPositiveDuration       = errors.New("duration must be positive")
 	errCACertificateDoesntExist  = errors.New("CA certificate doesnt exist")
 	errCAKeyDoesntExist          = errors.New("CA certificate key doesnt exist")
+	errUnknownPKIBackend         = errors.New("unknown PKI backend")
+	errMissingVaultHost          = errors.New("no host specified for Vault")
 )
 
 type config struct {
@@ -124,20 +190,42 @@ type config struct {
 	serverKey    string
 	baseURL      string
 	thingsPrefix string
+	bootstrapURL string
 	jaegerURL    string
 	authURL      string
 	authTimeout  time.Duration
+	esURL        string
+	esPass       string
+	esDB         string
 	// Sign and issue certificates
 	// without 3rd party PKI
 	signCAPath     string
 	signCAKeyPath  string
 	signRSABits    int
 	signHoursValid string
+	// Key type and size of the issued
+	// certificates unless requested
+	keyType string
+	keyBits int
+	// PKI backend issuing certificates,
+	// either 3rd party or built-in CA
+	pkiBackend string
 	// 3rd party PKI API access settings
 	pkiPath  string
 	pkiToken string
 	pkiHost  string
 	pkiRole  string
+	// Renewal of the expiring certificates
+	renewLeadTime time.Duration
+	renewInterval time.Duration
+	// Notification of the certificate owners
+	// about the expiry
+	notifyBefore         []time.Duration
+	notifyInterval       time.Duration
+	notifyEmail          bool
+	notifyWebhookURL     string
+	notifyWebhookTimeout time.Duration
+	emailConf            email.Config
 }
 
 func main() {
@@ -153,13 +241,9 @@ func main() {
 		logger.Error("Failed to load CA certificates for issuing client certs")
 	}
 
-	if cfg.pkiHost == "" {
-		log.Fatalf("No host specified for PKI engine")
-	}
-
-	pkiClient, err := vault.NewVaultClient(cfg.pkiToken, cfg.pkiHost, cfg.pkiPath, cfg.pkiRole)
+	pkiClient, err := newPKIAgent(cfg, tlsCert, caCert)
 	if err != nil {
-		log.Fatalf("Failed to configure client for PKI engine")
+		log.Fatalf("Failed to configure PKI backend: %s", err)
 	}
 
 	db := connectToDB(cfg.dbConfig, logger)
@@ -173,11 +257,27 @@ func main() {
 
 	auth := authapi.NewClient(authTracer, authConn, cfg.authTimeout)
 
-	svc := newService(auth, db, logger, nil, tlsCert, caCert, cfg, pkiClient)
+	esClient := connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
+	defer esClient.Close()
+
+	notifiers, err := newNotifiers(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure expiry notifiers: %s", err)
+	}
+
+	svc := newService(auth, db, logger, esClient, tlsCert, caCert, cfg, pkiClient, notifiers)
 	errs := make(chan error, 2)
 
 	go startHTTPServer(svc, cfg, logger, errs)
 
+	if cfg.renewInterval > 0 {
+		go renewCerts(svc, cfg.renewInterval, logger)
+	}
+
+	if cfg.notifyInterval > 0 && len(notifiers) > 0 {
+		go notifyCerts(svc, cfg.notifyInterval, logger)
+	}
+
 	go func() {
 		c := make(chan os.Signal)
 		signal.Notify(c, syscall.SIGINT)
@@ -215,6 +315,52 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envSignRSABits, err.Error())
 	}
 
+	keyBits, err := strconv.Atoi(mainflux.Env(envKeyBits, defKeyBits))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envKeyBits, err.Error())
+	}
+
+	renewLeadTime, err := time.ParseDuration(mainflux.Env(envRenewLeadTime, defRenewLeadTime))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRenewLeadTime, err.Error())
+	}
+
+	renewInterval, err := time.ParseDuration(mainflux.Env(envRenewInterval, defRenewInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRenewInterval, err.Error())
+	}
+
+	notifyBefore, err := parseDurations(mainflux.Env(envNotifyBefore, defNotifyBefore))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envNotifyBefore, err.Error())
+	}
+
+	notifyInterval, err := time.ParseDuration(mainflux.Env(envNotifyInterval, defNotifyInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envNotifyInterval, err.Error())
+	}
+
+	notifyEmail, err := strconv.ParseBool(mainflux.Env(envNotifyEmail, defNotifyEmail))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envNotifyEmail, err.Error())
+	}
+
+	notifyWebhookTimeout, err := time.ParseDuration(mainflux.Env(envNotifyWebhookTimeout, defNotifyWebhookTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envNotifyWebhookTimeout, err.Error())
+	}
+
+	emailConf := email.Config{
+		FromAddress: mainflux.Env(envEmailFromAddress, defEmailFromAddress),
+		FromName:    mainflux.Env(envEmailFromName, defEmailFromName),
+		Host:        mainflux.Env(envEmailHost, defEmailHost),
+		Port:        mainflux.Env(envEmailPort, defEmailPort),
+		Username:    mainflux.Env(envEmailUsername, defEmailUsername),
+		Password:    mainflux.Env(envEmailPassword, defEmailPassword),
+		Secret:      mainflux.Env(envEmailSecret, defEmailSecret),
+		Template:    mainflux.Env(envEmailTemplate, defEmailTemplate),
+	}
+
 	return config{
 		logLevel:     mainflux.Env(envLogLevel, defLogLevel),
 		dbConfig:     dbConfig,
@@ -225,21 +371,128 @@ func loadConfig() config {
 		serverKey:    mainflux.Env(envServerKey, defServerKey),
 		baseURL:      mainflux.Env(envBaseURL, defBaseURL),
 		thingsPrefix: mainflux.Env(envThingsPrefix, defThingsPrefix),
+		bootstrapURL: mainflux.Env(envBootstrapURL, defBootstrapURL),
 		jaegerURL:    mainflux.Env(envJaegerURL, defJaegerURL),
 		authURL:      mainflux.Env(envAuthURL, defAuthURL),
 		authTimeout:  authTimeout,
+		esURL:        mainflux.Env(envESURL, defESURL),
+		esPass:       mainflux.Env(envESPass, defESPass),
+		esDB:         mainflux.Env(envESDB, defESDB),
 
 		signCAKeyPath:  mainflux.Env(envSignCAKey, defSignCAKeyPath),
 		signCAPath:     mainflux.Env(envSignCAPath, defSignCAPath),
 		signHoursValid: mainflux.Env(envSignHoursValid, defSignHoursValid),
 		signRSABits:    signRSABits,
 
+		keyType: mainflux.Env(envKeyType, defKeyType),
+		keyBits: keyBits,
+
+		pkiBackend: mainflux.Env(envPKIBackend, defPKIBackend),
+
 		pkiToken: mainflux.Env(envVaultToken, defVaultToken),
 		pkiPath:  mainflux.Env(envVaultPKIIntPath, defVaultPKIIntPath),
 		pkiRole:  mainflux.Env(envVaultRole, defVaultRole),
 		pkiHost:  mainflux.Env(envVaultHost, defVaultHost),
+
+		renewLeadTime: renewLeadTime,
+		renewInterval: renewInterval,
+
+		notifyBefore:         notifyBefore,
+		notifyInterval:       notifyInterval,
+		notifyEmail:          notifyEmail,
+		notifyWebhookURL:     mainflux.Env(envNotifyWebhookURL, defNotifyWebhookURL),
+		notifyWebhookTimeout: notifyWebhookTimeout,
+		emailConf:            emailConf,
+	}
+
+}
+
+// newPKIAgent returns the agent of the configured PKI backend. Vault is used
+// by default if its host is set, and built-in CA otherwise.
+func newPKIAgent(cfg config, tlsCert tls.Certificate, caCert *x509.Certificate) (pki.Agent, error) {
+	backend := cfg.pkiBackend
+	if backend == "" {
+		backend = localPKI
+		if cfg.pkiHost != "" {
+			backend = vaultPKI
+		}
+	}
+
+	switch backend {
+	case vaultPKI:
+		if cfg.pkiHost == "" {
+			return nil, errMissingVaultHost
+		}
+		return pki.NewVaultClient(cfg.pkiToken, cfg.pkiHost, cfg.pkiPath, cfg.pkiRole)
+	case localPKI:
+		if caCert == nil {
+			return nil, errMissingCACertificate
+		}
+		return pki.NewLocalAgent(tlsCert, caCert, cfg.signRSABits, cfg.signHoursValid), nil
+	default:
+		return nil, errUnknownPKIBackend
+	}
+}
+
+func renewCerts(svc certs.Service, interval time.Duration, logger mflog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := svc.RenewCerts(context.Background()); err != nil {
+			logger.Error(fmt.Sprintf("Failed to renew expiring certificates: %s", err))
+		}
+		<-ticker.C
+	}
+}
+
+func notifyCerts(svc certs.Service, interval time.Duration, logger mflog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := svc.NotifyCerts(context.Background()); err != nil {
+			logger.Error(fmt.Sprintf("Failed to notify about expiring certificates: %s", err))
+		}
+		<-ticker.C
+	}
+}
+
+func newNotifiers(cfg config) ([]certs.Notifier, error) {
+	notifiers := []certs.Notifier{}
+	if cfg.notifyEmail {
+		agent, err := email.New(&cfg.emailConf)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, smtp.New(agent))
+	}
+	if cfg.notifyWebhookURL != "" {
+		notifiers = append(notifiers, webhook.New(cfg.notifyWebhookURL, cfg.notifyWebhookTimeout))
+	}
+
+	return notifiers, nil
+}
+
+// parseDurations parses comma separated list of positive durations.
+func parseDurations(s string) ([]time.Duration, error) {
+	durations := []time.Duration{}
+	if s == "" {
+		return durations, nil
+	}
+
+	for _, v := range strings.Split(s, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, errNonPositiveDuration
+		}
+		durations = append(durations, d)
 	}
 
+	return durations, nil
 }
 
 func connectToRedis(redisURL, redisPass, redisDB string, logger mflog.Logger) *redis.Client {
@@ -314,7 +567,7 @@ func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer,
 	return tracer, closer
 }
 
-func newService(auth mainflux.AuthServiceClient, db *sqlx.DB, logger mflog.Logger, esClient *redis.Client, tlsCert tls.Certificate, x509Cert *x509.Certificate, cfg config, pkiAgent vault.Agent) certs.Service {
+func newService(auth mainflux.AuthServiceClient, db *sqlx.DB, logger mflog.Logger, esClient *redis.Client, tlsCert tls.Certificate, x509Cert *x509.Certificate, cfg config, pkiAgent pki.Agent, notifiers []certs.Notifier) certs.Service {
 	certsRepo := postgres.NewRepository(db, logger)
 
 	certsConfig := certs.Config{
@@ -337,16 +590,22 @@ func newService(auth mainflux.AuthServiceClient, db *sqlx.DB, logger mflog.Logge
 		PKIHost:        cfg.pkiHost,
 		PKIPath:        cfg.pkiPath,
 		PKIRole:        cfg.pkiRole,
+		RenewLeadTime:  cfg.renewLeadTime,
+		KeyType:        cfg.keyType,
+		KeyBits:        cfg.keyBits,
+		NotifyBefore:   cfg.notifyBefore,
 	}
 
 	config := mfsdk.Config{
 		BaseURL:      cfg.baseURL,
 		ThingsPrefix: cfg.thingsPrefix,
+		BootstrapURL: cfg.bootstrapURL,
 	}
 
 	sdk := mfsdk.NewSDK(config)
 
-	svc := certs.New(auth, certsRepo, sdk, certsConfig, pkiAgent)
+	svc := certs.New(auth, certsRepo, sdk, certsConfig, pkiAgent, notifiers)
+	svc = producer.NewEventStoreMiddleware(svc, esClient)
 	svc = api.NewLoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
 		svc,
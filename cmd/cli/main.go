@@ -18,12 +18,14 @@ func main() {
 		ReaderURL:         "http://localhost:8905",
 		BootstrapURL:      "http://localhost:8202",
 		CertsURL:          "http://localhost:8204",
+		TwinsURL:          "http://localhost:9021",
 		ReaderPrefix:      "",
 		UsersPrefix:       "",
 		GroupsPrefix:      "",
 		ThingsPrefix:      "",
 		HTTPAdapterPrefix: "http",
 		BootstrapPrefix:   "things",
+		TwinsPrefix:       "",
 		MsgContentType:    sdk.ContentType(msgContentType),
 		TLSVerification:   false,
 	}
@@ -0,0 +1,339 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	r "github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux"
+	authapi "github.com/mainflux/mainflux/auth/api/grpc"
+	"github.com/mainflux/mainflux/logger"
+	chclient "github.com/mainflux/mainflux/pkg/clickhouse"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	"github.com/mainflux/mainflux/readers"
+	"github.com/mainflux/mainflux/readers/api"
+	grpcapi "github.com/mainflux/mainflux/readers/api/grpc"
+	"github.com/mainflux/mainflux/readers/clickhouse"
+	"github.com/mainflux/mainflux/readers/redis"
+	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
+	opentracing "github.com/opentracing/opentracing-go"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	jconfig "github.com/uber/jaeger-client-go/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	svcName = "clickhouse-reader"
+
+	defLogLevel          = "error"
+	defNatsURL           = "nats://localhost:4222"
+	defPort              = "8180"
+	defGRPCPort          = "8191"
+	defClientTLS         = "false"
+	defCACerts           = ""
+	defDBURL             = "http://localhost:8123"
+	defDBUser            = "default"
+	defDBPass            = ""
+	defDB                = "mainflux"
+	defDBTimeout         = "10s"
+	defJaegerURL         = ""
+	defThingsAuthURL     = "localhost:8181"
+	defThingsAuthTimeout = "1s"
+	defAuthURL           = "localhost:8181"
+	defAuthTimeout       = "1s"
+	defESURL             = ""
+	defESPass            = ""
+	defESDB              = "0"
+
+	envLogLevel          = "MF_CLICKHOUSE_READER_LOG_LEVEL"
+	envNatsURL           = "MF_NATS_URL"
+	envPort              = "MF_CLICKHOUSE_READER_PORT"
+	envGRPCPort          = "MF_CLICKHOUSE_READER_GRPC_PORT"
+	envClientTLS         = "MF_CLICKHOUSE_READER_CLIENT_TLS"
+	envCACerts           = "MF_CLICKHOUSE_READER_CA_CERTS"
+	envDBURL             = "MF_CLICKHOUSE_READER_DB_URL"
+	envDBUser            = "MF_CLICKHOUSE_READER_DB_USER"
+	envDBPass            = "MF_CLICKHOUSE_READER_DB_PASS"
+	envDB                = "MF_CLICKHOUSE_READER_DB"
+	envDBTimeout         = "MF_CLICKHOUSE_READER_DB_TIMEOUT"
+	envJaegerURL         = "MF_JAEGER_URL"
+	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
+	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envAuthURL           = "MF_AUTH_GRPC_URL"
+	envAuthTimeout       = "MF_AUTH_GRPC_TIMEOUT"
+	envESURL             = "MF_CLICKHOUSE_READER_ES_URL"
+	envESPass            = "MF_CLICKHOUSE_READER_ES_PASS"
+	envESDB              = "MF_CLICKHOUSE_READER_ES_DB"
+)
+
+type config struct {
+	logLevel          string
+	natsURL           string
+	port              string
+	grpcPort          string
+	clientTLS         bool
+	caCerts           string
+	dbConfig          chclient.Config
+	jaegerURL         string
+	thingsAuthURL     string
+	thingsAuthTimeout time.Duration
+	authURL           string
+	authTimeout       time.Duration
+	esURL             string
+	esPass            string
+	esDB              string
+}
+
+func main() {
+	cfg := loadConfig()
+
+	logger, err := logger.New(os.Stdout, cfg.logLevel)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	conn := connectToThings(cfg, logger)
+	defer conn.Close()
+
+	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
+	defer thingsCloser.Close()
+
+	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
+
+	authConn := connectToAuth(cfg, logger)
+	defer authConn.Close()
+
+	authTracer, authCloser := initJaeger("auth", cfg.jaegerURL, logger)
+	defer authCloser.Close()
+
+	ac := authapi.NewClient(authTracer, authConn, cfg.authTimeout)
+
+	client := chclient.New(cfg.dbConfig)
+
+	var esClient *r.Client
+	if cfg.esURL != "" {
+		esClient = connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
+		defer esClient.Close()
+	}
+
+	repo := newService(client, esClient, logger)
+
+	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
+	}
+	defer pubSub.Close()
+
+	stream := readers.NewLiveStream()
+	if err := pubSub.Subscribe(nats.SubjectAllChannels, stream.Handle); err != nil {
+		logger.Error(fmt.Sprintf("Failed to subscribe to live messages: %s", err))
+		os.Exit(1)
+	}
+
+	errs := make(chan error, 2)
+
+	go startHTTPServer(repo, tc, ac, stream, cfg.port, logger, errs)
+	go startGRPCServer(repo, tc, ac, cfg.grpcPort, logger, errs)
+
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT)
+		errs <- fmt.Errorf("%s", <-c)
+	}()
+
+	err = <-errs
+	logger.Error(fmt.Sprintf("ClickHouse reader service terminated: %s", err))
+}
+
+func loadConfig() config {
+	timeout, err := time.ParseDuration(mainflux.Env(envDBTimeout, defDBTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envDBTimeout, err.Error())
+	}
+
+	dbConfig := chclient.Config{
+		URL:     mainflux.Env(envDBURL, defDBURL),
+		User:    mainflux.Env(envDBUser, defDBUser),
+		Pass:    mainflux.Env(envDBPass, defDBPass),
+		DB:      mainflux.Env(envDB, defDB),
+		Timeout: timeout,
+	}
+
+	tls, err := strconv.ParseBool(mainflux.Env(envClientTLS, defClientTLS))
+	if err != nil {
+		log.Fatalf("Invalid value passed for %s\n", envClientTLS)
+	}
+
+	thingsAuthTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
+	}
+
+	authTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
+	}
+
+	return config{
+		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
+		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
+		port:              mainflux.Env(envPort, defPort),
+		grpcPort:          mainflux.Env(envGRPCPort, defGRPCPort),
+		clientTLS:         tls,
+		caCerts:           mainflux.Env(envCACerts, defCACerts),
+		dbConfig:          dbConfig,
+		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
+		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
+		thingsAuthTimeout: thingsAuthTimeout,
+		authURL:           mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout:       authTimeout,
+		esURL:             mainflux.Env(envESURL, defESURL),
+		esPass:            mainflux.Env(envESPass, defESPass),
+		esDB:              mainflux.Env(envESDB, defESDB),
+	}
+}
+
+func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer, io.Closer) {
+	if url == "" {
+		return opentracing.NoopTracer{}, ioutil.NopCloser(nil)
+	}
+
+	tracer, closer, err := jconfig.Configuration{
+		ServiceName: svcName,
+		Sampler: &jconfig.SamplerConfig{
+			Type:  "const",
+			Param: 1,
+		},
+		Reporter: &jconfig.ReporterConfig{
+			LocalAgentHostPort: url,
+			LogSpans:           true,
+		},
+	}.NewTracer()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to init Jaeger client: %s", err))
+		os.Exit(1)
+	}
+
+	return tracer, closer
+}
+
+func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
+	var opts []grpc.DialOption
+	if cfg.clientTLS {
+		if cfg.caCerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.caCerts, "")
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to load certs: %s", err))
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.WithTransportCredentials(tpc))
+		}
+	} else {
+		logger.Info("gRPC communication is not encrypted")
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(cfg.thingsAuthURL, opts...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to things service: %s", err))
+		os.Exit(1)
+	}
+	return conn
+}
+
+func connectToAuth(cfg config, logger logger.Logger) *grpc.ClientConn {
+	var opts []grpc.DialOption
+	if cfg.clientTLS {
+		if cfg.caCerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.caCerts, "")
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to load certs: %s", err))
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.WithTransportCredentials(tpc))
+		}
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(cfg.authURL, opts...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to auth service: %s", err))
+		os.Exit(1)
+	}
+	return conn
+}
+
+func connectToRedis(redisURL, redisPass, redisDB string, logger logger.Logger) *r.Client {
+	db, err := strconv.Atoi(redisDB)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to redis: %s", err))
+		os.Exit(1)
+	}
+
+	return r.NewClient(&r.Options{
+		Addr:     redisURL,
+		Password: redisPass,
+		DB:       db,
+	})
+}
+
+func newService(client chclient.Client, esClient *r.Client, logger logger.Logger) readers.MessageRepository {
+	svc := clickhouse.New(client)
+	if esClient != nil {
+		svc = redis.NewEventStoreMiddleware(svc, esClient)
+	}
+	svc = api.LoggingMiddleware(svc, logger)
+	svc = api.MetricsMiddleware(
+		svc,
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "clickhouse",
+			Subsystem: "message_reader",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, []string{"method"}),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "clickhouse",
+			Subsystem: "message_reader",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, []string{"method"}),
+	)
+
+	return svc
+}
+
+func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient, stream readers.LiveStream, port string, logger logger.Logger, errs chan error) {
+	p := fmt.Sprintf(":%s", port)
+	logger.Info(fmt.Sprintf("ClickHouse reader service started, exposed port %s", port))
+	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, ac, stream, svcName))
+}
+
+func startGRPCServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient, port string, logger logger.Logger, errs chan error) {
+	p := fmt.Sprintf(":%s", port)
+	listener, err := net.Listen("tcp", p)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to listen on port %s: %s", port, err))
+		os.Exit(1)
+	}
+
+	server := grpc.NewServer()
+	mainflux.RegisterReadersServiceServer(server, grpcapi.NewServer(repo, tc, ac))
+	logger.Info(fmt.Sprintf("ClickHouse reader gRPC service started, exposed port %s", port))
+	errs <- server.Serve(listener)
+}
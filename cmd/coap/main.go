@@ -19,11 +19,14 @@ import (
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/coap"
 	"github.com/mainflux/mainflux/coap/api"
+	"github.com/mainflux/mainflux/coap/oscore"
 	logger "github.com/mainflux/mainflux/logger"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	broker "github.com/nats-io/nats.go"
 	opentracing "github.com/opentracing/opentracing-go"
-	gocoap "github.com/plgd-dev/go-coap/v2"
+	coapnet "github.com/plgd-dev/go-coap/v2/net"
+	"github.com/plgd-dev/go-coap/v2/net/blockwise"
+	"github.com/plgd-dev/go-coap/v2/udp"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	jconfig "github.com/uber/jaeger-client-go/config"
 	"google.golang.org/grpc"
@@ -39,6 +42,14 @@ const (
 	defJaegerURL         = ""
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
+	defSenMLStrict       = "false"
+	defBlockSize         = "1024"
+	defBlockwiseTimeout  = "3s"
+	defMaxMessageSize    = "65536"
+	defObserveInterval   = "0s"
+	defObserveMaxAge     = "60s"
+	defOSCOREContexts    = ""
+	defOSCOREState       = "oscore-state.json"
 
 	envPort              = "MF_COAP_ADAPTER_PORT"
 	envNatsURL           = "MF_NATS_URL"
@@ -48,6 +59,14 @@ const (
 	envJaegerURL         = "MF_JAEGER_URL"
 	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
 	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envSenMLStrict       = "MF_COAP_ADAPTER_SENML_STRICT"
+	envBlockSize         = "MF_COAP_ADAPTER_BLOCK_SIZE"
+	envBlockwiseTimeout  = "MF_COAP_ADAPTER_BLOCKWISE_TIMEOUT"
+	envMaxMessageSize    = "MF_COAP_ADAPTER_MAX_MESSAGE_SIZE"
+	envObserveInterval   = "MF_COAP_ADAPTER_OBSERVE_INTERVAL"
+	envObserveMaxAge     = "MF_COAP_ADAPTER_OBSERVE_MAX_AGE"
+	envOSCOREContexts    = "MF_COAP_ADAPTER_OSCORE_CONTEXTS"
+	envOSCOREState       = "MF_COAP_ADAPTER_OSCORE_STATE"
 )
 
 type config struct {
@@ -59,6 +78,13 @@ type config struct {
 	jaegerURL         string
 	thingsAuthURL     string
 	thingsAuthTimeout time.Duration
+	senmlStrict       bool
+	blockSZX          blockwise.SZX
+	blockwiseTimeout  time.Duration
+	maxMessageSize    int
+	notify            coap.NotifyConfig
+	oscoreContexts    string
+	oscoreState       string
 }
 
 func main() {
@@ -83,7 +109,7 @@ func main() {
 	}
 	defer nc.Close()
 
-	svc := coap.New(tc, nc)
+	svc := coap.New(tc, nc, cfg.senmlStrict)
 
 	svc = api.LoggingMiddleware(svc, logger)
 
@@ -103,10 +129,12 @@ func main() {
 		}, []string{"method"}),
 	)
 
+	ctxs := loadOSCOREContexts(cfg, logger)
+
 	errs := make(chan error, 2)
 
 	go startHTTPServer(cfg.port, logger, errs)
-	go startCOAPServer(cfg, svc, nil, logger, errs)
+	go startCOAPServer(cfg, svc, ctxs, logger, errs)
 
 	go func() {
 		c := make(chan os.Signal)
@@ -129,6 +157,36 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
 	}
 
+	strict, err := strconv.ParseBool(mainflux.Env(envSenMLStrict, defSenMLStrict))
+	if err != nil {
+		log.Fatalf("Invalid value passed for %s\n", envSenMLStrict)
+	}
+
+	szx, err := parseBlockSize(mainflux.Env(envBlockSize, defBlockSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envBlockSize, err.Error())
+	}
+
+	bwTimeout, err := time.ParseDuration(mainflux.Env(envBlockwiseTimeout, defBlockwiseTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envBlockwiseTimeout, err.Error())
+	}
+
+	maxSize, err := strconv.Atoi(mainflux.Env(envMaxMessageSize, defMaxMessageSize))
+	if err != nil || maxSize <= 0 {
+		log.Fatalf("Invalid value passed for %s\n", envMaxMessageSize)
+	}
+
+	interval, err := time.ParseDuration(mainflux.Env(envObserveInterval, defObserveInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envObserveInterval, err.Error())
+	}
+
+	maxAge, err := time.ParseDuration(mainflux.Env(envObserveMaxAge, defObserveMaxAge))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envObserveMaxAge, err.Error())
+	}
+
 	return config{
 		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
 		port:              mainflux.Env(envPort, defPort),
@@ -138,7 +196,57 @@ func loadConfig() config {
 		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
 		thingsAuthTimeout: authTimeout,
+		senmlStrict:       strict,
+		blockSZX:          szx,
+		blockwiseTimeout:  bwTimeout,
+		maxMessageSize:    maxSize,
+		notify: coap.NotifyConfig{
+			Interval: interval,
+			MaxAge:   maxAge,
+		},
+		oscoreContexts: mainflux.Env(envOSCOREContexts, defOSCOREContexts),
+		oscoreState:    mainflux.Env(envOSCOREState, defOSCOREState),
+	}
+}
+
+// parseBlockSize returns the block size exponent of the RFC 7959 block size,
+// which is a power of two between 16 and 1024 bytes.
+func parseBlockSize(size string) (blockwise.SZX, error) {
+	n, err := strconv.Atoi(size)
+	if err != nil {
+		return 0, err
+	}
+
+	for szx := blockwise.SZX16; szx <= blockwise.SZX1024; szx++ {
+		if szx.Size() == int64(n) {
+			return szx, nil
+		}
+	}
+
+	return 0, fmt.Errorf("block size %d is not a power of two between 16 and 1024", n)
+}
+
+// loadOSCOREContexts returns the OSCORE security contexts, or nil if OSCORE
+// is not configured.
+func loadOSCOREContexts(cfg config, logger logger.Logger) *oscore.Contexts {
+	if cfg.oscoreContexts == "" {
+		return nil
+	}
+
+	cfgs, err := oscore.ReadConfig(cfg.oscoreContexts)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to read OSCORE security contexts: %s", err))
+		os.Exit(1)
+	}
+
+	ctxs, err := oscore.NewContexts(cfgs, cfg.oscoreState)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load OSCORE security contexts: %s", err))
+		os.Exit(1)
 	}
+	logger.Info(fmt.Sprintf("OSCORE enabled with %d security contexts", len(cfgs)))
+
+	return ctxs
 }
 
 func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
@@ -195,8 +303,26 @@ func startHTTPServer(port string, logger logger.Logger, errs chan error) {
 	errs <- http.ListenAndServe(p, api.MakeHTTPHandler())
 }
 
-func startCOAPServer(cfg config, svc coap.Service, auth mainflux.ThingsServiceClient, l logger.Logger, errs chan error) {
+func startCOAPServer(cfg config, svc coap.Service, ctxs *oscore.Contexts, l logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", cfg.port)
 	l.Info(fmt.Sprintf("CoAP adapter service started, exposed port %s", cfg.port))
-	errs <- gocoap.ListenAndServe("udp", p, api.MakeCoAPHandler(svc, l))
+
+	lis, err := coapnet.NewListenUDP("udp", p)
+	if err != nil {
+		errs <- err
+		return
+	}
+	defer lis.Close()
+
+	// The payloads larger than the block size are transferred block-wise,
+	// as defined by RFC 7959, up to the maximal message size.
+	s := udp.NewServer(
+		udp.WithMux(api.MakeCoAPHandler(svc, cfg.notify, ctxs, l)),
+		udp.WithBlockwise(true, cfg.blockSZX, cfg.blockwiseTimeout),
+		udp.WithMaxMessageSize(cfg.maxMessageSize),
+		udp.WithErrors(func(err error) {
+			l.Warn(fmt.Sprintf("CoAP server error: %s", err))
+		}),
+	)
+	errs <- s.Serve(lis)
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/mainflux/mainflux/http/api"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	"github.com/mainflux/mainflux/pkg/uuid"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	"github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -39,6 +40,9 @@ const (
 	defJaegerURL         = ""
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
+	defSenMLStrict       = "false"
+	defRateLimit         = "0"
+	defRateLimitBurst    = "0"
 
 	envLogLevel          = "MF_HTTP_ADAPTER_LOG_LEVEL"
 	envClientTLS         = "MF_HTTP_ADAPTER_CLIENT_TLS"
@@ -48,6 +52,9 @@ const (
 	envJaegerURL         = "MF_JAEGER_URL"
 	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
 	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envSenMLStrict       = "MF_HTTP_ADAPTER_SENML_STRICT"
+	envRateLimit         = "MF_HTTP_ADAPTER_RATE_LIMIT"
+	envRateLimitBurst    = "MF_HTTP_ADAPTER_RATE_LIMIT_BURST"
 )
 
 type config struct {
@@ -59,6 +66,9 @@ type config struct {
 	jaegerURL         string
 	thingsAuthURL     string
 	thingsAuthTimeout time.Duration
+	senmlStrict       bool
+	rateLimit         float64
+	rateLimitBurst    int
 }
 
 func main() {
@@ -78,15 +88,15 @@ func main() {
 	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
 	defer thingsCloser.Close()
 
-	pub, err := nats.NewPublisher(cfg.natsURL)
+	ps, err := nats.NewPubSub(cfg.natsURL, "", logger)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
 		os.Exit(1)
 	}
-	defer pub.Close()
+	defer ps.Close()
 
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
-	svc := adapter.New(pub, tc)
+	svc := adapter.New(ps, tc, uuid.New(), newRateLimiter(cfg), cfg.senmlStrict)
 
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
@@ -134,6 +144,21 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
 	}
 
+	strict, err := strconv.ParseBool(mainflux.Env(envSenMLStrict, defSenMLStrict))
+	if err != nil {
+		log.Fatalf("Invalid value passed for %s\n", envSenMLStrict)
+	}
+
+	rateLimit, err := strconv.ParseFloat(mainflux.Env(envRateLimit, defRateLimit), 64)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRateLimit, err.Error())
+	}
+
+	rateLimitBurst, err := strconv.Atoi(mainflux.Env(envRateLimitBurst, defRateLimitBurst))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRateLimitBurst, err.Error())
+	}
+
 	return config{
 		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
 		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
@@ -143,9 +168,27 @@ func loadConfig() config {
 		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
 		thingsAuthTimeout: authTimeout,
+		senmlStrict:       strict,
+		rateLimit:         rateLimit,
+		rateLimitBurst:    rateLimitBurst,
 	}
 }
 
+func newRateLimiter(cfg config) adapter.RateLimiter {
+	if cfg.rateLimit <= 0 {
+		return nil
+	}
+
+	violations := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "http_adapter",
+		Subsystem: "rate_limit",
+		Name:      "violations",
+		Help:      "Number of requests rejected by the rate limit.",
+	}, []string{})
+
+	return adapter.NewRateLimiter(cfg.rateLimit, cfg.rateLimitBurst, violations)
+}
+
 func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer, io.Closer) {
 	if url == "" {
 		return opentracing.NoopTracer{}, ioutil.NopCloser(nil)
@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,12 +14,18 @@ import (
 	"time"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	r "github.com/go-redis/redis/v8"
 	influxdata "github.com/influxdata/influxdb/client/v2"
 	"github.com/mainflux/mainflux"
+	authapi "github.com/mainflux/mainflux/auth/api/grpc"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/influxdb2"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/readers"
 	"github.com/mainflux/mainflux/readers/api"
+	grpcapi "github.com/mainflux/mainflux/readers/api/grpc"
 	"github.com/mainflux/mainflux/readers/influxdb"
+	"github.com/mainflux/mainflux/readers/redis"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -29,12 +36,19 @@ import (
 
 const (
 	defLogLevel          = "error"
+	defNatsURL           = "nats://localhost:4222"
 	defPort              = "8180"
+	defGRPCPort          = "8191"
 	defDB                = "mainflux"
 	defDBHost            = "localhost"
 	defDBPort            = "8086"
 	defDBUser            = "mainflux"
 	defDBPass            = "mainflux"
+	defDBVersion         = "1"
+	defDBOrg             = "mainflux"
+	defDBBucket          = "mainflux"
+	defDBToken           = ""
+	defRollups           = ""
 	defClientTLS         = "false"
 	defCACerts           = ""
 	defServerCert        = ""
@@ -42,14 +56,26 @@ const (
 	defJaegerURL         = ""
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
+	defAuthURL           = "localhost:8181"
+	defAuthTimeout       = "1s"
+	defESURL             = ""
+	defESPass            = ""
+	defESDB              = "0"
 
 	envLogLevel          = "MF_INFLUX_READER_LOG_LEVEL"
+	envNatsURL           = "MF_NATS_URL"
 	envPort              = "MF_INFLUX_READER_PORT"
+	envGRPCPort          = "MF_INFLUX_READER_GRPC_PORT"
 	envDB                = "MF_INFLUXDB_DB"
 	envDBHost            = "MF_INFLUX_READER_DB_HOST"
 	envDBPort            = "MF_INFLUXDB_PORT"
 	envDBUser            = "MF_INFLUXDB_ADMIN_USER"
 	envDBPass            = "MF_INFLUXDB_ADMIN_PASSWORD"
+	envDBVersion         = "MF_INFLUXDB_VERSION"
+	envDBOrg             = "MF_INFLUXDB_ORG"
+	envDBBucket          = "MF_INFLUXDB_BUCKET"
+	envDBToken           = "MF_INFLUXDB_TOKEN"
+	envRollups           = "MF_INFLUX_READER_ROLLUPS"
 	envClientTLS         = "MF_INFLUX_READER_CLIENT_TLS"
 	envCACerts           = "MF_INFLUX_READER_CA_CERTS"
 	envServerCert        = "MF_INFLUX_READER_SERVER_CERT"
@@ -57,16 +83,28 @@ const (
 	envJaegerURL         = "MF_JAEGER_URL"
 	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
 	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envAuthURL           = "MF_AUTH_GRPC_URL"
+	envAuthTimeout       = "MF_AUTH_GRPC_TIMEOUT"
+	envESURL             = "MF_INFLUX_READER_ES_URL"
+	envESPass            = "MF_INFLUX_READER_ES_PASS"
+	envESDB              = "MF_INFLUX_READER_ES_DB"
 )
 
 type config struct {
 	logLevel          string
+	natsURL           string
 	port              string
+	grpcPort          string
 	dbName            string
 	dbHost            string
 	dbPort            string
 	dbUser            string
 	dbPass            string
+	dbVersion         string
+	dbOrg             string
+	dbBucket          string
+	dbToken           string
+	rollups           []influxdb.Rollup
 	clientTLS         bool
 	caCerts           string
 	serverCert        string
@@ -74,6 +112,11 @@ type config struct {
 	jaegerURL         string
 	thingsAuthURL     string
 	thingsAuthTimeout time.Duration
+	authURL           string
+	authTimeout       time.Duration
+	esURL             string
+	esPass            string
+	esDB              string
 }
 
 func main() {
@@ -90,14 +133,34 @@ func main() {
 
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
 
-	client, err := influxdata.NewHTTPClient(clientCfg)
+	authConn := connectToAuth(cfg, logger)
+	defer authConn.Close()
+
+	authTracer, authCloser := initJaeger("auth", cfg.jaegerURL, logger)
+	defer authCloser.Close()
+
+	ac := authapi.NewClient(authTracer, authConn, cfg.authTimeout)
+
+	var esClient *r.Client
+	if cfg.esURL != "" {
+		esClient = connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
+		defer esClient.Close()
+	}
+
+	repo := newService(newRepo(cfg, clientCfg, logger), esClient, logger)
+
+	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Failed to create InfluxDB client: %s", err))
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
 		os.Exit(1)
 	}
-	defer client.Close()
+	defer pubSub.Close()
 
-	repo := newService(client, cfg.dbName, logger)
+	stream := readers.NewLiveStream()
+	if err := pubSub.Subscribe(nats.SubjectAllChannels, stream.Handle); err != nil {
+		logger.Error(fmt.Sprintf("Failed to subscribe to live messages: %s", err))
+		os.Exit(1)
+	}
 
 	errs := make(chan error, 2)
 	go func() {
@@ -106,7 +169,8 @@ func main() {
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
-	go startHTTPServer(repo, tc, cfg, logger, errs)
+	go startHTTPServer(repo, tc, ac, stream, cfg, logger, errs)
+	go startGRPCServer(repo, tc, ac, cfg, logger, errs)
 
 	err = <-errs
 	logger.Error(fmt.Sprintf("InfluxDB writer service terminated: %s", err))
@@ -118,26 +182,48 @@ func loadConfigs() (config, influxdata.HTTPConfig) {
 		log.Fatalf("Invalid value passed for %s\n", envClientTLS)
 	}
 
-	authTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
+	thingsAuthTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
 	if err != nil {
 		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
 	}
 
+	authTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
+	}
+
+	rollups, err := influxdb.ParseRollups(mainflux.Env(envRollups, defRollups))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRollups, err.Error())
+	}
+
 	cfg := config{
 		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
+		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
 		port:              mainflux.Env(envPort, defPort),
+		grpcPort:          mainflux.Env(envGRPCPort, defGRPCPort),
 		dbName:            mainflux.Env(envDB, defDB),
 		dbHost:            mainflux.Env(envDBHost, defDBHost),
 		dbPort:            mainflux.Env(envDBPort, defDBPort),
 		dbUser:            mainflux.Env(envDBUser, defDBUser),
 		dbPass:            mainflux.Env(envDBPass, defDBPass),
+		dbVersion:         mainflux.Env(envDBVersion, defDBVersion),
+		dbOrg:             mainflux.Env(envDBOrg, defDBOrg),
+		dbBucket:          mainflux.Env(envDBBucket, defDBBucket),
+		dbToken:           mainflux.Env(envDBToken, defDBToken),
+		rollups:           rollups,
 		clientTLS:         tls,
 		caCerts:           mainflux.Env(envCACerts, defCACerts),
 		serverCert:        mainflux.Env(envServerCert, defServerCert),
 		serverKey:         mainflux.Env(envServerKey, defServerKey),
 		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
-		thingsAuthTimeout: authTimeout,
+		thingsAuthTimeout: thingsAuthTimeout,
+		authURL:           mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout:       authTimeout,
+		esURL:             mainflux.Env(envESURL, defESURL),
+		esPass:            mainflux.Env(envESPass, defESPass),
+		esDB:              mainflux.Env(envESDB, defESDB),
 	}
 
 	clientCfg := influxdata.HTTPConfig{
@@ -173,6 +259,29 @@ func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
 	return conn
 }
 
+func connectToAuth(cfg config, logger logger.Logger) *grpc.ClientConn {
+	var opts []grpc.DialOption
+	if cfg.clientTLS {
+		if cfg.caCerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.caCerts, "")
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to load certs: %s", err))
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.WithTransportCredentials(tpc))
+		}
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(cfg.authURL, opts...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to auth service: %s", err))
+		os.Exit(1)
+	}
+	return conn
+}
+
 func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer, io.Closer) {
 	if url == "" {
 		return opentracing.NoopTracer{}, ioutil.NopCloser(nil)
@@ -197,8 +306,49 @@ func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer,
 	return tracer, closer
 }
 
-func newService(client influxdata.Client, dbName string, logger logger.Logger) readers.MessageRepository {
-	repo := influxdb.New(client, dbName)
+// newRepo returns the reader of the configured InfluxDB version.
+func newRepo(cfg config, clientCfg influxdata.HTTPConfig, logger logger.Logger) readers.MessageRepository {
+	switch cfg.dbVersion {
+	case "1":
+		client, err := influxdata.NewHTTPClient(clientCfg)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to create InfluxDB client: %s", err))
+			os.Exit(1)
+		}
+		return influxdb.New(client, cfg.dbName, cfg.rollups)
+	case "2":
+		logger.Info("Using InfluxDB 2.x API")
+		client := influxdb2.New(influxdb2.Config{
+			URL:   clientCfg.Addr,
+			Org:   cfg.dbOrg,
+			Token: cfg.dbToken,
+		})
+		return influxdb.NewV2(client, cfg.dbBucket, cfg.rollups)
+	default:
+		logger.Error(fmt.Sprintf("Unsupported InfluxDB version %s", cfg.dbVersion))
+		os.Exit(1)
+		return nil
+	}
+}
+
+func connectToRedis(redisURL, redisPass, redisDB string, logger logger.Logger) *r.Client {
+	db, err := strconv.Atoi(redisDB)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to redis: %s", err))
+		os.Exit(1)
+	}
+
+	return r.NewClient(&r.Options{
+		Addr:     redisURL,
+		Password: redisPass,
+		DB:       db,
+	})
+}
+
+func newService(repo readers.MessageRepository, esClient *r.Client, logger logger.Logger) readers.MessageRepository {
+	if esClient != nil {
+		repo = redis.NewEventStoreMiddleware(repo, esClient)
+	}
 	repo = api.LoggingMiddleware(repo, logger)
 	repo = api.MetricsMiddleware(
 		repo,
@@ -219,14 +369,41 @@ func newService(client influxdata.Client, dbName string, logger logger.Logger) r
 	return repo
 }
 
-func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, cfg config, logger logger.Logger, errs chan error) {
+func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient, stream readers.LiveStream, cfg config, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", cfg.port)
 	if cfg.serverCert != "" || cfg.serverKey != "" {
 		logger.Info(fmt.Sprintf("InfluxDB reader service started using https on port %s with cert %s key %s",
 			cfg.port, cfg.serverCert, cfg.serverKey))
-		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tc, "influxdb-reader"))
+		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tc, ac, stream, "influxdb-reader"))
 		return
 	}
 	logger.Info(fmt.Sprintf("InfluxDB reader service started, exposed port %s", cfg.port))
-	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, "influxdb-reader"))
+	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, ac, stream, "influxdb-reader"))
+}
+
+func startGRPCServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient, cfg config, logger logger.Logger, errs chan error) {
+	p := fmt.Sprintf(":%s", cfg.grpcPort)
+	listener, err := net.Listen("tcp", p)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to listen on port %s: %s", cfg.grpcPort, err))
+		os.Exit(1)
+	}
+
+	var server *grpc.Server
+	if cfg.serverCert != "" || cfg.serverKey != "" {
+		creds, err := credentials.NewServerTLSFromFile(cfg.serverCert, cfg.serverKey)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to load influxdb reader certificates: %s", err))
+			os.Exit(1)
+		}
+		logger.Info(fmt.Sprintf("InfluxDB reader gRPC service started using https on port %s with cert %s key %s",
+			cfg.grpcPort, cfg.serverCert, cfg.serverKey))
+		server = grpc.NewServer(grpc.Creds(creds))
+	} else {
+		logger.Info(fmt.Sprintf("InfluxDB reader gRPC service started using http on port %s", cfg.grpcPort))
+		server = grpc.NewServer()
+	}
+
+	mainflux.RegisterReadersServiceServer(server, grpcapi.NewServer(repo, tc, ac))
+	errs <- server.Serve(listener)
 }
@@ -4,68 +4,168 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
-	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	r "github.com/go-redis/redis/v8"
 	influxdata "github.com/influxdata/influxdb/client/v2"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/writers"
 	"github.com/mainflux/mainflux/consumers/writers/api"
 	"github.com/mainflux/mainflux/consumers/writers/influxdb"
+	"github.com/mainflux/mainflux/consumers/writers/redis"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/influxdb2"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/avro"
 	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/lpp"
+	"github.com/mainflux/mainflux/pkg/transformers/lua"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
-	stdprometheus "github.com/prometheus/client_golang/prometheus"
 )
 
 const (
 	svcName = "influxdb-writer"
 
-	defNatsURL     = "nats://localhost:4222"
-	defLogLevel    = "error"
-	defPort        = "8180"
-	defDB          = "mainflux"
-	defDBHost      = "localhost"
-	defDBPort      = "8086"
-	defDBUser      = "mainflux"
-	defDBPass      = "mainflux"
-	defConfigPath  = "/config.toml"
-	defContentType = "application/senml+json"
-	defTransformer = "senml"
-
-	envNatsURL     = "MF_NATS_URL"
-	envLogLevel    = "MF_INFLUX_WRITER_LOG_LEVEL"
-	envPort        = "MF_INFLUX_WRITER_PORT"
-	envDB          = "MF_INFLUXDB_DB"
-	envDBHost      = "MF_INFLUX_WRITER_DB_HOST"
-	envDBPort      = "MF_INFLUXDB_PORT"
-	envDBUser      = "MF_INFLUXDB_ADMIN_USER"
-	envDBPass      = "MF_INFLUXDB_ADMIN_PASSWORD"
-	envConfigPath  = "MF_INFLUX_WRITER_CONFIG_PATH"
-	envContentType = "MF_INFLUX_WRITER_CONTENT_TYPE"
-	envTransformer = "MF_INFLUX_WRITER_TRANSFORMER"
+	defNatsURL               = "nats://localhost:4222"
+	defLogLevel              = "error"
+	defPort                  = "8180"
+	defDB                    = "mainflux"
+	defDBHost                = "localhost"
+	defDBPort                = "8086"
+	defDBUser                = "mainflux"
+	defDBPass                = "mainflux"
+	defDBVersion             = "1"
+	defDBOrg                 = "mainflux"
+	defDBBucket              = "mainflux"
+	defDBToken               = ""
+	defConfigPath            = "/config.toml"
+	defHookPath              = ""
+	defQueueSize             = "0"
+	defDedupWindow           = "0s"
+	defDedupSize             = "100000"
+	defDedupIDField          = ""
+	defDedupRedisURL         = ""
+	defDedupRedisPass        = ""
+	defDedupRedisDB          = "0"
+	defESURL                 = ""
+	defESPass                = ""
+	defESDB                  = "0"
+	defESConsumerName        = svcName
+	defContentType           = "application/senml+json"
+	defTransformer           = "senml"
+	defSenMLStrict           = "false"
+	defJSONNamePath          = ""
+	defJSONValuePath         = ""
+	defJSONTimePath          = ""
+	defSchemasRedisURL       = ""
+	defSchemasRedisPass      = ""
+	defSchemasRedisDB        = "0"
+	defSchemaRegistryURL     = ""
+	defSchemaRegistryTimeout = "5s"
+	defAdminToken            = ""
+	defBatchSize             = "1"
+	defFlushInterval         = "1s"
+	defRetryInterval         = "500ms"
+	defRetryMaxTime          = "0s"
+	defDeadLetterSubject     = ""
+
+	envNatsURL               = "MF_NATS_URL"
+	envLogLevel              = "MF_INFLUX_WRITER_LOG_LEVEL"
+	envPort                  = "MF_INFLUX_WRITER_PORT"
+	envDB                    = "MF_INFLUXDB_DB"
+	envDBHost                = "MF_INFLUX_WRITER_DB_HOST"
+	envDBPort                = "MF_INFLUXDB_PORT"
+	envDBUser                = "MF_INFLUXDB_ADMIN_USER"
+	envDBPass                = "MF_INFLUXDB_ADMIN_PASSWORD"
+	envDBVersion             = "MF_INFLUXDB_VERSION"
+	envDBOrg                 = "MF_INFLUXDB_ORG"
+	envDBBucket              = "MF_INFLUXDB_BUCKET"
+	envDBToken               = "MF_INFLUXDB_TOKEN"
+	envConfigPath            = "MF_INFLUX_WRITER_CONFIG_PATH"
+	envHookPath              = "MF_INFLUX_WRITER_HOOK_PATH"
+	envQueueSize             = "MF_INFLUX_WRITER_QUEUE_SIZE"
+	envDedupWindow           = "MF_INFLUX_WRITER_DEDUP_WINDOW"
+	envDedupSize             = "MF_INFLUX_WRITER_DEDUP_SIZE"
+	envDedupIDField          = "MF_INFLUX_WRITER_DEDUP_ID_FIELD"
+	envDedupRedisURL         = "MF_INFLUX_WRITER_DEDUP_REDIS_URL"
+	envDedupRedisPass        = "MF_INFLUX_WRITER_DEDUP_REDIS_PASS"
+	envDedupRedisDB          = "MF_INFLUX_WRITER_DEDUP_REDIS_DB"
+	envESURL                 = "MF_THINGS_ES_URL"
+	envESPass                = "MF_THINGS_ES_PASS"
+	envESDB                  = "MF_THINGS_ES_DB"
+	envESConsumerName        = "MF_INFLUX_WRITER_EVENT_CONSUMER"
+	envContentType           = "MF_INFLUX_WRITER_CONTENT_TYPE"
+	envTransformer           = "MF_INFLUX_WRITER_TRANSFORMER"
+	envSenMLStrict           = "MF_INFLUX_WRITER_SENML_STRICT"
+	envJSONNamePath          = "MF_INFLUX_WRITER_JSON_NAME_PATH"
+	envJSONValuePath         = "MF_INFLUX_WRITER_JSON_VALUE_PATH"
+	envJSONTimePath          = "MF_INFLUX_WRITER_JSON_TIME_PATH"
+	envSchemasRedisURL       = "MF_INFLUX_WRITER_SCHEMAS_REDIS_URL"
+	envSchemasRedisPass      = "MF_INFLUX_WRITER_SCHEMAS_REDIS_PASS"
+	envSchemasRedisDB        = "MF_INFLUX_WRITER_SCHEMAS_REDIS_DB"
+	envSchemaRegistryURL     = "MF_INFLUX_WRITER_SCHEMA_REGISTRY_URL"
+	envSchemaRegistryTimeout = "MF_INFLUX_WRITER_SCHEMA_REGISTRY_TIMEOUT"
+	envAdminToken            = "MF_INFLUX_WRITER_ADMIN_TOKEN"
+	envBatchSize             = "MF_INFLUX_WRITER_BATCH_SIZE"
+	envFlushInterval         = "MF_INFLUX_WRITER_FLUSH_INTERVAL"
+	envRetryInterval         = "MF_INFLUX_WRITER_RETRY_INTERVAL"
+	envRetryMaxTime          = "MF_INFLUX_WRITER_RETRY_MAX_TIME"
+	envDeadLetterSubject     = "MF_INFLUX_WRITER_DEAD_LETTER_SUBJECT"
 )
 
 type config struct {
-	natsURL     string
-	logLevel    string
-	port        string
-	dbName      string
-	dbHost      string
-	dbPort      string
-	dbUser      string
-	dbPass      string
-	configPath  string
-	contentType string
-	transformer string
+	natsURL               string
+	logLevel              string
+	port                  string
+	dbName                string
+	dbHost                string
+	dbPort                string
+	dbUser                string
+	dbPass                string
+	dbVersion             string
+	dbOrg                 string
+	dbBucket              string
+	dbToken               string
+	configPath            string
+	hookPath              string
+	queueSize             int
+	dedupWindow           time.Duration
+	dedupSize             int
+	dedupIDField          string
+	dedupRedisURL         string
+	dedupRedisPass        string
+	dedupRedisDB          string
+	esURL                 string
+	esPass                string
+	esDB                  string
+	esConsumerName        string
+	contentType           string
+	transformer           string
+	senmlStrict           bool
+	jsonMapping           json.Mapping
+	schemasRedisURL       string
+	schemasRedisPass      string
+	schemasRedisDB        string
+	schemaRegistryURL     string
+	schemaRegistryTimeout time.Duration
+	adminToken            string
+	batchSize             int
+	flushInterval         time.Duration
+	retryInterval         time.Duration
+	retryMaxTime          time.Duration
+	deadLetterSubject     string
 }
 
 func main() {
@@ -83,51 +183,150 @@ func main() {
 	}
 	defer pubSub.Close()
 
-	client, err := influxdata.NewHTTPClient(clientCfg)
-	if err != nil {
-		logger.Error(fmt.Sprintf("Failed to create InfluxDB client: %s", err))
-		os.Exit(1)
-	}
-	defer client.Close()
-
-	repo := influxdb.New(client, cfg.dbName)
+	repo, ping := newRepo(cfg, clientCfg, newRouter(cfg, logger), logger)
 
-	counter, latency := makeMetrics()
+	metrics := api.MakeMetrics("influxdb", pubSub.Pending)
 	repo = api.LoggingMiddleware(repo, logger)
-	repo = api.MetricsMiddleware(repo, counter, latency)
-	t := makeTransformer(cfg, logger)
+	repo = api.MetricsMiddleware(repo, metrics)
+	deadLetter := connectToDeadLetter(cfg, logger)
+	if deadLetter != nil {
+		defer deadLetter.Close()
+	}
+	repo = writers.NewRetryConsumer(repo, deadLetter, cfg.retryInterval, cfg.retryMaxTime, logger)
+	repo = writers.NewBatchConsumer(repo, cfg.batchSize, cfg.flushInterval, logger)
+	queue := writers.NewQueueConsumer(repo, cfg.queueSize, metrics.QueueDepth, metrics.Dropped)
+	repo = writers.NewDedupConsumer(queue, newDedupCache(cfg, logger), cfg.dedupIDField, logger)
+	filter, err := writers.LoadFilter(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to load filter: %s", err))
+	}
+	repo = writers.NewFilterConsumer(repo, filter)
+	repo = writers.NewHookConsumer(repo, loadHook(cfg, logger))
+	schemas := newSchemaRepository(cfg, logger)
+	t := makeTransformer(cfg, schemas, logger)
 
-	if err := consumers.Start(pubSub, repo, t, cfg.configPath, logger); err != nil {
+	subjects, err := consumers.StartReloadable(pubSub, repo, t, cfg.configPath, logger)
+	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to start InfluxDB writer: %s", err))
 		os.Exit(1)
 	}
+	go reloadSubjects(subjects, logger)
+
+	checks := map[string]func() error{
+		"nats":     pubSub.Status,
+		"database": ping,
+	}
 
 	errs := make(chan error, 2)
 	go func() {
-		c := make(chan os.Signal)
-		signal.Notify(c, syscall.SIGINT)
-		errs <- fmt.Errorf("%s", <-c)
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-c
+		// The queued and buffered messages are saved before the service
+		// terminates.
+		if err := writers.Close(queue); err != nil {
+			logger.Error(fmt.Sprintf("Failed to save queued messages: %s", err))
+		}
+		errs <- fmt.Errorf("%s", sig)
 	}()
 
-	go startHTTPService(cfg.port, logger, errs)
+	go startHTTPService(cfg.port, checks, schemas, cfg.adminToken, logger, errs)
 
 	err = <-errs
 	logger.Error(fmt.Sprintf("InfluxDB writer service terminated: %s", err))
 }
 
 func loadConfigs() (config, influxdata.HTTPConfig) {
+	queueSize, err := strconv.Atoi(mainflux.Env(envQueueSize, defQueueSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envQueueSize, err.Error())
+	}
+
+	dedupWindow, err := time.ParseDuration(mainflux.Env(envDedupWindow, defDedupWindow))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envDedupWindow, err.Error())
+	}
+
+	dedupSize, err := strconv.Atoi(mainflux.Env(envDedupSize, defDedupSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envDedupSize, err.Error())
+	}
+
+	batchSize, err := strconv.Atoi(mainflux.Env(envBatchSize, defBatchSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envBatchSize, err.Error())
+	}
+
+	flushInterval, err := time.ParseDuration(mainflux.Env(envFlushInterval, defFlushInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envFlushInterval, err.Error())
+	}
+
+	retryInterval, err := time.ParseDuration(mainflux.Env(envRetryInterval, defRetryInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetryInterval, err.Error())
+	}
+
+	retryMaxTime, err := time.ParseDuration(mainflux.Env(envRetryMaxTime, defRetryMaxTime))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetryMaxTime, err.Error())
+	}
+
+	senmlStrict, err := strconv.ParseBool(mainflux.Env(envSenMLStrict, defSenMLStrict))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envSenMLStrict, err.Error())
+	}
+
+	registryTimeout, err := time.ParseDuration(mainflux.Env(envSchemaRegistryTimeout, defSchemaRegistryTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envSchemaRegistryTimeout, err.Error())
+	}
+
 	cfg := config{
-		natsURL:     mainflux.Env(envNatsURL, defNatsURL),
-		logLevel:    mainflux.Env(envLogLevel, defLogLevel),
-		port:        mainflux.Env(envPort, defPort),
-		dbName:      mainflux.Env(envDB, defDB),
-		dbHost:      mainflux.Env(envDBHost, defDBHost),
-		dbPort:      mainflux.Env(envDBPort, defDBPort),
-		dbUser:      mainflux.Env(envDBUser, defDBUser),
-		dbPass:      mainflux.Env(envDBPass, defDBPass),
-		configPath:  mainflux.Env(envConfigPath, defConfigPath),
-		contentType: mainflux.Env(envContentType, defContentType),
-		transformer: mainflux.Env(envTransformer, defTransformer),
+		natsURL:        mainflux.Env(envNatsURL, defNatsURL),
+		logLevel:       mainflux.Env(envLogLevel, defLogLevel),
+		port:           mainflux.Env(envPort, defPort),
+		dbName:         mainflux.Env(envDB, defDB),
+		dbHost:         mainflux.Env(envDBHost, defDBHost),
+		dbPort:         mainflux.Env(envDBPort, defDBPort),
+		dbUser:         mainflux.Env(envDBUser, defDBUser),
+		dbPass:         mainflux.Env(envDBPass, defDBPass),
+		dbVersion:      mainflux.Env(envDBVersion, defDBVersion),
+		dbOrg:          mainflux.Env(envDBOrg, defDBOrg),
+		dbBucket:       mainflux.Env(envDBBucket, defDBBucket),
+		dbToken:        mainflux.Env(envDBToken, defDBToken),
+		configPath:     mainflux.Env(envConfigPath, defConfigPath),
+		hookPath:       mainflux.Env(envHookPath, defHookPath),
+		queueSize:      queueSize,
+		dedupWindow:    dedupWindow,
+		dedupSize:      dedupSize,
+		dedupIDField:   mainflux.Env(envDedupIDField, defDedupIDField),
+		dedupRedisURL:  mainflux.Env(envDedupRedisURL, defDedupRedisURL),
+		dedupRedisPass: mainflux.Env(envDedupRedisPass, defDedupRedisPass),
+		dedupRedisDB:   mainflux.Env(envDedupRedisDB, defDedupRedisDB),
+		esURL:          mainflux.Env(envESURL, defESURL),
+		esPass:         mainflux.Env(envESPass, defESPass),
+		esDB:           mainflux.Env(envESDB, defESDB),
+		esConsumerName: mainflux.Env(envESConsumerName, defESConsumerName),
+		contentType:    mainflux.Env(envContentType, defContentType),
+		transformer:    mainflux.Env(envTransformer, defTransformer),
+		senmlStrict:    senmlStrict,
+		jsonMapping: json.Mapping{
+			Name:  mainflux.Env(envJSONNamePath, defJSONNamePath),
+			Value: mainflux.Env(envJSONValuePath, defJSONValuePath),
+			Time:  mainflux.Env(envJSONTimePath, defJSONTimePath),
+		},
+		schemasRedisURL:       mainflux.Env(envSchemasRedisURL, defSchemasRedisURL),
+		schemasRedisPass:      mainflux.Env(envSchemasRedisPass, defSchemasRedisPass),
+		schemasRedisDB:        mainflux.Env(envSchemasRedisDB, defSchemasRedisDB),
+		schemaRegistryURL:     mainflux.Env(envSchemaRegistryURL, defSchemaRegistryURL),
+		schemaRegistryTimeout: registryTimeout,
+		adminToken:            mainflux.Env(envAdminToken, defAdminToken),
+		batchSize:             batchSize,
+		flushInterval:         flushInterval,
+		retryInterval:         retryInterval,
+		retryMaxTime:          retryMaxTime,
+		deadLetterSubject:     mainflux.Env(envDeadLetterSubject, defDeadLetterSubject),
 	}
 
 	clientCfg := influxdata.HTTPConfig{
@@ -139,32 +338,103 @@ func loadConfigs() (config, influxdata.HTTPConfig) {
 	return cfg, clientCfg
 }
 
-func makeMetrics() (*kitprometheus.Counter, *kitprometheus.Summary) {
-	counter := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
-		Namespace: "influxdb",
-		Subsystem: "message_writer",
-		Name:      "request_count",
-		Help:      "Number of database inserts.",
-	}, []string{"method"})
-
-	latency := kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
-		Namespace: "influxdb",
-		Subsystem: "message_writer",
-		Name:      "request_latency_microseconds",
-		Help:      "Total duration of inserts in microseconds.",
-	}, []string{"method"})
-
-	return counter, latency
+// newRepo returns the writer of the configured InfluxDB version, along with
+// the database health check. The route targets are the databases for
+// InfluxDB 1.x and the buckets for InfluxDB 2.x.
+func newRepo(cfg config, clientCfg influxdata.HTTPConfig, router writers.Router, logger logger.Logger) (consumers.Consumer, func() error) {
+	switch cfg.dbVersion {
+	case "1":
+		client, err := influxdata.NewHTTPClient(clientCfg)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to create InfluxDB client: %s", err))
+			os.Exit(1)
+		}
+		repo := influxdb.New(client, cfg.dbName)
+		repo = writers.NewRouterConsumer(repo, router, func(target string) (consumers.Consumer, error) {
+			return influxdb.New(client, target), nil
+		})
+		ping := func() error {
+			_, _, err := client.Ping(0)
+			return err
+		}
+		return repo, ping
+	case "2":
+		logger.Info("Using InfluxDB 2.x API")
+		client := influxdb2.New(influxdb2.Config{
+			URL:   clientCfg.Addr,
+			Org:   cfg.dbOrg,
+			Token: cfg.dbToken,
+		})
+		repo := influxdb.NewV2(client, cfg.dbBucket)
+		repo = writers.NewRouterConsumer(repo, router, func(target string) (consumers.Consumer, error) {
+			return influxdb.NewV2(client, target), nil
+		})
+		return repo, client.Ping
+	default:
+		logger.Error(fmt.Sprintf("Unsupported InfluxDB version %s", cfg.dbVersion))
+		os.Exit(1)
+		return nil, nil
+	}
 }
 
-func makeTransformer(cfg config, logger logger.Logger) transformers.Transformer {
+func makeTransformer(cfg config, schemas protobuf.SchemaRepository, logger logger.Logger) transformers.Transformer {
 	switch strings.ToUpper(cfg.transformer) {
 	case "SENML":
+		units, err := writers.LoadUnits(cfg.configPath)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Failed to load unit conversions: %s", err))
+		}
+		if cfg.senmlStrict {
+			logger.Info("Using strict SenML transformer")
+			return senml.NewStrict(cfg.contentType, units)
+		}
+		if len(units) > 0 {
+			logger.Info("Using SenML transformer with unit conversions")
+			return senml.NewNormalized(cfg.contentType, units)
+		}
 		logger.Info("Using SenML transformer")
 		return senml.New(cfg.contentType)
 	case "JSON":
 		logger.Info("Using JSON transformer")
 		return json.New()
+	case "MAPPED":
+		if cfg.jsonMapping.Value == "" {
+			logger.Error(fmt.Sprintf("Can't create mapped JSON transformer: %s is not set", envJSONValuePath))
+			os.Exit(1)
+		}
+		logger.Info("Using mapped JSON transformer")
+		return json.NewMapped(cfg.jsonMapping)
+	case "LPP":
+		logger.Info("Using Cayenne LPP transformer")
+		return lpp.New()
+	case "LUA":
+		scripts, err := writers.LoadScripts(cfg.configPath)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Can't create Lua transformer: %s", err))
+			os.Exit(1)
+		}
+		t, err := lua.New(scripts)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Can't create Lua transformer: %s", err))
+			os.Exit(1)
+		}
+		logger.Info("Using Lua transformer")
+		return t
+	case "PROTOBUF":
+		if schemas == nil {
+			logger.Error(fmt.Sprintf("Can't create protobuf transformer: %s is not set", envSchemasRedisURL))
+			os.Exit(1)
+		}
+		logger.Info("Using protobuf transformer")
+		return protobuf.New(schemas)
+	case "AVRO":
+		if cfg.schemaRegistryURL == "" {
+			logger.Error(fmt.Sprintf("Can't create Avro transformer: %s is not set", envSchemaRegistryURL))
+			os.Exit(1)
+		}
+		registry := avro.NewRegistry(cfg.schemaRegistryURL, cfg.schemaRegistryTimeout)
+		logger.Info("Using Avro transformer")
+		return avro.New(registry)
 	default:
 		logger.Error(fmt.Sprintf("Can't create transformer: unknown transformer type %s", cfg.transformer))
 		os.Exit(1)
@@ -172,8 +442,114 @@ func makeTransformer(cfg config, logger logger.Logger) transformers.Transformer
 	}
 }
 
-func startHTTPService(port string, logger logger.Logger, errs chan error) {
+func loadHook(cfg config, logger logger.Logger) writers.Hook {
+	if cfg.hookPath == "" {
+		return writers.Hook{}
+	}
+
+	hook, err := writers.LoadHook(cfg.hookPath)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load hook: %s", err))
+		os.Exit(1)
+	}
+	return hook
+}
+
+func newRouter(cfg config, logger logger.Logger) writers.Router {
+	routes, err := writers.LoadRoutes(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to load routes: %s", err))
+	}
+
+	if cfg.esURL == "" {
+		if len(routes) == 0 {
+			return nil
+		}
+		router, _ := writers.NewRouter(routes, nil)
+		return router
+	}
+
+	esClient := connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
+	router, err := writers.NewRouter(routes, redis.NewChannelRepository(esClient))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load routed channels: %s", err))
+		os.Exit(1)
+	}
+	go subscribeToThingsES(router, esClient, cfg.esConsumerName, logger)
+
+	return router
+}
+
+func connectToRedis(redisURL, redisPass, redisDB string, logger logger.Logger) *r.Client {
+	db, err := strconv.Atoi(redisDB)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to redis: %s", err))
+		os.Exit(1)
+	}
+
+	return r.NewClient(&r.Options{
+		Addr:     redisURL,
+		Password: redisPass,
+		DB:       db,
+	})
+}
+
+func newDedupCache(cfg config, logger logger.Logger) writers.DedupCache {
+	if cfg.dedupWindow <= 0 {
+		return nil
+	}
+	if cfg.dedupRedisURL == "" {
+		return writers.NewLRUCache(cfg.dedupSize, cfg.dedupWindow)
+	}
+
+	client := connectToRedis(cfg.dedupRedisURL, cfg.dedupRedisPass, cfg.dedupRedisDB, logger)
+	return redis.NewDedupCache(client, svcName, cfg.dedupWindow)
+}
+
+func newSchemaRepository(cfg config, logger logger.Logger) protobuf.SchemaRepository {
+	if cfg.schemasRedisURL == "" {
+		return nil
+	}
+
+	client := connectToRedis(cfg.schemasRedisURL, cfg.schemasRedisPass, cfg.schemasRedisDB, logger)
+	return redis.NewSchemaRepository(client)
+}
+
+func subscribeToThingsES(router writers.Router, client *r.Client, consumer string, logger logger.Logger) {
+	eventStore := redis.NewEventStore(router, client, consumer, logger)
+	logger.Info("Subscribed to Redis Event Store")
+	if err := eventStore.Subscribe(context.Background()); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to subscribe to Redis event source: %s", err))
+	}
+}
+
+func connectToDeadLetter(cfg config, logger logger.Logger) writers.DeadLetter {
+	if cfg.deadLetterSubject == "" {
+		return nil
+	}
+
+	dl, err := writers.NewDeadLetter(cfg.natsURL, cfg.deadLetterSubject)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
+	}
+	return dl
+}
+
+func reloadSubjects(subjects consumers.Subjects, logger logger.Logger) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	for range c {
+		if err := subjects.Reload(); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to reload subjects: %s", err))
+			continue
+		}
+		logger.Info("Reloaded subjects")
+	}
+}
+
+func startHTTPService(port string, checks map[string]func() error, schemas protobuf.SchemaRepository, adminToken string, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", port)
 	logger.Info(fmt.Sprintf("InfluxDB writer service started, exposed port %s", p))
-	errs <- http.ListenAndServe(p, api.MakeHandler(svcName))
+	errs <- http.ListenAndServe(p, api.MakeHandler(svcName, checks, schemas, adminToken))
 }
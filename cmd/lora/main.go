@@ -16,17 +16,24 @@ import (
 	"time"
 
 	r "github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/lora"
 	"github.com/mainflux/mainflux/lora/api"
+	"github.com/mainflux/mainflux/lora/chirpstack"
+	loramqtt "github.com/mainflux/mainflux/lora/mqtt"
+	"github.com/mainflux/mainflux/lora/postgres"
 	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/messaging/mqtt"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	"github.com/mainflux/mainflux/lora/redis"
+	"github.com/mainflux/mainflux/pkg/errors"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
@@ -42,6 +49,28 @@ const (
 	defRouteMapURL    = "localhost:6379"
 	defRouteMapPass   = ""
 	defRouteMapDB     = "0"
+	defLPP            = "false"
+
+	defServerMode = "legacy"
+	defMarshaler  = chirpstack.JSON
+	defAPIURL     = ""
+	defAPIToken   = ""
+	defClientTLS  = "false"
+	defCACerts    = ""
+	defCodec      = ""
+	defConfigPath = ""
+
+	defDeadLetterSubject = ""
+
+	defDBHost        = ""
+	defDBPort        = "5432"
+	defDBUser        = "mainflux"
+	defDBPass        = "mainflux"
+	defDB            = "lora"
+	defDBSSLMode     = "disable"
+	defDBSSLCert     = ""
+	defDBSSLKey      = ""
+	defDBSSLRootCert = ""
 
 	envHTTPPort       = "MF_LORA_ADAPTER_HTTP_PORT"
 	envLoraMsgURL     = "MF_LORA_ADAPTER_MESSAGES_URL"
@@ -55,12 +84,41 @@ const (
 	envRouteMapURL    = "MF_LORA_ADAPTER_ROUTE_MAP_URL"
 	envRouteMapPass   = "MF_LORA_ADAPTER_ROUTE_MAP_PASS"
 	envRouteMapDB     = "MF_LORA_ADAPTER_ROUTE_MAP_DB"
+	envLPP            = "MF_LORA_ADAPTER_LPP"
+
+	envServerMode = "MF_LORA_ADAPTER_SERVER_MODE"
+	envMarshaler  = "MF_LORA_ADAPTER_MARSHALER"
+	envAPIURL     = "MF_LORA_ADAPTER_API_URL"
+	envAPIToken   = "MF_LORA_ADAPTER_API_TOKEN"
+	envClientTLS  = "MF_LORA_ADAPTER_CLIENT_TLS"
+	envCACerts    = "MF_LORA_ADAPTER_CA_CERTS"
+	envCodec      = "MF_LORA_ADAPTER_CODEC"
+	envConfigPath = "MF_LORA_ADAPTER_CONFIG_PATH"
+
+	envDeadLetterSubject = "MF_LORA_ADAPTER_DEAD_LETTER_SUBJECT"
+
+	envDBHost        = "MF_LORA_ADAPTER_DB_HOST"
+	envDBPort        = "MF_LORA_ADAPTER_DB_PORT"
+	envDBUser        = "MF_LORA_ADAPTER_DB_USER"
+	envDBPass        = "MF_LORA_ADAPTER_DB_PASS"
+	envDB            = "MF_LORA_ADAPTER_DB"
+	envDBSSLMode     = "MF_LORA_ADAPTER_DB_SSL_MODE"
+	envDBSSLCert     = "MF_LORA_ADAPTER_DB_SSL_CERT"
+	envDBSSLKey      = "MF_LORA_ADAPTER_DB_SSL_KEY"
+	envDBSSLRootCert = "MF_LORA_ADAPTER_DB_SSL_ROOT_CERT"
+
+	legacyMode       = "legacy"
+	chirpstackV4Mode = "chirpstack-v4"
 
 	loraServerTopic = "application/+/device/+/rx"
+	loraEventTopic  = "application/+/device/+/%s"
+	commandsSubject = "channels.*." + lora.CommandsSubtopic + ".*"
+	natsQueue       = "lora"
 
-	thingsRMPrefix   = "thing"
-	channelsRMPrefix = "channel"
-	connsRMPrefix    = "connection"
+	thingsRMPrefix    = "thing"
+	appThingsRMPrefix = "app_thing"
+	channelsRMPrefix  = "channel"
+	connsRMPrefix     = "connection"
 )
 
 type config struct {
@@ -76,6 +134,15 @@ type config struct {
 	routeMapURL    string
 	routeMapPass   string
 	routeMapDB     string
+	codec          lora.CodecConfig
+	serverMode     string
+	marshaler      string
+	apiURL         string
+	apiToken       string
+	clientTLS      bool
+	caCerts        string
+	deadLetter     string
+	dbConfig       postgres.Config
 }
 
 func main() {
@@ -92,18 +159,45 @@ func main() {
 	esConn := connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
 	defer esConn.Close()
 
-	pub, err := nats.NewPublisher(cfg.natsURL)
+	pubSub, err := nats.NewPubSub(cfg.natsURL, natsQueue, logger)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
 		os.Exit(1)
 	}
-	defer pub.Close()
+	defer pubSub.Close()
 
-	thingsRM := newRouteMapRepository(rmConn, thingsRMPrefix, logger)
-	chansRM := newRouteMapRepository(rmConn, channelsRMPrefix, logger)
-	connsRM := newRouteMapRepository(rmConn, connsRMPrefix, logger)
+	mpub, err := mqtt.NewPublisher(cfg.loraMsgURL, cfg.subTimeout)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create MQTT publisher: %s", err))
+		os.Exit(1)
+	}
+	downlinker := newDownlinker(cfg, mpub, logger)
 
-	svc := lora.New(pub, thingsRM, chansRM, connsRM)
+	var devices lora.DeviceRepository
+	if cfg.apiURL != "" {
+		conn := connectToAPI(cfg, logger)
+		defer conn.Close()
+		devices = chirpstack.NewDeviceRepository(conn, cfg.apiToken)
+	}
+
+	var db *sqlx.DB
+	if cfg.dbConfig.Host != "" {
+		db = connectToDB(cfg.dbConfig, logger)
+		defer db.Close()
+	}
+
+	thingsRM := newRouteMapRepository(rmConn, db, thingsRMPrefix, logger)
+	appThingsRM := newRouteMapRepository(rmConn, db, appThingsRMPrefix, logger)
+	chansRM := newRouteMapRepository(rmConn, db, channelsRMPrefix, logger)
+	connsRM := newRouteMapRepository(rmConn, db, connsRMPrefix, logger)
+
+	codec, err := lora.NewCodec(cfg.codec)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create payload codec: %s", err))
+		os.Exit(1)
+	}
+
+	svc := lora.New(pubSub, downlinker, devices, thingsRM, appThingsRM, chansRM, connsRM, codec)
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
 		svc,
@@ -127,13 +221,28 @@ func main() {
 		os.Exit(1)
 	}
 
-	go subscribeToLoRaBroker(svc, msub, logger)
+	var deadLetter lora.DeadLetter
+	if cfg.deadLetter != "" {
+		if deadLetter, err = lora.NewDeadLetter(cfg.natsURL, cfg.deadLetter); err != nil {
+			logger.Error(fmt.Sprintf("Failed to connect to dead letter: %s", err))
+			os.Exit(1)
+		}
+		defer deadLetter.Close()
+	}
+
+	go subscribeToLoRaBroker(svc, msub, deadLetter, cfg, logger)
+
+	go subscribeToLoRaEvents(svc, msub, cfg, logger)
+
+	go subscribeToCommands(svc, pubSub, logger)
 
-	go subscribeToThingsES(svc, esConn, cfg.esConsumerName, logger)
+	eventStore := redis.NewEventStore(svc, esConn, cfg.esConsumerName, logger)
+
+	go subscribeToThingsES(eventStore, logger)
 
 	errs := make(chan error, 2)
 
-	go startHTTPServer(cfg, logger, errs)
+	go startHTTPServer(eventStore, cfg, logger, errs)
 
 	go func() {
 		c := make(chan os.Signal)
@@ -150,6 +259,54 @@ func loadConfig() config {
 	if err != nil {
 		log.Fatalf("Invalid %s value: %s", envSubTimeout, err.Error())
 	}
+
+	lpp, err := strconv.ParseBool(mainflux.Env(envLPP, defLPP))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envLPP, err.Error())
+	}
+
+	codec := lora.CodecConfig{}
+	if path := mainflux.Env(envConfigPath, defConfigPath); path != "" {
+		if codec, err = lora.LoadCodecConfig(path); err != nil {
+			log.Fatalf("Failed to load codecs from %s: %s", path, err.Error())
+		}
+	}
+	if codec.Default == "" {
+		codec.Default = mainflux.Env(envCodec, defCodec)
+	}
+	// MF_LORA_ADAPTER_LPP is kept for compatibility, selecting the LPP codec
+	// unless the default codec is set.
+	if codec.Default == "" && lpp {
+		codec.Default = lora.LPPCodec
+	}
+
+	serverMode := mainflux.Env(envServerMode, defServerMode)
+	if serverMode != legacyMode && serverMode != chirpstackV4Mode {
+		log.Fatalf("Invalid %s value: %s", envServerMode, serverMode)
+	}
+
+	marshaler := mainflux.Env(envMarshaler, defMarshaler)
+	if marshaler != chirpstack.JSON && marshaler != chirpstack.Protobuf {
+		log.Fatalf("Invalid %s value: %s", envMarshaler, marshaler)
+	}
+
+	tls, err := strconv.ParseBool(mainflux.Env(envClientTLS, defClientTLS))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envClientTLS, err.Error())
+	}
+
+	dbConfig := postgres.Config{
+		Host:        mainflux.Env(envDBHost, defDBHost),
+		Port:        mainflux.Env(envDBPort, defDBPort),
+		User:        mainflux.Env(envDBUser, defDBUser),
+		Pass:        mainflux.Env(envDBPass, defDBPass),
+		Name:        mainflux.Env(envDB, defDB),
+		SSLMode:     mainflux.Env(envDBSSLMode, defDBSSLMode),
+		SSLCert:     mainflux.Env(envDBSSLCert, defDBSSLCert),
+		SSLKey:      mainflux.Env(envDBSSLKey, defDBSSLKey),
+		SSLRootCert: mainflux.Env(envDBSSLRootCert, defDBSSLRootCert),
+	}
+
 	return config{
 		httpPort:       mainflux.Env(envHTTPPort, defHTTPPort),
 		loraMsgURL:     mainflux.Env(envLoraMsgURL, defLoraMsgURL),
@@ -163,6 +320,15 @@ func loadConfig() config {
 		routeMapURL:    mainflux.Env(envRouteMapURL, defRouteMapURL),
 		routeMapPass:   mainflux.Env(envRouteMapPass, defRouteMapPass),
 		routeMapDB:     mainflux.Env(envRouteMapDB, defRouteMapDB),
+		codec:          codec,
+		serverMode:     serverMode,
+		marshaler:      marshaler,
+		apiURL:         mainflux.Env(envAPIURL, defAPIURL),
+		apiToken:       mainflux.Env(envAPIToken, defAPIToken),
+		clientTLS:      tls,
+		caCerts:        mainflux.Env(envCACerts, defCACerts),
+		deadLetter:     mainflux.Env(envDeadLetterSubject, defDeadLetterSubject),
+		dbConfig:       dbConfig,
 	}
 }
 
@@ -180,18 +346,24 @@ func connectToRedis(redisURL, redisPass, redisDB string, logger logger.Logger) *
 	})
 }
 
-func subscribeToLoRaBroker(svc lora.Service, msub messaging.Subscriber, logger logger.Logger) {
-	err := msub.Subscribe(loraServerTopic, func(msg messaging.Message) error {
+func subscribeToLoRaBroker(svc lora.Service, msub messaging.Subscriber, deadLetter lora.DeadLetter, cfg config, logger logger.Logger) {
+	topic := loraServerTopic
+	decode := func(payload []byte) (lora.Message, error) {
 		var m lora.Message
-		if err := json.Unmarshal(msg.Payload, &m); err != nil {
-			logger.Warn(fmt.Sprintf("Failed to Unmarshal message: %s", err.Error()))
-			return err
+		if err := json.Unmarshal(payload, &m); err != nil {
+			return lora.Message{}, errors.Wrap(lora.ErrMalformedMessage, err)
 		}
-		if err := svc.Publish(context.Background(), m); err != nil {
-			return err
+		return m, nil
+	}
+	if cfg.serverMode == chirpstackV4Mode {
+		topic = chirpstack.UplinkTopic
+		decode = func(payload []byte) (lora.Message, error) {
+			return chirpstack.DecodeUplink(payload, cfg.marshaler)
 		}
-		return nil
-	})
+	}
+
+	handler := api.UplinkHandler(svc, decode, api.MakeUplinkMetrics(), deadLetter, logger)
+	err := msub.Subscribe(topic, handler)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to subscribe to LoRa MQTT broker: %s", err))
 		os.Exit(1)
@@ -199,21 +371,105 @@ func subscribeToLoRaBroker(svc lora.Service, msub messaging.Subscriber, logger l
 	logger.Info("Subscribed to LoRa MQTT broker")
 }
 
-func subscribeToThingsES(svc lora.Service, client *r.Client, consumer string, logger logger.Logger) {
-	eventStore := redis.NewEventStore(svc, client, consumer, logger)
+func subscribeToLoRaEvents(svc lora.Service, msub messaging.Subscriber, cfg config, logger logger.Logger) {
+	topic, types := loraEventTopic, lora.EventTypes
+	decode := lora.DecodeEvent
+	if cfg.serverMode == chirpstackV4Mode {
+		topic, types = chirpstack.EventTopic, chirpstack.EventTypes
+		decode = func(topic string, payload []byte) (lora.Event, error) {
+			return chirpstack.DecodeEvent(topic, payload, cfg.marshaler)
+		}
+	}
+
+	handler := api.EventHandler(svc, decode)
+	for _, typ := range types {
+		if err := msub.Subscribe(fmt.Sprintf(topic, typ), handler); err != nil {
+			logger.Error(fmt.Sprintf("Failed to subscribe to LoRa MQTT broker %s events: %s", typ, err))
+			os.Exit(1)
+		}
+	}
+	logger.Info("Subscribed to LoRa MQTT broker events")
+}
+
+func newDownlinker(cfg config, pub messaging.Publisher, logger logger.Logger) lora.Downlinker {
+	if cfg.serverMode != chirpstackV4Mode {
+		return loramqtt.NewDownlinker(pub)
+	}
+
+	downlinker, err := chirpstack.NewDownlinker(pub, cfg.marshaler)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create ChirpStack downlinker: %s", err))
+		os.Exit(1)
+	}
+	return downlinker
+}
+
+func connectToAPI(cfg config, logger logger.Logger) *grpc.ClientConn {
+	var opts []grpc.DialOption
+	if cfg.clientTLS {
+		if cfg.caCerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.caCerts, "")
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to create tls credentials: %s", err))
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.WithTransportCredentials(tpc))
+		} else {
+			opts = append(opts, grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, "")))
+		}
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+		logger.Info("gRPC communication is not encrypted")
+	}
+
+	conn, err := grpc.Dial(cfg.apiURL, opts...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to ChirpStack API: %s", err))
+		os.Exit(1)
+	}
+
+	return conn
+}
+
+func subscribeToCommands(svc lora.Service, sub messaging.Subscriber, logger logger.Logger) {
+	err := sub.Subscribe(commandsSubject, func(msg messaging.Message) error {
+		return svc.Command(context.Background(), msg)
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to subscribe to NATS commands: %s", err))
+		os.Exit(1)
+	}
+	logger.Info("Subscribed to NATS commands")
+}
+
+func connectToDB(dbConfig postgres.Config, logger logger.Logger) *sqlx.DB {
+	db, err := postgres.Connect(dbConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to postgres: %s", err))
+		os.Exit(1)
+	}
+	return db
+}
+
+func subscribeToThingsES(eventStore redis.Subscriber, logger logger.Logger) {
 	logger.Info("Subscribed to Redis Event Store")
 	if err := eventStore.Subscribe(context.Background(), "mainflux.things"); err != nil {
 		logger.Warn(fmt.Sprintf("LoRa-adapter service failed to subscribe to Redis event source: %s", err))
 	}
 }
 
-func newRouteMapRepository(client *r.Client, prefix string, logger logger.Logger) lora.RouteMapRepository {
+func newRouteMapRepository(client *r.Client, db *sqlx.DB, prefix string, logger logger.Logger) lora.RouteMapRepository {
+	if db != nil {
+		logger.Info(fmt.Sprintf("Connected to %s PostgreSQL Route-map cached in Redis", prefix))
+		return redis.NewRouteMapCache(client, prefix, postgres.NewRouteMapRepository(db, prefix))
+	}
+
 	logger.Info(fmt.Sprintf("Connected to %s Redis Route-map", prefix))
 	return redis.NewRouteMapRepository(client, prefix)
 }
 
-func startHTTPServer(cfg config, logger logger.Logger, errs chan error) {
+func startHTTPServer(replayer api.Replayer, cfg config, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", cfg.httpPort)
 	logger.Info(fmt.Sprintf("LoRa-adapter service started, exposed port %s", cfg.httpPort))
-	errs <- http.ListenAndServe(p, api.MakeHandler())
+	errs <- http.ListenAndServe(p, api.MakeHandler(replayer))
 }
@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,11 +18,16 @@ import (
 	"time"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	r "github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux"
+	authapi "github.com/mainflux/mainflux/auth/api/grpc"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/readers"
 	"github.com/mainflux/mainflux/readers/api"
+	grpcapi "github.com/mainflux/mainflux/readers/api/grpc"
 	"github.com/mainflux/mainflux/readers/mongodb"
+	"github.com/mainflux/mainflux/readers/redis"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -34,7 +40,9 @@ import (
 
 const (
 	defLogLevel          = "error"
+	defNatsURL           = "nats://localhost:4222"
 	defPort              = "8180"
+	defGRPCPort          = "8191"
 	defDB                = "mainflux"
 	defDBHost            = "localhost"
 	defDBPort            = "27017"
@@ -45,9 +53,16 @@ const (
 	defJaegerURL         = ""
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
+	defAuthURL           = "localhost:8181"
+	defAuthTimeout       = "1s"
+	defESURL             = ""
+	defESPass            = ""
+	defESDB              = "0"
 
 	envLogLevel          = "MF_MONGO_READER_LOG_LEVEL"
+	envNatsURL           = "MF_NATS_URL"
 	envPort              = "MF_MONGO_READER_PORT"
+	envGRPCPort          = "MF_MONGO_READER_GRPC_PORT"
 	envDB                = "MF_MONGO_READER_DB"
 	envDBHost            = "MF_MONGO_READER_DB_HOST"
 	envDBPort            = "MF_MONGO_READER_DB_PORT"
@@ -58,11 +73,18 @@ const (
 	envJaegerURL         = "MF_JAEGER_URL"
 	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
 	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envAuthURL           = "MF_AUTH_GRPC_URL"
+	envAuthTimeout       = "MF_AUTH_GRPC_TIMEOUT"
+	envESURL             = "MF_MONGO_READER_ES_URL"
+	envESPass            = "MF_MONGO_READER_ES_PASS"
+	envESDB              = "MF_MONGO_READER_ES_DB"
 )
 
 type config struct {
 	logLevel          string
+	natsURL           string
 	port              string
+	grpcPort          string
 	dbName            string
 	dbHost            string
 	dbPort            string
@@ -73,6 +95,11 @@ type config struct {
 	jaegerURL         string
 	thingsAuthURL     string
 	thingsAuthTimeout time.Duration
+	authURL           string
+	authTimeout       time.Duration
+	esURL             string
+	esPass            string
+	esDB              string
 }
 
 func main() {
@@ -90,9 +117,36 @@ func main() {
 
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
 
+	authConn := connectToAuth(cfg, logger)
+	defer authConn.Close()
+
+	authTracer, authCloser := initJaeger("auth", cfg.jaegerURL, logger)
+	defer authCloser.Close()
+
+	ac := authapi.NewClient(authTracer, authConn, cfg.authTimeout)
+
 	db := connectToMongoDB(cfg.dbHost, cfg.dbPort, cfg.dbName, logger)
 
-	repo := newService(db, logger)
+	var esClient *r.Client
+	if cfg.esURL != "" {
+		esClient = connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
+		defer esClient.Close()
+	}
+
+	repo := newService(db, esClient, logger)
+
+	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
+	}
+	defer pubSub.Close()
+
+	stream := readers.NewLiveStream()
+	if err := pubSub.Subscribe(nats.SubjectAllChannels, stream.Handle); err != nil {
+		logger.Error(fmt.Sprintf("Failed to subscribe to live messages: %s", err))
+		os.Exit(1)
+	}
 
 	errs := make(chan error, 2)
 	go func() {
@@ -101,7 +155,8 @@ func main() {
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
-	go startHTTPServer(repo, tc, cfg, logger, errs)
+	go startHTTPServer(repo, tc, ac, stream, cfg, logger, errs)
+	go startGRPCServer(repo, tc, ac, cfg, logger, errs)
 
 	err = <-errs
 	logger.Error(fmt.Sprintf("MongoDB reader service terminated: %s", err))
@@ -113,14 +168,21 @@ func loadConfigs() config {
 		log.Fatalf("Invalid value passed for %s\n", envClientTLS)
 	}
 
-	authTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
+	thingsAuthTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
 	if err != nil {
 		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
 	}
 
+	authTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
+	}
+
 	return config{
 		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
+		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
 		port:              mainflux.Env(envPort, defPort),
+		grpcPort:          mainflux.Env(envGRPCPort, defGRPCPort),
 		dbName:            mainflux.Env(envDB, defDB),
 		dbHost:            mainflux.Env(envDBHost, defDBHost),
 		dbPort:            mainflux.Env(envDBPort, defDBPort),
@@ -130,7 +192,12 @@ func loadConfigs() config {
 		serverKey:         mainflux.Env(envServerKey, defServerKey),
 		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
-		thingsAuthTimeout: authTimeout,
+		thingsAuthTimeout: thingsAuthTimeout,
+		authURL:           mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout:       authTimeout,
+		esURL:             mainflux.Env(envESURL, defESURL),
+		esPass:            mainflux.Env(envESPass, defESPass),
+		esDB:              mainflux.Env(envESDB, defESDB),
 	}
 }
 
@@ -193,8 +260,48 @@ func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
 	return conn
 }
 
-func newService(db *mongo.Database, logger logger.Logger) readers.MessageRepository {
+func connectToAuth(cfg config, logger logger.Logger) *grpc.ClientConn {
+	var opts []grpc.DialOption
+	if cfg.clientTLS {
+		if cfg.caCerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.caCerts, "")
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to load certs: %s", err))
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.WithTransportCredentials(tpc))
+		}
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(cfg.authURL, opts...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to auth service: %s", err))
+		os.Exit(1)
+	}
+	return conn
+}
+
+func connectToRedis(redisURL, redisPass, redisDB string, logger logger.Logger) *r.Client {
+	db, err := strconv.Atoi(redisDB)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to redis: %s", err))
+		os.Exit(1)
+	}
+
+	return r.NewClient(&r.Options{
+		Addr:     redisURL,
+		Password: redisPass,
+		DB:       db,
+	})
+}
+
+func newService(db *mongo.Database, esClient *r.Client, logger logger.Logger) readers.MessageRepository {
 	repo := mongodb.New(db)
+	if esClient != nil {
+		repo = redis.NewEventStoreMiddleware(repo, esClient)
+	}
 	repo = api.LoggingMiddleware(repo, logger)
 	repo = api.MetricsMiddleware(
 		repo,
@@ -215,14 +322,41 @@ func newService(db *mongo.Database, logger logger.Logger) readers.MessageReposit
 	return repo
 }
 
-func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, cfg config, logger logger.Logger, errs chan error) {
+func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient, stream readers.LiveStream, cfg config, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", cfg.port)
 	if cfg.serverCert != "" || cfg.serverKey != "" {
 		logger.Info(fmt.Sprintf("Mongo reader service started using https on port %s with cert %s key %s",
 			cfg.port, cfg.serverCert, cfg.serverKey))
-		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tc, "mongodb-reader"))
+		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tc, ac, stream, "mongodb-reader"))
 		return
 	}
 	logger.Info(fmt.Sprintf("Mongo reader service started, exposed port %s", cfg.port))
-	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, "mongodb-reader"))
+	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, ac, stream, "mongodb-reader"))
+}
+
+func startGRPCServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient, cfg config, logger logger.Logger, errs chan error) {
+	p := fmt.Sprintf(":%s", cfg.grpcPort)
+	listener, err := net.Listen("tcp", p)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to listen on port %s: %s", cfg.grpcPort, err))
+		os.Exit(1)
+	}
+
+	var server *grpc.Server
+	if cfg.serverCert != "" || cfg.serverKey != "" {
+		creds, err := credentials.NewServerTLSFromFile(cfg.serverCert, cfg.serverKey)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to load mongodb reader certificates: %s", err))
+			os.Exit(1)
+		}
+		logger.Info(fmt.Sprintf("Mongo reader gRPC service started using https on port %s with cert %s key %s",
+			cfg.grpcPort, cfg.serverCert, cfg.serverKey))
+		server = grpc.NewServer(grpc.Creds(creds))
+	} else {
+		logger.Info(fmt.Sprintf("Mongo reader gRPC service started using http on port %s", cfg.grpcPort))
+		server = grpc.NewServer()
+	}
+
+	mainflux.RegisterReadersServiceServer(server, grpcapi.NewServer(repo, tc, ac))
+	errs <- server.Serve(listener)
 }
@@ -1,33 +1,43 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	"github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux"
 	mflog "github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/mqtt"
+	"github.com/mainflux/mainflux/mqtt/api"
 	mqttredis "github.com/mainflux/mainflux/mqtt/redis"
 	"github.com/mainflux/mainflux/pkg/auth"
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/pkg/messaging"
 	mqttpub "github.com/mainflux/mainflux/pkg/messaging/mqtt"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	"github.com/mainflux/mainflux/pkg/uuid"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
-	mp "github.com/mainflux/mproxy/pkg/mqtt"
 	"github.com/mainflux/mproxy/pkg/session"
+	mptls "github.com/mainflux/mproxy/pkg/tls"
 	ws "github.com/mainflux/mproxy/pkg/websocket"
 	opentracing "github.com/opentracing/opentracing-go"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	jconfig "github.com/uber/jaeger-client-go/config"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -48,6 +58,20 @@ const (
 	envMQTTTargetPort        = "MF_MQTT_ADAPTER_MQTT_TARGET_PORT"
 	envMQTTTargetHealthCheck = "MF_MQTT_ADAPTER_MQTT_TARGET_HEALTH_CHECK"
 	envMQTTForwarderTimeout  = "MF_MQTT_ADAPTER_FORWARDER_TIMEOUT"
+	// MQTTS
+	defMQTTSPort     = "8883"
+	defServerCert    = ""
+	defServerKey     = ""
+	defClientCACerts = ""
+	envMQTTSPort     = "MF_MQTT_ADAPTER_MQTTS_PORT"
+	envServerCert    = "MF_MQTT_ADAPTER_SERVER_CERT"
+	envServerKey     = "MF_MQTT_ADAPTER_SERVER_KEY"
+	envClientCACerts = "MF_MQTT_ADAPTER_CLIENT_CA_CERTS"
+	// Certificate revocation
+	envCRLURL     = "MF_MQTT_ADAPTER_CRL_URL"
+	envCRLRefresh = "MF_MQTT_ADAPTER_CRL_REFRESH"
+	defCRLURL     = ""
+	defCRLRefresh = "5m"
 	// HTTP
 	defHTTPPort       = "8080"
 	defHTTPTargetHost = "localhost"
@@ -57,6 +81,12 @@ const (
 	envHTTPTargetHost = "MF_MQTT_ADAPTER_WS_TARGET_HOST"
 	envHTTPTargetPort = "MF_MQTT_ADAPTER_WS_TARGET_PORT"
 	envHTTPTargetPath = "MF_MQTT_ADAPTER_WS_TARGET_PATH"
+	// HTTPS
+	defHTTPSPort = "8443"
+	envHTTPSPort = "MF_MQTT_ADAPTER_WSS_PORT"
+	// API
+	defAPIPort = "8186"
+	envAPIPort = "MF_MQTT_ADAPTER_HTTP_PORT"
 	// Things
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
@@ -90,18 +120,66 @@ const (
 	defAuthcacheURL  = "localhost:6379"
 	defAuthCachePass = ""
 	defAuthCacheDB   = "0"
+	// Graceful shutdown
+	envDrainPeriod = "MF_MQTT_ADAPTER_DRAIN_PERIOD"
+	defDrainPeriod = "5s"
+	// Authorization decisions cache
+	envAuthzCacheTTL = "MF_MQTT_ADAPTER_AUTHZ_CACHE_TTL"
+	defAuthzCacheTTL = "0"
+	// Adapter cache
+	envCacheURL  = "MF_MQTT_ADAPTER_CACHE_URL"
+	envCachePass = "MF_MQTT_ADAPTER_CACHE_PASS"
+	envCacheDB   = "MF_MQTT_ADAPTER_CACHE_DB"
+	defCacheURL  = "localhost:6379"
+	defCachePass = ""
+	defCacheDB   = "0"
+	// Retained messages
+	envRetainEnabled = "MF_MQTT_ADAPTER_RETAIN_ENABLED"
+	envRetainMaxSize = "MF_MQTT_ADAPTER_RETAIN_MAX_SIZE"
+	envRetainTTL     = "MF_MQTT_ADAPTER_RETAIN_TTL"
+	defRetainEnabled = "false"
+	defRetainMaxSize = "65536"
+	defRetainTTL     = "24h"
+	// Rate limiting
+	envRateLimitMsgs  = "MF_MQTT_ADAPTER_RATE_LIMIT_MSGS"
+	envRateLimitBytes = "MF_MQTT_ADAPTER_RATE_LIMIT_BYTES"
+	envRateLimitMode  = "MF_MQTT_ADAPTER_RATE_LIMIT_MODE"
+	defRateLimitMsgs  = "0"
+	defRateLimitBytes = "0"
+	defRateLimitMode  = mqtt.ThrottleMode
+	// Block list
+	envBlockListEnabled = "MF_MQTT_ADAPTER_BLOCKLIST_ENABLED"
+	envAdminToken       = "MF_MQTT_ADAPTER_ADMIN_TOKEN"
+	defBlockListEnabled = "false"
+	defAdminToken       = ""
+	// Persistent sessions
+	envPersistSessions = "MF_MQTT_ADAPTER_PERSIST_SESSIONS"
+	envSessionTTL      = "MF_MQTT_ADAPTER_SESSION_TTL"
+	defPersistSessions = "false"
+	defSessionTTL      = "24h"
 )
 
+// crlTimeout is the timeout of fetching the certificate revocation list.
+const crlTimeout = 10 * time.Second
+
 type config struct {
 	mqttPort              string
 	mqttTargetHost        string
 	mqttTargetPort        string
 	mqttForwarderTimeout  time.Duration
 	mqttTargetHealthCheck string
+	mqttsPort             string
+	serverCert            string
+	serverKey             string
+	clientCACerts         string
+	crlURL                string
+	crlRefresh            time.Duration
 	httpPort              string
 	httpTargetHost        string
 	httpTargetPort        string
 	httpTargetPath        string
+	httpsPort             string
+	apiPort               string
 	jaegerURL             string
 	logLevel              string
 	thingsURL             string
@@ -117,6 +195,21 @@ type config struct {
 	authURL               string
 	authPass              string
 	authDB                string
+	authzCacheTTL         time.Duration
+	drainPeriod           time.Duration
+	cacheURL              string
+	cachePass             string
+	cacheDB               string
+	retainEnabled         bool
+	retainMaxSize         int
+	retainTTL             time.Duration
+	rateLimitMsgs         float64
+	rateLimitBytes        float64
+	rateLimitMode         string
+	blockListEnabled      bool
+	adminToken            string
+	persistSessions       bool
+	sessionTTL            time.Duration
 }
 
 func main() {
@@ -158,6 +251,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	messages := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "mqtt_adapter",
+		Subsystem: "api",
+		Name:      "message_count",
+		Help:      "Number of messages received from and sent to clients.",
+	}, []string{"direction"})
+	mpub = api.PublisherMetricsMiddleware(mpub, messages)
+
 	fwd := mqtt.NewForwarder(nats.SubjectAllChannels, logger)
 	if err := fwd.Forward(nps, mpub); err != nil {
 		logger.Error(fmt.Sprintf("Failed to forward NATS messages: %s", err))
@@ -180,27 +281,104 @@ func main() {
 	defer thingsCloser.Close()
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
 
-	authClient := auth.New(ac, tc)
+	var authClient auth.Client = auth.New(ac, tc)
+	var authCache mqtt.AuthCache
+	if cfg.authzCacheTTL > 0 {
+		authCache = mqtt.NewAuthCache(authClient, cfg.authzCacheTTL)
+		authClient = authCache
+		ts := mqttredis.NewThingsSubscriber(ec, authCache, logger)
+		go func() {
+			if err := ts.Subscribe(context.Background()); err != nil {
+				logger.Warn(fmt.Sprintf("Failed to subscribe to things events: %s", err))
+			}
+		}()
+	}
+
+	cc := connectToRedis(cfg.cacheURL, cfg.cachePass, cfg.cacheDB, logger)
+	defer cc.Close()
+
+	var retained mqttredis.RetainedStore
+	if cfg.retainEnabled {
+		retained = mqttredis.NewRetainedStore(cc, cfg.retainMaxSize, cfg.retainTTL)
+	}
+
+	var blocked mqttredis.BlockList
+	if cfg.blockListEnabled {
+		blocked = mqttredis.NewBlockList(cc)
+	}
+
+	var sessions mqttredis.SessionStore
+	if cfg.persistSessions {
+		sessions = mqttredis.NewSessionStore(cc, cfg.sessionTTL)
+	}
 
 	// Event handler for MQTT hooks
-	h := mqtt.NewHandler([]messaging.Publisher{np}, es, logger, authClient)
+	h := mqtt.NewHandler([]messaging.Publisher{np}, es, logger, authClient, retained, uuid.New())
+	if cfg.crlURL != "" {
+		h = mqtt.NewRevocationHandler(h, newCRL(cfg, logger), logger)
+	}
+	if authCache != nil {
+		h = mqtt.NewAuthCacheHandler(h, authCache)
+	}
+	if blocked != nil {
+		h = mqtt.NewBlockListHandler(h, blocked, logger)
+	}
+	if cfg.rateLimitMsgs > 0 || cfg.rateLimitBytes > 0 {
+		h = newRateLimitHandler(cfg, h)
+	}
+	h = api.MetricsMiddleware(
+		h,
+		kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "mqtt_adapter",
+			Subsystem: "api",
+			Name:      "connected_clients",
+			Help:      "Number of connected clients.",
+		}, []string{}),
+		messages,
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "mqtt_adapter",
+			Subsystem: "api",
+			Name:      "auth_failures",
+			Help:      "Number of failed authentications and authorizations.",
+		}, []string{"method"}),
+	)
 
 	errs := make(chan error, 2)
 
+	go startHTTPServer(cfg.apiPort, blocked, cfg.adminToken, logger, errs)
+
+	target := fmt.Sprintf("%s:%s", cfg.mqttTargetHost, cfg.mqttTargetPort)
+	mp := mqtt.NewProxy(fmt.Sprintf(":%s", cfg.mqttPort), target, h, sessions, retained, blocked, logger)
+	proxies := []*mqtt.Proxy{mp}
 	logger.Info(fmt.Sprintf("Starting MQTT proxy on port %s", cfg.mqttPort))
-	go proxyMQTT(cfg, logger, h, errs)
+	go proxyMQTT(mp, errs)
 
+	if cfg.serverCert != "" || cfg.serverKey != "" {
+		mps := mqtt.NewProxy(fmt.Sprintf(":%s", cfg.mqttsPort), target, h, sessions, retained, blocked, logger)
+		proxies = append(proxies, mps)
+		logger.Info(fmt.Sprintf("Starting MQTTS proxy on port %s", cfg.mqttsPort))
+		go proxyMQTTS(cfg, mps, errs)
+	}
+
+	wp := newWSProxy(cfg, logger, h, blocked)
 	logger.Info(fmt.Sprintf("Starting MQTT over WS  proxy on port %s", cfg.httpPort))
-	go proxyWS(cfg, logger, h, errs)
+	go proxyWS(cfg, wp, errs)
+
+	if cfg.serverCert != "" || cfg.serverKey != "" {
+		logger.Info(fmt.Sprintf("Starting MQTT over WSS proxy on port %s", cfg.httpsPort))
+		go proxyWSS(cfg, errs)
+	}
 
 	go func() {
 		c := make(chan os.Signal, 1)
-		signal.Notify(c, syscall.SIGINT)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
 	err = <-errs
 	logger.Error(fmt.Sprintf("mProxy terminated: %s", err))
+
+	drain(proxies, cfg.drainPeriod, logger)
 }
 
 func loadConfig() config {
@@ -219,16 +397,84 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envMQTTForwarderTimeout, err.Error())
 	}
 
+	retainEnabled, err := strconv.ParseBool(mainflux.Env(envRetainEnabled, defRetainEnabled))
+	if err != nil {
+		log.Fatalf("Invalid value passed for %s\n", envRetainEnabled)
+	}
+
+	retainMaxSize, err := strconv.Atoi(mainflux.Env(envRetainMaxSize, defRetainMaxSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetainMaxSize, err.Error())
+	}
+
+	retainTTL, err := time.ParseDuration(mainflux.Env(envRetainTTL, defRetainTTL))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetainTTL, err.Error())
+	}
+
+	rateLimitMsgs, err := strconv.ParseFloat(mainflux.Env(envRateLimitMsgs, defRateLimitMsgs), 64)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRateLimitMsgs, err.Error())
+	}
+
+	rateLimitBytes, err := strconv.ParseFloat(mainflux.Env(envRateLimitBytes, defRateLimitBytes), 64)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRateLimitBytes, err.Error())
+	}
+
+	rateLimitMode := mainflux.Env(envRateLimitMode, defRateLimitMode)
+	if rateLimitMode != mqtt.ThrottleMode && rateLimitMode != mqtt.DisconnectMode {
+		log.Fatalf("Invalid value passed for %s\n", envRateLimitMode)
+	}
+
+	persistSessions, err := strconv.ParseBool(mainflux.Env(envPersistSessions, defPersistSessions))
+	if err != nil {
+		log.Fatalf("Invalid value passed for %s\n", envPersistSessions)
+	}
+
+	sessionTTL, err := time.ParseDuration(mainflux.Env(envSessionTTL, defSessionTTL))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envSessionTTL, err.Error())
+	}
+
+	drainPeriod, err := time.ParseDuration(mainflux.Env(envDrainPeriod, defDrainPeriod))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envDrainPeriod, err.Error())
+	}
+
+	authzCacheTTL, err := time.ParseDuration(mainflux.Env(envAuthzCacheTTL, defAuthzCacheTTL))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthzCacheTTL, err.Error())
+	}
+
+	crlRefresh, err := time.ParseDuration(mainflux.Env(envCRLRefresh, defCRLRefresh))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envCRLRefresh, err.Error())
+	}
+
+	blockListEnabled, err := strconv.ParseBool(mainflux.Env(envBlockListEnabled, defBlockListEnabled))
+	if err != nil {
+		log.Fatalf("Invalid value passed for %s\n", envBlockListEnabled)
+	}
+
 	return config{
 		mqttPort:              mainflux.Env(envMQTTPort, defMQTTPort),
 		mqttTargetHost:        mainflux.Env(envMQTTTargetHost, defMQTTTargetHost),
 		mqttTargetPort:        mainflux.Env(envMQTTTargetPort, defMQTTTargetPort),
 		mqttForwarderTimeout:  mqttTimeout,
 		mqttTargetHealthCheck: mainflux.Env(envMQTTTargetHealthCheck, defMQTTTargetHealthCheck),
+		mqttsPort:             mainflux.Env(envMQTTSPort, defMQTTSPort),
+		serverCert:            mainflux.Env(envServerCert, defServerCert),
+		serverKey:             mainflux.Env(envServerKey, defServerKey),
+		clientCACerts:         mainflux.Env(envClientCACerts, defClientCACerts),
+		crlURL:                mainflux.Env(envCRLURL, defCRLURL),
+		crlRefresh:            crlRefresh,
 		httpPort:              mainflux.Env(envHTTPPort, defHTTPPort),
 		httpTargetHost:        mainflux.Env(envHTTPTargetHost, defHTTPTargetHost),
 		httpTargetPort:        mainflux.Env(envHTTPTargetPort, defHTTPTargetPort),
 		httpTargetPath:        mainflux.Env(envHTTPTargetPath, defHTTPTargetPath),
+		httpsPort:             mainflux.Env(envHTTPSPort, defHTTPSPort),
+		apiPort:               mainflux.Env(envAPIPort, defAPIPort),
 		jaegerURL:             mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:         mainflux.Env(envThingsAuthURL, defThingsAuthURL),
 		thingsAuthTimeout:     authTimeout,
@@ -244,9 +490,55 @@ func loadConfig() config {
 		authURL:               mainflux.Env(envAuthCacheURL, defAuthcacheURL),
 		authPass:              mainflux.Env(envAuthCachePass, defAuthCachePass),
 		authDB:                mainflux.Env(envAuthCacheDB, defAuthCacheDB),
+		cacheURL:              mainflux.Env(envCacheURL, defCacheURL),
+		cachePass:             mainflux.Env(envCachePass, defCachePass),
+		cacheDB:               mainflux.Env(envCacheDB, defCacheDB),
+		retainEnabled:         retainEnabled,
+		retainMaxSize:         retainMaxSize,
+		retainTTL:             retainTTL,
+		rateLimitMsgs:         rateLimitMsgs,
+		rateLimitBytes:        rateLimitBytes,
+		rateLimitMode:         rateLimitMode,
+		blockListEnabled:      blockListEnabled,
+		adminToken:            mainflux.Env(envAdminToken, defAdminToken),
+		persistSessions:       persistSessions,
+		sessionTTL:            sessionTTL,
+		authzCacheTTL:         authzCacheTTL,
+		drainPeriod:           drainPeriod,
 	}
 }
 
+func newCRL(cfg config, logger mflog.Logger) mqtt.RevocationList {
+	data, err := ioutil.ReadFile(cfg.clientCACerts)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load client CA certificates: %s", err))
+		os.Exit(1)
+	}
+
+	var cas []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		ca, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to parse client CA certificate: %s", err))
+			os.Exit(1)
+		}
+		cas = append(cas, ca)
+	}
+
+	if len(cas) == 0 {
+		logger.Error(fmt.Sprintf("%s requires client CA certificates to verify the CRL", envCRLURL))
+		os.Exit(1)
+	}
+
+	client := &http.Client{Timeout: crlTimeout}
+	return mqtt.NewCRL(cfg.crlURL, cas, cfg.crlRefresh, client)
+}
+
 func initJaeger(svcName, url string, logger mflog.Logger) (opentracing.Tracer, io.Closer) {
 	if url == "" {
 		return opentracing.NoopTracer{}, ioutil.NopCloser(nil)
@@ -309,21 +601,116 @@ func connectToRedis(redisURL, redisPass, redisDB string, logger mflog.Logger) *r
 	})
 }
 
-func proxyMQTT(cfg config, logger mflog.Logger, handler session.Handler, errs chan error) {
-	address := fmt.Sprintf(":%s", cfg.mqttPort)
-	target := fmt.Sprintf("%s:%s", cfg.mqttTargetHost, cfg.mqttTargetPort)
-	mp := mp.New(address, target, handler, logger)
+func newRateLimitHandler(cfg config, h mqtt.Handler) mqtt.Handler {
+	var msgs, bytes mqtt.RateLimiter
+	if cfg.rateLimitMsgs > 0 {
+		msgs = mqtt.NewRateLimiter(cfg.rateLimitMsgs)
+	}
+	if cfg.rateLimitBytes > 0 {
+		bytes = mqtt.NewRateLimiter(cfg.rateLimitBytes)
+	}
 
+	violations := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "mqtt_adapter",
+		Subsystem: "rate_limit",
+		Name:      "violations",
+		Help:      "Number of publish rate limit violations.",
+	}, []string{"limit"})
+
+	return mqtt.NewRateLimitHandler(h, msgs, bytes, cfg.rateLimitMode, violations)
+}
+
+func proxyMQTT(mp *mqtt.Proxy, errs chan error) {
 	errs <- mp.Listen()
 }
-func proxyWS(cfg config, logger mflog.Logger, handler session.Handler, errs chan error) {
+
+func startHTTPServer(port string, blocked mqttredis.BlockList, adminToken string, logger mflog.Logger, errs chan error) {
+	p := fmt.Sprintf(":%s", port)
+	logger.Info(fmt.Sprintf("MQTT adapter HTTP API service started on port %s", port))
+	errs <- http.ListenAndServe(p, api.MakeHandler(blocked, adminToken))
+}
+
+func proxyMQTTS(cfg config, mp *mqtt.Proxy, errs chan error) {
+	tlsCfg, err := mptls.LoadTLSCfg(cfg.clientCACerts, cfg.serverCert, cfg.serverKey)
+	if err != nil {
+		errs <- err
+		return
+	}
+
+	errs <- mp.ListenTLS(tlsCfg)
+}
+
+// drain disconnects the clients of all the proxies over the drain period.
+func drain(proxies []*mqtt.Proxy, period time.Duration, logger mflog.Logger) {
+	logger.Info(fmt.Sprintf("Draining MQTT proxies over %s", period))
+
+	var wg sync.WaitGroup
+	for _, mp := range proxies {
+		wg.Add(1)
+		go func(mp *mqtt.Proxy) {
+			defer wg.Done()
+			mp.Drain(period)
+		}(mp)
+	}
+	wg.Wait()
+}
+
+// newWSProxy creates MQTT over WS proxy and registers its handler,
+// which is used both by WS and WSS listeners.
+func newWSProxy(cfg config, logger mflog.Logger, handler session.Handler, blocked mqttredis.BlockList) *ws.Proxy {
 	target := fmt.Sprintf("%s:%s", cfg.httpTargetHost, cfg.httpTargetPort)
 	wp := ws.New(target, cfg.httpTargetPath, "ws", handler, logger)
-	http.Handle("/mqtt", wp.Handler())
+	h := wp.Handler()
+	if blocked != nil {
+		h = mqtt.NewBlockListMiddleware(h, blocked, logger)
+	}
+	http.Handle("/mqtt", h)
+
+	return wp
+}
 
+func proxyWS(cfg config, wp *ws.Proxy, errs chan error) {
 	errs <- wp.Listen(cfg.httpPort)
 }
 
+// proxyWSS serves the MQTT over WS handler over TLS, without requiring the
+// client certificates, as browsers usually have none.
+func proxyWSS(cfg config, errs chan error) {
+	cert, err := tls.LoadX509KeyPair(cfg.serverCert, cfg.serverKey)
+	if err != nil {
+		errs <- err
+		return
+	}
+
+	l, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.httpsPort))
+	if err != nil {
+		errs <- err
+		return
+	}
+
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+	errs <- http.Serve(wssListener{tls.NewListener(l, tlsCfg)}, nil)
+}
+
+// wssListener hides the TLS connections from mProxy, which expects each TLS
+// client to present a certificate, so the WSS clients authenticate using the
+// thing key as the password.
+type wssListener struct {
+	net.Listener
+}
+
+func (l wssListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return wssConn{conn}, nil
+}
+
+type wssConn struct {
+	net.Conn
+}
+
 func healthcheck(cfg config) func() error {
 	return func() error {
 		res, err := http.Get(cfg.mqttTargetHealthCheck)
@@ -5,13 +5,17 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 
 	r "github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux"
@@ -21,7 +25,13 @@ import (
 	"github.com/mainflux/mainflux/opcua/db"
 	"github.com/mainflux/mainflux/opcua/gopcua"
 	"github.com/mainflux/mainflux/opcua/redis"
+	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
+	opentracing "github.com/opentracing/opentracing-go"
+	jconfig "github.com/uber/jaeger-client-go/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -43,6 +53,11 @@ const (
 	defRouteMapURL    = "localhost:6379"
 	defRouteMapPass   = ""
 	defRouteMapDB     = "0"
+	defClientTLS      = "false"
+	defCACerts        = ""
+	defJaegerURL      = ""
+	defThingsAuthURL  = "localhost:8181"
+	defThingsTimeout  = "1s"
 
 	envLogLevel       = "MF_OPCUA_ADAPTER_LOG_LEVEL"
 	envHTTPPort       = "MF_OPCUA_ADAPTER_HTTP_PORT"
@@ -59,10 +74,17 @@ const (
 	envRouteMapURL    = "MF_OPCUA_ADAPTER_ROUTE_MAP_URL"
 	envRouteMapPass   = "MF_OPCUA_ADAPTER_ROUTE_MAP_PASS"
 	envRouteMapDB     = "MF_OPCUA_ADAPTER_ROUTE_MAP_DB"
+	envClientTLS      = "MF_OPCUA_ADAPTER_CLIENT_TLS"
+	envCACerts        = "MF_OPCUA_ADAPTER_CA_CERTS"
+	envJaegerURL      = "MF_JAEGER_URL"
+	envThingsAuthURL  = "MF_THINGS_AUTH_GRPC_URL"
+	envThingsTimeout  = "MF_THINGS_AUTH_GRPC_TIMEOUT"
 
 	thingsRMPrefix     = "thing"
 	channelsRMPrefix   = "channel"
 	connectionRMPrefix = "connection"
+
+	commandsSubject = "channels.*." + opcua.CommandsSubtopic + ".*"
 )
 
 type config struct {
@@ -77,6 +99,11 @@ type config struct {
 	routeMapURL    string
 	routeMapPass   string
 	routeMapDB     string
+	clientTLS      bool
+	caCerts        string
+	jaegerURL      string
+	thingsAuthURL  string
+	thingsTimeout  time.Duration
 }
 
 func main() {
@@ -104,11 +131,27 @@ func main() {
 	}
 	defer pubSub.Close()
 
+	conn := connectToThings(cfg, logger)
+	defer conn.Close()
+
+	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
+	defer thingsCloser.Close()
+
+	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsTimeout)
+
 	ctx := context.Background()
-	sub := gopcua.NewSubscriber(ctx, pubSub, thingRM, chanRM, connRM, logger)
-	browser := gopcua.NewBrowser(ctx, logger)
+	pool := gopcua.NewPool(ctx, cfg.opcuaConfig, logger)
+	defer pool.Close()
+
+	sub := gopcua.NewSubscriber(ctx, pool, pubSub, thingRM, chanRM, connRM, logger)
+	browser := gopcua.NewBrowser(pool, logger)
+	reader := gopcua.NewHistoryReader(ctx, pool, pubSub, thingRM, chanRM, connRM, logger)
+	writer := gopcua.NewWriter(pool, logger)
+	caller := gopcua.NewCaller(pool, logger)
 
-	svc := opcua.New(sub, browser, thingRM, chanRM, connRM, cfg.opcuaConfig, logger)
+	monitoring := redis.NewMonitoringRepository(rmConn)
+
+	svc := opcua.New(sub, browser, reader, writer, caller, tc, pubSub, thingRM, chanRM, connRM, monitoring, cfg.opcuaConfig, logger)
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
 		svc,
@@ -128,10 +171,11 @@ func main() {
 
 	go subscribeToStoredSubs(sub, cfg.opcuaConfig, logger)
 	go subscribeToThingsES(svc, esConn, cfg.esConsumerName, logger)
+	go subscribeToCommands(svc, pubSub, logger)
 
 	errs := make(chan error, 2)
 
-	go startHTTPServer(svc, cfg, logger, errs)
+	go startHTTPServer(svc, pool, cfg, logger, errs)
 
 	go func() {
 		c := make(chan os.Signal)
@@ -151,6 +195,16 @@ func loadConfig() config {
 		CertFile: mainflux.Env(envOPCCertFile, defOPCCertFile),
 		KeyFile:  mainflux.Env(envOPCKeyFile, defOPCKeyFile),
 	}
+	tls, err := strconv.ParseBool(mainflux.Env(envClientTLS, defClientTLS))
+	if err != nil {
+		log.Fatalf("Invalid value passed for %s\n", envClientTLS)
+	}
+
+	timeout, err := time.ParseDuration(mainflux.Env(envThingsTimeout, defThingsTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envThingsTimeout, err.Error())
+	}
+
 	return config{
 		httpPort:       mainflux.Env(envHTTPPort, defHTTPPort),
 		opcuaConfig:    oc,
@@ -163,9 +217,62 @@ func loadConfig() config {
 		routeMapURL:    mainflux.Env(envRouteMapURL, defRouteMapURL),
 		routeMapPass:   mainflux.Env(envRouteMapPass, defRouteMapPass),
 		routeMapDB:     mainflux.Env(envRouteMapDB, defRouteMapDB),
+		clientTLS:      tls,
+		caCerts:        mainflux.Env(envCACerts, defCACerts),
+		jaegerURL:      mainflux.Env(envJaegerURL, defJaegerURL),
+		thingsAuthURL:  mainflux.Env(envThingsAuthURL, defThingsAuthURL),
+		thingsTimeout:  timeout,
 	}
 }
 
+func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer, io.Closer) {
+	if url == "" {
+		return opentracing.NoopTracer{}, ioutil.NopCloser(nil)
+	}
+
+	tracer, closer, err := jconfig.Configuration{
+		ServiceName: svcName,
+		Sampler: &jconfig.SamplerConfig{
+			Type:  "const",
+			Param: 1,
+		},
+		Reporter: &jconfig.ReporterConfig{
+			LocalAgentHostPort: url,
+			LogSpans:           true,
+		},
+	}.NewTracer()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to init Jaeger client: %s", err))
+		os.Exit(1)
+	}
+
+	return tracer, closer
+}
+
+func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
+	var opts []grpc.DialOption
+	if cfg.clientTLS {
+		if cfg.caCerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.caCerts, "")
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to load certs: %s", err))
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.WithTransportCredentials(tpc))
+		}
+	} else {
+		logger.Info("gRPC communication is not encrypted")
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(cfg.thingsAuthURL, opts...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to things service: %s", err))
+		os.Exit(1)
+	}
+	return conn
+}
+
 func connectToRedis(redisURL, redisPass, redisDB string, logger logger.Logger) *r.Client {
 	db, err := strconv.Atoi(redisDB)
 	if err != nil {
@@ -190,11 +297,17 @@ func subscribeToStoredSubs(sub opcua.Subscriber, cfg opcua.Config, logger logger
 	for _, n := range nodes {
 		cfg.ServerURI = n.ServerURI
 		cfg.NodeID = n.NodeID
-		go func() {
+		cfg.Monitoring = opcua.MonitoringParams{}
+		if n.Monitoring != "" {
+			if err := json.Unmarshal([]byte(n.Monitoring), &cfg.Monitoring); err != nil {
+				logger.Warn(fmt.Sprintf("Invalid monitoring parameters of node %s: %s", n.NodeID, err))
+			}
+		}
+		go func(cfg opcua.Config) {
 			if err := sub.Subscribe(context.Background(), cfg); err != nil {
 				logger.Warn(fmt.Sprintf("Subscription failed: %s", err))
 			}
-		}()
+		}(cfg)
 	}
 }
 
@@ -204,6 +317,17 @@ func subscribeToOpcuaServer(gc opcua.Subscriber, cfg opcua.Config, logger logger
 	}
 }
 
+func subscribeToCommands(svc opcua.Service, sub messaging.Subscriber, logger logger.Logger) {
+	err := sub.Subscribe(commandsSubject, func(msg messaging.Message) error {
+		return svc.Command(context.Background(), msg)
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to subscribe to NATS commands: %s", err))
+		os.Exit(1)
+	}
+	logger.Info("Subscribed to NATS commands")
+}
+
 func subscribeToThingsES(svc opcua.Service, client *r.Client, prefix string, logger logger.Logger) {
 	eventStore := redis.NewEventStore(svc, client, prefix, logger)
 	if err := eventStore.Subscribe(context.Background(), "mainflux.things"); err != nil {
@@ -216,8 +340,8 @@ func newRouteMapRepositoy(client *r.Client, prefix string, logger logger.Logger)
 	return redis.NewRouteMapRepository(client, prefix)
 }
 
-func startHTTPServer(svc opcua.Service, cfg config, logger logger.Logger, errs chan error) {
+func startHTTPServer(svc opcua.Service, pool opcua.Pool, cfg config, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", cfg.httpPort)
 	logger.Info(fmt.Sprintf("opcua-adapter service started, exposed port %s", cfg.httpPort))
-	errs <- http.ListenAndServe(p, api.MakeHandler(svc))
+	errs <- http.ListenAndServe(p, api.MakeHandler(svc, pool))
 }
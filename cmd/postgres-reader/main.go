@@ -8,6 +8,7 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,12 +17,17 @@ import (
 	"time"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	r "github.com/go-redis/redis/v8"
 	"github.com/jmoiron/sqlx"
 	"github.com/mainflux/mainflux"
+	authapi "github.com/mainflux/mainflux/auth/api/grpc"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/readers"
 	"github.com/mainflux/mainflux/readers/api"
+	grpcapi "github.com/mainflux/mainflux/readers/api/grpc"
 	"github.com/mainflux/mainflux/readers/postgres"
+	"github.com/mainflux/mainflux/readers/redis"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -35,7 +41,9 @@ const (
 	sep     = ","
 
 	defLogLevel          = "error"
+	defNatsURL           = "nats://localhost:4222"
 	defPort              = "8180"
+	defGRPCPort          = "8191"
 	defClientTLS         = "false"
 	defCACerts           = ""
 	defDBHost            = "localhost"
@@ -50,9 +58,16 @@ const (
 	defJaegerURL         = ""
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
+	defAuthURL           = "localhost:8181"
+	defAuthTimeout       = "1s"
+	defESURL             = ""
+	defESPass            = ""
+	defESDB              = "0"
 
 	envLogLevel          = "MF_POSTGRES_READER_LOG_LEVEL"
+	envNatsURL           = "MF_NATS_URL"
 	envPort              = "MF_POSTGRES_READER_PORT"
+	envGRPCPort          = "MF_POSTGRES_READER_GRPC_PORT"
 	envClientTLS         = "MF_POSTGRES_READER_CLIENT_TLS"
 	envCACerts           = "MF_POSTGRES_READER_CA_CERTS"
 	envDBHost            = "MF_POSTGRES_READER_DB_HOST"
@@ -67,17 +82,29 @@ const (
 	envJaegerURL         = "MF_JAEGER_URL"
 	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
 	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envAuthURL           = "MF_AUTH_GRPC_URL"
+	envAuthTimeout       = "MF_AUTH_GRPC_TIMEOUT"
+	envESURL             = "MF_POSTGRES_READER_ES_URL"
+	envESPass            = "MF_POSTGRES_READER_ES_PASS"
+	envESDB              = "MF_POSTGRES_READER_ES_DB"
 )
 
 type config struct {
 	logLevel          string
+	natsURL           string
 	port              string
+	grpcPort          string
 	clientTLS         bool
 	caCerts           string
 	dbConfig          postgres.Config
 	jaegerURL         string
 	thingsAuthURL     string
 	thingsAuthTimeout time.Duration
+	authURL           string
+	authTimeout       time.Duration
+	esURL             string
+	esPass            string
+	esDB              string
 }
 
 func main() {
@@ -96,14 +123,42 @@ func main() {
 
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
 
+	authConn := connectToAuth(cfg, logger)
+	defer authConn.Close()
+
+	authTracer, authCloser := initJaeger("auth", cfg.jaegerURL, logger)
+	defer authCloser.Close()
+
+	ac := authapi.NewClient(authTracer, authConn, cfg.authTimeout)
+
 	db := connectToDB(cfg.dbConfig, logger)
 	defer db.Close()
 
-	repo := newService(db, logger)
+	var esClient *r.Client
+	if cfg.esURL != "" {
+		esClient = connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
+		defer esClient.Close()
+	}
+
+	repo := newService(db, esClient, logger)
+
+	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
+	}
+	defer pubSub.Close()
+
+	stream := readers.NewLiveStream()
+	if err := pubSub.Subscribe(nats.SubjectAllChannels, stream.Handle); err != nil {
+		logger.Error(fmt.Sprintf("Failed to subscribe to live messages: %s", err))
+		os.Exit(1)
+	}
 
 	errs := make(chan error, 2)
 
-	go startHTTPServer(repo, tc, cfg.port, logger, errs)
+	go startHTTPServer(repo, tc, ac, stream, cfg.port, logger, errs)
+	go startGRPCServer(repo, tc, ac, cfg.grpcPort, logger, errs)
 
 	go func() {
 		c := make(chan os.Signal)
@@ -133,20 +188,32 @@ func loadConfig() config {
 		log.Fatalf("Invalid value passed for %s\n", envClientTLS)
 	}
 
-	authTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
+	thingsAuthTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
 	if err != nil {
 		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
 	}
 
+	authTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
+	}
+
 	return config{
 		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
+		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
 		port:              mainflux.Env(envPort, defPort),
+		grpcPort:          mainflux.Env(envGRPCPort, defGRPCPort),
 		clientTLS:         tls,
 		caCerts:           mainflux.Env(envCACerts, defCACerts),
 		dbConfig:          dbConfig,
 		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
-		thingsAuthTimeout: authTimeout,
+		thingsAuthTimeout: thingsAuthTimeout,
+		authURL:           mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout:       authTimeout,
+		esURL:             mainflux.Env(envESURL, defESURL),
+		esPass:            mainflux.Env(envESPass, defESPass),
+		esDB:              mainflux.Env(envESDB, defESDB),
 	}
 }
 
@@ -207,8 +274,48 @@ func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
 	return conn
 }
 
-func newService(db *sqlx.DB, logger logger.Logger) readers.MessageRepository {
+func connectToAuth(cfg config, logger logger.Logger) *grpc.ClientConn {
+	var opts []grpc.DialOption
+	if cfg.clientTLS {
+		if cfg.caCerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.caCerts, "")
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to load certs: %s", err))
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.WithTransportCredentials(tpc))
+		}
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(cfg.authURL, opts...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to auth service: %s", err))
+		os.Exit(1)
+	}
+	return conn
+}
+
+func connectToRedis(redisURL, redisPass, redisDB string, logger logger.Logger) *r.Client {
+	db, err := strconv.Atoi(redisDB)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to redis: %s", err))
+		os.Exit(1)
+	}
+
+	return r.NewClient(&r.Options{
+		Addr:     redisURL,
+		Password: redisPass,
+		DB:       db,
+	})
+}
+
+func newService(db *sqlx.DB, esClient *r.Client, logger logger.Logger) readers.MessageRepository {
 	svc := postgres.New(db)
+	if esClient != nil {
+		svc = redis.NewEventStoreMiddleware(svc, esClient)
+	}
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
 		svc,
@@ -229,8 +336,22 @@ func newService(db *sqlx.DB, logger logger.Logger) readers.MessageRepository {
 	return svc
 }
 
-func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, port string, logger logger.Logger, errs chan error) {
+func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient, stream readers.LiveStream, port string, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", port)
 	logger.Info(fmt.Sprintf("Postgres reader service started, exposed port %s", port))
-	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, svcName))
+	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, ac, stream, svcName))
+}
+
+func startGRPCServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient, port string, logger logger.Logger, errs chan error) {
+	p := fmt.Sprintf(":%s", port)
+	listener, err := net.Listen("tcp", p)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to listen on port %s: %s", port, err))
+		os.Exit(1)
+	}
+
+	server := grpc.NewServer()
+	mainflux.RegisterReadersServiceServer(server, grpcapi.NewServer(repo, tc, ac))
+	logger.Info(fmt.Sprintf("Postgres reader gRPC service started, exposed port %s", port))
+	errs <- server.Serve(listener)
 }
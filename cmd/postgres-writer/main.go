@@ -4,73 +4,174 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
-	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	r "github.com/go-redis/redis/v8"
 	"github.com/jmoiron/sqlx"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/writers"
 	"github.com/mainflux/mainflux/consumers/writers/api"
 	"github.com/mainflux/mainflux/consumers/writers/postgres"
+	"github.com/mainflux/mainflux/consumers/writers/redis"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/compression"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/avro"
 	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/lpp"
+	"github.com/mainflux/mainflux/pkg/transformers/lua"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
-	stdprometheus "github.com/prometheus/client_golang/prometheus"
 )
 
 const (
 	svcName = "postgres-writer"
 	sep     = ","
 
-	defLogLevel      = "error"
-	defNatsURL       = "nats://localhost:4222"
-	defPort          = "8180"
-	defDBHost        = "localhost"
-	defDBPort        = "5432"
-	defDBUser        = "mainflux"
-	defDBPass        = "mainflux"
-	defDB            = "mainflux"
-	defDBSSLMode     = "disable"
-	defDBSSLCert     = ""
-	defDBSSLKey      = ""
-	defDBSSLRootCert = ""
-	defConfigPath    = "/config.toml"
-	defContentType   = "application/senml+json"
-	defTransformer   = "senml"
-
-	envNatsURL       = "MF_NATS_URL"
-	envLogLevel      = "MF_POSTGRES_WRITER_LOG_LEVEL"
-	envPort          = "MF_POSTGRES_WRITER_PORT"
-	envDBHost        = "MF_POSTGRES_WRITER_DB_HOST"
-	envDBPort        = "MF_POSTGRES_WRITER_DB_PORT"
-	envDBUser        = "MF_POSTGRES_WRITER_DB_USER"
-	envDBPass        = "MF_POSTGRES_WRITER_DB_PASS"
-	envDB            = "MF_POSTGRES_WRITER_DB"
-	envDBSSLMode     = "MF_POSTGRES_WRITER_DB_SSL_MODE"
-	envDBSSLCert     = "MF_POSTGRES_WRITER_DB_SSL_CERT"
-	envDBSSLKey      = "MF_POSTGRES_WRITER_DB_SSL_KEY"
-	envDBSSLRootCert = "MF_POSTGRES_WRITER_DB_SSL_ROOT_CERT"
-	envConfigPath    = "MF_POSTGRES_WRITER_CONFIG_PATH"
-	envContentType   = "MF_POSTGRES_WRITER_CONTENT_TYPE"
-	envTransformer   = "MF_POSTGRES_WRITER_TRANSFORMER"
+	defLogLevel              = "error"
+	defNatsURL               = "nats://localhost:4222"
+	defPort                  = "8180"
+	defDBHost                = "localhost"
+	defDBPort                = "5432"
+	defDBUser                = "mainflux"
+	defDBPass                = "mainflux"
+	defDB                    = "mainflux"
+	defDBSSLMode             = "disable"
+	defDBSSLCert             = ""
+	defDBSSLKey              = ""
+	defDBSSLRootCert         = ""
+	defPartition             = ""
+	defRetention             = ""
+	defPartitionCheck        = "1h"
+	defCompression           = ""
+	defConfigPath            = "/config.toml"
+	defHookPath              = ""
+	defQueueSize             = "0"
+	defDedupWindow           = "0s"
+	defDedupSize             = "100000"
+	defDedupIDField          = ""
+	defDedupRedisURL         = ""
+	defDedupRedisPass        = ""
+	defDedupRedisDB          = "0"
+	defESURL                 = ""
+	defESPass                = ""
+	defESDB                  = "0"
+	defESConsumerName        = svcName
+	defContentType           = "application/senml+json"
+	defTransformer           = "senml"
+	defSenMLStrict           = "false"
+	defJSONNamePath          = ""
+	defJSONValuePath         = ""
+	defJSONTimePath          = ""
+	defSchemasRedisURL       = ""
+	defSchemasRedisPass      = ""
+	defSchemasRedisDB        = "0"
+	defSchemaRegistryURL     = ""
+	defSchemaRegistryTimeout = "5s"
+	defAdminToken            = ""
+	defJSONNested            = "false"
+	defBatchSize             = "1"
+	defFlushInterval         = "1s"
+	defRetryInterval         = "500ms"
+	defRetryMaxTime          = "0s"
+	defDeadLetterSubject     = ""
+
+	envNatsURL               = "MF_NATS_URL"
+	envLogLevel              = "MF_POSTGRES_WRITER_LOG_LEVEL"
+	envPort                  = "MF_POSTGRES_WRITER_PORT"
+	envDBHost                = "MF_POSTGRES_WRITER_DB_HOST"
+	envDBPort                = "MF_POSTGRES_WRITER_DB_PORT"
+	envDBUser                = "MF_POSTGRES_WRITER_DB_USER"
+	envDBPass                = "MF_POSTGRES_WRITER_DB_PASS"
+	envDB                    = "MF_POSTGRES_WRITER_DB"
+	envDBSSLMode             = "MF_POSTGRES_WRITER_DB_SSL_MODE"
+	envDBSSLCert             = "MF_POSTGRES_WRITER_DB_SSL_CERT"
+	envDBSSLKey              = "MF_POSTGRES_WRITER_DB_SSL_KEY"
+	envDBSSLRootCert         = "MF_POSTGRES_WRITER_DB_SSL_ROOT_CERT"
+	envPartition             = "MF_POSTGRES_WRITER_PARTITION"
+	envRetention             = "MF_POSTGRES_WRITER_RETENTION"
+	envPartitionCheck        = "MF_POSTGRES_WRITER_PARTITION_CHECK_INTERVAL"
+	envCompression           = "MF_POSTGRES_WRITER_COMPRESSION"
+	envConfigPath            = "MF_POSTGRES_WRITER_CONFIG_PATH"
+	envHookPath              = "MF_POSTGRES_WRITER_HOOK_PATH"
+	envQueueSize             = "MF_POSTGRES_WRITER_QUEUE_SIZE"
+	envDedupWindow           = "MF_POSTGRES_WRITER_DEDUP_WINDOW"
+	envDedupSize             = "MF_POSTGRES_WRITER_DEDUP_SIZE"
+	envDedupIDField          = "MF_POSTGRES_WRITER_DEDUP_ID_FIELD"
+	envDedupRedisURL         = "MF_POSTGRES_WRITER_DEDUP_REDIS_URL"
+	envDedupRedisPass        = "MF_POSTGRES_WRITER_DEDUP_REDIS_PASS"
+	envDedupRedisDB          = "MF_POSTGRES_WRITER_DEDUP_REDIS_DB"
+	envESURL                 = "MF_THINGS_ES_URL"
+	envESPass                = "MF_THINGS_ES_PASS"
+	envESDB                  = "MF_THINGS_ES_DB"
+	envESConsumerName        = "MF_POSTGRES_WRITER_EVENT_CONSUMER"
+	envContentType           = "MF_POSTGRES_WRITER_CONTENT_TYPE"
+	envTransformer           = "MF_POSTGRES_WRITER_TRANSFORMER"
+	envSenMLStrict           = "MF_POSTGRES_WRITER_SENML_STRICT"
+	envJSONNamePath          = "MF_POSTGRES_WRITER_JSON_NAME_PATH"
+	envJSONValuePath         = "MF_POSTGRES_WRITER_JSON_VALUE_PATH"
+	envJSONTimePath          = "MF_POSTGRES_WRITER_JSON_TIME_PATH"
+	envSchemasRedisURL       = "MF_POSTGRES_WRITER_SCHEMAS_REDIS_URL"
+	envSchemasRedisPass      = "MF_POSTGRES_WRITER_SCHEMAS_REDIS_PASS"
+	envSchemasRedisDB        = "MF_POSTGRES_WRITER_SCHEMAS_REDIS_DB"
+	envSchemaRegistryURL     = "MF_POSTGRES_WRITER_SCHEMA_REGISTRY_URL"
+	envSchemaRegistryTimeout = "MF_POSTGRES_WRITER_SCHEMA_REGISTRY_TIMEOUT"
+	envAdminToken            = "MF_POSTGRES_WRITER_ADMIN_TOKEN"
+	envJSONNested            = "MF_POSTGRES_WRITER_JSON_NESTED"
+	envBatchSize             = "MF_POSTGRES_WRITER_BATCH_SIZE"
+	envFlushInterval         = "MF_POSTGRES_WRITER_FLUSH_INTERVAL"
+	envRetryInterval         = "MF_POSTGRES_WRITER_RETRY_INTERVAL"
+	envRetryMaxTime          = "MF_POSTGRES_WRITER_RETRY_MAX_TIME"
+	envDeadLetterSubject     = "MF_POSTGRES_WRITER_DEAD_LETTER_SUBJECT"
 )
 
 type config struct {
-	natsURL     string
-	logLevel    string
-	port        string
-	configPath  string
-	contentType string
-	transformer string
-	dbConfig    postgres.Config
+	natsURL               string
+	logLevel              string
+	port                  string
+	configPath            string
+	hookPath              string
+	queueSize             int
+	dedupWindow           time.Duration
+	dedupSize             int
+	dedupIDField          string
+	dedupRedisURL         string
+	dedupRedisPass        string
+	dedupRedisDB          string
+	esURL                 string
+	esPass                string
+	esDB                  string
+	esConsumerName        string
+	contentType           string
+	transformer           string
+	senmlStrict           bool
+	jsonMapping           json.Mapping
+	schemasRedisURL       string
+	schemasRedisPass      string
+	schemasRedisDB        string
+	schemaRegistryURL     string
+	schemaRegistryTimeout time.Duration
+	adminToken            string
+	jsonNested            bool
+	batchSize             int
+	flushInterval         time.Duration
+	retryInterval         time.Duration
+	retryMaxTime          time.Duration
+	deadLetterSubject     string
+	partitionCheck        time.Duration
+	compression           string
+	dbConfig              postgres.Config
 }
 
 func main() {
@@ -90,22 +191,57 @@ func main() {
 
 	db := connectToDB(cfg.dbConfig, logger)
 	defer db.Close()
+	go managePartitions(db, cfg.dbConfig, cfg.partitionCheck, logger)
+
+	metrics := api.MakeMetrics("postgres", pubSub.Pending)
+	repo := newService(db, newRouter(cfg, logger), cfg, metrics, logger)
+	deadLetter := connectToDeadLetter(cfg, logger)
+	if deadLetter != nil {
+		defer deadLetter.Close()
+	}
+	repo = writers.NewRetryConsumer(repo, deadLetter, cfg.retryInterval, cfg.retryMaxTime, logger)
+	repo = writers.NewBatchConsumer(repo, cfg.batchSize, cfg.flushInterval, logger)
+	queue := writers.NewQueueConsumer(repo, cfg.queueSize, metrics.QueueDepth, metrics.Dropped)
+	repo = writers.NewDedupConsumer(queue, newDedupCache(cfg, logger), cfg.dedupIDField, logger)
+	filter, err := writers.LoadFilter(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to load filter: %s", err))
+	}
+	repo = writers.NewFilterConsumer(repo, filter)
+	repo = writers.NewHookConsumer(repo, loadHook(cfg, logger))
+	schemas := newSchemaRepository(cfg, logger)
+	t := makeTransformer(cfg, schemas, logger)
 
-	repo := newService(db, logger)
-	t := makeTransformer(cfg, logger)
+	subjects, subErr := consumers.StartReloadable(pubSub, repo, t, cfg.configPath, logger)
+	if subErr != nil {
+		logger.Error(fmt.Sprintf("Failed to create Postgres writer: %s", subErr))
+	}
+	go reloadSubjects(subjects, logger)
 
-	if err = consumers.Start(pubSub, repo, t, cfg.configPath, logger); err != nil {
-		logger.Error(fmt.Sprintf("Failed to create Postgres writer: %s", err))
+	checks := map[string]func() error{
+		"nats": func() error {
+			if subErr != nil {
+				return subErr
+			}
+			return pubSub.Status()
+		},
+		"database": db.Ping,
 	}
 
 	errs := make(chan error, 2)
 
-	go startHTTPServer(cfg.port, errs, logger)
+	go startHTTPServer(cfg.port, checks, schemas, cfg.adminToken, errs, logger)
 
 	go func() {
-		c := make(chan os.Signal)
-		signal.Notify(c, syscall.SIGINT)
-		errs <- fmt.Errorf("%s", <-c)
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-c
+		// The queued and buffered messages are saved before the service
+		// terminates.
+		if err := writers.Close(queue); err != nil {
+			logger.Error(fmt.Sprintf("Failed to save queued messages: %s", err))
+		}
+		errs <- fmt.Errorf("%s", sig)
 	}()
 
 	err = <-errs
@@ -123,16 +259,110 @@ func loadConfig() config {
 		SSLCert:     mainflux.Env(envDBSSLCert, defDBSSLCert),
 		SSLKey:      mainflux.Env(envDBSSLKey, defDBSSLKey),
 		SSLRootCert: mainflux.Env(envDBSSLRootCert, defDBSSLRootCert),
+		Partition:   mainflux.Env(envPartition, defPartition),
+		Retention:   mainflux.Env(envRetention, defRetention),
+	}
+
+	queueSize, err := strconv.Atoi(mainflux.Env(envQueueSize, defQueueSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envQueueSize, err.Error())
+	}
+
+	dedupWindow, err := time.ParseDuration(mainflux.Env(envDedupWindow, defDedupWindow))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envDedupWindow, err.Error())
+	}
+
+	dedupSize, err := strconv.Atoi(mainflux.Env(envDedupSize, defDedupSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envDedupSize, err.Error())
+	}
+
+	batchSize, err := strconv.Atoi(mainflux.Env(envBatchSize, defBatchSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envBatchSize, err.Error())
+	}
+
+	flushInterval, err := time.ParseDuration(mainflux.Env(envFlushInterval, defFlushInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envFlushInterval, err.Error())
+	}
+
+	retryInterval, err := time.ParseDuration(mainflux.Env(envRetryInterval, defRetryInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetryInterval, err.Error())
+	}
+
+	retryMaxTime, err := time.ParseDuration(mainflux.Env(envRetryMaxTime, defRetryMaxTime))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetryMaxTime, err.Error())
+	}
+
+	jsonNested, err := strconv.ParseBool(mainflux.Env(envJSONNested, defJSONNested))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envJSONNested, err.Error())
+	}
+
+	codec := mainflux.Env(envCompression, defCompression)
+	if err := compression.Validate(codec); err != nil {
+		log.Fatalf("Invalid %s value: %s", envCompression, err.Error())
+	}
+
+	partitionCheck, err := time.ParseDuration(mainflux.Env(envPartitionCheck, defPartitionCheck))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envPartitionCheck, err.Error())
+	}
+
+	senmlStrict, err := strconv.ParseBool(mainflux.Env(envSenMLStrict, defSenMLStrict))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envSenMLStrict, err.Error())
+	}
+
+	registryTimeout, err := time.ParseDuration(mainflux.Env(envSchemaRegistryTimeout, defSchemaRegistryTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envSchemaRegistryTimeout, err.Error())
 	}
 
 	return config{
-		natsURL:     mainflux.Env(envNatsURL, defNatsURL),
-		logLevel:    mainflux.Env(envLogLevel, defLogLevel),
-		port:        mainflux.Env(envPort, defPort),
-		configPath:  mainflux.Env(envConfigPath, defConfigPath),
-		contentType: mainflux.Env(envContentType, defContentType),
-		transformer: mainflux.Env(envTransformer, defTransformer),
-		dbConfig:    dbConfig,
+		natsURL:        mainflux.Env(envNatsURL, defNatsURL),
+		logLevel:       mainflux.Env(envLogLevel, defLogLevel),
+		port:           mainflux.Env(envPort, defPort),
+		configPath:     mainflux.Env(envConfigPath, defConfigPath),
+		hookPath:       mainflux.Env(envHookPath, defHookPath),
+		queueSize:      queueSize,
+		dedupWindow:    dedupWindow,
+		dedupSize:      dedupSize,
+		dedupIDField:   mainflux.Env(envDedupIDField, defDedupIDField),
+		dedupRedisURL:  mainflux.Env(envDedupRedisURL, defDedupRedisURL),
+		dedupRedisPass: mainflux.Env(envDedupRedisPass, defDedupRedisPass),
+		dedupRedisDB:   mainflux.Env(envDedupRedisDB, defDedupRedisDB),
+		esURL:          mainflux.Env(envESURL, defESURL),
+		esPass:         mainflux.Env(envESPass, defESPass),
+		esDB:           mainflux.Env(envESDB, defESDB),
+		esConsumerName: mainflux.Env(envESConsumerName, defESConsumerName),
+		contentType:    mainflux.Env(envContentType, defContentType),
+		transformer:    mainflux.Env(envTransformer, defTransformer),
+		senmlStrict:    senmlStrict,
+		jsonMapping: json.Mapping{
+			Name:  mainflux.Env(envJSONNamePath, defJSONNamePath),
+			Value: mainflux.Env(envJSONValuePath, defJSONValuePath),
+			Time:  mainflux.Env(envJSONTimePath, defJSONTimePath),
+		},
+		schemasRedisURL:       mainflux.Env(envSchemasRedisURL, defSchemasRedisURL),
+		schemasRedisPass:      mainflux.Env(envSchemasRedisPass, defSchemasRedisPass),
+		schemasRedisDB:        mainflux.Env(envSchemasRedisDB, defSchemasRedisDB),
+		schemaRegistryURL:     mainflux.Env(envSchemaRegistryURL, defSchemaRegistryURL),
+		schemaRegistryTimeout: registryTimeout,
+		adminToken:            mainflux.Env(envAdminToken, defAdminToken),
+		jsonNested:            jsonNested,
+		batchSize:             batchSize,
+		flushInterval:         flushInterval,
+		retryInterval:         retryInterval,
+		retryMaxTime:          retryMaxTime,
+		deadLetterSubject:     mainflux.Env(envDeadLetterSubject, defDeadLetterSubject),
+		partitionCheck:        partitionCheck,
+		compression:           codec,
+		dbConfig:              dbConfig,
 	}
 }
 
@@ -145,36 +375,119 @@ func connectToDB(dbConfig postgres.Config, logger logger.Logger) *sqlx.DB {
 	return db
 }
 
-func newService(db *sqlx.DB, logger logger.Logger) consumers.Consumer {
-	svc := postgres.New(db)
+func newService(db *sqlx.DB, router writers.Router, cfg config, metrics api.Metrics, logger logger.Logger) consumers.Consumer {
+	svc := postgres.New(db, cfg.dbConfig.Partition, cfg.compression)
+	svc = writers.NewRouterConsumer(svc, router, func(target string) (consumers.Consumer, error) {
+		dbConfig := cfg.dbConfig
+		dbConfig.Name = target
+		db, err := postgres.Connect(dbConfig)
+		if err != nil {
+			return nil, err
+		}
+		go managePartitions(db, dbConfig, cfg.partitionCheck, logger)
+		return postgres.New(db, dbConfig.Partition, cfg.compression), nil
+	})
 	svc = api.LoggingMiddleware(svc, logger)
-	svc = api.MetricsMiddleware(
-		svc,
-		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
-			Namespace: "postgres",
-			Subsystem: "message_writer",
-			Name:      "request_count",
-			Help:      "Number of requests received.",
-		}, []string{"method"}),
-		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
-			Namespace: "postgres",
-			Subsystem: "message_writer",
-			Name:      "request_latency_microseconds",
-			Help:      "Total duration of requests in microseconds.",
-		}, []string{"method"}),
-	)
+	svc = api.MetricsMiddleware(svc, metrics)
 
 	return svc
 }
 
-func makeTransformer(cfg config, logger logger.Logger) transformers.Transformer {
+// managePartitions periodically creates the upcoming partitions of the
+// messages table and drops the expired ones.
+func managePartitions(db *sqlx.DB, dbConfig postgres.Config, interval time.Duration, logger logger.Logger) {
+	if dbConfig.Partition == "" {
+		return
+	}
+
+	pm := postgres.NewPartitionManager(db, dbConfig.Partition, dbConfig.Retention)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := pm.Create(); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to create partitions in %s: %s", dbConfig.Name, err))
+		}
+		dropped, err := pm.Drop()
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Failed to drop expired partitions in %s: %s", dbConfig.Name, err))
+		}
+		for _, name := range dropped {
+			logger.Info(fmt.Sprintf("Dropped expired partition %s in %s", name, dbConfig.Name))
+		}
+		<-ticker.C
+	}
+}
+
+func makeTransformer(cfg config, schemas protobuf.SchemaRepository, logger logger.Logger) transformers.Transformer {
 	switch strings.ToUpper(cfg.transformer) {
 	case "SENML":
+		units, err := writers.LoadUnits(cfg.configPath)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Failed to load unit conversions: %s", err))
+		}
+		if cfg.senmlStrict {
+			logger.Info("Using strict SenML transformer")
+			return senml.NewStrict(cfg.contentType, units)
+		}
+		if len(units) > 0 {
+			logger.Info("Using SenML transformer with unit conversions")
+			return senml.NewNormalized(cfg.contentType, units)
+		}
 		logger.Info("Using SenML transformer")
 		return senml.New(cfg.contentType)
 	case "JSON":
+		if cfg.jsonNested {
+			logger.Info("Using nested JSON transformer")
+			return json.NewNested()
+		}
 		logger.Info("Using JSON transformer")
 		return json.New()
+	case "MAPPED":
+		if cfg.jsonMapping.Value == "" {
+			logger.Error(fmt.Sprintf("Can't create mapped JSON transformer: %s is not set", envJSONValuePath))
+			os.Exit(1)
+		}
+		logger.Info("Using mapped JSON transformer")
+		return json.NewMapped(cfg.jsonMapping)
+	case "LPP":
+		logger.Info("Using Cayenne LPP transformer")
+		return lpp.New()
+	case "LUA":
+		scripts, err := writers.LoadScripts(cfg.configPath)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Can't create Lua transformer: %s", err))
+			os.Exit(1)
+		}
+		t, err := lua.New(scripts)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Can't create Lua transformer: %s", err))
+			os.Exit(1)
+		}
+		logger.Info("Using Lua transformer")
+		return t
+	case "PROTOBUF":
+		if schemas == nil {
+			logger.Error(fmt.Sprintf("Can't create protobuf transformer: %s is not set", envSchemasRedisURL))
+			os.Exit(1)
+		}
+		if cfg.jsonNested {
+			logger.Info("Using nested protobuf transformer")
+			return protobuf.NewNested(schemas)
+		}
+		logger.Info("Using protobuf transformer")
+		return protobuf.New(schemas)
+	case "AVRO":
+		if cfg.schemaRegistryURL == "" {
+			logger.Error(fmt.Sprintf("Can't create Avro transformer: %s is not set", envSchemaRegistryURL))
+			os.Exit(1)
+		}
+		registry := avro.NewRegistry(cfg.schemaRegistryURL, cfg.schemaRegistryTimeout)
+		if cfg.jsonNested {
+			logger.Info("Using nested Avro transformer")
+			return avro.NewNested(registry)
+		}
+		logger.Info("Using Avro transformer")
+		return avro.New(registry)
 	default:
 		logger.Error(fmt.Sprintf("Can't create transformer: unknown transformer type %s", cfg.transformer))
 		os.Exit(1)
@@ -182,8 +495,114 @@ func makeTransformer(cfg config, logger logger.Logger) transformers.Transformer
 	}
 }
 
-func startHTTPServer(port string, errs chan error, logger logger.Logger) {
+func loadHook(cfg config, logger logger.Logger) writers.Hook {
+	if cfg.hookPath == "" {
+		return writers.Hook{}
+	}
+
+	hook, err := writers.LoadHook(cfg.hookPath)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load hook: %s", err))
+		os.Exit(1)
+	}
+	return hook
+}
+
+func newRouter(cfg config, logger logger.Logger) writers.Router {
+	routes, err := writers.LoadRoutes(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to load routes: %s", err))
+	}
+
+	if cfg.esURL == "" {
+		if len(routes) == 0 {
+			return nil
+		}
+		router, _ := writers.NewRouter(routes, nil)
+		return router
+	}
+
+	esClient := connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
+	router, err := writers.NewRouter(routes, redis.NewChannelRepository(esClient))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load routed channels: %s", err))
+		os.Exit(1)
+	}
+	go subscribeToThingsES(router, esClient, cfg.esConsumerName, logger)
+
+	return router
+}
+
+func connectToRedis(redisURL, redisPass, redisDB string, logger logger.Logger) *r.Client {
+	db, err := strconv.Atoi(redisDB)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to redis: %s", err))
+		os.Exit(1)
+	}
+
+	return r.NewClient(&r.Options{
+		Addr:     redisURL,
+		Password: redisPass,
+		DB:       db,
+	})
+}
+
+func newDedupCache(cfg config, logger logger.Logger) writers.DedupCache {
+	if cfg.dedupWindow <= 0 {
+		return nil
+	}
+	if cfg.dedupRedisURL == "" {
+		return writers.NewLRUCache(cfg.dedupSize, cfg.dedupWindow)
+	}
+
+	client := connectToRedis(cfg.dedupRedisURL, cfg.dedupRedisPass, cfg.dedupRedisDB, logger)
+	return redis.NewDedupCache(client, svcName, cfg.dedupWindow)
+}
+
+func newSchemaRepository(cfg config, logger logger.Logger) protobuf.SchemaRepository {
+	if cfg.schemasRedisURL == "" {
+		return nil
+	}
+
+	client := connectToRedis(cfg.schemasRedisURL, cfg.schemasRedisPass, cfg.schemasRedisDB, logger)
+	return redis.NewSchemaRepository(client)
+}
+
+func subscribeToThingsES(router writers.Router, client *r.Client, consumer string, logger logger.Logger) {
+	eventStore := redis.NewEventStore(router, client, consumer, logger)
+	logger.Info("Subscribed to Redis Event Store")
+	if err := eventStore.Subscribe(context.Background()); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to subscribe to Redis event source: %s", err))
+	}
+}
+
+func connectToDeadLetter(cfg config, logger logger.Logger) writers.DeadLetter {
+	if cfg.deadLetterSubject == "" {
+		return nil
+	}
+
+	dl, err := writers.NewDeadLetter(cfg.natsURL, cfg.deadLetterSubject)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
+	}
+	return dl
+}
+
+func reloadSubjects(subjects consumers.Subjects, logger logger.Logger) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	for range c {
+		if err := subjects.Reload(); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to reload subjects: %s", err))
+			continue
+		}
+		logger.Info("Reloaded subjects")
+	}
+}
+
+func startHTTPServer(port string, checks map[string]func() error, schemas protobuf.SchemaRepository, adminToken string, errs chan error, logger logger.Logger) {
 	p := fmt.Sprintf(":%s", port)
 	logger.Info(fmt.Sprintf("Postgres writer service started, exposed port %s", port))
-	errs <- http.ListenAndServe(p, api.MakeHandler(svcName))
+	errs <- http.ListenAndServe(p, api.MakeHandler(svcName, checks, schemas, adminToken))
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/pkg/ulid"
+	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	jconfig "github.com/uber/jaeger-client-go/config"
@@ -68,6 +69,9 @@ const (
 	defAuthURL     = "localhost:8181"
 	defAuthTimeout = "1s"
 
+	defThingsAuthURL     = "localhost:8181"
+	defThingsAuthTimeout = "1s"
+
 	envLogLevel      = "MF_SMTP_NOTIFIER_LOG_LEVEL"
 	envDBHost        = "MF_SMTP_NOTIFIER_DB_HOST"
 	envDBPort        = "MF_SMTP_NOTIFIER_DB_PORT"
@@ -98,22 +102,27 @@ const (
 	envAuthCACerts = "MF_AUTH_CA_CERTS"
 	envAuthURL     = "MF_AUTH_GRPC_URL"
 	envAuthTimeout = "MF_AUTH_GRPC_TIMEOUT"
+
+	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
+	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
 )
 
 type config struct {
-	natsURL     string
-	configPath  string
-	logLevel    string
-	dbConfig    postgres.Config
-	emailConf   email.Config
-	httpPort    string
-	serverCert  string
-	serverKey   string
-	jaegerURL   string
-	authTLS     bool
-	authCACerts string
-	authURL     string
-	authTimeout time.Duration
+	natsURL           string
+	configPath        string
+	logLevel          string
+	dbConfig          postgres.Config
+	emailConf         email.Config
+	httpPort          string
+	serverCert        string
+	serverKey         string
+	jaegerURL         string
+	authTLS           bool
+	authCACerts       string
+	authURL           string
+	authTimeout       time.Duration
+	thingsAuthURL     string
+	thingsAuthTimeout time.Duration
 }
 
 func main() {
@@ -142,13 +151,19 @@ func main() {
 		defer close()
 	}
 
+	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
+	defer thingsCloser.Close()
+
+	things, thingsClose := connectToThings(cfg, thingsTracer, logger)
+	defer thingsClose()
+
 	tracer, closer := initJaeger("smtp-notifier", cfg.jaegerURL, logger)
 	defer closer.Close()
 
 	dbTracer, dbCloser := initJaeger("smtp-notifier_db", cfg.jaegerURL, logger)
 	defer dbCloser.Close()
 
-	svc := newService(db, dbTracer, auth, cfg, logger)
+	svc := newService(db, dbTracer, auth, things, cfg, logger)
 	errs := make(chan error, 2)
 
 	if err = consumers.Start(pubSub, svc, nil, cfg.configPath, logger); err != nil {
@@ -173,6 +188,11 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
 	}
 
+	thingsAuthTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
+	}
+
 	tls, err := strconv.ParseBool(mainflux.Env(envAuthTLS, defAuthTLS))
 	if err != nil {
 		log.Fatalf("Invalid value passed for %s\n", envAuthTLS)
@@ -215,6 +235,9 @@ func loadConfig() config {
 		authCACerts: mainflux.Env(envAuthCACerts, defAuthCACerts),
 		authURL:     mainflux.Env(envAuthURL, defAuthURL),
 		authTimeout: authTimeout,
+
+		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
+		thingsAuthTimeout: thingsAuthTimeout,
 	}
 
 }
@@ -277,7 +300,32 @@ func connectToAuth(cfg config, tracer opentracing.Tracer, logger logger.Logger)
 	return authapi.NewClient(tracer, conn, cfg.authTimeout), conn.Close
 }
 
-func newService(db *sqlx.DB, tracer opentracing.Tracer, auth mainflux.AuthServiceClient, c config, logger logger.Logger) notifiers.Service {
+func connectToThings(cfg config, tracer opentracing.Tracer, logger logger.Logger) (mainflux.ThingsServiceClient, func() error) {
+	var opts []grpc.DialOption
+	if cfg.authTLS {
+		if cfg.authCACerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.authCACerts, "")
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to create tls credentials: %s", err))
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.WithTransportCredentials(tpc))
+		}
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+		logger.Info("gRPC communication is not encrypted")
+	}
+
+	conn, err := grpc.Dial(cfg.thingsAuthURL, opts...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to things service: %s", err))
+		os.Exit(1)
+	}
+
+	return thingsapi.NewClient(conn, tracer, cfg.thingsAuthTimeout), conn.Close
+}
+
+func newService(db *sqlx.DB, tracer opentracing.Tracer, auth mainflux.AuthServiceClient, things mainflux.ThingsServiceClient, c config, logger logger.Logger) notifiers.Service {
 	database := postgres.NewDatabase(db)
 	repo := tracing.New(postgres.New(database), tracer)
 	idp := ulid.New()
@@ -289,7 +337,7 @@ func newService(db *sqlx.DB, tracer opentracing.Tracer, auth mainflux.AuthServic
 	}
 
 	notifier := smtp.New(agent)
-	svc := notifiers.New(auth, repo, idp, notifier)
+	svc := notifiers.New(auth, things, repo, idp, notifier)
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
 		svc,
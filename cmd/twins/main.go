@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -22,18 +23,20 @@ import (
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/mainflux/mainflux/pkg/uuid"
 	localusers "github.com/mainflux/mainflux/things/users"
 	"github.com/mainflux/mainflux/twins"
 	"github.com/mainflux/mainflux/twins/api"
 	twapi "github.com/mainflux/mainflux/twins/api/http"
 	twmongodb "github.com/mainflux/mainflux/twins/mongodb"
+	twpostgres "github.com/mainflux/mainflux/twins/postgres"
 	rediscache "github.com/mainflux/mainflux/twins/redis"
+	twthings "github.com/mainflux/mainflux/twins/things"
 	"github.com/mainflux/mainflux/twins/tracing"
 	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	jconfig "github.com/uber/jaeger-client-go/config"
-	"go.mongodb.org/mongo-driver/mongo"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -42,17 +45,33 @@ import (
 const (
 	queue = "twins"
 
+	dbMongo    = "mongodb"
+	dbPostgres = "postgres"
+
 	defLogLevel        = "error"
 	defHTTPPort        = "8180"
 	defJaegerURL       = ""
 	defServerCert      = ""
 	defServerKey       = ""
+	defDBType          = dbMongo
 	defDB              = "mainflux-twins"
 	defDBHost          = "localhost"
-	defDBPort          = "27017"
+	defMongoPort       = "27017"
+	defPostgresPort    = "5432"
+	defDBUser          = "mainflux"
+	defDBPass          = "mainflux"
+	defDBSSLMode       = "disable"
+	defDBSSLCert       = ""
+	defDBSSLKey        = ""
+	defDBSSLRootCert   = ""
 	defCacheURL        = "localhost:6379"
 	defCachePass       = ""
 	defCacheDB         = "0"
+	defESURL           = "localhost:6379"
+	defESPass          = ""
+	defESDB            = "0"
+	defBaseURL         = "http://localhost"
+	defThingsPrefix    = ""
 	defSingleUserEmail = ""
 	defSingleUserToken = ""
 	defClientTLS       = "false"
@@ -61,18 +80,31 @@ const (
 	defNatsURL         = "nats://localhost:4222"
 	defAuthURL         = "localhost:8181"
 	defAuthTimeout     = "1s"
+	defPurgeInterval   = "10m"
 
 	envLogLevel        = "MF_TWINS_LOG_LEVEL"
 	envHTTPPort        = "MF_TWINS_HTTP_PORT"
 	envJaegerURL       = "MF_JAEGER_URL"
 	envServerCert      = "MF_TWINS_SERVER_CERT"
 	envServerKey       = "MF_TWINS_SERVER_KEY"
+	envDBType          = "MF_TWINS_DB_TYPE"
 	envDB              = "MF_TWINS_DB"
 	envDBHost          = "MF_TWINS_DB_HOST"
 	envDBPort          = "MF_TWINS_DB_PORT"
+	envDBUser          = "MF_TWINS_DB_USER"
+	envDBPass          = "MF_TWINS_DB_PASS"
+	envDBSSLMode       = "MF_TWINS_DB_SSL_MODE"
+	envDBSSLCert       = "MF_TWINS_DB_SSL_CERT"
+	envDBSSLKey        = "MF_TWINS_DB_SSL_KEY"
+	envDBSSLRootCert   = "MF_TWINS_DB_SSL_ROOT_CERT"
 	envCacheURL        = "MF_TWINS_CACHE_URL"
 	envCachePass       = "MF_TWINS_CACHE_PASS"
 	envCacheDB         = "MF_TWINS_CACHE_DB"
+	envESURL           = "MF_TWINS_ES_URL"
+	envESPass          = "MF_TWINS_ES_PASS"
+	envESDB            = "MF_TWINS_ES_DB"
+	envBaseURL         = "MF_SDK_BASE_URL"
+	envThingsPrefix    = "MF_SDK_THINGS_PREFIX"
 	envSingleUserEmail = "MF_TWINS_SINGLE_USER_EMAIL"
 	envSingleUserToken = "MF_TWINS_SINGLE_USER_TOKEN"
 	envClientTLS       = "MF_TWINS_CLIENT_TLS"
@@ -81,6 +113,7 @@ const (
 	envNatsURL         = "MF_NATS_URL"
 	envAuthURL         = "MF_AUTH_GRPC_URL"
 	envAuthTimeout     = "MF_AUTH_GRPC_TIMEOUT"
+	envPurgeInterval   = "MF_TWINS_PURGE_INTERVAL"
 )
 
 type config struct {
@@ -89,10 +122,17 @@ type config struct {
 	jaegerURL       string
 	serverCert      string
 	serverKey       string
-	dbCfg           twmongodb.Config
+	dbType          string
+	mongoCfg        twmongodb.Config
+	postgresCfg     twpostgres.Config
 	cacheURL        string
 	cachePass       string
 	cacheDB         string
+	esURL           string
+	esPass          string
+	esDB            string
+	baseURL         string
+	thingsPrefix    string
 	singleUserEmail string
 	singleUserToken string
 	clientTLS       bool
@@ -102,6 +142,8 @@ type config struct {
 
 	authURL     string
 	authTimeout time.Duration
+
+	purgeInterval time.Duration
 }
 
 func main() {
@@ -116,11 +158,9 @@ func main() {
 	cacheTracer, cacheCloser := initJaeger("twins_cache", cfg.jaegerURL, logger)
 	defer cacheCloser.Close()
 
-	db, err := twmongodb.Connect(cfg.dbCfg, logger)
-	if err != nil {
-		logger.Error(err.Error())
-		os.Exit(1)
-	}
+	esClient := connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
+
+	twinRepo, stateRepo, purge := newRepositories(cfg, logger)
 	dbTracer, dbCloser := initJaeger("twins_db", cfg.jaegerURL, logger)
 	defer dbCloser.Close()
 
@@ -135,7 +175,15 @@ func main() {
 	}
 	defer pubSub.Close()
 
-	svc := newService(pubSub, cfg.channelID, auth, dbTracer, db, cacheTracer, cacheClient, logger)
+	sdk := mfsdk.NewSDK(mfsdk.Config{
+		BaseURL:      cfg.baseURL,
+		ThingsPrefix: cfg.thingsPrefix,
+	})
+	things := twthings.New(sdk)
+
+	svc := newService(pubSub, cfg.channelID, auth, things, dbTracer, twinRepo, stateRepo, cacheTracer, cacheClient, esClient, logger)
+
+	go purge(context.Background())
 
 	tracer, closer := initJaeger("twins", cfg.jaegerURL, logger)
 	defer closer.Close()
@@ -163,22 +211,56 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
 	}
 
-	dbCfg := twmongodb.Config{
+	purgeInterval, err := time.ParseDuration(mainflux.Env(envPurgeInterval, defPurgeInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envPurgeInterval, err.Error())
+	}
+
+	dbType := mainflux.Env(envDBType, defDBType)
+	if dbType != dbMongo && dbType != dbPostgres {
+		log.Fatalf("Invalid %s value: %s", envDBType, dbType)
+	}
+
+	defDBPort := defMongoPort
+	if dbType == dbPostgres {
+		defDBPort = defPostgresPort
+	}
+
+	mongoCfg := twmongodb.Config{
 		Name: mainflux.Env(envDB, defDB),
 		Host: mainflux.Env(envDBHost, defDBHost),
 		Port: mainflux.Env(envDBPort, defDBPort),
 	}
 
+	postgresCfg := twpostgres.Config{
+		Host:        mainflux.Env(envDBHost, defDBHost),
+		Port:        mainflux.Env(envDBPort, defDBPort),
+		User:        mainflux.Env(envDBUser, defDBUser),
+		Pass:        mainflux.Env(envDBPass, defDBPass),
+		Name:        mainflux.Env(envDB, defDB),
+		SSLMode:     mainflux.Env(envDBSSLMode, defDBSSLMode),
+		SSLCert:     mainflux.Env(envDBSSLCert, defDBSSLCert),
+		SSLKey:      mainflux.Env(envDBSSLKey, defDBSSLKey),
+		SSLRootCert: mainflux.Env(envDBSSLRootCert, defDBSSLRootCert),
+	}
+
 	return config{
 		logLevel:        mainflux.Env(envLogLevel, defLogLevel),
 		httpPort:        mainflux.Env(envHTTPPort, defHTTPPort),
 		serverCert:      mainflux.Env(envServerCert, defServerCert),
 		serverKey:       mainflux.Env(envServerKey, defServerKey),
 		jaegerURL:       mainflux.Env(envJaegerURL, defJaegerURL),
-		dbCfg:           dbCfg,
+		dbType:          dbType,
+		mongoCfg:        mongoCfg,
+		postgresCfg:     postgresCfg,
 		cacheURL:        mainflux.Env(envCacheURL, defCacheURL),
 		cachePass:       mainflux.Env(envCachePass, defCachePass),
 		cacheDB:         mainflux.Env(envCacheDB, defCacheDB),
+		esURL:           mainflux.Env(envESURL, defESURL),
+		esPass:          mainflux.Env(envESPass, defESPass),
+		esDB:            mainflux.Env(envESDB, defESDB),
+		baseURL:         mainflux.Env(envBaseURL, defBaseURL),
+		thingsPrefix:    mainflux.Env(envThingsPrefix, defThingsPrefix),
 		singleUserEmail: mainflux.Env(envSingleUserEmail, defSingleUserEmail),
 		singleUserToken: mainflux.Env(envSingleUserToken, defSingleUserToken),
 		clientTLS:       tls,
@@ -187,6 +269,7 @@ func loadConfig() config {
 		natsURL:         mainflux.Env(envNatsURL, defNatsURL),
 		authURL:         mainflux.Env(envAuthURL, defAuthURL),
 		authTimeout:     authTimeout,
+		purgeInterval:   purgeInterval,
 	}
 }
 
@@ -262,18 +345,43 @@ func connectToRedis(cacheURL, cachePass, cacheDB string, logger logger.Logger) *
 	})
 }
 
-func newService(ps messaging.PubSub, chanID string, users mainflux.AuthServiceClient, dbTracer opentracing.Tracer, db *mongo.Database, cacheTracer opentracing.Tracer, cacheClient *redis.Client, logger logger.Logger) twins.Service {
-	twinRepo := twmongodb.NewTwinRepository(db)
-	twinRepo = tracing.TwinRepositoryMiddleware(dbTracer, twinRepo)
+// newRepositories connects to the configured database and returns the twin
+// and state repositories, along with the function periodically purging the
+// states.
+func newRepositories(cfg config, logger logger.Logger) (twins.TwinRepository, twins.StateRepository, func(context.Context)) {
+	if cfg.dbType == dbPostgres {
+		db, err := twpostgres.Connect(cfg.postgresCfg)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to connect to postgres: %s", err))
+			os.Exit(1)
+		}
+		purge := func(ctx context.Context) {
+			twpostgres.PurgeStates(ctx, db, cfg.purgeInterval, logger)
+		}
+		return twpostgres.NewTwinRepository(db), twpostgres.NewStateRepository(db), purge
+	}
 
-	stateRepo := twmongodb.NewStateRepository(db)
+	db, err := twmongodb.Connect(cfg.mongoCfg, logger)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	purge := func(ctx context.Context) {
+		twmongodb.PurgeStates(ctx, db, cfg.purgeInterval, logger)
+	}
+	return twmongodb.NewTwinRepository(db), twmongodb.NewStateRepository(db), purge
+}
+
+func newService(ps messaging.PubSub, chanID string, users mainflux.AuthServiceClient, things twins.ThingsService, dbTracer opentracing.Tracer, twinRepo twins.TwinRepository, stateRepo twins.StateRepository, cacheTracer opentracing.Tracer, cacheClient *redis.Client, esClient *redis.Client, logger logger.Logger) twins.Service {
+	twinRepo = tracing.TwinRepositoryMiddleware(dbTracer, twinRepo)
 	stateRepo = tracing.StateRepositoryMiddleware(dbTracer, stateRepo)
 
 	idProvider := uuid.New()
 	twinCache := rediscache.NewTwinCache(cacheClient)
 	twinCache = tracing.TwinCacheMiddleware(cacheTracer, twinCache)
 
-	svc := twins.New(ps, users, twinRepo, twinCache, stateRepo, idProvider, chanID, logger)
+	svc := twins.New(ps, users, things, twinRepo, twinCache, stateRepo, idProvider, chanID, logger)
+	svc = rediscache.NewEventStoreMiddleware(svc, twinCache, esClient)
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
 		svc,
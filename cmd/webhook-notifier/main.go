@@ -0,0 +1,374 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/jmoiron/sqlx"
+	"github.com/mainflux/mainflux"
+	authapi "github.com/mainflux/mainflux/auth/api/grpc"
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/notifiers"
+	"github.com/mainflux/mainflux/consumers/notifiers/api"
+	"github.com/mainflux/mainflux/consumers/notifiers/postgres"
+	"github.com/mainflux/mainflux/consumers/notifiers/tracing"
+	"github.com/mainflux/mainflux/consumers/notifiers/webhook"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	"github.com/mainflux/mainflux/pkg/ulid"
+	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
+	opentracing "github.com/opentracing/opentracing-go"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	jconfig "github.com/uber/jaeger-client-go/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	defLogLevel      = "error"
+	defDBHost        = "localhost"
+	defDBPort        = "5432"
+	defDBUser        = "mainflux"
+	defDBPass        = "mainflux"
+	defDB            = "subscriptions"
+	defConfigPath    = "/config.toml"
+	defDBSSLMode     = "disable"
+	defDBSSLCert     = ""
+	defDBSSLKey      = ""
+	defDBSSLRootCert = ""
+	defHTTPPort      = "8907"
+	defServerCert    = ""
+	defServerKey     = ""
+	defJaegerURL     = ""
+	defNatsURL       = "nats://localhost:4222"
+
+	defSecret          = ""
+	defTimeout         = "5s"
+	defRetryInterval   = "500ms"
+	defRetryMaxElapsed = "10s"
+	defAllowPrivate    = "false"
+
+	defAuthTLS     = "false"
+	defAuthCACerts = ""
+	defAuthURL     = "localhost:8181"
+	defAuthTimeout = "1s"
+
+	defThingsAuthURL     = "localhost:8181"
+	defThingsAuthTimeout = "1s"
+
+	envLogLevel      = "MF_WEBHOOK_NOTIFIER_LOG_LEVEL"
+	envDBHost        = "MF_WEBHOOK_NOTIFIER_DB_HOST"
+	envDBPort        = "MF_WEBHOOK_NOTIFIER_DB_PORT"
+	envDBUser        = "MF_WEBHOOK_NOTIFIER_DB_USER"
+	envDBPass        = "MF_WEBHOOK_NOTIFIER_DB_PASS"
+	envDB            = "MF_WEBHOOK_NOTIFIER_DB"
+	envConfigPath    = "MF_WEBHOOK_NOTIFIER_CONFIG_PATH"
+	envDBSSLMode     = "MF_WEBHOOK_NOTIFIER_DB_SSL_MODE"
+	envDBSSLCert     = "MF_WEBHOOK_NOTIFIER_DB_SSL_CERT"
+	envDBSSLKey      = "MF_WEBHOOK_NOTIFIER_DB_SSL_KEY"
+	envDBSSLRootCert = "MF_WEBHOOK_NOTIFIER_DB_SSL_ROOT_CERT"
+	envHTTPPort      = "MF_WEBHOOK_NOTIFIER_PORT"
+	envServerCert    = "MF_WEBHOOK_NOTIFIER_SERVER_CERT"
+	envServerKey     = "MF_WEBHOOK_NOTIFIER_SERVER_KEY"
+	envJaegerURL     = "MF_JAEGER_URL"
+	envNatsURL       = "MF_NATS_URL"
+
+	envSecret          = "MF_WEBHOOK_NOTIFIER_SECRET"
+	envTimeout         = "MF_WEBHOOK_NOTIFIER_TIMEOUT"
+	envRetryInterval   = "MF_WEBHOOK_NOTIFIER_RETRY_INTERVAL"
+	envRetryMaxElapsed = "MF_WEBHOOK_NOTIFIER_RETRY_MAX_ELAPSED"
+	envAllowPrivate    = "MF_WEBHOOK_NOTIFIER_ALLOW_PRIVATE"
+
+	envAuthTLS     = "MF_AUTH_CLIENT_TLS"
+	envAuthCACerts = "MF_AUTH_CA_CERTS"
+	envAuthURL     = "MF_AUTH_GRPC_URL"
+	envAuthTimeout = "MF_AUTH_GRPC_TIMEOUT"
+
+	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
+	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+)
+
+type config struct {
+	natsURL           string
+	configPath        string
+	logLevel          string
+	dbConfig          postgres.Config
+	webhookConf       webhook.Config
+	httpPort          string
+	serverCert        string
+	serverKey         string
+	jaegerURL         string
+	authTLS           bool
+	authCACerts       string
+	authURL           string
+	authTimeout       time.Duration
+	thingsAuthURL     string
+	thingsAuthTimeout time.Duration
+}
+
+func main() {
+	cfg := loadConfig()
+
+	logger, err := logger.New(os.Stdout, cfg.logLevel)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	db := connectToDB(cfg.dbConfig, logger)
+	defer db.Close()
+
+	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
+	}
+	defer pubSub.Close()
+
+	authTracer, closer := initJaeger("auth", cfg.jaegerURL, logger)
+	defer closer.Close()
+
+	auth, close := connectToAuth(cfg, authTracer, logger)
+	if close != nil {
+		defer close()
+	}
+
+	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
+	defer thingsCloser.Close()
+
+	things, thingsClose := connectToThings(cfg, thingsTracer, logger)
+	defer thingsClose()
+
+	tracer, closer := initJaeger("webhook-notifier", cfg.jaegerURL, logger)
+	defer closer.Close()
+
+	dbTracer, dbCloser := initJaeger("webhook-notifier_db", cfg.jaegerURL, logger)
+	defer dbCloser.Close()
+
+	svc := newService(db, dbTracer, auth, things, cfg, logger)
+	errs := make(chan error, 2)
+
+	if err = consumers.Start(pubSub, svc, nil, cfg.configPath, logger); err != nil {
+		logger.Error(fmt.Sprintf("Failed to start webhook notifier: %s", err))
+	}
+
+	go startHTTPServer(tracer, svc, cfg.httpPort, cfg.serverCert, cfg.serverKey, logger, errs)
+
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT)
+		errs <- fmt.Errorf("%s", <-c)
+	}()
+
+	err = <-errs
+	logger.Error(fmt.Sprintf("Webhook notifier service terminated: %s", err))
+}
+
+func loadConfig() config {
+	authTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
+	}
+
+	thingsAuthTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
+	}
+
+	tls, err := strconv.ParseBool(mainflux.Env(envAuthTLS, defAuthTLS))
+	if err != nil {
+		log.Fatalf("Invalid value passed for %s\n", envAuthTLS)
+	}
+
+	allowPrivate, err := strconv.ParseBool(mainflux.Env(envAllowPrivate, defAllowPrivate))
+	if err != nil {
+		log.Fatalf("Invalid value passed for %s\n", envAllowPrivate)
+	}
+
+	dbConfig := postgres.Config{
+		Host:        mainflux.Env(envDBHost, defDBHost),
+		Port:        mainflux.Env(envDBPort, defDBPort),
+		User:        mainflux.Env(envDBUser, defDBUser),
+		Pass:        mainflux.Env(envDBPass, defDBPass),
+		Name:        mainflux.Env(envDB, defDB),
+		SSLMode:     mainflux.Env(envDBSSLMode, defDBSSLMode),
+		SSLCert:     mainflux.Env(envDBSSLCert, defDBSSLCert),
+		SSLKey:      mainflux.Env(envDBSSLKey, defDBSSLKey),
+		SSLRootCert: mainflux.Env(envDBSSLRootCert, defDBSSLRootCert),
+	}
+
+	webhookConf := webhook.Config{
+		Secret:          mainflux.Env(envSecret, defSecret),
+		Timeout:         parseDuration(envTimeout, defTimeout),
+		RetryInterval:   parseDuration(envRetryInterval, defRetryInterval),
+		RetryMaxElapsed: parseDuration(envRetryMaxElapsed, defRetryMaxElapsed),
+		AllowPrivate:    allowPrivate,
+	}
+
+	return config{
+		logLevel:    mainflux.Env(envLogLevel, defLogLevel),
+		natsURL:     mainflux.Env(envNatsURL, defNatsURL),
+		configPath:  mainflux.Env(envConfigPath, defConfigPath),
+		dbConfig:    dbConfig,
+		webhookConf: webhookConf,
+		httpPort:    mainflux.Env(envHTTPPort, defHTTPPort),
+		serverCert:  mainflux.Env(envServerCert, defServerCert),
+		serverKey:   mainflux.Env(envServerKey, defServerKey),
+		jaegerURL:   mainflux.Env(envJaegerURL, defJaegerURL),
+		authTLS:     tls,
+		authCACerts: mainflux.Env(envAuthCACerts, defAuthCACerts),
+		authURL:     mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout: authTimeout,
+
+		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
+		thingsAuthTimeout: thingsAuthTimeout,
+	}
+
+}
+
+func parseDuration(env, def string) time.Duration {
+	d, err := time.ParseDuration(mainflux.Env(env, def))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", env, err.Error())
+	}
+
+	return d
+}
+
+func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer, io.Closer) {
+	if url == "" {
+		return opentracing.NoopTracer{}, ioutil.NopCloser(nil)
+	}
+
+	tracer, closer, err := jconfig.Configuration{
+		ServiceName: svcName,
+		Sampler: &jconfig.SamplerConfig{
+			Type:  "const",
+			Param: 1,
+		},
+		Reporter: &jconfig.ReporterConfig{
+			LocalAgentHostPort: url,
+			LogSpans:           true,
+		},
+	}.NewTracer()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to init Jaeger: %s", err))
+		os.Exit(1)
+	}
+
+	return tracer, closer
+}
+
+func connectToDB(dbConfig postgres.Config, logger logger.Logger) *sqlx.DB {
+	db, err := postgres.Connect(dbConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to postgres: %s", err))
+		os.Exit(1)
+	}
+	return db
+}
+
+func connectToAuth(cfg config, tracer opentracing.Tracer, logger logger.Logger) (mainflux.AuthServiceClient, func() error) {
+	var opts []grpc.DialOption
+	if cfg.authTLS {
+		if cfg.authCACerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.authCACerts, "")
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to create tls credentials: %s", err))
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.WithTransportCredentials(tpc))
+		}
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+		logger.Info("gRPC communication is not encrypted")
+	}
+
+	conn, err := grpc.Dial(cfg.authURL, opts...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to auth service: %s", err))
+		os.Exit(1)
+	}
+
+	return authapi.NewClient(tracer, conn, cfg.authTimeout), conn.Close
+}
+
+func connectToThings(cfg config, tracer opentracing.Tracer, logger logger.Logger) (mainflux.ThingsServiceClient, func() error) {
+	var opts []grpc.DialOption
+	if cfg.authTLS {
+		if cfg.authCACerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.authCACerts, "")
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to create tls credentials: %s", err))
+				os.Exit(1)
+			}
+			opts = append(opts, grpc.WithTransportCredentials(tpc))
+		}
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+		logger.Info("gRPC communication is not encrypted")
+	}
+
+	conn, err := grpc.Dial(cfg.thingsAuthURL, opts...)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to things service: %s", err))
+		os.Exit(1)
+	}
+
+	return thingsapi.NewClient(conn, tracer, cfg.thingsAuthTimeout), conn.Close
+}
+
+func newService(db *sqlx.DB, tracer opentracing.Tracer, auth mainflux.AuthServiceClient, things mainflux.ThingsServiceClient, c config, logger logger.Logger) notifiers.Service {
+	database := postgres.NewDatabase(db)
+	repo := tracing.New(postgres.New(database), tracer)
+	idp := ulid.New()
+
+	failures := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "notifier",
+		Subsystem: "webhook",
+		Name:      "delivery_failures",
+		Help:      "Number of messages that failed to be delivered to webhooks.",
+	}, []string{"code"})
+
+	notifier := webhook.New(c.webhookConf, failures)
+	svc := notifiers.New(auth, things, repo, idp, notifier)
+	svc = api.LoggingMiddleware(svc, logger)
+	svc = api.MetricsMiddleware(
+		svc,
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "notifier",
+			Subsystem: "webhook",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, []string{"method"}),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "notifier",
+			Subsystem: "webhook",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, []string{"method"}),
+	)
+	return svc
+}
+
+func startHTTPServer(tracer opentracing.Tracer, svc notifiers.Service, port string, certFile string, keyFile string, logger logger.Logger, errs chan error) {
+	p := fmt.Sprintf(":%s", port)
+	if certFile != "" || keyFile != "" {
+		logger.Info(fmt.Sprintf("Webhook notifier service started using https, cert %s key %s, exposed port %s", certFile, keyFile, port))
+		errs <- http.ListenAndServeTLS(p, certFile, keyFile, api.MakeHandler(svc, tracer))
+	} else {
+		logger.Info(fmt.Sprintf("Webhook notifier service started using http, exposed port %s", port))
+		errs <- http.ListenAndServe(p, api.MakeHandler(svc, tracer))
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
 )
 
 const chansPrefix = "channels"
@@ -38,6 +39,10 @@ type Service interface {
 
 	// Unsubscribe method is used to stop observing resource.
 	Unsubscribe(ctx context.Context, key, chanID, subptopic, token string) error
+
+	// Discover returns the IDs of the channels the thing identified by the
+	// given key is connected to.
+	Discover(ctx context.Context, key string) ([]string, error)
 }
 
 var _ Service = (*adapterService)(nil)
@@ -48,15 +53,19 @@ type adapterService struct {
 	conn      *broker.Conn
 	observers map[string]observers
 	obsLock   sync.Mutex
+	strict    bool
 }
 
-// New instantiates the CoAP adapter implementation.
-func New(auth mainflux.ThingsServiceClient, nc *broker.Conn) Service {
+// New instantiates the CoAP adapter implementation. In strict mode, the SenML
+// messages violating RFC 8428 are rejected, reporting the invalid records
+// using senml.ValidationError, instead of being published.
+func New(auth mainflux.ThingsServiceClient, nc *broker.Conn, strict bool) Service {
 	as := &adapterService{
 		auth:      auth,
 		conn:      nc,
 		observers: make(map[string]observers),
 		obsLock:   sync.Mutex{},
+		strict:    strict,
 	}
 
 	return as
@@ -73,6 +82,12 @@ func (svc *adapterService) Publish(ctx context.Context, key string, msg messagin
 	}
 	msg.Publisher = thid.GetValue()
 
+	if svc.strict && (msg.ContentType == senml.JSON || msg.ContentType == senml.CBOR) {
+		if err := senml.Validate(msg.Payload, msg.ContentType); err != nil {
+			return err
+		}
+	}
+
 	data, err := proto.Marshal(&msg)
 	if err != nil {
 		return err
@@ -129,6 +144,15 @@ func (svc *adapterService) Unsubscribe(ctx context.Context, key, chanID, subtopi
 	return svc.remove(subject, token)
 }
 
+func (svc *adapterService) Discover(ctx context.Context, key string) ([]string, error) {
+	res, err := svc.auth.ChannelsByKey(ctx, &mainflux.Token{Value: key})
+	if err != nil {
+		return nil, errors.Wrap(ErrUnauthorized, err)
+	}
+
+	return res.GetValue(), nil
+}
+
 func (svc *adapterService) put(endpoint, token string, o Observer) error {
 	svc.obsLock.Lock()
 	defer svc.obsLock.Unlock()
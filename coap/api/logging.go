@@ -69,3 +69,16 @@ func (lm *loggingMiddleware) Unsubscribe(ctx context.Context, key, chanID, subto
 
 	return lm.svc.Unsubscribe(ctx, key, chanID, subtopic, token)
 }
+
+func (lm *loggingMiddleware) Discover(ctx context.Context, key string) (ids []string, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method discover took %s to complete", time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Discover(ctx, key)
+}
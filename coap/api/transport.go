@@ -4,7 +4,9 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -18,8 +20,10 @@ import (
 	"github.com/go-zoo/bone"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/coap"
+	"github.com/mainflux/mainflux/coap/oscore"
 	log "github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/plgd-dev/go-coap/v2/message"
 	"github.com/plgd-dev/go-coap/v2/message/codes"
 	"github.com/plgd-dev/go-coap/v2/mux"
@@ -29,15 +33,38 @@ import (
 const (
 	protocol  = "coap"
 	authQuery = "auth"
+	// discoveryPath is the well-known URI of the resource discovery, as
+	// defined by RFC 6690.
+	discoveryPath = ".well-known/core"
+	channelRT     = "mainflux.channel"
+)
+
+// SenML content formats, as registered by RFC 8428.
+const (
+	senmlJSON message.MediaType = 110
+	senmlCBOR message.MediaType = 112
 )
 
 var channelPartRegExp = regexp.MustCompile(`^channels/([\w\-]+)/messages(/[^?]*)?(\?.*)?$`)
 
+// contentTypes maps the CoAP content formats to the message content types.
+var contentTypes = map[message.MediaType]string{
+	message.AppJSON: "application/json",
+	message.AppCBOR: "application/cbor",
+	senmlJSON:       "application/senml+json",
+	senmlCBOR:       "application/senml+cbor",
+}
+
+// channelCT lists the content formats accepted by the channel resources.
+var channelCT = fmt.Sprintf("%d %d %d %d %d", message.TextPlain, message.AppJSON, message.AppCBOR, senmlJSON, senmlCBOR)
+
 var errMalformedSubtopic = errors.New("malformed subtopic")
 
 var (
-	logger  log.Logger
-	service coap.Service
+	logger    log.Logger
+	service   coap.Service
+	notifyCfg coap.NotifyConfig
+	contexts  *oscore.Contexts
 )
 
 //MakeHTTPHandler creates handler for version endpoint.
@@ -49,16 +76,27 @@ func MakeHTTPHandler() http.Handler {
 	return b
 }
 
-// MakeCoAPHandler creates handler for CoAP messages.
-func MakeCoAPHandler(svc coap.Service, l log.Logger) mux.HandlerFunc {
+// MakeCoAPHandler creates handler for CoAP messages, notifying the observers
+// as configured. The messages protected using OSCORE are handled if the
+// security contexts are provided.
+func MakeCoAPHandler(svc coap.Service, cfg coap.NotifyConfig, ctxs *oscore.Contexts, l log.Logger) mux.HandlerFunc {
 	logger = l
 	service = svc
+	notifyCfg = cfg
+	contexts = ctxs
 
 	return handler
 }
 
+// sendResp sets the response of the request, instead of writing it to the
+// client, so that the large response bodies are sent block-wise, as defined
+// by RFC 7959, when the client requests the following blocks.
 func sendResp(w mux.ResponseWriter, resp *message.Message) {
-	if err := w.Client().WriteMessage(resp); err != nil {
+	cf, err := resp.Options.ContentFormat()
+	if err != nil {
+		cf = message.TextPlain
+	}
+	if err := w.SetResponse(resp.Code, cf, resp.Body, resp.Options...); err != nil {
 		logger.Warn(fmt.Sprintf("Can't set response: %s", err))
 	}
 }
@@ -66,16 +104,67 @@ func sendResp(w mux.ResponseWriter, resp *message.Message) {
 func handler(w mux.ResponseWriter, m *mux.Message) {
 	resp := message.Message{
 		Code:    codes.Content,
-		Token:   m.Token,
-		Context: m.Context,
 		Options: make(message.Options, 0, 16),
 	}
+	if contexts != nil && m.Options.HasOption(oscore.Option) {
+		handleOSCORE(w, m, &resp)
+		return
+	}
 	defer sendResp(w, &resp)
+	serve(w.Client(), m, &resp)
+}
+
+// handleOSCORE serves the inner request of the OSCORE protected request, as
+// defined by RFC 8613, and protects the response, along with the observe
+// notifications. The errors of the OSCORE processing are sent unprotected.
+func handleOSCORE(w mux.ResponseWriter, m *mux.Message, resp *message.Message) {
+	inner, ex, err := contexts.Unprotect(m.Message)
+	switch {
+	case err == nil:
+		serve(oscore.NewClient(w.Client(), ex), &mux.Message{
+			Message:        inner,
+			SequenceNumber: m.SequenceNumber,
+			IsConfirmable:  m.IsConfirmable,
+		}, resp)
+	case errors.Contains(err, oscore.ErrFreshness):
+		// The client repeats the request along with the Echo option.
+		resp.Code = codes.Unauthorized
+	default:
+		logger.Warn(fmt.Sprintf("Error unprotecting message: %s", err))
+		switch {
+		case errors.Contains(err, oscore.ErrOption):
+			resp.Code = codes.BadOption
+		case errors.Contains(err, oscore.ErrDecrypt):
+			resp.Code = codes.BadRequest
+		case errors.Contains(err, oscore.ErrContextNotFound),
+			errors.Contains(err, oscore.ErrReplay):
+			resp.Code = codes.Unauthorized
+		default:
+			resp.Code = codes.InternalServerError
+		}
+		sendResp(w, resp)
+		return
+	}
+
+	out, err := ex.Protect(resp)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Error protecting message: %s", err))
+		sendResp(w, &message.Message{Code: codes.InternalServerError})
+		return
+	}
+	sendResp(w, out)
+}
+
+func serve(mc mux.Client, m *mux.Message, resp *message.Message) {
 	if m.Options == nil {
 		logger.Warn("Nil options")
 		resp.Code = codes.BadOption
 		return
 	}
+	if path, err := m.Options.Path(); err == nil && path == discoveryPath {
+		discover(m, resp)
+		return
+	}
 	msg, err := decodeMessage(m)
 	if err != nil {
 		logger.Warn(fmt.Sprintf("Error decoding message: %s", err))
@@ -98,8 +187,11 @@ func handler(w mux.ResponseWriter, m *mux.Message) {
 			return
 		}
 		if obs == 0 {
-			c := coap.NewClient(w.Client(), m.Token, logger)
+			c := coap.NewClient(mc, m.Token, notifyCfg, logger)
 			err = service.Subscribe(context.Background(), key, msg.Channel, msg.Subtopic, c)
+			if err == nil {
+				setObserve(resp)
+			}
 			break
 		}
 		service.Unsubscribe(context.Background(), key, msg.Channel, msg.Subtopic, m.Token.String())
@@ -116,10 +208,82 @@ func handler(w mux.ResponseWriter, m *mux.Message) {
 			return
 		case errors.Contains(err, coap.ErrUnsubscribe):
 			resp.Code = codes.InternalServerError
+		case errors.Contains(err, senml.ErrValidation):
+			logger.Warn(fmt.Sprintf("Invalid SenML message: %s", err))
+			resp.Code = codes.BadRequest
+			if ve, ok := err.(*senml.ValidationError); ok {
+				setValidationBody(resp, ve)
+			}
 		}
 	}
 }
 
+// discover responds with the channel resources accessible to the thing in the
+// CoRE Link Format, as defined by RFC 6690, so the clients don't need to know
+// the channel URIs in advance.
+func discover(m *mux.Message, resp *message.Message) {
+	if m.Code != codes.GET {
+		resp.Code = codes.MethodNotAllowed
+		return
+	}
+	key, err := parseKey(m)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Error parsing auth: %s", err))
+		resp.Code = codes.Unauthorized
+		return
+	}
+	ids, err := service.Discover(context.Background(), key)
+	if err != nil {
+		resp.Code = codes.Unauthorized
+		return
+	}
+
+	links := make([]string, len(ids))
+	for i, id := range ids {
+		links[i] = fmt.Sprintf(`</channels/%s/messages>;rt="%s";obs;ct="%s"`, id, channelRT, channelCT)
+	}
+
+	opts, _, err := resp.Options.SetContentFormat(make([]byte, 4), message.AppLinkFormat)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Can't set content format: %s", err))
+		resp.Code = codes.InternalServerError
+		return
+	}
+	resp.Options = opts
+	resp.Body = strings.NewReader(strings.Join(links, ","))
+}
+
+// setObserve sets the Observe option of the registration response, telling
+// the client that it was added to the observers, as defined by RFC 7641. The
+// notifications follow with the increasing sequence numbers.
+func setObserve(resp *message.Message) {
+	opts, _, err := resp.Options.SetObserve(make([]byte, 4), 0)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Can't set observe option: %s", err))
+		return
+	}
+	resp.Options = opts
+}
+
+// setValidationBody sets the invalid records as the JSON response body, so
+// the device can fix them.
+func setValidationBody(resp *message.Message, ve *senml.ValidationError) {
+	body, err := json.Marshal(ve)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Can't encode validation errors: %s", err))
+		return
+	}
+
+	buff := make([]byte, 4)
+	opts, _, err := resp.Options.SetContentFormat(buff, message.AppJSON)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Can't set content format: %s", err))
+		return
+	}
+	resp.Options = opts
+	resp.Body = bytes.NewReader(body)
+}
+
 func decodeMessage(msg *mux.Message) (messaging.Message, error) {
 	path, err := msg.Options.Path()
 	if err != nil {
@@ -142,6 +306,10 @@ func decodeMessage(msg *mux.Message) (messaging.Message, error) {
 		Created:  time.Now().UnixNano(),
 	}
 
+	if cf, err := msg.Options.ContentFormat(); err == nil {
+		ret.ContentType = contentTypes[cf]
+	}
+
 	if msg.Body != nil {
 		buff, err := ioutil.ReadAll(msg.Body)
 		if err != nil {
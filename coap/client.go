@@ -6,6 +6,8 @@ package coap
 import (
 	"bytes"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/errors"
@@ -26,20 +28,45 @@ type Client interface {
 
 type observers map[string]Observer
 
+// maxObserveSeq is the largest notification sequence number, which is a
+// 24-bit number as defined by RFC 7641.
+const maxObserveSeq = 1<<24 - 1
+
+// NotifyConfig represents the parameters of the observe notifications sent
+// to the clients.
+type NotifyConfig struct {
+	// Interval is the minimal interval between the notifications sent to a
+	// client. The messages received in between are conflated, so that only
+	// the latest one is sent once the interval elapses. Zero interval
+	// disables throttling.
+	Interval time.Duration
+
+	// MaxAge is the freshness of the notifications, sent as their Max-Age
+	// option. Zero max age omits the option, in which case the clients use
+	// the default of 60 seconds.
+	MaxAge time.Duration
+}
+
 // ErrOption indicates an error when adding an option.
 var ErrOption = errors.New("unable to set option")
 
 type client struct {
-	client mux.Client
-	token  message.Token
-	logger logger.Logger
+	client  mux.Client
+	token   message.Token
+	cfg     NotifyConfig
+	logger  logger.Logger
+	mu      sync.Mutex
+	seq     uint32
+	sent    time.Time
+	pending *messaging.Message
 }
 
-// NewClient instantiates a new Observer.
-func NewClient(mc mux.Client, token message.Token, l logger.Logger) Client {
+// NewClient instantiates a new Observer, notifying it as configured.
+func NewClient(mc mux.Client, token message.Token, cfg NotifyConfig, l logger.Logger) Client {
 	return &client{
 		client: mc,
 		token:  token,
+		cfg:    cfg,
 		logger: l,
 	}
 }
@@ -57,25 +84,78 @@ func (c *client) Token() string {
 }
 
 func (c *client) SendMessage(msg messaging.Message) error {
-	m := message.Message{
-		Code:    codes.Content,
-		Token:   c.token,
-		Context: c.client.Context(),
-		Body:    bytes.NewReader(msg.Payload),
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if wait := c.cfg.Interval - time.Since(c.sent); wait > 0 {
+		// Only the first message received within the interval schedules
+		// the notification, the following ones replace it.
+		if c.pending == nil {
+			time.AfterFunc(wait, c.flush)
+		}
+		c.pending = &msg
+		return nil
+	}
+
+	return c.send(msg)
+}
+
+// flush sends the latest message received within the interval.
+func (c *client) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	msg := c.pending
+	c.pending = nil
+	if msg == nil {
+		return
+	}
+
+	select {
+	case <-c.Done():
+		return
+	default:
+	}
+
+	// There is no error handling, but the error is logged by send.
+	c.send(*msg)
+}
+
+// send sends the message as the notification, with the increasing sequence
+// number, the ETag of the payload and the Max-Age of the notification.
+func (c *client) send(msg messaging.Message) error {
+	body := bytes.NewReader(msg.Payload)
+	etag, err := message.GetETag(body)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("Can't calculate ETag: %s.", err))
+		return errors.Wrap(ErrOption, err)
 	}
-	var opts message.Options
-	var buff []byte
 
-	opts, n, err := opts.SetContentFormat(buff, message.TextPlain)
-	if err == message.ErrTooSmall {
-		buff = append(buff, make([]byte, n)...)
-		opts, n, err = opts.SetContentFormat(buff, message.TextPlain)
+	c.seq = (c.seq + 1) & maxObserveSeq
+
+	// Each option is encoded to its own buffer, since it references it.
+	opts := make(message.Options, 0, 4)
+	opts, _, err = opts.SetContentFormat(make([]byte, 4), message.TextPlain)
+	if err == nil {
+		opts, _, err = opts.SetObserve(make([]byte, 4), c.seq)
+	}
+	if err == nil && c.cfg.MaxAge > 0 {
+		opts, _, err = opts.SetUint32(make([]byte, 4), message.MaxAge, uint32(c.cfg.MaxAge/time.Second))
 	}
 	if err != nil {
-		c.logger.Error(fmt.Sprintf("Can't set content format: %s.", err))
+		c.logger.Error(fmt.Sprintf("Can't set options: %s.", err))
 		return errors.Wrap(ErrOption, err)
 	}
-	m.Options = opts
+	opts = opts.Set(message.Option{ID: message.ETag, Value: etag})
+
+	m := message.Message{
+		Code:    codes.Content,
+		Token:   c.token,
+		Context: c.client.Context(),
+		Options: opts,
+		Body:    body,
+	}
+	c.sent = time.Now()
 	if err := c.client.WriteMessage(&m); err != nil {
 		c.logger.Error(fmt.Sprintf("Error sending message: %s.", err))
 		return err
@@ -0,0 +1,35 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package oscore
+
+import (
+	"github.com/plgd-dev/go-coap/v2/message"
+	"github.com/plgd-dev/go-coap/v2/mux"
+)
+
+var _ mux.Client = (*client)(nil)
+
+type client struct {
+	mux.Client
+	ex *Exchange
+}
+
+// NewClient wraps the CoAP client, so that the messages written to the client,
+// such as the observe notifications, are protected as the responses to the
+// exchange.
+func NewClient(mc mux.Client, ex *Exchange) mux.Client {
+	return &client{
+		Client: mc,
+		ex:     ex,
+	}
+}
+
+func (c *client) WriteMessage(req *message.Message) error {
+	msg, err := c.ex.Protect(req)
+	if err != nil {
+		return err
+	}
+
+	return c.Client.WriteMessage(msg)
+}
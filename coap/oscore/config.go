@@ -0,0 +1,62 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package oscore
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/pelletier/go-toml"
+)
+
+var errReadConfig = errors.New("failed to read security contexts configuration")
+
+type contextConf struct {
+	MasterSecret string `toml:"master_secret"`
+	MasterSalt   string `toml:"master_salt"`
+	SenderID     string `toml:"sender_id"`
+	RecipientID  string `toml:"recipient_id"`
+	IDContext    string `toml:"id_context"`
+}
+
+type fileConf struct {
+	Contexts []contextConf `toml:"contexts"`
+}
+
+// ReadConfig reads the security contexts configuration from the TOML file,
+// where the parameters are hex encoded.
+func ReadConfig(file string) ([]Config, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(errReadConfig, err)
+	}
+
+	var fc fileConf
+	if err := toml.Unmarshal(data, &fc); err != nil {
+		return nil, errors.Wrap(errReadConfig, err)
+	}
+
+	cfgs := make([]Config, len(fc.Contexts))
+	for i, cc := range fc.Contexts {
+		var cfg Config
+		for _, f := range []struct {
+			dst *[]byte
+			val string
+		}{
+			{&cfg.MasterSecret, cc.MasterSecret},
+			{&cfg.MasterSalt, cc.MasterSalt},
+			{&cfg.SenderID, cc.SenderID},
+			{&cfg.RecipientID, cc.RecipientID},
+			{&cfg.IDContext, cc.IDContext},
+		} {
+			if *f.dst, err = hex.DecodeString(f.val); err != nil {
+				return nil, errors.Wrap(ErrInvalidConfig, err)
+			}
+		}
+		cfgs[i] = cfg
+	}
+
+	return cfgs, nil
+}
@@ -0,0 +1,220 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package oscore implements the server side of the Object Security for
+// Constrained RESTful Environments (OSCORE), as defined by RFC 8613. OSCORE
+// protects the CoAP messages end-to-end, so that the messages stay protected
+// when forwarded by the proxies, unlike with DTLS which only protects the
+// transport between the hops.
+package oscore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"io"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/pion/dtls/v2/pkg/crypto/ccm"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// algAEAD is the COSE identifier of the AES-CCM-16-64-128 algorithm,
+	// which is the mandatory to implement OSCORE AEAD algorithm.
+	algAEAD  = 10
+	keyLen   = 16
+	nonceLen = 13
+	tagLen   = 8
+
+	// maxIDLen is the maximal length of the sender and recipient IDs, which
+	// are a part of the nonce.
+	maxIDLen = nonceLen - 6
+	// maxSeq is the largest sequence number, encoded to the 5 bytes long
+	// Partial IV.
+	maxSeq = 1<<40 - 1
+	// replayWindow is the number of the sequence numbers, lower than the
+	// highest received one, that are accepted once.
+	replayWindow = 32
+	// seqReserve is the number of the sender sequence numbers reserved with
+	// each write of the state, as described in Appendix B.1.1 of RFC 8613.
+	seqReserve = 256
+)
+
+var (
+	// ErrInvalidConfig indicates an invalid security context configuration.
+	ErrInvalidConfig = errors.New("invalid security context configuration")
+
+	// ErrSeqExhausted indicates that the sender sequence numbers of the
+	// security context are exhausted, and the context must be renewed.
+	ErrSeqExhausted = errors.New("sender sequence numbers exhausted")
+
+	errDerive = errors.New("failed to derive security context")
+)
+
+// Config represents the parameters of the security context shared with a
+// client. The sender is the adapter, and the recipient is the client.
+type Config struct {
+	MasterSecret []byte
+	MasterSalt   []byte
+	SenderID     []byte
+	RecipientID  []byte
+	IDContext    []byte
+}
+
+func (cfg Config) validate() error {
+	if len(cfg.MasterSecret) == 0 ||
+		len(cfg.SenderID) > maxIDLen ||
+		len(cfg.RecipientID) > maxIDLen ||
+		string(cfg.SenderID) == string(cfg.RecipientID) {
+		return ErrInvalidConfig
+	}
+
+	return nil
+}
+
+// Context is the security context shared with a client, as defined by
+// section 3 of RFC 8613.
+type Context struct {
+	senderID    []byte
+	recipientID []byte
+	idContext   []byte
+	commonIV    []byte
+	sender      cipher.AEAD
+	recipient   cipher.AEAD
+	save        func(reserved uint64) error
+
+	mu       sync.Mutex
+	seq      uint64
+	reserved uint64
+	synced   bool
+	highest  uint64
+	window   uint32
+	echo     []byte
+}
+
+// newContext derives the security context from the configuration, starting
+// the sender sequence numbers from seq. The sequence numbers are reserved
+// using save before they are used.
+func newContext(cfg Config, seq uint64, save func(uint64) error) (*Context, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	senderKey, err := derive(cfg, cfg.SenderID, "Key", keyLen)
+	if err != nil {
+		return nil, err
+	}
+	recipientKey, err := derive(cfg, cfg.RecipientID, "Key", keyLen)
+	if err != nil {
+		return nil, err
+	}
+	commonIV, err := derive(cfg, []byte{}, "IV", nonceLen)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := newAEAD(senderKey)
+	if err != nil {
+		return nil, errors.Wrap(errDerive, err)
+	}
+	recipient, err := newAEAD(recipientKey)
+	if err != nil {
+		return nil, errors.Wrap(errDerive, err)
+	}
+
+	return &Context{
+		senderID:    cfg.SenderID,
+		recipientID: cfg.RecipientID,
+		idContext:   cfg.IDContext,
+		commonIV:    commonIV,
+		sender:      sender,
+		recipient:   recipient,
+		save:        save,
+		seq:         seq,
+		reserved:    seq,
+	}, nil
+}
+
+// derive derives the key or the common IV using HKDF SHA-256, as defined by
+// section 3.2.1 of RFC 8613.
+func derive(cfg Config, id []byte, typ string, l int) ([]byte, error) {
+	var idContext interface{}
+	if len(cfg.IDContext) > 0 {
+		idContext = cfg.IDContext
+	}
+	info, err := cbor.Marshal([]interface{}{append([]byte{}, id...), idContext, algAEAD, typ, l})
+	if err != nil {
+		return nil, errors.Wrap(errDerive, err)
+	}
+
+	out := make([]byte, l)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, cfg.MasterSecret, cfg.MasterSalt, info), out); err != nil {
+		return nil, errors.Wrap(errDerive, err)
+	}
+
+	return out, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return ccm.NewCCM(block, tagLen, nonceLen)
+}
+
+// nextSeq returns the next sender sequence number, reserving the following
+// ones when the reserved ones are used up, so that the nonces are not reused
+// after the restart.
+func (c *Context) nextSeq() (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.seq > maxSeq {
+		return 0, ErrSeqExhausted
+	}
+	if c.seq >= c.reserved {
+		reserved := c.seq + seqReserve
+		if err := c.save(reserved); err != nil {
+			return 0, err
+		}
+		c.reserved = reserved
+	}
+
+	seq := c.seq
+	c.seq++
+	return seq, nil
+}
+
+// replayed reports whether the request with the sequence number was already
+// received or is too old to tell. The caller must hold the lock.
+func (c *Context) replayed(seq uint64) bool {
+	if seq > c.highest {
+		return false
+	}
+
+	diff := c.highest - seq
+	return diff >= replayWindow || c.window&(1<<diff) != 0
+}
+
+// accept marks the sequence number as received. The caller must hold the
+// lock.
+func (c *Context) accept(seq uint64) {
+	if seq > c.highest {
+		diff := seq - c.highest
+		if diff >= replayWindow {
+			c.window = 0
+		} else {
+			c.window <<= diff
+		}
+		c.window |= 1
+		c.highest = seq
+		return
+	}
+
+	c.window |= 1 << (c.highest - seq)
+}
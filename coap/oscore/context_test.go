@@ -0,0 +1,299 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package oscore
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test vectors are defined by Appendix C of RFC 8613.
+var (
+	masterSecret = unhex("0102030405060708090a0b0c0d0e0f10")
+	masterSalt   = unhex("9e7ca92223786340")
+	idContext    = unhex("37cbf3210017a2d3")
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestDerive(t *testing.T) {
+	cases := []struct {
+		desc         string
+		cfg          Config
+		senderKey    string
+		recipientKey string
+		commonIV     string
+	}{
+		{
+			desc: "derive client context with master salt",
+			cfg: Config{
+				MasterSecret: masterSecret,
+				MasterSalt:   masterSalt,
+				SenderID:     []byte{},
+				RecipientID:  []byte{0x01},
+			},
+			senderKey:    "f0910ed7295e6ad4b54fc793154302ff",
+			recipientKey: "ffb14e093c94c9cac9471648b4f98710",
+			commonIV:     "4622d4dd6d944168eefb54987c",
+		},
+		{
+			desc: "derive server context with master salt",
+			cfg: Config{
+				MasterSecret: masterSecret,
+				MasterSalt:   masterSalt,
+				SenderID:     []byte{0x01},
+				RecipientID:  []byte{},
+			},
+			senderKey:    "ffb14e093c94c9cac9471648b4f98710",
+			recipientKey: "f0910ed7295e6ad4b54fc793154302ff",
+			commonIV:     "4622d4dd6d944168eefb54987c",
+		},
+		{
+			desc: "derive client context without master salt",
+			cfg: Config{
+				MasterSecret: masterSecret,
+				SenderID:     []byte{0x00},
+				RecipientID:  []byte{0x01},
+			},
+			senderKey:    "321b26943253c7ffb6003b0b64d74041",
+			recipientKey: "e57b5635815177cd679ab4bcec9d7dda",
+			commonIV:     "be35ae297d2dace910c52e99f9",
+		},
+		{
+			desc: "derive server context without master salt",
+			cfg: Config{
+				MasterSecret: masterSecret,
+				SenderID:     []byte{0x01},
+				RecipientID:  []byte{0x00},
+			},
+			senderKey:    "e57b5635815177cd679ab4bcec9d7dda",
+			recipientKey: "321b26943253c7ffb6003b0b64d74041",
+			commonIV:     "be35ae297d2dace910c52e99f9",
+		},
+		{
+			desc: "derive client context with ID context",
+			cfg: Config{
+				MasterSecret: masterSecret,
+				MasterSalt:   masterSalt,
+				SenderID:     []byte{},
+				RecipientID:  []byte{0x01},
+				IDContext:    idContext,
+			},
+			senderKey:    "af2a1300a5e95788b356336eeecd2b92",
+			recipientKey: "e39a0c7c77b43f03b4b39ab9a268699f",
+			commonIV:     "2ca58fb85ff1b81c0b7181b85e",
+		},
+	}
+
+	for _, tc := range cases {
+		senderKey, err := derive(tc.cfg, tc.cfg.SenderID, "Key", keyLen)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.senderKey, hex.EncodeToString(senderKey), fmt.Sprintf("%s: expected sender key %s got %x\n", tc.desc, tc.senderKey, senderKey))
+
+		recipientKey, err := derive(tc.cfg, tc.cfg.RecipientID, "Key", keyLen)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.recipientKey, hex.EncodeToString(recipientKey), fmt.Sprintf("%s: expected recipient key %s got %x\n", tc.desc, tc.recipientKey, recipientKey))
+
+		commonIV, err := derive(tc.cfg, []byte{}, "IV", nonceLen)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.commonIV, hex.EncodeToString(commonIV), fmt.Sprintf("%s: expected common IV %s got %x\n", tc.desc, tc.commonIV, commonIV))
+	}
+}
+
+func TestNonce(t *testing.T) {
+	cases := []struct {
+		desc     string
+		commonIV string
+		id       []byte
+		piv      []byte
+		nonce    string
+	}{
+		{
+			desc:     "nonce for empty ID",
+			commonIV: "4622d4dd6d944168eefb54987c",
+			id:       []byte{},
+			piv:      []byte{0x00},
+			nonce:    "4622d4dd6d944168eefb54987c",
+		},
+		{
+			desc:     "nonce for one byte ID",
+			commonIV: "4622d4dd6d944168eefb54987c",
+			id:       []byte{0x01},
+			piv:      []byte{0x00},
+			nonce:    "4722d4dd6d944169eefb54987c",
+		},
+		{
+			desc:     "nonce for zero ID",
+			commonIV: "be35ae297d2dace910c52e99f9",
+			id:       []byte{0x00},
+			piv:      []byte{0x00},
+			nonce:    "bf35ae297d2dace910c52e99f9",
+		},
+		{
+			desc:     "nonce for zero ID of the recipient",
+			commonIV: "be35ae297d2dace910c52e99f9",
+			id:       []byte{0x01},
+			piv:      []byte{0x00},
+			nonce:    "bf35ae297d2dace810c52e99f9",
+		},
+		{
+			desc:     "nonce for Partial IV of the request",
+			commonIV: "4622d4dd6d944168eefb54987c",
+			id:       []byte{},
+			piv:      []byte{0x14},
+			nonce:    "4622d4dd6d944168eefb549868",
+		},
+		{
+			desc:     "nonce for Partial IV of the request with zero ID",
+			commonIV: "be35ae297d2dace910c52e99f9",
+			id:       []byte{0x00},
+			piv:      []byte{0x14},
+			nonce:    "bf35ae297d2dace910c52e99ed",
+		},
+		{
+			desc:     "nonce for Partial IV of the request with ID context",
+			commonIV: "2ca58fb85ff1b81c0b7181b85e",
+			id:       []byte{},
+			piv:      []byte{0x14},
+			nonce:    "2ca58fb85ff1b81c0b7181b84a",
+		},
+	}
+
+	for _, tc := range cases {
+		n := nonce(unhex(tc.commonIV), tc.id, tc.piv)
+		assert.Equal(t, tc.nonce, hex.EncodeToString(n), fmt.Sprintf("%s: expected %s got %x\n", tc.desc, tc.nonce, n))
+	}
+}
+
+func TestReplayWindow(t *testing.T) {
+	cases := []struct {
+		desc     string
+		accepted []uint64
+		seq      uint64
+		replayed bool
+	}{
+		{
+			desc:     "check the first sequence number",
+			accepted: []uint64{0},
+			seq:      1,
+			replayed: false,
+		},
+		{
+			desc:     "check the duplicate of the highest sequence number",
+			accepted: []uint64{0, 5},
+			seq:      5,
+			replayed: true,
+		},
+		{
+			desc:     "check the duplicate of the lower sequence number",
+			accepted: []uint64{0, 3, 5},
+			seq:      3,
+			replayed: true,
+		},
+		{
+			desc:     "check the skipped sequence number",
+			accepted: []uint64{0, 5},
+			seq:      3,
+			replayed: false,
+		},
+		{
+			desc:     "check the lowest sequence number in the window",
+			accepted: []uint64{0, replayWindow},
+			seq:      1,
+			replayed: false,
+		},
+		{
+			desc:     "check the sequence number out of the window",
+			accepted: []uint64{0, replayWindow},
+			seq:      0,
+			replayed: true,
+		},
+		{
+			desc:     "check the sequence number out of the window after the jump",
+			accepted: []uint64{0, 100},
+			seq:      50,
+			replayed: true,
+		},
+		{
+			desc:     "check the skipped sequence number after the jump",
+			accepted: []uint64{0, 100},
+			seq:      100 - replayWindow + 1,
+			replayed: false,
+		},
+		{
+			desc:     "check the duplicate shifted to the window edge",
+			accepted: []uint64{0, 1, replayWindow},
+			seq:      1,
+			replayed: true,
+		},
+		{
+			desc:     "check the duplicate shifted out of the window",
+			accepted: []uint64{0, 1, replayWindow + 1},
+			seq:      1,
+			replayed: true,
+		},
+		{
+			desc:     "check the sequence number reused after the jump",
+			accepted: []uint64{0, 1, 2, 2 + replayWindow},
+			seq:      2 + replayWindow - 1,
+			replayed: false,
+		},
+		{
+			desc:     "check the largest sequence number",
+			accepted: []uint64{0, maxSeq - 1},
+			seq:      maxSeq,
+			replayed: false,
+		},
+		{
+			desc:     "check the duplicate of the largest sequence number",
+			accepted: []uint64{0, maxSeq},
+			seq:      maxSeq,
+			replayed: true,
+		},
+	}
+
+	for _, tc := range cases {
+		c := Context{}
+		for _, seq := range tc.accepted {
+			require.False(t, c.replayed(seq), fmt.Sprintf("%s: unexpected replay of %d\n", tc.desc, seq))
+			c.accept(seq)
+		}
+		replayed := c.replayed(tc.seq)
+		assert.Equal(t, tc.replayed, replayed, fmt.Sprintf("%s: expected replayed %t got %t\n", tc.desc, tc.replayed, replayed))
+	}
+}
+
+func TestNextSeq(t *testing.T) {
+	saved := []uint64{}
+	save := func(reserved uint64) error {
+		saved = append(saved, reserved)
+		return nil
+	}
+
+	c := Context{save: save}
+	for i := uint64(0); i <= seqReserve; i++ {
+		seq, err := c.nextSeq()
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+		require.Equal(t, i, seq, fmt.Sprintf("expected sequence number %d got %d\n", i, seq))
+	}
+	assert.Equal(t, []uint64{seqReserve, 2 * seqReserve}, saved, fmt.Sprintf("expected reserved %v got %v\n", []uint64{seqReserve, 2 * seqReserve}, saved))
+
+	// Sequence numbers don't wrap around, since the nonce would be reused.
+	c = Context{save: save, seq: maxSeq, reserved: maxSeq + 1}
+	seq, err := c.nextSeq()
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	assert.Equal(t, uint64(maxSeq), seq, fmt.Sprintf("expected sequence number %d got %d\n", uint64(maxSeq), seq))
+	_, err = c.nextSeq()
+	assert.Equal(t, ErrSeqExhausted, err, fmt.Sprintf("expected %s got %s\n", ErrSeqExhausted, err))
+}
@@ -0,0 +1,327 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package oscore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/plgd-dev/go-coap/v2/message"
+	"github.com/plgd-dev/go-coap/v2/message/codes"
+)
+
+const echoLen = 8
+
+var (
+	// ErrContextNotFound indicates that there is no security context for
+	// the kid of the request.
+	ErrContextNotFound = errors.New("security context not found")
+
+	// ErrReplay indicates that the request was already received.
+	ErrReplay = errors.New("replay detected")
+
+	// ErrDecrypt indicates that the request can't be decrypted or verified.
+	ErrDecrypt = errors.New("decryption failed")
+
+	// ErrFreshness indicates that the freshness of the request can't be
+	// verified, since it's the first request received after the restart. The
+	// request is rejected, and the client is challenged to repeat it along
+	// with the Echo option of the response.
+	ErrFreshness = errors.New("request freshness not verified")
+
+	errState = errors.New("failed to save security context state")
+)
+
+// Contexts holds the security contexts shared with the clients, along with
+// the state file where their sender sequence numbers are persisted.
+type Contexts struct {
+	state string
+	mu    sync.Mutex
+	seqs  map[string]uint64
+	byKID map[string][]*Context
+}
+
+// NewContexts derives the security contexts from the configurations. The
+// sender sequence numbers used before the restart are loaded from the state
+// file, which is created if it doesn't exist.
+func NewContexts(cfgs []Config, state string) (*Contexts, error) {
+	cs := &Contexts{
+		state: state,
+		seqs:  make(map[string]uint64),
+		byKID: make(map[string][]*Context),
+	}
+
+	data, err := ioutil.ReadFile(state)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, errors.Wrap(errState, err)
+	default:
+		if err := json.Unmarshal(data, &cs.seqs); err != nil {
+			return nil, errors.Wrap(errState, err)
+		}
+	}
+
+	for _, cfg := range cfgs {
+		key := stateKey(cfg)
+		ctx, err := newContext(cfg, cs.seqs[key], func(reserved uint64) error {
+			return cs.save(key, reserved)
+		})
+		if err != nil {
+			return nil, err
+		}
+		kid := string(cfg.RecipientID)
+		cs.byKID[kid] = append(cs.byKID[kid], ctx)
+	}
+
+	return cs, nil
+}
+
+// stateKey identifies the sender key in the state file, without revealing
+// the master secret.
+func stateKey(cfg Config) string {
+	return fmt.Sprintf("%s:%s:%s", hex.EncodeToString(cfg.IDContext), hex.EncodeToString(cfg.SenderID), hex.EncodeToString(cfg.RecipientID))
+}
+
+func (cs *Contexts) save(key string, reserved uint64) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.seqs[key] = reserved
+	data, err := json.Marshal(cs.seqs)
+	if err != nil {
+		return errors.Wrap(errState, err)
+	}
+
+	// The state is written to the temporary file first, so that it's never
+	// left partially written.
+	tmp := cs.state + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrap(errState, err)
+	}
+	if err := os.Rename(tmp, cs.state); err != nil {
+		return errors.Wrap(errState, err)
+	}
+
+	return nil
+}
+
+func (cs *Contexts) find(o oscoreOption) (*Context, error) {
+	var found *Context
+	for _, ctx := range cs.byKID[string(o.kid)] {
+		if o.kidContext != nil && !bytes.Equal(o.kidContext, ctx.idContext) {
+			continue
+		}
+		// Without the kid context, the kid must identify a single context.
+		if found != nil {
+			return nil, ErrContextNotFound
+		}
+		found = ctx
+	}
+	if found == nil {
+		return nil, ErrContextNotFound
+	}
+
+	return found, nil
+}
+
+// Unprotect verifies and decrypts the protected request, as defined by
+// section 8.2 of RFC 8613, returning the inner request and the exchange used
+// to protect its responses. In case of ErrFreshness, the returned exchange is
+// used to protect the response challenging the client.
+func (cs *Contexts) Unprotect(req *message.Message) (*message.Message, *Exchange, error) {
+	val, err := req.Options.GetBytes(Option)
+	if err != nil {
+		return nil, nil, errors.Wrap(ErrOption, err)
+	}
+	o, err := decodeOption(val)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The requests carry the Partial IV and the kid.
+	if len(o.piv) == 0 || !o.hasKID {
+		return nil, nil, ErrOption
+	}
+
+	ctx, err := cs.find(o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ct []byte
+	if req.Body != nil {
+		if _, err := req.Body.Seek(0, 0); err != nil {
+			return nil, nil, errors.Wrap(ErrDecrypt, err)
+		}
+		if ct, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, nil, errors.Wrap(ErrDecrypt, err)
+		}
+	}
+
+	ad, err := aad(o.kid, o.piv)
+	if err != nil {
+		return nil, nil, errors.Wrap(ErrDecrypt, err)
+	}
+	n := nonce(ctx.commonIV, ctx.recipientID, o.piv)
+	pt, err := ctx.recipient.Open(nil, n, ct, ad)
+	if err != nil {
+		return nil, nil, errors.Wrap(ErrDecrypt, err)
+	}
+
+	code, opts, payload, err := decodePlaintext(pt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ex := &Exchange{
+		ctx:   ctx,
+		kid:   o.kid,
+		piv:   o.piv,
+		nonce: n,
+	}
+	if err := ex.verify(decodePIV(o.piv), opts); err != nil {
+		return nil, ex, err
+	}
+
+	inner := &message.Message{
+		Context: req.Context,
+		Token:   req.Token,
+		Code:    code,
+		Options: opts,
+	}
+	if payload != nil {
+		inner.Body = bytes.NewReader(payload)
+	}
+
+	return inner, ex, nil
+}
+
+// Exchange represents a protected request, used to protect its responses.
+type Exchange struct {
+	ctx   *Context
+	kid   []byte
+	piv   []byte
+	nonce []byte
+
+	mu    sync.Mutex
+	fresh bool
+	used  bool
+	echo  []byte
+}
+
+// verify checks that the request wasn't received before. The first request
+// received after the restart is accepted only if it repeats the Echo value
+// sent to the client, as described in Appendix B.1.2 of RFC 8613.
+func (ex *Exchange) verify(seq uint64, opts message.Options) error {
+	ctx := ex.ctx
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	if !ctx.synced {
+		echo, err := opts.GetBytes(Echo)
+		if err != nil || ctx.echo == nil || !bytes.Equal(echo, ctx.echo) {
+			ctx.echo = make([]byte, echoLen)
+			if _, err := rand.Read(ctx.echo); err != nil {
+				return err
+			}
+			ex.echo = ctx.echo
+			return ErrFreshness
+		}
+		ctx.synced = true
+		ctx.echo = nil
+		ctx.highest = seq
+		ctx.window = 1
+		ex.fresh = true
+		return nil
+	}
+
+	if ctx.replayed(seq) {
+		return ErrReplay
+	}
+	ctx.accept(seq)
+	ex.fresh = true
+	return nil
+}
+
+// Protect encrypts the response, as defined by section 8.3 of RFC 8613. The
+// first response to the fresh request reuses the nonce of the request, while
+// the following ones, such as the observe notifications, use the sender
+// sequence numbers of the adapter.
+func (ex *Exchange) Protect(resp *message.Message) (*message.Message, error) {
+	var payload []byte
+	if resp.Body != nil {
+		if _, err := resp.Body.Seek(0, 0); err != nil {
+			return nil, err
+		}
+		var err error
+		if payload, err = ioutil.ReadAll(resp.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := resp.Options
+	if ex.echo != nil {
+		opts = append(message.Options{}, opts...).Set(message.Option{ID: Echo, Value: ex.echo})
+	}
+	pt, err := encodePlaintext(resp.Code, opts, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var o oscoreOption
+	n := ex.responseNonce()
+	if n == nil {
+		seq, err := ex.ctx.nextSeq()
+		if err != nil {
+			return nil, err
+		}
+		o.piv = encodePIV(seq)
+		n = nonce(ex.ctx.commonIV, ex.ctx.senderID, o.piv)
+	}
+
+	ad, err := aad(ex.kid, ex.piv)
+	if err != nil {
+		return nil, err
+	}
+	ct := ex.ctx.sender.Seal(nil, n, pt, ad)
+
+	// The observe notifications are sent as the 2.05 Content responses, so
+	// that the proxies keep forwarding them, as defined by section 4.2 of RFC
+	// 8613.
+	code := codes.Changed
+	outer := make(message.Options, 0, 2)
+	if obs, err := resp.Options.GetBytes(message.Observe); err == nil {
+		code = codes.Content
+		outer = outer.Set(message.Option{ID: message.Observe, Value: obs})
+	}
+	outer = outer.Set(message.Option{ID: Option, Value: o.encode()})
+
+	return &message.Message{
+		Context: resp.Context,
+		Token:   resp.Token,
+		Code:    code,
+		Options: outer,
+		Body:    bytes.NewReader(ct),
+	}, nil
+}
+
+// responseNonce returns the nonce of the request, unless it's already used
+// or the request freshness wasn't verified.
+func (ex *Exchange) responseNonce() []byte {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+
+	if !ex.fresh || ex.used {
+		return nil
+	}
+	ex.used = true
+	return ex.nonce
+}
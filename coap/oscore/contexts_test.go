@@ -0,0 +1,495 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package oscore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/plgd-dev/go-coap/v2/message"
+	"github.com/plgd-dev/go-coap/v2/message/codes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// Server contexts of Appendix C.1.2, C.2.2 and C.3.2 of RFC 8613.
+	saltCfg = Config{
+		MasterSecret: masterSecret,
+		MasterSalt:   masterSalt,
+		SenderID:     []byte{0x01},
+		RecipientID:  []byte{},
+	}
+	noSaltCfg = Config{
+		MasterSecret: masterSecret,
+		SenderID:     []byte{0x01},
+		RecipientID:  []byte{0x00},
+	}
+	idContextCfg = Config{
+		MasterSecret: masterSecret,
+		MasterSalt:   masterSalt,
+		SenderID:     []byte{0x01},
+		RecipientID:  []byte{},
+		IDContext:    idContext,
+	}
+
+	// tv1 are the options of the request for coap://localhost/tv1.
+	tv1 = message.Options{
+		{ID: message.URIHost, Value: []byte("localhost")},
+		{ID: message.URIPath, Value: []byte("tv1")},
+	}
+	helloWorld = []byte("Hello World!")
+)
+
+func newContexts(t *testing.T, cfgs ...Config) *Contexts {
+	dir, err := ioutil.TempDir("", "oscore")
+	require.Nil(t, err, fmt.Sprintf("unexpected temp dir creation error: %s\n", err))
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	cs, err := NewContexts(cfgs, filepath.Join(dir, "state.json"))
+	require.Nil(t, err, fmt.Sprintf("unexpected contexts creation error: %s\n", err))
+	return cs
+}
+
+// synchronize marks the contexts as synchronized, as if the freshness of the
+// requests was already verified.
+func synchronize(cs *Contexts) {
+	for _, ctxs := range cs.byKID {
+		for _, ctx := range ctxs {
+			ctx.synced = true
+		}
+	}
+}
+
+// newClient returns the client side of the server context.
+func newClient(t *testing.T, cfg Config) *Context {
+	cfg.SenderID, cfg.RecipientID = cfg.RecipientID, cfg.SenderID
+	ctx, err := newContext(cfg, 0, func(uint64) error { return nil })
+	require.Nil(t, err, fmt.Sprintf("unexpected context creation error: %s\n", err))
+	return ctx
+}
+
+// protectRequest protects the request as the client, as defined by section
+// 8.1 of RFC 8613.
+func protectRequest(ctx *Context, seq uint64, code codes.Code, opts message.Options, payload []byte) (*message.Message, error) {
+	pt, err := encodePlaintext(code, opts, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	o := oscoreOption{
+		piv:    encodePIV(seq),
+		kid:    ctx.senderID,
+		hasKID: true,
+	}
+	if len(ctx.idContext) > 0 {
+		o.kidContext = ctx.idContext
+	}
+	ad, err := aad(o.kid, o.piv)
+	if err != nil {
+		return nil, err
+	}
+	ct := ctx.sender.Seal(nil, nonce(ctx.commonIV, ctx.senderID, o.piv), pt, ad)
+
+	outer := message.Options{}
+	for _, opt := range opts {
+		if outerOptions[opt.ID] {
+			outer = outer.Add(opt)
+		}
+	}
+	outer = outer.Set(message.Option{ID: Option, Value: o.encode()})
+
+	return &message.Message{
+		Token:   message.Token{0x00, 0x00, 0x39, 0x74},
+		Code:    codes.POST,
+		Options: outer,
+		Body:    bytes.NewReader(ct),
+	}, nil
+}
+
+// unprotectResponse verifies and decrypts the response as the client, as
+// defined by section 8.4 of RFC 8613.
+func unprotectResponse(ctx *Context, req, resp *message.Message) (codes.Code, message.Options, []byte, error) {
+	val, err := req.Options.GetBytes(Option)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	ro, err := decodeOption(val)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	val, err = resp.Options.GetBytes(Option)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	o, err := decodeOption(val)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	n := nonce(ctx.commonIV, ctx.senderID, ro.piv)
+	if len(o.piv) > 0 {
+		n = nonce(ctx.commonIV, ctx.recipientID, o.piv)
+	}
+	ad, err := aad(ro.kid, ro.piv)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	ct, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	pt, err := ctx.recipient.Open(nil, n, ct, ad)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	return decodePlaintext(pt)
+}
+
+func body(t *testing.T, msg *message.Message) []byte {
+	if msg.Body == nil {
+		return nil
+	}
+	_, err := msg.Body.Seek(0, 0)
+	require.Nil(t, err, fmt.Sprintf("unexpected body seek error: %s\n", err))
+	b, err := ioutil.ReadAll(msg.Body)
+	require.Nil(t, err, fmt.Sprintf("unexpected body read error: %s\n", err))
+	return b
+}
+
+func TestProtectRequest(t *testing.T) {
+	cases := []struct {
+		desc       string
+		cfg        Config
+		option     string
+		ciphertext string
+	}{
+		{
+			desc:       "protect request with master salt",
+			cfg:        saltCfg,
+			option:     "0914",
+			ciphertext: "612f1092f1776f1c1668b3825e",
+		},
+		{
+			desc:       "protect request without master salt",
+			cfg:        noSaltCfg,
+			option:     "091400",
+			ciphertext: "4ed339a5a379b0b8bc731fffb0",
+		},
+		{
+			desc:       "protect request with ID context",
+			cfg:        idContextCfg,
+			option:     "19140837cbf3210017a2d3",
+			ciphertext: "72cd7273fd331ac45cffbe55c3",
+		},
+	}
+
+	for _, tc := range cases {
+		req, err := protectRequest(newClient(t, tc.cfg), 20, codes.GET, tv1, nil)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+
+		opt, err := req.Options.GetBytes(Option)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.option, hex.EncodeToString(opt), fmt.Sprintf("%s: expected option %s got %x\n", tc.desc, tc.option, opt))
+		ct := body(t, req)
+		assert.Equal(t, tc.ciphertext, hex.EncodeToString(ct), fmt.Sprintf("%s: expected ciphertext %s got %x\n", tc.desc, tc.ciphertext, ct))
+	}
+}
+
+func TestUnprotect(t *testing.T) {
+	cs := newContexts(t, noSaltCfg, idContextCfg)
+	synchronize(cs)
+
+	request := func(option, ciphertext string) *message.Message {
+		return &message.Message{
+			Code: codes.POST,
+			Options: message.Options{
+				{ID: message.URIHost, Value: []byte("localhost")},
+				{ID: Option, Value: unhex(option)},
+			},
+			Body: bytes.NewReader(unhex(ciphertext)),
+		}
+	}
+
+	cases := []struct {
+		desc string
+		req  *message.Message
+		err  error
+	}{
+		{
+			desc: "unprotect request without master salt",
+			req:  request("091400", "4ed339a5a379b0b8bc731fffb0"),
+			err:  nil,
+		},
+		{
+			desc: "unprotect request with ID context",
+			req:  request("19140837cbf3210017a2d3", "72cd7273fd331ac45cffbe55c3"),
+			err:  nil,
+		},
+		{
+			desc: "unprotect replayed request",
+			req:  request("091400", "4ed339a5a379b0b8bc731fffb0"),
+			err:  ErrReplay,
+		},
+		{
+			desc: "unprotect request with modified ciphertext",
+			req:  request("091500", "4ed339a5a379b0b8bc731fffb1"),
+			err:  ErrDecrypt,
+		},
+		{
+			desc: "unprotect request with modified Partial IV",
+			req:  request("091600", "4ed339a5a379b0b8bc731fffb0"),
+			err:  ErrDecrypt,
+		},
+		{
+			desc: "unprotect request with unknown kid",
+			req:  request("091402", "4ed339a5a379b0b8bc731fffb0"),
+			err:  ErrContextNotFound,
+		},
+		{
+			desc: "unprotect request with unknown kid context",
+			req:  request("19140837cbf3210017a2d4", "72cd7273fd331ac45cffbe55c3"),
+			err:  ErrContextNotFound,
+		},
+		{
+			desc: "unprotect request without Partial IV",
+			req:  request("0800", "4ed339a5a379b0b8bc731fffb0"),
+			err:  ErrOption,
+		},
+		{
+			desc: "unprotect request with reserved flags",
+			req:  request("891400", "4ed339a5a379b0b8bc731fffb0"),
+			err:  ErrOption,
+		},
+	}
+
+	for _, tc := range cases {
+		inner, _, err := cs.Unprotect(tc.req)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+		assert.Equal(t, codes.GET, inner.Code, fmt.Sprintf("%s: expected code %s got %s\n", tc.desc, codes.GET, inner.Code))
+		path, err := inner.Options.Path()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, "tv1", path, fmt.Sprintf("%s: expected path %s got %s\n", tc.desc, "tv1", path))
+		assert.Nil(t, inner.Body, fmt.Sprintf("%s: expected empty body\n", tc.desc))
+	}
+}
+
+func TestProtectResponse(t *testing.T) {
+	cs := newContexts(t, saltCfg)
+	synchronize(cs)
+
+	req := &message.Message{
+		Code: codes.POST,
+		Options: message.Options{
+			{ID: message.URIHost, Value: []byte("localhost")},
+			{ID: Option, Value: unhex("0914")},
+		},
+		Body: bytes.NewReader(unhex("612f1092f1776f1c1668b3825e")),
+	}
+	_, ex, err := cs.Unprotect(req)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	// The first response reuses the nonce of the request, while the following
+	// ones use the sender sequence numbers.
+	cases := []struct {
+		desc       string
+		option     string
+		ciphertext string
+	}{
+		{
+			desc:       "protect response without Partial IV",
+			option:     "",
+			ciphertext: "dbaad1e9a7e7b2a813d3c31524378303cdafae119106",
+		},
+		{
+			desc:       "protect response with Partial IV",
+			option:     "0100",
+			ciphertext: "4d4c13669384b67354b2b6175ff4b8658c666a6cf88e",
+		},
+	}
+
+	for _, tc := range cases {
+		resp, err := ex.Protect(&message.Message{
+			Code: codes.Content,
+			Body: bytes.NewReader(helloWorld),
+		})
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+
+		assert.Equal(t, codes.Changed, resp.Code, fmt.Sprintf("%s: expected code %s got %s\n", tc.desc, codes.Changed, resp.Code))
+		opt, err := resp.Options.GetBytes(Option)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.option, hex.EncodeToString(opt), fmt.Sprintf("%s: expected option %s got %x\n", tc.desc, tc.option, opt))
+		ct := body(t, resp)
+		assert.Equal(t, tc.ciphertext, hex.EncodeToString(ct), fmt.Sprintf("%s: expected ciphertext %s got %x\n", tc.desc, tc.ciphertext, ct))
+	}
+}
+
+func TestProtectUnprotect(t *testing.T) {
+	cases := []struct {
+		desc     string
+		cfg      Config
+		seq      uint64
+		code     codes.Code
+		opts     message.Options
+		payload  []byte
+		respCode codes.Code
+		respOpts message.Options
+		respBody []byte
+	}{
+		{
+			desc:     "exchange GET request with master salt",
+			cfg:      saltCfg,
+			seq:      1,
+			code:     codes.GET,
+			opts:     tv1,
+			respCode: codes.Content,
+			respBody: helloWorld,
+		},
+		{
+			desc:     "exchange POST request without master salt",
+			cfg:      noSaltCfg,
+			seq:      0x0100,
+			code:     codes.POST,
+			opts:     append(append(message.Options{}, tv1...), message.Option{ID: message.ContentFormat, Value: []byte{byte(message.AppJSON)}}),
+			payload:  []byte(`[{"bn":"tv1","v":1}]`),
+			respCode: codes.Changed,
+		},
+		{
+			desc:     "exchange observe request with ID context",
+			cfg:      idContextCfg,
+			seq:      maxSeq,
+			code:     codes.GET,
+			opts:     append(append(message.Options{}, tv1...), message.Option{ID: message.Observe, Value: []byte{}}),
+			respCode: codes.Content,
+			respOpts: message.Options{{ID: message.Observe, Value: []byte{0x02}}},
+			respBody: helloWorld,
+		},
+	}
+
+	for _, tc := range cases {
+		cs := newContexts(t, tc.cfg)
+		synchronize(cs)
+		client := newClient(t, tc.cfg)
+		req, err := protectRequest(client, tc.seq, tc.code, tc.opts, tc.payload)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+
+		inner, ex, err := cs.Unprotect(req)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.code, inner.Code, fmt.Sprintf("%s: expected code %s got %s\n", tc.desc, tc.code, inner.Code))
+		path, err := inner.Options.Path()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, "tv1", path, fmt.Sprintf("%s: expected path %s got %s\n", tc.desc, "tv1", path))
+		assert.False(t, inner.Options.HasOption(message.URIHost), fmt.Sprintf("%s: unexpected outer option in inner request\n", tc.desc))
+		if tc.payload != nil {
+			assert.Equal(t, tc.payload, body(t, inner), fmt.Sprintf("%s: expected payload %s got %s\n", tc.desc, tc.payload, body(t, inner)))
+		}
+
+		// The observe notifications are protected using the server sequence
+		// numbers, so the responses are sent twice.
+		for i := 0; i < 2; i++ {
+			resp := &message.Message{
+				Token:   req.Token,
+				Code:    tc.respCode,
+				Options: tc.respOpts,
+			}
+			if tc.respBody != nil {
+				resp.Body = bytes.NewReader(tc.respBody)
+			}
+			protected, err := ex.Protect(resp)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+
+			code, opts, payload, err := unprotectResponse(client, req, protected)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+			assert.Equal(t, tc.respCode, code, fmt.Sprintf("%s: expected code %s got %s\n", tc.desc, tc.respCode, code))
+			assert.Equal(t, tc.respBody, payload, fmt.Sprintf("%s: expected payload %s got %s\n", tc.desc, tc.respBody, payload))
+			assert.False(t, opts.HasOption(message.Observe), fmt.Sprintf("%s: unexpected outer option in inner response\n", tc.desc))
+			assert.Equal(t, tc.respOpts.HasOption(message.Observe), protected.Options.HasOption(message.Observe), fmt.Sprintf("%s: expected outer observe option\n", tc.desc))
+		}
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	cs := newContexts(t, saltCfg)
+	client := newClient(t, saltCfg)
+
+	req, err := protectRequest(client, 20, codes.GET, tv1, nil)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	_, ex, err := cs.Unprotect(req)
+	assert.True(t, errors.Contains(err, ErrFreshness), fmt.Sprintf("unprotect first request: expected %s got %s\n", ErrFreshness, err))
+	require.NotNil(t, ex, "unprotect first request: expected exchange")
+
+	resp, err := ex.Protect(&message.Message{Code: codes.Unauthorized})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	_, opts, _, err := unprotectResponse(client, req, resp)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	echo, err := opts.GetBytes(Echo)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	cases := []struct {
+		desc  string
+		seq   uint64
+		echo  bool
+		wrong bool
+		err   error
+	}{
+		{
+			desc:  "repeat request with wrong Echo option",
+			seq:   21,
+			echo:  true,
+			wrong: true,
+			err:   ErrFreshness,
+		},
+		{
+			desc: "repeat request with Echo option",
+			seq:  22,
+			echo: true,
+			err:  nil,
+		},
+		{
+			desc: "send following request",
+			seq:  23,
+			err:  nil,
+		},
+		{
+			desc: "replay request",
+			seq:  22,
+			err:  ErrReplay,
+		},
+	}
+
+	for _, tc := range cases {
+		opts := tv1
+		if tc.echo {
+			val := echo
+			if tc.wrong {
+				val = []byte("echo")
+			}
+			opts = append(append(message.Options{}, tv1...), message.Option{ID: Echo, Value: val})
+		}
+		req, err := protectRequest(client, tc.seq, codes.GET, opts, nil)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		_, ex, err := cs.Unprotect(req)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+
+		// The new Echo value is sent with each challenge.
+		if tc.err == ErrFreshness {
+			resp, err := ex.Protect(&message.Message{Code: codes.Unauthorized})
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+			_, opts, _, err := unprotectResponse(client, req, resp)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+			echo, err = opts.GetBytes(Echo)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		}
+	}
+}
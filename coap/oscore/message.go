@@ -0,0 +1,220 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package oscore
+
+import (
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/plgd-dev/go-coap/v2/message"
+	"github.com/plgd-dev/go-coap/v2/message/codes"
+)
+
+const (
+	// Option is the number of the OSCORE option.
+	Option message.OptionID = 9
+	// Echo is the number of the Echo option, defined by RFC 9175, used to
+	// verify the freshness of the requests.
+	Echo message.OptionID = 252
+
+	// FETCH is the code of the FETCH method, defined by RFC 8132, which is
+	// the outer code of the protected observe requests.
+	FETCH codes.Code = 5
+
+	oscoreVersion = 1
+	payloadMarker = 0xff
+	maxOptions    = 32
+
+	flagKID        = 0x08
+	flagKIDContext = 0x10
+	flagsReserved  = 0xe0
+	maxPIVLen      = 5
+)
+
+// ErrOption indicates a malformed OSCORE option or plaintext.
+var ErrOption = errors.New("malformed OSCORE message")
+
+// outerOptions are the options that are not encrypted, since they are used
+// by the proxies, or are sent as the outer options only, as defined by section
+// 4.1 of RFC 8613.
+var outerOptions = map[message.OptionID]bool{
+	message.URIHost:     true,
+	message.Observe:     true,
+	message.URIPort:     true,
+	Option:              true,
+	message.Block1:      true,
+	message.Block2:      true,
+	message.Size1:       true,
+	message.Size2:       true,
+	message.ProxyURI:    true,
+	message.ProxyScheme: true,
+}
+
+// oscoreOption represents the value of the OSCORE option, as defined by
+// section 6.1 of RFC 8613.
+type oscoreOption struct {
+	piv        []byte
+	kid        []byte
+	kidContext []byte
+	hasKID     bool
+}
+
+func (o oscoreOption) encode() []byte {
+	if len(o.piv) == 0 && !o.hasKID && o.kidContext == nil {
+		return []byte{}
+	}
+
+	flags := byte(len(o.piv))
+	if o.hasKID {
+		flags |= flagKID
+	}
+	if o.kidContext != nil {
+		flags |= flagKIDContext
+	}
+
+	ret := append([]byte{flags}, o.piv...)
+	if o.kidContext != nil {
+		ret = append(ret, byte(len(o.kidContext)))
+		ret = append(ret, o.kidContext...)
+	}
+
+	return append(ret, o.kid...)
+}
+
+func decodeOption(val []byte) (oscoreOption, error) {
+	var o oscoreOption
+	if len(val) == 0 {
+		return o, nil
+	}
+
+	flags := val[0]
+	n := int(flags & 0x07)
+	if flags&flagsReserved != 0 || n > maxPIVLen {
+		return o, ErrOption
+	}
+	val = val[1:]
+	if len(val) < n {
+		return o, ErrOption
+	}
+	o.piv, val = val[:n], val[n:]
+
+	if flags&flagKIDContext != 0 {
+		if len(val) < 1 || len(val) < int(val[0])+1 {
+			return o, ErrOption
+		}
+		s := int(val[0])
+		o.kidContext, val = val[1:s+1], val[s+1:]
+	}
+
+	if flags&flagKID != 0 {
+		o.kid, o.hasKID = val, true
+		return o, nil
+	}
+	if len(val) > 0 {
+		return o, ErrOption
+	}
+
+	return o, nil
+}
+
+// encodePIV encodes the sequence number as the shortest Partial IV.
+func encodePIV(seq uint64) []byte {
+	piv := []byte{}
+	for ; seq > 0; seq >>= 8 {
+		piv = append([]byte{byte(seq)}, piv...)
+	}
+	if len(piv) == 0 {
+		piv = []byte{0}
+	}
+
+	return piv
+}
+
+func decodePIV(piv []byte) uint64 {
+	var seq uint64
+	for _, b := range piv {
+		seq = seq<<8 | uint64(b)
+	}
+
+	return seq
+}
+
+// nonce composes the AEAD nonce from the Partial IV and the ID of its sender,
+// as defined by section 5.2 of RFC 8613.
+func nonce(commonIV, id, piv []byte) []byte {
+	n := make([]byte, nonceLen)
+	n[0] = byte(len(id))
+	copy(n[1+maxIDLen-len(id):1+maxIDLen], id)
+	copy(n[nonceLen-len(piv):], piv)
+	for i := range n {
+		n[i] ^= commonIV[i]
+	}
+
+	return n
+}
+
+// aad composes the additional authenticated data from the kid and the
+// Partial IV of the request, as defined by section 5.4 of RFC 8613.
+func aad(kid, piv []byte) ([]byte, error) {
+	external, err := cbor.Marshal([]interface{}{
+		oscoreVersion,
+		[]interface{}{algAEAD},
+		append([]byte{}, kid...),
+		append([]byte{}, piv...),
+		[]byte{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cbor.Marshal([]interface{}{"Encrypt0", []byte{}, external})
+}
+
+// encodePlaintext encodes the code, the inner options and the payload of the
+// message, as defined by section 5.3 of RFC 8613.
+func encodePlaintext(code codes.Code, opts message.Options, payload []byte) ([]byte, error) {
+	inner := make(message.Options, 0, len(opts))
+	for _, o := range opts {
+		if !outerOptions[o.ID] {
+			inner = append(inner, o)
+		}
+	}
+
+	n, err := inner.Marshal(nil)
+	if err != nil && err != message.ErrTooSmall {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if n > 0 {
+		if _, err := inner.Marshal(buf); err != nil {
+			return nil, err
+		}
+	}
+
+	ret := append([]byte{byte(code)}, buf...)
+	if len(payload) > 0 {
+		ret = append(ret, payloadMarker)
+		ret = append(ret, payload...)
+	}
+
+	return ret, nil
+}
+
+func decodePlaintext(pt []byte) (codes.Code, message.Options, []byte, error) {
+	if len(pt) == 0 {
+		return 0, nil, nil, ErrOption
+	}
+
+	opts := make(message.Options, 0, maxOptions)
+	n, err := opts.Unmarshal(pt[1:], message.CoapOptionDefs)
+	if err != nil {
+		return 0, nil, nil, errors.Wrap(ErrOption, err)
+	}
+
+	var payload []byte
+	if rest := pt[1+n:]; len(rest) > 0 {
+		payload = rest
+	}
+
+	return codes.Code(pt[0]), opts, payload, nil
+}
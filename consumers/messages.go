@@ -6,6 +6,7 @@ package consumers
 import (
 	"fmt"
 	"io/ioutil"
+	"sync"
 
 	"github.com/pelletier/go-toml"
 
@@ -21,20 +22,88 @@ var (
 	errParseConfFile = errors.New("unable to parse configuration file")
 )
 
+// Subjects keeps the subscriptions in line with the subjects configuration.
+type Subjects interface {
+	// Reload reads the subjects configuration file again, subscribes to the
+	// added subjects and unsubscribes from the removed ones. If the file
+	// can't be read, the subscriptions are left unchanged.
+	Reload() error
+}
+
+type subjects struct {
+	mu         sync.Mutex
+	sub        messaging.Subscriber
+	handler    messaging.MessageHandler
+	path       string
+	subscribed map[string]bool
+	logger     logger.Logger
+}
+
 // Start method starts consuming messages received from NATS.
 // This method transforms messages to SenML format before
 // using MessageRepository to store them.
 func Start(sub messaging.Subscriber, consumer Consumer, transformer transformers.Transformer, subjectsCfgPath string, logger logger.Logger) error {
-	subjects, err := loadSubjectsConfig(subjectsCfgPath)
+	_, err := StartReloadable(sub, consumer, transformer, subjectsCfgPath, logger)
+	return err
+}
+
+// StartReloadable starts consuming messages the same way as Start does and
+// returns the subjects, which can be reloaded without restarting the consumer.
+func StartReloadable(sub messaging.Subscriber, consumer Consumer, transformer transformers.Transformer, subjectsCfgPath string, logger logger.Logger) (Subjects, error) {
+	s := &subjects{
+		sub:        sub,
+		handler:    handler(transformer, consumer),
+		path:       subjectsCfgPath,
+		subscribed: make(map[string]bool),
+		logger:     logger,
+	}
+
+	subs, err := loadSubjectsConfig(subjectsCfgPath)
 	if err != nil {
 		logger.Warn(fmt.Sprintf("Failed to load subjects: %s", err))
 	}
 
+	return s, s.update(subs)
+}
+
+func (s *subjects) Reload() error {
+	subs, err := loadSubjectsConfig(s.path)
+	if err != nil {
+		return err
+	}
+	return s.update(subs)
+}
+
+// update subscribes to the new subjects before unsubscribing from the removed
+// ones, so that no messages are missed while the subjects are replaced.
+func (s *subjects) update(subjects []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keep := make(map[string]bool)
 	for _, subject := range subjects {
-		if err := sub.Subscribe(subject, handler(transformer, consumer)); err != nil {
+		keep[subject] = true
+		if s.subscribed[subject] {
+			continue
+		}
+		if err := s.sub.Subscribe(subject, s.handler); err != nil {
 			return err
 		}
+		s.subscribed[subject] = true
+		s.logger.Info(fmt.Sprintf("Subscribed to %s", subject))
 	}
+
+	for subject := range s.subscribed {
+		if keep[subject] {
+			continue
+		}
+		if err := s.sub.Unsubscribe(subject); err != nil {
+			return err
+		}
+		delete(s.subscribed, subject)
+		s.logger.Info(fmt.Sprintf("Unsubscribed from %s", subject))
+	}
+
 	return nil
 }
 
@@ -0,0 +1,100 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package consumers_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/mainflux/mainflux/consumers"
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var logger, _ = log.New(os.Stdout, log.Info.String())
+
+func TestReloadSubjects(t *testing.T) {
+	file, err := ioutil.TempFile("", "config.toml")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	defer os.Remove(file.Name())
+	writeSubjects(t, file.Name(), `["channels.a", "channels.b"]`)
+
+	sub := &subscriberMock{topics: make(map[string]bool)}
+	subjects, err := consumers.StartReloadable(sub, consumerMock{}, nil, file.Name(), logger)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, []string{"channels.a", "channels.b"}, sub.subscribed(), "expected initial subscriptions")
+
+	cases := []struct {
+		desc     string
+		config   string
+		topics   []string
+		reloaded bool
+	}{
+		{
+			desc:     "reload with added and removed subjects",
+			config:   `["channels.b", "channels.c"]`,
+			topics:   []string{"channels.b", "channels.c"},
+			reloaded: true,
+		},
+		{
+			desc:     "reload with unchanged subjects",
+			config:   `["channels.c", "channels.b"]`,
+			topics:   []string{"channels.b", "channels.c"},
+			reloaded: true,
+		},
+		{
+			desc:     "reload with invalid configuration",
+			config:   `"channels.>"`,
+			topics:   []string{"channels.b", "channels.c"},
+			reloaded: false,
+		},
+	}
+
+	for _, tc := range cases {
+		writeSubjects(t, file.Name(), tc.config)
+		err := subjects.Reload()
+		assert.Equal(t, tc.reloaded, err == nil, fmt.Sprintf("%s: unexpected reload error %s\n", tc.desc, err))
+		assert.Equal(t, tc.topics, sub.subscribed(), fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.topics, sub.subscribed()))
+	}
+}
+
+func writeSubjects(t *testing.T, path, filter string) {
+	cfg := fmt.Sprintf("[subjects]\nfilter = %s\n", filter)
+	err := ioutil.WriteFile(path, []byte(cfg), 0644)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+}
+
+type subscriberMock struct {
+	topics map[string]bool
+}
+
+func (sm *subscriberMock) Subscribe(topic string, handler messaging.MessageHandler) error {
+	sm.topics[topic] = true
+	return nil
+}
+
+func (sm *subscriberMock) Unsubscribe(topic string) error {
+	delete(sm.topics, topic)
+	return nil
+}
+
+func (sm *subscriberMock) subscribed() []string {
+	var topics []string
+	for topic := range sm.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+type consumerMock struct{}
+
+func (consumerMock) Consume(msgs interface{}) error {
+	return nil
+}
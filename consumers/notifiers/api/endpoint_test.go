@@ -66,9 +66,10 @@ func (tr testRequest) make() (*http.Response, error) {
 func newService(tokens map[string]string) notifiers.Service {
 	auth := mocks.NewAuth(tokens)
 	repo := mocks.NewRepo(make(map[string]notifiers.Subscription))
+	things := mocks.NewThings(map[string][]string{topic: {email}})
 	idp := uuid.NewMock()
 	notif := mocks.NewNotifier()
-	return notifiers.New(auth, repo, idp, notif)
+	return notifiers.New(auth, things, repo, idp, notif)
 }
 
 func newServer(svc notifiers.Service) *httptest.Server {
@@ -95,6 +96,8 @@ func TestCreate(t *testing.T) {
 
 	emptyTopic := toJSON(notifiers.Subscription{Contact: contact1})
 	emptyContact := toJSON(notifiers.Subscription{Topic: "topic123"})
+	otherChannel := toJSON(notifiers.Subscription{Topic: "topic123", Contact: contact1})
+	invalidContact := toJSON(notifiers.Subscription{Topic: topic, Contact: "invalid"})
 
 	cases := []struct {
 		desc        string
@@ -136,6 +139,22 @@ func TestCreate(t *testing.T) {
 			status:      http.StatusBadRequest,
 			location:    "",
 		},
+		{
+			desc:        "add to channel of another user",
+			req:         otherChannel,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusUnauthorized,
+			location:    "",
+		},
+		{
+			desc:        "add with invalid contact",
+			req:         invalidContact,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+			location:    "",
+		},
 		{
 			desc:        "add with invalid auth token",
 			req:         data,
@@ -139,6 +139,7 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 		switch {
 		case errors.Contains(errorVal, errors.ErrMalformedEntity),
 			errors.Contains(errorVal, errInvalidContact),
+			errors.Contains(errorVal, notifiers.ErrInvalidContact),
 			errors.Contains(errorVal, errInvalidTopic),
 			errors.Contains(errorVal, errors.ErrInvalidQueryParams):
 			w.WriteHeader(http.StatusBadRequest)
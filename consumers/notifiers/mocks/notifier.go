@@ -8,9 +8,15 @@ import (
 	"github.com/mainflux/mainflux/pkg/messaging"
 )
 
-var _ notifiers.Notifier = (*notifier)(nil)
+var (
+	_ notifiers.Notifier  = (*notifier)(nil)
+	_ notifiers.Validator = (*notifier)(nil)
+)
 
-const invalidSender = "invalid@example.com"
+const (
+	invalidSender  = "invalid@example.com"
+	invalidContact = "invalid"
+)
 
 type notifier struct{}
 
@@ -27,3 +33,10 @@ func (n notifier) Notify(from string, to []string, msg messaging.Message) error
 	}
 	return nil
 }
+
+func (n notifier) Validate(contact string) error {
+	if contact == invalidContact {
+		return notifiers.ErrInvalidContact
+	}
+	return nil
+}
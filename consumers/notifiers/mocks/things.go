@@ -0,0 +1,50 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/things"
+	"google.golang.org/grpc"
+)
+
+var _ mainflux.ThingsServiceClient = (*thingsServiceMock)(nil)
+
+type thingsServiceMock struct {
+	owners map[string][]string
+}
+
+// NewThings creates mock of things service, where the owners map the
+// channels to the emails of the users allowed to access them.
+func NewThings(owners map[string][]string) mainflux.ThingsServiceClient {
+	return &thingsServiceMock{owners}
+}
+
+func (svc thingsServiceMock) CanAccessByKey(ctx context.Context, in *mainflux.AccessByKeyReq, opts ...grpc.CallOption) (*mainflux.ThingID, error) {
+	panic("not implemented")
+}
+
+func (svc thingsServiceMock) CanAccessByID(ctx context.Context, in *mainflux.AccessByIDReq, opts ...grpc.CallOption) (*empty.Empty, error) {
+	panic("not implemented")
+}
+
+func (svc thingsServiceMock) IsChannelOwner(ctx context.Context, in *mainflux.ChannelOwnerReq, opts ...grpc.CallOption) (*empty.Empty, error) {
+	for _, owner := range svc.owners[in.GetChanID()] {
+		if owner == in.GetOwner() {
+			return &empty.Empty{}, nil
+		}
+	}
+	return nil, things.ErrUnauthorizedAccess
+}
+
+func (svc thingsServiceMock) Identify(ctx context.Context, in *mainflux.Token, opts ...grpc.CallOption) (*mainflux.ThingID, error) {
+	panic("not implemented")
+}
+
+func (svc thingsServiceMock) ChannelsByKey(ctx context.Context, in *mainflux.Token, opts ...grpc.CallOption) (*mainflux.ChannelIDs, error) {
+	panic("not implemented")
+}
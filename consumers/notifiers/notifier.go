@@ -18,3 +18,11 @@ type Notifier interface {
 	// received message to the provided list of receivers.
 	Notify(from string, to []string, msg messaging.Message) error
 }
+
+// Validator is implemented by the notifiers that restrict the contacts they
+// send the notifications to. The contacts are validated before the
+// subscriptions are saved.
+type Validator interface {
+	// Validate checks whether the notifications can be sent to the contact.
+	Validate(contact string) error
+}
@@ -6,6 +6,7 @@ package notifiers
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/consumers"
@@ -38,12 +39,16 @@ var (
 
 	// ErrMessage indicates an error converting a message to Mainflux message.
 	ErrMessage = errors.New("failed to convert to Mainflux message")
+
+	// ErrInvalidContact indicates the contact the notifier doesn't send the
+	// notifications to.
+	ErrInvalidContact = errors.New("invalid subscription contact")
 )
 
 // Service reprents a notification service.
 type Service interface {
-	// CreateSubscription persists a subscription.
-	// Successful operation is indicated by non-nil error response.
+	// CreateSubscription persists a subscription to the channel owned by the
+	// user. Successful operation is indicated by non-nil error response.
 	CreateSubscription(ctx context.Context, token string, sub Subscription) (string, error)
 
 	// ViewSubscription retrieves the subscription for the given user and id.
@@ -62,15 +67,17 @@ var _ Service = (*notifierService)(nil)
 
 type notifierService struct {
 	auth     mainflux.AuthServiceClient
+	things   mainflux.ThingsServiceClient
 	subs     SubscriptionsRepository
 	idp      mainflux.IDProvider
 	notifier Notifier
 }
 
 // New instantiates the subscriptions service implementation.
-func New(auth mainflux.AuthServiceClient, subs SubscriptionsRepository, idp mainflux.IDProvider, notifier Notifier) Service {
+func New(auth mainflux.AuthServiceClient, things mainflux.ThingsServiceClient, subs SubscriptionsRepository, idp mainflux.IDProvider, notifier Notifier) Service {
 	return &notifierService{
 		auth:     auth,
+		things:   things,
 		subs:     subs,
 		idp:      idp,
 		notifier: notifier,
@@ -82,6 +89,18 @@ func (ns *notifierService) CreateSubscription(ctx context.Context, token string,
 	if err != nil {
 		return "", errors.Wrap(ErrUnauthorizedAccess, err)
 	}
+
+	// The topic is the channel ID, optionally followed by the subtopic.
+	chanID := strings.SplitN(sub.Topic, ".", 2)[0]
+	if _, err := ns.things.IsChannelOwner(ctx, &mainflux.ChannelOwnerReq{Owner: res.GetEmail(), ChanID: chanID}); err != nil {
+		return "", errors.Wrap(ErrUnauthorizedAccess, err)
+	}
+	if v, ok := ns.notifier.(Validator); ok {
+		if err := v.Validate(sub.Contact); err != nil {
+			return "", errors.Wrap(ErrInvalidContact, err)
+		}
+	}
+
 	sub.ID, err = ns.idp.ID()
 	if err != nil {
 		return "", errors.Wrap(ErrCreateID, err)
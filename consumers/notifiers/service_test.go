@@ -22,14 +22,16 @@ const (
 	exampleUser1 = "email1@example.com"
 	exampleUser2 = "email2@example.com"
 	invalidUser  = "invalid@example.com"
+	chanID       = "valid"
 )
 
 func newService() notifiers.Service {
 	repo := mocks.NewRepo(make(map[string]notifiers.Subscription))
 	auth := mocks.NewAuth(map[string]string{exampleUser1: exampleUser1, exampleUser2: exampleUser2, invalidUser: invalidUser})
+	things := mocks.NewThings(map[string][]string{chanID: {exampleUser1}, "topic": {exampleUser1, exampleUser2}})
 	notifier := mocks.NewNotifier()
 	idp := uuid.NewMock()
-	return notifiers.New(auth, repo, idp, notifier)
+	return notifiers.New(auth, things, repo, idp, notifier)
 }
 
 func TestCreateSubscription(t *testing.T) {
@@ -63,6 +65,27 @@ func TestCreateSubscription(t *testing.T) {
 			id:    "",
 			err:   notifiers.ErrUnauthorizedAccess,
 		},
+		{
+			desc:  "test subscribing to channel of another user",
+			token: exampleUser2,
+			sub:   notifiers.Subscription{Contact: exampleUser2, Topic: "valid.topic"},
+			id:    "",
+			err:   notifiers.ErrUnauthorizedAccess,
+		},
+		{
+			desc:  "test subscribing to non-existing channel",
+			token: exampleUser1,
+			sub:   notifiers.Subscription{Contact: exampleUser1, Topic: "invalid.topic"},
+			id:    "",
+			err:   notifiers.ErrUnauthorizedAccess,
+		},
+		{
+			desc:  "test invalid contact",
+			token: exampleUser1,
+			sub:   notifiers.Subscription{Contact: "invalid", Topic: "valid"},
+			id:    "",
+			err:   notifiers.ErrInvalidContact,
+		},
 	}
 
 	for _, tc := range cases {
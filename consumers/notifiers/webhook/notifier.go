@@ -0,0 +1,288 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-kit/kit/metrics"
+	notifiers "github.com/mainflux/mainflux/consumers/notifiers"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+const (
+	// ChannelHeader carries the ID of the channel the message was sent to.
+	ChannelHeader = "X-Mainflux-Channel"
+	// SubtopicHeader carries the subtopic of the message, if any.
+	SubtopicHeader = "X-Mainflux-Subtopic"
+	// PublisherHeader carries the ID of the thing that sent the message.
+	PublisherHeader = "X-Mainflux-Publisher"
+	// ProtocolHeader carries the protocol the message was sent over.
+	ProtocolHeader = "X-Mainflux-Protocol"
+	// CreatedHeader carries the creation time of the message in nanoseconds.
+	CreatedHeader = "X-Mainflux-Created"
+	// TimestampHeader carries the signing time in Unix seconds.
+	TimestampHeader = "X-Mainflux-Timestamp"
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of the timestamp
+	// and the body, joined by a dot, prefixed with "sha256=".
+	SignatureHeader = "X-Mainflux-Signature"
+
+	signaturePrefix = "sha256="
+	failureError    = "error"
+)
+
+var (
+	errInvalidURL     = errors.New("invalid webhook URL")
+	errPrivateAddress = errors.New("webhook address is not public")
+	errStatus         = errors.New("webhook responded with unexpected status")
+)
+
+// privateNets are the networks, besides the loopback, link-local, multicast
+// and unspecified addresses, which are reachable only from the internal
+// networks.
+var privateNets = parseNets("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7")
+
+// Config represents the webhook delivery parameters.
+type Config struct {
+	// Secret is the key used to sign the requests. Empty secret disables
+	// signing.
+	Secret string
+	// Timeout is the timeout of a single request.
+	Timeout time.Duration
+	// RetryInterval is the initial interval of the exponential backoff.
+	RetryInterval time.Duration
+	// RetryMaxElapsed is the time after which the retries are given up. Zero
+	// disables retrying.
+	RetryMaxElapsed time.Duration
+	// AllowPrivate allows the webhooks on the loopback and private addresses.
+	AllowPrivate bool
+}
+
+var (
+	_ notifiers.Notifier  = (*notifier)(nil)
+	_ notifiers.Validator = (*notifier)(nil)
+)
+
+type notifier struct {
+	client   *http.Client
+	cfg      Config
+	failures metrics.Counter
+}
+
+// New instantiates webhook message notifier, which posts the message payload
+// to the subscribed URLs. The failed deliveries are counted once the retries
+// are exhausted, labeled by the last response status code. Unless private
+// addresses are allowed, the connections to them are refused, so that the
+// webhooks can't reach the internal services, even if their host names are
+// resolved to the private addresses after the subscription.
+func New(cfg Config, failures metrics.Counter) notifiers.Notifier {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !public(net.ParseIP(host)) {
+				return errors.Wrap(errPrivateAddress, fmt.Errorf("%s", host))
+			}
+			return nil
+		}
+	}
+
+	return &notifier{
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+		},
+		cfg:      cfg,
+		failures: failures,
+	}
+}
+
+// Validate checks that the contact is HTTP or HTTPS URL and, unless private
+// addresses are allowed, that its host is resolved to the public addresses
+// only.
+func (n *notifier) Validate(contact string) error {
+	u, err := parseURL(contact)
+	if err != nil {
+		return err
+	}
+	if n.cfg.AllowPrivate {
+		return nil
+	}
+
+	ips := []net.IP{net.ParseIP(u.Hostname())}
+	if ips[0] == nil {
+		if ips, err = net.LookupIP(u.Hostname()); err != nil {
+			return errors.Wrap(errInvalidURL, err)
+		}
+	}
+	for _, ip := range ips {
+		if !public(ip) {
+			return errors.Wrap(errPrivateAddress, fmt.Errorf("%s", ip))
+		}
+	}
+
+	return nil
+}
+
+// Notify delivers the message to all the URLs concurrently, so that a slow
+// or failing webhook doesn't delay the others. The first delivery error is
+// returned.
+func (n *notifier) Notify(_ string, to []string, msg messaging.Message) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(to))
+	for i, u := range to {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			errs[i] = n.deliver(u, msg)
+		}(i, u)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *notifier) deliver(u string, msg messaging.Message) error {
+	if _, err := parseURL(u); err != nil {
+		n.failures.With("code", failureError).Add(1)
+		return err
+	}
+
+	code := failureError
+	send := func() error {
+		req, err := n.request(u, msg)
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		res, err := n.client.Do(req)
+		if err != nil {
+			code = failureError
+			return err
+		}
+		res.Body.Close()
+
+		code = strconv.Itoa(res.StatusCode)
+		switch {
+		case res.StatusCode >= 200 && res.StatusCode < 300:
+			return nil
+		case res.StatusCode == http.StatusRequestTimeout,
+			res.StatusCode == http.StatusTooManyRequests,
+			res.StatusCode >= 500:
+			return errors.Wrap(errStatus, fmt.Errorf("%s: %s", u, res.Status))
+		default:
+			return backoff.Permanent(errors.Wrap(errStatus, fmt.Errorf("%s: %s", u, res.Status)))
+		}
+	}
+
+	var b backoff.BackOff = &backoff.StopBackOff{}
+	if n.cfg.RetryMaxElapsed > 0 {
+		eb := backoff.NewExponentialBackOff()
+		eb.InitialInterval = n.cfg.RetryInterval
+		eb.MaxElapsedTime = n.cfg.RetryMaxElapsed
+		b = eb
+	}
+	if err := backoff.Retry(send, b); err != nil {
+		n.failures.With("code", code).Add(1)
+		return err
+	}
+
+	return nil
+}
+
+// request creates the request carrying the message payload, along with its
+// content type and the message metadata as the headers. The request is
+// signed, so that the receiver can verify its origin.
+func (n *notifier) request(u string, msg messaging.Message) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(msg.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.ContentType != "" {
+		req.Header.Set("Content-Type", msg.ContentType)
+	}
+	req.Header.Set(ChannelHeader, msg.Channel)
+	if msg.Subtopic != "" {
+		req.Header.Set(SubtopicHeader, msg.Subtopic)
+	}
+	req.Header.Set(PublisherHeader, msg.Publisher)
+	req.Header.Set(ProtocolHeader, msg.Protocol)
+	req.Header.Set(CreatedHeader, strconv.FormatInt(msg.Created, 10))
+
+	if n.cfg.Secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, ts)
+		req.Header.Set(SignatureHeader, signaturePrefix+Sign(n.cfg.Secret, ts, msg.Payload))
+	}
+
+	return req, nil
+}
+
+func parseURL(u string) (*url.URL, error) {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.Wrap(errInvalidURL, fmt.Errorf("%q", u))
+	}
+	return parsed, nil
+}
+
+func public(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+		return false
+	}
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseNets(cidrs ...string) []*net.IPNet {
+	nets := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the timestamp and the body,
+// which the receivers compare to the signature header value, without its
+// prefix, to verify the request. Including the timestamp lets the receivers
+// reject the replayed requests.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,250 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package webhook_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	notifiers "github.com/mainflux/mainflux/consumers/notifiers"
+	"github.com/mainflux/mainflux/consumers/notifiers/webhook"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+)
+
+const secret = "secret"
+
+var msg = messaging.Message{
+	Channel:     "1",
+	Subtopic:    "room.temp",
+	Publisher:   "2",
+	Protocol:    "http",
+	ContentType: "application/senml+json",
+	Payload:     []byte(`[{"n":"temp","v":21.5}]`),
+	Created:     1,
+}
+
+// counterMock counts the failures per label values.
+type counterMock struct {
+	mu     *sync.Mutex
+	lvs    string
+	counts map[string]float64
+}
+
+func newCounterMock() *counterMock {
+	return &counterMock{
+		mu:     &sync.Mutex{},
+		counts: make(map[string]float64),
+	}
+}
+
+func (c *counterMock) With(labelValues ...string) metrics.Counter {
+	return &counterMock{
+		mu:     c.mu,
+		lvs:    strings.Join(labelValues, ","),
+		counts: c.counts,
+	}
+}
+
+func (c *counterMock) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[c.lvs] += delta
+}
+
+func (c *counterMock) value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[strings.Join(labelValues, ",")]
+}
+
+func TestNotify(t *testing.T) {
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts[r.URL.Path]++
+		n := attempts[r.URL.Path]
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/unavailable":
+			if n == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/invalid":
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		case "/failing":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		sig := strings.TrimPrefix(r.Header.Get(webhook.SignatureHeader), "sha256=")
+		if sig != webhook.Sign(secret, r.Header.Get(webhook.TimestampHeader), body) ||
+			string(body) != string(msg.Payload) ||
+			r.Header.Get("Content-Type") != msg.ContentType ||
+			r.Header.Get(webhook.ChannelHeader) != msg.Channel ||
+			r.Header.Get(webhook.SubtopicHeader) != msg.Subtopic ||
+			r.Header.Get(webhook.PublisherHeader) != msg.Publisher {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	cfg := webhook.Config{
+		Secret:          secret,
+		Timeout:         time.Second,
+		RetryInterval:   time.Millisecond,
+		RetryMaxElapsed: 50 * time.Millisecond,
+		AllowPrivate:    true,
+	}
+
+	cases := map[string]struct {
+		path     string
+		err      bool
+		attempts int
+		failure  string
+	}{
+		"deliver message": {
+			path:     "/valid",
+			err:      false,
+			attempts: 1,
+		},
+		"deliver message after retry": {
+			path:     "/unavailable",
+			err:      false,
+			attempts: 2,
+		},
+		"deliver message rejected by webhook": {
+			path:     "/invalid",
+			err:      true,
+			attempts: 1,
+			failure:  "400",
+		},
+		"deliver message to failing webhook": {
+			path:    "/failing",
+			err:     true,
+			failure: "500",
+		},
+	}
+
+	for desc, tc := range cases {
+		failures := newCounterMock()
+		n := webhook.New(cfg, failures)
+		err := n.Notify("", []string{ts.URL + tc.path}, msg)
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s", desc, tc.err, err))
+
+		mu.Lock()
+		got := attempts[tc.path]
+		mu.Unlock()
+		if tc.attempts > 0 {
+			assert.Equal(t, tc.attempts, got, fmt.Sprintf("%s: expected %d attempts got %d", desc, tc.attempts, got))
+		}
+		if tc.failure != "" {
+			assert.Equal(t, float64(1), failures.value("code", tc.failure), fmt.Sprintf("%s: expected failure to be counted", desc))
+		}
+	}
+}
+
+func TestNotifyInvalidURL(t *testing.T) {
+	failures := newCounterMock()
+	n := webhook.New(webhook.Config{Timeout: time.Second}, failures)
+
+	err := n.Notify("", []string{"ftp://example.com"}, msg)
+	assert.NotNil(t, err, "expected error for invalid URL")
+	assert.Equal(t, float64(1), failures.value("code", "error"), "expected failure to be counted")
+}
+
+func TestNotifyPrivateAddress(t *testing.T) {
+	delivered := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	failures := newCounterMock()
+	n := webhook.New(webhook.Config{Timeout: time.Second}, failures)
+
+	err := n.Notify("", []string{ts.URL}, msg)
+	assert.NotNil(t, err, "expected error for loopback address")
+	assert.False(t, delivered, "expected message not to be delivered to loopback address")
+	assert.Equal(t, float64(1), failures.value("code", "error"), "expected failure to be counted")
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		url          string
+		allowPrivate bool
+		err          bool
+	}{
+		"validate public address": {
+			url: "https://93.184.216.34/hook",
+			err: false,
+		},
+		"validate public address with port": {
+			url: "http://93.184.216.34:8080/hook",
+			err: false,
+		},
+		"validate non-HTTP URL": {
+			url: "ftp://93.184.216.34/hook",
+			err: true,
+		},
+		"validate URL without host": {
+			url: "https:///hook",
+			err: true,
+		},
+		"validate loopback address": {
+			url: "http://127.0.0.1:8080/hook",
+			err: true,
+		},
+		"validate loopback host name": {
+			url: "http://localhost:8080/hook",
+			err: true,
+		},
+		"validate IPv6 loopback address": {
+			url: "http://[::1]:8080/hook",
+			err: true,
+		},
+		"validate private address": {
+			url: "http://10.0.0.1/hook",
+			err: true,
+		},
+		"validate link-local address": {
+			url: "http://169.254.169.254/latest/meta-data",
+			err: true,
+		},
+		"validate unspecified address": {
+			url: "http://0.0.0.0/hook",
+			err: true,
+		},
+		"validate private address when allowed": {
+			url:          "http://10.0.0.1/hook",
+			allowPrivate: true,
+			err:          false,
+		},
+	}
+
+	for desc, tc := range cases {
+		n := webhook.New(webhook.Config{Timeout: time.Second, AllowPrivate: tc.allowPrivate}, newCounterMock())
+		err := n.(notifiers.Validator).Validate(tc.url)
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s", desc, tc.err, err))
+	}
+}
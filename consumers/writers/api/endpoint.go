@@ -0,0 +1,64 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// +build !test
+
+package api
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
+)
+
+func viewSchemaEndpoint(schemas protobuf.SchemaRepository, adminToken string) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(schemaReq)
+		if err := req.validate(adminToken); err != nil {
+			return nil, err
+		}
+
+		s, err := schemas.Retrieve(req.channel)
+		if err != nil {
+			return nil, err
+		}
+
+		return schemaRes{Schema: s}, nil
+	}
+}
+
+func saveSchemaEndpoint(schemas protobuf.SchemaRepository, adminToken string) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(saveSchemaReq)
+		if err := req.validate(adminToken); err != nil {
+			return nil, err
+		}
+
+		s := protobuf.Schema{
+			Channel:    req.channel,
+			Message:    req.Message,
+			Descriptor: req.Descriptor,
+		}
+		if err := schemas.Save(s); err != nil {
+			return nil, err
+		}
+
+		return changeSchemaRes{}, nil
+	}
+}
+
+func removeSchemaEndpoint(schemas protobuf.SchemaRepository, adminToken string) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(schemaReq)
+		if err := req.validate(adminToken); err != nil {
+			return nil, err
+		}
+
+		if err := schemas.Remove(req.channel); err != nil {
+			return nil, err
+		}
+
+		return changeSchemaRes{}, nil
+	}
+}
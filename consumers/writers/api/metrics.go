@@ -7,31 +7,122 @@ import (
 	"time"
 
 	"github.com/go-kit/kit/metrics"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
 )
 
+const subsystem = "message_writer"
+
+// Metrics contains the writer metrics.
+type Metrics struct {
+	// Counter counts the saves.
+	Counter metrics.Counter
+	// Latency observes the save duration.
+	Latency metrics.Histogram
+	// Errors counts the failed saves.
+	Errors metrics.Counter
+	// BatchSize observes the number of messages saved at once.
+	BatchSize metrics.Histogram
+	// QueueDepth is the number of received messages waiting in the queue.
+	QueueDepth metrics.Gauge
+	// Dropped counts the received messages dropped from the full queue.
+	Dropped metrics.Counter
+}
+
+// MakeMetrics returns the writer metrics exposed to Prometheus, within the
+// namespace of the writer database. Pending function is used to report the
+// number of received NATS messages which are not handled yet.
+func MakeMetrics(namespace string, pending func() int) Metrics {
+	stdprometheus.MustRegister(stdprometheus.NewGaugeFunc(stdprometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "pending_messages",
+		Help:      "Number of received NATS messages which are not handled yet.",
+	}, func() float64 {
+		return float64(pending())
+	}))
+
+	return Metrics{
+		Counter: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, []string{"method"}),
+		Latency: kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, []string{"method"}),
+		Errors: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "error_count",
+			Help:      "Number of failed requests.",
+		}, []string{"method"}),
+		BatchSize: kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "batch_size",
+			Help:      "Number of messages saved at once.",
+			Buckets:   stdprometheus.ExponentialBuckets(1, 4, 8),
+		}, []string{"method"}),
+		QueueDepth: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "queue_depth",
+			Help:      "Number of received messages waiting in the queue.",
+		}, []string{}),
+		Dropped: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "dropped_messages",
+			Help:      "Number of received messages dropped from the full queue.",
+		}, []string{}),
+	}
+}
+
 var _ consumers.Consumer = (*metricsMiddleware)(nil)
 
 type metricsMiddleware struct {
-	counter  metrics.Counter
-	latency  metrics.Histogram
+	metrics  Metrics
 	consumer consumers.Consumer
 }
 
 // MetricsMiddleware returns new message repository
 // with Save method wrapped to expose metrics.
-func MetricsMiddleware(consumer consumers.Consumer, counter metrics.Counter, latency metrics.Histogram) consumers.Consumer {
+func MetricsMiddleware(consumer consumers.Consumer, metrics Metrics) consumers.Consumer {
 	return &metricsMiddleware{
-		counter:  counter,
-		latency:  latency,
+		metrics:  metrics,
 		consumer: consumer,
 	}
 }
 
 func (mm *metricsMiddleware) Consume(msgs interface{}) error {
 	defer func(begin time.Time) {
-		mm.counter.With("method", "consume").Add(1)
-		mm.latency.With("method", "consume").Observe(time.Since(begin).Seconds())
+		mm.metrics.Counter.With("method", "consume").Add(1)
+		mm.metrics.Latency.With("method", "consume").Observe(time.Since(begin).Seconds())
+		mm.metrics.BatchSize.With("method", "consume").Observe(float64(count(msgs)))
 	}(time.Now())
-	return mm.consumer.Consume(msgs)
+
+	err := mm.consumer.Consume(msgs)
+	if err != nil {
+		mm.metrics.Errors.With("method", "consume").Add(1)
+	}
+	return err
+}
+
+func count(msgs interface{}) int {
+	switch m := msgs.(type) {
+	case []senml.Message:
+		return len(m)
+	case json.Messages:
+		return len(m.Data)
+	default:
+		return 1
+	}
 }
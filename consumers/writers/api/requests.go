@@ -0,0 +1,62 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// +build !test
+
+package api
+
+import (
+	"crypto/subtle"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
+)
+
+func authorize(token, adminToken string) error {
+	if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		return errUnauthorized
+	}
+
+	return nil
+}
+
+type schemaReq struct {
+	token   string
+	channel string
+}
+
+func (req schemaReq) validate(adminToken string) error {
+	if err := authorize(req.token, adminToken); err != nil {
+		return err
+	}
+
+	if req.channel == "" {
+		return errors.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type saveSchemaReq struct {
+	token      string
+	channel    string
+	Message    string `json:"message"`
+	Descriptor []byte `json:"descriptor"`
+}
+
+func (req saveSchemaReq) validate(adminToken string) error {
+	if err := authorize(req.token, adminToken); err != nil {
+		return err
+	}
+
+	if req.channel == "" || req.Message == "" || len(req.Descriptor) == 0 {
+		return errors.ErrMalformedEntity
+	}
+
+	s := protobuf.Schema{
+		Channel:    req.channel,
+		Message:    req.Message,
+		Descriptor: req.Descriptor,
+	}
+	return s.Validate()
+}
@@ -0,0 +1,48 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// +build !test
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
+)
+
+var (
+	_ mainflux.Response = (*schemaRes)(nil)
+	_ mainflux.Response = (*changeSchemaRes)(nil)
+)
+
+type schemaRes struct {
+	protobuf.Schema
+}
+
+func (res schemaRes) Code() int {
+	return http.StatusOK
+}
+
+func (res schemaRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res schemaRes) Empty() bool {
+	return false
+}
+
+type changeSchemaRes struct{}
+
+func (res changeSchemaRes) Code() int {
+	return http.StatusNoContent
+}
+
+func (res changeSchemaRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res changeSchemaRes) Empty() bool {
+	return true
+}
@@ -6,18 +6,129 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 
+	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/go-zoo/bone"
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// MakeHandler returns a HTTP API handler with version and metrics.
-func MakeHandler(svcName string) http.Handler {
+const contentType = "application/json"
+
+var errUnauthorized = errors.New("missing or invalid credentials provided")
+
+// MakeHandler returns a HTTP API handler with version, health and metrics.
+// Health endpoint reports the writer as unavailable if any of the checks of
+// the writer dependencies fails. If the schema repository and admin token
+// are provided, the protobuf schemas management endpoints, available using
+// the admin token, are exposed as well.
+func MakeHandler(svcName string, checks map[string]func() error, schemas protobuf.SchemaRepository, adminToken string) http.Handler {
+	opts := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(encodeError),
+	}
+
 	r := bone.New()
+
+	if schemas != nil && adminToken != "" {
+		r.Get("/schemas/:id", kithttp.NewServer(
+			viewSchemaEndpoint(schemas, adminToken),
+			decodeSchema,
+			encodeResponse,
+			opts...,
+		))
+
+		r.Put("/schemas/:id", kithttp.NewServer(
+			saveSchemaEndpoint(schemas, adminToken),
+			decodeSaveSchema,
+			encodeResponse,
+			opts...,
+		))
+
+		r.Delete("/schemas/:id", kithttp.NewServer(
+			removeSchemaEndpoint(schemas, adminToken),
+			decodeSchema,
+			encodeResponse,
+			opts...,
+		))
+	}
+
 	r.GetFunc("/version", mainflux.Version(svcName))
+	r.GetFunc("/health", mainflux.HealthCheck(svcName, checks))
 	r.Handle("/metrics", promhttp.Handler())
 
 	return r
 }
+
+func decodeSchema(_ context.Context, r *http.Request) (interface{}, error) {
+	req := schemaReq{
+		token:   r.Header.Get("Authorization"),
+		channel: bone.GetValue(r, "id"),
+	}
+
+	return req, nil
+}
+
+func decodeSaveSchema(_ context.Context, r *http.Request) (interface{}, error) {
+	if r.Header.Get("Content-Type") != contentType {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := saveSchemaReq{
+		token:   r.Header.Get("Authorization"),
+		channel: bone.GetValue(r, "id"),
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", contentType)
+
+	if ar, ok := response.(mainflux.Response); ok {
+		for k, v := range ar.Headers() {
+			w.Header().Set(k, v)
+		}
+
+		w.WriteHeader(ar.Code())
+
+		if ar.Empty() {
+			return nil
+		}
+	}
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+func encodeError(_ context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", contentType)
+
+	switch {
+	case err == errUnauthorized:
+		w.WriteHeader(http.StatusUnauthorized)
+	case err == errors.ErrUnsupportedContentType:
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	case err == protobuf.ErrNotFound:
+		w.WriteHeader(http.StatusNotFound)
+	case err == io.EOF, err == errors.ErrMalformedEntity,
+		errors.Contains(err, protobuf.ErrMalformedSchema):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		switch err.(type) {
+		case *json.SyntaxError:
+			w.WriteHeader(http.StatusBadRequest)
+		case *json.UnmarshalTypeError:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+// Closer is implemented by the consumers holding the messages in memory,
+// which have to be saved before the service terminates.
+type Closer interface {
+	// Close passes the messages held in memory to the wrapped consumer. The
+	// messages received once the consumer is closed are passed to the
+	// wrapped consumer immediately.
+	Close() error
+}
+
+// Close closes the consumer, if it holds the messages in memory.
+func Close(consumer consumers.Consumer) error {
+	if c, ok := consumer.(Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+var (
+	_ consumers.Consumer = (*batchConsumer)(nil)
+	_ Closer             = (*batchConsumer)(nil)
+)
+
+type batchConsumer struct {
+	consumer consumers.Consumer
+	size     int
+	ticker   *time.Ticker
+	done     chan struct{}
+	saved    chan struct{}
+	batches  chan []interface{}
+	sending  sync.WaitGroup
+	logger   logger.Logger
+	mu       sync.Mutex
+	closed   bool
+	senml    []senml.Message
+	json     map[string][]json.Message
+	count    int
+}
+
+// NewBatchConsumer wraps the consumer with the buffer that merges received
+// messages and passes them to the wrapped consumer in batches, once the batch
+// size is reached or once the flush interval elapses, whichever comes first.
+// SenML messages are merged into a single batch, while JSON messages are
+// batched per format. Since batches are saved asynchronously, the errors of
+// the wrapped consumer are logged. The buffered messages are saved once the
+// consumer is closed. If the batch size is not greater than 1, the consumer
+// is returned unchanged.
+func NewBatchConsumer(consumer consumers.Consumer, size int, interval time.Duration, logger logger.Logger) consumers.Consumer {
+	if size <= 1 {
+		return consumer
+	}
+
+	bc := &batchConsumer{
+		consumer: consumer,
+		size:     size,
+		ticker:   time.NewTicker(interval),
+		done:     make(chan struct{}),
+		saved:    make(chan struct{}),
+		batches:  make(chan []interface{}, 1),
+		logger:   logger,
+		json:     map[string][]json.Message{},
+	}
+
+	go bc.save()
+	go bc.tick()
+
+	return bc
+}
+
+func (bc *batchConsumer) Consume(messages interface{}) error {
+	bc.mu.Lock()
+	if bc.closed {
+		bc.mu.Unlock()
+		return bc.consumer.Consume(messages)
+	}
+	switch m := messages.(type) {
+	case []senml.Message:
+		bc.senml = append(bc.senml, m...)
+		bc.count += len(m)
+	case json.Messages:
+		bc.json[m.Format] = append(bc.json[m.Format], m.Data...)
+		bc.count += len(m.Data)
+	default:
+		// Unknown messages are not batched.
+		bc.mu.Unlock()
+		return bc.consumer.Consume(messages)
+	}
+	var batch []interface{}
+	if bc.count >= bc.size {
+		batch = bc.swap()
+		bc.sending.Add(1)
+	}
+	bc.mu.Unlock()
+
+	// Sending the batch blocks while the previous one is being saved,
+	// which slows down consuming when the database can not keep up.
+	if batch != nil {
+		bc.batches <- batch
+		bc.sending.Done()
+	}
+
+	return nil
+}
+
+// Close stops the flushing, waits for the pending batches to be saved and
+// saves the buffered messages.
+func (bc *batchConsumer) Close() error {
+	bc.mu.Lock()
+	if bc.closed {
+		bc.mu.Unlock()
+		return nil
+	}
+	bc.closed = true
+	batch := bc.swap()
+	bc.mu.Unlock()
+
+	bc.ticker.Stop()
+	close(bc.done)
+	// No batches are sent once closed, so the channel can be closed as soon
+	// as the ones being sent are received.
+	bc.sending.Wait()
+	close(bc.batches)
+	<-bc.saved
+
+	return bc.saveBatch(batch)
+}
+
+// swap empties the buffer and returns the buffered messages in the form
+// accepted by the wrapped consumer. It must be called with the lock held.
+func (bc *batchConsumer) swap() []interface{} {
+	var batch []interface{}
+	if len(bc.senml) > 0 {
+		batch = append(batch, bc.senml)
+	}
+	for format, data := range bc.json {
+		batch = append(batch, json.Messages{
+			Data:   data,
+			Format: format,
+		})
+	}
+
+	bc.senml = nil
+	bc.json = map[string][]json.Message{}
+	bc.count = 0
+
+	return batch
+}
+
+func (bc *batchConsumer) tick() {
+	for {
+		select {
+		case <-bc.done:
+			return
+		case <-bc.ticker.C:
+		}
+
+		bc.mu.Lock()
+		if bc.closed || bc.count == 0 {
+			bc.mu.Unlock()
+			continue
+		}
+		batch := bc.swap()
+		bc.sending.Add(1)
+		bc.mu.Unlock()
+
+		bc.batches <- batch
+		bc.sending.Done()
+	}
+}
+
+func (bc *batchConsumer) save() {
+	for batch := range bc.batches {
+		if err := bc.saveBatch(batch); err != nil {
+			bc.logger.Warn(fmt.Sprintf("Failed to save batch: %s", err))
+		}
+	}
+	close(bc.saved)
+}
+
+// saveBatch passes all the messages of the batch to the wrapped consumer,
+// returning the first error.
+func (bc *batchConsumer) saveBatch(batch []interface{}) error {
+	var ret error
+	for _, msgs := range batch {
+		if err := bc.consumer.Consume(msgs); err != nil && ret == nil {
+			ret = err
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/writers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	batchSize     = 10
+	flushInterval = 100 * time.Millisecond
+)
+
+type consumerMock struct {
+	mu    sync.Mutex
+	calls []interface{}
+}
+
+func (cm *consumerMock) Consume(msgs interface{}) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cm.calls = append(cm.calls, msgs)
+	return nil
+}
+
+func (cm *consumerMock) consumed() []interface{} {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	return cm.calls
+}
+
+func TestBatchSize(t *testing.T) {
+	mock := &consumerMock{}
+	c := writers.NewBatchConsumer(mock, batchSize, time.Hour, testLog)
+
+	for i := 0; i < batchSize-1; i++ {
+		err := c.Consume([]senml.Message{{Name: fmt.Sprintf("%d", i)}})
+		assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	}
+	time.Sleep(flushInterval)
+	assert.Len(t, mock.consumed(), 0, "expected no messages saved before batch is full")
+
+	err := c.Consume([]senml.Message{{Name: "last"}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	time.Sleep(flushInterval)
+
+	calls := mock.consumed()
+	assert.Len(t, calls, 1, fmt.Sprintf("expected 1 batch got %d\n", len(calls)))
+	msgs, ok := calls[0].([]senml.Message)
+	assert.True(t, ok, "expected SenML messages")
+	assert.Len(t, msgs, batchSize, fmt.Sprintf("expected %d messages got %d\n", batchSize, len(msgs)))
+	assert.Equal(t, "last", msgs[batchSize-1].Name, "expected messages to keep the order")
+}
+
+func TestFlushInterval(t *testing.T) {
+	mock := &consumerMock{}
+	c := writers.NewBatchConsumer(mock, batchSize, flushInterval, testLog)
+
+	err := c.Consume(json.Messages{Format: "a", Data: []json.Message{{}, {}}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	err = c.Consume(json.Messages{Format: "b", Data: []json.Message{{}}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	err = c.Consume(json.Messages{Format: "a", Data: []json.Message{{}}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	time.Sleep(3 * flushInterval)
+
+	counts := map[string]int{}
+	for _, call := range mock.consumed() {
+		msgs, ok := call.(json.Messages)
+		assert.True(t, ok, "expected JSON messages")
+		counts[msgs.Format] += len(msgs.Data)
+	}
+	assert.Equal(t, map[string]int{"a": 3, "b": 1}, counts, fmt.Sprintf("expected batches per format got %v\n", counts))
+}
+
+func TestBatchDisabled(t *testing.T) {
+	mock := &consumerMock{}
+	c := writers.NewBatchConsumer(mock, 1, flushInterval, testLog)
+
+	err := c.Consume([]senml.Message{{}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	assert.Len(t, mock.consumed(), 1, "expected messages saved immediately")
+}
+
+func TestBatchClose(t *testing.T) {
+	mock := &consumerMock{}
+	c := writers.NewBatchConsumer(mock, batchSize, time.Hour, testLog)
+
+	err := c.Consume([]senml.Message{{Name: "buffered"}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	assert.Len(t, mock.consumed(), 0, "expected no messages saved before closing")
+
+	err = writers.Close(c)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	calls := mock.consumed()
+	assert.Len(t, calls, 1, "expected buffered messages saved on close")
+
+	err = c.Consume([]senml.Message{{Name: "closed"}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	assert.Len(t, mock.consumed(), 2, "expected messages saved immediately once closed")
+
+	err = writers.Close(c)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+}
+
+func TestBatchCloseError(t *testing.T) {
+	c := writers.NewBatchConsumer(&failingConsumer{failures: 1}, batchSize, time.Hour, testLog)
+
+	err := c.Consume([]senml.Message{{}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	err = writers.Close(c)
+	assert.Equal(t, errSave, err, fmt.Sprintf("expected %s got %s\n", errSave, err))
+}
@@ -6,6 +6,7 @@ package cassandra
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 
 	"github.com/gocql/gocql"
 	"github.com/mainflux/mainflux/consumers"
@@ -14,6 +15,8 @@ import (
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 )
 
+const senmlTable = "messages"
+
 var (
 	errSaveMessage = errors.New("failed to save message to cassandra database")
 	errNoTable     = errors.New("table does not exist")
@@ -21,12 +24,19 @@ var (
 var _ consumers.Consumer = (*cassandraRepository)(nil)
 
 type cassandraRepository struct {
-	session *gocql.Session
+	session  *gocql.Session
+	keyspace string
+	ttl      TTLConfig
 }
 
-// New instantiates Cassandra message repository.
-func New(session *gocql.Session) consumers.Consumer {
-	return &cassandraRepository{session}
+// New instantiates Cassandra message repository. Messages are saved with the
+// TTL configured for the table of the session keyspace.
+func New(session *gocql.Session, keyspace string, ttl TTLConfig) consumers.Consumer {
+	return &cassandraRepository{
+		session:  session,
+		keyspace: keyspace,
+		ttl:      ttl,
+	}
 }
 
 func (cr *cassandraRepository) Consume(message interface{}) error {
@@ -46,7 +56,7 @@ func (cr *cassandraRepository) saveSenml(messages interface{}) error {
 	cql := `INSERT INTO messages (id, channel, subtopic, publisher, protocol,
             name, unit, value, string_value, bool_value, data_value, sum,
             time, update_time)
-            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)` + cr.usingTTL(senmlTable)
 	id := gocql.TimeUUID()
 
 	for _, msg := range msgs {
@@ -80,8 +90,8 @@ func (cr *cassandraRepository) insertJSON(msgs mfjson.Messages) error {
 		if err != nil {
 			return err
 		}
-		cql := `INSERT INTO %s (id, channel, created, subtopic, publisher, protocol, payload) VALUES (?, ?, ?, ?, ?, ?, ?)`
-		cql = fmt.Sprintf(cql, msgs.Format)
+		cql := `INSERT INTO %s (id, channel, created, subtopic, publisher, protocol, payload) VALUES (?, ?, ?, ?, ?, ?, ?)%s`
+		cql = fmt.Sprintf(cql, msgs.Format, cr.usingTTL(msgs.Format))
 		id := gocql.TimeUUID()
 
 		err = cr.session.Query(cql, id, msg.Channel, msg.Created, msg.Subtopic, msg.Publisher, msg.Protocol, string(pld)).Exec()
@@ -99,3 +109,14 @@ func (cr *cassandraRepository) createTable(name string) error {
 	q := fmt.Sprintf(jsonTable, name)
 	return cr.session.Query(q).Exec()
 }
+
+// usingTTL returns the insert clause which sets the TTL of the messages saved
+// to the table, or an empty string if the messages don't expire. The clause
+// is omitted for zero TTL, so that the default TTL of the table is applied.
+func (cr *cassandraRepository) usingTTL(table string) string {
+	ttl := cr.ttl.TTL(cr.keyspace, table)
+	if ttl <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" USING TTL %d", int64(math.Ceil(ttl.Seconds())))
+}
@@ -39,7 +39,7 @@ func TestSaveSenml(t *testing.T) {
 		Keyspace: keyspace,
 	})
 	require.Nil(t, err, fmt.Sprintf("failed to connect to Cassandra: %s", err))
-	repo := cassandra.New(session)
+	repo := cassandra.New(session, keyspace, cassandra.TTLConfig{})
 	now := time.Now().Unix()
 	msg := senml.Message{
 		Channel:   "1",
@@ -79,7 +79,7 @@ func TestSaveJSON(t *testing.T) {
 		Keyspace: keyspace,
 	})
 	require.Nil(t, err, fmt.Sprintf("failed to connect to Cassandra: %s", err))
-	repo := cassandra.New(session)
+	repo := cassandra.New(session, keyspace, cassandra.TTLConfig{})
 	chid, err := uuid.NewV4()
 	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
 	pubid, err := uuid.NewV4()
@@ -0,0 +1,73 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cassandra
+
+import (
+	"io/ioutil"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/pelletier/go-toml"
+)
+
+var (
+	errOpenTTLFile  = errors.New("unable to open TTL configuration file")
+	errParseTTLFile = errors.New("unable to parse TTL configuration file")
+	errInvalidTTL   = errors.New("TTL must not be negative")
+)
+
+// TTLConfig contains the time to live of the saved messages. Tables are
+// listed in the "keyspace.table" form and take precedence over the keyspaces,
+// which take precedence over the default TTL. Zero TTL means that the
+// messages don't expire.
+type TTLConfig struct {
+	Default   time.Duration            `toml:"default"`
+	Keyspaces map[string]time.Duration `toml:"keyspaces"`
+	Tables    map[string]time.Duration `toml:"tables"`
+}
+
+type ttlFile struct {
+	TTL TTLConfig `toml:"ttl"`
+}
+
+// LoadTTL loads the TTL configuration from the "ttl" section of the
+// configuration file which contains the subjects list.
+func LoadTTL(path string) (TTLConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return TTLConfig{}, errors.Wrap(errOpenTTLFile, err)
+	}
+
+	var cfg ttlFile
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return TTLConfig{}, errors.Wrap(errParseTTLFile, err)
+	}
+
+	ttls := []time.Duration{cfg.TTL.Default}
+	for _, ttl := range cfg.TTL.Keyspaces {
+		ttls = append(ttls, ttl)
+	}
+	for _, ttl := range cfg.TTL.Tables {
+		ttls = append(ttls, ttl)
+	}
+	for _, ttl := range ttls {
+		if ttl < 0 {
+			return TTLConfig{}, errInvalidTTL
+		}
+	}
+
+	return cfg.TTL, nil
+}
+
+// TTL returns the time to live of the messages saved to the table of the
+// keyspace.
+func (cfg TTLConfig) TTL(keyspace, table string) time.Duration {
+	if ttl, ok := cfg.Tables[keyspace+"."+table]; ok {
+		return ttl
+	}
+	if ttl, ok := cfg.Keyspaces[keyspace]; ok {
+		return ttl
+	}
+	return cfg.Default
+}
@@ -0,0 +1,86 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cassandra_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/mainflux/mainflux/consumers/writers/cassandra"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ttlConfig = `
+[subjects]
+filter = ["channels.>"]
+
+[ttl]
+default = "720h"
+keyspaces = { tenant = "24h" }
+tables = { "tenant.messages" = "1h", "mainflux.events" = "0s" }
+`
+
+func TestLoadTTL(t *testing.T) {
+	file, err := ioutil.TempFile("", "config.toml")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(ttlConfig)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	file.Close()
+
+	cfg, err := cassandra.LoadTTL(file.Name())
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	cases := []struct {
+		desc     string
+		keyspace string
+		table    string
+		ttl      time.Duration
+	}{
+		{desc: "TTL of the table", keyspace: "tenant", table: "messages", ttl: time.Hour},
+		{desc: "TTL of the keyspace", keyspace: "tenant", table: "events", ttl: 24 * time.Hour},
+		{desc: "default TTL", keyspace: "mainflux", table: "messages", ttl: 720 * time.Hour},
+		{desc: "disabled TTL of the table", keyspace: "mainflux", table: "events", ttl: 0},
+	}
+
+	for _, tc := range cases {
+		ttl := cfg.TTL(tc.keyspace, tc.table)
+		assert.Equal(t, tc.ttl, ttl, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.ttl, ttl))
+	}
+
+	_, err = cassandra.LoadTTL("nonexistent.toml")
+	assert.NotNil(t, err, "expected error loading nonexistent file")
+}
+
+func TestSaveWithTTL(t *testing.T) {
+	session, err := cassandra.Connect(cassandra.DBConfig{
+		Hosts:    []string{addr},
+		Keyspace: keyspace,
+	})
+	require.Nil(t, err, fmt.Sprintf("failed to connect to Cassandra: %s", err))
+	repo := cassandra.New(session, keyspace, cassandra.TTLConfig{Default: time.Hour})
+
+	chid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	msg := senml.Message{
+		Channel:   chid.String(),
+		Publisher: "1",
+		Protocol:  "mqtt",
+		Value:     &v,
+		Time:      float64(time.Now().Unix()),
+	}
+	err = repo.Consume([]senml.Message{msg})
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	var ttl int
+	err = session.Query(`SELECT TTL(value) FROM messages WHERE channel = ?`, msg.Channel).Scan(&ttl)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.True(t, ttl > 0 && ttl <= 3600, fmt.Sprintf("expected TTL of an hour got %d seconds", ttl))
+}
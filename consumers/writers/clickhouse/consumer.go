@@ -0,0 +1,154 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/pkg/clickhouse"
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+const insertQuery = "INSERT INTO %s FORMAT JSONEachRow"
+
+var (
+	errSaveMessage = errors.New("failed to save message to clickhouse database")
+	errUnsupported = errors.New("unsupported message format")
+)
+
+var _ consumers.Consumer = (*clickhouseRepo)(nil)
+
+type clickhouseRepo struct {
+	client clickhouse.Client
+	// tables contains JSON tables known to exist.
+	tables sync.Map
+}
+
+// New returns new ClickHouse writer. Since ClickHouse is optimized for large
+// inserts, the writer should be wrapped with the batch consumer.
+func New(client clickhouse.Client) consumers.Consumer {
+	return &clickhouseRepo{
+		client: client,
+	}
+}
+
+func (cr *clickhouseRepo) Consume(messages interface{}) error {
+	switch m := messages.(type) {
+	case mfjson.Messages:
+		return cr.saveJSON(m)
+	case []senml.Message:
+		return cr.saveSenml(m)
+	default:
+		return errors.Wrap(errSaveMessage, errUnsupported)
+	}
+}
+
+func (cr *clickhouseRepo) saveSenml(msgs []senml.Message) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, msg := range msgs {
+		if err := enc.Encode(toMessage(msg)); err != nil {
+			return errors.Wrap(errSaveMessage, err)
+		}
+	}
+
+	return cr.insert("messages", &body)
+}
+
+func (cr *clickhouseRepo) saveJSON(msgs mfjson.Messages) error {
+	table := quote(msgs.Format)
+	if _, ok := cr.tables.Load(table); !ok {
+		if err := cr.client.Exec(fmt.Sprintf(createJSONTable, table), nil); err != nil {
+			return errors.Wrap(errSaveMessage, err)
+		}
+		cr.tables.Store(table, struct{}{})
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, msg := range msgs.Data {
+		pld, err := json.Marshal(msg.Payload)
+		if err != nil {
+			return errors.Wrap(errSaveMessage, err)
+		}
+		m := jsonMessage{
+			Channel:   msg.Channel,
+			Subtopic:  msg.Subtopic,
+			Publisher: msg.Publisher,
+			Protocol:  msg.Protocol,
+			Created:   msg.Created,
+			Payload:   string(pld),
+		}
+		if err := enc.Encode(m); err != nil {
+			return errors.Wrap(errSaveMessage, err)
+		}
+	}
+
+	return cr.insert(table, &body)
+}
+
+func (cr *clickhouseRepo) insert(table string, body *bytes.Buffer) error {
+	if err := cr.client.Exec(fmt.Sprintf(insertQuery, table), body); err != nil {
+		return errors.Wrap(errSaveMessage, err)
+	}
+
+	return nil
+}
+
+type jsonMessage struct {
+	Channel   string `json:"channel"`
+	Subtopic  string `json:"subtopic"`
+	Publisher string `json:"publisher"`
+	Protocol  string `json:"protocol"`
+	Created   int64  `json:"created"`
+	Payload   string `json:"payload"`
+}
+
+type message struct {
+	Channel     string   `json:"channel"`
+	Subtopic    string   `json:"subtopic"`
+	Publisher   string   `json:"publisher"`
+	Protocol    string   `json:"protocol"`
+	Name        string   `json:"name"`
+	Unit        string   `json:"unit"`
+	Value       *float64 `json:"value"`
+	StringValue *string  `json:"string_value"`
+	BoolValue   *uint8   `json:"bool_value"`
+	DataValue   *string  `json:"data_value"`
+	Sum         *float64 `json:"sum"`
+	Time        float64  `json:"time"`
+	UpdateTime  float64  `json:"update_time"`
+}
+
+func toMessage(msg senml.Message) message {
+	m := message{
+		Channel:     msg.Channel,
+		Subtopic:    msg.Subtopic,
+		Publisher:   msg.Publisher,
+		Protocol:    msg.Protocol,
+		Name:        msg.Name,
+		Unit:        msg.Unit,
+		Value:       msg.Value,
+		StringValue: msg.StringValue,
+		DataValue:   msg.DataValue,
+		Sum:         msg.Sum,
+		Time:        msg.Time,
+		UpdateTime:  msg.UpdateTime,
+	}
+	if msg.BoolValue != nil {
+		var b uint8
+		if *msg.BoolValue {
+			b = 1
+		}
+		m.BoolValue = &b
+	}
+
+	return m
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mainflux/mainflux/pkg/clickhouse"
+)
+
+// Messages are partitioned by month and sorted by channel and time, which
+// corresponds to the way they are read.
+const createTable = `CREATE TABLE IF NOT EXISTS messages (
+    channel       String,
+    subtopic      String,
+    publisher     String,
+    protocol      String,
+    name          String,
+    unit          String,
+    value         Nullable(Float64),
+    string_value  Nullable(String),
+    bool_value    Nullable(UInt8),
+    data_value    Nullable(String),
+    sum           Nullable(Float64),
+    time          Float64,
+    update_time   Float64
+) ENGINE = MergeTree()
+PARTITION BY toYYYYMM(toDateTime(toUInt32(time)))
+ORDER BY (channel, time)`
+
+// JSON messages are saved to the table named by the message format, with the
+// payload kept as a JSON string, which can be queried using JSON functions.
+const createJSONTable = `CREATE TABLE IF NOT EXISTS %s (
+    channel    String,
+    subtopic   String,
+    publisher  String,
+    protocol   String,
+    created    Int64,
+    payload    String
+) ENGINE = MergeTree()
+PARTITION BY toYYYYMM(toDateTime(intDiv(created, 1000000000)))
+ORDER BY (channel, subtopic, created)`
+
+// Connect creates ClickHouse client and creates the database and messages
+// table if they don't exist. A non-nil error is returned to indicate failure.
+func Connect(cfg clickhouse.Config) (clickhouse.Client, error) {
+	if cfg.DB != "" {
+		// The database is created using the default one.
+		def := cfg
+		def.DB = ""
+		if err := clickhouse.New(def).Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", cfg.DB), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	client := clickhouse.New(cfg)
+	if err := client.Exec(createTable, nil); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// quote quotes the identifier, since JSON message format can be any
+// valid subtopic name.
+func quote(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clickhouse_test contains tests for ClickHouse repository
+// implementations.
+package clickhouse_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	writer "github.com/mainflux/mainflux/consumers/writers/clickhouse"
+	"github.com/mainflux/mainflux/pkg/clickhouse"
+	dockertest "github.com/ory/dockertest/v3"
+)
+
+var client clickhouse.Client
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	container, err := pool.Run("yandex/clickhouse-server", "21.8", nil)
+	if err != nil {
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	cfg := clickhouse.Config{
+		URL: fmt.Sprintf("http://localhost:%s", container.GetPort("8123/tcp")),
+		DB:  "default",
+	}
+
+	if err := pool.Retry(func() error {
+		return clickhouse.New(cfg).Ping()
+	}); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	client, err = writer.Connect(cfg)
+	if err != nil {
+		log.Fatalf("Could not setup test DB connection: %s", err)
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(container); err != nil {
+		log.Fatalf("Could not purge container: %s", err)
+	}
+
+	os.Exit(code)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	broker "github.com/nats-io/nats.go"
+)
+
+const senmlFormat = "senml"
+
+var errDeadLetter = errors.New("failed to publish messages to dead letter")
+
+// DeadLetter specifies the API for publishing the messages which failed to
+// be saved.
+type DeadLetter interface {
+	// Publish publishes the messages along with the error
+	// which caused the failure.
+	Publish(messages interface{}, err error) error
+
+	// Close closes the dead letter connection.
+	Close()
+}
+
+// Letter is published to the dead letter subject. It contains the messages
+// in the format they are saved in, along with the error metadata.
+type Letter struct {
+	Error    string      `json:"error"`
+	Format   string      `json:"format"`
+	Time     time.Time   `json:"time"`
+	Messages interface{} `json:"messages"`
+}
+
+type deadLetter struct {
+	conn    *broker.Conn
+	subject string
+}
+
+// NewDeadLetter returns dead letter which publishes the messages to the NATS
+// subject as JSON encoded Letter. The subject should not start with
+// "channels.", so that the dead letters are not consumed by the writers.
+func NewDeadLetter(url, subject string) (DeadLetter, error) {
+	conn, err := broker.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deadLetter{
+		conn:    conn,
+		subject: subject,
+	}, nil
+}
+
+func (dl *deadLetter) Publish(messages interface{}, err error) error {
+	letter := Letter{
+		Error:    err.Error(),
+		Time:     time.Now().UTC(),
+		Messages: messages,
+	}
+	switch m := messages.(type) {
+	case []senml.Message:
+		letter.Format = senmlFormat
+	case mfjson.Messages:
+		letter.Format = m.Format
+		letter.Messages = m.Data
+	}
+
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return errors.Wrap(errDeadLetter, err)
+	}
+	if err := dl.conn.Publish(dl.subject, data); err != nil {
+		return errors.Wrap(errDeadLetter, err)
+	}
+
+	return nil
+}
+
+func (dl *deadLetter) Close() {
+	dl.conn.Flush()
+	dl.conn.Close()
+}
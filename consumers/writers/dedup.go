@@ -0,0 +1,171 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+// DedupCache keeps track of the recently saved messages.
+type DedupCache interface {
+	// Seen marks the message key as seen and reports whether it has
+	// already been seen within the deduplication window.
+	Seen(key string) (bool, error)
+}
+
+var _ DedupCache = (*lruCache)(nil)
+
+type lruEntry struct {
+	key     string
+	expires time.Time
+}
+
+type lruCache struct {
+	size    int
+	window  time.Duration
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// NewLRUCache returns the in-memory deduplication cache which keeps at most
+// size most recently seen keys, each of them for the duration of the window.
+func NewLRUCache(size int, window time.Duration) DedupCache {
+	return &lruCache{
+		size:    size,
+		window:  window,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (lc *lruCache) Seen(key string) (bool, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	now := time.Now()
+	if el, ok := lc.entries[key]; ok {
+		lc.order.MoveToFront(el)
+		entry := el.Value.(*lruEntry)
+		if now.Before(entry.expires) {
+			return true, nil
+		}
+		entry.expires = now.Add(lc.window)
+		return false, nil
+	}
+
+	lc.entries[key] = lc.order.PushFront(&lruEntry{key: key, expires: now.Add(lc.window)})
+	if lc.size > 0 && lc.order.Len() > lc.size {
+		el := lc.order.Back()
+		lc.order.Remove(el)
+		delete(lc.entries, el.Value.(*lruEntry).key)
+	}
+
+	return false, nil
+}
+
+var _ consumers.Consumer = (*dedupConsumer)(nil)
+
+type dedupConsumer struct {
+	consumer consumers.Consumer
+	cache    DedupCache
+	idField  string
+	logger   logger.Logger
+}
+
+// NewDedupConsumer wraps the consumer with dropping the messages which have
+// already been seen by the cache, so that the redelivered messages are not
+// saved twice. SenML messages are identified by the ID of the received
+// message, time and name, or by the channel, publisher, subtopic, time and
+// name if the message has no ID. JSON messages are identified by the payload
+// field named idField, by the ID of the received message and the position in
+// it, or by the channel, publisher, subtopic and creation time if neither is
+// set. If the cache fails, messages are passed as not seen. If the cache is
+// nil, the consumer is returned unchanged.
+func NewDedupConsumer(consumer consumers.Consumer, cache DedupCache, idField string, logger logger.Logger) consumers.Consumer {
+	if cache == nil {
+		return consumer
+	}
+
+	return &dedupConsumer{
+		consumer: consumer,
+		cache:    cache,
+		idField:  idField,
+		logger:   logger,
+	}
+}
+
+func (dc *dedupConsumer) Consume(messages interface{}) error {
+	switch m := messages.(type) {
+	case []senml.Message:
+		var msgs []senml.Message
+		for _, msg := range m {
+			if !dc.seen(senmlKey(msg)...) {
+				msgs = append(msgs, msg)
+			}
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+		return dc.consumer.Consume(msgs)
+	case json.Messages:
+		msgs := json.Messages{Format: m.Format}
+		for i, msg := range m.Data {
+			if !dc.seen(dc.jsonKey(msg, i)...) {
+				msgs.Data = append(msgs.Data, msg)
+			}
+		}
+		if len(msgs.Data) == 0 {
+			return nil
+		}
+		return dc.consumer.Consume(msgs)
+	default:
+		return dc.consumer.Consume(messages)
+	}
+}
+
+func senmlKey(msg senml.Message) []string {
+	t := strconv.FormatFloat(msg.Time, 'f', -1, 64)
+	if msg.ID != "" {
+		return []string{msg.ID, t, msg.Name}
+	}
+	return []string{msg.Channel, msg.Publisher, msg.Subtopic, t, msg.Name}
+}
+
+// jsonKey returns the key of the message at the given position among the
+// messages received at once, such as the elements of a JSON array.
+func (dc *dedupConsumer) jsonKey(msg json.Message, pos int) []string {
+	if id, ok := msg.Payload[dc.idField]; ok && dc.idField != "" {
+		return []string{msg.Channel, fmt.Sprint(id)}
+	}
+	if msg.ID != "" {
+		return []string{msg.ID, strconv.Itoa(pos)}
+	}
+	return []string{msg.Channel, msg.Publisher, msg.Subtopic, strconv.FormatInt(msg.Created, 10)}
+}
+
+func (dc *dedupConsumer) seen(parts ...string) bool {
+	// Length prefixes keep the key unambiguous regardless of the content.
+	var key strings.Builder
+	for _, p := range parts {
+		fmt.Fprintf(&key, "%d:%s", len(p), p)
+	}
+
+	seen, err := dc.cache.Seen(key.String())
+	if err != nil {
+		dc.logger.Warn(fmt.Sprintf("Failed to check message duplicate: %s", err))
+		return false
+	}
+	return seen
+}
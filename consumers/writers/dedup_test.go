@@ -0,0 +1,173 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/writers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCache(t *testing.T) {
+	window := 50 * time.Millisecond
+	cache := writers.NewLRUCache(2, window)
+
+	cases := []struct {
+		desc string
+		key  string
+		seen bool
+	}{
+		{desc: "check new key", key: "a", seen: false},
+		{desc: "check seen key", key: "a", seen: true},
+		{desc: "check another new key", key: "b", seen: false},
+		{desc: "check key evicting the least recently used", key: "c", seen: false},
+		{desc: "check evicted key", key: "a", seen: false},
+		{desc: "check recently used key", key: "a", seen: true},
+	}
+
+	for _, tc := range cases {
+		seen, err := cache.Seen(tc.key)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s\n", tc.desc, err))
+		assert.Equal(t, tc.seen, seen, fmt.Sprintf("%s: expected %t got %t\n", tc.desc, tc.seen, seen))
+	}
+
+	time.Sleep(window)
+	seen, err := cache.Seen("a")
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	assert.False(t, seen, "expected key to expire after the window")
+}
+
+func TestDedupSenML(t *testing.T) {
+	mock := &consumerMock{}
+	c := writers.NewDedupConsumer(mock, writers.NewLRUCache(100, time.Minute), "", testLog)
+
+	msgs := []senml.Message{
+		{Channel: "chan", Publisher: "pub", Name: "temperature", Time: 1},
+		{Channel: "chan", Publisher: "pub", Name: "temperature", Time: 1},
+		{Channel: "chan", Publisher: "pub", Name: "humidity", Time: 1},
+	}
+	err := c.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	err = c.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	err = c.Consume([]senml.Message{{Channel: "chan", Publisher: "pub", Name: "temperature", Time: 2}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	expected := []interface{}{
+		[]senml.Message{msgs[0], msgs[2]},
+		[]senml.Message{{Channel: "chan", Publisher: "pub", Name: "temperature", Time: 2}},
+	}
+	assert.Equal(t, expected, mock.consumed(), "expected duplicates to be dropped")
+}
+
+func TestDedupSenMLKey(t *testing.T) {
+	cases := []struct {
+		desc     string
+		msgs     []senml.Message
+		expected []senml.Message
+	}{
+		{
+			desc: "deduplicate by message ID",
+			msgs: []senml.Message{
+				{ID: "1", Channel: "chan", Publisher: "pub", Name: "temperature", Time: 1},
+				{ID: "1", Channel: "chan", Publisher: "pub", Name: "humidity", Time: 1},
+				{ID: "2", Channel: "chan", Publisher: "pub", Name: "temperature", Time: 1},
+				{ID: "1", Channel: "chan", Publisher: "pub", Name: "temperature", Time: 1},
+			},
+			expected: []senml.Message{
+				{ID: "1", Channel: "chan", Publisher: "pub", Name: "temperature", Time: 1},
+				{ID: "1", Channel: "chan", Publisher: "pub", Name: "humidity", Time: 1},
+				{ID: "2", Channel: "chan", Publisher: "pub", Name: "temperature", Time: 1},
+			},
+		},
+		{
+			desc: "deduplicate by message metadata including subtopic",
+			msgs: []senml.Message{
+				{Channel: "chan", Subtopic: "a", Publisher: "pub", Name: "temperature", Time: 1},
+				{Channel: "chan", Subtopic: "b", Publisher: "pub", Name: "temperature", Time: 1},
+				{Channel: "chan", Subtopic: "a", Publisher: "pub", Name: "temperature", Time: 1},
+			},
+			expected: []senml.Message{
+				{Channel: "chan", Subtopic: "a", Publisher: "pub", Name: "temperature", Time: 1},
+				{Channel: "chan", Subtopic: "b", Publisher: "pub", Name: "temperature", Time: 1},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		mock := &consumerMock{}
+		c := writers.NewDedupConsumer(mock, writers.NewLRUCache(100, time.Minute), "", testLog)
+		err := c.Consume(tc.msgs)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s\n", tc.desc, err))
+		expected := []interface{}{tc.expected}
+		assert.Equal(t, expected, mock.consumed(), fmt.Sprintf("%s: expected duplicates to be dropped\n", tc.desc))
+	}
+}
+
+func TestDedupJSON(t *testing.T) {
+	cases := []struct {
+		desc     string
+		idField  string
+		msgs     []json.Message
+		expected []json.Message
+	}{
+		{
+			desc: "deduplicate by message metadata",
+			msgs: []json.Message{
+				{Channel: "chan", Publisher: "pub", Created: 1, Payload: json.Payload{"id": "1"}},
+				{Channel: "chan", Publisher: "pub", Created: 1, Payload: json.Payload{"id": "2"}},
+				{Channel: "chan", Publisher: "pub", Created: 2, Payload: json.Payload{"id": "1"}},
+			},
+			expected: []json.Message{
+				{Channel: "chan", Publisher: "pub", Created: 1, Payload: json.Payload{"id": "1"}},
+				{Channel: "chan", Publisher: "pub", Created: 2, Payload: json.Payload{"id": "1"}},
+			},
+		},
+		{
+			desc: "deduplicate by received message ID",
+			msgs: []json.Message{
+				{ID: "1", Channel: "chan", Publisher: "pub", Created: 1, Payload: json.Payload{"id": "1"}},
+				{ID: "1", Channel: "chan", Publisher: "pub", Created: 1, Payload: json.Payload{"id": "2"}},
+			},
+			expected: []json.Message{
+				{ID: "1", Channel: "chan", Publisher: "pub", Created: 1, Payload: json.Payload{"id": "1"}},
+				{ID: "1", Channel: "chan", Publisher: "pub", Created: 1, Payload: json.Payload{"id": "2"}},
+			},
+		},
+		{
+			desc:    "deduplicate by message ID",
+			idField: "id",
+			msgs: []json.Message{
+				{Channel: "chan", Publisher: "pub", Created: 1, Payload: json.Payload{"id": "1"}},
+				{Channel: "chan", Publisher: "pub", Created: 1, Payload: json.Payload{"id": "2"}},
+				{Channel: "chan", Publisher: "pub", Created: 2, Payload: json.Payload{"id": "1"}},
+			},
+			expected: []json.Message{
+				{Channel: "chan", Publisher: "pub", Created: 1, Payload: json.Payload{"id": "1"}},
+				{Channel: "chan", Publisher: "pub", Created: 1, Payload: json.Payload{"id": "2"}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		mock := &consumerMock{}
+		c := writers.NewDedupConsumer(mock, writers.NewLRUCache(100, time.Minute), tc.idField, testLog)
+		err := c.Consume(json.Messages{Data: tc.msgs, Format: "format"})
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s\n", tc.desc, err))
+		expected := []interface{}{json.Messages{Data: tc.expected, Format: "format"}}
+		assert.Equal(t, expected, mock.consumed(), fmt.Sprintf("%s: expected duplicates to be dropped\n", tc.desc))
+	}
+}
+
+func TestDedupDisabled(t *testing.T) {
+	mock := &consumerMock{}
+	c := writers.NewDedupConsumer(mock, nil, "", testLog)
+	assert.Equal(t, consumers.Consumer(mock), c, "expected consumer unchanged for nil cache")
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const ndjson = "application/x-ndjson"
+
+// ErrRequest indicates failure to execute Elasticsearch request.
+var ErrRequest = errors.New("failed to execute elasticsearch request")
+
+// Client represents Elasticsearch REST API client.
+type Client interface {
+	// Bulk executes the bulk request. The body contains newline
+	// delimited action and document lines.
+	Bulk(body io.Reader) error
+
+	// PutTemplate creates or updates the index template.
+	PutTemplate(name string, template interface{}) error
+
+	// Ping checks if Elasticsearch is available.
+	Ping() error
+}
+
+type client struct {
+	url  string
+	user string
+	pass string
+	http *http.Client
+}
+
+// NewClient returns new Elasticsearch client.
+func NewClient(url, user, pass string, timeout time.Duration) Client {
+	return client{
+		url:  strings.TrimSuffix(url, "/"),
+		user: user,
+		pass: pass,
+		http: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c client) Bulk(body io.Reader) error {
+	data, err := c.do(http.MethodPost, "/_bulk", ndjson, body)
+	if err != nil {
+		return err
+	}
+
+	// Bulk request succeeds even if some of the documents are rejected,
+	// so the response has to be checked for item errors.
+	var res bulkRes
+	if err := json.Unmarshal(data, &res); err != nil {
+		return errors.Wrap(ErrRequest, err)
+	}
+	if !res.Errors {
+		return nil
+	}
+	for _, item := range res.Items {
+		if e := item.Index.Error; e != nil {
+			return errors.Wrap(ErrRequest, fmt.Errorf("%s: %s", e.Type, e.Reason))
+		}
+	}
+
+	return ErrRequest
+}
+
+func (c client) PutTemplate(name string, template interface{}) error {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return errors.Wrap(ErrRequest, err)
+	}
+
+	_, err = c.do(http.MethodPut, "/_index_template/"+name, "application/json", bytes.NewReader(data))
+	return err
+}
+
+func (c client) Ping() error {
+	_, err := c.do(http.MethodGet, "/", "", nil)
+	return err
+}
+
+func (c client) do(method, path, contentType string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequest(method, c.url+path, body)
+	if err != nil {
+		return nil, errors.Wrap(ErrRequest, err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.pass)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(ErrRequest, err)
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(ErrRequest, err)
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		return nil, errors.Wrap(ErrRequest, errors.New(strings.TrimSpace(string(data))))
+	}
+
+	return data, nil
+}
+
+type bulkRes struct {
+	Errors bool `json:"errors"`
+	Items  []struct {
+		Index struct {
+			Error *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"index"`
+	} `json:"items"`
+}
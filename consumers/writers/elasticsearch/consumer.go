@@ -0,0 +1,130 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+const (
+	senmlFormat = "messages"
+	dateLayout  = "2006.01.02"
+)
+
+var errSaveMessage = errors.New("failed to save message to elasticsearch")
+
+var _ consumers.Consumer = (*elasticRepo)(nil)
+
+type elasticRepo struct {
+	client Client
+	index  string
+}
+
+// New returns new Elasticsearch writer. Messages are indexed into the daily
+// indices named <index>-<format>-<yyyy.mm.dd>, where the date is the message
+// time in UTC, and SenML messages use "messages" format.
+func New(client Client, index string) consumers.Consumer {
+	return &elasticRepo{
+		client: client,
+		index:  index,
+	}
+}
+
+func (repo *elasticRepo) Consume(message interface{}) error {
+	switch m := message.(type) {
+	case mfjson.Messages:
+		return repo.saveJSON(m)
+	default:
+		return repo.saveSenml(m)
+	}
+}
+
+func (repo *elasticRepo) saveSenml(messages interface{}) error {
+	msgs, ok := messages.([]senml.Message)
+	if !ok {
+		return errSaveMessage
+	}
+
+	var body bytes.Buffer
+	for _, msg := range msgs {
+		sec, dec := math.Modf(msg.Time)
+		t := time.Unix(int64(sec), int64(dec*1e9)).UTC()
+		doc := senmlDoc{
+			Timestamp: t,
+			Message:   msg,
+		}
+		if err := repo.add(&body, senmlFormat, t, doc); err != nil {
+			return errors.Wrap(errSaveMessage, err)
+		}
+	}
+
+	return repo.bulk(&body)
+}
+
+func (repo *elasticRepo) saveJSON(msgs mfjson.Messages) error {
+	var body bytes.Buffer
+	for _, msg := range msgs.Data {
+		t := time.Unix(0, msg.Created).UTC()
+		doc := jsonDoc{
+			Timestamp: t,
+			Message:   msg,
+		}
+		if err := repo.add(&body, msgs.Format, t, doc); err != nil {
+			return errors.Wrap(errSaveMessage, err)
+		}
+	}
+
+	return repo.bulk(&body)
+}
+
+// add appends the index action followed by the document to the bulk body.
+func (repo *elasticRepo) add(body *bytes.Buffer, format string, t time.Time, doc interface{}) error {
+	action := map[string]interface{}{
+		"index": map[string]string{
+			"_index": repo.indexName(format, t),
+		},
+	}
+
+	enc := json.NewEncoder(body)
+	if err := enc.Encode(action); err != nil {
+		return err
+	}
+	return enc.Encode(doc)
+}
+
+func (repo *elasticRepo) bulk(body *bytes.Buffer) error {
+	if body.Len() == 0 {
+		return nil
+	}
+	if err := repo.client.Bulk(body); err != nil {
+		return errors.Wrap(errSaveMessage, err)
+	}
+	return nil
+}
+
+// indexName returns the name of the daily index. Index names must be
+// lowercase, so the format is lowercased.
+func (repo *elasticRepo) indexName(format string, t time.Time) string {
+	return fmt.Sprintf("%s-%s-%s", repo.index, strings.ToLower(format), t.Format(dateLayout))
+}
+
+type senmlDoc struct {
+	Timestamp time.Time `json:"@timestamp"`
+	senml.Message
+}
+
+type jsonDoc struct {
+	Timestamp time.Time `json:"@timestamp"`
+	mfjson.Message
+}
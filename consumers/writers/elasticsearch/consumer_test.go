@@ -0,0 +1,138 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearch_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	writer "github.com/mainflux/mainflux/consumers/writers/elasticsearch"
+	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	msgsNum     = 42
+	valueFields = 5
+	subtopic    = "topic"
+)
+
+var (
+	v       float64 = 5
+	stringV         = "value"
+	boolV           = true
+	dataV           = "base64"
+	sum     float64 = 42
+)
+
+func TestSaveSenml(t *testing.T) {
+	repo := writer.New(client, index)
+
+	chid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	msg := senml.Message{}
+	msg.Channel = chid.String()
+
+	pubid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	msg.Publisher = pubid.String()
+
+	// Messages span two days, so two daily indices are created.
+	now := time.Now().Unix()
+	var msgs []senml.Message
+
+	for i := 0; i < msgsNum; i++ {
+		// Mix possible values as well as value sum.
+		count := i % valueFields
+		switch count {
+		case 0:
+			msg.Subtopic = subtopic
+			msg.Value = &v
+		case 1:
+			msg.BoolValue = &boolV
+		case 2:
+			msg.StringValue = &stringV
+		case 3:
+			msg.DataValue = &dataV
+		case 4:
+			msg.Sum = &sum
+		}
+
+		msg.Time = float64(now - int64(i*3600))
+		msgs = append(msgs, msg)
+	}
+
+	err = repo.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	count := countDocs(t, fmt.Sprintf("%s-messages-*", index), chid.String())
+	assert.Equal(t, msgsNum, count, fmt.Sprintf("expected %d messages got %d\n", msgsNum, count))
+}
+
+func TestSaveJSON(t *testing.T) {
+	repo := writer.New(client, index)
+
+	chid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	msg := mfjson.Message{
+		Channel:   chid.String(),
+		Publisher: "publisher",
+		Created:   time.Now().UnixNano(),
+		Subtopic:  subtopic,
+		Protocol:  "mqtt",
+		Payload: map[string]interface{}{
+			"field_1": 123,
+			"field_2": "value",
+			"field_3": false,
+			"field_4": 12.344,
+			"field_5": map[string]interface{}{
+				"field_1": "value",
+				"field_2": 42,
+			},
+		},
+	}
+
+	msgs := mfjson.Messages{
+		Format: "Some_JSON",
+	}
+	for i := 0; i < msgsNum; i++ {
+		msgs.Data = append(msgs.Data, msg)
+	}
+
+	err = repo.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	count := countDocs(t, fmt.Sprintf("%s-some_json-*", index), chid.String())
+	assert.Equal(t, msgsNum, count, fmt.Sprintf("expected %d messages got %d\n", msgsNum, count))
+}
+
+// countDocs refreshes the indices matching the pattern, so that the indexed
+// documents are searchable, and counts the documents of the channel.
+func countDocs(t *testing.T, pattern, channel string) int {
+	res, err := http.Post(fmt.Sprintf("%s/%s/_refresh", addr, pattern), "application/json", nil)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	res.Body.Close()
+
+	q := url.QueryEscape(fmt.Sprintf(`channel:"%s"`, channel))
+	res, err = http.Get(fmt.Sprintf("%s/%s/_count?q=%s", addr, pattern, q))
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	defer res.Body.Close()
+
+	var body struct {
+		Count int `json:"count"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	return body.Count
+}
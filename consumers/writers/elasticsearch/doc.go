@@ -0,0 +1,6 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package elasticsearch contains repository implementations using
+// Elasticsearch as the underlying database.
+package elasticsearch
@@ -0,0 +1,85 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultMappings are used for the message indices unless custom mappings
+// are provided. Fields that are not listed are mapped dynamically, which
+// covers JSON message payloads.
+var DefaultMappings = json.RawMessage(`{
+  "properties": {
+    "@timestamp":   { "type": "date" },
+    "channel":      { "type": "keyword" },
+    "subtopic":     { "type": "keyword" },
+    "publisher":    { "type": "keyword" },
+    "protocol":     { "type": "keyword" },
+    "name":         { "type": "keyword" },
+    "unit":         { "type": "keyword" },
+    "time":         { "type": "double" },
+    "update_time":  { "type": "double" },
+    "value":        { "type": "double" },
+    "string_value": { "type": "text", "fields": { "keyword": { "type": "keyword", "ignore_above": 256 } } },
+    "data_value":   { "type": "keyword", "index": false },
+    "bool_value":   { "type": "boolean" },
+    "sum":          { "type": "double" },
+    "created":      { "type": "long" }
+  }
+}`)
+
+// Config defines the options that are used when connecting to an
+// Elasticsearch instance.
+type Config struct {
+	URL     string
+	User    string
+	Pass    string
+	Timeout time.Duration
+
+	// Index is the prefix of the daily message indices.
+	Index string
+
+	// Shards and Replicas are the number of primary shards and replicas
+	// of each daily index.
+	Shards   int
+	Replicas int
+
+	// Mappings are applied to every daily index. If empty,
+	// DefaultMappings are used.
+	Mappings json.RawMessage
+}
+
+// Connect creates Elasticsearch client and creates the index template which
+// applies settings and mappings to the daily message indices. A non-nil error
+// is returned to indicate failure.
+func Connect(cfg Config) (Client, error) {
+	client := NewClient(cfg.URL, cfg.User, cfg.Pass, cfg.Timeout)
+	if err := client.Ping(); err != nil {
+		return nil, err
+	}
+
+	mappings := cfg.Mappings
+	if len(mappings) == 0 {
+		mappings = DefaultMappings
+	}
+
+	tmpl := map[string]interface{}{
+		"index_patterns": []string{fmt.Sprintf("%s-*", cfg.Index)},
+		"template": map[string]interface{}{
+			"settings": map[string]interface{}{
+				"number_of_shards":   cfg.Shards,
+				"number_of_replicas": cfg.Replicas,
+			},
+			"mappings": mappings,
+		},
+	}
+	if err := client.PutTemplate(cfg.Index, tmpl); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
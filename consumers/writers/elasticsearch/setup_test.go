@@ -0,0 +1,63 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package elasticsearch_test contains tests for Elasticsearch repository
+// implementations.
+package elasticsearch_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	writer "github.com/mainflux/mainflux/consumers/writers/elasticsearch"
+	dockertest "github.com/ory/dockertest/v3"
+)
+
+const index = "mainflux"
+
+var (
+	client writer.Client
+	addr   string
+)
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	env := []string{
+		"discovery.type=single-node",
+		"ES_JAVA_OPTS=-Xms512m -Xmx512m",
+	}
+	container, err := pool.Run("docker.elastic.co/elasticsearch/elasticsearch", "7.14.0", env)
+	if err != nil {
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	addr = fmt.Sprintf("http://localhost:%s", container.GetPort("9200/tcp"))
+	cfg := writer.Config{
+		URL:     addr,
+		Timeout: 10 * time.Second,
+		Index:   index,
+		Shards:  1,
+	}
+
+	if err := pool.Retry(func() error {
+		client, err = writer.Connect(cfg)
+		return err
+	}); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(container); err != nil {
+		log.Fatalf("Could not purge container: %s", err)
+	}
+
+	os.Exit(code)
+}
@@ -0,0 +1,182 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/pelletier/go-toml"
+)
+
+var (
+	errOpenFilterFile  = errors.New("unable to open filter configuration file")
+	errParseFilterFile = errors.New("unable to parse filter configuration file")
+)
+
+// Filter specifies which messages are saved. Each of the non-empty rules must
+// be satisfied by the message, while the message needs to match any of the
+// listed values of the rule. Subtopics are matched using NATS wildcards,
+// where "*" matches a single token and ">" matches the remaining tokens.
+// Names and value range apply to SenML messages only, and value range applies
+// to the messages with numeric value only.
+type Filter struct {
+	Channels        []string `toml:"channels"`
+	ExcludeChannels []string `toml:"exclude_channels"`
+	Subtopics       []string `toml:"subtopics"`
+	Publishers      []string `toml:"publishers"`
+	Names           []string `toml:"names"`
+	MinValue        *float64 `toml:"min_value"`
+	MaxValue        *float64 `toml:"max_value"`
+}
+
+type filterConfig struct {
+	Filter Filter `toml:"filter"`
+}
+
+// LoadFilter loads the filter from the "filter" section of the configuration
+// file which contains the subjects list.
+func LoadFilter(path string) (Filter, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Filter{}, errors.Wrap(errOpenFilterFile, err)
+	}
+
+	var cfg filterConfig
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return Filter{}, errors.Wrap(errParseFilterFile, err)
+	}
+
+	return cfg.Filter, nil
+}
+
+func (f Filter) empty() bool {
+	return len(f.Channels) == 0 && len(f.ExcludeChannels) == 0 && len(f.Subtopics) == 0 &&
+		len(f.Publishers) == 0 && len(f.Names) == 0 && f.MinValue == nil && f.MaxValue == nil
+}
+
+func (f Filter) matchSenML(msg senml.Message) bool {
+	if !f.match(msg.Channel, msg.Subtopic, msg.Publisher) {
+		return false
+	}
+	if len(f.Names) > 0 && !contains(f.Names, msg.Name) {
+		return false
+	}
+	if msg.Value != nil {
+		if f.MinValue != nil && *msg.Value < *f.MinValue {
+			return false
+		}
+		if f.MaxValue != nil && *msg.Value > *f.MaxValue {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Filter) match(channel, subtopic, publisher string) bool {
+	if len(f.Channels) > 0 && !contains(f.Channels, channel) {
+		return false
+	}
+	if contains(f.ExcludeChannels, channel) {
+		return false
+	}
+	if len(f.Publishers) > 0 && !contains(f.Publishers, publisher) {
+		return false
+	}
+	if len(f.Subtopics) == 0 {
+		return true
+	}
+	for _, pattern := range f.Subtopics {
+		if matchSubtopic(pattern, subtopic) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSubtopic(pattern, subtopic string) bool {
+	if pattern == subtopic {
+		return true
+	}
+	if subtopic == "" {
+		return false
+	}
+
+	pts := strings.Split(pattern, ".")
+	sts := strings.Split(subtopic, ".")
+	for i, p := range pts {
+		switch {
+		case p == ">":
+			return i < len(sts)
+		case i >= len(sts):
+			return false
+		case p != "*" && p != sts[i]:
+			return false
+		}
+	}
+	return len(pts) == len(sts)
+}
+
+var _ consumers.Consumer = (*filterConsumer)(nil)
+
+type filterConsumer struct {
+	consumer consumers.Consumer
+	filter   Filter
+}
+
+// NewFilterConsumer wraps the consumer with the filter, so that only the
+// messages which match the filter are passed to the wrapped consumer. If the
+// filter is empty, the consumer is returned unchanged.
+func NewFilterConsumer(consumer consumers.Consumer, filter Filter) consumers.Consumer {
+	if filter.empty() {
+		return consumer
+	}
+
+	return &filterConsumer{
+		consumer: consumer,
+		filter:   filter,
+	}
+}
+
+func (fc *filterConsumer) Consume(messages interface{}) error {
+	switch m := messages.(type) {
+	case []senml.Message:
+		var msgs []senml.Message
+		for _, msg := range m {
+			if fc.filter.matchSenML(msg) {
+				msgs = append(msgs, msg)
+			}
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+		return fc.consumer.Consume(msgs)
+	case json.Messages:
+		msgs := json.Messages{Format: m.Format}
+		for _, msg := range m.Data {
+			if fc.filter.match(msg.Channel, msg.Subtopic, msg.Publisher) {
+				msgs.Data = append(msgs.Data, msg)
+			}
+		}
+		if len(msgs.Data) == 0 {
+			return nil
+		}
+		return fc.consumer.Consume(msgs)
+	default:
+		return fc.consumer.Consume(messages)
+	}
+}
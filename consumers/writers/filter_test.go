@@ -0,0 +1,143 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mainflux/mainflux/consumers/writers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const filterConfig = `
+[subjects]
+filter = ["channels.>"]
+
+[filter]
+exclude_channels = ["excluded"]
+subtopics = ["sensors.*.temp", "actuators.>"]
+names = ["temperature", "state"]
+min_value = -50.0
+max_value = 100.0
+`
+
+func TestLoadFilter(t *testing.T) {
+	file, err := ioutil.TempFile("", "config.toml")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(filterConfig)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	file.Close()
+
+	f, err := writers.LoadFilter(file.Name())
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	assert.Equal(t, []string{"excluded"}, f.ExcludeChannels, "expected excluded channels")
+	assert.Equal(t, []string{"sensors.*.temp", "actuators.>"}, f.Subtopics, "expected subtopics")
+	require.NotNil(t, f.MinValue, "expected min value")
+	assert.Equal(t, -50.0, *f.MinValue, "expected min value")
+
+	_, err = writers.LoadFilter("nonexistent.toml")
+	assert.NotNil(t, err, "expected error loading nonexistent file")
+}
+
+func TestFilterSenML(t *testing.T) {
+	min, max := -50.0, 100.0
+	filter := writers.Filter{
+		ExcludeChannels: []string{"excluded"},
+		Subtopics:       []string{"sensors.*.temp", "actuators.>"},
+		Names:           []string{"temperature", "state"},
+		MinValue:        &min,
+		MaxValue:        &max,
+	}
+	v, outlier := 21.5, 1000.0
+
+	cases := []struct {
+		desc  string
+		msg   senml.Message
+		saved bool
+	}{
+		{
+			desc:  "save matching message",
+			msg:   senml.Message{Channel: "ch", Subtopic: "sensors.room.temp", Name: "temperature", Value: &v},
+			saved: true,
+		},
+		{
+			desc:  "save message matching multi token wildcard",
+			msg:   senml.Message{Channel: "ch", Subtopic: "actuators.room.valve", Name: "state"},
+			saved: true,
+		},
+		{
+			desc:  "drop message from excluded channel",
+			msg:   senml.Message{Channel: "excluded", Subtopic: "sensors.room.temp", Name: "temperature", Value: &v},
+			saved: false,
+		},
+		{
+			desc:  "drop message with non-matching subtopic",
+			msg:   senml.Message{Channel: "ch", Subtopic: "sensors.room.humidity", Name: "temperature", Value: &v},
+			saved: false,
+		},
+		{
+			desc:  "drop message without subtopic",
+			msg:   senml.Message{Channel: "ch", Name: "temperature", Value: &v},
+			saved: false,
+		},
+		{
+			desc:  "drop message with non-matching name",
+			msg:   senml.Message{Channel: "ch", Subtopic: "sensors.room.temp", Name: "humidity", Value: &v},
+			saved: false,
+		},
+		{
+			desc:  "drop message with value out of range",
+			msg:   senml.Message{Channel: "ch", Subtopic: "sensors.room.temp", Name: "temperature", Value: &outlier},
+			saved: false,
+		},
+	}
+
+	for _, tc := range cases {
+		mock := &consumerMock{}
+		c := writers.NewFilterConsumer(mock, filter)
+		err := c.Consume([]senml.Message{tc.msg})
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s\n", tc.desc, err))
+		assert.Equal(t, tc.saved, len(mock.consumed()) == 1, fmt.Sprintf("%s: expected saved %t\n", tc.desc, tc.saved))
+	}
+}
+
+func TestFilterJSON(t *testing.T) {
+	filter := writers.Filter{
+		Channels:   []string{"ch1", "ch2"},
+		Publishers: []string{"pub"},
+	}
+	mock := &consumerMock{}
+	c := writers.NewFilterConsumer(mock, filter)
+
+	msgs := json.Messages{
+		Format: "format",
+		Data: []json.Message{
+			{Channel: "ch1", Publisher: "pub"},
+			{Channel: "ch2", Publisher: "other"},
+			{Channel: "ch3", Publisher: "pub"},
+			{Channel: "ch2", Publisher: "pub"},
+		},
+	}
+	err := c.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	calls := mock.consumed()
+	require.Len(t, calls, 1, fmt.Sprintf("expected 1 save got %d\n", len(calls)))
+	saved := calls[0].(json.Messages)
+	assert.Equal(t, "format", saved.Format, "expected format to be kept")
+	assert.Equal(t, []json.Message{msgs.Data[0], msgs.Data[3]}, saved.Data, "expected matching messages saved")
+}
+
+func TestFilterEmpty(t *testing.T) {
+	mock := &consumerMock{}
+	c := writers.NewFilterConsumer(mock, writers.Filter{})
+	assert.Equal(t, mock, c, "expected consumer unchanged for empty filter")
+}
@@ -0,0 +1,121 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"plugin"
+
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+const (
+	senmlHookSymbol = "SenML"
+	jsonHookSymbol  = "JSON"
+)
+
+var (
+	errOpenHook    = errors.New("unable to open hook plugin")
+	errInvalidHook = errors.New("invalid hook plugin")
+	errMissingHook = errors.New("hook plugin exports neither SenML nor JSON function")
+)
+
+// Hook contains the functions executed per message before the message is
+// saved. The function can modify the message in place (e.g. rename fields or
+// convert units), while returning false drops the message. Either of the
+// functions can be nil, in which case the messages of the corresponding
+// format are passed unchanged.
+type Hook struct {
+	SenML func(msg *senml.Message) bool
+	JSON  func(msg *json.Message) bool
+}
+
+// LoadHook loads the hook from the Go plugin on the given path. The plugin
+// needs to export SenML or JSON function, or both of them, with the
+// signatures of the corresponding Hook fields.
+func LoadHook(path string) (Hook, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return Hook{}, errors.Wrap(errOpenHook, err)
+	}
+
+	var h Hook
+	if sym, err := p.Lookup(senmlHookSymbol); err == nil {
+		fn, ok := sym.(func(*senml.Message) bool)
+		if !ok {
+			return Hook{}, errors.Wrap(errInvalidHook, errors.New("invalid SenML function signature"))
+		}
+		h.SenML = fn
+	}
+	if sym, err := p.Lookup(jsonHookSymbol); err == nil {
+		fn, ok := sym.(func(*json.Message) bool)
+		if !ok {
+			return Hook{}, errors.Wrap(errInvalidHook, errors.New("invalid JSON function signature"))
+		}
+		h.JSON = fn
+	}
+	if h.SenML == nil && h.JSON == nil {
+		return Hook{}, errMissingHook
+	}
+
+	return h, nil
+}
+
+var _ consumers.Consumer = (*hookConsumer)(nil)
+
+type hookConsumer struct {
+	consumer consumers.Consumer
+	hook     Hook
+}
+
+// NewHookConsumer wraps the consumer with the hook, so that the messages are
+// passed to the wrapped consumer once processed by the hook. If the hook has
+// no functions, the consumer is returned unchanged.
+func NewHookConsumer(consumer consumers.Consumer, hook Hook) consumers.Consumer {
+	if hook.SenML == nil && hook.JSON == nil {
+		return consumer
+	}
+
+	return &hookConsumer{
+		consumer: consumer,
+		hook:     hook,
+	}
+}
+
+func (hc *hookConsumer) Consume(messages interface{}) error {
+	switch m := messages.(type) {
+	case []senml.Message:
+		if hc.hook.SenML == nil {
+			return hc.consumer.Consume(m)
+		}
+		var msgs []senml.Message
+		for _, msg := range m {
+			if hc.hook.SenML(&msg) {
+				msgs = append(msgs, msg)
+			}
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+		return hc.consumer.Consume(msgs)
+	case json.Messages:
+		if hc.hook.JSON == nil {
+			return hc.consumer.Consume(m)
+		}
+		msgs := json.Messages{Format: m.Format}
+		for _, msg := range m.Data {
+			if hc.hook.JSON(&msg) {
+				msgs.Data = append(msgs.Data, msg)
+			}
+		}
+		if len(msgs.Data) == 0 {
+			return nil
+		}
+		return hc.consumer.Consume(msgs)
+	default:
+		return hc.consumer.Consume(messages)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/consumers/writers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadHook(t *testing.T) {
+	_, err := writers.LoadHook("nonexistent.so")
+	assert.NotNil(t, err, "expected error loading nonexistent plugin")
+}
+
+func TestHookSenML(t *testing.T) {
+	// Convert Fahrenheit to Celsius and drop the messages without value.
+	hook := writers.Hook{
+		SenML: func(msg *senml.Message) bool {
+			if msg.Value == nil {
+				return false
+			}
+			if msg.Unit == "degF" {
+				v := (*msg.Value - 32) * 5 / 9
+				msg.Value = &v
+				msg.Unit = "Cel"
+			}
+			return true
+		},
+	}
+	mock := &consumerMock{}
+	c := writers.NewHookConsumer(mock, hook)
+
+	f, cel := 212.0, 21.5
+	msgs := []senml.Message{
+		{Channel: "ch", Name: "temperature", Unit: "degF", Value: &f},
+		{Channel: "ch", Name: "temperature", Unit: "Cel", Value: &cel},
+		{Channel: "ch", Name: "state"},
+	}
+	err := c.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	calls := mock.consumed()
+	require.Len(t, calls, 1, "expected single call of wrapped consumer")
+	saved := calls[0].([]senml.Message)
+	require.Len(t, saved, 2, "expected message without value to be dropped")
+	assert.Equal(t, "Cel", saved[0].Unit, "expected converted unit")
+	assert.Equal(t, 100.0, *saved[0].Value, "expected converted value")
+	assert.Equal(t, cel, *saved[1].Value, "expected unchanged value")
+
+	err = c.Consume([]senml.Message{{Channel: "ch", Name: "state"}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	assert.Len(t, mock.consumed(), 1, "expected all dropped messages to be skipped")
+}
+
+func TestHookJSON(t *testing.T) {
+	// Rename the field and drop the test messages.
+	hook := writers.Hook{
+		JSON: func(msg *json.Message) bool {
+			if _, ok := msg.Payload["test"]; ok {
+				return false
+			}
+			if v, ok := msg.Payload["tmp"]; ok {
+				msg.Payload["temperature"] = v
+				delete(msg.Payload, "tmp")
+			}
+			return true
+		},
+	}
+	mock := &consumerMock{}
+	c := writers.NewHookConsumer(mock, hook)
+
+	msgs := json.Messages{
+		Format: "some_json",
+		Data: []json.Message{
+			{Channel: "ch", Payload: map[string]interface{}{"tmp": 21.5}},
+			{Channel: "ch", Payload: map[string]interface{}{"test": true}},
+		},
+	}
+	err := c.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	calls := mock.consumed()
+	require.Len(t, calls, 1, "expected single call of wrapped consumer")
+	saved := calls[0].(json.Messages)
+	assert.Equal(t, "some_json", saved.Format, "expected format to be kept")
+	require.Len(t, saved.Data, 1, "expected test message to be dropped")
+	assert.Equal(t, json.Payload{"temperature": 21.5}, saved.Data[0].Payload, "expected renamed field")
+
+	// SenML messages are passed unchanged, since there is no SenML function.
+	senmlMsgs := []senml.Message{{Channel: "ch", Name: "state"}}
+	err = c.Consume(senmlMsgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	assert.Equal(t, senmlMsgs, mock.consumed()[1], "expected unchanged SenML messages")
+}
+
+func TestHookEmpty(t *testing.T) {
+	mock := &consumerMock{}
+	c := writers.NewHookConsumer(mock, writers.Hook{})
+	assert.Equal(t, mock, c, "expected consumer unchanged for empty hook")
+}
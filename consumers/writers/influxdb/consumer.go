@@ -4,11 +4,13 @@
 package influxdb
 
 import (
+	"bytes"
 	"math"
 	"time"
 
 	"github.com/mainflux/mainflux/consumers"
 	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/influxdb2"
 	"github.com/mainflux/mainflux/pkg/transformers/json"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 
@@ -22,47 +24,58 @@ var errSaveMessage = errors.New("failed to save message to influxdb database")
 var _ consumers.Consumer = (*influxRepo)(nil)
 
 type influxRepo struct {
-	client influxdata.Client
-	cfg    influxdata.BatchPointsConfig
+	writer pointsWriter
 }
 
 // New returns new InfluxDB writer.
 func New(client influxdata.Client, database string) consumers.Consumer {
 	return &influxRepo{
-		client: client,
-		cfg: influxdata.BatchPointsConfig{
-			Database: database,
+		writer: v1Writer{
+			client: client,
+			cfg: influxdata.BatchPointsConfig{
+				Database: database,
+			},
 		},
 	}
 }
 
-func (repo *influxRepo) Consume(message interface{}) error {
-	pts, err := influxdata.NewBatchPoints(repo.cfg)
-	if err != nil {
-		return errors.Wrap(errSaveMessage, err)
+// NewV2 returns new InfluxDB 2.x writer, which writes the messages to the
+// bucket.
+func NewV2(client influxdb2.Client, bucket string) consumers.Consumer {
+	return &influxRepo{
+		writer: v2Writer{
+			client: client,
+			bucket: bucket,
+		},
 	}
+}
+
+func (repo *influxRepo) Consume(message interface{}) error {
+	var pts []*influxdata.Point
+	var err error
 	switch m := message.(type) {
 	case json.Messages:
-		pts, err = repo.jsonPoints(pts, m)
+		pts, err = repo.jsonPoints(m)
 	default:
-		pts, err = repo.senmlPoints(pts, m)
+		pts, err = repo.senmlPoints(m)
 	}
 	if err != nil {
 		return err
 	}
 
-	if err := repo.client.Write(pts); err != nil {
+	if err := repo.writer.write(pts); err != nil {
 		return errors.Wrap(errSaveMessage, err)
 	}
 	return nil
 }
 
-func (repo *influxRepo) senmlPoints(pts influxdata.BatchPoints, messages interface{}) (influxdata.BatchPoints, error) {
+func (repo *influxRepo) senmlPoints(messages interface{}) ([]*influxdata.Point, error) {
 	msgs, ok := messages.([]senml.Message)
 	if !ok {
 		return nil, errSaveMessage
 	}
 
+	var pts []*influxdata.Point
 	for _, msg := range msgs {
 		tgs, flds := senmlTags(msg), senmlFields(msg)
 
@@ -73,13 +86,14 @@ func (repo *influxRepo) senmlPoints(pts influxdata.BatchPoints, messages interfa
 		if err != nil {
 			return nil, errors.Wrap(errSaveMessage, err)
 		}
-		pts.AddPoint(pt)
+		pts = append(pts, pt)
 	}
 
 	return pts, nil
 }
 
-func (repo *influxRepo) jsonPoints(pts influxdata.BatchPoints, msgs json.Messages) (influxdata.BatchPoints, error) {
+func (repo *influxRepo) jsonPoints(msgs json.Messages) ([]*influxdata.Point, error) {
+	var pts []*influxdata.Point
 	for i, m := range msgs.Data {
 		t := time.Unix(0, m.Created+int64(i))
 
@@ -94,8 +108,42 @@ func (repo *influxRepo) jsonPoints(pts influxdata.BatchPoints, msgs json.Message
 		if err != nil {
 			return nil, errors.Wrap(errSaveMessage, err)
 		}
-		pts.AddPoint(pt)
+		pts = append(pts, pt)
 	}
 
 	return pts, nil
 }
+
+// pointsWriter writes the points using the API of the particular InfluxDB
+// version.
+type pointsWriter interface {
+	write(pts []*influxdata.Point) error
+}
+
+type v1Writer struct {
+	client influxdata.Client
+	cfg    influxdata.BatchPointsConfig
+}
+
+func (w v1Writer) write(pts []*influxdata.Point) error {
+	bp, err := influxdata.NewBatchPoints(w.cfg)
+	if err != nil {
+		return err
+	}
+	bp.AddPoints(pts)
+	return w.client.Write(bp)
+}
+
+type v2Writer struct {
+	client influxdb2.Client
+	bucket string
+}
+
+func (w v2Writer) write(pts []*influxdata.Point) error {
+	var body bytes.Buffer
+	for _, pt := range pts {
+		body.WriteString(pt.PrecisionString("ns"))
+		body.WriteByte('\n')
+	}
+	return w.client.Write(w.bucket, &body)
+}
@@ -5,7 +5,10 @@ package influxdb_test
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	influxdata "github.com/influxdata/influxdb/client/v2"
 	writer "github.com/mainflux/mainflux/consumers/writers/influxdb"
 	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/influxdb2"
 	"github.com/mainflux/mainflux/pkg/transformers/json"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/stretchr/testify/assert"
@@ -178,3 +182,48 @@ func TestSaveJSON(t *testing.T) {
 	count := len(row)
 	assert.Equal(t, streamsSize, count, fmt.Sprintf("Expected to have %d messages saved, found %d instead.\n", streamsSize, count))
 }
+
+func TestSaveV2(t *testing.T) {
+	mock := &influxdb2Mock{}
+	repo := writer.NewV2(mock, "bucket")
+
+	msgs := []senml.Message{
+		{Channel: "45", Publisher: "2580", Protocol: "http", Name: "temperature", Value: &v, Time: 1633046400},
+		{Channel: "45", Publisher: "2580", Protocol: "http", Name: "state", BoolValue: &boolV, Time: 1633046400.5},
+	}
+	err := repo.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	assert.Equal(t, "bucket", mock.bucket, "expected messages to be written to the bucket")
+	lines := strings.Split(strings.TrimSpace(mock.points), "\n")
+	require.Len(t, lines, len(msgs), "expected a line per message")
+	assert.True(t, strings.HasPrefix(lines[0], "messages,channel=45,name=temperature,publisher=2580 "), fmt.Sprintf("expected tags in line protocol got %s", lines[0]))
+	assert.True(t, strings.HasSuffix(lines[1], " 1633046400500000000"), fmt.Sprintf("expected nanosecond timestamp got %s", lines[1]))
+}
+
+type influxdb2Mock struct {
+	bucket string
+	points string
+}
+
+func (im *influxdb2Mock) Write(bucket string, points io.Reader) error {
+	data, err := ioutil.ReadAll(points)
+	if err != nil {
+		return err
+	}
+	im.bucket = bucket
+	im.points = string(data)
+	return nil
+}
+
+func (im *influxdb2Mock) Query(query string) ([]influxdb2.Row, error) {
+	return nil, nil
+}
+
+func (im *influxdb2Mock) Delete(bucket string, start, stop time.Time, predicate string) error {
+	return nil
+}
+
+func (im *influxdb2Mock) Ping() error {
+	return nil
+}
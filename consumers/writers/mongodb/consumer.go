@@ -5,6 +5,9 @@ package mongodb
 
 import (
 	"context"
+	"math"
+	"sync"
+	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 
@@ -21,12 +24,30 @@ var errSaveMessage = errors.New("failed to save message to mongodb database")
 var _ consumers.Consumer = (*mongoRepo)(nil)
 
 type mongoRepo struct {
-	db *mongo.Database
+	db        *mongo.Database
+	retention Retention
+	prepared  sync.Map
 }
 
-// New returns new MongoDB writer.
-func New(db *mongo.Database) consumers.Consumer {
-	return &mongoRepo{db}
+// New returns new MongoDB writer. Collections are created according to the
+// retention once the messages are saved to them for the first time.
+func New(db *mongo.Database, retention Retention) consumers.Consumer {
+	return &mongoRepo{
+		db:        db,
+		retention: retention,
+	}
+}
+
+// senmlMessage is SenML message with the time field of TTL index.
+type senmlMessage struct {
+	senml.Message `bson:",inline"`
+	Datetime      time.Time `bson:"datetime"`
+}
+
+// jsonMessage is JSON message with the time field of TTL index.
+type jsonMessage struct {
+	json.Message `bson:",inline"`
+	Datetime     time.Time `bson:"datetime"`
 }
 
 func (repo *mongoRepo) Consume(message interface{}) error {
@@ -44,9 +65,17 @@ func (repo *mongoRepo) saveSenml(messages interface{}) error {
 	if !ok {
 		return errSaveMessage
 	}
+	if err := repo.prepare(senmlCollection); err != nil {
+		return errors.Wrap(errSaveMessage, err)
+	}
 	coll := repo.db.Collection(senmlCollection)
 	var dbMsgs []interface{}
 	for _, msg := range msgs {
+		if repo.retention.TTL > 0 {
+			sec, dec := math.Modf(msg.Time)
+			dbMsgs = append(dbMsgs, senmlMessage{msg, time.Unix(int64(sec), int64(dec*1e9))})
+			continue
+		}
 		dbMsgs = append(dbMsgs, msg)
 	}
 
@@ -59,8 +88,15 @@ func (repo *mongoRepo) saveSenml(messages interface{}) error {
 }
 
 func (repo *mongoRepo) saveJSON(msgs json.Messages) error {
+	if err := repo.prepare(msgs.Format); err != nil {
+		return errors.Wrap(errSaveMessage, err)
+	}
 	m := []interface{}{}
 	for _, msg := range msgs.Data {
+		if repo.retention.TTL > 0 {
+			m = append(m, jsonMessage{msg, time.Unix(0, msg.Created)})
+			continue
+		}
 		m = append(m, msg)
 	}
 
@@ -48,7 +48,7 @@ func TestSaveSenml(t *testing.T) {
 	require.Nil(t, err, fmt.Sprintf("Creating new MongoDB client expected to succeed: %s.\n", err))
 
 	db := client.Database(testDB)
-	repo := mongodb.New(db)
+	repo := mongodb.New(db, mongodb.Retention{})
 
 	now := time.Now().Unix()
 	msg := senml.Message{
@@ -96,7 +96,7 @@ func TestSaveJSON(t *testing.T) {
 	require.Nil(t, err, fmt.Sprintf("Creating new MongoDB client expected to succeed: %s.\n", err))
 
 	db := client.Database(testDB)
-	repo := mongodb.New(db)
+	repo := mongodb.New(db, mongodb.Retention{})
 
 	chid, err := uuid.NewV4()
 	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
@@ -134,3 +134,40 @@ func TestSaveJSON(t *testing.T) {
 	err = repo.Consume(msgs)
 	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
 }
+
+func TestRetention(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(addr))
+	require.Nil(t, err, fmt.Sprintf("Creating new MongoDB client expected to succeed: %s.\n", err))
+
+	msgs := []senml.Message{{Channel: "45", Publisher: "2580", Name: "name", Time: float64(time.Now().Unix()), Value: &v}}
+
+	ttlDB := client.Database("ttl")
+	err = mongodb.New(ttlDB, mongodb.Retention{TTL: time.Hour}).Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	// Changed TTL updates the existing index.
+	err = mongodb.New(ttlDB, mongodb.Retention{TTL: 2 * time.Hour}).Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	cursor, err := ttlDB.Collection(collection).Indexes().List(context.Background())
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	var indexes []bson.M
+	err = cursor.All(context.Background(), &indexes)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	var expire interface{}
+	for _, index := range indexes {
+		if e, ok := index["expireAfterSeconds"]; ok {
+			expire = e
+		}
+	}
+	assert.EqualValues(t, 7200, expire, fmt.Sprintf("expected TTL index expiring after 7200s got %v\n", expire))
+
+	cappedDB := client.Database("capped")
+	err = mongodb.New(cappedDB, mongodb.Retention{CappedSize: 1 << 20}).Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	var stats bson.M
+	err = cappedDB.RunCommand(context.Background(), bson.D{{Key: "collStats", Value: collection}}).Decode(&stats)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, true, stats["capped"], "expected capped collection")
+}
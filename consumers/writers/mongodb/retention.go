@@ -0,0 +1,107 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// ttlField is the message time as BSON date, which the TTL index is
+	// created on, since the TTL index ignores the numeric message time.
+	ttlField = "datetime"
+
+	namespaceExists      = 48
+	indexOptionsConflict = 85
+)
+
+var (
+	// ErrRetention indicates that both TTL and capped size are set.
+	ErrRetention = errors.New("TTL index is not supported on capped collections")
+
+	errPrepareCollection = errors.New("failed to prepare collection")
+)
+
+// Retention contains the automatic expiration settings of the messages
+// collections. Messages expire once they are older than TTL, or the oldest
+// messages are removed once the collection reaches the capped size in bytes.
+// Zero value disables the respective setting. TTL and capped size are
+// mutually exclusive, since MongoDB doesn't support TTL indexes on capped
+// collections.
+type Retention struct {
+	TTL        time.Duration
+	CappedSize int64
+}
+
+// Validate returns an error if the retention settings can't be applied.
+func (r Retention) Validate() error {
+	if r.TTL > 0 && r.CappedSize > 0 {
+		return ErrRetention
+	}
+	return nil
+}
+
+// prepare creates the collection as capped or creates the TTL index of the
+// collection, depending on the retention. Each collection is prepared once,
+// before the messages are saved to it for the first time.
+func (repo *mongoRepo) prepare(name string) error {
+	if _, ok := repo.prepared.Load(name); ok {
+		return nil
+	}
+
+	var err error
+	switch {
+	case repo.retention.CappedSize > 0:
+		err = repo.createCapped(name)
+	case repo.retention.TTL > 0:
+		err = repo.createTTLIndex(name)
+	}
+	if err != nil {
+		return errors.Wrap(errPrepareCollection, err)
+	}
+
+	repo.prepared.Store(name, true)
+	return nil
+}
+
+// createCapped creates the capped collection. Existing collections are left
+// unchanged, since they need to be converted manually.
+func (repo *mongoRepo) createCapped(name string) error {
+	opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(repo.retention.CappedSize)
+	err := repo.db.CreateCollection(context.Background(), name, opts)
+	if cmdErr, ok := err.(mongo.CommandError); ok && cmdErr.HasErrorCode(namespaceExists) {
+		return nil
+	}
+	return err
+}
+
+// createTTLIndex creates the TTL index, or updates the expiration of the
+// existing one if the TTL has been changed.
+func (repo *mongoRepo) createTTLIndex(name string) error {
+	ttl := int32(repo.retention.TTL.Seconds())
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: ttlField, Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(ttl),
+	}
+
+	ctx := context.Background()
+	_, err := repo.db.Collection(name).Indexes().CreateOne(ctx, index)
+	if cmdErr, ok := err.(mongo.CommandError); ok && cmdErr.HasErrorCode(indexOptionsConflict) {
+		cmd := bson.D{
+			{Key: "collMod", Value: name},
+			{Key: "index", Value: bson.D{
+				{Key: "keyPattern", Value: bson.D{{Key: ttlField, Value: 1}}},
+				{Key: "expireAfterSeconds", Value: ttl},
+			}},
+		}
+		return repo.db.RunCommand(ctx, cmd).Err()
+	}
+	return err
+}
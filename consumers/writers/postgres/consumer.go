@@ -7,11 +7,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq" // required for DB access
 	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/pkg/compression"
 	"github.com/mainflux/mainflux/pkg/errors"
 	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
@@ -27,17 +29,27 @@ var (
 	errSaveMessage    = errors.New("failed to save message to postgres database")
 	errTransRollback  = errors.New("failed to rollback transaction")
 	errNoTable        = errors.New("relation does not exist")
+	errNoPartition    = errors.New("partition does not exist")
 )
 
 var _ consumers.Consumer = (*postgresRepo)(nil)
 
 type postgresRepo struct {
-	db *sqlx.DB
+	db          *sqlx.DB
+	partition   string
+	compression string
 }
 
-// New returns new PostgreSQL writer.
-func New(db *sqlx.DB) consumers.Consumer {
-	return &postgresRepo{db: db}
+// New returns new PostgreSQL writer. If the partition interval is set, the
+// missing partitions of the messages table are created on write. If the
+// compression codec is set, payloads of JSON messages are compressed and
+// saved to BYTEA column instead of JSONB.
+func New(db *sqlx.DB, partition, compression string) consumers.Consumer {
+	return &postgresRepo{
+		db:          db,
+		partition:   partition,
+		compression: compression,
+	}
 }
 
 func (pr postgresRepo) Consume(message interface{}) (err error) {
@@ -49,11 +61,24 @@ func (pr postgresRepo) Consume(message interface{}) (err error) {
 	}
 }
 
-func (pr postgresRepo) saveSenml(messages interface{}) (err error) {
+func (pr postgresRepo) saveSenml(messages interface{}) error {
 	msgs, ok := messages.([]senml.Message)
 	if !ok {
 		return errSaveMessage
 	}
+	if err := pr.insertSenml(msgs); err != nil {
+		if err == errNoPartition && pr.partition != "" {
+			if err := pr.createPartitions(msgs); err != nil {
+				return err
+			}
+			return pr.insertSenml(msgs)
+		}
+		return err
+	}
+	return nil
+}
+
+func (pr postgresRepo) insertSenml(msgs []senml.Message) (err error) {
 	q := `INSERT INTO messages (id, channel, subtopic, publisher, protocol,
           name, unit, value, string_value, bool_value, data_value, sum,
           time, update_time)
@@ -90,6 +115,8 @@ func (pr postgresRepo) saveSenml(messages interface{}) (err error) {
 				switch pqErr.Code.Name() {
 				case errInvalid:
 					return errors.Wrap(errSaveMessage, errInvalidMessage)
+				case errCheckViolation:
+					return errNoPartition
 				}
 			}
 
@@ -99,6 +126,25 @@ func (pr postgresRepo) saveSenml(messages interface{}) (err error) {
 	return err
 }
 
+// createPartitions creates the partitions for the messages which are out of
+// the range of the existing partitions, e.g. the messages with the delayed
+// or future time.
+func (pr postgresRepo) createPartitions(msgs []senml.Message) error {
+	created := make(map[string]bool)
+	for _, msg := range msgs {
+		t := time.Unix(0, int64(msg.Time*float64(time.Second)))
+		name, _, _ := partitionRange(pr.partition, t)
+		if created[name] {
+			continue
+		}
+		if err := createPartition(pr.db, pr.partition, t); err != nil {
+			return errors.Wrap(errSaveMessage, err)
+		}
+		created[name] = true
+	}
+	return nil
+}
+
 func (pr postgresRepo) saveJSON(msgs mfjson.Messages) error {
 	if err := pr.insertJSON(msgs); err != nil {
 		if err == errNoTable {
@@ -136,7 +182,7 @@ func (pr postgresRepo) insertJSON(msgs mfjson.Messages) error {
 
 	for _, m := range msgs.Data {
 		var dbmsg jsonMessage
-		dbmsg, err = toJSONMessage(m)
+		dbmsg, err = toJSONMessage(m, pr.compression)
 		if err != nil {
 			return errors.Wrap(errSaveMessage, err)
 		}
@@ -164,10 +210,14 @@ func (pr postgresRepo) createTable(name string) error {
                         subtopic      VARCHAR(254),
                         publisher     VARCHAR(254),
                         protocol      TEXT,
-                        payload       JSONB,
+                        payload       %s,
                         PRIMARY KEY (id)
                     )`
-	q = fmt.Sprintf(q, name)
+	payload := "JSONB"
+	if pr.compression != compression.None {
+		payload = "BYTEA"
+	}
+	q = fmt.Sprintf(q, name, payload)
 
 	_, err := pr.db.Exec(q)
 	return err
@@ -188,7 +238,7 @@ type jsonMessage struct {
 	Payload   []byte `db:"payload"`
 }
 
-func toJSONMessage(msg mfjson.Message) (jsonMessage, error) {
+func toJSONMessage(msg mfjson.Message, codec string) (jsonMessage, error) {
 	id, err := uuid.NewV4()
 	if err != nil {
 		return jsonMessage{}, err
@@ -202,6 +252,10 @@ func toJSONMessage(msg mfjson.Message) (jsonMessage, error) {
 		}
 		data = b
 	}
+	data, err = compression.Compress(codec, data)
+	if err != nil {
+		return jsonMessage{}, err
+	}
 
 	m := jsonMessage{
 		ID:        id.String(),
@@ -32,7 +32,7 @@ var (
 )
 
 func TestSaveSenml(t *testing.T) {
-	repo := postgres.New(db)
+	repo := postgres.New(db, "", "")
 
 	chid, err := uuid.NewV4()
 	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
@@ -73,7 +73,7 @@ func TestSaveSenml(t *testing.T) {
 }
 
 func TestSaveJSON(t *testing.T) {
-	repo := postgres.New(db)
+	repo := postgres.New(db, "", "")
 
 	chid, err := uuid.NewV4()
 	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
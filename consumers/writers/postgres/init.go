@@ -11,7 +11,8 @@ import (
 	migrate "github.com/rubenv/sql-migrate"
 )
 
-// Config defines the options that are used when connecting to a PostgreSQL instance
+// Config defines the options that are used when connecting to a PostgreSQL
+// instance, as well as messages table partitioning.
 type Config struct {
 	Host        string
 	Port        string
@@ -22,12 +23,24 @@ type Config struct {
 	SSLCert     string
 	SSLKey      string
 	SSLRootCert string
+	// Partition is the time interval covered by a single partition of the
+	// messages table, either daily or monthly. The messages table is not
+	// partitioned if empty.
+	Partition string
+	// Retention is the age of the partitions that are dropped, in
+	// PostgreSQL interval format. Partitions are kept if empty.
+	Retention string
 }
 
 // Connect creates a connection to the PostgreSQL instance and applies any
-// unapplied database migrations. A non-nil error is returned to indicate
-// failure.
+// unapplied database migrations. If partitioning is enabled, the messages
+// table is created as partitioned, along with the partitions for the current
+// and the next interval. A non-nil error is returned to indicate failure.
 func Connect(cfg Config) (*sqlx.DB, error) {
+	if !validPartition(cfg.Partition) {
+		return nil, errInvalidPartition
+	}
+
 	url := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s sslcert=%s sslkey=%s sslrootcert=%s", cfg.Host, cfg.Port, cfg.User, cfg.Name, cfg.Pass, cfg.SSLMode, cfg.SSLCert, cfg.SSLKey, cfg.SSLRootCert)
 
 	db, err := sqlx.Open("postgres", url)
@@ -35,10 +48,22 @@ func Connect(cfg Config) (*sqlx.DB, error) {
 		return nil, err
 	}
 
+	if cfg.Partition != "" {
+		if err := createPartitionedTable(db); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := migrateDB(db); err != nil {
 		return nil, err
 	}
 
+	if cfg.Partition != "" {
+		if err := NewPartitionManager(db, cfg.Partition, cfg.Retention).Create(); err != nil {
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
 
@@ -0,0 +1,196 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	// Daily partitions contain the messages of a single day.
+	Daily = "daily"
+	// Monthly partitions contain the messages of a single month.
+	Monthly = "monthly"
+
+	partitionPrefix = "messages_p"
+	dailyLayout     = "20060102"
+	monthlyLayout   = "200601"
+
+	errCheckViolation = "check_violation"
+)
+
+var (
+	errInvalidPartition = errors.New("invalid partition interval")
+	errNotPartitioned   = errors.New("messages table exists and is not partitioned")
+	errCreatePartition  = errors.New("failed to create messages partition")
+	errDropPartition    = errors.New("failed to drop messages partition")
+)
+
+// PartitionManager manages the partitions of the messages table.
+type PartitionManager interface {
+	// Create creates the partitions for the current and the next interval,
+	// so that the messages are never written to the missing partition
+	// while the interval changes.
+	Create() error
+
+	// Drop drops the partitions which contain only the messages older than
+	// the retention period and returns their names. Nothing is dropped if
+	// the retention is not set.
+	Drop() ([]string, error)
+}
+
+var _ PartitionManager = (*partitionManager)(nil)
+
+type partitionManager struct {
+	db        *sqlx.DB
+	interval  string
+	retention string
+}
+
+// NewPartitionManager returns the manager of the messages table partitions.
+// The retention is given in PostgreSQL interval format (e.g. "30 days").
+func NewPartitionManager(db *sqlx.DB, interval, retention string) PartitionManager {
+	return &partitionManager{
+		db:        db,
+		interval:  interval,
+		retention: retention,
+	}
+}
+
+func (pm partitionManager) Create() error {
+	now := time.Now().UTC()
+	_, _, next := partitionRange(pm.interval, now)
+	for _, t := range []time.Time{now, next} {
+		if err := createPartition(pm.db, pm.interval, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pm partitionManager) Drop() ([]string, error) {
+	if pm.retention == "" {
+		return nil, nil
+	}
+
+	var cutoff float64
+	if err := pm.db.Get(&cutoff, `SELECT EXTRACT(EPOCH FROM now() - $1::INTERVAL)`, pm.retention); err != nil {
+		return nil, errors.Wrap(errDropPartition, err)
+	}
+
+	var names []string
+	q := `SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+          WHERE i.inhparent = 'messages'::regclass`
+	if err := pm.db.Select(&names, q); err != nil {
+		return nil, errors.Wrap(errDropPartition, err)
+	}
+
+	var dropped []string
+	for _, name := range names {
+		to, ok := partitionEnd(name)
+		if !ok || float64(to.Unix()) > cutoff {
+			continue
+		}
+		if _, err := pm.db.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s`, pq.QuoteIdentifier(name))); err != nil {
+			return dropped, errors.Wrap(errDropPartition, err)
+		}
+		dropped = append(dropped, name)
+	}
+
+	return dropped, nil
+}
+
+// createPartitionedTable creates the messages table partitioned by the
+// message time. The primary key of partitioned table needs to contain the
+// partition column, so the id is not unique across the partitions.
+func createPartitionedTable(db *sqlx.DB) error {
+	q := `CREATE TABLE IF NOT EXISTS messages (
+                        id            UUID,
+                        channel       UUID,
+                        subtopic      VARCHAR(254),
+                        publisher     UUID,
+                        protocol      TEXT,
+                        name          TEXT,
+                        unit          TEXT,
+                        value         FLOAT,
+                        string_value  TEXT,
+                        bool_value    BOOL,
+                        data_value    BYTEA,
+                        sum           FLOAT,
+                        time          FLOAT,
+                        update_time   FLOAT,
+                        PRIMARY KEY (id, time)
+                    ) PARTITION BY RANGE (time)`
+	if _, err := db.Exec(q); err != nil {
+		return err
+	}
+
+	var partitioned bool
+	q = `SELECT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'messages'::regclass)`
+	if err := db.Get(&partitioned, q); err != nil {
+		return err
+	}
+	if !partitioned {
+		return errNotPartitioned
+	}
+
+	return nil
+}
+
+// createPartition creates the partition of the messages table which
+// contains the given time.
+func createPartition(db *sqlx.DB, interval string, t time.Time) error {
+	name, from, to := partitionRange(interval, t)
+	q := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF messages FOR VALUES FROM (%d) TO (%d)`,
+		pq.QuoteIdentifier(name), from.Unix(), to.Unix())
+	if _, err := db.Exec(q); err != nil {
+		return errors.Wrap(errCreatePartition, err)
+	}
+	return nil
+}
+
+// partitionRange returns the name and the time range of the partition which
+// contains the given time.
+func partitionRange(interval string, t time.Time) (string, time.Time, time.Time) {
+	t = t.UTC()
+	switch interval {
+	case Monthly:
+		from := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return partitionPrefix + from.Format(monthlyLayout), from, from.AddDate(0, 1, 0)
+	default:
+		from := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return partitionPrefix + from.Format(dailyLayout), from, from.AddDate(0, 0, 1)
+	}
+}
+
+// partitionEnd returns the end of the partition time range, parsed from the
+// partition name.
+func partitionEnd(name string) (time.Time, bool) {
+	suffix := strings.TrimPrefix(name, partitionPrefix)
+	if suffix == name {
+		return time.Time{}, false
+	}
+	if from, err := time.Parse(dailyLayout, suffix); err == nil && len(suffix) == len(dailyLayout) {
+		return from.AddDate(0, 0, 1), true
+	}
+	if from, err := time.Parse(monthlyLayout, suffix); err == nil && len(suffix) == len(monthlyLayout) {
+		return from.AddDate(0, 1, 0), true
+	}
+	return time.Time{}, false
+}
+
+func validPartition(interval string) bool {
+	switch interval {
+	case "", Daily, Monthly:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/mainflux/mainflux/consumers/writers/postgres"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectInvalidPartition(t *testing.T) {
+	cfg := dbConfig
+	cfg.Partition = "weekly"
+	_, err := postgres.Connect(cfg)
+	assert.NotNil(t, err, "expected error connecting with invalid partition interval")
+
+	// Messages table of the test database is not partitioned.
+	cfg.Partition = postgres.Daily
+	_, err = postgres.Connect(cfg)
+	assert.NotNil(t, err, "expected error partitioning existing messages table")
+}
+
+func TestPartitions(t *testing.T) {
+	_, err := db.Exec("CREATE DATABASE partitioned")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	cfg := dbConfig
+	cfg.Name = "partitioned"
+	cfg.Partition = postgres.Daily
+	cfg.Retention = "30 days"
+	pdb, err := postgres.Connect(cfg)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	defer pdb.Close()
+
+	chid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	now := time.Now()
+	old := now.AddDate(0, 0, -40)
+	msgs := []senml.Message{
+		{Channel: chid.String(), Name: "current", Value: &v, Time: float64(now.Unix())},
+		{Channel: chid.String(), Name: "old", Value: &v, Time: float64(old.Unix())},
+	}
+
+	repo := postgres.New(pdb, cfg.Partition, "")
+	err = repo.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected missing partition to be created got %s\n", err))
+
+	var count int
+	err = pdb.Get(&count, "SELECT COUNT(*) FROM messages")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, len(msgs), count, "expected all messages to be saved")
+
+	pm := postgres.NewPartitionManager(pdb, cfg.Partition, cfg.Retention)
+	err = pm.Create()
+	assert.Nil(t, err, fmt.Sprintf("expected no error creating existing partitions got %s\n", err))
+
+	dropped, err := pm.Drop()
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	expected := []string{fmt.Sprintf("messages_p%s", old.UTC().Format("20060102"))}
+	assert.Equal(t, expected, dropped, "expected expired partition to be dropped")
+
+	err = pdb.Get(&count, "SELECT COUNT(*) FROM messages")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, 1, count, "expected expired messages to be removed")
+}
@@ -16,7 +16,10 @@ import (
 	dockertest "github.com/ory/dockertest/v3"
 )
 
-var db *sqlx.DB
+var (
+	db       *sqlx.DB
+	dbConfig postgres.Config
+)
 
 func TestMain(m *testing.M) {
 	pool, err := dockertest.NewPool("")
@@ -47,7 +50,7 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Could not connect to docker: %s", err)
 	}
 
-	dbConfig := postgres.Config{
+	dbConfig = postgres.Config{
 		Host:        "localhost",
 		Port:        port,
 		User:        "test",
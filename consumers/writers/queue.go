@@ -0,0 +1,129 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"sync"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+var (
+	_ consumers.Consumer = (*queueConsumer)(nil)
+	_ Closer             = (*queueConsumer)(nil)
+)
+
+type queueConsumer struct {
+	consumer consumers.Consumer
+	size     int
+	mu       sync.Mutex
+	cond     *sync.Cond
+	queue    []interface{}
+	closed   bool
+	drained  chan struct{}
+	depth    metrics.Gauge
+	dropped  metrics.Counter
+}
+
+// NewQueueConsumer wraps the consumer with the bounded in-memory queue, so
+// that the received messages are passed to the wrapped consumer in the
+// background, without blocking the consuming. Size is the max number of the
+// queued messages received from NATS. Once the queue is full, the oldest
+// messages are dropped in favor of the new ones, which protects the database
+// from the load spikes at the cost of losing the messages. Since messages are
+// saved asynchronously, the errors are reported by the wrapped consumer only.
+// Closing the consumer saves the queued messages and closes the wrapped
+// consumer. If the size is not greater than 0, the consumer is returned
+// unchanged.
+func NewQueueConsumer(consumer consumers.Consumer, size int, depth metrics.Gauge, dropped metrics.Counter) consumers.Consumer {
+	if size <= 0 {
+		return consumer
+	}
+
+	qc := &queueConsumer{
+		consumer: consumer,
+		size:     size,
+		drained:  make(chan struct{}),
+		depth:    depth,
+		dropped:  dropped,
+	}
+	qc.cond = sync.NewCond(&qc.mu)
+
+	go qc.save()
+
+	return qc
+}
+
+func (qc *queueConsumer) Consume(messages interface{}) error {
+	qc.mu.Lock()
+	if qc.closed {
+		qc.mu.Unlock()
+		return qc.consumer.Consume(messages)
+	}
+	defer qc.mu.Unlock()
+
+	if len(qc.queue) >= qc.size {
+		qc.dropped.Add(float64(count(qc.queue[0])))
+		qc.queue[0] = nil
+		qc.queue = qc.queue[1:]
+	}
+	qc.queue = append(qc.queue, messages)
+	qc.depth.Set(float64(len(qc.queue)))
+	qc.cond.Signal()
+
+	return nil
+}
+
+// Close waits for the queued messages to be saved and closes the wrapped
+// consumer.
+func (qc *queueConsumer) Close() error {
+	qc.mu.Lock()
+	if qc.closed {
+		qc.mu.Unlock()
+		return nil
+	}
+	qc.closed = true
+	qc.cond.Signal()
+	qc.mu.Unlock()
+
+	<-qc.drained
+
+	return Close(qc.consumer)
+}
+
+func (qc *queueConsumer) save() {
+	for {
+		qc.mu.Lock()
+		for len(qc.queue) == 0 && !qc.closed {
+			qc.cond.Wait()
+		}
+		if len(qc.queue) == 0 {
+			qc.mu.Unlock()
+			close(qc.drained)
+			return
+		}
+		msgs := qc.queue[0]
+		qc.queue[0] = nil
+		qc.queue = qc.queue[1:]
+		qc.depth.Set(float64(len(qc.queue)))
+		qc.mu.Unlock()
+
+		qc.consumer.Consume(msgs)
+	}
+}
+
+// count returns the number of the messages, counting the unknown ones as one.
+func count(messages interface{}) int {
+	switch m := messages.(type) {
+	case []senml.Message:
+		return len(m)
+	case json.Messages:
+		return len(m.Data)
+	default:
+		return 1
+	}
+}
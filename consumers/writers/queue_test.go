@@ -0,0 +1,107 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/writers"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const queueSize = 3
+
+// blockingConsumer blocks consuming until released.
+type blockingConsumer struct {
+	consumerMock
+	release chan struct{}
+}
+
+func (bc *blockingConsumer) Consume(msgs interface{}) error {
+	<-bc.release
+	return bc.consumerMock.Consume(msgs)
+}
+
+func TestQueueDropOldest(t *testing.T) {
+	mock := &blockingConsumer{release: make(chan struct{})}
+	depth, dropped := generic.NewGauge("depth"), generic.NewCounter("dropped")
+	c := writers.NewQueueConsumer(mock, queueSize, depth, dropped)
+
+	// The first message is taken from the queue and blocks the saving.
+	err := c.Consume([]senml.Message{{Name: "0"}})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	time.Sleep(flushInterval)
+
+	msgsNum := 6
+	for i := 1; i < msgsNum; i++ {
+		err := c.Consume([]senml.Message{{Name: fmt.Sprintf("%d", i)}})
+		assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	}
+	assert.Equal(t, float64(queueSize), depth.Value(), "expected full queue")
+	assert.Equal(t, float64(msgsNum-1-queueSize), dropped.Value(), "expected oldest messages to be dropped")
+
+	close(mock.release)
+	time.Sleep(flushInterval)
+
+	var names []string
+	for _, call := range mock.consumed() {
+		names = append(names, call.([]senml.Message)[0].Name)
+	}
+	assert.Equal(t, []string{"0", "3", "4", "5"}, names, "expected newest messages to be saved in order")
+	assert.Equal(t, float64(0), depth.Value(), "expected empty queue")
+}
+
+func TestQueueDisabled(t *testing.T) {
+	mock := &consumerMock{}
+	c := writers.NewQueueConsumer(mock, 0, generic.NewGauge("depth"), generic.NewCounter("dropped"))
+	assert.Equal(t, consumers.Consumer(mock), c, "expected consumer unchanged for zero size")
+}
+
+func TestQueueDropCount(t *testing.T) {
+	mock := &blockingConsumer{release: make(chan struct{})}
+	dropped := generic.NewCounter("dropped")
+	c := writers.NewQueueConsumer(mock, 1, generic.NewGauge("depth"), dropped)
+
+	// The first message is taken from the queue and blocks the saving.
+	err := c.Consume([]senml.Message{{}})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	time.Sleep(flushInterval)
+
+	err = c.Consume([]senml.Message{{}, {}, {}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	err = c.Consume([]senml.Message{{}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	assert.Equal(t, float64(3), dropped.Value(), "expected dropped messages to be counted")
+
+	close(mock.release)
+}
+
+func TestQueueClose(t *testing.T) {
+	mock := &blockingConsumer{release: make(chan struct{})}
+	batch := writers.NewBatchConsumer(mock, batchSize, time.Hour, testLog)
+	c := writers.NewQueueConsumer(batch, queueSize, generic.NewGauge("depth"), generic.NewCounter("dropped"))
+
+	for i := 0; i < queueSize; i++ {
+		err := c.Consume([]senml.Message{{Name: fmt.Sprintf("%d", i)}})
+		assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	}
+
+	close(mock.release)
+	err := writers.Close(c)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	var names []string
+	for _, call := range mock.consumed() {
+		for _, msg := range call.([]senml.Message) {
+			names = append(names, msg.Name)
+		}
+	}
+	assert.Equal(t, []string{"0", "1", "2"}, names, "expected queued and buffered messages to be saved on close")
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/consumers/writers"
+)
+
+const channelsKey = "writers:channels"
+
+var _ writers.ChannelRepository = (*channelRepository)(nil)
+
+type channelRepository struct {
+	client *redis.Client
+}
+
+// NewChannelRepository returns Redis channel repository implementation.
+func NewChannelRepository(client *redis.Client) writers.ChannelRepository {
+	return channelRepository{
+		client: client,
+	}
+}
+
+func (cr channelRepository) Save(ch writers.Channel) error {
+	data, err := json.Marshal(ch)
+	if err != nil {
+		return err
+	}
+	return cr.client.HSet(context.Background(), channelsKey, ch.ID, data).Err()
+}
+
+func (cr channelRepository) Remove(id string) error {
+	return cr.client.HDel(context.Background(), channelsKey, id).Err()
+}
+
+func (cr channelRepository) RetrieveAll() ([]writers.Channel, error) {
+	vals, err := cr.client.HGetAll(context.Background(), channelsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var chs []writers.Channel
+	for _, val := range vals {
+		var ch writers.Channel
+		if err := json.Unmarshal([]byte(val), &ch); err != nil {
+			return nil, err
+		}
+		chs = append(chs, ch)
+	}
+
+	return chs, nil
+}
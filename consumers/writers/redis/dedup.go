@@ -0,0 +1,44 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/consumers/writers"
+)
+
+const dedupPrefix = "writers:dedup"
+
+var _ writers.DedupCache = (*dedupCache)(nil)
+
+type dedupCache struct {
+	client   *redis.Client
+	consumer string
+	window   time.Duration
+}
+
+// NewDedupCache returns Redis deduplication cache, which keeps the seen keys
+// for the duration of the window. Keys are namespaced by the consumer name,
+// so that the writers of the different databases don't share the keys, while
+// the instances of the same writer do.
+func NewDedupCache(client *redis.Client, consumer string, window time.Duration) writers.DedupCache {
+	return dedupCache{
+		client:   client,
+		consumer: consumer,
+		window:   window,
+	}
+}
+
+func (dc dedupCache) Seen(key string) (bool, error) {
+	key = fmt.Sprintf("%s:%s:%s", dedupPrefix, dc.consumer, key)
+	set, err := dc.client.SetNX(context.Background(), key, 1, dc.window).Result()
+	if err != nil {
+		return false, err
+	}
+	return !set, nil
+}
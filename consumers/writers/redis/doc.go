@@ -0,0 +1,7 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redis contains the things event stream subscriber and the channel
+// repository implementation used by the writers routing, as well as the
+// messages deduplication cache and the protobuf schema repository.
+package redis
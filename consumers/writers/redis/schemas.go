@@ -0,0 +1,57 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
+)
+
+const schemasKey = "writers:protobuf:schemas"
+
+var _ protobuf.SchemaRepository = (*schemaRepository)(nil)
+
+type schemaRepository struct {
+	client *redis.Client
+}
+
+// NewSchemaRepository returns Redis repository of the protobuf schemas, kept
+// in a single hash keyed by the channel ID, so that all the writers share
+// the registered schemas.
+func NewSchemaRepository(client *redis.Client) protobuf.SchemaRepository {
+	return schemaRepository{
+		client: client,
+	}
+}
+
+func (sr schemaRepository) Save(s protobuf.Schema) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return sr.client.HSet(context.Background(), schemasKey, s.Channel, data).Err()
+}
+
+func (sr schemaRepository) Retrieve(channel string) (protobuf.Schema, error) {
+	data, err := sr.client.HGet(context.Background(), schemasKey, channel).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return protobuf.Schema{}, protobuf.ErrNotFound
+		}
+		return protobuf.Schema{}, err
+	}
+
+	var s protobuf.Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return protobuf.Schema{}, err
+	}
+	return s, nil
+}
+
+func (sr schemaRepository) Remove(channel string) error {
+	return sr.client.HDel(context.Background(), schemasKey, channel).Err()
+}
@@ -0,0 +1,123 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/consumers/writers"
+	"github.com/mainflux/mainflux/logger"
+)
+
+const (
+	stream      = "mainflux.things"
+	groupPrefix = "mainflux."
+
+	keyType   = "writer"
+	keyTarget = "target"
+
+	channelPrefix = "channel."
+	channelCreate = channelPrefix + "create"
+	channelUpdate = channelPrefix + "update"
+	channelRemove = channelPrefix + "remove"
+
+	exists = "BUSYGROUP Consumer Group name already exists"
+)
+
+// Subscriber represents event source for channels provisioning.
+type Subscriber interface {
+	// Subscribe subscribes to the things event stream and receives events.
+	Subscribe(context.Context) error
+}
+
+type eventStore struct {
+	router   writers.Router
+	client   *redis.Client
+	group    string
+	consumer string
+	logger   logger.Logger
+}
+
+// NewEventStore returns new event store instance which keeps the router
+// channels up to date. The consumer name is used as the consumer group name
+// as well, so it has to be unique per writer instance in order for each of
+// the writers to receive all the events.
+func NewEventStore(router writers.Router, client *redis.Client, consumer string, logger logger.Logger) Subscriber {
+	return eventStore{
+		router:   router,
+		client:   client,
+		group:    groupPrefix + consumer,
+		consumer: consumer,
+		logger:   logger,
+	}
+}
+
+func (es eventStore) Subscribe(ctx context.Context) error {
+	// The group is read from the beginning of the stream, so that the
+	// channels created before the writer has been started are routed.
+	err := es.client.XGroupCreateMkStream(ctx, stream, es.group, "0").Err()
+	if err != nil && err.Error() != exists {
+		return err
+	}
+
+	for {
+		streams, err := es.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    es.group,
+			Consumer: es.consumer,
+			Streams:  []string{stream, ">"},
+			Count:    100,
+		}).Result()
+		if err != nil || len(streams) == 0 {
+			continue
+		}
+
+		for _, msg := range streams[0].Messages {
+			event := msg.Values
+
+			var err error
+			switch event["operation"] {
+			case channelCreate, channelUpdate:
+				err = es.router.SaveChannel(decodeChannel(event))
+			case channelRemove:
+				err = es.router.RemoveChannel(read(event, "id", ""))
+			}
+			if err != nil {
+				es.logger.Warn(fmt.Sprintf("Failed to handle event sourcing: %s", err.Error()))
+				break
+			}
+			es.client.XAck(ctx, stream, es.group, msg.ID)
+		}
+	}
+}
+
+// decodeChannel decodes the channel create and update events. The target is
+// read from the channel metadata, e.g. {"writer": {"target": "tenant"}}.
+func decodeChannel(event map[string]interface{}) writers.Channel {
+	ch := writers.Channel{
+		ID:    read(event, "id", ""),
+		Owner: read(event, "owner", ""),
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(read(event, "metadata", "{}")), &metadata); err != nil {
+		return ch
+	}
+	if wm, ok := metadata[keyType].(map[string]interface{}); ok {
+		ch.Target, _ = wm[keyTarget].(string)
+	}
+
+	return ch
+}
+
+func read(event map[string]interface{}, key, def string) string {
+	val, ok := event[key].(string)
+	if !ok {
+		return def
+	}
+
+	return val
+}
@@ -0,0 +1,66 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var _ consumers.Consumer = (*retryConsumer)(nil)
+
+type retryConsumer struct {
+	consumer   consumers.Consumer
+	deadLetter DeadLetter
+	interval   time.Duration
+	maxElapsed time.Duration
+	logger     logger.Logger
+}
+
+// NewRetryConsumer wraps the consumer with retrying failed saves using
+// exponential backoff, starting with the given interval, until the max elapsed
+// time is reached. Max elapsed time 0 disables retrying. Once the retries are
+// exhausted, messages are published to the dead letter, if it's not nil.
+// Since retries block consuming, the consumer should be wrapped with the batch
+// consumer if the retry time is long.
+func NewRetryConsumer(consumer consumers.Consumer, deadLetter DeadLetter, interval, maxElapsed time.Duration, logger logger.Logger) consumers.Consumer {
+	if maxElapsed <= 0 && deadLetter == nil {
+		return consumer
+	}
+
+	return &retryConsumer{
+		consumer:   consumer,
+		deadLetter: deadLetter,
+		interval:   interval,
+		maxElapsed: maxElapsed,
+		logger:     logger,
+	}
+}
+
+func (rc *retryConsumer) Consume(messages interface{}) error {
+	err := rc.consumer.Consume(messages)
+	if err != nil && rc.maxElapsed > 0 {
+		b := backoff.NewExponentialBackOff()
+		b.InitialInterval = rc.interval
+		b.MaxElapsedTime = rc.maxElapsed
+		err = backoff.Retry(func() error {
+			return rc.consumer.Consume(messages)
+		}, b)
+	}
+	if err == nil || rc.deadLetter == nil {
+		return err
+	}
+
+	if dlErr := rc.deadLetter.Publish(messages, err); dlErr != nil {
+		return errors.Wrap(err, dlErr)
+	}
+	rc.logger.Warn(fmt.Sprintf("Messages published to dead letter after failing to save: %s", err))
+
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/writers"
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testLog, _ = log.New(os.Stdout, log.Info.String())
+	errSave    = errors.New("failed to save")
+)
+
+// failingConsumer fails the given number of times before succeeding.
+type failingConsumer struct {
+	failures int
+	calls    int
+}
+
+func (fc *failingConsumer) Consume(msgs interface{}) error {
+	fc.calls++
+	if fc.calls <= fc.failures {
+		return errSave
+	}
+	return nil
+}
+
+type deadLetterMock struct {
+	messages []interface{}
+	errs     []error
+}
+
+func (dl *deadLetterMock) Publish(msgs interface{}, err error) error {
+	dl.messages = append(dl.messages, msgs)
+	dl.errs = append(dl.errs, err)
+	return nil
+}
+
+func (dl *deadLetterMock) Close() {}
+
+func TestRetry(t *testing.T) {
+	msgs := []senml.Message{{Name: "name"}}
+
+	cases := []struct {
+		desc       string
+		failures   int
+		maxElapsed time.Duration
+		deadLetter bool
+		calls      int
+		dead       int
+		err        error
+	}{
+		{
+			desc:       "save messages on the first attempt",
+			failures:   0,
+			maxElapsed: time.Second,
+			deadLetter: true,
+			calls:      1,
+			dead:       0,
+			err:        nil,
+		},
+		{
+			desc:       "save messages after retries",
+			failures:   2,
+			maxElapsed: time.Second,
+			deadLetter: true,
+			calls:      3,
+			dead:       0,
+			err:        nil,
+		},
+		{
+			desc:       "publish messages to dead letter after retries",
+			failures:   1000,
+			maxElapsed: 100 * time.Millisecond,
+			deadLetter: true,
+			dead:       1,
+			err:        nil,
+		},
+		{
+			desc:       "publish messages to dead letter without retries",
+			failures:   1,
+			maxElapsed: 0,
+			deadLetter: true,
+			calls:      1,
+			dead:       1,
+			err:        nil,
+		},
+		{
+			desc:       "fail to save messages without dead letter",
+			failures:   1000,
+			maxElapsed: 100 * time.Millisecond,
+			deadLetter: false,
+			err:        errSave,
+		},
+	}
+
+	for _, tc := range cases {
+		fc := &failingConsumer{failures: tc.failures}
+		dl := &deadLetterMock{}
+		var deadLetter writers.DeadLetter
+		if tc.deadLetter {
+			deadLetter = dl
+		}
+
+		c := writers.NewRetryConsumer(fc, deadLetter, 10*time.Millisecond, tc.maxElapsed, testLog)
+		err := c.Consume(msgs)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if tc.calls > 0 {
+			assert.Equal(t, tc.calls, fc.calls, fmt.Sprintf("%s: expected %d attempts got %d\n", tc.desc, tc.calls, fc.calls))
+		}
+		assert.Len(t, dl.messages, tc.dead, fmt.Sprintf("%s: expected %d dead letters got %d\n", tc.desc, tc.dead, len(dl.messages)))
+		for i := range dl.messages {
+			assert.Equal(t, msgs, dl.messages[i], fmt.Sprintf("%s: expected dead letter messages %v got %v\n", tc.desc, msgs, dl.messages[i]))
+			assert.Equal(t, errSave, dl.errs[i], fmt.Sprintf("%s: expected dead letter error %s got %s\n", tc.desc, errSave, dl.errs[i]))
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"io/ioutil"
+	"sync"
+
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/pelletier/go-toml"
+)
+
+var (
+	errOpenRoutesFile  = errors.New("unable to open routes configuration file")
+	errParseRoutesFile = errors.New("unable to parse routes configuration file")
+	errRouteTarget     = errors.New("failed to create route target consumer")
+)
+
+// Route maps the channels and the channel owners to the target. The meaning of
+// the target depends on the writer, e.g. it's the database for Postgres and
+// the keyspace for Cassandra writer.
+type Route struct {
+	Target   string   `toml:"target"`
+	Channels []string `toml:"channels"`
+	Owners   []string `toml:"owners"`
+}
+
+type routesConfig struct {
+	Routes []Route `toml:"routes"`
+}
+
+// LoadRoutes loads the routes from the "routes" section of the configuration
+// file which contains the subjects list.
+func LoadRoutes(path string) ([]Route, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(errOpenRoutesFile, err)
+	}
+
+	var cfg routesConfig
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return nil, errors.Wrap(errParseRoutesFile, err)
+	}
+
+	return cfg.Routes, nil
+}
+
+// Channel contains the channel data used for routing, which is received
+// from the things event stream.
+type Channel struct {
+	ID    string `json:"id"`
+	Owner string `json:"owner,omitempty"`
+	// Target is set using the channel metadata and takes precedence
+	// over the configured routes.
+	Target string `json:"target,omitempty"`
+}
+
+// ChannelRepository specifies the channels persistence API.
+type ChannelRepository interface {
+	// Save persists the channel.
+	Save(ch Channel) error
+
+	// Remove removes the channel.
+	Remove(id string) error
+
+	// RetrieveAll retrieves all the channels.
+	RetrieveAll() ([]Channel, error)
+}
+
+// Router resolves the targets of the channels.
+type Router interface {
+	// Target returns the target of the channel, or an empty string if the
+	// channel is not routed.
+	Target(channel string) string
+
+	// SaveChannel saves the channel data used for routing.
+	SaveChannel(ch Channel) error
+
+	// RemoveChannel removes the channel data used for routing.
+	RemoveChannel(id string) error
+}
+
+var _ Router = (*router)(nil)
+
+type router struct {
+	mu       sync.RWMutex
+	channels map[string]string
+	owners   map[string]string
+	known    map[string]Channel
+	repo     ChannelRepository
+}
+
+// NewRouter returns the router which resolves the targets using the routes
+// and the channels saved to the repository. The channels are loaded from the
+// repository on creation. If the repository is nil, the channels are kept
+// in memory only.
+func NewRouter(routes []Route, repo ChannelRepository) (Router, error) {
+	r := &router{
+		channels: make(map[string]string),
+		owners:   make(map[string]string),
+		known:    make(map[string]Channel),
+		repo:     repo,
+	}
+	for _, route := range routes {
+		for _, ch := range route.Channels {
+			r.channels[ch] = route.Target
+		}
+		for _, o := range route.Owners {
+			r.owners[o] = route.Target
+		}
+	}
+
+	if repo == nil {
+		return r, nil
+	}
+	chs, err := repo.RetrieveAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range chs {
+		r.known[ch.ID] = ch
+	}
+
+	return r, nil
+}
+
+func (r *router) Target(channel string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ch := r.known[channel]
+	if ch.Target != "" {
+		return ch.Target
+	}
+	if t, ok := r.channels[channel]; ok {
+		return t
+	}
+	return r.owners[ch.Owner]
+}
+
+func (r *router) SaveChannel(ch Channel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Channel update events don't contain the owner.
+	if ch.Owner == "" {
+		ch.Owner = r.known[ch.ID].Owner
+	}
+	if r.repo != nil {
+		if err := r.repo.Save(ch); err != nil {
+			return err
+		}
+	}
+	r.known[ch.ID] = ch
+
+	return nil
+}
+
+func (r *router) RemoveChannel(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.repo != nil {
+		if err := r.repo.Remove(id); err != nil {
+			return err
+		}
+	}
+	delete(r.known, id)
+
+	return nil
+}
+
+// ConsumerFactory creates the consumer which saves the messages to the target.
+type ConsumerFactory func(target string) (consumers.Consumer, error)
+
+var _ consumers.Consumer = (*routerConsumer)(nil)
+
+type routerConsumer struct {
+	mu      sync.Mutex
+	def     consumers.Consumer
+	router  Router
+	factory ConsumerFactory
+	targets map[string]consumers.Consumer
+}
+
+// NewRouterConsumer returns the consumer which passes the messages of the
+// routed channels to the consumers of their targets, while the rest of the
+// messages are passed to the default consumer. Target consumers are created
+// using the factory once the first message is routed to the target. If the
+// router is nil, the default consumer is returned unchanged.
+func NewRouterConsumer(def consumers.Consumer, router Router, factory ConsumerFactory) consumers.Consumer {
+	if router == nil {
+		return def
+	}
+
+	return &routerConsumer{
+		def:     def,
+		router:  router,
+		factory: factory,
+		targets: make(map[string]consumers.Consumer),
+	}
+}
+
+func (rc *routerConsumer) Consume(messages interface{}) error {
+	switch m := messages.(type) {
+	case []senml.Message:
+		var targets []string
+		msgs := make(map[string][]senml.Message)
+		for _, msg := range m {
+			t := rc.router.Target(msg.Channel)
+			if _, ok := msgs[t]; !ok {
+				targets = append(targets, t)
+			}
+			msgs[t] = append(msgs[t], msg)
+		}
+		for _, t := range targets {
+			if err := rc.consume(t, msgs[t]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case json.Messages:
+		var targets []string
+		msgs := make(map[string]json.Messages)
+		for _, msg := range m.Data {
+			t := rc.router.Target(msg.Channel)
+			tm, ok := msgs[t]
+			if !ok {
+				targets = append(targets, t)
+				tm.Format = m.Format
+			}
+			tm.Data = append(tm.Data, msg)
+			msgs[t] = tm
+		}
+		for _, t := range targets {
+			if err := rc.consume(t, msgs[t]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return rc.def.Consume(messages)
+	}
+}
+
+func (rc *routerConsumer) consume(target string, messages interface{}) error {
+	c, err := rc.consumer(target)
+	if err != nil {
+		return err
+	}
+	return c.Consume(messages)
+}
+
+func (rc *routerConsumer) consumer(target string) (consumers.Consumer, error) {
+	if target == "" {
+		return rc.def, nil
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if c, ok := rc.targets[target]; ok {
+		return c, nil
+	}
+	c, err := rc.factory(target)
+	if err != nil {
+		return nil, errors.Wrap(errRouteTarget, err)
+	}
+	rc.targets[target] = c
+
+	return c, nil
+}
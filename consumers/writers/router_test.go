@@ -0,0 +1,179 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/writers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const routesConfig = `
+[subjects]
+filter = ["channels.>"]
+
+[[routes]]
+target = "tenant_a"
+channels = ["ch_a1", "ch_a2"]
+
+[[routes]]
+target = "tenant_b"
+owners = ["owner_b"]
+`
+
+func TestLoadRoutes(t *testing.T) {
+	file, err := ioutil.TempFile("", "config.toml")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(routesConfig)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	file.Close()
+
+	routes, err := writers.LoadRoutes(file.Name())
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	expected := []writers.Route{
+		{Target: "tenant_a", Channels: []string{"ch_a1", "ch_a2"}},
+		{Target: "tenant_b", Owners: []string{"owner_b"}},
+	}
+	assert.Equal(t, expected, routes, "expected loaded routes")
+
+	_, err = writers.LoadRoutes("nonexistent.toml")
+	assert.NotNil(t, err, "expected error loading nonexistent file")
+}
+
+func TestRouterTarget(t *testing.T) {
+	routes := []writers.Route{
+		{Target: "tenant_a", Channels: []string{"ch_a"}},
+		{Target: "tenant_b", Owners: []string{"owner_b"}},
+	}
+	repo := &channelRepoMock{channels: map[string]writers.Channel{
+		"ch_b": {ID: "ch_b", Owner: "owner_b"},
+	}}
+	router, err := writers.NewRouter(routes, repo)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	err = router.SaveChannel(writers.Channel{ID: "ch_meta", Owner: "owner_b", Target: "tenant_c"})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	err = router.SaveChannel(writers.Channel{ID: "ch_a", Owner: "owner_b"})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	cases := []struct {
+		desc    string
+		channel string
+		target  string
+	}{
+		{
+			desc:    "route channel listed in routes",
+			channel: "ch_a",
+			target:  "tenant_a",
+		},
+		{
+			desc:    "route channel loaded from repository by owner",
+			channel: "ch_b",
+			target:  "tenant_b",
+		},
+		{
+			desc:    "route channel using metadata target",
+			channel: "ch_meta",
+			target:  "tenant_c",
+		},
+		{
+			desc:    "don't route unknown channel",
+			channel: "unknown",
+			target:  "",
+		},
+	}
+
+	for _, tc := range cases {
+		target := router.Target(tc.channel)
+		assert.Equal(t, tc.target, target, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.target, target))
+	}
+
+	// Update events don't contain the owner, so the known one is kept.
+	err = router.SaveChannel(writers.Channel{ID: "ch_meta"})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, "tenant_b", router.Target("ch_meta"), "expected channel to be routed by owner")
+	assert.Equal(t, "owner_b", repo.channels["ch_meta"].Owner, "expected owner to be saved")
+
+	err = router.RemoveChannel("ch_b")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, "", router.Target("ch_b"), "expected removed channel not to be routed")
+	assert.NotContains(t, repo.channels, "ch_b", "expected channel to be removed from repository")
+}
+
+func TestRouterConsumer(t *testing.T) {
+	routes := []writers.Route{
+		{Target: "tenant_a", Channels: []string{"ch_a"}},
+		{Target: "invalid", Channels: []string{"ch_invalid"}},
+	}
+	router, err := writers.NewRouter(routes, nil)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	def := &consumerMock{}
+	targets := map[string]*consumerMock{}
+	factory := func(target string) (consumers.Consumer, error) {
+		if target == "invalid" {
+			return nil, errors.New("invalid target")
+		}
+		c := &consumerMock{}
+		targets[target] = c
+		return c, nil
+	}
+	c := writers.NewRouterConsumer(def, router, factory)
+
+	err = c.Consume([]senml.Message{{Channel: "ch_a"}, {Channel: "ch"}, {Channel: "ch_a"}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	err = c.Consume(json.Messages{Format: "some_json", Data: []json.Message{{Channel: "ch_a"}, {Channel: "ch"}}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	require.Len(t, targets, 1, "expected target consumer to be created once")
+	assert.Equal(t, []interface{}{
+		[]senml.Message{{Channel: "ch_a"}, {Channel: "ch_a"}},
+		json.Messages{Format: "some_json", Data: []json.Message{{Channel: "ch_a"}}},
+	}, targets["tenant_a"].consumed(), "expected routed messages")
+	assert.Equal(t, []interface{}{
+		[]senml.Message{{Channel: "ch"}},
+		json.Messages{Format: "some_json", Data: []json.Message{{Channel: "ch"}}},
+	}, def.consumed(), "expected default messages")
+
+	err = c.Consume([]senml.Message{{Channel: "ch_invalid"}})
+	assert.NotNil(t, err, "expected error creating target consumer")
+}
+
+func TestRouterConsumerNil(t *testing.T) {
+	mock := &consumerMock{}
+	c := writers.NewRouterConsumer(mock, nil, nil)
+	assert.Equal(t, mock, c, "expected consumer unchanged for nil router")
+}
+
+type channelRepoMock struct {
+	channels map[string]writers.Channel
+}
+
+func (crm *channelRepoMock) Save(ch writers.Channel) error {
+	crm.channels[ch.ID] = ch
+	return nil
+}
+
+func (crm *channelRepoMock) Remove(id string) error {
+	delete(crm.channels, id)
+	return nil
+}
+
+func (crm *channelRepoMock) RetrieveAll() ([]writers.Channel, error) {
+	var chs []writers.Channel
+	for _, ch := range crm.channels {
+		chs = append(chs, ch)
+	}
+	return chs, nil
+}
@@ -0,0 +1,267 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package s3
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+const (
+	senmlFormat = "messages"
+	dateLayout  = "2006-01-02"
+)
+
+var errSaveMessage = errors.New("failed to save message to s3 storage")
+
+var _ consumers.Consumer = (*s3Repo)(nil)
+
+// partition identifies the Parquet file the messages are written to.
+type partition struct {
+	format  string
+	channel string
+	date    string
+}
+
+type batch map[partition][]interface{}
+
+type s3Repo struct {
+	storage     Storage
+	prefix      string
+	batchSize   int
+	compression string
+	logger      logger.Logger
+	mu          sync.Mutex
+	buf         batch
+	count       int
+	batches     chan batch
+}
+
+// New returns new S3 archival writer. Messages are buffered and periodically
+// flushed to Parquet files, partitioned by format, channel and date, in the
+// <prefix>/<format>/channel=<channel>/date=<yyyy-mm-dd>/ directories. SenML
+// messages use "messages" format. Besides on each flush interval, messages
+// are flushed once the number of buffered messages reaches the batch size.
+// Parquet pages are compressed using the compression codec, if it's set.
+func New(storage Storage, prefix string, batchSize int, flushInterval time.Duration, compression string, logger logger.Logger) consumers.Consumer {
+	repo := &s3Repo{
+		storage:     storage,
+		prefix:      prefix,
+		batchSize:   batchSize,
+		compression: compression,
+		logger:      logger,
+		buf:         batch{},
+		batches:     make(chan batch, 1),
+	}
+
+	go repo.upload()
+	go repo.tick(flushInterval)
+
+	return repo
+}
+
+func (repo *s3Repo) Consume(message interface{}) error {
+	repo.mu.Lock()
+	switch m := message.(type) {
+	case mfjson.Messages:
+		for _, msg := range m.Data {
+			date := time.Unix(0, msg.Created).UTC().Format(dateLayout)
+			repo.add(partition{m.Format, msg.Channel, date}, msg)
+		}
+	case []senml.Message:
+		for _, msg := range m {
+			date := senmlTime(msg.Time).Format(dateLayout)
+			repo.add(partition{senmlFormat, msg.Channel, date}, msg)
+		}
+	default:
+		repo.mu.Unlock()
+		return errSaveMessage
+	}
+	var b batch
+	if repo.count >= repo.batchSize {
+		b = repo.swap()
+	}
+	repo.mu.Unlock()
+
+	// Sending the batch blocks while the previous one is being uploaded,
+	// which slows down consuming when the storage can not keep up.
+	if b != nil {
+		repo.batches <- b
+	}
+
+	return nil
+}
+
+// add appends the message to the buffer. It must be called with the lock held.
+func (repo *s3Repo) add(p partition, msg interface{}) {
+	repo.buf[p] = append(repo.buf[p], msg)
+	repo.count++
+}
+
+// swap replaces the buffer with the empty one and returns the buffered
+// messages. It must be called with the lock held.
+func (repo *s3Repo) swap() batch {
+	b := repo.buf
+	repo.buf = batch{}
+	repo.count = 0
+	return b
+}
+
+func (repo *s3Repo) tick(interval time.Duration) {
+	for range time.Tick(interval) {
+		repo.mu.Lock()
+		var b batch
+		if repo.count > 0 {
+			b = repo.swap()
+		}
+		repo.mu.Unlock()
+
+		if b != nil {
+			repo.batches <- b
+		}
+	}
+}
+
+func (repo *s3Repo) upload() {
+	for b := range repo.batches {
+		for p, msgs := range b {
+			if err := repo.save(p, msgs); err != nil {
+				repo.logger.Error(fmt.Sprintf("Failed to upload %d messages of channel %s: %s", len(msgs), p.channel, err))
+			}
+		}
+	}
+}
+
+func (repo *s3Repo) save(p partition, msgs []interface{}) error {
+	var cols []column
+	switch p.format {
+	case senmlFormat:
+		cols = senmlColumns(msgs)
+	default:
+		var err error
+		if cols, err = jsonColumns(msgs); err != nil {
+			return errors.Wrap(errSaveMessage, err)
+		}
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return errors.Wrap(errSaveMessage, err)
+	}
+	name := fmt.Sprintf("%d-%s.parquet", time.Now().UnixNano(), id)
+	key := path.Join(repo.prefix, p.format, "channel="+p.channel, "date="+p.date, name)
+
+	data, err := encodeParquet(cols, repo.compression)
+	if err != nil {
+		return errors.Wrap(errSaveMessage, err)
+	}
+	if err := repo.storage.Put(key, data); err != nil {
+		return errors.Wrap(errSaveMessage, err)
+	}
+
+	return nil
+}
+
+func senmlColumns(msgs []interface{}) []column {
+	cols := []column{
+		{name: "channel", typ: typeByteArray},
+		{name: "subtopic", typ: typeByteArray},
+		{name: "publisher", typ: typeByteArray},
+		{name: "protocol", typ: typeByteArray},
+		{name: "name", typ: typeByteArray},
+		{name: "unit", typ: typeByteArray},
+		{name: "time", typ: typeDouble},
+		{name: "update_time", typ: typeDouble},
+		{name: "value", typ: typeDouble, optional: true},
+		{name: "string_value", typ: typeByteArray, optional: true},
+		{name: "data_value", typ: typeByteArray, optional: true},
+		{name: "bool_value", typ: typeBoolean, optional: true},
+		{name: "sum", typ: typeDouble, optional: true},
+	}
+
+	for _, m := range msgs {
+		msg := m.(senml.Message)
+		row := []interface{}{
+			msg.Channel,
+			msg.Subtopic,
+			msg.Publisher,
+			msg.Protocol,
+			msg.Name,
+			msg.Unit,
+			msg.Time,
+			msg.UpdateTime,
+			nil, nil, nil, nil, nil,
+		}
+		if msg.Value != nil {
+			row[8] = *msg.Value
+		}
+		if msg.StringValue != nil {
+			row[9] = *msg.StringValue
+		}
+		if msg.DataValue != nil {
+			row[10] = *msg.DataValue
+		}
+		if msg.BoolValue != nil {
+			row[11] = *msg.BoolValue
+		}
+		if msg.Sum != nil {
+			row[12] = *msg.Sum
+		}
+		for i, v := range row {
+			cols[i].values = append(cols[i].values, v)
+		}
+	}
+
+	return cols
+}
+
+// jsonColumns stores JSON payload as a JSON encoded string, since payloads
+// of the same format are not guaranteed to share the same structure.
+func jsonColumns(msgs []interface{}) ([]column, error) {
+	cols := []column{
+		{name: "channel", typ: typeByteArray},
+		{name: "created", typ: typeInt64},
+		{name: "subtopic", typ: typeByteArray},
+		{name: "publisher", typ: typeByteArray},
+		{name: "protocol", typ: typeByteArray},
+		{name: "payload", typ: typeByteArray},
+	}
+
+	for _, m := range msgs {
+		msg := m.(mfjson.Message)
+		payload, err := json.Marshal(msg.Payload)
+		if err != nil {
+			return nil, err
+		}
+		row := []interface{}{
+			msg.Channel,
+			msg.Created,
+			msg.Subtopic,
+			msg.Publisher,
+			msg.Protocol,
+			string(payload),
+		}
+		for i, v := range row {
+			cols[i].values = append(cols[i].values, v)
+		}
+	}
+
+	return cols, nil
+}
+
+func senmlTime(t float64) time.Time {
+	sec, dec := math.Modf(t)
+	return time.Unix(int64(sec), int64(dec*1e9)).UTC()
+}
@@ -0,0 +1,243 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package s3_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	writer "github.com/mainflux/mainflux/consumers/writers/s3"
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/compression"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	bucket        = "archive"
+	prefix        = "mainflux"
+	msgsNum       = 42
+	batchSize     = 10
+	flushInterval = 100 * time.Millisecond
+)
+
+var (
+	testLog, _         = log.New(os.Stdout, log.Info.String())
+	v          float64 = 5
+	boolV              = true
+)
+
+// objectStore mocks S3 storage, keeping the uploaded objects by path.
+type objectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *objectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	hash := sha256.Sum256(body)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") ||
+		r.Header.Get("x-amz-content-sha256") != hex.EncodeToString(hash[:]) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodHead {
+		if r.URL.Path != "/"+bucket {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return
+	}
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	s.objects[r.URL.Path] = body
+	s.mu.Unlock()
+}
+
+func (s *objectStore) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var keys []string
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func newStorage(url string) writer.Storage {
+	return writer.NewStorage(writer.Config{
+		Endpoint:  url,
+		Region:    "us-east-1",
+		Bucket:    bucket,
+		AccessKey: "key",
+		SecretKey: "secret",
+		Timeout:   time.Second,
+	})
+}
+
+func TestSaveSenml(t *testing.T) {
+	store := &objectStore{objects: map[string][]byte{}}
+	ts := httptest.NewServer(store)
+	defer ts.Close()
+
+	repo := writer.New(newStorage(ts.URL), prefix, batchSize, flushInterval, "", testLog)
+
+	chid, err := uuid.NewV4()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages span two days.
+	day := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	var msgs []senml.Message
+	for i := 0; i < msgsNum; i++ {
+		msg := senml.Message{
+			Channel:   chid.String(),
+			Publisher: "publisher",
+			Name:      "name",
+			Time:      float64(day.Unix() + int64(i*3600)),
+		}
+		switch i % 2 {
+		case 0:
+			msg.Value = &v
+		case 1:
+			msg.BoolValue = &boolV
+		}
+		msgs = append(msgs, msg)
+	}
+
+	err = repo.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	// Wait for the messages to be flushed.
+	time.Sleep(5 * flushInterval)
+
+	keys := store.keys()
+	assert.Len(t, keys, 2, fmt.Sprintf("expected 2 objects got %d\n", len(keys)))
+	for _, date := range []string{"2021-09-01", "2021-09-02"} {
+		dir := fmt.Sprintf("/%s/%s/messages/channel=%s/date=%s/", bucket, prefix, chid, date)
+		found := false
+		for _, k := range keys {
+			if strings.HasPrefix(k, dir) && strings.HasSuffix(k, ".parquet") {
+				found = true
+				data := store.objects[k]
+				assert.True(t, bytes.HasPrefix(data, []byte("PAR1")) && bytes.HasSuffix(data, []byte("PAR1")), "expected Parquet file")
+			}
+		}
+		assert.True(t, found, fmt.Sprintf("expected object in %s\n", dir))
+	}
+}
+
+func TestSaveJSON(t *testing.T) {
+	store := &objectStore{objects: map[string][]byte{}}
+	ts := httptest.NewServer(store)
+	defer ts.Close()
+
+	// Flush interval is long, so only the full batch is flushed.
+	repo := writer.New(newStorage(ts.URL), prefix, batchSize, time.Hour, "", testLog)
+
+	msgs := json.Messages{
+		Format: "some_json",
+	}
+	for i := 0; i < batchSize-1; i++ {
+		msgs.Data = append(msgs.Data, json.Message{
+			Channel: "channel",
+			Created: time.Now().UnixNano(),
+			Payload: map[string]interface{}{"field": i},
+		})
+	}
+
+	err := repo.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	time.Sleep(flushInterval)
+	assert.Len(t, store.keys(), 0, "expected no objects before the batch is full")
+
+	msgs.Data = msgs.Data[:1]
+	err = repo.Consume(msgs)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	time.Sleep(flushInterval)
+
+	keys := store.keys()
+	require.Len(t, keys, 1, fmt.Sprintf("expected 1 object got %d\n", len(keys)))
+	dir := fmt.Sprintf("/%s/%s/some_json/channel=channel/", bucket, prefix)
+	assert.True(t, strings.HasPrefix(keys[0], dir), fmt.Sprintf("expected object in %s got %s\n", dir, keys[0]))
+}
+
+func TestSaveCompressed(t *testing.T) {
+	msgs := json.Messages{
+		Format: "some_json",
+	}
+	for i := 0; i < batchSize; i++ {
+		msgs.Data = append(msgs.Data, json.Message{
+			Channel:   "channel",
+			Publisher: "publisher",
+			Created:   time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC).UnixNano(),
+			Payload:   map[string]interface{}{"field": "value"},
+		})
+	}
+
+	sizes := map[string]int{}
+	for _, codec := range []string{compression.None, compression.Gzip, compression.Snappy, compression.Zstd} {
+		store := &objectStore{objects: map[string][]byte{}}
+		ts := httptest.NewServer(store)
+
+		repo := writer.New(newStorage(ts.URL), prefix, batchSize, time.Hour, codec, testLog)
+		err := repo.Consume(msgs)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s\n", codec, err))
+		time.Sleep(flushInterval)
+		ts.Close()
+
+		keys := store.keys()
+		require.Len(t, keys, 1, fmt.Sprintf("%s: expected 1 object got %d\n", codec, len(keys)))
+		data := store.objects[keys[0]]
+		assert.True(t, bytes.HasPrefix(data, []byte("PAR1")) && bytes.HasSuffix(data, []byte("PAR1")), fmt.Sprintf("%s: expected Parquet file", codec))
+		sizes[codec] = len(data)
+	}
+
+	for _, codec := range []string{compression.Gzip, compression.Snappy, compression.Zstd} {
+		assert.Less(t, sizes[codec], sizes[compression.None], fmt.Sprintf("%s: expected compressed file to be smaller", codec))
+	}
+}
+
+func TestSaveUnsupported(t *testing.T) {
+	repo := writer.New(newStorage("http://localhost"), prefix, batchSize, flushInterval, "", testLog)
+
+	err := repo.Consume("message")
+	assert.NotNil(t, err, "expected error saving unsupported message")
+}
+
+func TestPing(t *testing.T) {
+	store := &objectStore{objects: map[string][]byte{}}
+	ts := httptest.NewServer(store)
+	defer ts.Close()
+
+	err := newStorage(ts.URL).Ping()
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	err = writer.NewStorage(writer.Config{
+		Endpoint:  ts.URL,
+		Region:    "us-east-1",
+		Bucket:    "nonexistent",
+		AccessKey: "key",
+		SecretKey: "secret",
+		Timeout:   time.Second,
+	}).Ping()
+	assert.True(t, errors.Contains(err, writer.ErrPing), fmt.Sprintf("expected %s got %s\n", writer.ErrPing, err))
+}
@@ -0,0 +1,6 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package s3 contains archival writer implementation which stores messages
+// as Parquet files in S3 compatible object storage.
+package s3
@@ -0,0 +1,338 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package s3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/mainflux/mainflux/pkg/compression"
+)
+
+// Parquet file is written as a single row group with a single PLAIN encoded
+// data page per column, compressed using the configured codec. Metadata is
+// serialized using Thrift compact protocol, as described in
+// https://github.com/apache/parquet-format.
+
+var parquetMagic = []byte("PAR1")
+
+// Parquet physical types.
+const (
+	typeBoolean   int32 = 0
+	typeInt64     int32 = 2
+	typeDouble    int32 = 5
+	typeByteArray int32 = 6
+)
+
+const (
+	repRequired      int32 = 0
+	repOptional      int32 = 1
+	convertedUTF8    int32 = 0
+	encodingPlain    int32 = 0
+	encodingRLE      int32 = 3
+	codecNone        int32 = 0
+	codecSnappy      int32 = 1
+	codecGzip        int32 = 2
+	codecZstd        int32 = 6
+	pageTypeData     int32 = 0
+	parquetVersion   int32 = 1
+	parquetCreatedBy       = "mainflux s3-writer"
+)
+
+// column contains the values of a single column. Values are float64, int64,
+// bool or string, depending on the column type, and nil values are allowed
+// in the optional columns only.
+type column struct {
+	name     string
+	typ      int32
+	optional bool
+	values   []interface{}
+}
+
+// Parquet codecs by the compression codec name.
+var parquetCodecs = map[string]int32{
+	compression.None:   codecNone,
+	compression.Snappy: codecSnappy,
+	compression.Gzip:   codecGzip,
+	compression.Zstd:   codecZstd,
+}
+
+// encodeParquet encodes the columns of equal length to Parquet file, with
+// the pages compressed using the codec.
+func encodeParquet(cols []column, codec string) ([]byte, error) {
+	var rows int
+	if len(cols) > 0 {
+		rows = len(cols[0].values)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(parquetMagic)
+
+	var chunks []columnChunk
+	var total int64
+	for _, col := range cols {
+		page := encodePage(col)
+		compressed, err := compressPage(codec, page)
+		if err != nil {
+			return nil, err
+		}
+
+		var hdr thriftWriter
+		hdr.fieldI32(1, pageTypeData)
+		hdr.fieldI32(2, int32(len(page)))
+		hdr.fieldI32(3, int32(len(compressed)))
+		hdr.fieldStruct(5)
+		hdr.fieldI32(1, int32(rows))
+		hdr.fieldI32(2, encodingPlain)
+		hdr.fieldI32(3, encodingRLE)
+		hdr.fieldI32(4, encodingRLE)
+		hdr.stop()
+		hdr.stop()
+
+		offset := int64(buf.Len())
+		buf.Write(hdr.Bytes())
+		buf.Write(compressed)
+
+		size := int64(hdr.Len() + len(compressed))
+		total += size
+		chunks = append(chunks, columnChunk{
+			col:          col,
+			offset:       offset,
+			size:         size,
+			uncompressed: int64(hdr.Len() + len(page)),
+		})
+	}
+
+	meta := encodeMetadata(cols, chunks, parquetCodecs[codec], int64(rows), total)
+	buf.Write(meta)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(meta)))
+	buf.Write(parquetMagic)
+
+	return buf.Bytes(), nil
+}
+
+// compressPage compresses the page. Parquet uses Snappy block format, while
+// the other codecs use the same format as the compression package.
+func compressPage(codec string, page []byte) ([]byte, error) {
+	if codec == compression.Snappy {
+		return snappy.Encode(nil, page), nil
+	}
+	return compression.Compress(codec, page)
+}
+
+type columnChunk struct {
+	col          column
+	offset       int64
+	size         int64
+	uncompressed int64
+}
+
+func encodeMetadata(cols []column, chunks []columnChunk, codec int32, rows, total int64) []byte {
+	var w thriftWriter
+	w.fieldI32(1, parquetVersion)
+
+	// Schema is flattened, with the root element followed by columns.
+	w.fieldList(2, thriftStruct, len(cols)+1)
+	w.begin()
+	w.fieldString(4, "schema")
+	w.fieldI32(5, int32(len(cols)))
+	w.stop()
+	for _, col := range cols {
+		w.begin()
+		w.fieldI32(1, col.typ)
+		rep := repRequired
+		if col.optional {
+			rep = repOptional
+		}
+		w.fieldI32(3, rep)
+		w.fieldString(4, col.name)
+		if col.typ == typeByteArray {
+			w.fieldI32(6, convertedUTF8)
+		}
+		w.stop()
+	}
+
+	w.fieldI64(3, rows)
+
+	w.fieldList(4, thriftStruct, 1)
+	w.begin()
+	w.fieldList(1, thriftStruct, len(chunks))
+	for _, ch := range chunks {
+		w.begin()
+		w.fieldI64(2, ch.offset)
+		w.fieldStruct(3)
+		w.fieldI32(1, ch.col.typ)
+		w.fieldList(2, thriftI32, 2)
+		w.i32(encodingPlain)
+		w.i32(encodingRLE)
+		w.fieldList(3, thriftBinary, 1)
+		w.string(ch.col.name)
+		w.fieldI32(4, codec)
+		w.fieldI64(5, rows)
+		w.fieldI64(6, ch.uncompressed)
+		w.fieldI64(7, ch.size)
+		w.fieldI64(9, ch.offset)
+		w.stop()
+		w.stop()
+	}
+	w.fieldI64(2, total)
+	w.fieldI64(3, rows)
+	w.stop()
+
+	w.fieldString(6, parquetCreatedBy)
+	w.stop()
+
+	return w.Bytes()
+}
+
+// encodePage encodes definition levels, if the column is optional, followed
+// by PLAIN encoded non-nil values.
+func encodePage(col column) []byte {
+	var buf bytes.Buffer
+	if col.optional {
+		levels := encodeLevels(col.values)
+		binary.Write(&buf, binary.LittleEndian, uint32(len(levels)))
+		buf.Write(levels)
+	}
+
+	var bits []bool
+	for _, v := range col.values {
+		switch v := v.(type) {
+		case float64:
+			binary.Write(&buf, binary.LittleEndian, math.Float64bits(v))
+		case int64:
+			binary.Write(&buf, binary.LittleEndian, v)
+		case string:
+			binary.Write(&buf, binary.LittleEndian, uint32(len(v)))
+			buf.WriteString(v)
+		case bool:
+			bits = append(bits, v)
+		}
+	}
+	if col.typ == typeBoolean {
+		buf.Write(packBits(bits))
+	}
+
+	return buf.Bytes()
+}
+
+// encodeLevels encodes definition levels as a single bit-packed run of the
+// RLE/bit-packing hybrid encoding with bit width 1.
+func encodeLevels(values []interface{}) []byte {
+	defined := make([]bool, len(values))
+	for i, v := range values {
+		defined[i] = v != nil
+	}
+
+	packed := packBits(defined)
+	header := uint64(len(packed))<<1 | 1
+
+	var buf bytes.Buffer
+	writeUvarint(&buf, header)
+	buf.Write(packed)
+	return buf.Bytes()
+}
+
+// packBits packs the values into bytes, starting from the least significant
+// bit, padded to the multiple of 8 values.
+func packBits(values []bool) []byte {
+	packed := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			packed[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return packed
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	b := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(b, v)
+	buf.Write(b[:n])
+}
+
+// Thrift compact protocol types.
+const (
+	thriftI32    byte = 5
+	thriftI64    byte = 6
+	thriftBinary byte = 8
+	thriftList   byte = 9
+	thriftStruct byte = 12
+)
+
+// thriftWriter writes Thrift compact protocol structs. Nested structs are
+// started with begin or fieldStruct and ended with stop.
+type thriftWriter struct {
+	bytes.Buffer
+	last  int16
+	stack []int16
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	if delta := id - w.last; delta > 0 && delta <= 15 {
+		w.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.WriteByte(typ)
+		w.i32(int32(id))
+	}
+	w.last = id
+}
+
+func (w *thriftWriter) begin() {
+	w.stack = append(w.stack, w.last)
+	w.last = 0
+}
+
+func (w *thriftWriter) stop() {
+	w.WriteByte(0)
+	if n := len(w.stack); n > 0 {
+		w.last = w.stack[n-1]
+		w.stack = w.stack[:n-1]
+	}
+}
+
+func (w *thriftWriter) i32(v int32) {
+	writeUvarint(&w.Buffer, uint64(uint32((v<<1)^(v>>31))))
+}
+
+func (w *thriftWriter) i64(v int64) {
+	writeUvarint(&w.Buffer, uint64((v<<1)^(v>>63)))
+}
+
+func (w *thriftWriter) string(v string) {
+	writeUvarint(&w.Buffer, uint64(len(v)))
+	w.WriteString(v)
+}
+
+func (w *thriftWriter) fieldI32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.i32(v)
+}
+
+func (w *thriftWriter) fieldI64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.i64(v)
+}
+
+func (w *thriftWriter) fieldString(id int16, v string) {
+	w.field(id, thriftBinary)
+	w.string(v)
+}
+
+func (w *thriftWriter) fieldStruct(id int16) {
+	w.field(id, thriftStruct)
+	w.begin()
+}
+
+func (w *thriftWriter) fieldList(id int16, elem byte, size int) {
+	w.field(id, thriftList)
+	if size < 15 {
+		w.WriteByte(byte(size)<<4 | elem)
+		return
+	}
+	w.WriteByte(0xF0 | elem)
+	writeUvarint(&w.Buffer, uint64(size))
+}
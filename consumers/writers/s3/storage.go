@@ -0,0 +1,169 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package s3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	amzDateLayout = "20060102T150405Z"
+	amzDayLayout  = "20060102"
+	signAlgorithm = "AWS4-HMAC-SHA256"
+	signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+)
+
+var (
+	// ErrPut indicates failure to upload the object.
+	ErrPut = errors.New("failed to put object to s3 storage")
+
+	// ErrPing indicates failure to access the bucket.
+	ErrPing = errors.New("failed to access s3 bucket")
+)
+
+// Config defines the options that are used when connecting to S3 compatible
+// object storage.
+type Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Timeout   time.Duration
+}
+
+// Storage represents S3 compatible object storage.
+type Storage interface {
+	// Put uploads the object with the given key.
+	Put(key string, data []byte) error
+
+	// Ping checks if the bucket exists and is accessible.
+	Ping() error
+}
+
+type storage struct {
+	cfg  Config
+	http *http.Client
+}
+
+// NewStorage returns S3 compatible object storage. Objects are addressed
+// using path style URLs and requests are signed using AWS Signature Version 4,
+// which is supported by AWS S3 and self-hosted storages such as MinIO.
+func NewStorage(cfg Config) Storage {
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	return storage{
+		cfg:  cfg,
+		http: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (s storage) Put(key string, data []byte) error {
+	path := fmt.Sprintf("/%s/%s", s.cfg.Bucket, strings.TrimPrefix(key, "/"))
+	if err := s.do(http.MethodPut, path, data); err != nil {
+		return errors.Wrap(ErrPut, err)
+	}
+
+	return nil
+}
+
+func (s storage) Ping() error {
+	if err := s.do(http.MethodHead, "/"+s.cfg.Bucket, nil); err != nil {
+		return errors.Wrap(ErrPing, err)
+	}
+
+	return nil
+}
+
+func (s storage) do(method, path string, data []byte) error {
+	req, err := http.NewRequest(method, s.cfg.Endpoint+escapePath(path), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	s.sign(req, data, time.Now().UTC())
+
+	res, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("%s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+func (s storage) sign(req *http.Request, payload []byte, t time.Time) {
+	hash := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(hash[:])
+	date := t.Format(amzDateLayout)
+
+	req.Header.Set("x-amz-date", date)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.URL.Host, payloadHash, date),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonical))
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", t.Format(amzDayLayout), s.cfg.Region)
+	toSign := strings.Join([]string{
+		signAlgorithm,
+		date,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), t.Format(amzDayLayout))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, s.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escapePath escapes all the characters except the unreserved ones and the
+// slashes, as required by the canonical request.
+func escapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if unreserved(c) || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func unreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '_' || c == '.' || c == '~'
+}
@@ -0,0 +1,83 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/lua"
+	"github.com/pelletier/go-toml"
+)
+
+var (
+	errOpenScriptsFile  = errors.New("unable to open scripts configuration file")
+	errParseScriptsFile = errors.New("unable to parse scripts configuration file")
+	errReadScript       = errors.New("unable to read script")
+	errNoScripts        = errors.New("no scripts configured")
+)
+
+type scripts struct {
+	Default  string            `toml:"default"`
+	Timeout  string            `toml:"timeout"`
+	Channels map[string]string `toml:"channels"`
+}
+
+type scriptsConfig struct {
+	Scripts scripts `toml:"scripts"`
+}
+
+// LoadScripts loads the Lua transformer scripts listed in the "scripts"
+// section of the configuration file which contains the subjects list. The
+// relative script paths are resolved against the configuration file
+// directory.
+func LoadScripts(path string) (lua.Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return lua.Config{}, errors.Wrap(errOpenScriptsFile, err)
+	}
+
+	var cfg scriptsConfig
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return lua.Config{}, errors.Wrap(errParseScriptsFile, err)
+	}
+	s := cfg.Scripts
+	if s.Default == "" && len(s.Channels) == 0 {
+		return lua.Config{}, errNoScripts
+	}
+
+	ret := lua.Config{Channels: make(map[string]string)}
+	if s.Timeout != "" {
+		if ret.Timeout, err = time.ParseDuration(s.Timeout); err != nil {
+			return lua.Config{}, errors.Wrap(errParseScriptsFile, err)
+		}
+	}
+
+	dir := filepath.Dir(path)
+	if s.Default != "" {
+		if ret.Script, err = readScript(dir, s.Default); err != nil {
+			return lua.Config{}, err
+		}
+	}
+	for ch, p := range s.Channels {
+		if ret.Channels[ch], err = readScript(dir, p); err != nil {
+			return lua.Config{}, err
+		}
+	}
+
+	return ret, nil
+}
+
+func readScript(dir, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(errReadScript, err)
+	}
+	return string(data), nil
+}
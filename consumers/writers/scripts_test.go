@@ -0,0 +1,62 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/writers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	scriptsConfig = `
+[subjects]
+filter = ["channels.>"]
+
+[scripts]
+default = "default.lua"
+timeout = "50ms"
+
+[scripts.channels]
+vendor = "vendor.lua"
+`
+	defaultScript = `function transform(msg) return {n = "default", v = 1} end`
+	vendorScript  = `function transform(msg) return {n = "vendor", v = 2} end`
+)
+
+func TestLoadScripts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scripts")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"config.toml": scriptsConfig,
+		"default.lua": defaultScript,
+		"vendor.lua":  vendorScript,
+		"empty.toml":  "[subjects]\nfilter = [\"channels.>\"]\n",
+	}
+	for name, data := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	}
+
+	cfg, err := writers.LoadScripts(filepath.Join(dir, "config.toml"))
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	assert.Equal(t, defaultScript, cfg.Script, "expected default script")
+	assert.Equal(t, map[string]string{"vendor": vendorScript}, cfg.Channels, "expected channel scripts")
+	assert.Equal(t, 50*time.Millisecond, cfg.Timeout, "expected timeout")
+
+	_, err = writers.LoadScripts(filepath.Join(dir, "empty.toml"))
+	assert.NotNil(t, err, "expected error loading configuration without scripts")
+
+	_, err = writers.LoadScripts("nonexistent.toml")
+	assert.NotNil(t, err, "expected error loading nonexistent file")
+}
@@ -0,0 +1,255 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package timescale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq" // required for DB access
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+const (
+	messagesTable = "messages"
+
+	errInvalid        = "invalid_text_representation"
+	errUndefinedTable = "undefined_table"
+)
+
+var (
+	errInvalidMessage = errors.New("invalid message representation")
+	errSaveMessage    = errors.New("failed to save message to timescale database")
+	errTransRollback  = errors.New("failed to rollback transaction")
+	errNoTable        = errors.New("relation does not exist")
+)
+
+var _ consumers.Consumer = (*timescaleRepo)(nil)
+
+type timescaleRepo struct {
+	db            *sqlx.DB
+	chunkInterval string
+	compressAfter string
+}
+
+// New returns new TimescaleDB writer. Chunk interval and compression
+// age are applied to the hypertables created for JSON messages.
+func New(db *sqlx.DB, chunkInterval, compressAfter string) consumers.Consumer {
+	return &timescaleRepo{
+		db:            db,
+		chunkInterval: chunkInterval,
+		compressAfter: compressAfter,
+	}
+}
+
+func (tr timescaleRepo) Consume(message interface{}) (err error) {
+	switch m := message.(type) {
+	case mfjson.Messages:
+		return tr.saveJSON(m)
+	default:
+		return tr.saveSenml(m)
+	}
+}
+
+func (tr timescaleRepo) saveSenml(messages interface{}) (err error) {
+	msgs, ok := messages.([]senml.Message)
+	if !ok {
+		return errSaveMessage
+	}
+	q := `INSERT INTO messages (time, id, channel, subtopic, publisher, protocol,
+          name, unit, value, string_value, bool_value, data_value, sum,
+          update_time)
+          VALUES (:time, :id, :channel, :subtopic, :publisher, :protocol, :name,
+          :unit, :value, :string_value, :bool_value, :data_value, :sum,
+          :update_time);`
+
+	tx, err := tr.db.BeginTxx(context.Background(), nil)
+	if err != nil {
+		return errors.Wrap(errSaveMessage, err)
+	}
+	defer func() {
+		if err != nil {
+			if txErr := tx.Rollback(); txErr != nil {
+				err = errors.Wrap(err, errors.Wrap(errTransRollback, txErr))
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			err = errors.Wrap(errSaveMessage, err)
+		}
+	}()
+
+	for _, msg := range msgs {
+		var m senmlMessage
+		m, err = toSenmlMessage(msg)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.NamedExec(q, m); err != nil {
+			pqErr, ok := err.(*pq.Error)
+			if ok {
+				switch pqErr.Code.Name() {
+				case errInvalid:
+					return errors.Wrap(errSaveMessage, errInvalidMessage)
+				}
+			}
+
+			return errors.Wrap(errSaveMessage, err)
+		}
+	}
+	return err
+}
+
+func (tr timescaleRepo) saveJSON(msgs mfjson.Messages) error {
+	if err := tr.insertJSON(msgs); err != nil {
+		if err == errNoTable {
+			if err := tr.createTable(msgs.Format); err != nil {
+				return err
+			}
+			return tr.insertJSON(msgs)
+		}
+		return err
+	}
+	return nil
+}
+
+func (tr timescaleRepo) insertJSON(msgs mfjson.Messages) (err error) {
+	tx, err := tr.db.BeginTxx(context.Background(), nil)
+	if err != nil {
+		return errors.Wrap(errSaveMessage, err)
+	}
+	defer func() {
+		if err != nil {
+			if txErr := tx.Rollback(); txErr != nil {
+				err = errors.Wrap(err, errors.Wrap(errTransRollback, txErr))
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			err = errors.Wrap(errSaveMessage, err)
+		}
+	}()
+
+	q := `INSERT INTO %s (time, id, channel, created, subtopic, publisher, protocol, payload)
+          VALUES (:time, :id, :channel, :created, :subtopic, :publisher, :protocol, :payload);`
+	q = fmt.Sprintf(q, msgs.Format)
+
+	for _, m := range msgs.Data {
+		var dbmsg jsonMessage
+		dbmsg, err = toJSONMessage(m)
+		if err != nil {
+			return errors.Wrap(errSaveMessage, err)
+		}
+		if _, err = tx.NamedExec(q, dbmsg); err != nil {
+			pqErr, ok := err.(*pq.Error)
+			if ok {
+				switch pqErr.Code.Name() {
+				case errInvalid:
+					return errors.Wrap(errSaveMessage, errInvalidMessage)
+				case errUndefinedTable:
+					return errNoTable
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (tr timescaleRepo) createTable(name string) error {
+	q := `CREATE TABLE IF NOT EXISTS %s (
+                        time          TIMESTAMPTZ NOT NULL,
+                        id            UUID NOT NULL,
+                        created       BIGINT,
+                        channel       VARCHAR(254),
+                        subtopic      VARCHAR(254),
+                        publisher     VARCHAR(254),
+                        protocol      TEXT,
+                        payload       JSONB,
+                        PRIMARY KEY (time, id)
+                    )`
+	q = fmt.Sprintf(q, name)
+
+	if _, err := tr.db.Exec(q); err != nil {
+		return err
+	}
+	if _, err := tr.db.Exec(`SELECT create_hypertable($1, 'time', if_not_exists => TRUE)`, name); err != nil {
+		return err
+	}
+
+	return configure(tr.db, name, tr.chunkInterval, tr.compressAfter)
+}
+
+type senmlMessage struct {
+	senml.Message
+	Time time.Time `db:"time"`
+	ID   string    `db:"id"`
+}
+
+type jsonMessage struct {
+	Time      time.Time `db:"time"`
+	ID        string    `db:"id"`
+	Channel   string    `db:"channel"`
+	Created   int64     `db:"created"`
+	Subtopic  string    `db:"subtopic"`
+	Publisher string    `db:"publisher"`
+	Protocol  string    `db:"protocol"`
+	Payload   []byte    `db:"payload"`
+}
+
+func toSenmlMessage(msg senml.Message) (senmlMessage, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return senmlMessage{}, err
+	}
+
+	// SenML time is represented in seconds since the epoch.
+	sec, frac := math.Modf(msg.Time)
+	m := senmlMessage{
+		Message: msg,
+		Time:    time.Unix(int64(sec), int64(frac*1e9)),
+		ID:      id.String(),
+	}
+
+	return m, nil
+}
+
+func toJSONMessage(msg mfjson.Message) (jsonMessage, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return jsonMessage{}, err
+	}
+
+	data := []byte("{}")
+	if msg.Payload != nil {
+		b, err := json.Marshal(msg.Payload)
+		if err != nil {
+			return jsonMessage{}, errors.Wrap(errSaveMessage, err)
+		}
+		data = b
+	}
+
+	m := jsonMessage{
+		Time:      time.Unix(0, msg.Created),
+		ID:        id.String(),
+		Channel:   msg.Channel,
+		Created:   msg.Created,
+		Subtopic:  msg.Subtopic,
+		Publisher: msg.Publisher,
+		Protocol:  msg.Protocol,
+		Payload:   data,
+	}
+
+	return m, nil
+}
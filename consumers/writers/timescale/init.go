@@ -0,0 +1,117 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package timescale
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq" // required for SQL access
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Config defines the options that are used when connecting to a TimescaleDB
+// instance, as well as messages hypertable partitioning and compression.
+type Config struct {
+	Host        string
+	Port        string
+	User        string
+	Pass        string
+	Name        string
+	SSLMode     string
+	SSLCert     string
+	SSLKey      string
+	SSLRootCert string
+	// ChunkInterval is the time interval covered by a single hypertable
+	// chunk, in PostgreSQL interval format (e.g. "1 day").
+	ChunkInterval string
+	// CompressAfter is the age of the chunks that are compressed, in
+	// PostgreSQL interval format. Compression is disabled if empty.
+	CompressAfter string
+}
+
+// Connect creates a connection to the TimescaleDB instance, applies any
+// unapplied database migrations and configures messages hypertable. A non-nil
+// error is returned to indicate failure.
+func Connect(cfg Config) (*sqlx.DB, error) {
+	url := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s sslcert=%s sslkey=%s sslrootcert=%s", cfg.Host, cfg.Port, cfg.User, cfg.Name, cfg.Pass, cfg.SSLMode, cfg.SSLCert, cfg.SSLKey, cfg.SSLRootCert)
+
+	db, err := sqlx.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateDB(db); err != nil {
+		return nil, err
+	}
+
+	if err := configure(db, messagesTable, cfg.ChunkInterval, cfg.CompressAfter); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func migrateDB(db *sqlx.DB) error {
+	migrations := &migrate.MemoryMigrationSource{
+		Migrations: []*migrate.Migration{
+			{
+				Id: "messages_1",
+				Up: []string{
+					`CREATE EXTENSION IF NOT EXISTS timescaledb`,
+					`CREATE TABLE IF NOT EXISTS messages (
+                        time          TIMESTAMPTZ NOT NULL,
+                        id            UUID NOT NULL,
+                        channel       UUID,
+                        subtopic      VARCHAR(254),
+                        publisher     UUID,
+                        protocol      TEXT,
+                        name          TEXT,
+                        unit          TEXT,
+                        value         FLOAT,
+                        string_value  TEXT,
+                        bool_value    BOOL,
+                        data_value    BYTEA,
+                        sum           FLOAT,
+                        update_time   FLOAT,
+                        PRIMARY KEY (time, id)
+                    )`,
+					`SELECT create_hypertable('messages', 'time', if_not_exists => TRUE)`,
+					`CREATE INDEX IF NOT EXISTS messages_channel_time_idx ON messages (channel, time DESC)`,
+				},
+				Down: []string{
+					"DROP TABLE messages",
+				},
+			},
+		},
+	}
+
+	_, err := migrate.Exec(db.DB, "postgres", migrations, migrate.Up)
+	return err
+}
+
+// configure sets the chunk time interval of the hypertable and replaces its
+// compression policy, so that the changes of configuration take effect on
+// the service restart.
+func configure(db *sqlx.DB, table, chunkInterval, compressAfter string) error {
+	if chunkInterval != "" {
+		if _, err := db.Exec(`SELECT set_chunk_time_interval($1, $2::INTERVAL)`, table, chunkInterval); err != nil {
+			return err
+		}
+	}
+
+	if _, err := db.Exec(`SELECT remove_compression_policy($1, if_exists => TRUE)`, table); err != nil {
+		return err
+	}
+	if compressAfter == "" {
+		return nil
+	}
+
+	q := fmt.Sprintf(`ALTER TABLE %s SET (timescaledb.compress, timescaledb.compress_segmentby = 'channel')`, table)
+	if _, err := db.Exec(q); err != nil {
+		return err
+	}
+	_, err := db.Exec(`SELECT add_compression_policy($1, $2::INTERVAL)`, table, compressAfter)
+	return err
+}
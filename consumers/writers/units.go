@@ -0,0 +1,69 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/pelletier/go-toml"
+)
+
+var (
+	errOpenUnitsFile  = errors.New("unable to open units configuration file")
+	errParseUnitsFile = errors.New("unable to parse units configuration file")
+	errInvalidUnit    = errors.New("invalid unit conversion")
+)
+
+type unit struct {
+	From   string   `toml:"from"`
+	To     string   `toml:"to"`
+	Scale  *float64 `toml:"scale"`
+	Offset float64  `toml:"offset"`
+}
+
+type unitsConfig struct {
+	Units []unit `toml:"units"`
+}
+
+// LoadUnits loads the unit conversion table from the "units" sections of the
+// configuration file which contains the subjects list. The scale defaults to
+// 1, so the conversions which only shift the value (e.g. from Kelvin to
+// Celsius) can omit it.
+func LoadUnits(path string) ([]senml.Conversion, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(errOpenUnitsFile, err)
+	}
+
+	var cfg unitsConfig
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return nil, errors.Wrap(errParseUnitsFile, err)
+	}
+
+	seen := make(map[string]bool)
+	var ret []senml.Conversion
+	for _, u := range cfg.Units {
+		if u.From == "" || u.To == "" {
+			return nil, errors.Wrap(errInvalidUnit, errors.New("missing unit"))
+		}
+		if seen[u.From] {
+			return nil, errors.Wrap(errInvalidUnit, errors.New(fmt.Sprintf("duplicate conversion of %s", u.From)))
+		}
+		seen[u.From] = true
+
+		c := senml.Conversion{From: u.From, To: u.To, Scale: 1, Offset: u.Offset}
+		if u.Scale != nil {
+			if *u.Scale == 0 {
+				return nil, errors.Wrap(errInvalidUnit, errors.New(fmt.Sprintf("zero scale of %s", u.From)))
+			}
+			c.Scale = *u.Scale
+		}
+		ret = append(ret, c)
+	}
+
+	return ret, nil
+}
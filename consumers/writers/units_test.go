@@ -0,0 +1,85 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package writers_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mainflux/mainflux/consumers/writers"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadUnits(t *testing.T) {
+	cases := []struct {
+		desc   string
+		config string
+		units  []senml.Conversion
+		err    bool
+	}{
+		{
+			desc: "load unit conversions",
+			config: `
+[[units]]
+from = "mV"
+to = "V"
+scale = 0.001
+
+[[units]]
+from = "K"
+to = "Cel"
+offset = -273.15
+`,
+			units: []senml.Conversion{
+				{From: "mV", To: "V", Scale: 0.001},
+				{From: "K", To: "Cel", Scale: 1, Offset: -273.15},
+			},
+			err: false,
+		},
+		{
+			desc:   "load configuration without unit conversions",
+			config: "[subjects]\nfilter = [\"channels.>\"]\n",
+			units:  nil,
+			err:    false,
+		},
+		{
+			desc:   "load unit conversion without target unit",
+			config: "[[units]]\nfrom = \"mV\"\nscale = 0.001\n",
+			units:  nil,
+			err:    true,
+		},
+		{
+			desc:   "load unit conversion with zero scale",
+			config: "[[units]]\nfrom = \"mV\"\nto = \"V\"\nscale = 0.0\n",
+			units:  nil,
+			err:    true,
+		},
+		{
+			desc:   "load duplicate unit conversions",
+			config: "[[units]]\nfrom = \"mV\"\nto = \"V\"\n\n[[units]]\nfrom = \"mV\"\nto = \"kV\"\n",
+			units:  nil,
+			err:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		file, err := ioutil.TempFile("", "config.toml")
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+		_, err = file.WriteString(tc.config)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+		file.Close()
+
+		units, err := writers.LoadUnits(file.Name())
+		os.Remove(file.Name())
+		assert.Equal(t, tc.units, units, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.units, units))
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s", tc.desc, tc.err, err))
+	}
+
+	_, err := writers.LoadUnits("nonexistent.toml")
+	assert.NotNil(t, err, "expected error loading nonexistent file")
+}
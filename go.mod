@@ -8,6 +8,7 @@ require (
 	github.com/docker/docker v20.10.6+incompatible
 	github.com/eclipse/paho.mqtt.golang v1.3.4
 	github.com/fatih/color v1.10.0
+	github.com/fxamacker/cbor/v2 v2.2.0
 	github.com/go-kit/kit v0.10.0
 	github.com/go-redis/redis/v8 v8.8.2
 	github.com/go-zoo/bone v1.3.0
@@ -16,12 +17,15 @@ require (
 	github.com/gogo/protobuf v1.3.2
 	github.com/golang/protobuf v1.4.3
 	github.com/gopcua/opcua v0.1.6
+	github.com/gorilla/websocket v1.4.2
 	github.com/hashicorp/vault/api v1.1.0
 	github.com/hokaccha/go-prettyjson v0.0.0-20210113012101-fb4e108d2519
 	github.com/influxdata/influxdb v1.8.5
 	github.com/jmoiron/sqlx v1.3.3
+	github.com/klauspost/compress v1.10.4
 	github.com/kr/text v0.2.0 // indirect
 	github.com/lib/pq v1.10.1
+	github.com/linkedin/goavro/v2 v2.11.1
 	github.com/mainflux/mproxy v0.2.2
 	github.com/mainflux/senml v1.5.0
 	github.com/mitchellh/mapstructure v1.4.1
@@ -30,6 +34,7 @@ require (
 	github.com/opentracing/opentracing-go v1.2.0
 	github.com/ory/dockertest/v3 v3.6.5
 	github.com/pelletier/go-toml v1.9.1
+	github.com/pion/dtls/v2 v2.0.1-0.20200503085337-8e86b3a7d585
 	github.com/plgd-dev/go-coap/v2 v2.4.0
 	github.com/prometheus/client_golang v1.10.0
 	github.com/rubenv/sql-migrate v0.0.0-20210408115534-a32ed26c37ea
@@ -38,11 +43,13 @@ require (
 	github.com/stretchr/testify v1.7.0
 	github.com/subosito/gotenv v1.2.0
 	github.com/uber/jaeger-client-go v2.28.0+incompatible
+	github.com/yuin/gopher-lua v0.0.0-20210529063254-f4c35e4016d9
 	go.mongodb.org/mongo-driver v1.4.0-beta2.0.20210512200446-5f449ba049cc
 	golang.org/x/crypto v0.0.0-20210314154223-e6e6c4f2bb5b
 	golang.org/x/net v0.0.0-20210510120150-4163338589ed
-	golang.org/x/time v0.0.0-20210220033141-f8bda1e9f3ba // indirect
+	golang.org/x/time v0.0.0-20210220033141-f8bda1e9f3ba
 	golang.org/x/tools v0.1.0 // indirect
 	gonum.org/v1/gonum v0.9.1
 	google.golang.org/grpc v1.36.0
+	google.golang.org/protobuf v1.25.0
 )
@@ -0,0 +1,114 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mainflux
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	statusPass = "pass"
+	statusFail = "fail"
+
+	checkTimeout = 5 * time.Second
+)
+
+// HealthInfo contains health endpoint response.
+type HealthInfo struct {
+	// Status contains service status.
+	Status string `json:"status"`
+
+	// Service contains service name.
+	Service string `json:"service"`
+
+	// Version contains service current version value.
+	Version string `json:"version"`
+
+	// Checks contains the statuses of the service dependencies.
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Health exposes an HTTP handler for retrieving service health.
+func Health(service string) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		res := HealthInfo{
+			Status:  statusPass,
+			Service: service,
+			Version: version,
+		}
+
+		data, _ := json.Marshal(res)
+
+		rw.Header().Set("Content-Type", "application/health+json")
+		rw.Write(data)
+	})
+}
+
+// HealthCheck exposes an HTTP handler for retrieving service health, which
+// checks the service dependencies (e.g. database or message broker) using the
+// given checks. If any of the checks fails or doesn't complete in 5 seconds,
+// the service status is "fail" and 503 Service Unavailable is returned.
+func HealthCheck(service string, checks map[string]func() error) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		res := HealthInfo{
+			Status:  statusPass,
+			Service: service,
+			Version: version,
+			Checks:  runChecks(checks),
+		}
+		for _, status := range res.Checks {
+			if status != statusPass {
+				res.Status = statusFail
+			}
+		}
+
+		data, _ := json.Marshal(res)
+
+		rw.Header().Set("Content-Type", "application/health+json")
+		if res.Status != statusPass {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+		rw.Write(data)
+	})
+}
+
+func runChecks(checks map[string]func() error) map[string]string {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	statuses := make(map[string]string, len(checks))
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func() error) {
+			defer wg.Done()
+			status := checkStatus(check)
+			mu.Lock()
+			statuses[name] = status
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+func checkStatus(check func() error) string {
+	// Buffered, so that the check which timed out doesn't leak.
+	errs := make(chan error, 1)
+	go func() {
+		errs <- check()
+	}()
+
+	select {
+	case err := <-errs:
+		if err != nil {
+			return statusFail + ": " + err.Error()
+		}
+		return statusPass
+	case <-time.After(checkTimeout):
+		return statusFail + ": timeout"
+	}
+}
@@ -7,42 +7,193 @@ package http
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
 )
 
+const chansPrefix = "channels"
+
+var (
+	// ErrRequestTimeout indicates that no response was received in time.
+	ErrRequestTimeout = errors.New("request timed out")
+
+	// ErrRateLimitExceeded indicates that the thing sent more requests than
+	// allowed by the rate limit.
+	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+)
+
+// BatchValidationError reports the invalid SenML message of the batch.
+type BatchValidationError struct {
+	// Message is the index of the invalid message in the batch.
+	Message int
+	*senml.ValidationError
+}
+
 // Service specifies coap service API.
 type Service interface {
 	// Publish Messssage
 	Publish(ctx context.Context, token string, msg messaging.Message) error
+
+	// PublishBatch publishes the messages of the same channel, authorizing
+	// the publisher once. In strict mode, the batch is rejected before any
+	// of the messages is published, if any of them is invalid.
+	PublishBatch(ctx context.Context, token string, msgs []messaging.Message) error
+
+	// Request publishes the command message and waits for the response
+	// published to the reply subtopic, until the context is done. The
+	// correlation ID is appended to the subtopic of the command, and the
+	// response is expected on the reply subtopic followed by the same ID.
+	Request(ctx context.Context, token string, msg messaging.Message, reply string) (messaging.Message, error)
 }
 
 var _ Service = (*adapterService)(nil)
 
 type adapterService struct {
-	publisher messaging.Publisher
-	things    mainflux.ThingsServiceClient
+	pubsub     messaging.PubSub
+	things     mainflux.ThingsServiceClient
+	idProvider mainflux.IDProvider
+	limiter    RateLimiter
+	strict     bool
 }
 
-// New instantiates the HTTP adapter implementation.
-func New(publisher messaging.Publisher, things mainflux.ThingsServiceClient) Service {
+// New instantiates the HTTP adapter implementation. In strict mode, the SenML
+// messages violating RFC 8428 are rejected, reporting the invalid records
+// using senml.ValidationError, instead of being published. The ID provider
+// generates the correlation IDs of the requests. The requests of the things
+// exceeding the rate limiter are rejected with ErrRateLimitExceeded, while nil
+// limiter disables rate limiting.
+func New(pubsub messaging.PubSub, things mainflux.ThingsServiceClient, idp mainflux.IDProvider, limiter RateLimiter, strict bool) Service {
 	return &adapterService{
-		publisher: publisher,
-		things:    things,
+		pubsub:     pubsub,
+		things:     things,
+		idProvider: idp,
+		limiter:    limiter,
+		strict:     strict,
 	}
 }
 
 func (as *adapterService) Publish(ctx context.Context, token string, msg messaging.Message) error {
+	thid, err := as.authorize(ctx, token, msg.Channel)
+	if err != nil {
+		return err
+	}
+	msg.Publisher = thid
+
+	if as.strict && (msg.ContentType == senml.JSON || msg.ContentType == senml.CBOR) {
+		if err := senml.Validate(msg.Payload, msg.ContentType); err != nil {
+			return err
+		}
+	}
+
+	return as.pubsub.Publish(msg.Channel, msg)
+}
+
+func (as *adapterService) PublishBatch(ctx context.Context, token string, msgs []messaging.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	thid, err := as.authorize(ctx, token, msgs[0].Channel)
+	if err != nil {
+		return err
+	}
+
+	for i := range msgs {
+		msgs[i].Publisher = thid
+		if as.strict && (msgs[i].ContentType == senml.JSON || msgs[i].ContentType == senml.CBOR) {
+			if err := senml.Validate(msgs[i].Payload, msgs[i].ContentType); err != nil {
+				if ve, ok := err.(*senml.ValidationError); ok {
+					return &BatchValidationError{Message: i, ValidationError: ve}
+				}
+				return err
+			}
+		}
+	}
+
+	for _, msg := range msgs {
+		if err := as.pubsub.Publish(msg.Channel, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (as *adapterService) Request(ctx context.Context, token string, msg messaging.Message, reply string) (messaging.Message, error) {
+	thid, err := as.authorize(ctx, token, msg.Channel)
+	if err != nil {
+		return messaging.Message{}, err
+	}
+	msg.Publisher = thid
+
+	if as.strict && (msg.ContentType == senml.JSON || msg.ContentType == senml.CBOR) {
+		if err := senml.Validate(msg.Payload, msg.ContentType); err != nil {
+			return messaging.Message{}, err
+		}
+	}
+
+	id, err := as.idProvider.ID()
+	if err != nil {
+		return messaging.Message{}, err
+	}
+
+	// The subscription precedes the command, so the response can't be missed.
+	res := make(chan messaging.Message, 1)
+	topic := fmt.Sprintf("%s.%s.%s.%s", chansPrefix, msg.Channel, reply, id)
+	err = as.pubsub.Subscribe(topic, func(m messaging.Message) error {
+		select {
+		case res <- m:
+		default:
+		}
+		return nil
+	})
+	if err != nil {
+		return messaging.Message{}, err
+	}
+	defer as.pubsub.Unsubscribe(topic)
+
+	msg.Subtopic = subtopic(msg.Subtopic, id)
+	if err := as.pubsub.Publish(msg.Channel, msg); err != nil {
+		return messaging.Message{}, err
+	}
+
+	select {
+	case m := <-res:
+		return m, nil
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return messaging.Message{}, ErrRequestTimeout
+		}
+		return messaging.Message{}, ctx.Err()
+	}
+}
+
+// authorize returns the ID of the thing allowed to access the channel, as long
+// as the thing didn't exceed the rate limit.
+func (as *adapterService) authorize(ctx context.Context, token, chanID string) (string, error) {
 	ar := &mainflux.AccessByKeyReq{
 		Token:  token,
-		ChanID: msg.Channel,
+		ChanID: chanID,
 	}
 	thid, err := as.things.CanAccessByKey(ctx, ar)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if as.limiter != nil && !as.limiter.Allow(thid.GetValue()) {
+		return "", ErrRateLimitExceeded
+	}
+
+	return thid.GetValue(), nil
+}
+
+func subtopic(prefix, id string) string {
+	if prefix == "" {
+		return id
 	}
-	msg.Publisher = thid.GetValue()
 
-	return as.publisher.Publish(msg.Channel, msg)
+	return fmt.Sprintf("%s.%s", prefix, id)
 }
@@ -17,3 +17,27 @@ func sendMessageEndpoint(svc http.Service) endpoint.Endpoint {
 		return nil, err
 	}
 }
+
+func sendBatchEndpoint(svc http.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(publishBatchReq)
+		err := svc.PublishBatch(ctx, req.token, req.msgs)
+		return nil, err
+	}
+}
+
+func requestEndpoint(svc http.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(requestReq)
+
+		ctx, cancel := context.WithTimeout(ctx, req.timeout)
+		defer cancel()
+
+		msg, err := svc.Request(ctx, req.token, req.msg, req.reply)
+		if err != nil {
+			return nil, err
+		}
+
+		return requestRes{msg: msg}, nil
+	}
+}
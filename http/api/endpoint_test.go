@@ -6,23 +6,27 @@ package api_test
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/go-kit/kit/metrics/generic"
 	"github.com/opentracing/opentracing-go/mocktracer"
 
 	"github.com/mainflux/mainflux"
 	adapter "github.com/mainflux/mainflux/http"
 	"github.com/mainflux/mainflux/http/api"
 	"github.com/mainflux/mainflux/http/mocks"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
-func newService(cc mainflux.ThingsServiceClient) adapter.Service {
-	pub := mocks.NewPublisher()
-	return adapter.New(pub, cc)
+func newService(cc mainflux.ThingsServiceClient, strict bool) adapter.Service {
+	pubsub := mocks.NewPubSub()
+	return adapter.New(pubsub, cc, uuid.NewMock(), nil, strict)
 }
 
 func newHTTPServer(svc adapter.Service) *httptest.Server {
@@ -60,7 +64,7 @@ func TestPublish(t *testing.T) {
 	invalidToken := "invalid_token"
 	msg := `[{"n":"current","t":-1,"v":1.6}]`
 	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
-	svc := newService(thingsClient)
+	svc := newService(thingsClient, false)
 	ts := newHTTPServer(svc)
 	defer ts.Close()
 
@@ -129,3 +133,356 @@ func TestPublish(t *testing.T) {
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", desc, tc.status, res.StatusCode))
 	}
 }
+
+func TestPublishStrict(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
+	svc := newService(thingsClient, true)
+	ts := newHTTPServer(svc)
+	defer ts.Close()
+
+	cases := map[string]struct {
+		msg         string
+		contentType string
+		status      int
+		res         string
+	}{
+		"publish valid SenML message": {
+			msg:         `[{"n":"current","t":1600000000,"v":1.6}]`,
+			contentType: "application/senml+json",
+			status:      http.StatusAccepted,
+			res:         "",
+		},
+		"publish SenML message with relative time": {
+			msg:         `[{"n":"current","t":-1,"v":1.6}]`,
+			contentType: "application/senml+json",
+			status:      http.StatusBadRequest,
+			res:         `{"error":"invalid senml pack","records":[{"record":0,"field":"t","reason":"relative time -1"}]}`,
+		},
+		"publish SenML message with unknown field": {
+			msg:         `[{"n":"current","v":1.6},{"n":"voltage","v":3.3,"foo":1}]`,
+			contentType: "application/senml+json",
+			status:      http.StatusBadRequest,
+			res:         `{"error":"invalid senml pack","records":[{"record":1,"field":"foo","reason":"unknown field"}]}`,
+		},
+		"publish non-SenML message": {
+			msg:         `{"current":1.6}`,
+			contentType: "application/json",
+			status:      http.StatusAccepted,
+			res:         "",
+		},
+	}
+
+	for desc, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			contentType: tc.contentType,
+			token:       token,
+			body:        strings.NewReader(tc.msg),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", desc, tc.status, res.StatusCode))
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", desc, err))
+		assert.Equal(t, tc.res, strings.TrimSpace(string(body)), fmt.Sprintf("%s: expected body %s got %s", desc, tc.res, body))
+	}
+}
+
+func TestPublishBatch(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	invalidToken := "invalid_token"
+	msgs := `[{"subtopic":"room1/temp","content_type":"application/senml+json","payload":[{"n":"current","t":-1,"v":1.6}]},{"payload":{"current":1.6}}]`
+	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
+	svc := newService(thingsClient, false)
+	ts := newHTTPServer(svc)
+	defer ts.Close()
+
+	tooMany := make([]string, 1001)
+	for i := range tooMany {
+		tooMany[i] = `{"payload":1}`
+	}
+
+	cases := map[string]struct {
+		chanID string
+		msgs   string
+		auth   string
+		status int
+	}{
+		"publish batch": {
+			chanID: chanID,
+			msgs:   msgs,
+			auth:   token,
+			status: http.StatusAccepted,
+		},
+		"publish batch without authorization token": {
+			chanID: chanID,
+			msgs:   msgs,
+			auth:   "",
+			status: http.StatusForbidden,
+		},
+		"publish batch with invalid authorization token": {
+			chanID: chanID,
+			msgs:   msgs,
+			auth:   invalidToken,
+			status: http.StatusForbidden,
+		},
+		"publish batch unable to authorize": {
+			chanID: chanID,
+			msgs:   msgs,
+			auth:   mocks.ServiceErrToken,
+			status: http.StatusServiceUnavailable,
+		},
+		"publish empty batch": {
+			chanID: chanID,
+			msgs:   `[]`,
+			auth:   token,
+			status: http.StatusBadRequest,
+		},
+		"publish too large batch": {
+			chanID: chanID,
+			msgs:   fmt.Sprintf("[%s]", strings.Join(tooMany, ",")),
+			auth:   token,
+			status: http.StatusBadRequest,
+		},
+		"publish malformed batch": {
+			chanID: chanID,
+			msgs:   `{"payload":1}`,
+			auth:   token,
+			status: http.StatusBadRequest,
+		},
+		"publish batch with message without payload": {
+			chanID: chanID,
+			msgs:   `[{"payload":1},{"subtopic":"temp"}]`,
+			auth:   token,
+			status: http.StatusBadRequest,
+		},
+		"publish batch with malformed subtopic": {
+			chanID: chanID,
+			msgs:   `[{"subtopic":"room*","payload":1}]`,
+			auth:   token,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for desc, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/channels/%s/messages/batch", ts.URL, tc.chanID),
+			contentType: "application/json",
+			token:       tc.auth,
+			body:        strings.NewReader(tc.msgs),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestPublishBatchStrict(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
+	svc := newService(thingsClient, true)
+	ts := newHTTPServer(svc)
+	defer ts.Close()
+
+	cases := map[string]struct {
+		msgs   string
+		status int
+		res    string
+	}{
+		"publish batch of valid SenML messages": {
+			msgs:   `[{"content_type":"application/senml+json","payload":[{"n":"current","t":1600000000,"v":1.6}]},{"payload":{"current":1.6}}]`,
+			status: http.StatusAccepted,
+			res:    "",
+		},
+		"publish batch with invalid SenML message": {
+			msgs:   `[{"content_type":"application/senml+json","payload":[{"n":"current","v":1.6}]},{"content_type":"application/senml+json","payload":[{"n":"current","t":-1,"v":1.6}]}]`,
+			status: http.StatusBadRequest,
+			res:    `{"error":"invalid senml pack","message":1,"records":[{"record":0,"field":"t","reason":"relative time -1"}]}`,
+		},
+	}
+
+	for desc, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/channels/%s/messages/batch", ts.URL, chanID),
+			contentType: "application/json",
+			token:       token,
+			body:        strings.NewReader(tc.msgs),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", desc, tc.status, res.StatusCode))
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", desc, err))
+		assert.Equal(t, tc.res, strings.TrimSpace(string(body)), fmt.Sprintf("%s: expected body %s got %s", desc, tc.res, body))
+	}
+}
+
+func TestRequest(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	invalidToken := "invalid_token"
+	command := `{"cmd":"on"}`
+	response := `{"status":"ok"}`
+	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
+	pubsub := mocks.NewPubSub()
+	svc := adapter.New(pubsub, thingsClient, uuid.NewMock(), nil, false)
+	ts := newHTTPServer(svc)
+	defer ts.Close()
+
+	// The responder replies to the commands published to the "commands"
+	// subtopic, using the correlation ID of the command.
+	err := pubsub.Subscribe(fmt.Sprintf("channels.%s.commands.*", chanID), func(msg messaging.Message) error {
+		id := msg.Subtopic[strings.LastIndex(msg.Subtopic, ".")+1:]
+		res := messaging.Message{
+			Channel:     chanID,
+			Subtopic:    fmt.Sprintf("responses.%s", id),
+			ContentType: "application/json",
+			Payload:     []byte(response),
+		}
+		return pubsub.Publish(chanID, res)
+	})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := map[string]struct {
+		path        string
+		auth        string
+		status      int
+		contentType string
+		res         string
+	}{
+		"send request": {
+			path:        "requests/commands",
+			auth:        token,
+			status:      http.StatusOK,
+			contentType: "application/json",
+			res:         response,
+		},
+		"send request without response": {
+			path:   "requests/other?timeout=10ms",
+			auth:   token,
+			status: http.StatusGatewayTimeout,
+		},
+		"send request with invalid authorization token": {
+			path:   "requests/commands",
+			auth:   invalidToken,
+			status: http.StatusForbidden,
+		},
+		"send request without authorization token": {
+			path:   "requests/commands",
+			auth:   "",
+			status: http.StatusForbidden,
+		},
+		"send request with invalid timeout": {
+			path:   "requests/commands?timeout=invalid",
+			auth:   token,
+			status: http.StatusBadRequest,
+		},
+		"send request with too long timeout": {
+			path:   "requests/commands?timeout=2m",
+			auth:   token,
+			status: http.StatusBadRequest,
+		},
+		"send request with wildcard subtopic": {
+			path:   "requests/commands/*",
+			auth:   token,
+			status: http.StatusBadRequest,
+		},
+		"send request with wildcard reply subtopic": {
+			path:   "requests/commands?reply=responses.>",
+			auth:   token,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for desc, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodPost,
+			url:    fmt.Sprintf("%s/channels/%s/%s", ts.URL, chanID, tc.path),
+			token:  tc.auth,
+			body:   strings.NewReader(command),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", desc, tc.status, res.StatusCode))
+		if tc.status != http.StatusOK {
+			continue
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", desc, err))
+		assert.Equal(t, tc.res, string(body), fmt.Sprintf("%s: expected response %s got %s", desc, tc.res, string(body)))
+		ct := res.Header.Get("Content-Type")
+		assert.Equal(t, tc.contentType, ct, fmt.Sprintf("%s: expected content type %s got %s", desc, tc.contentType, ct))
+	}
+}
+
+func TestPublishRateLimit(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	otherToken := "other_token"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+	thingsClient := mocks.NewThingsClient(map[string]string{token: "1", otherToken: "2"})
+	violations := generic.NewCounter("violations")
+	// The tokens are refilled too slowly to affect the test.
+	limiter := adapter.NewRateLimiter(0.001, 2, violations)
+	svc := adapter.New(mocks.NewPubSub(), thingsClient, uuid.NewMock(), limiter, false)
+	ts := newHTTPServer(svc)
+	defer ts.Close()
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+	}{
+		{
+			desc:   "publish first message within burst",
+			auth:   token,
+			status: http.StatusAccepted,
+		},
+		{
+			desc:   "publish second message within burst",
+			auth:   token,
+			status: http.StatusAccepted,
+		},
+		{
+			desc:   "publish message exceeding rate limit",
+			auth:   token,
+			status: http.StatusTooManyRequests,
+		},
+		{
+			desc:   "publish message of other thing",
+			auth:   otherToken,
+			status: http.StatusAccepted,
+		},
+		{
+			desc:   "publish message with invalid authorization token",
+			auth:   "invalid_token",
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			contentType: "application/senml+json",
+			token:       tc.auth,
+			body:        strings.NewReader(msg),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+	assert.Equal(t, float64(1), violations.Value(), "expected rejected request to be counted")
+}
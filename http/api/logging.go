@@ -43,3 +43,37 @@ func (lm *loggingMiddleware) Publish(ctx context.Context, token string, msg mess
 
 	return lm.svc.Publish(ctx, token, msg)
 }
+
+func (lm *loggingMiddleware) PublishBatch(ctx context.Context, token string, msgs []messaging.Message) (err error) {
+	defer func(begin time.Time) {
+		destChannel := ""
+		if len(msgs) > 0 {
+			destChannel = msgs[0].Channel
+		}
+		message := fmt.Sprintf("Method publish_batch of %d messages to channel %s took %s to complete", len(msgs), destChannel, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.PublishBatch(ctx, token, msgs)
+}
+
+func (lm *loggingMiddleware) Request(ctx context.Context, token string, msg messaging.Message, reply string) (res messaging.Message, err error) {
+	defer func(begin time.Time) {
+		destChannel := msg.Channel
+		if msg.Subtopic != "" {
+			destChannel = fmt.Sprintf("%s.%s", destChannel, msg.Subtopic)
+		}
+		message := fmt.Sprintf("Method request to channel %s with reply subtopic %s took %s to complete", destChannel, reply, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Request(ctx, token, msg, reply)
+}
@@ -39,3 +39,21 @@ func (mm *metricsMiddleware) Publish(ctx context.Context, token string, msg mess
 
 	return mm.svc.Publish(ctx, token, msg)
 }
+
+func (mm *metricsMiddleware) PublishBatch(ctx context.Context, token string, msgs []messaging.Message) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "publish_batch").Add(1)
+		mm.latency.With("method", "publish_batch").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.PublishBatch(ctx, token, msgs)
+}
+
+func (mm *metricsMiddleware) Request(ctx context.Context, token string, msg messaging.Message, reply string) (messaging.Message, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "request").Add(1)
+		mm.latency.With("method", "request").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Request(ctx, token, msg, reply)
+}
@@ -4,6 +4,9 @@
 package api
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/mainflux/mainflux/pkg/messaging"
 )
 
@@ -11,3 +14,23 @@ type publishReq struct {
 	msg   messaging.Message
 	token string
 }
+
+type requestReq struct {
+	msg     messaging.Message
+	token   string
+	reply   string
+	timeout time.Duration
+}
+
+type publishBatchReq struct {
+	msgs  []messaging.Message
+	token string
+}
+
+// batchMessage is the message of the batch, where the payload is the JSON
+// value published as is.
+type batchMessage struct {
+	Subtopic    string          `json:"subtopic,omitempty"`
+	ContentType string          `json:"content_type,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+}
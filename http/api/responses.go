@@ -0,0 +1,19 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+type requestRes struct {
+	msg messaging.Message
+}
+
+type validationRes struct {
+	Error   string              `json:"error"`
+	Message *int                `json:"message,omitempty"`
+	Records []senml.RecordError `json:"records"`
+}
@@ -5,6 +5,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"io/ioutil"
@@ -20,6 +21,7 @@ import (
 	"github.com/mainflux/mainflux"
 	adapter "github.com/mainflux/mainflux/http"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/mainflux/mainflux/things"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -27,14 +29,30 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-const protocol = "http"
+const (
+	protocol    = "http"
+	contentType = "application/json"
+	// maxBatchSize is the maximal number of messages published in a batch.
+	maxBatchSize = 1000
+
+	replyKey     = "reply"
+	timeoutKey   = "timeout"
+	defReply     = "responses"
+	defTimeout   = 10 * time.Second
+	maxTimeout   = time.Minute
+	wildcardOne  = "*"
+	wildcardRest = ">"
+)
 
 var (
 	errMalformedData     = errors.New("malformed request data")
 	errMalformedSubtopic = errors.New("malformed subtopic")
 )
 
-var channelPartRegExp = regexp.MustCompile(`^/channels/([\w\-]+)/messages(/[^?]*)?(\?.*)?$`)
+var (
+	channelPartRegExp = regexp.MustCompile(`^/channels/([\w\-]+)/messages(/[^?]*)?(\?.*)?$`)
+	requestPartRegExp = regexp.MustCompile(`^/channels/([\w\-]+)/requests(/[^?]*)?(\?.*)?$`)
+)
 
 // MakeHandler returns a HTTP handler for API endpoints.
 func MakeHandler(svc adapter.Service, tracer opentracing.Tracer) http.Handler {
@@ -50,6 +68,15 @@ func MakeHandler(svc adapter.Service, tracer opentracing.Tracer) http.Handler {
 		opts...,
 	))
 
+	// The batch route is registered before the subtopic one, which would
+	// match it otherwise.
+	r.Post("/channels/:id/messages/batch", kithttp.NewServer(
+		kitot.TraceServer(tracer, "publish_batch")(sendBatchEndpoint(svc)),
+		decodeBatchRequest,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Post("/channels/:id/messages/*", kithttp.NewServer(
 		kitot.TraceServer(tracer, "publish")(sendMessageEndpoint(svc)),
 		decodeRequest,
@@ -57,6 +84,20 @@ func MakeHandler(svc adapter.Service, tracer opentracing.Tracer) http.Handler {
 		opts...,
 	))
 
+	r.Post("/channels/:id/requests", kithttp.NewServer(
+		kitot.TraceServer(tracer, "request")(requestEndpoint(svc)),
+		decodeRequestReq,
+		encodeRequestResponse,
+		opts...,
+	))
+
+	r.Post("/channels/:id/requests/*", kithttp.NewServer(
+		kitot.TraceServer(tracer, "request")(requestEndpoint(svc)),
+		decodeRequestReq,
+		encodeRequestResponse,
+		opts...,
+	))
+
 	r.GetFunc("/version", mainflux.Version("http"))
 	r.Handle("/metrics", promhttp.Handler())
 
@@ -110,11 +151,12 @@ func decodeRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	}
 
 	msg := messaging.Message{
-		Protocol: protocol,
-		Channel:  chanID,
-		Subtopic: subtopic,
-		Payload:  payload,
-		Created:  time.Now().UnixNano(),
+		Protocol:    protocol,
+		Channel:     chanID,
+		Subtopic:    subtopic,
+		Payload:     payload,
+		Created:     time.Now().UnixNano(),
+		ContentType: r.Header.Get("Content-Type"),
 	}
 
 	req := publishReq{
@@ -125,6 +167,117 @@ func decodeRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	return req, nil
 }
 
+// decodeRequestReq decodes the command, published to the subtopic given by the
+// path, and the reply subtopic and the timeout of the response given by the
+// query parameters.
+func decodeRequestReq(_ context.Context, r *http.Request) (interface{}, error) {
+	requestParts := requestPartRegExp.FindStringSubmatch(r.RequestURI)
+	if len(requestParts) < 2 {
+		return nil, errMalformedData
+	}
+
+	chanID := bone.GetValue(r, "id")
+	subtopic, err := parseSubtopic(requestParts[2])
+	if err != nil {
+		return nil, err
+	}
+
+	q := r.URL.Query()
+	reply := defReply
+	if v := q.Get(replyKey); v != "" {
+		if reply, err = parseSubtopic(v); err != nil {
+			return nil, err
+		}
+	}
+	if reply == "" || hasWildcard(subtopic) || hasWildcard(reply) {
+		return nil, errMalformedSubtopic
+	}
+
+	timeout := defTimeout
+	if v := q.Get(timeoutKey); v != "" {
+		if timeout, err = time.ParseDuration(v); err != nil || timeout <= 0 || timeout > maxTimeout {
+			return nil, errMalformedData
+		}
+	}
+
+	payload, err := decodePayload(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := messaging.Message{
+		Protocol:    protocol,
+		Channel:     chanID,
+		Subtopic:    subtopic,
+		Payload:     payload,
+		Created:     time.Now().UnixNano(),
+		ContentType: r.Header.Get("Content-Type"),
+	}
+
+	req := requestReq{
+		msg:     msg,
+		token:   r.Header.Get("Authorization"),
+		reply:   reply,
+		timeout: timeout,
+	}
+
+	return req, nil
+}
+
+// hasWildcard reports whether the subtopic matches multiple subtopics, which
+// can't be used to correlate the response.
+func hasWildcard(subtopic string) bool {
+	for _, elem := range strings.Split(subtopic, ".") {
+		if elem == wildcardOne || elem == wildcardRest {
+			return true
+		}
+	}
+
+	return false
+}
+
+func decodeBatchRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	chanID := bone.GetValue(r, "id")
+	if chanID == "" {
+		return nil, errMalformedData
+	}
+
+	var bms []batchMessage
+	if err := json.NewDecoder(r.Body).Decode(&bms); err != nil {
+		return nil, errMalformedData
+	}
+	if len(bms) == 0 || len(bms) > maxBatchSize {
+		return nil, errMalformedData
+	}
+
+	created := time.Now().UnixNano()
+	msgs := make([]messaging.Message, len(bms))
+	for i, bm := range bms {
+		if len(bm.Payload) == 0 || string(bm.Payload) == "null" {
+			return nil, errMalformedData
+		}
+		subtopic, err := parseSubtopic(bm.Subtopic)
+		if err != nil {
+			return nil, err
+		}
+		msgs[i] = messaging.Message{
+			Protocol:    protocol,
+			Channel:     chanID,
+			Subtopic:    subtopic,
+			Payload:     bm.Payload,
+			Created:     created,
+			ContentType: bm.ContentType,
+		}
+	}
+
+	req := publishBatchReq{
+		msgs:  msgs,
+		token: r.Header.Get("Authorization"),
+	}
+
+	return req, nil
+}
+
 func decodePayload(body io.ReadCloser) ([]byte, error) {
 	payload, err := ioutil.ReadAll(body)
 	if err != nil {
@@ -140,12 +293,40 @@ func encodeResponse(_ context.Context, w http.ResponseWriter, response interface
 	return nil
 }
 
+func encodeRequestResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(requestRes)
+	if res.msg.ContentType != "" {
+		w.Header().Set("Content-Type", res.msg.ContentType)
+	}
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write(res.msg.Payload)
+	return err
+}
+
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
+	if be, ok := err.(*adapter.BatchValidationError); ok {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(validationRes{Error: be.Msg(), Message: &be.Message, Records: be.Records})
+		return
+	}
+
+	if ve, ok := err.(*senml.ValidationError); ok {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(validationRes{Error: ve.Msg(), Records: ve.Records})
+		return
+	}
+
 	switch err {
 	case errMalformedData, errMalformedSubtopic:
 		w.WriteHeader(http.StatusBadRequest)
 	case things.ErrUnauthorizedAccess:
 		w.WriteHeader(http.StatusForbidden)
+	case adapter.ErrRequestTimeout:
+		w.WriteHeader(http.StatusGatewayTimeout)
+	case adapter.ErrRateLimitExceeded:
+		w.WriteHeader(http.StatusTooManyRequests)
 	default:
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
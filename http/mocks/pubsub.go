@@ -0,0 +1,77 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+var _ messaging.PubSub = (*mockPubSub)(nil)
+
+type mockPubSub struct {
+	mu   sync.Mutex
+	subs map[string]messaging.MessageHandler
+}
+
+// NewPubSub returns mock message publisher/subscriber, which delivers the
+// published messages to the handlers of the matching subscriptions.
+func NewPubSub() messaging.PubSub {
+	return &mockPubSub{
+		subs: make(map[string]messaging.MessageHandler),
+	}
+}
+
+func (ps *mockPubSub) Publish(topic string, msg messaging.Message) error {
+	subject := fmt.Sprintf("channels.%s", topic)
+	if msg.Subtopic != "" {
+		subject = fmt.Sprintf("%s.%s", subject, msg.Subtopic)
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	for sub, h := range ps.subs {
+		if matches(sub, subject) {
+			go h(msg)
+		}
+	}
+	return nil
+}
+
+func (ps *mockPubSub) Subscribe(topic string, handler messaging.MessageHandler) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.subs[topic] = handler
+	return nil
+}
+
+func (ps *mockPubSub) Unsubscribe(topic string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	delete(ps.subs, topic)
+	return nil
+}
+
+// matches reports whether the subject matches the subscription, supporting
+// the NATS wildcards.
+func matches(sub, subject string) bool {
+	subTokens := strings.Split(sub, ".")
+	tokens := strings.Split(subject, ".")
+	for i, st := range subTokens {
+		if st == ">" {
+			return len(tokens) > i
+		}
+		if i >= len(tokens) || (st != "*" && st != tokens[i]) {
+			return false
+		}
+	}
+
+	return len(subTokens) == len(tokens)
+}
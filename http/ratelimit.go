@@ -0,0 +1,62 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"math"
+	"sync"
+
+	"github.com/go-kit/kit/metrics"
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter specifies per thing request rate limiting API.
+type RateLimiter interface {
+	// Allow reports whether the thing with the given ID may send the request
+	// now, taking a token from its bucket if so.
+	Allow(thingID string) bool
+}
+
+var _ RateLimiter = (*rateLimiter)(nil)
+
+type rateLimiter struct {
+	mu         sync.Mutex
+	limit      rate.Limit
+	burst      int
+	limiters   map[string]*rate.Limiter
+	violations metrics.Counter
+}
+
+// NewRateLimiter returns token bucket RateLimiter allowing rps requests per
+// second per thing, with the given burst. Burst less than 1 is replaced by the
+// number of requests allowed in a second. Each rejected request is counted.
+func NewRateLimiter(rps float64, burst int, violations metrics.Counter) RateLimiter {
+	if burst < 1 {
+		burst = int(math.Ceil(rps))
+	}
+
+	return &rateLimiter{
+		limit:      rate.Limit(rps),
+		burst:      burst,
+		limiters:   make(map[string]*rate.Limiter),
+		violations: violations,
+	}
+}
+
+func (rl *rateLimiter) Allow(thingID string) bool {
+	rl.mu.Lock()
+	l, ok := rl.limiters[thingID]
+	if !ok {
+		l = rate.NewLimiter(rl.limit, rl.burst)
+		rl.limiters[thingID] = l
+	}
+	rl.mu.Unlock()
+
+	if l.Allow() {
+		return true
+	}
+	rl.violations.Add(1)
+
+	return false
+}
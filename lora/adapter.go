@@ -6,15 +6,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/mainflux/mainflux/pkg/messaging"
+	mfsenml "github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/senml"
 )
 
 const (
 	protocol      = "lora"
 	thingSuffix   = "thing"
 	channelSuffix = "channel"
+
+	// CommandsSubtopic is the subtopic prefix of the messages carrying the
+	// downlink commands. The command subtopic is commands.<thingID>.
+	CommandsSubtopic = "commands"
+	minFPort         = 1
+	maxFPort         = 223
 )
 
 var (
@@ -40,7 +49,12 @@ type Service interface {
 	// UpdateThing updates thingID:devEUI route-map
 	UpdateThing(ctx context.Context, thingID string, devEUI string) error
 
-	// RemoveThing removes thingID:devEUI route-map
+	// CreateAppThing creates thingID:appID route-map of the thing which
+	// publishes the messages of the application devices without their own
+	// thing.
+	CreateAppThing(ctx context.Context, thingID string, appID string) error
+
+	// RemoveThing removes thingID:devEUI or thingID:appID route-map
 	RemoveThing(ctx context.Context, thingID string) error
 
 	// CreateChannel creates channelID:appID route-map
@@ -60,85 +74,211 @@ type Service interface {
 
 	// Publish forwards messages from the LoRa MQTT broker to Mainflux NATS broker
 	Publish(ctx context.Context, msg Message) error
+
+	// PublishEvent forwards the device event from the LoRa MQTT broker to
+	// Mainflux NATS broker as SenML JSON message on the event subtopic.
+	PublishEvent(ctx context.Context, e Event) error
+
+	// Command forwards the command published on the Mainflux NATS broker to
+	// the LoRa MQTT broker as the downlink message of the targeted device.
+	Command(ctx context.Context, msg messaging.Message) error
 }
 
 var _ Service = (*adapterService)(nil)
 
 type adapterService struct {
-	publisher  messaging.Publisher
-	thingsRM   RouteMapRepository
-	channelsRM RouteMapRepository
-	connectRM  RouteMapRepository
+	publisher   messaging.Publisher
+	downlinker  Downlinker
+	devices     DeviceRepository
+	thingsRM    RouteMapRepository
+	appThingsRM RouteMapRepository
+	channelsRM  RouteMapRepository
+	connectRM   RouteMapRepository
+	codec       Codec
 }
 
-// New instantiates the LoRa adapter implementation.
-func New(publisher messaging.Publisher, thingsRM, channelsRM, connectRM RouteMapRepository) Service {
+// New instantiates the LoRa adapter implementation. The raw payloads are
+// decoded using the codec of the application before publishing. The downlinker sends the commands to the LoRa MQTT broker. If devices is
+// set, the device EUIs are checked against the LoRa Server device metadata.
+func New(publisher messaging.Publisher, downlinker Downlinker, devices DeviceRepository, thingsRM, appThingsRM, channelsRM, connectRM RouteMapRepository, codec Codec) Service {
 	return &adapterService{
-		publisher:  publisher,
-		thingsRM:   thingsRM,
-		channelsRM: channelsRM,
-		connectRM:  connectRM,
+		publisher:   publisher,
+		downlinker:  downlinker,
+		devices:     devices,
+		thingsRM:    thingsRM,
+		appThingsRM: appThingsRM,
+		channelsRM:  channelsRM,
+		connectRM:   connectRM,
+		codec:       codec,
 	}
 }
 
 // Publish forwards messages from Lora MQTT broker to Mainflux NATS broker
 func (as *adapterService) Publish(ctx context.Context, m Message) error {
-	// Get route map of lora application
-	thingID, err := as.thingsRM.Get(ctx, m.DevEUI)
+	thingID, chanID, err := as.route(ctx, m.ApplicationID, m.DevEUI)
 	if err != nil {
-		return ErrNotFoundDev
-	}
-
-	// Get route map of lora application
-	chanID, err := as.channelsRM.Get(ctx, m.ApplicationID)
-	if err != nil {
-		return ErrNotFoundApp
+		return err
 	}
 
-	c := fmt.Sprintf("%s:%s", chanID, thingID)
-	if _, err := as.connectRM.Get(ctx, c); err != nil {
-		return ErrNotConnected
+	msg := messaging.Message{
+		Publisher: thingID,
+		Protocol:  protocol,
+		Channel:   chanID,
+		Created:   time.Now().UnixNano(),
 	}
 
 	// Use the SenML message decoded on LoRa Server application if
 	// field Object isn't empty. Otherwise, decode standard field Data.
-	var payload []byte
 	switch m.Object {
 	case nil:
-		payload, err = base64.StdEncoding.DecodeString(m.Data)
+		raw, err := base64.StdEncoding.DecodeString(m.Data)
 		if err != nil {
 			return ErrMalformedMessage
 		}
+		msg.Payload = raw
+		if msg.Payload, msg.ContentType, err = as.codec.Decode(m.ApplicationID, m.FPort, msg); err != nil {
+			return err
+		}
 	default:
 		jo, err := json.Marshal(m.Object)
 		if err != nil {
 			return err
 		}
-		payload = []byte(jo)
+		msg.Payload = []byte(jo)
 	}
 
 	// Publish on Mainflux NATS broker
+	return as.publisher.Publish(msg.Channel, msg)
+}
+
+// PublishEvent forwards device events from Lora MQTT broker to Mainflux NATS broker
+func (as *adapterService) PublishEvent(ctx context.Context, e Event) error {
+	thingID, chanID, err := as.route(ctx, e.ApplicationID, e.DevEUI)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	p, err := e.pack(now)
+	if err != nil {
+		return err
+	}
+	payload, err := senml.Encode(p, senml.JSON)
+	if err != nil {
+		return err
+	}
+
 	msg := messaging.Message{
-		Publisher: thingID,
-		Protocol:  protocol,
-		Channel:   chanID,
-		Payload:   payload,
-		Created:   time.Now().UnixNano(),
+		Publisher:   thingID,
+		Protocol:    protocol,
+		Channel:     chanID,
+		Subtopic:    fmt.Sprintf("%s.%s", EventsSubtopic, e.Type),
+		Payload:     payload,
+		Created:     now.UnixNano(),
+		ContentType: mfsenml.JSON,
 	}
 
 	return as.publisher.Publish(msg.Channel, msg)
 }
 
+// route returns the thing and the channel the messages of the device of the
+// application are published as.
+func (as *adapterService) route(ctx context.Context, appID, devEUI string) (string, string, error) {
+	// Get route map of lora device, falling back to the application thing
+	thingID, err := as.thingsRM.Get(ctx, devEUI)
+	if err != nil {
+		if thingID, err = as.appThingsRM.Get(ctx, appID); err != nil {
+			return "", "", ErrNotFoundDev
+		}
+	}
+
+	// Get route map of lora application
+	chanID, err := as.channelsRM.Get(ctx, appID)
+	if err != nil {
+		return "", "", ErrNotFoundApp
+	}
+
+	c := fmt.Sprintf("%s:%s", chanID, thingID)
+	if _, err := as.connectRM.Get(ctx, c); err != nil {
+		return "", "", ErrNotConnected
+	}
+
+	return thingID, chanID, nil
+}
+
+// Command forwards the command from Mainflux NATS broker to Lora MQTT broker
+func (as *adapterService) Command(ctx context.Context, msg messaging.Message) error {
+	// Skip the messages forwarded from Lora MQTT broker
+	if msg.Protocol == protocol {
+		return nil
+	}
+
+	thingID := strings.TrimPrefix(msg.Subtopic, CommandsSubtopic+".")
+	if thingID == msg.Subtopic || thingID == "" || strings.Contains(thingID, ".") {
+		return ErrMalformedMessage
+	}
+
+	appID, err := as.channelsRM.Get(ctx, msg.Channel)
+	if err != nil {
+		return ErrNotFoundApp
+	}
+
+	devEUI, err := as.thingsRM.Get(ctx, thingID)
+	if err != nil {
+		return ErrNotFoundDev
+	}
+
+	c := fmt.Sprintf("%s:%s", msg.Channel, thingID)
+	if _, err := as.connectRM.Get(ctx, c); err != nil {
+		return ErrNotConnected
+	}
+
+	var dm DownlinkMessage
+	if err := json.Unmarshal(msg.Payload, &dm); err != nil {
+		return ErrMalformedMessage
+	}
+	if err := dm.validate(); err != nil {
+		return err
+	}
+
+	return as.downlinker.Downlink(appID, devEUI, dm)
+}
+
 func (as *adapterService) CreateThing(ctx context.Context, thingID string, devEUI string) error {
+	if err := as.checkDevice(ctx, devEUI); err != nil {
+		return err
+	}
+	// The thing may have represented the application before the update.
+	as.appThingsRM.Remove(ctx, thingID)
 	return as.thingsRM.Save(ctx, thingID, devEUI)
 }
 
 func (as *adapterService) UpdateThing(ctx context.Context, thingID string, devEUI string) error {
-	return as.thingsRM.Save(ctx, thingID, devEUI)
+	return as.CreateThing(ctx, thingID, devEUI)
+}
+
+func (as *adapterService) CreateAppThing(ctx context.Context, thingID string, appID string) error {
+	// The thing may have represented the device before the update.
+	as.thingsRM.Remove(ctx, thingID)
+	return as.appThingsRM.Save(ctx, thingID, appID)
+}
+
+// checkDevice checks the device exists on LoRa Server, if its metadata is
+// available.
+func (as *adapterService) checkDevice(ctx context.Context, devEUI string) error {
+	if as.devices == nil {
+		return nil
+	}
+	_, err := as.devices.RetrieveByEUI(ctx, devEUI)
+	return err
 }
 
 func (as *adapterService) RemoveThing(ctx context.Context, thingID string) error {
-	return as.thingsRM.Remove(ctx, thingID)
+	devErr := as.thingsRM.Remove(ctx, thingID)
+	if appErr := as.appThingsRM.Remove(ctx, thingID); devErr != nil && appErr != nil {
+		return devErr
+	}
+	return nil
 }
 
 func (as *adapterService) CreateChannel(ctx context.Context, chanID string, appID string) error {
@@ -158,7 +298,7 @@ func (as *adapterService) ConnectThing(ctx context.Context, chanID, thingID stri
 		return ErrNotFoundApp
 	}
 
-	if _, err := as.thingsRM.Get(ctx, thingID); err != nil {
+	if !as.mappedThing(ctx, thingID) {
 		return ErrNotFoundDev
 	}
 
@@ -171,10 +311,20 @@ func (as *adapterService) DisconnectThing(ctx context.Context, chanID, thingID s
 		return ErrNotFoundApp
 	}
 
-	if _, err := as.thingsRM.Get(ctx, thingID); err != nil {
+	if !as.mappedThing(ctx, thingID) {
 		return ErrNotFoundDev
 	}
 
 	c := fmt.Sprintf("%s:%s", chanID, thingID)
 	return as.connectRM.Remove(ctx, c)
 }
+
+// mappedThing returns true if the thing is mapped either to the device or
+// to the application.
+func (as *adapterService) mappedThing(ctx context.Context, thingID string) bool {
+	if _, err := as.thingsRM.Get(ctx, thingID); err == nil {
+		return true
+	}
+	_, err := as.appThingsRM.Get(ctx, thingID)
+	return err == nil
+}
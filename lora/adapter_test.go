@@ -11,6 +11,7 @@ import (
 	"github.com/mainflux/mainflux/lora"
 	"github.com/mainflux/mainflux/lora/mocks"
 	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -27,17 +28,29 @@ const (
 	msg      = `[{"bn":"msg-base-name","n":"temperature","v": 17},{"n":"humidity","v": 56}]`
 )
 
-func newService() lora.Service {
+func newService(codec string) lora.Service {
+	c, _ := lora.NewCodec(lora.CodecConfig{Default: codec})
 	pub := mocks.NewPublisher()
 	thingsRM := mocks.NewRouteMap()
 	channelsRM := mocks.NewRouteMap()
 	connsRM := mocks.NewRouteMap()
 
-	return lora.New(pub, thingsRM, channelsRM, connsRM)
+	return lora.New(pub, mocks.NewDownlinker(), nil, thingsRM, mocks.NewRouteMap(), channelsRM, connsRM, c)
+}
+
+func newCommandService(downlinker *mocks.Downlinker) lora.Service {
+	pub := mocks.NewPublisher()
+	thingsRM := mocks.NewRouteMap()
+	channelsRM := mocks.NewRouteMap()
+	connsRM := mocks.NewRouteMap()
+
+	codec, _ := lora.NewCodec(lora.CodecConfig{})
+
+	return lora.New(pub, downlinker, nil, thingsRM, mocks.NewRouteMap(), channelsRM, connsRM, codec)
 }
 
 func TestPublish(t *testing.T) {
-	svc := newService()
+	svc := newService(lora.RawCodec)
 
 	err := svc.CreateChannel(nil, chanID, appID)
 	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
@@ -110,3 +123,297 @@ func TestPublish(t *testing.T) {
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 	}
 }
+
+func TestPublishAppThing(t *testing.T) {
+	svc := newService(lora.RawCodec)
+
+	err := svc.CreateChannel(nil, chanID, appID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	err = svc.CreateAppThing(nil, thingID, appID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	err = svc.ConnectThing(nil, chanID, thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	err = svc.CreateThing(nil, thingID2, devEUI2)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	msgBase64 := base64.StdEncoding.EncodeToString([]byte(msg))
+
+	cases := []struct {
+		desc string
+		err  error
+		msg  lora.Message
+	}{
+		{
+			desc: "publish message of unmapped device of mapped application",
+			err:  nil,
+			msg: lora.Message{
+				ApplicationID: appID,
+				DevEUI:        devEUI,
+				Data:          msgBase64,
+			},
+		},
+		{
+			desc: "publish message of mapped device of mapped application",
+			err:  lora.ErrNotConnected,
+			msg: lora.Message{
+				ApplicationID: appID,
+				DevEUI:        devEUI2,
+				Data:          msgBase64,
+			},
+		},
+		{
+			desc: "publish message of unmapped device of application without thing",
+			err:  lora.ErrNotFoundDev,
+			msg: lora.Message{
+				ApplicationID: appID2,
+				DevEUI:        devEUI,
+				Data:          msgBase64,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		err := svc.Publish(nil, tc.msg)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	err = svc.RemoveThing(nil, thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	err = svc.Publish(nil, cases[0].msg)
+	assert.True(t, errors.Contains(err, lora.ErrNotFoundDev), fmt.Sprintf("publish message of removed application thing: expected %s got %s\n", lora.ErrNotFoundDev, err))
+}
+
+func TestPublishLPP(t *testing.T) {
+	svc := newService(lora.LPPCodec)
+
+	err := svc.CreateChannel(nil, chanID, appID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	err = svc.CreateThing(nil, thingID, devEUI)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	err = svc.ConnectThing(nil, chanID, thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	// Temperature of 27.2 Cel on channel 3.
+	lppBase64 := base64.StdEncoding.EncodeToString([]byte{0x03, 0x67, 0x01, 0x10})
+	invalidBase64 := base64.StdEncoding.EncodeToString([]byte{0x03, 0xff, 0x01})
+
+	cases := []struct {
+		desc string
+		err  error
+		msg  lora.Message
+	}{
+		{
+			desc: "publish message with valid LPP Data",
+			err:  nil,
+			msg: lora.Message{
+				ApplicationID: appID,
+				DevEUI:        devEUI,
+				Data:          lppBase64,
+			},
+		},
+		{
+			desc: "publish message with invalid LPP Data",
+			err:  lora.ErrMalformedMessage,
+			msg: lora.Message{
+				ApplicationID: appID,
+				DevEUI:        devEUI,
+				Data:          invalidBase64,
+			},
+		},
+		{
+			desc: "publish message with decoded Object",
+			err:  nil,
+			msg: lora.Message{
+				ApplicationID: appID,
+				DevEUI:        devEUI,
+				Object:        map[string]interface{}{"temperature": 27.2},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		err := svc.Publish(nil, tc.msg)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestCommand(t *testing.T) {
+	downlinker := mocks.NewDownlinker()
+	svc := newCommandService(downlinker)
+
+	err := svc.CreateChannel(nil, chanID, appID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	err = svc.CreateThing(nil, thingID, devEUI)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	err = svc.ConnectThing(nil, chanID, thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	err = svc.CreateThing(nil, thingID2, devEUI2)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	dataBase64 := base64.StdEncoding.EncodeToString([]byte{0x01, 0x02})
+	subtopic := fmt.Sprintf("%s.%s", lora.CommandsSubtopic, thingID)
+
+	cases := []struct {
+		desc   string
+		msg    messaging.Message
+		appID  string
+		devEUI string
+		dm     lora.DownlinkMessage
+		err    error
+	}{
+		{
+			desc: "send command with valid data",
+			msg: messaging.Message{
+				Channel:  chanID,
+				Subtopic: subtopic,
+				Payload:  []byte(fmt.Sprintf(`{"confirmed":true,"fPort":10,"data":"%s"}`, dataBase64)),
+			},
+			appID:  appID,
+			devEUI: devEUI,
+			dm:     lora.DownlinkMessage{Confirmed: true, FPort: 10, Data: dataBase64},
+			err:    nil,
+		},
+		{
+			desc: "send command with valid object",
+			msg: messaging.Message{
+				Channel:  chanID,
+				Subtopic: subtopic,
+				Payload:  []byte(`{"fPort":2,"object":{"led":true}}`),
+			},
+			appID:  appID,
+			devEUI: devEUI,
+			dm:     lora.DownlinkMessage{FPort: 2, Object: map[string]interface{}{"led": true}},
+			err:    nil,
+		},
+		{
+			desc: "send command with port out of range",
+			msg: messaging.Message{
+				Channel:  chanID,
+				Subtopic: subtopic,
+				Payload:  []byte(fmt.Sprintf(`{"fPort":224,"data":"%s"}`, dataBase64)),
+			},
+			err: lora.ErrMalformedMessage,
+		},
+		{
+			desc: "send command without port",
+			msg: messaging.Message{
+				Channel:  chanID,
+				Subtopic: subtopic,
+				Payload:  []byte(fmt.Sprintf(`{"data":"%s"}`, dataBase64)),
+			},
+			err: lora.ErrMalformedMessage,
+		},
+		{
+			desc: "send command with invalid data",
+			msg: messaging.Message{
+				Channel:  chanID,
+				Subtopic: subtopic,
+				Payload:  []byte(`{"fPort":10,"data":"wrong"}`),
+			},
+			err: lora.ErrMalformedMessage,
+		},
+		{
+			desc: "send command with both data and object",
+			msg: messaging.Message{
+				Channel:  chanID,
+				Subtopic: subtopic,
+				Payload:  []byte(fmt.Sprintf(`{"fPort":10,"data":"%s","object":{"led":true}}`, dataBase64)),
+			},
+			err: lora.ErrMalformedMessage,
+		},
+		{
+			desc: "send command with invalid JSON",
+			msg: messaging.Message{
+				Channel:  chanID,
+				Subtopic: subtopic,
+				Payload:  []byte(`{"fPort":`),
+			},
+			err: lora.ErrMalformedMessage,
+		},
+		{
+			desc: "send command without target thing",
+			msg: messaging.Message{
+				Channel:  chanID,
+				Subtopic: lora.CommandsSubtopic,
+				Payload:  []byte(fmt.Sprintf(`{"fPort":10,"data":"%s"}`, dataBase64)),
+			},
+			err: lora.ErrMalformedMessage,
+		},
+		{
+			desc: "send command with non existing appID route-map",
+			msg: messaging.Message{
+				Channel:  chanID2,
+				Subtopic: subtopic,
+				Payload:  []byte(fmt.Sprintf(`{"fPort":10,"data":"%s"}`, dataBase64)),
+			},
+			err: lora.ErrNotFoundApp,
+		},
+		{
+			desc: "send command with non existing devEUI route-map",
+			msg: messaging.Message{
+				Channel:  chanID,
+				Subtopic: fmt.Sprintf("%s.%s", lora.CommandsSubtopic, "wrong"),
+				Payload:  []byte(fmt.Sprintf(`{"fPort":10,"data":"%s"}`, dataBase64)),
+			},
+			err: lora.ErrNotFoundDev,
+		},
+		{
+			desc: "send command with non existing connection route-map",
+			msg: messaging.Message{
+				Channel:  chanID,
+				Subtopic: fmt.Sprintf("%s.%s", lora.CommandsSubtopic, thingID2),
+				Payload:  []byte(fmt.Sprintf(`{"fPort":10,"data":"%s"}`, dataBase64)),
+			},
+			err: lora.ErrNotConnected,
+		},
+	}
+
+	for _, tc := range cases {
+		*downlinker = mocks.Downlinker{}
+		err := svc.Command(nil, tc.msg)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.appID, downlinker.AppID, fmt.Sprintf("%s: expected app ID %s got %s\n", tc.desc, tc.appID, downlinker.AppID))
+		assert.Equal(t, tc.devEUI, downlinker.DevEUI, fmt.Sprintf("%s: expected device EUI %s got %s\n", tc.desc, tc.devEUI, downlinker.DevEUI))
+		assert.Equal(t, tc.dm, downlinker.Message, fmt.Sprintf("%s: expected downlink %v got %v\n", tc.desc, tc.dm, downlinker.Message))
+	}
+}
+
+func TestCreateThing(t *testing.T) {
+	devices := mocks.NewDeviceRepository(lora.Device{DevEUI: devEUI, Name: "device", ApplicationID: appID})
+	codec, _ := lora.NewCodec(lora.CodecConfig{})
+	svc := lora.New(mocks.NewPublisher(), mocks.NewDownlinker(), devices, mocks.NewRouteMap(), mocks.NewRouteMap(), mocks.NewRouteMap(), mocks.NewRouteMap(), codec)
+
+	cases := []struct {
+		desc    string
+		thingID string
+		devEUI  string
+		err     error
+	}{
+		{
+			desc:    "create thing of existing device",
+			thingID: thingID,
+			devEUI:  devEUI,
+			err:     nil,
+		},
+		{
+			desc:    "create thing of non existing device",
+			thingID: thingID2,
+			devEUI:  devEUI2,
+			err:     lora.ErrNotFoundDev,
+		},
+	}
+
+	for _, tc := range cases {
+		err := svc.CreateThing(nil, tc.thingID, tc.devEUI)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
@@ -4,18 +4,68 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 
+	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/go-zoo/bone"
 	"github.com/mainflux/mainflux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+const contentType = "application/json"
+
+// Replayer replays the things events in order to rebuild the route maps.
+type Replayer interface {
+	// Replay handles the retained things events again and returns their number.
+	Replay(context.Context) (int, error)
+}
+
 // MakeHandler returns a HTTP handler for API endpoints.
-func MakeHandler() http.Handler {
+func MakeHandler(replayer Replayer) http.Handler {
+	opts := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(encodeError),
+	}
+
 	r := bone.New()
+
+	r.Post("/rebuild", kithttp.NewServer(
+		rebuildEndpoint(replayer),
+		decodeRebuild,
+		encodeResponse,
+		opts...,
+	))
+
 	r.GetFunc("/version", mainflux.Version("lora-adapter"))
 	r.Handle("/metrics", promhttp.Handler())
 
 	return r
 }
+
+func decodeRebuild(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", contentType)
+
+	if ar, ok := response.(mainflux.Response); ok {
+		for k, v := range ar.Headers() {
+			w.Header().Set(k, v)
+		}
+
+		w.WriteHeader(ar.Code())
+
+		if ar.Empty() {
+			return nil
+		}
+	}
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+func encodeError(_ context.Context, _ error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusInternalServerError)
+}
@@ -0,0 +1,25 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+func rebuildEndpoint(replayer Replayer) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		cnt, err := replayer.Replay(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		res := rebuildRes{
+			Events: cnt,
+		}
+
+		return res, nil
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mainflux/mainflux/lora/api"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type replayerMock struct {
+	cnt int
+	err error
+}
+
+func (rm replayerMock) Replay(context.Context) (int, error) {
+	return rm.cnt, rm.err
+}
+
+func TestRebuild(t *testing.T) {
+	cases := []struct {
+		desc     string
+		replayer replayerMock
+		method   string
+		status   int
+		res      string
+	}{
+		{
+			desc:     "rebuild route maps",
+			replayer: replayerMock{cnt: 5},
+			method:   http.MethodPost,
+			status:   http.StatusOK,
+			res:      `{"events":5}`,
+		},
+		{
+			desc:     "rebuild route maps with unavailable event stream",
+			replayer: replayerMock{err: errors.New("connection refused")},
+			method:   http.MethodPost,
+			status:   http.StatusInternalServerError,
+			res:      "",
+		},
+		{
+			desc:     "rebuild route maps with invalid method",
+			replayer: replayerMock{cnt: 5},
+			method:   http.MethodGet,
+			status:   http.StatusMethodNotAllowed,
+			res:      "",
+		},
+	}
+
+	for _, tc := range cases {
+		ts := httptest.NewServer(api.MakeHandler(tc.replayer))
+		req, err := http.NewRequest(tc.method, fmt.Sprintf("%s/rebuild", ts.URL), nil)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+
+		res, err := ts.Client().Do(req)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		body, err := ioutil.ReadAll(res.Body)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		res.Body.Close()
+		ts.Close()
+
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d\n", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.res, strings.TrimSpace(string(body)), fmt.Sprintf("%s: expected body %s got %s\n", tc.desc, tc.res, body))
+	}
+}
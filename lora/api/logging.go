@@ -10,6 +10,7 @@ import (
 
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/pkg/messaging"
 )
 
 var _ lora.Service = (*loggingMiddleware)(nil)
@@ -40,6 +41,19 @@ func (lm loggingMiddleware) CreateThing(ctx context.Context, thingID string, lor
 	return lm.svc.CreateThing(ctx, thingID, loraDevEUI)
 }
 
+func (lm loggingMiddleware) CreateAppThing(ctx context.Context, thingID string, loraAppID string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("create_app_thing for thing %s and lora-app-id %s took %s to complete", thingID, loraAppID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.CreateAppThing(ctx, thingID, loraAppID)
+}
+
 func (lm loggingMiddleware) UpdateThing(ctx context.Context, thingID string, loraDevEUI string) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("update_thing for thing %s and lora-dev-eui %s took %s to complete", thingID, loraDevEUI, time.Since(begin))
@@ -143,3 +157,29 @@ func (lm loggingMiddleware) Publish(ctx context.Context, msg lora.Message) (err
 
 	return lm.svc.Publish(ctx, msg)
 }
+
+func (lm loggingMiddleware) Command(ctx context.Context, msg messaging.Message) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("command channels/%s/messages/%s took %s to complete", msg.Channel, msg.Subtopic, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Command(ctx, msg)
+}
+
+func (lm loggingMiddleware) PublishEvent(ctx context.Context, e lora.Event) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("publish_event %s of application/%s/device/%s took %s to complete", e.Type, e.ApplicationID, e.DevEUI, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.PublishEvent(ctx, e)
+}
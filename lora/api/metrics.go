@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-kit/kit/metrics"
 	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/pkg/messaging"
 )
 
 var _ lora.Service = (*metricsMiddleware)(nil)
@@ -37,6 +38,15 @@ func (mm *metricsMiddleware) CreateThing(ctx context.Context, thingID string, lo
 	return mm.svc.CreateThing(ctx, thingID, loraDevEUI)
 }
 
+func (mm *metricsMiddleware) CreateAppThing(ctx context.Context, thingID string, loraAppID string) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "create_app_thing").Add(1)
+		mm.latency.With("method", "create_app_thing").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.CreateAppThing(ctx, thingID, loraAppID)
+}
+
 func (mm *metricsMiddleware) UpdateThing(ctx context.Context, thingID string, loraDevEUI string) error {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "update_thing").Add(1)
@@ -108,3 +118,21 @@ func (mm *metricsMiddleware) Publish(ctx context.Context, msg lora.Message) erro
 
 	return mm.svc.Publish(ctx, msg)
 }
+
+func (mm *metricsMiddleware) Command(ctx context.Context, msg messaging.Message) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "command").Add(1)
+		mm.latency.With("method", "command").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Command(ctx, msg)
+}
+
+func (mm *metricsMiddleware) PublishEvent(ctx context.Context, e lora.Event) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "publish_event").Add(1)
+		mm.latency.With("method", "publish_event").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.PublishEvent(ctx, e)
+}
@@ -0,0 +1,28 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/mainflux/mainflux"
+)
+
+var _ mainflux.Response = (*rebuildRes)(nil)
+
+type rebuildRes struct {
+	Events int `json:"events"`
+}
+
+func (res rebuildRes) Code() int {
+	return http.StatusOK
+}
+
+func (res rebuildRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res rebuildRes) Empty() bool {
+	return false
+}
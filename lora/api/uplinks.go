@@ -0,0 +1,116 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/metrics"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "lora_adapter"
+	subsystem = "uplink"
+)
+
+// UplinkMetrics contains the metrics of the uplinks received from the LoRa
+// MQTT broker.
+type UplinkMetrics struct {
+	// Received counts the received uplinks.
+	Received metrics.Counter
+	// DecodeFailures counts the uplinks which failed to be decoded.
+	DecodeFailures metrics.Counter
+	// Unmapped counts the uplinks of the devices and applications without
+	// route map or connection.
+	Unmapped metrics.Counter
+	// PublishErrors counts the uplinks which failed to be published to NATS.
+	PublishErrors metrics.Counter
+}
+
+// MakeUplinkMetrics returns the uplink metrics exposed to Prometheus.
+func MakeUplinkMetrics() UplinkMetrics {
+	counter := func(name, help string) metrics.Counter {
+		return kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      name,
+			Help:      help,
+		}, []string{})
+	}
+
+	return UplinkMetrics{
+		Received:       counter("received_count", "Number of received uplinks."),
+		DecodeFailures: counter("decode_failure_count", "Number of uplinks which failed to be decoded."),
+		Unmapped:       counter("unmapped_count", "Number of uplinks of unmapped devices."),
+		PublishErrors:  counter("publish_error_count", "Number of uplinks which failed to be published."),
+	}
+}
+
+// UplinkDecoder decodes the payload received from the LoRa MQTT broker.
+type UplinkDecoder func(payload []byte) (lora.Message, error)
+
+// UplinkHandler returns the handler of the uplinks received from the LoRa
+// MQTT broker, which decodes and publishes them using the service. The
+// uplinks which fail to be decoded are published to the dead letter, if
+// it's not nil.
+func UplinkHandler(svc lora.Service, decode UplinkDecoder, m UplinkMetrics, deadLetter lora.DeadLetter, logger logger.Logger) messaging.MessageHandler {
+	undecodable := func(msg messaging.Message, err error) error {
+		m.DecodeFailures.Add(1)
+		if deadLetter == nil {
+			return err
+		}
+		if dlErr := deadLetter.Publish(msg.Subtopic, msg.Payload, err); dlErr != nil {
+			return errors.Wrap(err, dlErr)
+		}
+		logger.Warn(fmt.Sprintf("Uplink published to dead letter after failing to decode: %s", err))
+		return nil
+	}
+
+	return func(msg messaging.Message) error {
+		m.Received.Add(1)
+
+		um, err := decode(msg.Payload)
+		if err != nil {
+			return undecodable(msg, err)
+		}
+
+		err = svc.Publish(context.Background(), um)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Contains(err, lora.ErrMalformedMessage):
+			return undecodable(msg, err)
+		case errors.Contains(err, lora.ErrNotFoundDev),
+			errors.Contains(err, lora.ErrNotFoundApp),
+			errors.Contains(err, lora.ErrNotConnected):
+			m.Unmapped.Add(1)
+		default:
+			m.PublishErrors.Add(1)
+		}
+		return err
+	}
+}
+
+// EventDecoder decodes the event received on the topic of the LoRa MQTT
+// broker.
+type EventDecoder func(topic string, payload []byte) (lora.Event, error)
+
+// EventHandler returns the handler of the device events received from the
+// LoRa MQTT broker, which decodes and publishes them using the service.
+func EventHandler(svc lora.Service, decode EventDecoder) messaging.MessageHandler {
+	return func(msg messaging.Message) error {
+		e, err := decode(msg.Subtopic, msg.Payload)
+		if err != nil {
+			return err
+		}
+		return svc.PublishEvent(context.Background(), e)
+	}
+}
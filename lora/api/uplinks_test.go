@@ -0,0 +1,151 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/go-kit/kit/metrics/generic"
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/lora/api"
+	"github.com/mainflux/mainflux/lora/mocks"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	thingID = "thingID-1"
+	chanID  = "chanID-1"
+	devEUI  = "devEUI-1"
+	appID   = "appID-1"
+	topic   = "application/appID-1/device/devEUI-1/rx"
+)
+
+var testLog, _ = log.New(os.Stdout, log.Info.String())
+
+type deadLetterMock struct {
+	topic   string
+	payload []byte
+	err     error
+}
+
+func (dl *deadLetterMock) Publish(topic string, payload []byte, err error) error {
+	dl.topic = topic
+	dl.payload = payload
+	dl.err = err
+	return nil
+}
+
+func (dl *deadLetterMock) Close() {}
+
+func decode(payload []byte) (lora.Message, error) {
+	var m lora.Message
+	if err := json.Unmarshal(payload, &m); err != nil {
+		return lora.Message{}, errors.Wrap(lora.ErrMalformedMessage, err)
+	}
+	return m, nil
+}
+
+func newMetrics() api.UplinkMetrics {
+	return api.UplinkMetrics{
+		Received:       generic.NewCounter("received"),
+		DecodeFailures: generic.NewCounter("decode_failures"),
+		Unmapped:       generic.NewCounter("unmapped"),
+		PublishErrors:  generic.NewCounter("publish_errors"),
+	}
+}
+
+func value(c interface{}) float64 {
+	return c.(*generic.Counter).Value()
+}
+
+func TestUplinkHandler(t *testing.T) {
+	codec, err := lora.NewCodec(lora.CodecConfig{Default: lora.LPPCodec})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	svc := lora.New(mocks.NewPublisher(), mocks.NewDownlinker(), nil, mocks.NewRouteMap(), mocks.NewRouteMap(), mocks.NewRouteMap(), mocks.NewRouteMap(), codec)
+
+	err = svc.CreateChannel(nil, chanID, appID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	err = svc.CreateThing(nil, thingID, devEUI)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	err = svc.ConnectThing(nil, chanID, thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	// Temperature of 27.2 Cel on channel 3.
+	lppBase64 := base64.StdEncoding.EncodeToString([]byte{0x03, 0x67, 0x01, 0x10})
+	invalidBase64 := base64.StdEncoding.EncodeToString([]byte{0x03, 0xff, 0x01})
+
+	cases := []struct {
+		desc           string
+		payload        string
+		deadLetter     bool
+		decodeFailures float64
+		unmapped       float64
+		err            error
+	}{
+		{
+			desc:    "handle valid uplink",
+			payload: fmt.Sprintf(`{"applicationID":"%s","devEUI":"%s","data":"%s"}`, appID, devEUI, lppBase64),
+			err:     nil,
+		},
+		{
+			desc:           "handle uplink with invalid JSON",
+			payload:        `{"applicationID":`,
+			deadLetter:     true,
+			decodeFailures: 1,
+			err:            nil,
+		},
+		{
+			desc:           "handle uplink with invalid LPP data",
+			payload:        fmt.Sprintf(`{"applicationID":"%s","devEUI":"%s","data":"%s"}`, appID, devEUI, invalidBase64),
+			deadLetter:     true,
+			decodeFailures: 2,
+			err:            nil,
+		},
+		{
+			desc:           "handle uplink of unmapped device",
+			payload:        fmt.Sprintf(`{"applicationID":"%s","devEUI":"wrong","data":"%s"}`, appID, lppBase64),
+			decodeFailures: 2,
+			unmapped:       1,
+			err:            lora.ErrNotFoundDev,
+		},
+	}
+
+	m := newMetrics()
+	dl := &deadLetterMock{}
+	handler := api.UplinkHandler(svc, decode, m, dl, testLog)
+	for i, tc := range cases {
+		*dl = deadLetterMock{}
+		err := handler(messaging.Message{Subtopic: topic, Payload: []byte(tc.payload)})
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.deadLetter, dl.err != nil, fmt.Sprintf("%s: expected dead letter %t got %t\n", tc.desc, tc.deadLetter, dl.err != nil))
+		if tc.deadLetter {
+			assert.Equal(t, topic, dl.topic, fmt.Sprintf("%s: expected topic %s got %s\n", tc.desc, topic, dl.topic))
+			assert.Equal(t, tc.payload, string(dl.payload), fmt.Sprintf("%s: expected payload %s got %s\n", tc.desc, tc.payload, dl.payload))
+		}
+		assert.Equal(t, float64(i+1), value(m.Received), fmt.Sprintf("%s: unexpected received count\n", tc.desc))
+		assert.Equal(t, tc.decodeFailures, value(m.DecodeFailures), fmt.Sprintf("%s: unexpected decode failure count\n", tc.desc))
+		assert.Equal(t, tc.unmapped, value(m.Unmapped), fmt.Sprintf("%s: unexpected unmapped count\n", tc.desc))
+		assert.Equal(t, float64(0), value(m.PublishErrors), fmt.Sprintf("%s: unexpected publish error count\n", tc.desc))
+	}
+}
+
+func TestUplinkHandlerWithoutDeadLetter(t *testing.T) {
+	codec, err := lora.NewCodec(lora.CodecConfig{})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	svc := lora.New(mocks.NewPublisher(), mocks.NewDownlinker(), nil, mocks.NewRouteMap(), mocks.NewRouteMap(), mocks.NewRouteMap(), mocks.NewRouteMap(), codec)
+
+	m := newMetrics()
+	handler := api.UplinkHandler(svc, decode, m, nil, testLog)
+	err = handler(messaging.Message{Subtopic: topic, Payload: []byte(`{"applicationID":`)})
+	assert.True(t, errors.Contains(err, lora.ErrMalformedMessage), fmt.Sprintf("expected %s got %s\n", lora.ErrMalformedMessage, err))
+	assert.Equal(t, float64(1), value(m.DecodeFailures), "unexpected decode failure count")
+}
@@ -0,0 +1,83 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package chirpstack contains the ChirpStack v4 integration of the LoRa
+// adapter: the MQTT event and command envelopes, encoded using JSON or
+// Protobuf marshaler, and the gRPC device metadata API.
+package chirpstack
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// JSON is the ChirpStack MQTT integration JSON marshaler.
+	JSON = "json"
+	// Protobuf is the ChirpStack MQTT integration Protobuf marshaler.
+	Protobuf = "protobuf"
+
+	// UplinkTopic is the topic of the ChirpStack uplink events.
+	UplinkTopic = "application/+/device/+/event/up"
+	// DownlinkTopic is the topic of the ChirpStack downlink commands.
+	DownlinkTopic = "application/%s/device/%s/command/down"
+	// EventTopic is the topic of the ChirpStack events of the given type.
+	EventTopic = "application/+/device/+/event/%s"
+)
+
+// ErrUnknownMarshaler indicates the marshaler other than JSON and Protobuf.
+var ErrUnknownMarshaler = errors.New("unknown ChirpStack marshaler")
+
+// field holds the value of the Protobuf field of varint, fixed32 or bytes
+// wire type.
+type field struct {
+	varint  uint64
+	fixed32 uint32
+	bytes   []byte
+}
+
+// fields parses the Protobuf encoded message. Fields of other wire types are
+// skipped, and the last value is kept for the repeated fields.
+func fields(b []byte) (map[protowire.Number]field, error) {
+	fs := map[protowire.Number]field{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			fs[num] = field{varint: v}
+			b = b[n:]
+		case protowire.Fixed32Type:
+			v, n := protowire.ConsumeFixed32(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			fs[num] = field{fixed32: v}
+			b = b[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			fs[num] = field{bytes: v}
+			b = b[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+
+	return fs, nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package chirpstack
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mainflux/mainflux/lora"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	getDeviceMethod = "/api.DeviceService/Get"
+	requestTimeout  = 5 * time.Second
+
+	// Protobuf field numbers of api.GetDeviceRequest, api.GetDeviceResponse
+	// and api.Device.
+	getDeviceDevEUI = 1
+	getDeviceDevice = 1
+	deviceDevEUI    = 1
+	deviceName      = 2
+	deviceAppID     = 4
+)
+
+var _ lora.DeviceRepository = (*deviceRepository)(nil)
+
+type deviceRepository struct {
+	conn  *grpc.ClientConn
+	token string
+}
+
+// NewDeviceRepository returns the device metadata repository using the
+// ChirpStack gRPC API, authenticated with the API token.
+func NewDeviceRepository(conn *grpc.ClientConn, token string) lora.DeviceRepository {
+	return deviceRepository{conn: conn, token: token}
+}
+
+func (repo deviceRepository) RetrieveByEUI(ctx context.Context, devEUI string) (lora.Device, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", fmt.Sprintf("Bearer %s", repo.token))
+
+	req := protowire.AppendTag(nil, getDeviceDevEUI, protowire.BytesType)
+	req = protowire.AppendString(req, devEUI)
+
+	var res []byte
+	if err := repo.conn.Invoke(ctx, getDeviceMethod, req, &res, grpc.ForceCodec(rawCodec{})); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return lora.Device{}, lora.ErrNotFoundDev
+		}
+		return lora.Device{}, err
+	}
+
+	fs, err := fields(res)
+	if err != nil {
+		return lora.Device{}, err
+	}
+	dev, err := fields(fs[getDeviceDevice].bytes)
+	if err != nil {
+		return lora.Device{}, err
+	}
+
+	return lora.Device{
+		DevEUI:        string(dev[deviceDevEUI].bytes),
+		Name:          string(dev[deviceName].bytes),
+		ApplicationID: string(dev[deviceAppID].bytes),
+	}, nil
+}
+
+// rawCodec passes the Protobuf encoded messages through, since ChirpStack
+// API messages are encoded and decoded by the repository itself.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,124 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package chirpstack_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/lora/chirpstack"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const apiToken = "token"
+
+// serverCodec passes the messages through, so the test server can serve
+// ChirpStack device API without the generated code.
+type serverCodec struct{}
+
+func (serverCodec) Marshal(v interface{}) ([]byte, error) {
+	return v.([]byte), nil
+}
+
+func (serverCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]byte) = append([]byte(nil), data...)
+	return nil
+}
+
+func (serverCodec) String() string {
+	return "proto"
+}
+
+func getDevice(_ interface{}, stream grpc.ServerStream) error {
+	method, _ := grpc.MethodFromServerStream(stream)
+	if method != "/api.DeviceService/Get" {
+		return status.Error(codes.Unimplemented, method)
+	}
+
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if auth := md.Get("authorization"); len(auth) != 1 || auth[0] != "Bearer "+apiToken {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	var req []byte
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+	_, _, n := protowire.ConsumeTag(req)
+	eui, _ := protowire.ConsumeString(req[n:])
+	if eui != devEUI {
+		return status.Error(codes.NotFound, "object does not exist")
+	}
+
+	dev := appendString(nil, 1, devEUI)
+	dev = appendString(dev, 2, devName)
+	dev = appendString(dev, 3, "description")
+	dev = appendString(dev, 4, appID)
+	return stream.SendMsg(appendBytes(nil, 1, dev))
+}
+
+func newDeviceRepository(t *testing.T, token string) (lora.DeviceRepository, func()) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	server := grpc.NewServer(grpc.CustomCodec(serverCodec{}), grpc.UnknownServiceHandler(getDevice))
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	return chirpstack.NewDeviceRepository(conn, token), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestRetrieveByEUI(t *testing.T) {
+	repo, stop := newDeviceRepository(t, apiToken)
+	defer stop()
+
+	cases := []struct {
+		desc   string
+		devEUI string
+		device lora.Device
+		err    error
+	}{
+		{
+			desc:   "retrieve existing device",
+			devEUI: devEUI,
+			device: lora.Device{DevEUI: devEUI, Name: devName, ApplicationID: appID},
+			err:    nil,
+		},
+		{
+			desc:   "retrieve non-existing device",
+			devEUI: "0202020202020202",
+			device: lora.Device{},
+			err:    lora.ErrNotFoundDev,
+		},
+	}
+
+	for _, tc := range cases {
+		dev, err := repo.RetrieveByEUI(context.Background(), tc.devEUI)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.device, dev, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.device, dev))
+	}
+}
+
+func TestRetrieveByEUIWithInvalidToken(t *testing.T) {
+	repo, stop := newDeviceRepository(t, "invalid")
+	defer stop()
+
+	_, err := repo.RetrieveByEUI(context.Background(), devEUI)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), fmt.Sprintf("expected %s got %s\n", codes.Unauthenticated, status.Code(err)))
+}
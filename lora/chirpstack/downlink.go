@@ -0,0 +1,118 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package chirpstack
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Protobuf field numbers of integration.DownlinkCommand.
+const (
+	downlinkDevEUI    = 2
+	downlinkConfirmed = 3
+	downlinkFPort     = 4
+	downlinkData      = 5
+	downlinkObject    = 6
+)
+
+var _ lora.Downlinker = (*downlinker)(nil)
+
+// downlinkCommand is ChirpStack downlink command (https://www.chirpstack.io/docs/chirpstack/integrations/mqtt.html#scheduling-a-downlink)
+type downlinkCommand struct {
+	DevEUI    string      `json:"devEui"`
+	Confirmed bool        `json:"confirmed"`
+	FPort     int         `json:"fPort"`
+	Data      string      `json:"data,omitempty"`
+	Object    interface{} `json:"object,omitempty"`
+}
+
+type downlinker struct {
+	publisher messaging.Publisher
+	marshaler string
+}
+
+// NewDownlinker returns the downlinker publishing the downlink commands
+// encoded using the marshaler to the ChirpStack MQTT broker.
+func NewDownlinker(publisher messaging.Publisher, marshaler string) (lora.Downlinker, error) {
+	if marshaler != JSON && marshaler != Protobuf {
+		return nil, ErrUnknownMarshaler
+	}
+
+	return downlinker{publisher: publisher, marshaler: marshaler}, nil
+}
+
+func (d downlinker) Downlink(appID, devEUI string, dm lora.DownlinkMessage) error {
+	var payload []byte
+	var err error
+	switch d.marshaler {
+	case Protobuf:
+		payload, err = encodeProtobufDownlink(devEUI, dm)
+	default:
+		payload, err = json.Marshal(downlinkCommand{
+			DevEUI:    devEUI,
+			Confirmed: dm.Confirmed,
+			FPort:     dm.FPort,
+			Data:      dm.Data,
+			Object:    dm.Object,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	msg := messaging.Message{
+		Payload: payload,
+		Created: time.Now().UnixNano(),
+	}
+
+	return d.publisher.Publish(fmt.Sprintf(DownlinkTopic, appID, devEUI), msg)
+}
+
+func encodeProtobufDownlink(devEUI string, dm lora.DownlinkMessage) ([]byte, error) {
+	b := protowire.AppendTag(nil, downlinkDevEUI, protowire.BytesType)
+	b = protowire.AppendString(b, devEUI)
+	if dm.Confirmed {
+		b = protowire.AppendTag(b, downlinkConfirmed, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(true))
+	}
+	b = protowire.AppendTag(b, downlinkFPort, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(dm.FPort))
+
+	if dm.Data != "" {
+		data, err := base64.StdEncoding.DecodeString(dm.Data)
+		if err != nil {
+			return nil, lora.ErrMalformedMessage
+		}
+		b = protowire.AppendTag(b, downlinkData, protowire.BytesType)
+		b = protowire.AppendBytes(b, data)
+	}
+
+	if dm.Object != nil {
+		obj, ok := dm.Object.(map[string]interface{})
+		if !ok {
+			return nil, lora.ErrMalformedMessage
+		}
+		s, err := structpb.NewStruct(obj)
+		if err != nil {
+			return nil, lora.ErrMalformedMessage
+		}
+		sb, err := proto.Marshal(s)
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, downlinkObject, protowire.BytesType)
+		b = protowire.AppendBytes(b, sb)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package chirpstack_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/lora/chirpstack"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type publisher struct {
+	topic string
+	msg   messaging.Message
+}
+
+func (pub *publisher) Publish(topic string, msg messaging.Message) error {
+	pub.topic = topic
+	pub.msg = msg
+	return nil
+}
+
+func TestNewDownlinker(t *testing.T) {
+	_, err := chirpstack.NewDownlinker(&publisher{}, "json_v3")
+	assert.True(t, errors.Contains(err, chirpstack.ErrUnknownMarshaler), fmt.Sprintf("expected %s got %s\n", chirpstack.ErrUnknownMarshaler, err))
+}
+
+func TestDownlinkJSON(t *testing.T) {
+	pub := &publisher{}
+	dl, err := chirpstack.NewDownlinker(pub, chirpstack.JSON)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	cases := []struct {
+		desc    string
+		dm      lora.DownlinkMessage
+		payload string
+	}{
+		{
+			desc:    "downlink data",
+			dm:      lora.DownlinkMessage{Confirmed: true, FPort: 10, Data: "AQI="},
+			payload: fmt.Sprintf(`{"devEui":"%s","confirmed":true,"fPort":10,"data":"AQI="}`, devEUI),
+		},
+		{
+			desc:    "downlink object",
+			dm:      lora.DownlinkMessage{FPort: 2, Object: map[string]interface{}{"led": true}},
+			payload: fmt.Sprintf(`{"devEui":"%s","confirmed":false,"fPort":2,"object":{"led":true}}`, devEUI),
+		},
+	}
+
+	topic := fmt.Sprintf("application/%s/device/%s/command/down", appID, devEUI)
+	for _, tc := range cases {
+		err := dl.Downlink(appID, devEUI, tc.dm)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, topic, pub.topic, fmt.Sprintf("%s: expected topic %s got %s\n", tc.desc, topic, pub.topic))
+		assert.JSONEq(t, tc.payload, string(pub.msg.Payload), fmt.Sprintf("%s: unexpected payload\n", tc.desc))
+	}
+}
+
+func TestDownlinkProtobuf(t *testing.T) {
+	pub := &publisher{}
+	dl, err := chirpstack.NewDownlinker(pub, chirpstack.Protobuf)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	obj, err := structpb.NewStruct(map[string]interface{}{"led": true})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	objBytes, err := proto.Marshal(obj)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	data := appendString(nil, 2, devEUI)
+	data = appendVarint(data, 3, protowire.EncodeBool(true))
+	data = appendVarint(data, 4, 10)
+	data = appendBytes(data, 5, []byte{0x01, 0x02})
+
+	object := appendString(nil, 2, devEUI)
+	object = appendVarint(object, 4, 2)
+	object = appendBytes(object, 6, objBytes)
+
+	cases := []struct {
+		desc    string
+		dm      lora.DownlinkMessage
+		payload []byte
+		err     error
+	}{
+		{
+			desc:    "downlink data",
+			dm:      lora.DownlinkMessage{Confirmed: true, FPort: 10, Data: "AQI="},
+			payload: data,
+			err:     nil,
+		},
+		{
+			desc:    "downlink object",
+			dm:      lora.DownlinkMessage{FPort: 2, Object: map[string]interface{}{"led": true}},
+			payload: object,
+			err:     nil,
+		},
+		{
+			desc: "downlink non-object",
+			dm:   lora.DownlinkMessage{FPort: 2, Object: []interface{}{true}},
+			err:  lora.ErrMalformedMessage,
+		},
+	}
+
+	for _, tc := range cases {
+		pub.msg = messaging.Message{}
+		err := dl.Downlink(appID, devEUI, tc.dm)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.payload, pub.msg.Payload, fmt.Sprintf("%s: unexpected payload\n", tc.desc))
+	}
+}
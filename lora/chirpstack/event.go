@@ -0,0 +1,145 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package chirpstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/mainflux/mainflux/lora"
+)
+
+const (
+	joinEvent   = "join"
+	ackEvent    = "ack"
+	statusEvent = "status"
+	logEvent    = "log"
+
+	// Protobuf field numbers of integration.JoinEvent, integration.AckEvent,
+	// integration.StatusEvent and integration.LogEvent.
+	eventDeviceInfo          = 3
+	joinDevAddr              = 4
+	ackAcknowledged          = 5
+	ackFCntDown              = 6
+	statusMargin             = 4
+	statusExternalPower      = 5
+	statusBatteryUnavailable = 6
+	statusBatteryLevel       = 7
+	logDeviceInfo            = 2
+	logLevel                 = 3
+	logDescription           = 5
+)
+
+// EventTypes are the types of the ChirpStack events forwarded as the device
+// events. Log events are forwarded as the error events.
+var EventTypes = []string{joinEvent, ackEvent, statusEvent, logEvent}
+
+var logLevels = []string{"INFO", "WARNING", "ERROR"}
+
+type event struct {
+	DeviceInfo              deviceInfo `json:"deviceInfo"`
+	DevAddr                 string     `json:"devAddr"`
+	Acknowledged            bool       `json:"acknowledged"`
+	FCntDown                int        `json:"fCntDown"`
+	Margin                  int        `json:"margin"`
+	ExternalPowerSource     bool       `json:"externalPowerSource"`
+	BatteryLevelUnavailable bool       `json:"batteryLevelUnavailable"`
+	BatteryLevel            float64    `json:"batteryLevel"`
+	Level                   string     `json:"level"`
+	Code                    string     `json:"code"`
+	Description             string     `json:"description"`
+}
+
+// DecodeEvent decodes the event received on the topic, encoded using the
+// marshaler, into the LoRa device event.
+func DecodeEvent(topic string, payload []byte, marshaler string) (lora.Event, error) {
+	typ := topic[strings.LastIndex(topic, "/")+1:]
+
+	var e event
+	switch marshaler {
+	case JSON:
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return lora.Event{}, lora.ErrMalformedMessage
+		}
+	case Protobuf:
+		var err error
+		if e, err = decodeProtobufEvent(typ, payload); err != nil {
+			return lora.Event{}, err
+		}
+	default:
+		return lora.Event{}, ErrUnknownMarshaler
+	}
+
+	le := lora.Event{
+		ApplicationID: e.DeviceInfo.ApplicationID,
+		DevEUI:        e.DeviceInfo.DevEUI,
+	}
+	switch typ {
+	case joinEvent:
+		le.Type = lora.JoinEvent
+		le.DevAddr = e.DevAddr
+	case ackEvent:
+		le.Type = lora.AckEvent
+		le.Acknowledged = e.Acknowledged
+		le.FCnt = e.FCntDown
+	case statusEvent:
+		le.Type = lora.StatusEvent
+		le.Margin = e.Margin
+		le.ExternalPowerSource = e.ExternalPowerSource
+		le.BatteryLevelUnavailable = e.BatteryLevelUnavailable
+		le.BatteryLevel = e.BatteryLevel
+	case logEvent:
+		le.Type = lora.ErrorEvent
+		le.ErrorType = strings.TrimSpace(fmt.Sprintf("%s %s", e.Level, e.Code))
+		le.Error = e.Description
+	default:
+		return lora.Event{}, lora.ErrMalformedMessage
+	}
+
+	return le, nil
+}
+
+func decodeProtobufEvent(typ string, payload []byte) (event, error) {
+	fs, err := fields(payload)
+	if err != nil {
+		return event{}, lora.ErrMalformedMessage
+	}
+
+	diField := fs[eventDeviceInfo]
+	if typ == logEvent {
+		diField = fs[logDeviceInfo]
+	}
+	di, err := fields(diField.bytes)
+	if err != nil {
+		return event{}, lora.ErrMalformedMessage
+	}
+
+	e := event{
+		DeviceInfo: deviceInfo{
+			ApplicationID: string(di[deviceInfoAppID].bytes),
+			DevEUI:        string(di[deviceInfoDevEUI].bytes),
+		},
+	}
+	switch typ {
+	case joinEvent:
+		e.DevAddr = string(fs[joinDevAddr].bytes)
+	case ackEvent:
+		e.Acknowledged = fs[ackAcknowledged].varint != 0
+		e.FCntDown = int(fs[ackFCntDown].varint)
+	case statusEvent:
+		e.Margin = int(int32(fs[statusMargin].varint))
+		e.ExternalPowerSource = fs[statusExternalPower].varint != 0
+		e.BatteryLevelUnavailable = fs[statusBatteryUnavailable].varint != 0
+		e.BatteryLevel = float64(math.Float32frombits(fs[statusBatteryLevel].fixed32))
+	case logEvent:
+		if lvl := fs[logLevel].varint; lvl < uint64(len(logLevels)) {
+			e.Level = logLevels[lvl]
+		}
+		e.Description = string(fs[logDescription].bytes)
+	}
+
+	return e, nil
+}
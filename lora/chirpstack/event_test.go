@@ -0,0 +1,114 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package chirpstack_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/lora/chirpstack"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestDecodeEvent(t *testing.T) {
+	di := appendString(nil, 3, appID)
+	di = appendString(di, 8, devEUI)
+
+	status := appendBytes(nil, 3, di)
+	margin := int64(-3)
+	status = appendVarint(status, 4, uint64(margin))
+	status = protowire.AppendTag(status, 7, protowire.Fixed32Type)
+	status = protowire.AppendFixed32(status, math.Float32bits(75.5))
+
+	log := appendBytes(nil, 2, di)
+	log = appendVarint(log, 3, 2)
+	log = appendString(log, 5, "codec failed")
+
+	join := appendBytes(nil, 3, di)
+	join = appendString(join, 4, "06682ea2")
+
+	topic := "application/%s/device/%s/event/%s"
+
+	cases := []struct {
+		desc      string
+		topic     string
+		payload   []byte
+		marshaler string
+		event     lora.Event
+		err       error
+	}{
+		{
+			desc:      "decode JSON join event",
+			topic:     fmt.Sprintf(topic, appID, devEUI, "join"),
+			payload:   []byte(fmt.Sprintf(`{"deviceInfo":{"applicationId":"%s","devEui":"%s"},"devAddr":"06682ea2"}`, appID, devEUI)),
+			marshaler: chirpstack.JSON,
+			event:     lora.Event{Type: lora.JoinEvent, ApplicationID: appID, DevEUI: devEUI, DevAddr: "06682ea2"},
+			err:       nil,
+		},
+		{
+			desc:      "decode JSON ack event",
+			topic:     fmt.Sprintf(topic, appID, devEUI, "ack"),
+			payload:   []byte(fmt.Sprintf(`{"deviceInfo":{"applicationId":"%s","devEui":"%s"},"acknowledged":true,"fCntDown":7}`, appID, devEUI)),
+			marshaler: chirpstack.JSON,
+			event:     lora.Event{Type: lora.AckEvent, ApplicationID: appID, DevEUI: devEUI, Acknowledged: true, FCnt: 7},
+			err:       nil,
+		},
+		{
+			desc:      "decode JSON log event",
+			topic:     fmt.Sprintf(topic, appID, devEUI, "log"),
+			payload:   []byte(fmt.Sprintf(`{"deviceInfo":{"applicationId":"%s","devEui":"%s"},"level":"ERROR","code":"UPLINK_CODEC","description":"codec failed"}`, appID, devEUI)),
+			marshaler: chirpstack.JSON,
+			event:     lora.Event{Type: lora.ErrorEvent, ApplicationID: appID, DevEUI: devEUI, ErrorType: "ERROR UPLINK_CODEC", Error: "codec failed"},
+			err:       nil,
+		},
+		{
+			desc:      "decode Protobuf join event",
+			topic:     fmt.Sprintf(topic, appID, devEUI, "join"),
+			payload:   join,
+			marshaler: chirpstack.Protobuf,
+			event:     lora.Event{Type: lora.JoinEvent, ApplicationID: appID, DevEUI: devEUI, DevAddr: "06682ea2"},
+			err:       nil,
+		},
+		{
+			desc:      "decode Protobuf status event",
+			topic:     fmt.Sprintf(topic, appID, devEUI, "status"),
+			payload:   status,
+			marshaler: chirpstack.Protobuf,
+			event:     lora.Event{Type: lora.StatusEvent, ApplicationID: appID, DevEUI: devEUI, Margin: -3, BatteryLevel: 75.5},
+			err:       nil,
+		},
+		{
+			desc:      "decode Protobuf log event",
+			topic:     fmt.Sprintf(topic, appID, devEUI, "log"),
+			payload:   log,
+			marshaler: chirpstack.Protobuf,
+			event:     lora.Event{Type: lora.ErrorEvent, ApplicationID: appID, DevEUI: devEUI, ErrorType: "ERROR", Error: "codec failed"},
+			err:       nil,
+		},
+		{
+			desc:      "decode event of unknown type",
+			topic:     fmt.Sprintf(topic, appID, devEUI, "txack"),
+			payload:   join,
+			marshaler: chirpstack.Protobuf,
+			err:       lora.ErrMalformedMessage,
+		},
+		{
+			desc:      "decode invalid JSON event",
+			topic:     fmt.Sprintf(topic, appID, devEUI, "join"),
+			payload:   []byte(`{"deviceInfo":`),
+			marshaler: chirpstack.JSON,
+			err:       lora.ErrMalformedMessage,
+		},
+	}
+
+	for _, tc := range cases {
+		e, err := chirpstack.DecodeEvent(tc.topic, tc.payload, tc.marshaler)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.event, e, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.event, e))
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package chirpstack
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/mainflux/mainflux/lora"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Protobuf field numbers of integration.UplinkEvent and integration.DeviceInfo.
+const (
+	uplinkDeviceInfo = 3
+	uplinkFCnt       = 7
+	uplinkFPort      = 8
+	uplinkData       = 10
+	uplinkObject     = 11
+
+	deviceInfoAppID   = 3
+	deviceInfoAppName = 4
+	deviceInfoName    = 7
+	deviceInfoDevEUI  = 8
+)
+
+// uplinkEvent is ChirpStack uplink event (https://www.chirpstack.io/docs/chirpstack/integrations/events.html#up---uplink-event)
+type uplinkEvent struct {
+	DeviceInfo deviceInfo  `json:"deviceInfo"`
+	FCnt       int         `json:"fCnt"`
+	FPort      int         `json:"fPort"`
+	Data       string      `json:"data"`
+	Object     interface{} `json:"object"`
+}
+
+type deviceInfo struct {
+	ApplicationID   string `json:"applicationId"`
+	ApplicationName string `json:"applicationName"`
+	DeviceName      string `json:"deviceName"`
+	DevEUI          string `json:"devEui"`
+}
+
+// DecodeUplink decodes the uplink event encoded using the marshaler into
+// the LoRa message. The data is kept base64 encoded, as in the legacy
+// LoRa Server events.
+func DecodeUplink(payload []byte, marshaler string) (lora.Message, error) {
+	switch marshaler {
+	case JSON:
+		var e uplinkEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return lora.Message{}, lora.ErrMalformedMessage
+		}
+		return e.message(), nil
+	case Protobuf:
+		return decodeProtobufUplink(payload)
+	default:
+		return lora.Message{}, ErrUnknownMarshaler
+	}
+}
+
+func decodeProtobufUplink(payload []byte) (lora.Message, error) {
+	fs, err := fields(payload)
+	if err != nil {
+		return lora.Message{}, lora.ErrMalformedMessage
+	}
+	di, err := fields(fs[uplinkDeviceInfo].bytes)
+	if err != nil {
+		return lora.Message{}, lora.ErrMalformedMessage
+	}
+
+	e := uplinkEvent{
+		DeviceInfo: deviceInfo{
+			ApplicationID:   string(di[deviceInfoAppID].bytes),
+			ApplicationName: string(di[deviceInfoAppName].bytes),
+			DeviceName:      string(di[deviceInfoName].bytes),
+			DevEUI:          string(di[deviceInfoDevEUI].bytes),
+		},
+		FCnt:  int(fs[uplinkFCnt].varint),
+		FPort: int(fs[uplinkFPort].varint),
+		Data:  base64.StdEncoding.EncodeToString(fs[uplinkData].bytes),
+	}
+
+	if obj, ok := fs[uplinkObject]; ok {
+		var s structpb.Struct
+		if err := proto.Unmarshal(obj.bytes, &s); err != nil {
+			return lora.Message{}, lora.ErrMalformedMessage
+		}
+		e.Object = s.AsMap()
+	}
+
+	return e.message(), nil
+}
+
+func (e uplinkEvent) message() lora.Message {
+	return lora.Message{
+		ApplicationID:   e.DeviceInfo.ApplicationID,
+		ApplicationName: e.DeviceInfo.ApplicationName,
+		DeviceName:      e.DeviceInfo.DeviceName,
+		DevEUI:          e.DeviceInfo.DevEUI,
+		FCnt:            e.FCnt,
+		FPort:           e.FPort,
+		Data:            e.Data,
+		Object:          e.Object,
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package chirpstack_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/lora/chirpstack"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	appID   = "a4c5a5c4-6ff2-4f52-9aa8-51e3e2ef0a9e"
+	appName = "app"
+	devName = "device"
+	devEUI  = "0101010101010101"
+)
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func protobufUplink(t *testing.T, data []byte, object map[string]interface{}) []byte {
+	di := appendString(nil, 1, "tenant")
+	di = appendString(di, 3, appID)
+	di = appendString(di, 4, appName)
+	di = appendString(di, 7, devName)
+	di = appendString(di, 8, devEUI)
+
+	b := appendString(nil, 1, "deduplication")
+	b = appendBytes(b, 3, di)
+	b = appendVarint(b, 5, protowire.EncodeBool(true))
+	b = appendVarint(b, 7, 12)
+	b = appendVarint(b, 8, 10)
+	b = appendBytes(b, 10, data)
+	if object != nil {
+		s, err := structpb.NewStruct(object)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+		sb, err := proto.Marshal(s)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+		b = appendBytes(b, 11, sb)
+	}
+	return b
+}
+
+func TestDecodeUplink(t *testing.T) {
+	msg := lora.Message{
+		ApplicationID:   appID,
+		ApplicationName: appName,
+		DeviceName:      devName,
+		DevEUI:          devEUI,
+		FCnt:            12,
+		FPort:           10,
+		Data:            "AQI=",
+	}
+	objMsg := msg
+	objMsg.Object = map[string]interface{}{"temperature": 27.2}
+
+	jsonUplink := fmt.Sprintf(`{"deduplicationId":"deduplication","deviceInfo":{"tenantId":"tenant","applicationId":"%s","applicationName":"%s","deviceName":"%s","devEui":"%s"},"adr":true,"fCnt":12,"fPort":10,"data":"AQI="}`, appID, appName, devName, devEUI)
+	jsonObjUplink := fmt.Sprintf(`{"deviceInfo":{"applicationId":"%s","applicationName":"%s","deviceName":"%s","devEui":"%s"},"fCnt":12,"fPort":10,"data":"AQI=","object":{"temperature":27.2}}`, appID, appName, devName, devEUI)
+
+	cases := []struct {
+		desc      string
+		payload   []byte
+		marshaler string
+		msg       lora.Message
+		err       error
+	}{
+		{
+			desc:      "decode JSON uplink",
+			payload:   []byte(jsonUplink),
+			marshaler: chirpstack.JSON,
+			msg:       msg,
+			err:       nil,
+		},
+		{
+			desc:      "decode JSON uplink with object",
+			payload:   []byte(jsonObjUplink),
+			marshaler: chirpstack.JSON,
+			msg:       objMsg,
+			err:       nil,
+		},
+		{
+			desc:      "decode invalid JSON uplink",
+			payload:   []byte(`{"deviceInfo":`),
+			marshaler: chirpstack.JSON,
+			err:       lora.ErrMalformedMessage,
+		},
+		{
+			desc:      "decode Protobuf uplink",
+			payload:   protobufUplink(t, []byte{0x01, 0x02}, nil),
+			marshaler: chirpstack.Protobuf,
+			msg:       msg,
+			err:       nil,
+		},
+		{
+			desc:      "decode Protobuf uplink with object",
+			payload:   protobufUplink(t, []byte{0x01, 0x02}, map[string]interface{}{"temperature": 27.2}),
+			marshaler: chirpstack.Protobuf,
+			msg:       objMsg,
+			err:       nil,
+		},
+		{
+			desc:      "decode invalid Protobuf uplink",
+			payload:   []byte{0x1a, 0x05, 0x01},
+			marshaler: chirpstack.Protobuf,
+			err:       lora.ErrMalformedMessage,
+		},
+		{
+			desc:      "decode uplink with unknown marshaler",
+			payload:   []byte(jsonUplink),
+			marshaler: "json_v3",
+			err:       chirpstack.ErrUnknownMarshaler,
+		},
+	}
+
+	for _, tc := range cases {
+		msg, err := chirpstack.DecodeUplink(tc.payload, tc.marshaler)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.msg, msg, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.msg, msg))
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lora
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/lpp"
+	"github.com/mainflux/mainflux/pkg/transformers/lua"
+	mfsenml "github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/senml"
+	"github.com/pelletier/go-toml"
+)
+
+const (
+	// RawCodec publishes the raw payloads as they are.
+	RawCodec = "raw"
+	// LPPCodec decodes the Cayenne LPP payloads to SenML JSON.
+	LPPCodec = "lpp"
+	// ScriptCodec decodes the payloads to SenML JSON using the Lua script.
+	ScriptCodec = "script"
+)
+
+var (
+	// ErrUnknownCodec indicates the codec other than raw and LPP.
+	ErrUnknownCodec = errors.New("unknown payload codec")
+
+	errOpenCodecsFile  = errors.New("unable to open codecs configuration file")
+	errParseCodecsFile = errors.New("unable to parse codecs configuration file")
+	errReadScript      = errors.New("unable to read codec script")
+	errScriptResult    = errors.New("codec script returned invalid result")
+)
+
+// Codec decodes the raw payloads of the LoRa devices.
+type Codec interface {
+	// Decode decodes the raw payload of the message received from the
+	// device of the application on the port. It returns the decoded
+	// payload and its content type, which is empty for the raw payloads.
+	Decode(appID string, fPort int, msg messaging.Message) ([]byte, string, error)
+}
+
+// CodecConfig contains the codecs of the LoRa applications.
+type CodecConfig struct {
+	// Default is the codec of the applications without their own codec.
+	Default string
+
+	// Applications maps the application IDs to their codecs.
+	Applications map[string]string
+
+	// Scripts maps the application IDs to the sources of the Lua scripts
+	// decoding their payloads. Scripts take precedence over the codecs.
+	Scripts map[string]string
+
+	// Timeout limits the execution time of the script per message.
+	Timeout time.Duration
+}
+
+var _ Codec = (*codec)(nil)
+
+type codec struct {
+	def     string
+	apps    map[string]string
+	scripts map[string]transformers.Transformer
+}
+
+// NewCodec returns the codec decoding the payloads of each application
+// using the Lua script or the codec configured for it.
+func NewCodec(cfg CodecConfig) (Codec, error) {
+	c := codec{
+		def:     cfg.Default,
+		apps:    make(map[string]string),
+		scripts: make(map[string]transformers.Transformer),
+	}
+	if c.def == "" {
+		c.def = RawCodec
+	}
+	if err := validCodec(c.def); err != nil {
+		return nil, err
+	}
+
+	for app, name := range cfg.Applications {
+		if err := validCodec(name); err != nil {
+			return nil, err
+		}
+		c.apps[app] = name
+	}
+
+	for app, src := range cfg.Scripts {
+		t, err := lua.New(lua.Config{Script: src, Timeout: cfg.Timeout})
+		if err != nil {
+			return nil, err
+		}
+		c.scripts[app] = t
+	}
+
+	return c, nil
+}
+
+func validCodec(name string) error {
+	if name != RawCodec && name != LPPCodec {
+		return errors.Wrap(ErrUnknownCodec, errors.New(name))
+	}
+	return nil
+}
+
+func (c codec) Decode(appID string, fPort int, msg messaging.Message) ([]byte, string, error) {
+	if t, ok := c.scripts[appID]; ok {
+		// Scripts receive the port as the message subtopic.
+		msg.Subtopic = strconv.Itoa(fPort)
+		return decodeScript(t, msg)
+	}
+
+	name, ok := c.apps[appID]
+	if !ok {
+		name = c.def
+	}
+
+	switch name {
+	case LPPCodec:
+		payload, err := encodeLPP(msg.Payload)
+		if err != nil {
+			return nil, "", errors.Wrap(ErrMalformedMessage, err)
+		}
+		return payload, mfsenml.JSON, nil
+	default:
+		return msg.Payload, "", nil
+	}
+}
+
+// encodeLPP converts Cayenne LPP payload to SenML JSON.
+func encodeLPP(payload []byte) ([]byte, error) {
+	p, err := lpp.Decode(payload)
+	if err != nil {
+		return nil, err
+	}
+	return senml.Encode(p, senml.JSON)
+}
+
+// decodeScript transforms the message using the Lua script and encodes the
+// resulting records as SenML JSON.
+func decodeScript(t transformers.Transformer, msg messaging.Message) ([]byte, string, error) {
+	res, err := t.Transform(msg)
+	if err != nil {
+		return nil, "", errors.Wrap(ErrMalformedMessage, err)
+	}
+	msgs, ok := res.([]mfsenml.Message)
+	if !ok {
+		return nil, "", errors.Wrap(ErrMalformedMessage, errScriptResult)
+	}
+
+	p := senml.Pack{Records: make([]senml.Record, len(msgs))}
+	for i, m := range msgs {
+		p.Records[i] = senml.Record{
+			Name:        m.Name,
+			Unit:        m.Unit,
+			Time:        m.Time,
+			UpdateTime:  m.UpdateTime,
+			Value:       m.Value,
+			StringValue: m.StringValue,
+			DataValue:   m.DataValue,
+			BoolValue:   m.BoolValue,
+			Sum:         m.Sum,
+		}
+	}
+
+	payload, err := senml.Encode(p, senml.JSON)
+	if err != nil {
+		return nil, "", errors.Wrap(ErrMalformedMessage, err)
+	}
+
+	return payload, mfsenml.JSON, nil
+}
+
+type codecs struct {
+	Default      string            `toml:"default"`
+	Timeout      string            `toml:"timeout"`
+	Applications map[string]string `toml:"applications"`
+	Scripts      map[string]string `toml:"scripts"`
+}
+
+type codecsConfig struct {
+	Codecs codecs `toml:"codecs"`
+}
+
+// LoadCodecConfig loads the codecs listed in the "codecs" section of the
+// configuration file. The relative script paths are resolved against the
+// configuration file directory.
+func LoadCodecConfig(path string) (CodecConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return CodecConfig{}, errors.Wrap(errOpenCodecsFile, err)
+	}
+
+	var cfg codecsConfig
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return CodecConfig{}, errors.Wrap(errParseCodecsFile, err)
+	}
+	c := cfg.Codecs
+
+	ret := CodecConfig{
+		Default:      c.Default,
+		Applications: c.Applications,
+		Scripts:      make(map[string]string),
+	}
+	if c.Timeout != "" {
+		if ret.Timeout, err = time.ParseDuration(c.Timeout); err != nil {
+			return CodecConfig{}, errors.Wrap(errParseCodecsFile, err)
+		}
+	}
+
+	dir := filepath.Dir(path)
+	for app, p := range c.Scripts {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		src, err := ioutil.ReadFile(p)
+		if err != nil {
+			return CodecConfig{}, errors.Wrap(errReadScript, err)
+		}
+		ret.Scripts[app] = string(src)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,207 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lora_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/lua"
+	mfsenml "github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	appID3 = "appID-3"
+	script = `
+function transform(msg)
+  return {bn = msg.publisher .. ":", n = "port_" .. msg.subtopic, v = string.byte(msg.payload, 1)}
+end
+`
+)
+
+func TestNewCodec(t *testing.T) {
+	cases := []struct {
+		desc string
+		cfg  lora.CodecConfig
+		err  error
+	}{
+		{
+			desc: "create codec with default configuration",
+			cfg:  lora.CodecConfig{},
+			err:  nil,
+		},
+		{
+			desc: "create codec with unknown default codec",
+			cfg:  lora.CodecConfig{Default: "wrong"},
+			err:  lora.ErrUnknownCodec,
+		},
+		{
+			desc: "create codec with unknown application codec",
+			cfg:  lora.CodecConfig{Applications: map[string]string{appID: "wrong"}},
+			err:  lora.ErrUnknownCodec,
+		},
+		{
+			desc: "create codec with invalid script",
+			cfg:  lora.CodecConfig{Scripts: map[string]string{appID: "function transform("}},
+			err:  lua.ErrCompile,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := lora.NewCodec(tc.cfg)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestDecode(t *testing.T) {
+	codec, err := lora.NewCodec(lora.CodecConfig{
+		Default:      lora.LPPCodec,
+		Applications: map[string]string{appID2: lora.RawCodec},
+		Scripts:      map[string]string{appID3: script},
+		Timeout:      time.Second,
+	})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	// Temperature of 27.2 Cel on channel 3.
+	lppPayload := []byte{0x03, 0x67, 0x01, 0x10}
+
+	cases := []struct {
+		desc        string
+		appID       string
+		payload     []byte
+		decoded     string
+		contentType string
+		err         error
+	}{
+		{
+			desc:        "decode payload using default codec",
+			appID:       appID,
+			payload:     lppPayload,
+			decoded:     `[{"n":"temperature_3","u":"Cel","v":27.2}]`,
+			contentType: mfsenml.JSON,
+			err:         nil,
+		},
+		{
+			desc:    "decode invalid payload using default codec",
+			appID:   appID,
+			payload: []byte{0x03, 0xff, 0x01},
+			err:     lora.ErrMalformedMessage,
+		},
+		{
+			desc:        "decode payload using application codec",
+			appID:       appID2,
+			payload:     lppPayload,
+			decoded:     string(lppPayload),
+			contentType: "",
+			err:         nil,
+		},
+		{
+			desc:        "decode payload using application script",
+			appID:       appID3,
+			payload:     []byte{0x2a},
+			decoded:     fmt.Sprintf(`[{"n":"%s:port_10","t":1,"v":42}]`, thingID),
+			contentType: mfsenml.JSON,
+			err:         nil,
+		},
+		{
+			desc:    "decode empty payload using application script",
+			appID:   appID3,
+			payload: []byte{},
+			err:     lora.ErrMalformedMessage,
+		},
+	}
+
+	for _, tc := range cases {
+		msg := messaging.Message{
+			Channel:   chanID,
+			Publisher: thingID,
+			Payload:   tc.payload,
+			Created:   1e9,
+		}
+		payload, contentType, err := codec.Decode(tc.appID, 10, msg)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.contentType, contentType, fmt.Sprintf("%s: expected content type %s got %s\n", tc.desc, tc.contentType, contentType))
+		if tc.err != nil {
+			continue
+		}
+		if tc.contentType == mfsenml.JSON {
+			assert.JSONEq(t, tc.decoded, string(payload), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.decoded, payload))
+			continue
+		}
+		assert.Equal(t, tc.decoded, string(payload), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.decoded, payload))
+	}
+}
+
+func TestLoadCodecConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lora")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "app.lua"), []byte(script), 0644)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	valid := fmt.Sprintf(`
+[codecs]
+default = "lpp"
+timeout = "100ms"
+
+[codecs.applications]
+"%s" = "raw"
+
+[codecs.scripts]
+"%s" = "app.lua"
+`, appID2, appID3)
+	missing := `
+[codecs.scripts]
+"app" = "missing.lua"
+`
+
+	cases := []struct {
+		desc    string
+		content string
+		cfg     lora.CodecConfig
+		err     error
+	}{
+		{
+			desc:    "load valid configuration",
+			content: valid,
+			cfg: lora.CodecConfig{
+				Default:      lora.LPPCodec,
+				Applications: map[string]string{appID2: lora.RawCodec},
+				Scripts:      map[string]string{appID3: script},
+				Timeout:      100 * time.Millisecond,
+			},
+			err: nil,
+		},
+		{
+			desc:    "load configuration with invalid timeout",
+			content: "[codecs]\ntimeout = \"wrong\"\n",
+			err:     errors.New("unable to parse codecs configuration file"),
+		},
+		{
+			desc:    "load configuration with missing script",
+			content: missing,
+			err:     errors.New("unable to read codec script"),
+		},
+	}
+
+	for i, tc := range cases {
+		path := filepath.Join(dir, fmt.Sprintf("config-%d.toml", i))
+		err := ioutil.WriteFile(path, []byte(tc.content), 0644)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+		cfg, err := lora.LoadCodecConfig(path)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.cfg, cfg, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.cfg, cfg))
+	}
+}
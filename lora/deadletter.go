@@ -0,0 +1,79 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lora
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	broker "github.com/nats-io/nats.go"
+)
+
+var errDeadLetter = errors.New("failed to publish uplink to dead letter")
+
+// DeadLetter specifies the API for publishing the uplinks which failed to
+// be decoded.
+type DeadLetter interface {
+	// Publish publishes the payload received on the LoRa MQTT broker topic
+	// along with the error which caused the failure.
+	Publish(topic string, payload []byte, err error) error
+
+	// Close closes the dead letter connection.
+	Close()
+}
+
+// Letter is published to the dead letter subject. It contains the uplink
+// payload as received, along with the error metadata.
+type Letter struct {
+	Error   string    `json:"error"`
+	Topic   string    `json:"topic"`
+	Time    time.Time `json:"time"`
+	Payload []byte    `json:"payload"`
+}
+
+type deadLetter struct {
+	conn    *broker.Conn
+	subject string
+}
+
+// NewDeadLetter returns dead letter which publishes the uplinks to the NATS
+// subject as JSON encoded Letter, with the payload base64 encoded. The
+// subject should not start with "channels.", so that the dead letters are
+// not consumed as messages.
+func NewDeadLetter(url, subject string) (DeadLetter, error) {
+	conn, err := broker.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deadLetter{
+		conn:    conn,
+		subject: subject,
+	}, nil
+}
+
+func (dl *deadLetter) Publish(topic string, payload []byte, err error) error {
+	letter := Letter{
+		Error:   err.Error(),
+		Topic:   topic,
+		Time:    time.Now().UTC(),
+		Payload: payload,
+	}
+
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return errors.Wrap(errDeadLetter, err)
+	}
+	if err := dl.conn.Publish(dl.subject, data); err != nil {
+		return errors.Wrap(errDeadLetter, err)
+	}
+
+	return nil
+}
+
+func (dl *deadLetter) Close() {
+	dl.conn.Flush()
+	dl.conn.Close()
+}
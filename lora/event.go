@@ -0,0 +1,101 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lora
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mainflux/senml"
+)
+
+const (
+	// JoinEvent is the event of the device joining the network.
+	JoinEvent = "join"
+	// AckEvent is the event of the device acknowledging the downlink.
+	AckEvent = "ack"
+	// ErrorEvent is the event of the device related error.
+	ErrorEvent = "error"
+	// StatusEvent is the event of the device battery and link margin status.
+	StatusEvent = "status"
+
+	// EventsSubtopic is the subtopic prefix of the messages carrying the
+	// device events. The event subtopic is events.<type>.
+	EventsSubtopic = "events"
+)
+
+// EventTypes are the types of LoRa Server events forwarded as the device
+// events. The event type is the last level of the event topic.
+var EventTypes = []string{JoinEvent, AckEvent, ErrorEvent, StatusEvent}
+
+// Event lora device event (https://www.chirpstack.io/application-server/integrations/events)
+type Event struct {
+	Type                    string  `json:"-"`
+	ApplicationID           string  `json:"applicationID"`
+	DevEUI                  string  `json:"devEUI"`
+	DevAddr                 string  `json:"devAddr"`
+	Acknowledged            bool    `json:"acknowledged"`
+	FCnt                    int     `json:"fCnt"`
+	ErrorType               string  `json:"type"`
+	Error                   string  `json:"error"`
+	Margin                  int     `json:"margin"`
+	ExternalPowerSource     bool    `json:"externalPowerSource"`
+	BatteryLevelUnavailable bool    `json:"batteryLevelUnavailable"`
+	BatteryLevel            float64 `json:"batteryLevel"`
+}
+
+// DecodeEvent decodes LoRa Server event received on the topic.
+func DecodeEvent(topic string, payload []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return Event{}, ErrMalformedMessage
+	}
+
+	e.Type = topic[strings.LastIndex(topic, "/")+1:]
+	return e, nil
+}
+
+// pack converts the event to the SenML records taken at the given time.
+func (e Event) pack(t time.Time) (senml.Pack, error) {
+	tm := float64(t.UnixNano()) / 1e9
+	var records []senml.Record
+	switch e.Type {
+	case JoinEvent:
+		joined := true
+		records = []senml.Record{
+			{Name: "join", BoolValue: &joined},
+			{Name: "dev_addr", StringValue: &e.DevAddr},
+		}
+	case AckEvent:
+		records = []senml.Record{
+			{Name: "ack", BoolValue: &e.Acknowledged},
+		}
+	case ErrorEvent:
+		msg := e.Error
+		if e.ErrorType != "" {
+			msg = fmt.Sprintf("%s: %s", e.ErrorType, e.Error)
+		}
+		records = []senml.Record{
+			{Name: "error", StringValue: &msg},
+		}
+	case StatusEvent:
+		margin := float64(e.Margin)
+		records = []senml.Record{
+			{Name: "margin", Unit: "dB", Value: &margin},
+			{Name: "external_power_source", BoolValue: &e.ExternalPowerSource},
+		}
+		if !e.BatteryLevelUnavailable && !e.ExternalPowerSource {
+			records = append(records, senml.Record{Name: "battery_level", Unit: "%EL", Value: &e.BatteryLevel})
+		}
+	default:
+		return senml.Pack{}, ErrMalformedMessage
+	}
+
+	for i := range records {
+		records[i].Time = tm
+	}
+	return senml.Pack{Records: records}, nil
+}
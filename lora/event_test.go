@@ -0,0 +1,153 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lora_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/lora/mocks"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	mfsenml "github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publisher struct {
+	msg messaging.Message
+}
+
+func (pub *publisher) Publish(_ string, msg messaging.Message) error {
+	pub.msg = msg
+	return nil
+}
+
+func TestDecodeEvent(t *testing.T) {
+	cases := []struct {
+		desc    string
+		topic   string
+		payload string
+		event   lora.Event
+		err     error
+	}{
+		{
+			desc:    "decode join event",
+			topic:   "application/appID-1/device/devEUI-1/join",
+			payload: `{"applicationID":"appID-1","devEUI":"devEUI-1","devAddr":"06682ea2"}`,
+			event:   lora.Event{Type: lora.JoinEvent, ApplicationID: appID, DevEUI: devEUI, DevAddr: "06682ea2"},
+			err:     nil,
+		},
+		{
+			desc:    "decode status event",
+			topic:   "application/appID-1/device/devEUI-1/status",
+			payload: `{"applicationID":"appID-1","devEUI":"devEUI-1","margin":6,"batteryLevel":75.5}`,
+			event:   lora.Event{Type: lora.StatusEvent, ApplicationID: appID, DevEUI: devEUI, Margin: 6, BatteryLevel: 75.5},
+			err:     nil,
+		},
+		{
+			desc:    "decode invalid event",
+			topic:   "application/appID-1/device/devEUI-1/status",
+			payload: `{"applicationID":`,
+			err:     lora.ErrMalformedMessage,
+		},
+	}
+
+	for _, tc := range cases {
+		e, err := lora.DecodeEvent(tc.topic, []byte(tc.payload))
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.event, e, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.event, e))
+	}
+}
+
+func TestPublishEvent(t *testing.T) {
+	pub := &publisher{}
+	codec, err := lora.NewCodec(lora.CodecConfig{})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	svc := lora.New(pub, mocks.NewDownlinker(), nil, mocks.NewRouteMap(), mocks.NewRouteMap(), mocks.NewRouteMap(), mocks.NewRouteMap(), codec)
+
+	err = svc.CreateChannel(nil, chanID, appID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	err = svc.CreateThing(nil, thingID, devEUI)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	err = svc.ConnectThing(nil, chanID, thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	cases := []struct {
+		desc     string
+		event    lora.Event
+		subtopic string
+		names    []string
+		err      error
+	}{
+		{
+			desc:     "publish join event",
+			event:    lora.Event{Type: lora.JoinEvent, ApplicationID: appID, DevEUI: devEUI, DevAddr: "06682ea2"},
+			subtopic: "events.join",
+			names:    []string{"join", "dev_addr"},
+			err:      nil,
+		},
+		{
+			desc:     "publish ack event",
+			event:    lora.Event{Type: lora.AckEvent, ApplicationID: appID, DevEUI: devEUI, Acknowledged: true},
+			subtopic: "events.ack",
+			names:    []string{"ack"},
+			err:      nil,
+		},
+		{
+			desc:     "publish error event",
+			event:    lora.Event{Type: lora.ErrorEvent, ApplicationID: appID, DevEUI: devEUI, ErrorType: "UPLINK_CODEC", Error: "failed"},
+			subtopic: "events.error",
+			names:    []string{"error"},
+			err:      nil,
+		},
+		{
+			desc:     "publish status event",
+			event:    lora.Event{Type: lora.StatusEvent, ApplicationID: appID, DevEUI: devEUI, Margin: 6, BatteryLevel: 75.5},
+			subtopic: "events.status",
+			names:    []string{"margin", "external_power_source", "battery_level"},
+			err:      nil,
+		},
+		{
+			desc:     "publish status event without battery level",
+			event:    lora.Event{Type: lora.StatusEvent, ApplicationID: appID, DevEUI: devEUI, Margin: 6, BatteryLevelUnavailable: true},
+			subtopic: "events.status",
+			names:    []string{"margin", "external_power_source"},
+			err:      nil,
+		},
+		{
+			desc:  "publish event of unknown type",
+			event: lora.Event{Type: "txack", ApplicationID: appID, DevEUI: devEUI},
+			err:   lora.ErrMalformedMessage,
+		},
+		{
+			desc:  "publish event of unmapped device",
+			event: lora.Event{Type: lora.JoinEvent, ApplicationID: appID, DevEUI: devEUI2},
+			err:   lora.ErrNotFoundDev,
+		},
+	}
+
+	for _, tc := range cases {
+		pub.msg = messaging.Message{}
+		err := svc.PublishEvent(nil, tc.event)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+		assert.Equal(t, thingID, pub.msg.Publisher, fmt.Sprintf("%s: expected publisher %s got %s\n", tc.desc, thingID, pub.msg.Publisher))
+		assert.Equal(t, chanID, pub.msg.Channel, fmt.Sprintf("%s: expected channel %s got %s\n", tc.desc, chanID, pub.msg.Channel))
+		assert.Equal(t, tc.subtopic, pub.msg.Subtopic, fmt.Sprintf("%s: expected subtopic %s got %s\n", tc.desc, tc.subtopic, pub.msg.Subtopic))
+		assert.Equal(t, mfsenml.JSON, pub.msg.ContentType, fmt.Sprintf("%s: expected content type %s got %s\n", tc.desc, mfsenml.JSON, pub.msg.ContentType))
+
+		p, err := senml.Decode(pub.msg.Payload, senml.JSON)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		var names []string
+		for _, r := range p.Records {
+			names = append(names, r.Name)
+		}
+		assert.Equal(t, tc.names, names, fmt.Sprintf("%s: expected records %v got %v\n", tc.desc, tc.names, names))
+	}
+}
@@ -1,5 +1,7 @@
 package lora
 
+import "encoding/base64"
+
 // RxInfo receiver parameters
 type RxInfo []struct {
 	Mac       string  `json:"mac"`
@@ -42,3 +44,31 @@ type Message struct {
 	Data                string      `json:"data"`
 	Object              interface{} `json:"object"`
 }
+
+// DownlinkMessage lora downlink msg (https://www.chirpstack.io/application-server/integrations/mqtt/#scheduling-a-downlink)
+type DownlinkMessage struct {
+	Confirmed bool        `json:"confirmed"`
+	FPort     int         `json:"fPort"`
+	Data      string      `json:"data,omitempty"`
+	Object    interface{} `json:"object,omitempty"`
+}
+
+// validate returns ErrMalformedMessage if the port is out of the application
+// range or the downlink carries neither or both of base64 data and object.
+func (dm DownlinkMessage) validate() error {
+	if dm.FPort < minFPort || dm.FPort > maxFPort {
+		return ErrMalformedMessage
+	}
+
+	if (dm.Data == "") == (dm.Object == nil) {
+		return ErrMalformedMessage
+	}
+
+	if dm.Data != "" {
+		if _, err := base64.StdEncoding.DecodeString(dm.Data); err != nil {
+			return ErrMalformedMessage
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/mainflux/mainflux/lora"
+)
+
+var _ lora.DeviceRepository = (*deviceRepositoryMock)(nil)
+
+type deviceRepositoryMock struct {
+	devices map[string]lora.Device
+}
+
+// NewDeviceRepository returns mock LoRa Server device metadata repository
+// holding the given devices.
+func NewDeviceRepository(devices ...lora.Device) lora.DeviceRepository {
+	repo := deviceRepositoryMock{devices: map[string]lora.Device{}}
+	for _, d := range devices {
+		repo.devices[d.DevEUI] = d
+	}
+	return repo
+}
+
+func (repo deviceRepositoryMock) RetrieveByEUI(_ context.Context, devEUI string) (lora.Device, error) {
+	d, ok := repo.devices[devEUI]
+	if !ok {
+		return lora.Device{}, lora.ErrNotFoundDev
+	}
+	return d, nil
+}
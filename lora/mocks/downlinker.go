@@ -0,0 +1,30 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import "github.com/mainflux/mainflux/lora"
+
+var _ lora.Downlinker = (*Downlinker)(nil)
+
+// Downlinker is the mock downlinker which keeps the last downlink message
+// and its target.
+type Downlinker struct {
+	AppID   string
+	DevEUI  string
+	Message lora.DownlinkMessage
+}
+
+// NewDownlinker returns mock downlinker which keeps the last downlink
+// message.
+func NewDownlinker() *Downlinker {
+	return &Downlinker{}
+}
+
+// Downlink keeps the downlink message and its target.
+func (d *Downlinker) Downlink(appID, devEUI string, dm lora.DownlinkMessage) error {
+	d.AppID = appID
+	d.DevEUI = devEUI
+	d.Message = dm
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package mqtt contains the downlink implementation for the legacy LoRa
+// Server MQTT integration.
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+// DownlinkTopic is the topic of the LoRa Server downlink messages.
+const DownlinkTopic = "application/%s/device/%s/tx"
+
+var _ lora.Downlinker = (*downlinker)(nil)
+
+type downlinker struct {
+	publisher messaging.Publisher
+}
+
+// NewDownlinker returns the downlinker publishing the downlink messages to
+// the LoRa Server MQTT broker.
+func NewDownlinker(publisher messaging.Publisher) lora.Downlinker {
+	return downlinker{publisher: publisher}
+}
+
+func (d downlinker) Downlink(appID, devEUI string, dm lora.DownlinkMessage) error {
+	payload, err := json.Marshal(dm)
+	if err != nil {
+		return err
+	}
+
+	msg := messaging.Message{
+		Payload: payload,
+		Created: time.Now().UnixNano(),
+	}
+
+	return d.publisher.Publish(fmt.Sprintf(DownlinkTopic, appID, devEUI), msg)
+}
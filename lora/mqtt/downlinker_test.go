@@ -0,0 +1,55 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/lora/mqtt"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publisher struct {
+	topic string
+	msg   messaging.Message
+}
+
+func (pub *publisher) Publish(topic string, msg messaging.Message) error {
+	pub.topic = topic
+	pub.msg = msg
+	return nil
+}
+
+func TestDownlink(t *testing.T) {
+	pub := &publisher{}
+	dl := mqtt.NewDownlinker(pub)
+
+	cases := []struct {
+		desc    string
+		dm      lora.DownlinkMessage
+		payload string
+	}{
+		{
+			desc:    "downlink data",
+			dm:      lora.DownlinkMessage{Confirmed: true, FPort: 10, Data: "AQI="},
+			payload: `{"confirmed":true,"fPort":10,"data":"AQI="}`,
+		},
+		{
+			desc:    "downlink object",
+			dm:      lora.DownlinkMessage{FPort: 2, Object: map[string]interface{}{"led": true}},
+			payload: `{"confirmed":false,"fPort":2,"object":{"led":true}}`,
+		},
+	}
+
+	for _, tc := range cases {
+		err := dl.Downlink("appID", "devEUI", tc.dm)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, "application/appID/device/devEUI/tx", pub.topic, fmt.Sprintf("%s: unexpected topic %s\n", tc.desc, pub.topic))
+		assert.JSONEq(t, tc.payload, string(pub.msg.Payload), fmt.Sprintf("%s: unexpected payload\n", tc.desc))
+	}
+}
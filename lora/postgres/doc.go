@@ -0,0 +1,6 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package postgres contains the route map repository implementation using
+// PostgreSQL as the underlying database.
+package postgres
@@ -0,0 +1,83 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mainflux/mainflux/lora"
+)
+
+var _ lora.RouteMapRepository = (*routeMap)(nil)
+
+type routeMap struct {
+	db     *sqlx.DB
+	prefix string
+}
+
+// NewRouteMapRepository returns PostgreSQL route map implementation. Route
+// maps of different kinds share the same table and are told apart by prefix.
+func NewRouteMapRepository(db *sqlx.DB, prefix string) lora.RouteMapRepository {
+	return &routeMap{
+		db:     db,
+		prefix: prefix,
+	}
+}
+
+func (rm *routeMap) Save(ctx context.Context, mfxID, loraID string) error {
+	q := `INSERT INTO route_maps (prefix, mfx_id, lora_id) VALUES ($1, $2, $3)
+	      ON CONFLICT (prefix, mfx_id) DO UPDATE SET lora_id = EXCLUDED.lora_id, saved_at = now()`
+
+	_, err := rm.db.ExecContext(ctx, q, rm.prefix, mfxID, loraID)
+	return err
+}
+
+// Get looks the ID up as the Mainflux ID first and as the LoRa ID then, in
+// which case the most recently saved Mainflux ID is returned, the same way
+// the Redis route map keeps the last mapping.
+func (rm *routeMap) Get(ctx context.Context, id string) (string, error) {
+	q := `SELECT lora_id FROM route_maps WHERE prefix = $1 AND mfx_id = $2`
+
+	var val string
+	err := rm.db.QueryRowxContext(ctx, q, rm.prefix, id).Scan(&val)
+	if err == nil {
+		return val, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", err
+	}
+
+	q = `SELECT mfx_id FROM route_maps WHERE prefix = $1 AND lora_id = $2
+	     ORDER BY saved_at DESC LIMIT 1`
+
+	if err := rm.db.QueryRowxContext(ctx, q, rm.prefix, id).Scan(&val); err != nil {
+		if err == sql.ErrNoRows {
+			return "", lora.ErrNotFound
+		}
+		return "", err
+	}
+
+	return val, nil
+}
+
+func (rm *routeMap) Remove(ctx context.Context, mfxID string) error {
+	q := `DELETE FROM route_maps WHERE prefix = $1 AND mfx_id = $2`
+
+	res, err := rm.db.ExecContext(ctx, q, rm.prefix, mfxID)
+	if err != nil {
+		return err
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return lora.ErrNotFound
+	}
+
+	return nil
+}
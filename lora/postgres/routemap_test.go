@@ -0,0 +1,129 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/lora"
+	"github.com/mainflux/mainflux/lora/postgres"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	thingID  = "thingID-1"
+	thingID2 = "thingID-2"
+	devEUI   = "devEUI-1"
+	devEUI2  = "devEUI-2"
+)
+
+func TestRouteMapSave(t *testing.T) {
+	rm := postgres.NewRouteMapRepository(db, "thing_save")
+
+	cases := []struct {
+		desc   string
+		mfxID  string
+		loraID string
+	}{
+		{
+			desc:   "save new route map",
+			mfxID:  thingID,
+			loraID: devEUI,
+		},
+		{
+			desc:   "save existing route map",
+			mfxID:  thingID,
+			loraID: devEUI2,
+		},
+	}
+
+	for _, tc := range cases {
+		err := rm.Save(context.Background(), tc.mfxID, tc.loraID)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+
+		loraID, err := rm.Get(context.Background(), tc.mfxID)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.loraID, loraID, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.loraID, loraID))
+	}
+}
+
+func TestRouteMapGet(t *testing.T) {
+	rm := postgres.NewRouteMapRepository(db, "thing_get")
+	other := postgres.NewRouteMapRepository(db, "channel_get")
+
+	err := rm.Save(context.Background(), thingID, devEUI)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	err = rm.Save(context.Background(), thingID2, devEUI)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	err = other.Save(context.Background(), thingID, devEUI2)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	cases := []struct {
+		desc string
+		id   string
+		val  string
+		err  error
+	}{
+		{
+			desc: "get route map by Mainflux ID",
+			id:   thingID,
+			val:  devEUI,
+			err:  nil,
+		},
+		{
+			desc: "get route map by LoRa ID",
+			id:   devEUI,
+			val:  thingID2,
+			err:  nil,
+		},
+		{
+			desc: "get route map of another prefix",
+			id:   devEUI2,
+			val:  "",
+			err:  lora.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		val, err := rm.Get(context.Background(), tc.id)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.val, val, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.val, val))
+	}
+}
+
+func TestRouteMapRemove(t *testing.T) {
+	rm := postgres.NewRouteMapRepository(db, "thing_remove")
+
+	err := rm.Save(context.Background(), thingID, devEUI)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	cases := []struct {
+		desc  string
+		mfxID string
+		err   error
+	}{
+		{
+			desc:  "remove existing route map",
+			mfxID: thingID,
+			err:   nil,
+		},
+		{
+			desc:  "remove removed route map",
+			mfxID: thingID,
+			err:   lora.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		err := rm.Remove(context.Background(), tc.mfxID)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	_, err = rm.Get(context.Background(), devEUI)
+	assert.True(t, errors.Contains(err, lora.ErrNotFound), fmt.Sprintf("get removed route map: expected %s got %s\n", lora.ErrNotFound, err))
+}
@@ -3,6 +3,7 @@ package redis
 type createThingEvent struct {
 	id         string
 	loraDevEUI string
+	loraAppID  string
 }
 
 type removeThingEvent struct {
@@ -60,3 +60,65 @@ func (mr *routerMap) Remove(ctx context.Context, mfxID string) error {
 	lkey := fmt.Sprintf("%s:%s", mr.prefix, lval)
 	return mr.client.Del(ctx, mkey, lkey).Err()
 }
+
+var _ lora.RouteMapRepository = (*routeMapCache)(nil)
+
+type routeMapCache struct {
+	client *redis.Client
+	prefix string
+	repo   lora.RouteMapRepository
+}
+
+// NewRouteMapCache returns redis route map cache of the persistent route map
+// repository. Route maps are saved and removed from both, while the missing
+// cache entries are read from the repository and cached.
+func NewRouteMapCache(client *redis.Client, prefix string, repo lora.RouteMapRepository) lora.RouteMapRepository {
+	return &routeMapCache{
+		client: client,
+		prefix: prefix,
+		repo:   repo,
+	}
+}
+
+func (rc *routeMapCache) Save(ctx context.Context, mfxID, loraID string) error {
+	if err := rc.repo.Save(ctx, mfxID, loraID); err != nil {
+		return err
+	}
+
+	tkey := fmt.Sprintf("%s:%s", rc.prefix, mfxID)
+	lkey := fmt.Sprintf("%s:%s", rc.prefix, loraID)
+	return rc.client.MSet(ctx, tkey, loraID, lkey, mfxID).Err()
+}
+
+func (rc *routeMapCache) Get(ctx context.Context, id string) (string, error) {
+	key := fmt.Sprintf("%s:%s", rc.prefix, id)
+	val, err := rc.client.Get(ctx, key).Result()
+	if err == nil {
+		return val, nil
+	}
+
+	if val, err = rc.repo.Get(ctx, id); err != nil {
+		return "", err
+	}
+
+	if err := rc.client.Set(ctx, key, val, 0).Err(); err != nil {
+		return "", err
+	}
+
+	return val, nil
+}
+
+func (rc *routeMapCache) Remove(ctx context.Context, mfxID string) error {
+	loraID, err := rc.repo.Get(ctx, mfxID)
+	if err != nil {
+		return err
+	}
+
+	if err := rc.repo.Remove(ctx, mfxID); err != nil {
+		return err
+	}
+
+	mkey := fmt.Sprintf("%s:%s", rc.prefix, mfxID)
+	lkey := fmt.Sprintf("%s:%s", rc.prefix, loraID)
+	return rc.client.Del(ctx, mkey, lkey).Err()
+}
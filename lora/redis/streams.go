@@ -32,6 +32,8 @@ const (
 	channelRemove = channelPrefix + "remove"
 
 	exists = "BUSYGROUP Consumer Group name already exists"
+
+	replayBatch = 100
 )
 
 var (
@@ -41,13 +43,17 @@ var (
 
 	errMetadataAppID = errors.New("application ID not found in channel metadatada")
 
-	errMetadataDevEUI = errors.New("device EUI not found in thing metadatada")
+	errMetadataDevEUI = errors.New("device EUI or application ID not found in thing metadatada")
 )
 
 // Subscriber represents event source for things and channels provisioning.
 type Subscriber interface {
 	// Subscribes to geven subject and receives events.
 	Subscribe(context.Context, string) error
+
+	// Replay handles all the events retained in the things event stream
+	// again, rebuilding the route maps, and returns the number of events.
+	Replay(context.Context) (int, error)
 }
 
 type eventStore struct {
@@ -85,53 +91,7 @@ func (es eventStore) Subscribe(ctx context.Context, subject string) error {
 		}
 
 		for _, msg := range streams[0].Messages {
-			event := msg.Values
-
-			var err error
-			switch event["operation"] {
-			case thingCreate:
-				cte, derr := decodeCreateThing(event)
-				if derr != nil {
-					err = derr
-					break
-				}
-				err = es.svc.CreateThing(ctx, cte.id, cte.loraDevEUI)
-			case thingUpdate:
-				ute, derr := decodeCreateThing(event)
-				if derr != nil {
-					err = derr
-					break
-				}
-				err = es.svc.CreateThing(ctx, ute.id, ute.loraDevEUI)
-
-			case channelCreate:
-				cce, derr := decodeCreateChannel(event)
-				if derr != nil {
-					err = derr
-					break
-				}
-				err = es.svc.CreateChannel(ctx, cce.id, cce.loraAppID)
-			case channelUpdate:
-				uce, derr := decodeCreateChannel(event)
-				if derr != nil {
-					err = derr
-					break
-				}
-				err = es.svc.CreateChannel(ctx, uce.id, uce.loraAppID)
-			case thingRemove:
-				rte := decodeRemoveThing(event)
-				err = es.svc.RemoveThing(ctx, rte.id)
-			case channelRemove:
-				rce := decodeRemoveChannel(event)
-				err = es.svc.RemoveChannel(ctx, rce.id)
-			case thingConnect:
-				tce := decodeConnectionThing(event)
-				err = es.svc.ConnectThing(ctx, tce.chanID, tce.thingID)
-			case thingDisconnect:
-				tde := decodeConnectionThing(event)
-				err = es.svc.DisconnectThing(ctx, tde.chanID, tde.thingID)
-			}
-			if err != nil && err != errMetadataType {
+			if err := es.handle(ctx, msg.Values); err != nil {
 				es.logger.Warn(fmt.Sprintf("Failed to handle event sourcing: %s", err.Error()))
 				break
 			}
@@ -140,6 +100,93 @@ func (es eventStore) Subscribe(ctx context.Context, subject string) error {
 	}
 }
 
+func (es eventStore) Replay(ctx context.Context) (int, error) {
+	start, cnt := "-", 0
+	for {
+		msgs, err := es.client.XRangeN(ctx, stream, start, "+", replayBatch).Result()
+		if err != nil {
+			return cnt, err
+		}
+
+		for _, msg := range msgs {
+			// The start of the range is inclusive, so the last event of the
+			// previous batch is skipped.
+			if msg.ID == start {
+				continue
+			}
+			if err := es.handle(ctx, msg.Values); err != nil {
+				es.logger.Warn(fmt.Sprintf("Failed to replay event %s: %s", msg.ID, err.Error()))
+			}
+			cnt++
+		}
+
+		if len(msgs) < replayBatch {
+			return cnt, nil
+		}
+		start = msgs[len(msgs)-1].ID
+	}
+}
+
+func (es eventStore) handle(ctx context.Context, event map[string]interface{}) error {
+	var err error
+	switch event["operation"] {
+	case thingCreate:
+		cte, derr := decodeCreateThing(event)
+		if derr != nil {
+			err = derr
+			break
+		}
+		err = es.createThing(ctx, cte)
+	case thingUpdate:
+		ute, derr := decodeCreateThing(event)
+		if derr != nil {
+			err = derr
+			break
+		}
+		err = es.createThing(ctx, ute)
+
+	case channelCreate:
+		cce, derr := decodeCreateChannel(event)
+		if derr != nil {
+			err = derr
+			break
+		}
+		err = es.svc.CreateChannel(ctx, cce.id, cce.loraAppID)
+	case channelUpdate:
+		uce, derr := decodeCreateChannel(event)
+		if derr != nil {
+			err = derr
+			break
+		}
+		err = es.svc.CreateChannel(ctx, uce.id, uce.loraAppID)
+	case thingRemove:
+		rte := decodeRemoveThing(event)
+		err = es.svc.RemoveThing(ctx, rte.id)
+	case channelRemove:
+		rce := decodeRemoveChannel(event)
+		err = es.svc.RemoveChannel(ctx, rce.id)
+	case thingConnect:
+		tce := decodeConnectionThing(event)
+		err = es.svc.ConnectThing(ctx, tce.chanID, tce.thingID)
+	case thingDisconnect:
+		tde := decodeConnectionThing(event)
+		err = es.svc.DisconnectThing(ctx, tde.chanID, tde.thingID)
+	}
+	if err == errMetadataType {
+		return nil
+	}
+	return err
+}
+
+// createThing maps the thing to the device, or to the whole application if
+// the thing metadata contains the application ID instead of the device EUI.
+func (es eventStore) createThing(ctx context.Context, cte createThingEvent) error {
+	if cte.loraAppID != "" {
+		return es.svc.CreateAppThing(ctx, cte.id, cte.loraAppID)
+	}
+	return es.svc.CreateThing(ctx, cte.id, cte.loraDevEUI)
+}
+
 func decodeCreateThing(event map[string]interface{}) (createThingEvent, error) {
 	strmeta := read(event, "metadata", "{}")
 	var metadata map[string]interface{}
@@ -161,12 +208,17 @@ func decodeCreateThing(event map[string]interface{}) (createThingEvent, error) {
 		return createThingEvent{}, errMetadataFormat
 	}
 
-	val, ok := lm[keyDevEUI].(string)
+	if val, ok := lm[keyDevEUI].(string); ok {
+		cte.loraDevEUI = val
+		return cte, nil
+	}
+
+	val, ok := lm[keyAppID].(string)
 	if !ok {
 		return createThingEvent{}, errMetadataDevEUI
 	}
 
-	cte.loraDevEUI = val
+	cte.loraAppID = val
 	return cte, nil
 }
 
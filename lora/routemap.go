@@ -3,7 +3,13 @@
 
 package lora
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound indicates a non-existent route map.
+var ErrNotFound = errors.New("route map not found")
 
 // RouteMapRepository store route map between Lora App Server and Mainflux
 type RouteMapRepository interface {
@@ -0,0 +1,27 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lora
+
+import "context"
+
+// Device represents the device metadata kept by LoRa Server.
+type Device struct {
+	DevEUI        string
+	Name          string
+	ApplicationID string
+}
+
+// DeviceRepository specifies the LoRa Server device metadata API.
+type DeviceRepository interface {
+	// RetrieveByEUI retrieves the device having the provided EUI. If the
+	// device doesn't exist, ErrNotFoundDev is returned.
+	RetrieveByEUI(ctx context.Context, devEUI string) (Device, error)
+}
+
+// Downlinker specifies an API for sending the downlink messages through the
+// LoRa Server MQTT integration.
+type Downlinker interface {
+	// Downlink sends the downlink message to the device of the application.
+	Downlink(appID, devEUI string, dm DownlinkMessage) error
+}
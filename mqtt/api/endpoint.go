@@ -0,0 +1,92 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/mainflux/mainflux/mqtt/redis"
+)
+
+func listBlockedEndpoint(bl redis.BlockList, adminToken string) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(listBlockedReq)
+		if err := req.validate(adminToken); err != nil {
+			return nil, err
+		}
+
+		things, err := bl.Things()
+		if err != nil {
+			return nil, err
+		}
+
+		nets, err := bl.Nets()
+		if err != nil {
+			return nil, err
+		}
+
+		return blockListRes{Things: things, Nets: nets}, nil
+	}
+}
+
+func blockThingEndpoint(bl redis.BlockList, adminToken string) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(blockThingReq)
+		if err := req.validate(adminToken); err != nil {
+			return nil, err
+		}
+
+		if err := bl.BlockThing(req.id); err != nil {
+			return nil, err
+		}
+
+		return blockRes{}, nil
+	}
+}
+
+func unblockThingEndpoint(bl redis.BlockList, adminToken string) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(blockThingReq)
+		if err := req.validate(adminToken); err != nil {
+			return nil, err
+		}
+
+		if err := bl.UnblockThing(req.id); err != nil {
+			return nil, err
+		}
+
+		return blockRes{}, nil
+	}
+}
+
+func blockNetEndpoint(bl redis.BlockList, adminToken string) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(blockNetReq)
+		if err := req.validate(adminToken); err != nil {
+			return nil, err
+		}
+
+		if err := bl.BlockNet(req.CIDR); err != nil {
+			return nil, err
+		}
+
+		return blockRes{}, nil
+	}
+}
+
+func unblockNetEndpoint(bl redis.BlockList, adminToken string) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(blockNetReq)
+		if err := req.validate(adminToken); err != nil {
+			return nil, err
+		}
+
+		if err := bl.UnblockNet(req.CIDR); err != nil {
+			return nil, err
+		}
+
+		return blockRes{}, nil
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// +build !test
+
+package api
+
+import (
+	"sync"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/mqtt"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mproxy/pkg/session"
+)
+
+const (
+	directionIn  = "in"
+	directionOut = "out"
+)
+
+var (
+	_ mqtt.Handler        = (*metricsMiddleware)(nil)
+	_ messaging.Publisher = (*publisherMetricsMiddleware)(nil)
+)
+
+type metricsMiddleware struct {
+	mqtt.Handler
+	mu           sync.Mutex
+	connected    map[*session.Client]bool
+	clients      metrics.Gauge
+	messages     metrics.Counter
+	authFailures metrics.Counter
+}
+
+// MetricsMiddleware instruments MQTT handler by tracking the number of
+// connected clients, received messages and authentication failures.
+func MetricsMiddleware(h mqtt.Handler, clients metrics.Gauge, messages, authFailures metrics.Counter) mqtt.Handler {
+	return &metricsMiddleware{
+		Handler:      h,
+		connected:    make(map[*session.Client]bool),
+		clients:      clients,
+		messages:     messages,
+		authFailures: authFailures,
+	}
+}
+
+func (mm *metricsMiddleware) AuthConnect(c *session.Client) error {
+	err := mm.Handler.AuthConnect(c)
+	if err != nil {
+		mm.authFailures.With("method", "connect").Add(1)
+	}
+	return err
+}
+
+func (mm *metricsMiddleware) AuthPublish(c *session.Client, topic *string, payload *[]byte) error {
+	err := mm.Handler.AuthPublish(c, topic, payload)
+	if err != nil {
+		mm.authFailures.With("method", "publish").Add(1)
+	}
+	return err
+}
+
+func (mm *metricsMiddleware) AuthSubscribe(c *session.Client, topics *[]string) error {
+	err := mm.Handler.AuthSubscribe(c, topics)
+	if err != nil {
+		mm.authFailures.With("method", "subscribe").Add(1)
+	}
+	return err
+}
+
+func (mm *metricsMiddleware) Connect(c *session.Client) {
+	// Disconnect is called for the clients that failed to connect as well,
+	// so the connected clients are tracked to keep the gauge accurate.
+	mm.mu.Lock()
+	mm.connected[c] = true
+	mm.mu.Unlock()
+	mm.clients.Add(1)
+
+	mm.Handler.Connect(c)
+}
+
+func (mm *metricsMiddleware) Publish(c *session.Client, topic *string, payload *[]byte) {
+	mm.messages.With("direction", directionIn).Add(1)
+	mm.Handler.Publish(c, topic, payload)
+}
+
+func (mm *metricsMiddleware) PublishQoS(c *session.Client, topic string, payload []byte, qos byte, retain bool) {
+	mm.messages.With("direction", directionIn).Add(1)
+	mm.Handler.PublishQoS(c, topic, payload, qos, retain)
+}
+
+func (mm *metricsMiddleware) Disconnect(c *session.Client) {
+	mm.mu.Lock()
+	connected := mm.connected[c]
+	delete(mm.connected, c)
+	mm.mu.Unlock()
+	if connected {
+		mm.clients.Add(-1)
+	}
+
+	mm.Handler.Disconnect(c)
+}
+
+type publisherMetricsMiddleware struct {
+	pub      messaging.Publisher
+	messages metrics.Counter
+}
+
+// PublisherMetricsMiddleware instruments the publisher used to forward
+// messages to the MQTT broker by tracking the number of sent messages.
+func PublisherMetricsMiddleware(pub messaging.Publisher, messages metrics.Counter) messaging.Publisher {
+	return &publisherMetricsMiddleware{
+		pub:      pub,
+		messages: messages,
+	}
+}
+
+func (pm *publisherMetricsMiddleware) Publish(topic string, msg messaging.Message) error {
+	pm.messages.With("direction", directionOut).Add(1)
+	return pm.pub.Publish(topic, msg)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"crypto/subtle"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+func authorize(token, adminToken string) error {
+	if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		return errUnauthorized
+	}
+
+	return nil
+}
+
+type listBlockedReq struct {
+	token string
+}
+
+func (req listBlockedReq) validate(adminToken string) error {
+	return authorize(req.token, adminToken)
+}
+
+type blockThingReq struct {
+	token string
+	id    string
+}
+
+func (req blockThingReq) validate(adminToken string) error {
+	if err := authorize(req.token, adminToken); err != nil {
+		return err
+	}
+
+	if req.id == "" {
+		return errors.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type blockNetReq struct {
+	token string
+	CIDR  string `json:"cidr"`
+}
+
+func (req blockNetReq) validate(adminToken string) error {
+	if err := authorize(req.token, adminToken); err != nil {
+		return err
+	}
+
+	if req.CIDR == "" {
+		return errors.ErrMalformedEntity
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/mainflux/mainflux"
+)
+
+var (
+	_ mainflux.Response = (*blockListRes)(nil)
+	_ mainflux.Response = (*blockRes)(nil)
+)
+
+type blockListRes struct {
+	Things []string `json:"things"`
+	Nets   []string `json:"nets"`
+}
+
+func (res blockListRes) Code() int {
+	return http.StatusOK
+}
+
+func (res blockListRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res blockListRes) Empty() bool {
+	return false
+}
+
+type blockRes struct{}
+
+func (res blockRes) Code() int {
+	return http.StatusNoContent
+}
+
+func (res blockRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res blockRes) Empty() bool {
+	return true
+}
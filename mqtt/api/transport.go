@@ -0,0 +1,154 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/go-zoo/bone"
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/mqtt/redis"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	contentType = "application/json"
+	cidrKey     = "cidr"
+)
+
+var errUnauthorized = errors.New("missing or invalid credentials provided")
+
+// MakeHandler returns a HTTP handler for health, version and metrics endpoints.
+// If the block list and admin token are provided, the block list management
+// endpoints, available using the admin token, are exposed as well.
+func MakeHandler(bl redis.BlockList, adminToken string) http.Handler {
+	opts := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(encodeError),
+	}
+
+	r := bone.New()
+
+	if bl != nil && adminToken != "" {
+		r.Get("/blocklist", kithttp.NewServer(
+			listBlockedEndpoint(bl, adminToken),
+			decodeListBlocked,
+			encodeResponse,
+			opts...,
+		))
+
+		r.Put("/blocklist/things/:id", kithttp.NewServer(
+			blockThingEndpoint(bl, adminToken),
+			decodeBlockThing,
+			encodeResponse,
+			opts...,
+		))
+
+		r.Delete("/blocklist/things/:id", kithttp.NewServer(
+			unblockThingEndpoint(bl, adminToken),
+			decodeBlockThing,
+			encodeResponse,
+			opts...,
+		))
+
+		r.Post("/blocklist/nets", kithttp.NewServer(
+			blockNetEndpoint(bl, adminToken),
+			decodeBlockNet,
+			encodeResponse,
+			opts...,
+		))
+
+		r.Delete("/blocklist/nets", kithttp.NewServer(
+			unblockNetEndpoint(bl, adminToken),
+			decodeUnblockNet,
+			encodeResponse,
+			opts...,
+		))
+	}
+
+	r.GetFunc("/health", mainflux.Health("mqtt"))
+	r.GetFunc("/version", mainflux.Version("mqtt"))
+	r.Handle("/metrics", promhttp.Handler())
+
+	return r
+}
+
+func decodeListBlocked(_ context.Context, r *http.Request) (interface{}, error) {
+	return listBlockedReq{token: r.Header.Get("Authorization")}, nil
+}
+
+func decodeBlockThing(_ context.Context, r *http.Request) (interface{}, error) {
+	req := blockThingReq{
+		token: r.Header.Get("Authorization"),
+		id:    bone.GetValue(r, "id"),
+	}
+
+	return req, nil
+}
+
+func decodeBlockNet(_ context.Context, r *http.Request) (interface{}, error) {
+	if r.Header.Get("Content-Type") != contentType {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := blockNetReq{token: r.Header.Get("Authorization")}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func decodeUnblockNet(_ context.Context, r *http.Request) (interface{}, error) {
+	req := blockNetReq{
+		token: r.Header.Get("Authorization"),
+		CIDR:  r.URL.Query().Get(cidrKey),
+	}
+
+	return req, nil
+}
+
+func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", contentType)
+
+	if ar, ok := response.(mainflux.Response); ok {
+		for k, v := range ar.Headers() {
+			w.Header().Set(k, v)
+		}
+
+		w.WriteHeader(ar.Code())
+
+		if ar.Empty() {
+			return nil
+		}
+	}
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+func encodeError(_ context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", contentType)
+
+	switch err {
+	case errUnauthorized:
+		w.WriteHeader(http.StatusUnauthorized)
+	case errors.ErrUnsupportedContentType:
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	case io.EOF, errors.ErrMalformedEntity, redis.ErrMalformedNet:
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		switch err.(type) {
+		case *json.SyntaxError:
+			w.WriteHeader(http.StatusBadRequest)
+		case *json.UnmarshalTypeError:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/auth"
+	"github.com/mainflux/mproxy/pkg/session"
+)
+
+// AuthCache represents auth client caching positive authorization
+// decisions locally, in order to avoid remote calls on each publish.
+type AuthCache interface {
+	auth.Client
+
+	// Remove removes the decision for the given channel and thing.
+	Remove(chanID, thingID string)
+
+	// RemoveThing removes all the decisions for the given thing.
+	RemoveThing(thingID string)
+
+	// RemoveChannel removes all the decisions for the given channel.
+	RemoveChannel(chanID string)
+}
+
+var _ AuthCache = (*authCache)(nil)
+
+type authCache struct {
+	auth.Client
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]map[string]time.Time
+	now     func() time.Time
+}
+
+// NewAuthCache returns AuthCache wrapping the given auth client, which keeps
+// authorization decisions for the ttl duration.
+func NewAuthCache(client auth.Client, ttl time.Duration) AuthCache {
+	return &authCache{
+		Client:  client,
+		ttl:     ttl,
+		entries: make(map[string]map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (ac *authCache) Authorize(ctx context.Context, chanID, thingID string) error {
+	ac.mu.RLock()
+	exp, ok := ac.entries[chanID][thingID]
+	ac.mu.RUnlock()
+	if ok && ac.now().Before(exp) {
+		return nil
+	}
+
+	if err := ac.Client.Authorize(ctx, chanID, thingID); err != nil {
+		ac.Remove(chanID, thingID)
+		return err
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	things, ok := ac.entries[chanID]
+	if !ok {
+		things = make(map[string]time.Time)
+		ac.entries[chanID] = things
+	}
+	things[thingID] = ac.now().Add(ac.ttl)
+
+	return nil
+}
+
+func (ac *authCache) Remove(chanID, thingID string) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	delete(ac.entries[chanID], thingID)
+	if len(ac.entries[chanID]) == 0 {
+		delete(ac.entries, chanID)
+	}
+}
+
+func (ac *authCache) RemoveThing(thingID string) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	for chanID, things := range ac.entries {
+		delete(things, thingID)
+		if len(things) == 0 {
+			delete(ac.entries, chanID)
+		}
+	}
+}
+
+func (ac *authCache) RemoveChannel(chanID string) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	delete(ac.entries, chanID)
+}
+
+var _ Handler = (*authCacheHandler)(nil)
+
+type authCacheHandler struct {
+	Handler
+	cache AuthCache
+}
+
+// NewAuthCacheHandler wraps the Handler with removal of the cached
+// authorization decisions of the things that disconnected.
+func NewAuthCacheHandler(h Handler, cache AuthCache) Handler {
+	return &authCacheHandler{
+		Handler: h,
+		cache:   cache,
+	}
+}
+
+func (ah *authCacheHandler) Disconnect(c *session.Client) {
+	ah.Handler.Disconnect(c)
+	if c != nil {
+		ah.cache.RemoveThing(c.Username)
+	}
+}
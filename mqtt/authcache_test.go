@@ -0,0 +1,168 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mproxy/pkg/session"
+	"github.com/stretchr/testify/assert"
+)
+
+const ttl = time.Minute
+
+// authClientMock counts the authorization requests, denying access if err
+// is set.
+type authClientMock struct {
+	err   error
+	calls int
+}
+
+func (ac *authClientMock) Authorize(ctx context.Context, chanID, thingID string) error {
+	ac.calls++
+	return ac.err
+}
+
+func (ac *authClientMock) Identify(ctx context.Context, thingKey string) (string, error) {
+	return thingKey, ac.err
+}
+
+func newFakeAuthCache(client *authClientMock, clock *fakeClock) AuthCache {
+	ac := NewAuthCache(client, ttl).(*authCache)
+	ac.now = clock.Now
+	return ac
+}
+
+func TestAuthCacheAuthorize(t *testing.T) {
+	clock := newFakeClock()
+	client := &authClientMock{}
+	cache := newFakeAuthCache(client, clock)
+
+	// The steps are run in order, sharing the cached decisions.
+	cases := []struct {
+		desc    string
+		advance time.Duration
+		chanID  string
+		err     error
+		wantErr error
+		calls   int
+	}{
+		{
+			desc:   "authorize thing",
+			chanID: chanID,
+			calls:  1,
+		},
+		{
+			desc:    "authorize cached thing",
+			advance: ttl - time.Second,
+			chanID:  chanID,
+			calls:   1,
+		},
+		{
+			desc:   "authorize thing for another channel",
+			chanID: chanID2,
+			calls:  2,
+		},
+		{
+			desc:    "authorize thing after decision expired",
+			advance: time.Second,
+			chanID:  chanID,
+			calls:   3,
+		},
+		{
+			desc:    "authorize thing with refreshed decision",
+			advance: ttl - time.Second,
+			chanID:  chanID,
+			calls:   3,
+		},
+		{
+			desc:    "authorize denied thing after decision expired",
+			advance: time.Second,
+			chanID:  chanID,
+			err:     errAuth,
+			wantErr: errAuth,
+			calls:   4,
+		},
+		{
+			desc:   "authorize thing after access was denied",
+			chanID: chanID,
+			calls:  5,
+		},
+	}
+
+	for _, tc := range cases {
+		clock.advance(tc.advance)
+		client.err = tc.err
+		err := cache.Authorize(context.Background(), tc.chanID, thingID)
+		assert.Equal(t, tc.wantErr, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.wantErr, err))
+		assert.Equal(t, tc.calls, client.calls, fmt.Sprintf("%s: expected %d calls got %d\n", tc.desc, tc.calls, client.calls))
+	}
+}
+
+func TestAuthCacheRemove(t *testing.T) {
+	cases := []struct {
+		desc   string
+		remove func(AuthCache)
+		calls  map[string]map[string]int
+	}{
+		{
+			desc:   "remove decision",
+			remove: func(ac AuthCache) { ac.Remove(chanID, thingID) },
+			calls: map[string]map[string]int{
+				chanID:  {thingID: 1, thingID2: 0},
+				chanID2: {thingID: 0, thingID2: 0},
+			},
+		},
+		{
+			desc:   "remove thing decisions",
+			remove: func(ac AuthCache) { ac.RemoveThing(thingID) },
+			calls: map[string]map[string]int{
+				chanID:  {thingID: 1, thingID2: 0},
+				chanID2: {thingID: 1, thingID2: 0},
+			},
+		},
+		{
+			desc:   "remove channel decisions",
+			remove: func(ac AuthCache) { ac.RemoveChannel(chanID) },
+			calls: map[string]map[string]int{
+				chanID:  {thingID: 1, thingID2: 1},
+				chanID2: {thingID: 0, thingID2: 0},
+			},
+		},
+		{
+			desc: "remove thing decisions on disconnect",
+			remove: func(ac AuthCache) {
+				h := NewAuthCacheHandler(&handlerMock{}, ac)
+				h.Disconnect(&session.Client{Username: thingID2})
+			},
+			calls: map[string]map[string]int{
+				chanID:  {thingID: 0, thingID2: 1},
+				chanID2: {thingID: 0, thingID2: 1},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		client := &authClientMock{}
+		cache := newFakeAuthCache(client, newFakeClock())
+		for _, ch := range []string{chanID, chanID2} {
+			for _, th := range []string{thingID, thingID2} {
+				cache.Authorize(context.Background(), ch, th)
+			}
+		}
+
+		tc.remove(cache)
+
+		for ch, things := range tc.calls {
+			for th, calls := range things {
+				client.calls = 0
+				cache.Authorize(context.Background(), ch, th)
+				assert.Equal(t, calls, client.calls, fmt.Sprintf("%s: expected %d calls for thing %s and channel %s got %d\n", tc.desc, calls, th, ch, client.calls))
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/mqtt/redis"
+	"github.com/mainflux/mproxy/pkg/session"
+)
+
+var errBlocked = errors.New("client is blocked")
+
+var _ Handler = (*blockListHandler)(nil)
+
+type blockListHandler struct {
+	Handler
+	blocked redis.BlockList
+	logger  logger.Logger
+}
+
+// NewBlockListHandler wraps the Handler with the check whether the thing is
+// blocked, which takes place on connect and on each publish. In case the block
+// list is not available, the client is let through in order to keep the
+// adapter available.
+func NewBlockListHandler(h Handler, blocked redis.BlockList, logger logger.Logger) Handler {
+	return &blockListHandler{
+		Handler: h,
+		blocked: blocked,
+		logger:  logger,
+	}
+}
+
+func (bh *blockListHandler) AuthConnect(c *session.Client) error {
+	// Username is verified (or set, for the clients using
+	// certificates) by the wrapped handler.
+	if err := bh.Handler.AuthConnect(c); err != nil {
+		return err
+	}
+	return bh.check(c.Username)
+}
+
+func (bh *blockListHandler) AuthPublish(c *session.Client, topic *string, payload *[]byte) error {
+	if err := bh.Handler.AuthPublish(c, topic, payload); err != nil {
+		return err
+	}
+	return bh.check(c.Username)
+}
+
+func (bh *blockListHandler) check(thingID string) error {
+	blocked, err := bh.blocked.ThingBlocked(thingID)
+	if err != nil {
+		bh.logger.Warn("Failed to check block list: " + err.Error())
+		return nil
+	}
+	if blocked {
+		bh.logger.Info("Blocked thing with ID: " + thingID)
+		return errBlocked
+	}
+	return nil
+}
+
+// NewBlockListMiddleware wraps the HTTP handler, such as the MQTT over WS one,
+// with the check whether the client IP address is blocked. Blocked clients
+// are refused before the connection is upgraded.
+func NewBlockListMiddleware(h http.Handler, blocked redis.BlockList, logger logger.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if err := checkIP(blocked, net.ParseIP(host), logger); err != nil {
+			logger.Info("Refused connection from blocked IP address: " + host)
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// checkIP returns errBlocked if the IP address is blocked. In case the block
+// list is not available, the client is let through.
+func checkIP(blocked redis.BlockList, ip net.IP, logger logger.Logger) error {
+	if blocked == nil || ip == nil {
+		return nil
+	}
+	ok, err := blocked.IPBlocked(ip)
+	if err != nil {
+		logger.Warn("Failed to check block list: " + err.Error())
+		return nil
+	}
+	if ok {
+		return errBlocked
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mproxy/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBlockList = errors.New("block list error")
+
+// blockListMock keeps the blocked things and IP addresses in memory, failing
+// the checks if err is set.
+type blockListMock struct {
+	things map[string]bool
+	ips    map[string]bool
+	err    error
+}
+
+func (bl *blockListMock) BlockThing(id string) error {
+	bl.things[id] = true
+	return nil
+}
+
+func (bl *blockListMock) UnblockThing(id string) error {
+	delete(bl.things, id)
+	return nil
+}
+
+func (bl *blockListMock) BlockNet(cidr string) error {
+	return nil
+}
+
+func (bl *blockListMock) UnblockNet(cidr string) error {
+	return nil
+}
+
+func (bl *blockListMock) Things() ([]string, error) {
+	things := []string{}
+	for id := range bl.things {
+		things = append(things, id)
+	}
+	return things, nil
+}
+
+func (bl *blockListMock) Nets() ([]string, error) {
+	return []string{}, nil
+}
+
+func (bl *blockListMock) ThingBlocked(id string) (bool, error) {
+	return bl.things[id], bl.err
+}
+
+func (bl *blockListMock) IPBlocked(ip net.IP) (bool, error) {
+	return bl.ips[ip.String()], bl.err
+}
+
+func TestBlockListHandler(t *testing.T) {
+	testLog, err := logger.New(os.Stdout, logger.Info.String())
+	require.Nil(t, err, fmt.Sprintf("unexpected logger creation error: %s\n", err))
+
+	blocked := &blockListMock{things: make(map[string]bool)}
+	wrapped := &handlerMock{}
+	h := NewBlockListHandler(wrapped, blocked, testLog)
+
+	// The steps are run in order, sharing the block list.
+	cases := []struct {
+		desc   string
+		update func()
+		err    error
+	}{
+		{
+			desc: "authorize thing",
+			err:  nil,
+		},
+		{
+			desc:   "authorize blocked thing",
+			update: func() { blocked.BlockThing(thingID) },
+			err:    errBlocked,
+		},
+		{
+			desc:   "authorize thing after another thing is unblocked",
+			update: func() { blocked.UnblockThing(thingID2) },
+			err:    errBlocked,
+		},
+		{
+			desc:   "authorize unblocked thing",
+			update: func() { blocked.UnblockThing(thingID) },
+			err:    nil,
+		},
+		{
+			desc: "authorize thing with unavailable block list",
+			update: func() {
+				blocked.BlockThing(thingID)
+				blocked.err = errBlockList
+			},
+			err: nil,
+		},
+		{
+			desc: "authorize unauthorized thing",
+			update: func() {
+				blocked.err = nil
+				wrapped.err = errAuth
+			},
+			err: errAuth,
+		},
+	}
+
+	for _, tc := range cases {
+		if tc.update != nil {
+			tc.update()
+		}
+
+		c := &session.Client{Username: thingID}
+		err := h.AuthConnect(c)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: connect: expected %s got %s\n", tc.desc, tc.err, err))
+
+		topic, payload := topic, []byte{}
+		err = h.AuthPublish(c, &topic, &payload)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: publish: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestBlockListMiddleware(t *testing.T) {
+	testLog, err := logger.New(os.Stdout, logger.Info.String())
+	require.Nil(t, err, fmt.Sprintf("unexpected logger creation error: %s\n", err))
+
+	blocked := &blockListMock{ips: map[string]bool{"192.168.1.10": true}}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := NewBlockListMiddleware(next, blocked, testLog)
+
+	cases := []struct {
+		desc       string
+		remoteAddr string
+		err        error
+		status     int
+	}{
+		{
+			desc:       "connect from allowed address",
+			remoteAddr: "192.168.1.11:1234",
+			status:     http.StatusOK,
+		},
+		{
+			desc:       "connect from blocked address",
+			remoteAddr: "192.168.1.10:1234",
+			status:     http.StatusForbidden,
+		},
+		{
+			desc:       "connect from blocked address with unavailable block list",
+			remoteAddr: "192.168.1.10:1234",
+			err:        errBlockList,
+			status:     http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		blocked.err = tc.err
+		req := httptest.NewRequest(http.MethodGet, "/mqtt", nil)
+		req.RemoteAddr = tc.remoteAddr
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		assert.Equal(t, tc.status, res.Code, fmt.Sprintf("%s: expected status %d got %d\n", tc.desc, tc.status, res.Code))
+	}
+}
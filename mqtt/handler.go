@@ -5,12 +5,17 @@ package mqtt
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
+	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
+	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/mqtt/redis"
 	"github.com/mainflux/mainflux/pkg/auth"
@@ -18,9 +23,18 @@ import (
 	"github.com/mainflux/mproxy/pkg/session"
 )
 
-var _ session.Handler = (*handler)(nil)
-
-const protocol = "mqtt"
+var _ Handler = (*handler)(nil)
+
+const (
+	protocol = "mqtt"
+	// Prefix of the shared subscription topic filter in the format:
+	// $share/<group>/<filter>
+	sharePrefix = "$share/"
+	// Number of retries when publishing QoS 1 and 2 messages to Mainflux.
+	pubRetries = 3
+	// Topic suffix which precedes the URL encoded payload content type.
+	ctSuffix = "/ct/"
+)
 
 var (
 	channelRegExp         = regexp.MustCompile(`^\/?channels\/([\w\-]+)\/messages(\/[^?]*)?(\?.*)?$`)
@@ -32,6 +46,7 @@ var (
 	errInvalidConnect     = errors.New("CONNECT request with invalid username or client ID")
 	errNilTopicPub        = errors.New("PUBLISH to nil topic")
 	errNilTopicSub        = errors.New("SUB to nil topic")
+	errMalformedShare     = errors.New("malformed shared subscription")
 )
 
 // Event implements events.Event interface
@@ -40,16 +55,25 @@ type handler struct {
 	auth       auth.Client
 	logger     logger.Logger
 	es         redis.EventStore
+	retained   redis.RetainedStore
+	idp        mainflux.IDProvider
+	mu         sync.Mutex
+	conns      map[*session.Client]redis.ConnInfo
 }
 
-// NewHandler creates new Handler entity
-func NewHandler(publishers []messaging.Publisher, es redis.EventStore,
-	logger logger.Logger, auth auth.Client) session.Handler {
+// NewHandler creates new Handler entity. If retained store is not nil, the
+// last message published with the RETAIN flag set to each topic is stored,
+// so that the proxy can deliver it to new subscribers.
+func NewHandler(publishers []messaging.Publisher, es redis.EventStore, logger logger.Logger, auth auth.Client,
+	retained redis.RetainedStore, idp mainflux.IDProvider) Handler {
 	return &handler{
 		es:         es,
 		logger:     logger,
 		publishers: publishers,
 		auth:       auth,
+		retained:   retained,
+		idp:        idp,
+		conns:      make(map[*session.Client]redis.ConnInfo),
 	}
 }
 
@@ -60,16 +84,26 @@ func (h *handler) AuthConnect(c *session.Client) error {
 		return errInvalidConnect
 	}
 
-	thid, err := h.auth.Identify(context.Background(), string(c.Password))
+	// Clients presenting a certificate are authenticated using the
+	// thing key contained in the certificate instead of the password.
+	key := string(c.Password)
+	if len(c.Cert.Raw) > 0 {
+		key = certKey(c.Cert)
+	}
+
+	thid, err := h.auth.Identify(context.Background(), key)
 	if err != nil {
 		return err
 	}
 
-	if thid != c.Username {
+	switch {
+	case len(c.Cert.Raw) > 0 && c.Username == "":
+		c.Username = thid
+	case thid != c.Username:
 		return errUnauthorizedAccess
 	}
 
-	if err := h.es.Connect(c.Username); err != nil {
+	if err := h.es.Connect(c.Username, h.connInfo(c, false)); err != nil {
 		h.logger.Warn("Failed to publish connect event: " + err.Error())
 	}
 
@@ -100,7 +134,13 @@ func (h *handler) AuthSubscribe(c *session.Client, topics *[]string) error {
 	}
 
 	for _, v := range *topics {
-		if err := h.authAccess(c.Username, v); err != nil {
+		// Shared subscriptions are load balanced by the broker,
+		// so only the topic filter is subject to authorization.
+		filter, err := parseShare(v)
+		if err != nil {
+			return err
+		}
+		if err := h.authAccess(c.Username, filter); err != nil {
 			return err
 		}
 
@@ -124,37 +164,22 @@ func (h *handler) Publish(c *session.Client, topic *string, payload *[]byte) {
 		h.logger.Error("Nil client publish")
 		return
 	}
-	h.logger.Info("Publish - client ID " + c.ID + " to the topic: " + *topic)
-	// Topics are in the format:
-	// channels/<channel_id>/messages/<subtopic>/.../ct/<content_type>
-
-	channelParts := channelRegExp.FindStringSubmatch(*topic)
-	if len(channelParts) < 1 {
-		h.logger.Info("Error in mqtt publish %s" + errMalformedData.Error())
-		return
-	}
-
-	chanID := channelParts[1]
-	subtopic := channelParts[2]
+	h.PublishQoS(c, *topic, *payload, 0, false)
+}
 
-	subtopic, err := parseSubtopic(subtopic)
-	if err != nil {
-		h.logger.Info("Error parsing subtopic: " + err.Error())
+// PublishQoS - after client successfully published with the given QoS
+// and RETAIN flag
+func (h *handler) PublishQoS(c *session.Client, topic string, payload []byte, qos byte, retain bool) {
+	if c == nil {
+		h.logger.Error("Nil client publish")
 		return
 	}
+	h.logger.Info(fmt.Sprintf("Publish - client ID %s to the topic: %s with QoS %d", c.ID, topic, qos))
+	h.publish(c.Username, topic, payload, qos)
 
-	msg := messaging.Message{
-		Protocol:  protocol,
-		Channel:   chanID,
-		Subtopic:  subtopic,
-		Publisher: c.Username,
-		Payload:   *payload,
-		Created:   time.Now().UnixNano(),
-	}
-
-	for _, pub := range h.publishers {
-		if err := pub.Publish(msg.Channel, msg); err != nil {
-			h.logger.Info("Error publishing to Mainflux " + err.Error())
+	if retain && h.retained != nil {
+		if err := h.retained.Save(topic, payload); err != nil {
+			h.logger.Warn("Failed to save retained message: " + err.Error())
 		}
 	}
 }
@@ -184,11 +209,117 @@ func (h *handler) Disconnect(c *session.Client) {
 		return
 	}
 	h.logger.Info("Disconnect - Client with ID: " + c.ID + " and username " + c.Username + " disconnected")
-	if err := h.es.Disconnect(c.Username); err != nil {
+	if err := h.es.Disconnect(c.Username, h.connInfo(c, true)); err != nil {
 		h.logger.Warn("Failed to publish disconnect event: " + err.Error())
 	}
 }
 
+// ConnInfo - prior to the client connection authentication
+func (h *handler) ConnInfo(c *session.Client, info redis.ConnInfo) {
+	if c == nil {
+		h.logger.Error("Nil client connection info")
+		return
+	}
+	h.mu.Lock()
+	h.conns[c] = info
+	h.mu.Unlock()
+}
+
+// Will - client with Last Will and Testament disconnected uncleanly
+func (h *handler) Will(c *session.Client, will Will) {
+	if c == nil {
+		h.logger.Error("Nil client will")
+		return
+	}
+	h.logger.Info("Will - client ID " + c.ID + " to the topic: " + will.Topic)
+	if err := h.authAccess(c.Username, will.Topic); err != nil {
+		h.logger.Warn("Failed to authorize will message: " + err.Error())
+		return
+	}
+
+	h.publish(c.Username, will.Topic, will.Payload, will.QoS)
+	if h.retained != nil && will.Retain {
+		if err := h.retained.Save(will.Topic, will.Payload); err != nil {
+			h.logger.Warn("Failed to save retained will message: " + err.Error())
+		}
+	}
+
+	if err := h.es.Will(c.Username); err != nil {
+		h.logger.Warn("Failed to publish will event: " + err.Error())
+	}
+}
+
+// connInfo returns the connection details of the client, removing
+// them once the client is disconnected.
+func (h *handler) connInfo(c *session.Client, remove bool) redis.ConnInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	info := h.conns[c]
+	if remove {
+		delete(h.conns, c)
+	}
+	return info
+}
+
+func (h *handler) publish(publisher, topic string, payload []byte, qos byte) {
+	// Topics are in the format:
+	// channels/<channel_id>/messages/<subtopic>/.../ct/<content_type>
+
+	channelParts := channelRegExp.FindStringSubmatch(topic)
+	if len(channelParts) < 1 {
+		h.logger.Info("Error in mqtt publish %s" + errMalformedData.Error())
+		return
+	}
+
+	chanID := channelParts[1]
+	subtopic, contentType, err := parseContentType(channelParts[2])
+	if err != nil {
+		h.logger.Info("Error parsing content type: " + err.Error())
+		return
+	}
+
+	subtopic, err = parseSubtopic(subtopic)
+	if err != nil {
+		h.logger.Info("Error parsing subtopic: " + err.Error())
+		return
+	}
+
+	id, err := h.idp.ID()
+	if err != nil {
+		h.logger.Warn("Failed to generate message ID: " + err.Error())
+	}
+
+	msg := messaging.Message{
+		Protocol:    protocol,
+		Channel:     chanID,
+		Subtopic:    subtopic,
+		Publisher:   publisher,
+		Payload:     payload,
+		Created:     time.Now().UnixNano(),
+		Id:          id,
+		ContentType: contentType,
+	}
+
+	for _, pub := range h.publishers {
+		publish := func() error {
+			return pub.Publish(msg.Channel, msg)
+		}
+		// QoS 0 messages are published at most once, while the ones with
+		// higher QoS are retried, relying on message ID for deduplication.
+		var err error
+		switch qos {
+		case 0:
+			err = publish()
+		default:
+			err = backoff.Retry(publish, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), pubRetries))
+		}
+		if err != nil {
+			h.logger.Info("Error publishing to Mainflux " + err.Error())
+		}
+	}
+}
+
 func (h *handler) authAccess(username string, topic string) error {
 	// Topics are in the format:
 	// channels/<channel_id>/messages/<subtopic>/.../ct/<content_type>
@@ -206,6 +337,49 @@ func (h *handler) authAccess(username string, topic string) error {
 	return h.auth.Authorize(context.Background(), chanID, username)
 }
 
+// certKey returns the thing key from the certificate common name,
+// falling back to the first DNS subject alternative name.
+func certKey(cert x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return ""
+}
+
+// parseShare returns the topic filter of the shared subscription,
+// validating the group name. Other topic filters are returned unchanged.
+func parseShare(topic string) (string, error) {
+	if !strings.HasPrefix(topic, sharePrefix) {
+		return topic, nil
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(topic, sharePrefix), "/", 2)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" || strings.ContainsAny(parts[0], "+#") {
+		return "", errMalformedShare
+	}
+
+	return parts[1], nil
+}
+
+// parseContentType splits the subtopic and the payload content type which is
+// advertised using the `ct` topic suffix.
+func parseContentType(subtopic string) (string, string, error) {
+	i := strings.LastIndex(subtopic, ctSuffix)
+	if i < 0 {
+		return subtopic, "", nil
+	}
+
+	ct, err := url.QueryUnescape(subtopic[i+len(ctSuffix):])
+	if err != nil {
+		return "", "", errMalformedSubtopic
+	}
+
+	return subtopic[:i], ct, nil
+}
+
 func parseSubtopic(subtopic string) (string, error) {
 	if subtopic == "" {
 		return subtopic, nil
@@ -0,0 +1,686 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/mqtt/redis"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mproxy/pkg/session"
+	mptls "github.com/mainflux/mproxy/pkg/tls"
+)
+
+// Time given to the drained client to receive DISCONNECT packet.
+const drainWriteTimeout = 5 * time.Second
+
+// Protocol levels of MQTT 3.1 and MQTT 3.1.1, which are the versions supported
+// by mProxy.
+const (
+	mqtt31  = 3
+	mqtt311 = 4
+)
+
+var (
+	errCreateListener      = errors.New("failed creating TLS listener")
+	errUnsupportedProtocol = errors.New("unsupported MQTT protocol version")
+	errMalformedPacket     = errors.New("malformed MQTT packet")
+)
+
+var (
+	// connackUnsupported is CONNACK packet with the unacceptable protocol
+	// version (0x01) return code.
+	connackUnsupported = []byte{packets.Connack << 4, 2, 0, packets.ErrRefusedBadProtocolVersion}
+	disconnectPacket   = []byte{packets.Disconnect << 4, 0}
+)
+
+// RETAIN flag bit of the PUBLISH packet fixed header and Will Retain flag bit
+// of the CONNECT packet flags.
+const (
+	retainFlag     = 0x01
+	willRetainFlag = 0x20
+)
+
+// Will represents MQTT Last Will and Testament message.
+type Will struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
+// Handler extends mProxy session handler with the hooks that
+// rely on the session details not exposed by mProxy.
+type Handler interface {
+	session.Handler
+
+	// Will is called when the client that specified the Last Will and
+	// Testament message is disconnected without sending DISCONNECT.
+	Will(c *session.Client, will Will)
+
+	// PublishQoS is called instead of Publish for the messages published
+	// over the MQTT proxy, so that the message QoS and RETAIN flag can be
+	// honored.
+	PublishQoS(c *session.Client, topic string, payload []byte, qos byte, retain bool)
+
+	// ConnInfo is called prior to AuthConnect for the clients connected
+	// over the MQTT proxy, passing the details of the client connection.
+	ConnInfo(c *session.Client, info redis.ConnInfo)
+}
+
+// Proxy is MQTT proxy which inspects client packets in order to keep track
+// of the session details, while the traffic is proxied using mProxy sessions.
+type Proxy struct {
+	address  string
+	target   string
+	handler  Handler
+	sessions redis.SessionStore
+	retained redis.RetainedStore
+	blocked  redis.BlockList
+	logger   logger.Logger
+	dialer   net.Dialer
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[*drainConn]bool
+	closing  bool
+	wg       sync.WaitGroup
+}
+
+// NewProxy returns a new MQTT Proxy instance. If session store is not nil,
+// the sessions of the clients connected with clean session flag not set
+// are persisted, so they can be resumed after the adapter restart. If
+// retained store is not nil, the retained messages are delivered to each
+// subscribing client by the proxy, instead of the broker. If block list is
+// not nil, connections from blocked IP addresses are refused.
+func NewProxy(address, target string, handler Handler, sessions redis.SessionStore, retained redis.RetainedStore, blocked redis.BlockList, logger logger.Logger) *Proxy {
+	return &Proxy{
+		address:  address,
+		target:   target,
+		handler:  handler,
+		sessions: sessions,
+		retained: retained,
+		blocked:  blocked,
+		logger:   logger,
+		conns:    make(map[*drainConn]bool),
+	}
+}
+
+// Listen starts accepting client connections. This method blocks until
+// the proxy is drained.
+func (p *Proxy) Listen() error {
+	l, err := net.Listen("tcp", p.address)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	p.accept(l)
+	return nil
+}
+
+// ListenTLS starts accepting client connections over TLS. This method blocks
+// until the proxy is drained.
+func (p *Proxy) ListenTLS(cfg *tls.Config) error {
+	l, err := tls.Listen("tcp", p.address, cfg)
+	if err != nil {
+		return errors.Wrap(errCreateListener, err)
+	}
+	defer l.Close()
+
+	p.accept(l)
+	return nil
+}
+
+// Drain stops accepting client connections and disconnects connected clients
+// evenly over the given period, so that they do not reconnect to other adapter
+// instances all at once. Clients are sent DISCONNECT packet.
+// This method blocks until all the connections are closed.
+func (p *Proxy) Drain(period time.Duration) {
+	p.mu.Lock()
+	p.closing = true
+	if p.listener != nil {
+		if err := p.listener.Close(); err != nil {
+			p.logger.Warn("Failed to close listener: " + err.Error())
+		}
+	}
+	conns := make([]*drainConn, 0, len(p.conns))
+	for c := range p.conns {
+		conns = append(conns, c)
+	}
+	p.mu.Unlock()
+
+	p.logger.Info(fmt.Sprintf("Draining %d connections over %s", len(conns), period))
+	for i, c := range conns {
+		if i > 0 {
+			time.Sleep(period / time.Duration(len(conns)))
+		}
+		c.drain()
+	}
+
+	p.wg.Wait()
+}
+
+func (p *Proxy) accept(l net.Listener) {
+	p.mu.Lock()
+	p.listener = l
+	closing := p.closing
+	p.mu.Unlock()
+	if closing {
+		return
+	}
+
+	for {
+		conn, err := l.Accept()
+		if p.isClosing() {
+			if err == nil {
+				p.close(conn)
+			}
+			return
+		}
+		if err != nil {
+			p.logger.Warn("Accept error " + err.Error())
+			continue
+		}
+
+		go p.handle(conn)
+	}
+}
+
+func (p *Proxy) isClosing() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closing
+}
+
+// track registers the connection to be drained, unless the proxy is
+// already being drained.
+func (p *Proxy) track(c *drainConn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closing {
+		return false
+	}
+	p.conns[c] = true
+	p.wg.Add(1)
+	return true
+}
+
+func (p *Proxy) untrack(c *drainConn) {
+	p.mu.Lock()
+	delete(p.conns, c)
+	p.mu.Unlock()
+	p.wg.Done()
+}
+
+func (p *Proxy) handle(inbound net.Conn) {
+	defer p.close(inbound)
+	ip := remoteIP(inbound)
+	if err := p.checkIP(ip); err != nil {
+		p.logger.Info("Refused connection from blocked IP address: " + ip.String())
+		return
+	}
+
+	outbound, err := p.dialer.Dial("tcp", p.target)
+	if err != nil {
+		p.logger.Error("Cannot connect to remote broker " + p.target + " due to: " + err.Error())
+		return
+	}
+	defer p.close(outbound)
+
+	cert, err := mptls.ClientCert(inbound)
+	if err != nil {
+		p.logger.Error("Failed to get client certificate: " + err.Error())
+		return
+	}
+
+	st := state{
+		inflight: make(map[uint16]bool),
+	}
+	in := &inspectConn{
+		Conn:    inbound,
+		inspect: st.inspect,
+		// The broker would deliver the retained messages to the new
+		// subscribers as well, duplicating the ones delivered by the proxy.
+		stripRetain: p.retained != nil,
+	}
+	out := &drainConn{
+		Conn:   outbound,
+		client: inbound,
+	}
+	if !p.track(out) {
+		return
+	}
+	defer p.untrack(out)
+
+	info := redis.ConnInfo{}
+	if ip != nil {
+		info.RemoteIP = ip.String()
+	}
+	_, info.TLS = inbound.(*tls.Conn)
+
+	h := sessionHandler{
+		Handler:  p.handler,
+		sessions: p.sessions,
+		retained: p.retained,
+		logger:   p.logger,
+		st:       &st,
+		out:      out,
+		info:     info,
+		checkIP: func() error {
+			return p.checkIP(ip)
+		},
+	}
+
+	s := session.New(in, out, h, p.logger, cert)
+	if err := s.Stream(); !errors.Contains(err, io.EOF) {
+		p.logger.Warn("Broken connection for client: " + s.Client.ID + " with error: " + err.Error())
+	}
+
+	// Drained clients are expected to reconnect,
+	// so their will messages are not published.
+	if st.will != nil && !st.disconnected && !out.draining() {
+		p.handler.Will(&s.Client, *st.will)
+	}
+}
+
+func (p *Proxy) checkIP(ip net.IP) error {
+	return checkIP(p.blocked, ip, p.logger)
+}
+
+func (p *Proxy) close(conn net.Conn) {
+	if err := conn.Close(); err != nil {
+		p.logger.Warn(fmt.Sprintf("Error closing connection %s", err.Error()))
+	}
+}
+
+// state contains the session details collected from client packets.
+// Since the packets are inspected right before mProxy session reads them,
+// the state always corresponds to the packet being handled by the session.
+type state struct {
+	version      byte
+	will         *Will
+	persistent   bool
+	disconnected bool
+	qos          byte
+	retain       bool
+	msgID        uint16
+	duplicate    bool
+	released     []uint16
+	// QoS 2 messages received from the client and not yet released.
+	inflight map[uint16]bool
+}
+
+func (st *state) inspect(pkt packets.ControlPacket) {
+	switch p := pkt.(type) {
+	case *packets.ConnectPacket:
+		st.version = p.ProtocolVersion
+		st.persistent = !p.CleanSession
+		if p.WillFlag {
+			st.will = &Will{
+				Topic:   p.WillTopic,
+				Payload: p.WillMessage,
+				QoS:     p.WillQos,
+				Retain:  p.WillRetain,
+			}
+		}
+	case *packets.PublishPacket:
+		st.qos = p.Qos
+		st.retain = p.Retain
+		st.msgID = p.MessageID
+		st.duplicate = false
+		if p.Qos == 2 {
+			// A QoS 2 message is retransmitted using the same packet ID until
+			// PUBREC is received, so it needs to be forwarded to the broker
+			// to complete the handshake, but not published to Mainflux again.
+			st.duplicate = st.inflight[p.MessageID]
+			st.inflight[p.MessageID] = true
+		}
+	case *packets.PubrelPacket:
+		delete(st.inflight, p.MessageID)
+		if st.persistent {
+			st.released = append(st.released, p.MessageID)
+		}
+	case *packets.DisconnectPacket:
+		st.disconnected = true
+	}
+}
+
+// sessionHandler passes the details of the inspected packets to the
+// Handler and persists the session if needed.
+type sessionHandler struct {
+	Handler
+	sessions redis.SessionStore
+	retained redis.RetainedStore
+	logger   logger.Logger
+	st       *state
+	out      *drainConn
+	info     redis.ConnInfo
+	checkIP  func() error
+}
+
+func (sh sessionHandler) AuthConnect(c *session.Client) error {
+	info := sh.info
+	info.Version = sh.st.version
+	info.CleanSession = !sh.st.persistent
+	sh.Handler.ConnInfo(c, info)
+
+	if err := sh.Handler.AuthConnect(c); err != nil {
+		return err
+	}
+	if sh.sessions == nil {
+		return nil
+	}
+
+	if !sh.st.persistent {
+		if err := sh.sessions.Remove(c.Username, c.ID); err != nil {
+			sh.logger.Warn("Failed to remove session: " + err.Error())
+		}
+		return nil
+	}
+
+	ids, err := sh.sessions.Inflight(c.Username, c.ID)
+	if err != nil {
+		sh.logger.Warn("Failed to retrieve session in-flight messages: " + err.Error())
+	}
+	for _, id := range ids {
+		sh.st.inflight[id] = true
+	}
+
+	// Since the broker resumes the session subscriptions,
+	// make sure the client is still allowed to access them.
+	topics, err := sh.sessions.Subscriptions(c.Username, c.ID)
+	if err != nil {
+		sh.logger.Warn("Failed to retrieve session subscriptions: " + err.Error())
+		return nil
+	}
+	if len(topics) == 0 {
+		return nil
+	}
+
+	return sh.Handler.AuthSubscribe(c, &topics)
+}
+
+func (sh sessionHandler) AuthPublish(c *session.Client, topic *string, payload *[]byte) error {
+	if err := sh.checkIP(); err != nil {
+		return err
+	}
+	return sh.Handler.AuthPublish(c, topic, payload)
+}
+
+// AuthSubscribe queues the retained messages matching the authorized topic
+// filters to be sent to the client. Since the SUBSCRIBE packet is forwarded
+// to the broker afterwards, they are sent no later than the SUBACK packet.
+func (sh sessionHandler) AuthSubscribe(c *session.Client, topics *[]string) error {
+	if err := sh.Handler.AuthSubscribe(c, topics); err != nil {
+		return err
+	}
+	if sh.retained != nil && topics != nil {
+		sh.deliverRetained(*topics)
+	}
+	return nil
+}
+
+func (sh sessionHandler) Publish(c *session.Client, topic *string, payload *[]byte) {
+	sh.release(c)
+	if sh.st.duplicate {
+		return
+	}
+	if sh.persist() && sh.st.qos == 2 {
+		if err := sh.sessions.SaveInflight(c.Username, c.ID, sh.st.msgID); err != nil {
+			sh.logger.Warn("Failed to save session in-flight message: " + err.Error())
+		}
+	}
+	sh.Handler.PublishQoS(c, *topic, *payload, sh.st.qos, sh.st.retain)
+}
+
+func (sh sessionHandler) Subscribe(c *session.Client, topics *[]string) {
+	sh.release(c)
+	if sh.persist() && topics != nil {
+		if err := sh.sessions.SaveSubscriptions(c.Username, c.ID, *topics); err != nil {
+			sh.logger.Warn("Failed to save session subscriptions: " + err.Error())
+		}
+	}
+	sh.Handler.Subscribe(c, topics)
+}
+
+func (sh sessionHandler) Unsubscribe(c *session.Client, topics *[]string) {
+	sh.release(c)
+	if sh.persist() && topics != nil {
+		if err := sh.sessions.RemoveSubscriptions(c.Username, c.ID, *topics); err != nil {
+			sh.logger.Warn("Failed to remove session subscriptions: " + err.Error())
+		}
+	}
+	sh.Handler.Unsubscribe(c, topics)
+}
+
+func (sh sessionHandler) Disconnect(c *session.Client) {
+	sh.release(c)
+	sh.Handler.Disconnect(c)
+}
+
+func (sh sessionHandler) deliverRetained(filters []string) {
+	for _, filter := range filters {
+		// Retained messages are not delivered to shared subscriptions,
+		// since each of them would be delivered to a single subscriber.
+		if strings.HasPrefix(filter, sharePrefix) {
+			continue
+		}
+		msgs, err := sh.retained.Retrieve(filter)
+		if err != nil {
+			sh.logger.Warn("Failed to retrieve retained messages: " + err.Error())
+			continue
+		}
+		for topic, payload := range msgs {
+			pub := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+			pub.TopicName = topic
+			pub.Payload = payload
+			pub.Retain = true
+			var buf bytes.Buffer
+			if err := pub.Write(&buf); err != nil {
+				sh.logger.Warn("Failed to encode retained message: " + err.Error())
+				continue
+			}
+			sh.out.inject(buf.Bytes())
+		}
+	}
+}
+
+func (sh sessionHandler) persist() bool {
+	return sh.sessions != nil && sh.st.persistent
+}
+
+// release removes the QoS 2 messages released by the client from the
+// session store. Since mProxy does not notify handler on PUBREL,
+// it is done lazily on the next hook.
+func (sh sessionHandler) release(c *session.Client) {
+	if !sh.persist() || len(sh.st.released) == 0 || c == nil {
+		return
+	}
+	for _, id := range sh.st.released {
+		if err := sh.sessions.RemoveInflight(c.Username, c.ID, id); err != nil {
+			sh.logger.Warn("Failed to remove session in-flight message: " + err.Error())
+		}
+	}
+	sh.st.released = sh.st.released[:0]
+}
+
+func remoteIP(conn net.Conn) net.IP {
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		return addr.IP
+	}
+	return nil
+}
+
+// inspectConn reads client packets one by one, passes them to the
+// inspect function and serves them to the reader unchanged, except for the
+// RETAIN flags of the PUBLISH and CONNECT packets which are cleared if
+// stripRetain is set.
+// Since mProxy decodes the packets as MQTT 3.1.1 ones, the clients using
+// other protocol versions, such as MQTT 5, are refused on connect, instead
+// of having their packets corrupted.
+type inspectConn struct {
+	net.Conn
+	buf         bytes.Buffer
+	inspect     func(packets.ControlPacket)
+	stripRetain bool
+}
+
+func (c *inspectConn) Read(b []byte) (int, error) {
+	if c.buf.Len() == 0 {
+		raw, err := readPacket(c.Conn)
+		if err != nil {
+			return 0, err
+		}
+		pkt, err := packets.ReadPacket(bytes.NewReader(raw))
+		if cp, ok := pkt.(*packets.ConnectPacket); ok && cp.ProtocolVersion != mqtt31 && cp.ProtocolVersion != mqtt311 {
+			c.Conn.Write(connackUnsupported)
+			return 0, errUnsupportedProtocol
+		}
+		if err != nil {
+			return 0, err
+		}
+		c.inspect(pkt)
+		if c.stripRetain {
+			clearRetain(pkt, raw)
+		}
+		c.buf.Write(raw)
+	}
+
+	return c.buf.Read(b)
+}
+
+// drainConn reads broker packets one by one, so that the DISCONNECT packet
+// and the injected packets can be sent to the client in between them.
+type drainConn struct {
+	net.Conn
+	client  net.Conn
+	buf     bytes.Buffer
+	drained int32
+	done    bool
+	mu      sync.Mutex
+	pending [][]byte
+}
+
+// inject queues the packet to be sent to the client after the broker packet
+// which is currently being read.
+func (c *drainConn) inject(pkt []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, pkt)
+}
+
+func (c *drainConn) next() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) == 0 {
+		return nil
+	}
+	pkt := c.pending[0]
+	c.pending = c.pending[1:]
+	return pkt
+}
+
+func (c *drainConn) drain() {
+	atomic.StoreInt32(&c.drained, 1)
+	// Unblock pending read from the broker and make sure that
+	// the client that stopped reading does not block the session.
+	now := time.Now()
+	c.Conn.SetReadDeadline(now)
+	c.client.SetWriteDeadline(now.Add(drainWriteTimeout))
+}
+
+func (c *drainConn) draining() bool {
+	return atomic.LoadInt32(&c.drained) == 1
+}
+
+func (c *drainConn) Read(b []byte) (int, error) {
+	if c.buf.Len() == 0 {
+		if c.draining() {
+			if c.done {
+				return 0, io.EOF
+			}
+			c.done = true
+			c.buf.Write(disconnectPacket)
+			return c.buf.Read(b)
+		}
+		if pkt := c.next(); pkt != nil {
+			c.buf.Write(pkt)
+			return c.buf.Read(b)
+		}
+
+		raw, err := readPacket(c.Conn)
+		if err != nil {
+			// The packet interrupted by the drain is dropped.
+			if c.draining() {
+				return c.Read(b)
+			}
+			return 0, err
+		}
+		c.buf.Write(raw)
+	}
+
+	return c.buf.Read(b)
+}
+
+// clearRetain clears the RETAIN flag of the encoded PUBLISH packet and the
+// Will Retain flag of the encoded CONNECT packet.
+func clearRetain(pkt packets.ControlPacket, raw []byte) {
+	switch pkt.(type) {
+	case *packets.PublishPacket:
+		raw[0] &^= retainFlag
+	case *packets.ConnectPacket:
+		// The variable header follows the remaining length bytes and starts
+		// with the protocol name and the protocol level, followed by flags.
+		i := 1
+		for raw[i]&0x80 != 0 {
+			i++
+		}
+		i++
+		name := int(raw[i])<<8 | int(raw[i+1])
+		raw[i+2+name+1] &^= willRetainFlag
+	}
+}
+
+// readPacket reads the encoded control packet, consisting of the fixed
+// header and the remaining length bytes.
+func readPacket(r io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	b := make([]byte, 1)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	buf.Write(b)
+
+	// Remaining length is encoded using up to 4 bytes.
+	var length int
+	for i := 0; ; i++ {
+		if i == 4 {
+			return nil, errMalformedPacket
+		}
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+		length |= int(b[0]&0x7f) << (7 * i)
+		if b[0]&0x80 == 0 {
+			break
+		}
+	}
+
+	if _, err := io.CopyN(&buf, r, int64(length)); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,278 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mproxy/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectV5 is MQTT 5 CONNECT packet with the session expiry interval
+// property and the client ID "c".
+var connectV5 = []byte{
+	0x10, 0x13,
+	0x00, 0x04, 'M', 'Q', 'T', 'T', 0x05, 0x02, 0x00, 0x3c,
+	0x05, 0x11, 0x00, 0x00, 0x00, 0x3c,
+	0x00, 0x01, 'c',
+}
+
+// retainedMock keeps the retained messages in memory, matching the filters
+// exactly or using the multi-level wildcard only.
+type retainedMock struct {
+	msgs map[string][]byte
+}
+
+func (rm retainedMock) Save(topic string, payload []byte) error {
+	rm.msgs[topic] = payload
+	return nil
+}
+
+func (rm retainedMock) Retrieve(filter string) (map[string][]byte, error) {
+	msgs := make(map[string][]byte)
+	for topic, payload := range rm.msgs {
+		if topic == filter || (strings.HasSuffix(filter, "/#") && strings.HasPrefix(topic, strings.TrimSuffix(filter, "#"))) {
+			msgs[topic] = payload
+		}
+	}
+	return msgs, nil
+}
+
+func encode(t *testing.T, pkt packets.ControlPacket) []byte {
+	var buf bytes.Buffer
+	err := pkt.Write(&buf)
+	require.Nil(t, err, fmt.Sprintf("unexpected packet encoding error: %s\n", err))
+	return buf.Bytes()
+}
+
+func TestInspectConn(t *testing.T) {
+	connect := packets.NewControlPacket(packets.Connect).(*packets.ConnectPacket)
+	connect.ProtocolName = "MQTT"
+	connect.ProtocolVersion = mqtt311
+	connect.CleanSession = true
+	connect.ClientIdentifier = "c"
+
+	will := packets.NewControlPacket(packets.Connect).(*packets.ConnectPacket)
+	will.ProtocolName = "MQTT"
+	will.ProtocolVersion = mqtt311
+	will.CleanSession = true
+	will.ClientIdentifier = "c"
+	will.WillFlag = true
+	will.WillTopic = "channels/1/messages/status"
+	will.WillMessage = []byte("offline")
+	will.WillRetain = true
+	noWillRetain := *will
+	noWillRetain.WillRetain = false
+
+	connect31 := packets.NewControlPacket(packets.Connect).(*packets.ConnectPacket)
+	connect31.ProtocolName = "MQIsdp"
+	connect31.ProtocolVersion = mqtt31
+	connect31.ClientIdentifier = "c"
+
+	publish := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+	publish.TopicName = "channels/1/messages"
+	publish.Qos = 1
+	publish.Retain = true
+	publish.MessageID = 1
+	publish.Payload = bytes.Repeat([]byte{'a'}, 200)
+	noRetain := *publish
+	noRetain.Retain = false
+
+	cases := []struct {
+		desc        string
+		packet      []byte
+		stripRetain bool
+		forwarded   []byte
+		version     byte
+		retain      bool
+		response    []byte
+		err         error
+	}{
+		{
+			desc:    "read MQTT 3.1.1 CONNECT packet",
+			packet:  encode(t, connect),
+			version: mqtt311,
+		},
+		{
+			desc:    "read MQTT 3.1 CONNECT packet",
+			packet:  encode(t, connect31),
+			version: mqtt31,
+		},
+		{
+			desc:   "read PUBLISH packet with multi-byte remaining length",
+			packet: encode(t, publish),
+			retain: true,
+		},
+		{
+			desc:        "read PUBLISH packet with stripped RETAIN flag",
+			packet:      encode(t, publish),
+			stripRetain: true,
+			forwarded:   encode(t, &noRetain),
+			retain:      true,
+		},
+		{
+			desc:        "read CONNECT packet with stripped Will Retain flag",
+			packet:      encode(t, will),
+			stripRetain: true,
+			forwarded:   encode(t, &noWillRetain),
+			version:     mqtt311,
+		},
+		{
+			desc:     "read MQTT 5 CONNECT packet",
+			packet:   connectV5,
+			response: connackUnsupported,
+			err:      errUnsupportedProtocol,
+		},
+		{
+			desc:   "read packet with malformed remaining length",
+			packet: []byte{0x30, 0xff, 0xff, 0xff, 0xff, 0x01},
+			err:    errMalformedPacket,
+		},
+	}
+
+	for _, tc := range cases {
+		client, server := net.Pipe()
+		st := state{inflight: make(map[uint16]bool)}
+		in := &inspectConn{
+			Conn:        server,
+			inspect:     st.inspect,
+			stripRetain: tc.stripRetain,
+		}
+
+		go client.Write(tc.packet)
+		response := make(chan []byte)
+		go func() {
+			b, _ := ioutil.ReadAll(client)
+			response <- b
+		}()
+
+		buf := make([]byte, len(tc.packet))
+		_, err := io.ReadFull(in, buf)
+		server.Close()
+		res := <-response
+		client.Close()
+
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, string(tc.response), string(res), fmt.Sprintf("%s: expected response %x got %x\n", tc.desc, tc.response, res))
+		if tc.err != nil {
+			continue
+		}
+		forwarded := tc.packet
+		if tc.forwarded != nil {
+			forwarded = tc.forwarded
+		}
+		assert.Equal(t, forwarded, buf, fmt.Sprintf("%s: expected packet %x got %x\n", tc.desc, forwarded, buf))
+		assert.Equal(t, tc.version, st.version, fmt.Sprintf("%s: expected version %d got %d\n", tc.desc, tc.version, st.version))
+		assert.Equal(t, tc.retain, st.retain, fmt.Sprintf("%s: expected retain %t got %t\n", tc.desc, tc.retain, st.retain))
+	}
+}
+
+func TestDrainConn(t *testing.T) {
+	suback := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+	suback.MessageID = 1
+	suback.ReturnCodes = []byte{0}
+	packet := encode(t, suback)
+
+	broker, server := net.Pipe()
+	client, _ := net.Pipe()
+	out := &drainConn{
+		Conn:   server,
+		client: client,
+	}
+
+	go broker.Write(packet)
+	buf := make([]byte, len(packet))
+	_, err := io.ReadFull(out, buf)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	assert.Equal(t, packet, buf, fmt.Sprintf("expected packet %x got %x\n", packet, buf))
+
+	out.drain()
+	buf, err = ioutil.ReadAll(out)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	assert.Equal(t, disconnectPacket, buf, fmt.Sprintf("expected DISCONNECT packet %x got %x\n", disconnectPacket, buf))
+}
+
+func TestDeliverRetained(t *testing.T) {
+	testLog, err := logger.New(os.Stdout, logger.Info.String())
+	require.Nil(t, err, fmt.Sprintf("unexpected logger creation error: %s\n", err))
+
+	retained := retainedMock{msgs: map[string][]byte{
+		topic:                 []byte("retained"),
+		topic + "/temp":       []byte("21.5"),
+		"channels/2/messages": []byte("other"),
+	}}
+	published := func(topic string, payload []byte) []byte {
+		pub := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+		pub.TopicName = topic
+		pub.Payload = payload
+		pub.Retain = true
+		return encode(t, pub)
+	}
+
+	cases := []struct {
+		desc      string
+		topics    []string
+		err       error
+		delivered [][]byte
+	}{
+		{
+			desc:      "subscribe to topic",
+			topics:    []string{topic},
+			delivered: [][]byte{published(topic, []byte("retained"))},
+		},
+		{
+			desc:      "subscribe to wildcard topic",
+			topics:    []string{topic + "/#"},
+			delivered: [][]byte{published(topic+"/temp", []byte("21.5"))},
+		},
+		{
+			desc:   "subscribe to topic without retained messages",
+			topics: []string{topic + "/humidity"},
+		},
+		{
+			desc:   "subscribe to shared topic",
+			topics: []string{"$share/group/" + topic},
+		},
+		{
+			desc:   "subscribe unauthorized",
+			topics: []string{topic},
+			err:    errAuth,
+		},
+	}
+
+	for _, tc := range cases {
+		out := &drainConn{}
+		h := sessionHandler{
+			Handler:  &handlerMock{err: tc.err},
+			retained: retained,
+			logger:   testLog,
+			st:       &state{},
+			out:      out,
+		}
+
+		topics := tc.topics
+		err := h.AuthSubscribe(&session.Client{Username: thingID}, &topics)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, len(tc.delivered), len(out.pending), fmt.Sprintf("%s: expected %d delivered messages got %d\n", tc.desc, len(tc.delivered), len(out.pending)))
+		for _, pkt := range tc.delivered {
+			// Injected packets are served before reading from the broker.
+			buf := make([]byte, len(pkt))
+			_, err := io.ReadFull(out, buf)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+			assert.Equal(t, pkt, buf, fmt.Sprintf("%s: expected packet %x got %x\n", tc.desc, pkt, buf))
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mproxy/pkg/session"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// ThrottleMode delays publishing until the message conforms to limits.
+	ThrottleMode = "throttle"
+	// DisconnectMode disconnects the client that exceeded limits.
+	DisconnectMode = "disconnect"
+
+	msgsLimit  = "messages"
+	bytesLimit = "bytes"
+
+	evictInterval = time.Minute
+)
+
+var errRateLimitExceeded = errors.New("publish rate limit exceeded")
+
+// RateLimiter specifies per client rate limiting API.
+type RateLimiter interface {
+	// Reserve takes n tokens from the client bucket and returns
+	// the duration client needs to wait for the tokens to be available.
+	Reserve(id string, n int) time.Duration
+}
+
+// limiter is the rate limiter of a client, which can be dropped once its
+// bucket is full again, since a new limiter starts with the full bucket.
+type limiter struct {
+	*rate.Limiter
+	full time.Time
+}
+
+type rateLimiter struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	burst    int
+	refill   time.Duration
+	limiters map[string]*limiter
+	evicted  time.Time
+	now      func() time.Time
+}
+
+// NewRateLimiter returns token bucket RateLimiter allowing rate tokens per
+// second, with burst equal to the number of tokens issued in a second. The
+// limiters of the idle clients are evicted every minute.
+func NewRateLimiter(r float64) RateLimiter {
+	burst := int(math.Ceil(r))
+	return &rateLimiter{
+		limit:    rate.Limit(r),
+		burst:    burst,
+		refill:   time.Duration(float64(burst) / r * float64(time.Second)),
+		limiters: make(map[string]*limiter),
+		now:      time.Now,
+	}
+}
+
+func (rl *rateLimiter) Reserve(id string, n int) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.evict(now)
+
+	l, ok := rl.limiters[id]
+	if !ok {
+		l = &limiter{Limiter: rate.NewLimiter(rl.limit, rl.burst)}
+		rl.limiters[id] = l
+	}
+
+	// Limiter reserves at most burst tokens at once, so larger reservations,
+	// such as big payloads, are made in parts.
+	var wait time.Duration
+	for ; n > 0; n -= rl.burst {
+		k := n
+		if k > rl.burst {
+			k = rl.burst
+		}
+		wait = l.ReserveN(now, k).DelayFrom(now)
+	}
+
+	if full := now.Add(wait + rl.refill); full.After(l.full) {
+		l.full = full
+	}
+
+	return wait
+}
+
+// evict removes the limiters whose buckets have been refilled.
+func (rl *rateLimiter) evict(now time.Time) {
+	if now.Sub(rl.evicted) < evictInterval {
+		return
+	}
+	rl.evicted = now
+
+	for id, l := range rl.limiters {
+		if !now.Before(l.full) {
+			delete(rl.limiters, id)
+		}
+	}
+}
+
+var _ Handler = (*rateLimitHandler)(nil)
+
+type rateLimitHandler struct {
+	Handler
+	msgs       RateLimiter
+	bytes      RateLimiter
+	disconnect bool
+	violations metrics.Counter
+	sleep      func(time.Duration)
+}
+
+// NewRateLimitHandler wraps the Handler with publish rate limiting per thing.
+// Messages and bytes limiters are optional. Depending on the mode, the client
+// that exceeded limits is either throttled or disconnected, while each
+// violation is counted.
+func NewRateLimitHandler(h Handler, msgs, bytes RateLimiter, mode string, violations metrics.Counter) Handler {
+	return &rateLimitHandler{
+		Handler:    h,
+		msgs:       msgs,
+		bytes:      bytes,
+		disconnect: mode == DisconnectMode,
+		violations: violations,
+		sleep:      time.Sleep,
+	}
+}
+
+func (rh *rateLimitHandler) AuthPublish(c *session.Client, topic *string, payload *[]byte) error {
+	if err := rh.Handler.AuthPublish(c, topic, payload); err != nil {
+		return err
+	}
+
+	var wait time.Duration
+	if rh.msgs != nil {
+		if d := rh.msgs.Reserve(c.Username, 1); d > 0 {
+			rh.violations.With("limit", msgsLimit).Add(1)
+			wait = d
+		}
+	}
+	if rh.bytes != nil && payload != nil {
+		if d := rh.bytes.Reserve(c.Username, len(*payload)); d > 0 {
+			rh.violations.With("limit", bytesLimit).Add(1)
+			if d > wait {
+				wait = d
+			}
+		}
+	}
+	if wait == 0 {
+		return nil
+	}
+
+	if rh.disconnect {
+		return errRateLimitExceeded
+	}
+	// Since packets are read from the client connection one by one,
+	// blocking the hook throttles the client.
+	rh.sleep(wait)
+	return nil
+}
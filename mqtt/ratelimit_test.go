@@ -0,0 +1,244 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mproxy/pkg/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeRateLimiter(rate float64, clock *fakeClock) RateLimiter {
+	rl := NewRateLimiter(rate).(*rateLimiter)
+	rl.now = clock.Now
+	return rl
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	clock := newFakeClock()
+	rl := newFakeRateLimiter(10, clock)
+
+	// The steps are run in order, sharing the bucket state.
+	cases := []struct {
+		desc    string
+		advance time.Duration
+		id      string
+		n       int
+		wait    time.Duration
+	}{
+		{
+			desc: "reserve burst",
+			id:   thingID,
+			n:    10,
+			wait: 0,
+		},
+		{
+			desc: "reserve over burst",
+			id:   thingID,
+			n:    1,
+			wait: 100 * time.Millisecond,
+		},
+		{
+			desc: "reserve over burst by another client",
+			id:   thingID2,
+			n:    10,
+			wait: 0,
+		},
+		{
+			desc:    "reserve after partial refill",
+			advance: 50 * time.Millisecond,
+			id:      thingID,
+			n:       1,
+			wait:    150 * time.Millisecond,
+		},
+		{
+			desc:    "reserve after refill",
+			advance: 300 * time.Millisecond,
+			id:      thingID,
+			n:       1,
+			wait:    0,
+		},
+		{
+			desc:    "reserve more than burst after long idle period",
+			advance: time.Hour,
+			id:      thingID,
+			n:       15,
+			wait:    500 * time.Millisecond,
+		},
+		{
+			desc:    "reserve after refill up to burst",
+			advance: time.Hour,
+			id:      thingID,
+			n:       10,
+			wait:    0,
+		},
+	}
+
+	for _, tc := range cases {
+		clock.advance(tc.advance)
+		wait := rl.Reserve(tc.id, tc.n)
+		assert.InDelta(t, float64(tc.wait), float64(wait), float64(time.Microsecond), fmt.Sprintf("%s: expected wait %s got %s\n", tc.desc, tc.wait, wait))
+	}
+}
+
+func TestRateLimiterEvict(t *testing.T) {
+	clock := newFakeClock()
+	rl := newFakeRateLimiter(10, clock).(*rateLimiter)
+
+	// The steps are run in order, sharing the limiters.
+	cases := []struct {
+		desc     string
+		advance  time.Duration
+		id       string
+		n        int
+		limiters int
+	}{
+		{
+			desc:     "reserve by client",
+			id:       thingID,
+			n:        10,
+			limiters: 1,
+		},
+		{
+			desc:     "reserve in debt by another client",
+			id:       thingID2,
+			n:        700,
+			limiters: 2,
+		},
+		{
+			desc:     "reserve while another client is in debt",
+			advance:  evictInterval,
+			id:       thingID,
+			n:        1,
+			limiters: 2,
+		},
+		{
+			desc:     "reserve after all the buckets are refilled",
+			advance:  evictInterval,
+			id:       thingID,
+			n:        1,
+			limiters: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		clock.advance(tc.advance)
+		rl.Reserve(tc.id, tc.n)
+		assert.Len(t, rl.limiters, tc.limiters, fmt.Sprintf("%s: expected %d limiters got %d\n", tc.desc, tc.limiters, len(rl.limiters)))
+	}
+}
+
+func TestRateLimitHandler(t *testing.T) {
+	payload := make([]byte, 100)
+
+	cases := []struct {
+		desc       string
+		mode       string
+		msgs       float64
+		bytes      float64
+		err        error
+		publishes  int
+		wantErr    error
+		sleep      time.Duration
+		violations map[string]float64
+	}{
+		{
+			desc:       "publish within limits",
+			mode:       ThrottleMode,
+			msgs:       2,
+			bytes:      200,
+			publishes:  2,
+			wantErr:    nil,
+			violations: map[string]float64{msgsLimit: 0, bytesLimit: 0},
+		},
+		{
+			desc:       "publish over messages limit in throttle mode",
+			mode:       ThrottleMode,
+			msgs:       2,
+			publishes:  3,
+			wantErr:    nil,
+			sleep:      500 * time.Millisecond,
+			violations: map[string]float64{msgsLimit: 1, bytesLimit: 0},
+		},
+		{
+			desc:       "publish over messages limit in disconnect mode",
+			mode:       DisconnectMode,
+			msgs:       2,
+			publishes:  3,
+			wantErr:    errRateLimitExceeded,
+			violations: map[string]float64{msgsLimit: 1, bytesLimit: 0},
+		},
+		{
+			desc:       "publish over bytes limit in throttle mode",
+			mode:       ThrottleMode,
+			bytes:      200,
+			publishes:  3,
+			wantErr:    nil,
+			sleep:      500 * time.Millisecond,
+			violations: map[string]float64{msgsLimit: 0, bytesLimit: 1},
+		},
+		{
+			desc:       "publish over bytes limit in disconnect mode",
+			mode:       DisconnectMode,
+			bytes:      200,
+			publishes:  3,
+			wantErr:    errRateLimitExceeded,
+			violations: map[string]float64{msgsLimit: 0, bytesLimit: 1},
+		},
+		{
+			desc:       "publish over both limits in throttle mode",
+			mode:       ThrottleMode,
+			msgs:       2,
+			bytes:      100,
+			publishes:  3,
+			wantErr:    nil,
+			sleep:      2 * time.Second,
+			violations: map[string]float64{msgsLimit: 0, bytesLimit: 2},
+		},
+		{
+			desc:       "publish unauthorized over limits",
+			mode:       DisconnectMode,
+			msgs:       2,
+			bytes:      200,
+			err:        errAuth,
+			publishes:  3,
+			wantErr:    errAuth,
+			violations: map[string]float64{msgsLimit: 0, bytesLimit: 0},
+		},
+	}
+
+	for _, tc := range cases {
+		clock := newFakeClock()
+		var msgs, bytes RateLimiter
+		if tc.msgs > 0 {
+			msgs = newFakeRateLimiter(tc.msgs, clock)
+		}
+		if tc.bytes > 0 {
+			bytes = newFakeRateLimiter(tc.bytes, clock)
+		}
+		violations := newCounterMock()
+		h := NewRateLimitHandler(&handlerMock{err: tc.err}, msgs, bytes, tc.mode, violations).(*rateLimitHandler)
+		// Throttled client is blocked while the buckets refill.
+		var slept time.Duration
+		h.sleep = func(d time.Duration) {
+			slept += d
+			clock.advance(d)
+		}
+
+		c := &session.Client{Username: thingID}
+		var err error
+		for i := 0; i < tc.publishes; i++ {
+			topic, payload := topic, payload
+			err = h.AuthPublish(c, &topic, &payload)
+		}
+		assert.Equal(t, tc.wantErr, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.wantErr, err))
+		assert.InDelta(t, float64(tc.sleep), float64(slept), float64(time.Microsecond), fmt.Sprintf("%s: expected sleep %s got %s\n", tc.desc, tc.sleep, slept))
+		for limit, v := range tc.violations {
+			assert.Equal(t, v, violations.value("limit", limit), fmt.Sprintf("%s: expected %v %s violations got %v\n", tc.desc, v, limit, violations.value("limit", limit)))
+		}
+	}
+}
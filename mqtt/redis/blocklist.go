@@ -0,0 +1,138 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	blockedThingsKey = "mqtt:blocked:things"
+	blockedNetsKey   = "mqtt:blocked:nets"
+)
+
+// ErrMalformedNet indicates that the value is neither IP address nor CIDR.
+var ErrMalformedNet = errors.New("malformed IP address or CIDR")
+
+// BlockList specifies the API for blocking things and IP ranges.
+type BlockList interface {
+	// BlockThing adds thing to the block list.
+	BlockThing(id string) error
+
+	// UnblockThing removes thing from the block list.
+	UnblockThing(id string) error
+
+	// BlockNet adds IP address or CIDR to the block list.
+	BlockNet(cidr string) error
+
+	// UnblockNet removes IP address or CIDR from the block list.
+	UnblockNet(cidr string) error
+
+	// Things retrieves blocked thing IDs.
+	Things() ([]string, error)
+
+	// Nets retrieves blocked networks in CIDR notation.
+	Nets() ([]string, error)
+
+	// ThingBlocked checks if the thing is blocked.
+	ThingBlocked(id string) (bool, error)
+
+	// IPBlocked checks if the IP address belongs to any of blocked networks.
+	IPBlocked(ip net.IP) (bool, error)
+}
+
+type blockList struct {
+	client *redis.Client
+}
+
+// NewBlockList returns Redis block list.
+func NewBlockList(client *redis.Client) BlockList {
+	return blockList{
+		client: client,
+	}
+}
+
+func (bl blockList) BlockThing(id string) error {
+	return bl.client.SAdd(context.Background(), blockedThingsKey, id).Err()
+}
+
+func (bl blockList) UnblockThing(id string) error {
+	return bl.client.SRem(context.Background(), blockedThingsKey, id).Err()
+}
+
+func (bl blockList) BlockNet(cidr string) error {
+	n, err := parseNet(cidr)
+	if err != nil {
+		return err
+	}
+	return bl.client.SAdd(context.Background(), blockedNetsKey, n.String()).Err()
+}
+
+func (bl blockList) UnblockNet(cidr string) error {
+	n, err := parseNet(cidr)
+	if err != nil {
+		return err
+	}
+	return bl.client.SRem(context.Background(), blockedNetsKey, n.String()).Err()
+}
+
+func (bl blockList) Things() ([]string, error) {
+	return bl.client.SMembers(context.Background(), blockedThingsKey).Result()
+}
+
+func (bl blockList) Nets() ([]string, error) {
+	return bl.client.SMembers(context.Background(), blockedNetsKey).Result()
+}
+
+func (bl blockList) ThingBlocked(id string) (bool, error) {
+	return bl.client.SIsMember(context.Background(), blockedThingsKey, id).Result()
+}
+
+func (bl blockList) IPBlocked(ip net.IP) (bool, error) {
+	nets, err := bl.Nets()
+	if err != nil {
+		return false, err
+	}
+
+	for _, cidr := range nets {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if n.Contains(ip) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// parseNet parses CIDR, treating a plain IP address as a single host network.
+func parseNet(cidr string) (*net.IPNet, error) {
+	if !strings.Contains(cidr, "/") {
+		ip := net.ParseIP(cidr)
+		if ip == nil {
+			return nil, ErrMalformedNet
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+		cidr = fmt.Sprintf("%s/%d", ip, bits)
+	}
+
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, ErrMalformedNet
+	}
+
+	return n, nil
+}
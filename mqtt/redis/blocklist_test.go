@@ -0,0 +1,65 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNet(t *testing.T) {
+	cases := []struct {
+		desc string
+		cidr string
+		net  string
+		err  error
+	}{
+		{
+			desc: "parse IPv4 address",
+			cidr: "192.168.1.10",
+			net:  "192.168.1.10/32",
+		},
+		{
+			desc: "parse IPv4 CIDR",
+			cidr: "192.168.1.10/24",
+			net:  "192.168.1.0/24",
+		},
+		{
+			desc: "parse IPv6 address",
+			cidr: "2001:db8::1",
+			net:  "2001:db8::1/128",
+		},
+		{
+			desc: "parse IPv6 CIDR",
+			cidr: "2001:db8::1/64",
+			net:  "2001:db8::/64",
+		},
+		{
+			desc: "parse IPv4-mapped IPv6 address",
+			cidr: "::ffff:192.168.1.10",
+			net:  "192.168.1.10/32",
+		},
+		{
+			desc: "parse malformed address",
+			cidr: "192.168.1",
+			err:  ErrMalformedNet,
+		},
+		{
+			desc: "parse CIDR with malformed prefix",
+			cidr: "192.168.1.10/33",
+			err:  ErrMalformedNet,
+		},
+	}
+
+	for _, tc := range cases {
+		n, err := parseNet(tc.cidr)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, tc.net, n.String(), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.net, n))
+	}
+}
@@ -3,6 +3,8 @@
 
 package redis
 
+import "strconv"
+
 type event interface {
 	Encode() map[string]interface{}
 }
@@ -11,18 +13,36 @@ var (
 	_ event = (*mqttEvent)(nil)
 )
 
+// ConnInfo contains the details of the client connection.
+type ConnInfo struct {
+	RemoteIP     string
+	TLS          bool
+	Version      byte
+	CleanSession bool
+}
+
 type mqttEvent struct {
 	clientID  string
 	timestamp string
 	eventType string
 	instance  string
+	conn      *ConnInfo
 }
 
 func (me mqttEvent) Encode() map[string]interface{} {
-	return map[string]interface{}{
+	val := map[string]interface{}{
 		"thing_id":   me.clientID,
 		"timestamp":  me.timestamp,
 		"event_type": me.eventType,
 		"instance":   me.instance,
 	}
+
+	if me.conn != nil {
+		val["remote_ip"] = me.conn.RemoteIP
+		val["tls"] = strconv.FormatBool(me.conn.TLS)
+		val["mqtt_version"] = strconv.Itoa(int(me.conn.Version))
+		val["clean_session"] = strconv.FormatBool(me.conn.CleanSession)
+	}
+
+	return val
 }
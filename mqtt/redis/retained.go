@@ -0,0 +1,136 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	retainedPrefix = "mqtt:retained"
+	scanCount      = 100
+)
+
+// ErrPayloadTooLarge indicates that retained payload exceeds configured size limit.
+var ErrPayloadTooLarge = errors.New("retained payload exceeds size limit")
+
+var globReplacer = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// RetainedStore specifies retained messages persistence API.
+type RetainedStore interface {
+	// Save stores payload as the last retained message for the given topic.
+	// Empty payload removes the retained message, as specified by MQTT.
+	Save(topic string, payload []byte) error
+
+	// Retrieve returns retained payloads of all topics that match the filter.
+	Retrieve(filter string) (map[string][]byte, error)
+}
+
+type retainedStore struct {
+	client  *redis.Client
+	maxSize int
+	ttl     time.Duration
+}
+
+// NewRetainedStore returns Redis retained messages store. Payloads larger than
+// maxSize are rejected if maxSize is positive and stored messages expire after
+// ttl if ttl is positive.
+func NewRetainedStore(client *redis.Client, maxSize int, ttl time.Duration) RetainedStore {
+	return retainedStore{
+		client:  client,
+		maxSize: maxSize,
+		ttl:     ttl,
+	}
+}
+
+func (rs retainedStore) Save(topic string, payload []byte) error {
+	key := fmt.Sprintf("%s:%s", retainedPrefix, normalize(topic))
+	if len(payload) == 0 {
+		return rs.client.Del(context.Background(), key).Err()
+	}
+	if rs.maxSize > 0 && len(payload) > rs.maxSize {
+		return ErrPayloadTooLarge
+	}
+
+	return rs.client.Set(context.Background(), key, payload, rs.ttl).Err()
+}
+
+func (rs retainedStore) Retrieve(filter string) (map[string][]byte, error) {
+	filter = normalize(filter)
+	ctx := context.Background()
+	msgs := make(map[string][]byte)
+
+	if !strings.ContainsAny(filter, "+#") {
+		payload, err := rs.client.Get(ctx, fmt.Sprintf("%s:%s", retainedPrefix, filter)).Bytes()
+		if err == redis.Nil {
+			return msgs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		msgs[filter] = payload
+		return msgs, nil
+	}
+
+	pattern := fmt.Sprintf("%s:%s", retainedPrefix, toGlob(filter))
+	iter := rs.client.Scan(ctx, 0, pattern, scanCount).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		topic := strings.TrimPrefix(key, retainedPrefix+":")
+		if !matches(filter, topic) {
+			continue
+		}
+		payload, err := rs.client.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			// Key expired in the meantime.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		msgs[topic] = payload
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return msgs, nil
+}
+
+func normalize(topic string) string {
+	return strings.TrimPrefix(topic, "/")
+}
+
+// toGlob converts MQTT topic filter to Redis glob pattern. Since Redis glob
+// does not respect topic levels, the result is wider than the filter and keys
+// need to be checked using matches.
+func toGlob(filter string) string {
+	filter = globReplacer.Replace(filter)
+	filter = strings.ReplaceAll(filter, "+", "*")
+	return strings.ReplaceAll(filter, "#", "*")
+}
+
+// matches checks if the topic matches MQTT topic filter.
+func matches(filter, topic string) bool {
+	fl := strings.Split(filter, "/")
+	tl := strings.Split(topic, "/")
+	for i, f := range fl {
+		if f == "#" {
+			return true
+		}
+		if i >= len(tl) {
+			return false
+		}
+		if f != "+" && f != tl[i] {
+			return false
+		}
+	}
+	return len(fl) == len(tl)
+}
@@ -0,0 +1,131 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToGlob(t *testing.T) {
+	cases := []struct {
+		desc   string
+		filter string
+		glob   string
+	}{
+		{
+			desc:   "convert filter without wildcards",
+			filter: "channels/1/messages",
+			glob:   "channels/1/messages",
+		},
+		{
+			desc:   "convert filter with single-level wildcard",
+			filter: "channels/+/messages",
+			glob:   "channels/*/messages",
+		},
+		{
+			desc:   "convert filter with multi-level wildcard",
+			filter: "channels/1/messages/#",
+			glob:   "channels/1/messages/*",
+		},
+		{
+			desc:   "convert filter with both wildcards",
+			filter: "channels/+/messages/#",
+			glob:   "channels/*/messages/*",
+		},
+		{
+			desc:   "convert filter with glob special characters",
+			filter: `channels/*/messages/?/[a]/\`,
+			glob:   `channels/\*/messages/\?/\[a\]/\\`,
+		},
+	}
+
+	for _, tc := range cases {
+		glob := toGlob(tc.filter)
+		assert.Equal(t, tc.glob, glob, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.glob, glob))
+	}
+}
+
+func TestMatches(t *testing.T) {
+	cases := []struct {
+		desc    string
+		filter  string
+		topic   string
+		matches bool
+	}{
+		{
+			desc:    "match the same topic",
+			filter:  "channels/1/messages",
+			topic:   "channels/1/messages",
+			matches: true,
+		},
+		{
+			desc:    "match different topic",
+			filter:  "channels/1/messages",
+			topic:   "channels/2/messages",
+			matches: false,
+		},
+		{
+			desc:    "match single-level wildcard",
+			filter:  "channels/+/messages",
+			topic:   "channels/1/messages",
+			matches: true,
+		},
+		{
+			desc:    "match single-level wildcard against multiple levels",
+			filter:  "channels/+/messages",
+			topic:   "channels/1/2/messages",
+			matches: false,
+		},
+		{
+			desc:    "match single-level wildcard against missing level",
+			filter:  "channels/1/messages/+",
+			topic:   "channels/1/messages",
+			matches: false,
+		},
+		{
+			desc:    "match multi-level wildcard against one level",
+			filter:  "channels/1/messages/#",
+			topic:   "channels/1/messages/temperature",
+			matches: true,
+		},
+		{
+			desc:    "match multi-level wildcard against multiple levels",
+			filter:  "channels/1/messages/#",
+			topic:   "channels/1/messages/room/temperature",
+			matches: true,
+		},
+		{
+			desc:    "match multi-level wildcard against parent level",
+			filter:  "channels/1/messages/#",
+			topic:   "channels/1/messages",
+			matches: true,
+		},
+		{
+			desc:    "match multi-level wildcard against different topic",
+			filter:  "channels/1/messages/#",
+			topic:   "channels/2/messages/temperature",
+			matches: false,
+		},
+		{
+			desc:    "match both wildcards",
+			filter:  "channels/+/messages/#",
+			topic:   "channels/1/messages/temperature",
+			matches: true,
+		},
+		{
+			desc:    "match longer topic",
+			filter:  "channels/1/messages",
+			topic:   "channels/1/messages/temperature",
+			matches: false,
+		},
+	}
+
+	for _, tc := range cases {
+		matches := matches(tc.filter, tc.topic)
+		assert.Equal(t, tc.matches, matches, fmt.Sprintf("%s: expected %t got %t\n", tc.desc, tc.matches, matches))
+	}
+}
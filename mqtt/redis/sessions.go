@@ -0,0 +1,138 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	sessionPrefix  = "mqtt:session"
+	subsSuffix     = "subs"
+	inflightSuffix = "inflight"
+)
+
+// SessionStore specifies persistent MQTT sessions storage API. Sessions are
+// identified by the thing ID and client ID, so that different things using
+// the same client ID do not share sessions.
+type SessionStore interface {
+	// SaveSubscriptions adds topics to the session subscriptions.
+	SaveSubscriptions(thingID, clientID string, topics []string) error
+
+	// RemoveSubscriptions removes topics from the session subscriptions.
+	RemoveSubscriptions(thingID, clientID string, topics []string) error
+
+	// Subscriptions retrieves the session subscriptions.
+	Subscriptions(thingID, clientID string) ([]string, error)
+
+	// SaveInflight stores the ID of the QoS 2 message not released yet.
+	SaveInflight(thingID, clientID string, id uint16) error
+
+	// RemoveInflight removes the ID of the released QoS 2 message.
+	RemoveInflight(thingID, clientID string, id uint16) error
+
+	// Inflight retrieves the IDs of the QoS 2 messages not released yet.
+	Inflight(thingID, clientID string) ([]uint16, error)
+
+	// Remove removes the session.
+	Remove(thingID, clientID string) error
+}
+
+type sessionStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewSessionStore returns Redis session store. Sessions expire after ttl
+// since the last change, unless ttl is zero.
+func NewSessionStore(client *redis.Client, ttl time.Duration) SessionStore {
+	return sessionStore{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (ss sessionStore) SaveSubscriptions(thingID, clientID string, topics []string) error {
+	if len(topics) == 0 {
+		return nil
+	}
+	key := sessionKey(thingID, clientID, subsSuffix)
+	return ss.add(key, toMembers(topics)...)
+}
+
+func (ss sessionStore) RemoveSubscriptions(thingID, clientID string, topics []string) error {
+	if len(topics) == 0 {
+		return nil
+	}
+	key := sessionKey(thingID, clientID, subsSuffix)
+	return ss.client.SRem(context.Background(), key, toMembers(topics)...).Err()
+}
+
+func (ss sessionStore) Subscriptions(thingID, clientID string) ([]string, error) {
+	key := sessionKey(thingID, clientID, subsSuffix)
+	return ss.client.SMembers(context.Background(), key).Result()
+}
+
+func (ss sessionStore) SaveInflight(thingID, clientID string, id uint16) error {
+	key := sessionKey(thingID, clientID, inflightSuffix)
+	return ss.add(key, id)
+}
+
+func (ss sessionStore) RemoveInflight(thingID, clientID string, id uint16) error {
+	key := sessionKey(thingID, clientID, inflightSuffix)
+	return ss.client.SRem(context.Background(), key, id).Err()
+}
+
+func (ss sessionStore) Inflight(thingID, clientID string) ([]uint16, error) {
+	key := sessionKey(thingID, clientID, inflightSuffix)
+	vals, err := ss.client.SMembers(context.Background(), key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint16, 0, len(vals))
+	for _, v := range vals {
+		id, err := strconv.ParseUint(v, 10, 16)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint16(id))
+	}
+
+	return ids, nil
+}
+
+func (ss sessionStore) Remove(thingID, clientID string) error {
+	subs := sessionKey(thingID, clientID, subsSuffix)
+	inflight := sessionKey(thingID, clientID, inflightSuffix)
+	return ss.client.Del(context.Background(), subs, inflight).Err()
+}
+
+func (ss sessionStore) add(key string, members ...interface{}) error {
+	ctx := context.Background()
+	pipe := ss.client.TxPipeline()
+	pipe.SAdd(ctx, key, members...)
+	if ss.ttl > 0 {
+		pipe.Expire(ctx, key, ss.ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func sessionKey(thingID, clientID, suffix string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", sessionPrefix, thingID, clientID, suffix)
+}
+
+func toMembers(topics []string) []interface{} {
+	members := make([]interface{}, len(topics))
+	for i, t := range topics {
+		members[i] = t
+	}
+	return members
+}
@@ -31,7 +31,7 @@ func NewEventStore(client *redis.Client, instance string) EventStore {
 	}
 }
 
-func (es EventStore) storeEvent(clientID, eventType string) error {
+func (es EventStore) storeEvent(clientID, eventType string, conn *ConnInfo) error {
 	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
 
 	event := mqttEvent{
@@ -39,6 +39,7 @@ func (es EventStore) storeEvent(clientID, eventType string) error {
 		timestamp: timestamp,
 		eventType: eventType,
 		instance:  es.instance,
+		conn:      conn,
 	}
 
 	record := &redis.XAddArgs{
@@ -55,11 +56,16 @@ func (es EventStore) storeEvent(clientID, eventType string) error {
 }
 
 // Connect issues event on MQTT CONNECT
-func (es EventStore) Connect(clientID string) error {
-	return es.storeEvent(clientID, "connect")
+func (es EventStore) Connect(clientID string, conn ConnInfo) error {
+	return es.storeEvent(clientID, "connect", &conn)
 }
 
 // Disconnect issues event on MQTT CONNECT
-func (es EventStore) Disconnect(clientID string) error {
-	return es.storeEvent(clientID, "disconnect")
+func (es EventStore) Disconnect(clientID string, conn ConnInfo) error {
+	return es.storeEvent(clientID, "disconnect", &conn)
+}
+
+// Will issues event when Last Will and Testament message is published
+func (es EventStore) Will(clientID string) error {
+	return es.storeEvent(clientID, "will", nil)
 }
@@ -0,0 +1,111 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/logger"
+)
+
+const (
+	thingsStream = "mainflux.things"
+
+	thingRemove     = "thing.remove"
+	thingDisconnect = "thing.disconnect"
+	channelRemove   = "channel.remove"
+
+	maxReadInterval = time.Minute
+)
+
+// AuthInvalidator specifies the API for removing stale authorization decisions.
+type AuthInvalidator interface {
+	// Remove removes the decision for the given channel and thing.
+	Remove(chanID, thingID string)
+
+	// RemoveThing removes all the decisions for the given thing.
+	RemoveThing(thingID string)
+
+	// RemoveChannel removes all the decisions for the given channel.
+	RemoveChannel(chanID string)
+}
+
+// ThingsSubscriber represents things event stream subscriber.
+type ThingsSubscriber interface {
+	// Subscribe receives things events until the context is canceled.
+	Subscribe(ctx context.Context) error
+}
+
+type thingsSubscriber struct {
+	client *redis.Client
+	inv    AuthInvalidator
+	logger logger.Logger
+}
+
+// NewThingsSubscriber returns subscriber which invalidates authorization
+// decisions on things removal, disconnection and channels removal. Since each
+// adapter instance keeps its own decisions, the stream is read without
+// consumer group, so that all the instances receive all the events. Failed
+// reads are retried with exponential backoff, up to a minute apart.
+func NewThingsSubscriber(client *redis.Client, inv AuthInvalidator, logger logger.Logger) ThingsSubscriber {
+	return thingsSubscriber{
+		client: client,
+		inv:    inv,
+		logger: logger,
+	}
+}
+
+func (ts thingsSubscriber) Subscribe(ctx context.Context) error {
+	b := backoff.NewExponentialBackOff()
+	b.MaxInterval = maxReadInterval
+	b.MaxElapsedTime = 0
+
+	last := "$"
+	for {
+		streams, err := ts.client.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{thingsStream, last},
+			Count:   100,
+		}).Result()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			wait := b.NextBackOff()
+			ts.logger.Warn(fmt.Sprintf("Failed to read things events, retrying in %s: %s", wait, err))
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			continue
+		}
+		b.Reset()
+		if len(streams) == 0 {
+			continue
+		}
+
+		for _, msg := range streams[0].Messages {
+			last = msg.ID
+			event := msg.Values
+
+			switch event["operation"] {
+			case thingRemove:
+				ts.inv.RemoveThing(read(event, "id"))
+			case thingDisconnect:
+				ts.inv.Remove(read(event, "chan_id"), read(event, "thing_id"))
+			case channelRemove:
+				ts.inv.RemoveChannel(read(event, "id"))
+			}
+		}
+	}
+}
+
+func read(event map[string]interface{}, key string) string {
+	val, _ := event[key].(string)
+	return val
+}
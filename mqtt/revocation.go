@@ -0,0 +1,152 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mproxy/pkg/session"
+)
+
+var (
+	errRevoked         = errors.New("client certificate is revoked")
+	errCRLFetch        = errors.New("failed to fetch certificate revocation list")
+	errCRLSignature    = errors.New("certificate revocation list is not signed by the client CA")
+	errRevocationCheck = errors.New("failed to check certificate revocation")
+)
+
+// RevocationList represents the list of the revoked client certificates.
+type RevocationList interface {
+	// Revoked checks if the certificate is revoked.
+	Revoked(cert x509.Certificate) (bool, error)
+}
+
+var _ RevocationList = (*crl)(nil)
+
+type crl struct {
+	url     string
+	client  *http.Client
+	cas     []*x509.Certificate
+	refresh time.Duration
+	mu      sync.Mutex
+	revoked map[string]bool
+	next    time.Time
+}
+
+// NewCRL returns RevocationList which fetches the DER encoded certificate
+// revocation list from the given URL, such as the certs service /crl
+// endpoint. The list must be signed by one of the client CA certificates. It
+// is fetched again once the refresh interval or the list next update time
+// passes, whichever comes first. If fetching fails, the previously fetched
+// list is used until the next attempt.
+func NewCRL(url string, cas []*x509.Certificate, refresh time.Duration, client *http.Client) RevocationList {
+	return &crl{
+		url:     url,
+		client:  client,
+		cas:     cas,
+		refresh: refresh,
+	}
+}
+
+func (c *crl) Revoked(cert x509.Certificate) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now := time.Now(); !now.Before(c.next) {
+		if err := c.fetch(now); err != nil && c.revoked == nil {
+			return false, err
+		}
+	}
+
+	return c.revoked[cert.SerialNumber.String()], nil
+}
+
+func (c *crl) fetch(now time.Time) error {
+	res, err := c.client.Get(c.url)
+	if err != nil {
+		return errCRLFetch
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errCRLFetch
+	}
+
+	der, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errCRLFetch
+	}
+	list, err := x509.ParseCRL(der)
+	if err != nil {
+		return errCRLFetch
+	}
+	if !c.signed(list) {
+		return errCRLSignature
+	}
+
+	revoked := make(map[string]bool)
+	for _, rc := range list.TBSCertList.RevokedCertificates {
+		revoked[rc.SerialNumber.String()] = true
+	}
+	c.revoked = revoked
+
+	c.next = now.Add(c.refresh)
+	if next := list.TBSCertList.NextUpdate; !next.IsZero() && next.Before(c.next) {
+		c.next = next
+	}
+
+	return nil
+}
+
+func (c *crl) signed(list *pkix.CertificateList) bool {
+	for _, ca := range c.cas {
+		if err := ca.CheckCRLSignature(list); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+var _ Handler = (*revocationHandler)(nil)
+
+type revocationHandler struct {
+	Handler
+	revoked RevocationList
+	logger  logger.Logger
+}
+
+// NewRevocationHandler wraps the Handler with the check whether the client
+// certificate is revoked, which takes place on connect, before the thing is
+// authenticated using the certificate. Since the certificate is the client
+// credential, the client is refused if the check fails.
+func NewRevocationHandler(h Handler, revoked RevocationList, logger logger.Logger) Handler {
+	return &revocationHandler{
+		Handler: h,
+		revoked: revoked,
+		logger:  logger,
+	}
+}
+
+func (rh *revocationHandler) AuthConnect(c *session.Client) error {
+	if c != nil && len(c.Cert.Raw) > 0 {
+		revoked, err := rh.revoked.Revoked(c.Cert)
+		if err != nil {
+			rh.logger.Warn(fmt.Sprintf("Failed to check certificate revocation: %s", err))
+			return errRevocationCheck
+		}
+		if revoked {
+			rh.logger.Info(fmt.Sprintf("Refused revoked certificate with serial: %s", c.Cert.SerialNumber))
+			return errRevoked
+		}
+	}
+
+	return rh.Handler.AuthConnect(c)
+}
@@ -0,0 +1,204 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/mqtt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(cn string) (testCA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return testCA{}, err
+	}
+
+	tmpl := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		return testCA{}, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return testCA{}, err
+	}
+
+	return testCA{cert: cert, key: key}, nil
+}
+
+func (ca testCA) crl(next time.Time, serials ...int64) ([]byte, error) {
+	revoked := []pkix.RevokedCertificate{}
+	for _, s := range serials {
+		revoked = append(revoked, pkix.RevokedCertificate{
+			SerialNumber:   big.NewInt(s),
+			RevocationTime: time.Now(),
+		})
+	}
+	return ca.cert.CreateCRL(rand.Reader, ca.key, revoked, time.Now(), next)
+}
+
+// crlServer serves the CRL, counting the requests.
+type crlServer struct {
+	mu       sync.Mutex
+	crl      []byte
+	status   int
+	requests int
+}
+
+func (cs *crlServer) set(crl []byte, status int) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.crl = crl
+	cs.status = status
+}
+
+func (cs *crlServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.requests++
+	w.WriteHeader(cs.status)
+	w.Write(cs.crl)
+}
+
+func TestCRLRevoked(t *testing.T) {
+	ca, err := newTestCA("ca")
+	require.Nil(t, err, fmt.Sprintf("unexpected CA creation error: %s\n", err))
+	other, err := newTestCA("other")
+	require.Nil(t, err, fmt.Sprintf("unexpected CA creation error: %s\n", err))
+
+	valid, err := ca.crl(time.Now().Add(time.Hour), 2)
+	require.Nil(t, err, fmt.Sprintf("unexpected CRL creation error: %s\n", err))
+	expired, err := ca.crl(time.Now().Add(-time.Minute), 2)
+	require.Nil(t, err, fmt.Sprintf("unexpected CRL creation error: %s\n", err))
+	unsigned, err := other.crl(time.Now().Add(time.Hour), 2)
+	require.Nil(t, err, fmt.Sprintf("unexpected CRL creation error: %s\n", err))
+
+	cases := []struct {
+		desc     string
+		crl      []byte
+		status   int
+		serial   int64
+		revoked  bool
+		requests int
+		err      bool
+	}{
+		{
+			desc:     "check revoked cert",
+			crl:      valid,
+			status:   http.StatusOK,
+			serial:   2,
+			revoked:  true,
+			requests: 1,
+		},
+		{
+			desc:     "check valid cert",
+			crl:      valid,
+			status:   http.StatusOK,
+			serial:   3,
+			revoked:  false,
+			requests: 1,
+		},
+		{
+			desc:     "check revoked cert using expired CRL",
+			crl:      expired,
+			status:   http.StatusOK,
+			serial:   2,
+			revoked:  true,
+			requests: 2,
+		},
+		{
+			desc:     "check cert using CRL not signed by CA",
+			crl:      unsigned,
+			status:   http.StatusOK,
+			serial:   2,
+			requests: 2,
+			err:      true,
+		},
+		{
+			desc:     "check cert using malformed CRL",
+			crl:      []byte("crl"),
+			status:   http.StatusOK,
+			serial:   2,
+			requests: 2,
+			err:      true,
+		},
+		{
+			desc:     "check cert with unavailable CRL",
+			crl:      nil,
+			status:   http.StatusInternalServerError,
+			serial:   2,
+			requests: 2,
+			err:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		srv := &crlServer{}
+		srv.set(tc.crl, tc.status)
+		ts := httptest.NewServer(srv)
+		crl := mqtt.NewCRL(ts.URL, []*x509.Certificate{ca.cert}, time.Hour, ts.Client())
+
+		cert := x509.Certificate{SerialNumber: big.NewInt(tc.serial)}
+		for i := 0; i < 2; i++ {
+			revoked, err := crl.Revoked(cert)
+			assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.revoked, revoked, fmt.Sprintf("%s: expected revoked %t got %t\n", tc.desc, tc.revoked, revoked))
+		}
+		ts.Close()
+		assert.Equal(t, tc.requests, srv.requests, fmt.Sprintf("%s: expected %d CRL requests got %d\n", tc.desc, tc.requests, srv.requests))
+	}
+}
+
+func TestCRLUnavailable(t *testing.T) {
+	ca, err := newTestCA("ca")
+	require.Nil(t, err, fmt.Sprintf("unexpected CA creation error: %s\n", err))
+
+	expired, err := ca.crl(time.Now().Add(-time.Minute), 2)
+	require.Nil(t, err, fmt.Sprintf("unexpected CRL creation error: %s\n", err))
+
+	srv := &crlServer{}
+	srv.set(expired, http.StatusOK)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	crl := mqtt.NewCRL(ts.URL, []*x509.Certificate{ca.cert}, time.Hour, ts.Client())
+
+	cert := x509.Certificate{SerialNumber: big.NewInt(2)}
+	revoked, err := crl.Revoked(cert)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	assert.True(t, revoked, "expected cert to be revoked")
+
+	// Previously fetched list is used while the CRL is unavailable.
+	srv.set(nil, http.StatusInternalServerError)
+	revoked, err = crl.Revoked(cert)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	assert.True(t, revoked, "expected cert to be revoked using previously fetched CRL")
+	assert.Equal(t, 2, srv.requests, fmt.Sprintf("expected %d CRL requests got %d\n", 2, srv.requests))
+}
@@ -0,0 +1,104 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mproxy/pkg/session"
+)
+
+const (
+	thingID  = "thing"
+	thingID2 = "thing2"
+	chanID   = "channel"
+	chanID2  = "channel2"
+	topic    = "channels/channel/messages"
+)
+
+var errAuth = errors.New("auth error")
+
+// fakeClock is the clock advanced by the tests instead of the passage of time.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// handlerMock is the Handler wrapped by the tested handlers, which accepts
+// the clients unless err is set.
+type handlerMock struct {
+	Handler
+	err          error
+	disconnected []string
+}
+
+func (h *handlerMock) AuthConnect(c *session.Client) error {
+	return h.err
+}
+
+func (h *handlerMock) AuthPublish(c *session.Client, topic *string, payload *[]byte) error {
+	return h.err
+}
+
+func (h *handlerMock) AuthSubscribe(c *session.Client, topics *[]string) error {
+	return h.err
+}
+
+func (h *handlerMock) Disconnect(c *session.Client) {
+	h.disconnected = append(h.disconnected, c.Username)
+}
+
+// counterMock counts the increments per label values.
+type counterMock struct {
+	mu     *sync.Mutex
+	lvs    string
+	counts map[string]float64
+}
+
+func newCounterMock() *counterMock {
+	return &counterMock{
+		mu:     &sync.Mutex{},
+		counts: make(map[string]float64),
+	}
+}
+
+func (c *counterMock) With(labelValues ...string) metrics.Counter {
+	return &counterMock{
+		mu:     c.mu,
+		lvs:    strings.Join(labelValues, ","),
+		counts: c.counts,
+	}
+}
+
+func (c *counterMock) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[c.lvs] += delta
+}
+
+func (c *counterMock) value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[strings.Join(labelValues, ",")]
+}
@@ -5,28 +5,59 @@ package opcua
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/opcua/db"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	mfsenml "github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/senml"
 )
 
-const protocol = "opcua"
+const (
+	protocol = "opcua"
+
+	// CommandsSubtopic is the subtopic prefix of the write commands, followed
+	// by the ID of the thing mapped to the written node.
+	CommandsSubtopic = "commands"
+
+	// WriteSubtopic is the subtopic of the write command results.
+	WriteSubtopic = "events.write"
+)
 
 var (
 	// ErrMalformedEntity indicates malformed entity specification.
 	ErrMalformedEntity = errors.New("malformed entity specification")
+
+	// ErrNotFoundServerURI indicates a non-existent route map for a channel.
+	ErrNotFoundServerURI = errors.New("route map not found for this channel")
+
+	// ErrNotFoundNodeID indicates a non-existent route map for a thing.
+	ErrNotFoundNodeID = errors.New("route map not found for this thing")
+
+	// ErrUnauthorizedAccess indicates missing or invalid credentials provided
+	// when accessing a protected resource.
+	ErrUnauthorizedAccess = errors.New("missing or invalid credentials provided")
+
+	// ErrNotConnected indicates a non-existent connection of the thing to the channel.
+	ErrNotConnected = errors.New("thing is not connected to the channel")
 )
 
 // Service specifies an API that must be fullfiled by the domain service
 // implementation, and all of its decorators (e.g. logging & metrics).
 type Service interface {
-	// CreateThing creates thingID:OPC-UA-nodeID route-map
-	CreateThing(ctx context.Context, thingID, nodeID string) error
+	// CreateThing creates thingID:OPC-UA-nodeID route-map, along with the
+	// monitoring parameters of the node
+	CreateThing(ctx context.Context, thingID, nodeID string, params MonitoringParams) error
 
-	// UpdateThing updates thingID:OPC-UA-nodeID route-map
-	UpdateThing(ctx context.Context, thingID, nodeID string) error
+	// UpdateThing updates thingID:OPC-UA-nodeID route-map, along with the
+	// monitoring parameters of the node
+	UpdateThing(ctx context.Context, thingID, nodeID string, params MonitoringParams) error
 
 	// RemoveThing removes thingID:OPC-UA-nodeID route-map
 	RemoveThing(ctx context.Context, thingID string) error
@@ -46,8 +77,27 @@ type Service interface {
 	// DisconnectThing removes thingID:channelID route-map
 	DisconnectThing(ctx context.Context, chanID, thingID string) error
 
-	// Browse browses available nodes for a given OPC-UA Server URI and NodeID
-	Browse(ctx context.Context, serverURI, namespace, identifier string) ([]BrowsedNode, error)
+	// Browse browses available nodes for a given OPC-UA Server URI and NodeID,
+	// walking the address space down to the given depth
+	Browse(ctx context.Context, serverURI, namespace, identifier string, depth uint64) ([]BrowsedNode, error)
+
+	// HistoryRead republishes the historical values of the OPC-UA node in
+	// the time range as the messages of the mapped thing, returning their number
+	HistoryRead(ctx context.Context, serverURI, namespace, identifier string, from, to time.Time) (int, error)
+
+	// Call calls the OPC-UA method of the node mapped to the thing identified
+	// by the key, on the server mapped to the channel, returning the output
+	// arguments.
+	Call(ctx context.Context, key, chanID, methodID string, args []interface{}) ([]interface{}, error)
+
+	// Command writes the value of the command message to the OPC-UA node
+	// mapped to the commanded thing, and publishes the write result.
+	Command(ctx context.Context, msg messaging.Message) error
+}
+
+// WriteCommand represents the command writing the value to the OPC-UA node.
+type WriteCommand struct {
+	Value interface{} `json:"value"`
 }
 
 // Config OPC-UA Server
@@ -59,6 +109,8 @@ type Config struct {
 	Mode      string
 	CertFile  string
 	KeyFile   string
+	// Monitoring holds the monitored item parameters of the node.
+	Monitoring MonitoringParams
 }
 
 var _ Service = (*adapterService)(nil)
@@ -66,35 +118,59 @@ var _ Service = (*adapterService)(nil)
 type adapterService struct {
 	subscriber Subscriber
 	browser    Browser
+	reader     HistoryReader
+	writer     Writer
+	caller     Caller
+	things     mainflux.ThingsServiceClient
+	publisher  messaging.Publisher
 	thingsRM   RouteMapRepository
 	channelsRM RouteMapRepository
 	connectRM  RouteMapRepository
+	monitoring MonitoringRepository
 	cfg        Config
 	logger     logger.Logger
 }
 
 // New instantiates the OPC-UA adapter implementation.
-func New(sub Subscriber, brow Browser, thingsRM, channelsRM, connectRM RouteMapRepository, cfg Config, log logger.Logger) Service {
+func New(sub Subscriber, brow Browser, reader HistoryReader, writer Writer, caller Caller, things mainflux.ThingsServiceClient, publisher messaging.Publisher, thingsRM, channelsRM, connectRM RouteMapRepository, monitoring MonitoringRepository, cfg Config, log logger.Logger) Service {
 	return &adapterService{
 		subscriber: sub,
 		browser:    brow,
+		reader:     reader,
+		writer:     writer,
+		caller:     caller,
+		things:     things,
+		publisher:  publisher,
 		thingsRM:   thingsRM,
 		channelsRM: channelsRM,
 		connectRM:  connectRM,
+		monitoring: monitoring,
 		cfg:        cfg,
 		logger:     log,
 	}
 }
 
-func (as *adapterService) CreateThing(ctx context.Context, thingID, nodeID string) error {
-	return as.thingsRM.Save(ctx, thingID, nodeID)
+func (as *adapterService) CreateThing(ctx context.Context, thingID, nodeID string, params MonitoringParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	if err := as.thingsRM.Save(ctx, thingID, nodeID); err != nil {
+		return err
+	}
+
+	if params == (MonitoringParams{}) {
+		return as.monitoring.Remove(ctx, thingID)
+	}
+	return as.monitoring.Save(ctx, thingID, params)
 }
 
-func (as *adapterService) UpdateThing(ctx context.Context, thingID, nodeID string) error {
-	return as.thingsRM.Save(ctx, thingID, nodeID)
+func (as *adapterService) UpdateThing(ctx context.Context, thingID, nodeID string, params MonitoringParams) error {
+	return as.CreateThing(ctx, thingID, nodeID, params)
 }
 
 func (as *adapterService) RemoveThing(ctx context.Context, thingID string) error {
+	as.monitoring.Remove(ctx, thingID)
 	return as.thingsRM.Remove(ctx, thingID)
 }
 
@@ -121,8 +197,13 @@ func (as *adapterService) ConnectThing(ctx context.Context, chanID, thingID stri
 		return err
 	}
 
-	as.cfg.NodeID = nodeID
-	as.cfg.ServerURI = serverURI
+	// The default parameters are used if the node has none.
+	params, _ := as.monitoring.Get(ctx, thingID)
+
+	cfg := as.cfg
+	cfg.NodeID = nodeID
+	cfg.ServerURI = serverURI
+	cfg.Monitoring = params
 
 	c := fmt.Sprintf("%s:%s", chanID, thingID)
 	if err := as.connectRM.Save(ctx, c, c); err != nil {
@@ -130,26 +211,157 @@ func (as *adapterService) ConnectThing(ctx context.Context, chanID, thingID stri
 	}
 
 	go func() {
-		if err := as.subscriber.Subscribe(ctx, as.cfg); err != nil {
+		if err := as.subscriber.Subscribe(ctx, cfg); err != nil {
 			as.logger.Warn(fmt.Sprintf("subscription failed: %s", err))
 		}
 	}()
 
 	// Store subscription details
-	return db.Save(serverURI, nodeID)
+	monitoring, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return db.Save(serverURI, nodeID, string(monitoring))
 }
 
-func (as *adapterService) Browse(ctx context.Context, serverURI, namespace, identifier string) ([]BrowsedNode, error) {
+func (as *adapterService) Browse(ctx context.Context, serverURI, namespace, identifier string, depth uint64) ([]BrowsedNode, error) {
 	nodeID := fmt.Sprintf("%s;%s", namespace, identifier)
 
-	nodes, err := as.browser.Browse(serverURI, nodeID)
+	nodes, err := as.browser.Browse(serverURI, nodeID, depth)
 	if err != nil {
 		return nil, err
 	}
 	return nodes, nil
 }
 
+func (as *adapterService) HistoryRead(ctx context.Context, serverURI, namespace, identifier string, from, to time.Time) (int, error) {
+	nodeID := fmt.Sprintf("%s;%s", namespace, identifier)
+
+	chanID, err := as.channelsRM.Get(ctx, serverURI)
+	if err != nil {
+		return 0, ErrNotFoundServerURI
+	}
+
+	thingID, err := as.thingsRM.Get(ctx, nodeID)
+	if err != nil {
+		return 0, ErrNotFoundNodeID
+	}
+
+	c := fmt.Sprintf("%s:%s", chanID, thingID)
+	if _, err := as.connectRM.Get(ctx, c); err != nil {
+		return 0, ErrNotConnected
+	}
+
+	cfg := as.cfg
+	cfg.ServerURI = serverURI
+	cfg.NodeID = nodeID
+
+	return as.reader.HistoryRead(ctx, cfg, from, to)
+}
+
 func (as *adapterService) DisconnectThing(ctx context.Context, chanID, thingID string) error {
 	c := fmt.Sprintf("%s:%s", chanID, thingID)
 	return as.connectRM.Remove(ctx, c)
 }
+
+func (as *adapterService) Call(ctx context.Context, key, chanID, methodID string, args []interface{}) ([]interface{}, error) {
+	ar := &mainflux.AccessByKeyReq{
+		Token:  key,
+		ChanID: chanID,
+	}
+	thid, err := as.things.CanAccessByKey(ctx, ar)
+	if err != nil {
+		return nil, err
+	}
+	thingID := thid.GetValue()
+
+	serverURI, err := as.channelsRM.Get(ctx, chanID)
+	if err != nil {
+		return nil, ErrNotFoundServerURI
+	}
+
+	nodeID, err := as.thingsRM.Get(ctx, thingID)
+	if err != nil {
+		return nil, ErrNotFoundNodeID
+	}
+
+	c := fmt.Sprintf("%s:%s", chanID, thingID)
+	if _, err := as.connectRM.Get(ctx, c); err != nil {
+		return nil, ErrNotConnected
+	}
+
+	return as.caller.Call(serverURI, nodeID, methodID, args)
+}
+
+func (as *adapterService) Command(ctx context.Context, msg messaging.Message) error {
+	// Skip the messages forwarded from the OPC-UA Server
+	if msg.Protocol == protocol {
+		return nil
+	}
+
+	thingID := strings.TrimPrefix(msg.Subtopic, CommandsSubtopic+".")
+	if thingID == msg.Subtopic || thingID == "" || strings.Contains(thingID, ".") {
+		return ErrMalformedEntity
+	}
+
+	serverURI, err := as.channelsRM.Get(ctx, msg.Channel)
+	if err != nil {
+		return ErrNotFoundServerURI
+	}
+
+	nodeID, err := as.thingsRM.Get(ctx, thingID)
+	if err != nil {
+		return ErrNotFoundNodeID
+	}
+
+	c := fmt.Sprintf("%s:%s", msg.Channel, thingID)
+	if _, err := as.connectRM.Get(ctx, c); err != nil {
+		return ErrNotConnected
+	}
+
+	var cmd WriteCommand
+	if err := json.Unmarshal(msg.Payload, &cmd); err != nil || cmd.Value == nil {
+		return ErrMalformedEntity
+	}
+
+	werr := as.writer.Write(serverURI, nodeID, cmd.Value)
+	if err := as.publishResult(msg.Channel, thingID, werr); err != nil {
+		return err
+	}
+
+	return werr
+}
+
+// publishResult publishes the result of the write as the thing mapped to
+// the written node, the same way its value changes are published.
+func (as *adapterService) publishResult(chanID, thingID string, werr error) error {
+	now := time.Now()
+	status := "OK"
+	if werr != nil {
+		status = werr.Error()
+	}
+
+	ok := werr == nil
+	p := senml.Pack{
+		Records: []senml.Record{
+			{BaseTime: float64(now.UnixNano()) / 1e9, Name: "write", BoolValue: &ok},
+			{Name: "status", StringValue: &status},
+		},
+	}
+	payload, err := senml.Encode(p, senml.JSON)
+	if err != nil {
+		return err
+	}
+
+	msg := messaging.Message{
+		Publisher:   thingID,
+		Protocol:    protocol,
+		Channel:     chanID,
+		Subtopic:    WriteSubtopic,
+		Payload:     payload,
+		Created:     now.UnixNano(),
+		ContentType: mfsenml.JSON,
+	}
+
+	return as.publisher.Publish(msg.Channel, msg)
+}
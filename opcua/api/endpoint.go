@@ -18,7 +18,7 @@ func browseEndpoint(svc opcua.Service) endpoint.Endpoint {
 			return nil, err
 		}
 
-		nodes, err := svc.Browse(ctx, req.ServerURI, req.Namespace, req.Identifier)
+		nodes, err := svc.Browse(ctx, req.ServerURI, req.Namespace, req.Identifier, req.Depth)
 		if err != nil {
 			return nil, err
 		}
@@ -30,3 +30,55 @@ func browseEndpoint(svc opcua.Service) endpoint.Endpoint {
 		return res, nil
 	}
 }
+
+func historyReadEndpoint(svc opcua.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(historyReadReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		cnt, err := svc.HistoryRead(ctx, req.ServerURI, req.Namespace, req.Identifier, req.From, req.To)
+		if err != nil {
+			return nil, err
+		}
+
+		res := historyReadRes{
+			Values: cnt,
+		}
+
+		return res, nil
+	}
+}
+
+func callEndpoint(svc opcua.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(callReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		outputs, err := svc.Call(ctx, req.key, req.chanID, req.MethodID, req.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		res := callRes{
+			Outputs: outputs,
+		}
+
+		return res, nil
+	}
+}
+
+func serversEndpoint(pool opcua.Pool) endpoint.Endpoint {
+	return func(_ context.Context, _ interface{}) (interface{}, error) {
+		res := serversRes{
+			Servers: pool.Status(),
+		}
+
+		return res, nil
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/opcua"
+	"github.com/mainflux/mainflux/pkg/messaging"
 )
 
 var _ opcua.Service = (*loggingMiddleware)(nil)
@@ -27,7 +28,7 @@ func LoggingMiddleware(svc opcua.Service, logger logger.Logger) opcua.Service {
 	}
 }
 
-func (lm loggingMiddleware) CreateThing(ctx context.Context, mfxThing, opcuaNodeID string) (err error) {
+func (lm loggingMiddleware) CreateThing(ctx context.Context, mfxThing, opcuaNodeID string, params opcua.MonitoringParams) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("create_thing %s with NodeID %s, took %s to complete", mfxThing, opcuaNodeID, time.Since(begin))
 		if err != nil {
@@ -37,10 +38,10 @@ func (lm loggingMiddleware) CreateThing(ctx context.Context, mfxThing, opcuaNode
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.CreateThing(ctx, mfxThing, opcuaNodeID)
+	return lm.svc.CreateThing(ctx, mfxThing, opcuaNodeID, params)
 }
 
-func (lm loggingMiddleware) UpdateThing(ctx context.Context, mfxThing, opcuaNodeID string) (err error) {
+func (lm loggingMiddleware) UpdateThing(ctx context.Context, mfxThing, opcuaNodeID string, params opcua.MonitoringParams) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("update_thing %s with NodeID %s, took %s to complete", mfxThing, opcuaNodeID, time.Since(begin))
 		if err != nil {
@@ -50,7 +51,7 @@ func (lm loggingMiddleware) UpdateThing(ctx context.Context, mfxThing, opcuaNode
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.UpdateThing(ctx, mfxThing, opcuaNodeID)
+	return lm.svc.UpdateThing(ctx, mfxThing, opcuaNodeID, params)
 }
 
 func (lm loggingMiddleware) RemoveThing(ctx context.Context, mfxThing string) (err error) {
@@ -131,9 +132,9 @@ func (lm loggingMiddleware) DisconnectThing(ctx context.Context, mfxChanID, mfxT
 	return lm.svc.DisconnectThing(ctx, mfxChanID, mfxThingID)
 }
 
-func (lm loggingMiddleware) Browse(ctx context.Context, serverURI, namespace, identifier string) (nodes []opcua.BrowsedNode, err error) {
+func (lm loggingMiddleware) Browse(ctx context.Context, serverURI, namespace, identifier string, depth uint64) (nodes []opcua.BrowsedNode, err error) {
 	defer func(begin time.Time) {
-		message := fmt.Sprintf("browse server URI %s and node %s;%s, took %s to complete", serverURI, namespace, identifier, time.Since(begin))
+		message := fmt.Sprintf("browse server URI %s and node %s;%s to depth %d, took %s to complete", serverURI, namespace, identifier, depth, time.Since(begin))
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -141,5 +142,44 @@ func (lm loggingMiddleware) Browse(ctx context.Context, serverURI, namespace, id
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.Browse(ctx, serverURI, namespace, identifier)
+	return lm.svc.Browse(ctx, serverURI, namespace, identifier, depth)
+}
+
+func (lm loggingMiddleware) HistoryRead(ctx context.Context, serverURI, namespace, identifier string, from, to time.Time) (cnt int, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("history read of server URI %s and node %s;%s from %s to %s, took %s to complete", serverURI, namespace, identifier, from, to, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.HistoryRead(ctx, serverURI, namespace, identifier, from, to)
+}
+
+func (lm loggingMiddleware) Call(ctx context.Context, key, chanID, methodID string, args []interface{}) (outputs []interface{}, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("call of method %s on channel %s, took %s to complete", methodID, chanID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Call(ctx, key, chanID, methodID, args)
+}
+
+func (lm loggingMiddleware) Command(ctx context.Context, msg messaging.Message) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("command on channel %s and subtopic %s, took %s to complete", msg.Channel, msg.Subtopic, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Command(ctx, msg)
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-kit/kit/metrics"
 	"github.com/mainflux/mainflux/opcua"
+	"github.com/mainflux/mainflux/pkg/messaging"
 )
 
 var _ opcua.Service = (*metricsMiddleware)(nil)
@@ -28,22 +29,22 @@ func MetricsMiddleware(svc opcua.Service, counter metrics.Counter, latency metri
 	}
 }
 
-func (mm *metricsMiddleware) CreateThing(ctx context.Context, mfxDevID, opcuaNodeID string) error {
+func (mm *metricsMiddleware) CreateThing(ctx context.Context, mfxDevID, opcuaNodeID string, params opcua.MonitoringParams) error {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "create_thing").Add(1)
 		mm.latency.With("method", "create_thing").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return mm.svc.CreateThing(ctx, mfxDevID, opcuaNodeID)
+	return mm.svc.CreateThing(ctx, mfxDevID, opcuaNodeID, params)
 }
 
-func (mm *metricsMiddleware) UpdateThing(ctx context.Context, mfxDevID, opcuaNodeID string) error {
+func (mm *metricsMiddleware) UpdateThing(ctx context.Context, mfxDevID, opcuaNodeID string, params opcua.MonitoringParams) error {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "update_thing").Add(1)
 		mm.latency.With("method", "update_thing").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return mm.svc.UpdateThing(ctx, mfxDevID, opcuaNodeID)
+	return mm.svc.UpdateThing(ctx, mfxDevID, opcuaNodeID, params)
 }
 
 func (mm *metricsMiddleware) RemoveThing(ctx context.Context, mfxDevID string) error {
@@ -100,11 +101,38 @@ func (mm *metricsMiddleware) DisconnectThing(ctx context.Context, mfxChanID, mfx
 	return mm.svc.DisconnectThing(ctx, mfxChanID, mfxThingID)
 }
 
-func (mm *metricsMiddleware) Browse(ctx context.Context, serverURI, namespace, identifier string) ([]opcua.BrowsedNode, error) {
+func (mm *metricsMiddleware) Browse(ctx context.Context, serverURI, namespace, identifier string, depth uint64) ([]opcua.BrowsedNode, error) {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "browse").Add(1)
 		mm.latency.With("method", "browse").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return mm.svc.Browse(ctx, serverURI, namespace, identifier)
+	return mm.svc.Browse(ctx, serverURI, namespace, identifier, depth)
+}
+
+func (mm *metricsMiddleware) HistoryRead(ctx context.Context, serverURI, namespace, identifier string, from, to time.Time) (int, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "history_read").Add(1)
+		mm.latency.With("method", "history_read").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.HistoryRead(ctx, serverURI, namespace, identifier, from, to)
+}
+
+func (mm *metricsMiddleware) Call(ctx context.Context, key, chanID, methodID string, args []interface{}) ([]interface{}, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "call").Add(1)
+		mm.latency.With("method", "call").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Call(ctx, key, chanID, methodID, args)
+}
+
+func (mm *metricsMiddleware) Command(ctx context.Context, msg messaging.Message) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "command").Add(1)
+		mm.latency.With("method", "command").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Command(ctx, msg)
 }
@@ -3,12 +3,19 @@
 
 package api
 
-import "github.com/mainflux/mainflux/opcua"
+import (
+	"time"
+
+	"github.com/mainflux/mainflux/opcua"
+)
+
+const maxDepth = 10
 
 type browseReq struct {
 	ServerURI  string
 	Namespace  string
 	Identifier string
+	Depth      uint64
 }
 
 func (req *browseReq) validate() error {
@@ -16,5 +23,48 @@ func (req *browseReq) validate() error {
 		return opcua.ErrMalformedEntity
 	}
 
+	if req.Depth > maxDepth {
+		return opcua.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type historyReadReq struct {
+	ServerURI  string
+	Namespace  string
+	Identifier string
+	From       time.Time
+	To         time.Time
+}
+
+func (req *historyReadReq) validate() error {
+	if req.ServerURI == "" || req.Namespace == "" || req.Identifier == "" {
+		return opcua.ErrMalformedEntity
+	}
+
+	if req.From.IsZero() || !req.From.Before(req.To) {
+		return opcua.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type callReq struct {
+	key      string
+	chanID   string
+	MethodID string        `json:"method_id"`
+	Args     []interface{} `json:"args"`
+}
+
+func (req *callReq) validate() error {
+	if req.key == "" {
+		return opcua.ErrUnauthorizedAccess
+	}
+
+	if req.chanID == "" || req.MethodID == "" {
+		return opcua.ErrMalformedEntity
+	}
+
 	return nil
 }
@@ -10,7 +10,12 @@ import (
 	"github.com/mainflux/mainflux/opcua"
 )
 
-var _ mainflux.Response = (*browseRes)(nil)
+var (
+	_ mainflux.Response = (*browseRes)(nil)
+	_ mainflux.Response = (*historyReadRes)(nil)
+	_ mainflux.Response = (*serversRes)(nil)
+	_ mainflux.Response = (*callRes)(nil)
+)
 
 type browseRes struct {
 	Nodes []opcua.BrowsedNode `json:"nodes"`
@@ -27,3 +32,51 @@ func (res browseRes) Headers() map[string]string {
 func (res browseRes) Empty() bool {
 	return false
 }
+
+type historyReadRes struct {
+	Values int `json:"values"`
+}
+
+func (res historyReadRes) Code() int {
+	return http.StatusOK
+}
+
+func (res historyReadRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res historyReadRes) Empty() bool {
+	return false
+}
+
+type serversRes struct {
+	Servers []opcua.ServerStatus `json:"servers"`
+}
+
+func (res serversRes) Code() int {
+	return http.StatusOK
+}
+
+func (res serversRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res serversRes) Empty() bool {
+	return false
+}
+
+type callRes struct {
+	Outputs []interface{} `json:"outputs"`
+}
+
+func (res callRes) Code() int {
+	return http.StatusOK
+}
+
+func (res callRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res callRes) Empty() bool {
+	return false
+}
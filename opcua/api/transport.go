@@ -6,7 +6,10 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net/http"
+	"strings"
+	"time"
 
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/go-zoo/bone"
@@ -15,6 +18,8 @@ import (
 	"github.com/mainflux/mainflux/opcua"
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -22,14 +27,18 @@ const (
 	serverParam     = "server"
 	namespaceParam  = "namespace"
 	identifierParam = "identifier"
+	depthParam      = "depth"
+	fromParam       = "from"
+	toParam         = "to"
 	defOffset       = 0
 	defLimit        = 10
 	defNamespace    = "ns=0" // Standard root namespace
 	defIdentifier   = "i=84" // Standard root identifier
+	defDepth        = 4
 )
 
 // MakeHandler returns a HTTP handler for API endpoints.
-func MakeHandler(svc opcua.Service) http.Handler {
+func MakeHandler(svc opcua.Service, pool opcua.Pool) http.Handler {
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(encodeError),
 	}
@@ -43,6 +52,27 @@ func MakeHandler(svc opcua.Service) http.Handler {
 		opts...,
 	))
 
+	r.Post("/history", kithttp.NewServer(
+		historyReadEndpoint(svc),
+		decodeHistoryRead,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Post("/channels/:id/call", kithttp.NewServer(
+		callEndpoint(svc),
+		decodeCall,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Get("/servers", kithttp.NewServer(
+		serversEndpoint(pool),
+		kithttp.NopRequestDecoder,
+		encodeResponse,
+		opts...,
+	))
+
 	r.GetFunc("/version", mainflux.Version("opcua-adapter"))
 	r.Handle("/metrics", promhttp.Handler())
 
@@ -65,6 +95,11 @@ func decodeBrowse(_ context.Context, r *http.Request) (interface{}, error) {
 		return nil, err
 	}
 
+	d, err := httputil.ReadUintQuery(r, depthParam, defDepth)
+	if err != nil {
+		return nil, err
+	}
+
 	if n == "" || i == "" {
 		n = defNamespace
 		i = defIdentifier
@@ -74,11 +109,76 @@ func decodeBrowse(_ context.Context, r *http.Request) (interface{}, error) {
 		ServerURI:  s,
 		Namespace:  n,
 		Identifier: i,
+		Depth:      d,
+	}
+
+	return req, nil
+}
+
+func decodeHistoryRead(_ context.Context, r *http.Request) (interface{}, error) {
+	s, err := httputil.ReadStringQuery(r, serverParam, "")
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := httputil.ReadStringQuery(r, namespaceParam, "")
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := httputil.ReadStringQuery(r, identifierParam, "")
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := httputil.ReadFloatQuery(r, fromParam, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := httputil.ReadFloatQuery(r, toParam, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	req := historyReadReq{
+		ServerURI:  s,
+		Namespace:  n,
+		Identifier: i,
+		To:         time.Now(),
+	}
+	if from > 0 {
+		req.From = toTime(from)
+	}
+	if to > 0 {
+		req.To = toTime(to)
 	}
 
 	return req, nil
 }
 
+func decodeCall(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := callReq{
+		key:    r.Header.Get("Authorization"),
+		chanID: bone.GetValue(r, "id"),
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, opcua.ErrMalformedEntity
+	}
+
+	return req, nil
+}
+
+// toTime converts the Unix time in seconds to time.
+func toTime(sec float64) time.Time {
+	s, frac := math.Modf(sec)
+	return time.Unix(int64(s), int64(frac*1e9))
+}
+
 func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
 	w.Header().Set("Content-Type", contentType)
 
@@ -105,7 +205,23 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 		w.WriteHeader(http.StatusBadRequest)
 	case errors.ErrInvalidQueryParams:
 		w.WriteHeader(http.StatusBadRequest)
+	case errors.ErrUnsupportedContentType:
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	case opcua.ErrUnauthorizedAccess:
+		w.WriteHeader(http.StatusUnauthorized)
+	case opcua.ErrNotFoundServerURI, opcua.ErrNotFoundNodeID, opcua.ErrNotConnected:
+		w.WriteHeader(http.StatusNotFound)
 	default:
+		// The errors of the things service authorizing the method calls.
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.PermissionDenied:
+				w.WriteHeader(http.StatusForbidden)
+			default:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
@@ -11,10 +11,13 @@ type BrowsedNode struct {
 	Unit        string
 	Scale       string
 	BrowseName  string
+	Path        string
+	Writable    bool
 }
 
 // Browser represents the OPC-UA Server Nodes browser.
 type Browser interface {
-	// Browse availlable Nodes for a given URI.
-	Browse(string, string) ([]BrowsedNode, error)
+	// Browse availlable Nodes for a given URI, down to the given depth
+	// below the Node.
+	Browse(serverURI, nodeID string, depth uint64) ([]BrowsedNode, error)
 }
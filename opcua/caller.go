@@ -0,0 +1,12 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+// Caller represents the OPC-UA Server Methods caller.
+type Caller interface {
+	// Call calls the Method of the Object of the OPC-UA Server with the
+	// input arguments, converted to the Method argument data types, and
+	// returns the output arguments.
+	Call(serverURI, objectID, methodID string, args []interface{}) ([]interface{}, error)
+}
@@ -26,16 +26,19 @@ var (
 type Node struct {
 	ServerURI string
 	NodeID    string
+	// Monitoring holds the JSON encoded monitored item parameters, empty
+	// for the subscriptions stored without them.
+	Monitoring string
 }
 
 // Save stores a successfull subscription
-func Save(serverURI, nodeID string) error {
+func Save(serverURI, nodeID, monitoring string) error {
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModePerm)
 	if err != nil {
 		errors.Wrap(errWriteFile, err)
 	}
 	csvWriter := csv.NewWriter(file)
-	csvWriter.Write([]string{serverURI, nodeID})
+	csvWriter.Write([]string{serverURI, nodeID, monitoring})
 	csvWriter.Flush()
 
 	return nil
@@ -54,6 +57,8 @@ func ReadAll() ([]Node, error) {
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	// The subscriptions stored before the monitoring parameters have fewer fields.
+	reader.FieldsPerRecord = -1
 	nodes := []Node{}
 	for {
 		l, err := reader.Read()
@@ -68,7 +73,11 @@ func ReadAll() ([]Node, error) {
 			return nil, errEmptyLine
 		}
 
-		nodes = append(nodes, Node{l[0], l[1]})
+		n := Node{ServerURI: l[0], NodeID: l[1]}
+		if len(l) > columns {
+			n.Monitoring = l[columns]
+		}
+		nodes = append(nodes, n)
 	}
 
 	return nodes, nil
@@ -4,8 +4,6 @@
 package gopcua
 
 import (
-	"context"
-
 	opcuaGopcua "github.com/gopcua/opcua"
 	"github.com/gopcua/opcua/id"
 	uaGopcua "github.com/gopcua/opcua/ua"
@@ -14,8 +12,6 @@ import (
 	"github.com/mainflux/mainflux/pkg/errors"
 )
 
-const maxChildrens = 4 // max browsing node children level
-
 // NodeDef represents the node browser responnse
 type NodeDef struct {
 	NodeID      *uaGopcua.NodeID
@@ -35,31 +31,41 @@ type NodeDef struct {
 var _ opcua.Browser = (*browser)(nil)
 
 type browser struct {
-	ctx    context.Context
+	pool   *Pool
 	logger logger.Logger
 }
 
-// NewBrowser returns new OPC-UA browser instance.
-func NewBrowser(ctx context.Context, log logger.Logger) opcua.Browser {
+// NewBrowser returns new OPC-UA browser instance, browsing using the pooled
+// connections to the servers.
+func NewBrowser(pool *Pool, log logger.Logger) opcua.Browser {
 	return browser{
-		ctx:    ctx,
+		pool:   pool,
 		logger: log,
 	}
 }
 
-func (c browser) Browse(serverURI, nodeID string) ([]opcua.BrowsedNode, error) {
-	opts := []opcuaGopcua.Option{
-		opcuaGopcua.SecurityMode(uaGopcua.MessageSecurityModeNone),
+func (c browser) Browse(serverURI, nodeID string, depth uint64) ([]opcua.BrowsedNode, error) {
+	// The node ID is parsed first, so that only the requests failing
+	// afterwards may close the connection.
+	if _, err := uaGopcua.ParseNodeID(nodeID); err != nil {
+		return nil, errors.Wrap(errFailedParseNodeID, err)
 	}
 
-	oc := opcuaGopcua.NewClient(serverURI, opts...)
-	if err := oc.Connect(c.ctx); err != nil {
-		return nil, errors.Wrap(errFailedConn, err)
+	oc, err := c.pool.Connect(serverURI)
+	if err != nil {
+		return nil, err
 	}
-	defer oc.Close()
 
-	nodeList, err := browse(oc, nodeID, "", 0)
+	b := walker{
+		client:  oc,
+		depth:   int(depth),
+		visited: map[string]bool{},
+	}
+	nodeList, err := b.browse(nodeID, "", 0)
 	if err != nil {
+		if connError(err) {
+			c.pool.Fail(serverURI, oc, err)
+		}
 		return nil, err
 	}
 
@@ -72,6 +78,8 @@ func (c browser) Browse(serverURI, nodeID string) ([]opcua.BrowsedNode, error) {
 			Unit:        s.Unit,
 			Scale:       s.Scale,
 			BrowseName:  s.BrowseName,
+			Path:        s.Path,
+			Writable:    s.Writable,
 		}
 		nodes = append(nodes, node)
 	}
@@ -79,16 +87,25 @@ func (c browser) Browse(serverURI, nodeID string) ([]opcua.BrowsedNode, error) {
 	return nodes, nil
 }
 
-func browse(oc *opcuaGopcua.Client, nodeID, path string, level int) ([]NodeDef, error) {
-	if level > maxChildrens {
-		return nil, nil
-	}
+// walker walks the address space of the OPC-UA Server down to the depth,
+// browsing each node once, since the nodes may be referenced from more than
+// one parent.
+type walker struct {
+	client  *opcuaGopcua.Client
+	depth   int
+	visited map[string]bool
+}
 
+func (w walker) browse(nodeID, path string, level int) ([]NodeDef, error) {
 	nid, err := uaGopcua.ParseNodeID(nodeID)
 	if err != nil {
 		return []NodeDef{}, err
 	}
-	n := oc.Node(nid)
+	if w.visited[nid.String()] {
+		return nil, nil
+	}
+	w.visited[nid.String()] = true
+	n := w.client.Node(nid)
 
 	attrs, err := n.Attributes(
 		uaGopcua.AttributeIDNodeClass,
@@ -181,19 +198,23 @@ func browse(oc *opcuaGopcua.Client, nodeID, path string, level int) ([]NodeDef,
 		nodes = append(nodes, def)
 	}
 
-	bc, err := browseChildren(oc, n, def.Path, level, id.HasComponent)
+	if level == w.depth {
+		return nodes, nil
+	}
+
+	bc, err := w.browseChildren(n, def.Path, level, id.HasComponent)
 	if err != nil {
 		return nil, err
 	}
 	nodes = append(nodes, bc...)
 
-	bc, err = browseChildren(oc, n, def.Path, level, id.Organizes)
+	bc, err = w.browseChildren(n, def.Path, level, id.Organizes)
 	if err != nil {
 		return nil, err
 	}
 	nodes = append(nodes, bc...)
 
-	bc, err = browseChildren(oc, n, def.Path, level, id.HasProperty)
+	bc, err = w.browseChildren(n, def.Path, level, id.HasProperty)
 	if err != nil {
 		return nil, err
 	}
@@ -202,7 +223,7 @@ func browse(oc *opcuaGopcua.Client, nodeID, path string, level int) ([]NodeDef,
 	return nodes, nil
 }
 
-func browseChildren(c *opcuaGopcua.Client, n *opcuaGopcua.Node, path string, level int, typeDef uint32) ([]NodeDef, error) {
+func (w walker) browseChildren(n *opcuaGopcua.Node, path string, level int, typeDef uint32) ([]NodeDef, error) {
 	nodes := []NodeDef{}
 	refs, err := n.ReferencedNodes(typeDef, uaGopcua.BrowseDirectionForward, uaGopcua.NodeClassAll, true)
 	if err != nil {
@@ -210,7 +231,7 @@ func browseChildren(c *opcuaGopcua.Client, n *opcuaGopcua.Node, path string, lev
 	}
 
 	for _, ref := range refs {
-		children, err := browse(c, ref.ID.String(), path, level+1)
+		children, err := w.browse(ref.ID.String(), path, level+1)
 		if err != nil {
 			return []NodeDef{}, err
 		}
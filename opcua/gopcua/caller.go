@@ -0,0 +1,156 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package gopcua
+
+import (
+	"fmt"
+
+	opcuaGopcua "github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
+	uaGopcua "github.com/gopcua/opcua/ua"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/opcua"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const inputArguments = "InputArguments"
+
+var (
+	errFailedCall   = errors.New("failed to call method")
+	errInvalidArgs  = errors.New("invalid number of method arguments")
+	errArrayArgType = errors.New("array method arguments are not supported")
+)
+
+var _ opcua.Caller = (*caller)(nil)
+
+func init() {
+	// The method arguments are described by the Argument extension objects,
+	// which the client does not decode by default.
+	uaGopcua.RegisterExtensionObject(uaGopcua.NewNumericNodeID(0, id.Argument_Encoding_DefaultBinary), new(uaGopcua.Argument))
+}
+
+type caller struct {
+	pool   *Pool
+	logger logger.Logger
+}
+
+// NewCaller returns new OPC-UA method caller instance, calling using the
+// pooled connections to the servers.
+func NewCaller(pool *Pool, log logger.Logger) opcua.Caller {
+	return caller{
+		pool:   pool,
+		logger: log,
+	}
+}
+
+func (c caller) Call(serverURI, objectID, methodID string, args []interface{}) ([]interface{}, error) {
+	oid, err := uaGopcua.ParseNodeID(objectID)
+	if err != nil {
+		return nil, errors.Wrap(errFailedParseNodeID, err)
+	}
+
+	mid, err := uaGopcua.ParseNodeID(methodID)
+	if err != nil {
+		return nil, errors.Wrap(errFailedParseNodeID, err)
+	}
+
+	oc, err := c.pool.Connect(serverURI)
+	if err != nil {
+		return nil, err
+	}
+
+	// The arguments are converted to the method argument data types, the
+	// same way as the written values.
+	inputs, err := arguments(oc, mid)
+	if err != nil {
+		if connError(err) {
+			c.pool.Fail(serverURI, oc, err)
+		}
+		return nil, errors.Wrap(errFailedRead, err)
+	}
+	if len(inputs) != len(args) {
+		return nil, errInvalidArgs
+	}
+
+	variants := make([]*uaGopcua.Variant, len(args))
+	for i, input := range inputs {
+		if input.ValueRank != -1 {
+			return nil, errArrayArgType
+		}
+
+		v, err := coerce(args[i], input.DataType)
+		if err != nil {
+			return nil, err
+		}
+
+		variants[i], err = uaGopcua.NewVariant(v)
+		if err != nil {
+			return nil, errors.Wrap(errInvalidValue, err)
+		}
+	}
+
+	req := &uaGopcua.CallMethodRequest{
+		ObjectID:       oid,
+		MethodID:       mid,
+		InputArguments: variants,
+	}
+
+	res, err := oc.Call(req)
+	if err != nil {
+		if connError(err) {
+			c.pool.Fail(serverURI, oc, err)
+		}
+		return nil, errors.Wrap(errFailedCall, err)
+	}
+	if res.StatusCode != uaGopcua.StatusOK {
+		return nil, errors.Wrap(errFailedCall, res.StatusCode)
+	}
+
+	outputs := make([]interface{}, len(res.OutputArguments))
+	for i, v := range res.OutputArguments {
+		outputs[i] = v.Value()
+	}
+
+	c.logger.Info(fmt.Sprintf("call to server %s and method_id %s of node_id %s", serverURI, methodID, objectID))
+	return outputs, nil
+}
+
+// arguments returns the input arguments of the method, read from its
+// InputArguments property. The methods without the property take no
+// arguments.
+func arguments(oc *opcuaGopcua.Client, mid *uaGopcua.NodeID) ([]*uaGopcua.Argument, error) {
+	refs, err := oc.Node(mid).References(id.HasProperty, uaGopcua.BrowseDirectionForward, uaGopcua.NodeClassVariable, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range refs {
+		if ref.BrowseName == nil || ref.BrowseName.Name != inputArguments {
+			continue
+		}
+
+		v, err := oc.Node(ref.NodeID.NodeID).Value()
+		if err != nil {
+			return nil, err
+		}
+
+		eos, ok := v.Value().([]*uaGopcua.ExtensionObject)
+		if !ok {
+			return nil, errUnsupportedType
+		}
+
+		args := make([]*uaGopcua.Argument, len(eos))
+		for i, eo := range eos {
+			arg, ok := eo.Value.(*uaGopcua.Argument)
+			if !ok {
+				return nil, errUnsupportedType
+			}
+			args[i] = arg
+		}
+
+		return args, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package gopcua
+
+import (
+	"context"
+	"time"
+
+	uaGopcua "github.com/gopcua/opcua/ua"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/opcua"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+const (
+	// historyBatch is the number of values read per HistoryRead call, the
+	// rest being read using the continuation points.
+	historyBatch = 1000
+
+	// statusBad is the severity bit of the bad status codes.
+	statusBad = 0x80000000
+)
+
+var errFailedHistoryRead = errors.New("failed to read history")
+
+var _ opcua.HistoryReader = (*client)(nil)
+
+// NewHistoryReader returns new OPC-UA history reader instance, publishing the
+// historical values the same way the subscriber publishes the value changes.
+func NewHistoryReader(ctx context.Context, pool *Pool, publisher messaging.Publisher, thingsRM, channelsRM, connectRM opcua.RouteMapRepository, log logger.Logger) opcua.HistoryReader {
+	return client{
+		ctx:        ctx,
+		pool:       pool,
+		publisher:  publisher,
+		thingsRM:   thingsRM,
+		channelsRM: channelsRM,
+		connectRM:  connectRM,
+		logger:     log,
+	}
+}
+
+func (c client) HistoryRead(ctx context.Context, cfg opcua.Config, from, to time.Time) (int, error) {
+	nid, err := uaGopcua.ParseNodeID(cfg.NodeID)
+	if err != nil {
+		return 0, errors.Wrap(errFailedParseNodeID, err)
+	}
+
+	oc, err := c.pool.Connect(cfg.ServerURI)
+	if err != nil {
+		return 0, err
+	}
+
+	details := &uaGopcua.ReadRawModifiedDetails{
+		StartTime:        from,
+		EndTime:          to,
+		NumValuesPerNode: historyBatch,
+	}
+	nodes := []*uaGopcua.HistoryReadValueID{
+		{
+			NodeID:       nid,
+			DataEncoding: &uaGopcua.QualifiedName{},
+		},
+	}
+
+	cnt := 0
+	for {
+		res, err := oc.HistoryReadRawModified(nodes, details)
+		if err != nil {
+			if connError(err) {
+				c.pool.Fail(cfg.ServerURI, oc, err)
+			}
+			return cnt, errors.Wrap(errFailedHistoryRead, err)
+		}
+		if len(res.Results) == 0 {
+			return cnt, errFailedHistoryRead
+		}
+
+		r := res.Results[0]
+		// Good status codes other than OK, such as GoodNoData or
+		// GoodMoreData, are read as usual.
+		if r.StatusCode&statusBad != 0 {
+			return cnt, errors.Wrap(errFailedHistoryRead, r.StatusCode)
+		}
+
+		if r.HistoryData != nil {
+			if data, ok := r.HistoryData.Value.(*uaGopcua.HistoryData); ok {
+				for _, dv := range data.DataValues {
+					if dv.Value == nil {
+						continue
+					}
+					if err := c.publish(ctx, token, newMessage(cfg.ServerURI, cfg.NodeID, dv)); err != nil {
+						return cnt, err
+					}
+					cnt++
+				}
+			}
+		}
+
+		if len(r.ContinuationPoint) == 0 {
+			return cnt, nil
+		}
+		nodes[0].ContinuationPoint = r.ContinuationPoint
+	}
+}
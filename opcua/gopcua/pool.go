@@ -0,0 +1,180 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package gopcua
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	opcuaGopcua "github.com/gopcua/opcua"
+	uaGopcua "github.com/gopcua/opcua/ua"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/opcua"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var _ opcua.Pool = (*Pool)(nil)
+
+// Pool maintains a single connection per OPC-UA Server, shared by the
+// subscriptions, the browser, the writer and the history reader. The failed
+// connections are closed and established again on the next use.
+type Pool struct {
+	ctx     context.Context
+	cfg     opcua.Config
+	mu      sync.Mutex
+	servers map[string]*server
+	logger  logger.Logger
+}
+
+type server struct {
+	mu     sync.Mutex
+	client *opcuaGopcua.Client
+	status opcua.ServerStatus
+}
+
+// NewPool returns new OPC-UA connection pool, connecting to the servers
+// using the security mode and policy of the configuration.
+func NewPool(ctx context.Context, cfg opcua.Config, log logger.Logger) *Pool {
+	return &Pool{
+		ctx:     ctx,
+		cfg:     cfg,
+		servers: map[string]*server{},
+		logger:  log,
+	}
+}
+
+// Connect returns the connection to the server, connecting to it first if
+// there is none.
+func (p *Pool) Connect(serverURI string) (*opcuaGopcua.Client, error) {
+	s := p.server(serverURI)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	oc, err := p.connect(serverURI)
+	if err != nil {
+		s.status.Connected = false
+		s.status.Retries++
+		s.status.LastError = err.Error()
+		return nil, err
+	}
+
+	s.client = oc
+	s.status = opcua.ServerStatus{
+		ServerURI: serverURI,
+		Connected: true,
+		Since:     time.Now(),
+	}
+	p.logger.Info(fmt.Sprintf("Connected to OPC-UA server %s", serverURI))
+
+	return oc, nil
+}
+
+// Fail closes the failed connection to the server, unless it was already
+// replaced, so that the next Connect connects to the server again.
+func (p *Pool) Fail(serverURI string, oc *opcuaGopcua.Client, err error) {
+	s := p.server(serverURI)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == nil || s.client != oc {
+		return
+	}
+
+	oc.Close()
+	s.client = nil
+	s.status = opcua.ServerStatus{
+		ServerURI: serverURI,
+		Connected: false,
+		Since:     time.Now(),
+		LastError: err.Error(),
+	}
+	p.logger.Warn(fmt.Sprintf("Lost connection to OPC-UA server %s: %s", serverURI, err))
+}
+
+// Status returns the health of the connections to the servers.
+func (p *Pool) Status() []opcua.ServerStatus {
+	p.mu.Lock()
+	servers := make([]*server, 0, len(p.servers))
+	for _, s := range p.servers {
+		servers = append(servers, s)
+	}
+	p.mu.Unlock()
+
+	statuses := make([]opcua.ServerStatus, 0, len(servers))
+	for _, s := range servers {
+		s.mu.Lock()
+		statuses = append(statuses, s.status)
+		s.mu.Unlock()
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ServerURI < statuses[j].ServerURI
+	})
+
+	return statuses
+}
+
+// Close closes the connections to all the servers.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range p.servers {
+		s.mu.Lock()
+		if s.client != nil {
+			s.client.Close()
+			s.client = nil
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (p *Pool) server(serverURI string) *server {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.servers[serverURI]
+	if !ok {
+		s = &server{
+			status: opcua.ServerStatus{
+				ServerURI: serverURI,
+				Since:     time.Now(),
+			},
+		}
+		p.servers[serverURI] = s
+	}
+
+	return s
+}
+
+func (p *Pool) connect(serverURI string) (*opcuaGopcua.Client, error) {
+	cfg := p.cfg
+	cfg.ServerURI = serverURI
+	opts, err := options(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	oc := opcuaGopcua.NewClient(serverURI, opts...)
+	if err := oc.Connect(p.ctx); err != nil {
+		return nil, errors.Wrap(errFailedConn, err)
+	}
+
+	return oc, nil
+}
+
+// connError returns true if the request failed because of the connection,
+// rather than returning an OPC-UA status, in which case the connection is
+// still usable.
+func connError(err error) bool {
+	_, ok := err.(uaGopcua.StatusCode)
+	return err != nil && !ok
+}
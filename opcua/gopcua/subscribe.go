@@ -10,6 +10,7 @@ import (
 	"time"
 
 	opcuaGopcua "github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
 	uaGopcua "github.com/gopcua/opcua/ua"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/opcua"
@@ -34,12 +35,19 @@ var (
 	errFailedParseNodeID   = errors.New("failed to parse NodeID")
 	errFailedCreateReq     = errors.New("failed to create request")
 	errResponseStatus      = errors.New("response status not OK")
+	errConnLost            = errors.New("connection lost")
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = 5 * time.Minute
 )
 
 var _ opcua.Subscriber = (*client)(nil)
 
 type client struct {
 	ctx        context.Context
+	pool       *Pool
 	publisher  messaging.Publisher
 	thingsRM   opcua.RouteMapRepository
 	channelsRM opcua.RouteMapRepository
@@ -57,9 +65,10 @@ type message struct {
 }
 
 // NewSubscriber returns new OPC-UA client instance.
-func NewSubscriber(ctx context.Context, publisher messaging.Publisher, thingsRM, channelsRM, connectRM opcua.RouteMapRepository, log logger.Logger) opcua.Subscriber {
+func NewSubscriber(ctx context.Context, pool *Pool, publisher messaging.Publisher, thingsRM, channelsRM, connectRM opcua.RouteMapRepository, log logger.Logger) opcua.Subscriber {
 	return client{
 		ctx:        ctx,
+		pool:       pool,
 		publisher:  publisher,
 		thingsRM:   thingsRM,
 		channelsRM: channelsRM,
@@ -68,8 +77,83 @@ func NewSubscriber(ctx context.Context, publisher messaging.Publisher, thingsRM,
 	}
 }
 
-// Subscribe subscribes to the OPC-UA Server.
+// Subscribe subscribes to the OPC-UA Server, subscribing again with the
+// exponential backoff whenever the connection to the server is lost, until
+// the node is unmapped.
 func (c client) Subscribe(ctx context.Context, cfg opcua.Config) error {
+	i, err := strconv.Atoi(cfg.Interval)
+	if err != nil {
+		return errors.Wrap(errFailedParseInterval, err)
+	}
+	interval := time.Duration(i) * time.Millisecond
+
+	backoff := minBackoff
+	for {
+		subscribed, err := c.subscribe(ctx, cfg, interval)
+		if err == nil || !retriable(err) {
+			if err != nil {
+				c.logger.Warn(fmt.Sprintf("Unsubscribed from OPC-UA node %s.%s: %s", cfg.ServerURI, cfg.NodeID, err))
+			}
+			return nil
+		}
+
+		if subscribed {
+			backoff = minBackoff
+		}
+		c.logger.Warn(fmt.Sprintf("Subscription to OPC-UA node %s.%s failed, retrying in %s: %s", cfg.ServerURI, cfg.NodeID, backoff, err))
+
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// subscribe subscribes to the node using the pooled connection to the server,
+// returning whether the subscription was established before it failed.
+func (c client) subscribe(ctx context.Context, cfg opcua.Config, interval time.Duration) (bool, error) {
+	oc, err := c.pool.Connect(cfg.ServerURI)
+	if err != nil {
+		return false, err
+	}
+
+	sub, err := oc.Subscribe(&opcuaGopcua.SubscriptionParameters{
+		Interval: interval,
+	})
+	if err != nil {
+		if connError(err) {
+			c.pool.Fail(cfg.ServerURI, oc, err)
+		}
+		return false, errors.Wrap(errFailedSub, err)
+	}
+	defer sub.Cancel()
+
+	err = c.runHandler(ctx, sub, cfg.ServerURI, cfg.NodeID, cfg.Monitoring)
+	if errors.Contains(err, errConnLost) {
+		c.pool.Fail(cfg.ServerURI, oc, err)
+		return true, err
+	}
+
+	return false, err
+}
+
+// retriable returns true if subscribing may succeed once the connection to
+// the server is established again.
+func retriable(err error) bool {
+	return errors.Contains(err, errFailedConn) ||
+		errors.Contains(err, errFailedFetchEndpoint) ||
+		errors.Contains(err, errFailedSub) ||
+		errors.Contains(err, errConnLost)
+}
+
+// options returns the client options of the security mode and policy
+// configured for the OPC-UA Server.
+func options(cfg opcua.Config) ([]opcuaGopcua.Option, error) {
 	opts := []opcuaGopcua.Option{
 		opcuaGopcua.SecurityMode(uaGopcua.MessageSecurityModeNone),
 	}
@@ -77,12 +161,12 @@ func (c client) Subscribe(ctx context.Context, cfg opcua.Config) error {
 	if cfg.Mode != "" {
 		endpoints, err := opcuaGopcua.GetEndpoints(cfg.ServerURI)
 		if err != nil {
-			return errors.Wrap(errFailedFetchEndpoint, err)
+			return nil, errors.Wrap(errFailedFetchEndpoint, err)
 		}
 
 		ep := opcuaGopcua.SelectEndpoint(endpoints, cfg.Policy, uaGopcua.MessageSecurityModeFromString(cfg.Mode))
 		if ep == nil {
-			return errFailedFindEndpoint
+			return nil, errFailedFindEndpoint
 		}
 
 		opts = []opcuaGopcua.Option{
@@ -95,33 +179,10 @@ func (c client) Subscribe(ctx context.Context, cfg opcua.Config) error {
 		}
 	}
 
-	oc := opcuaGopcua.NewClient(cfg.ServerURI, opts...)
-	if err := oc.Connect(c.ctx); err != nil {
-		return errors.Wrap(errFailedConn, err)
-	}
-	defer oc.Close()
-
-	i, err := strconv.Atoi(cfg.Interval)
-	if err != nil {
-		return errors.Wrap(errFailedParseInterval, err)
-	}
-
-	sub, err := oc.Subscribe(&opcuaGopcua.SubscriptionParameters{
-		Interval: time.Duration(i) * time.Millisecond,
-	})
-	if err != nil {
-		return errors.Wrap(errFailedSub, err)
-	}
-	defer sub.Cancel()
-
-	if err := c.runHandler(ctx, sub, cfg.ServerURI, cfg.NodeID); err != nil {
-		c.logger.Warn(fmt.Sprintf("Unsubscribed from OPC-UA node %s.%s: %s", cfg.ServerURI, cfg.NodeID, err))
-	}
-
-	return nil
+	return opts, nil
 }
 
-func (c client) runHandler(ctx context.Context, sub *opcuaGopcua.Subscription, uri, node string) error {
+func (c client) runHandler(ctx context.Context, sub *opcuaGopcua.Subscription, uri, node string, params opcua.MonitoringParams) error {
 	nodeID, err := uaGopcua.ParseNodeID(node)
 	if err != nil {
 		return errors.Wrap(errFailedParseNodeID, err)
@@ -130,15 +191,27 @@ func (c client) runHandler(ctx context.Context, sub *opcuaGopcua.Subscription, u
 	// arbitrary client handle for the monitoring item
 	handle := uint32(42)
 	miCreateRequest := opcuaGopcua.NewMonitoredItemCreateRequestWithDefaults(nodeID, uaGopcua.AttributeIDValue, handle)
+	monitoringParameters(miCreateRequest.RequestedParameters, params)
 	res, err := sub.Monitor(uaGopcua.TimestampsToReturnBoth, miCreateRequest)
 	if err != nil {
+		if connError(err) {
+			return errors.Wrap(errConnLost, err)
+		}
 		return errors.Wrap(errFailedCreateReq, err)
 	}
 	if res.Results[0].StatusCode != uaGopcua.StatusOK {
 		return errResponseStatus
 	}
 
-	go sub.Run(c.ctx)
+	runCtx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+
+	// Run returns on the irrecoverable errors, such as the connection loss.
+	done := make(chan struct{})
+	go func() {
+		sub.Run(runCtx)
+		close(done)
+	}()
 
 	c.logger.Info(fmt.Sprintf("subscribed to server %s and node_id %s", uri, node))
 
@@ -146,6 +219,8 @@ func (c client) runHandler(ctx context.Context, sub *opcuaGopcua.Subscription, u
 		select {
 		case <-c.ctx.Done():
 			return nil
+		case <-done:
+			return errConnLost
 		case res := <-sub.Notifs:
 			if res.Error != nil {
 				c.logger.Error(res.Error.Error())
@@ -155,38 +230,7 @@ func (c client) runHandler(ctx context.Context, sub *opcuaGopcua.Subscription, u
 			switch x := res.Value.(type) {
 			case *uaGopcua.DataChangeNotification:
 				for _, item := range x.MonitoredItems {
-					msg := message{
-						ServerURI: uri,
-						NodeID:    node,
-						Type:      item.Value.Value.Type().String(),
-						Time:      item.Value.SourceTimestamp.Unix(),
-						DataKey:   "v",
-					}
-
-					switch item.Value.Value.Type() {
-					case uaGopcua.TypeIDBoolean:
-						msg.DataKey = "vb"
-						msg.Data = item.Value.Value.Bool()
-					case uaGopcua.TypeIDString, uaGopcua.TypeIDByteString:
-						msg.DataKey = "vs"
-						msg.Data = item.Value.Value.String()
-					case uaGopcua.TypeIDDataValue:
-						msg.DataKey = "vd"
-						msg.Data = item.Value.Value.String()
-					case uaGopcua.TypeIDInt64, uaGopcua.TypeIDInt32, uaGopcua.TypeIDInt16:
-						msg.Data = float64(item.Value.Value.Int())
-					case uaGopcua.TypeIDUint64, uaGopcua.TypeIDUint32, uaGopcua.TypeIDUint16:
-						msg.Data = float64(item.Value.Value.Uint())
-					case uaGopcua.TypeIDFloat, uaGopcua.TypeIDDouble:
-						msg.Data = item.Value.Value.Float()
-					case uaGopcua.TypeIDByte:
-						msg.Data = float64(item.Value.Value.Uint())
-					case uaGopcua.TypeIDDateTime:
-						msg.Data = item.Value.Value.Time().Unix()
-					default:
-						msg.Data = 0
-					}
-
+					msg := newMessage(uri, node, item.Value)
 					if err := c.publish(ctx, token, msg); err != nil {
 						switch err {
 						case errNotFoundServerURI, errNotFoundNodeID, errNotFoundConn:
@@ -204,6 +248,76 @@ func (c client) runHandler(ctx context.Context, sub *opcuaGopcua.Subscription, u
 	}
 }
 
+// monitoringParameters sets the requested monitored item parameters, keeping
+// the defaults of the unset ones.
+func monitoringParameters(mp *uaGopcua.MonitoringParameters, params opcua.MonitoringParams) {
+	if params.SamplingInterval > 0 {
+		mp.SamplingInterval = params.SamplingInterval
+	}
+
+	if params.QueueSize > 0 {
+		mp.QueueSize = params.QueueSize
+	}
+
+	var deadband uaGopcua.DeadbandType
+	switch params.DeadbandType {
+	case opcua.AbsoluteDeadband:
+		deadband = uaGopcua.DeadbandTypeAbsolute
+	case opcua.PercentDeadband:
+		deadband = uaGopcua.DeadbandTypePercent
+	default:
+		return
+	}
+
+	mp.Filter = &uaGopcua.ExtensionObject{
+		EncodingMask: uaGopcua.ExtensionObjectBinary,
+		TypeID:       uaGopcua.NewFourByteExpandedNodeID(0, id.DataChangeFilter_Encoding_DefaultBinary),
+		Value: &uaGopcua.DataChangeFilter{
+			Trigger:       uaGopcua.DataChangeTriggerStatusValue,
+			DeadbandType:  uint32(deadband),
+			DeadbandValue: params.DeadbandValue,
+		},
+	}
+}
+
+// newMessage converts the value of the node to the message published as the
+// mapped thing.
+func newMessage(uri, node string, dv *uaGopcua.DataValue) message {
+	msg := message{
+		ServerURI: uri,
+		NodeID:    node,
+		Type:      dv.Value.Type().String(),
+		Time:      dv.SourceTimestamp.Unix(),
+		DataKey:   "v",
+	}
+
+	switch dv.Value.Type() {
+	case uaGopcua.TypeIDBoolean:
+		msg.DataKey = "vb"
+		msg.Data = dv.Value.Bool()
+	case uaGopcua.TypeIDString, uaGopcua.TypeIDByteString:
+		msg.DataKey = "vs"
+		msg.Data = dv.Value.String()
+	case uaGopcua.TypeIDDataValue:
+		msg.DataKey = "vd"
+		msg.Data = dv.Value.String()
+	case uaGopcua.TypeIDInt64, uaGopcua.TypeIDInt32, uaGopcua.TypeIDInt16:
+		msg.Data = float64(dv.Value.Int())
+	case uaGopcua.TypeIDUint64, uaGopcua.TypeIDUint32, uaGopcua.TypeIDUint16:
+		msg.Data = float64(dv.Value.Uint())
+	case uaGopcua.TypeIDFloat, uaGopcua.TypeIDDouble:
+		msg.Data = dv.Value.Float()
+	case uaGopcua.TypeIDByte:
+		msg.Data = float64(dv.Value.Uint())
+	case uaGopcua.TypeIDDateTime:
+		msg.Data = dv.Value.Time().Unix()
+	default:
+		msg.Data = 0
+	}
+
+	return msg
+}
+
 // Publish forwards messages from the OPC-UA Server to Mainflux NATS broker
 func (c client) publish(ctx context.Context, token string, m message) error {
 	// Get route-map of the OPC-UA ServerURI
@@ -0,0 +1,227 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package gopcua
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gopcua/opcua/id"
+	uaGopcua "github.com/gopcua/opcua/ua"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/opcua"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var (
+	errFailedWrite     = errors.New("failed to write")
+	errUnsupportedType = errors.New("unsupported node data type")
+	errInvalidValue    = errors.New("invalid value for node data type")
+)
+
+var _ opcua.Writer = (*writer)(nil)
+
+type writer struct {
+	pool   *Pool
+	logger logger.Logger
+}
+
+// NewWriter returns new OPC-UA writer instance, writing using the pooled
+// connections to the servers.
+func NewWriter(pool *Pool, log logger.Logger) opcua.Writer {
+	return writer{
+		pool:   pool,
+		logger: log,
+	}
+}
+
+func (w writer) Write(serverURI, nodeID string, value interface{}) error {
+	nid, err := uaGopcua.ParseNodeID(nodeID)
+	if err != nil {
+		return errors.Wrap(errFailedParseNodeID, err)
+	}
+
+	oc, err := w.pool.Connect(serverURI)
+	if err != nil {
+		return err
+	}
+
+	// The value is converted to the node data type, since JSON only tells
+	// numbers, booleans and strings apart.
+	attrs, err := oc.Node(nid).Attributes(uaGopcua.AttributeIDDataType)
+	if err != nil {
+		if connError(err) {
+			w.pool.Fail(serverURI, oc, err)
+		}
+		return errors.Wrap(errFailedRead, err)
+	}
+	if attrs[0].Status != uaGopcua.StatusOK {
+		return errors.Wrap(errFailedRead, attrs[0].Status)
+	}
+
+	v, err := coerce(value, attrs[0].Value.NodeID())
+	if err != nil {
+		return err
+	}
+
+	variant, err := uaGopcua.NewVariant(v)
+	if err != nil {
+		return errors.Wrap(errInvalidValue, err)
+	}
+
+	req := &uaGopcua.WriteRequest{
+		NodesToWrite: []*uaGopcua.WriteValue{
+			{
+				NodeID:      nid,
+				AttributeID: uaGopcua.AttributeIDValue,
+				Value: &uaGopcua.DataValue{
+					EncodingMask: uaGopcua.DataValueValue,
+					Value:        variant,
+				},
+			},
+		},
+	}
+
+	res, err := oc.Write(req)
+	if err != nil {
+		if connError(err) {
+			w.pool.Fail(serverURI, oc, err)
+		}
+		return errors.Wrap(errFailedWrite, err)
+	}
+	if res.Results[0] != uaGopcua.StatusOK {
+		return errors.Wrap(errFailedWrite, res.Results[0])
+	}
+
+	w.logger.Info(fmt.Sprintf("write to server %s and node_id %s with value %v", serverURI, nodeID, v))
+	return nil
+}
+
+// coerce converts the value decoded from JSON to the Go type of the OPC-UA
+// built-in data type.
+func coerce(value interface{}, dataType *uaGopcua.NodeID) (interface{}, error) {
+	if dataType.Namespace() != 0 {
+		return nil, errors.Wrap(errUnsupportedType, errors.New(dataType.String()))
+	}
+
+	switch dataType.IntID() {
+	case id.Boolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, errors.Wrap(errInvalidValue, err)
+			}
+			return b, nil
+		}
+	case id.SByte:
+		i, err := toInt(value, 8)
+		return int8(i), err
+	case id.Int16:
+		i, err := toInt(value, 16)
+		return int16(i), err
+	case id.Int32:
+		i, err := toInt(value, 32)
+		return int32(i), err
+	case id.Int64:
+		return toInt(value, 64)
+	case id.Byte:
+		u, err := toUint(value, 8)
+		return byte(u), err
+	case id.UInt16:
+		u, err := toUint(value, 16)
+		return uint16(u), err
+	case id.UInt32:
+		u, err := toUint(value, 32)
+		return uint32(u), err
+	case id.UInt64:
+		return toUint(value, 64)
+	case id.Float:
+		f, err := toFloat(value, 32)
+		return float32(f), err
+	case id.Double:
+		return toFloat(value, 64)
+	case id.String:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprint(value), nil
+	case id.ByteString:
+		if s, ok := value.(string); ok {
+			return []byte(s), nil
+		}
+	case id.DateTime, id.UtcTime:
+		switch v := value.(type) {
+		case float64:
+			sec, frac := math.Modf(v)
+			return time.Unix(int64(sec), int64(frac*1e9)), nil
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, errors.Wrap(errInvalidValue, err)
+			}
+			return t, nil
+		}
+	default:
+		return nil, errors.Wrap(errUnsupportedType, errors.New(dataType.String()))
+	}
+
+	return nil, errInvalidValue
+}
+
+func toInt(value interface{}, bits int) (int64, error) {
+	switch v := value.(type) {
+	case float64:
+		limit := math.Ldexp(1, bits-1)
+		if v != math.Trunc(v) || v < -limit || v >= limit {
+			return 0, errInvalidValue
+		}
+		return int64(v), nil
+	case string:
+		i, err := strconv.ParseInt(v, 10, bits)
+		if err != nil {
+			return 0, errors.Wrap(errInvalidValue, err)
+		}
+		return i, nil
+	}
+
+	return 0, errInvalidValue
+}
+
+func toUint(value interface{}, bits int) (uint64, error) {
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) || v < 0 || v >= math.Ldexp(1, bits) {
+			return 0, errInvalidValue
+		}
+		return uint64(v), nil
+	case string:
+		u, err := strconv.ParseUint(v, 10, bits)
+		if err != nil {
+			return 0, errors.Wrap(errInvalidValue, err)
+		}
+		return u, nil
+	}
+
+	return 0, errInvalidValue
+}
+
+func toFloat(value interface{}, bits int) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(v, bits)
+		if err != nil {
+			return 0, errors.Wrap(errInvalidValue, err)
+		}
+		return f, nil
+	}
+
+	return 0, errInvalidValue
+}
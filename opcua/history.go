@@ -0,0 +1,17 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"time"
+)
+
+// HistoryReader represents the OPC-UA Server historical data reader.
+type HistoryReader interface {
+	// HistoryRead reads the raw historical values of the configured Node in
+	// the time range, publishes them as the messages of the mapped thing and
+	// returns their number.
+	HistoryRead(ctx context.Context, cfg Config, from, to time.Time) (int, error)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import "context"
+
+const (
+	// AbsoluteDeadband filters out the changes smaller than the deadband value.
+	AbsoluteDeadband = "absolute"
+
+	// PercentDeadband filters out the changes smaller than the deadband value
+	// percentage of the node EURange.
+	PercentDeadband = "percent"
+)
+
+// MonitoringParams represents the parameters of the monitored item of the
+// subscribed OPC-UA node. The zero values keep the server defaults.
+type MonitoringParams struct {
+	// SamplingInterval is the node sampling interval in milliseconds.
+	SamplingInterval float64 `json:"sampling_interval,omitempty"`
+	// QueueSize is the number of the values queued between the publishes.
+	QueueSize uint32 `json:"queue_size,omitempty"`
+	// DeadbandType is the deadband type of the data change filter, either
+	// absolute or percent.
+	DeadbandType string `json:"deadband_type,omitempty"`
+	// DeadbandValue is the deadband of the data change filter.
+	DeadbandValue float64 `json:"deadband_value,omitempty"`
+}
+
+// Validate returns an error if the monitoring parameters are invalid.
+func (p MonitoringParams) Validate() error {
+	if p.SamplingInterval < 0 || p.DeadbandValue < 0 {
+		return ErrMalformedEntity
+	}
+
+	switch p.DeadbandType {
+	case "":
+		if p.DeadbandValue != 0 {
+			return ErrMalformedEntity
+		}
+	case AbsoluteDeadband:
+	case PercentDeadband:
+		if p.DeadbandValue > 100 {
+			return ErrMalformedEntity
+		}
+	default:
+		return ErrMalformedEntity
+	}
+
+	return nil
+}
+
+// MonitoringRepository stores the monitoring parameters of the things nodes.
+type MonitoringRepository interface {
+	// Save stores the monitoring parameters of the thing node.
+	Save(ctx context.Context, thingID string, params MonitoringParams) error
+
+	// Get returns the monitoring parameters of the thing node.
+	Get(ctx context.Context, thingID string) (MonitoringParams, error)
+
+	// Remove removes the monitoring parameters of the thing node.
+	Remove(ctx context.Context, thingID string) error
+}
@@ -0,0 +1,22 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import "time"
+
+// ServerStatus represents the health of the connection to the OPC-UA Server.
+type ServerStatus struct {
+	ServerURI string    `json:"server_uri"`
+	Connected bool      `json:"connected"`
+	Since     time.Time `json:"since"`
+	Retries   int       `json:"retries"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Pool represents the supervised connections to the OPC-UA Servers.
+type Pool interface {
+	// Status returns the health of the connections to the servers, ordered
+	// by the server URI.
+	Status() []ServerStatus
+}
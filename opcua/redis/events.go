@@ -3,9 +3,12 @@
 
 package redis
 
+import "github.com/mainflux/mainflux/opcua"
+
 type createThingEvent struct {
 	id          string
 	opcuaNodeID string
+	monitoring  opcua.MonitoringParams
 }
 
 type removeThingEvent struct {
@@ -0,0 +1,58 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/opcua"
+)
+
+const monitoringPrefix = "monitoring"
+
+var _ opcua.MonitoringRepository = (*monitoringRepository)(nil)
+
+type monitoringRepository struct {
+	client *redis.Client
+}
+
+// NewMonitoringRepository returns redis monitoring parameters repository.
+func NewMonitoringRepository(client *redis.Client) opcua.MonitoringRepository {
+	return &monitoringRepository{
+		client: client,
+	}
+}
+
+func (mr *monitoringRepository) Save(ctx context.Context, thingID string, params opcua.MonitoringParams) error {
+	val, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s:%s", monitoringPrefix, thingID)
+	return mr.client.Set(ctx, key, val, 0).Err()
+}
+
+func (mr *monitoringRepository) Get(ctx context.Context, thingID string) (opcua.MonitoringParams, error) {
+	key := fmt.Sprintf("%s:%s", monitoringPrefix, thingID)
+	val, err := mr.client.Get(ctx, key).Bytes()
+	if err != nil {
+		return opcua.MonitoringParams{}, err
+	}
+
+	var params opcua.MonitoringParams
+	if err := json.Unmarshal(val, &params); err != nil {
+		return opcua.MonitoringParams{}, err
+	}
+
+	return params, nil
+}
+
+func (mr *monitoringRepository) Remove(ctx context.Context, thingID string) error {
+	key := fmt.Sprintf("%s:%s", monitoringPrefix, thingID)
+	return mr.client.Del(ctx, key).Err()
+}
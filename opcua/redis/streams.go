@@ -94,14 +94,14 @@ func (es eventStore) Subscribe(ctx context.Context, subject string) error {
 					err = e
 					break
 				}
-				err = es.svc.CreateThing(ctx, cte.id, cte.opcuaNodeID)
+				err = es.svc.CreateThing(ctx, cte.id, cte.opcuaNodeID, cte.monitoring)
 			case thingUpdate:
 				ute, e := decodeCreateThing(event)
 				if e != nil {
 					err = e
 					break
 				}
-				err = es.svc.CreateThing(ctx, ute.id, ute.opcuaNodeID)
+				err = es.svc.UpdateThing(ctx, ute.id, ute.opcuaNodeID, ute.monitoring)
 			case thingRemove:
 				rte := decodeRemoveThing(event)
 				err = es.svc.RemoveThing(ctx, rte.id)
@@ -165,6 +165,16 @@ func decodeCreateThing(event map[string]interface{}) (createThingEvent, error) {
 	}
 
 	cte.opcuaNodeID = val
+
+	// The monitoring parameters are read from the same metadata object.
+	b, err := json.Marshal(metadataVal)
+	if err != nil {
+		return createThingEvent{}, errMetadataFormat
+	}
+	if err := json.Unmarshal(b, &cte.monitoring); err != nil {
+		return createThingEvent{}, errMetadataFormat
+	}
+
 	return cte, nil
 }
 
@@ -0,0 +1,11 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+// Writer represents the OPC-UA Server Nodes writer.
+type Writer interface {
+	// Write writes the value to the Node of the OPC-UA Server, converting
+	// it to the Node data type.
+	Write(serverURI, nodeID string, value interface{}) error
+}
@@ -0,0 +1,155 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clickhouse contains ClickHouse HTTP interface client.
+package clickhouse
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var (
+	// ErrExec indicates failure to execute the query.
+	ErrExec = errors.New("failed to execute clickhouse query")
+
+	// ErrDecode indicates failure to decode the query result.
+	ErrDecode = errors.New("failed to decode clickhouse query result")
+)
+
+// Config defines the options that are used when connecting to a ClickHouse
+// instance.
+type Config struct {
+	URL     string
+	DB      string
+	User    string
+	Pass    string
+	Timeout time.Duration
+}
+
+// Client represents ClickHouse HTTP interface client.
+type Client interface {
+	// Exec executes the query. If the body is not nil, it is sent
+	// following the query, which is used to insert data.
+	Exec(query string, body io.Reader) error
+
+	// Query executes the query with the given parameters and decodes
+	// the result rows into the slice pointed to by rows.
+	Query(query string, params map[string]string, rows interface{}) error
+
+	// Mutate executes the mutation query, such as ALTER TABLE DELETE, with
+	// the given parameters, and waits for the mutation to complete.
+	Mutate(query string, params map[string]string) error
+
+	// Ping checks if ClickHouse is available.
+	Ping() error
+}
+
+type client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// New returns new ClickHouse client.
+func New(cfg Config) Client {
+	return client{
+		cfg:  cfg,
+		http: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (c client) Exec(query string, body io.Reader) error {
+	if body == nil {
+		_, err := c.do(query, nil, nil, nil)
+		return err
+	}
+
+	// The data to be inserted follows the query in the request body.
+	r := io.MultiReader(strings.NewReader(query+"\n"), body)
+	_, err := c.do("", nil, nil, r)
+	return err
+}
+
+func (c client) Query(query string, params map[string]string, rows interface{}) error {
+	data, err := c.do(query+" FORMAT JSON", params, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	res := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return errors.Wrap(ErrDecode, err)
+	}
+	if err := json.Unmarshal(res.Data, rows); err != nil {
+		return errors.Wrap(ErrDecode, err)
+	}
+
+	return nil
+}
+
+func (c client) Mutate(query string, params map[string]string) error {
+	// Mutations are asynchronous unless the synchronous mode is set.
+	settings := map[string]string{"mutations_sync": "1"}
+	_, err := c.do(query, params, settings, nil)
+	return err
+}
+
+func (c client) Ping() error {
+	return c.Exec("SELECT 1", nil)
+}
+
+func (c client) do(query string, params, settings map[string]string, body io.Reader) ([]byte, error) {
+	vals := url.Values{}
+	if query != "" {
+		vals.Set("query", query)
+	}
+	if c.cfg.DB != "" {
+		vals.Set("database", c.cfg.DB)
+	}
+	// Prevent 64-bit integers from being quoted in JSON output.
+	vals.Set("output_format_json_quote_64bit_integers", "0")
+	for k, v := range params {
+		vals.Set("param_"+k, v)
+	}
+	for k, v := range settings {
+		vals.Set(k, v)
+	}
+
+	if body == nil {
+		body = &bytes.Buffer{}
+	}
+	req, err := http.NewRequest(http.MethodPost, c.cfg.URL+"/?"+vals.Encode(), body)
+	if err != nil {
+		return nil, errors.Wrap(ErrExec, err)
+	}
+	if c.cfg.User != "" {
+		req.Header.Set("X-ClickHouse-User", c.cfg.User)
+		req.Header.Set("X-ClickHouse-Key", c.cfg.Pass)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(ErrExec, err)
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(ErrExec, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Wrap(ErrExec, errors.New(strings.TrimSpace(string(data))))
+	}
+
+	return data, nil
+}
@@ -0,0 +1,171 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/clickhouse"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	db   = "mainflux"
+	user = "user"
+	pass = "pass"
+)
+
+type row struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-ClickHouse-User") != user || r.Header.Get("X-ClickHouse-Key") != pass {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		assert.Equal(t, db, r.URL.Query().Get("database"), "expected database to be set")
+
+		query := r.URL.Query().Get("query")
+		body, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case strings.HasPrefix(query, "SELECT name, value FROM test WHERE name = {name:String}"):
+			name := r.URL.Query().Get("param_name")
+			fmt.Fprintf(w, `{"meta": [], "data": [{"name": "%s", "value": 42}], "rows": 1}`, name)
+		case strings.HasPrefix(query, "ALTER TABLE test DELETE WHERE name = {name:String}"):
+			assert.Equal(t, "1", r.URL.Query().Get("mutations_sync"), "expected synchronous mutation")
+			assert.Equal(t, "temperature", r.URL.Query().Get("param_name"), "expected query parameter")
+		case strings.HasPrefix(query, "SELECT"):
+			fmt.Fprint(w, "1\n")
+		case query == "" && strings.HasPrefix(string(body), "INSERT INTO test FORMAT JSONEachRow\n"):
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "Code: 62. DB::Exception: Syntax error")
+		}
+	}))
+}
+
+func TestExec(t *testing.T) {
+	ts := newServer(t)
+	defer ts.Close()
+	c := clickhouse.New(clickhouse.Config{URL: ts.URL, DB: db, User: user, Pass: pass})
+
+	cases := []struct {
+		desc  string
+		query string
+		body  string
+		err   error
+	}{
+		{
+			desc:  "execute valid query",
+			query: "SELECT 1",
+			err:   nil,
+		},
+		{
+			desc:  "insert data",
+			query: "INSERT INTO test FORMAT JSONEachRow",
+			body:  `{"name": "name", "value": 42}`,
+			err:   nil,
+		},
+		{
+			desc:  "execute invalid query",
+			query: "SELEC 1",
+			err:   clickhouse.ErrExec,
+		},
+	}
+
+	for _, tc := range cases {
+		var err error
+		switch tc.body {
+		case "":
+			err = c.Exec(tc.query, nil)
+		default:
+			err = c.Exec(tc.query, strings.NewReader(tc.body))
+		}
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestQuery(t *testing.T) {
+	ts := newServer(t)
+	defer ts.Close()
+	c := clickhouse.New(clickhouse.Config{URL: ts.URL, DB: db, User: user, Pass: pass})
+	unauthorized := clickhouse.New(clickhouse.Config{URL: ts.URL, DB: db, User: user})
+
+	cases := []struct {
+		desc   string
+		client clickhouse.Client
+		query  string
+		params map[string]string
+		rows   []row
+		err    error
+	}{
+		{
+			desc:   "query rows",
+			client: c,
+			query:  "SELECT name, value FROM test WHERE name = {name:String}",
+			params: map[string]string{"name": "temperature"},
+			rows:   []row{{Name: "temperature", Value: 42}},
+			err:    nil,
+		},
+		{
+			desc:   "query rows with invalid query",
+			client: c,
+			query:  "SELEC name FROM test",
+			err:    clickhouse.ErrExec,
+		},
+		{
+			desc:   "query rows with invalid credentials",
+			client: unauthorized,
+			query:  "SELECT name, value FROM test WHERE name = {name:String}",
+			params: map[string]string{"name": "temperature"},
+			err:    clickhouse.ErrExec,
+		},
+	}
+
+	for _, tc := range cases {
+		var rows []row
+		err := tc.client.Query(tc.query, tc.params, &rows)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.rows, rows, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.rows, rows))
+	}
+}
+
+func TestMutate(t *testing.T) {
+	ts := newServer(t)
+	defer ts.Close()
+	c := clickhouse.New(clickhouse.Config{URL: ts.URL, DB: db, User: user, Pass: pass})
+
+	cases := []struct {
+		desc   string
+		query  string
+		params map[string]string
+		err    error
+	}{
+		{
+			desc:   "execute mutation",
+			query:  "ALTER TABLE test DELETE WHERE name = {name:String}",
+			params: map[string]string{"name": "temperature"},
+			err:    nil,
+		},
+		{
+			desc:  "execute invalid mutation",
+			query: "ALTER TABLE test DELET WHERE 1",
+			err:   clickhouse.ErrExec,
+		},
+	}
+
+	for _, tc := range cases {
+		err := c.Mutate(tc.query, tc.params)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package compression contains the compression codecs used for reducing the
+// size of the stored messages.
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// Supported codecs. Empty codec means that the data is not compressed.
+const (
+	None   = ""
+	Gzip   = "gzip"
+	Snappy = "snappy"
+	Zstd   = "zstd"
+)
+
+var (
+	// ErrUnknownCodec indicates that the codec is not supported.
+	ErrUnknownCodec = errors.New("unknown compression codec")
+
+	// ErrCompress indicates failure to compress the data.
+	ErrCompress = errors.New("failed to compress data")
+
+	// ErrDecompress indicates failure to decompress the data.
+	ErrDecompress = errors.New("failed to decompress data")
+)
+
+// Magic numbers which the compressed data starts with. Snappy data uses the
+// framing format, since the block format has no magic number.
+var (
+	gzipMagic   = []byte{0x1f, 0x8b}
+	snappyMagic = []byte("\xff\x06\x00\x00sNaPpY")
+	zstdMagic   = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Encoder and decoder are safe for concurrent use of EncodeAll and DecodeAll.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// Validate returns an error if the codec is not supported.
+func Validate(codec string) error {
+	switch codec {
+	case None, Gzip, Snappy, Zstd:
+		return nil
+	default:
+		return ErrUnknownCodec
+	}
+}
+
+// Compress compresses the data using the codec.
+func Compress(codec string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	switch codec {
+	case None:
+		return data, nil
+	case Gzip:
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, errors.Wrap(ErrCompress, err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, errors.Wrap(ErrCompress, err)
+		}
+	case Snappy:
+		w := snappy.NewBufferedWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, errors.Wrap(ErrCompress, err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, errors.Wrap(ErrCompress, err)
+		}
+	case Zstd:
+		return zstdEncoder.EncodeAll(data, nil), nil
+	default:
+		return nil, ErrUnknownCodec
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress detects the codec of the data by its magic number and
+// decompresses it. Data which isn't compressed is returned unchanged.
+func Decompress(data []byte) ([]byte, error) {
+	var ret []byte
+	var err error
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		var r *gzip.Reader
+		if r, err = gzip.NewReader(bytes.NewReader(data)); err == nil {
+			ret, err = ioutil.ReadAll(r)
+		}
+	case bytes.HasPrefix(data, snappyMagic):
+		ret, err = ioutil.ReadAll(snappy.NewReader(bytes.NewReader(data)))
+	case bytes.HasPrefix(data, zstdMagic):
+		ret, err = zstdDecoder.DecodeAll(data, nil)
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(ErrDecompress, err)
+	}
+	return ret, nil
+}
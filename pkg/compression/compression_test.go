@@ -0,0 +1,54 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package compression_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/compression"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"temperature":21.5,"humidity":40}`), 100)
+
+	cases := []struct {
+		desc  string
+		codec string
+		err   error
+	}{
+		{desc: "compress without codec", codec: compression.None},
+		{desc: "compress using gzip", codec: compression.Gzip},
+		{desc: "compress using snappy", codec: compression.Snappy},
+		{desc: "compress using zstd", codec: compression.Zstd},
+		{desc: "compress using unknown codec", codec: "lz4", err: compression.ErrUnknownCodec},
+	}
+
+	for _, tc := range cases {
+		err := compression.Validate(tc.codec)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected validation error %s got %s\n", tc.desc, tc.err, err))
+
+		compressed, err := compression.Compress(tc.codec, data)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+		if tc.codec != compression.None {
+			assert.Less(t, len(compressed), len(data), fmt.Sprintf("%s: expected compressed data", tc.desc))
+		}
+
+		decompressed, err := compression.Decompress(compressed)
+		require.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", tc.desc, err))
+		assert.Equal(t, data, decompressed, fmt.Sprintf("%s: expected original data", tc.desc))
+	}
+}
+
+func TestDecompressCorrupted(t *testing.T) {
+	_, err := compression.Decompress([]byte{0x1f, 0x8b, 0x00})
+	assert.True(t, errors.Contains(err, compression.ErrDecompress), fmt.Sprintf("expected %s got %s\n", compression.ErrDecompress, err))
+}
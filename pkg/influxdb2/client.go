@@ -0,0 +1,281 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package influxdb2 contains InfluxDB 2.x HTTP API client.
+package influxdb2
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	datatypeAnnotation = "#datatype"
+	errorColumn        = "error"
+)
+
+var (
+	// ErrWrite indicates failure to write the points.
+	ErrWrite = errors.New("failed to write influxdb points")
+
+	// ErrQuery indicates failure to execute the Flux query.
+	ErrQuery = errors.New("failed to execute influxdb query")
+
+	// ErrDecode indicates failure to decode the query result.
+	ErrDecode = errors.New("failed to decode influxdb query result")
+
+	// ErrDelete indicates failure to delete the points.
+	ErrDelete = errors.New("failed to delete influxdb points")
+
+	// ErrPing indicates that InfluxDB is not available.
+	ErrPing = errors.New("failed to ping influxdb")
+)
+
+// Config defines the options that are used when connecting to an InfluxDB
+// 2.x instance.
+type Config struct {
+	URL     string
+	Org     string
+	Token   string
+	Timeout time.Duration
+}
+
+// Row represents the row of the query result, mapping the column names to
+// the values. Null values are omitted.
+type Row map[string]interface{}
+
+// Client represents InfluxDB 2.x HTTP API client.
+type Client interface {
+	// Write writes the points given in line protocol, with nanosecond
+	// precision timestamps, to the bucket.
+	Write(bucket string, points io.Reader) error
+
+	// Query executes the Flux query and returns the rows of all the result
+	// tables. The values are converted to Go types using the column data
+	// types.
+	Query(query string) ([]Row, error)
+
+	// Delete deletes the points of the bucket within the time range, which
+	// match the delete predicate.
+	Delete(bucket string, start, stop time.Time, predicate string) error
+
+	// Ping checks if InfluxDB is available.
+	Ping() error
+}
+
+type client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// New returns new InfluxDB 2.x client.
+func New(cfg Config) Client {
+	return client{
+		cfg:  cfg,
+		http: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (c client) Write(bucket string, points io.Reader) error {
+	vals := url.Values{}
+	vals.Set("org", c.cfg.Org)
+	vals.Set("bucket", bucket)
+	vals.Set("precision", "ns")
+
+	res, err := c.do(http.MethodPost, "/api/v2/write?"+vals.Encode(), "text/plain; charset=utf-8", points)
+	if err != nil {
+		return errors.Wrap(ErrWrite, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return errors.Wrap(ErrWrite, apiError(res))
+	}
+	return nil
+}
+
+func (c client) Query(query string) ([]Row, error) {
+	req := map[string]interface{}{
+		"query": query,
+		"type":  "flux",
+		"dialect": map[string]interface{}{
+			"header":      true,
+			"annotations": []string{"datatype"},
+		},
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(ErrQuery, err)
+	}
+
+	vals := url.Values{}
+	vals.Set("org", c.cfg.Org)
+	res, err := c.do(http.MethodPost, "/api/v2/query?"+vals.Encode(), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(ErrQuery, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Wrap(ErrQuery, apiError(res))
+	}
+
+	return decode(res.Body)
+}
+
+func (c client) Delete(bucket string, start, stop time.Time, predicate string) error {
+	req := map[string]interface{}{
+		"start":     start.UTC().Format(time.RFC3339Nano),
+		"stop":      stop.UTC().Format(time.RFC3339Nano),
+		"predicate": predicate,
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return errors.Wrap(ErrDelete, err)
+	}
+
+	vals := url.Values{}
+	vals.Set("org", c.cfg.Org)
+	vals.Set("bucket", bucket)
+	res, err := c.do(http.MethodPost, "/api/v2/delete?"+vals.Encode(), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(ErrDelete, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return errors.Wrap(ErrDelete, apiError(res))
+	}
+	return nil
+}
+
+func (c client) Ping() error {
+	res, err := c.do(http.MethodGet, "/health", "", nil)
+	if err != nil {
+		return errors.Wrap(ErrPing, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.Wrap(ErrPing, apiError(res))
+	}
+	return nil
+}
+
+func (c client) do(method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.cfg.URL, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Token "+c.cfg.Token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return c.http.Do(req)
+}
+
+// apiError returns the error message from the response body of the failed
+// request.
+func apiError(res *http.Response) error {
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	e := struct {
+		Message string `json:"message"`
+	}{}
+	if err := json.Unmarshal(data, &e); err == nil && e.Message != "" {
+		return errors.New(e.Message)
+	}
+	if msg := strings.TrimSpace(string(data)); msg != "" {
+		return errors.New(msg)
+	}
+	return errors.New(res.Status)
+}
+
+// decode decodes the annotated CSV query result. Each result table starts
+// with the data types annotation, followed by the header.
+func decode(r io.Reader) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	var rows []Row
+	var types, header []string
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(ErrDecode, err)
+		}
+
+		switch {
+		case rec[0] == datatypeAnnotation:
+			types, header = rec, nil
+		case header == nil:
+			header = rec
+		case len(header) > 1 && header[1] == errorColumn && len(rec) > 1:
+			// Errors which occur once the response has started are
+			// returned in the error table.
+			return nil, errors.Wrap(ErrQuery, errors.New(rec[1]))
+		default:
+			row, err := decodeRow(header, types, rec)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+	}
+}
+
+func decodeRow(header, types, rec []string) (Row, error) {
+	row := Row{}
+	// The first column is reserved for the annotations.
+	for i := 1; i < len(header) && i < len(rec); i++ {
+		if rec[i] == "" {
+			continue
+		}
+		var typ string
+		if i < len(types) {
+			typ = types[i]
+		}
+		v, err := decodeValue(typ, rec[i])
+		if err != nil {
+			return nil, errors.Wrap(ErrDecode, err)
+		}
+		row[header[i]] = v
+	}
+	return row, nil
+}
+
+func decodeValue(typ, val string) (interface{}, error) {
+	switch {
+	case typ == "double":
+		return strconv.ParseFloat(val, 64)
+	case typ == "long":
+		return strconv.ParseInt(val, 10, 64)
+	case typ == "unsignedLong":
+		return strconv.ParseUint(val, 10, 64)
+	case typ == "boolean":
+		return strconv.ParseBool(val)
+	case strings.HasPrefix(typ, "dateTime"):
+		return time.Parse(time.RFC3339Nano, val)
+	default:
+		return val, nil
+	}
+}
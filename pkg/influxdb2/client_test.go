@@ -0,0 +1,222 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb2_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/influxdb2"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	org    = "mainflux"
+	bucket = "messages"
+	token  = "token"
+	point  = "messages,channel=ch value=5 1633046400000000000"
+	pred   = `_measurement="messages" AND channel="ch"`
+
+	result = "#datatype,string,long,dateTime:RFC3339,double,boolean,string\r\n" +
+		",result,table,_time,value,boolValue,protocol\r\n" +
+		",_result,0,2021-10-01T00:00:00Z,5,,mqtt\r\n" +
+		",_result,0,2021-10-01T00:00:01.5Z,,true,http\r\n" +
+		"\r\n"
+	errResult = "#datatype,string,string\r\n" +
+		",error,reference\r\n" +
+		",runtime error: column not found,\r\n"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			fmt.Fprint(w, `{"name": "influxdb", "status": "pass"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Token "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"code": "unauthorized", "message": "unauthorized access"}`)
+			return
+		}
+		assert.Equal(t, org, r.URL.Query().Get("org"), "expected org to be set")
+
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/api/v2/write":
+			if r.URL.Query().Get("bucket") != bucket {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"code": "not found", "message": "bucket not found"}`)
+				return
+			}
+			assert.Equal(t, point, string(body), "expected points in line protocol")
+			w.WriteHeader(http.StatusNoContent)
+		case "/api/v2/query":
+			var req struct {
+				Query string `json:"query"`
+			}
+			json.Unmarshal(body, &req)
+			switch {
+			case strings.HasPrefix(req.Query, "from(bucket: \"messages\")"):
+				fmt.Fprint(w, result)
+			case strings.HasPrefix(req.Query, "from(bucket: \"invalid\")"):
+				fmt.Fprint(w, errResult)
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"code": "invalid", "message": "compilation failed"}`)
+			}
+		case "/api/v2/delete":
+			if r.URL.Query().Get("bucket") != bucket {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"code": "not found", "message": "bucket not found"}`)
+				return
+			}
+			var req struct {
+				Start     string `json:"start"`
+				Stop      string `json:"stop"`
+				Predicate string `json:"predicate"`
+			}
+			json.Unmarshal(body, &req)
+			assert.Equal(t, "1970-01-01T00:00:00Z", req.Start, "expected start of the time range")
+			assert.Equal(t, "2021-10-01T00:00:00.5Z", req.Stop, "expected stop of the time range")
+			assert.Equal(t, pred, req.Predicate, "expected delete predicate")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestWrite(t *testing.T) {
+	ts := newServer(t)
+	defer ts.Close()
+	c := influxdb2.New(influxdb2.Config{URL: ts.URL, Org: org, Token: token})
+	unauthorized := influxdb2.New(influxdb2.Config{URL: ts.URL, Org: org})
+
+	cases := []struct {
+		desc   string
+		client influxdb2.Client
+		bucket string
+		err    error
+	}{
+		{
+			desc:   "write points",
+			client: c,
+			bucket: bucket,
+			err:    nil,
+		},
+		{
+			desc:   "write points to nonexistent bucket",
+			client: c,
+			bucket: "nonexistent",
+			err:    influxdb2.ErrWrite,
+		},
+		{
+			desc:   "write points with invalid token",
+			client: unauthorized,
+			bucket: bucket,
+			err:    influxdb2.ErrWrite,
+		},
+	}
+
+	for _, tc := range cases {
+		err := tc.client.Write(tc.bucket, strings.NewReader(point))
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestQuery(t *testing.T) {
+	ts := newServer(t)
+	defer ts.Close()
+	c := influxdb2.New(influxdb2.Config{URL: ts.URL, Org: org, Token: token})
+
+	cases := []struct {
+		desc  string
+		query string
+		rows  []influxdb2.Row
+		err   error
+	}{
+		{
+			desc:  "query rows",
+			query: `from(bucket: "messages") |> range(start: 0)`,
+			rows: []influxdb2.Row{
+				{"result": "_result", "table": int64(0), "_time": time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC), "value": 5.0, "protocol": "mqtt"},
+				{"result": "_result", "table": int64(0), "_time": time.Date(2021, 10, 1, 0, 0, 1, 5e8, time.UTC), "boolValue": true, "protocol": "http"},
+			},
+			err: nil,
+		},
+		{
+			desc:  "query rows with runtime error",
+			query: `from(bucket: "invalid") |> range(start: 0)`,
+			err:   influxdb2.ErrQuery,
+		},
+		{
+			desc:  "query rows with invalid query",
+			query: `from(bucket: "messages"`,
+			err:   influxdb2.ErrQuery,
+		},
+	}
+
+	for _, tc := range cases {
+		rows, err := c.Query(tc.query)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.rows, rows, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.rows, rows))
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ts := newServer(t)
+	defer ts.Close()
+	c := influxdb2.New(influxdb2.Config{URL: ts.URL, Org: org, Token: token})
+	unauthorized := influxdb2.New(influxdb2.Config{URL: ts.URL, Org: org})
+
+	cases := []struct {
+		desc   string
+		client influxdb2.Client
+		bucket string
+		err    error
+	}{
+		{
+			desc:   "delete points",
+			client: c,
+			bucket: bucket,
+			err:    nil,
+		},
+		{
+			desc:   "delete points of nonexistent bucket",
+			client: c,
+			bucket: "nonexistent",
+			err:    influxdb2.ErrDelete,
+		},
+		{
+			desc:   "delete points with invalid token",
+			client: unauthorized,
+			bucket: bucket,
+			err:    influxdb2.ErrDelete,
+		},
+	}
+
+	start, stop := time.Unix(0, 0), time.Date(2021, 10, 1, 0, 0, 0, 5e8, time.UTC)
+	for _, tc := range cases {
+		err := tc.client.Delete(tc.bucket, start, stop, pred)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestPing(t *testing.T) {
+	ts := newServer(t)
+	c := influxdb2.New(influxdb2.Config{URL: ts.URL})
+	err := c.Ping()
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	ts.Close()
+	err = c.Ping()
+	assert.True(t, errors.Contains(err, influxdb2.ErrPing), fmt.Sprintf("expected %s got %s\n", influxdb2.ErrPing, err))
+}
@@ -30,6 +30,8 @@ type Message struct {
 	Protocol             string   `protobuf:"bytes,4,opt,name=protocol,proto3" json:"protocol,omitempty"`
 	Payload              []byte   `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
 	Created              int64    `protobuf:"varint,6,opt,name=created,proto3" json:"created,omitempty"`
+	Id                   string   `protobuf:"bytes,7,opt,name=id,proto3" json:"id,omitempty"`
+	ContentType          string   `protobuf:"bytes,8,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -110,6 +112,20 @@ func (m *Message) GetCreated() int64 {
 	return 0
 }
 
+func (m *Message) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Message) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Message)(nil), "messaging.Message")
 }
@@ -117,19 +133,21 @@ func init() {
 func init() { proto.RegisterFile("pkg/messaging/message.proto", fileDescriptor_e5e29d24c44e4762) }
 
 var fileDescriptor_e5e29d24c44e4762 = []byte{
-	// 187 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x92, 0x2e, 0xc8, 0x4e, 0xd7,
-	0xcf, 0x4d, 0x2d, 0x2e, 0x4e, 0x4c, 0xcf, 0xcc, 0x83, 0xb1, 0x52, 0xf5, 0x0a, 0x8a, 0xf2, 0x4b,
-	0xf2, 0x85, 0x38, 0xe1, 0x12, 0x4a, 0x6b, 0x19, 0xb9, 0xd8, 0x7d, 0x21, 0x92, 0x42, 0x12, 0x5c,
-	0xec, 0xc9, 0x19, 0x89, 0x79, 0x79, 0xa9, 0x39, 0x12, 0x8c, 0x0a, 0x8c, 0x1a, 0x9c, 0x41, 0x30,
-	0xae, 0x90, 0x14, 0x17, 0x47, 0x71, 0x69, 0x52, 0x49, 0x7e, 0x41, 0x66, 0xb2, 0x04, 0x13, 0x58,
-	0x0a, 0xce, 0x17, 0x92, 0xe1, 0xe2, 0x2c, 0x28, 0x4d, 0xca, 0xc9, 0x2c, 0xce, 0x48, 0x2d, 0x92,
-	0x60, 0x06, 0x4b, 0x22, 0x04, 0x40, 0x3a, 0xc1, 0x76, 0x26, 0xe7, 0xe7, 0x48, 0xb0, 0x40, 0x74,
-	0xc2, 0xf8, 0x20, 0xfb, 0x0a, 0x12, 0x2b, 0x73, 0xf2, 0x13, 0x53, 0x24, 0x58, 0x15, 0x18, 0x35,
-	0x78, 0x82, 0x60, 0x5c, 0xb0, 0x4b, 0x8a, 0x52, 0x13, 0x4b, 0x52, 0x53, 0x24, 0xd8, 0x14, 0x18,
-	0x35, 0x98, 0x83, 0x60, 0x5c, 0x27, 0x81, 0x13, 0x8f, 0xe4, 0x18, 0x2f, 0x3c, 0x92, 0x63, 0x7c,
-	0xf0, 0x48, 0x8e, 0x71, 0xc6, 0x63, 0x39, 0x86, 0x24, 0x36, 0xb0, 0x79, 0xc6, 0x80, 0x00, 0x00,
-	0x00, 0xff, 0xff, 0xa9, 0x1e, 0x4a, 0xea, 0xf2, 0x00, 0x00, 0x00,
+	// 222 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x44, 0x8e, 0x31, 0x4e, 0xc3, 0x30,
+	0x14, 0x86, 0x79, 0x29, 0x34, 0x8d, 0xa9, 0x10, 0xf2, 0xf4, 0x04, 0x28, 0x0a, 0x4c, 0x99, 0x60,
+	0xe0, 0x06, 0xec, 0x2c, 0x11, 0x3b, 0x72, 0xec, 0xa7, 0xd6, 0xc2, 0xd8, 0x56, 0xec, 0x0e, 0xb9,
+	0x09, 0x47, 0x62, 0xe4, 0x08, 0x55, 0xb8, 0x08, 0xaa, 0x8b, 0xd3, 0xcd, 0xdf, 0xfb, 0xf4, 0xfb,
+	0xff, 0xd9, 0xad, 0xff, 0xd8, 0x3c, 0x7d, 0x52, 0x08, 0x62, 0xa3, 0x6d, 0x7e, 0xd1, 0xa3, 0x1f,
+	0x5c, 0x74, 0xbc, 0x9a, 0xc5, 0xc3, 0x1e, 0x58, 0xf9, 0x7a, 0x94, 0x1c, 0x59, 0x29, 0xb7, 0xc2,
+	0x5a, 0x32, 0x08, 0x0d, 0xb4, 0x55, 0x97, 0x91, 0xdf, 0xb0, 0x55, 0xd8, 0xf5, 0xd1, 0x79, 0x2d,
+	0xb1, 0x48, 0x6a, 0x66, 0x7e, 0xc7, 0x2a, 0xbf, 0xeb, 0x8d, 0x0e, 0x5b, 0x1a, 0x70, 0x91, 0xe4,
+	0xe9, 0x70, 0x48, 0xa6, 0x4e, 0xe9, 0x0c, 0x9e, 0x1f, 0x93, 0x99, 0x0f, 0x7d, 0x5e, 0x8c, 0xc6,
+	0x09, 0x85, 0x17, 0x0d, 0xb4, 0xeb, 0x2e, 0x63, 0x5a, 0x32, 0x90, 0x88, 0xa4, 0x70, 0xd9, 0x40,
+	0xbb, 0xe8, 0x32, 0xf2, 0x2b, 0x56, 0x68, 0x85, 0x65, 0xfa, 0xa9, 0xd0, 0x8a, 0xdf, 0xb3, 0xb5,
+	0x74, 0x36, 0x92, 0x8d, 0xef, 0x71, 0xf4, 0x84, 0xab, 0x64, 0x2e, 0xff, 0x6f, 0x6f, 0xa3, 0xa7,
+	0x97, 0xeb, 0xef, 0xa9, 0x86, 0x9f, 0xa9, 0x86, 0xfd, 0x54, 0xc3, 0xd7, 0x6f, 0x7d, 0xd6, 0x2f,
+	0xd3, 0x84, 0xe7, 0xbf, 0x01, 0x00, 0x5a, 0xbe, 0x06, 0xa8, 0x25, 0x01, 0x00, 0x00,
 }
 
 func (m *Message) Marshal() (dAtA []byte, err error) {
@@ -156,6 +174,20 @@ func (m *Message) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.ContentType) > 0 {
+		i -= len(m.ContentType)
+		copy(dAtA[i:], m.ContentType)
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.ContentType)))
+		i--
+		dAtA[i] = 0x42
+	}
+	if len(m.Id) > 0 {
+		i -= len(m.Id)
+		copy(dAtA[i:], m.Id)
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Id)))
+		i--
+		dAtA[i] = 0x3a
+	}
 	if m.Created != 0 {
 		i = encodeVarintMessage(dAtA, i, uint64(m.Created))
 		i--
@@ -239,6 +271,14 @@ func (m *Message) Size() (n int) {
 	if m.Created != 0 {
 		n += 1 + sovMessage(uint64(m.Created))
 	}
+	l = len(m.Id)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.ContentType)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -461,16 +501,77 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Id = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ContentType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ContentType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMessage(dAtA[iNdEx:])
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthMessage
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthMessage
 			}
 			if (iNdEx + skippy) > l {
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
-	"github.com/gogo/protobuf/proto"
 	log "github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/pkg/messaging"
@@ -72,12 +71,14 @@ func (sub subscriber) Unsubscribe(topic string) error {
 	return nil
 }
 
+// mqttHandler passes the received payloads as they are, since the publisher
+// publishes the raw payloads too. The MQTT topic is kept as the subtopic.
 func (sub subscriber) mqttHandler(h messaging.MessageHandler) mqtt.MessageHandler {
 	return func(c mqtt.Client, m mqtt.Message) {
-		var msg messaging.Message
-		if err := proto.Unmarshal(m.Payload(), &msg); err != nil {
-			sub.logger.Warn(fmt.Sprintf("Failed to unmarshal received message: %s", err))
-			return
+		msg := messaging.Message{
+			Subtopic: m.Topic(),
+			Payload:  m.Payload(),
+			Created:  time.Now().UnixNano(),
 		}
 		if err := h(msg); err != nil {
 			sub.logger.Warn(fmt.Sprintf("Failed to handle Mainflux message: %s", err))
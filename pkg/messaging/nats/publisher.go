@@ -54,5 +54,8 @@ func (pub *publisher) Publish(topic string, msg messaging.Message) error {
 }
 
 func (pub *publisher) Close() {
+	// Make sure that the pending messages are
+	// processed by the server before closing.
+	pub.conn.Flush()
 	pub.conn.Close()
 }
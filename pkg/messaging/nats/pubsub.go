@@ -24,6 +24,8 @@ var (
 	errAlreadySubscribed = errors.New("already subscribed to topic")
 	errNotSubscribed     = errors.New("not subscribed")
 	errEmptyTopic        = errors.New("empty topic")
+	errInvalidSub        = errors.New("invalid subscription")
+	errNotConnected      = errors.New("not connected")
 )
 
 var _ messaging.PubSub = (*pubsub)(nil)
@@ -32,6 +34,15 @@ var _ messaging.PubSub = (*pubsub)(nil)
 // Close() method for NATS connection.
 type PubSub interface {
 	messaging.PubSub
+
+	// Status returns an error if NATS connection is not established or
+	// any of the subscriptions is no longer valid.
+	Status() error
+
+	// Pending returns the number of the received messages which are not
+	// handled yet, across all the subscriptions.
+	Pending() int
+
 	Close()
 }
 
@@ -128,6 +139,35 @@ func (ps *pubsub) Unsubscribe(topic string) error {
 	return nil
 }
 
+func (ps *pubsub) Status() error {
+	if !ps.conn.IsConnected() {
+		return errNotConnected
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	for topic, sub := range ps.subscriptions {
+		if !sub.IsValid() {
+			return fmt.Errorf("%s: %s", errInvalidSub, topic)
+		}
+	}
+	return nil
+}
+
+func (ps *pubsub) Pending() int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	var pending int
+	for _, sub := range ps.subscriptions {
+		// Closed subscriptions return an error and no pending messages.
+		msgs, _, _ := sub.Pending()
+		pending += msgs
+	}
+	return pending
+}
+
 func (ps *pubsub) Close() {
 	ps.conn.Close()
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,6 +25,25 @@ var (
 	data    = []byte("payload")
 )
 
+func TestStatus(t *testing.T) {
+	ps, ok := pubsub.(nats.PubSub)
+	require.True(t, ok, "expected NATS PubSub")
+
+	err := ps.Subscribe(fmt.Sprintf("%s.%s.status", chansPrefix, topic), handler)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	err = ps.Status()
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+}
+
+func TestPending(t *testing.T) {
+	ps, ok := pubsub.(nats.PubSub)
+	require.True(t, ok, "expected NATS PubSub")
+
+	pending := ps.Pending()
+	assert.Equal(t, 0, pending, fmt.Sprintf("expected no pending messages got %d\n", pending))
+}
+
 func TestPubsub(t *testing.T) {
 	err := pubsub.Subscribe(fmt.Sprintf("%s.%s", chansPrefix, topic), handler)
 	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
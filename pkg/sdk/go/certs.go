@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
 )
 
 const certsEndpoint = "certs"
@@ -17,6 +21,25 @@ type Cert struct {
 	ClientCert string `json:"client_cert,omitempty"`
 }
 
+// IssuedCert represents the issued certificate listed by certs service.
+type IssuedCert struct {
+	ThingID        string     `json:"thing_id"`
+	Serial         string     `json:"cert_serial"`
+	Expire         time.Time  `json:"expire"`
+	Revoked        bool       `json:"revoked"`
+	RevocationTime *time.Time `json:"revocation_time,omitempty"`
+}
+
+// CertsFilter filters the listed certificates. Empty thing ID and zero
+// expiry bounds don't filter the certificates. Status is one of "active",
+// "revoked" or "all", the active ones being listed by default.
+type CertsFilter struct {
+	ThingID       string
+	ExpiresAfter  time.Time
+	ExpiresBefore time.Time
+	Status        string
+}
+
 func (sdk mfSDK) IssueCert(thingID string, keyBits int, keyType, valid, token string) (Cert, error) {
 	var c Cert
 	r := certReq{
@@ -67,6 +90,54 @@ func (sdk mfSDK) RemoveCert(id, token string) error {
 	}
 }
 
+func (sdk mfSDK) ListCerts(filter CertsFilter, offset, limit uint64, token string) (CertsPage, error) {
+	query := url.Values{}
+	query.Set("offset", fmt.Sprint(offset))
+	query.Set("limit", fmt.Sprint(limit))
+	if filter.ThingID != "" {
+		query.Set("thing_id", filter.ThingID)
+	}
+	if !filter.ExpiresAfter.IsZero() {
+		query.Set("expires_after", filter.ExpiresAfter.Format(time.RFC3339))
+	}
+	if !filter.ExpiresBefore.IsZero() {
+		query.Set("expires_before", filter.ExpiresBefore.Format(time.RFC3339))
+	}
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+
+	endpoint := fmt.Sprintf("%s?%s", certsEndpoint, query.Encode())
+	reqURL := createURL(sdk.certsURL, sdk.certsPrefix, endpoint)
+
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return CertsPage{}, err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return CertsPage{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return CertsPage{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return CertsPage{}, errors.Wrap(ErrFailedFetch, errors.New(resp.Status))
+	}
+
+	var cp CertsPage
+	if err := json.Unmarshal(body, &cp); err != nil {
+		return CertsPage{}, err
+	}
+
+	return cp, nil
+}
+
 func (sdk mfSDK) RevokeCert(thingID, certID string, token string) error {
 	panic("not implemented")
 }
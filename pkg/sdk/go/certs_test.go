@@ -0,0 +1,172 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/certs"
+	certsapi "github.com/mainflux/mainflux/certs/api"
+	certsmocks "github.com/mainflux/mainflux/certs/mocks"
+	"github.com/mainflux/mainflux/certs/pki"
+	"github.com/mainflux/mainflux/pkg/errors"
+	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	caPath       = "../../../docker/ssl/certs/ca.crt"
+	caKeyPath    = "../../../docker/ssl/certs/ca.key"
+	certsRSABits = 2048
+	certsNum     = 5
+)
+
+func newCertsService(tokens map[string]string, thingsURL string) (certs.Service, error) {
+	tlsCert, err := tls.LoadX509KeyPair(caPath, caKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	caCert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	config := certs.Config{
+		SignTLSCert:    tlsCert,
+		SignX509Cert:   caCert,
+		SignRSABits:    certsRSABits,
+		SignHoursValid: "24h",
+	}
+	agent := pki.NewLocalAgent(tlsCert, caCert, certsRSABits, config.SignHoursValid)
+	mfsdk := sdk.NewSDK(sdk.Config{BaseURL: thingsURL})
+
+	return certs.New(mocks.NewAuthService(tokens), certsmocks.NewCertsRepository(), mfsdk, config, agent, nil), nil
+}
+
+func TestListCerts(t *testing.T) {
+	tokens := map[string]string{token: email}
+	thingsSvc := newThingsService(tokens)
+	ts := newThingsServer(thingsSvc)
+	defer ts.Close()
+
+	ths, err := thingsSvc.CreateThings(context.Background(), token, things.Thing{Name: "thing"})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	thingID := ths[0].ID
+
+	svc, err := newCertsService(tokens, ts.URL)
+	require.Nil(t, err, fmt.Sprintf("unexpected certs service creation error: %s", err))
+	cs := httptest.NewServer(certsapi.MakeHandler(svc))
+	defer cs.Close()
+
+	for i := 0; i < certsNum; i++ {
+		_, err := svc.IssueCert(context.Background(), token, thingID, "1h", 0, "ec")
+		require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s", err))
+	}
+	_, err = svc.IssueCert(context.Background(), token, thingID, "", 0, "ec")
+	require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s", err))
+	_, err = svc.RevokeCert(context.Background(), token, thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert revocation error: %s", err))
+
+	mainfluxSDK := sdk.NewSDK(sdk.Config{CertsURL: cs.URL})
+	window := time.Now().Add(2 * time.Hour)
+
+	cases := []struct {
+		desc    string
+		filter  sdk.CertsFilter
+		token   string
+		offset  uint64
+		limit   uint64
+		size    int
+		revoked int
+		err     error
+	}{
+		{
+			desc:   "list active certs",
+			filter: sdk.CertsFilter{},
+			token:  token,
+			offset: 0,
+			limit:  10,
+			size:   certsNum,
+			err:    nil,
+		},
+		{
+			desc:   "list active certs of thing",
+			filter: sdk.CertsFilter{ThingID: thingID},
+			token:  token,
+			offset: 1,
+			limit:  10,
+			size:   certsNum - 1,
+			err:    nil,
+		},
+		{
+			desc:    "list revoked certs",
+			filter:  sdk.CertsFilter{Status: certs.RevokedStatus},
+			token:   token,
+			offset:  0,
+			limit:   10,
+			size:    1,
+			revoked: 1,
+			err:     nil,
+		},
+		{
+			desc:    "list certs expiring after given time",
+			filter:  sdk.CertsFilter{ExpiresAfter: window, Status: certs.AllStatus},
+			token:   token,
+			offset:  0,
+			limit:   10,
+			size:    1,
+			revoked: 1,
+			err:     nil,
+		},
+		{
+			desc:   "list certs expiring before given time",
+			filter: sdk.CertsFilter{ExpiresBefore: window, Status: certs.AllStatus},
+			token:  token,
+			offset: 0,
+			limit:  10,
+			size:   certsNum,
+			err:    nil,
+		},
+		{
+			desc:   "list certs with invalid status",
+			filter: sdk.CertsFilter{Status: wrongValue},
+			token:  token,
+			offset: 0,
+			limit:  10,
+			size:   0,
+			err:    sdk.ErrFailedFetch,
+		},
+		{
+			desc:   "list certs with empty expiry window",
+			filter: sdk.CertsFilter{ExpiresAfter: window, ExpiresBefore: window},
+			token:  token,
+			offset: 0,
+			limit:  10,
+			size:   0,
+			err:    sdk.ErrFailedFetch,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := mainfluxSDK.ListCerts(tc.filter, tc.offset, tc.limit, tc.token)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.size, len(page.Certs), fmt.Sprintf("%s: expected %d certs, got %d", tc.desc, tc.size, len(page.Certs)))
+		revoked := 0
+		for _, c := range page.Certs {
+			if c.Revoked {
+				revoked++
+			}
+		}
+		assert.Equal(t, tc.revoked, revoked, fmt.Sprintf("%s: expected %d revoked certs, got %d", tc.desc, tc.revoked, revoked))
+	}
+}
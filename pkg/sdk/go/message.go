@@ -80,7 +80,7 @@ func (sdk mfSDK) ReadMessages(chanName, token string) (MessagesPage, error) {
 }
 
 func (sdk mfSDK) SetContentType(ct ContentType) error {
-	if ct != CTJSON && ct != CTJSONSenML && ct != CTBinary {
+	if ct != CTJSON && ct != CTJSONSenML && ct != CTCBORSenML && ct != CTBinary {
 		return ErrInvalidContentType
 	}
 
@@ -14,13 +14,14 @@ import (
 	"github.com/mainflux/mainflux/http/api"
 	"github.com/mainflux/mainflux/http/mocks"
 	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/pkg/uuid"
 	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/assert"
 )
 
 func newMessageService(cc mainflux.ThingsServiceClient) adapter.Service {
-	pub := mocks.NewPublisher()
-	return adapter.New(pub, cc)
+	pubsub := mocks.NewPubSub()
+	return adapter.New(pubsub, cc, uuid.NewMock(), nil, false)
 }
 
 func newMessageServer(svc adapter.Service) *httptest.Server {
@@ -14,3 +14,9 @@ type ConnectionIDs struct {
 	ChannelIDs []string `json:"channel_ids"`
 	ThingIDs   []string `json:"thing_ids"`
 }
+
+type twinReq struct {
+	Name       string                 `json:"name,omitempty"`
+	Definition Definition             `json:"definition,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
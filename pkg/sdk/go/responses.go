@@ -41,6 +41,25 @@ type MessagesPage struct {
 	pageRes
 }
 
+// TwinsPage contains list of twins in a page with proper metadata.
+type TwinsPage struct {
+	Twins []Twin `json:"twins"`
+	pageRes
+}
+
+// StatesPage contains list of twin states in a page with proper metadata.
+type StatesPage struct {
+	States []State `json:"states"`
+	pageRes
+}
+
+// CertsPage contains list of issued certificates in a page with proper
+// metadata.
+type CertsPage struct {
+	Certs []IssuedCert `json:"certs"`
+	pageRes
+}
+
 type GroupsPage struct {
 	Groups []Group `json:"groups"`
 	pageRes
@@ -19,6 +19,9 @@ const (
 	// CTJSONSenML represents JSON SenML content type.
 	CTJSONSenML ContentType = "application/senml+json"
 
+	// CTCBORSenML represents CBOR SenML content type.
+	CTCBORSenML ContentType = "application/senml+cbor"
+
 	// CTBinary represents binary content type.
 	CTBinary ContentType = "application/octet-stream"
 )
@@ -253,8 +256,30 @@ type SDK interface {
 	// RemoveCert removes a certificate
 	RemoveCert(id, token string) error
 
+	// ListCerts lists the issued certificates matching the filter.
+	ListCerts(filter CertsFilter, offset, limit uint64, token string) (CertsPage, error)
+
 	// RevokeCert revokes certificate with certID for thing with thingID
 	RevokeCert(thingID, certID, token string) error
+
+	// CreateTwin creates new twin with the given definition and returns its id.
+	CreateTwin(twin Twin, def Definition, token string) (string, error)
+
+	// UpdateTwin updates existing twin. Non-empty definition is appended
+	// as the new twin definition.
+	UpdateTwin(twin Twin, def Definition, token string) error
+
+	// Twin returns twin object by id.
+	Twin(id, token string) (Twin, error)
+
+	// ListTwins returns page of twins.
+	ListTwins(token string, offset, limit uint64, name string) (TwinsPage, error)
+
+	// DeleteTwin removes existing twin.
+	DeleteTwin(id, token string) error
+
+	// TwinStates returns page of states of the twin with specified id.
+	TwinStates(token, twinID string, offset, limit uint64) (StatesPage, error)
 }
 
 type mfSDK struct {
@@ -262,6 +287,7 @@ type mfSDK struct {
 	readerURL         string
 	bootstrapURL      string
 	certsURL          string
+	twinsURL          string
 	readerPrefix      string
 	usersPrefix       string
 	groupsPrefix      string
@@ -270,6 +296,7 @@ type mfSDK struct {
 	channelsPrefix    string
 	httpAdapterPrefix string
 	bootstrapPrefix   string
+	twinsPrefix       string
 	msgContentType    ContentType
 	client            *http.Client
 }
@@ -280,12 +307,14 @@ type Config struct {
 	ReaderURL         string
 	BootstrapURL      string
 	CertsURL          string
+	TwinsURL          string
 	ReaderPrefix      string
 	UsersPrefix       string
 	GroupsPrefix      string
 	ThingsPrefix      string
 	HTTPAdapterPrefix string
 	BootstrapPrefix   string
+	TwinsPrefix       string
 	MsgContentType    ContentType
 	TLSVerification   bool
 }
@@ -297,12 +326,14 @@ func NewSDK(conf Config) SDK {
 		readerURL:         conf.ReaderURL,
 		bootstrapURL:      conf.BootstrapURL,
 		certsURL:          conf.CertsURL,
+		twinsURL:          conf.TwinsURL,
 		readerPrefix:      conf.ReaderPrefix,
 		usersPrefix:       conf.UsersPrefix,
 		groupsPrefix:      conf.GroupsPrefix,
 		thingsPrefix:      conf.ThingsPrefix,
 		httpAdapterPrefix: conf.HTTPAdapterPrefix,
 		bootstrapPrefix:   conf.BootstrapPrefix,
+		twinsPrefix:       conf.TwinsPrefix,
 		msgContentType:    conf.MsgContentType,
 		client: &http.Client{
 			Transport: &http.Transport{
@@ -0,0 +1,237 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const twinsEndpoint = "twins"
+const statesEndpoint = "states"
+
+// Attribute represents twin attribute, bound to the channel and subtopic
+// whose messages determine the twin's state.
+type Attribute struct {
+	Name         string `json:"name"`
+	Channel      string `json:"channel"`
+	Subtopic     string `json:"subtopic"`
+	PersistState bool   `json:"persist_state"`
+}
+
+// Definition represents twin definition, i.e. the set of twin attributes.
+type Definition struct {
+	ID         int         `json:"id"`
+	Created    time.Time   `json:"created"`
+	Attributes []Attribute `json:"attributes"`
+	Delta      int64       `json:"delta"`
+}
+
+// Twin represents mainflux digital twin.
+type Twin struct {
+	Owner       string                 `json:"owner,omitempty"`
+	ID          string                 `json:"id,omitempty"`
+	Name        string                 `json:"name,omitempty"`
+	Revision    int                    `json:"revision,omitempty"`
+	Created     time.Time              `json:"created,omitempty"`
+	Updated     time.Time              `json:"updated,omitempty"`
+	Definitions []Definition           `json:"definitions,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// State represents the twin state.
+type State struct {
+	TwinID     string                 `json:"twin_id"`
+	ID         int64                  `json:"id"`
+	Definition int                    `json:"definition"`
+	Created    time.Time              `json:"created"`
+	Payload    map[string]interface{} `json:"payload"`
+}
+
+func (sdk mfSDK) CreateTwin(twin Twin, def Definition, token string) (string, error) {
+	data, err := json.Marshal(twinReq{
+		Name:       twin.Name,
+		Definition: def,
+		Metadata:   twin.Metadata,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	url := createURL(sdk.twinsURL, sdk.twinsPrefix, twinsEndpoint)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", errors.Wrap(ErrFailedCreation, errors.New(resp.Status))
+	}
+
+	id := strings.TrimPrefix(resp.Header.Get("Location"), fmt.Sprintf("/%s/", twinsEndpoint))
+	return id, nil
+}
+
+func (sdk mfSDK) UpdateTwin(twin Twin, def Definition, token string) error {
+	data, err := json.Marshal(twinReq{
+		Name:       twin.Name,
+		Definition: def,
+		Metadata:   twin.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/%s", twinsEndpoint, twin.ID)
+	url := createURL(sdk.twinsURL, sdk.twinsPrefix, endpoint)
+
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Wrap(ErrFailedUpdate, errors.New(resp.Status))
+	}
+
+	return nil
+}
+
+func (sdk mfSDK) Twin(id, token string) (Twin, error) {
+	endpoint := fmt.Sprintf("%s/%s", twinsEndpoint, id)
+	url := createURL(sdk.twinsURL, sdk.twinsPrefix, endpoint)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return Twin{}, err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return Twin{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Twin{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return Twin{}, errors.Wrap(ErrFailedFetch, errors.New(resp.Status))
+	}
+
+	var t Twin
+	if err := json.Unmarshal(body, &t); err != nil {
+		return Twin{}, err
+	}
+
+	return t, nil
+}
+
+func (sdk mfSDK) ListTwins(token string, offset, limit uint64, name string) (TwinsPage, error) {
+	endpoint := fmt.Sprintf("%s?offset=%d&limit=%d&name=%s", twinsEndpoint, offset, limit, name)
+	url := createURL(sdk.twinsURL, sdk.twinsPrefix, endpoint)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return TwinsPage{}, err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return TwinsPage{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return TwinsPage{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return TwinsPage{}, errors.Wrap(ErrFailedFetch, errors.New(resp.Status))
+	}
+
+	var tp TwinsPage
+	if err := json.Unmarshal(body, &tp); err != nil {
+		return TwinsPage{}, err
+	}
+
+	return tp, nil
+}
+
+func (sdk mfSDK) DeleteTwin(id, token string) error {
+	endpoint := fmt.Sprintf("%s/%s", twinsEndpoint, id)
+	url := createURL(sdk.twinsURL, sdk.twinsPrefix, endpoint)
+
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return errors.Wrap(ErrFailedRemoval, errors.New(resp.Status))
+	}
+
+	return nil
+}
+
+func (sdk mfSDK) TwinStates(token, twinID string, offset, limit uint64) (StatesPage, error) {
+	endpoint := fmt.Sprintf("%s/%s?offset=%d&limit=%d", statesEndpoint, twinID, offset, limit)
+	url := createURL(sdk.twinsURL, sdk.twinsPrefix, endpoint)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return StatesPage{}, err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return StatesPage{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return StatesPage{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return StatesPage{}, errors.Wrap(ErrFailedFetch, errors.New(resp.Status))
+	}
+
+	var sp StatesPage
+	if err := json.Unmarshal(body, &sp); err != nil {
+		return StatesPage{}, err
+	}
+
+	return sp, nil
+}
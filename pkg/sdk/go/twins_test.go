@@ -0,0 +1,308 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/twins"
+	twhttpapi "github.com/mainflux/mainflux/twins/api/http"
+	twmocks "github.com/mainflux/mainflux/twins/mocks"
+	"github.com/mainflux/senml"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTwinsServer(svc twins.Service) *httptest.Server {
+	mux := twhttpapi.MakeHandler(mocktracer.New(), svc)
+	return httptest.NewServer(mux)
+}
+
+func newTwinsSDK(url string) sdk.SDK {
+	return sdk.NewSDK(sdk.Config{
+		TwinsURL:        url,
+		MsgContentType:  contentType,
+		TLSVerification: false,
+	})
+}
+
+func TestCreateTwin(t *testing.T) {
+	svc := twmocks.NewService(map[string]string{token: email})
+	ts := newTwinsServer(svc)
+	defer ts.Close()
+	mainfluxSDK := newTwinsSDK(ts.URL)
+
+	def := sdk.Definition{
+		Attributes: []sdk.Attribute{{Name: "temperature", Channel: "chanID", Subtopic: "engine"}},
+	}
+
+	cases := []struct {
+		desc  string
+		twin  sdk.Twin
+		def   sdk.Definition
+		token string
+		err   error
+	}{
+		{
+			desc:  "create new twin",
+			twin:  sdk.Twin{Name: "twin", Metadata: metadata},
+			def:   def,
+			token: token,
+			err:   nil,
+		},
+		{
+			desc:  "create new twin with invalid token",
+			twin:  sdk.Twin{Name: "twin"},
+			def:   def,
+			token: wrongValue,
+			err:   createError(sdk.ErrFailedCreation, http.StatusForbidden),
+		},
+		{
+			desc:  "create new twin with empty token",
+			twin:  sdk.Twin{Name: "twin"},
+			def:   def,
+			token: "",
+			err:   createError(sdk.ErrFailedCreation, http.StatusForbidden),
+		},
+	}
+
+	for _, tc := range cases {
+		id, err := mainfluxSDK.CreateTwin(tc.twin, tc.def, tc.token)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+
+		tw, err := mainfluxSDK.Twin(id, tc.token)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, tc.twin.Name, tw.Name, fmt.Sprintf("%s: expected name %s got %s", tc.desc, tc.twin.Name, tw.Name))
+		require.Len(t, tw.Definitions, 1, fmt.Sprintf("%s: expected one definition", tc.desc))
+		assert.Equal(t, tc.def.Attributes, tw.Definitions[0].Attributes, fmt.Sprintf("%s: expected attributes %v got %v", tc.desc, tc.def.Attributes, tw.Definitions[0].Attributes))
+	}
+}
+
+func TestUpdateTwin(t *testing.T) {
+	svc := twmocks.NewService(map[string]string{token: email})
+	ts := newTwinsServer(svc)
+	defer ts.Close()
+	mainfluxSDK := newTwinsSDK(ts.URL)
+
+	id, err := mainfluxSDK.CreateTwin(sdk.Twin{Name: "twin"}, sdk.Definition{}, token)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc  string
+		twin  sdk.Twin
+		token string
+		err   error
+	}{
+		{
+			desc:  "update existing twin",
+			twin:  sdk.Twin{ID: id, Name: "updated", Metadata: metadata2},
+			token: token,
+			err:   nil,
+		},
+		{
+			desc:  "update non-existing twin",
+			twin:  sdk.Twin{ID: badID, Name: "updated"},
+			token: token,
+			err:   createError(sdk.ErrFailedUpdate, http.StatusNotFound),
+		},
+		{
+			desc:  "update twin with invalid token",
+			twin:  sdk.Twin{ID: id, Name: "updated"},
+			token: wrongValue,
+			err:   createError(sdk.ErrFailedUpdate, http.StatusForbidden),
+		},
+	}
+
+	for _, tc := range cases {
+		err := mainfluxSDK.UpdateTwin(tc.twin, sdk.Definition{}, tc.token)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+	}
+}
+
+func TestListTwins(t *testing.T) {
+	svc := twmocks.NewService(map[string]string{token: email})
+	ts := newTwinsServer(svc)
+	defer ts.Close()
+	mainfluxSDK := newTwinsSDK(ts.URL)
+
+	n := 10
+	for i := 0; i < n; i++ {
+		_, err := mainfluxSDK.CreateTwin(sdk.Twin{Name: fmt.Sprintf("twin_%d", i)}, sdk.Definition{}, token)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+
+	cases := []struct {
+		desc   string
+		token  string
+		offset uint64
+		limit  uint64
+		name   string
+		size   int
+		err    error
+	}{
+		{
+			desc:   "list all twins",
+			token:  token,
+			offset: 0,
+			limit:  uint64(n),
+			size:   n,
+			err:    nil,
+		},
+		{
+			desc:   "list twins with offset",
+			token:  token,
+			offset: 5,
+			limit:  uint64(n),
+			size:   n - 5,
+			err:    nil,
+		},
+		{
+			desc:   "list twins filtered by name",
+			token:  token,
+			offset: 0,
+			limit:  uint64(n),
+			name:   "twin_3",
+			size:   1,
+			err:    nil,
+		},
+		{
+			desc:   "list twins with invalid token",
+			token:  wrongValue,
+			offset: 0,
+			limit:  uint64(n),
+			size:   0,
+			err:    createError(sdk.ErrFailedFetch, http.StatusForbidden),
+		},
+		{
+			desc:   "list twins with zero limit",
+			token:  token,
+			offset: 0,
+			limit:  0,
+			size:   0,
+			err:    createError(sdk.ErrFailedFetch, http.StatusBadRequest),
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := mainfluxSDK.ListTwins(tc.token, tc.offset, tc.limit, tc.name)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+		assert.Len(t, page.Twins, tc.size, fmt.Sprintf("%s: expected %d twins got %d", tc.desc, tc.size, len(page.Twins)))
+	}
+}
+
+func TestDeleteTwin(t *testing.T) {
+	svc := twmocks.NewService(map[string]string{token: email})
+	ts := newTwinsServer(svc)
+	defer ts.Close()
+	mainfluxSDK := newTwinsSDK(ts.URL)
+
+	id, err := mainfluxSDK.CreateTwin(sdk.Twin{Name: "twin"}, sdk.Definition{}, token)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc  string
+		id    string
+		token string
+		err   error
+	}{
+		{
+			desc:  "delete twin with invalid token",
+			id:    id,
+			token: wrongValue,
+			err:   createError(sdk.ErrFailedRemoval, http.StatusForbidden),
+		},
+		{
+			desc:  "delete existing twin",
+			id:    id,
+			token: token,
+			err:   nil,
+		},
+		{
+			desc:  "delete deleted twin",
+			id:    id,
+			token: token,
+			err:   nil,
+		},
+	}
+
+	for _, tc := range cases {
+		err := mainfluxSDK.DeleteTwin(tc.id, tc.token)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+	}
+}
+
+func TestTwinStates(t *testing.T) {
+	svc := twmocks.NewService(map[string]string{token: email})
+	ts := newTwinsServer(svc)
+	defer ts.Close()
+	mainfluxSDK := newTwinsSDK(ts.URL)
+
+	attr := twins.Attribute{Name: "temperature", Channel: "chanID_1", Subtopic: "engine", PersistState: true}
+	def := sdk.Definition{
+		Attributes: []sdk.Attribute{{Name: attr.Name, Channel: attr.Channel, Subtopic: attr.Subtopic, PersistState: attr.PersistState}},
+	}
+	id, err := mainfluxSDK.CreateTwin(sdk.Twin{Name: "twin"}, def, token)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	n := 5
+	for i := 0; i < n; i++ {
+		val := float64(i)
+		msg, err := twmocks.CreateMessage(attr, []senml.Record{{Name: "temperature", Value: &val, Time: float64(i + 1)}})
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		err = svc.SaveStates(msg)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+
+	cases := []struct {
+		desc   string
+		twinID string
+		token  string
+		offset uint64
+		limit  uint64
+		size   int
+		err    error
+	}{
+		{
+			desc:   "list all states",
+			twinID: id,
+			token:  token,
+			offset: 0,
+			limit:  10,
+			size:   n,
+			err:    nil,
+		},
+		{
+			desc:   "list states with offset",
+			twinID: id,
+			token:  token,
+			offset: 2,
+			limit:  10,
+			size:   n - 2,
+			err:    nil,
+		},
+		{
+			desc:   "list states with invalid token",
+			twinID: id,
+			token:  wrongValue,
+			offset: 0,
+			limit:  10,
+			size:   0,
+			err:    createError(sdk.ErrFailedFetch, http.StatusForbidden),
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := mainfluxSDK.TwinStates(tc.token, tc.twinID, tc.offset, tc.limit)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+		assert.Len(t, page.States, tc.size, fmt.Sprintf("%s: expected %d states got %d", tc.desc, tc.size, len(page.States)))
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const registryContentType = "application/vnd.schemaregistry.v1+json"
+
+var (
+	// ErrNotFound indicates that the schema or the subject doesn't exist in
+	// the schema registry.
+	ErrNotFound = errors.New("schema not found")
+
+	// ErrRegistry indicates that the schema registry request failed.
+	ErrRegistry = errors.New("schema registry request failed")
+)
+
+// Registry specifies the API of the Confluent compatible schema registry.
+type Registry interface {
+	// Schema returns the schema with the given ID.
+	Schema(id int) (string, error)
+
+	// Latest returns the ID and the schema of the latest version of the
+	// subject.
+	Latest(subject string) (int, string, error)
+}
+
+var _ Registry = (*registry)(nil)
+
+type registry struct {
+	url    string
+	client *http.Client
+}
+
+type schemaRes struct {
+	ID     int    `json:"id"`
+	Schema string `json:"schema"`
+}
+
+// NewRegistry returns the client of the Confluent compatible schema registry
+// on the given URL. Basic authentication credentials can be set as the URL
+// user info.
+func NewRegistry(registryURL string, timeout time.Duration) Registry {
+	return &registry{
+		url:    strings.TrimSuffix(registryURL, "/"),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (r *registry) Schema(id int) (string, error) {
+	res, err := r.get(fmt.Sprintf("/schemas/ids/%d", id))
+	if err != nil {
+		return "", err
+	}
+
+	return res.Schema, nil
+}
+
+func (r *registry) Latest(subject string) (int, string, error) {
+	res, err := r.get(fmt.Sprintf("/subjects/%s/versions/latest", url.PathEscape(subject)))
+	if err != nil {
+		return 0, "", err
+	}
+
+	return res.ID, res.Schema, nil
+}
+
+func (r *registry) get(path string) (schemaRes, error) {
+	req, err := http.NewRequest(http.MethodGet, r.url+path, nil)
+	if err != nil {
+		return schemaRes{}, errors.Wrap(ErrRegistry, err)
+	}
+	req.Header.Set("Accept", registryContentType)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return schemaRes{}, errors.Wrap(ErrRegistry, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return schemaRes{}, ErrNotFound
+	default:
+		return schemaRes{}, errors.Wrap(ErrRegistry, errors.New(resp.Status))
+	}
+
+	var res schemaRes
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return schemaRes{}, errors.Wrap(ErrRegistry, err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,239 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package avro
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// schema contains the compiled Avro schema along with the parsed schema
+// specification, which is used to convert the decoded values to the values
+// they would have if they were decoded from JSON.
+type schema struct {
+	codec *goavro.Codec
+	spec  interface{}
+	name  string
+	// names maps the full and the short names of the named types to their
+	// specifications.
+	names map[string]map[string]interface{}
+}
+
+func newSchema(spec string) (*schema, error) {
+	codec, err := goavro.NewCodec(spec)
+	if err != nil {
+		return nil, errors.Wrap(errMalformedSchema, err)
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(spec), &parsed); err != nil {
+		return nil, errors.Wrap(errMalformedSchema, err)
+	}
+
+	s := &schema{
+		codec: codec,
+		spec:  parsed,
+		names: make(map[string]map[string]interface{}),
+	}
+	s.resolve(parsed, "")
+	if m, ok := parsed.(map[string]interface{}); ok {
+		if name, ok := m["name"].(string); ok {
+			s.name = name[strings.LastIndex(name, ".")+1:]
+		}
+	}
+
+	return s, nil
+}
+
+// resolve replaces the names of the named types with their full names, as
+// used by the decoder to identify the union branches, and registers them.
+func (s *schema) resolve(spec interface{}, namespace string) {
+	switch sp := spec.(type) {
+	case []interface{}:
+		for _, b := range sp {
+			s.resolve(b, namespace)
+		}
+	case map[string]interface{}:
+		if name, ok := sp["name"].(string); ok && isNamed(sp["type"]) {
+			if ns, ok := sp["namespace"].(string); ok && !strings.Contains(name, ".") {
+				namespace = ns
+			}
+			full := name
+			switch {
+			case strings.Contains(name, "."):
+				namespace = name[:strings.LastIndex(name, ".")]
+			case namespace != "":
+				full = namespace + "." + name
+			}
+			sp["name"] = full
+			s.names[full] = sp
+			s.names[full[strings.LastIndex(full, ".")+1:]] = sp
+		}
+		if fields, ok := sp["fields"].([]interface{}); ok {
+			for _, f := range fields {
+				if fm, ok := f.(map[string]interface{}); ok {
+					s.resolve(fm["type"], namespace)
+				}
+			}
+		}
+		for _, key := range []string{"type", "items", "values"} {
+			if _, ok := sp[key].(string); !ok {
+				s.resolve(sp[key], namespace)
+			}
+		}
+	}
+}
+
+func isNamed(typ interface{}) bool {
+	switch typ {
+	case "record", "error", "enum", "fixed":
+		return true
+	default:
+		return false
+	}
+}
+
+// value converts the decoded value of the given type, unwrapping the union
+// values and converting the numbers, bytes and logical types.
+func (s *schema) value(spec interface{}, v interface{}) interface{} {
+	switch sp := spec.(type) {
+	case []interface{}:
+		m, ok := v.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			return scalar(v)
+		}
+		for branch, bv := range m {
+			return s.value(s.branch(sp, branch), bv)
+		}
+	case map[string]interface{}:
+		switch sp["type"] {
+		case "record", "error":
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return scalar(v)
+			}
+			ret := make(map[string]interface{}, len(m))
+			fields, _ := sp["fields"].([]interface{})
+			for _, f := range fields {
+				fm, _ := f.(map[string]interface{})
+				name, _ := fm["name"].(string)
+				if fv, ok := m[name]; ok {
+					ret[name] = s.value(fm["type"], fv)
+				}
+			}
+			return ret
+		case "array":
+			l, ok := v.([]interface{})
+			if !ok {
+				return scalar(v)
+			}
+			ret := make([]interface{}, len(l))
+			for i, e := range l {
+				ret[i] = s.value(sp["items"], e)
+			}
+			return ret
+		case "map":
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return scalar(v)
+			}
+			ret := make(map[string]interface{}, len(m))
+			for k, e := range m {
+				ret[k] = s.value(sp["values"], e)
+			}
+			return ret
+		case "enum", "fixed":
+			return scalar(v)
+		default:
+			return s.value(sp["type"], v)
+		}
+	case string:
+		if named, ok := s.names[sp]; ok {
+			return s.value(named, v)
+		}
+	}
+
+	return scalar(v)
+}
+
+// branch returns the union branch with the given name, as set by the
+// decoder: the full name of the named types, the name of the primitive
+// types, optionally followed by the logical type, or the complex type name.
+func (s *schema) branch(union []interface{}, name string) interface{} {
+	for _, b := range union {
+		if branchName(s.resolveName(b)) == name {
+			return b
+		}
+	}
+	return nil
+}
+
+func (s *schema) resolveName(spec interface{}) interface{} {
+	if name, ok := spec.(string); ok {
+		if named, ok := s.names[name]; ok {
+			return named
+		}
+	}
+	return spec
+}
+
+func branchName(spec interface{}) string {
+	switch sp := spec.(type) {
+	case string:
+		return sp
+	case map[string]interface{}:
+		if isNamed(sp["type"]) {
+			name, _ := sp["name"].(string)
+			return name
+		}
+		typ, _ := sp["type"].(string)
+		if lt, ok := sp["logicalType"].(string); ok {
+			return typ + "." + lt
+		}
+		return typ
+	}
+	return ""
+}
+
+// scalar converts the value to the type the same value would have if it was
+// decoded from JSON, so the messages are stored the same way.
+func scalar(v interface{}) interface{} {
+	switch val := v.(type) {
+	case int32:
+		return float64(val)
+	case int64:
+		return float64(val)
+	case float32:
+		return float64(val)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(val)
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case time.Duration:
+		return val.Seconds()
+	case *big.Rat:
+		f, _ := val.Float64()
+		return f
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			ret[k] = scalar(e)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(val))
+		for i, e := range val {
+			ret[i] = scalar(e)
+		}
+		return ret
+	default:
+		return v
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package avro
+
+import (
+	"encoding/binary"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+)
+
+const (
+	// Confluent wire format consists of the magic byte and the schema ID
+	// preceding the Avro binary encoded data.
+	magicByte  = 0
+	headerSize = 5
+
+	// latestTTL is the time after which the latest version of the channel
+	// subject is fetched from the registry again.
+	latestTTL = time.Minute
+)
+
+var (
+	// ErrTransform represents an error during decoding of the Avro payload.
+	ErrTransform = errors.New("unable to decode avro message")
+
+	errMalformedSchema = errors.New("malformed avro schema")
+	errInvalidPayload  = errors.New("avro payload is not a record")
+)
+
+var _ transformers.Transformer = (*transformer)(nil)
+
+type latest struct {
+	id      int
+	fetched time.Time
+}
+
+type transformer struct {
+	registry Registry
+	payload  func(map[string]interface{}) (map[string]interface{}, error)
+	mu       sync.Mutex
+	schemas  map[int]*schema
+	latest   map[string]latest
+}
+
+// New returns a new Avro transformer which decodes the payloads into the JSON
+// messages, flattening nested records. Payloads in Confluent wire format are
+// decoded using the schema with the ID from the payload header, while the
+// other payloads are decoded using the latest schema of the subject named by
+// the message channel ID.
+func New(registry Registry) transformers.Transformer {
+	return &transformer{
+		registry: registry,
+		payload:  json.Flatten,
+		schemas:  make(map[int]*schema),
+		latest:   make(map[string]latest),
+	}
+}
+
+// NewNested returns a new Avro transformer which keeps nested records as
+// nested JSON objects. It's meant to be used by the consumers whose
+// underlying database natively supports nested documents.
+func NewNested(registry Registry) transformers.Transformer {
+	return &transformer{
+		registry: registry,
+		payload: func(m map[string]interface{}) (map[string]interface{}, error) {
+			return m, nil
+		},
+		schemas: make(map[int]*schema),
+		latest:  make(map[string]latest),
+	}
+}
+
+func (t *transformer) Transform(msg messaging.Message) (interface{}, error) {
+	data := msg.Payload
+	var s *schema
+	var err error
+	if len(data) >= headerSize && data[0] == magicByte {
+		s, err = t.schema(int(binary.BigEndian.Uint32(data[1:headerSize])))
+		data = data[headerSize:]
+	} else {
+		s, err = t.subjectSchema(msg.Channel)
+	}
+	if err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+
+	native, _, err := s.codec.NativeFromBinary(data)
+	if err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+
+	m, ok := s.value(s.spec, native).(map[string]interface{})
+	if !ok {
+		return nil, errors.Wrap(ErrTransform, errInvalidPayload)
+	}
+
+	pld, err := t.payload(m)
+	if err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+
+	// Use the last subtopic part as the message format, the same as
+	// JSON transformer does, falling back to the record name.
+	format := s.name
+	if msg.Subtopic != "" {
+		subs := strings.Split(msg.Subtopic, ".")
+		format = subs[len(subs)-1]
+	}
+
+	ret := json.Message{
+		ID:        msg.Id,
+		Channel:   msg.Channel,
+		Created:   msg.Created,
+		Subtopic:  msg.Subtopic,
+		Publisher: msg.Publisher,
+		Protocol:  msg.Protocol,
+		Payload:   pld,
+	}
+
+	return json.Messages{Data: []json.Message{ret}, Format: format}, nil
+}
+
+// schema returns the schema with the given ID. Since the registered schemas
+// are immutable, they are fetched and compiled only once.
+func (t *transformer) schema(id int) (*schema, error) {
+	t.mu.Lock()
+	s, ok := t.schemas[id]
+	t.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+
+	spec, err := t.registry.Schema(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.compile(id, spec)
+}
+
+// subjectSchema returns the latest schema of the channel subject.
+func (t *transformer) subjectSchema(channel string) (*schema, error) {
+	t.mu.Lock()
+	l, ok := t.latest[channel]
+	t.mu.Unlock()
+	if ok && time.Since(l.fetched) < latestTTL {
+		return t.schema(l.id)
+	}
+
+	id, spec, err := t.registry.Latest(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.latest[channel] = latest{id: id, fetched: time.Now()}
+	s, ok := t.schemas[id]
+	t.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+
+	return t.compile(id, spec)
+}
+
+func (t *transformer) compile(id int, spec string) (*schema, error) {
+	s, err := newSchema(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.schemas[id] = s
+	t.mu.Unlock()
+
+	return s, nil
+}
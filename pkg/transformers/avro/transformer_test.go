@@ -0,0 +1,204 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package avro_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/avro"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	channel  = "channel-1"
+	schemaID = 7
+	schema   = `{
+  "type": "record",
+  "name": "Telemetry",
+  "namespace": "telemetry",
+  "fields": [
+    {"name": "sensor", "type": "string"},
+    {"name": "temperature", "type": "double"},
+    {"name": "uptime", "type": "long"},
+    {"name": "alarm", "type": ["null", "boolean"]},
+    {"name": "loc", "type": ["null", {"type": "record", "name": "Location", "fields": [
+      {"name": "x", "type": "float"},
+      {"name": "y", "type": "float"}
+    ]}]},
+    {"name": "state", "type": {"type": "enum", "name": "State", "symbols": ["ON", "OFF"]}},
+    {"name": "readings", "type": {"type": "array", "items": "int"}}
+  ]
+}`
+)
+
+// newRegistry returns the server implementing the subset of the schema
+// registry API used by the transformer.
+func newRegistry() *httptest.Server {
+	schemaRes := fmt.Sprintf(`{"id": %d, "schema": %q}`, schemaID, schema)
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf("/schemas/ids/%d", schemaID), func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(schemaRes))
+	})
+	mux.HandleFunc(fmt.Sprintf("/subjects/%s/versions/latest", channel), func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(schemaRes))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestTransform(t *testing.T) {
+	ts := newRegistry()
+	defer ts.Close()
+	registry := avro.NewRegistry(ts.URL, time.Second)
+
+	codec, err := goavro.NewCodec(schema)
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating codec: %s", err))
+	data, err := codec.BinaryFromNative(nil, map[string]interface{}{
+		"sensor":      "temperature",
+		"temperature": 21.5,
+		"uptime":      int64(3600),
+		"alarm":       goavro.Union("boolean", true),
+		"loc":         goavro.Union("telemetry.Location", map[string]interface{}{"x": float32(1), "y": float32(2)}),
+		"state":       "ON",
+		"readings":    []interface{}{int32(4), int32(5)},
+	})
+	require.Nil(t, err, fmt.Sprintf("unexpected error encoding message: %s", err))
+
+	header := make([]byte, 5)
+	binary.BigEndian.PutUint32(header[1:], schemaID)
+
+	now := time.Now().UnixNano()
+	msg := messaging.Message{
+		Channel:   channel,
+		Publisher: "publisher-1",
+		Protocol:  "mqtt",
+		Payload:   data,
+		Created:   now,
+	}
+
+	wireMsg := msg
+	wireMsg.Channel = "channel-2"
+	wireMsg.Payload = append(header, data...)
+
+	formatMsg := msg
+	formatMsg.Subtopic = "home.telemetry"
+
+	unknownMsg := msg
+	unknownMsg.Channel = "channel-2"
+
+	unknownIDMsg := wireMsg
+	unknownIDMsg.Payload = append([]byte{0, 0, 0, 0, 8}, data...)
+
+	invalidMsg := msg
+	invalidMsg.Payload = []byte{0x02}
+
+	base := json.Message{
+		Channel:   channel,
+		Created:   now,
+		Publisher: "publisher-1",
+		Protocol:  "mqtt",
+	}
+	flat := base
+	flat.Payload = map[string]interface{}{
+		"sensor":      "temperature",
+		"temperature": 21.5,
+		"uptime":      float64(3600),
+		"alarm":       true,
+		"loc/x":       1.0,
+		"loc/y":       2.0,
+		"state":       "ON",
+		"readings":    []interface{}{float64(4), float64(5)},
+	}
+	nested := base
+	nested.Payload = map[string]interface{}{
+		"sensor":      "temperature",
+		"temperature": 21.5,
+		"uptime":      float64(3600),
+		"alarm":       true,
+		"loc": map[string]interface{}{
+			"x": 1.0,
+			"y": 2.0,
+		},
+		"state":    "ON",
+		"readings": []interface{}{float64(4), float64(5)},
+	}
+	wire := flat
+	wire.Channel = "channel-2"
+	formatted := flat
+	formatted.Subtopic = "home.telemetry"
+
+	cases := []struct {
+		desc string
+		tr   transformers.Transformer
+		msg  messaging.Message
+		res  interface{}
+		err  error
+	}{
+		{
+			desc: "transform avro message using channel subject",
+			tr:   avro.New(registry),
+			msg:  msg,
+			res:  json.Messages{Data: []json.Message{flat}, Format: "Telemetry"},
+			err:  nil,
+		},
+		{
+			desc: "transform avro message in wire format",
+			tr:   avro.New(registry),
+			msg:  wireMsg,
+			res:  json.Messages{Data: []json.Message{wire}, Format: "Telemetry"},
+			err:  nil,
+		},
+		{
+			desc: "transform avro message with format in subtopic",
+			tr:   avro.New(registry),
+			msg:  formatMsg,
+			res:  json.Messages{Data: []json.Message{formatted}, Format: "telemetry"},
+			err:  nil,
+		},
+		{
+			desc: "transform avro message keeping nested records",
+			tr:   avro.NewNested(registry),
+			msg:  msg,
+			res:  json.Messages{Data: []json.Message{nested}, Format: "Telemetry"},
+			err:  nil,
+		},
+		{
+			desc: "transform avro message of channel without subject",
+			tr:   avro.New(registry),
+			msg:  unknownMsg,
+			res:  nil,
+			err:  avro.ErrNotFound,
+		},
+		{
+			desc: "transform avro message with unknown schema ID",
+			tr:   avro.New(registry),
+			msg:  unknownIDMsg,
+			res:  nil,
+			err:  avro.ErrNotFound,
+		},
+		{
+			desc: "transform invalid avro message",
+			tr:   avro.New(registry),
+			msg:  invalidMsg,
+			res:  nil,
+			err:  avro.ErrTransform,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := tc.tr.Transform(tc.msg)
+		assert.Equal(t, tc.res, res, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.res, res))
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package json
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+var (
+	errMissingField = errors.New("missing mapped field")
+	errInvalidField = errors.New("invalid mapped field")
+)
+
+// Mapping contains the paths of the JSON payload fields which are mapped to
+// the SenML message fields. Paths consist of the object keys separated by
+// the `/`, the same as the keys of the flattened JSON objects.
+type Mapping struct {
+	// Name is the path of the message name. If it's empty, the value path
+	// is used as the name.
+	Name string
+
+	// Value is the path of the message value, which is mapped to the value,
+	// the boolean value or the string value, depending on its type.
+	Value string
+
+	// Time is the path of the message time, in seconds since the Unix epoch
+	// or in RFC3339 format. If it's empty, the time of the message reception
+	// is used.
+	Time string
+}
+
+// NewMapped returns a new JSON transformer which maps the fields of the JSON
+// payloads to SenML messages, so that the messages of the devices which don't
+// publish SenML are stored and read the same as SenML messages.
+func NewMapped(m Mapping) transformers.Transformer {
+	return funcTransformer(func(msg messaging.Message) (interface{}, error) {
+		var pld interface{}
+		if err := json.Unmarshal(msg.Payload, &pld); err != nil {
+			return nil, errors.Wrap(ErrTransform, err)
+		}
+
+		var objs []interface{}
+		switch p := pld.(type) {
+		case map[string]interface{}:
+			objs = []interface{}{p}
+		case []interface{}:
+			objs = p
+		default:
+			return nil, errors.Wrap(ErrTransform, errInvalidFormat)
+		}
+
+		msgs := make([]senml.Message, len(objs))
+		for i, o := range objs {
+			obj, ok := o.(map[string]interface{})
+			if !ok {
+				return nil, errors.Wrap(ErrTransform, errInvalidNestedJSON)
+			}
+			ret, err := m.transform(msg, obj)
+			if err != nil {
+				return nil, errors.Wrap(ErrTransform, err)
+			}
+			msgs[i] = ret
+		}
+
+		return msgs, nil
+	})
+}
+
+func (m Mapping) transform(msg messaging.Message, obj map[string]interface{}) (senml.Message, error) {
+	ret := senml.Message{
+		Channel:   msg.Channel,
+		Subtopic:  msg.Subtopic,
+		Publisher: msg.Publisher,
+		Protocol:  msg.Protocol,
+		Name:      m.Value,
+		// Convert the Unix timestamp in nanoseconds to float64.
+		Time: float64(msg.Created) / float64(1e9),
+	}
+
+	if m.Name != "" {
+		val, ok := lookup(obj, m.Name)
+		if !ok {
+			return senml.Message{}, errors.Wrap(errMissingField, errors.New(m.Name))
+		}
+		name, ok := val.(string)
+		if !ok {
+			return senml.Message{}, errors.Wrap(errInvalidField, errors.New(m.Name))
+		}
+		ret.Name = name
+	}
+
+	val, ok := lookup(obj, m.Value)
+	if !ok {
+		return senml.Message{}, errors.Wrap(errMissingField, errors.New(m.Value))
+	}
+	switch v := val.(type) {
+	case float64:
+		ret.Value = &v
+	case bool:
+		ret.BoolValue = &v
+	case string:
+		ret.StringValue = &v
+	default:
+		return senml.Message{}, errors.Wrap(errInvalidField, errors.New(m.Value))
+	}
+
+	if m.Time != "" {
+		val, ok := lookup(obj, m.Time)
+		if !ok {
+			return senml.Message{}, errors.Wrap(errMissingField, errors.New(m.Time))
+		}
+		switch v := val.(type) {
+		case float64:
+			ret.Time = v
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return senml.Message{}, errors.Wrap(errInvalidField, errors.New(m.Time))
+			}
+			ret.Time = float64(t.UnixNano()) / float64(1e9)
+		default:
+			return senml.Message{}, errors.Wrap(errInvalidField, errors.New(m.Time))
+		}
+	}
+
+	return ret, nil
+}
+
+// lookup returns the value of the nested JSON object field at the path.
+func lookup(obj map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, sep)
+	for _, k := range keys[:len(keys)-1] {
+		nested, ok := obj[k].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		obj = nested
+	}
+	val, ok := obj[keys[len(keys)-1]]
+	return val, ok && val != nil
+}
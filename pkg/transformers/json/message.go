@@ -6,8 +6,10 @@ package json
 // Payload represents JSON Message payload.
 type Payload map[string]interface{}
 
-// Message represents a JSON messages.
+// Message represents a JSON messages. ID is the ID of the received message,
+// which is shared by the messages of a JSON array and isn't stored.
 type Message struct {
+	ID        string  `json:"-" db:"-" bson:"-"`
 	Channel   string  `json:"channel,omitempty" db:"channel" bson:"channel"`
 	Created   int64   `json:"created,omitempty" db:"created" bson:"created"`
 	Subtopic  string  `json:"subtopic,omitempty" db:"subtopic" bson:"subtopic,omitempty"`
@@ -27,17 +27,29 @@ var (
 
 type funcTransformer func(messaging.Message) (interface{}, error)
 
-// New returns a new JSON transformer.
+// New returns a new JSON transformer which flattens nested JSON objects.
 func New() transformers.Transformer {
-	return funcTransformer(transformer)
+	return funcTransformer(func(msg messaging.Message) (interface{}, error) {
+		return transform(msg, Flatten)
+	})
+}
+
+// NewNested returns a new JSON transformer which keeps nested JSON objects
+// as they are. It's meant to be used by the consumers whose underlying
+// database natively supports nested documents (e.g. JSONB or BSON).
+func NewNested() transformers.Transformer {
+	return funcTransformer(func(msg messaging.Message) (interface{}, error) {
+		return transform(msg, nest)
+	})
 }
 
 func (fh funcTransformer) Transform(msg messaging.Message) (interface{}, error) {
 	return fh(msg)
 }
 
-func transformer(msg messaging.Message) (interface{}, error) {
+func transform(msg messaging.Message, payload func(map[string]interface{}) (map[string]interface{}, error)) (interface{}, error) {
 	ret := Message{
+		ID:        msg.Id,
 		Publisher: msg.Publisher,
 		Created:   msg.Created,
 		Protocol:  msg.Protocol,
@@ -52,13 +64,13 @@ func transformer(msg messaging.Message) (interface{}, error) {
 		return nil, errors.Wrap(ErrTransform, errUnknownFormat)
 	}
 	format := subs[len(subs)-1]
-	var payload interface{}
-	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+	var pld interface{}
+	if err := json.Unmarshal(msg.Payload, &pld); err != nil {
 		return nil, errors.Wrap(ErrTransform, err)
 	}
-	switch p := payload.(type) {
+	switch p := pld.(type) {
 	case map[string]interface{}:
-		flat, err := Flatten(p)
+		flat, err := payload(p)
 		if err != nil {
 			return nil, errors.Wrap(ErrTransform, err)
 		}
@@ -72,7 +84,7 @@ func transformer(msg messaging.Message) (interface{}, error) {
 			if !ok {
 				return nil, errors.Wrap(ErrTransform, errInvalidNestedJSON)
 			}
-			flat, err := Flatten(v)
+			flat, err := payload(v)
 			if err != nil {
 				return nil, errors.Wrap(ErrTransform, err)
 			}
@@ -86,6 +98,10 @@ func transformer(msg messaging.Message) (interface{}, error) {
 	}
 }
 
+func nest(m map[string]interface{}) (map[string]interface{}, error) {
+	return m, nil
+}
+
 // ParseFlat receives flat map that reprents complex JSON objects and returns
 // the corresponding complex JSON object with nested maps. It's the opposite
 // of the Flatten function.
@@ -10,7 +10,9 @@ import (
 
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
 	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -129,3 +131,232 @@ func TestTransformJSON(t *testing.T) {
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s, got %s", tc.desc, tc.err, err))
 	}
 }
+
+func TestTransformNestedJSON(t *testing.T) {
+	now := time.Now().Unix()
+	tr := json.NewNested()
+	msg := messaging.Message{
+		Channel:   "channel-1",
+		Subtopic:  "subtopic-1",
+		Publisher: "publisher-1",
+		Protocol:  "protocol",
+		Payload:   []byte(validPayload),
+		Created:   now,
+	}
+
+	slashed := msg
+	slashed.Payload = []byte(invalidPayload)
+
+	invalidFmt := msg
+	invalidFmt.Subtopic = ""
+
+	jsonMsg := json.Messages{
+		Data: []json.Message{
+			{
+				Channel:   msg.Channel,
+				Subtopic:  msg.Subtopic,
+				Publisher: msg.Publisher,
+				Protocol:  msg.Protocol,
+				Created:   msg.Created,
+				Payload: map[string]interface{}{
+					"key1": "val1",
+					"key2": float64(123),
+					"key3": "val3",
+					"key4": map[string]interface{}{"key5": "val5"},
+				},
+			},
+		},
+		Format: msg.Subtopic,
+	}
+
+	slashedMsg := json.Messages{
+		Data: []json.Message{
+			{
+				Channel:   msg.Channel,
+				Subtopic:  msg.Subtopic,
+				Publisher: msg.Publisher,
+				Protocol:  msg.Protocol,
+				Created:   msg.Created,
+				Payload: map[string]interface{}{
+					"key1":   "val1",
+					"key2":   float64(123),
+					"key3/1": "val3",
+					"key4":   map[string]interface{}{"key5": "val5"},
+				},
+			},
+		},
+		Format: msg.Subtopic,
+	}
+
+	cases := []struct {
+		desc string
+		msg  messaging.Message
+		json interface{}
+		err  error
+	}{
+		{
+			desc: "test transform nested JSON",
+			msg:  msg,
+			json: jsonMsg,
+			err:  nil,
+		},
+		{
+			desc: "test transform nested JSON with separator in key",
+			msg:  slashed,
+			json: slashedMsg,
+			err:  nil,
+		},
+		{
+			desc: "test transform nested JSON with an invalid subtopic",
+			msg:  invalidFmt,
+			json: nil,
+			err:  json.ErrTransform,
+		},
+	}
+
+	for _, tc := range cases {
+		m, err := tr.Transform(tc.msg)
+		assert.Equal(t, tc.json, m, fmt.Sprintf("%s expected %v, got %v", tc.desc, tc.json, m))
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s, got %s", tc.desc, tc.err, err))
+	}
+}
+
+func TestTransformMappedJSON(t *testing.T) {
+	now := time.Now().UnixNano()
+	tr := json.NewMapped(json.Mapping{
+		Name:  "sensor",
+		Value: "d/tmp",
+		Time:  "ts",
+	})
+	msg := messaging.Message{
+		Channel:   "channel-1",
+		Subtopic:  "subtopic-1",
+		Publisher: "publisher-1",
+		Protocol:  "protocol",
+		Payload:   []byte(`{"sensor": "temperature", "ts": 1633046400, "d": {"tmp": 21.5}}`),
+		Created:   now,
+	}
+
+	rfcMsg := msg
+	rfcMsg.Payload = []byte(`{"sensor": "status", "ts": "2021-10-01T00:00:00.5Z", "d": {"tmp": "on"}}`)
+
+	listMsg := msg
+	listMsg.Payload = []byte(`[{"sensor": "alarm", "ts": 1633046400, "d": {"tmp": true}}, {"sensor": "temperature", "ts": 1633046460, "d": {"tmp": 22}}]`)
+
+	missingValue := msg
+	missingValue.Payload = []byte(`{"sensor": "temperature", "ts": 1633046400, "d": {"hmd": 87}}`)
+
+	invalidValue := msg
+	invalidValue.Payload = []byte(`{"sensor": "temperature", "ts": 1633046400, "d": {"tmp": [21.5]}}`)
+
+	invalidName := msg
+	invalidName.Payload = []byte(`{"sensor": 1, "ts": 1633046400, "d": {"tmp": 21.5}}`)
+
+	invalidTime := msg
+	invalidTime.Payload = []byte(`{"sensor": "temperature", "ts": "yesterday", "d": {"tmp": 21.5}}`)
+
+	invalidPayload := msg
+	invalidPayload.Payload = []byte(`"temperature"`)
+
+	tmp, tmp2, status, alarm := 21.5, float64(22), "on", true
+	base := senml.Message{
+		Channel:   msg.Channel,
+		Subtopic:  msg.Subtopic,
+		Publisher: msg.Publisher,
+		Protocol:  msg.Protocol,
+	}
+	tmpMsg := base
+	tmpMsg.Name, tmpMsg.Time, tmpMsg.Value = "temperature", 1633046400, &tmp
+	statusMsg := base
+	statusMsg.Name, statusMsg.Time, statusMsg.StringValue = "status", 1633046400.5, &status
+	alarmMsg := base
+	alarmMsg.Name, alarmMsg.Time, alarmMsg.BoolValue = "alarm", 1633046400, &alarm
+	tmpMsg2 := base
+	tmpMsg2.Name, tmpMsg2.Time, tmpMsg2.Value = "temperature", 1633046460, &tmp2
+
+	cases := []struct {
+		desc string
+		tr   transformers.Transformer
+		msg  messaging.Message
+		res  interface{}
+		err  error
+	}{
+		{
+			desc: "test transform mapped JSON",
+			tr:   tr,
+			msg:  msg,
+			res:  []senml.Message{tmpMsg},
+			err:  nil,
+		},
+		{
+			desc: "test transform mapped JSON with RFC3339 time and string value",
+			tr:   tr,
+			msg:  rfcMsg,
+			res:  []senml.Message{statusMsg},
+			err:  nil,
+		},
+		{
+			desc: "test transform mapped JSON array",
+			tr:   tr,
+			msg:  listMsg,
+			res:  []senml.Message{alarmMsg, tmpMsg2},
+			err:  nil,
+		},
+		{
+			desc: "test transform mapped JSON without name and time paths",
+			tr:   json.NewMapped(json.Mapping{Value: "d/tmp"}),
+			msg:  msg,
+			res: []senml.Message{{
+				Channel:   msg.Channel,
+				Subtopic:  msg.Subtopic,
+				Publisher: msg.Publisher,
+				Protocol:  msg.Protocol,
+				Name:      "d/tmp",
+				Time:      float64(now) / float64(1e9),
+				Value:     &tmp,
+			}},
+			err: nil,
+		},
+		{
+			desc: "test transform mapped JSON with missing value",
+			tr:   tr,
+			msg:  missingValue,
+			res:  nil,
+			err:  json.ErrTransform,
+		},
+		{
+			desc: "test transform mapped JSON with invalid value",
+			tr:   tr,
+			msg:  invalidValue,
+			res:  nil,
+			err:  json.ErrTransform,
+		},
+		{
+			desc: "test transform mapped JSON with invalid name",
+			tr:   tr,
+			msg:  invalidName,
+			res:  nil,
+			err:  json.ErrTransform,
+		},
+		{
+			desc: "test transform mapped JSON with invalid time",
+			tr:   tr,
+			msg:  invalidTime,
+			res:  nil,
+			err:  json.ErrTransform,
+		},
+		{
+			desc: "test transform mapped JSON with invalid payload",
+			tr:   tr,
+			msg:  invalidPayload,
+			res:  nil,
+			err:  json.ErrTransform,
+		},
+	}
+
+	for _, tc := range cases {
+		m, err := tc.tr.Transform(tc.msg)
+		assert.Equal(t, tc.res, m, fmt.Sprintf("%s expected %v, got %v", tc.desc, tc.res, m))
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s, got %s", tc.desc, tc.err, err))
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lpp
+
+import (
+	"fmt"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/senml"
+)
+
+var (
+	// ErrDecode represents an error during decoding of LPP payload.
+	ErrDecode = errors.New("failed to decode LPP payload")
+
+	errUnknownType = errors.New("unknown data type")
+	errTruncated   = errors.New("truncated data")
+)
+
+// field describes a single value of the data type.
+type field struct {
+	// suffix is appended to the record name of the multi-value types.
+	suffix string
+	size   int
+	signed bool
+	// divisor converts the raw integer to the value in the data type unit.
+	divisor float64
+}
+
+type dataType struct {
+	name   string
+	unit   string
+	fields []field
+}
+
+func (dt dataType) size() int {
+	n := 0
+	for _, f := range dt.fields {
+		n += f.size
+	}
+	return n
+}
+
+// Data types as specified by Cayenne Low Power Payload, based on IPSO
+// Alliance Smart Objects Guidelines, with the object ID reduced by 3200.
+var types = map[byte]dataType{
+	0:   {name: "digital_input", fields: []field{{size: 1, divisor: 1}}},
+	1:   {name: "digital_output", fields: []field{{size: 1, divisor: 1}}},
+	2:   {name: "analog_input", fields: []field{{size: 2, signed: true, divisor: 100}}},
+	3:   {name: "analog_output", fields: []field{{size: 2, signed: true, divisor: 100}}},
+	101: {name: "illuminance", unit: "lx", fields: []field{{size: 2, divisor: 1}}},
+	102: {name: "presence", fields: []field{{size: 1, divisor: 1}}},
+	103: {name: "temperature", unit: "Cel", fields: []field{{size: 2, signed: true, divisor: 10}}},
+	104: {name: "humidity", unit: "%RH", fields: []field{{size: 1, divisor: 2}}},
+	113: {name: "accelerometer", unit: "g", fields: []field{
+		{suffix: "_x", size: 2, signed: true, divisor: 1000},
+		{suffix: "_y", size: 2, signed: true, divisor: 1000},
+		{suffix: "_z", size: 2, signed: true, divisor: 1000},
+	}},
+	115: {name: "barometer", unit: "hPa", fields: []field{{size: 2, divisor: 10}}},
+	134: {name: "gyrometer", unit: "deg/s", fields: []field{
+		{suffix: "_x", size: 2, signed: true, divisor: 100},
+		{suffix: "_y", size: 2, signed: true, divisor: 100},
+		{suffix: "_z", size: 2, signed: true, divisor: 100},
+	}},
+	136: {name: "gps", fields: []field{
+		{suffix: "_lat", size: 3, signed: true, divisor: 10000},
+		{suffix: "_lon", size: 3, signed: true, divisor: 10000},
+		{suffix: "_alt", size: 3, signed: true, divisor: 100},
+	}},
+}
+
+// Decode decodes Cayenne LPP payload, consisting of the channel, type and
+// value triplets, into the SenML pack. Each value is decoded into a record
+// named by the data type and the channel (e.g. `temperature_3`), while the
+// values of the multi-value data types get the value suffix as well (e.g.
+// `gps_1_lat`).
+func Decode(payload []byte) (senml.Pack, error) {
+	var p senml.Pack
+	for i := 0; i < len(payload); {
+		if len(payload)-i < 2 {
+			return senml.Pack{}, errors.Wrap(ErrDecode, errTruncated)
+		}
+		ch, typ := payload[i], payload[i+1]
+		i += 2
+
+		dt, ok := types[typ]
+		if !ok {
+			return senml.Pack{}, errors.Wrap(ErrDecode, errUnknownType)
+		}
+		if len(payload)-i < dt.size() {
+			return senml.Pack{}, errors.Wrap(ErrDecode, errTruncated)
+		}
+
+		for _, f := range dt.fields {
+			v := value(payload[i:i+f.size], f.signed) / f.divisor
+			i += f.size
+			p.Records = append(p.Records, senml.Record{
+				Name:  fmt.Sprintf("%s_%d%s", dt.name, ch, f.suffix),
+				Unit:  dt.unit,
+				Value: &v,
+			})
+		}
+	}
+
+	return p, nil
+}
+
+// value decodes the big endian integer.
+func value(b []byte, signed bool) float64 {
+	var v int64
+	for _, c := range b {
+		v = v<<8 | int64(c)
+	}
+	if signed && b[0]&0x80 != 0 {
+		v -= 1 << (8 * uint(len(b)))
+	}
+	return float64(v)
+}
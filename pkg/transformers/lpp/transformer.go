@@ -0,0 +1,48 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lpp
+
+import (
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+var _ transformers.Transformer = (*transformer)(nil)
+
+type transformer struct{}
+
+// New returns transformer service implementation for Cayenne LPP messages,
+// which transforms them to SenML messages.
+func New() transformers.Transformer {
+	return transformer{}
+}
+
+func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
+	p, err := Decode(msg.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// LPP carries no time, so the reception timestamp in nanoseconds
+	// is converted to float64 and used instead.
+	tm := float64(msg.Created) / float64(1e9)
+
+	msgs := make([]senml.Message, len(p.Records))
+	for i, r := range p.Records {
+		msgs[i] = senml.Message{
+			ID:        msg.Id,
+			Channel:   msg.Channel,
+			Subtopic:  msg.Subtopic,
+			Publisher: msg.Publisher,
+			Protocol:  msg.Protocol,
+			Name:      r.Name,
+			Unit:      r.Unit,
+			Time:      tm,
+			Value:     r.Value,
+		}
+	}
+
+	return msgs, nil
+}
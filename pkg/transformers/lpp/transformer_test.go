@@ -0,0 +1,111 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lpp_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/lpp"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransform(t *testing.T) {
+	now := time.Now().UnixNano()
+	tm := float64(now) / float64(1e9)
+	msg := messaging.Message{
+		Channel:   "channel",
+		Subtopic:  "subtopic",
+		Publisher: "publisher",
+		Protocol:  "lora",
+		Created:   now,
+	}
+
+	// Temperature of 27.2 Cel on channel 3 and humidity of 50.5 %RH on channel 5.
+	sensors := msg
+	sensors.Payload = []byte{0x03, 0x67, 0x01, 0x10, 0x05, 0x68, 0x65}
+
+	// Negative temperature of -4.1 Cel on channel 1.
+	negative := msg
+	negative.Payload = []byte{0x01, 0x67, 0xff, 0xd7}
+
+	// GPS location on channel 1: latitude 42.3519, longitude -87.9094 and altitude 10 m.
+	gps := msg
+	gps.Payload = []byte{0x01, 0x88, 0x06, 0x76, 0x5f, 0xf2, 0x96, 0x0a, 0x00, 0x03, 0xe8}
+
+	unknown := msg
+	unknown.Payload = []byte{0x01, 0xff, 0x01}
+
+	truncated := msg
+	truncated.Payload = []byte{0x03, 0x67, 0x01}
+
+	record := func(name, unit string, v float64) senml.Message {
+		return senml.Message{
+			Channel:   msg.Channel,
+			Subtopic:  msg.Subtopic,
+			Publisher: msg.Publisher,
+			Protocol:  msg.Protocol,
+			Name:      name,
+			Unit:      unit,
+			Time:      tm,
+			Value:     &v,
+		}
+	}
+
+	cases := []struct {
+		desc string
+		msg  messaging.Message
+		res  interface{}
+		err  error
+	}{
+		{
+			desc: "transform temperature and humidity",
+			msg:  sensors,
+			res: []senml.Message{
+				record("temperature_3", "Cel", 27.2),
+				record("humidity_5", "%RH", 50.5),
+			},
+			err: nil,
+		},
+		{
+			desc: "transform negative temperature",
+			msg:  negative,
+			res:  []senml.Message{record("temperature_1", "Cel", -4.1)},
+			err:  nil,
+		},
+		{
+			desc: "transform GPS location",
+			msg:  gps,
+			res: []senml.Message{
+				record("gps_1_lat", "", 42.3519),
+				record("gps_1_lon", "", -87.9094),
+				record("gps_1_alt", "", 10),
+			},
+			err: nil,
+		},
+		{
+			desc: "transform unknown data type",
+			msg:  unknown,
+			res:  nil,
+			err:  lpp.ErrDecode,
+		},
+		{
+			desc: "transform truncated payload",
+			msg:  truncated,
+			res:  nil,
+			err:  lpp.ErrDecode,
+		},
+	}
+
+	tr := lpp.New()
+	for _, tc := range cases {
+		res, err := tr.Transform(tc.msg)
+		assert.Equal(t, tc.res, res, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.res, res))
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+	}
+}
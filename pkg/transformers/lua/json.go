@@ -0,0 +1,55 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lua
+
+import (
+	"encoding/json"
+
+	glua "github.com/yuin/gopher-lua"
+)
+
+// jsonModule returns the table with the `decode` function, which decodes
+// the JSON string into the Lua value. On failure, it returns nil and the
+// error message.
+func jsonModule(ls *glua.LState) *glua.LTable {
+	mod := ls.NewTable()
+	mod.RawSetString("decode", ls.NewFunction(decode))
+	return mod
+}
+
+func decode(ls *glua.LState) int {
+	var v interface{}
+	if err := json.Unmarshal([]byte(ls.CheckString(1)), &v); err != nil {
+		ls.Push(glua.LNil)
+		ls.Push(glua.LString(err.Error()))
+		return 2
+	}
+	ls.Push(toLua(ls, v))
+	return 1
+}
+
+func toLua(ls *glua.LState, v interface{}) glua.LValue {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		tbl := ls.NewTable()
+		for k, e := range val {
+			tbl.RawSetString(k, toLua(ls, e))
+		}
+		return tbl
+	case []interface{}:
+		tbl := ls.NewTable()
+		for _, e := range val {
+			tbl.Append(toLua(ls, e))
+		}
+		return tbl
+	case string:
+		return glua.LString(val)
+	case float64:
+		return glua.LNumber(val)
+	case bool:
+		return glua.LBool(val)
+	default:
+		return glua.LNil
+	}
+}
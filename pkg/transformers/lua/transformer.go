@@ -0,0 +1,342 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lua
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	mfsenml "github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/senml"
+	glua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+const (
+	// Name of the function the script has to define.
+	transformFn = "transform"
+	// Limits of the script call stack and registry.
+	callStackSize   = 256
+	registrySize    = 1024
+	registryMaxSize = 64 * 1024
+)
+
+var (
+	// ErrCompile indicates that the script can't be compiled.
+	ErrCompile = errors.New("failed to compile script")
+
+	// ErrTransform represents an error during the script execution.
+	ErrTransform = errors.New("failed to transform message using script")
+
+	errInvalidResult = errors.New("script returned invalid result")
+	errNormalize     = errors.New("failed to normalize script result")
+)
+
+// Functions of the base library which aren't available to the scripts,
+// since they access the file system, load code or affect the interpreter.
+var unsafeBase = []string{
+	"collectgarbage", "dofile", "getfenv", "load", "loadfile", "loadstring",
+	"module", "newproxy", "print", "require", "setfenv", "_printregs",
+}
+
+// Config contains the scripts used by the transformer.
+type Config struct {
+	// Script is the source of the script used for the messages of the
+	// channels without their own script.
+	Script string
+
+	// Channels maps the channel IDs to the sources of their scripts.
+	Channels map[string]string
+
+	// Timeout limits the execution time of the script per message.
+	Timeout time.Duration
+}
+
+var _ transformers.Transformer = (*transformer)(nil)
+
+type transformer struct {
+	script   *glua.FunctionProto
+	channels map[string]*glua.FunctionProto
+	timeout  time.Duration
+}
+
+// New returns a new transformer which transforms the messages to SenML
+// messages using the user-defined Lua scripts. Each script has to define
+// the `transform` function, which receives the message as a table with
+// `channel`, `subtopic`, `publisher`, `protocol`, `created` and `payload`
+// fields, and returns the SenML record or the list of SenML records, as
+// tables keyed by the SenML JSON labels. Scripts are executed in sandbox,
+// without access to the file system, OS and modules.
+func New(cfg Config) (transformers.Transformer, error) {
+	t := transformer{
+		channels: make(map[string]*glua.FunctionProto),
+		timeout:  cfg.Timeout,
+	}
+
+	if cfg.Script != "" {
+		fp, err := compile(cfg.Script, "script")
+		if err != nil {
+			return nil, err
+		}
+		t.script = fp
+	}
+
+	for ch, src := range cfg.Channels {
+		fp, err := compile(src, ch)
+		if err != nil {
+			return nil, err
+		}
+		t.channels[ch] = fp
+	}
+
+	return t, nil
+}
+
+func compile(src, name string) (*glua.FunctionProto, error) {
+	chunk, err := parse.Parse(strings.NewReader(src), name)
+	if err != nil {
+		return nil, errors.Wrap(ErrCompile, err)
+	}
+
+	fp, err := glua.Compile(chunk, name)
+	if err != nil {
+		return nil, errors.Wrap(ErrCompile, err)
+	}
+
+	return fp, nil
+}
+
+func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
+	fp, ok := t.channels[msg.Channel]
+	if !ok {
+		fp = t.script
+	}
+	if fp == nil {
+		return nil, errors.Wrap(ErrTransform, errors.New("no script for channel"))
+	}
+
+	ls := sandbox()
+	defer ls.Close()
+
+	if t.timeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+		defer cancel()
+		ls.SetContext(ctx)
+	}
+
+	ls.Push(ls.NewFunctionFromProto(fp))
+	if err := ls.PCall(0, glua.MultRet, nil); err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+
+	fn, ok := ls.GetGlobal(transformFn).(*glua.LFunction)
+	if !ok {
+		return nil, errors.Wrap(ErrTransform, errors.New("transform function is not defined"))
+	}
+
+	call := glua.P{Fn: fn, NRet: 1, Protect: true}
+	if err := ls.CallByParam(call, message(ls, msg)); err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+	ret := ls.Get(-1)
+	ls.Pop(1)
+
+	p, err := pack(ret)
+	if err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+
+	return messages(msg, p)
+}
+
+// sandbox returns the Lua state with the base, table, string and math
+// libraries, excluding the unsafe functions of the base library.
+func sandbox() *glua.LState {
+	ls := glua.NewState(glua.Options{
+		SkipOpenLibs:    true,
+		CallStackSize:   callStackSize,
+		RegistrySize:    registrySize,
+		RegistryMaxSize: registryMaxSize,
+	})
+
+	libs := []struct {
+		name string
+		fn   glua.LGFunction
+	}{
+		{glua.BaseLibName, glua.OpenBase},
+		{glua.TabLibName, glua.OpenTable},
+		{glua.StringLibName, glua.OpenString},
+		{glua.MathLibName, glua.OpenMath},
+	}
+	for _, lib := range libs {
+		ls.Push(ls.NewFunction(lib.fn))
+		ls.Push(glua.LString(lib.name))
+		ls.Call(1, 0)
+	}
+
+	for _, name := range unsafeBase {
+		ls.SetGlobal(name, glua.LNil)
+	}
+	ls.SetGlobal("json", jsonModule(ls))
+
+	return ls
+}
+
+func message(ls *glua.LState, msg messaging.Message) *glua.LTable {
+	tbl := ls.NewTable()
+	tbl.RawSetString("channel", glua.LString(msg.Channel))
+	tbl.RawSetString("subtopic", glua.LString(msg.Subtopic))
+	tbl.RawSetString("publisher", glua.LString(msg.Publisher))
+	tbl.RawSetString("protocol", glua.LString(msg.Protocol))
+	tbl.RawSetString("created", glua.LNumber(msg.Created))
+	tbl.RawSetString("payload", glua.LString(msg.Payload))
+	return tbl
+}
+
+// pack converts the script result, either a single record or the list of
+// records, to SenML pack.
+func pack(ret glua.LValue) (senml.Pack, error) {
+	tbl, ok := ret.(*glua.LTable)
+	if !ok {
+		return senml.Pack{}, errInvalidResult
+	}
+
+	var tbls []*glua.LTable
+	switch tbl.Len() {
+	case 0:
+		tbls = []*glua.LTable{tbl}
+	default:
+		for i := 1; i <= tbl.Len(); i++ {
+			r, ok := tbl.RawGetInt(i).(*glua.LTable)
+			if !ok {
+				return senml.Pack{}, errInvalidResult
+			}
+			tbls = append(tbls, r)
+		}
+	}
+
+	var p senml.Pack
+	for _, t := range tbls {
+		r, err := record(t)
+		if err != nil {
+			return senml.Pack{}, err
+		}
+		p.Records = append(p.Records, r)
+	}
+
+	return p, nil
+}
+
+func record(tbl *glua.LTable) (senml.Record, error) {
+	var r senml.Record
+	var err error
+	tbl.ForEach(func(k, v glua.LValue) {
+		if err != nil {
+			return
+		}
+		key, ok := k.(glua.LString)
+		if !ok {
+			err = errInvalidResult
+			return
+		}
+		switch string(key) {
+		case "bn":
+			r.BaseName, err = str(v)
+		case "bt":
+			r.BaseTime, err = num(v)
+		case "bu":
+			r.BaseUnit, err = str(v)
+		case "n":
+			r.Name, err = str(v)
+		case "u":
+			r.Unit, err = str(v)
+		case "t":
+			r.Time, err = num(v)
+		case "ut":
+			r.UpdateTime, err = num(v)
+		case "v":
+			var f float64
+			f, err = num(v)
+			r.Value = &f
+		case "vs":
+			var s string
+			s, err = str(v)
+			r.StringValue = &s
+		case "vd":
+			var s string
+			s, err = str(v)
+			r.DataValue = &s
+		case "vb":
+			b, ok := v.(glua.LBool)
+			if !ok {
+				err = errInvalidResult
+				return
+			}
+			vb := bool(b)
+			r.BoolValue = &vb
+		case "s":
+			var f float64
+			f, err = num(v)
+			r.Sum = &f
+		default:
+			err = errInvalidResult
+		}
+	})
+	return r, err
+}
+
+func str(v glua.LValue) (string, error) {
+	s, ok := v.(glua.LString)
+	if !ok {
+		return "", errInvalidResult
+	}
+	return string(s), nil
+}
+
+func num(v glua.LValue) (float64, error) {
+	n, ok := v.(glua.LNumber)
+	if !ok {
+		return 0, errInvalidResult
+	}
+	return float64(n), nil
+}
+
+func messages(msg messaging.Message, p senml.Pack) (interface{}, error) {
+	normalized, err := senml.Normalize(p)
+	if err != nil {
+		return nil, errors.Wrap(ErrTransform, errors.Wrap(errNormalize, err))
+	}
+
+	msgs := make([]mfsenml.Message, len(normalized.Records))
+	for i, v := range normalized.Records {
+		// Use reception timestamp if the record time is missing.
+		t := v.Time
+		if t == 0 {
+			t = float64(msg.Created) / float64(1e9)
+		}
+
+		msgs[i] = mfsenml.Message{
+			ID:          msg.Id,
+			Channel:     msg.Channel,
+			Subtopic:    msg.Subtopic,
+			Publisher:   msg.Publisher,
+			Protocol:    msg.Protocol,
+			Name:        v.Name,
+			Unit:        v.Unit,
+			Time:        t,
+			UpdateTime:  v.UpdateTime,
+			Value:       v.Value,
+			BoolValue:   v.BoolValue,
+			DataValue:   v.DataValue,
+			StringValue: v.StringValue,
+			Sum:         v.Sum,
+		}
+	}
+
+	return msgs, nil
+}
@@ -0,0 +1,212 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package lua_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/lua"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// Decodes the vendor JSON payload, such as {"temp": 21.5, "hum": 40}.
+	vendorScript = `
+function transform(msg)
+  local data, err = json.decode(msg.payload)
+  if data == nil then
+    error(err)
+  end
+  return {
+    {bn = msg.publisher .. ":", n = "temperature", u = "Cel", v = data.temp},
+    {n = "humidity", u = "%RH", v = data.hum, t = 1000},
+  }
+end
+`
+	// Decodes the payload consisting of a single byte representing the
+	// battery level.
+	batteryScript = `
+function transform(msg)
+  return {n = "battery", u = "%EL", v = string.byte(msg.payload, 1)}
+end
+`
+	sandboxScript = `
+function transform(msg)
+  return {n = "file", vs = tostring(io.open("/etc/passwd"))}
+end
+`
+	loopScript = `
+function transform(msg)
+  while true do end
+end
+`
+	invalidResultScript = `
+function transform(msg)
+  return "invalid"
+end
+`
+	noFuncScript = `local x = 1`
+)
+
+func TestTransform(t *testing.T) {
+	now := time.Now().UnixNano()
+	tm := float64(now) / float64(1e9)
+	msg := messaging.Message{
+		Channel:   "vendor",
+		Subtopic:  "subtopic",
+		Publisher: "publisher",
+		Protocol:  "mqtt",
+		Payload:   []byte(`{"temp": 21.5, "hum": 40}`),
+		Created:   now,
+	}
+
+	battery := msg
+	battery.Channel = "battery"
+	battery.Payload = []byte{0x5a}
+
+	invalid := msg
+	invalid.Payload = []byte("invalid")
+
+	channels := map[string]string{
+		"battery": batteryScript,
+		"sandbox": sandboxScript,
+		"loop":    loopScript,
+		"result":  invalidResultScript,
+		"nofunc":  noFuncScript,
+	}
+	tr, err := lua.New(lua.Config{Script: vendorScript, Channels: channels, Timeout: 100 * time.Millisecond})
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating transformer: %s", err))
+
+	noDefault, err := lua.New(lua.Config{Channels: channels})
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating transformer: %s", err))
+
+	record := func(ch, name, unit string, t, v float64) senml.Message {
+		return senml.Message{
+			Channel:   ch,
+			Subtopic:  msg.Subtopic,
+			Publisher: msg.Publisher,
+			Protocol:  msg.Protocol,
+			Name:      name,
+			Unit:      unit,
+			Time:      t,
+			Value:     &v,
+		}
+	}
+
+	with := func(ch string) messaging.Message {
+		m := msg
+		m.Channel = ch
+		return m
+	}
+
+	cases := []struct {
+		desc string
+		tr   transformers.Transformer
+		msg  messaging.Message
+		res  interface{}
+		err  error
+	}{
+		{
+			desc: "transform message using default script",
+			tr:   tr,
+			msg:  msg,
+			res: []senml.Message{
+				record(msg.Channel, "publisher:temperature", "Cel", tm, 21.5),
+				record(msg.Channel, "publisher:humidity", "%RH", 1000, 40),
+			},
+			err: nil,
+		},
+		{
+			desc: "transform message using channel script",
+			tr:   tr,
+			msg:  battery,
+			res:  []senml.Message{record(battery.Channel, "battery", "%EL", tm, 90)},
+			err:  nil,
+		},
+		{
+			desc: "transform message failing in script",
+			tr:   tr,
+			msg:  invalid,
+			res:  nil,
+			err:  lua.ErrTransform,
+		},
+		{
+			desc: "transform message using script accessing unavailable library",
+			tr:   tr,
+			msg:  with("sandbox"),
+			res:  nil,
+			err:  lua.ErrTransform,
+		},
+		{
+			desc: "transform message using script exceeding timeout",
+			tr:   tr,
+			msg:  with("loop"),
+			res:  nil,
+			err:  lua.ErrTransform,
+		},
+		{
+			desc: "transform message using script returning invalid result",
+			tr:   tr,
+			msg:  with("result"),
+			res:  nil,
+			err:  lua.ErrTransform,
+		},
+		{
+			desc: "transform message using script without transform function",
+			tr:   tr,
+			msg:  with("nofunc"),
+			res:  nil,
+			err:  lua.ErrTransform,
+		},
+		{
+			desc: "transform message of channel without script",
+			tr:   noDefault,
+			msg:  msg,
+			res:  nil,
+			err:  lua.ErrTransform,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := tc.tr.Transform(tc.msg)
+		assert.Equal(t, tc.res, res, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.res, res))
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		desc string
+		cfg  lua.Config
+		err  error
+	}{
+		{
+			desc: "create transformer with valid scripts",
+			cfg:  lua.Config{Script: vendorScript, Channels: map[string]string{"battery": batteryScript}},
+			err:  nil,
+		},
+		{
+			desc: "create transformer with invalid default script",
+			cfg:  lua.Config{Script: "function transform("},
+			err:  lua.ErrCompile,
+		},
+		{
+			desc: "create transformer with invalid channel script",
+			cfg:  lua.Config{Channels: map[string]string{"battery": "end"}},
+			err:  lua.ErrCompile,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := lua.New(tc.cfg)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"sync"
+
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
+)
+
+var _ protobuf.SchemaRepository = (*schemaRepositoryMock)(nil)
+
+type schemaRepositoryMock struct {
+	mu      sync.Mutex
+	schemas map[string]protobuf.Schema
+}
+
+// NewSchemaRepository returns mock schema repository.
+func NewSchemaRepository() protobuf.SchemaRepository {
+	return &schemaRepositoryMock{
+		schemas: make(map[string]protobuf.Schema),
+	}
+}
+
+func (srm *schemaRepositoryMock) Save(s protobuf.Schema) error {
+	srm.mu.Lock()
+	defer srm.mu.Unlock()
+
+	srm.schemas[s.Channel] = s
+	return nil
+}
+
+func (srm *schemaRepositoryMock) Retrieve(channel string) (protobuf.Schema, error) {
+	srm.mu.Lock()
+	defer srm.mu.Unlock()
+
+	s, ok := srm.schemas[channel]
+	if !ok {
+		return protobuf.Schema{}, protobuf.ErrNotFound
+	}
+	return s, nil
+}
+
+func (srm *schemaRepositoryMock) Remove(channel string) error {
+	srm.mu.Lock()
+	defer srm.mu.Unlock()
+
+	delete(srm.schemas, channel)
+	return nil
+}
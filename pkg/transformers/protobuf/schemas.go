@@ -0,0 +1,78 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package protobuf
+
+import (
+	"github.com/mainflux/mainflux/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var (
+	// ErrNotFound indicates that the channel has no registered schema.
+	ErrNotFound = errors.New("schema not found")
+
+	// ErrMalformedSchema indicates that the descriptor can't be parsed or
+	// doesn't contain the message type.
+	ErrMalformedSchema = errors.New("malformed schema")
+)
+
+// Schema represents the protobuf schema registered for the channel.
+type Schema struct {
+	// Channel is the ID of the channel the schema is registered for.
+	Channel string `json:"channel"`
+
+	// Message is the fully qualified name of the payload message type.
+	Message string `json:"message"`
+
+	// Descriptor is the serialized FileDescriptorSet containing the message
+	// type along with its dependencies, as produced by
+	// `protoc --include_imports --descriptor_set_out`.
+	Descriptor []byte `json:"descriptor"`
+}
+
+// Validate checks if the descriptor contains the message type.
+func (s Schema) Validate() error {
+	_, err := s.compile()
+	return err
+}
+
+func (s Schema) compile() (protoreflect.MessageDescriptor, error) {
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(s.Descriptor, &fds); err != nil {
+		return nil, errors.Wrap(ErrMalformedSchema, err)
+	}
+
+	files, err := protodesc.NewFiles(&fds)
+	if err != nil {
+		return nil, errors.Wrap(ErrMalformedSchema, err)
+	}
+
+	d, err := files.FindDescriptorByName(protoreflect.FullName(s.Message))
+	if err != nil {
+		return nil, errors.Wrap(ErrMalformedSchema, err)
+	}
+
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, ErrMalformedSchema
+	}
+
+	return md, nil
+}
+
+// SchemaRepository specifies the per-channel schemas persistence API.
+type SchemaRepository interface {
+	// Save registers the schema, replacing the existing schema of the
+	// channel.
+	Save(s Schema) error
+
+	// Retrieve returns the schema registered for the channel.
+	Retrieve(channel string) (Schema, error)
+
+	// Remove removes the schema registered for the channel.
+	Remove(channel string) error
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package protobuf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"sync"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ErrTransform represents an error during decoding of the protobuf payload.
+var ErrTransform = errors.New("unable to decode protobuf message")
+
+var _ transformers.Transformer = (*transformer)(nil)
+
+type compiled struct {
+	schema Schema
+	desc   protoreflect.MessageDescriptor
+}
+
+type transformer struct {
+	schemas SchemaRepository
+	payload func(map[string]interface{}) (map[string]interface{}, error)
+	mu      sync.Mutex
+	cache   map[string]compiled
+}
+
+// New returns a new protobuf transformer which decodes the payloads using
+// the schemas registered for the message channels into the JSON messages,
+// flattening nested messages.
+func New(schemas SchemaRepository) transformers.Transformer {
+	return &transformer{
+		schemas: schemas,
+		payload: json.Flatten,
+		cache:   make(map[string]compiled),
+	}
+}
+
+// NewNested returns a new protobuf transformer which keeps nested messages
+// as nested JSON objects. It's meant to be used by the consumers whose
+// underlying database natively supports nested documents.
+func NewNested(schemas SchemaRepository) transformers.Transformer {
+	return &transformer{
+		schemas: schemas,
+		payload: func(m map[string]interface{}) (map[string]interface{}, error) {
+			return m, nil
+		},
+		cache: make(map[string]compiled),
+	}
+}
+
+func (t *transformer) Transform(msg messaging.Message) (interface{}, error) {
+	s, err := t.schemas.Retrieve(msg.Channel)
+	if err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+
+	desc, err := t.descriptor(s)
+	if err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+
+	pm := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(msg.Payload, pm); err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+
+	pld, err := t.payload(toMap(pm))
+	if err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+
+	// Use the last subtopic part as the message format, the same as
+	// JSON transformer does, falling back to the message type name.
+	format := string(desc.Name())
+	if msg.Subtopic != "" {
+		subs := strings.Split(msg.Subtopic, ".")
+		format = subs[len(subs)-1]
+	}
+
+	ret := json.Message{
+		ID:        msg.Id,
+		Channel:   msg.Channel,
+		Created:   msg.Created,
+		Subtopic:  msg.Subtopic,
+		Publisher: msg.Publisher,
+		Protocol:  msg.Protocol,
+		Payload:   pld,
+	}
+
+	return json.Messages{Data: []json.Message{ret}, Format: format}, nil
+}
+
+// descriptor returns the message descriptor of the schema, compiling the
+// schema only if it's changed since the last message of the channel.
+func (t *transformer) descriptor(s Schema) (protoreflect.MessageDescriptor, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if c, ok := t.cache[s.Channel]; ok && c.schema.Message == s.Message && bytes.Equal(c.schema.Descriptor, s.Descriptor) {
+		return c.desc, nil
+	}
+
+	desc, err := s.compile()
+	if err != nil {
+		return nil, err
+	}
+	t.cache[s.Channel] = compiled{schema: s, desc: desc}
+
+	return desc, nil
+}
+
+func toMap(m protoreflect.Message) map[string]interface{} {
+	ret := make(map[string]interface{})
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		ret[string(fd.Name())] = value(fd, v)
+		return true
+	})
+	return ret
+}
+
+func value(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch {
+	case fd.IsList():
+		l := v.List()
+		ret := make([]interface{}, l.Len())
+		for i := range ret {
+			ret[i] = scalar(fd, l.Get(i))
+		}
+		return ret
+	case fd.IsMap():
+		ret := make(map[string]interface{})
+		v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+			ret[k.String()] = scalar(fd.MapValue(), mv)
+			return true
+		})
+		return ret
+	default:
+		return scalar(fd, v)
+	}
+}
+
+// scalar converts the field value to the type the same value would have
+// if it was decoded from JSON, so the messages are stored the same way.
+func scalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return toMap(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return float64(v.Enum())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return float64(v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return float64(v.Uint())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float()
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	default:
+		return v.Interface()
+	}
+}
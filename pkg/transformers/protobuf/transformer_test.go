@@ -0,0 +1,252 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package protobuf_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	channel = "channel-1"
+	msgType = "telemetry.Telemetry"
+)
+
+// descriptor returns the FileDescriptorSet of the following schema:
+//
+//	syntax = "proto3";
+//	package telemetry;
+//	message Location {
+//	  double x = 1;
+//	  double y = 2;
+//	}
+//	message Telemetry {
+//	  string sensor = 1;
+//	  double temperature = 2;
+//	  int64 uptime = 3;
+//	  bool alarm = 4;
+//	  Location loc = 5;
+//	  repeated int32 readings = 6;
+//	}
+func descriptor() *descriptorpb.FileDescriptorSet {
+	field := func(name string, num int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(num),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+		}
+	}
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	loc := field("loc", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, opt)
+	loc.TypeName = proto.String(".telemetry.Location")
+
+	return &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:    proto.String("telemetry.proto"),
+				Package: proto.String("telemetry"),
+				Syntax:  proto.String("proto3"),
+				MessageType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("Location"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							field("x", 1, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, opt),
+							field("y", 2, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, opt),
+						},
+					},
+					{
+						Name: proto.String("Telemetry"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							field("sensor", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, opt),
+							field("temperature", 2, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, opt),
+							field("uptime", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64, opt),
+							field("alarm", 4, descriptorpb.FieldDescriptorProto_TYPE_BOOL, opt),
+							loc,
+							field("readings", 6, descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_LABEL_REPEATED),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTransform(t *testing.T) {
+	fds := descriptor()
+	raw, err := proto.Marshal(fds)
+	require.Nil(t, err, fmt.Sprintf("unexpected error marshaling descriptor: %s", err))
+
+	schemas := mocks.NewSchemaRepository()
+	err = schemas.Save(protobuf.Schema{Channel: channel, Message: msgType, Descriptor: raw})
+	require.Nil(t, err, fmt.Sprintf("unexpected error saving schema: %s", err))
+
+	files, err := protodesc.NewFiles(fds)
+	require.Nil(t, err, fmt.Sprintf("unexpected error parsing descriptor: %s", err))
+	d, err := files.FindDescriptorByName(msgType)
+	require.Nil(t, err, fmt.Sprintf("unexpected error finding message: %s", err))
+
+	pm := dynamicpb.NewMessage(d.(protoreflect.MessageDescriptor))
+	pm.Set(pm.Descriptor().Fields().ByName("sensor"), protoreflect.ValueOf("temperature"))
+	pm.Set(pm.Descriptor().Fields().ByName("temperature"), protoreflect.ValueOf(21.5))
+	pm.Set(pm.Descriptor().Fields().ByName("uptime"), protoreflect.ValueOf(int64(3600)))
+	pm.Set(pm.Descriptor().Fields().ByName("alarm"), protoreflect.ValueOf(true))
+	locField := pm.Descriptor().Fields().ByName("loc")
+	loc := pm.Mutable(locField).Message()
+	loc.Set(locField.Message().Fields().ByName("x"), protoreflect.ValueOf(1.0))
+	loc.Set(locField.Message().Fields().ByName("y"), protoreflect.ValueOf(2.0))
+	readings := pm.Mutable(pm.Descriptor().Fields().ByName("readings")).List()
+	readings.Append(protoreflect.ValueOf(int32(4)))
+	readings.Append(protoreflect.ValueOf(int32(5)))
+
+	pld, err := proto.Marshal(pm)
+	require.Nil(t, err, fmt.Sprintf("unexpected error marshaling message: %s", err))
+
+	now := time.Now().UnixNano()
+	msg := messaging.Message{
+		Channel:   channel,
+		Publisher: "publisher-1",
+		Protocol:  "mqtt",
+		Payload:   pld,
+		Created:   now,
+	}
+
+	formatMsg := msg
+	formatMsg.Subtopic = "home.telemetry"
+
+	unknownMsg := msg
+	unknownMsg.Channel = "channel-2"
+
+	invalidMsg := msg
+	invalidMsg.Payload = []byte{0xff, 0xff, 0xff}
+
+	base := json.Message{
+		Channel:   channel,
+		Created:   now,
+		Publisher: "publisher-1",
+		Protocol:  "mqtt",
+	}
+	flat := base
+	flat.Payload = map[string]interface{}{
+		"sensor":      "temperature",
+		"temperature": 21.5,
+		"uptime":      float64(3600),
+		"alarm":       true,
+		"loc/x":       1.0,
+		"loc/y":       2.0,
+		"readings":    []interface{}{float64(4), float64(5)},
+	}
+	nested := base
+	nested.Payload = map[string]interface{}{
+		"sensor":      "temperature",
+		"temperature": 21.5,
+		"uptime":      float64(3600),
+		"alarm":       true,
+		"loc": map[string]interface{}{
+			"x": 1.0,
+			"y": 2.0,
+		},
+		"readings": []interface{}{float64(4), float64(5)},
+	}
+	formatted := flat
+	formatted.Subtopic = "home.telemetry"
+
+	cases := []struct {
+		desc string
+		tr   transformers.Transformer
+		msg  messaging.Message
+		res  interface{}
+		err  error
+	}{
+		{
+			desc: "transform protobuf message",
+			tr:   protobuf.New(schemas),
+			msg:  msg,
+			res:  json.Messages{Data: []json.Message{flat}, Format: "Telemetry"},
+			err:  nil,
+		},
+		{
+			desc: "transform protobuf message with format in subtopic",
+			tr:   protobuf.New(schemas),
+			msg:  formatMsg,
+			res:  json.Messages{Data: []json.Message{formatted}, Format: "telemetry"},
+			err:  nil,
+		},
+		{
+			desc: "transform protobuf message keeping nested messages",
+			tr:   protobuf.NewNested(schemas),
+			msg:  msg,
+			res:  json.Messages{Data: []json.Message{nested}, Format: "Telemetry"},
+			err:  nil,
+		},
+		{
+			desc: "transform protobuf message of channel without schema",
+			tr:   protobuf.New(schemas),
+			msg:  unknownMsg,
+			res:  nil,
+			err:  protobuf.ErrNotFound,
+		},
+		{
+			desc: "transform invalid protobuf message",
+			tr:   protobuf.New(schemas),
+			msg:  invalidMsg,
+			res:  nil,
+			err:  protobuf.ErrTransform,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := tc.tr.Transform(tc.msg)
+		assert.Equal(t, tc.res, res, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.res, res))
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	raw, err := proto.Marshal(descriptor())
+	require.Nil(t, err, fmt.Sprintf("unexpected error marshaling descriptor: %s", err))
+
+	cases := []struct {
+		desc   string
+		schema protobuf.Schema
+		err    error
+	}{
+		{
+			desc:   "validate schema",
+			schema: protobuf.Schema{Channel: channel, Message: msgType, Descriptor: raw},
+			err:    nil,
+		},
+		{
+			desc:   "validate schema with unknown message type",
+			schema: protobuf.Schema{Channel: channel, Message: "telemetry.Unknown", Descriptor: raw},
+			err:    protobuf.ErrMalformedSchema,
+		},
+		{
+			desc:   "validate schema with invalid descriptor",
+			schema: protobuf.Schema{Channel: channel, Message: msgType, Descriptor: []byte{0xff}},
+			err:    protobuf.ErrMalformedSchema,
+		},
+	}
+
+	for _, tc := range cases {
+		err := tc.schema.Validate()
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+	}
+}
@@ -1,7 +1,10 @@
 package senml
 
-// Message represents a resolved (normalized) SenML record.
+// Message represents a resolved (normalized) SenML record. ID is the ID of
+// the received message, which is shared by the records of a SenML pack and
+// isn't stored.
 type Message struct {
+	ID          string   `json:"-" db:"-" bson:"-"`
 	Channel     string   `json:"channel,omitempty" db:"channel" bson:"channel"`
 	Subtopic    string   `json:"subtopic,omitempty" db:"subtopic" bson:"subtopic,omitempty"`
 	Publisher   string   `json:"publisher,omitempty" db:"publisher" bson:"publisher"`
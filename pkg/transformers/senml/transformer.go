@@ -29,9 +29,13 @@ var formats = map[string]senml.Format{
 
 type transformer struct {
 	format senml.Format
+	units  map[string]Conversion
+	strict bool
 }
 
-// New returns transformer service implementation for SenML messages.
+// New returns transformer service implementation for SenML messages. The
+// content format is used for the messages which don't carry the content
+// type advertised by the publisher.
 func New(contentFormat string) transformers.Transformer {
 	format, ok := formats[contentFormat]
 	if !ok {
@@ -43,8 +47,44 @@ func New(contentFormat string) transformers.Transformer {
 	}
 }
 
+// NewNormalized returns transformer service implementation for SenML messages
+// which converts the units of the normalized records using the conversion
+// table, so the messages of the heterogeneous devices are stored in the
+// consistent units. Units without a conversion are kept unchanged.
+func NewNormalized(contentFormat string, conversions []Conversion) transformers.Transformer {
+	t := New(contentFormat).(transformer)
+	t.units = make(map[string]Conversion, len(conversions))
+	for _, c := range conversions {
+		t.units[c.From] = c
+	}
+
+	return t
+}
+
+// NewStrict returns transformer service implementation for SenML messages
+// which rejects the packs violating RFC 8428, reporting all the invalid
+// records using ValidationError, instead of silently dropping the unknown
+// fields. The units are converted using the conversion table, if any.
+func NewStrict(contentFormat string, conversions []Conversion) transformers.Transformer {
+	t := NewNormalized(contentFormat, conversions).(transformer)
+	t.strict = true
+
+	return t
+}
+
 func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
-	raw, err := senml.Decode(msg.Payload, t.format)
+	format := t.format
+	if f, ok := formats[msg.ContentType]; ok {
+		format = f
+	}
+
+	if t.strict {
+		if err := validate(msg.Payload, format); err != nil {
+			return nil, err
+		}
+	}
+
+	raw, err := senml.Decode(msg.Payload, format)
 	if err != nil {
 		return nil, errors.Wrap(errDecode, err)
 	}
@@ -54,6 +94,7 @@ func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
 		return nil, errors.Wrap(errNormalize, err)
 	}
 
+	units := t.units
 	msgs := make([]Message, len(normalized.Records))
 	for i, v := range normalized.Records {
 		// Use reception timestamp if SenML messsage Time is missing
@@ -64,6 +105,7 @@ func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
 		}
 
 		msgs[i] = Message{
+			ID:          msg.Id,
 			Channel:     msg.Channel,
 			Subtopic:    msg.Subtopic,
 			Publisher:   msg.Publisher,
@@ -78,6 +120,7 @@ func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
 			StringValue: v.StringValue,
 			Sum:         v.Sum,
 		}
+		convert(&msgs[i], units)
 	}
 
 	return msgs, nil
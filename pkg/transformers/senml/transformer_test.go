@@ -10,6 +10,7 @@ import (
 
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	mfsenml "github.com/mainflux/senml"
 	"github.com/stretchr/testify/assert"
@@ -108,6 +109,9 @@ func TestTransformCBOR(t *testing.T) {
 	tooManyMsg := msg
 	tooManyMsg.Payload = tooManyBytes
 
+	advertisedPld := cborPld
+	advertisedPld.ContentType = senml.CBOR
+
 	val := 52.0
 	sum := 110.0
 	msgs := []senml.Message{
@@ -127,18 +131,28 @@ func TestTransformCBOR(t *testing.T) {
 
 	cases := []struct {
 		desc string
+		tr   transformers.Transformer
 		msg  messaging.Message
 		msgs interface{}
 		err  error
 	}{
 		{
 			desc: "test normalize CBOR",
+			tr:   tr,
 			msg:  cborPld,
 			msgs: msgs,
 			err:  nil,
 		},
+		{
+			desc: "test normalize CBOR with content type advertised by publisher",
+			tr:   senml.New(senml.JSON),
+			msg:  advertisedPld,
+			msgs: msgs,
+			err:  nil,
+		},
 		{
 			desc: "test invalid payload",
+			tr:   tr,
 			msg:  tooManyMsg,
 			msgs: nil,
 			err:  mfsenml.ErrTooManyValues,
@@ -146,8 +160,92 @@ func TestTransformCBOR(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		msgs, err := tr.Transform(tc.msg)
+		msgs, err := tc.tr.Transform(tc.msg)
 		assert.Equal(t, tc.msgs, msgs, fmt.Sprintf("%s expected %v, got %v", tc.desc, tc.msgs, msgs))
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s, got %s", tc.desc, tc.err, err))
 	}
 }
+
+func TestTransformUnits(t *testing.T) {
+	pld := []byte(`[{"bn":"sensor:","n":"temperature","u":"degF","v":212,"t":100},` +
+		`{"n":"voltage","u":"mV","v":3300,"s":6600,"t":100},` +
+		`{"n":"humidity","u":"%RH","v":40,"t":100}]`)
+	msg := messaging.Message{
+		Channel:   "channel",
+		Subtopic:  "subtopic",
+		Publisher: "publisher",
+		Protocol:  "protocol",
+		Payload:   pld,
+	}
+
+	record := func(name, unit string, v float64, s *float64) senml.Message {
+		return senml.Message{
+			Channel:   "channel",
+			Subtopic:  "subtopic",
+			Publisher: "publisher",
+			Protocol:  "protocol",
+			Name:      name,
+			Unit:      unit,
+			Time:      100,
+			Value:     &v,
+			Sum:       s,
+		}
+	}
+	sum, convertedSum := 6600.0, 6.6
+
+	conversions := []senml.Conversion{senml.FahrenheitToCelsius, senml.MillivoltToVolt}
+
+	cases := []struct {
+		desc string
+		tr   transformers.Transformer
+		msgs interface{}
+	}{
+		{
+			desc: "test normalize units using conversion table",
+			tr:   senml.NewNormalized(senml.JSON, conversions),
+			msgs: []senml.Message{
+				record("sensor:temperature", "Cel", 100, nil),
+				record("sensor:voltage", "V", 3.3, &convertedSum),
+				record("sensor:humidity", "%RH", 40, nil),
+			},
+		},
+		{
+			desc: "test normalize units using empty conversion table",
+			tr:   senml.NewNormalized(senml.JSON, nil),
+			msgs: []senml.Message{
+				record("sensor:temperature", "degF", 212, nil),
+				record("sensor:voltage", "mV", 3300, &sum),
+				record("sensor:humidity", "%RH", 40, nil),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		msgs, err := tc.tr.Transform(msg)
+		assert.Nil(t, err, fmt.Sprintf("%s expected no error, got %s", tc.desc, err))
+		assert.InDeltaSlice(t, values(tc.msgs), values(msgs), 1e-9, fmt.Sprintf("%s expected %v, got %v", tc.desc, tc.msgs, msgs))
+		assert.Equal(t, strip(tc.msgs), strip(msgs), fmt.Sprintf("%s expected %v, got %v", tc.desc, tc.msgs, msgs))
+	}
+}
+
+// values returns the values and the sums of the messages, so they can be
+// compared regardless of the floating point conversion error.
+func values(msgs interface{}) []float64 {
+	var ret []float64
+	for _, m := range msgs.([]senml.Message) {
+		ret = append(ret, *m.Value)
+		if m.Sum != nil {
+			ret = append(ret, *m.Sum)
+		}
+	}
+	return ret
+}
+
+func strip(msgs interface{}) []senml.Message {
+	var ret []senml.Message
+	for _, m := range msgs.([]senml.Message) {
+		m.Value, m.Sum = nil, nil
+		ret = append(ret, m)
+	}
+	return ret
+}
@@ -0,0 +1,42 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package senml
+
+// Conversion converts the values of the records in one unit to another unit,
+// as `To = From * Scale + Offset`. Sums are only scaled, since the offset
+// doesn't apply to the integrals of the values.
+type Conversion struct {
+	From   string
+	To     string
+	Scale  float64
+	Offset float64
+}
+
+// Common conversions to the units registered by SenML (RFC 8428).
+var (
+	FahrenheitToCelsius = Conversion{From: "degF", To: "Cel", Scale: 5.0 / 9, Offset: -160.0 / 9}
+	KelvinToCelsius     = Conversion{From: "K", To: "Cel", Scale: 1, Offset: -273.15}
+	MillivoltToVolt     = Conversion{From: "mV", To: "V", Scale: 0.001}
+	MilliampereToAmpere = Conversion{From: "mA", To: "A", Scale: 0.001}
+	KilowattToWatt      = Conversion{From: "kW", To: "W", Scale: 1000}
+	HectopascalToPascal = Conversion{From: "hPa", To: "Pa", Scale: 100}
+)
+
+// convert applies the conversion of the message unit, if there is any.
+func convert(msg *Message, units map[string]Conversion) {
+	c, ok := units[msg.Unit]
+	if !ok {
+		return
+	}
+
+	msg.Unit = c.To
+	if msg.Value != nil {
+		v := *msg.Value*c.Scale + c.Offset
+		msg.Value = &v
+	}
+	if msg.Sum != nil {
+		s := *msg.Sum * c.Scale
+		msg.Sum = &s
+	}
+}
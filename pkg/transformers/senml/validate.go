@@ -0,0 +1,277 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package senml
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/senml"
+)
+
+const (
+	// Highest base version supported by the transformer.
+	maxVersion = 10
+	// Times below 2^28 are relative to the current time (RFC 8428 4.5.3).
+	relativeTime = 1 << 28
+)
+
+var (
+	// ErrValidation indicates that the SenML pack doesn't satisfy RFC 8428.
+	ErrValidation = errors.New("invalid senml pack")
+
+	errUnsupportedFormat = errors.New("unsupported senml content type")
+)
+
+var nameRegExp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9\-:./_]*$`)
+
+// Labels of the fields of the SenML JSON records.
+var labels = map[string]bool{
+	"bn": true, "bt": true, "bu": true, "bver": true, "bv": true, "bs": true,
+	"n": true, "u": true, "v": true, "vs": true, "vb": true, "vd": true,
+	"s": true, "t": true, "ut": true, "l": true,
+}
+
+// Labels of the fields of the SenML CBOR records (RFC 8428 6).
+var cborLabels = map[int64]string{
+	-1: "bver", -2: "bn", -3: "bt", -4: "bu", -5: "bv", -6: "bs",
+	0: "n", 1: "u", 2: "v", 3: "vs", 4: "vb", 5: "s", 6: "t", 7: "ut", 8: "vd",
+}
+
+// RecordError describes the RFC 8428 constraint violated by the record.
+type RecordError struct {
+	// Record is the index of the record in the pack.
+	Record int `json:"record"`
+
+	// Field is the label of the invalid field, if the error is related to
+	// the single field.
+	Field string `json:"field,omitempty"`
+
+	// Reason describes the violated constraint.
+	Reason string `json:"reason"`
+}
+
+var _ errors.Error = (*ValidationError)(nil)
+
+// ValidationError contains the errors of all the invalid records of the
+// pack, so that the publisher can fix them at once.
+type ValidationError struct {
+	Records []RecordError `json:"records"`
+}
+
+// Error implements the error interface.
+func (ve *ValidationError) Error() string {
+	parts := make([]string, len(ve.Records))
+	for i, re := range ve.Records {
+		parts[i] = fmt.Sprintf("record %d", re.Record)
+		if re.Field != "" {
+			parts[i] += fmt.Sprintf(" field %s", re.Field)
+		}
+		parts[i] += ": " + re.Reason
+	}
+	return ErrValidation.Error() + " : " + strings.Join(parts, "; ")
+}
+
+// Msg returns the error message, so that the validation errors can be
+// matched against ErrValidation.
+func (ve *ValidationError) Msg() string {
+	return ErrValidation.Error()
+}
+
+// Err returns wrapped error.
+func (ve *ValidationError) Err() errors.Error {
+	return nil
+}
+
+func (ve *ValidationError) add(record int, field, reason string) {
+	ve.Records = append(ve.Records, RecordError{Record: record, Field: field, Reason: reason})
+}
+
+// Validate checks if the SenML payload of the given content type satisfies
+// RFC 8428: names resolve to valid names, times resolve to absolute times,
+// each record has exactly one value or a sum, base version doesn't change
+// and records contain no unknown fields. All the invalid records are
+// reported using ValidationError.
+func Validate(payload []byte, contentType string) error {
+	format, ok := formats[contentType]
+	if !ok {
+		return errUnsupportedFormat
+	}
+
+	return validate(payload, format)
+}
+
+func validate(payload []byte, format senml.Format) error {
+	var recs []senml.Record
+	var err error
+	ve := &ValidationError{}
+	switch format {
+	case senml.CBOR:
+		recs, err = decodeCBOR(payload, ve)
+	default:
+		recs, err = decodeJSON(payload, ve)
+	}
+	if err != nil {
+		return errors.Wrap(ErrValidation, err)
+	}
+
+	validateRecords(recs, ve)
+	if len(ve.Records) > 0 {
+		sort.SliceStable(ve.Records, func(i, j int) bool {
+			return ve.Records[i].Record < ve.Records[j].Record
+		})
+		return ve
+	}
+
+	return nil
+}
+
+// decodeJSON decodes the records one by one, reporting the unknown fields
+// and the records which can't be decoded.
+func decodeJSON(payload []byte, ve *ValidationError) ([]senml.Record, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, err
+	}
+
+	recs := make([]senml.Record, len(raw))
+	for i, r := range raw {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(r, &fields); err != nil {
+			ve.add(i, "", "record is not an object")
+			continue
+		}
+		for l := range fields {
+			if !labels[l] {
+				ve.add(i, l, unknownReason(l))
+			}
+		}
+		if err := json.Unmarshal(r, &recs[i]); err != nil {
+			ve.add(i, "", fmt.Sprintf("malformed record: %s", err))
+		}
+	}
+
+	return recs, nil
+}
+
+func decodeCBOR(payload []byte, ve *ValidationError) ([]senml.Record, error) {
+	var raw []cbor.RawMessage
+	if err := cbor.Unmarshal(payload, &raw); err != nil {
+		return nil, err
+	}
+
+	recs := make([]senml.Record, len(raw))
+	for i, r := range raw {
+		var fields map[interface{}]cbor.RawMessage
+		if err := cbor.Unmarshal(r, &fields); err != nil {
+			ve.add(i, "", "record is not a map")
+			continue
+		}
+		for k := range fields {
+			if l, ok := cborLabel(k); !ok {
+				ve.add(i, l, unknownReason(l))
+			}
+		}
+		if err := cbor.Unmarshal(r, &recs[i]); err != nil {
+			ve.add(i, "", fmt.Sprintf("malformed record: %s", err))
+		}
+	}
+
+	return recs, nil
+}
+
+func cborLabel(k interface{}) (string, bool) {
+	var n int64
+	switch key := k.(type) {
+	case int64:
+		n = key
+	case uint64:
+		if key > math.MaxInt64 {
+			return fmt.Sprint(key), false
+		}
+		n = int64(key)
+	default:
+		return fmt.Sprint(key), false
+	}
+	l, ok := cborLabels[n]
+	if !ok {
+		return fmt.Sprint(n), false
+	}
+	return l, true
+}
+
+// unknownReason distinguishes the fields which must be understood by the
+// recipient (labels ending with "_") from the fields which would otherwise
+// be silently dropped.
+func unknownReason(label string) string {
+	if strings.HasSuffix(label, "_") {
+		return "unsupported must-understand field"
+	}
+	return "unknown field"
+}
+
+func validateRecords(recs []senml.Record, ve *ValidationError) {
+	var bver uint
+	var bname string
+	var btime, bsum float64
+	for i, r := range recs {
+		if r.BaseVersion != 0 {
+			switch {
+			case r.BaseVersion > maxVersion:
+				ve.add(i, "bver", fmt.Sprintf("unsupported version %d", r.BaseVersion))
+			case bver != 0 && r.BaseVersion != bver:
+				ve.add(i, "bver", "version change")
+			}
+			if bver == 0 {
+				bver = r.BaseVersion
+			}
+		}
+		if r.BaseName != "" {
+			bname = r.BaseName
+		}
+		if r.BaseTime != 0 {
+			btime = r.BaseTime
+		}
+		if r.BaseSum != 0 {
+			bsum = r.BaseSum
+		}
+
+		switch name := bname + r.Name; {
+		case name == "":
+			ve.add(i, "n", "empty name")
+		case !nameRegExp.MatchString(name):
+			ve.add(i, "n", fmt.Sprintf("invalid name %s", name))
+		}
+
+		values := 0
+		for _, set := range []bool{r.Value != nil, r.StringValue != nil, r.BoolValue != nil, r.DataValue != nil} {
+			if set {
+				values++
+			}
+		}
+		switch {
+		case values > 1:
+			ve.add(i, "", "more than one value field")
+		case values == 0 && r.Sum == nil && bsum == 0:
+			ve.add(i, "", "no value or sum field")
+		}
+		if r.BaseValue != 0 && r.Value == nil && values > 0 {
+			ve.add(i, "bv", "base value of non-numeric value")
+		}
+
+		// Zero time is allowed, since it's replaced by the reception time.
+		if t := btime + r.Time; t != 0 && math.Abs(t) < relativeTime {
+			ve.add(i, "t", fmt.Sprintf("relative time %v", t))
+		}
+		if r.UpdateTime < 0 {
+			ve.add(i, "ut", "negative update time")
+		}
+	}
+}
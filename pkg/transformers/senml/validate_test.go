@@ -0,0 +1,112 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package senml_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	cborPld, err := cbor.Marshal([]map[interface{}]interface{}{
+		{-2: "dev:", 0: "temp", 2: 21.5, 6: 1600000000.0},
+		{0: "hum", 2: 40.0, 6: 100.0, 9: "unknown"},
+	})
+	require.Nil(t, err, fmt.Sprintf("unexpected error encoding CBOR: %s", err))
+
+	cases := []struct {
+		desc    string
+		payload []byte
+		ct      string
+		records []senml.RecordError
+		err     error
+	}{
+		{
+			desc:    "validate valid JSON pack",
+			payload: []byte(`[{"bn":"dev:","bt":1600000000,"n":"temp","u":"Cel","v":21.5},{"n":"hum","t":10,"v":40}]`),
+			ct:      senml.JSON,
+			err:     nil,
+		},
+		{
+			desc:    "validate JSON pack without time",
+			payload: []byte(`[{"n":"temp","v":21.5}]`),
+			ct:      senml.JSON,
+			err:     nil,
+		},
+		{
+			desc:    "validate JSON pack with invalid records",
+			payload: []byte(`[{"bn":"dev:","bver":10,"n":"temp","v":21.5,"vs":"x","t":1600000000},{"bver":5,"v":1,"t":10,"x_":1},{"n":"temp!","vs":"on","ut":-1,"t":1600000000,"foo":1}]`),
+			ct:      senml.JSON,
+			records: []senml.RecordError{
+				{Record: 0, Reason: "more than one value field"},
+				{Record: 1, Field: "x_", Reason: "unsupported must-understand field"},
+				{Record: 1, Field: "bver", Reason: "version change"},
+				{Record: 1, Field: "t", Reason: "relative time 10"},
+				{Record: 2, Field: "foo", Reason: "unknown field"},
+				{Record: 2, Field: "n", Reason: "invalid name dev:temp!"},
+				{Record: 2, Field: "ut", Reason: "negative update time"},
+			},
+			err: senml.ErrValidation,
+		},
+		{
+			desc:    "validate JSON pack with record without name and value",
+			payload: []byte(`[{"u":"Cel","t":1600000000}]`),
+			ct:      senml.JSON,
+			records: []senml.RecordError{
+				{Record: 0, Field: "n", Reason: "empty name"},
+				{Record: 0, Reason: "no value or sum field"},
+			},
+			err: senml.ErrValidation,
+		},
+		{
+			desc:    "validate malformed JSON pack",
+			payload: []byte(`{"n":"temp"}`),
+			ct:      senml.JSON,
+			err:     senml.ErrValidation,
+		},
+		{
+			desc:    "validate CBOR pack with invalid records",
+			payload: cborPld,
+			ct:      senml.CBOR,
+			records: []senml.RecordError{
+				{Record: 1, Field: "9", Reason: "unknown field"},
+				{Record: 1, Field: "t", Reason: "relative time 100"},
+			},
+			err: senml.ErrValidation,
+		},
+	}
+
+	for _, tc := range cases {
+		err := senml.Validate(tc.payload, tc.ct)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		if tc.records == nil {
+			continue
+		}
+		ve, ok := err.(*senml.ValidationError)
+		require.True(t, ok, fmt.Sprintf("%s: expected validation error got %s", tc.desc, err))
+		assert.ElementsMatch(t, tc.records, ve.Records, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.records, ve.Records))
+	}
+}
+
+func TestTransformStrict(t *testing.T) {
+	msg := messaging.Message{
+		Channel:   "channel",
+		Publisher: "publisher",
+		Protocol:  "protocol",
+		Payload:   []byte(`[{"n":"temp","v":21.5,"t":1600000000,"foo":1}]`),
+	}
+
+	_, err := senml.New(senml.JSON).Transform(msg)
+	assert.Nil(t, err, fmt.Sprintf("expected unknown field to be dropped got %s", err))
+
+	_, err = senml.NewStrict(senml.JSON, nil).Transform(msg)
+	assert.True(t, errors.Contains(err, senml.ErrValidation), fmt.Sprintf("expected %s got %s", senml.ErrValidation, err))
+}
@@ -0,0 +1,2106 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: readers.proto
+
+package mainflux
+
+import (
+	context "context"
+	encoding_binary "encoding/binary"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// The token is the thing key used to access the channel. Filters are the
+// same as the ones of the readers HTTP API. Messages are streamed in
+// chronological order by default, starting at the offset, up to the limit if
+// it is set.
+type ReadMessagesReq struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	ChanID               string   `protobuf:"bytes,2,opt,name=chanID,proto3" json:"chanID,omitempty"`
+	Offset               uint64   `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit                uint64   `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Format               string   `protobuf:"bytes,5,opt,name=format,proto3" json:"format,omitempty"`
+	Subtopic             string   `protobuf:"bytes,6,opt,name=subtopic,proto3" json:"subtopic,omitempty"`
+	Publisher            string   `protobuf:"bytes,7,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	Protocol             string   `protobuf:"bytes,8,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Name                 string   `protobuf:"bytes,9,opt,name=name,proto3" json:"name,omitempty"`
+	Value                float64  `protobuf:"fixed64,10,opt,name=value,proto3" json:"value,omitempty"`
+	Comparator           string   `protobuf:"bytes,11,opt,name=comparator,proto3" json:"comparator,omitempty"`
+	BoolValue            bool     `protobuf:"varint,12,opt,name=boolValue,proto3" json:"boolValue,omitempty"`
+	StringValue          string   `protobuf:"bytes,13,opt,name=stringValue,proto3" json:"stringValue,omitempty"`
+	DataValue            string   `protobuf:"bytes,14,opt,name=dataValue,proto3" json:"dataValue,omitempty"`
+	From                 float64  `protobuf:"fixed64,15,opt,name=from,proto3" json:"from,omitempty"`
+	To                   float64  `protobuf:"fixed64,16,opt,name=to,proto3" json:"to,omitempty"`
+	Dir                  string   `protobuf:"bytes,17,opt,name=dir,proto3" json:"dir,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadMessagesReq) Reset()         { *m = ReadMessagesReq{} }
+func (m *ReadMessagesReq) String() string { return proto.CompactTextString(m) }
+func (*ReadMessagesReq) ProtoMessage()    {}
+func (*ReadMessagesReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_20c42f9840d95f21, []int{0}
+}
+func (m *ReadMessagesReq) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ReadMessagesReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ReadMessagesReq.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ReadMessagesReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadMessagesReq.Merge(m, src)
+}
+func (m *ReadMessagesReq) XXX_Size() int {
+	return m.Size()
+}
+func (m *ReadMessagesReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadMessagesReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadMessagesReq proto.InternalMessageInfo
+
+func (m *ReadMessagesReq) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *ReadMessagesReq) GetChanID() string {
+	if m != nil {
+		return m.ChanID
+	}
+	return ""
+}
+
+func (m *ReadMessagesReq) GetOffset() uint64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *ReadMessagesReq) GetLimit() uint64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ReadMessagesReq) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *ReadMessagesReq) GetSubtopic() string {
+	if m != nil {
+		return m.Subtopic
+	}
+	return ""
+}
+
+func (m *ReadMessagesReq) GetPublisher() string {
+	if m != nil {
+		return m.Publisher
+	}
+	return ""
+}
+
+func (m *ReadMessagesReq) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *ReadMessagesReq) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ReadMessagesReq) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *ReadMessagesReq) GetComparator() string {
+	if m != nil {
+		return m.Comparator
+	}
+	return ""
+}
+
+func (m *ReadMessagesReq) GetBoolValue() bool {
+	if m != nil {
+		return m.BoolValue
+	}
+	return false
+}
+
+func (m *ReadMessagesReq) GetStringValue() string {
+	if m != nil {
+		return m.StringValue
+	}
+	return ""
+}
+
+func (m *ReadMessagesReq) GetDataValue() string {
+	if m != nil {
+		return m.DataValue
+	}
+	return ""
+}
+
+func (m *ReadMessagesReq) GetFrom() float64 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+func (m *ReadMessagesReq) GetTo() float64 {
+	if m != nil {
+		return m.To
+	}
+	return 0
+}
+
+func (m *ReadMessagesReq) GetDir() string {
+	if m != nil {
+		return m.Dir
+	}
+	return ""
+}
+
+// Stored message is either SenML message, or JSON message with the creation
+// time in nanoseconds and JSON encoded payload.
+type StoredMessage struct {
+	Channel    string  `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Subtopic   string  `protobuf:"bytes,2,opt,name=subtopic,proto3" json:"subtopic,omitempty"`
+	Publisher  string  `protobuf:"bytes,3,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	Protocol   string  `protobuf:"bytes,4,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Name       string  `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	Unit       string  `protobuf:"bytes,6,opt,name=unit,proto3" json:"unit,omitempty"`
+	Time       float64 `protobuf:"fixed64,7,opt,name=time,proto3" json:"time,omitempty"`
+	UpdateTime float64 `protobuf:"fixed64,8,opt,name=updateTime,proto3" json:"updateTime,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//	*StoredMessage_FloatValue
+	//	*StoredMessage_StringValue
+	//	*StoredMessage_BoolValue
+	//	*StoredMessage_DataValue
+	Value isStoredMessage_Value `protobuf_oneof:"value"`
+	// Types that are valid to be assigned to SumValue:
+	//	*StoredMessage_Sum
+	SumValue             isStoredMessage_SumValue `protobuf_oneof:"sumValue"`
+	Created              int64                    `protobuf:"varint,14,opt,name=created,proto3" json:"created,omitempty"`
+	Payload              []byte                   `protobuf:"bytes,15,opt,name=payload,proto3" json:"payload,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *StoredMessage) Reset()         { *m = StoredMessage{} }
+func (m *StoredMessage) String() string { return proto.CompactTextString(m) }
+func (*StoredMessage) ProtoMessage()    {}
+func (*StoredMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_20c42f9840d95f21, []int{1}
+}
+func (m *StoredMessage) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *StoredMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_StoredMessage.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *StoredMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StoredMessage.Merge(m, src)
+}
+func (m *StoredMessage) XXX_Size() int {
+	return m.Size()
+}
+func (m *StoredMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_StoredMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StoredMessage proto.InternalMessageInfo
+
+type isStoredMessage_Value interface {
+	isStoredMessage_Value()
+	MarshalTo([]byte) (int, error)
+	Size() int
+}
+type isStoredMessage_SumValue interface {
+	isStoredMessage_SumValue()
+	MarshalTo([]byte) (int, error)
+	Size() int
+}
+
+type StoredMessage_FloatValue struct {
+	FloatValue float64 `protobuf:"fixed64,9,opt,name=floatValue,proto3,oneof" json:"floatValue,omitempty"`
+}
+type StoredMessage_StringValue struct {
+	StringValue string `protobuf:"bytes,10,opt,name=stringValue,proto3,oneof" json:"stringValue,omitempty"`
+}
+type StoredMessage_BoolValue struct {
+	BoolValue bool `protobuf:"varint,11,opt,name=boolValue,proto3,oneof" json:"boolValue,omitempty"`
+}
+type StoredMessage_DataValue struct {
+	DataValue string `protobuf:"bytes,12,opt,name=dataValue,proto3,oneof" json:"dataValue,omitempty"`
+}
+type StoredMessage_Sum struct {
+	Sum float64 `protobuf:"fixed64,13,opt,name=sum,proto3,oneof" json:"sum,omitempty"`
+}
+
+func (*StoredMessage_FloatValue) isStoredMessage_Value()  {}
+func (*StoredMessage_StringValue) isStoredMessage_Value() {}
+func (*StoredMessage_BoolValue) isStoredMessage_Value()   {}
+func (*StoredMessage_DataValue) isStoredMessage_Value()   {}
+func (*StoredMessage_Sum) isStoredMessage_SumValue()      {}
+
+func (m *StoredMessage) GetValue() isStoredMessage_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+func (m *StoredMessage) GetSumValue() isStoredMessage_SumValue {
+	if m != nil {
+		return m.SumValue
+	}
+	return nil
+}
+
+func (m *StoredMessage) GetChannel() string {
+	if m != nil {
+		return m.Channel
+	}
+	return ""
+}
+
+func (m *StoredMessage) GetSubtopic() string {
+	if m != nil {
+		return m.Subtopic
+	}
+	return ""
+}
+
+func (m *StoredMessage) GetPublisher() string {
+	if m != nil {
+		return m.Publisher
+	}
+	return ""
+}
+
+func (m *StoredMessage) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *StoredMessage) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StoredMessage) GetUnit() string {
+	if m != nil {
+		return m.Unit
+	}
+	return ""
+}
+
+func (m *StoredMessage) GetTime() float64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *StoredMessage) GetUpdateTime() float64 {
+	if m != nil {
+		return m.UpdateTime
+	}
+	return 0
+}
+
+func (m *StoredMessage) GetFloatValue() float64 {
+	if x, ok := m.GetValue().(*StoredMessage_FloatValue); ok {
+		return x.FloatValue
+	}
+	return 0
+}
+
+func (m *StoredMessage) GetStringValue() string {
+	if x, ok := m.GetValue().(*StoredMessage_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (m *StoredMessage) GetBoolValue() bool {
+	if x, ok := m.GetValue().(*StoredMessage_BoolValue); ok {
+		return x.BoolValue
+	}
+	return false
+}
+
+func (m *StoredMessage) GetDataValue() string {
+	if x, ok := m.GetValue().(*StoredMessage_DataValue); ok {
+		return x.DataValue
+	}
+	return ""
+}
+
+func (m *StoredMessage) GetSum() float64 {
+	if x, ok := m.GetSumValue().(*StoredMessage_Sum); ok {
+		return x.Sum
+	}
+	return 0
+}
+
+func (m *StoredMessage) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *StoredMessage) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*StoredMessage) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*StoredMessage_FloatValue)(nil),
+		(*StoredMessage_StringValue)(nil),
+		(*StoredMessage_BoolValue)(nil),
+		(*StoredMessage_DataValue)(nil),
+		(*StoredMessage_Sum)(nil),
+	}
+}
+
+func init() {
+	proto.RegisterType((*ReadMessagesReq)(nil), "mainflux.ReadMessagesReq")
+	proto.RegisterType((*StoredMessage)(nil), "mainflux.StoredMessage")
+}
+
+func init() { proto.RegisterFile("readers.proto", fileDescriptor_20c42f9840d95f21) }
+
+var fileDescriptor_20c42f9840d95f21 = []byte{
+	// 517 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x93, 0xcf, 0x8e, 0xd3, 0x30,
+	0x10, 0xc6, 0xeb, 0xa6, 0x7f, 0xd2, 0xd9, 0xb6, 0x5b, 0x2c, 0x04, 0x66, 0x85, 0xa2, 0xa8, 0xa7,
+	0x9e, 0x2a, 0x04, 0x6f, 0x50, 0x21, 0x54, 0x0e, 0x5c, 0xbc, 0x08, 0x71, 0x75, 0x1b, 0x67, 0xd7,
+	0x22, 0x89, 0x83, 0xe3, 0xac, 0xe0, 0x4d, 0x78, 0x15, 0x2e, 0x9c, 0x39, 0xf2, 0x08, 0xa8, 0xbc,
+	0x08, 0xf2, 0x38, 0xd9, 0x26, 0x48, 0xec, 0x6d, 0xbe, 0x6f, 0x32, 0x96, 0xf3, 0xfd, 0x3c, 0xb0,
+	0x30, 0x52, 0x24, 0xd2, 0x54, 0xdb, 0xd2, 0x68, 0xab, 0x69, 0x98, 0x0b, 0x55, 0xa4, 0x59, 0xfd,
+	0x65, 0xfd, 0x3d, 0x80, 0x4b, 0x2e, 0x45, 0xf2, 0x4e, 0x56, 0x95, 0xb8, 0x91, 0x15, 0x97, 0x9f,
+	0xe9, 0x63, 0x18, 0x5b, 0xfd, 0x49, 0x16, 0x8c, 0xc4, 0x64, 0x33, 0xe3, 0x5e, 0xd0, 0x27, 0x30,
+	0x39, 0xde, 0x8a, 0xe2, 0xed, 0x6b, 0x36, 0x44, 0xbb, 0x51, 0xce, 0xd7, 0x69, 0x5a, 0x49, 0xcb,
+	0x82, 0x98, 0x6c, 0x46, 0xbc, 0x51, 0xee, 0x94, 0x4c, 0xe5, 0xca, 0xb2, 0x11, 0xda, 0x5e, 0xb8,
+	0xaf, 0x53, 0x6d, 0x72, 0x61, 0xd9, 0xd8, 0x9f, 0xe2, 0x15, 0xbd, 0x82, 0xb0, 0xaa, 0x0f, 0x56,
+	0x97, 0xea, 0xc8, 0x26, 0xd8, 0xb9, 0xd7, 0xf4, 0x39, 0xcc, 0xca, 0xfa, 0x90, 0xa9, 0xea, 0x56,
+	0x1a, 0x36, 0xc5, 0xe6, 0xd9, 0x70, 0x93, 0xf8, 0x53, 0x47, 0x9d, 0xb1, 0xd0, 0x4f, 0xb6, 0x9a,
+	0x52, 0x18, 0x15, 0x22, 0x97, 0x6c, 0x86, 0x3e, 0xd6, 0xee, 0x5e, 0x77, 0x22, 0xab, 0x25, 0x83,
+	0x98, 0x6c, 0x08, 0xf7, 0x82, 0x46, 0x00, 0x47, 0x9d, 0x97, 0xc2, 0x08, 0xab, 0x0d, 0xbb, 0xc0,
+	0xef, 0x3b, 0x8e, 0xbb, 0xc3, 0x41, 0xeb, 0xec, 0x03, 0x4e, 0xce, 0x63, 0xb2, 0x09, 0xf9, 0xd9,
+	0xa0, 0x31, 0x5c, 0x54, 0xd6, 0xa8, 0xe2, 0xc6, 0xf7, 0x17, 0x38, 0xde, 0xb5, 0xdc, 0x7c, 0x22,
+	0xac, 0xf0, 0xfd, 0xa5, 0xff, 0x87, 0x7b, 0xc3, 0xdd, 0x33, 0x35, 0x3a, 0x67, 0x97, 0x78, 0x25,
+	0xac, 0xe9, 0x12, 0x86, 0x56, 0xb3, 0x15, 0x3a, 0x43, 0xab, 0xe9, 0x0a, 0x82, 0x44, 0x19, 0xf6,
+	0x08, 0x67, 0x5d, 0xb9, 0xfe, 0x11, 0xc0, 0xe2, 0xda, 0x6a, 0x23, 0x5b, 0x7a, 0x94, 0xc1, 0xd4,
+	0x51, 0x29, 0x64, 0xd6, 0xb0, 0x6b, 0x65, 0x2f, 0xdf, 0xe1, 0x43, 0xf9, 0x06, 0x0f, 0xe5, 0x3b,
+	0xfa, 0x4f, 0xbe, 0xe3, 0x4e, 0xbe, 0x14, 0x46, 0x75, 0xa1, 0x6c, 0x43, 0x11, 0x6b, 0xe7, 0x59,
+	0x95, 0x4b, 0x84, 0x47, 0x38, 0xd6, 0x2e, 0xf1, 0xba, 0x4c, 0x84, 0x95, 0xef, 0x5d, 0x27, 0xc4,
+	0x4e, 0xc7, 0xa1, 0x31, 0x40, 0x9a, 0x69, 0x61, 0x7d, 0x64, 0x8e, 0x20, 0xd9, 0x0f, 0x78, 0xc7,
+	0xa3, 0xeb, 0x7e, 0xea, 0x8e, 0xe7, 0x6c, 0x3f, 0xe8, 0xe7, 0x1e, 0x75, 0xb9, 0x39, 0xac, 0xe1,
+	0x7e, 0xd0, 0x25, 0x17, 0x75, 0xb9, 0xcc, 0x9b, 0x13, 0x7a, 0x64, 0x82, 0xaa, 0xce, 0x91, 0x28,
+	0xd9, 0x13, 0xee, 0x04, 0xa6, 0x6c, 0xa4, 0xb0, 0x32, 0x41, 0x92, 0x01, 0x6f, 0xa5, 0xeb, 0x94,
+	0xe2, 0x6b, 0xa6, 0x45, 0x82, 0x28, 0xe7, 0xbc, 0x95, 0xbb, 0x69, 0xf3, 0xea, 0x76, 0xe0, 0x40,
+	0xe4, 0x78, 0xf8, 0xcb, 0x8f, 0xb0, 0xe4, 0x7e, 0x2f, 0xaf, 0xa5, 0xb9, 0x53, 0x47, 0x49, 0xdf,
+	0xc0, 0xbc, 0xbb, 0x8d, 0xf4, 0xd9, 0xb6, 0xdd, 0xd4, 0xed, 0x3f, 0x5b, 0x7a, 0xf5, 0xf4, 0xdc,
+	0xea, 0x3d, 0x82, 0xf5, 0xe0, 0x05, 0xd9, 0xad, 0x7e, 0x9e, 0x22, 0xf2, 0xeb, 0x14, 0x91, 0xdf,
+	0xa7, 0x88, 0x7c, 0xfb, 0x13, 0x0d, 0x0e, 0x13, 0x84, 0xf6, 0xea, 0xef, 0x00, 0xae, 0xd8, 0xca,
+	0xfe, 0x0a, 0x04, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ReadersServiceClient is the client API for ReadersService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ReadersServiceClient interface {
+	ReadMessages(ctx context.Context, in *ReadMessagesReq, opts ...grpc.CallOption) (ReadersService_ReadMessagesClient, error)
+}
+
+type readersServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewReadersServiceClient(cc *grpc.ClientConn) ReadersServiceClient {
+	return &readersServiceClient{cc}
+}
+
+func (c *readersServiceClient) ReadMessages(ctx context.Context, in *ReadMessagesReq, opts ...grpc.CallOption) (ReadersService_ReadMessagesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ReadersService_serviceDesc.Streams[0], "/mainflux.ReadersService/ReadMessages", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &readersServiceReadMessagesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ReadersService_ReadMessagesClient interface {
+	Recv() (*StoredMessage, error)
+	grpc.ClientStream
+}
+
+type readersServiceReadMessagesClient struct {
+	grpc.ClientStream
+}
+
+func (x *readersServiceReadMessagesClient) Recv() (*StoredMessage, error) {
+	m := new(StoredMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ReadersServiceServer is the server API for ReadersService service.
+type ReadersServiceServer interface {
+	ReadMessages(*ReadMessagesReq, ReadersService_ReadMessagesServer) error
+}
+
+// UnimplementedReadersServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedReadersServiceServer struct {
+}
+
+func (*UnimplementedReadersServiceServer) ReadMessages(req *ReadMessagesReq, srv ReadersService_ReadMessagesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReadMessages not implemented")
+}
+
+func RegisterReadersServiceServer(s *grpc.Server, srv ReadersServiceServer) {
+	s.RegisterService(&_ReadersService_serviceDesc, srv)
+}
+
+func _ReadersService_ReadMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadMessagesReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReadersServiceServer).ReadMessages(m, &readersServiceReadMessagesServer{stream})
+}
+
+type ReadersService_ReadMessagesServer interface {
+	Send(*StoredMessage) error
+	grpc.ServerStream
+}
+
+type readersServiceReadMessagesServer struct {
+	grpc.ServerStream
+}
+
+func (x *readersServiceReadMessagesServer) Send(m *StoredMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _ReadersService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "mainflux.ReadersService",
+	HandlerType: (*ReadersServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ReadMessages",
+			Handler:       _ReadersService_ReadMessages_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "readers.proto",
+}
+
+func (m *ReadMessagesReq) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReadMessagesReq) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ReadMessagesReq) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Dir) > 0 {
+		i -= len(m.Dir)
+		copy(dAtA[i:], m.Dir)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Dir)))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x8a
+	}
+	if m.To != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.To))))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x81
+	}
+	if m.From != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.From))))
+		i--
+		dAtA[i] = 0x79
+	}
+	if len(m.DataValue) > 0 {
+		i -= len(m.DataValue)
+		copy(dAtA[i:], m.DataValue)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.DataValue)))
+		i--
+		dAtA[i] = 0x72
+	}
+	if len(m.StringValue) > 0 {
+		i -= len(m.StringValue)
+		copy(dAtA[i:], m.StringValue)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.StringValue)))
+		i--
+		dAtA[i] = 0x6a
+	}
+	if m.BoolValue {
+		i--
+		if m.BoolValue {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x60
+	}
+	if len(m.Comparator) > 0 {
+		i -= len(m.Comparator)
+		copy(dAtA[i:], m.Comparator)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Comparator)))
+		i--
+		dAtA[i] = 0x5a
+	}
+	if m.Value != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Value))))
+		i--
+		dAtA[i] = 0x51
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0x4a
+	}
+	if len(m.Protocol) > 0 {
+		i -= len(m.Protocol)
+		copy(dAtA[i:], m.Protocol)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Protocol)))
+		i--
+		dAtA[i] = 0x42
+	}
+	if len(m.Publisher) > 0 {
+		i -= len(m.Publisher)
+		copy(dAtA[i:], m.Publisher)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Publisher)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if len(m.Subtopic) > 0 {
+		i -= len(m.Subtopic)
+		copy(dAtA[i:], m.Subtopic)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Subtopic)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.Format) > 0 {
+		i -= len(m.Format)
+		copy(dAtA[i:], m.Format)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Format)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.Limit != 0 {
+		i = encodeVarintReaders(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Offset != 0 {
+		i = encodeVarintReaders(dAtA, i, uint64(m.Offset))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.ChanID) > 0 {
+		i -= len(m.ChanID)
+		copy(dAtA[i:], m.ChanID)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.ChanID)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Token) > 0 {
+		i -= len(m.Token)
+		copy(dAtA[i:], m.Token)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Token)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *StoredMessage) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *StoredMessage) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *StoredMessage) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Payload) > 0 {
+		i -= len(m.Payload)
+		copy(dAtA[i:], m.Payload)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Payload)))
+		i--
+		dAtA[i] = 0x7a
+	}
+	if m.Created != 0 {
+		i = encodeVarintReaders(dAtA, i, uint64(m.Created))
+		i--
+		dAtA[i] = 0x70
+	}
+	if m.SumValue != nil {
+		{
+			size := m.SumValue.Size()
+			i -= size
+			if _, err := m.SumValue.MarshalTo(dAtA[i:]); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if m.Value != nil {
+		{
+			size := m.Value.Size()
+			i -= size
+			if _, err := m.Value.MarshalTo(dAtA[i:]); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if m.UpdateTime != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.UpdateTime))))
+		i--
+		dAtA[i] = 0x41
+	}
+	if m.Time != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Time))))
+		i--
+		dAtA[i] = 0x39
+	}
+	if len(m.Unit) > 0 {
+		i -= len(m.Unit)
+		copy(dAtA[i:], m.Unit)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Unit)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Protocol) > 0 {
+		i -= len(m.Protocol)
+		copy(dAtA[i:], m.Protocol)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Protocol)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Publisher) > 0 {
+		i -= len(m.Publisher)
+		copy(dAtA[i:], m.Publisher)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Publisher)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Subtopic) > 0 {
+		i -= len(m.Subtopic)
+		copy(dAtA[i:], m.Subtopic)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Subtopic)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Channel) > 0 {
+		i -= len(m.Channel)
+		copy(dAtA[i:], m.Channel)
+		i = encodeVarintReaders(dAtA, i, uint64(len(m.Channel)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *StoredMessage_FloatValue) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *StoredMessage_FloatValue) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	i -= 8
+	encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.FloatValue))))
+	i--
+	dAtA[i] = 0x49
+	return len(dAtA) - i, nil
+}
+func (m *StoredMessage_StringValue) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *StoredMessage_StringValue) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	i -= len(m.StringValue)
+	copy(dAtA[i:], m.StringValue)
+	i = encodeVarintReaders(dAtA, i, uint64(len(m.StringValue)))
+	i--
+	dAtA[i] = 0x52
+	return len(dAtA) - i, nil
+}
+func (m *StoredMessage_BoolValue) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *StoredMessage_BoolValue) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	i--
+	if m.BoolValue {
+		dAtA[i] = 1
+	} else {
+		dAtA[i] = 0
+	}
+	i--
+	dAtA[i] = 0x58
+	return len(dAtA) - i, nil
+}
+func (m *StoredMessage_DataValue) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *StoredMessage_DataValue) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	i -= len(m.DataValue)
+	copy(dAtA[i:], m.DataValue)
+	i = encodeVarintReaders(dAtA, i, uint64(len(m.DataValue)))
+	i--
+	dAtA[i] = 0x62
+	return len(dAtA) - i, nil
+}
+func (m *StoredMessage_Sum) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *StoredMessage_Sum) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	i -= 8
+	encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Sum))))
+	i--
+	dAtA[i] = 0x69
+	return len(dAtA) - i, nil
+}
+func encodeVarintReaders(dAtA []byte, offset int, v uint64) int {
+	offset -= sovReaders(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *ReadMessagesReq) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Token)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	l = len(m.ChanID)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	if m.Offset != 0 {
+		n += 1 + sovReaders(uint64(m.Offset))
+	}
+	if m.Limit != 0 {
+		n += 1 + sovReaders(uint64(m.Limit))
+	}
+	l = len(m.Format)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	l = len(m.Subtopic)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	l = len(m.Publisher)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	l = len(m.Protocol)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	if m.Value != 0 {
+		n += 9
+	}
+	l = len(m.Comparator)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	if m.BoolValue {
+		n += 2
+	}
+	l = len(m.StringValue)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	l = len(m.DataValue)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	if m.From != 0 {
+		n += 9
+	}
+	if m.To != 0 {
+		n += 10
+	}
+	l = len(m.Dir)
+	if l > 0 {
+		n += 2 + l + sovReaders(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *StoredMessage) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Channel)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	l = len(m.Subtopic)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	l = len(m.Publisher)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	l = len(m.Protocol)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	l = len(m.Unit)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	if m.Time != 0 {
+		n += 9
+	}
+	if m.UpdateTime != 0 {
+		n += 9
+	}
+	if m.Value != nil {
+		n += m.Value.Size()
+	}
+	if m.SumValue != nil {
+		n += m.SumValue.Size()
+	}
+	if m.Created != 0 {
+		n += 1 + sovReaders(uint64(m.Created))
+	}
+	l = len(m.Payload)
+	if l > 0 {
+		n += 1 + l + sovReaders(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *StoredMessage_FloatValue) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	n += 9
+	return n
+}
+func (m *StoredMessage_StringValue) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.StringValue)
+	n += 1 + l + sovReaders(uint64(l))
+	return n
+}
+func (m *StoredMessage_BoolValue) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	n += 2
+	return n
+}
+func (m *StoredMessage_DataValue) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.DataValue)
+	n += 1 + l + sovReaders(uint64(l))
+	return n
+}
+func (m *StoredMessage_Sum) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	n += 9
+	return n
+}
+
+func sovReaders(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozReaders(x uint64) (n int) {
+	return sovReaders(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *ReadMessagesReq) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowReaders
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReadMessagesReq: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReadMessagesReq: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Token", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Token = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChanID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ChanID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Offset", wireType)
+			}
+			m.Offset = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Offset |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Format", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Format = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Subtopic", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Subtopic = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Publisher", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Publisher = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocol", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Protocol = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Value = float64(math.Float64frombits(v))
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Comparator", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Comparator = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BoolValue", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.BoolValue = bool(v != 0)
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StringValue", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StringValue = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DataValue", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DataValue = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 15:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field From", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.From = float64(math.Float64frombits(v))
+		case 16:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field To", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.To = float64(math.Float64frombits(v))
+		case 17:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dir", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Dir = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipReaders(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StoredMessage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowReaders
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StoredMessage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StoredMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Channel", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Channel = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Subtopic", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Subtopic = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Publisher", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Publisher = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocol", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Protocol = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Unit", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Unit = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Time", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Time = float64(math.Float64frombits(v))
+		case 8:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UpdateTime", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.UpdateTime = float64(math.Float64frombits(v))
+		case 9:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FloatValue", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Value = &StoredMessage_FloatValue{float64(math.Float64frombits(v))}
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StringValue", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = &StoredMessage_StringValue{string(dAtA[iNdEx:postIndex])}
+			iNdEx = postIndex
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BoolValue", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			b := bool(v != 0)
+			m.Value = &StoredMessage_BoolValue{b}
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DataValue", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = &StoredMessage_DataValue{string(dAtA[iNdEx:postIndex])}
+			iNdEx = postIndex
+		case 13:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sum", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.SumValue = &StoredMessage_Sum{float64(math.Float64frombits(v))}
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Created", wireType)
+			}
+			m.Created = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Created |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Payload", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthReaders
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Payload = append(m.Payload[:0], dAtA[iNdEx:postIndex]...)
+			if m.Payload == nil {
+				m.Payload = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipReaders(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthReaders
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipReaders(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowReaders
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowReaders
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthReaders
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupReaders
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthReaders
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthReaders        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowReaders          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupReaders = fmt.Errorf("proto: unexpected end of group")
+)
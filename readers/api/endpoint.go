@@ -18,15 +18,123 @@ func listMessagesEndpoint(svc readers.MessageRepository) endpoint.Endpoint {
 			return nil, err
 		}
 
+		if req.pageMeta.Aggregation != "" {
+			page, err := svc.Aggregate(req.chanID, req.pageMeta)
+			if err != nil {
+				return nil, err
+			}
+
+			return aggregatesPageRes{
+				PageMetadata: page.PageMetadata,
+				Total:        page.Total,
+				Aggregates:   page.Aggregates,
+			}, nil
+		}
+
 		page, err := svc.ReadAll(req.chanID, req.pageMeta)
 		if err != nil {
 			return nil, err
 		}
 
 		return pageRes{
+			PageMetadata:  page.PageMetadata,
+			Total:         page.Total,
+			Messages:      page.Messages,
+			NextPageToken: page.NextPageToken,
+		}, nil
+	}
+}
+
+func listChannelsMessagesEndpoint(svc readers.MessageRepository) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(listChannelsMessagesReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page, err := readers.ReadChannels(svc, req.chanIDs, req.pageMeta)
+		if err != nil {
+			return nil, err
+		}
+
+		return channelsPageRes{
 			PageMetadata: page.PageMetadata,
 			Total:        page.Total,
+			Channels:     page.Channels,
 			Messages:     page.Messages,
 		}, nil
 	}
 }
+
+func summaryEndpoint(svc readers.MessageRepository) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(summaryReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		summary, err := svc.Summarize(req.chanID, req.pageMeta)
+		if err != nil {
+			return nil, err
+		}
+
+		return summaryRes{
+			Count:      summary.Count,
+			First:      summary.First,
+			Last:       summary.Last,
+			Publishers: summary.Publishers,
+		}, nil
+	}
+}
+
+func latestMessagesEndpoint(svc readers.MessageRepository) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(latestMessagesReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page, err := svc.ReadLatest(req.chanID, req.pageMeta)
+		if err != nil {
+			return nil, err
+		}
+
+		return pageRes{
+			PageMetadata: page.PageMetadata,
+			Total:        page.Total,
+			Messages:     page.Messages,
+		}, nil
+	}
+}
+
+func deleteMessagesEndpoint(svc readers.MessageRepository) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteMessagesReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		count, err := svc.Delete(req.chanID, req.pageMeta)
+		if err != nil {
+			return nil, err
+		}
+
+		return deleteMessagesRes{Removed: count}, nil
+	}
+}
+
+func exportMessagesEndpoint(svc readers.MessageRepository) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(exportMessagesReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		return newExportRes(svc, req), nil
+	}
+}
@@ -4,15 +4,22 @@
 package api_test
 
 import (
+	"compress/gzip"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/mainflux/mainflux/pkg/uuid"
 	"github.com/mainflux/mainflux/readers"
@@ -25,6 +32,10 @@ import (
 const (
 	svcName       = "test-service"
 	token         = "1"
+	userToken     = "user-token"
+	otherToken    = "other-user-token"
+	email         = "user@example.com"
+	otherEmail    = "other@example.com"
 	invalid       = "invalid"
 	numOfMessages = 100
 	valueFields   = 5
@@ -42,10 +53,14 @@ var (
 	sum float64 = 42
 
 	idProvider = uuid.New()
+	users      = map[string]string{
+		userToken:  email,
+		otherToken: otherEmail,
+	}
 )
 
 func newServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient) *httptest.Server {
-	mux := api.MakeHandler(repo, tc, svcName)
+	mux := api.MakeHandler(repo, tc, mocks.NewAuthService(users), readers.NewLiveStream(), svcName)
 	return httptest.NewServer(mux)
 }
 
@@ -122,7 +137,7 @@ func TestReadAll(t *testing.T) {
 		messages = append(messages, msg)
 	}
 
-	svc := mocks.NewThingsService()
+	svc := mocks.NewThingsService(map[string]string{chanID: email}, mocks.NewAuthService(users))
 	repo := mocks.NewMessageRepository(chanID, fromSenml(messages))
 	ts := newServer(repo, svc)
 	defer ts.Close()
@@ -145,6 +160,22 @@ func TestReadAll(t *testing.T) {
 				Messages: messages[0:10],
 			},
 		},
+		{
+			desc:   "read page with token of channel owner",
+			url:    fmt.Sprintf("%s/channels/%s/messages?offset=0&limit=10", ts.URL, chanID),
+			token:  userToken,
+			status: http.StatusOK,
+			res: pageRes{
+				Total:    uint64(len(messages)),
+				Messages: messages[0:10],
+			},
+		},
+		{
+			desc:   "read page with token of user who doesn't own the channel",
+			url:    fmt.Sprintf("%s/channels/%s/messages?offset=0&limit=10", ts.URL, chanID),
+			token:  otherToken,
+			status: http.StatusForbidden,
+		},
 		{
 			desc:   "read page with negative offset",
 			url:    fmt.Sprintf("%s/channels/%s/messages?offset=-1&limit=10", ts.URL, chanID),
@@ -416,6 +447,70 @@ func TestReadAll(t *testing.T) {
 				Messages: messages[5:15],
 			},
 		},
+		{
+			desc:   "read page in ascending order",
+			url:    fmt.Sprintf("%s/channels/%s/messages?dir=asc&limit=5", ts.URL, chanID),
+			token:  token,
+			status: http.StatusOK,
+			res: pageRes{
+				Total:    uint64(len(messages)),
+				Messages: []senml.Message{messages[99], messages[98], messages[97], messages[96], messages[95]},
+			},
+		},
+		{
+			desc:   "read page with invalid direction",
+			url:    fmt.Sprintf("%s/channels/%s/messages?dir=%s", ts.URL, chanID, invalid),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read page with negative from",
+			url:    fmt.Sprintf("%s/channels/%s/messages?from=-1", ts.URL, chanID),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read page with from after to",
+			url:    fmt.Sprintf("%s/channels/%s/messages?from=%f&to=%f", ts.URL, chanID, messages[4].Time, messages[19].Time),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read page with payload fields of json format",
+			url:    fmt.Sprintf("%s/channels/%s/messages?format=some_json&payload.temperature=21&payload.status=on", ts.URL, chanID),
+			token:  token,
+			status: http.StatusOK,
+		},
+		{
+			desc:   "read page with payload field of senml format",
+			url:    fmt.Sprintf("%s/channels/%s/messages?payload.temperature=21", ts.URL, chanID),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read page with empty payload field name",
+			url:    fmt.Sprintf("%s/channels/%s/messages?format=some_json&payload.=21", ts.URL, chanID),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read page with repeated payload field",
+			url:    fmt.Sprintf("%s/channels/%s/messages?format=some_json&payload.status=on&payload.status=off", ts.URL, chanID),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read page with page token and offset",
+			url:    fmt.Sprintf("%s/channels/%s/messages?page_token=AQID&offset=10", ts.URL, chanID),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read page with repeated page token",
+			url:    fmt.Sprintf("%s/channels/%s/messages?page_token=AQID&page_token=BAUG", ts.URL, chanID),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
 	}
 
 	for _, tc := range cases {
@@ -437,10 +532,1059 @@ func TestReadAll(t *testing.T) {
 	}
 }
 
-type pageRes struct {
-	readers.PageMetadata
-	Total    uint64          `json:"total"`
-	Messages []senml.Message `json:"messages,omitempty"`
+func TestAggregate(t *testing.T) {
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages span two hours, with values from 1 to 4 in each of them.
+	hour := float64(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC).Unix())
+	var messages []senml.Message
+	for i := 0; i < 8; i++ {
+		val := float64(i%4 + 1)
+		messages = append(messages, senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      hour + float64(i*900),
+			Value:     &val,
+		})
+	}
+	messages = append(messages, senml.Message{
+		Channel:     chanID,
+		Publisher:   pubID,
+		Protocol:    mqttProt,
+		Name:        msgName,
+		Time:        hour,
+		StringValue: &vs,
+	})
+
+	svc := mocks.NewThingsService(nil, mocks.NewAuthService(users))
+	repo := mocks.NewMessageRepository(chanID, fromSenml(messages))
+	ts := newServer(repo, svc)
+	defer ts.Close()
+
+	aggregate := func(start, v float64) readers.Aggregate {
+		return readers.Aggregate{Time: start, Name: msgName, Publisher: pubID, Value: v}
+	}
+
+	cases := []struct {
+		desc   string
+		url    string
+		status int
+		res    aggregatesPageRes
+	}{
+		{
+			desc:   "aggregate average by hour",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=avg&interval=1h", ts.URL, chanID),
+			status: http.StatusOK,
+			res: aggregatesPageRes{
+				Total:      2,
+				Aggregates: []readers.Aggregate{aggregate(hour+3600, 2.5), aggregate(hour, 2.5)},
+			},
+		},
+		{
+			desc:   "aggregate minimum by hour",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=min&interval=1h", ts.URL, chanID),
+			status: http.StatusOK,
+			res: aggregatesPageRes{
+				Total:      2,
+				Aggregates: []readers.Aggregate{aggregate(hour+3600, 1), aggregate(hour, 1)},
+			},
+		},
+		{
+			desc:   "aggregate maximum by two hours",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=max&interval=2h", ts.URL, chanID),
+			status: http.StatusOK,
+			res: aggregatesPageRes{
+				Total:      1,
+				Aggregates: []readers.Aggregate{aggregate(hour, 4)},
+			},
+		},
+		{
+			desc:   "aggregate count by hour",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=count&interval=1h", ts.URL, chanID),
+			status: http.StatusOK,
+			res: aggregatesPageRes{
+				Total:      2,
+				Aggregates: []readers.Aggregate{aggregate(hour+3600, 4), aggregate(hour, 5)},
+			},
+		},
+		{
+			desc:   "aggregate with offset and limit",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=count&interval=1h&offset=1&limit=1", ts.URL, chanID),
+			status: http.StatusOK,
+			res: aggregatesPageRes{
+				Total:      2,
+				Aggregates: []readers.Aggregate{aggregate(hour, 5)},
+			},
+		},
+		{
+			desc:   "aggregate with time range",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=count&interval=1h&from=%f", ts.URL, chanID, hour+1800),
+			status: http.StatusOK,
+			res: aggregatesPageRes{
+				Total:      2,
+				Aggregates: []readers.Aggregate{aggregate(hour+3600, 4), aggregate(hour, 2)},
+			},
+		},
+		{
+			desc:   "aggregate count by hour in ascending order",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=count&interval=1h&dir=asc", ts.URL, chanID),
+			status: http.StatusOK,
+			res: aggregatesPageRes{
+				Total:      2,
+				Aggregates: []readers.Aggregate{aggregate(hour, 5), aggregate(hour+3600, 4)},
+			},
+		},
+		{
+			desc:   "aggregate with invalid aggregation",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=sum&interval=1h", ts.URL, chanID),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "aggregate without interval",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=avg", ts.URL, chanID),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "aggregate with interval shorter than a second",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=avg&interval=100ms", ts.URL, chanID),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "aggregate with invalid interval",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=avg&interval=hour", ts.URL, chanID),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "aggregate interval without aggregation",
+			url:    fmt.Sprintf("%s/channels/%s/messages?interval=1h", ts.URL, chanID),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "aggregate JSON messages",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=avg&interval=1h&format=some_json", ts.URL, chanID),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "aggregate with page token",
+			url:    fmt.Sprintf("%s/channels/%s/messages?aggregation=avg&interval=1h&page_token=AQID", ts.URL, chanID),
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		var page aggregatesPageRes
+		json.NewDecoder(res.Body).Decode(&page)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.res.Total, page.Total, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.res.Total, page.Total))
+		assert.Equal(t, tc.res.Aggregates, page.Aggregates, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.res.Aggregates, page.Aggregates))
+	}
+}
+
+func TestSummary(t *testing.T) {
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	now := float64(time.Now().Unix())
+	var messages []senml.Message
+	for i := 0; i < numOfMessages; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      now - float64(i),
+			Value:     &v,
+		}
+		if i%2 == 1 {
+			msg.Publisher = pubID2
+			msg.Protocol = httpProt
+		}
+		messages = append(messages, msg)
+	}
+
+	svc := mocks.NewThingsService(map[string]string{chanID: email}, mocks.NewAuthService(users))
+	repo := mocks.NewMessageRepository(chanID, fromSenml(messages))
+	ts := newServer(repo, svc)
+	defer ts.Close()
+
+	publishers := []string{pubID, pubID2}
+	sort.Strings(publishers)
+
+	cases := []struct {
+		desc   string
+		url    string
+		token  string
+		status int
+		res    summaryRes
+	}{
+		{
+			desc:   "summarize messages",
+			url:    fmt.Sprintf("%s/channels/%s/messages/summary", ts.URL, chanID),
+			token:  token,
+			status: http.StatusOK,
+			res: summaryRes{
+				Count:      numOfMessages,
+				First:      now - numOfMessages + 1,
+				Last:       now,
+				Publishers: publishers,
+			},
+		},
+		{
+			desc:   "summarize messages with token of channel owner",
+			url:    fmt.Sprintf("%s/channels/%s/messages/summary", ts.URL, chanID),
+			token:  userToken,
+			status: http.StatusOK,
+			res: summaryRes{
+				Count:      numOfMessages,
+				First:      now - numOfMessages + 1,
+				Last:       now,
+				Publishers: publishers,
+			},
+		},
+		{
+			desc:   "summarize messages ignoring offset and limit",
+			url:    fmt.Sprintf("%s/channels/%s/messages/summary?offset=10&limit=1", ts.URL, chanID),
+			token:  token,
+			status: http.StatusOK,
+			res: summaryRes{
+				Count:      numOfMessages,
+				First:      now - numOfMessages + 1,
+				Last:       now,
+				Publishers: publishers,
+			},
+		},
+		{
+			desc:   "summarize messages with publisher",
+			url:    fmt.Sprintf("%s/channels/%s/messages/summary?publisher=%s", ts.URL, chanID, pubID2),
+			token:  token,
+			status: http.StatusOK,
+			res: summaryRes{
+				Count:      numOfMessages / 2,
+				First:      now - numOfMessages + 1,
+				Last:       now - 1,
+				Publishers: []string{pubID2},
+			},
+		},
+		{
+			desc:   "summarize messages with protocol and time range",
+			url:    fmt.Sprintf("%s/channels/%s/messages/summary?protocol=%s&from=%f&to=%f", ts.URL, chanID, mqttProt, now-10, now),
+			token:  token,
+			status: http.StatusOK,
+			res: summaryRes{
+				Count:      5,
+				First:      now - 10,
+				Last:       now - 2,
+				Publishers: []string{pubID},
+			},
+		},
+		{
+			desc:   "summarize messages without matches",
+			url:    fmt.Sprintf("%s/channels/%s/messages/summary?name=%s", ts.URL, chanID, invalid),
+			token:  token,
+			status: http.StatusOK,
+			res: summaryRes{
+				Publishers: []string{},
+			},
+		},
+		{
+			desc:   "summarize messages with aggregation",
+			url:    fmt.Sprintf("%s/channels/%s/messages/summary?aggregation=count&interval=1h", ts.URL, chanID),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "summarize messages with invalid time range",
+			url:    fmt.Sprintf("%s/channels/%s/messages/summary?from=%f&to=%f", ts.URL, chanID, now, now-10),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "summarize messages with invalid token",
+			url:    fmt.Sprintf("%s/channels/%s/messages/summary", ts.URL, chanID),
+			token:  invalid,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "summarize messages with token of user who doesn't own the channel",
+			url:    fmt.Sprintf("%s/channels/%s/messages/summary", ts.URL, chanID),
+			token:  otherToken,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "summarize messages without token",
+			url:    fmt.Sprintf("%s/channels/%s/messages/summary", ts.URL, chanID),
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		var summary summaryRes
+		json.NewDecoder(res.Body).Decode(&summary)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.res, summary, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.res, summary))
+	}
+}
+
+func TestReadLatest(t *testing.T) {
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages alternate between the publishers, and every other pair of
+	// them between the names.
+	now := float64(time.Now().Unix())
+	var messages []senml.Message
+	for i := 0; i < numOfMessages; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      now - float64(i),
+			Value:     &v,
+		}
+		if i%2 == 1 {
+			msg.Publisher = pubID2
+		}
+		if i%4 > 1 {
+			msg.Name = "humidity"
+		}
+		messages = append(messages, msg)
+	}
+
+	svc := mocks.NewThingsService(map[string]string{chanID: email}, mocks.NewAuthService(users))
+	repo := mocks.NewMessageRepository(chanID, fromSenml(messages))
+	ts := newServer(repo, svc)
+	defer ts.Close()
+
+	sorted := func(msgs ...senml.Message) []senml.Message {
+		sort.Slice(msgs, func(i, j int) bool {
+			if msgs[i].Publisher != msgs[j].Publisher {
+				return msgs[i].Publisher < msgs[j].Publisher
+			}
+			return msgs[i].Name < msgs[j].Name
+		})
+		return msgs
+	}
+	latest := sorted(messages[0], messages[1], messages[2], messages[3])
+
+	cases := []struct {
+		desc   string
+		url    string
+		token  string
+		status int
+		res    pageRes
+	}{
+		{
+			desc:   "read latest messages",
+			url:    fmt.Sprintf("%s/channels/%s/messages/latest", ts.URL, chanID),
+			token:  token,
+			status: http.StatusOK,
+			res: pageRes{
+				Total:    4,
+				Messages: latest,
+			},
+		},
+		{
+			desc:   "read latest messages with token of channel owner",
+			url:    fmt.Sprintf("%s/channels/%s/messages/latest", ts.URL, chanID),
+			token:  userToken,
+			status: http.StatusOK,
+			res: pageRes{
+				Total:    4,
+				Messages: latest,
+			},
+		},
+		{
+			desc:   "read latest messages with offset and limit",
+			url:    fmt.Sprintf("%s/channels/%s/messages/latest?offset=1&limit=2", ts.URL, chanID),
+			token:  token,
+			status: http.StatusOK,
+			res: pageRes{
+				Total:    4,
+				Messages: latest[1:3],
+			},
+		},
+		{
+			desc:   "read latest messages with name",
+			url:    fmt.Sprintf("%s/channels/%s/messages/latest?name=humidity", ts.URL, chanID),
+			token:  token,
+			status: http.StatusOK,
+			res: pageRes{
+				Total:    2,
+				Messages: sorted(messages[2], messages[3]),
+			},
+		},
+		{
+			desc:   "read latest messages with time range",
+			url:    fmt.Sprintf("%s/channels/%s/messages/latest?to=%f", ts.URL, chanID, now-3),
+			token:  token,
+			status: http.StatusOK,
+			res: pageRes{
+				Total:    4,
+				Messages: sorted(messages[4], messages[5], messages[6], messages[7]),
+			},
+		},
+		{
+			desc:   "read latest messages without matches",
+			url:    fmt.Sprintf("%s/channels/%s/messages/latest?publisher=%s", ts.URL, chanID, invalid),
+			token:  token,
+			status: http.StatusOK,
+			res:    pageRes{},
+		},
+		{
+			desc:   "read latest messages of json format",
+			url:    fmt.Sprintf("%s/channels/%s/messages/latest?format=some_json", ts.URL, chanID),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read latest messages with aggregation",
+			url:    fmt.Sprintf("%s/channels/%s/messages/latest?aggregation=count&interval=1h", ts.URL, chanID),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read latest messages with invalid limit",
+			url:    fmt.Sprintf("%s/channels/%s/messages/latest?limit=0", ts.URL, chanID),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read latest messages with invalid token",
+			url:    fmt.Sprintf("%s/channels/%s/messages/latest", ts.URL, chanID),
+			token:  invalid,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "read latest messages without token",
+			url:    fmt.Sprintf("%s/channels/%s/messages/latest", ts.URL, chanID),
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		var page pageRes
+		json.NewDecoder(res.Body).Decode(&page)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.res.Total, page.Total, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.res.Total, page.Total))
+		assert.Equal(t, tc.res.Messages, page.Messages, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res.Messages, page.Messages))
+	}
+}
+
+func TestDelete(t *testing.T) {
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	now := float64(time.Now().Unix())
+	var messages []senml.Message
+	for i := 0; i < numOfMessages; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      now - float64(i),
+			Value:     &v,
+		}
+		if i%2 == 1 {
+			msg.Publisher = pubID2
+		}
+		messages = append(messages, msg)
+	}
+
+	svc := mocks.NewThingsService(map[string]string{chanID: email}, mocks.NewAuthService(users))
+	repo := mocks.NewMessageRepository(chanID, fromSenml(messages))
+	ts := newServer(repo, svc)
+	defer ts.Close()
+
+	cases := []struct {
+		desc    string
+		url     string
+		token   string
+		status  int
+		removed uint64
+	}{
+		{
+			desc:   "delete messages with thing key",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			token:  token,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "delete messages with token of user who doesn't own the channel",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			token:  otherToken,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "delete messages without token",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "delete messages with invalid time range",
+			url:    fmt.Sprintf("%s/channels/%s/messages?from=%f&to=%f", ts.URL, chanID, now, now-10),
+			token:  userToken,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "delete messages with invalid time",
+			url:    fmt.Sprintf("%s/channels/%s/messages?from=abc", ts.URL, chanID),
+			token:  userToken,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:    "delete messages of publisher within time range",
+			url:     fmt.Sprintf("%s/channels/%s/messages?publisher=%s&from=%f&to=%f", ts.URL, chanID, pubID2, now-10, now),
+			token:   userToken,
+			status:  http.StatusOK,
+			removed: 5,
+		},
+		{
+			desc:    "delete messages of publisher",
+			url:     fmt.Sprintf("%s/channels/%s/messages?publisher=%s", ts.URL, chanID, pubID2),
+			token:   userToken,
+			status:  http.StatusOK,
+			removed: numOfMessages/2 - 5,
+		},
+		{
+			desc:    "delete JSON messages",
+			url:     fmt.Sprintf("%s/channels/%s/messages?format=some_json", ts.URL, chanID),
+			token:   userToken,
+			status:  http.StatusOK,
+			removed: 0,
+		},
+		{
+			desc:    "delete messages with invalid format",
+			url:     fmt.Sprintf("%s/channels/%s/messages?format=messages%%20--", ts.URL, chanID),
+			token:   userToken,
+			status:  http.StatusBadRequest,
+			removed: 0,
+		},
+		{
+			desc:    "delete all messages",
+			url:     fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			token:   userToken,
+			status:  http.StatusOK,
+			removed: numOfMessages / 2,
+		},
+		{
+			desc:    "delete messages of empty channel",
+			url:     fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			token:   userToken,
+			status:  http.StatusOK,
+			removed: 0,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodDelete,
+			url:    tc.url,
+			token:  tc.token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		var body deleteRes
+		json.NewDecoder(res.Body).Decode(&body)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.removed, body.Removed, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.removed, body.Removed))
+	}
+}
+
+func TestReadChannels(t *testing.T) {
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	chanID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	emptyChanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages of the channels are interleaved in time, from the newest one.
+	now := float64(time.Now().Unix())
+	repo := map[string][]readers.Message{}
+	var messages []senml.Message
+	for i := 0; i < numOfMessages; i++ {
+		msg := senml.Message{
+			Channel:  chanID,
+			Protocol: mqttProt,
+			Name:     msgName,
+			Time:     now - float64(i),
+			Value:    &v,
+		}
+		if i%2 == 1 {
+			msg.Channel = chanID2
+			msg.Protocol = httpProt
+		}
+		repo[msg.Channel] = append(repo[msg.Channel], msg)
+		messages = append(messages, msg)
+	}
+	var manyChans []string
+	for i := 0; i <= 100; i++ {
+		manyChans = append(manyChans, fmt.Sprintf("chan%d", i))
+	}
+	httpMsgs := []senml.Message{}
+	for _, m := range messages {
+		if m.Protocol == httpProt {
+			httpMsgs = append(httpMsgs, m)
+		}
+	}
+
+	svc := mocks.NewThingsService(nil, mocks.NewAuthService(users))
+	ts := newServer(mocks.NewChannelsMessageRepository(repo), svc)
+	defer ts.Close()
+
+	cases := []struct {
+		desc   string
+		url    string
+		token  string
+		status int
+		res    channelsPageRes
+	}{
+		{
+			desc:   "read messages of multiple channels",
+			url:    fmt.Sprintf("%s/messages?channels=%s,%s&offset=0&limit=10", ts.URL, chanID, chanID2),
+			token:  token,
+			status: http.StatusOK,
+			res: channelsPageRes{
+				Total:    numOfMessages,
+				Channels: map[string]uint64{chanID: numOfMessages / 2, chanID2: numOfMessages / 2},
+				Messages: messages[0:10],
+			},
+		},
+		{
+			desc:   "read messages of multiple channels with offset",
+			url:    fmt.Sprintf("%s/messages?channels=%s,%s&offset=15&limit=10", ts.URL, chanID, chanID2),
+			token:  token,
+			status: http.StatusOK,
+			res: channelsPageRes{
+				Total:    numOfMessages,
+				Channels: map[string]uint64{chanID: numOfMessages / 2, chanID2: numOfMessages / 2},
+				Messages: messages[15:25],
+			},
+		},
+		{
+			desc:   "read messages of multiple channels in ascending order",
+			url:    fmt.Sprintf("%s/messages?channels=%s,%s&offset=0&limit=2&dir=asc", ts.URL, chanID, chanID2),
+			token:  token,
+			status: http.StatusOK,
+			res: channelsPageRes{
+				Total:    numOfMessages,
+				Channels: map[string]uint64{chanID: numOfMessages / 2, chanID2: numOfMessages / 2},
+				Messages: []senml.Message{messages[numOfMessages-1], messages[numOfMessages-2]},
+			},
+		},
+		{
+			desc:   "read messages of multiple channels with protocol",
+			url:    fmt.Sprintf("%s/messages?channels=%s,%s&offset=0&limit=5&protocol=%s", ts.URL, chanID, chanID2, httpProt),
+			token:  token,
+			status: http.StatusOK,
+			res: channelsPageRes{
+				Total:    uint64(len(httpMsgs)),
+				Channels: map[string]uint64{chanID: 0, chanID2: uint64(len(httpMsgs))},
+				Messages: httpMsgs[0:5],
+			},
+		},
+		{
+			desc:   "read messages of channel without messages",
+			url:    fmt.Sprintf("%s/messages?channels=%s,%s&offset=0&limit=1", ts.URL, chanID, emptyChanID),
+			token:  token,
+			status: http.StatusOK,
+			res: channelsPageRes{
+				Total:    numOfMessages / 2,
+				Channels: map[string]uint64{chanID: numOfMessages / 2, emptyChanID: 0},
+				Messages: messages[0:1],
+			},
+		},
+		{
+			desc:   "read messages of duplicated channels",
+			url:    fmt.Sprintf("%s/messages?channels=%s,%s&offset=0&limit=1", ts.URL, chanID, chanID),
+			token:  token,
+			status: http.StatusOK,
+			res: channelsPageRes{
+				Total:    numOfMessages / 2,
+				Channels: map[string]uint64{chanID: numOfMessages / 2},
+				Messages: messages[0:1],
+			},
+		},
+		{
+			desc:   "read messages without channels",
+			url:    fmt.Sprintf("%s/messages?offset=0&limit=10", ts.URL),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read messages of too many channels",
+			url:    fmt.Sprintf("%s/messages?channels=%s", ts.URL, strings.Join(manyChans, ",")),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read aggregated messages of multiple channels",
+			url:    fmt.Sprintf("%s/messages?channels=%s,%s&aggregation=avg&interval=1h", ts.URL, chanID, chanID2),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read messages of multiple channels with page token",
+			url:    fmt.Sprintf("%s/messages?channels=%s,%s&page_token=AQID", ts.URL, chanID, chanID2),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read messages of multiple channels with invalid direction",
+			url:    fmt.Sprintf("%s/messages?channels=%s,%s&dir=up", ts.URL, chanID, chanID2),
+			token:  token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read messages of multiple channels with invalid token",
+			url:    fmt.Sprintf("%s/messages?channels=%s,%s", ts.URL, chanID, chanID2),
+			token:  invalid,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "read messages of multiple channels with empty token",
+			url:    fmt.Sprintf("%s/messages?channels=%s,%s", ts.URL, chanID, chanID2),
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		var page channelsPageRes
+		json.NewDecoder(res.Body).Decode(&page)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.res.Total, page.Total, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.res.Total, page.Total))
+		assert.Equal(t, tc.res.Channels, page.Channels, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.res.Channels, page.Channels))
+		assert.ElementsMatch(t, tc.res.Messages, page.Messages, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.res.Messages, page.Messages))
+		assert.Equal(t, tc.res.Messages, page.Messages, fmt.Sprintf("%s: expected messages in order", tc.desc))
+	}
+}
+
+func TestStream(t *testing.T) {
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	otherChanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	otherPubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages are kept from the newest one.
+	now := float64(time.Now().Unix())
+	var messages []senml.Message
+	for i := 0; i < 10; i++ {
+		messages = append(messages, senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      now - float64(i),
+			Value:     &v,
+		})
+	}
+
+	stream := readers.NewLiveStream()
+	repo := mocks.NewMessageRepository(chanID, fromSenml(messages))
+	auth := mocks.NewAuthService(users)
+	ts := httptest.NewServer(api.MakeHandler(repo, mocks.NewThingsService(nil, auth), auth, stream, svcName))
+	defer ts.Close()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	cases := []struct {
+		desc   string
+		url    string
+		status int
+	}{
+		{
+			desc:   "stream messages with invalid token",
+			url:    fmt.Sprintf("%s/channels/%s/messages/stream?authorization=%s", wsURL, chanID, invalid),
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "stream messages without token",
+			url:    fmt.Sprintf("%s/channels/%s/messages/stream", wsURL, chanID),
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "stream messages with invalid limit",
+			url:    fmt.Sprintf("%s/channels/%s/messages/stream?authorization=%s&limit=0", wsURL, chanID, token),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "stream aggregated values",
+			url:    fmt.Sprintf("%s/channels/%s/messages/stream?authorization=%s&aggregation=avg&interval=1h", wsURL, chanID, token),
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		_, res, err := websocket.DefaultDialer.Dial(tc.url, nil)
+		assert.NotNil(t, err, fmt.Sprintf("%s: expected handshake error", tc.desc))
+		require.NotNil(t, res, fmt.Sprintf("%s: expected response", tc.desc))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+
+	url := fmt.Sprintf("%s/channels/%s/messages/stream?limit=2&publisher=%s", wsURL, chanID, pubID)
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": []string{token}})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	defer conn.Close()
+
+	publish := func(chanID, pubID string, time float64) {
+		payload := fmt.Sprintf(`[{"n":"%s","v":%f,"t":%f}]`, msgName, v, time)
+		err := stream.Handle(messaging.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  httpProt,
+			Payload:   []byte(payload),
+		})
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	}
+	// Only the last message is newer than the saved ones, published to the
+	// channel and matching the filter.
+	publish(chanID, pubID, now-5)
+	publish(otherChanID, pubID, now+1)
+	publish(chanID, otherPubID, now+2)
+	publish(chanID, pubID, now+3)
+
+	live := senml.Message{
+		Channel:   chanID,
+		Publisher: pubID,
+		Protocol:  httpProt,
+		Name:      msgName,
+		Time:      now + 3,
+		Value:     &v,
+	}
+	for i, expected := range []senml.Message{messages[1], messages[0], live} {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		var msg senml.Message
+		err := conn.ReadJSON(&msg)
+		require.Nil(t, err, fmt.Sprintf("message %d: got unexpected error: %s", i, err))
+		assert.Equal(t, expected, msg, fmt.Sprintf("message %d: expected %v got %v", i, expected, msg))
+	}
+
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	var msg senml.Message
+	err = conn.ReadJSON(&msg)
+	assert.NotNil(t, err, fmt.Sprintf("expected no more messages got %v", msg))
+}
+
+func TestExport(t *testing.T) {
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages span more than a single export batch, newest first.
+	msgsNum := 2500
+	now := float64(time.Now().Unix())
+	var messages []senml.Message
+	for i := 0; i < msgsNum; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      now - float64(i),
+			Value:     &v,
+		}
+		if i%2 == 1 {
+			msg.Publisher = pubID2
+			msg.Value = nil
+			msg.StringValue = &vs
+		}
+		messages = append(messages, msg)
+	}
+
+	svc := mocks.NewThingsService(nil, mocks.NewAuthService(users))
+	repo := mocks.NewMessageRepository(chanID, fromSenml(messages))
+	ts := newServer(repo, svc)
+	defer ts.Close()
+
+	oldest := strconv.FormatFloat(now-float64(msgsNum-1), 'f', -1, 64)
+	newest := strconv.FormatFloat(now, 'f', -1, 64)
+	header := []string{"channel", "subtopic", "publisher", "protocol", "name", "unit", "value", "string_value", "bool_value", "data_value", "sum", "time", "update_time"}
+	cases := []struct {
+		desc     string
+		url      string
+		token    string
+		encoding string
+		status   int
+		header   []string
+		rows     int
+		first    []string
+	}{
+		{
+			desc:     "export all messages",
+			url:      fmt.Sprintf("%s/channels/%s/messages/export", ts.URL, chanID),
+			token:    token,
+			encoding: "identity",
+			status:   http.StatusOK,
+			header:   header,
+			rows:     msgsNum,
+			first:    []string{chanID, "", pubID2, mqttProt, msgName, "", "", vs, "", "", "", oldest, "0"},
+		},
+		{
+			desc:     "export all messages compressed",
+			url:      fmt.Sprintf("%s/channels/%s/messages/export", ts.URL, chanID),
+			token:    token,
+			encoding: "gzip",
+			status:   http.StatusOK,
+			header:   header,
+			rows:     msgsNum,
+			first:    []string{chanID, "", pubID2, mqttProt, msgName, "", "", vs, "", "", "", oldest, "0"},
+		},
+		{
+			desc:     "export messages with publisher in descending order",
+			url:      fmt.Sprintf("%s/channels/%s/messages/export?publisher=%s&dir=desc", ts.URL, chanID, pubID),
+			token:    token,
+			encoding: "identity",
+			status:   http.StatusOK,
+			header:   header,
+			rows:     msgsNum / 2,
+			first:    []string{chanID, "", pubID, mqttProt, msgName, "", "5", "", "", "", "", newest, "0"},
+		},
+		{
+			desc:     "export aggregated messages",
+			url:      fmt.Sprintf("%s/channels/%s/messages/export?aggregation=count&interval=1h&publisher=%s", ts.URL, chanID, pubID),
+			token:    token,
+			encoding: "identity",
+			status:   http.StatusOK,
+			header:   []string{"time", "name", "publisher", "value"},
+		},
+		{
+			desc:     "export messages with invalid direction",
+			url:      fmt.Sprintf("%s/channels/%s/messages/export?dir=%s", ts.URL, chanID, invalid),
+			token:    token,
+			encoding: "identity",
+			status:   http.StatusBadRequest,
+		},
+		{
+			desc:     "export messages with invalid token",
+			url:      fmt.Sprintf("%s/channels/%s/messages/export", ts.URL, chanID),
+			token:    invalid,
+			encoding: "identity",
+			status:   http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		req.Header.Set("Authorization", tc.token)
+		req.Header.Set("Accept-Encoding", tc.encoding)
+		res, err := ts.Client().Do(req)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.status != http.StatusOK {
+			continue
+		}
+		assert.Equal(t, "text/csv", res.Header.Get("Content-Type"), fmt.Sprintf("%s: expected CSV content type", tc.desc))
+
+		var body io.Reader = res.Body
+		if tc.encoding == "gzip" {
+			assert.Equal(t, "gzip", res.Header.Get("Content-Encoding"), fmt.Sprintf("%s: expected gzip content encoding", tc.desc))
+			body, err = gzip.NewReader(res.Body)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		}
+		records, err := csv.NewReader(body).ReadAll()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		require.NotEmpty(t, records, fmt.Sprintf("%s: expected CSV header", tc.desc))
+		assert.Equal(t, tc.header, records[0], fmt.Sprintf("%s: expected header %v got %v", tc.desc, tc.header, records[0]))
+		if tc.first == nil {
+			continue
+		}
+		assert.Len(t, records[1:], tc.rows, fmt.Sprintf("%s: expected %d rows got %d", tc.desc, tc.rows, len(records)-1))
+		assert.Equal(t, tc.first, records[1], fmt.Sprintf("%s: expected first row %v got %v", tc.desc, tc.first, records[1]))
+	}
+}
+
+type pageRes struct {
+	readers.PageMetadata
+	Total    uint64          `json:"total"`
+	Messages []senml.Message `json:"messages,omitempty"`
+}
+
+type channelsPageRes struct {
+	readers.PageMetadata
+	Total    uint64            `json:"total"`
+	Channels map[string]uint64 `json:"channels"`
+	Messages []senml.Message   `json:"messages,omitempty"`
+}
+
+type aggregatesPageRes struct {
+	readers.PageMetadata
+	Total      uint64              `json:"total"`
+	Aggregates []readers.Aggregate `json:"aggregates"`
+}
+
+type summaryRes struct {
+	Count      uint64   `json:"count"`
+	First      float64  `json:"first"`
+	Last       float64  `json:"last"`
+	Publishers []string `json:"publishers"`
+}
+
+type deleteRes struct {
+	Removed uint64 `json:"removed"`
 }
 
 func fromSenml(in []senml.Message) []readers.Message {
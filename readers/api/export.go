@@ -0,0 +1,170 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+)
+
+// exportBatchSize is the number of messages read at once while exporting.
+const exportBatchSize = 1000
+
+var errUnsupportedMessage = errors.New("unsupported message type")
+
+var (
+	senmlHeader     = []string{"channel", "subtopic", "publisher", "protocol", "name", "unit", "value", "string_value", "bool_value", "data_value", "sum", "time", "update_time"}
+	jsonHeader      = []string{"channel", "subtopic", "publisher", "protocol", "created", "payload"}
+	aggregateHeader = []string{"time", "name", "publisher", "value"}
+)
+
+// exportRes contains the CSV header and reads the CSV rows of the exported
+// messages in batches, so that the messages are streamed while encoding.
+type exportRes struct {
+	filename string
+	gzip     bool
+	header   []string
+	rows     func(offset uint64) ([][]string, error)
+}
+
+func newExportRes(svc readers.MessageRepository, req exportMessagesReq) exportRes {
+	res := exportRes{
+		filename: fmt.Sprintf("%s.csv", req.chanID),
+		gzip:     req.gzip,
+	}
+
+	switch {
+	case req.pageMeta.Aggregation != "":
+		res.header = aggregateHeader
+		res.rows = func(offset uint64) ([][]string, error) {
+			pm := req.pageMeta
+			pm.Offset = offset
+			page, err := svc.Aggregate(req.chanID, pm)
+			if err != nil {
+				return nil, err
+			}
+			var rows [][]string
+			for _, agg := range page.Aggregates {
+				rows = append(rows, aggregateRow(agg))
+			}
+			return rows, nil
+		}
+	default:
+		res.header = senmlHeader
+		if req.pageMeta.Format != defFormat {
+			res.header = jsonHeader
+		}
+		// Batches are read using the page tokens, if the repository returns
+		// them, so that the offset is not skipped for each batch.
+		var token string
+		var paged bool
+		res.rows = func(offset uint64) ([][]string, error) {
+			pm := req.pageMeta
+			pm.Offset = offset
+			if paged {
+				// No token is returned with the last batch.
+				if token == "" {
+					return nil, nil
+				}
+				pm.Offset, pm.PageToken = 0, token
+			}
+			page, err := svc.ReadAll(req.chanID, pm)
+			if err != nil {
+				return nil, err
+			}
+			token = page.NextPageToken
+			paged = paged || token != ""
+			var rows [][]string
+			for _, msg := range page.Messages {
+				row, err := messageRow(msg)
+				if err != nil {
+					return nil, err
+				}
+				rows = append(rows, row)
+			}
+			return rows, nil
+		}
+	}
+
+	return res
+}
+
+func messageRow(msg readers.Message) ([]string, error) {
+	switch m := msg.(type) {
+	case senml.Message:
+		return []string{
+			m.Channel,
+			m.Subtopic,
+			m.Publisher,
+			m.Protocol,
+			m.Name,
+			m.Unit,
+			formatFloat(m.Value),
+			formatString(m.StringValue),
+			formatBool(m.BoolValue),
+			formatString(m.DataValue),
+			formatFloat(m.Sum),
+			strconv.FormatFloat(m.Time, 'f', -1, 64),
+			strconv.FormatFloat(m.UpdateTime, 'f', -1, 64),
+		}, nil
+	case map[string]interface{}:
+		payload, err := json.Marshal(m["payload"])
+		if err != nil {
+			return nil, err
+		}
+		row := []string{}
+		for _, col := range jsonHeader[:len(jsonHeader)-1] {
+			row = append(row, formatValue(m[col]))
+		}
+		return append(row, string(payload)), nil
+	default:
+		return nil, errUnsupportedMessage
+	}
+}
+
+func aggregateRow(agg readers.Aggregate) []string {
+	return []string{
+		strconv.FormatFloat(agg.Time, 'f', -1, 64),
+		agg.Name,
+		agg.Publisher,
+		strconv.FormatFloat(agg.Value, 'f', -1, 64),
+	}
+}
+
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func formatFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+func formatString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func formatBool(v *bool) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatBool(*v)
+}
@@ -0,0 +1,31 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/mainflux/mainflux/readers"
+)
+
+func readMessagesEndpoint(svc readers.MessageRepository) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(readMessagesReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page, err := svc.ReadAll(req.chanID, req.pageMeta)
+		if err != nil {
+			return nil, err
+		}
+
+		return readMessagesRes{
+			messages:      page.Messages,
+			nextPageToken: page.NextPageToken,
+		}, nil
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReadMessages(t *testing.T) {
+	conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", port), grpc.WithInsecure())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	defer conn.Close()
+	cli := mainflux.NewReadersServiceClient(conn)
+
+	oldest := messages[numOfMessages-1]
+	cases := []struct {
+		desc  string
+		req   *mainflux.ReadMessagesReq
+		count int
+		first *mainflux.StoredMessage
+		code  codes.Code
+	}{
+		{
+			desc:  "read all messages",
+			req:   &mainflux.ReadMessagesReq{Token: token, ChanID: chanID},
+			count: numOfMessages,
+			first: &mainflux.StoredMessage{
+				Channel:   oldest.Channel,
+				Publisher: oldest.Publisher,
+				Protocol:  oldest.Protocol,
+				Name:      oldest.Name,
+				Time:      oldest.Time,
+				Value:     &mainflux.StoredMessage_StringValue{StringValue: vs},
+			},
+			code: codes.OK,
+		},
+		{
+			desc:  "read messages with offset and limit",
+			req:   &mainflux.ReadMessagesReq{Token: token, ChanID: chanID, Offset: 1, Limit: 1200},
+			count: 1200,
+			first: &mainflux.StoredMessage{
+				Channel:   oldest.Channel,
+				Publisher: oldest.Publisher,
+				Protocol:  oldest.Protocol,
+				Name:      oldest.Name,
+				Time:      oldest.Time + 1,
+				Value:     &mainflux.StoredMessage_FloatValue{FloatValue: v},
+				SumValue:  &mainflux.StoredMessage_Sum{Sum: sum},
+			},
+			code: codes.OK,
+		},
+		{
+			desc:  "read messages in descending order",
+			req:   &mainflux.ReadMessagesReq{Token: token, ChanID: chanID, Limit: 1, Dir: "desc"},
+			count: 1,
+			first: &mainflux.StoredMessage{
+				Channel:   oldest.Channel,
+				Publisher: oldest.Publisher,
+				Protocol:  oldest.Protocol,
+				Name:      oldest.Name,
+				Time:      now,
+				Value:     &mainflux.StoredMessage_FloatValue{FloatValue: v},
+				SumValue:  &mainflux.StoredMessage_Sum{Sum: sum},
+			},
+			code: codes.OK,
+		},
+		{
+			desc:  "read messages with filters",
+			req:   &mainflux.ReadMessagesReq{Token: token, ChanID: chanID, StringValue: vs, From: now - 10},
+			count: 5,
+			first: &mainflux.StoredMessage{
+				Channel:   oldest.Channel,
+				Publisher: oldest.Publisher,
+				Protocol:  oldest.Protocol,
+				Name:      oldest.Name,
+				Time:      now - 9,
+				Value:     &mainflux.StoredMessage_StringValue{StringValue: vs},
+			},
+			code: codes.OK,
+		},
+		{
+			desc:  "read messages with offset after the last one",
+			req:   &mainflux.ReadMessagesReq{Token: token, ChanID: chanID, Offset: numOfMessages},
+			count: 0,
+			code:  codes.OK,
+		},
+		{
+			desc: "read messages with invalid comparator",
+			req:  &mainflux.ReadMessagesReq{Token: token, ChanID: chanID, Value: v, Comparator: "invalid"},
+			code: codes.InvalidArgument,
+		},
+		{
+			desc: "read messages with invalid time range",
+			req:  &mainflux.ReadMessagesReq{Token: token, ChanID: chanID, From: now, To: now - 10},
+			code: codes.InvalidArgument,
+		},
+		{
+			desc: "read messages with invalid direction",
+			req:  &mainflux.ReadMessagesReq{Token: token, ChanID: chanID, Dir: "up"},
+			code: codes.InvalidArgument,
+		},
+		{
+			desc: "read messages without channel",
+			req:  &mainflux.ReadMessagesReq{Token: token},
+			code: codes.InvalidArgument,
+		},
+		{
+			desc:  "read messages with token of channel owner",
+			req:   &mainflux.ReadMessagesReq{Token: userToken, ChanID: chanID, Limit: 10},
+			count: 10,
+			first: &mainflux.StoredMessage{
+				Channel:   oldest.Channel,
+				Publisher: oldest.Publisher,
+				Protocol:  oldest.Protocol,
+				Name:      oldest.Name,
+				Time:      oldest.Time,
+				Value:     &mainflux.StoredMessage_StringValue{StringValue: vs},
+			},
+			code: codes.OK,
+		},
+		{
+			desc: "read messages with token of user who doesn't own the channel",
+			req:  &mainflux.ReadMessagesReq{Token: otherToken, ChanID: chanID},
+			code: codes.PermissionDenied,
+		},
+		{
+			desc: "read messages with invalid token",
+			req:  &mainflux.ReadMessagesReq{Token: invalid, ChanID: chanID},
+			code: codes.PermissionDenied,
+		},
+		{
+			desc: "read messages with empty token",
+			req:  &mainflux.ReadMessagesReq{ChanID: chanID},
+			code: codes.PermissionDenied,
+		},
+	}
+
+	for _, tc := range cases {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		stream, err := cli.ReadMessages(ctx, tc.req)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+
+		var msgs []*mainflux.StoredMessage
+		for {
+			var msg *mainflux.StoredMessage
+			if msg, err = stream.Recv(); err != nil {
+				break
+			}
+			msgs = append(msgs, msg)
+		}
+		cancel()
+
+		if err == io.EOF {
+			err = nil
+		}
+		e, ok := status.FromError(err)
+		assert.True(t, ok, fmt.Sprintf("%s: gRPC status required", tc.desc))
+		assert.Equal(t, tc.code, e.Code(), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.code, e.Code()))
+		if tc.code != codes.OK {
+			continue
+		}
+		assert.Len(t, msgs, tc.count, fmt.Sprintf("%s: expected %d messages got %d", tc.desc, tc.count, len(msgs)))
+		if tc.first != nil && len(msgs) > 0 {
+			assert.Equal(t, tc.first, msgs[0], fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.first, msgs[0]))
+		}
+	}
+}
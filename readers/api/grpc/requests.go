@@ -0,0 +1,35 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import "github.com/mainflux/mainflux/readers"
+
+type readMessagesReq struct {
+	token    string
+	chanID   string
+	pageMeta readers.PageMetadata
+}
+
+func (req readMessagesReq) validate() error {
+	if req.chanID == "" {
+		return errMalformedRequest
+	}
+	if req.pageMeta.Comparator != "" &&
+		req.pageMeta.Comparator != readers.EqualKey &&
+		req.pageMeta.Comparator != readers.LowerThanKey &&
+		req.pageMeta.Comparator != readers.LowerThanEqualKey &&
+		req.pageMeta.Comparator != readers.GreaterThanKey &&
+		req.pageMeta.Comparator != readers.GreaterThanEqualKey {
+		return errMalformedRequest
+	}
+	if req.pageMeta.From < 0 || req.pageMeta.To < 0 ||
+		(req.pageMeta.To != 0 && req.pageMeta.From >= req.pageMeta.To) {
+		return errMalformedRequest
+	}
+	if req.pageMeta.Dir != readers.AscDir && req.pageMeta.Dir != readers.DescDir {
+		return errMalformedRequest
+	}
+
+	return nil
+}
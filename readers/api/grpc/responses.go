@@ -0,0 +1,11 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import "github.com/mainflux/mainflux/readers"
+
+type readMessagesRes struct {
+	messages      []readers.Message
+	nextPageToken string
+}
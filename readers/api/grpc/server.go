@@ -0,0 +1,231 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	batchSize = 1000
+	defFormat = "messages"
+)
+
+var (
+	errMalformedRequest   = errors.New("malformed read messages request")
+	errUnauthorizedAccess = errors.New("missing or invalid credentials provided")
+	errUnsupportedMessage = errors.New("unsupported message")
+)
+
+var _ mainflux.ReadersServiceServer = (*grpcServer)(nil)
+
+type grpcServer struct {
+	readMessages endpoint.Endpoint
+	things       mainflux.ThingsServiceClient
+	users        mainflux.AuthServiceClient
+}
+
+// NewServer returns new ReadersServiceServer instance, which authorizes
+// the requests using the things service, and the auth service for the user
+// tokens.
+func NewServer(svc readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient) mainflux.ReadersServiceServer {
+	return &grpcServer{
+		readMessages: readMessagesEndpoint(svc),
+		things:       tc,
+		users:        ac,
+	}
+}
+
+// ReadMessages reads the messages in batches and streams them one by one,
+// until all the messages or the requested number of them are sent. Batches
+// are read using the page tokens, if the repository returns them.
+func (gs *grpcServer) ReadMessages(req *mainflux.ReadMessagesReq, stream mainflux.ReadersService_ReadMessagesServer) error {
+	ctx := stream.Context()
+	r := decodeReadMessagesRequest(req)
+	if err := r.validate(); err != nil {
+		return encodeError(err)
+	}
+	if err := gs.authorize(ctx, r.token, r.chanID); err != nil {
+		return encodeError(err)
+	}
+
+	limit := req.GetLimit()
+	for sent := uint64(0); limit == 0 || sent < limit; {
+		if r.pageMeta.PageToken == "" {
+			r.pageMeta.Offset = req.GetOffset() + sent
+		}
+		r.pageMeta.Limit = batchSize
+		if limit != 0 && limit-sent < batchSize {
+			r.pageMeta.Limit = limit - sent
+		}
+
+		res, err := gs.readMessages(ctx, r)
+		if err != nil {
+			return encodeError(err)
+		}
+
+		page := res.(readMessagesRes)
+		msgs := page.messages
+		for _, msg := range msgs {
+			sm, err := encodeMessage(msg)
+			if err != nil {
+				return encodeError(err)
+			}
+			if err := stream.Send(sm); err != nil {
+				return err
+			}
+		}
+
+		sent += uint64(len(msgs))
+		if uint64(len(msgs)) < r.pageMeta.Limit ||
+			(r.pageMeta.PageToken != "" && page.nextPageToken == "") {
+			break
+		}
+		if page.nextPageToken != "" {
+			r.pageMeta.Offset, r.pageMeta.PageToken = 0, page.nextPageToken
+		}
+	}
+
+	return nil
+}
+
+func (gs *grpcServer) authorize(ctx context.Context, token, chanID string) error {
+	if token == "" {
+		return errUnauthorizedAccess
+	}
+
+	_, err := gs.things.CanAccessByKey(ctx, &mainflux.AccessByKeyReq{Token: token, ChanID: chanID})
+	if err == nil {
+		return nil
+	}
+	if e, ok := status.FromError(err); !ok || e.Code() != codes.PermissionDenied {
+		return err
+	}
+
+	// The token is not the key of the thing connected to the channel, so it
+	// is checked as the token of the user who owns the channel.
+	id, err := gs.users.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		e, ok := status.FromError(err)
+		if ok && e.Code() == codes.Unauthenticated {
+			return errUnauthorizedAccess
+		}
+		return err
+	}
+
+	_, err = gs.things.IsChannelOwner(ctx, &mainflux.ChannelOwnerReq{Owner: id.GetEmail(), ChanID: chanID})
+	if err != nil {
+		e, ok := status.FromError(err)
+		if ok && (e.Code() == codes.NotFound || e.Code() == codes.PermissionDenied) {
+			return errUnauthorizedAccess
+		}
+		return err
+	}
+
+	return nil
+}
+
+func decodeReadMessagesRequest(req *mainflux.ReadMessagesReq) readMessagesReq {
+	format := req.GetFormat()
+	if format == "" {
+		format = defFormat
+	}
+	dir := req.GetDir()
+	if dir == "" {
+		dir = readers.AscDir
+	}
+
+	return readMessagesReq{
+		token:  req.GetToken(),
+		chanID: req.GetChanID(),
+		pageMeta: readers.PageMetadata{
+			Format:      format,
+			Subtopic:    req.GetSubtopic(),
+			Publisher:   req.GetPublisher(),
+			Protocol:    req.GetProtocol(),
+			Name:        req.GetName(),
+			Value:       req.GetValue(),
+			Comparator:  req.GetComparator(),
+			BoolValue:   req.GetBoolValue(),
+			StringValue: req.GetStringValue(),
+			DataValue:   req.GetDataValue(),
+			From:        req.GetFrom(),
+			To:          req.GetTo(),
+			Dir:         dir,
+		},
+	}
+}
+
+func encodeMessage(msg readers.Message) (*mainflux.StoredMessage, error) {
+	switch m := msg.(type) {
+	case senml.Message:
+		sm := &mainflux.StoredMessage{
+			Channel:    m.Channel,
+			Subtopic:   m.Subtopic,
+			Publisher:  m.Publisher,
+			Protocol:   m.Protocol,
+			Name:       m.Name,
+			Unit:       m.Unit,
+			Time:       m.Time,
+			UpdateTime: m.UpdateTime,
+		}
+		switch {
+		case m.Value != nil:
+			sm.Value = &mainflux.StoredMessage_FloatValue{FloatValue: *m.Value}
+		case m.StringValue != nil:
+			sm.Value = &mainflux.StoredMessage_StringValue{StringValue: *m.StringValue}
+		case m.BoolValue != nil:
+			sm.Value = &mainflux.StoredMessage_BoolValue{BoolValue: *m.BoolValue}
+		case m.DataValue != nil:
+			sm.Value = &mainflux.StoredMessage_DataValue{DataValue: *m.DataValue}
+		}
+		if m.Sum != nil {
+			sm.SumValue = &mainflux.StoredMessage_Sum{Sum: *m.Sum}
+		}
+		return sm, nil
+	case map[string]interface{}:
+		payload, err := json.Marshal(m["payload"])
+		if err != nil {
+			return nil, err
+		}
+		sm := &mainflux.StoredMessage{
+			Payload: payload,
+		}
+		sm.Channel, _ = m["channel"].(string)
+		sm.Subtopic, _ = m["subtopic"].(string)
+		sm.Publisher, _ = m["publisher"].(string)
+		sm.Protocol, _ = m["protocol"].(string)
+		switch created := m["created"].(type) {
+		case int64:
+			sm.Created = created
+		case float64:
+			sm.Created = int64(created)
+		case json.Number:
+			sm.Created, _ = created.Int64()
+		}
+		return sm, nil
+	default:
+		return nil, errUnsupportedMessage
+	}
+}
+
+func encodeError(err error) error {
+	switch {
+	case errors.Contains(err, errMalformedRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Contains(err, errUnauthorizedAccess):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc_test
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+	grpcapi "github.com/mainflux/mainflux/readers/api/grpc"
+	"github.com/mainflux/mainflux/readers/mocks"
+	"google.golang.org/grpc"
+)
+
+const (
+	port          = 8192
+	chanID        = "chan"
+	token         = "token"
+	userToken     = "user-token"
+	otherToken    = "other-user-token"
+	email         = "user@example.com"
+	invalid       = "invalid"
+	numOfMessages = 2500
+)
+
+var (
+	v   float64 = 5
+	sum float64 = 42
+	vs          = "value"
+
+	now      = float64(time.Now().Unix())
+	messages []senml.Message
+)
+
+func TestMain(m *testing.M) {
+	startServer()
+	code := m.Run()
+	os.Exit(code)
+}
+
+func startServer() {
+	// Messages are kept from the newest one.
+	var msgs []readers.Message
+	for i := 0; i < numOfMessages; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: "publisher",
+			Protocol:  "mqtt",
+			Name:      "temperature",
+			Time:      now - float64(i),
+		}
+		switch i % 2 {
+		case 0:
+			msg.Value = &v
+			msg.Sum = &sum
+		case 1:
+			msg.StringValue = &vs
+		}
+		messages = append(messages, msg)
+		msgs = append(msgs, msg)
+	}
+
+	repo := mocks.NewMessageRepository(chanID, msgs)
+	listener, _ := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	server := grpc.NewServer()
+	auth := mocks.NewAuthService(map[string]string{
+		userToken:  email,
+		otherToken: "other@example.com",
+	})
+	tc := mocks.NewThingsService(map[string]string{chanID: email}, auth)
+	mainflux.RegisterReadersServiceServer(server, grpcapi.NewServer(repo, tc, auth))
+	go server.Serve(listener)
+}
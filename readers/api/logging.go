@@ -40,3 +40,55 @@ func (lm *loggingMiddleware) ReadAll(chanID string, rpm readers.PageMetadata) (p
 
 	return lm.svc.ReadAll(chanID, rpm)
 }
+
+func (lm *loggingMiddleware) Aggregate(chanID string, rpm readers.PageMetadata) (page readers.AggregatesPage, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method aggregate for channel %s with query %v took %s to complete", chanID, rpm, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Aggregate(chanID, rpm)
+}
+
+func (lm *loggingMiddleware) Summarize(chanID string, rpm readers.PageMetadata) (summary readers.Summary, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method summarize for channel %s with query %v took %s to complete", chanID, rpm, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Summarize(chanID, rpm)
+}
+
+func (lm *loggingMiddleware) ReadLatest(chanID string, rpm readers.PageMetadata) (page readers.MessagesPage, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method read_latest for channel %s with query %v took %s to complete", chanID, rpm, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ReadLatest(chanID, rpm)
+}
+
+func (lm *loggingMiddleware) Delete(chanID string, rpm readers.PageMetadata) (count uint64, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method delete for channel %s with query %v took %s to complete", chanID, rpm, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors, %d messages removed.", message, count))
+	}(time.Now())
+
+	return lm.svc.Delete(chanID, rpm)
+}
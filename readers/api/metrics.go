@@ -37,3 +37,39 @@ func (mm *metricsMiddleware) ReadAll(chanID string, rpm readers.PageMetadata) (r
 
 	return mm.svc.ReadAll(chanID, rpm)
 }
+
+func (mm *metricsMiddleware) Aggregate(chanID string, rpm readers.PageMetadata) (readers.AggregatesPage, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "aggregate").Add(1)
+		mm.latency.With("method", "aggregate").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Aggregate(chanID, rpm)
+}
+
+func (mm *metricsMiddleware) Summarize(chanID string, rpm readers.PageMetadata) (readers.Summary, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "summarize").Add(1)
+		mm.latency.With("method", "summarize").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Summarize(chanID, rpm)
+}
+
+func (mm *metricsMiddleware) ReadLatest(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "read_latest").Add(1)
+		mm.latency.With("method", "read_latest").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.ReadLatest(chanID, rpm)
+}
+
+func (mm *metricsMiddleware) Delete(chanID string, rpm readers.PageMetadata) (uint64, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "delete").Add(1)
+		mm.latency.With("method", "delete").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Delete(chanID, rpm)
+}
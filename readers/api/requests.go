@@ -29,6 +29,132 @@ func (req listMessagesReq) validate() error {
 		req.pageMeta.Comparator != readers.GreaterThanEqualKey {
 		return errors.ErrInvalidQueryParams
 	}
+	if req.pageMeta.From < 0 || req.pageMeta.To < 0 ||
+		(req.pageMeta.To != 0 && req.pageMeta.From >= req.pageMeta.To) {
+		return errors.ErrInvalidQueryParams
+	}
+	if req.pageMeta.Dir != "" &&
+		req.pageMeta.Dir != readers.AscDir && req.pageMeta.Dir != readers.DescDir {
+		return errors.ErrInvalidQueryParams
+	}
+	// The page token replaces the offset, and aggregated values are paged
+	// by the offset only.
+	if req.pageMeta.PageToken != "" &&
+		(req.pageMeta.Offset != 0 || req.pageMeta.Aggregation != "" || req.pageMeta.Interval != "") {
+		return errors.ErrInvalidQueryParams
+	}
+	// Only JSON messages contain the payload fields.
+	if len(req.pageMeta.Payload) > 0 &&
+		(req.pageMeta.Format == "" || req.pageMeta.Format == defFormat) {
+		return errors.ErrInvalidQueryParams
+	}
+	for key := range req.pageMeta.Payload {
+		if key == "" {
+			return errors.ErrInvalidQueryParams
+		}
+	}
+	if req.pageMeta.Aggregation == "" && req.pageMeta.Interval == "" {
+		return nil
+	}
+
+	// Only SenML messages contain the values to aggregate.
+	if req.pageMeta.Format != "" && req.pageMeta.Format != defFormat {
+		return errors.ErrInvalidQueryParams
+	}
+	switch req.pageMeta.Aggregation {
+	case readers.AvgAggregation,
+		readers.MinAggregation,
+		readers.MaxAggregation,
+		readers.CountAggregation:
+	default:
+		return errors.ErrInvalidQueryParams
+	}
+	if _, err := readers.ParseInterval(req.pageMeta); err != nil {
+		return errors.ErrInvalidQueryParams
+	}
+
+	return nil
+}
+
+type listChannelsMessagesReq struct {
+	chanIDs  []string
+	pageMeta readers.PageMetadata
+}
+
+func (req listChannelsMessagesReq) validate() error {
+	// Aggregated values don't contain the channel, so they're read per channel.
+	// Page tokens are issued per channel as well.
+	if req.pageMeta.Aggregation != "" || req.pageMeta.Interval != "" ||
+		req.pageMeta.PageToken != "" {
+		return errors.ErrInvalidQueryParams
+	}
+
+	return listMessagesReq{pageMeta: req.pageMeta}.validate()
+}
+
+type streamMessagesReq struct {
+	chanID   string
+	pageMeta readers.PageMetadata
+}
+
+func (req streamMessagesReq) validate() error {
+	// Aggregated values are not streamed, since the live messages are not
+	// aggregated.
+	if req.pageMeta.Aggregation != "" || req.pageMeta.Interval != "" {
+		return errors.ErrInvalidQueryParams
+	}
+
+	return listMessagesReq{chanID: req.chanID, pageMeta: req.pageMeta}.validate()
+}
+
+type summaryReq struct {
+	chanID   string
+	pageMeta readers.PageMetadata
+}
+
+func (req summaryReq) validate() error {
+	// Summary covers all the matching messages, so they're not aggregated.
+	if req.pageMeta.Aggregation != "" || req.pageMeta.Interval != "" {
+		return errors.ErrInvalidQueryParams
+	}
+
+	return listMessagesReq{chanID: req.chanID, pageMeta: req.pageMeta}.validate()
+}
+
+type latestMessagesReq struct {
+	chanID   string
+	pageMeta readers.PageMetadata
+}
+
+func (req latestMessagesReq) validate() error {
+	// Only SenML messages have the name, and the latest messages are not
+	// aggregated.
+	if (req.pageMeta.Format != "" && req.pageMeta.Format != defFormat) ||
+		req.pageMeta.Aggregation != "" || req.pageMeta.Interval != "" {
+		return errors.ErrInvalidQueryParams
+	}
+
+	return listMessagesReq{chanID: req.chanID, pageMeta: req.pageMeta}.validate()
+}
+
+type deleteMessagesReq struct {
+	chanID   string
+	pageMeta readers.PageMetadata
+}
+
+func (req deleteMessagesReq) validate() error {
+	if req.chanID == "" {
+		return errors.ErrInvalidQueryParams
+	}
+	if req.pageMeta.From < 0 || req.pageMeta.To < 0 ||
+		(req.pageMeta.To != 0 && req.pageMeta.From >= req.pageMeta.To) {
+		return errors.ErrInvalidQueryParams
+	}
 
 	return nil
 }
+
+type exportMessagesReq struct {
+	listMessagesReq
+	gzip bool
+}
@@ -14,8 +14,9 @@ var _ mainflux.Response = (*pageRes)(nil)
 
 type pageRes struct {
 	readers.PageMetadata
-	Total    uint64            `json:"total"`
-	Messages []readers.Message `json:"messages,omitempty"`
+	Total         uint64            `json:"total"`
+	Messages      []readers.Message `json:"messages,omitempty"`
+	NextPageToken string            `json:"next_page_token,omitempty"`
 }
 
 func (res pageRes) Headers() map[string]string {
@@ -30,6 +31,86 @@ func (res pageRes) Empty() bool {
 	return false
 }
 
+var _ mainflux.Response = (*aggregatesPageRes)(nil)
+
+type aggregatesPageRes struct {
+	readers.PageMetadata
+	Total      uint64              `json:"total"`
+	Aggregates []readers.Aggregate `json:"aggregates"`
+}
+
+func (res aggregatesPageRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res aggregatesPageRes) Code() int {
+	return http.StatusOK
+}
+
+func (res aggregatesPageRes) Empty() bool {
+	return false
+}
+
+var _ mainflux.Response = (*channelsPageRes)(nil)
+
+type channelsPageRes struct {
+	readers.PageMetadata
+	Total    uint64            `json:"total"`
+	Channels map[string]uint64 `json:"channels"`
+	Messages []readers.Message `json:"messages,omitempty"`
+}
+
+func (res channelsPageRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res channelsPageRes) Code() int {
+	return http.StatusOK
+}
+
+func (res channelsPageRes) Empty() bool {
+	return false
+}
+
+var _ mainflux.Response = (*summaryRes)(nil)
+
+type summaryRes struct {
+	Count      uint64   `json:"count"`
+	First      float64  `json:"first"`
+	Last       float64  `json:"last"`
+	Publishers []string `json:"publishers"`
+}
+
+func (res summaryRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res summaryRes) Code() int {
+	return http.StatusOK
+}
+
+func (res summaryRes) Empty() bool {
+	return false
+}
+
+var _ mainflux.Response = (*deleteMessagesRes)(nil)
+
+type deleteMessagesRes struct {
+	Removed uint64 `json:"removed"`
+}
+
+func (res deleteMessagesRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res deleteMessagesRes) Code() int {
+	return http.StatusOK
+}
+
+func (res deleteMessagesRes) Empty() bool {
+	return false
+}
+
 type errorRes struct {
 	Err string `json:"error"`
 }
@@ -0,0 +1,127 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/go-zoo/bone"
+	"github.com/gorilla/websocket"
+	"github.com/mainflux/mainflux/internal/httputil"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// streamMessages upgrades the connection to WebSocket and sends the latest
+// saved messages of the channel in chronological order, followed by the
+// messages published to the channel as they arrive. The live stream is
+// subscribed to before the saved messages are read, so that no messages are
+// missed in between.
+func streamMessages(svc readers.MessageRepository, stream readers.LiveStream) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		req, err := decodeStream(r)
+		if err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+		if err := req.validate(); err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+
+		msgs, cancel := stream.Subscribe(req.chanID, req.pageMeta)
+		defer cancel()
+
+		page, err := svc.ReadAll(req.chanID, req.pageMeta)
+		if err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// Messages sent by the client are discarded, reading only detects
+		// the closed connection.
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		latest := math.Inf(-1)
+		for i := len(page.Messages) - 1; i >= 0; i-- {
+			if err := conn.WriteJSON(page.Messages[i]); err != nil {
+				return
+			}
+			latest = math.Max(latest, readers.MessageTime(page.Messages[i]))
+		}
+
+		for {
+			select {
+			case msg := <-msgs:
+				// Live messages which are not newer than the saved ones
+				// may have already been sent.
+				if readers.MessageTime(msg) <= latest {
+					continue
+				}
+				if err := conn.WriteJSON(msg); err != nil {
+					return
+				}
+			case <-closed:
+				return
+			}
+		}
+	}
+}
+
+// decodeStream decodes the same query parameters as the messages list, where
+// the limit is the number of the latest saved messages to send. Since browsers
+// can't set the WebSocket request headers, the thing key can be passed as the
+// query parameter as well.
+func decodeStream(r *http.Request) (streamMessagesReq, error) {
+	chanID := bone.GetValue(r, "chanID")
+	if chanID == "" {
+		return streamMessagesReq{}, errors.ErrInvalidQueryParams
+	}
+
+	token := r.Header.Get("Authorization")
+	if token == "" {
+		t, err := httputil.ReadStringQuery(r, authKey, "")
+		if err != nil {
+			return streamMessagesReq{}, err
+		}
+		token = t
+	}
+	if err := authorize(token, chanID); err != nil {
+		return streamMessagesReq{}, err
+	}
+
+	pm, err := decodePageMeta(r)
+	if err != nil {
+		return streamMessagesReq{}, err
+	}
+	pm.Offset = 0
+	pm.Dir = readers.DescDir
+
+	return streamMessagesReq{
+		chanID:   chanID,
+		pageMeta: pm,
+	}, nil
+}
@@ -4,10 +4,16 @@
 package api
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	kithttp "github.com/go-kit/kit/transport/http"
@@ -23,6 +29,7 @@ import (
 
 const (
 	contentType    = "application/json"
+	csvContentType = "text/csv"
 	offsetKey      = "offset"
 	limitKey       = "limit"
 	formatKey      = "format"
@@ -36,19 +43,33 @@ const (
 	comparatorKey  = "comparator"
 	fromKey        = "from"
 	toKey          = "to"
+	aggregationKey = "aggregation"
+	intervalKey    = "interval"
+	dirKey         = "dir"
+	authKey        = "authorization"
+	channelsKey    = "channels"
+	payloadPrefix  = "payload."
+	pageTokenKey   = "page_token"
 	defLimit       = 10
 	defOffset      = 0
 	defFormat      = "messages"
+	maxChannels    = 100
 )
 
 var (
 	errUnauthorizedAccess = errors.New("missing or invalid credentials provided")
 	auth                  mainflux.ThingsServiceClient
+	users                 mainflux.AuthServiceClient
+
+	// formatRegExp matches the formats, used as the table names by the
+	// repositories, that are safe to be used in the queries.
+	formatRegExp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 )
 
 // MakeHandler returns a HTTP handler for API endpoints.
-func MakeHandler(svc readers.MessageRepository, tc mainflux.ThingsServiceClient, svcName string) http.Handler {
+func MakeHandler(svc readers.MessageRepository, tc mainflux.ThingsServiceClient, ac mainflux.AuthServiceClient, stream readers.LiveStream, svcName string) http.Handler {
 	auth = tc
+	users = ac
 
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(encodeError),
@@ -62,6 +83,43 @@ func MakeHandler(svc readers.MessageRepository, tc mainflux.ThingsServiceClient,
 		opts...,
 	))
 
+	mux.Delete("/channels/:chanID/messages", kithttp.NewServer(
+		deleteMessagesEndpoint(svc),
+		decodeDelete,
+		encodeResponse,
+		opts...,
+	))
+
+	mux.Get("/messages", kithttp.NewServer(
+		listChannelsMessagesEndpoint(svc),
+		decodeListChannels,
+		encodeResponse,
+		opts...,
+	))
+
+	mux.Get("/channels/:chanID/messages/export", kithttp.NewServer(
+		exportMessagesEndpoint(svc),
+		decodeExport,
+		encodeExport,
+		opts...,
+	))
+
+	mux.Get("/channels/:chanID/messages/summary", kithttp.NewServer(
+		summaryEndpoint(svc),
+		decodeSummary,
+		encodeResponse,
+		opts...,
+	))
+
+	mux.Get("/channels/:chanID/messages/latest", kithttp.NewServer(
+		latestMessagesEndpoint(svc),
+		decodeLatest,
+		encodeResponse,
+		opts...,
+	))
+
+	mux.GetFunc("/channels/:chanID/messages/stream", streamMessages(svc, stream))
+
 	mux.GetFunc("/version", mainflux.Version(svcName))
 	mux.Handle("/metrics", promhttp.Handler())
 
@@ -74,103 +132,289 @@ func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
 		return nil, errors.ErrInvalidQueryParams
 	}
 
-	if err := authorize(r, chanID); err != nil {
+	if err := authorize(r.Header.Get("Authorization"), chanID); err != nil {
 		return nil, err
 	}
 
-	offset, err := httputil.ReadUintQuery(r, offsetKey, defOffset)
+	pm, err := decodePageMeta(r)
 	if err != nil {
 		return nil, err
 	}
 
-	limit, err := httputil.ReadUintQuery(r, limitKey, defLimit)
+	return listMessagesReq{
+		chanID:   chanID,
+		pageMeta: pm,
+	}, nil
+}
+
+// decodeDelete decodes the format, the publisher and the time range of the
+// messages to delete. Messages can be deleted only by the channel owner, since
+// the thing keys are given to the devices.
+func decodeDelete(_ context.Context, r *http.Request) (interface{}, error) {
+	chanID := bone.GetValue(r, "chanID")
+	if chanID == "" {
+		return nil, errors.ErrInvalidQueryParams
+	}
+
+	if err := authorizeOwner(r.Header.Get("Authorization"), chanID); err != nil {
+		return nil, err
+	}
+
+	format, err := readFormat(r)
+	if err != nil {
+		return nil, err
+	}
+
+	publisher, err := httputil.ReadStringQuery(r, publisherKey, "")
 	if err != nil {
 		return nil, err
 	}
 
+	from, err := httputil.ReadFloatQuery(r, fromKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := httputil.ReadFloatQuery(r, toKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return deleteMessagesReq{
+		chanID: chanID,
+		pageMeta: readers.PageMetadata{
+			Format:    format,
+			Publisher: publisher,
+			From:      from,
+			To:        to,
+		},
+	}, nil
+}
+
+// readFormat reads the format, rejecting the ones that are not valid
+// identifiers, since the format is used as the table name.
+func readFormat(r *http.Request) (string, error) {
 	format, err := httputil.ReadStringQuery(r, formatKey, defFormat)
+	if err != nil {
+		return "", err
+	}
+	if !formatRegExp.MatchString(format) {
+		return "", errors.ErrInvalidQueryParams
+	}
+
+	return format, nil
+}
+
+// decodeListChannels decodes the list of the channels, given either as the
+// comma-separated or repeated query parameter, each of which has to be
+// accessible with the thing key, and the same query parameters as the
+// messages list.
+func decodeListChannels(_ context.Context, r *http.Request) (interface{}, error) {
+	var chanIDs []string
+	seen := make(map[string]bool)
+	for _, chanID := range bone.GetQuery(r, channelsKey) {
+		chanID = strings.TrimSpace(chanID)
+		if chanID == "" || seen[chanID] {
+			continue
+		}
+		seen[chanID] = true
+		chanIDs = append(chanIDs, chanID)
+	}
+	if len(chanIDs) == 0 || len(chanIDs) > maxChannels {
+		return nil, errors.ErrInvalidQueryParams
+	}
+
+	for _, chanID := range chanIDs {
+		if err := authorize(r.Header.Get("Authorization"), chanID); err != nil {
+			return nil, err
+		}
+	}
+
+	pm, err := decodePageMeta(r)
 	if err != nil {
 		return nil, err
 	}
 
+	return listChannelsMessagesReq{
+		chanIDs:  chanIDs,
+		pageMeta: pm,
+	}, nil
+}
+
+func decodePageMeta(r *http.Request) (readers.PageMetadata, error) {
+	offset, err := httputil.ReadUintQuery(r, offsetKey, defOffset)
+	if err != nil {
+		return readers.PageMetadata{}, err
+	}
+
+	limit, err := httputil.ReadUintQuery(r, limitKey, defLimit)
+	if err != nil {
+		return readers.PageMetadata{}, err
+	}
+
+	format, err := readFormat(r)
+	if err != nil {
+		return readers.PageMetadata{}, err
+	}
+
 	subtopic, err := httputil.ReadStringQuery(r, subtopicKey, "")
 	if err != nil {
-		return nil, err
+		return readers.PageMetadata{}, err
 	}
 
 	publisher, err := httputil.ReadStringQuery(r, publisherKey, "")
 	if err != nil {
-		return nil, err
+		return readers.PageMetadata{}, err
 	}
 
 	protocol, err := httputil.ReadStringQuery(r, protocolKey, "")
 	if err != nil {
-		return nil, err
+		return readers.PageMetadata{}, err
 	}
 
 	name, err := httputil.ReadStringQuery(r, nameKey, "")
 	if err != nil {
-		return nil, err
+		return readers.PageMetadata{}, err
 	}
 
 	v, err := httputil.ReadFloatQuery(r, valueKey, 0)
 	if err != nil {
-		return nil, err
+		return readers.PageMetadata{}, err
 	}
 
 	comparator, err := httputil.ReadStringQuery(r, comparatorKey, "")
 	if err != nil {
-		return nil, err
+		return readers.PageMetadata{}, err
 	}
 
 	vs, err := httputil.ReadStringQuery(r, stringValueKey, "")
 	if err != nil {
-		return nil, err
+		return readers.PageMetadata{}, err
 	}
 
 	vd, err := httputil.ReadStringQuery(r, dataValueKey, "")
 	if err != nil {
-		return nil, err
+		return readers.PageMetadata{}, err
 	}
 
 	from, err := httputil.ReadFloatQuery(r, fromKey, 0)
 	if err != nil {
-		return nil, err
+		return readers.PageMetadata{}, err
 	}
 
 	to, err := httputil.ReadFloatQuery(r, toKey, 0)
 	if err != nil {
-		return nil, err
+		return readers.PageMetadata{}, err
 	}
 
-	req := listMessagesReq{
-		chanID: chanID,
-		pageMeta: readers.PageMetadata{
-			Offset:      offset,
-			Limit:       limit,
-			Format:      format,
-			Subtopic:    subtopic,
-			Publisher:   publisher,
-			Protocol:    protocol,
-			Name:        name,
-			Value:       v,
-			Comparator:  comparator,
-			StringValue: vs,
-			DataValue:   vd,
-			From:        from,
-			To:          to,
-		},
+	aggregation, err := httputil.ReadStringQuery(r, aggregationKey, "")
+	if err != nil {
+		return readers.PageMetadata{}, err
+	}
+
+	interval, err := httputil.ReadStringQuery(r, intervalKey, "")
+	if err != nil {
+		return readers.PageMetadata{}, err
+	}
+
+	dir, err := httputil.ReadStringQuery(r, dirKey, "")
+	if err != nil {
+		return readers.PageMetadata{}, err
+	}
+
+	pageToken, err := httputil.ReadStringQuery(r, pageTokenKey, "")
+	if err != nil {
+		return readers.PageMetadata{}, err
+	}
+
+	pm := readers.PageMetadata{
+		Offset:      offset,
+		Limit:       limit,
+		Format:      format,
+		Subtopic:    subtopic,
+		Publisher:   publisher,
+		Protocol:    protocol,
+		Name:        name,
+		Value:       v,
+		Comparator:  comparator,
+		StringValue: vs,
+		DataValue:   vd,
+		From:        from,
+		To:          to,
+		Aggregation: aggregation,
+		Interval:    interval,
+		Dir:         dir,
+		PageToken:   pageToken,
 	}
 
 	vb, err := readBoolValueQuery(r, "vb")
 	if err != nil && err != errors.ErrNotFoundParam {
-		return nil, err
+		return readers.PageMetadata{}, err
 	}
 	if err == nil {
-		req.pageMeta.BoolValue = vb
+		pm.BoolValue = vb
 	}
 
-	return req, nil
+	if pm.Payload, err = readPayloadQuery(r); err != nil {
+		return readers.PageMetadata{}, err
+	}
+
+	return pm, nil
+}
+
+// decodeExport decodes the same query parameters as the messages list, except
+// for the offset and limit, since all the messages are exported. Messages are
+// exported in chronological order by default.
+func decodeExport(ctx context.Context, r *http.Request) (interface{}, error) {
+	req, err := decodeList(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	listReq := req.(listMessagesReq)
+	listReq.pageMeta.Offset = 0
+	listReq.pageMeta.Limit = exportBatchSize
+	if listReq.pageMeta.Dir == "" {
+		listReq.pageMeta.Dir = readers.AscDir
+	}
+
+	return exportMessagesReq{
+		listMessagesReq: listReq,
+		gzip:            strings.Contains(r.Header.Get("Accept-Encoding"), "gzip"),
+	}, nil
+}
+
+// decodeSummary decodes the same filters as the messages list. Offset and limit
+// are ignored, since all the matching messages are summarized.
+func decodeSummary(ctx context.Context, r *http.Request) (interface{}, error) {
+	req, err := decodeList(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	listReq := req.(listMessagesReq)
+	listReq.pageMeta.Offset = defOffset
+	listReq.pageMeta.Limit = defLimit
+
+	return summaryReq{
+		chanID:   listReq.chanID,
+		pageMeta: listReq.pageMeta,
+	}, nil
+}
+
+// decodeLatest decodes the same filters as the messages list. Offset and limit
+// page the publisher and name pairs.
+func decodeLatest(ctx context.Context, r *http.Request) (interface{}, error) {
+	req, err := decodeList(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	listReq := req.(listMessagesReq)
+	return latestMessagesReq{
+		chanID:   listReq.chanID,
+		pageMeta: listReq.pageMeta,
+	}, nil
 }
 
 func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
@@ -191,10 +435,59 @@ func encodeResponse(_ context.Context, w http.ResponseWriter, response interface
 	return json.NewEncoder(w).Encode(response)
 }
 
+// encodeExport streams the CSV rows of the exported messages, flushing each
+// batch as soon as it's read. The first batch is read before the response is
+// written, so that the errors are reported with the status code.
+func encodeExport(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(exportRes)
+
+	rows, err := res.rows(0)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", csvContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, res.filename))
+	var out io.Writer = w
+	if res.gzip {
+		w.Header().Set("Content-Encoding", "gzip")
+		gw := gzip.NewWriter(w)
+		defer gw.Close()
+		out = gw
+	}
+
+	cw := csv.NewWriter(out)
+	if err := cw.Write(res.header); err != nil {
+		return err
+	}
+	for offset := uint64(0); ; {
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		if gw, ok := out.(*gzip.Writer); ok {
+			if err := gw.Flush(); err != nil {
+				return err
+			}
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		if len(rows) < exportBatchSize {
+			return nil
+		}
+
+		offset += uint64(len(rows))
+		if rows, err = res.rows(offset); err != nil {
+			return err
+		}
+	}
+}
+
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	switch {
 	case errors.Contains(err, nil):
-	case errors.Contains(err, errors.ErrInvalidQueryParams):
+	case errors.Contains(err, errors.ErrInvalidQueryParams),
+		errors.Contains(err, readers.ErrInvalidPageToken):
 		w.WriteHeader(http.StatusBadRequest)
 	case errors.Contains(err, errUnauthorizedAccess):
 		w.WriteHeader(http.StatusForbidden)
@@ -210,8 +503,7 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	}
 }
 
-func authorize(r *http.Request, chanID string) error {
-	token := r.Header.Get("Authorization")
+func authorize(token, chanID string) error {
 	if token == "" {
 		return errUnauthorizedAccess
 	}
@@ -220,9 +512,43 @@ func authorize(r *http.Request, chanID string) error {
 	defer cancel()
 
 	_, err := auth.CanAccessByKey(ctx, &mainflux.AccessByKeyReq{Token: token, ChanID: chanID})
+	if err == nil {
+		return nil
+	}
+	if e, ok := status.FromError(err); !ok || e.Code() != codes.PermissionDenied {
+		return err
+	}
+
+	// The token is not the key of the thing connected to the channel, so it
+	// is checked as the token of the user who owns the channel.
+	return authorizeUser(ctx, token, chanID)
+}
+
+func authorizeOwner(token, chanID string) error {
+	if token == "" {
+		return errUnauthorizedAccess
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	return authorizeUser(ctx, token, chanID)
+}
+
+func authorizeUser(ctx context.Context, token, chanID string) error {
+	id, err := users.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		e, ok := status.FromError(err)
+		if ok && e.Code() == codes.Unauthenticated {
+			return errUnauthorizedAccess
+		}
+		return err
+	}
+
+	_, err = auth.IsChannelOwner(ctx, &mainflux.ChannelOwnerReq{Owner: id.GetEmail(), ChanID: chanID})
 	if err != nil {
 		e, ok := status.FromError(err)
-		if ok && e.Code() == codes.PermissionDenied {
+		if ok && (e.Code() == codes.NotFound || e.Code() == codes.PermissionDenied) {
 			return errUnauthorizedAccess
 		}
 		return err
@@ -248,3 +574,24 @@ func readBoolValueQuery(r *http.Request, key string) (bool, error) {
 
 	return b, nil
 }
+
+// readPayloadQuery reads the filters of the top-level JSON payload fields,
+// given as the query parameters prefixed with "payload.", such as
+// "payload.temperature=21".
+func readPayloadQuery(r *http.Request) (map[string]string, error) {
+	var filters map[string]string
+	for key, vals := range r.URL.Query() {
+		if !strings.HasPrefix(key, payloadPrefix) {
+			continue
+		}
+		if len(vals) > 1 {
+			return nil, errors.ErrInvalidQueryParams
+		}
+		if filters == nil {
+			filters = make(map[string]string)
+		}
+		filters[strings.TrimPrefix(key, payloadPrefix)] = vals[0]
+	}
+
+	return filters, nil
+}
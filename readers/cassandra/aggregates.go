@@ -0,0 +1,111 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cassandra
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gocql/gocql"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+// aggregateKey identifies the group of the messages which are aggregated.
+type aggregateKey struct {
+	time      float64
+	name      string
+	publisher string
+}
+
+// Aggregate reads the messages matching the page metadata and aggregates
+// them while iterating. Cassandra supports grouping by the primary key
+// columns only, so the messages can't be grouped by the time interval, name
+// and publisher in the query.
+func (cr cassandraRepository) Aggregate(chanID string, rpm readers.PageMetadata) (readers.AggregatesPage, error) {
+	interval, err := readers.ParseInterval(rpm)
+	if err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	switch rpm.Aggregation {
+	case readers.AvgAggregation, readers.MinAggregation, readers.MaxAggregation, readers.CountAggregation:
+	default:
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, readers.ErrInvalidAggregation)
+	}
+
+	q, vals := buildQuery(chanID, rpm)
+	// Messages are aggregated regardless of the page, so the limit is dropped.
+	cql := fmt.Sprintf(`SELECT name, publisher, value, time FROM %s WHERE channel = ? %s ALLOW FILTERING`, defTable, q)
+	iter := cr.session.Query(cql, vals[:len(vals)-1]...).Iter()
+	scanner := iter.Scanner()
+
+	aggs := map[aggregateKey]*readers.Aggregate{}
+	counts := map[aggregateKey]float64{}
+	for scanner.Next() {
+		var name, publisher string
+		var value *float64
+		var t float64
+		if err := scanner.Scan(&name, &publisher, &value, &t); err != nil {
+			iter.Close()
+			return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+		}
+		if value == nil && rpm.Aggregation != readers.CountAggregation {
+			continue
+		}
+
+		key := aggregateKey{
+			time:      math.Floor(t/interval.Seconds()) * interval.Seconds(),
+			name:      name,
+			publisher: publisher,
+		}
+		agg, ok := aggs[key]
+		if !ok {
+			agg = &readers.Aggregate{Time: key.time, Name: name, Publisher: publisher}
+			aggs[key] = agg
+		}
+		counts[key]++
+		switch {
+		case rpm.Aggregation == readers.CountAggregation:
+			agg.Value = counts[key]
+		case rpm.Aggregation == readers.AvgAggregation:
+			agg.Value += (*value - agg.Value) / counts[key]
+		case !ok,
+			rpm.Aggregation == readers.MinAggregation && *value < agg.Value,
+			rpm.Aggregation == readers.MaxAggregation && *value > agg.Value:
+			agg.Value = *value
+		}
+	}
+	if err := iter.Close(); err != nil {
+		if e, ok := err.(gocql.RequestError); ok && e.Code() == undefinedTableCode {
+			return readers.AggregatesPage{}, nil
+		}
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+
+	sorted := make([]readers.Aggregate, 0, len(aggs))
+	for _, agg := range aggs {
+		sorted = append(sorted, *agg)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Time != sorted[j].Time {
+			return (sorted[i].Time > sorted[j].Time) != (rpm.Dir == readers.AscDir)
+		}
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Publisher < sorted[j].Publisher
+	})
+
+	page := readers.AggregatesPage{
+		PageMetadata: rpm,
+		Total:        uint64(len(sorted)),
+		Aggregates:   []readers.Aggregate{},
+	}
+	for i := rpm.Offset; i < page.Total && i < rpm.Offset+rpm.Limit; i++ {
+		page.Aggregates = append(page.Aggregates, sorted[i])
+	}
+
+	return page, nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cassandra
+
+import (
+	"fmt"
+
+	"github.com/gocql/gocql"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+const deleteBatchSize = 100
+
+var errDeleteMessages = errors.New("failed to delete messages from cassandra database")
+
+// Delete reads the primary keys of the matching messages and deletes them in
+// batches, since Cassandra deletes the rows by the primary key only. Batches
+// contain the rows of the same partition, so they're not logged.
+func (cr cassandraRepository) Delete(chanID string, rpm readers.PageMetadata) (uint64, error) {
+	table, timeColumn := defTable, "time"
+	if rpm.Format != "" && rpm.Format != defTable {
+		table, timeColumn = rpm.Format, "created"
+	}
+
+	// Only the publisher and the time range are used to select the messages.
+	pm := readers.PageMetadata{
+		Format:    rpm.Format,
+		Publisher: rpm.Publisher,
+		From:      rpm.From,
+		To:        rpm.To,
+	}
+	q, vals := buildQuery(chanID, pm)
+	cql := fmt.Sprintf(`SELECT %s, id FROM %s WHERE channel = ? %s ALLOW FILTERING`, timeColumn, table, q)
+	iter := cr.session.Query(cql, vals[:len(vals)-1]...).Iter()
+	scanner := iter.Scanner()
+
+	del := fmt.Sprintf(`DELETE FROM %s WHERE channel = ? AND %s = ? AND id = ?`, table, timeColumn)
+	batch := cr.session.NewBatch(gocql.UnloggedBatch)
+	var count uint64
+	for scanner.Next() {
+		var t interface{}
+		var id gocql.UUID
+		var err error
+		if table == defTable {
+			var ts float64
+			err = scanner.Scan(&ts, &id)
+			t = ts
+		} else {
+			var created int64
+			err = scanner.Scan(&created, &id)
+			t = created
+		}
+		if err != nil {
+			iter.Close()
+			return count, errors.Wrap(errDeleteMessages, err)
+		}
+
+		batch.Query(del, chanID, t, id)
+		if batch.Size() < deleteBatchSize {
+			continue
+		}
+		if err := cr.session.ExecuteBatch(batch); err != nil {
+			iter.Close()
+			return count, errors.Wrap(errDeleteMessages, err)
+		}
+		count += uint64(batch.Size())
+		batch = cr.session.NewBatch(gocql.UnloggedBatch)
+	}
+	if err := iter.Close(); err != nil {
+		if e, ok := err.(gocql.RequestError); ok && e.Code() == undefinedTableCode {
+			return 0, nil
+		}
+		return count, errors.Wrap(errDeleteMessages, err)
+	}
+
+	if batch.Size() > 0 {
+		if err := cr.session.ExecuteBatch(batch); err != nil {
+			return count, errors.Wrap(errDeleteMessages, err)
+		}
+		count += uint64(batch.Size())
+	}
+
+	return count, nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cassandra
+
+import (
+	"fmt"
+
+	"github.com/gocql/gocql"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+)
+
+// ReadLatest reads the messages of the channel from the newest one and keeps
+// the first message of each publisher and name pair, since the messages are
+// partitioned by the channel only, so Cassandra can't group them by the pair.
+func (cr cassandraRepository) ReadLatest(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	q, vals := buildQuery(chanID, rpm)
+	cql := fmt.Sprintf(`SELECT channel, subtopic, publisher, protocol, name, unit,
+		value, string_value, bool_value, data_value, sum, time,
+		update_time FROM %s WHERE channel = ? %s ALLOW FILTERING`, defTable, q)
+	iter := cr.session.Query(cql, vals[:len(vals)-1]...).Iter()
+	scanner := iter.Scanner()
+
+	type pair struct {
+		publisher string
+		name      string
+	}
+	seen := map[pair]bool{}
+	var msgs []senml.Message
+	for scanner.Next() {
+		var msg senml.Message
+		err := scanner.Scan(&msg.Channel, &msg.Subtopic, &msg.Publisher, &msg.Protocol,
+			&msg.Name, &msg.Unit, &msg.Value, &msg.StringValue, &msg.BoolValue,
+			&msg.DataValue, &msg.Sum, &msg.Time, &msg.UpdateTime)
+		if err != nil {
+			iter.Close()
+			return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+		}
+		p := pair{publisher: msg.Publisher, name: msg.Name}
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		msgs = append(msgs, msg)
+	}
+	if err := iter.Close(); err != nil {
+		if e, ok := err.(gocql.RequestError); ok && e.Code() == undefinedTableCode {
+			return readers.MessagesPage{}, nil
+		}
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+
+	return readers.LatestPage(msgs, rpm), nil
+}
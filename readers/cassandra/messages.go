@@ -4,6 +4,7 @@
 package cassandra
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 
@@ -23,6 +24,9 @@ const (
 
 	// Error code for Undefined table error.
 	undefinedTableCode = 8704
+
+	// Maximum number of the rows read in a single page.
+	maxPageSize = 5000
 )
 
 var _ readers.MessageRepository = (*cassandraRepository)(nil)
@@ -45,28 +49,37 @@ func (cr cassandraRepository) ReadAll(chanID string, rpm readers.PageMetadata) (
 	}
 
 	q, vals := buildQuery(chanID, rpm)
+	if format != defTable && len(rpm.Payload) > 0 {
+		return cr.readPayload(format, q, vals, rpm)
+	}
+
+	// Messages are clustered from the newest one, so only the ascending
+	// order needs to be set.
+	var order string
+	if rpm.Dir == readers.AscDir {
+		order = "ORDER BY time ASC"
+		if format != defTable {
+			order = "ORDER BY created ASC"
+		}
+	}
 
+	// Messages are paged by the paging state, so the limit is dropped.
 	selectCQL := fmt.Sprintf(`SELECT channel, subtopic, publisher, protocol, name, unit,
 		value, string_value, bool_value, data_value, sum, time,
-		update_time FROM messages WHERE channel = ? %s LIMIT ?
-		ALLOW FILTERING`, q)
+		update_time FROM messages WHERE channel = ? %s %s
+		ALLOW FILTERING`, q, order)
 	countCQL := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE channel = ? %s ALLOW FILTERING`, format, q)
 
 	if format != defTable {
-		selectCQL = fmt.Sprintf(`SELECT channel, subtopic, publisher, protocol, created, payload FROM %s WHERE channel = ? %s LIMIT ?
-			ALLOW FILTERING`, format, q)
+		selectCQL = fmt.Sprintf(`SELECT channel, subtopic, publisher, protocol, created, payload FROM %s WHERE channel = ? %s %s
+			ALLOW FILTERING`, format, q, order)
 		countCQL = fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE channel = ? %s ALLOW FILTERING`, format, q)
 	}
+	vals = vals[:len(vals)-1]
 
-	iter := cr.session.Query(selectCQL, vals...).Iter()
-	defer iter.Close()
-	scanner := iter.Scanner()
-
-	// skip first OFFSET rows
-	for i := uint64(0); i < rpm.Offset; i++ {
-		if !scanner.Next() {
-			break
-		}
+	state, err := decodePageToken(rpm.PageToken)
+	if err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
 	}
 
 	page := readers.MessagesPage{
@@ -74,45 +87,53 @@ func (cr cassandraRepository) ReadAll(chanID string, rpm readers.PageMetadata) (
 		Messages:     []readers.Message{},
 	}
 
-	switch format {
-	case defTable:
-		for scanner.Next() {
-			var msg senml.Message
-			err := scanner.Scan(&msg.Channel, &msg.Subtopic, &msg.Publisher, &msg.Protocol,
-				&msg.Name, &msg.Unit, &msg.Value, &msg.StringValue, &msg.BoolValue,
-				&msg.DataValue, &msg.Sum, &msg.Time, &msg.UpdateTime)
-			if err != nil {
-				if e, ok := err.(gocql.RequestError); ok {
-					if e.Code() == undefinedTableCode {
-						return readers.MessagesPage{}, nil
-					}
-				}
-				return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
-			}
-			page.Messages = append(page.Messages, msg)
+	scan := func(scanner gocql.Scanner) error {
+		var msg senml.Message
+		if err := scanner.Scan(&msg.Channel, &msg.Subtopic, &msg.Publisher, &msg.Protocol,
+			&msg.Name, &msg.Unit, &msg.Value, &msg.StringValue, &msg.BoolValue,
+			&msg.DataValue, &msg.Sum, &msg.Time, &msg.UpdateTime); err != nil {
+			return err
 		}
-	default:
-		for scanner.Next() {
+		page.Messages = append(page.Messages, msg)
+		return nil
+	}
+	if format != defTable {
+		scan = func(scanner gocql.Scanner) error {
 			var msg jsonMessage
-			err := scanner.Scan(&msg.Channel, &msg.Subtopic, &msg.Publisher, &msg.Protocol, &msg.Created, &msg.Payload)
-			if err != nil {
-				if e, ok := err.(gocql.RequestError); ok {
-					if e.Code() == undefinedTableCode {
-						return readers.MessagesPage{}, nil
-					}
-				}
-				return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+			if err := scanner.Scan(&msg.Channel, &msg.Subtopic, &msg.Publisher, &msg.Protocol, &msg.Created, &msg.Payload); err != nil {
+				return err
 			}
 			m, err := msg.toMap()
 			if err != nil {
-				return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+				return err
 			}
 			m["payload"] = jsont.ParseFlat(m["payload"])
 			page.Messages = append(page.Messages, m)
+			return nil
 		}
 	}
 
-	if err := cr.session.Query(countCQL, vals[:len(vals)-1]...).Scan(&page.Total); err != nil {
+	// Without the page token, the offset rows are skipped page by page,
+	// which is much slower than continuing from the token of the previous
+	// page.
+	more := true
+	if state == nil && rpm.Offset > 0 {
+		skip := func(gocql.Scanner) error { return nil }
+		state, err = cr.readPages(selectCQL, vals, nil, rpm.Offset, skip)
+		more = state != nil
+	}
+	if err == nil && more {
+		state, err = cr.readPages(selectCQL, vals, state, rpm.Limit, scan)
+		page.NextPageToken = encodePageToken(state)
+	}
+	if err != nil {
+		if e, ok := err.(gocql.RequestError); ok && e.Code() == undefinedTableCode {
+			return readers.MessagesPage{}, nil
+		}
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+
+	if err := cr.session.Query(countCQL, vals...).Scan(&page.Total); err != nil {
 		if e, ok := err.(gocql.RequestError); ok {
 			if e.Code() == undefinedTableCode {
 				return readers.MessagesPage{}, nil
@@ -124,6 +145,111 @@ func (cr cassandraRepository) ReadAll(chanID string, rpm readers.PageMetadata) (
 	return page, nil
 }
 
+// readPages reads the rows of the query page by page, starting at the paging
+// state, until the given number of rows is scanned or all the rows are read.
+// Pages of the filtered queries can contain less rows than requested, so the
+// pages are read until the number of the rows is reached. The returned paging
+// state of the rest of the rows is nil if all the rows are read.
+func (cr cassandraRepository) readPages(cql string, vals []interface{}, state []byte, n uint64, scan func(gocql.Scanner) error) ([]byte, error) {
+	for read := uint64(0); read < n; {
+		size := n - read
+		if size > maxPageSize {
+			size = maxPageSize
+		}
+		iter := cr.session.Query(cql, vals...).PageSize(int(size)).PageState(state).Iter()
+		state = iter.PageState()
+		scanner := iter.Scanner()
+		for scanner.Next() {
+			if err := scan(scanner); err != nil {
+				scanner.Err()
+				return nil, err
+			}
+			read++
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		if len(state) == 0 {
+			return nil, nil
+		}
+	}
+
+	return state, nil
+}
+
+// encodePageToken returns the page token of the paging state.
+func encodePageToken(state []byte) string {
+	return base64.RawURLEncoding.EncodeToString(state)
+}
+
+// decodePageToken returns the paging state of the page token, which is nil if
+// the token is empty.
+func decodePageToken(token string) ([]byte, error) {
+	if token == "" {
+		return nil, nil
+	}
+	state, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(state) == 0 {
+		return nil, readers.ErrInvalidPageToken
+	}
+
+	return state, nil
+}
+
+// readPayload reads the JSON messages matching the payload field filters.
+// Since Cassandra can't filter by the payload, all the messages matching the
+// rest of the page metadata are read and filtered while iterating, in order
+// to count them and skip the offset.
+func (cr cassandraRepository) readPayload(format, q string, vals []interface{}, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	var order string
+	if rpm.Dir == readers.AscDir {
+		order = "ORDER BY created ASC"
+	}
+	cql := fmt.Sprintf(`SELECT channel, subtopic, publisher, protocol, created, payload FROM %s WHERE channel = ? %s %s
+		ALLOW FILTERING`, format, q, order)
+	iter := cr.session.Query(cql, vals[:len(vals)-1]...).Iter()
+	scanner := iter.Scanner()
+
+	page := readers.MessagesPage{
+		PageMetadata: rpm,
+		Messages:     []readers.Message{},
+	}
+	for scanner.Next() {
+		var msg jsonMessage
+		if err := scanner.Scan(&msg.Channel, &msg.Subtopic, &msg.Publisher, &msg.Protocol, &msg.Created, &msg.Payload); err != nil {
+			iter.Close()
+			return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+		}
+		match, err := msg.matchPayload(rpm.Payload)
+		if err != nil {
+			iter.Close()
+			return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+		}
+		if !match {
+			continue
+		}
+		page.Total++
+		if page.Total <= rpm.Offset || page.Total > rpm.Offset+rpm.Limit {
+			continue
+		}
+		m, err := msg.toMap()
+		if err != nil {
+			iter.Close()
+			return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+		}
+		m["payload"] = jsont.ParseFlat(m["payload"])
+		page.Messages = append(page.Messages, m)
+	}
+	if err := iter.Close(); err != nil {
+		if e, ok := err.(gocql.RequestError); ok && e.Code() == undefinedTableCode {
+			return readers.MessagesPage{}, nil
+		}
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+
+	return page, nil
+}
+
 func buildQuery(chanID string, rpm readers.PageMetadata) (string, []interface{}) {
 	var condCQL string
 	vals := []interface{}{chanID}
@@ -135,6 +261,9 @@ func buildQuery(chanID string, rpm readers.PageMetadata) (string, []interface{})
 	}
 	json.Unmarshal(meta, &query)
 
+	// JSON messages are filtered by the creation time in nanoseconds.
+	jsonFormat := rpm.Format != "" && rpm.Format != defTable
+
 	for name, val := range query {
 		switch name {
 		case
@@ -159,9 +288,19 @@ func buildQuery(chanID string, rpm readers.PageMetadata) (string, []interface{})
 			vals = append(vals, val)
 			condCQL = fmt.Sprintf(`%s AND data_value = ?`, condCQL)
 		case "from":
+			if jsonFormat {
+				vals = append(vals, int64(rpm.From*1e9))
+				condCQL = fmt.Sprintf(`%s AND created >= ?`, condCQL)
+				continue
+			}
 			vals = append(vals, val)
 			condCQL = fmt.Sprintf(`%s AND time >= ?`, condCQL)
 		case "to":
+			if jsonFormat {
+				vals = append(vals, int64(rpm.To*1e9))
+				condCQL = fmt.Sprintf(`%s AND created < ?`, condCQL)
+				continue
+			}
 			vals = append(vals, val)
 			condCQL = fmt.Sprintf(`%s AND time < ?`, condCQL)
 		}
@@ -198,3 +337,16 @@ func (msg jsonMessage) toMap() (map[string]interface{}, error) {
 	ret["payload"] = pld
 	return ret, nil
 }
+
+// matchPayload reports whether the message payload matches the payload field
+// filters.
+func (msg jsonMessage) matchPayload(filters map[string]string) (bool, error) {
+	if len(filters) == 0 {
+		return true, nil
+	}
+	pld := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(msg.Payload), &pld); err != nil {
+		return false, err
+	}
+	return readers.MatchPayload(pld, filters), nil
+}
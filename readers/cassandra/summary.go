@@ -0,0 +1,78 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cassandra
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gocql/gocql"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+// Summarize reads the publishers and the times of the messages matching the
+// page metadata and summarizes them while iterating, since Cassandra can't
+// select the distinct values of the columns which are not partition keys.
+// JSON messages are filtered by the payload fields while iterating as well.
+func (cr cassandraRepository) Summarize(chanID string, rpm readers.PageMetadata) (readers.Summary, error) {
+	table, columns := defTable, "publisher, time"
+	if rpm.Format != "" && rpm.Format != defTable {
+		table, columns = rpm.Format, "publisher, created, payload"
+	}
+
+	q, vals := buildQuery(chanID, rpm)
+	// Messages are summarized regardless of the page, so the limit is dropped.
+	cql := fmt.Sprintf(`SELECT %s FROM %s WHERE channel = ? %s ALLOW FILTERING`, columns, table, q)
+	iter := cr.session.Query(cql, vals[:len(vals)-1]...).Iter()
+	scanner := iter.Scanner()
+
+	summary := readers.Summary{Publishers: []string{}}
+	publishers := map[string]bool{}
+	for scanner.Next() {
+		var publisher string
+		var t float64
+		var err error
+		match := true
+		if table == defTable {
+			err = scanner.Scan(&publisher, &t)
+		} else {
+			// JSON messages are created in nanoseconds.
+			var msg jsonMessage
+			err = scanner.Scan(&publisher, &msg.Created, &msg.Payload)
+			t = float64(msg.Created) / 1e9
+			if err == nil {
+				match, err = msg.matchPayload(rpm.Payload)
+			}
+		}
+		if err != nil {
+			iter.Close()
+			return readers.Summary{}, errors.Wrap(errReadMessages, err)
+		}
+		if !match {
+			continue
+		}
+
+		if summary.Count == 0 || t < summary.First {
+			summary.First = t
+		}
+		if summary.Count == 0 || t > summary.Last {
+			summary.Last = t
+		}
+		summary.Count++
+		if !publishers[publisher] {
+			publishers[publisher] = true
+			summary.Publishers = append(summary.Publishers, publisher)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		if e, ok := err.(gocql.RequestError); ok && e.Code() == undefinedTableCode {
+			return readers.Summary{Publishers: []string{}}, nil
+		}
+		return readers.Summary{}, errors.Wrap(errReadMessages, err)
+	}
+	sort.Strings(summary.Publishers)
+
+	return summary, nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+var aggregateFuncs = map[string]string{
+	readers.AvgAggregation:   "avg(value)",
+	readers.MinAggregation:   "min(value)",
+	readers.MaxAggregation:   "max(value)",
+	readers.CountAggregation: "count()",
+}
+
+type aggregate struct {
+	Bucket    float64 `json:"bucket"`
+	Name      string  `json:"name"`
+	Publisher string  `json:"publisher"`
+	Value     float64 `json:"value"`
+}
+
+func (cr clickhouseRepository) Aggregate(chanID string, rpm readers.PageMetadata) (readers.AggregatesPage, error) {
+	interval, err := readers.ParseInterval(rpm)
+	if err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	fn, ok := aggregateFuncs[rpm.Aggregation]
+	if !ok {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, readers.ErrInvalidAggregation)
+	}
+
+	condition := fmtCondition(rpm)
+	if rpm.Aggregation != readers.CountAggregation {
+		condition = fmt.Sprintf(`%s AND value IS NOT NULL`, condition)
+	}
+	params := map[string]string{
+		"channel":      chanID,
+		"limit":        strconv.FormatUint(rpm.Limit, 10),
+		"offset":       strconv.FormatUint(rpm.Offset, 10),
+		"interval":     strconv.FormatFloat(interval.Seconds(), 'f', -1, 64),
+		"subtopic":     rpm.Subtopic,
+		"publisher":    rpm.Publisher,
+		"name":         rpm.Name,
+		"protocol":     rpm.Protocol,
+		"value":        strconv.FormatFloat(rpm.Value, 'f', -1, 64),
+		"bool_value":   "0",
+		"string_value": rpm.StringValue,
+		"data_value":   rpm.DataValue,
+		"from":         strconv.FormatFloat(rpm.From, 'f', -1, 64),
+		"to":           strconv.FormatFloat(rpm.To, 'f', -1, 64),
+	}
+	if rpm.BoolValue {
+		params["bool_value"] = "1"
+	}
+
+	groups := fmt.Sprintf(`SELECT floor(time / {interval:Float64}) * {interval:Float64} AS bucket, name, publisher, %s AS value
+    FROM %s WHERE %s GROUP BY bucket, name, publisher`, fn, defTable, condition)
+
+	var aggs []aggregate
+	q := fmt.Sprintf(`%s ORDER BY bucket %s, name, publisher
+    LIMIT {limit:UInt64} OFFSET {offset:UInt64}`, groups, fmtDir(rpm))
+	if err := cr.client.Query(q, params, &aggs); err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+
+	page := readers.AggregatesPage{
+		PageMetadata: rpm,
+		Aggregates:   []readers.Aggregate{},
+	}
+	for _, a := range aggs {
+		page.Aggregates = append(page.Aggregates, readers.Aggregate{
+			Time:      a.Bucket,
+			Name:      a.Name,
+			Publisher: a.Publisher,
+			Value:     a.Value,
+		})
+	}
+
+	var total []struct {
+		Total uint64 `json:"total"`
+	}
+	q = fmt.Sprintf(`SELECT count() AS total FROM (%s)`, groups)
+	if err := cr.client.Query(q, params, &total); err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	if len(total) > 0 {
+		page.Total = total[0].Total
+	}
+
+	return page, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+var errDeleteMessages = errors.New("failed to delete messages from clickhouse database")
+
+// Delete counts the matching messages before deleting them, since the
+// mutations don't return the number of the deleted rows.
+func (cr clickhouseRepository) Delete(chanID string, rpm readers.PageMetadata) (uint64, error) {
+	// Only SenML messages are stored by ClickHouse writer.
+	if rpm.Format != "" && rpm.Format != defTable {
+		return 0, nil
+	}
+
+	// Only the publisher and the time range are used to select the messages.
+	pm := readers.PageMetadata{
+		Publisher: rpm.Publisher,
+		From:      rpm.From,
+		To:        rpm.To,
+	}
+	params := map[string]string{
+		"channel":   chanID,
+		"publisher": pm.Publisher,
+		"from":      strconv.FormatFloat(pm.From, 'f', -1, 64),
+		"to":        strconv.FormatFloat(pm.To, 'f', -1, 64),
+	}
+	condition := fmtCondition(pm)
+
+	q := fmt.Sprintf(`SELECT count() AS total FROM %s WHERE %s`, defTable, condition)
+	var total []struct {
+		Total uint64 `json:"total"`
+	}
+	if err := cr.client.Query(q, params, &total); err != nil {
+		return 0, errors.Wrap(errDeleteMessages, err)
+	}
+	if len(total) < 1 || total[0].Total == 0 {
+		return 0, nil
+	}
+
+	q = fmt.Sprintf(`ALTER TABLE %s DELETE WHERE %s`, defTable, condition)
+	if err := cr.client.Mutate(q, params); err != nil {
+		return 0, errors.Wrap(errDeleteMessages, err)
+	}
+
+	return total[0].Total, nil
+}
@@ -0,0 +1,6 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clickhouse contains repository implementations using ClickHouse as
+// the underlying database.
+package clickhouse
@@ -0,0 +1,65 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+func (cr clickhouseRepository) ReadLatest(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	condition := fmtCondition(rpm)
+	params := map[string]string{
+		"channel":      chanID,
+		"limit":        strconv.FormatUint(rpm.Limit, 10),
+		"offset":       strconv.FormatUint(rpm.Offset, 10),
+		"subtopic":     rpm.Subtopic,
+		"publisher":    rpm.Publisher,
+		"name":         rpm.Name,
+		"protocol":     rpm.Protocol,
+		"value":        strconv.FormatFloat(rpm.Value, 'f', -1, 64),
+		"bool_value":   "0",
+		"string_value": rpm.StringValue,
+		"data_value":   rpm.DataValue,
+		"from":         strconv.FormatFloat(rpm.From, 'f', -1, 64),
+		"to":           strconv.FormatFloat(rpm.To, 'f', -1, 64),
+	}
+	if rpm.BoolValue {
+		params["bool_value"] = "1"
+	}
+
+	// LIMIT BY keeps the first row of each pair, which is the latest one
+	// since the rows of the pair are sorted by time.
+	q := fmt.Sprintf(`SELECT * FROM %s WHERE %s ORDER BY publisher, name, time DESC
+    LIMIT 1 BY publisher, name
+    LIMIT {limit:UInt64} OFFSET {offset:UInt64}`, defTable, condition)
+
+	var msgs []message
+	if err := cr.client.Query(q, params, &msgs); err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	page := readers.MessagesPage{
+		PageMetadata: rpm,
+		Messages:     []readers.Message{},
+	}
+	for _, m := range msgs {
+		page.Messages = append(page.Messages, m.toSenml())
+	}
+
+	q = fmt.Sprintf(`SELECT uniqExact(publisher, name) AS total FROM %s WHERE %s`, defTable, condition)
+	var total []struct {
+		Total uint64 `json:"total"`
+	}
+	if err := cr.client.Query(q, params, &total); err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	if len(total) > 0 {
+		page.Total = total[0].Total
+	}
+
+	return page, nil
+}
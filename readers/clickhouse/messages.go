@@ -0,0 +1,171 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/mainflux/mainflux/pkg/clickhouse"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+)
+
+// Table for SenML messages
+const defTable = "messages"
+
+var errReadMessages = errors.New("failed to read messages from clickhouse database")
+
+var _ readers.MessageRepository = (*clickhouseRepository)(nil)
+
+type clickhouseRepository struct {
+	client clickhouse.Client
+}
+
+// New returns new ClickHouse reader.
+func New(client clickhouse.Client) readers.MessageRepository {
+	return &clickhouseRepository{
+		client: client,
+	}
+}
+
+func (cr clickhouseRepository) ReadAll(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	page := readers.MessagesPage{
+		PageMetadata: rpm,
+		Messages:     []readers.Message{},
+	}
+	// Only SenML messages are stored by ClickHouse writer.
+	if rpm.Format != "" && rpm.Format != defTable {
+		return page, nil
+	}
+
+	condition := fmtCondition(rpm)
+	params := map[string]string{
+		"channel":      chanID,
+		"limit":        strconv.FormatUint(rpm.Limit, 10),
+		"offset":       strconv.FormatUint(rpm.Offset, 10),
+		"subtopic":     rpm.Subtopic,
+		"publisher":    rpm.Publisher,
+		"name":         rpm.Name,
+		"protocol":     rpm.Protocol,
+		"value":        strconv.FormatFloat(rpm.Value, 'f', -1, 64),
+		"bool_value":   "0",
+		"string_value": rpm.StringValue,
+		"data_value":   rpm.DataValue,
+		"from":         strconv.FormatFloat(rpm.From, 'f', -1, 64),
+		"to":           strconv.FormatFloat(rpm.To, 'f', -1, 64),
+	}
+	if rpm.BoolValue {
+		params["bool_value"] = "1"
+	}
+
+	q := fmt.Sprintf(`SELECT * FROM %s WHERE %s ORDER BY time %s
+    LIMIT {limit:UInt64} OFFSET {offset:UInt64}`, defTable, condition, fmtDir(rpm))
+
+	var msgs []message
+	if err := cr.client.Query(q, params, &msgs); err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	for _, m := range msgs {
+		page.Messages = append(page.Messages, m.toSenml())
+	}
+
+	q = fmt.Sprintf(`SELECT count() AS total FROM %s WHERE %s`, defTable, condition)
+	var total []struct {
+		Total uint64 `json:"total"`
+	}
+	if err := cr.client.Query(q, params, &total); err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	if len(total) > 0 {
+		page.Total = total[0].Total
+	}
+
+	return page, nil
+}
+
+func fmtCondition(rpm readers.PageMetadata) string {
+	condition := `channel = {channel:String}`
+
+	var query map[string]interface{}
+	meta, err := json.Marshal(rpm)
+	if err != nil {
+		return condition
+	}
+	json.Unmarshal(meta, &query)
+
+	for name := range query {
+		switch name {
+		case
+			"subtopic",
+			"publisher",
+			"name",
+			"protocol":
+			condition = fmt.Sprintf(`%s AND %s = {%s:String}`, condition, name, name)
+		case "v":
+			comparator := readers.ParseValueComparator(query)
+			condition = fmt.Sprintf(`%s AND value %s {value:Float64}`, condition, comparator)
+		case "vb":
+			condition = fmt.Sprintf(`%s AND bool_value = {bool_value:UInt8}`, condition)
+		case "vs":
+			condition = fmt.Sprintf(`%s AND string_value = {string_value:String}`, condition)
+		case "vd":
+			condition = fmt.Sprintf(`%s AND data_value = {data_value:String}`, condition)
+		case "from":
+			condition = fmt.Sprintf(`%s AND time >= {from:Float64}`, condition)
+		case "to":
+			condition = fmt.Sprintf(`%s AND time < {to:Float64}`, condition)
+		}
+	}
+	return condition
+}
+
+// fmtDir returns the time order direction of the page metadata.
+func fmtDir(rpm readers.PageMetadata) string {
+	if rpm.Dir == readers.AscDir {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+type message struct {
+	Channel     string   `json:"channel"`
+	Subtopic    string   `json:"subtopic"`
+	Publisher   string   `json:"publisher"`
+	Protocol    string   `json:"protocol"`
+	Name        string   `json:"name"`
+	Unit        string   `json:"unit"`
+	Value       *float64 `json:"value"`
+	StringValue *string  `json:"string_value"`
+	BoolValue   *uint8   `json:"bool_value"`
+	DataValue   *string  `json:"data_value"`
+	Sum         *float64 `json:"sum"`
+	Time        float64  `json:"time"`
+	UpdateTime  float64  `json:"update_time"`
+}
+
+func (m message) toSenml() senml.Message {
+	msg := senml.Message{
+		Channel:     m.Channel,
+		Subtopic:    m.Subtopic,
+		Publisher:   m.Publisher,
+		Protocol:    m.Protocol,
+		Name:        m.Name,
+		Unit:        m.Unit,
+		Value:       m.Value,
+		StringValue: m.StringValue,
+		DataValue:   m.DataValue,
+		Sum:         m.Sum,
+		Time:        m.Time,
+		UpdateTime:  m.UpdateTime,
+	}
+	if m.BoolValue != nil {
+		b := *m.BoolValue == 1
+		msg.BoolValue = &b
+	}
+
+	return msg
+}
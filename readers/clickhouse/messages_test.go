@@ -0,0 +1,678 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	cwriter "github.com/mainflux/mainflux/consumers/writers/clickhouse"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/pkg/uuid"
+	"github.com/mainflux/mainflux/readers"
+	creader "github.com/mainflux/mainflux/readers/clickhouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	subtopic    = "subtopic"
+	msgsNum     = 100
+	limit       = 10
+	valueFields = 5
+	mqttProt    = "mqtt"
+	httpProt    = "http"
+	msgName     = "temperature"
+)
+
+var (
+	v   float64 = 5
+	vs          = "value"
+	vb          = true
+	vd          = "dataValue"
+	sum float64 = 42
+
+	idProvider = uuid.New()
+)
+
+func TestReadSenml(t *testing.T) {
+	writer := cwriter.New(client)
+
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	wrongID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	m := senml.Message{
+		Channel:   chanID,
+		Publisher: pubID,
+		Protocol:  mqttProt,
+	}
+
+	messages := []senml.Message{}
+	valueMsgs := []senml.Message{}
+	boolMsgs := []senml.Message{}
+	stringMsgs := []senml.Message{}
+	dataMsgs := []senml.Message{}
+	queryMsgs := []senml.Message{}
+
+	now := float64(time.Now().Unix())
+	for i := 0; i < msgsNum; i++ {
+		// Mix possible values as well as value sum.
+		msg := m
+		msg.Time = now - float64(i)
+
+		count := i % valueFields
+		switch count {
+		case 0:
+			msg.Value = &v
+			valueMsgs = append(valueMsgs, msg)
+		case 1:
+			msg.BoolValue = &vb
+			boolMsgs = append(boolMsgs, msg)
+		case 2:
+			msg.StringValue = &vs
+			stringMsgs = append(stringMsgs, msg)
+		case 3:
+			msg.DataValue = &vd
+			dataMsgs = append(dataMsgs, msg)
+		case 4:
+			msg.Sum = &sum
+			msg.Subtopic = subtopic
+			msg.Protocol = httpProt
+			msg.Publisher = pubID2
+			msg.Name = msgName
+			queryMsgs = append(queryMsgs, msg)
+		}
+
+		messages = append(messages, msg)
+	}
+
+	err = writer.Consume(messages)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := creader.New(client)
+
+	// Since messages are not saved in natural order,
+	// cases that return subset of messages are only
+	// checking data result set size, but not content.
+	cases := map[string]struct {
+		chanID   string
+		pageMeta readers.PageMetadata
+		page     readers.MessagesPage
+	}{
+		"read message page for existing channel": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset: 0,
+				Limit:  msgsNum,
+			},
+			page: readers.MessagesPage{
+				Total:    msgsNum,
+				Messages: fromSenml(messages),
+			},
+		},
+		"read message page for non-existent channel": {
+			chanID: wrongID,
+			pageMeta: readers.PageMetadata{
+				Offset: 0,
+				Limit:  msgsNum,
+			},
+			page: readers.MessagesPage{
+				Messages: []readers.Message{},
+			},
+		},
+		"read message last page": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset: msgsNum - 20,
+				Limit:  msgsNum,
+			},
+			page: readers.MessagesPage{
+				Total:    msgsNum,
+				Messages: fromSenml(messages[msgsNum-20 : msgsNum]),
+			},
+		},
+		"read message first page in ascending order": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset: 0,
+				Limit:  20,
+				Dir:    readers.AscDir,
+			},
+			page: readers.MessagesPage{
+				Total:    msgsNum,
+				Messages: fromSenml(messages[msgsNum-20 : msgsNum]),
+			},
+		},
+		"read message with non-existent subtopic": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:   0,
+				Limit:    msgsNum,
+				Subtopic: "not-present",
+			},
+			page: readers.MessagesPage{
+				Messages: []readers.Message{},
+			},
+		},
+		"read message with subtopic": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:   0,
+				Limit:    uint64(len(queryMsgs)),
+				Subtopic: subtopic,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(queryMsgs)),
+				Messages: fromSenml(queryMsgs),
+			},
+		},
+		"read message with publisher": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:    0,
+				Limit:     uint64(len(queryMsgs)),
+				Publisher: pubID2,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(queryMsgs)),
+				Messages: fromSenml(queryMsgs),
+			},
+		},
+		"read message with wrong format": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Format:    "messagess",
+				Offset:    0,
+				Limit:     uint64(len(queryMsgs)),
+				Publisher: pubID2,
+			},
+			page: readers.MessagesPage{
+				Total:    0,
+				Messages: []readers.Message{},
+			},
+		},
+		"read message with protocol": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:   0,
+				Limit:    uint64(len(queryMsgs)),
+				Protocol: httpProt,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(queryMsgs)),
+				Messages: fromSenml(queryMsgs),
+			},
+		},
+		"read message with name": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset: 0,
+				Limit:  limit,
+				Name:   msgName,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(queryMsgs)),
+				Messages: fromSenml(queryMsgs[0:limit]),
+			},
+		},
+		"read message with value": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset: 0,
+				Limit:  limit,
+				Value:  v,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(valueMsgs)),
+				Messages: fromSenml(valueMsgs[0:limit]),
+			},
+		},
+		"read message with value and equal comparator": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:     0,
+				Limit:      limit,
+				Value:      v,
+				Comparator: readers.EqualKey,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(valueMsgs)),
+				Messages: fromSenml(valueMsgs[0:limit]),
+			},
+		},
+		"read message with value and lower-than comparator": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:     0,
+				Limit:      limit,
+				Value:      v + 1,
+				Comparator: readers.LowerThanKey,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(valueMsgs)),
+				Messages: fromSenml(valueMsgs[0:limit]),
+			},
+		},
+		"read message with value and lower-than-or-equal comparator": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:     0,
+				Limit:      limit,
+				Value:      v + 1,
+				Comparator: readers.LowerThanEqualKey,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(valueMsgs)),
+				Messages: fromSenml(valueMsgs[0:limit]),
+			},
+		},
+		"read message with value and greater-than comparator": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:     0,
+				Limit:      limit,
+				Value:      v - 1,
+				Comparator: readers.GreaterThanKey,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(valueMsgs)),
+				Messages: fromSenml(valueMsgs[0:limit]),
+			},
+		},
+		"read message with value and greater-than-or-equal comparator": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:     0,
+				Limit:      limit,
+				Value:      v - 1,
+				Comparator: readers.GreaterThanEqualKey,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(valueMsgs)),
+				Messages: fromSenml(valueMsgs[0:limit]),
+			},
+		},
+		"read message with boolean value": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:    0,
+				Limit:     limit,
+				BoolValue: vb,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(boolMsgs)),
+				Messages: fromSenml(boolMsgs[0:limit]),
+			},
+		},
+		"read message with string value": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:      0,
+				Limit:       limit,
+				StringValue: vs,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(stringMsgs)),
+				Messages: fromSenml(stringMsgs[0:limit]),
+			},
+		},
+		"read message with data value": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset:    0,
+				Limit:     limit,
+				DataValue: vd,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(dataMsgs)),
+				Messages: fromSenml(dataMsgs[0:limit]),
+			},
+		},
+		"read message with from": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset: 0,
+				Limit:  uint64(len(messages[0:21])),
+				From:   messages[20].Time,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(messages[0:21])),
+				Messages: fromSenml(messages[0:21]),
+			},
+		},
+		"read message with to": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset: 0,
+				Limit:  uint64(len(messages[21:])),
+				To:     messages[20].Time,
+			},
+			page: readers.MessagesPage{
+				Total:    uint64(len(messages[21:])),
+				Messages: fromSenml(messages[21:]),
+			},
+		},
+		"read message with from/to": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset: 0,
+				Limit:  limit,
+				From:   messages[5].Time,
+				To:     messages[0].Time,
+			},
+			page: readers.MessagesPage{
+				Total:    5,
+				Messages: fromSenml(messages[1:6]),
+			},
+		},
+	}
+
+	for desc, tc := range cases {
+		result, err := reader.ReadAll(tc.chanID, tc.pageMeta)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", desc, err))
+		assert.ElementsMatch(t, tc.page.Messages, result.Messages, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Messages, result.Messages))
+		assert.Equal(t, tc.page.Total, result.Total, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Total, result.Total))
+	}
+}
+func fromSenml(msg []senml.Message) []readers.Message {
+	var ret []readers.Message
+	for _, m := range msg {
+		ret = append(ret, m)
+	}
+	return ret
+}
+
+func TestAggregate(t *testing.T) {
+	writer := cwriter.New(client)
+
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages span two hours, with values from 1 to 4 in each of them.
+	hour := float64(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC).Unix())
+	var msgs []senml.Message
+	for i := 0; i < 8; i++ {
+		val := float64(i%4 + 1)
+		msgs = append(msgs, senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      hour + float64(i*900),
+			Value:     &val,
+		})
+	}
+	err = writer.Consume(msgs)
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := creader.New(client)
+	aggregate := func(start, v float64) readers.Aggregate {
+		return readers.Aggregate{Time: start, Name: msgName, Publisher: pubID, Value: v}
+	}
+
+	cases := map[string]struct {
+		pageMeta readers.PageMetadata
+		page     readers.AggregatesPage
+	}{
+		"aggregate average by hour": {
+			pageMeta: readers.PageMetadata{Limit: limit, Aggregation: readers.AvgAggregation, Interval: "1h"},
+			page: readers.AggregatesPage{
+				Total:      2,
+				Aggregates: []readers.Aggregate{aggregate(hour+3600, 2.5), aggregate(hour, 2.5)},
+			},
+		},
+		"aggregate maximum by two hours": {
+			pageMeta: readers.PageMetadata{Limit: limit, Aggregation: readers.MaxAggregation, Interval: "2h"},
+			page: readers.AggregatesPage{
+				Total:      1,
+				Aggregates: []readers.Aggregate{aggregate(hour, 4)},
+			},
+		},
+		"aggregate count with time range and offset": {
+			pageMeta: readers.PageMetadata{Offset: 1, Limit: limit, Aggregation: readers.CountAggregation, Interval: "1h", From: hour + 1800},
+			page: readers.AggregatesPage{
+				Total:      2,
+				Aggregates: []readers.Aggregate{aggregate(hour, 2)},
+			},
+		},
+	}
+
+	for desc, tc := range cases {
+		result, err := reader.Aggregate(chanID, tc.pageMeta)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", desc, err))
+		assert.Equal(t, tc.page.Total, result.Total, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Total, result.Total))
+		assert.Equal(t, tc.page.Aggregates, result.Aggregates, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Aggregates, result.Aggregates))
+	}
+}
+
+func TestSummary(t *testing.T) {
+	writer := cwriter.New(client)
+
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages are published every minute, alternately by two publishers.
+	start := float64(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC).Unix())
+	var msgs []senml.Message
+	for i := 0; i < 10; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      start + float64(i*60),
+			Value:     &v,
+		}
+		if i%2 == 1 {
+			msg.Publisher = pubID2
+			msg.Protocol = httpProt
+		}
+		msgs = append(msgs, msg)
+	}
+	err = writer.Consume(msgs)
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := creader.New(client)
+	publishers := []string{pubID, pubID2}
+	sort.Strings(publishers)
+
+	cases := map[string]struct {
+		pageMeta readers.PageMetadata
+		summary  readers.Summary
+	}{
+		"summarize all messages": {
+			pageMeta: readers.PageMetadata{Limit: limit},
+			summary:  readers.Summary{Count: 10, First: start, Last: start + 540, Publishers: publishers},
+		},
+		"summarize messages by publisher": {
+			pageMeta: readers.PageMetadata{Limit: limit, Publisher: pubID2},
+			summary:  readers.Summary{Count: 5, First: start + 60, Last: start + 540, Publishers: []string{pubID2}},
+		},
+		"summarize messages by protocol with time range": {
+			pageMeta: readers.PageMetadata{Limit: limit, Protocol: mqttProt, From: start + 60, To: start + 300},
+			summary:  readers.Summary{Count: 2, First: start + 120, Last: start + 240, Publishers: []string{pubID}},
+		},
+		"summarize messages without matches": {
+			pageMeta: readers.PageMetadata{Limit: limit, Name: "wrong"},
+			summary:  readers.Summary{Publishers: []string{}},
+		},
+	}
+
+	for desc, tc := range cases {
+		summary, err := reader.Summarize(chanID, tc.pageMeta)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", desc, err))
+		assert.Equal(t, tc.summary, summary, fmt.Sprintf("%s: expected %v got %v", desc, tc.summary, summary))
+	}
+}
+
+func TestReadLatest(t *testing.T) {
+	writer := cwriter.New(client)
+
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages are published every minute, alternately by two publishers,
+	// and every other pair of them is named differently.
+	start := float64(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC).Unix())
+	var msgs []senml.Message
+	for i := 0; i < 10; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      start + float64(i*60),
+			Value:     &v,
+		}
+		if i%2 == 1 {
+			msg.Publisher = pubID2
+		}
+		if i%4 > 1 {
+			msg.Name = "humidity"
+		}
+		msgs = append(msgs, msg)
+	}
+	err = writer.Consume(msgs)
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := creader.New(client)
+
+	// Latest messages are sorted by publisher and name.
+	latest := func(msgs ...senml.Message) []readers.Message {
+		sort.Slice(msgs, func(i, j int) bool {
+			if msgs[i].Publisher != msgs[j].Publisher {
+				return msgs[i].Publisher < msgs[j].Publisher
+			}
+			return msgs[i].Name < msgs[j].Name
+		})
+		return fromSenml(msgs)
+	}
+
+	cases := map[string]struct {
+		pageMeta readers.PageMetadata
+		page     readers.MessagesPage
+	}{
+		"read latest messages": {
+			pageMeta: readers.PageMetadata{Limit: limit},
+			page:     readers.MessagesPage{Total: 4, Messages: latest(msgs[6], msgs[7], msgs[8], msgs[9])},
+		},
+		"read latest messages page": {
+			pageMeta: readers.PageMetadata{Offset: 1, Limit: 2},
+			page:     readers.MessagesPage{Total: 4, Messages: latest(msgs[6], msgs[7], msgs[8], msgs[9])[1:3]},
+		},
+		"read latest messages by name": {
+			pageMeta: readers.PageMetadata{Limit: limit, Name: "humidity"},
+			page:     readers.MessagesPage{Total: 2, Messages: latest(msgs[6], msgs[7])},
+		},
+		"read latest messages with time range": {
+			pageMeta: readers.PageMetadata{Limit: limit, To: start + 360},
+			page:     readers.MessagesPage{Total: 4, Messages: latest(msgs[2], msgs[3], msgs[4], msgs[5])},
+		},
+		"read latest messages without matches": {
+			pageMeta: readers.PageMetadata{Limit: limit, Name: "wrong"},
+			page:     readers.MessagesPage{Messages: []readers.Message{}},
+		},
+	}
+
+	for desc, tc := range cases {
+		page, err := reader.ReadLatest(chanID, tc.pageMeta)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", desc, err))
+		assert.Equal(t, tc.page.Total, page.Total, fmt.Sprintf("%s: expected %d got %d", desc, tc.page.Total, page.Total))
+		assert.Equal(t, tc.page.Messages, page.Messages, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Messages, page.Messages))
+	}
+}
+
+func TestDelete(t *testing.T) {
+	writer := cwriter.New(client)
+
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages are published every minute, alternately by two publishers.
+	start := float64(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC).Unix())
+	var msgs []senml.Message
+	for i := 0; i < 10; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      start + float64(i*60),
+			Value:     &v,
+		}
+		if i%2 == 1 {
+			msg.Publisher = pubID2
+		}
+		msgs = append(msgs, msg)
+	}
+	err = writer.Consume(msgs)
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := creader.New(client)
+
+	// Cases are ordered, since each of them deletes the messages.
+	cases := []struct {
+		desc     string
+		pageMeta readers.PageMetadata
+		count    uint64
+		total    uint64
+	}{
+		{
+			desc:     "delete messages of publisher within time range",
+			pageMeta: readers.PageMetadata{Publisher: pubID2, From: start, To: start + 300},
+			count:    2,
+			total:    8,
+		},
+		{
+			desc:     "delete messages of publisher",
+			pageMeta: readers.PageMetadata{Publisher: pubID2},
+			count:    3,
+			total:    5,
+		},
+		{
+			desc:     "delete all messages",
+			pageMeta: readers.PageMetadata{},
+			count:    5,
+			total:    0,
+		},
+		{
+			desc:     "delete messages of empty channel",
+			pageMeta: readers.PageMetadata{},
+			count:    0,
+			total:    0,
+		},
+	}
+
+	for _, tc := range cases {
+		count, err := reader.Delete(chanID, tc.pageMeta)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", tc.desc, err))
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.count, count))
+
+		page, err := reader.ReadAll(chanID, readers.PageMetadata{Limit: limit})
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", tc.desc, err))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected %d remaining messages got %d", tc.desc, tc.total, page.Total))
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+type summary struct {
+	Total      uint64   `json:"total"`
+	First      float64  `json:"first"`
+	Last       float64  `json:"last"`
+	Publishers []string `json:"publishers"`
+}
+
+func (cr clickhouseRepository) Summarize(chanID string, rpm readers.PageMetadata) (readers.Summary, error) {
+	ret := readers.Summary{Publishers: []string{}}
+	// Only SenML messages are stored by ClickHouse writer.
+	if rpm.Format != "" && rpm.Format != defTable {
+		return ret, nil
+	}
+
+	params := map[string]string{
+		"channel":      chanID,
+		"subtopic":     rpm.Subtopic,
+		"publisher":    rpm.Publisher,
+		"name":         rpm.Name,
+		"protocol":     rpm.Protocol,
+		"value":        strconv.FormatFloat(rpm.Value, 'f', -1, 64),
+		"bool_value":   "0",
+		"string_value": rpm.StringValue,
+		"data_value":   rpm.DataValue,
+		"from":         strconv.FormatFloat(rpm.From, 'f', -1, 64),
+		"to":           strconv.FormatFloat(rpm.To, 'f', -1, 64),
+	}
+	if rpm.BoolValue {
+		params["bool_value"] = "1"
+	}
+
+	// Aggregates of no messages are zero. Distinct publishers are sorted,
+	// since the order of groupUniqArray is not defined.
+	q := fmt.Sprintf(`SELECT count() AS total, min(time) AS first, max(time) AS last,
+    arraySort(groupUniqArray(publisher)) AS publishers
+    FROM %s WHERE %s`, defTable, fmtCondition(rpm))
+	var rows []summary
+	if err := cr.client.Query(q, params, &rows); err != nil {
+		return readers.Summary{}, errors.Wrap(errReadMessages, err)
+	}
+	if len(rows) > 0 {
+		ret.Count, ret.First, ret.Last = rows[0].Total, rows[0].First, rows[0].Last
+		ret.Publishers = append(ret.Publishers, rows[0].Publishers...)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,204 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	influxdata "github.com/influxdata/influxdb/client/v2"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+// aggregateFields contains the fields aggregated by the aggregation. Protocol
+// field is present in all the messages, so it's used to count them.
+var aggregateFields = map[string]string{
+	readers.AvgAggregation:   "value",
+	readers.MinAggregation:   "value",
+	readers.MaxAggregation:   "value",
+	readers.CountAggregation: "protocol",
+}
+
+var influxQLFuncs = map[string]string{
+	readers.AvgAggregation:   "MEAN",
+	readers.MinAggregation:   "MIN",
+	readers.MaxAggregation:   "MAX",
+	readers.CountAggregation: "COUNT",
+}
+
+var fluxFuncs = map[string]string{
+	readers.AvgAggregation:   "mean",
+	readers.MinAggregation:   "min",
+	readers.MaxAggregation:   "max",
+	readers.CountAggregation: "count",
+}
+
+func (repo *influxRepository) Aggregate(chanID string, rpm readers.PageMetadata) (readers.AggregatesPage, error) {
+	interval, err := readers.ParseInterval(rpm)
+	if err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	fn, ok := influxQLFuncs[rpm.Aggregation]
+	if !ok {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, readers.ErrInvalidAggregation)
+	}
+
+	selection := fmt.Sprintf(`%s("%s")`, fn, aggregateFields[rpm.Aggregation])
+	measurement := defMeasurement
+	if r, ok := selectRollup(repo.rollups, interval, rpm); ok {
+		selection = influxQLRollupFuncs[rpm.Aggregation]
+		measurement = fmt.Sprintf(`"%s".%s`, r.Target, defMeasurement)
+	}
+
+	cmd := fmt.Sprintf(`SELECT %s FROM %s WHERE %s GROUP BY time(%dms), "name", "publisher" fill(none)`,
+		selection, measurement, fmtCondition(chanID, rpm), interval.Milliseconds())
+	q := influxdata.Query{
+		Command:  cmd,
+		Database: repo.database,
+	}
+	resp, err := repo.client.Query(q)
+	if err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	if resp.Error() != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, resp.Error())
+	}
+
+	// Each series contains the aggregated values of a single name and
+	// publisher, as time and value columns.
+	var aggs []readers.Aggregate
+	for _, res := range resp.Results {
+		for _, series := range res.Series {
+			for _, row := range series.Values {
+				// Average of the rollup is null if there are no values
+				// within the interval.
+				if len(row) < 2 || row[1] == nil {
+					continue
+				}
+				agg, err := parseAggregate(row[0], row[1])
+				if err != nil {
+					return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+				}
+				agg.Name, agg.Publisher = series.Tags["name"], series.Tags["publisher"]
+				aggs = append(aggs, agg)
+			}
+		}
+	}
+
+	return pageAggregates(aggs, rpm), nil
+}
+
+func (repo *fluxRepository) Aggregate(chanID string, rpm readers.PageMetadata) (readers.AggregatesPage, error) {
+	interval, err := readers.ParseInterval(rpm)
+	if err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	fn, ok := fluxFuncs[rpm.Aggregation]
+	if !ok {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, readers.ErrInvalidAggregation)
+	}
+
+	field := aggregateFields[rpm.Aggregation]
+	query := fmt.Sprintf(`%s
+  |> filter(fn: (r) => exists r.%s)
+  |> map(fn: (r) => ({r with _value: r.%s}))
+  |> group(columns: ["name", "publisher"])
+  |> aggregateWindow(every: %dms, fn: %s, createEmpty: false, timeSrc: "_start")
+  |> group()`, fmtFlux(repo.bucket, defMeasurement, chanID, rpm), field, field, interval.Milliseconds(), fn)
+	if r, ok := selectRollup(repo.rollups, interval, rpm); ok {
+		query = fmt.Sprintf(`%s
+  |> group(columns: ["name", "publisher"])
+  |> window(every: %dms)
+  |> %s
+  |> map(fn: (r) => ({_time: r._start, name: r.name, publisher: r.publisher, _value: r._value}))
+  |> group()`, fmtFlux(r.Target, defMeasurement, chanID, rpm), interval.Milliseconds(), fluxRollupFuncs[rpm.Aggregation])
+	}
+
+	rows, err := repo.client.Query(query)
+	if err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+
+	var aggs []readers.Aggregate
+	for _, row := range rows {
+		names, fields := fluxFields(row)
+		var t, v interface{}
+		agg := readers.Aggregate{}
+		for i, name := range names {
+			switch name {
+			case "time":
+				t = fields[i]
+			case "_value":
+				v = fields[i]
+			case "name":
+				agg.Name = fmt.Sprint(fields[i])
+			case "publisher":
+				agg.Publisher = fmt.Sprint(fields[i])
+			}
+		}
+		parsed, err := parseAggregate(t, v)
+		if err != nil {
+			return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+		}
+		agg.Time, agg.Value = parsed.Time, parsed.Value
+		aggs = append(aggs, agg)
+	}
+
+	return pageAggregates(aggs, rpm), nil
+}
+
+// parseAggregate parses the interval start time and the aggregated value
+// in the form of InfluxDB 1.x query result.
+func parseAggregate(t, v interface{}) (readers.Aggregate, error) {
+	ts, ok := t.(string)
+	if !ok {
+		return readers.Aggregate{}, readers.ErrInvalidAggregation
+	}
+	start, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return readers.Aggregate{}, err
+	}
+	num, ok := v.(json.Number)
+	if !ok {
+		return readers.Aggregate{}, readers.ErrInvalidAggregation
+	}
+	value, err := num.Float64()
+	if err != nil {
+		return readers.Aggregate{}, err
+	}
+
+	return readers.Aggregate{
+		Time:  float64(start.UnixNano()) / 1e9,
+		Value: value,
+	}, nil
+}
+
+// pageAggregates returns the page of the aggregated values. InfluxDB limits
+// the values of each series separately, so the values are sorted by time and
+// paged once all the series are read.
+func pageAggregates(aggs []readers.Aggregate, rpm readers.PageMetadata) readers.AggregatesPage {
+	sort.Slice(aggs, func(i, j int) bool {
+		if aggs[i].Time != aggs[j].Time {
+			return (aggs[i].Time > aggs[j].Time) != (rpm.Dir == readers.AscDir)
+		}
+		if aggs[i].Name != aggs[j].Name {
+			return aggs[i].Name < aggs[j].Name
+		}
+		return aggs[i].Publisher < aggs[j].Publisher
+	})
+
+	page := readers.AggregatesPage{
+		PageMetadata: rpm,
+		Total:        uint64(len(aggs)),
+		Aggregates:   []readers.Aggregate{},
+	}
+	for i := rpm.Offset; i < uint64(len(aggs)) && i < rpm.Offset+rpm.Limit; i++ {
+		page.Aggregates = append(page.Aggregates, aggs[i])
+	}
+
+	return page
+}
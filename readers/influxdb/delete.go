@@ -0,0 +1,120 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	influxdata "github.com/influxdata/influxdb/client/v2"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+var errDeleteMessages = errors.New("failed to delete messages from influxdb database")
+
+// Delete counts the matching points before deleting them, since InfluxQL
+// DELETE doesn't return the number of the deleted points. Points are deleted
+// from all the retention policies, including the rollups.
+func (repo *influxRepository) Delete(chanID string, rpm readers.PageMetadata) (uint64, error) {
+	measurement := defMeasurement
+	if rpm.Format != "" {
+		measurement = rpm.Format
+	}
+
+	// Only the publisher and the time range are used to select the messages,
+	// since InfluxQL DELETE supports only the tags and the time conditions.
+	pm := readers.PageMetadata{
+		Publisher: rpm.Publisher,
+		From:      rpm.From,
+		To:        rpm.To,
+	}
+	condition := fmtCondition(chanID, pm)
+
+	count, err := repo.count(measurement, condition)
+	if err != nil {
+		return 0, errors.Wrap(errDeleteMessages, err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	q := influxdata.Query{
+		Command:  fmt.Sprintf(`DELETE FROM %s WHERE %s`, quoteIdentifier(measurement), condition),
+		Database: repo.database,
+	}
+	resp, err := repo.client.Query(q)
+	if err != nil {
+		return 0, errors.Wrap(errDeleteMessages, err)
+	}
+	if resp.Error() != nil {
+		return 0, errors.Wrap(errDeleteMessages, resp.Error())
+	}
+
+	return count, nil
+}
+
+// Delete counts the matching points of the bucket before deleting them, and
+// deletes the points within the time range from the rollup buckets as well.
+func (repo *fluxRepository) Delete(chanID string, rpm readers.PageMetadata) (uint64, error) {
+	measurement := defMeasurement
+	if rpm.Format != "" {
+		measurement = rpm.Format
+	}
+
+	// Only the publisher and the time range are used to select the messages,
+	// since the delete predicate supports only the tags.
+	pm := readers.PageMetadata{
+		Publisher: rpm.Publisher,
+		From:      rpm.From,
+		To:        rpm.To,
+	}
+	count, err := repo.count(fmtFlux(repo.bucket, measurement, chanID, pm))
+	if err != nil {
+		return 0, errors.Wrap(errDeleteMessages, err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	// Delete time range includes the stop time, unlike the one of the query.
+	start, stop := time.Unix(0, 0), time.Now()
+	if pm.From != 0 {
+		start = time.Unix(0, int64(pm.From*1e9))
+	}
+	if pm.To != 0 {
+		stop = time.Unix(0, int64(pm.To*1e9)-1)
+	}
+
+	pred := []string{
+		fmt.Sprintf(`_measurement=%s`, fluxString(measurement)),
+		fmt.Sprintf(`channel=%s`, fluxString(chanID)),
+	}
+	if pm.Publisher != "" {
+		pred = append(pred, fmt.Sprintf(`publisher=%s`, fluxString(pm.Publisher)))
+	}
+	predicate := strings.Join(pred, " AND ")
+
+	buckets := []string{repo.bucket}
+	if measurement == defMeasurement {
+		for _, r := range repo.rollups {
+			buckets = append(buckets, r.Target)
+		}
+	}
+	for _, bucket := range buckets {
+		if err := repo.client.Delete(bucket, start, stop, predicate); err != nil {
+			return 0, errors.Wrap(errDeleteMessages, err)
+		}
+	}
+
+	return count, nil
+}
+
+// quoteIdentifier quotes the InfluxQL identifier, escaping the quotes and the
+// backslashes, so that it can't change the query.
+func quoteIdentifier(name string) string {
+	name = strings.ReplaceAll(name, `\`, `\\`)
+	return `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+}
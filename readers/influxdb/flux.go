@@ -0,0 +1,208 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/influxdb2"
+	"github.com/mainflux/mainflux/readers"
+)
+
+// Columns of the Flux result tables which are not a part of the message.
+var fluxColumns = map[string]bool{
+	"result":       true,
+	"table":        true,
+	"_start":       true,
+	"_stop":        true,
+	"_measurement": true,
+}
+
+var _ readers.MessageRepository = (*fluxRepository)(nil)
+
+type fluxRepository struct {
+	bucket  string
+	client  influxdb2.Client
+	rollups []Rollup
+}
+
+// NewV2 returns new InfluxDB 2.x reader, which reads the messages from the
+// bucket using Flux queries. Aggregated values are read from the rollup
+// buckets, if the requested interval and the time range allow it.
+func NewV2(client influxdb2.Client, bucket string, rollups []Rollup) readers.MessageRepository {
+	return &fluxRepository{
+		bucket:  bucket,
+		client:  client,
+		rollups: rollups,
+	}
+}
+
+func (repo *fluxRepository) ReadAll(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	format := defMeasurement
+	if rpm.Format != "" {
+		format = rpm.Format
+	}
+
+	query := fmtFlux(repo.bucket, format, chanID, rpm)
+	rows, err := repo.client.Query(fmt.Sprintf(`%s
+  |> sort(columns: ["_time"], desc: %t)
+  |> limit(n: %d, offset: %d)`, query, rpm.Dir != readers.AscDir, rpm.Limit, rpm.Offset))
+	if err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+
+	var ret []readers.Message
+	for _, row := range rows {
+		names, fields := fluxFields(row)
+		msg, err := parseMessage(format, names, fields)
+		if err != nil {
+			return readers.MessagesPage{}, err
+		}
+		ret = append(ret, msg)
+	}
+
+	total, err := repo.count(query)
+	if err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+
+	page := readers.MessagesPage{
+		PageMetadata: rpm,
+		Total:        total,
+		Messages:     ret,
+	}
+
+	return page, nil
+}
+
+func (repo *fluxRepository) count(query string) (uint64, error) {
+	// Protocol field is present in all the messages.
+	rows, err := repo.client.Query(query + `
+  |> count(column: "protocol")`)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) < 1 {
+		return 0, nil
+	}
+
+	count, ok := rows[0]["protocol"].(int64)
+	if !ok {
+		return 0, nil
+	}
+	return uint64(count), nil
+}
+
+// fmtFlux returns the Flux query which reads the messages of the channel
+// matching the page metadata as a single table. The message fields are
+// pivoted into the columns, so that each row contains a single message.
+func fmtFlux(bucket, measurement, chanID string, rpm readers.PageMetadata) string {
+	start, stop := "0", "now()"
+	if rpm.From != 0 {
+		start = fmt.Sprintf("time(v: %d)", int64(rpm.From*1e9))
+	}
+	if rpm.To != 0 {
+		stop = fmt.Sprintf("time(v: %d)", int64(rpm.To*1e9))
+	}
+
+	// Tags are filtered before the pivot, since it's much cheaper.
+	tags := []string{
+		fmt.Sprintf(`r._measurement == %s`, fluxString(measurement)),
+		fmt.Sprintf(`r.channel == %s`, fluxString(chanID)),
+	}
+	var fields []string
+
+	var query map[string]interface{}
+	meta, err := json.Marshal(rpm)
+	if err == nil {
+		json.Unmarshal(meta, &query)
+	}
+	for name, value := range query {
+		switch name {
+		case
+			"subtopic",
+			"publisher",
+			"name":
+			tags = append(tags, fmt.Sprintf(`r.%s == %s`, name, fluxString(fmt.Sprint(value))))
+		case "protocol":
+			fields = append(fields, fmt.Sprintf(`r.protocol == %s`, fluxString(fmt.Sprint(value))))
+		case "v":
+			comparator := readers.ParseValueComparator(query)
+			if comparator == "=" {
+				comparator = "=="
+			}
+			fields = append(fields, fmt.Sprintf(`r.value %s %f`, comparator, value))
+		case "vb":
+			fields = append(fields, fmt.Sprintf(`r.boolValue == %t`, value))
+		case "vs":
+			fields = append(fields, fmt.Sprintf(`r.stringValue == %s`, fluxString(fmt.Sprint(value))))
+		case "vd":
+			fields = append(fields, fmt.Sprintf(`r.dataValue == %s`, fluxString(fmt.Sprint(value))))
+		case "payload":
+			// Payload fields can be of any type, so they're compared as
+			// strings.
+			for key, val := range rpm.Payload {
+				col := fmt.Sprintf(`r[%s]`, fluxString(key))
+				fields = append(fields, fmt.Sprintf(`(exists %s and string(v: %s) == %s)`, col, col, fluxString(fmtPayloadValue(val))))
+			}
+		}
+	}
+	// Map iteration order is random, so the conditions are sorted to keep
+	// the query stable.
+	sort.Strings(tags[2:])
+	sort.Strings(fields)
+
+	q := fmt.Sprintf(`from(bucket: %s)
+  |> range(start: %s, stop: %s)
+  |> filter(fn: (r) => %s)
+  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")`,
+		fluxString(bucket), start, stop, strings.Join(tags, " and "))
+	if len(fields) > 0 {
+		q = fmt.Sprintf(`%s
+  |> filter(fn: (r) => %s)`, q, strings.Join(fields, " and "))
+	}
+
+	return q + `
+  |> group()`
+}
+
+// fluxString returns the Flux string literal of the value.
+func fluxString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `${`, `\${`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// fluxFields converts the Flux result row into the names and the values in
+// the form of InfluxDB 1.x query result, so that the same parsing is used.
+func fluxFields(row influxdb2.Row) ([]string, []interface{}) {
+	var names []string
+	var fields []interface{}
+	for name, value := range row {
+		if fluxColumns[name] {
+			continue
+		}
+		switch v := value.(type) {
+		case time.Time:
+			value = v.Format(time.RFC3339Nano)
+		case float64:
+			value = json.Number(strconv.FormatFloat(v, 'f', -1, 64))
+		case int64:
+			value = json.Number(strconv.FormatInt(v, 10))
+		case uint64:
+			value = json.Number(strconv.FormatUint(v, 10))
+		}
+		if name == "_time" {
+			name = "time"
+		}
+		names = append(names, name)
+		fields = append(fields, value)
+	}
+	return names, fields
+}
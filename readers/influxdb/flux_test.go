@@ -0,0 +1,402 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/influxdb2"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+	ireader "github.com/mainflux/mainflux/readers/influxdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAllV2(t *testing.T) {
+	now := time.Unix(1633046400, 5e8).UTC()
+	mock := &influxdb2Mock{
+		rows: []influxdb2.Row{
+			{
+				"result":       "_result",
+				"table":        int64(0),
+				"_start":       time.Unix(0, 0).UTC(),
+				"_stop":        now,
+				"_measurement": "messages",
+				"_time":        now,
+				"channel":      "chan",
+				"publisher":    "pub",
+				"name":         msgName,
+				"protocol":     mqttProt,
+				"unit":         "Cel",
+				"value":        v,
+				"sum":          sum,
+				"updateTime":   "0",
+			},
+		},
+		count: 42,
+	}
+	reader := ireader.NewV2(mock, "bucket", nil)
+
+	pm := readers.PageMetadata{
+		Offset:     10,
+		Limit:      limit,
+		Name:       msgName,
+		Protocol:   mqttProt,
+		Value:      v,
+		Comparator: readers.GreaterThanEqualKey,
+		From:       1633046400,
+	}
+	page, err := reader.ReadAll("chan", pm)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	expected := senml.Message{
+		Channel:   "chan",
+		Publisher: "pub",
+		Name:      msgName,
+		Protocol:  mqttProt,
+		Unit:      "Cel",
+		Value:     &v,
+		Sum:       &sum,
+		Time:      1633046400.5,
+	}
+	assert.Equal(t, uint64(42), page.Total, "expected total count of messages")
+	assert.Equal(t, []readers.Message{expected}, page.Messages, "expected parsed messages")
+
+	require.Len(t, mock.queries, 2, "expected page and count queries")
+	for _, q := range mock.queries {
+		assert.True(t, strings.HasPrefix(q, `from(bucket: "bucket")`), fmt.Sprintf("expected query of the bucket got %s", q))
+		assert.Contains(t, q, `range(start: time(v: 1633046400000000000), stop: now())`, "expected time range")
+		assert.Contains(t, q, `r._measurement == "messages" and r.channel == "chan" and r.name == "temperature"`, "expected tags filter")
+		assert.Contains(t, q, `r.protocol == "mqtt" and r.value >= 5.000000`, "expected fields filter")
+	}
+	assert.Contains(t, mock.queries[0], `limit(n: 10, offset: 10)`, "expected page limit")
+	assert.Contains(t, mock.queries[1], `count(column: "protocol")`, "expected count")
+	assert.Contains(t, mock.queries[0], `sort(columns: ["_time"], desc: true)`, "expected newest messages first")
+
+	pm.Dir = readers.AscDir
+	_, err = reader.ReadAll("chan", pm)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Contains(t, mock.queries[2], `sort(columns: ["_time"], desc: false)`, "expected oldest messages first")
+
+	mock.err = influxdb2.ErrQuery
+	_, err = reader.ReadAll("chan", pm)
+	assert.NotNil(t, err, "expected error reading messages")
+}
+
+func TestReadJSONV2(t *testing.T) {
+	now := time.Unix(1633046400, 0).UTC()
+	mock := &influxdb2Mock{
+		rows: []influxdb2.Row{
+			{
+				"_measurement": "some_json",
+				"_time":        now,
+				"channel":      "chan",
+				"publisher":    "pub",
+				"protocol":     mqttProt,
+				"status":       "on",
+				"temperature":  21.5,
+			},
+		},
+		count: 1,
+	}
+	reader := ireader.NewV2(mock, "bucket", nil)
+
+	pm := readers.PageMetadata{
+		Limit:   limit,
+		Format:  "some_json",
+		Payload: map[string]string{"temperature": "21.50", "status": "on"},
+	}
+	page, err := reader.ReadAll("chan", pm)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	expected := map[string]interface{}{
+		"channel":   "chan",
+		"publisher": "pub",
+		"protocol":  mqttProt,
+		"time":      now.Format(time.RFC3339Nano),
+		"payload": map[string]interface{}{
+			"status":      "on",
+			"temperature": 21.5,
+		},
+	}
+	assert.Equal(t, uint64(1), page.Total, "expected total count of messages")
+	assert.Equal(t, []readers.Message{expected}, page.Messages, "expected parsed messages")
+
+	require.Len(t, mock.queries, 2, "expected page and count queries")
+	for _, q := range mock.queries {
+		assert.Contains(t, q, `r._measurement == "some_json" and r.channel == "chan"`, "expected tags filter")
+		assert.Contains(t, q, `(exists r["status"] and string(v: r["status"]) == "on") and (exists r["temperature"] and string(v: r["temperature"]) == "21.5")`, "expected payload fields filter")
+	}
+}
+
+func TestAggregateV2(t *testing.T) {
+	hour := time.Unix(1633046400, 0).UTC()
+	row := func(t time.Time, pub string, value float64) influxdb2.Row {
+		return influxdb2.Row{
+			"result":    "_result",
+			"table":     int64(0),
+			"_start":    time.Unix(0, 0).UTC(),
+			"_stop":     hour.Add(2 * time.Hour),
+			"_time":     t,
+			"name":      msgName,
+			"publisher": pub,
+			"_value":    value,
+		}
+	}
+	mock := &influxdb2Mock{
+		rows: []influxdb2.Row{
+			row(hour, "pub1", 1.5),
+			row(hour.Add(time.Hour), "pub2", 3),
+			row(hour.Add(time.Hour), "pub1", 2),
+		},
+	}
+	reader := ireader.NewV2(mock, "bucket", nil)
+
+	pm := readers.PageMetadata{
+		Offset:      1,
+		Limit:       limit,
+		Name:        msgName,
+		Aggregation: readers.AvgAggregation,
+		Interval:    "1h",
+	}
+	page, err := reader.Aggregate("chan", pm)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	expected := []readers.Aggregate{
+		{Time: float64(hour.Add(time.Hour).Unix()), Name: msgName, Publisher: "pub2", Value: 3},
+		{Time: float64(hour.Unix()), Name: msgName, Publisher: "pub1", Value: 1.5},
+	}
+	assert.Equal(t, uint64(3), page.Total, "expected total count of aggregates")
+	assert.Equal(t, expected, page.Aggregates, "expected aggregates sorted from the newest interval")
+
+	require.Len(t, mock.queries, 1, "expected aggregation query")
+	q := mock.queries[0]
+	assert.Contains(t, q, `r._measurement == "messages" and r.channel == "chan" and r.name == "temperature"`, "expected tags filter")
+	assert.Contains(t, q, `filter(fn: (r) => exists r.value)`, "expected value filter")
+	assert.Contains(t, q, `aggregateWindow(every: 3600000ms, fn: mean`, "expected aggregation window")
+
+	pm.Aggregation = "sum"
+	_, err = reader.Aggregate("chan", pm)
+	assert.True(t, errors.Contains(err, readers.ErrInvalidAggregation), fmt.Sprintf("expected %s got %s", readers.ErrInvalidAggregation, err))
+}
+
+func TestAggregateRollupV2(t *testing.T) {
+	hour := time.Unix(1633046400, 0).UTC()
+	mock := &influxdb2Mock{
+		rows: []influxdb2.Row{
+			{
+				"result":    "_result",
+				"table":     int64(0),
+				"_time":     hour,
+				"name":      msgName,
+				"publisher": "pub",
+				"_value":    2.5,
+			},
+		},
+	}
+	rollups := []ireader.Rollup{
+		{Interval: time.Minute, Target: "rollup_1m"},
+		{Interval: time.Hour, Target: "rollup_1h"},
+	}
+	reader := ireader.NewV2(mock, "bucket", rollups)
+
+	cases := map[string]struct {
+		pageMeta readers.PageMetadata
+		bucket   string
+	}{
+		"aggregate by interval of the rollup": {
+			pageMeta: readers.PageMetadata{Limit: limit, Aggregation: readers.AvgAggregation, Interval: "1h"},
+			bucket:   "rollup_1h",
+		},
+		"aggregate by multiple of the interval of the rollup": {
+			pageMeta: readers.PageMetadata{Limit: limit, Aggregation: readers.AvgAggregation, Interval: "24h"},
+			bucket:   "rollup_1h",
+		},
+		"aggregate by interval finer than the coarse rollup": {
+			pageMeta: readers.PageMetadata{Limit: limit, Aggregation: readers.AvgAggregation, Interval: "30m"},
+			bucket:   "rollup_1m",
+		},
+		"aggregate with time range not aligned to the coarse rollup": {
+			pageMeta: readers.PageMetadata{Limit: limit, Aggregation: readers.AvgAggregation, Interval: "1h", From: 1633046460},
+			bucket:   "rollup_1m",
+		},
+		"aggregate by interval finer than the rollups": {
+			pageMeta: readers.PageMetadata{Limit: limit, Aggregation: readers.AvgAggregation, Interval: "10s"},
+			bucket:   "bucket",
+		},
+		"aggregate values filtered by the field": {
+			pageMeta: readers.PageMetadata{Limit: limit, Aggregation: readers.AvgAggregation, Interval: "1h", Protocol: mqttProt},
+			bucket:   "bucket",
+		},
+	}
+
+	for desc, tc := range cases {
+		mock.queries = nil
+		page, err := reader.Aggregate("chan", tc.pageMeta)
+		require.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", desc, err))
+		expected := []readers.Aggregate{{Time: float64(hour.Unix()), Name: msgName, Publisher: "pub", Value: 2.5}}
+		assert.Equal(t, expected, page.Aggregates, fmt.Sprintf("%s: expected %v got %v", desc, expected, page.Aggregates))
+		require.Len(t, mock.queries, 1, fmt.Sprintf("%s: expected aggregation query", desc))
+		q := mock.queries[0]
+		assert.True(t, strings.HasPrefix(q, fmt.Sprintf(`from(bucket: "%s")`, tc.bucket)), fmt.Sprintf("%s: expected query of the bucket %s got %s", desc, tc.bucket, q))
+	}
+
+	mock.queries = nil
+	_, err := reader.Aggregate("chan", readers.PageMetadata{Limit: limit, Aggregation: readers.CountAggregation, Interval: "2h"})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Contains(t, mock.queries[0], `window(every: 7200000ms)`, "expected aggregation window")
+	assert.Contains(t, mock.queries[0], `sum(column: "count_protocol")`, "expected sum of the counts")
+}
+
+func TestReadLatestV2(t *testing.T) {
+	now := time.Unix(1633046400, 0).UTC()
+	mock := &influxdb2Mock{
+		rows: []influxdb2.Row{
+			{"_time": now, "channel": "chan", "publisher": "pub2", "name": msgName, "protocol": mqttProt, "value": v},
+			{"_time": now, "channel": "chan", "publisher": "pub1", "name": msgName, "protocol": mqttProt, "value": v},
+			{"_time": now, "channel": "chan", "publisher": "pub1", "name": "humidity", "protocol": mqttProt, "value": v},
+		},
+	}
+	reader := ireader.NewV2(mock, "bucket", nil)
+
+	page, err := reader.ReadLatest("chan", readers.PageMetadata{Offset: 1, Limit: limit, Protocol: mqttProt})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	msg := func(publisher, name string) senml.Message {
+		return senml.Message{
+			Channel:   "chan",
+			Publisher: publisher,
+			Name:      name,
+			Protocol:  mqttProt,
+			Value:     &v,
+			Time:      float64(now.Unix()),
+		}
+	}
+	expected := []readers.Message{msg("pub1", msgName), msg("pub2", msgName)}
+	assert.Equal(t, uint64(3), page.Total, "expected total count of pairs")
+	assert.Equal(t, expected, page.Messages, "expected latest messages sorted by publisher and name")
+
+	require.Len(t, mock.queries, 1, "expected single query")
+	assert.Contains(t, mock.queries[0], `r.protocol == "mqtt"`, "expected fields filter")
+	assert.Contains(t, mock.queries[0], `group(columns: ["publisher", "name"])
+  |> top(n: 1, columns: ["_time"])`, "expected latest message of each pair")
+}
+
+func TestSummaryV2(t *testing.T) {
+	hour := time.Unix(1633046400, 0).UTC()
+	mock := &influxdb2Mock{
+		rows: []influxdb2.Row{
+			{"_time": hour, "_value": "pub"},
+		},
+		count: 3,
+	}
+	reader := ireader.NewV2(mock, "bucket", nil)
+
+	summary, err := reader.Summarize("chan", readers.PageMetadata{Limit: limit, Name: msgName})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	expected := readers.Summary{
+		Count:      3,
+		First:      float64(hour.Unix()),
+		Last:       float64(hour.Unix()),
+		Publishers: []string{"pub"},
+	}
+	assert.Equal(t, expected, summary, fmt.Sprintf("expected %v got %v", expected, summary))
+
+	require.Len(t, mock.queries, 4, "expected count, first, last and publishers queries")
+	assert.Contains(t, mock.queries[0], `r.channel == "chan" and r.name == "temperature"`, "expected tags filter")
+	assert.Contains(t, mock.queries[1], `sort(columns: ["_time"], desc: false)`, "expected first message query")
+	assert.Contains(t, mock.queries[2], `sort(columns: ["_time"], desc: true)`, "expected last message query")
+	assert.Contains(t, mock.queries[3], `distinct(column: "publisher")`, "expected distinct publishers query")
+
+	mock.queries, mock.count = nil, 0
+	summary, err = reader.Summarize("chan", readers.PageMetadata{Limit: limit})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, readers.Summary{Publishers: []string{}}, summary, "expected empty summary")
+	assert.Len(t, mock.queries, 1, "expected only count query")
+}
+
+func TestDeleteV2(t *testing.T) {
+	mock := &influxdb2Mock{count: 3}
+	rollups := []ireader.Rollup{{Interval: time.Hour, Target: "rollup_1h"}}
+	reader := ireader.NewV2(mock, "bucket", rollups)
+
+	from, to := float64(1633046400), float64(1633050000)
+	count, err := reader.Delete("chan", readers.PageMetadata{Publisher: "pub", Name: msgName, From: from, To: to})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, uint64(3), count, fmt.Sprintf("expected %d got %d", 3, count))
+
+	require.Len(t, mock.queries, 1, "expected count query")
+	assert.NotContains(t, mock.queries[0], `r.name`, "expected the name filter to be ignored")
+	pred := `_measurement="messages" AND channel="chan" AND publisher="pub"`
+	expected := []deleteReq{
+		{bucket: "bucket", start: time.Unix(0, int64(from*1e9)), stop: time.Unix(0, int64(to*1e9)-1), predicate: pred},
+		{bucket: "rollup_1h", start: time.Unix(0, int64(from*1e9)), stop: time.Unix(0, int64(to*1e9)-1), predicate: pred},
+	}
+	assert.Equal(t, expected, mock.deletes, fmt.Sprintf("expected %v got %v", expected, mock.deletes))
+
+	mock.queries, mock.deletes = nil, nil
+	count, err = reader.Delete("chan", readers.PageMetadata{Format: "some_json"})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, uint64(3), count, fmt.Sprintf("expected %d got %d", 3, count))
+	require.Len(t, mock.deletes, 1, "expected JSON messages to be deleted from the bucket only")
+	assert.Equal(t, `_measurement="some_json" AND channel="chan"`, mock.deletes[0].predicate, "expected delete predicate")
+
+	mock.deletes, mock.count = nil, 0
+	count, err = reader.Delete("chan", readers.PageMetadata{})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, uint64(0), count, fmt.Sprintf("expected %d got %d", 0, count))
+	assert.Empty(t, mock.deletes, "expected no deletion without matching messages")
+
+	mock.count, mock.err = 3, errors.New("failed")
+	_, err = reader.Delete("chan", readers.PageMetadata{})
+	assert.NotNil(t, err, "expected error")
+}
+
+type influxdb2Mock struct {
+	rows    []influxdb2.Row
+	count   int64
+	err     error
+	queries []string
+	deletes []deleteReq
+}
+
+type deleteReq struct {
+	bucket    string
+	start     time.Time
+	stop      time.Time
+	predicate string
+}
+
+func (im *influxdb2Mock) Write(bucket string, points io.Reader) error {
+	return nil
+}
+
+func (im *influxdb2Mock) Query(query string) ([]influxdb2.Row, error) {
+	if im.err != nil {
+		return nil, im.err
+	}
+	im.queries = append(im.queries, query)
+	if strings.Contains(query, "count(") {
+		return []influxdb2.Row{{"protocol": im.count}}, nil
+	}
+	return im.rows, nil
+}
+
+func (im *influxdb2Mock) Delete(bucket string, start, stop time.Time, predicate string) error {
+	if im.err != nil {
+		return im.err
+	}
+	im.deletes = append(im.deletes, deleteReq{bucket, start, stop, predicate})
+	return nil
+}
+
+func (im *influxdb2Mock) Ping() error {
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb
+
+import (
+	"fmt"
+
+	influxdata "github.com/influxdata/influxdb/client/v2"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+)
+
+func (repo *influxRepository) ReadLatest(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	// Publisher and name are tags, so each group is a single series and the
+	// limit applies to each of them.
+	cmd := fmt.Sprintf(`SELECT * FROM %s WHERE %s GROUP BY "publisher", "name" ORDER BY time DESC LIMIT 1`, defMeasurement, fmtCondition(chanID, rpm))
+	q := influxdata.Query{
+		Command:  cmd,
+		Database: repo.database,
+	}
+	resp, err := repo.client.Query(q)
+	if err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	if resp.Error() != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, resp.Error())
+	}
+
+	var msgs []senml.Message
+	if len(resp.Results) > 0 {
+		for _, series := range resp.Results[0].Series {
+			if len(series.Values) < 1 {
+				continue
+			}
+			// Grouped tags are not returned as the columns.
+			msg := parseSenml(series.Columns, series.Values[0]).(senml.Message)
+			msg.Publisher, msg.Name = series.Tags["publisher"], series.Tags["name"]
+			msgs = append(msgs, msg)
+		}
+	}
+
+	return readers.LatestPage(msgs, rpm), nil
+}
+
+func (repo *fluxRepository) ReadLatest(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	query := fmtFlux(repo.bucket, defMeasurement, chanID, rpm)
+	rows, err := repo.client.Query(query + `
+  |> group(columns: ["publisher", "name"])
+  |> top(n: 1, columns: ["_time"])
+  |> group()`)
+	if err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+
+	var msgs []senml.Message
+	for _, row := range rows {
+		names, fields := fluxFields(row)
+		msgs = append(msgs, parseSenml(names, fields).(senml.Message))
+	}
+
+	return readers.LatestPage(msgs, rpm), nil
+}
@@ -29,13 +29,16 @@ var _ readers.MessageRepository = (*influxRepository)(nil)
 type influxRepository struct {
 	database string
 	client   influxdata.Client
+	rollups  []Rollup
 }
 
-// New returns new InfluxDB reader.
-func New(client influxdata.Client, database string) readers.MessageRepository {
+// New returns new InfluxDB reader. Aggregated values are read from the
+// rollups, if the requested interval and the time range allow it.
+func New(client influxdata.Client, database string, rollups []Rollup) readers.MessageRepository {
 	return &influxRepository{
 		database,
 		client,
+		rollups,
 	}
 }
 
@@ -47,7 +50,7 @@ func (repo *influxRepository) ReadAll(chanID string, rpm readers.PageMetadata) (
 
 	condition := fmtCondition(chanID, rpm)
 
-	cmd := fmt.Sprintf(`SELECT * FROM %s WHERE %s ORDER BY time DESC LIMIT %d OFFSET %d`, format, condition, rpm.Limit, rpm.Offset)
+	cmd := fmt.Sprintf(`SELECT * FROM %s WHERE %s ORDER BY time %s LIMIT %d OFFSET %d`, format, condition, fmtDir(rpm), rpm.Limit, rpm.Offset)
 	q := influxdata.Query{
 		Command:  cmd,
 		Database: repo.database,
@@ -92,7 +95,7 @@ func (repo *influxRepository) ReadAll(chanID string, rpm readers.PageMetadata) (
 }
 
 func (repo *influxRepository) count(measurement, condition string) (uint64, error) {
-	cmd := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, measurement, condition)
+	cmd := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, quoteIdentifier(measurement), condition)
 	q := influxdata.Query{
 		Command:  cmd,
 		Database: repo.database,
@@ -166,11 +169,46 @@ func fmtCondition(chanID string, rpm readers.PageMetadata) string {
 		case "to":
 			iVal := int64(value.(float64) * 1e9)
 			condition = fmt.Sprintf(`%s AND time < %d`, condition, iVal)
+		case "payload":
+			for key, val := range rpm.Payload {
+				condition = fmt.Sprintf(`%s AND %s = %s`, condition, quoteIdentifier(key), fmtLiteral(val))
+			}
 		}
 	}
 	return condition
 }
 
+// fmtLiteral returns the InfluxQL literal of the payload field filter value.
+func fmtLiteral(value string) string {
+	if _, ok := readers.PayloadValue(value).(string); !ok {
+		return fmtPayloadValue(value)
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return `'` + strings.ReplaceAll(value, `'`, `\'`) + `'`
+}
+
+// fmtPayloadValue returns the string representation of the payload field
+// filter value, where the numbers and booleans are formatted the same way as
+// by InfluxDB.
+func fmtPayloadValue(value string) string {
+	switch v := readers.PayloadValue(value).(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return value
+	}
+}
+
+// fmtDir returns the time order direction of the page metadata.
+func fmtDir(rpm readers.PageMetadata) string {
+	if rpm.Dir == readers.AscDir {
+		return "ASC"
+	}
+	return "DESC"
+}
+
 // ParseMessage and parseValues are util methods. Since InfluxDB client returns
 // results in form of rows and columns, this obscure message conversion is needed
 // to return actual []broker.Message from the query result.
@@ -0,0 +1,112 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+var errInvalidRollup = errors.New("invalid rollup")
+
+// influxQLRollupFuncs contains the InfluxQL selections which aggregate the
+// downsampled values into the requested intervals.
+var influxQLRollupFuncs = map[string]string{
+	readers.AvgAggregation:   `SUM("sum_value") / SUM("count_value")`,
+	readers.MinAggregation:   `MIN("min_value")`,
+	readers.MaxAggregation:   `MAX("max_value")`,
+	readers.CountAggregation: `SUM("count_protocol")`,
+}
+
+// fluxRollupFuncs contains the Flux functions which aggregate the windows of
+// the downsampled values into the _value column.
+var fluxRollupFuncs = map[string]string{
+	readers.AvgAggregation: `filter(fn: (r) => exists r.sum_value and exists r.count_value)
+  |> reduce(identity: {sum: 0.0, count: 0.0}, fn: (r, accumulator) => ({sum: accumulator.sum + float(v: r.sum_value), count: accumulator.count + float(v: r.count_value)}))
+  |> filter(fn: (r) => r.count > 0.0)
+  |> map(fn: (r) => ({r with _value: r.sum / r.count}))`,
+	readers.MinAggregation: `filter(fn: (r) => exists r.min_value)
+  |> min(column: "min_value")
+  |> map(fn: (r) => ({r with _value: float(v: r.min_value)}))`,
+	readers.MaxAggregation: `filter(fn: (r) => exists r.max_value)
+  |> max(column: "max_value")
+  |> map(fn: (r) => ({r with _value: float(v: r.max_value)}))`,
+	readers.CountAggregation: `filter(fn: (r) => exists r.count_protocol)
+  |> sum(column: "count_protocol")
+  |> map(fn: (r) => ({r with _value: float(v: r.count_protocol)}))`,
+}
+
+// Rollup is the SenML messages measurement downsampled by the continuous
+// query in InfluxDB 1.x, or by the task in InfluxDB 2.x. For each interval,
+// name and publisher, rollup contains the sum_value, count_value, min_value
+// and max_value fields aggregated from the values, and the count_protocol
+// field which is the count of the messages.
+type Rollup struct {
+	// Interval is the interval the messages are downsampled by.
+	Interval time.Duration
+
+	// Target is the retention policy of the rollup in InfluxDB 1.x, or
+	// the bucket of the rollup in InfluxDB 2.x.
+	Target string
+}
+
+// ParseRollups parses the comma separated list of the rollups in the form of
+// interval:target, such as "1h:rp_1h,24h:rp_1d".
+func ParseRollups(s string) ([]Rollup, error) {
+	var rollups []Rollup
+	for _, r := range strings.Split(s, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+
+		parts := strings.SplitN(r, ":", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, errInvalidRollup
+		}
+		interval, err := time.ParseDuration(parts[0])
+		if err != nil || interval <= 0 {
+			return nil, errors.Wrap(errInvalidRollup, err)
+		}
+
+		rollups = append(rollups, Rollup{
+			Interval: interval,
+			Target:   parts[1],
+		})
+	}
+
+	return rollups, nil
+}
+
+// selectRollup returns the coarsest rollup whose intervals exactly make up
+// the requested interval and the time range, so that the aggregated values
+// are the same as the ones aggregated from the messages.
+func selectRollup(rollups []Rollup, interval time.Duration, rpm readers.PageMetadata) (Rollup, bool) {
+	// Rollups keep only the tags of the messages, so the messages filtered
+	// by the fields have to be aggregated from the measurement itself.
+	if rpm.Protocol != "" || rpm.Value != 0 || rpm.BoolValue ||
+		rpm.StringValue != "" || rpm.DataValue != "" {
+		return Rollup{}, false
+	}
+
+	var ret Rollup
+	for _, r := range rollups {
+		if r.Interval <= ret.Interval || interval%r.Interval != 0 ||
+			!aligned(rpm.From, r.Interval) || !aligned(rpm.To, r.Interval) {
+			continue
+		}
+		ret = r
+	}
+
+	return ret, ret.Interval != 0
+}
+
+// aligned reports whether the time in seconds is at the start of the
+// interval. InfluxDB aligns the intervals to the Unix epoch.
+func aligned(t float64, interval time.Duration) bool {
+	return int64(t*1e9)%int64(interval) == 0
+}
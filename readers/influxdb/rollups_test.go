@@ -0,0 +1,54 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	ireader "github.com/mainflux/mainflux/readers/influxdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRollups(t *testing.T) {
+	cases := map[string]struct {
+		rollups  string
+		expected []ireader.Rollup
+		err      bool
+	}{
+		"parse empty rollups": {
+			rollups: "",
+		},
+		"parse rollups": {
+			rollups: "1h:rp_1h, 24h:rp_1d",
+			expected: []ireader.Rollup{
+				{Interval: time.Hour, Target: "rp_1h"},
+				{Interval: 24 * time.Hour, Target: "rp_1d"},
+			},
+		},
+		"parse rollup without target": {
+			rollups: "1h",
+			err:     true,
+		},
+		"parse rollup with empty target": {
+			rollups: "1h:",
+			err:     true,
+		},
+		"parse rollup with invalid interval": {
+			rollups: "1y:rp_1y",
+			err:     true,
+		},
+		"parse rollup with negative interval": {
+			rollups: "-1h:rp_1h",
+			err:     true,
+		},
+	}
+
+	for desc, tc := range cases {
+		rollups, err := ireader.ParseRollups(tc.rollups)
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s", desc, tc.err, err))
+		assert.Equal(t, tc.expected, rollups, fmt.Sprintf("%s: expected %v got %v", desc, tc.expected, rollups))
+	}
+}
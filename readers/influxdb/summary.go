@@ -0,0 +1,148 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	influxdata "github.com/influxdata/influxdb/client/v2"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+func (repo *influxRepository) Summarize(chanID string, rpm readers.PageMetadata) (readers.Summary, error) {
+	measurement := defMeasurement
+	if rpm.Format != "" {
+		measurement = rpm.Format
+	}
+	condition := fmtCondition(chanID, rpm)
+
+	// Protocol field is present in all the messages. Selecting only its
+	// first or last value returns the time of the message as well.
+	cmd := fmt.Sprintf(`SELECT COUNT("protocol") FROM %s WHERE %s GROUP BY "publisher";
+SELECT FIRST("protocol") FROM %s WHERE %s;
+SELECT LAST("protocol") FROM %s WHERE %s`, measurement, condition, measurement, condition, measurement, condition)
+	q := influxdata.Query{
+		Command:  cmd,
+		Database: repo.database,
+	}
+	resp, err := repo.client.Query(q)
+	if err != nil {
+		return readers.Summary{}, errors.Wrap(errReadMessages, err)
+	}
+	if resp.Error() != nil {
+		return readers.Summary{}, errors.Wrap(errReadMessages, resp.Error())
+	}
+
+	summary := readers.Summary{Publishers: []string{}}
+	if len(resp.Results) < 3 {
+		return summary, nil
+	}
+
+	// Each series contains the count of the messages of a single publisher.
+	for _, series := range resp.Results[0].Series {
+		if len(series.Values) < 1 || len(series.Values[0]) < 2 {
+			continue
+		}
+		num, ok := series.Values[0][1].(json.Number)
+		if !ok {
+			continue
+		}
+		count, err := strconv.ParseUint(num.String(), 10, 64)
+		if err != nil {
+			return readers.Summary{}, errors.Wrap(errReadMessages, err)
+		}
+		summary.Count += count
+		summary.Publishers = append(summary.Publishers, series.Tags["publisher"])
+	}
+	sort.Strings(summary.Publishers)
+
+	if summary.First, err = parseFirstTime(resp.Results[1]); err != nil {
+		return readers.Summary{}, errors.Wrap(errReadMessages, err)
+	}
+	if summary.Last, err = parseFirstTime(resp.Results[2]); err != nil {
+		return readers.Summary{}, errors.Wrap(errReadMessages, err)
+	}
+
+	return summary, nil
+}
+
+func (repo *fluxRepository) Summarize(chanID string, rpm readers.PageMetadata) (readers.Summary, error) {
+	format := defMeasurement
+	if rpm.Format != "" {
+		format = rpm.Format
+	}
+	query := fmtFlux(repo.bucket, format, chanID, rpm)
+
+	count, err := repo.count(query)
+	if err != nil {
+		return readers.Summary{}, errors.Wrap(errReadMessages, err)
+	}
+	summary := readers.Summary{
+		Count:      count,
+		Publishers: []string{},
+	}
+	if count == 0 {
+		return summary, nil
+	}
+
+	for _, desc := range []bool{false, true} {
+		rows, err := repo.client.Query(fmt.Sprintf(`%s
+  |> keep(columns: ["_time"])
+  |> sort(columns: ["_time"], desc: %t)
+  |> limit(n: 1)`, query, desc))
+		if err != nil {
+			return readers.Summary{}, errors.Wrap(errReadMessages, err)
+		}
+		if len(rows) < 1 {
+			continue
+		}
+		t, ok := rows[0]["_time"].(time.Time)
+		if !ok {
+			continue
+		}
+		if desc {
+			summary.Last = float64(t.UnixNano()) / 1e9
+			continue
+		}
+		summary.First = float64(t.UnixNano()) / 1e9
+	}
+
+	rows, err := repo.client.Query(query + `
+  |> keep(columns: ["publisher"])
+  |> distinct(column: "publisher")`)
+	if err != nil {
+		return readers.Summary{}, errors.Wrap(errReadMessages, err)
+	}
+	for _, row := range rows {
+		if publisher, ok := row["_value"].(string); ok {
+			summary.Publishers = append(summary.Publishers, publisher)
+		}
+	}
+	sort.Strings(summary.Publishers)
+
+	return summary, nil
+}
+
+// parseFirstTime parses the time of the first row of the query result, which
+// is zero if there are no rows.
+func parseFirstTime(res influxdata.Result) (float64, error) {
+	if len(res.Series) < 1 || len(res.Series[0].Values) < 1 || len(res.Series[0].Values[0]) < 1 {
+		return 0, nil
+	}
+	ts, ok := res.Series[0].Values[0][0].(string)
+	if !ok {
+		return 0, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(t.UnixNano()) / 1e9, nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package readers
+
+import (
+	"sync"
+
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+const (
+	senmlFormat    = "messages"
+	liveBufferSize = 100
+)
+
+// LiveStream distributes the messages published to the channels to their
+// subscribers, as they arrive from the message broker.
+type LiveStream interface {
+	// Subscribe returns the messages published to the channel which match
+	// the page metadata filters, and the function which ends the
+	// subscription. Messages are dropped if the subscriber falls behind.
+	Subscribe(chanID string, pm PageMetadata) (<-chan Message, func())
+
+	// Handle transforms the published message and passes it to the
+	// subscribers of its channel.
+	Handle(msg messaging.Message) error
+}
+
+var _ LiveStream = (*liveStream)(nil)
+
+type subscriber struct {
+	pm   PageMetadata
+	msgs chan Message
+}
+
+type liveStream struct {
+	mu    sync.RWMutex
+	subs  map[string]map[*subscriber]bool
+	senml transformers.Transformer
+	json  transformers.Transformer
+}
+
+// NewLiveStream returns the live stream of the messages, which are
+// transformed to SenML or JSON messages, depending on the requested format.
+func NewLiveStream() LiveStream {
+	return &liveStream{
+		subs:  make(map[string]map[*subscriber]bool),
+		senml: senml.New(senml.JSON),
+		json:  json.New(),
+	}
+}
+
+func (ls *liveStream) Subscribe(chanID string, pm PageMetadata) (<-chan Message, func()) {
+	sub := &subscriber{
+		pm:   pm,
+		msgs: make(chan Message, liveBufferSize),
+	}
+
+	ls.mu.Lock()
+	if ls.subs[chanID] == nil {
+		ls.subs[chanID] = make(map[*subscriber]bool)
+	}
+	ls.subs[chanID][sub] = true
+	ls.mu.Unlock()
+
+	var once sync.Once
+	return sub.msgs, func() {
+		once.Do(func() {
+			ls.mu.Lock()
+			defer ls.mu.Unlock()
+			delete(ls.subs[chanID], sub)
+			if len(ls.subs[chanID]) == 0 {
+				delete(ls.subs, chanID)
+			}
+			close(sub.msgs)
+		})
+	}
+}
+
+func (ls *liveStream) Handle(msg messaging.Message) error {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+
+	subs := ls.subs[msg.Channel]
+	if len(subs) == 0 {
+		return nil
+	}
+
+	// Messages are transformed only to the formats which are subscribed to.
+	var senmlMsgs []senml.Message
+	var jsonMsgs json.Messages
+	for sub := range subs {
+		var msgs []Message
+		switch sub.pm.Format {
+		case "", senmlFormat:
+			if senmlMsgs == nil {
+				m, err := ls.senml.Transform(msg)
+				if err != nil {
+					continue
+				}
+				senmlMsgs = m.([]senml.Message)
+			}
+			for _, m := range senmlMsgs {
+				if matchSenML(m, sub.pm) {
+					msgs = append(msgs, m)
+				}
+			}
+		default:
+			if jsonMsgs.Format == "" {
+				m, err := ls.json.Transform(msg)
+				if err != nil {
+					continue
+				}
+				jsonMsgs = m.(json.Messages)
+			}
+			if jsonMsgs.Format != sub.pm.Format {
+				continue
+			}
+			for _, m := range jsonMsgs.Data {
+				if matchJSON(m, sub.pm) {
+					msgs = append(msgs, m)
+				}
+			}
+		}
+
+		for _, m := range msgs {
+			select {
+			case sub.msgs <- m:
+			default:
+			}
+		}
+	}
+
+	return nil
+}
+
+func matchSenML(msg senml.Message, pm PageMetadata) bool {
+	if (pm.Subtopic != "" && msg.Subtopic != pm.Subtopic) ||
+		(pm.Publisher != "" && msg.Publisher != pm.Publisher) ||
+		(pm.Protocol != "" && msg.Protocol != pm.Protocol) ||
+		(pm.Name != "" && msg.Name != pm.Name) ||
+		(pm.From != 0 && msg.Time < pm.From) ||
+		(pm.To != 0 && msg.Time >= pm.To) {
+		return false
+	}
+	if pm.BoolValue && (msg.BoolValue == nil || !*msg.BoolValue) {
+		return false
+	}
+	if pm.StringValue != "" && (msg.StringValue == nil || *msg.StringValue != pm.StringValue) {
+		return false
+	}
+	if pm.DataValue != "" && (msg.DataValue == nil || *msg.DataValue != pm.DataValue) {
+		return false
+	}
+	if pm.Value == 0 {
+		return true
+	}
+	if msg.Value == nil {
+		return false
+	}
+
+	switch v := *msg.Value; pm.Comparator {
+	case LowerThanKey:
+		return v < pm.Value
+	case LowerThanEqualKey:
+		return v <= pm.Value
+	case GreaterThanKey:
+		return v > pm.Value
+	case GreaterThanEqualKey:
+		return v >= pm.Value
+	default:
+		return v == pm.Value
+	}
+}
+
+func matchJSON(msg json.Message, pm PageMetadata) bool {
+	created := float64(msg.Created) / 1e9
+	return (pm.Subtopic == "" || msg.Subtopic == pm.Subtopic) &&
+		(pm.Publisher == "" || msg.Publisher == pm.Publisher) &&
+		(pm.Protocol == "" || msg.Protocol == pm.Protocol) &&
+		(pm.From == 0 || created >= pm.From) &&
+		(pm.To == 0 || created < pm.To) &&
+		MatchPayload(msg.Payload, pm.Payload)
+}
@@ -3,7 +3,17 @@
 
 package readers
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	jsont "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
 
 const (
 	// EqualKey represents the equal comparison operator key.
@@ -16,45 +26,263 @@ const (
 	GreaterThanKey = "gt"
 	// GreaterThanEqualKey represents the greater-than-or-equal comparison operator key.
 	GreaterThanEqualKey = "ge"
+
+	// AvgAggregation represents the average value aggregation.
+	AvgAggregation = "avg"
+	// MinAggregation represents the minimum value aggregation.
+	MinAggregation = "min"
+	// MaxAggregation represents the maximum value aggregation.
+	MaxAggregation = "max"
+	// CountAggregation represents the number of messages aggregation.
+	CountAggregation = "count"
+
+	// AscDir represents the ascending time order, from the oldest message.
+	AscDir = "asc"
+	// DescDir represents the descending time order, from the newest message.
+	DescDir = "desc"
 )
 
-// ErrNotFound indicates that requested entity doesn't exist.
-var ErrNotFound = errors.New("entity not found")
+var (
+	// ErrNotFound indicates that requested entity doesn't exist.
+	ErrNotFound = errors.New("entity not found")
+
+	// ErrInvalidAggregation indicates unsupported aggregation or interval.
+	ErrInvalidAggregation = errors.New("invalid aggregation")
+
+	// ErrInvalidPageToken indicates malformed page token.
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
 
 // MessageRepository specifies message reader API.
 type MessageRepository interface {
 	// ReadAll skips given number of messages for given channel and returns next
-	// limited number of messages.
+	// limited number of messages. If the page metadata contains the time range,
+	// only the messages saved within it are returned. Messages are sorted by
+	// time in the page metadata direction, newest first by default.
 	ReadAll(chanID string, pm PageMetadata) (MessagesPage, error)
+
+	// Aggregate groups SenML messages of the given channel by the time
+	// interval, name and publisher and returns the limited number of
+	// aggregated values, sorted by time in the page metadata direction.
+	Aggregate(chanID string, pm PageMetadata) (AggregatesPage, error)
+
+	// Summarize returns the summary of the messages of the given channel
+	// which match the page metadata filters, without reading the messages.
+	Summarize(chanID string, pm PageMetadata) (Summary, error)
+
+	// ReadLatest returns the latest SenML message of each publisher and name
+	// pair of the given channel which matches the page metadata filters. The
+	// messages are sorted by publisher and name, and the page total is the
+	// number of the pairs.
+	ReadLatest(chanID string, pm PageMetadata) (MessagesPage, error)
+
+	// Delete removes the messages of the given channel and format which
+	// match the publisher and the time range of the page metadata, and
+	// returns the number of the removed messages. The other filters are
+	// ignored.
+	Delete(chanID string, pm PageMetadata) (uint64, error)
 }
 
 // Message represents any message format.
 type Message interface{}
 
 // MessagesPage contains page related metadata as well as list of messages that
-// belong to this page.
+// belong to this page. Repositories which page the messages by the tokens
+// return the token of the next page, which is empty on the last page.
 type MessagesPage struct {
 	PageMetadata
-	Total    uint64
-	Messages []Message
+	Total         uint64
+	Messages      []Message
+	NextPageToken string
 }
 
-// PageMetadata represents the parameters used to create database queries
+// ChannelsPage contains the page of the messages read from multiple channels,
+// as well as the total number of the matching messages of each channel.
+type ChannelsPage struct {
+	MessagesPage
+	Channels map[string]uint64
+}
+
+// PageMetadata represents the parameters used to create database queries.
+// From and To are Unix times in seconds, where From is inclusive and To is
+// exclusive. JSON messages are compared by their creation time, and filtered
+// by the values of their top-level payload fields. If the page token is set,
+// the page starts where the page which returned it ended, and the offset is
+// ignored by the repositories which support the tokens.
 type PageMetadata struct {
-	Offset      uint64  `json:"offset"`
-	Limit       uint64  `json:"limit"`
-	Subtopic    string  `json:"subtopic,omitempty"`
-	Publisher   string  `json:"publisher,omitempty"`
-	Protocol    string  `json:"protocol,omitempty"`
-	Name        string  `json:"name,omitempty"`
-	Value       float64 `json:"v,omitempty"`
-	Comparator  string  `json:"comparator,omitempty"`
-	BoolValue   bool    `json:"vb,omitempty"`
-	StringValue string  `json:"vs,omitempty"`
-	DataValue   string  `json:"vd,omitempty"`
-	From        float64 `json:"from,omitempty"`
-	To          float64 `json:"to,omitempty"`
-	Format      string  `json:"format,omitempty"`
+	Offset      uint64            `json:"offset"`
+	Limit       uint64            `json:"limit"`
+	Subtopic    string            `json:"subtopic,omitempty"`
+	Publisher   string            `json:"publisher,omitempty"`
+	Protocol    string            `json:"protocol,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Value       float64           `json:"v,omitempty"`
+	Comparator  string            `json:"comparator,omitempty"`
+	BoolValue   bool              `json:"vb,omitempty"`
+	StringValue string            `json:"vs,omitempty"`
+	DataValue   string            `json:"vd,omitempty"`
+	From        float64           `json:"from,omitempty"`
+	To          float64           `json:"to,omitempty"`
+	Format      string            `json:"format,omitempty"`
+	Aggregation string            `json:"aggregation,omitempty"`
+	Interval    string            `json:"interval,omitempty"`
+	Dir         string            `json:"dir,omitempty"`
+	Payload     map[string]string `json:"payload,omitempty"`
+	PageToken   string            `json:"page_token,omitempty"`
+}
+
+// Aggregate represents the aggregated value of the messages with the same
+// name and publisher, saved within the interval starting at the time.
+type Aggregate struct {
+	Time      float64 `json:"time"`
+	Name      string  `json:"name"`
+	Publisher string  `json:"publisher"`
+	Value     float64 `json:"value"`
+}
+
+// AggregatesPage contains page related metadata as well as list of aggregated
+// values that belong to this page.
+type AggregatesPage struct {
+	PageMetadata
+	Total      uint64
+	Aggregates []Aggregate
+}
+
+// Summary represents the number of the messages, the times of the first and
+// the last one, and their distinct publishers sorted by ID. Times are zero if
+// there are no messages.
+type Summary struct {
+	Count      uint64
+	First      float64
+	Last       float64
+	Publishers []string
+}
+
+// ParseInterval returns the aggregation interval of the page metadata. The
+// interval is at least a second long.
+func ParseInterval(pm PageMetadata) (time.Duration, error) {
+	interval, err := time.ParseDuration(pm.Interval)
+	if err != nil || interval < time.Second {
+		return 0, ErrInvalidAggregation
+	}
+	return interval, nil
+}
+
+// ReadChannels reads the messages of the given channels and merges them into a
+// single page, sorted by time in the page metadata direction. Since the page
+// can contain the messages of any of the channels, each channel is read from
+// the first message up to the end of the page.
+func ReadChannels(repo MessageRepository, chanIDs []string, pm PageMetadata) (ChannelsPage, error) {
+	cpm := pm
+	cpm.Offset, cpm.Limit = 0, pm.Offset+pm.Limit
+
+	ret := ChannelsPage{
+		MessagesPage: MessagesPage{
+			PageMetadata: pm,
+			Messages:     []Message{},
+		},
+		Channels: make(map[string]uint64),
+	}
+	var msgs []Message
+	for _, chanID := range chanIDs {
+		page, err := repo.ReadAll(chanID, cpm)
+		if err != nil {
+			return ChannelsPage{}, err
+		}
+		ret.Total += page.Total
+		ret.Channels[chanID] = page.Total
+		msgs = append(msgs, page.Messages...)
+	}
+
+	sort.SliceStable(msgs, func(i, j int) bool {
+		ti, tj := MessageTime(msgs[i]), MessageTime(msgs[j])
+		if pm.Dir == AscDir {
+			return ti < tj
+		}
+		return ti > tj
+	})
+
+	if pm.Offset < uint64(len(msgs)) {
+		end := pm.Offset + pm.Limit
+		if end > uint64(len(msgs)) {
+			end = uint64(len(msgs))
+		}
+		ret.Messages = msgs[pm.Offset:end]
+	}
+
+	return ret, nil
+}
+
+// LatestPage sorts the latest messages of the publisher and name pairs by
+// publisher and name, and returns the page of them. It's used by the
+// repositories which can't sort and page the pairs natively.
+func LatestPage(msgs []senml.Message, pm PageMetadata) MessagesPage {
+	sort.Slice(msgs, func(i, j int) bool {
+		if msgs[i].Publisher != msgs[j].Publisher {
+			return msgs[i].Publisher < msgs[j].Publisher
+		}
+		return msgs[i].Name < msgs[j].Name
+	})
+
+	page := MessagesPage{
+		PageMetadata: pm,
+		Total:        uint64(len(msgs)),
+		Messages:     []Message{},
+	}
+	for i := pm.Offset; i < uint64(len(msgs)) && i < pm.Offset+pm.Limit; i++ {
+		page.Messages = append(page.Messages, msgs[i])
+	}
+
+	return page
+}
+
+// MessageTime returns the message time in seconds. The time of JSON messages
+// is their creation time, which is saved in nanoseconds.
+func MessageTime(msg Message) float64 {
+	switch m := msg.(type) {
+	case senml.Message:
+		return m.Time
+	case jsont.Message:
+		return float64(m.Created) / 1e9
+	case map[string]interface{}:
+		switch created := m["created"].(type) {
+		case int64:
+			return float64(created) / 1e9
+		case float64:
+			return created / 1e9
+		case json.Number:
+			v, _ := created.Float64()
+			return v / 1e9
+		}
+	}
+	return 0
+}
+
+// PayloadValue returns the value of the payload field filter, which is a
+// number or a boolean if it can be parsed as one, and a string otherwise.
+func PayloadValue(value string) interface{} {
+	if v, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(v) && !math.IsInf(v, 0) {
+		return v
+	}
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return value
+}
+
+// MatchPayload reports whether the top-level fields of the JSON message
+// payload are equal to the values of all the payload filters.
+func MatchPayload(payload map[string]interface{}, filters map[string]string) bool {
+	for key, value := range filters {
+		v, ok := payload[key]
+		if !ok || v != PayloadValue(value) {
+			return false
+		}
+	}
+	return true
 }
 
 // ParseValueComparator convert comparison operator keys into mathematic anotation
@@ -0,0 +1,51 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/mainflux/mainflux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errUnauthenticated = status.Error(codes.Unauthenticated, "missing or invalid credentials provided")
+
+var _ mainflux.AuthServiceClient = (*authServiceMock)(nil)
+
+type authServiceMock struct {
+	users map[string]string
+}
+
+// NewAuthService returns mock implementation of auth service, where the users
+// map the tokens to the emails of the users.
+func NewAuthService(users map[string]string) mainflux.AuthServiceClient {
+	return authServiceMock{users}
+}
+
+func (svc authServiceMock) Identify(ctx context.Context, in *mainflux.Token, opts ...grpc.CallOption) (*mainflux.UserIdentity, error) {
+	if email, ok := svc.users[in.GetValue()]; ok {
+		return &mainflux.UserIdentity{Id: email, Email: email}, nil
+	}
+	return nil, errUnauthenticated
+}
+
+func (svc authServiceMock) Issue(context.Context, *mainflux.IssueReq, ...grpc.CallOption) (*mainflux.Token, error) {
+	panic("not implemented")
+}
+
+func (svc authServiceMock) Authorize(context.Context, *mainflux.AuthorizeReq, ...grpc.CallOption) (*mainflux.AuthorizeRes, error) {
+	panic("not implemented")
+}
+
+func (svc authServiceMock) Assign(context.Context, *mainflux.Assignment, ...grpc.CallOption) (*empty.Empty, error) {
+	panic("not implemented")
+}
+
+func (svc authServiceMock) Members(context.Context, *mainflux.MembersReq, ...grpc.CallOption) (*mainflux.MembersRes, error) {
+	panic("not implemented")
+}
@@ -5,6 +5,8 @@ package mocks
 
 import (
 	"encoding/json"
+	"math"
+	"sort"
 	"sync"
 
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
@@ -24,9 +26,15 @@ func NewMessageRepository(chanID string, messages []readers.Message) readers.Mes
 		chanID: messages,
 	}
 
+	return NewChannelsMessageRepository(repo)
+}
+
+// NewChannelsMessageRepository returns mock implementation of message
+// repository containing the messages of multiple channels.
+func NewChannelsMessageRepository(messages map[string][]readers.Message) readers.MessageRepository {
 	return &messageRepositoryMock{
 		mutex:    sync.Mutex{},
-		messages: repo,
+		messages: messages,
 	}
 }
 
@@ -140,6 +148,13 @@ func (repo *messageRepositoryMock) ReadAll(chanID string, rpm readers.PageMetada
 		}
 	}
 
+	// Messages are kept from the newest one.
+	if rpm.Dir == readers.AscDir {
+		for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+			msgs[i], msgs[j] = msgs[j], msgs[i]
+		}
+	}
+
 	numOfMessages := uint64(len(msgs))
 
 	if rpm.Offset >= numOfMessages {
@@ -161,3 +176,151 @@ func (repo *messageRepositoryMock) ReadAll(chanID string, rpm readers.PageMetada
 		Messages:     msgs[rpm.Offset:end],
 	}, nil
 }
+
+func (repo *messageRepositoryMock) Aggregate(chanID string, rpm readers.PageMetadata) (readers.AggregatesPage, error) {
+	interval, err := readers.ParseInterval(rpm)
+	if err != nil {
+		return readers.AggregatesPage{}, err
+	}
+
+	all := rpm
+	all.Offset, all.Limit = 0, math.MaxUint64
+	page, err := repo.ReadAll(chanID, all)
+	if err != nil {
+		return readers.AggregatesPage{}, err
+	}
+
+	type group struct {
+		agg   readers.Aggregate
+		count float64
+	}
+	groups := map[readers.Aggregate]*group{}
+	var keys []readers.Aggregate
+	for _, m := range page.Messages {
+		msg := m.(senml.Message)
+		if rpm.Aggregation != readers.CountAggregation && msg.Value == nil {
+			continue
+		}
+		key := readers.Aggregate{
+			Time:      math.Floor(msg.Time/interval.Seconds()) * interval.Seconds(),
+			Name:      msg.Name,
+			Publisher: msg.Publisher,
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{agg: key}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.count++
+		switch {
+		case rpm.Aggregation == readers.CountAggregation:
+			g.agg.Value = g.count
+		case rpm.Aggregation == readers.AvgAggregation:
+			g.agg.Value += (*msg.Value - g.agg.Value) / g.count
+		case g.count == 1,
+			rpm.Aggregation == readers.MinAggregation && *msg.Value < g.agg.Value,
+			rpm.Aggregation == readers.MaxAggregation && *msg.Value > g.agg.Value:
+			g.agg.Value = *msg.Value
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Time != keys[j].Time {
+			return (keys[i].Time > keys[j].Time) != (rpm.Dir == readers.AscDir)
+		}
+		if keys[i].Name != keys[j].Name {
+			return keys[i].Name < keys[j].Name
+		}
+		return keys[i].Publisher < keys[j].Publisher
+	})
+
+	ret := readers.AggregatesPage{
+		PageMetadata: rpm,
+		Total:        uint64(len(keys)),
+		Aggregates:   []readers.Aggregate{},
+	}
+	for i := rpm.Offset; i < uint64(len(keys)) && i < rpm.Offset+rpm.Limit; i++ {
+		ret.Aggregates = append(ret.Aggregates, groups[keys[i]].agg)
+	}
+
+	return ret, nil
+}
+
+func (repo *messageRepositoryMock) Summarize(chanID string, rpm readers.PageMetadata) (readers.Summary, error) {
+	all := rpm
+	all.Offset, all.Limit = 0, math.MaxUint64
+	page, err := repo.ReadAll(chanID, all)
+	if err != nil {
+		return readers.Summary{}, err
+	}
+
+	summary := readers.Summary{Publishers: []string{}}
+	publishers := map[string]bool{}
+	for _, m := range page.Messages {
+		msg := m.(senml.Message)
+		if summary.Count == 0 || msg.Time < summary.First {
+			summary.First = msg.Time
+		}
+		if summary.Count == 0 || msg.Time > summary.Last {
+			summary.Last = msg.Time
+		}
+		summary.Count++
+		if !publishers[msg.Publisher] {
+			publishers[msg.Publisher] = true
+			summary.Publishers = append(summary.Publishers, msg.Publisher)
+		}
+	}
+	sort.Strings(summary.Publishers)
+
+	return summary, nil
+}
+
+func (repo *messageRepositoryMock) ReadLatest(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	all := rpm
+	all.Offset, all.Limit = 0, math.MaxUint64
+	page, err := repo.ReadAll(chanID, all)
+	if err != nil {
+		return readers.MessagesPage{}, err
+	}
+
+	// Messages are kept from the newest one.
+	seen := map[[2]string]bool{}
+	var msgs []senml.Message
+	for _, m := range page.Messages {
+		msg := m.(senml.Message)
+		pair := [2]string{msg.Publisher, msg.Name}
+		if seen[pair] {
+			continue
+		}
+		seen[pair] = true
+		msgs = append(msgs, msg)
+	}
+
+	return readers.LatestPage(msgs, rpm), nil
+}
+
+func (repo *messageRepositoryMock) Delete(chanID string, rpm readers.PageMetadata) (uint64, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if rpm.Format != "" && rpm.Format != "messages" {
+		return 0, nil
+	}
+
+	var kept []readers.Message
+	var count uint64
+	for _, m := range repo.messages[chanID] {
+		msg := m.(senml.Message)
+		if (rpm.Publisher != "" && msg.Publisher != rpm.Publisher) ||
+			(rpm.From != 0 && msg.Time < rpm.From) ||
+			(rpm.To != 0 && msg.Time >= rpm.To) {
+			kept = append(kept, m)
+			continue
+		}
+		count++
+	}
+	repo.messages[chanID] = kept
+
+	return count, nil
+}
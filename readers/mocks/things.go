@@ -14,15 +14,26 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-var errUnauthorized = status.Error(codes.PermissionDenied, "missing or invalid credentials provided")
+var (
+	errUnauthorized = status.Error(codes.PermissionDenied, "missing or invalid credentials provided")
+	errNotFound     = status.Error(codes.NotFound, "entity does not exist")
+)
 
 var _ mainflux.ThingsServiceClient = (*thingsServiceMock)(nil)
 
-type thingsServiceMock struct{}
+type thingsServiceMock struct {
+	owners map[string]string
+	users  mainflux.AuthServiceClient
+}
 
-// NewThingsService returns mock implementation of things service
-func NewThingsService() mainflux.ThingsServiceClient {
-	return thingsServiceMock{}
+// NewThingsService returns mock implementation of things service, where the
+// owners map the channels to the emails of their owners. Any token except the
+// invalid one and the tokens of the users is a valid thing key.
+func NewThingsService(owners map[string]string, users mainflux.AuthServiceClient) mainflux.ThingsServiceClient {
+	return thingsServiceMock{
+		owners: owners,
+		users:  users,
+	}
 }
 
 func (svc thingsServiceMock) CanAccessByKey(ctx context.Context, in *mainflux.AccessByKeyReq, opts ...grpc.CallOption) (*mainflux.ThingID, error) {
@@ -35,6 +46,10 @@ func (svc thingsServiceMock) CanAccessByKey(ctx context.Context, in *mainflux.Ac
 		return nil, errUnauthorized
 	}
 
+	if _, err := svc.users.Identify(ctx, &mainflux.Token{Value: token}); err == nil {
+		return nil, errUnauthorized
+	}
+
 	return &mainflux.ThingID{Value: token}, nil
 }
 
@@ -42,10 +57,18 @@ func (svc thingsServiceMock) CanAccessByID(context.Context, *mainflux.AccessByID
 	panic("not implemented")
 }
 
-func (svc thingsServiceMock) IsChannelOwner(context.Context, *mainflux.ChannelOwnerReq, ...grpc.CallOption) (*empty.Empty, error) {
-	panic("not implemented")
+func (svc thingsServiceMock) IsChannelOwner(ctx context.Context, in *mainflux.ChannelOwnerReq, opts ...grpc.CallOption) (*empty.Empty, error) {
+	if owner, ok := svc.owners[in.GetChanID()]; !ok || owner != in.GetOwner() {
+		return nil, errNotFound
+	}
+
+	return &empty.Empty{}, nil
 }
 
 func (svc thingsServiceMock) Identify(context.Context, *mainflux.Token, ...grpc.CallOption) (*mainflux.ThingID, error) {
 	panic("not implemented")
 }
+
+func (svc thingsServiceMock) ChannelsByKey(context.Context, *mainflux.Token, ...grpc.CallOption) (*mainflux.ChannelIDs, error) {
+	panic("not implemented")
+}
@@ -0,0 +1,102 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mongodb
+
+import (
+	"context"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var aggregateFuncs = map[string]bson.M{
+	readers.AvgAggregation:   {"$avg": "$value"},
+	readers.MinAggregation:   {"$min": "$value"},
+	readers.MaxAggregation:   {"$max": "$value"},
+	readers.CountAggregation: {"$sum": 1},
+}
+
+type aggregate struct {
+	ID struct {
+		Time      float64 `bson:"time"`
+		Name      string  `bson:"name"`
+		Publisher string  `bson:"publisher"`
+	} `bson:"_id"`
+	Value float64 `bson:"value"`
+}
+
+func (repo mongoRepository) Aggregate(chanID string, rpm readers.PageMetadata) (readers.AggregatesPage, error) {
+	interval, err := readers.ParseInterval(rpm)
+	if err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	fn, ok := aggregateFuncs[rpm.Aggregation]
+	if !ok {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, readers.ErrInvalidAggregation)
+	}
+
+	pipeline := []bson.M{{"$match": fmtCondition(chanID, rpm)}}
+	if rpm.Aggregation != readers.CountAggregation {
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"value": bson.M{"$type": "number"}}})
+	}
+	// Messages are grouped by the start of the interval they belong to.
+	pipeline = append(pipeline, bson.M{"$group": bson.M{
+		"_id": bson.M{
+			"time":      bson.M{"$subtract": bson.A{"$time", bson.M{"$mod": bson.A{"$time", interval.Seconds()}}}},
+			"name":      "$name",
+			"publisher": "$publisher",
+		},
+		"value": fn,
+	}})
+
+	ctx := context.Background()
+	col := repo.db.Collection(defCollection)
+	page := append(pipeline[:len(pipeline):len(pipeline)],
+		bson.M{"$sort": bson.D{{Key: "_id.time", Value: fmtDir(rpm)}, {Key: "_id.name", Value: 1}, {Key: "_id.publisher", Value: 1}}},
+		bson.M{"$skip": int64(rpm.Offset)},
+		bson.M{"$limit": int64(rpm.Limit)},
+	)
+	cursor, err := col.Aggregate(ctx, page)
+	if err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	defer cursor.Close(ctx)
+
+	ret := readers.AggregatesPage{
+		PageMetadata: rpm,
+		Aggregates:   []readers.Aggregate{},
+	}
+	for cursor.Next(ctx) {
+		var agg aggregate
+		if err := cursor.Decode(&agg); err != nil {
+			return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+		}
+		ret.Aggregates = append(ret.Aggregates, readers.Aggregate{
+			Time:      agg.ID.Time,
+			Name:      agg.ID.Name,
+			Publisher: agg.ID.Publisher,
+			Value:     agg.Value,
+		})
+	}
+
+	count := append(pipeline[:len(pipeline):len(pipeline)], bson.M{"$count": "total"})
+	cursor, err = col.Aggregate(ctx, count)
+	if err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	defer cursor.Close(ctx)
+
+	if cursor.Next(ctx) {
+		var total struct {
+			Total int64 `bson:"total"`
+		}
+		if err := cursor.Decode(&total); err != nil {
+			return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+		}
+		ret.Total = uint64(total.Total)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,34 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mongodb
+
+import (
+	"context"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+var errDeleteMessages = errors.New("failed to delete messages from mongodb database")
+
+func (repo mongoRepository) Delete(chanID string, rpm readers.PageMetadata) (uint64, error) {
+	format := defCollection
+	if rpm.Format != "" {
+		format = rpm.Format
+	}
+
+	// Only the publisher and the time range are used to select the messages.
+	pm := readers.PageMetadata{
+		Format:    rpm.Format,
+		Publisher: rpm.Publisher,
+		From:      rpm.From,
+		To:        rpm.To,
+	}
+	res, err := repo.db.Collection(format).DeleteMany(context.Background(), fmtCondition(chanID, pm))
+	if err != nil {
+		return 0, errors.Wrap(errDeleteMessages, err)
+	}
+
+	return uint64(res.DeletedCount), nil
+}
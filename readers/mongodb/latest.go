@@ -0,0 +1,74 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mongodb
+
+import (
+	"context"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type latestPage struct {
+	Messages []senml.Message `bson:"messages"`
+	Total    []struct {
+		Total int64 `bson:"total"`
+	} `bson:"total"`
+}
+
+func (repo mongoRepository) ReadLatest(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	// Messages of each pair are sorted from the newest one, so the first
+	// message of the group is the latest one. The facet counts the pairs and
+	// returns the page of them in a single query.
+	pipeline := []bson.M{
+		{"$match": fmtCondition(chanID, rpm)},
+		{"$sort": bson.D{{Key: "publisher", Value: 1}, {Key: "name", Value: 1}, {Key: "time", Value: -1}}},
+		{"$group": bson.M{
+			"_id": bson.M{"publisher": "$publisher", "name": "$name"},
+			"msg": bson.M{"$first": "$$ROOT"},
+		}},
+		{"$replaceRoot": bson.M{"newRoot": "$msg"}},
+		{"$sort": bson.D{{Key: "publisher", Value: 1}, {Key: "name", Value: 1}}},
+		{"$facet": bson.M{
+			"messages": []bson.M{
+				{"$skip": int64(rpm.Offset)},
+				{"$limit": int64(rpm.Limit)},
+			},
+			"total": []bson.M{
+				{"$count": "total"},
+			},
+		}},
+	}
+
+	ctx := context.Background()
+	cursor, err := repo.db.Collection(defCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	defer cursor.Close(ctx)
+
+	page := readers.MessagesPage{
+		PageMetadata: rpm,
+		Messages:     []readers.Message{},
+	}
+	if cursor.Next(ctx) {
+		var lp latestPage
+		if err := cursor.Decode(&lp); err != nil {
+			return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+		}
+		for _, m := range lp.Messages {
+			page.Messages = append(page.Messages, m)
+		}
+		if len(lp.Total) > 0 {
+			page.Total = uint64(lp.Total[0].Total)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+
+	return page, nil
+}
@@ -48,7 +48,7 @@ func (repo mongoRepository) ReadAll(chanID string, rpm readers.PageMetadata) (re
 	col := repo.db.Collection(format)
 
 	sortMap := map[string]interface{}{
-		order: -1,
+		order: fmtDir(rpm),
 	}
 	// Remove format filter and format the rest properly.
 	filter := fmtCondition(chanID, rpm)
@@ -95,6 +95,14 @@ func (repo mongoRepository) ReadAll(chanID string, rpm readers.PageMetadata) (re
 	return mp, nil
 }
 
+// fmtDir returns the time sort direction of the page metadata.
+func fmtDir(rpm readers.PageMetadata) int {
+	if rpm.Dir == readers.AscDir {
+		return 1
+	}
+	return -1
+}
+
 func fmtCondition(chanID string, rpm readers.PageMetadata) bson.D {
 	filter := bson.D{
 		bson.E{
@@ -110,6 +118,12 @@ func fmtCondition(chanID string, rpm readers.PageMetadata) bson.D {
 	}
 	json.Unmarshal(meta, &query)
 
+	// JSON messages are filtered by the creation time in nanoseconds.
+	timeField, timeUnit := "time", 1.0
+	if rpm.Format != "" && rpm.Format != defCollection {
+		timeField, timeUnit = "created", 1e9
+	}
+
 	for name, value := range query {
 		switch name {
 		case
@@ -144,9 +158,13 @@ func fmtCondition(chanID string, rpm readers.PageMetadata) bson.D {
 		case "vd":
 			filter = append(filter, bson.E{Key: "data_value", Value: value})
 		case "from":
-			filter = append(filter, bson.E{Key: "time", Value: bson.M{"$gte": value}})
+			filter = append(filter, bson.E{Key: timeField, Value: bson.M{"$gte": value.(float64) * timeUnit}})
 		case "to":
-			filter = append(filter, bson.E{Key: "time", Value: bson.M{"$lt": value}})
+			filter = append(filter, bson.E{Key: timeField, Value: bson.M{"$lt": value.(float64) * timeUnit}})
+		case "payload":
+			for key, val := range rpm.Payload {
+				filter = append(filter, bson.E{Key: "payload." + key, Value: readers.PayloadValue(val)})
+			}
 		}
 	}
 
@@ -0,0 +1,65 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mongodb
+
+import (
+	"context"
+	"sort"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type summary struct {
+	Count      uint64   `bson:"count"`
+	First      float64  `bson:"first"`
+	Last       float64  `bson:"last"`
+	Publishers []string `bson:"publishers"`
+}
+
+func (repo mongoRepository) Summarize(chanID string, rpm readers.PageMetadata) (readers.Summary, error) {
+	format, timeField, timeUnit := defCollection, "$time", 1.0
+	if rpm.Format != "" && rpm.Format != defCollection {
+		format, timeField, timeUnit = rpm.Format, "$created", 1e9
+	}
+
+	pipeline := []bson.M{
+		{"$match": fmtCondition(chanID, rpm)},
+		{"$group": bson.M{
+			"_id":        nil,
+			"count":      bson.M{"$sum": 1},
+			"first":      bson.M{"$min": timeField},
+			"last":       bson.M{"$max": timeField},
+			"publishers": bson.M{"$addToSet": "$publisher"},
+		}},
+	}
+
+	ctx := context.Background()
+	cursor, err := repo.db.Collection(format).Aggregate(ctx, pipeline)
+	if err != nil {
+		return readers.Summary{}, errors.Wrap(errReadMessages, err)
+	}
+	defer cursor.Close(ctx)
+
+	ret := readers.Summary{Publishers: []string{}}
+	if cursor.Next(ctx) {
+		var s summary
+		if err := cursor.Decode(&s); err != nil {
+			return readers.Summary{}, errors.Wrap(errReadMessages, err)
+		}
+		sort.Strings(s.Publishers)
+		ret = readers.Summary{
+			Count:      s.Count,
+			First:      s.First / timeUnit,
+			Last:       s.Last / timeUnit,
+			Publishers: append(ret.Publishers, s.Publishers...),
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return readers.Summary{}, errors.Wrap(errReadMessages, err)
+	}
+
+	return ret, nil
+}
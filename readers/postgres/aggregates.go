@@ -0,0 +1,95 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+var aggregateFuncs = map[string]string{
+	readers.AvgAggregation:   "AVG(value)",
+	readers.MinAggregation:   "MIN(value)",
+	readers.MaxAggregation:   "MAX(value)",
+	readers.CountAggregation: "COUNT(*)",
+}
+
+func (tr postgresRepository) Aggregate(chanID string, rpm readers.PageMetadata) (readers.AggregatesPage, error) {
+	interval, err := readers.ParseInterval(rpm)
+	if err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	fn, ok := aggregateFuncs[rpm.Aggregation]
+	if !ok {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, readers.ErrInvalidAggregation)
+	}
+
+	condition := fmtCondition(chanID, "time", rpm)
+	if rpm.Aggregation != readers.CountAggregation {
+		condition = fmt.Sprintf(`%s AND value IS NOT NULL`, condition)
+	}
+
+	params := map[string]interface{}{
+		"channel":      chanID,
+		"limit":        rpm.Limit,
+		"offset":       rpm.Offset,
+		"interval":     interval.Seconds(),
+		"subtopic":     rpm.Subtopic,
+		"publisher":    rpm.Publisher,
+		"name":         rpm.Name,
+		"protocol":     rpm.Protocol,
+		"value":        rpm.Value,
+		"bool_value":   rpm.BoolValue,
+		"string_value": rpm.StringValue,
+		"data_value":   rpm.DataValue,
+		"from":         rpm.From,
+		"to":           rpm.To,
+	}
+
+	q := fmt.Sprintf(`SELECT FLOOR(time / :interval) * :interval AS bucket, name, publisher, %s AS value
+	FROM %s WHERE %s
+	GROUP BY bucket, name, publisher
+	ORDER BY bucket %s, name, publisher
+	LIMIT :limit OFFSET :offset;`, fn, defTable, condition, fmtDir(rpm))
+
+	rows, err := tr.db.NamedQuery(q, params)
+	if err != nil {
+		if e, ok := err.(*pq.Error); ok && e.Code == undefinedTableCode {
+			return readers.AggregatesPage{}, nil
+		}
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	defer rows.Close()
+
+	page := readers.AggregatesPage{
+		PageMetadata: rpm,
+		Aggregates:   []readers.Aggregate{},
+	}
+	for rows.Next() {
+		var agg readers.Aggregate
+		if err := rows.Scan(&agg.Time, &agg.Name, &agg.Publisher, &agg.Value); err != nil {
+			return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+		}
+		page.Aggregates = append(page.Aggregates, agg)
+	}
+
+	q = fmt.Sprintf(`SELECT COUNT(*) FROM (SELECT 1 FROM %s WHERE %s
+	GROUP BY FLOOR(time / :interval), name, publisher) AS groups;`, defTable, condition)
+	rows, err = tr.db.NamedQuery(q, params)
+	if err != nil {
+		return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&page.Total); err != nil {
+			return readers.AggregatesPage{}, errors.Wrap(errReadMessages, err)
+		}
+	}
+
+	return page, nil
+}
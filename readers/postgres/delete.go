@@ -0,0 +1,55 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+var errDeleteMessages = errors.New("failed to delete messages from postgres database")
+
+func (tr postgresRepository) Delete(chanID string, rpm readers.PageMetadata) (uint64, error) {
+	table, timeColumn := defTable, "time"
+	if rpm.Format != "" && rpm.Format != defTable {
+		table, timeColumn = rpm.Format, "created"
+	}
+
+	// Only the publisher and the time range are used to select the messages.
+	pm := readers.PageMetadata{
+		Publisher: rpm.Publisher,
+		From:      rpm.From,
+		To:        rpm.To,
+	}
+	params := map[string]interface{}{
+		"channel":   chanID,
+		"publisher": pm.Publisher,
+		"from":      pm.From,
+		"to":        pm.To,
+	}
+	// JSON messages are filtered by the creation time in nanoseconds.
+	if table != defTable {
+		params["from"] = int64(pm.From * 1e9)
+		params["to"] = int64(pm.To * 1e9)
+	}
+
+	q := fmt.Sprintf(`DELETE FROM %s WHERE %s;`, pq.QuoteIdentifier(table), fmtCondition(chanID, timeColumn, pm))
+	res, err := tr.db.NamedExec(q, params)
+	if err != nil {
+		if e, ok := err.(*pq.Error); ok && e.Code == undefinedTableCode {
+			return 0, nil
+		}
+		return 0, errors.Wrap(errDeleteMessages, err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(errDeleteMessages, err)
+	}
+
+	return uint64(count), nil
+}
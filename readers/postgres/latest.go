@@ -0,0 +1,75 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+)
+
+func (tr postgresRepository) ReadLatest(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	condition := fmtCondition(chanID, "time", rpm)
+	params := map[string]interface{}{
+		"channel":      chanID,
+		"limit":        rpm.Limit,
+		"offset":       rpm.Offset,
+		"subtopic":     rpm.Subtopic,
+		"publisher":    rpm.Publisher,
+		"name":         rpm.Name,
+		"protocol":     rpm.Protocol,
+		"value":        rpm.Value,
+		"bool_value":   rpm.BoolValue,
+		"string_value": rpm.StringValue,
+		"data_value":   rpm.DataValue,
+		"from":         rpm.From,
+		"to":           rpm.To,
+	}
+
+	// DISTINCT ON keeps the first row of each pair, which is the latest one
+	// since the rows of the pair are sorted by time.
+	q := fmt.Sprintf(`SELECT DISTINCT ON (publisher, name) * FROM %s
+	WHERE %s ORDER BY publisher, name, time DESC
+	LIMIT :limit OFFSET :offset;`, defTable, condition)
+
+	rows, err := tr.db.NamedQuery(q, params)
+	if err != nil {
+		if e, ok := err.(*pq.Error); ok && e.Code == undefinedTableCode {
+			return readers.MessagesPage{}, nil
+		}
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	defer rows.Close()
+
+	page := readers.MessagesPage{
+		PageMetadata: rpm,
+		Messages:     []readers.Message{},
+	}
+	for rows.Next() {
+		msg := senmlMessage{Message: senml.Message{}}
+		if err := rows.StructScan(&msg); err != nil {
+			return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+		}
+		page.Messages = append(page.Messages, msg.Message)
+	}
+
+	q = fmt.Sprintf(`SELECT COUNT(*) FROM (SELECT DISTINCT publisher, name FROM %s
+	WHERE %s) AS pairs;`, defTable, condition)
+	rows, err = tr.db.NamedQuery(q, params)
+	if err != nil {
+		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&page.Total); err != nil {
+			return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
+		}
+	}
+
+	return page, nil
+}
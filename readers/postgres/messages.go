@@ -6,9 +6,11 @@ package postgres
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/jmoiron/sqlx" // required for DB access
 	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/pkg/compression"
 	"github.com/mainflux/mainflux/pkg/errors"
 	jsont "github.com/mainflux/mainflux/pkg/transformers/json"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
@@ -51,8 +53,8 @@ func (tr postgresRepository) ReadAll(chanID string, rpm readers.PageMetadata) (r
 	}
 
 	q := fmt.Sprintf(`SELECT * FROM %s
-    WHERE %s ORDER BY %s DESC
-	LIMIT :limit OFFSET :offset;`, format, fmtCondition(chanID, rpm), order)
+    WHERE %s ORDER BY %s %s
+	LIMIT :limit OFFSET :offset;`, format, fmtCondition(chanID, order, rpm), order, fmtDir(rpm))
 
 	params := map[string]interface{}{
 		"channel":      chanID,
@@ -69,6 +71,12 @@ func (tr postgresRepository) ReadAll(chanID string, rpm readers.PageMetadata) (r
 		"from":         rpm.From,
 		"to":           rpm.To,
 	}
+	// JSON messages are filtered by the creation time in nanoseconds.
+	if format != defTable {
+		params["from"] = int64(rpm.From * 1e9)
+		params["to"] = int64(rpm.To * 1e9)
+	}
+	fmtPayloadParams(rpm, params)
 
 	rows, err := tr.db.NamedQuery(q, params)
 	if err != nil {
@@ -111,7 +119,7 @@ func (tr postgresRepository) ReadAll(chanID string, rpm readers.PageMetadata) (r
 
 	}
 
-	q = fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s;`, format, fmtCondition(chanID, rpm))
+	q = fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s;`, format, fmtCondition(chanID, order, rpm))
 	rows, err = tr.db.NamedQuery(q, params)
 	if err != nil {
 		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
@@ -129,7 +137,9 @@ func (tr postgresRepository) ReadAll(chanID string, rpm readers.PageMetadata) (r
 	return page, nil
 }
 
-func fmtCondition(chanID string, rpm readers.PageMetadata) string {
+// fmtCondition returns the query condition of the page metadata, where the
+// time range is applied to the time column.
+func fmtCondition(chanID, timeColumn string, rpm readers.PageMetadata) string {
 	condition := `channel = :channel`
 
 	var query map[string]interface{}
@@ -157,14 +167,49 @@ func fmtCondition(chanID string, rpm readers.PageMetadata) string {
 		case "vd":
 			condition = fmt.Sprintf(`%s AND data_value = :data_value`, condition)
 		case "from":
-			condition = fmt.Sprintf(`%s AND time >= :from`, condition)
+			condition = fmt.Sprintf(`%s AND %s >= :from`, condition, timeColumn)
 		case "to":
-			condition = fmt.Sprintf(`%s AND time < :to`, condition)
+			condition = fmt.Sprintf(`%s AND %s < :to`, condition, timeColumn)
+		case "payload":
+			for i := range payloadKeys(rpm) {
+				condition = fmt.Sprintf(`%s AND payload -> :payload_key_%d = CAST(:payload_value_%d AS jsonb)`, condition, i, i)
+			}
 		}
 	}
 	return condition
 }
 
+// fmtPayloadParams adds the parameters of the payload field filters to the
+// query parameters. Filter values are compared as JSONB, so that numbers and
+// booleans match regardless of their formatting.
+func fmtPayloadParams(rpm readers.PageMetadata, params map[string]interface{}) {
+	for i, key := range payloadKeys(rpm) {
+		// Numbers, booleans and strings are always encoded successfully.
+		value, _ := json.Marshal(readers.PayloadValue(rpm.Payload[key]))
+		params[fmt.Sprintf("payload_key_%d", i)] = key
+		params[fmt.Sprintf("payload_value_%d", i)] = string(value)
+	}
+}
+
+// payloadKeys returns the sorted keys of the payload field filters, so that
+// the conditions and the parameters are numbered the same way.
+func payloadKeys(rpm readers.PageMetadata) []string {
+	var keys []string
+	for key := range rpm.Payload {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fmtDir returns the time order direction of the page metadata.
+func fmtDir(rpm readers.PageMetadata) string {
+	if rpm.Dir == readers.AscDir {
+		return "ASC"
+	}
+	return "DESC"
+}
+
 type senmlMessage struct {
 	ID string `db:"id"`
 	senml.Message
@@ -190,8 +235,13 @@ func (msg jsonMessage) toMap() (map[string]interface{}, error) {
 		"protocol":  msg.Protocol,
 		"payload":   map[string]interface{}{},
 	}
+	// Payload is compressed if the writer compression is enabled.
+	data, err := compression.Decompress(msg.Payload)
+	if err != nil {
+		return nil, err
+	}
 	pld := make(map[string]interface{})
-	if err := json.Unmarshal(msg.Payload, &pld); err != nil {
+	if err := json.Unmarshal(data, &pld); err != nil {
 		return nil, err
 	}
 	ret["payload"] = pld
@@ -5,6 +5,7 @@ package postgres_test
 
 import (
 	"fmt"
+	"sort"
 	"testing"
 	"time"
 
@@ -43,7 +44,7 @@ var (
 )
 
 func TestReadSenml(t *testing.T) {
-	writer := pwriter.New(db)
+	writer := pwriter.New(db, "", "")
 
 	chanID, err := idProvider.ID()
 	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
@@ -144,6 +145,18 @@ func TestReadSenml(t *testing.T) {
 				Messages: fromSenml(messages[msgsNum-20 : msgsNum]),
 			},
 		},
+		"read message first page in ascending order": {
+			chanID: chanID,
+			pageMeta: readers.PageMetadata{
+				Offset: 0,
+				Limit:  20,
+				Dir:    readers.AscDir,
+			},
+			page: readers.MessagesPage{
+				Total:    msgsNum,
+				Messages: fromSenml(messages[msgsNum-20 : msgsNum]),
+			},
+		},
 		"read message with non-existent subtopic": {
 			chanID: chanID,
 			pageMeta: readers.PageMetadata{
@@ -376,15 +389,302 @@ func TestReadSenml(t *testing.T) {
 	}
 }
 
+func TestAggregate(t *testing.T) {
+	writer := pwriter.New(db, "", "")
+
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages span two hours, with values from 1 to 4 in each of them.
+	hour := float64(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC).Unix())
+	var msgs []senml.Message
+	for i := 0; i < 8; i++ {
+		val := float64(i%4 + 1)
+		msgs = append(msgs, senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      hour + float64(i*900),
+			Value:     &val,
+		})
+	}
+	err = writer.Consume(msgs)
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := preader.New(db)
+	aggregate := func(start, v float64) readers.Aggregate {
+		return readers.Aggregate{Time: start, Name: msgName, Publisher: pubID, Value: v}
+	}
+
+	cases := map[string]struct {
+		pageMeta readers.PageMetadata
+		page     readers.AggregatesPage
+	}{
+		"aggregate average by hour": {
+			pageMeta: readers.PageMetadata{Limit: limit, Aggregation: readers.AvgAggregation, Interval: "1h"},
+			page: readers.AggregatesPage{
+				Total:      2,
+				Aggregates: []readers.Aggregate{aggregate(hour+3600, 2.5), aggregate(hour, 2.5)},
+			},
+		},
+		"aggregate maximum by two hours": {
+			pageMeta: readers.PageMetadata{Limit: limit, Aggregation: readers.MaxAggregation, Interval: "2h"},
+			page: readers.AggregatesPage{
+				Total:      1,
+				Aggregates: []readers.Aggregate{aggregate(hour, 4)},
+			},
+		},
+		"aggregate count with time range and offset": {
+			pageMeta: readers.PageMetadata{Offset: 1, Limit: limit, Aggregation: readers.CountAggregation, Interval: "1h", From: hour + 1800},
+			page: readers.AggregatesPage{
+				Total:      2,
+				Aggregates: []readers.Aggregate{aggregate(hour, 2)},
+			},
+		},
+	}
+
+	for desc, tc := range cases {
+		result, err := reader.Aggregate(chanID, tc.pageMeta)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", desc, err))
+		assert.Equal(t, tc.page.Total, result.Total, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Total, result.Total))
+		assert.Equal(t, tc.page.Aggregates, result.Aggregates, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Aggregates, result.Aggregates))
+	}
+}
+
+func TestSummary(t *testing.T) {
+	writer := pwriter.New(db, "", "")
+
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages are published every minute, alternately by two publishers.
+	start := float64(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC).Unix())
+	var msgs []senml.Message
+	for i := 0; i < 10; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      start + float64(i*60),
+			Value:     &v,
+		}
+		if i%2 == 1 {
+			msg.Publisher = pubID2
+			msg.Protocol = httpProt
+		}
+		msgs = append(msgs, msg)
+	}
+	err = writer.Consume(msgs)
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := preader.New(db)
+	publishers := []string{pubID, pubID2}
+	sort.Strings(publishers)
+
+	cases := map[string]struct {
+		pageMeta readers.PageMetadata
+		summary  readers.Summary
+	}{
+		"summarize all messages": {
+			pageMeta: readers.PageMetadata{Limit: limit},
+			summary:  readers.Summary{Count: 10, First: start, Last: start + 540, Publishers: publishers},
+		},
+		"summarize messages by publisher": {
+			pageMeta: readers.PageMetadata{Limit: limit, Publisher: pubID2},
+			summary:  readers.Summary{Count: 5, First: start + 60, Last: start + 540, Publishers: []string{pubID2}},
+		},
+		"summarize messages by protocol with time range": {
+			pageMeta: readers.PageMetadata{Limit: limit, Protocol: mqttProt, From: start + 60, To: start + 300},
+			summary:  readers.Summary{Count: 2, First: start + 120, Last: start + 240, Publishers: []string{pubID}},
+		},
+		"summarize messages without matches": {
+			pageMeta: readers.PageMetadata{Limit: limit, Name: "wrong"},
+			summary:  readers.Summary{Publishers: []string{}},
+		},
+	}
+
+	for desc, tc := range cases {
+		summary, err := reader.Summarize(chanID, tc.pageMeta)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", desc, err))
+		assert.Equal(t, tc.summary, summary, fmt.Sprintf("%s: expected %v got %v", desc, tc.summary, summary))
+	}
+}
+
+func TestReadLatest(t *testing.T) {
+	writer := pwriter.New(db, "", "")
+
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages are published every minute, alternately by two publishers,
+	// and every other pair of them is named differently.
+	start := float64(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC).Unix())
+	var msgs []senml.Message
+	for i := 0; i < 10; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      start + float64(i*60),
+			Value:     &v,
+		}
+		if i%2 == 1 {
+			msg.Publisher = pubID2
+		}
+		if i%4 > 1 {
+			msg.Name = "humidity"
+		}
+		msgs = append(msgs, msg)
+	}
+	err = writer.Consume(msgs)
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := preader.New(db)
+
+	// Latest messages are sorted by publisher and name.
+	latest := func(msgs ...senml.Message) []readers.Message {
+		sort.Slice(msgs, func(i, j int) bool {
+			if msgs[i].Publisher != msgs[j].Publisher {
+				return msgs[i].Publisher < msgs[j].Publisher
+			}
+			return msgs[i].Name < msgs[j].Name
+		})
+		return fromSenml(msgs)
+	}
+
+	cases := map[string]struct {
+		pageMeta readers.PageMetadata
+		page     readers.MessagesPage
+	}{
+		"read latest messages": {
+			pageMeta: readers.PageMetadata{Limit: limit},
+			page:     readers.MessagesPage{Total: 4, Messages: latest(msgs[6], msgs[7], msgs[8], msgs[9])},
+		},
+		"read latest messages page": {
+			pageMeta: readers.PageMetadata{Offset: 1, Limit: 2},
+			page:     readers.MessagesPage{Total: 4, Messages: latest(msgs[6], msgs[7], msgs[8], msgs[9])[1:3]},
+		},
+		"read latest messages by name": {
+			pageMeta: readers.PageMetadata{Limit: limit, Name: "humidity"},
+			page:     readers.MessagesPage{Total: 2, Messages: latest(msgs[6], msgs[7])},
+		},
+		"read latest messages with time range": {
+			pageMeta: readers.PageMetadata{Limit: limit, To: start + 360},
+			page:     readers.MessagesPage{Total: 4, Messages: latest(msgs[2], msgs[3], msgs[4], msgs[5])},
+		},
+		"read latest messages without matches": {
+			pageMeta: readers.PageMetadata{Limit: limit, Name: "wrong"},
+			page:     readers.MessagesPage{Messages: []readers.Message{}},
+		},
+	}
+
+	for desc, tc := range cases {
+		page, err := reader.ReadLatest(chanID, tc.pageMeta)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", desc, err))
+		assert.Equal(t, tc.page.Total, page.Total, fmt.Sprintf("%s: expected %d got %d", desc, tc.page.Total, page.Total))
+		assert.Equal(t, tc.page.Messages, page.Messages, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Messages, page.Messages))
+	}
+}
+
+func TestDelete(t *testing.T) {
+	writer := pwriter.New(db, "", "")
+
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages are published every minute, alternately by two publishers.
+	start := float64(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC).Unix())
+	var msgs []senml.Message
+	for i := 0; i < 10; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      start + float64(i*60),
+			Value:     &v,
+		}
+		if i%2 == 1 {
+			msg.Publisher = pubID2
+		}
+		msgs = append(msgs, msg)
+	}
+	err = writer.Consume(msgs)
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := preader.New(db)
+
+	// Cases are ordered, since each of them deletes the messages.
+	cases := []struct {
+		desc     string
+		pageMeta readers.PageMetadata
+		count    uint64
+		total    uint64
+	}{
+		{
+			desc:     "delete messages of publisher within time range",
+			pageMeta: readers.PageMetadata{Publisher: pubID2, From: start, To: start + 300},
+			count:    2,
+			total:    8,
+		},
+		{
+			desc:     "delete messages of publisher",
+			pageMeta: readers.PageMetadata{Publisher: pubID2},
+			count:    3,
+			total:    5,
+		},
+		{
+			desc:     "delete all messages",
+			pageMeta: readers.PageMetadata{},
+			count:    5,
+			total:    0,
+		},
+		{
+			desc:     "delete messages of empty channel",
+			pageMeta: readers.PageMetadata{},
+			count:    0,
+			total:    0,
+		},
+	}
+
+	for _, tc := range cases {
+		count, err := reader.Delete(chanID, tc.pageMeta)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", tc.desc, err))
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.count, count))
+
+		page, err := reader.ReadAll(chanID, readers.PageMetadata{Limit: limit})
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", tc.desc, err))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected %d remaining messages got %d", tc.desc, tc.total, page.Total))
+	}
+}
+
 func TestReadJSON(t *testing.T) {
-	writer := pwriter.New(db)
+	writer := pwriter.New(db, "", "")
 
+	created := time.Now().UnixNano()
 	id1, err := idProvider.ID()
 	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
 	m := json.Message{
 		Channel:   id1,
 		Publisher: id1,
-		Created:   time.Now().Unix(),
+		Created:   created,
 		Subtopic:  "subtopic/format/some_json",
 		Protocol:  "coap",
 		Payload: map[string]interface{}{
@@ -501,6 +801,57 @@ func TestReadJSON(t *testing.T) {
 				Messages: fromJSON(httpMsgs),
 			},
 		},
+		"read message with payload fields": {
+			chanID: id2,
+			pageMeta: readers.PageMetadata{
+				Format:  messages2.Format,
+				Offset:  0,
+				Limit:   msgsNum,
+				Payload: map[string]string{"field_1": "other_value", "false_value": "false", "field_pi": "3.14159265"},
+			},
+			page: readers.MessagesPage{
+				Total:    msgsNum,
+				Messages: fromJSON(msgs2),
+			},
+		},
+		"read message with non-matching payload field": {
+			chanID: id2,
+			pageMeta: readers.PageMetadata{
+				Format:  messages2.Format,
+				Offset:  0,
+				Limit:   msgsNum,
+				Payload: map[string]string{"field_pi": "3"},
+			},
+			page: readers.MessagesPage{
+				Messages: []readers.Message{},
+			},
+		},
+		"read message with from/to": {
+			chanID: id1,
+			pageMeta: readers.PageMetadata{
+				Format: messages1.Format,
+				Offset: 0,
+				Limit:  10,
+				From:   float64(created/1e9 - 1),
+				To:     float64(created/1e9 + 1),
+			},
+			page: readers.MessagesPage{
+				Total:    100,
+				Messages: fromJSON(msgs1[:10]),
+			},
+		},
+		"read message with to before creation": {
+			chanID: id1,
+			pageMeta: readers.PageMetadata{
+				Format: messages1.Format,
+				Offset: 0,
+				Limit:  10,
+				To:     float64(created/1e9 - 1),
+			},
+			page: readers.MessagesPage{
+				Messages: []readers.Message{},
+			},
+		},
 	}
 
 	for desc, tc := range cases {
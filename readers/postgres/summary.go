@@ -0,0 +1,75 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/readers"
+)
+
+func (tr postgresRepository) Summarize(chanID string, rpm readers.PageMetadata) (readers.Summary, error) {
+	table, timeColumn, scale := defTable, "time", ""
+	if rpm.Format != "" && rpm.Format != defTable {
+		table, timeColumn, scale = rpm.Format, "created", " / 1e9"
+	}
+
+	params := map[string]interface{}{
+		"channel":      chanID,
+		"subtopic":     rpm.Subtopic,
+		"publisher":    rpm.Publisher,
+		"name":         rpm.Name,
+		"protocol":     rpm.Protocol,
+		"value":        rpm.Value,
+		"bool_value":   rpm.BoolValue,
+		"string_value": rpm.StringValue,
+		"data_value":   rpm.DataValue,
+		"from":         rpm.From,
+		"to":           rpm.To,
+	}
+	// JSON messages are filtered by the creation time in nanoseconds.
+	if table != defTable {
+		params["from"] = int64(rpm.From * 1e9)
+		params["to"] = int64(rpm.To * 1e9)
+	}
+	fmtPayloadParams(rpm, params)
+	condition := fmtCondition(chanID, timeColumn, rpm)
+
+	q := fmt.Sprintf(`SELECT COUNT(*), COALESCE(MIN(%s), 0)%s, COALESCE(MAX(%s), 0)%s
+	FROM %s WHERE %s;`, timeColumn, scale, timeColumn, scale, table, condition)
+	rows, err := tr.db.NamedQuery(q, params)
+	if err != nil {
+		if e, ok := err.(*pq.Error); ok && e.Code == undefinedTableCode {
+			return readers.Summary{Publishers: []string{}}, nil
+		}
+		return readers.Summary{}, errors.Wrap(errReadMessages, err)
+	}
+	defer rows.Close()
+
+	summary := readers.Summary{Publishers: []string{}}
+	if rows.Next() {
+		if err := rows.Scan(&summary.Count, &summary.First, &summary.Last); err != nil {
+			return readers.Summary{}, errors.Wrap(errReadMessages, err)
+		}
+	}
+
+	q = fmt.Sprintf(`SELECT DISTINCT publisher FROM %s WHERE %s ORDER BY publisher;`, table, condition)
+	rows, err = tr.db.NamedQuery(q, params)
+	if err != nil {
+		return readers.Summary{}, errors.Wrap(errReadMessages, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publisher string
+		if err := rows.Scan(&publisher); err != nil {
+			return readers.Summary{}, errors.Wrap(errReadMessages, err)
+		}
+		summary.Publishers = append(summary.Publishers, publisher)
+	}
+
+	return summary, nil
+}
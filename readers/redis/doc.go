@@ -0,0 +1,7 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redis contains the message repository middleware which sends the
+// message removal events to Redis event store, so that the removals can be
+// audited.
+package redis
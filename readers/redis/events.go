@@ -0,0 +1,52 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import "strconv"
+
+const (
+	messagesPrefix = "messages."
+	messagesRemove = messagesPrefix + "remove"
+)
+
+type event interface {
+	Encode() map[string]interface{}
+}
+
+var _ event = (*removeMessagesEvent)(nil)
+
+type removeMessagesEvent struct {
+	channel   string
+	format    string
+	publisher string
+	from      float64
+	to        float64
+	count     uint64
+}
+
+func (rme removeMessagesEvent) Encode() map[string]interface{} {
+	val := map[string]interface{}{
+		"channel":   rme.channel,
+		"count":     rme.count,
+		"operation": messagesRemove,
+	}
+
+	if rme.format != "" {
+		val["format"] = rme.format
+	}
+
+	if rme.publisher != "" {
+		val["publisher"] = rme.publisher
+	}
+
+	if rme.from != 0 {
+		val["from"] = strconv.FormatFloat(rme.from, 'f', -1, 64)
+	}
+
+	if rme.to != 0 {
+		val["to"] = strconv.FormatFloat(rme.to, 'f', -1, 64)
+	}
+
+	return val
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/readers"
+)
+
+const (
+	streamID  = "mainflux.readers"
+	streamLen = 1000
+)
+
+var _ readers.MessageRepository = (*eventStore)(nil)
+
+type eventStore struct {
+	svc    readers.MessageRepository
+	client *redis.Client
+}
+
+// NewEventStoreMiddleware returns wrapper around message repository that sends
+// message removal events to event store.
+func NewEventStoreMiddleware(svc readers.MessageRepository, client *redis.Client) readers.MessageRepository {
+	return eventStore{
+		svc:    svc,
+		client: client,
+	}
+}
+
+func (es eventStore) ReadAll(chanID string, pm readers.PageMetadata) (readers.MessagesPage, error) {
+	return es.svc.ReadAll(chanID, pm)
+}
+
+func (es eventStore) Aggregate(chanID string, pm readers.PageMetadata) (readers.AggregatesPage, error) {
+	return es.svc.Aggregate(chanID, pm)
+}
+
+func (es eventStore) Summarize(chanID string, pm readers.PageMetadata) (readers.Summary, error) {
+	return es.svc.Summarize(chanID, pm)
+}
+
+func (es eventStore) ReadLatest(chanID string, pm readers.PageMetadata) (readers.MessagesPage, error) {
+	return es.svc.ReadLatest(chanID, pm)
+}
+
+func (es eventStore) Delete(chanID string, pm readers.PageMetadata) (uint64, error) {
+	count, err := es.svc.Delete(chanID, pm)
+	if err != nil {
+		return count, err
+	}
+
+	event := removeMessagesEvent{
+		channel:   chanID,
+		format:    pm.Format,
+		publisher: pm.Publisher,
+		from:      pm.From,
+		to:        pm.To,
+		count:     count,
+	}
+	record := &redis.XAddArgs{
+		Stream:       streamID,
+		MaxLenApprox: streamLen,
+		Values:       event.Encode(),
+	}
+	es.client.XAdd(context.Background(), record).Err()
+
+	return count, nil
+}
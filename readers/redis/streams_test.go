@@ -0,0 +1,115 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	r "github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/pkg/uuid"
+	"github.com/mainflux/mainflux/readers"
+	"github.com/mainflux/mainflux/readers/mocks"
+	"github.com/mainflux/mainflux/readers/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	streamID       = "mainflux.readers"
+	messagesRemove = "messages.remove"
+)
+
+func TestDelete(t *testing.T) {
+	_ = redisClient.FlushAll(context.Background()).Err()
+
+	idProvider := uuid.New()
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	var msgs []readers.Message
+	for i := 0; i < 10; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Time:      float64(1633046400 + i),
+		}
+		if i%2 == 1 {
+			msg.Publisher = pubID2
+		}
+		msgs = append(msgs, msg)
+	}
+	repo := redis.NewEventStoreMiddleware(mocks.NewMessageRepository(chanID, msgs), redisClient)
+
+	cases := []struct {
+		desc     string
+		pageMeta readers.PageMetadata
+		count    uint64
+		event    map[string]interface{}
+	}{
+		{
+			desc:     "delete messages of publisher within time range",
+			pageMeta: readers.PageMetadata{Publisher: pubID, From: 1633046400, To: 1633046404},
+			count:    2,
+			event: map[string]interface{}{
+				"channel":   chanID,
+				"publisher": pubID,
+				"from":      "1633046400",
+				"to":        "1633046404",
+				"count":     "2",
+				"operation": messagesRemove,
+			},
+		},
+		{
+			desc:     "delete all messages of channel",
+			pageMeta: readers.PageMetadata{},
+			count:    8,
+			event: map[string]interface{}{
+				"channel":   chanID,
+				"count":     "8",
+				"operation": messagesRemove,
+			},
+		},
+		{
+			desc:     "delete messages of empty channel",
+			pageMeta: readers.PageMetadata{Format: "messages"},
+			count:    0,
+			event: map[string]interface{}{
+				"channel":   chanID,
+				"format":    "messages",
+				"count":     "0",
+				"operation": messagesRemove,
+			},
+		},
+	}
+
+	lastID := "0"
+	for _, tc := range cases {
+		count, err := repo.Delete(chanID, tc.pageMeta)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s\n", tc.desc, err))
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d\n", tc.desc, tc.count, count))
+
+		streams := redisClient.XRead(context.Background(), &r.XReadArgs{
+			Streams: []string{streamID, lastID},
+			Count:   1,
+			Block:   time.Second,
+		}).Val()
+
+		var event map[string]interface{}
+		if len(streams) > 0 && len(streams[0].Messages) > 0 {
+			msg := streams[0].Messages[0]
+			event = msg.Values
+			lastID = msg.ID
+		}
+
+		assert.Equal(t, tc.event, event, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.event, event))
+	}
+}
@@ -24,6 +24,7 @@ type grpcClient struct {
 	canAccessByID  endpoint.Endpoint
 	isChannelOwner endpoint.Endpoint
 	identify       endpoint.Endpoint
+	channelsByKey  endpoint.Endpoint
 }
 
 // NewClient returns new gRPC client instance.
@@ -64,6 +65,14 @@ func NewClient(conn *grpc.ClientConn, tracer opentracing.Tracer, timeout time.Du
 			decodeIdentityResponse,
 			mainflux.ThingID{},
 		).Endpoint()),
+		channelsByKey: kitot.TraceClient(tracer, "channels_by_key")(kitgrpc.NewClient(
+			conn,
+			svcName,
+			"ChannelsByKey",
+			encodeIdentifyRequest,
+			decodeChannelIDsResponse,
+			mainflux.ChannelIDs{},
+		).Endpoint()),
 	}
 }
 
@@ -119,6 +128,19 @@ func (client grpcClient) Identify(ctx context.Context, req *mainflux.Token, _ ..
 	return &mainflux.ThingID{Value: ir.id}, nil
 }
 
+func (client grpcClient) ChannelsByKey(ctx context.Context, req *mainflux.Token, _ ...grpc.CallOption) (*mainflux.ChannelIDs, error) {
+	ctx, cancel := context.WithTimeout(ctx, client.timeout)
+	defer cancel()
+
+	res, err := client.channelsByKey(ctx, identifyReq{key: req.GetValue()})
+	if err != nil {
+		return nil, err
+	}
+
+	cr := res.(channelIDsRes)
+	return &mainflux.ChannelIDs{Value: cr.ids}, nil
+}
+
 func encodeCanAccessByKeyRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(AccessByKeyReq)
 	return &mainflux.AccessByKeyReq{Token: req.thingKey, ChanID: req.chanID}, nil
@@ -144,6 +166,11 @@ func decodeIdentityResponse(_ context.Context, grpcRes interface{}) (interface{}
 	return identityRes{id: res.GetValue()}, nil
 }
 
+func decodeChannelIDsResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
+	res := grpcRes.(*mainflux.ChannelIDs)
+	return channelIDsRes{ids: res.GetValue()}, nil
+}
+
 func decodeEmptyResponse(_ context.Context, _ interface{}) (interface{}, error) {
 	return emptyRes{}, nil
 }
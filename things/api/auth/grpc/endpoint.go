@@ -62,3 +62,18 @@ func identifyEndpoint(svc things.Service) endpoint.Endpoint {
 		return identityRes{id: id}, nil
 	}
 }
+
+func channelsByKeyEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(identifyReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		ids, err := svc.ChannelsByKey(ctx, req.key)
+		if err != nil {
+			return channelIDsRes{}, err
+		}
+		return channelIDsRes{ids: ids}, nil
+	}
+}
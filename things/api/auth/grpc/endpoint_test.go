@@ -185,3 +185,58 @@ func TestIdentify(t *testing.T) {
 		assert.Equal(t, tc.code, e.Code(), fmt.Sprintf("%s: expected %s got %s", desc, tc.code, e.Code()))
 	}
 }
+
+func TestChannelsByKey(t *testing.T) {
+	ths, err := svc.CreateThings(context.Background(), token, thing, thing)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	th1 := ths[0]
+	th2 := ths[1]
+
+	chs, err := svc.CreateChannels(context.Background(), token, channel)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	ch := chs[0]
+	err = svc.Connect(context.Background(), token, []string{ch.ID}, []string{th1.ID})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	usersAddr := fmt.Sprintf("localhost:%d", port)
+	conn, err := grpc.Dial(usersAddr, grpc.WithInsecure())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	cli := grpcapi.NewClient(conn, mocktracer.New(), time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	cases := map[string]struct {
+		key  string
+		ids  []string
+		code codes.Code
+	}{
+		"list channels of connected thing": {
+			key:  th1.Key,
+			ids:  []string{ch.ID},
+			code: codes.OK,
+		},
+		"list channels of unconnected thing": {
+			key:  th2.Key,
+			ids:  nil,
+			code: codes.OK,
+		},
+		"list channels with wrong access key": {
+			key:  wrong,
+			ids:  nil,
+			code: codes.NotFound,
+		},
+		"list channels with empty access key": {
+			key:  "",
+			ids:  nil,
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for desc, tc := range cases {
+		res, err := cli.ChannelsByKey(ctx, &mainflux.Token{Value: tc.key})
+		e, ok := status.FromError(err)
+		assert.True(t, ok, "OK expected to be true")
+		assert.Equal(t, tc.ids, res.GetValue(), fmt.Sprintf("%s: expected %v got %v", desc, tc.ids, res.GetValue()))
+		assert.Equal(t, tc.code, e.Code(), fmt.Sprintf("%s: expected %s got %s", desc, tc.code, e.Code()))
+	}
+}
@@ -7,6 +7,10 @@ type identityRes struct {
 	id string
 }
 
+type channelIDsRes struct {
+	ids []string
+}
+
 type emptyRes struct {
 	err error
 }
@@ -10,6 +10,7 @@ import (
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/things"
 	opentracing "github.com/opentracing/opentracing-go"
 	"google.golang.org/grpc/codes"
@@ -23,6 +24,7 @@ type grpcServer struct {
 	canAccessByID  kitgrpc.Handler
 	isChannelOwner kitgrpc.Handler
 	identify       kitgrpc.Handler
+	channelsByKey  kitgrpc.Handler
 }
 
 // NewServer returns new ThingsServiceServer instance.
@@ -48,6 +50,11 @@ func NewServer(tracer opentracing.Tracer, svc things.Service) mainflux.ThingsSer
 			decodeIdentifyRequest,
 			encodeIdentityResponse,
 		),
+		channelsByKey: kitgrpc.NewServer(
+			kitot.TraceServer(tracer, "channels_by_key")(channelsByKeyEndpoint(svc)),
+			decodeIdentifyRequest,
+			encodeChannelIDsResponse,
+		),
 	}
 }
 
@@ -87,6 +94,15 @@ func (gs *grpcServer) Identify(ctx context.Context, req *mainflux.Token) (*mainf
 	return res.(*mainflux.ThingID), nil
 }
 
+func (gs *grpcServer) ChannelsByKey(ctx context.Context, req *mainflux.Token) (*mainflux.ChannelIDs, error) {
+	_, res, err := gs.channelsByKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+
+	return res.(*mainflux.ChannelIDs), nil
+}
+
 func decodeCanAccessByKeyRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(*mainflux.AccessByKeyReq)
 	return AccessByKeyReq{thingKey: req.GetToken(), chanID: req.GetChanID()}, nil
@@ -112,22 +128,27 @@ func encodeIdentityResponse(_ context.Context, grpcRes interface{}) (interface{}
 	return &mainflux.ThingID{Value: res.id}, nil
 }
 
+func encodeChannelIDsResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
+	res := grpcRes.(channelIDsRes)
+	return &mainflux.ChannelIDs{Value: res.ids}, nil
+}
+
 func encodeEmptyResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
 	res := grpcRes.(emptyRes)
 	return &empty.Empty{}, encodeError(res.err)
 }
 
 func encodeError(err error) error {
-	switch err {
-	case nil:
+	switch {
+	case err == nil:
 		return nil
-	case things.ErrMalformedEntity:
+	case errors.Contains(err, things.ErrMalformedEntity):
 		return status.Error(codes.InvalidArgument, "received invalid can access request")
-	case things.ErrUnauthorizedAccess:
+	case errors.Contains(err, things.ErrUnauthorizedAccess):
 		return status.Error(codes.PermissionDenied, "missing or invalid credentials provided")
-	case things.ErrEntityConnected:
+	case errors.Contains(err, things.ErrEntityConnected):
 		return status.Error(codes.PermissionDenied, "entities are not connected")
-	case things.ErrNotFound:
+	case errors.Contains(err, things.ErrNotFound):
 		return status.Error(codes.NotFound, "entity does not exist")
 	default:
 		return status.Error(codes.Internal, "internal server error")
@@ -201,6 +201,15 @@ func (ms *metricsMiddleware) Identify(ctx context.Context, key string) (string,
 	return ms.svc.Identify(ctx, key)
 }
 
+func (ms *metricsMiddleware) ChannelsByKey(ctx context.Context, key string) ([]string, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "channels_by_key").Add(1)
+		ms.latency.With("method", "channels_by_key").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ChannelsByKey(ctx, key)
+}
+
 func (ms *metricsMiddleware) ListMembers(ctx context.Context, token, groupID string, pm things.PageMetadata) (tp things.Page, err error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "list_members").Add(1)
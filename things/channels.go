@@ -45,6 +45,10 @@ type ChannelRepository interface {
 	// user and have specified thing connected or not connected to them.
 	RetrieveByThing(ctx context.Context, owner, thID string, pm PageMetadata) (ChannelsPage, error)
 
+	// RetrieveIDsByThing retrieves the identifiers of all the channels the
+	// specified thing is connected to.
+	RetrieveIDsByThing(ctx context.Context, thID string) ([]string, error)
+
 	// Remove removes the channel having the provided identifier, that is owned
 	// by the specified user.
 	Remove(ctx context.Context, owner, id string) error
@@ -6,6 +6,7 @@ package mocks
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -175,6 +176,16 @@ func (crm *channelRepositoryMock) RetrieveByThing(_ context.Context, owner, thID
 	return page, nil
 }
 
+func (crm *channelRepositoryMock) RetrieveIDsByThing(_ context.Context, thID string) ([]string, error) {
+	ids := []string{}
+	for id := range crm.cconns[thID] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
 func (crm *channelRepositoryMock) Remove(_ context.Context, owner, id string) error {
 	delete(crm.channels, key(owner, id))
 	// delete channel from any thing list
@@ -262,6 +262,31 @@ func (cr channelRepository) RetrieveByThing(ctx context.Context, owner, thID str
 	}, nil
 }
 
+func (cr channelRepository) RetrieveIDsByThing(ctx context.Context, thID string) ([]string, error) {
+	q := `SELECT channel_id FROM connections WHERE thing_id = :thing ORDER BY channel_id;`
+
+	params := map[string]interface{}{
+		"thing": thID,
+	}
+
+	rows, err := cr.db.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return nil, errors.Wrap(things.ErrSelectEntity, err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(things.ErrSelectEntity, err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
 func (cr channelRepository) Remove(ctx context.Context, owner, id string) error {
 	dbch := dbChannel{
 		ID:    id,
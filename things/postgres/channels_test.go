@@ -6,6 +6,7 @@ package postgres_test
 import (
 	"context"
 	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -870,6 +871,66 @@ func TestHasThingByID(t *testing.T) {
 	}
 }
 
+func TestRetrieveIDsByThing(t *testing.T) {
+	email := "channel-ids-by-thing@example.com"
+	dbMiddleware := postgres.NewDatabase(db)
+	thingRepo := postgres.NewThingRepository(dbMiddleware)
+	chanRepo := postgres.NewChannelRepository(dbMiddleware)
+
+	var thIDs []string
+	for i := 0; i < 2; i++ {
+		thID, err := idProvider.ID()
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+		thKey, err := idProvider.ID()
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+		_, err = thingRepo.Save(context.Background(), things.Thing{ID: thID, Owner: email, Key: thKey})
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+		thIDs = append(thIDs, thID)
+	}
+
+	var chIDs []string
+	for i := 0; i < 3; i++ {
+		chID, err := idProvider.ID()
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+		_, err = chanRepo.Save(context.Background(), things.Channel{ID: chID, Owner: email})
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+		chIDs = append(chIDs, chID)
+	}
+	sort.Strings(chIDs)
+
+	err := chanRepo.Connect(context.Background(), email, chIDs, thIDs[:1])
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	nonexistentThID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	cases := map[string]struct {
+		thID string
+		ids  []string
+	}{
+		"retrieve channel IDs of connected thing": {
+			thID: thIDs[0],
+			ids:  chIDs,
+		},
+		"retrieve channel IDs of disconnected thing": {
+			thID: thIDs[1],
+			ids:  []string{},
+		},
+		"retrieve channel IDs of non-existing thing": {
+			thID: nonexistentThID,
+			ids:  []string{},
+		},
+	}
+
+	for desc, tc := range cases {
+		ids, err := chanRepo.RetrieveIDsByThing(context.Background(), tc.thID)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", desc, err))
+		assert.Equal(t, tc.ids, ids, fmt.Sprintf("%s: expected %v got %v\n", desc, tc.ids, ids))
+	}
+}
+
 func testSortChannels(t *testing.T, pm things.PageMetadata, chs []things.Channel) {
 	switch pm.Order {
 	case "name":
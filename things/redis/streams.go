@@ -244,6 +244,10 @@ func (es eventStore) Identify(ctx context.Context, key string) (string, error) {
 	return es.svc.Identify(ctx, key)
 }
 
+func (es eventStore) ChannelsByKey(ctx context.Context, key string) ([]string, error) {
+	return es.svc.ChannelsByKey(ctx, key)
+}
+
 func (es eventStore) ListMembers(ctx context.Context, token, groupID string, pm things.PageMetadata) (things.Page, error) {
 	return es.svc.ListMembers(ctx, token, groupID, pm)
 }
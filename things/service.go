@@ -119,6 +119,10 @@ type Service interface {
 	// Identify returns thing ID for given thing key.
 	Identify(ctx context.Context, key string) (string, error)
 
+	// ChannelsByKey returns the IDs of the channels the thing with the given
+	// key is connected to.
+	ChannelsByKey(ctx context.Context, key string) ([]string, error)
+
 	// ListMembers retrieves everything that is assigned to a group identified by groupID.
 	ListMembers(ctx context.Context, token, groupID string, pm PageMetadata) (Page, error)
 }
@@ -395,6 +399,15 @@ func (ts *thingsService) Identify(ctx context.Context, key string) (string, erro
 	return id, nil
 }
 
+func (ts *thingsService) ChannelsByKey(ctx context.Context, key string) ([]string, error) {
+	id, err := ts.Identify(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.channels.RetrieveIDsByThing(ctx, id)
+}
+
 func (ts *thingsService) hasThing(ctx context.Context, chanID, thingKey string) (string, error) {
 	thingID, err := ts.thingCache.ID(ctx, thingKey)
 	if err != nil {
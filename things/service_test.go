@@ -1280,6 +1280,45 @@ func TestIdentify(t *testing.T) {
 	}
 }
 
+func TestChannelsByKey(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+
+	ths, err := svc.CreateThings(context.Background(), token, thing, thing)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	chs, err := svc.CreateChannels(context.Background(), token, channel, channel, channel)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	err = svc.Connect(context.Background(), token, []string{chs[0].ID, chs[1].ID}, []string{ths[0].ID})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	cases := map[string]struct {
+		token string
+		ids   []string
+		err   error
+	}{
+		"list channels of connected thing": {
+			token: ths[0].Key,
+			ids:   []string{chs[0].ID, chs[1].ID},
+			err:   nil,
+		},
+		"list channels of disconnected thing": {
+			token: ths[1].Key,
+			ids:   []string{},
+			err:   nil,
+		},
+		"list channels of non-existing thing": {
+			token: wrongValue,
+			ids:   nil,
+			err:   things.ErrNotFound,
+		},
+	}
+
+	for desc, tc := range cases {
+		ids, err := svc.ChannelsByKey(context.Background(), tc.token)
+		assert.Equal(t, tc.ids, ids, fmt.Sprintf("%s: expected %v got %v\n", desc, tc.ids, ids))
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
 func testSortThings(t *testing.T, pm things.PageMetadata, ths []things.Thing) {
 	switch pm.Order {
 	case "name":
@@ -16,6 +16,7 @@ const (
 	retrieveChannelByIDOp     = "retrieve_channel_by_id"
 	retrieveAllChannelsOp     = "retrieve_all_channels"
 	retrieveChannelsByThingOp = "retrieve_channels_by_thing"
+	retrieveIDsByThingOp      = "retrieve_ids_by_thing"
 	removeChannelOp           = "retrieve_channel"
 	connectOp                 = "connect"
 	disconnectOp              = "disconnect"
@@ -82,6 +83,14 @@ func (crm channelRepositoryMiddleware) RetrieveByThing(ctx context.Context, owne
 	return crm.repo.RetrieveByThing(ctx, owner, thID, pm)
 }
 
+func (crm channelRepositoryMiddleware) RetrieveIDsByThing(ctx context.Context, thID string) ([]string, error) {
+	span := createSpan(ctx, crm.tracer, retrieveIDsByThingOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return crm.repo.RetrieveIDsByThing(ctx, thID)
+}
+
 func (crm channelRepositoryMiddleware) Remove(ctx context.Context, owner, id string) error {
 	span := createSpan(ctx, crm.tracer, removeChannelOp)
 	defer span.Finish()
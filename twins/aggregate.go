@@ -0,0 +1,160 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package twins
+
+import (
+	"sort"
+	"time"
+)
+
+// Functions aggregating the attribute values of the states within the
+// interval. Average, minimum and maximum consider numeric values only.
+const (
+	AggLast = "last"
+	AggAvg  = "avg"
+	AggMin  = "min"
+	AggMax  = "max"
+)
+
+// MaxBuckets limits the number of buckets returned by the aggregation.
+const MaxBuckets = 1000
+
+var aggregations = map[string]bool{
+	AggLast: true,
+	AggAvg:  true,
+	AggMin:  true,
+	AggMax:  true,
+}
+
+// AggregationQuery groups the states created within the range into buckets
+// of the interval length, aligned to the Unix epoch, and applies the
+// function to the values of each of the attributes. Zero From or To leaves
+// the range open.
+type AggregationQuery struct {
+	Interval   time.Duration
+	Function   string
+	Attributes []string
+	From       time.Time
+	To         time.Time
+}
+
+// Bucket contains the aggregated attribute values of the states created
+// within the interval starting at Start. Attributes without values are
+// omitted.
+type Bucket struct {
+	Start  time.Time
+	Count  int64
+	Values map[string]interface{}
+}
+
+// Validate returns an error if the query is malformed.
+func (q AggregationQuery) Validate() error {
+	if q.Interval < time.Second || !aggregations[q.Function] {
+		return ErrMalformedEntity
+	}
+
+	for _, attr := range q.Attributes {
+		if attr == "" {
+			return ErrMalformedEntity
+		}
+	}
+
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return ErrMalformedEntity
+	}
+
+	return nil
+}
+
+// BucketStart returns the start of the interval the time belongs to.
+func BucketStart(t time.Time, interval time.Duration) time.Time {
+	ns := t.UnixNano()
+	return time.Unix(0, ns-ns%int64(interval)).UTC()
+}
+
+// Aggregate aggregates the states as specified by the query. Buckets are
+// ordered by the start time.
+func Aggregate(states []State, query AggregationQuery) []Bucket {
+	sorted := make([]State, len(states))
+	copy(sorted, states)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	type acc struct {
+		bucket Bucket
+		sums   map[string]float64
+		counts map[string]int64
+	}
+
+	accs := map[time.Time]*acc{}
+	var starts []time.Time
+	for _, st := range sorted {
+		if (!query.From.IsZero() && st.Created.Before(query.From)) ||
+			(!query.To.IsZero() && st.Created.After(query.To)) {
+			continue
+		}
+
+		start := BucketStart(st.Created, query.Interval)
+		a, ok := accs[start]
+		if !ok {
+			a = &acc{
+				bucket: Bucket{Start: start, Values: map[string]interface{}{}},
+				sums:   map[string]float64{},
+				counts: map[string]int64{},
+			}
+			accs[start] = a
+			starts = append(starts, start)
+		}
+		a.bucket.Count++
+
+		for _, attr := range query.Attributes {
+			val, ok := st.Payload[attr]
+			if !ok {
+				continue
+			}
+			val = deref(val)
+			if query.Function == AggLast {
+				if val != nil {
+					a.bucket.Values[attr] = val
+				}
+				continue
+			}
+
+			f, ok := val.(float64)
+			if !ok {
+				continue
+			}
+			cur, seen := a.bucket.Values[attr].(float64)
+			switch query.Function {
+			case AggAvg:
+				a.sums[attr] += f
+				a.counts[attr]++
+				a.bucket.Values[attr] = a.sums[attr] / float64(a.counts[attr])
+			case AggMin:
+				if !seen || f < cur {
+					a.bucket.Values[attr] = f
+				}
+			case AggMax:
+				if !seen || f > cur {
+					a.bucket.Values[attr] = f
+				}
+			}
+		}
+	}
+
+	sort.Slice(starts, func(i, j int) bool {
+		return starts[i].Before(starts[j])
+	})
+	if len(starts) > MaxBuckets {
+		starts = starts[:MaxBuckets]
+	}
+
+	buckets := []Bucket{}
+	for _, start := range starts {
+		buckets = append(buckets, accs[start].bucket)
+	}
+
+	return buckets
+}
@@ -0,0 +1,120 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package twins_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/twins"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregationQueryValidate(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		desc  string
+		query twins.AggregationQuery
+		err   error
+	}{
+		{
+			desc:  "validate valid query",
+			query: twins.AggregationQuery{Interval: time.Hour, Function: twins.AggAvg, Attributes: []string{"temperature"}, From: now, To: now},
+			err:   nil,
+		},
+		{
+			desc:  "validate query with too short interval",
+			query: twins.AggregationQuery{Interval: time.Millisecond, Function: twins.AggAvg},
+			err:   twins.ErrMalformedEntity,
+		},
+		{
+			desc:  "validate query with unknown function",
+			query: twins.AggregationQuery{Interval: time.Hour, Function: "sum"},
+			err:   twins.ErrMalformedEntity,
+		},
+		{
+			desc:  "validate query with empty attribute",
+			query: twins.AggregationQuery{Interval: time.Hour, Function: twins.AggLast, Attributes: []string{""}},
+			err:   twins.ErrMalformedEntity,
+		},
+		{
+			desc:  "validate query with inverted range",
+			query: twins.AggregationQuery{Interval: time.Hour, Function: twins.AggLast, From: now, To: now.Add(-time.Second)},
+			err:   twins.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		err := tc.query.Validate()
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	// Day-aligned start of the states.
+	start := time.Unix(1600041600, 0).UTC()
+	temps := []float64{20, 22, 30, 40}
+	modes := []string{"eco", "boost", "eco", "off"}
+	offsets := []time.Duration{0, 10 * time.Minute, time.Hour, time.Hour + 30*time.Minute}
+
+	var states []twins.State
+	for i := range temps {
+		states = append(states, twins.State{
+			ID:      int64(i),
+			Created: start.Add(offsets[i]),
+			Payload: map[string]interface{}{"temperature": &temps[i], "mode": &modes[i]},
+		})
+	}
+	attrs := []string{"temperature", "mode"}
+
+	cases := []struct {
+		desc    string
+		query   twins.AggregationQuery
+		buckets []twins.Bucket
+	}{
+		{
+			desc:  "aggregate last values",
+			query: twins.AggregationQuery{Interval: time.Hour, Function: twins.AggLast, Attributes: attrs},
+			buckets: []twins.Bucket{
+				{Start: start, Count: 2, Values: map[string]interface{}{"temperature": 22.0, "mode": "boost"}},
+				{Start: start.Add(time.Hour), Count: 2, Values: map[string]interface{}{"temperature": 40.0, "mode": "off"}},
+			},
+		},
+		{
+			desc:  "aggregate average values",
+			query: twins.AggregationQuery{Interval: time.Hour, Function: twins.AggAvg, Attributes: attrs},
+			buckets: []twins.Bucket{
+				{Start: start, Count: 2, Values: map[string]interface{}{"temperature": 21.0}},
+				{Start: start.Add(time.Hour), Count: 2, Values: map[string]interface{}{"temperature": 35.0}},
+			},
+		},
+		{
+			desc:  "aggregate minimum values",
+			query: twins.AggregationQuery{Interval: 2 * time.Hour, Function: twins.AggMin, Attributes: attrs},
+			buckets: []twins.Bucket{
+				{Start: start, Count: 4, Values: map[string]interface{}{"temperature": 20.0}},
+			},
+		},
+		{
+			desc:  "aggregate maximum values within range",
+			query: twins.AggregationQuery{Interval: time.Hour, Function: twins.AggMax, Attributes: attrs, From: start.Add(5 * time.Minute), To: start.Add(time.Hour)},
+			buckets: []twins.Bucket{
+				{Start: start, Count: 1, Values: map[string]interface{}{"temperature": 22.0}},
+				{Start: start.Add(time.Hour), Count: 1, Values: map[string]interface{}{"temperature": 30.0}},
+			},
+		},
+		{
+			desc:    "aggregate states outside of range",
+			query:   twins.AggregationQuery{Interval: time.Hour, Function: twins.AggLast, Attributes: attrs, From: start.Add(24 * time.Hour)},
+			buckets: []twins.Bucket{},
+		},
+	}
+
+	for _, tc := range cases {
+		buckets := twins.Aggregate(states, tc.query)
+		assert.Equal(t, tc.buckets, buckets, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.buckets, buckets))
+	}
+}
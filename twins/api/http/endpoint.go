@@ -19,8 +19,9 @@ func addTwinEndpoint(svc twins.Service) endpoint.Endpoint {
 		}
 
 		twin := twins.Twin{
-			Name:     req.Name,
-			Metadata: req.Metadata,
+			Name:      req.Name,
+			Metadata:  req.Metadata,
+			Retention: req.Retention,
 		}
 		saved, err := svc.AddTwin(ctx, req.token, twin, req.Definition)
 		if err != nil {
@@ -35,6 +36,38 @@ func addTwinEndpoint(svc twins.Service) endpoint.Endpoint {
 	}
 }
 
+func addTwinsEndpoint(svc twins.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(addTwinsReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		saved, err := svc.AddTwins(ctx, req.token, req.ThingIDs, req.ChannelID, req.Definition)
+		if err != nil {
+			return nil, err
+		}
+
+		res := twinsRes{Twins: []viewTwinRes{}}
+		for _, twin := range saved {
+			view := viewTwinRes{
+				Owner:       twin.Owner,
+				ID:          twin.ID,
+				ThingID:     twin.ThingID,
+				Name:        twin.Name,
+				Created:     twin.Created,
+				Updated:     twin.Updated,
+				Revision:    twin.Revision,
+				Definitions: twin.Definitions,
+			}
+			res.Twins = append(res.Twins, view)
+		}
+
+		return res, nil
+	}
+}
+
 func updateTwinEndpoint(svc twins.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(updateTwinReq)
@@ -44,9 +77,10 @@ func updateTwinEndpoint(svc twins.Service) endpoint.Endpoint {
 		}
 
 		twin := twins.Twin{
-			ID:       req.id,
-			Name:     req.Name,
-			Metadata: req.Metadata,
+			ID:        req.id,
+			Name:      req.Name,
+			Metadata:  req.Metadata,
+			Retention: req.Retention,
 		}
 
 		if err := svc.UpdateTwin(ctx, req.token, twin, req.Definition); err != nil {
@@ -74,12 +108,15 @@ func viewTwinEndpoint(svc twins.Service) endpoint.Endpoint {
 		res := viewTwinRes{
 			Owner:       twin.Owner,
 			ID:          twin.ID,
+			ThingID:     twin.ThingID,
 			Name:        twin.Name,
 			Created:     twin.Created,
 			Updated:     twin.Updated,
 			Revision:    twin.Revision,
 			Definitions: twin.Definitions,
 			Metadata:    twin.Metadata,
+			Desired:     twin.Desired,
+			Retention:   twin.Retention,
 		}
 		return res, nil
 	}
@@ -110,12 +147,15 @@ func listTwinsEndpoint(svc twins.Service) endpoint.Endpoint {
 			view := viewTwinRes{
 				Owner:       twin.Owner,
 				ID:          twin.ID,
+				ThingID:     twin.ThingID,
 				Name:        twin.Name,
 				Created:     twin.Created,
 				Updated:     twin.Updated,
 				Revision:    twin.Revision,
 				Definitions: twin.Definitions,
 				Metadata:    twin.Metadata,
+				Desired:     twin.Desired,
+				Retention:   twin.Retention,
 			}
 			res.Twins = append(res.Twins, view)
 		}
@@ -124,6 +164,60 @@ func listTwinsEndpoint(svc twins.Service) endpoint.Endpoint {
 	}
 }
 
+func listDefinitionsEndpoint(svc twins.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewTwinReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		defs, err := svc.ListDefinitions(ctx, req.token, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		res := definitionsRes{Definitions: []twins.Definition{}}
+		res.Definitions = append(res.Definitions, defs...)
+
+		return res, nil
+	}
+}
+
+func viewDefinitionEndpoint(svc twins.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(definitionReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		def, err := svc.ViewDefinition(ctx, req.token, req.id, req.defID)
+		if err != nil {
+			return nil, err
+		}
+
+		return definitionRes{Definition: def}, nil
+	}
+}
+
+func rollbackDefinitionEndpoint(svc twins.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(definitionReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		def, err := svc.RollbackDefinition(ctx, req.token, req.id, req.defID)
+		if err != nil {
+			return nil, err
+		}
+
+		return definitionRes{Definition: def, twinID: req.id, created: true}, nil
+	}
+}
+
 func removeTwinEndpoint(svc twins.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(viewTwinReq)
@@ -141,6 +235,23 @@ func removeTwinEndpoint(svc twins.Service) endpoint.Endpoint {
 	}
 }
 
+func setDesiredEndpoint(svc twins.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(setDesiredReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		delta, err := svc.SetDesired(ctx, req.token, req.id, req.desired)
+		if err != nil {
+			return nil, err
+		}
+
+		return desiredRes{Delta: delta}, nil
+	}
+}
+
 func listStatesEndpoint(svc twins.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(listStatesReq)
@@ -149,7 +260,7 @@ func listStatesEndpoint(svc twins.Service) endpoint.Endpoint {
 			return nil, err
 		}
 
-		page, err := svc.ListStates(ctx, req.token, req.offset, req.limit, req.id)
+		page, err := svc.ListStates(ctx, req.token, req.offset, req.limit, req.id, req.query)
 		if err != nil {
 			return nil, err
 		}
@@ -176,3 +287,74 @@ func listStatesEndpoint(svc twins.Service) endpoint.Endpoint {
 		return res, nil
 	}
 }
+
+func diffStatesEndpoint(svc twins.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(diffStatesReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		diff, err := svc.DiffStates(ctx, req.token, req.id, req.from, req.to)
+		if err != nil {
+			return nil, err
+		}
+
+		res := stateDiffRes{
+			TwinID: diff.TwinID,
+			From: stateRefRes{
+				ID:         diff.From.ID,
+				Definition: diff.From.Definition,
+				Created:    diff.From.Created,
+			},
+			To: stateRefRes{
+				ID:         diff.To.ID,
+				Definition: diff.To.Definition,
+				Created:    diff.To.Created,
+			},
+			Changes: []changeRes{},
+		}
+		for _, c := range diff.Changes {
+			res.Changes = append(res.Changes, changeRes{
+				Attribute: c.Attribute,
+				Kind:      c.Kind,
+				From:      c.From,
+				To:        c.To,
+			})
+		}
+
+		return res, nil
+	}
+}
+
+func aggregateStatesEndpoint(svc twins.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(aggregateStatesReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		buckets, err := svc.AggregateStates(ctx, req.token, req.id, req.query)
+		if err != nil {
+			return nil, err
+		}
+
+		res := aggregateRes{
+			TwinID:   req.id,
+			Interval: req.query.Interval.String(),
+			Function: req.query.Function,
+			Buckets:  []bucketRes{},
+		}
+		for _, b := range buckets {
+			res.Buckets = append(res.Buckets, bucketRes{
+				Start:  b.Start,
+				Count:  b.Count,
+				Values: b.Values,
+			})
+		}
+
+		return res, nil
+	}
+}
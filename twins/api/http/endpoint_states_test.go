@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/mainflux/mainflux/twins"
 	"github.com/mainflux/senml"
@@ -41,6 +42,29 @@ type statesPageRes struct {
 	States []stateRes `json:"states"`
 }
 
+type changeRes struct {
+	Attribute string      `json:"attribute"`
+	Kind      string      `json:"kind"`
+	From      interface{} `json:"from,omitempty"`
+	To        interface{} `json:"to,omitempty"`
+}
+
+type stateDiffRes struct {
+	TwinID  string      `json:"twin_id"`
+	Changes []changeRes `json:"changes"`
+}
+
+type bucketRes struct {
+	Start  time.Time              `json:"start"`
+	Count  int64                  `json:"count"`
+	Values map[string]interface{} `json:"values"`
+}
+
+type aggregateRes struct {
+	TwinID  string      `json:"twin_id"`
+	Buckets []bucketRes `json:"buckets"`
+}
+
 func TestListStates(t *testing.T) {
 	svc := mocks.NewService(map[string]string{token: email})
 	ts := newServer(svc)
@@ -174,6 +198,48 @@ func TestListStates(t *testing.T) {
 			url:    fmt.Sprintf("%s%s", baseURL, "?offset=4&limit=4&limit=5&offset=5"),
 			res:    nil,
 		},
+		{
+			desc:   "get a list of states filtered by time range",
+			auth:   token,
+			status: http.StatusOK,
+			url:    fmt.Sprintf("%s?from=2000-01-01T00:00:00Z&to=2100-01-01T00:00:00Z", baseURL),
+			res:    data[0:10],
+		},
+		{
+			desc:   "get a list of states filtered by attribute value",
+			auth:   token,
+			status: http.StatusOK,
+			url:    fmt.Sprintf("%s?filter=temperature:gt:30", baseURL),
+			res:    []stateRes{},
+		},
+		{
+			desc:   "get a list of states with invalid filter",
+			auth:   token,
+			status: http.StatusBadRequest,
+			url:    fmt.Sprintf("%s?filter=temperature:gt", baseURL),
+			res:    nil,
+		},
+		{
+			desc:   "get a list of states with unknown filter operator",
+			auth:   token,
+			status: http.StatusBadRequest,
+			url:    fmt.Sprintf("%s?filter=temperature:like:30", baseURL),
+			res:    nil,
+		},
+		{
+			desc:   "get a list of states with invalid time",
+			auth:   token,
+			status: http.StatusBadRequest,
+			url:    fmt.Sprintf("%s?from=yesterday", baseURL),
+			res:    nil,
+		},
+		{
+			desc:   "get a list of states with inverted time range",
+			auth:   token,
+			status: http.StatusBadRequest,
+			url:    fmt.Sprintf("%s?from=2100-01-01T00:00:00Z&to=2000-01-01T00:00:00Z", baseURL),
+			res:    nil,
+		},
 		{
 			desc:   "get a list of states with redundant query parameters",
 			auth:   token,
@@ -212,3 +278,196 @@ func createStateResponse(id int, tw twins.Twin, rec senml.Record) stateRes {
 		Payload:    map[string]interface{}{rec.BaseName: nil},
 	}
 }
+
+func TestDiffStates(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	def := twins.Definition{
+		Attributes: []twins.Attribute{
+			{Name: "temperature", Channel: channels[0], Subtopic: subtopics[0], PersistState: true},
+		},
+	}
+	tw, err := svc.AddTwin(context.Background(), token, twins.Twin{Owner: email}, def)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	for _, v := range []float64{21, 23} {
+		val := v
+		message, err := mocks.CreateMessage(def.Attributes[0], []senml.Record{{Value: &val}})
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		err = svc.SaveStates(message)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+
+	baseURL := fmt.Sprintf("%s/twins/%s/states/diff", ts.URL, tw.ID)
+	cases := []struct {
+		desc    string
+		auth    string
+		status  int
+		url     string
+		changes []changeRes
+	}{
+		{
+			desc:    "diff states",
+			auth:    token,
+			status:  http.StatusOK,
+			url:     fmt.Sprintf("%s?from=0&to=1", baseURL),
+			changes: []changeRes{{Attribute: "temperature", Kind: twins.ChangeUpdated, From: 21.0, To: 23.0}},
+		},
+		{
+			desc:    "diff same state",
+			auth:    token,
+			status:  http.StatusOK,
+			url:     fmt.Sprintf("%s?from=1&to=1", baseURL),
+			changes: []changeRes{},
+		},
+		{
+			desc:   "diff non-existent state",
+			auth:   token,
+			status: http.StatusNotFound,
+			url:    fmt.Sprintf("%s?from=0&to=5", baseURL),
+		},
+		{
+			desc:   "diff states without to",
+			auth:   token,
+			status: http.StatusBadRequest,
+			url:    fmt.Sprintf("%s?from=0", baseURL),
+		},
+		{
+			desc:   "diff states with invalid from",
+			auth:   token,
+			status: http.StatusBadRequest,
+			url:    fmt.Sprintf("%s?from=first&to=1", baseURL),
+		},
+		{
+			desc:   "diff states with invalid token",
+			auth:   wrongValue,
+			status: http.StatusForbidden,
+			url:    fmt.Sprintf("%s?from=0&to=1", baseURL),
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		if tc.changes != nil {
+			var resData stateDiffRes
+			err = json.NewDecoder(res.Body).Decode(&resData)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.changes, resData.Changes, fmt.Sprintf("%s: expected changes %v got %v", tc.desc, tc.changes, resData.Changes))
+		}
+	}
+}
+
+func TestAggregateStates(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	def := twins.Definition{
+		Attributes: []twins.Attribute{
+			{Name: "temperature", Channel: channels[0], Subtopic: subtopics[0], PersistState: true},
+		},
+	}
+	tw, err := svc.AddTwin(context.Background(), token, twins.Twin{Owner: email}, def)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	// Day-aligned time of the first state.
+	start := time.Unix(1600041600, 0).UTC()
+	temps := []float64{20, 22, 30, 40}
+	offsets := []time.Duration{0, 10 * time.Minute, time.Hour, time.Hour + 30*time.Minute}
+	for i := range temps {
+		rec := senml.Record{Value: &temps[i], BaseTime: float64(start.Add(offsets[i]).Unix())}
+		message, err := mocks.CreateMessage(def.Attributes[0], []senml.Record{rec})
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		err = svc.SaveStates(message)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+
+	baseURL := fmt.Sprintf("%s/twins/%s/states/aggregate", ts.URL, tw.ID)
+	cases := []struct {
+		desc    string
+		auth    string
+		status  int
+		url     string
+		buckets []bucketRes
+	}{
+		{
+			desc:   "aggregate hourly averages",
+			auth:   token,
+			status: http.StatusOK,
+			url:    fmt.Sprintf("%s?interval=1h&function=avg", baseURL),
+			buckets: []bucketRes{
+				{Start: start, Count: 2, Values: map[string]interface{}{"temperature": 21.0}},
+				{Start: start.Add(time.Hour), Count: 2, Values: map[string]interface{}{"temperature": 35.0}},
+			},
+		},
+		{
+			desc:   "aggregate last values of attribute within range",
+			auth:   token,
+			status: http.StatusOK,
+			url:    fmt.Sprintf("%s?interval=1h&attribute=temperature&from=%s", baseURL, start.Add(time.Hour).Format(time.RFC3339)),
+			buckets: []bucketRes{
+				{Start: start.Add(time.Hour), Count: 2, Values: map[string]interface{}{"temperature": 40.0}},
+			},
+		},
+		{
+			desc:   "aggregate without interval",
+			auth:   token,
+			status: http.StatusBadRequest,
+			url:    baseURL,
+		},
+		{
+			desc:   "aggregate with invalid interval",
+			auth:   token,
+			status: http.StatusBadRequest,
+			url:    fmt.Sprintf("%s?interval=hourly", baseURL),
+		},
+		{
+			desc:   "aggregate with invalid function",
+			auth:   token,
+			status: http.StatusBadRequest,
+			url:    fmt.Sprintf("%s?interval=1h&function=sum", baseURL),
+		},
+		{
+			desc:   "aggregate states of non-existent twin",
+			auth:   token,
+			status: http.StatusNotFound,
+			url:    fmt.Sprintf("%s/twins/%s/states/aggregate?interval=1h", ts.URL, wrongValue),
+		},
+		{
+			desc:   "aggregate with invalid token",
+			auth:   wrongValue,
+			status: http.StatusForbidden,
+			url:    fmt.Sprintf("%s?interval=1h", baseURL),
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		if tc.buckets != nil {
+			var resData aggregateRes
+			err = json.NewDecoder(res.Body).Decode(&resData)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.buckets, resData.Buckets, fmt.Sprintf("%s: expected buckets %v got %v", tc.desc, tc.buckets, resData.Buckets))
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -44,6 +45,7 @@ type twinReq struct {
 type twinRes struct {
 	Owner    string                 `json:"owner"`
 	ID       string                 `json:"id"`
+	ThingID  string                 `json:"thing_id,omitempty"`
 	Name     string                 `json:"name,omitempty"`
 	Revision int                    `json:"revision"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
@@ -60,6 +62,10 @@ type twinsPageRes struct {
 	Twins []twinRes `json:"twins"`
 }
 
+type twinsRes struct {
+	Twins []twinRes `json:"twins"`
+}
+
 type testRequest struct {
 	client      *http.Client
 	method      string
@@ -181,6 +187,14 @@ func TestAddTwin(t *testing.T) {
 			status:      http.StatusBadRequest,
 			location:    "",
 		},
+		{
+			desc:        "add twin with negative retention",
+			req:         `{"retention":{"max_states":-1}}`,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+			location:    "",
+		},
 	}
 
 	for _, tc := range cases {
@@ -201,6 +215,124 @@ func TestAddTwin(t *testing.T) {
 	}
 }
 
+func TestAddTwins(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	thingIDs := []string{mocks.ThingID(1), mocks.ThingID(2)}
+	allThingIDs := []string{mocks.ThingID(1), mocks.ThingID(2), mocks.ThingID(3)}
+
+	cases := []struct {
+		desc        string
+		req         string
+		contentType string
+		auth        string
+		status      int
+		thingIDs    []string
+	}{
+		{
+			desc:        "add twins for things",
+			req:         fmt.Sprintf(`{"thing_ids":["%s","%s"]}`, thingIDs[0], thingIDs[1]),
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusCreated,
+			thingIDs:    thingIDs,
+		},
+		{
+			desc:        "add twins for channel things",
+			req:         fmt.Sprintf(`{"channel_id":"%s","definition":{"attributes":[{"name":"temperature","channel":"%s","subtopic":"engine"}]}}`, mocks.ThingsChannel, mocks.ThingsChannel),
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusCreated,
+			thingIDs:    allThingIDs,
+		},
+		{
+			desc:        "add twins for non-existing thing",
+			req:         fmt.Sprintf(`{"thing_ids":["%s","%s"]}`, thingIDs[0], wrongValue),
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusNotFound,
+		},
+		{
+			desc:        "add twins for non-existing channel",
+			req:         fmt.Sprintf(`{"channel_id":"%s"}`, wrongValue),
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusNotFound,
+		},
+		{
+			desc:        "add twins for both things and channel",
+			req:         fmt.Sprintf(`{"thing_ids":["%s"],"channel_id":"%s"}`, thingIDs[0], mocks.ThingsChannel),
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "add twins without things and channel",
+			req:         "{}",
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "add twins with empty thing ID",
+			req:         `{"thing_ids":[""]}`,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "add twins with invalid auth token",
+			req:         fmt.Sprintf(`{"channel_id":"%s"}`, mocks.ThingsChannel),
+			contentType: contentType,
+			auth:        wrongValue,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:        "add twins with invalid request format",
+			req:         "}",
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "add twins without content type",
+			req:         fmt.Sprintf(`{"channel_id":"%s"}`, mocks.ThingsChannel),
+			contentType: "",
+			auth:        token,
+			status:      http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/twins/bulk", ts.URL),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.status != http.StatusCreated {
+			continue
+		}
+
+		var body twinsRes
+		err = json.NewDecoder(res.Body).Decode(&body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		var ids []string
+		for _, tw := range body.Twins {
+			ids = append(ids, tw.ThingID)
+		}
+		assert.Equal(t, tc.thingIDs, ids, fmt.Sprintf("%s: expected thing IDs %v got %v", tc.desc, tc.thingIDs, ids))
+	}
+}
+
 func TestUpdateTwin(t *testing.T) {
 	svc := mocks.NewService(map[string]string{token: email})
 	ts := newServer(svc)
@@ -630,3 +762,228 @@ func TestRemoveTwin(t *testing.T) {
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
 	}
 }
+
+func TestSetDesired(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	def := twins.Definition{
+		Attributes: []twins.Attribute{
+			{Name: "temperature", Channel: "channel", Subtopic: topic, PersistState: true},
+		},
+	}
+	stw, err := svc.AddTwin(context.Background(), token, twins.Twin{}, def)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc        string
+		req         string
+		id          string
+		contentType string
+		auth        string
+		status      int
+		res         string
+	}{
+		{
+			desc:        "set desired value",
+			req:         `{"temperature":22.5}`,
+			id:          stw.ID,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusOK,
+			res:         `{"delta":{"temperature":22.5}}`,
+		},
+		{
+			desc:        "set desired value of unknown attribute",
+			req:         `{"unknown":22.5}`,
+			id:          stw.ID,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "set desired values with empty JSON request",
+			req:         "{}",
+			id:          stw.ID,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "set desired values with invalid data format",
+			req:         "{",
+			id:          stw.ID,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "set desired values of non-existent twin",
+			req:         `{"temperature":22.5}`,
+			id:          strconv.FormatUint(wrongID, 10),
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusNotFound,
+		},
+		{
+			desc:        "set desired values with invalid user token",
+			req:         `{"temperature":22.5}`,
+			id:          stw.ID,
+			contentType: contentType,
+			auth:        wrongValue,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:        "set desired values without content type",
+			req:         `{"temperature":22.5}`,
+			id:          stw.ID,
+			contentType: "",
+			auth:        token,
+			status:      http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPut,
+			url:         fmt.Sprintf("%s/twins/%s/desired", ts.URL, tc.id),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.res != "" {
+			body, err := ioutil.ReadAll(res.Body)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.JSONEq(t, tc.res, string(body), fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.res, body))
+		}
+	}
+}
+
+func TestViewDefinitions(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	stw, err := svc.AddTwin(context.Background(), token, twins.Twin{}, mocks.CreateDefinition([]string{"channel"}, []string{topic}))
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc   string
+		url    string
+		auth   string
+		status int
+	}{
+		{
+			desc:   "list definitions",
+			url:    fmt.Sprintf("%s/twins/%s/definitions", ts.URL, stw.ID),
+			auth:   token,
+			status: http.StatusOK,
+		},
+		{
+			desc:   "list definitions of non-existent twin",
+			url:    fmt.Sprintf("%s/twins/%d/definitions", ts.URL, wrongID),
+			auth:   token,
+			status: http.StatusNotFound,
+		},
+		{
+			desc:   "list definitions with invalid token",
+			url:    fmt.Sprintf("%s/twins/%s/definitions", ts.URL, stw.ID),
+			auth:   wrongValue,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "view definition",
+			url:    fmt.Sprintf("%s/twins/%s/definitions/0", ts.URL, stw.ID),
+			auth:   token,
+			status: http.StatusOK,
+		},
+		{
+			desc:   "view non-existent definition",
+			url:    fmt.Sprintf("%s/twins/%s/definitions/1", ts.URL, stw.ID),
+			auth:   token,
+			status: http.StatusNotFound,
+		},
+		{
+			desc:   "view definition with invalid id",
+			url:    fmt.Sprintf("%s/twins/%s/definitions/first", ts.URL, stw.ID),
+			auth:   token,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    tc.url,
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestRollbackDefinition(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	stw, err := svc.AddTwin(context.Background(), token, twins.Twin{}, mocks.CreateDefinition([]string{"channel"}, []string{topic}))
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	err = svc.UpdateTwin(context.Background(), token, twins.Twin{ID: stw.ID}, mocks.CreateDefinition([]string{"other"}, []string{topic}))
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc     string
+		defID    string
+		auth     string
+		status   int
+		location string
+	}{
+		{
+			desc:     "roll back to previous definition",
+			defID:    "0",
+			auth:     token,
+			status:   http.StatusCreated,
+			location: fmt.Sprintf("/twins/%s/definitions/2", stw.ID),
+		},
+		{
+			desc:   "roll back to current definition",
+			defID:  "2",
+			auth:   token,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "roll back to non-existent definition",
+			defID:  "5",
+			auth:   token,
+			status: http.StatusNotFound,
+		},
+		{
+			desc:   "roll back with invalid token",
+			defID:  "0",
+			auth:   wrongValue,
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodPost,
+			url:    fmt.Sprintf("%s/twins/%s/definitions/%s/rollback", ts.URL, stw.ID, tc.defID),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		location := res.Header.Get("Location")
+		assert.Equal(t, tc.location, location, fmt.Sprintf("%s: expected location %s got %s", tc.desc, tc.location, location))
+	}
+}
@@ -21,6 +21,7 @@ type addTwinReq struct {
 	Name       string                 `json:"name,omitempty"`
 	Definition twins.Definition       `json:"definition,omitempty"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Retention  *twins.Retention       `json:"retention,omitempty"`
 }
 
 func (req addTwinReq) validate() error {
@@ -32,6 +33,32 @@ func (req addTwinReq) validate() error {
 		return twins.ErrMalformedEntity
 	}
 
+	return validateRetention(req.Retention)
+}
+
+type addTwinsReq struct {
+	token      string
+	ThingIDs   []string         `json:"thing_ids,omitempty"`
+	ChannelID  string           `json:"channel_id,omitempty"`
+	Definition twins.Definition `json:"definition,omitempty"`
+}
+
+func (req addTwinsReq) validate() error {
+	if req.token == "" {
+		return twins.ErrUnauthorizedAccess
+	}
+
+	// Twins are bound either to the listed things or to the channel things.
+	if (len(req.ThingIDs) == 0) == (req.ChannelID == "") {
+		return twins.ErrMalformedEntity
+	}
+
+	for _, id := range req.ThingIDs {
+		if id == "" {
+			return twins.ErrMalformedEntity
+		}
+	}
+
 	return nil
 }
 
@@ -41,6 +68,7 @@ type updateTwinReq struct {
 	Name       string                 `json:"name,omitempty"`
 	Definition twins.Definition       `json:"definition,omitempty"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Retention  *twins.Retention       `json:"retention,omitempty"`
 }
 
 func (req updateTwinReq) validate() error {
@@ -56,6 +84,14 @@ func (req updateTwinReq) validate() error {
 		return twins.ErrMalformedEntity
 	}
 
+	return validateRetention(req.Retention)
+}
+
+func validateRetention(ret *twins.Retention) error {
+	if ret != nil && (ret.MaxStates < 0 || ret.MaxAge < 0) {
+		return twins.ErrMalformedEntity
+	}
+
 	return nil
 }
 
@@ -105,6 +141,7 @@ type listStatesReq struct {
 	offset uint64
 	limit  uint64
 	id     string
+	query  twins.StateQuery
 }
 
 func (req *listStatesReq) validate() error {
@@ -120,5 +157,78 @@ func (req *listStatesReq) validate() error {
 		return twins.ErrMalformedEntity
 	}
 
+	return req.query.Validate()
+}
+
+type setDesiredReq struct {
+	token   string
+	id      string
+	desired twins.Desired
+}
+
+func (req setDesiredReq) validate() error {
+	if req.token == "" {
+		return twins.ErrUnauthorizedAccess
+	}
+
+	if req.id == "" || len(req.desired) == 0 {
+		return twins.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type diffStatesReq struct {
+	token string
+	id    string
+	from  int64
+	to    int64
+}
+
+func (req diffStatesReq) validate() error {
+	if req.token == "" {
+		return twins.ErrUnauthorizedAccess
+	}
+
+	if req.id == "" || req.from < 0 || req.to < 0 {
+		return twins.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type aggregateStatesReq struct {
+	token string
+	id    string
+	query twins.AggregationQuery
+}
+
+func (req aggregateStatesReq) validate() error {
+	if req.token == "" {
+		return twins.ErrUnauthorizedAccess
+	}
+
+	if req.id == "" {
+		return twins.ErrMalformedEntity
+	}
+
+	return nil
+}
+
+type definitionReq struct {
+	token string
+	id    string
+	defID int
+}
+
+func (req definitionReq) validate() error {
+	if req.token == "" {
+		return twins.ErrUnauthorizedAccess
+	}
+
+	if req.id == "" || req.defID < 0 {
+		return twins.ErrMalformedEntity
+	}
+
 	return nil
 }
@@ -15,10 +15,16 @@ import (
 var (
 	_ mainflux.Response = (*twinRes)(nil)
 	_ mainflux.Response = (*viewTwinRes)(nil)
+	_ mainflux.Response = (*twinsRes)(nil)
 	_ mainflux.Response = (*viewStateRes)(nil)
 	_ mainflux.Response = (*twinsPageRes)(nil)
 	_ mainflux.Response = (*statesPageRes)(nil)
 	_ mainflux.Response = (*removeRes)(nil)
+	_ mainflux.Response = (*desiredRes)(nil)
+	_ mainflux.Response = (*stateDiffRes)(nil)
+	_ mainflux.Response = (*aggregateRes)(nil)
+	_ mainflux.Response = (*definitionRes)(nil)
+	_ mainflux.Response = (*definitionsRes)(nil)
 )
 
 type twinRes struct {
@@ -51,12 +57,15 @@ func (res twinRes) Empty() bool {
 type viewTwinRes struct {
 	Owner       string                 `json:"owner,omitempty"`
 	ID          string                 `json:"id"`
+	ThingID     string                 `json:"thing_id,omitempty"`
 	Name        string                 `json:"name,omitempty"`
 	Revision    int                    `json:"revision"`
 	Created     time.Time              `json:"created"`
 	Updated     time.Time              `json:"updated"`
 	Definitions []twins.Definition     `json:"definitions,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Desired     map[string]interface{} `json:"desired,omitempty"`
+	Retention   *twins.Retention       `json:"retention,omitempty"`
 }
 
 func (res viewTwinRes) Code() int {
@@ -71,6 +80,22 @@ func (res viewTwinRes) Empty() bool {
 	return false
 }
 
+type twinsRes struct {
+	Twins []viewTwinRes `json:"twins"`
+}
+
+func (res twinsRes) Code() int {
+	return http.StatusCreated
+}
+
+func (res twinsRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res twinsRes) Empty() bool {
+	return false
+}
+
 type viewStateRes struct {
 	TwinID     string                 `json:"twin_id"`
 	ID         int64                  `json:"id"`
@@ -144,3 +169,120 @@ func (res removeRes) Headers() map[string]string {
 func (res removeRes) Empty() bool {
 	return true
 }
+
+type desiredRes struct {
+	Delta map[string]interface{} `json:"delta"`
+}
+
+func (res desiredRes) Code() int {
+	return http.StatusOK
+}
+
+func (res desiredRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res desiredRes) Empty() bool {
+	return false
+}
+
+type stateRefRes struct {
+	ID         int64     `json:"id"`
+	Definition int       `json:"definition"`
+	Created    time.Time `json:"created"`
+}
+
+type changeRes struct {
+	Attribute string      `json:"attribute"`
+	Kind      string      `json:"kind"`
+	From      interface{} `json:"from,omitempty"`
+	To        interface{} `json:"to,omitempty"`
+}
+
+type stateDiffRes struct {
+	TwinID  string      `json:"twin_id"`
+	From    stateRefRes `json:"from"`
+	To      stateRefRes `json:"to"`
+	Changes []changeRes `json:"changes"`
+}
+
+func (res stateDiffRes) Code() int {
+	return http.StatusOK
+}
+
+func (res stateDiffRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res stateDiffRes) Empty() bool {
+	return false
+}
+
+type bucketRes struct {
+	Start  time.Time              `json:"start"`
+	Count  int64                  `json:"count"`
+	Values map[string]interface{} `json:"values"`
+}
+
+type aggregateRes struct {
+	TwinID   string      `json:"twin_id"`
+	Interval string      `json:"interval"`
+	Function string      `json:"function"`
+	Buckets  []bucketRes `json:"buckets"`
+}
+
+func (res aggregateRes) Code() int {
+	return http.StatusOK
+}
+
+func (res aggregateRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res aggregateRes) Empty() bool {
+	return false
+}
+
+type definitionRes struct {
+	twins.Definition
+	twinID  string
+	created bool
+}
+
+func (res definitionRes) Code() int {
+	if res.created {
+		return http.StatusCreated
+	}
+
+	return http.StatusOK
+}
+
+func (res definitionRes) Headers() map[string]string {
+	if res.created {
+		return map[string]string{
+			"Location": fmt.Sprintf("/twins/%s/definitions/%d", res.twinID, res.ID),
+		}
+	}
+
+	return map[string]string{}
+}
+
+func (res definitionRes) Empty() bool {
+	return false
+}
+
+type definitionsRes struct {
+	Definitions []twins.Definition `json:"definitions"`
+}
+
+func (res definitionsRes) Code() int {
+	return http.StatusOK
+}
+
+func (res definitionsRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res definitionsRes) Empty() bool {
+	return false
+}
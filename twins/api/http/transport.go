@@ -8,7 +8,9 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	kitot "github.com/go-kit/kit/tracing/opentracing"
 	kithttp "github.com/go-kit/kit/transport/http"
@@ -27,6 +29,12 @@ const (
 	limitKey    = "limit"
 	nameKey     = "name"
 	metadataKey = "metadata"
+	filterKey   = "filter"
+	fromKey     = "from"
+	toKey       = "to"
+	intervalKey = "interval"
+	functionKey = "function"
+	attrKey     = "attribute"
 	defLimit    = 10
 	defOffset   = 0
 )
@@ -46,6 +54,13 @@ func MakeHandler(tracer opentracing.Tracer, svc twins.Service) http.Handler {
 		opts...,
 	))
 
+	r.Post("/twins/bulk", kithttp.NewServer(
+		kitot.TraceServer(tracer, "add_twins")(addTwinsEndpoint(svc)),
+		decodeTwinsCreation,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Put("/twins/:id", kithttp.NewServer(
 		kitot.TraceServer(tracer, "update_twin")(updateTwinEndpoint(svc)),
 		decodeTwinUpdate,
@@ -74,6 +89,48 @@ func MakeHandler(tracer opentracing.Tracer, svc twins.Service) http.Handler {
 		opts...,
 	))
 
+	r.Get("/twins/:id/definitions", kithttp.NewServer(
+		kitot.TraceServer(tracer, "list_definitions")(listDefinitionsEndpoint(svc)),
+		decodeView,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Get("/twins/:id/definitions/:defID", kithttp.NewServer(
+		kitot.TraceServer(tracer, "view_definition")(viewDefinitionEndpoint(svc)),
+		decodeDefinition,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Post("/twins/:id/definitions/:defID/rollback", kithttp.NewServer(
+		kitot.TraceServer(tracer, "rollback_definition")(rollbackDefinitionEndpoint(svc)),
+		decodeDefinition,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Put("/twins/:id/desired", kithttp.NewServer(
+		kitot.TraceServer(tracer, "set_desired")(setDesiredEndpoint(svc)),
+		decodeDesired,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Get("/twins/:id/states/diff", kithttp.NewServer(
+		kitot.TraceServer(tracer, "diff_states")(diffStatesEndpoint(svc)),
+		decodeDiffStates,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Get("/twins/:id/states/aggregate", kithttp.NewServer(
+		kitot.TraceServer(tracer, "aggregate_states")(aggregateStatesEndpoint(svc)),
+		decodeAggregateStates,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Get("/states/:id", kithttp.NewServer(
 		kitot.TraceServer(tracer, "list_states")(listStatesEndpoint(svc)),
 		decodeListStates,
@@ -100,6 +157,19 @@ func decodeTwinCreation(_ context.Context, r *http.Request) (interface{}, error)
 	return req, nil
 }
 
+func decodeTwinsCreation(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := addTwinsReq{token: r.Header.Get("Authorization")}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
 func decodeTwinUpdate(_ context.Context, r *http.Request) (interface{}, error) {
 	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
 		return nil, errors.ErrUnsupportedContentType
@@ -116,6 +186,37 @@ func decodeTwinUpdate(_ context.Context, r *http.Request) (interface{}, error) {
 	return req, nil
 }
 
+func decodeDesired(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := setDesiredReq{
+		token: r.Header.Get("Authorization"),
+		id:    bone.GetValue(r, "id"),
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req.desired); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func decodeDefinition(_ context.Context, r *http.Request) (interface{}, error) {
+	defID, err := strconv.Atoi(bone.GetValue(r, "defID"))
+	if err != nil {
+		return nil, twins.ErrMalformedEntity
+	}
+
+	req := definitionReq{
+		token: r.Header.Get("Authorization"),
+		id:    bone.GetValue(r, "id"),
+		defID: defID,
+	}
+
+	return req, nil
+}
+
 func decodeView(_ context.Context, r *http.Request) (interface{}, error) {
 	req := viewTwinReq{
 		token: r.Header.Get("Authorization"),
@@ -168,16 +269,154 @@ func decodeListStates(_ context.Context, r *http.Request) (interface{}, error) {
 		return nil, err
 	}
 
+	q, err := readStateQuery(r)
+	if err != nil {
+		return nil, err
+	}
+
 	req := listStatesReq{
 		token:  r.Header.Get("Authorization"),
 		limit:  l,
 		offset: o,
 		id:     bone.GetValue(r, "id"),
+		query:  q,
 	}
 
 	return req, nil
 }
 
+func decodeDiffStates(_ context.Context, r *http.Request) (interface{}, error) {
+	from, err := readStateID(r, fromKey)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := readStateID(r, toKey)
+	if err != nil {
+		return nil, err
+	}
+
+	req := diffStatesReq{
+		token: r.Header.Get("Authorization"),
+		id:    bone.GetValue(r, "id"),
+		from:  from,
+		to:    to,
+	}
+
+	return req, nil
+}
+
+func decodeAggregateStates(_ context.Context, r *http.Request) (interface{}, error) {
+	s, err := httputil.ReadStringQuery(r, intervalKey, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var interval time.Duration
+	if s != "" {
+		if interval, err = time.ParseDuration(s); err != nil {
+			return nil, errors.ErrInvalidQueryParams
+		}
+	}
+
+	fn, err := httputil.ReadStringQuery(r, functionKey, twins.AggLast)
+	if err != nil {
+		return nil, err
+	}
+
+	q := twins.AggregationQuery{
+		Interval:   interval,
+		Function:   fn,
+		Attributes: bone.GetQuery(r, attrKey),
+	}
+	if q.From, err = readTimeQuery(r, fromKey); err != nil {
+		return nil, err
+	}
+	if q.To, err = readTimeQuery(r, toKey); err != nil {
+		return nil, err
+	}
+
+	req := aggregateStatesReq{
+		token: r.Header.Get("Authorization"),
+		id:    bone.GetValue(r, "id"),
+		query: q,
+	}
+
+	return req, nil
+}
+
+// readStateID reads the required state ID, returning -1 if it's missing.
+func readStateID(r *http.Request, key string) (int64, error) {
+	s, err := httputil.ReadStringQuery(r, key, "")
+	if err != nil || s == "" {
+		return -1, err
+	}
+
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return -1, errors.ErrInvalidQueryParams
+	}
+
+	return id, nil
+}
+
+// readStateQuery reads the state query consisting of the conditions in the
+// `path:operator:value` format, passed as the filter parameters, and of the
+// RFC3339 formatted time range.
+func readStateQuery(r *http.Request) (twins.StateQuery, error) {
+	var q twins.StateQuery
+	for _, f := range bone.GetQuery(r, filterKey) {
+		parts := strings.SplitN(f, ":", 3)
+		if len(parts) != 3 {
+			return twins.StateQuery{}, errors.ErrInvalidQueryParams
+		}
+		q.Conditions = append(q.Conditions, twins.Condition{
+			Path:     parts[0],
+			Operator: parts[1],
+			Value:    parseValue(parts[2]),
+		})
+	}
+
+	var err error
+	if q.From, err = readTimeQuery(r, fromKey); err != nil {
+		return twins.StateQuery{}, err
+	}
+	if q.To, err = readTimeQuery(r, toKey); err != nil {
+		return twins.StateQuery{}, err
+	}
+
+	return q, nil
+}
+
+func readTimeQuery(r *http.Request, key string) (time.Time, error) {
+	s, err := httputil.ReadStringQuery(r, key, "")
+	if err != nil || s == "" {
+		return time.Time{}, err
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, errors.ErrInvalidQueryParams
+	}
+
+	return t, nil
+}
+
+// parseValue parses the condition value as number or boolean (`true` or
+// `false`), falling back to string. Quoted values are always strings.
+func parseValue(s string) interface{} {
+	if len(s) > 1 && strings.HasPrefix(s, "\"") && strings.HasSuffix(s, "\"") {
+		return s[1 : len(s)-1]
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	if s == "true" || s == "false" {
+		return s == "true"
+	}
+	return s
+}
+
 func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
 	w.Header().Set("Content-Type", contentType)
 
@@ -40,6 +40,19 @@ func (lm *loggingMiddleware) AddTwin(ctx context.Context, token string, twin twi
 	return lm.svc.AddTwin(ctx, token, twin, def)
 }
 
+func (lm *loggingMiddleware) AddTwins(ctx context.Context, token string, thingIDs []string, chanID string, def twins.Definition) (tws []twins.Twin, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method add_twins for token %s and channel %s took %s to complete", token, chanID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.AddTwins(ctx, token, thingIDs, chanID, def)
+}
+
 func (lm *loggingMiddleware) UpdateTwin(ctx context.Context, token string, twin twins.Twin, def twins.Definition) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method update_twin for token %s and twin %s took %s to complete", token, twin.ID, time.Since(begin))
@@ -92,7 +105,7 @@ func (lm *loggingMiddleware) SaveStates(msg *messaging.Message) (err error) {
 	return lm.svc.SaveStates(msg)
 }
 
-func (lm *loggingMiddleware) ListStates(ctx context.Context, token string, offset uint64, limit uint64, twinID string) (page twins.StatesPage, err error) {
+func (lm *loggingMiddleware) ListStates(ctx context.Context, token string, offset uint64, limit uint64, twinID string, query twins.StateQuery) (page twins.StatesPage, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method list_states for token %s took %s to complete", token, time.Since(begin))
 		if err != nil {
@@ -102,7 +115,7 @@ func (lm *loggingMiddleware) ListStates(ctx context.Context, token string, offse
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.ListStates(ctx, token, offset, limit, twinID)
+	return lm.svc.ListStates(ctx, token, offset, limit, twinID, query)
 }
 
 func (lm *loggingMiddleware) RemoveTwin(ctx context.Context, token, twinID string) (err error) {
@@ -117,3 +130,81 @@ func (lm *loggingMiddleware) RemoveTwin(ctx context.Context, token, twinID strin
 
 	return lm.svc.RemoveTwin(ctx, token, twinID)
 }
+
+func (lm *loggingMiddleware) SetDesired(ctx context.Context, token, twinID string, desired twins.Desired) (delta twins.Desired, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method set_desired for token %s and twin %s took %s to complete", token, twinID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.SetDesired(ctx, token, twinID, desired)
+}
+
+func (lm *loggingMiddleware) DiffStates(ctx context.Context, token, twinID string, from, to int64) (diff twins.StateDiff, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method diff_states for token %s and twin %s took %s to complete", token, twinID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.DiffStates(ctx, token, twinID, from, to)
+}
+
+func (lm *loggingMiddleware) AggregateStates(ctx context.Context, token, twinID string, query twins.AggregationQuery) (buckets []twins.Bucket, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method aggregate_states for token %s and twin %s took %s to complete", token, twinID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.AggregateStates(ctx, token, twinID, query)
+}
+
+func (lm *loggingMiddleware) ListDefinitions(ctx context.Context, token, twinID string) (defs []twins.Definition, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method list_definitions for token %s and twin %s took %s to complete", token, twinID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ListDefinitions(ctx, token, twinID)
+}
+
+func (lm *loggingMiddleware) ViewDefinition(ctx context.Context, token, twinID string, defID int) (def twins.Definition, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method view_definition for token %s and twin %s took %s to complete", token, twinID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ViewDefinition(ctx, token, twinID, defID)
+}
+
+func (lm *loggingMiddleware) RollbackDefinition(ctx context.Context, token, twinID string, defID int) (def twins.Definition, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method rollback_definition for token %s and twin %s took %s to complete", token, twinID, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.RollbackDefinition(ctx, token, twinID, defID)
+}
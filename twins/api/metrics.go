@@ -40,6 +40,15 @@ func (ms *metricsMiddleware) AddTwin(ctx context.Context, token string, twin twi
 	return ms.svc.AddTwin(ctx, token, twin, def)
 }
 
+func (ms *metricsMiddleware) AddTwins(ctx context.Context, token string, thingIDs []string, chanID string, def twins.Definition) (tws []twins.Twin, err error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "add_twins").Add(1)
+		ms.latency.With("method", "add_twins").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.AddTwins(ctx, token, thingIDs, chanID, def)
+}
+
 func (ms *metricsMiddleware) UpdateTwin(ctx context.Context, token string, twin twins.Twin, def twins.Definition) (err error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "update_twin").Add(1)
@@ -76,13 +85,13 @@ func (ms *metricsMiddleware) SaveStates(msg *messaging.Message) error {
 	return ms.svc.SaveStates(msg)
 }
 
-func (ms *metricsMiddleware) ListStates(ctx context.Context, token string, offset uint64, limit uint64, twinID string) (st twins.StatesPage, err error) {
+func (ms *metricsMiddleware) ListStates(ctx context.Context, token string, offset uint64, limit uint64, twinID string, query twins.StateQuery) (st twins.StatesPage, err error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "list_states").Add(1)
 		ms.latency.With("method", "list_states").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.ListStates(ctx, token, offset, limit, twinID)
+	return ms.svc.ListStates(ctx, token, offset, limit, twinID, query)
 }
 
 func (ms *metricsMiddleware) RemoveTwin(ctx context.Context, token, twinID string) (err error) {
@@ -93,3 +102,57 @@ func (ms *metricsMiddleware) RemoveTwin(ctx context.Context, token, twinID strin
 
 	return ms.svc.RemoveTwin(ctx, token, twinID)
 }
+
+func (ms *metricsMiddleware) SetDesired(ctx context.Context, token, twinID string, desired twins.Desired) (delta twins.Desired, err error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "set_desired").Add(1)
+		ms.latency.With("method", "set_desired").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.SetDesired(ctx, token, twinID, desired)
+}
+
+func (ms *metricsMiddleware) DiffStates(ctx context.Context, token, twinID string, from, to int64) (diff twins.StateDiff, err error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "diff_states").Add(1)
+		ms.latency.With("method", "diff_states").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.DiffStates(ctx, token, twinID, from, to)
+}
+
+func (ms *metricsMiddleware) AggregateStates(ctx context.Context, token, twinID string, query twins.AggregationQuery) (buckets []twins.Bucket, err error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "aggregate_states").Add(1)
+		ms.latency.With("method", "aggregate_states").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.AggregateStates(ctx, token, twinID, query)
+}
+
+func (ms *metricsMiddleware) ListDefinitions(ctx context.Context, token, twinID string) (defs []twins.Definition, err error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_definitions").Add(1)
+		ms.latency.With("method", "list_definitions").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ListDefinitions(ctx, token, twinID)
+}
+
+func (ms *metricsMiddleware) ViewDefinition(ctx context.Context, token, twinID string, defID int) (def twins.Definition, err error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "view_definition").Add(1)
+		ms.latency.With("method", "view_definition").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ViewDefinition(ctx, token, twinID, defID)
+}
+
+func (ms *metricsMiddleware) RollbackDefinition(ctx context.Context, token, twinID string, defID int) (def twins.Definition, err error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "rollback_definition").Add(1)
+		ms.latency.With("method", "rollback_definition").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.RollbackDefinition(ctx, token, twinID, defID)
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package twins
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Kinds of the attribute changes between two states.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeUpdated = "updated"
+)
+
+// Change describes the change of the attribute value between two states.
+type Change struct {
+	Attribute string
+	Kind      string
+	From      interface{}
+	To        interface{}
+}
+
+// StateDiff contains the attribute-level differences between two states of
+// the twin.
+type StateDiff struct {
+	TwinID  string
+	From    State
+	To      State
+	Changes []Change
+}
+
+// Diff returns the changes of the attribute values between the states,
+// ordered by the attribute name.
+func Diff(from, to State) []Change {
+	changes := []Change{}
+	for name, val := range from.Payload {
+		next, ok := to.Payload[name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Attribute: name, Kind: ChangeRemoved, From: deref(val)})
+		case !reflect.DeepEqual(deref(val), deref(next)):
+			changes = append(changes, Change{Attribute: name, Kind: ChangeUpdated, From: deref(val), To: deref(next)})
+		}
+	}
+	for name, val := range to.Payload {
+		if _, ok := from.Payload[name]; !ok {
+			changes = append(changes, Change{Attribute: name, Kind: ChangeAdded, To: deref(val)})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Attribute < changes[j].Attribute
+	})
+
+	return changes
+}
@@ -0,0 +1,46 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package twins_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/twins"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	temp, mode := 21.0, "eco"
+	from := twins.State{
+		ID:      0,
+		Payload: map[string]interface{}{"temperature": &temp, "mode": &mode, "on": true},
+	}
+
+	cases := []struct {
+		desc    string
+		to      twins.State
+		changes []twins.Change
+	}{
+		{
+			desc:    "diff equal states",
+			to:      twins.State{ID: 1, Payload: map[string]interface{}{"temperature": 21.0, "mode": "eco", "on": true}},
+			changes: []twins.Change{},
+		},
+		{
+			desc: "diff states with updated, added and removed attributes",
+			to:   twins.State{ID: 1, Payload: map[string]interface{}{"temperature": 23.0, "on": true, "rpm": 3000.0}},
+			changes: []twins.Change{
+				{Attribute: "mode", Kind: twins.ChangeRemoved, From: "eco"},
+				{Attribute: "rpm", Kind: twins.ChangeAdded, To: 3000.0},
+				{Attribute: "temperature", Kind: twins.ChangeUpdated, From: 21.0, To: 23.0},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		changes := twins.Diff(from, tc.to)
+		assert.Equal(t, tc.changes, changes, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.changes, changes))
+	}
+}
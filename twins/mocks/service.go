@@ -25,7 +25,7 @@ func NewService(tokens map[string]string) twins.Service {
 	subs := map[string]string{"chanID": "chanID"}
 	broker := NewBroker(subs)
 
-	return twins.New(broker, auth, twinsRepo, twinCache, statesRepo, idProvider, "chanID", nil)
+	return twins.New(broker, auth, NewThingsService(), twinsRepo, twinCache, statesRepo, idProvider, "chanID", nil)
 }
 
 // CreateDefinition creates twin definition
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/mainflux/mainflux/twins"
 )
@@ -32,7 +33,7 @@ func (srm *stateRepositoryMock) Save(ctx context.Context, st twins.State) error
 	srm.mu.Lock()
 	defer srm.mu.Unlock()
 
-	srm.states[key(st.TwinID, strconv.FormatInt(st.ID, 10))] = st
+	srm.states[key(st.TwinID, strconv.FormatInt(st.ID, 10))] = clone(st)
 
 	return nil
 }
@@ -42,7 +43,7 @@ func (srm *stateRepositoryMock) Update(ctx context.Context, st twins.State) erro
 	srm.mu.Lock()
 	defer srm.mu.Unlock()
 
-	srm.states[key(st.TwinID, strconv.FormatInt(st.ID, 10))] = st
+	srm.states[key(st.TwinID, strconv.FormatInt(st.ID, 10))] = clone(st)
 
 	return nil
 }
@@ -52,7 +53,7 @@ func (srm *stateRepositoryMock) Count(ctx context.Context, tw twins.Twin) (int64
 	return int64(len(srm.states)), nil
 }
 
-func (srm *stateRepositoryMock) RetrieveAll(ctx context.Context, offset uint64, limit uint64, twinID string) (twins.StatesPage, error) {
+func (srm *stateRepositoryMock) RetrieveAll(ctx context.Context, offset uint64, limit uint64, twinID string, query twins.StateQuery) (twins.StatesPage, error) {
 	srm.mu.Lock()
 	defer srm.mu.Unlock()
 
@@ -62,26 +63,29 @@ func (srm *stateRepositoryMock) RetrieveAll(ctx context.Context, offset uint64,
 
 	var items []twins.State
 	for k, v := range srm.states {
-		if (uint64)(len(items)) >= limit {
-			break
-		}
-		if !strings.HasPrefix(k, twinID) {
+		if !strings.HasPrefix(k, twinID) || !query.Match(v) {
 			continue
 		}
-		id := uint64(v.ID)
-		if id >= offset && id < offset+limit {
-			items = append(items, v)
-		}
+		items = append(items, v)
 	}
 
 	sort.SliceStable(items, func(i, j int) bool {
 		return items[i].ID < items[j].ID
 	})
 
+	total := uint64(len(items))
+	start, end := offset, offset+limit
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+
 	page := twins.StatesPage{
-		States: items,
+		States: items[start:end],
 		PageMetadata: twins.PageMetadata{
-			Total:  srm.total(twinID),
+			Total:  total,
 			Offset: offset,
 			Limit:  limit,
 		},
@@ -90,14 +94,17 @@ func (srm *stateRepositoryMock) RetrieveAll(ctx context.Context, offset uint64,
 	return page, nil
 }
 
-func (srm *stateRepositoryMock) total(twinID string) uint64 {
-	var total uint64
-	for k := range srm.states {
-		if strings.HasPrefix(k, twinID) {
-			total++
-		}
+// RetrieveByID returns the state of the twin spec by id
+func (srm *stateRepositoryMock) RetrieveByID(ctx context.Context, twinID string, id int64) (twins.State, error) {
+	srm.mu.Lock()
+	defer srm.mu.Unlock()
+
+	st, ok := srm.states[key(twinID, strconv.FormatInt(id, 10))]
+	if !ok {
+		return twins.State{}, twins.ErrNotFound
 	}
-	return total
+
+	return st, nil
 }
 
 // RetrieveLast returns the last state related to twin spec by id
@@ -116,7 +123,67 @@ func (srm *stateRepositoryMock) RetrieveLast(ctx context.Context, twinID string)
 	})
 
 	if len(items) > 0 {
-		return items[len(items)-1], nil
+		return clone(items[len(items)-1]), nil
 	}
 	return twins.State{}, nil
 }
+
+// Aggregate groups the states of the twin into buckets
+func (srm *stateRepositoryMock) Aggregate(ctx context.Context, twinID string, query twins.AggregationQuery) ([]twins.Bucket, error) {
+	srm.mu.Lock()
+	defer srm.mu.Unlock()
+
+	var items []twins.State
+	for _, v := range srm.states {
+		if v.TwinID == twinID {
+			items = append(items, v)
+		}
+	}
+
+	return twins.Aggregate(items, query), nil
+}
+
+// Purge removes the states of the twin exceeding the retention policy
+func (srm *stateRepositoryMock) Purge(ctx context.Context, twinID string, retention twins.Retention) (int64, error) {
+	srm.mu.Lock()
+	defer srm.mu.Unlock()
+
+	var items []twins.State
+	for _, v := range srm.states {
+		if v.TwinID == twinID {
+			items = append(items, v)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ID > items[j].ID
+	})
+
+	cutoff := time.Now().Add(-time.Duration(retention.MaxAge) * time.Second)
+	var n int64
+	for i, st := range items {
+		if i == 0 {
+			continue
+		}
+		if (retention.MaxStates > 0 && int64(i) >= retention.MaxStates) ||
+			(retention.MaxAge > 0 && st.Created.Before(cutoff)) {
+			delete(srm.states, key(st.TwinID, strconv.FormatInt(st.ID, 10)))
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// clone copies the state payload, since the service modifies the payload of
+// the last state when preparing the next one.
+func clone(st twins.State) twins.State {
+	if st.Payload == nil {
+		return st
+	}
+	payload := make(map[string]interface{}, len(st.Payload))
+	for k, v := range st.Payload {
+		payload[k] = v
+	}
+	st.Payload = payload
+	return st
+}
@@ -0,0 +1,65 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"fmt"
+
+	"github.com/mainflux/mainflux/twins"
+)
+
+const (
+	// ThingsChannel is the ID of the channel all the mocked things are
+	// connected to.
+	ThingsChannel = "things-channel"
+
+	numThings = 3
+)
+
+var _ twins.ThingsService = (*thingsServiceMock)(nil)
+
+type thingsServiceMock struct {
+	things map[string]twins.Thing
+}
+
+// NewThingsService creates mock of things service, containing the things
+// identified by ThingID, all connected to the ThingsChannel.
+func NewThingsService() twins.ThingsService {
+	ths := make(map[string]twins.Thing)
+	for i := 1; i <= numThings; i++ {
+		ths[ThingID(i)] = twins.Thing{
+			ID:   ThingID(i),
+			Name: fmt.Sprintf("thing_%d", i),
+		}
+	}
+
+	return &thingsServiceMock{things: ths}
+}
+
+// ThingID returns the ID of the n-th mocked thing, starting from 1.
+func ThingID(n int) string {
+	return fmt.Sprintf("thing-%d", n)
+}
+
+func (svc *thingsServiceMock) Thing(_, id string) (twins.Thing, error) {
+	th, ok := svc.things[id]
+	if !ok {
+		return twins.Thing{}, twins.ErrNotFound
+	}
+
+	return th, nil
+}
+
+func (svc *thingsServiceMock) ChannelThings(_, chanID string) ([]twins.Thing, error) {
+	if chanID != ThingsChannel {
+		return nil, twins.ErrNotFound
+	}
+
+	var ths []twins.Thing
+	for i := 1; i <= numThings; i++ {
+		ths = append(ths, svc.things[ThingID(i)])
+	}
+
+	return ths, nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mainflux/mainflux/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PurgeStates periodically removes the states exceeding the retention
+// policies of the twins, until the context is canceled. Non-positive
+// interval disables purging.
+func PurgeStates(ctx context.Context, db *mongo.Database, interval time.Duration, logger logger.Logger) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := purge(ctx, db)
+			if err != nil {
+				logger.Warn(fmt.Sprintf("Failed to purge states: %s", err))
+				continue
+			}
+			if n > 0 {
+				logger.Info(fmt.Sprintf("Purged %d states", n))
+			}
+		}
+	}
+}
+
+func purge(ctx context.Context, db *mongo.Database) (int64, error) {
+	coll := db.Collection(twinsCollection)
+
+	filter := bson.M{
+		"$or": []bson.M{
+			{"retention.maxstates": bson.M{"$gt": 0}},
+			{"retention.maxage": bson.M{"$gt": 0}},
+		},
+	}
+	findOptions := options.Find()
+	findOptions.SetProjection(bson.M{"id": true, "retention": true})
+
+	cur, err := coll.Find(ctx, filter, findOptions)
+	if err != nil {
+		return 0, err
+	}
+
+	tws, err := decodeTwins(ctx, cur)
+	if err != nil {
+		return 0, err
+	}
+
+	repo := NewStateRepository(db)
+	var total int64
+	for _, tw := range tws {
+		if tw.Retention == nil {
+			continue
+		}
+		n, err := repo.Purge(ctx, tw.ID, *tw.Retention)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+
+	return total, nil
+}
@@ -5,9 +5,12 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/mainflux/mainflux/twins"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -17,6 +20,15 @@ const (
 	twinid                  = "twinid"
 )
 
+var operators = map[string]string{
+	twins.OpEq: "$eq",
+	twins.OpNe: "$ne",
+	twins.OpGt: "$gt",
+	twins.OpGe: "$gte",
+	twins.OpLt: "$lt",
+	twins.OpLe: "$lte",
+}
+
 type stateRepository struct {
 	db *mongo.Database
 }
@@ -69,14 +81,15 @@ func (sr *stateRepository) Count(ctx context.Context, tw twins.Twin) (int64, err
 }
 
 // RetrieveAll retrieves the subset of states related to twin specified by id
-func (sr *stateRepository) RetrieveAll(ctx context.Context, offset uint64, limit uint64, twinID string) (twins.StatesPage, error) {
+// which satisfy the query
+func (sr *stateRepository) RetrieveAll(ctx context.Context, offset uint64, limit uint64, twinID string, query twins.StateQuery) (twins.StatesPage, error) {
 	coll := sr.db.Collection(statesCollection)
 
 	findOptions := options.Find()
 	findOptions.SetSkip(int64(offset))
 	findOptions.SetLimit(int64(limit))
 
-	filter := bson.M{twinid: twinID}
+	filter := stateFilter(twinID, query)
 
 	cur, err := coll.Find(ctx, filter, findOptions)
 	if err != nil {
@@ -103,6 +116,22 @@ func (sr *stateRepository) RetrieveAll(ctx context.Context, offset uint64, limit
 	}, nil
 }
 
+// RetrieveByID returns the state of the twin spec by id
+func (sr *stateRepository) RetrieveByID(ctx context.Context, twinID string, id int64) (twins.State, error) {
+	coll := sr.db.Collection(statesCollection)
+
+	var st twins.State
+	filter := bson.M{twinid: twinID, "id": id}
+	if err := coll.FindOne(ctx, filter).Decode(&st); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return twins.State{}, twins.ErrNotFound
+		}
+		return twins.State{}, err
+	}
+
+	return st, nil
+}
+
 // RetrieveLast returns the last state related to twin spec by id
 func (sr *stateRepository) RetrieveLast(ctx context.Context, twinID string) (twins.State, error) {
 	coll := sr.db.Collection(statesCollection)
@@ -137,6 +166,177 @@ func (sr *stateRepository) RetrieveLast(ctx context.Context, twinID string) (twi
 	return results[0], nil
 }
 
+// Aggregate groups the states of the twin into buckets using the
+// aggregation pipeline
+func (sr *stateRepository) Aggregate(ctx context.Context, twinID string, query twins.AggregationQuery) ([]twins.Bucket, error) {
+	coll := sr.db.Collection(statesCollection)
+
+	// Buckets are aligned to the Unix epoch: start = created - (created - epoch) % interval.
+	elapsed := bson.M{"$subtract": []interface{}{"$created", time.Unix(0, 0)}}
+	start := bson.M{"$subtract": []interface{}{"$created", bson.M{"$mod": []interface{}{elapsed, query.Interval.Milliseconds()}}}}
+
+	group := bson.M{
+		"_id":   start,
+		"count": bson.M{"$sum": 1},
+	}
+	for i, attr := range query.Attributes {
+		group[fmt.Sprintf("v%d", i)] = aggregation(query.Function, fmt.Sprintf("$payload.%s", attr))
+	}
+
+	pipeline := []bson.M{
+		{"$match": stateFilter(twinID, twins.StateQuery{From: query.From, To: query.To})},
+		{"$sort": bson.M{"id": 1}},
+		{"$group": group},
+		{"$sort": bson.M{"_id": 1}},
+		{"$limit": twins.MaxBuckets},
+	}
+
+	cur, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	buckets := []twins.Bucket{}
+	for cur.Next(ctx) {
+		var doc bson.M
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		b := twins.Bucket{Values: map[string]interface{}{}}
+		if start, ok := doc["_id"].(primitive.DateTime); ok {
+			b.Start = start.Time().UTC()
+		}
+		switch count := doc["count"].(type) {
+		case int32:
+			b.Count = int64(count)
+		case int64:
+			b.Count = count
+		}
+		for i, attr := range query.Attributes {
+			if val := doc[fmt.Sprintf("v%d", i)]; val != nil {
+				b.Values[attr] = val
+			}
+		}
+		buckets = append(buckets, b)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return buckets, nil
+}
+
+// aggregation returns the accumulator applying the function to the field.
+// Average, minimum and maximum consider numeric values only.
+func aggregation(fn, field string) bson.M {
+	numeric := bson.M{"$cond": []interface{}{
+		bson.M{"$in": []interface{}{bson.M{"$type": field}, []string{"double", "int", "long", "decimal"}}},
+		field,
+		nil,
+	}}
+
+	switch fn {
+	case twins.AggAvg:
+		return bson.M{"$avg": numeric}
+	case twins.AggMin:
+		return bson.M{"$min": numeric}
+	case twins.AggMax:
+		return bson.M{"$max": numeric}
+	default:
+		return bson.M{"$last": field}
+	}
+}
+
+// Purge removes the states of the twin exceeding the retention policy
+func (sr *stateRepository) Purge(ctx context.Context, twinID string, retention twins.Retention) (int64, error) {
+	coll := sr.db.Collection(statesCollection)
+
+	// The last state is always kept, since it holds the reported state.
+	last, ok, err := sr.retrieveNth(ctx, twinID, 0)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	var conds []bson.M
+	if retention.MaxStates > 0 {
+		oldest, ok, err := sr.retrieveNth(ctx, twinID, retention.MaxStates-1)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			conds = append(conds, bson.M{"id": bson.M{"$lt": oldest.ID}})
+		}
+	}
+	if retention.MaxAge > 0 {
+		cutoff := time.Now().Add(-time.Duration(retention.MaxAge) * time.Second)
+		conds = append(conds, bson.M{"created": bson.M{"$lt": cutoff}})
+	}
+	if len(conds) == 0 {
+		return 0, nil
+	}
+
+	filter := bson.M{
+		twinid: twinID,
+		"id":   bson.M{"$lt": last.ID},
+		"$or":  conds,
+	}
+	res, err := coll.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.DeletedCount, nil
+}
+
+// retrieveNth retrieves the n-th state of the twin, counting from the last.
+func (sr *stateRepository) retrieveNth(ctx context.Context, twinID string, n int64) (twins.State, bool, error) {
+	coll := sr.db.Collection(statesCollection)
+
+	findOptions := options.FindOne()
+	findOptions.SetSort(bson.M{"id": -1})
+	findOptions.SetSkip(n)
+
+	var st twins.State
+	if err := coll.FindOne(ctx, bson.M{twinid: twinID}, findOptions).Decode(&st); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return twins.State{}, false, nil
+		}
+		return twins.State{}, false, err
+	}
+
+	return st, true, nil
+}
+
+func stateFilter(twinID string, query twins.StateQuery) bson.M {
+	filter := bson.M{twinid: twinID}
+
+	for _, c := range query.Conditions {
+		key := fmt.Sprintf("payload.%s", c.Path)
+		cond, ok := filter[key].(bson.M)
+		if !ok {
+			cond = bson.M{}
+			filter[key] = cond
+		}
+		cond[operators[c.Operator]] = c.Value
+	}
+
+	created := bson.M{}
+	if !query.From.IsZero() {
+		created["$gte"] = query.From
+	}
+	if !query.To.IsZero() {
+		created["$lte"] = query.To
+	}
+	if len(created) > 0 {
+		filter["created"] = created
+	}
+
+	return filter
+}
+
 func decodeStates(ctx context.Context, cur *mongo.Cursor) ([]twins.State, error) {
 	defer cur.Close(ctx)
 
@@ -107,7 +107,7 @@ func TestStatesRetrieveAll(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		page, err := repo.RetrieveAll(context.Background(), tc.offset, tc.limit, tc.twid)
+		page, err := repo.RetrieveAll(context.Background(), tc.offset, tc.limit, tc.twid, twins.StateQuery{})
 		size := uint64(len(page.States))
 		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
 		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.total, page.Total))
@@ -158,3 +158,262 @@ func TestStatesRetrieveLast(t *testing.T) {
 		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %d\n", desc, err))
 	}
 }
+
+func TestStatesPurge(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(addr))
+	require.Nil(t, err, fmt.Sprintf("Creating new MongoDB client expected to succeed: %s.\n", err))
+
+	db := client.Database(testDB)
+	db.Collection("states").DeleteMany(context.Background(), bson.D{})
+
+	repo := mongodb.NewStateRepository(db)
+
+	twid, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	n := 10
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		st := twins.State{
+			TwinID:  twid,
+			ID:      int64(i),
+			Created: now.Add(time.Duration(i-n)*time.Hour + 30*time.Minute),
+		}
+		err := repo.Save(context.Background(), st)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	}
+
+	cases := []struct {
+		desc      string
+		retention twins.Retention
+		purged    int64
+		total     uint64
+	}{
+		{
+			desc:      "purge states without retention",
+			retention: twins.Retention{},
+			purged:    0,
+			total:     10,
+		},
+		{
+			desc:      "purge states older than 5 hours",
+			retention: twins.Retention{MaxAge: 5 * 3600},
+			purged:    5,
+			total:     5,
+		},
+		{
+			desc:      "purge states exceeding 3 states",
+			retention: twins.Retention{MaxStates: 3},
+			purged:    2,
+			total:     3,
+		},
+		{
+			desc:      "purge states keeping the last state",
+			retention: twins.Retention{MaxAge: 1},
+			purged:    2,
+			total:     1,
+		},
+	}
+
+	for _, tc := range cases {
+		purged, err := repo.Purge(context.Background(), twid, tc.retention)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.purged, purged, fmt.Sprintf("%s: expected %d purged got %d\n", tc.desc, tc.purged, purged))
+
+		page, err := repo.RetrieveAll(context.Background(), 0, uint64(n), twid, twins.StateQuery{})
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected %d total got %d\n", tc.desc, tc.total, page.Total))
+	}
+}
+
+func TestStatesRetrieveAllQuery(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(addr))
+	require.Nil(t, err, fmt.Sprintf("Creating new MongoDB client expected to succeed: %s.\n", err))
+
+	db := client.Database(testDB)
+	db.Collection("states").DeleteMany(context.Background(), bson.D{})
+
+	repo := mongodb.NewStateRepository(db)
+
+	twid, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	n := 10
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		temp := float64(20 + i)
+		mode := "eco"
+		if i%2 == 0 {
+			mode = "comfort"
+		}
+		st := twins.State{
+			TwinID:  twid,
+			ID:      int64(i),
+			Created: now.Add(time.Duration(i-n)*time.Hour + 30*time.Minute),
+			Payload: map[string]interface{}{"temperature": &temp, "mode": mode},
+		}
+		err := repo.Save(context.Background(), st)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	}
+
+	cases := []struct {
+		desc  string
+		query twins.StateQuery
+		total uint64
+	}{
+		{
+			desc:  "retrieve states with number condition",
+			query: twins.StateQuery{Conditions: []twins.Condition{{Path: "temperature", Operator: twins.OpGe, Value: 25.0}}},
+			total: 5,
+		},
+		{
+			desc:  "retrieve states with string condition",
+			query: twins.StateQuery{Conditions: []twins.Condition{{Path: "mode", Operator: twins.OpEq, Value: "eco"}}},
+			total: 5,
+		},
+		{
+			desc: "retrieve states with multiple conditions",
+			query: twins.StateQuery{Conditions: []twins.Condition{
+				{Path: "temperature", Operator: twins.OpGt, Value: 21.0},
+				{Path: "temperature", Operator: twins.OpLt, Value: 26.0},
+				{Path: "mode", Operator: twins.OpNe, Value: "eco"},
+			}},
+			total: 2,
+		},
+		{
+			desc:  "retrieve states within time range",
+			query: twins.StateQuery{From: now.Add(-3 * time.Hour), To: now},
+			total: 3,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := repo.RetrieveAll(context.Background(), 0, uint64(n), twid, tc.query)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected %d total got %d\n", tc.desc, tc.total, page.Total))
+	}
+}
+
+func TestStatesAggregate(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(addr))
+	require.Nil(t, err, fmt.Sprintf("Creating new MongoDB client expected to succeed: %s.\n", err))
+
+	db := client.Database(testDB)
+	db.Collection("states").DeleteMany(context.Background(), bson.D{})
+
+	repo := mongodb.NewStateRepository(db)
+
+	twid, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Day-aligned time of the first state.
+	start := time.Unix(1600041600, 0).UTC()
+	temps := []float64{20, 22, 30, 40}
+	modes := []string{"eco", "boost", "eco", "off"}
+	offsets := []time.Duration{0, 10 * time.Minute, time.Hour, time.Hour + 30*time.Minute}
+	for i := range temps {
+		st := twins.State{
+			TwinID:  twid,
+			ID:      int64(i),
+			Created: start.Add(offsets[i]),
+			Payload: map[string]interface{}{"temperature": &temps[i], "mode": &modes[i]},
+		}
+		err := repo.Save(context.Background(), st)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	}
+	attrs := []string{"temperature", "mode"}
+
+	cases := []struct {
+		desc    string
+		query   twins.AggregationQuery
+		buckets []twins.Bucket
+	}{
+		{
+			desc:  "aggregate last values",
+			query: twins.AggregationQuery{Interval: time.Hour, Function: twins.AggLast, Attributes: attrs},
+			buckets: []twins.Bucket{
+				{Start: start, Count: 2, Values: map[string]interface{}{"temperature": 22.0, "mode": "boost"}},
+				{Start: start.Add(time.Hour), Count: 2, Values: map[string]interface{}{"temperature": 40.0, "mode": "off"}},
+			},
+		},
+		{
+			desc:  "aggregate average values",
+			query: twins.AggregationQuery{Interval: time.Hour, Function: twins.AggAvg, Attributes: attrs},
+			buckets: []twins.Bucket{
+				{Start: start, Count: 2, Values: map[string]interface{}{"temperature": 21.0}},
+				{Start: start.Add(time.Hour), Count: 2, Values: map[string]interface{}{"temperature": 35.0}},
+			},
+		},
+		{
+			desc:  "aggregate minimum values",
+			query: twins.AggregationQuery{Interval: 2 * time.Hour, Function: twins.AggMin, Attributes: attrs},
+			buckets: []twins.Bucket{
+				{Start: start, Count: 4, Values: map[string]interface{}{"temperature": 20.0}},
+			},
+		},
+		{
+			desc:  "aggregate maximum values within range",
+			query: twins.AggregationQuery{Interval: time.Hour, Function: twins.AggMax, Attributes: attrs, From: start.Add(5 * time.Minute), To: start.Add(time.Hour)},
+			buckets: []twins.Bucket{
+				{Start: start, Count: 1, Values: map[string]interface{}{"temperature": 22.0}},
+				{Start: start.Add(time.Hour), Count: 1, Values: map[string]interface{}{"temperature": 30.0}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		buckets, err := repo.Aggregate(context.Background(), twid, tc.query)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.buckets, buckets, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.buckets, buckets))
+	}
+}
+
+func TestStatesRetrieveByID(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(addr))
+	require.Nil(t, err, fmt.Sprintf("Creating new MongoDB client expected to succeed: %s.\n", err))
+
+	db := client.Database(testDB)
+	repo := mongodb.NewStateRepository(db)
+
+	twid, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	st := twins.State{
+		TwinID:  twid,
+		ID:      3,
+		Created: time.Now(),
+	}
+	err = repo.Save(context.Background(), st)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	cases := []struct {
+		desc string
+		twid string
+		id   int64
+		err  error
+	}{
+		{
+			desc: "retrieve existing state",
+			twid: twid,
+			id:   3,
+			err:  nil,
+		},
+		{
+			desc: "retrieve non-existent state",
+			twid: twid,
+			id:   4,
+			err:  twins.ErrNotFound,
+		},
+		{
+			desc: "retrieve state of non-existent twin",
+			twid: wrongID,
+			id:   3,
+			err:  twins.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := repo.RetrieveByID(context.Background(), tc.twid, tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq" // required for SQL access
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Config defines the options that are used when connecting to a PostgreSQL instance
+type Config struct {
+	Host        string
+	Port        string
+	User        string
+	Pass        string
+	Name        string
+	SSLMode     string
+	SSLCert     string
+	SSLKey      string
+	SSLRootCert string
+}
+
+// Connect creates a connection to the PostgreSQL instance and applies any
+// unapplied database migrations. A non-nil error is returned to indicate
+// failure.
+func Connect(cfg Config) (*sqlx.DB, error) {
+	url := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s sslcert=%s sslkey=%s sslrootcert=%s", cfg.Host, cfg.Port, cfg.User, cfg.Name, cfg.Pass, cfg.SSLMode, cfg.SSLCert, cfg.SSLKey, cfg.SSLRootCert)
+
+	db, err := sqlx.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateDB(db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func migrateDB(db *sqlx.DB) error {
+	migrations := &migrate.MemoryMigrationSource{
+		Migrations: []*migrate.Migration{
+			{
+				Id: "twins_1",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS twins (
+						id          VARCHAR(254) PRIMARY KEY,
+						owner       VARCHAR(254),
+						thing_id    VARCHAR(254),
+						name        VARCHAR(1024),
+						created     TIMESTAMPTZ,
+						updated     TIMESTAMPTZ,
+						revision    INTEGER,
+						definitions JSONB,
+						metadata    JSONB,
+						desired     JSONB,
+						retention   JSONB
+					)`,
+					`CREATE TABLE IF NOT EXISTS states (
+						twin_id    VARCHAR(254),
+						id         BIGINT,
+						definition INTEGER,
+						created    TIMESTAMPTZ,
+						payload    JSONB,
+						PRIMARY KEY (twin_id, id)
+					)`,
+					`CREATE INDEX IF NOT EXISTS states_created_idx ON states (twin_id, created)`,
+				},
+				Down: []string{
+					"DROP TABLE states",
+					"DROP TABLE twins",
+				},
+			},
+		},
+	}
+
+	_, err := migrate.Exec(db.DB, "postgres", migrations, migrate.Up)
+	return err
+}
@@ -0,0 +1,83 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/twins"
+)
+
+// PurgeStates periodically removes the states exceeding the retention
+// policies of the twins, until the context is canceled. Non-positive
+// interval disables purging.
+func PurgeStates(ctx context.Context, db *sqlx.DB, interval time.Duration, logger logger.Logger) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := purge(ctx, db)
+			if err != nil {
+				logger.Warn(fmt.Sprintf("Failed to purge states: %s", err))
+				continue
+			}
+			if n > 0 {
+				logger.Info(fmt.Sprintf("Purged %d states", n))
+			}
+		}
+	}
+}
+
+func purge(ctx context.Context, db *sqlx.DB) (int64, error) {
+	q := `SELECT id, retention FROM twins
+		  WHERE CAST(retention ->> 'max_states' AS BIGINT) > 0
+		  OR CAST(retention ->> 'max_age' AS BIGINT) > 0;`
+
+	rows, err := db.QueryxContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+
+	retentions := map[string]twins.Retention{}
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		var r twins.Retention
+		if err := json.Unmarshal(data, &r); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		retentions[id] = r
+	}
+	rows.Close()
+
+	repo := NewStateRepository(db)
+	var total int64
+	for id, r := range retentions {
+		n, err := repo.Purge(ctx, id, r)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+
+	return total, nil
+}
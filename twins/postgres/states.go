@@ -0,0 +1,402 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/twins"
+)
+
+var operators = map[string]string{
+	twins.OpEq: "=",
+	twins.OpNe: "IS DISTINCT FROM",
+	twins.OpGt: ">",
+	twins.OpGe: ">=",
+	twins.OpLt: "<",
+	twins.OpLe: "<=",
+}
+
+type stateRepository struct {
+	db *sqlx.DB
+}
+
+var _ twins.StateRepository = (*stateRepository)(nil)
+
+// NewStateRepository instantiates a PostgreSQL implementation of state
+// repository.
+func NewStateRepository(db *sqlx.DB) twins.StateRepository {
+	return &stateRepository{
+		db: db,
+	}
+}
+
+// Save persists the state
+func (sr *stateRepository) Save(ctx context.Context, st twins.State) error {
+	dbst, err := toDBState(st)
+	if err != nil {
+		return err
+	}
+
+	q := `INSERT INTO states (twin_id, id, definition, created, payload)
+		  VALUES (:twin_id, :id, :definition, :created, :payload);`
+
+	if _, err := sr.db.NamedExecContext(ctx, q, dbst); err != nil {
+		return convertError(err)
+	}
+
+	return nil
+}
+
+// Update persists the state
+func (sr *stateRepository) Update(ctx context.Context, st twins.State) error {
+	dbst, err := toDBState(st)
+	if err != nil {
+		return err
+	}
+
+	q := `UPDATE states SET definition = :definition, created = :created, payload = :payload
+		  WHERE twin_id = :twin_id AND id = :id;`
+
+	if _, err := sr.db.NamedExecContext(ctx, q, dbst); err != nil {
+		return convertError(err)
+	}
+
+	return nil
+}
+
+// Count returns the number of states related to twin
+func (sr *stateRepository) Count(ctx context.Context, tw twins.Twin) (int64, error) {
+	q := `SELECT COUNT(*) FROM states WHERE twin_id = $1;`
+
+	var total int64
+	if err := sr.db.GetContext(ctx, &total, q, tw.ID); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// RetrieveAll retrieves the subset of states related to twin specified by id
+// which satisfy the query
+func (sr *stateRepository) RetrieveAll(ctx context.Context, offset uint64, limit uint64, twinID string, query twins.StateQuery) (twins.StatesPage, error) {
+	fq, params, err := stateFilter(twinID, query)
+	if err != nil {
+		return twins.StatesPage{}, err
+	}
+	params["limit"] = limit
+	params["offset"] = offset
+
+	q := fmt.Sprintf(`SELECT twin_id, id, definition, created, payload FROM states
+		  WHERE %s ORDER BY id LIMIT :limit OFFSET :offset;`, fq)
+
+	rows, err := sr.db.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return twins.StatesPage{}, err
+	}
+	defer rows.Close()
+
+	var items []twins.State
+	for rows.Next() {
+		var dbst dbState
+		if err := rows.StructScan(&dbst); err != nil {
+			return twins.StatesPage{}, err
+		}
+
+		st, err := toState(dbst)
+		if err != nil {
+			return twins.StatesPage{}, err
+		}
+
+		items = append(items, st)
+	}
+
+	cq := fmt.Sprintf(`SELECT COUNT(*) FROM states WHERE %s;`, fq)
+	total, err := total(ctx, sr.db, cq, params)
+	if err != nil {
+		return twins.StatesPage{}, err
+	}
+
+	return twins.StatesPage{
+		States: items,
+		PageMetadata: twins.PageMetadata{
+			Total:  total,
+			Offset: offset,
+			Limit:  limit,
+		},
+	}, nil
+}
+
+// RetrieveByID returns the state of the twin spec by id
+func (sr *stateRepository) RetrieveByID(ctx context.Context, twinID string, id int64) (twins.State, error) {
+	q := `SELECT twin_id, id, definition, created, payload FROM states WHERE twin_id = $1 AND id = $2;`
+
+	var dbst dbState
+	if err := sr.db.QueryRowxContext(ctx, q, twinID, id).StructScan(&dbst); err != nil {
+		if err == sql.ErrNoRows {
+			return twins.State{}, twins.ErrNotFound
+		}
+		return twins.State{}, err
+	}
+
+	return toState(dbst)
+}
+
+// RetrieveLast returns the last state related to twin spec by id
+func (sr *stateRepository) RetrieveLast(ctx context.Context, twinID string) (twins.State, error) {
+	st, _, err := sr.retrieveNth(ctx, twinID, 0)
+	return st, err
+}
+
+// Aggregate groups the states of the twin into buckets, aligned to the Unix
+// epoch, and reduces the attribute values of each bucket
+func (sr *stateRepository) Aggregate(ctx context.Context, twinID string, query twins.AggregationQuery) ([]twins.Bucket, error) {
+	fq, params, err := stateFilter(twinID, twins.StateQuery{From: query.From, To: query.To})
+	if err != nil {
+		return nil, err
+	}
+	params["interval"] = query.Interval.Milliseconds()
+	params["limit"] = twins.MaxBuckets
+
+	var cols string
+	for i, attr := range query.Attributes {
+		key := fmt.Sprintf("attr%d", i)
+		params[key] = pq.Array(strings.Split(attr, "."))
+		cols += fmt.Sprintf(", %s AS v%d", aggregation(query.Function, key), i)
+	}
+
+	q := fmt.Sprintf(`SELECT to_timestamp(floor(extract(epoch FROM created) * 1000 / :interval) * :interval / 1000) AS start,
+		  COUNT(*) AS count%s
+		  FROM states WHERE %s GROUP BY start ORDER BY start LIMIT :limit;`, cols, fq)
+
+	rows, err := sr.db.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []twins.Bucket{}
+	for rows.Next() {
+		var start time.Time
+		var count int64
+		vals := make([][]byte, len(query.Attributes))
+		dest := []interface{}{&start, &count}
+		for i := range vals {
+			dest = append(dest, &vals[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		b := twins.Bucket{
+			Start:  start.UTC(),
+			Count:  count,
+			Values: map[string]interface{}{},
+		}
+		for i, attr := range query.Attributes {
+			if vals[i] == nil {
+				continue
+			}
+			var val interface{}
+			if err := json.Unmarshal(vals[i], &val); err != nil {
+				return nil, err
+			}
+			if val != nil {
+				b.Values[attr] = val
+			}
+		}
+		buckets = append(buckets, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return buckets, nil
+}
+
+// aggregation returns the aggregate expression applying the function to the
+// payload value at the path bound to the key. Average, minimum and maximum
+// consider numeric values only.
+func aggregation(fn, key string) string {
+	val := fmt.Sprintf("payload #> :%s", key)
+	numeric := fmt.Sprintf("CASE WHEN jsonb_typeof(%s) = 'number' THEN CAST(payload #>> :%s AS DOUBLE PRECISION) END", val, key)
+
+	switch fn {
+	case twins.AggAvg:
+		return fmt.Sprintf("to_jsonb(AVG(%s))", numeric)
+	case twins.AggMin:
+		return fmt.Sprintf("to_jsonb(MIN(%s))", numeric)
+	case twins.AggMax:
+		return fmt.Sprintf("to_jsonb(MAX(%s))", numeric)
+	default:
+		return fmt.Sprintf("(array_agg(%s ORDER BY id DESC) FILTER (WHERE jsonb_typeof(%s) <> 'null'))[1]", val, val)
+	}
+}
+
+// Purge removes the states of the twin exceeding the retention policy
+func (sr *stateRepository) Purge(ctx context.Context, twinID string, retention twins.Retention) (int64, error) {
+	// The last state is always kept, since it holds the reported state.
+	last, ok, err := sr.retrieveNth(ctx, twinID, 0)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	params := map[string]interface{}{
+		"twin_id": twinID,
+		"last":    last.ID,
+	}
+
+	var conds []string
+	if retention.MaxStates > 0 {
+		oldest, ok, err := sr.retrieveNth(ctx, twinID, retention.MaxStates-1)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			conds = append(conds, "id < :oldest")
+			params["oldest"] = oldest.ID
+		}
+	}
+	if retention.MaxAge > 0 {
+		conds = append(conds, "created < :cutoff")
+		params["cutoff"] = time.Now().Add(-time.Duration(retention.MaxAge) * time.Second)
+	}
+	if len(conds) == 0 {
+		return 0, nil
+	}
+
+	q := fmt.Sprintf(`DELETE FROM states WHERE twin_id = :twin_id AND id < :last AND (%s);`, strings.Join(conds, " OR "))
+
+	res, err := sr.db.NamedExecContext(ctx, q, params)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// retrieveNth retrieves the n-th state of the twin, counting from the last.
+func (sr *stateRepository) retrieveNth(ctx context.Context, twinID string, n int64) (twins.State, bool, error) {
+	q := `SELECT twin_id, id, definition, created, payload FROM states
+		  WHERE twin_id = $1 ORDER BY id DESC LIMIT 1 OFFSET $2;`
+
+	var dbst dbState
+	if err := sr.db.QueryRowxContext(ctx, q, twinID, n).StructScan(&dbst); err != nil {
+		if err == sql.ErrNoRows {
+			return twins.State{}, false, nil
+		}
+		return twins.State{}, false, err
+	}
+
+	st, err := toState(dbst)
+	if err != nil {
+		return twins.State{}, false, err
+	}
+
+	return st, true, nil
+}
+
+// stateFilter returns the condition of the WHERE clause matching the states
+// of the twin which satisfy the query, along with its named parameters.
+func stateFilter(twinID string, query twins.StateQuery) (string, map[string]interface{}, error) {
+	conds := []string{"twin_id = :twin_id"}
+	params := map[string]interface{}{
+		"twin_id": twinID,
+	}
+
+	for i, c := range query.Conditions {
+		op, ok := operators[c.Operator]
+		if !ok {
+			return "", nil, twins.ErrMalformedEntity
+		}
+		val, err := json.Marshal(c.Value)
+		if err != nil {
+			return "", nil, twins.ErrMalformedEntity
+		}
+
+		path, value := fmt.Sprintf("path%d", i), fmt.Sprintf("value%d", i)
+		params[path] = pq.Array(strings.Split(c.Path, "."))
+		params[value] = val
+
+		cond := fmt.Sprintf("payload #> :%s %s :%s", path, op, value)
+		if c.Operator != twins.OpEq && c.Operator != twins.OpNe {
+			// JSONB orders the values of different types, so the
+			// comparison has to be limited to the values of the same type.
+			typ := fmt.Sprintf("type%d", i)
+			params[typ] = jsonType(c.Value)
+			cond = fmt.Sprintf("jsonb_typeof(payload #> :%s) = :%s AND %s", path, typ, cond)
+		}
+		conds = append(conds, cond)
+	}
+
+	if !query.From.IsZero() {
+		conds = append(conds, "created >= :from")
+		params["from"] = query.From
+	}
+	if !query.To.IsZero() {
+		conds = append(conds, "created <= :to")
+		params["to"] = query.To
+	}
+
+	return strings.Join(conds, " AND "), params, nil
+}
+
+func jsonType(val interface{}) string {
+	switch val.(type) {
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+type dbState struct {
+	TwinID     string    `db:"twin_id"`
+	ID         int64     `db:"id"`
+	Definition int       `db:"definition"`
+	Created    time.Time `db:"created"`
+	Payload    []byte    `db:"payload"`
+}
+
+func toDBState(st twins.State) (dbState, error) {
+	payload, err := json.Marshal(st.Payload)
+	if err != nil {
+		return dbState{}, twins.ErrMalformedEntity
+	}
+
+	return dbState{
+		TwinID:     st.TwinID,
+		ID:         st.ID,
+		Definition: st.Definition,
+		Created:    st.Created,
+		Payload:    payload,
+	}, nil
+}
+
+func toState(dbst dbState) (twins.State, error) {
+	st := twins.State{
+		TwinID:     dbst.TwinID,
+		ID:         dbst.ID,
+		Definition: dbst.Definition,
+		Created:    dbst.Created.UTC(),
+	}
+
+	if dbst.Payload != nil {
+		if err := json.Unmarshal(dbst.Payload, &st.Payload); err != nil {
+			return twins.State{}, err
+		}
+	}
+
+	return st, nil
+}
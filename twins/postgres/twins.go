@@ -0,0 +1,339 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/twins"
+)
+
+const (
+	errDuplicate  = "unique_violation"
+	errInvalid    = "invalid_text_representation"
+	errTruncation = "string_data_right_truncation"
+
+	maxNameSize = 1024
+)
+
+type twinRepository struct {
+	db *sqlx.DB
+}
+
+var _ twins.TwinRepository = (*twinRepository)(nil)
+
+// NewTwinRepository instantiates a PostgreSQL implementation of twin
+// repository.
+func NewTwinRepository(db *sqlx.DB) twins.TwinRepository {
+	return &twinRepository{
+		db: db,
+	}
+}
+
+func (tr *twinRepository) Save(ctx context.Context, tw twins.Twin) (string, error) {
+	if len(tw.Name) > maxNameSize {
+		return "", twins.ErrMalformedEntity
+	}
+
+	dbtw, err := toDBTwin(tw)
+	if err != nil {
+		return "", err
+	}
+
+	q := `INSERT INTO twins (id, owner, thing_id, name, created, updated, revision, definitions, metadata, desired, retention)
+		  VALUES (:id, :owner, :thing_id, :name, :created, :updated, :revision, :definitions, :metadata, :desired, :retention);`
+
+	if _, err := tr.db.NamedExecContext(ctx, q, dbtw); err != nil {
+		return "", convertError(err)
+	}
+
+	return tw.ID, nil
+}
+
+func (tr *twinRepository) Update(ctx context.Context, tw twins.Twin) error {
+	if len(tw.Name) > maxNameSize {
+		return twins.ErrMalformedEntity
+	}
+
+	dbtw, err := toDBTwin(tw)
+	if err != nil {
+		return err
+	}
+
+	q := `UPDATE twins SET owner = :owner, thing_id = :thing_id, name = :name, created = :created, updated = :updated,
+		  revision = :revision, definitions = :definitions, metadata = :metadata, desired = :desired, retention = :retention
+		  WHERE id = :id;`
+
+	res, err := tr.db.NamedExecContext(ctx, q, dbtw)
+	if err != nil {
+		return convertError(err)
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return twins.ErrNotFound
+	}
+
+	return nil
+}
+
+func (tr *twinRepository) RetrieveByID(ctx context.Context, twinID string) (twins.Twin, error) {
+	q := `SELECT id, owner, thing_id, name, created, updated, revision, definitions, metadata, desired, retention
+		  FROM twins WHERE id = $1;`
+
+	var dbtw dbTwin
+	if err := tr.db.QueryRowxContext(ctx, q, twinID).StructScan(&dbtw); err != nil {
+		if err == sql.ErrNoRows {
+			return twins.Twin{}, twins.ErrNotFound
+		}
+		return twins.Twin{}, err
+	}
+
+	return toTwin(dbtw)
+}
+
+func (tr *twinRepository) RetrieveByAttribute(ctx context.Context, channel, subtopic string) ([]string, error) {
+	// Only the attributes of the latest definition are matched.
+	q := `SELECT id FROM twins
+		  WHERE definitions -> -1 -> 'attributes' @> :attribute
+		  OR definitions -> -1 -> 'attributes' @> :wildcard;`
+
+	attribute, err := json.Marshal([]map[string]string{{"channel": channel, "subtopic": subtopic}})
+	if err != nil {
+		return nil, err
+	}
+	wildcard, err := json.Marshal([]map[string]string{{"channel": channel, "subtopic": twins.SubtopicWildcard}})
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{
+		"attribute": attribute,
+		"wildcard":  wildcard,
+	}
+
+	rows, err := tr.db.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func (tr *twinRepository) RetrieveAll(ctx context.Context, owner string, offset uint64, limit uint64, name string, metadata twins.Metadata) (twins.Page, error) {
+	var conds []string
+	params := map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	if owner != "" {
+		conds = append(conds, "owner = :owner")
+		params["owner"] = owner
+	}
+	if name != "" {
+		conds = append(conds, "name = :name")
+		params["name"] = name
+	}
+	if len(metadata) > 0 {
+		m, err := json.Marshal(metadata)
+		if err != nil {
+			return twins.Page{}, twins.ErrMalformedEntity
+		}
+		conds = append(conds, "metadata @> :metadata")
+		params["metadata"] = m
+	}
+
+	var wq string
+	if len(conds) > 0 {
+		wq = fmt.Sprintf("WHERE %s", strings.Join(conds, " AND "))
+	}
+
+	q := fmt.Sprintf(`SELECT id, owner, thing_id, name, created, updated, revision, definitions, metadata, desired, retention
+		  FROM twins %s ORDER BY created, id LIMIT :limit OFFSET :offset;`, wq)
+
+	rows, err := tr.db.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return twins.Page{}, err
+	}
+	defer rows.Close()
+
+	var items []twins.Twin
+	for rows.Next() {
+		var dbtw dbTwin
+		if err := rows.StructScan(&dbtw); err != nil {
+			return twins.Page{}, err
+		}
+
+		tw, err := toTwin(dbtw)
+		if err != nil {
+			return twins.Page{}, err
+		}
+
+		items = append(items, tw)
+	}
+
+	cq := fmt.Sprintf(`SELECT COUNT(*) FROM twins %s;`, wq)
+	total, err := total(ctx, tr.db, cq, params)
+	if err != nil {
+		return twins.Page{}, err
+	}
+
+	return twins.Page{
+		Twins: items,
+		PageMetadata: twins.PageMetadata{
+			Total:  total,
+			Offset: offset,
+			Limit:  limit,
+		},
+	}, nil
+}
+
+func (tr *twinRepository) Remove(ctx context.Context, twinID string) error {
+	q := `DELETE FROM twins WHERE id = $1;`
+
+	res, err := tr.db.ExecContext(ctx, q, twinID)
+	if err != nil {
+		return err
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return twins.ErrNotFound
+	}
+
+	return nil
+}
+
+type dbTwin struct {
+	ID          string    `db:"id"`
+	Owner       string    `db:"owner"`
+	ThingID     string    `db:"thing_id"`
+	Name        string    `db:"name"`
+	Created     time.Time `db:"created"`
+	Updated     time.Time `db:"updated"`
+	Revision    int       `db:"revision"`
+	Definitions []byte    `db:"definitions"`
+	Metadata    []byte    `db:"metadata"`
+	Desired     []byte    `db:"desired"`
+	Retention   []byte    `db:"retention"`
+}
+
+func toDBTwin(tw twins.Twin) (dbTwin, error) {
+	dbtw := dbTwin{
+		ID:       tw.ID,
+		Owner:    tw.Owner,
+		ThingID:  tw.ThingID,
+		Name:     tw.Name,
+		Created:  tw.Created,
+		Updated:  tw.Updated,
+		Revision: tw.Revision,
+	}
+
+	fields := []struct {
+		dst *[]byte
+		val interface{}
+	}{
+		{&dbtw.Definitions, tw.Definitions},
+		{&dbtw.Metadata, tw.Metadata},
+		{&dbtw.Desired, tw.Desired},
+		{&dbtw.Retention, tw.Retention},
+	}
+	for _, f := range fields {
+		b, err := json.Marshal(f.val)
+		if err != nil {
+			return dbTwin{}, twins.ErrMalformedEntity
+		}
+		*f.dst = b
+	}
+
+	return dbtw, nil
+}
+
+func toTwin(dbtw dbTwin) (twins.Twin, error) {
+	tw := twins.Twin{
+		ID:       dbtw.ID,
+		Owner:    dbtw.Owner,
+		ThingID:  dbtw.ThingID,
+		Name:     dbtw.Name,
+		Created:  dbtw.Created.UTC(),
+		Updated:  dbtw.Updated.UTC(),
+		Revision: dbtw.Revision,
+	}
+
+	fields := []struct {
+		src []byte
+		dst interface{}
+	}{
+		{dbtw.Definitions, &tw.Definitions},
+		{dbtw.Metadata, &tw.Metadata},
+		{dbtw.Desired, &tw.Desired},
+		{dbtw.Retention, &tw.Retention},
+	}
+	for _, f := range fields {
+		if f.src == nil {
+			continue
+		}
+		if err := json.Unmarshal(f.src, f.dst); err != nil {
+			return twins.Twin{}, err
+		}
+	}
+
+	return tw, nil
+}
+
+func total(ctx context.Context, db *sqlx.DB, query string, params interface{}) (uint64, error) {
+	rows, err := db.NamedQueryContext(ctx, query, params)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var total uint64
+	if rows.Next() {
+		if err := rows.Scan(&total); err != nil {
+			return 0, err
+		}
+	}
+
+	return total, nil
+}
+
+// convertError maps the PostgreSQL errors to the twins errors.
+func convertError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code.Name() {
+		case errInvalid, errTruncation:
+			return twins.ErrMalformedEntity
+		case errDuplicate:
+			return twins.ErrConflict
+		}
+	}
+
+	return err
+}
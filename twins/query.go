@@ -0,0 +1,179 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package twins
+
+import (
+	"strings"
+	"time"
+)
+
+// Comparison operators of the state query conditions.
+const (
+	OpEq = "eq"
+	OpNe = "ne"
+	OpGt = "gt"
+	OpGe = "ge"
+	OpLt = "lt"
+	OpLe = "le"
+)
+
+var operators = map[string]bool{
+	OpEq: true,
+	OpNe: true,
+	OpGt: true,
+	OpGe: true,
+	OpLt: true,
+	OpLe: true,
+}
+
+// Condition compares the value found at the path of the state payload with
+// the provided value. The path is dot-separated and starts with the attribute
+// name (e.g. `temperature`). The value is a number, string or boolean.
+type Condition struct {
+	Path     string
+	Operator string
+	Value    interface{}
+}
+
+// StateQuery filters the states by the conditions, all of which have to be
+// satisfied, and by the range of the state creation time. Zero From or To
+// leaves the range open.
+type StateQuery struct {
+	Conditions []Condition
+	From       time.Time
+	To         time.Time
+}
+
+// Validate returns an error if the query is malformed.
+func (q StateQuery) Validate() error {
+	for _, c := range q.Conditions {
+		if c.Path == "" || !operators[c.Operator] {
+			return ErrMalformedEntity
+		}
+		switch c.Value.(type) {
+		case float64, string:
+		case bool:
+			if c.Operator != OpEq && c.Operator != OpNe {
+				return ErrMalformedEntity
+			}
+		default:
+			return ErrMalformedEntity
+		}
+	}
+
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return ErrMalformedEntity
+	}
+
+	return nil
+}
+
+// Match reports whether the state satisfies the query.
+func (q StateQuery) Match(st State) bool {
+	if !q.From.IsZero() && st.Created.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && st.Created.After(q.To) {
+		return false
+	}
+
+	for _, c := range q.Conditions {
+		if !c.match(lookup(st.Payload, c.Path)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c Condition) match(val interface{}) bool {
+	cmp, ok := compare(val, c.Value)
+	if !ok {
+		// Values of different types are never equal.
+		return c.Operator == OpNe
+	}
+
+	switch c.Operator {
+	case OpEq:
+		return cmp == 0
+	case OpNe:
+		return cmp != 0
+	case OpGt:
+		return cmp > 0
+	case OpGe:
+		return cmp >= 0
+	case OpLt:
+		return cmp < 0
+	case OpLe:
+		return cmp <= 0
+	default:
+		return false
+	}
+}
+
+// compare returns -1, 0 or 1 if a is less than, equal to or greater than b.
+// The second result is false if the values aren't comparable.
+func compare(a, b interface{}) (int, bool) {
+	switch v := deref(a).(type) {
+	case float64:
+		w, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case v < w:
+			return -1, true
+		case v > w:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		w, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(v, w), true
+	case bool:
+		w, ok := b.(bool)
+		if !ok || v != w {
+			return 1, ok
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// lookup returns the value found at the dot-separated path of the payload.
+func lookup(payload map[string]interface{}, path string) interface{} {
+	var val interface{} = payload
+	for _, key := range strings.Split(path, ".") {
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		val = m[key]
+	}
+	return val
+}
+
+func deref(val interface{}) interface{} {
+	switch v := val.(type) {
+	case *float64:
+		if v != nil {
+			return *v
+		}
+	case *string:
+		if v != nil {
+			return *v
+		}
+	case *bool:
+		if v != nil {
+			return *v
+		}
+	default:
+		return val
+	}
+	return nil
+}
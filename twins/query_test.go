@@ -0,0 +1,160 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package twins_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/twins"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateQueryValidate(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		desc  string
+		query twins.StateQuery
+		err   error
+	}{
+		{
+			desc:  "validate empty query",
+			query: twins.StateQuery{},
+			err:   nil,
+		},
+		{
+			desc: "validate query with conditions and time range",
+			query: twins.StateQuery{
+				Conditions: []twins.Condition{
+					{Path: "temperature", Operator: twins.OpGt, Value: 30.0},
+					{Path: "mode", Operator: twins.OpEq, Value: "eco"},
+					{Path: "on", Operator: twins.OpNe, Value: true},
+				},
+				From: now.Add(-time.Hour),
+				To:   now,
+			},
+			err: nil,
+		},
+		{
+			desc:  "validate query with empty path",
+			query: twins.StateQuery{Conditions: []twins.Condition{{Operator: twins.OpEq, Value: 1.0}}},
+			err:   twins.ErrMalformedEntity,
+		},
+		{
+			desc:  "validate query with unknown operator",
+			query: twins.StateQuery{Conditions: []twins.Condition{{Path: "temperature", Operator: "like", Value: 1.0}}},
+			err:   twins.ErrMalformedEntity,
+		},
+		{
+			desc:  "validate query ordering boolean values",
+			query: twins.StateQuery{Conditions: []twins.Condition{{Path: "on", Operator: twins.OpGt, Value: true}}},
+			err:   twins.ErrMalformedEntity,
+		},
+		{
+			desc:  "validate query with unsupported value",
+			query: twins.StateQuery{Conditions: []twins.Condition{{Path: "temperature", Operator: twins.OpEq, Value: 1}}},
+			err:   twins.ErrMalformedEntity,
+		},
+		{
+			desc:  "validate query with inverted time range",
+			query: twins.StateQuery{From: now, To: now.Add(-time.Hour)},
+			err:   twins.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		err := tc.query.Validate()
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestStateQueryMatch(t *testing.T) {
+	temp := 31.5
+	now := time.Now()
+	st := twins.State{
+		Created: now.Add(-time.Hour),
+		Payload: map[string]interface{}{
+			"temperature": &temp,
+			"mode":        "eco",
+			"on":          true,
+			"engine":      map[string]interface{}{"rpm": 3000.0},
+		},
+	}
+
+	cond := func(path, op string, val interface{}) []twins.Condition {
+		return []twins.Condition{{Path: path, Operator: op, Value: val}}
+	}
+
+	cases := []struct {
+		desc  string
+		query twins.StateQuery
+		match bool
+	}{
+		{
+			desc:  "match empty query",
+			query: twins.StateQuery{},
+			match: true,
+		},
+		{
+			desc:  "match greater number",
+			query: twins.StateQuery{Conditions: cond("temperature", twins.OpGt, 30.0)},
+			match: true,
+		},
+		{
+			desc:  "match less or equal number",
+			query: twins.StateQuery{Conditions: cond("temperature", twins.OpLe, 30.0)},
+			match: false,
+		},
+		{
+			desc:  "match equal string",
+			query: twins.StateQuery{Conditions: cond("mode", twins.OpEq, "eco")},
+			match: true,
+		},
+		{
+			desc:  "match not equal boolean",
+			query: twins.StateQuery{Conditions: cond("on", twins.OpNe, true)},
+			match: false,
+		},
+		{
+			desc:  "match nested path",
+			query: twins.StateQuery{Conditions: cond("engine.rpm", twins.OpGe, 3000.0)},
+			match: true,
+		},
+		{
+			desc:  "match value of different type",
+			query: twins.StateQuery{Conditions: cond("mode", twins.OpGt, 1.0)},
+			match: false,
+		},
+		{
+			desc:  "match not equal missing value",
+			query: twins.StateQuery{Conditions: cond("unknown", twins.OpNe, 1.0)},
+			match: true,
+		},
+		{
+			desc: "match all conditions",
+			query: twins.StateQuery{Conditions: []twins.Condition{
+				{Path: "temperature", Operator: twins.OpGt, Value: 30.0},
+				{Path: "mode", Operator: twins.OpEq, Value: "comfort"},
+			}},
+			match: false,
+		},
+		{
+			desc:  "match time range",
+			query: twins.StateQuery{From: now.Add(-2 * time.Hour), To: now},
+			match: true,
+		},
+		{
+			desc:  "match time range excluding state",
+			query: twins.StateQuery{From: now.Add(-30 * time.Minute)},
+			match: false,
+		},
+	}
+
+	for _, tc := range cases {
+		match := tc.query.Match(st)
+		assert.Equal(t, tc.match, match, fmt.Sprintf("%s: expected %t got %t\n", tc.desc, tc.match, match))
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"encoding/json"
+
+	"github.com/mainflux/mainflux/twins"
+)
+
+const (
+	twinPrefix  = "twin."
+	twinCreate  = twinPrefix + "created"
+	twinUpdate  = twinPrefix + "updated"
+	twinRemove  = twinPrefix + "deleted"
+	statePrefix = "state."
+	stateSave   = statePrefix + "saved"
+)
+
+type event interface {
+	Encode() map[string]interface{}
+}
+
+var (
+	_ event = (*createTwinEvent)(nil)
+	_ event = (*updateTwinEvent)(nil)
+	_ event = (*removeTwinEvent)(nil)
+	_ event = (*saveStateEvent)(nil)
+)
+
+type createTwinEvent struct {
+	id         string
+	owner      string
+	thingID    string
+	name       string
+	metadata   map[string]interface{}
+	definition twins.Definition
+}
+
+func newCreateTwinEvent(tw twins.Twin) createTwinEvent {
+	event := createTwinEvent{
+		id:       tw.ID,
+		owner:    tw.Owner,
+		thingID:  tw.ThingID,
+		name:     tw.Name,
+		metadata: tw.Metadata,
+	}
+	if len(tw.Definitions) > 0 {
+		event.definition = tw.Definitions[len(tw.Definitions)-1]
+	}
+
+	return event
+}
+
+func (cte createTwinEvent) Encode() map[string]interface{} {
+	val := map[string]interface{}{
+		"id":        cte.id,
+		"owner":     cte.owner,
+		"operation": twinCreate,
+	}
+
+	if cte.thingID != "" {
+		val["thing_id"] = cte.thingID
+	}
+
+	if cte.name != "" {
+		val["name"] = cte.name
+	}
+
+	if cte.metadata != nil {
+		if metadata, err := json.Marshal(cte.metadata); err == nil {
+			val["metadata"] = string(metadata)
+		}
+	}
+
+	if definition, err := json.Marshal(cte.definition); err == nil {
+		val["definition"] = string(definition)
+	}
+
+	return val
+}
+
+type updateTwinEvent struct {
+	id         string
+	name       string
+	metadata   map[string]interface{}
+	definition *twins.Definition
+	desired    twins.Desired
+}
+
+func (ute updateTwinEvent) Encode() map[string]interface{} {
+	val := map[string]interface{}{
+		"id":        ute.id,
+		"operation": twinUpdate,
+	}
+
+	if ute.name != "" {
+		val["name"] = ute.name
+	}
+
+	if ute.metadata != nil {
+		if metadata, err := json.Marshal(ute.metadata); err == nil {
+			val["metadata"] = string(metadata)
+		}
+	}
+
+	if ute.definition != nil {
+		if definition, err := json.Marshal(ute.definition); err == nil {
+			val["definition"] = string(definition)
+		}
+	}
+
+	if ute.desired != nil {
+		if desired, err := json.Marshal(ute.desired); err == nil {
+			val["desired"] = string(desired)
+		}
+	}
+
+	return val
+}
+
+type removeTwinEvent struct {
+	id string
+}
+
+func (rte removeTwinEvent) Encode() map[string]interface{} {
+	return map[string]interface{}{
+		"id":        rte.id,
+		"operation": twinRemove,
+	}
+}
+
+type saveStateEvent struct {
+	twinID    string
+	channel   string
+	subtopic  string
+	publisher string
+}
+
+func (sse saveStateEvent) Encode() map[string]interface{} {
+	val := map[string]interface{}{
+		"twin_id":   sse.twinID,
+		"channel":   sse.channel,
+		"publisher": sse.publisher,
+		"operation": stateSave,
+	}
+
+	if sse.subtopic != "" {
+		val["subtopic"] = sse.subtopic
+	}
+
+	return val
+}
@@ -0,0 +1,174 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/twins"
+)
+
+const (
+	streamID  = "mainflux.twins"
+	streamLen = 1000
+)
+
+var _ twins.Service = (*eventStore)(nil)
+
+type eventStore struct {
+	svc    twins.Service
+	cache  twins.TwinCache
+	client *redis.Client
+}
+
+// NewEventStoreMiddleware returns wrapper around twins service that sends
+// twin lifecycle and state events to event store. The twin cache is used to
+// resolve the twins whose states are saved from the received message.
+func NewEventStoreMiddleware(svc twins.Service, cache twins.TwinCache, client *redis.Client) twins.Service {
+	return eventStore{
+		svc:    svc,
+		cache:  cache,
+		client: client,
+	}
+}
+
+func (es eventStore) AddTwin(ctx context.Context, token string, twin twins.Twin, def twins.Definition) (twins.Twin, error) {
+	stw, err := es.svc.AddTwin(ctx, token, twin, def)
+	if err != nil {
+		return stw, err
+	}
+
+	es.add(ctx, newCreateTwinEvent(stw))
+
+	return stw, nil
+}
+
+func (es eventStore) AddTwins(ctx context.Context, token string, thingIDs []string, chanID string, def twins.Definition) ([]twins.Twin, error) {
+	tws, err := es.svc.AddTwins(ctx, token, thingIDs, chanID, def)
+	for _, tw := range tws {
+		es.add(ctx, newCreateTwinEvent(tw))
+	}
+
+	return tws, err
+}
+
+func (es eventStore) UpdateTwin(ctx context.Context, token string, twin twins.Twin, def twins.Definition) error {
+	if err := es.svc.UpdateTwin(ctx, token, twin, def); err != nil {
+		return err
+	}
+
+	event := updateTwinEvent{
+		id:       twin.ID,
+		name:     twin.Name,
+		metadata: twin.Metadata,
+	}
+	if len(def.Attributes) > 0 {
+		event.definition = &def
+	}
+	es.add(ctx, event)
+
+	return nil
+}
+
+func (es eventStore) ViewTwin(ctx context.Context, token, twinID string) (twins.Twin, error) {
+	return es.svc.ViewTwin(ctx, token, twinID)
+}
+
+func (es eventStore) ListDefinitions(ctx context.Context, token, twinID string) ([]twins.Definition, error) {
+	return es.svc.ListDefinitions(ctx, token, twinID)
+}
+
+func (es eventStore) ViewDefinition(ctx context.Context, token, twinID string, defID int) (twins.Definition, error) {
+	return es.svc.ViewDefinition(ctx, token, twinID, defID)
+}
+
+func (es eventStore) RollbackDefinition(ctx context.Context, token, twinID string, defID int) (twins.Definition, error) {
+	def, err := es.svc.RollbackDefinition(ctx, token, twinID, defID)
+	if err != nil {
+		return def, err
+	}
+
+	es.add(ctx, updateTwinEvent{
+		id:         twinID,
+		definition: &def,
+	})
+
+	return def, nil
+}
+
+func (es eventStore) RemoveTwin(ctx context.Context, token, twinID string) error {
+	if err := es.svc.RemoveTwin(ctx, token, twinID); err != nil {
+		return err
+	}
+
+	es.add(ctx, removeTwinEvent{id: twinID})
+
+	return nil
+}
+
+func (es eventStore) ListTwins(ctx context.Context, token string, offset uint64, limit uint64, name string, metadata twins.Metadata) (twins.Page, error) {
+	return es.svc.ListTwins(ctx, token, offset, limit, name, metadata)
+}
+
+func (es eventStore) ListStates(ctx context.Context, token string, offset uint64, limit uint64, twinID string, query twins.StateQuery) (twins.StatesPage, error) {
+	return es.svc.ListStates(ctx, token, offset, limit, twinID, query)
+}
+
+func (es eventStore) DiffStates(ctx context.Context, token, twinID string, from, to int64) (twins.StateDiff, error) {
+	return es.svc.DiffStates(ctx, token, twinID, from, to)
+}
+
+func (es eventStore) AggregateStates(ctx context.Context, token, twinID string, query twins.AggregationQuery) ([]twins.Bucket, error) {
+	return es.svc.AggregateStates(ctx, token, twinID, query)
+}
+
+// SaveStates sends the event for each twin whose attributes match the
+// message channel and subtopic.
+func (es eventStore) SaveStates(msg *messaging.Message) error {
+	if err := es.svc.SaveStates(msg); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	ids, err := es.cache.IDs(ctx, msg.Channel, msg.Subtopic)
+	if err != nil {
+		return nil
+	}
+
+	for _, id := range ids {
+		es.add(ctx, saveStateEvent{
+			twinID:    id,
+			channel:   msg.Channel,
+			subtopic:  msg.Subtopic,
+			publisher: msg.Publisher,
+		})
+	}
+
+	return nil
+}
+
+func (es eventStore) SetDesired(ctx context.Context, token, twinID string, desired twins.Desired) (twins.Desired, error) {
+	delta, err := es.svc.SetDesired(ctx, token, twinID, desired)
+	if err != nil {
+		return delta, err
+	}
+
+	es.add(ctx, updateTwinEvent{
+		id:      twinID,
+		desired: desired,
+	})
+
+	return delta, nil
+}
+
+func (es eventStore) add(ctx context.Context, e event) {
+	record := &redis.XAddArgs{
+		Stream:       streamID,
+		MaxLenApprox: streamLen,
+		Values:       e.Encode(),
+	}
+	es.client.XAdd(ctx, record).Err()
+}
@@ -0,0 +1,206 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	r "github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/twins"
+	"github.com/mainflux/mainflux/twins/mocks"
+	"github.com/mainflux/mainflux/twins/redis"
+	"github.com/mainflux/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	streamID    = "mainflux.twins"
+	email       = "user@example.com"
+	token       = "token"
+	twinPrefix  = "twin."
+	twinCreate  = twinPrefix + "created"
+	twinUpdate  = twinPrefix + "updated"
+	twinRemove  = twinPrefix + "deleted"
+	statePrefix = "state."
+	stateSave   = statePrefix + "saved"
+)
+
+func TestAddTwinEvent(t *testing.T) {
+	redisClient.FlushAll(context.Background())
+
+	svc := mocks.NewService(map[string]string{token: email})
+	svc = redis.NewEventStoreMiddleware(svc, redis.NewTwinCache(redisClient), redisClient)
+
+	cases := []struct {
+		desc  string
+		token string
+		event map[string]interface{}
+	}{
+		{
+			desc:  "add twin successfully",
+			token: token,
+			event: map[string]interface{}{
+				"name":      "twin",
+				"owner":     email,
+				"operation": twinCreate,
+			},
+		},
+		{
+			desc:  "add twin with invalid credentials",
+			token: wrongValue,
+			event: nil,
+		},
+	}
+
+	lastID := "0"
+	for _, tc := range cases {
+		tw, _ := svc.AddTwin(context.Background(), tc.token, twins.Twin{Name: "twin"}, twins.Definition{})
+
+		var event map[string]interface{}
+		event, lastID = readEvent(lastID)
+		if tc.event != nil {
+			tc.event["id"] = tw.ID
+			require.Contains(t, event, "definition", fmt.Sprintf("%s: expected definition in event", tc.desc))
+			delete(event, "definition")
+		}
+		assert.Equal(t, tc.event, event, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.event, event))
+	}
+}
+
+func TestUpdateTwinEvent(t *testing.T) {
+	redisClient.FlushAll(context.Background())
+
+	svc := mocks.NewService(map[string]string{token: email})
+	svc = redis.NewEventStoreMiddleware(svc, redis.NewTwinCache(redisClient), redisClient)
+
+	tw, err := svc.AddTwin(context.Background(), token, twins.Twin{}, twins.Definition{})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	_, lastID := readEvent("0")
+
+	cases := []struct {
+		desc  string
+		twin  twins.Twin
+		token string
+		event map[string]interface{}
+	}{
+		{
+			desc:  "update twin successfully",
+			twin:  twins.Twin{ID: tw.ID, Name: "updated"},
+			token: token,
+			event: map[string]interface{}{
+				"id":        tw.ID,
+				"name":      "updated",
+				"operation": twinUpdate,
+			},
+		},
+		{
+			desc:  "update non-existing twin",
+			twin:  twins.Twin{ID: wrongValue, Name: "updated"},
+			token: token,
+			event: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		svc.UpdateTwin(context.Background(), tc.token, tc.twin, twins.Definition{})
+
+		var event map[string]interface{}
+		event, lastID = readEvent(lastID)
+		assert.Equal(t, tc.event, event, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.event, event))
+	}
+}
+
+func TestRemoveTwinEvent(t *testing.T) {
+	redisClient.FlushAll(context.Background())
+
+	svc := mocks.NewService(map[string]string{token: email})
+	svc = redis.NewEventStoreMiddleware(svc, redis.NewTwinCache(redisClient), redisClient)
+
+	tw, err := svc.AddTwin(context.Background(), token, twins.Twin{}, twins.Definition{})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	_, lastID := readEvent("0")
+
+	cases := []struct {
+		desc  string
+		id    string
+		token string
+		event map[string]interface{}
+	}{
+		{
+			desc:  "remove twin with invalid credentials",
+			id:    tw.ID,
+			token: wrongValue,
+			event: nil,
+		},
+		{
+			desc:  "remove twin successfully",
+			id:    tw.ID,
+			token: token,
+			event: map[string]interface{}{
+				"id":        tw.ID,
+				"operation": twinRemove,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		svc.RemoveTwin(context.Background(), tc.token, tc.id)
+
+		var event map[string]interface{}
+		event, lastID = readEvent(lastID)
+		assert.Equal(t, tc.event, event, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.event, event))
+	}
+}
+
+func TestSaveStatesEvent(t *testing.T) {
+	redisClient.FlushAll(context.Background())
+
+	svc := mocks.NewService(map[string]string{token: email})
+	svc = redis.NewEventStoreMiddleware(svc, redis.NewTwinCache(redisClient), redisClient)
+
+	def := mocks.CreateDefinition(channels[0:1], subtopics[0:1])
+	tw, err := svc.AddTwin(context.Background(), token, twins.Twin{}, def)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	_, lastID := readEvent("0")
+
+	// The mocked service keeps its own cache, so the event store one is
+	// populated in order to resolve the twins affected by the message.
+	err = redis.NewTwinCache(redisClient).Save(context.Background(), tw)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	val := 17.0
+	msg, err := mocks.CreateMessage(def.Attributes[0], []senml.Record{{Name: "temperature", Value: &val}})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	err = svc.SaveStates(msg)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	expected := map[string]interface{}{
+		"twin_id":   tw.ID,
+		"channel":   msg.Channel,
+		"subtopic":  msg.Subtopic,
+		"publisher": msg.Publisher,
+		"operation": stateSave,
+	}
+	event, _ := readEvent(lastID)
+	assert.Equal(t, expected, event, fmt.Sprintf("save states: expected %v got %v\n", expected, event))
+}
+
+func readEvent(lastID string) (map[string]interface{}, string) {
+	streams := redisClient.XRead(context.Background(), &r.XReadArgs{
+		Streams: []string{streamID, lastID},
+		Count:   1,
+		Block:   time.Second,
+	}).Val()
+
+	if len(streams) > 0 && len(streams[0].Messages) > 0 {
+		msg := streams[0].Messages[0]
+		return msg.Values, msg.ID
+	}
+	return nil, lastID
+}
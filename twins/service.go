@@ -8,6 +8,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/mainflux/mainflux/logger"
@@ -42,6 +44,12 @@ type Service interface {
 	// AddTwin adds new twin related to user identified by the provided key.
 	AddTwin(ctx context.Context, token string, twin Twin, def Definition) (tw Twin, err error)
 
+	// AddTwins adds the twins bound to the things identified by the provided
+	// IDs, or to all things connected to the channel identified by chanID if
+	// no IDs are provided. The twins are named after the things and share
+	// the provided definition.
+	AddTwins(ctx context.Context, token string, thingIDs []string, chanID string, def Definition) ([]Twin, error)
+
 	// UpdateTwin updates twin identified by the provided Twin that
 	// belongs to the user identified by the provided key.
 	UpdateTwin(ctx context.Context, token string, twin Twin, def Definition) (err error)
@@ -50,6 +58,19 @@ type Service interface {
 	// ID belonging to the user identified by the provided key.
 	ViewTwin(ctx context.Context, token, twinID string) (tw Twin, err error)
 
+	// ListDefinitions retrieves the definition revisions of the twin
+	// identified by the provided ID.
+	ListDefinitions(ctx context.Context, token, twinID string) ([]Definition, error)
+
+	// ViewDefinition retrieves the definition revision identified by defID
+	// of the twin identified by the provided ID.
+	ViewDefinition(ctx context.Context, token, twinID string, defID int) (Definition, error)
+
+	// RollbackDefinition rolls the twin identified by the provided ID back
+	// to the definition revision identified by defID. The definition is
+	// appended as the new revision, keeping the history intact.
+	RollbackDefinition(ctx context.Context, token, twinID string, defID int) (Definition, error)
+
 	// RemoveTwin removes the twin identified with the provided ID, that
 	// belongs to the user identified by the provided key.
 	RemoveTwin(ctx context.Context, token, twinID string) (err error)
@@ -59,11 +80,27 @@ type Service interface {
 	ListTwins(ctx context.Context, token string, offset uint64, limit uint64, name string, metadata Metadata) (Page, error)
 
 	// ListStates retrieves data about subset of states that belongs to the
-	// twin identified by the id.
-	ListStates(ctx context.Context, token string, offset uint64, limit uint64, twinID string) (StatesPage, error)
+	// twin identified by the id and satisfies the query.
+	ListStates(ctx context.Context, token string, offset uint64, limit uint64, twinID string, query StateQuery) (StatesPage, error)
+
+	// DiffStates retrieves the attribute-level differences between the
+	// states identified by from and to of the twin identified by the id.
+	DiffStates(ctx context.Context, token, twinID string, from, to int64) (StateDiff, error)
+
+	// AggregateStates groups the states of the twin identified by the id
+	// into buckets of the query interval, aggregating the values of the
+	// query attributes, or of the attributes of the twin's current
+	// definition if none are provided.
+	AggregateStates(ctx context.Context, token, twinID string, query AggregationQuery) ([]Bucket, error)
 
 	// SaveStates persists states into database
 	SaveStates(msg *messaging.Message) error
+
+	// SetDesired sets the desired values of the attributes of the twin
+	// identified by the provided ID. For each attribute whose desired value
+	// differs from the last reported state, the command is published on the
+	// attribute's channel. The difference is returned as the delta.
+	SetDesired(ctx context.Context, token, twinID string, desired Desired) (delta Desired, err error)
 }
 
 const (
@@ -73,24 +110,32 @@ const (
 	millisec         = 1e6
 	nanosec          = 1e9
 	SubtopicWildcard = ">"
+	// DesiredSubtopic is appended to the attribute subtopic to form the
+	// subtopic of the commands carrying the desired attribute values.
+	DesiredSubtopic = "desired"
 )
 
 var crudOp = map[string]string{
-	"createSucc": "create.success",
-	"createFail": "create.failure",
-	"updateSucc": "update.success",
-	"updateFail": "update.failure",
-	"getSucc":    "get.success",
-	"getFail":    "get.failure",
-	"removeSucc": "remove.success",
-	"removeFail": "remove.failure",
-	"stateSucc":  "save.success",
-	"stateFail":  "save.failure",
+	"createSucc":   "create.success",
+	"createFail":   "create.failure",
+	"updateSucc":   "update.success",
+	"updateFail":   "update.failure",
+	"getSucc":      "get.success",
+	"getFail":      "get.failure",
+	"removeSucc":   "remove.success",
+	"removeFail":   "remove.failure",
+	"stateSucc":    "save.success",
+	"stateFail":    "save.failure",
+	"desiredSucc":  "desired.success",
+	"desiredFail":  "desired.failure",
+	"rollbackSucc": "rollback.success",
+	"rollbackFail": "rollback.failure",
 }
 
 type twinsService struct {
 	publisher  messaging.Publisher
 	auth       mainflux.AuthServiceClient
+	things     ThingsService
 	twins      TwinRepository
 	states     StateRepository
 	idProvider mainflux.IDProvider
@@ -102,10 +147,11 @@ type twinsService struct {
 var _ Service = (*twinsService)(nil)
 
 // New instantiates the twins service implementation.
-func New(publisher messaging.Publisher, auth mainflux.AuthServiceClient, twins TwinRepository, tcache TwinCache, sr StateRepository, idp mainflux.IDProvider, chann string, logger logger.Logger) Service {
+func New(publisher messaging.Publisher, auth mainflux.AuthServiceClient, things ThingsService, twins TwinRepository, tcache TwinCache, sr StateRepository, idp mainflux.IDProvider, chann string, logger logger.Logger) Service {
 	return &twinsService{
 		publisher:  publisher,
 		auth:       auth,
+		things:     things,
 		twins:      twins,
 		twinCache:  tcache,
 		states:     sr,
@@ -158,6 +204,40 @@ func (ts *twinsService) AddTwin(ctx context.Context, token string, twin Twin, de
 	return twin, ts.twinCache.Save(ctx, twin)
 }
 
+func (ts *twinsService) AddTwins(ctx context.Context, token string, thingIDs []string, chanID string, def Definition) ([]Twin, error) {
+	if _, err := ts.auth.Identify(ctx, &mainflux.Token{Value: token}); err != nil {
+		return nil, ErrUnauthorizedAccess
+	}
+
+	var ths []Thing
+	switch {
+	case len(thingIDs) > 0:
+		for _, id := range thingIDs {
+			th, err := ts.things.Thing(token, id)
+			if err != nil {
+				return nil, err
+			}
+			ths = append(ths, th)
+		}
+	default:
+		var err error
+		if ths, err = ts.things.ChannelThings(token, chanID); err != nil {
+			return nil, err
+		}
+	}
+
+	tws := []Twin{}
+	for _, th := range ths {
+		tw, err := ts.AddTwin(ctx, token, Twin{ThingID: th.ID, Name: th.Name}, def)
+		if err != nil {
+			return tws, err
+		}
+		tws = append(tws, tw)
+	}
+
+	return tws, nil
+}
+
 func (ts *twinsService) UpdateTwin(ctx context.Context, token string, twin Twin, def Definition) (err error) {
 	var b []byte
 	var id string
@@ -192,6 +272,11 @@ func (ts *twinsService) UpdateTwin(ctx context.Context, token string, twin Twin,
 		tw.Metadata = twin.Metadata
 	}
 
+	if twin.Retention != nil {
+		revision = true
+		tw.Retention = twin.Retention
+	}
+
 	if !revision {
 		return ErrMalformedEntity
 	}
@@ -206,7 +291,7 @@ func (ts *twinsService) UpdateTwin(ctx context.Context, token string, twin Twin,
 	id = twin.ID
 	b, err = json.Marshal(tw)
 
-	return ts.twinCache.Update(ctx, twin)
+	return ts.twinCache.Update(ctx, tw)
 }
 
 func (ts *twinsService) ViewTwin(ctx context.Context, token, twinID string) (tw Twin, err error) {
@@ -228,6 +313,78 @@ func (ts *twinsService) ViewTwin(ctx context.Context, token, twinID string) (tw
 	return twin, nil
 }
 
+func (ts *twinsService) ListDefinitions(ctx context.Context, token, twinID string) ([]Definition, error) {
+	_, err := ts.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return nil, ErrUnauthorizedAccess
+	}
+
+	tw, err := ts.twins.RetrieveByID(ctx, twinID)
+	if err != nil {
+		return nil, err
+	}
+
+	return tw.Definitions, nil
+}
+
+func (ts *twinsService) ViewDefinition(ctx context.Context, token, twinID string, defID int) (Definition, error) {
+	defs, err := ts.ListDefinitions(ctx, token, twinID)
+	if err != nil {
+		return Definition{}, err
+	}
+
+	idx := findDefinition(defID, defs)
+	if idx < 0 {
+		return Definition{}, ErrNotFound
+	}
+
+	return defs[idx], nil
+}
+
+func (ts *twinsService) RollbackDefinition(ctx context.Context, token, twinID string, defID int) (def Definition, err error) {
+	var b []byte
+	defer ts.publish(&twinID, &err, crudOp["rollbackSucc"], crudOp["rollbackFail"], &b)
+
+	_, err = ts.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return Definition{}, ErrUnauthorizedAccess
+	}
+
+	tw, err := ts.twins.RetrieveByID(ctx, twinID)
+	if err != nil {
+		return Definition{}, err
+	}
+
+	idx := findDefinition(defID, tw.Definitions)
+	if idx < 0 {
+		return Definition{}, ErrNotFound
+	}
+
+	last := tw.Definitions[len(tw.Definitions)-1]
+	if idx == len(tw.Definitions)-1 {
+		return Definition{}, ErrMalformedEntity
+	}
+
+	def = tw.Definitions[idx]
+	def.ID = last.ID + 1
+	def.Created = time.Now()
+	tw.Definitions = append(tw.Definitions, def)
+
+	tw.Updated = time.Now()
+	tw.Revision++
+
+	if err := ts.twins.Update(ctx, tw); err != nil {
+		return Definition{}, err
+	}
+
+	b, err = json.Marshal(tw)
+	if err != nil {
+		return Definition{}, err
+	}
+
+	return def, ts.twinCache.Update(ctx, tw)
+}
+
 func (ts *twinsService) RemoveTwin(ctx context.Context, token, twinID string) (err error) {
 	var b []byte
 	defer ts.publish(&twinID, &err, crudOp["removeSucc"], crudOp["removeFail"], &b)
@@ -253,18 +410,162 @@ func (ts *twinsService) ListTwins(ctx context.Context, token string, offset uint
 	return ts.twins.RetrieveAll(ctx, res.GetEmail(), offset, limit, name, metadata)
 }
 
-func (ts *twinsService) ListStates(ctx context.Context, token string, offset uint64, limit uint64, twinID string) (StatesPage, error) {
+func (ts *twinsService) ListStates(ctx context.Context, token string, offset uint64, limit uint64, twinID string, query StateQuery) (StatesPage, error) {
 	_, err := ts.auth.Identify(ctx, &mainflux.Token{Value: token})
 	if err != nil {
 		return StatesPage{}, ErrUnauthorizedAccess
 	}
 
-	return ts.states.RetrieveAll(ctx, offset, limit, twinID)
+	if err := query.Validate(); err != nil {
+		return StatesPage{}, err
+	}
+
+	return ts.states.RetrieveAll(ctx, offset, limit, twinID, query)
+}
+
+func (ts *twinsService) DiffStates(ctx context.Context, token, twinID string, from, to int64) (StateDiff, error) {
+	_, err := ts.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return StateDiff{}, ErrUnauthorizedAccess
+	}
+
+	fst, err := ts.states.RetrieveByID(ctx, twinID, from)
+	if err != nil {
+		return StateDiff{}, err
+	}
+
+	tst, err := ts.states.RetrieveByID(ctx, twinID, to)
+	if err != nil {
+		return StateDiff{}, err
+	}
+
+	return StateDiff{
+		TwinID:  twinID,
+		From:    fst,
+		To:      tst,
+		Changes: Diff(fst, tst),
+	}, nil
+}
+
+func (ts *twinsService) AggregateStates(ctx context.Context, token, twinID string, query AggregationQuery) ([]Bucket, error) {
+	_, err := ts.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return nil, ErrUnauthorizedAccess
+	}
+
+	tw, err := ts.twins.RetrieveByID(ctx, twinID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(query.Attributes) == 0 {
+		for _, attr := range tw.Definitions[len(tw.Definitions)-1].Attributes {
+			query.Attributes = append(query.Attributes, attr.Name)
+		}
+	}
+
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	return ts.states.Aggregate(ctx, twinID, query)
+}
+
+func (ts *twinsService) SetDesired(ctx context.Context, token, twinID string, desired Desired) (delta Desired, err error) {
+	var b []byte
+	defer ts.publish(&twinID, &err, crudOp["desiredSucc"], crudOp["desiredFail"], &b)
+
+	_, err = ts.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return nil, ErrUnauthorizedAccess
+	}
+
+	tw, err := ts.twins.RetrieveByID(ctx, twinID)
+	if err != nil {
+		return nil, err
+	}
+
+	def := tw.Definitions[len(tw.Definitions)-1]
+	if tw.Desired == nil {
+		tw.Desired = Desired{}
+	}
+	for name, val := range desired {
+		if findAttribute(name, def.Attributes) < 0 {
+			return nil, ErrMalformedEntity
+		}
+		if val == nil {
+			delete(tw.Desired, name)
+			continue
+		}
+		if _, ok := desiredRecord(name, val); !ok {
+			return nil, ErrMalformedEntity
+		}
+		tw.Desired[name] = val
+	}
+
+	tw.Updated = time.Now()
+	if err := ts.twins.Update(ctx, tw); err != nil {
+		return nil, err
+	}
+
+	st, err := ts.states.RetrieveLast(ctx, tw.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	delta = Desired{}
+	for name, val := range tw.Desired {
+		idx := findAttribute(name, def.Attributes)
+		if idx < 0 {
+			continue
+		}
+		if reported, ok := st.Payload[name]; ok && equal(reported, val) {
+			continue
+		}
+		if err := ts.command(def.Attributes[idx], val); err != nil {
+			return nil, err
+		}
+		delta[name] = val
+	}
+
+	b, err = json.Marshal(tw)
+
+	return delta, err
+}
+
+// command publishes the desired attribute value as SenML record on the
+// attribute's channel, so that the device can reconcile its state.
+func (ts *twinsService) command(attr Attribute, val interface{}) error {
+	rec, _ := desiredRecord(attr.Name, val)
+	payload, err := json.Marshal([]senml.Record{rec})
+	if err != nil {
+		return err
+	}
+
+	subtopic := DesiredSubtopic
+	if attr.Subtopic != "" && attr.Subtopic != SubtopicWildcard {
+		subtopic = fmt.Sprintf("%s.%s", attr.Subtopic, DesiredSubtopic)
+	}
+
+	msg := messaging.Message{
+		Channel:   attr.Channel,
+		Subtopic:  subtopic,
+		Payload:   payload,
+		Publisher: publisher,
+		Created:   time.Now().UnixNano(),
+	}
+
+	return ts.publisher.Publish(msg.Channel, msg)
 }
 
 func (ts *twinsService) SaveStates(msg *messaging.Message) error {
 	var ids []string
 
+	// Commands carry desired values, not the reported state.
+	if msg.Subtopic == DesiredSubtopic || strings.HasSuffix(msg.Subtopic, "."+DesiredSubtopic) {
+		return nil
+	}
+
 	ctx := context.TODO()
 	channel, subtopic := msg.Channel, msg.Subtopic
 	ids, err := ts.twinCache.IDs(ctx, channel, subtopic)
@@ -403,6 +704,37 @@ func findValue(rec senml.Record) interface{} {
 	return nil
 }
 
+// desiredRecord returns the SenML record carrying the desired value. Only
+// numeric, string and boolean values are supported.
+func desiredRecord(name string, val interface{}) (senml.Record, bool) {
+	rec := senml.Record{Name: name}
+	switch v := val.(type) {
+	case float64:
+		rec.Value = &v
+	case string:
+		rec.StringValue = &v
+	case bool:
+		rec.BoolValue = &v
+	default:
+		return senml.Record{}, false
+	}
+	return rec, true
+}
+
+// equal reports whether the reported state value matches the desired one.
+func equal(reported, desired interface{}) bool {
+	return reflect.DeepEqual(deref(reported), desired)
+}
+
+func findDefinition(id int, defs []Definition) int {
+	for idx, def := range defs {
+		if def.ID == id {
+			return idx
+		}
+	}
+	return -1
+}
+
 func findAttribute(name string, attrs []Attribute) (idx int) {
 	for idx, attr := range attrs {
 		if attr.Name == name {
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/mainflux/mainflux/twins"
 	"github.com/mainflux/mainflux/twins/mocks"
@@ -61,6 +62,66 @@ func TestAddTwin(t *testing.T) {
 	}
 }
 
+func TestAddTwins(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+	def := mocks.CreateDefinition(channels[0:1], subtopics[0:1])
+
+	cases := []struct {
+		desc     string
+		thingIDs []string
+		chanID   string
+		token    string
+		size     int
+		err      error
+	}{
+		{
+			desc:     "add twins for things",
+			thingIDs: []string{mocks.ThingID(1), mocks.ThingID(2)},
+			token:    token,
+			size:     2,
+			err:      nil,
+		},
+		{
+			desc:   "add twins for channel things",
+			chanID: mocks.ThingsChannel,
+			token:  token,
+			size:   3,
+			err:    nil,
+		},
+		{
+			desc:     "add twins for non-existing thing",
+			thingIDs: []string{mocks.ThingID(1), wrongID},
+			token:    token,
+			size:     0,
+			err:      twins.ErrNotFound,
+		},
+		{
+			desc:   "add twins for non-existing channel",
+			chanID: wrongID,
+			token:  token,
+			size:   0,
+			err:    twins.ErrNotFound,
+		},
+		{
+			desc:   "add twins with wrong credentials",
+			chanID: mocks.ThingsChannel,
+			token:  wrongToken,
+			size:   0,
+			err:    twins.ErrUnauthorizedAccess,
+		},
+	}
+
+	for _, tc := range cases {
+		tws, err := svc.AddTwins(context.Background(), tc.token, tc.thingIDs, tc.chanID, def)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Len(t, tws, tc.size, fmt.Sprintf("%s: expected %d twins got %d\n", tc.desc, tc.size, len(tws)))
+		for _, tw := range tws {
+			assert.NotEmpty(t, tw.ThingID, fmt.Sprintf("%s: expected twin bound to thing\n", tc.desc))
+			assert.Equal(t, def.Attributes, tw.Definitions[0].Attributes, fmt.Sprintf("%s: expected attributes %v got %v\n", tc.desc, def.Attributes, tw.Definitions[0].Attributes))
+		}
+	}
+}
+
 func TestUpdateTwin(t *testing.T) {
 	svc := mocks.NewService(map[string]string{token: email})
 	twin := twins.Twin{}
@@ -307,11 +368,11 @@ func TestSaveStates(t *testing.T) {
 		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
 
 		ttlAdded += tc.size
-		page, err := svc.ListStates(context.TODO(), token, 0, 10, tw.ID)
+		page, err := svc.ListStates(context.TODO(), token, 0, 10, tw.ID, twins.StateQuery{})
 		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
 		assert.Equal(t, ttlAdded, page.Total, fmt.Sprintf("%s: expected %d total got %d total\n", tc.desc, ttlAdded, page.Total))
 
-		page, err = svc.ListStates(context.TODO(), token, 0, 10, twWildcard.ID)
+		page, err = svc.ListStates(context.TODO(), token, 0, 10, twWildcard.ID, twins.StateQuery{})
 		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
 		assert.Equal(t, ttlAdded, page.Total, fmt.Sprintf("%s: expected %d total got %d total\n", tc.desc, ttlAdded, page.Total))
 	}
@@ -413,8 +474,409 @@ func TestListStates(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		page, err := svc.ListStates(context.TODO(), tc.token, tc.offset, tc.limit, tc.id)
+		page, err := svc.ListStates(context.TODO(), tc.token, tc.offset, tc.limit, tc.id, twins.StateQuery{})
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 		assert.Equal(t, tc.size, len(page.States), fmt.Sprintf("%s: expected %d total got %d total\n", tc.desc, tc.size, len(page.States)))
 	}
 }
+
+func TestSetDesired(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+
+	def := twins.Definition{
+		Attributes: []twins.Attribute{
+			{Name: "temperature", Channel: channels[0], Subtopic: subtopics[0], PersistState: true},
+			{Name: "mode", Channel: channels[1], Subtopic: twins.SubtopicWildcard, PersistState: true},
+		},
+	}
+	tw, err := svc.AddTwin(context.Background(), token, twins.Twin{Owner: email}, def)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	v := 21.0
+	message, err := mocks.CreateMessage(def.Attributes[0], []senml.Record{{Value: &v}})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	err = svc.SaveStates(message)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc    string
+		id      string
+		token   string
+		desired twins.Desired
+		delta   twins.Desired
+		err     error
+	}{
+		{
+			desc:    "set desired values differing from reported state",
+			id:      tw.ID,
+			token:   token,
+			desired: twins.Desired{"temperature": 21.0, "mode": "eco"},
+			delta:   twins.Desired{"mode": "eco"},
+			err:     nil,
+		},
+		{
+			desc:    "set desired value keeping previously desired values",
+			id:      tw.ID,
+			token:   token,
+			desired: twins.Desired{"temperature": 23.0},
+			delta:   twins.Desired{"temperature": 23.0, "mode": "eco"},
+			err:     nil,
+		},
+		{
+			desc:    "clear desired value",
+			id:      tw.ID,
+			token:   token,
+			desired: twins.Desired{"mode": nil},
+			delta:   twins.Desired{"temperature": 23.0},
+			err:     nil,
+		},
+		{
+			desc:    "set desired value of unknown attribute",
+			id:      tw.ID,
+			token:   token,
+			desired: twins.Desired{"unknown": 1.0},
+			delta:   nil,
+			err:     twins.ErrMalformedEntity,
+		},
+		{
+			desc:    "set desired value of unsupported type",
+			id:      tw.ID,
+			token:   token,
+			desired: twins.Desired{"mode": map[string]interface{}{"eco": true}},
+			delta:   nil,
+			err:     twins.ErrMalformedEntity,
+		},
+		{
+			desc:    "set desired values with wrong credentials",
+			id:      tw.ID,
+			token:   wrongToken,
+			desired: twins.Desired{"mode": "eco"},
+			delta:   nil,
+			err:     twins.ErrUnauthorizedAccess,
+		},
+		{
+			desc:    "set desired values of non-existent twin",
+			id:      wrongID,
+			token:   token,
+			desired: twins.Desired{"mode": "eco"},
+			delta:   nil,
+			err:     twins.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		delta, err := svc.SetDesired(context.Background(), tc.token, tc.id, tc.desired)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.delta, delta, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.delta, delta))
+	}
+}
+
+func TestAggregateStates(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+
+	def := twins.Definition{
+		Attributes: []twins.Attribute{
+			{Name: "temperature", Channel: channels[0], Subtopic: subtopics[0], PersistState: true},
+		},
+	}
+	tw, err := svc.AddTwin(context.Background(), token, twins.Twin{Owner: email}, def)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	// Day-aligned time of the first state.
+	start := time.Unix(1600041600, 0).UTC()
+	temps := []float64{20, 22, 30, 40}
+	offsets := []time.Duration{0, 10 * time.Minute, time.Hour, time.Hour + 30*time.Minute}
+	for i := range temps {
+		rec := senml.Record{Value: &temps[i], BaseTime: float64(start.Add(offsets[i]).Unix())}
+		message, err := mocks.CreateMessage(def.Attributes[0], []senml.Record{rec})
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		err = svc.SaveStates(message)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+
+	cases := []struct {
+		desc    string
+		id      string
+		token   string
+		query   twins.AggregationQuery
+		buckets []twins.Bucket
+		err     error
+	}{
+		{
+			desc:  "aggregate states of definition attributes",
+			id:    tw.ID,
+			token: token,
+			query: twins.AggregationQuery{Interval: time.Hour, Function: twins.AggAvg},
+			buckets: []twins.Bucket{
+				{Start: start, Count: 2, Values: map[string]interface{}{"temperature": 21.0}},
+				{Start: start.Add(time.Hour), Count: 2, Values: map[string]interface{}{"temperature": 35.0}},
+			},
+			err: nil,
+		},
+		{
+			desc:  "aggregate states of provided attributes",
+			id:    tw.ID,
+			token: token,
+			query: twins.AggregationQuery{Interval: 2 * time.Hour, Function: twins.AggMax, Attributes: []string{"temperature"}},
+			buckets: []twins.Bucket{
+				{Start: start, Count: 4, Values: map[string]interface{}{"temperature": 40.0}},
+			},
+			err: nil,
+		},
+		{
+			desc:    "aggregate states with invalid function",
+			id:      tw.ID,
+			token:   token,
+			query:   twins.AggregationQuery{Interval: time.Hour, Function: "sum"},
+			buckets: nil,
+			err:     twins.ErrMalformedEntity,
+		},
+		{
+			desc:    "aggregate states of non-existent twin",
+			id:      wrongID,
+			token:   token,
+			query:   twins.AggregationQuery{Interval: time.Hour, Function: twins.AggLast},
+			buckets: nil,
+			err:     twins.ErrNotFound,
+		},
+		{
+			desc:    "aggregate states with wrong credentials",
+			id:      tw.ID,
+			token:   wrongToken,
+			query:   twins.AggregationQuery{Interval: time.Hour, Function: twins.AggLast},
+			buckets: nil,
+			err:     twins.ErrUnauthorizedAccess,
+		},
+	}
+
+	for _, tc := range cases {
+		buckets, err := svc.AggregateStates(context.Background(), tc.token, tc.id, tc.query)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.buckets, buckets, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.buckets, buckets))
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+
+	def := twins.Definition{
+		Attributes: []twins.Attribute{
+			{Name: "temperature", Channel: channels[0], Subtopic: subtopics[0], PersistState: true},
+			{Name: "mode", Channel: channels[1], Subtopic: subtopics[1], PersistState: true},
+		},
+	}
+	tw, err := svc.AddTwin(context.Background(), token, twins.Twin{Owner: email}, def)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	temp1, temp2, mode := 21.0, 23.0, "eco"
+	msgs := []struct {
+		attr twins.Attribute
+		rec  senml.Record
+	}{
+		{attr: def.Attributes[0], rec: senml.Record{Value: &temp1}},
+		{attr: def.Attributes[0], rec: senml.Record{Value: &temp2}},
+		{attr: def.Attributes[1], rec: senml.Record{StringValue: &mode}},
+	}
+	for _, m := range msgs {
+		message, err := mocks.CreateMessage(m.attr, []senml.Record{m.rec})
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		err = svc.SaveStates(message)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+
+	cases := []struct {
+		desc    string
+		id      string
+		token   string
+		from    int64
+		to      int64
+		changes []twins.Change
+		err     error
+	}{
+		{
+			desc:  "diff states with updated attribute",
+			id:    tw.ID,
+			token: token,
+			from:  0,
+			to:    1,
+			changes: []twins.Change{
+				{Attribute: "temperature", Kind: twins.ChangeUpdated, From: 21.0, To: 23.0},
+			},
+			err: nil,
+		},
+		{
+			desc:  "diff states with added attribute",
+			id:    tw.ID,
+			token: token,
+			from:  1,
+			to:    2,
+			changes: []twins.Change{
+				{Attribute: "mode", Kind: twins.ChangeAdded, To: "eco"},
+			},
+			err: nil,
+		},
+		{
+			desc:    "diff non-existent state",
+			id:      tw.ID,
+			token:   token,
+			from:    0,
+			to:      10,
+			changes: nil,
+			err:     twins.ErrNotFound,
+		},
+		{
+			desc:    "diff states with wrong credentials",
+			id:      tw.ID,
+			token:   wrongToken,
+			from:    0,
+			to:      1,
+			changes: nil,
+			err:     twins.ErrUnauthorizedAccess,
+		},
+	}
+
+	for _, tc := range cases {
+		diff, err := svc.DiffStates(context.Background(), tc.token, tc.id, tc.from, tc.to)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.changes, diff.Changes, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.changes, diff.Changes))
+	}
+}
+
+func TestViewDefinition(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+
+	def := mocks.CreateDefinition(channels[0:1], subtopics[0:1])
+	tw, err := svc.AddTwin(context.Background(), token, twins.Twin{Owner: email}, def)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	update := mocks.CreateDefinition(channels[1:2], subtopics[1:2])
+	err = svc.UpdateTwin(context.Background(), token, twins.Twin{ID: tw.ID}, update)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	defs, err := svc.ListDefinitions(context.Background(), token, tw.ID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, 2, len(defs), fmt.Sprintf("expected 2 definitions got %d\n", len(defs)))
+
+	cases := []struct {
+		desc    string
+		id      string
+		token   string
+		defID   int
+		channel string
+		err     error
+	}{
+		{
+			desc:    "view first definition",
+			id:      tw.ID,
+			token:   token,
+			defID:   0,
+			channel: channels[0],
+			err:     nil,
+		},
+		{
+			desc:    "view last definition",
+			id:      tw.ID,
+			token:   token,
+			defID:   1,
+			channel: channels[1],
+			err:     nil,
+		},
+		{
+			desc:  "view non-existent definition",
+			id:    tw.ID,
+			token: token,
+			defID: 2,
+			err:   twins.ErrNotFound,
+		},
+		{
+			desc:  "view definition of non-existent twin",
+			id:    wrongID,
+			token: token,
+			defID: 0,
+			err:   twins.ErrNotFound,
+		},
+		{
+			desc:  "view definition with wrong credentials",
+			id:    tw.ID,
+			token: wrongToken,
+			defID: 0,
+			err:   twins.ErrUnauthorizedAccess,
+		},
+	}
+
+	for _, tc := range cases {
+		def, err := svc.ViewDefinition(context.Background(), tc.token, tc.id, tc.defID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, tc.channel, def.Attributes[0].Channel, fmt.Sprintf("%s: expected channel %s got %s\n", tc.desc, tc.channel, def.Attributes[0].Channel))
+		}
+	}
+}
+
+func TestRollbackDefinition(t *testing.T) {
+	svc := mocks.NewService(map[string]string{token: email})
+
+	def := mocks.CreateDefinition(channels[0:1], subtopics[0:1])
+	tw, err := svc.AddTwin(context.Background(), token, twins.Twin{Owner: email}, def)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	update := mocks.CreateDefinition(channels[1:2], subtopics[1:2])
+	err = svc.UpdateTwin(context.Background(), token, twins.Twin{ID: tw.ID}, update)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc  string
+		id    string
+		token string
+		defID int
+		newID int
+		err   error
+	}{
+		{
+			desc:  "roll back to first definition",
+			id:    tw.ID,
+			token: token,
+			defID: 0,
+			newID: 2,
+			err:   nil,
+		},
+		{
+			desc:  "roll back to current definition",
+			id:    tw.ID,
+			token: token,
+			defID: 2,
+			err:   twins.ErrMalformedEntity,
+		},
+		{
+			desc:  "roll back to non-existent definition",
+			id:    tw.ID,
+			token: token,
+			defID: 5,
+			err:   twins.ErrNotFound,
+		},
+		{
+			desc:  "roll back with wrong credentials",
+			id:    tw.ID,
+			token: wrongToken,
+			defID: 1,
+			err:   twins.ErrUnauthorizedAccess,
+		},
+	}
+
+	for _, tc := range cases {
+		def, err := svc.RollbackDefinition(context.Background(), tc.token, tc.id, tc.defID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.newID, def.ID, fmt.Sprintf("%s: expected definition %d got %d\n", tc.desc, tc.newID, def.ID))
+	}
+
+	// States are saved for the attributes of the rolled back definition.
+	recs := make([]senml.Record, 1)
+	message, err := mocks.CreateMessage(def.Attributes[0], recs)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	err = svc.SaveStates(message)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	page, err := svc.ListStates(context.Background(), token, 0, 10, tw.ID, twins.StateQuery{})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, uint64(1), page.Total, fmt.Sprintf("expected 1 state got %d\n", page.Total))
+}
@@ -36,8 +36,20 @@ type StateRepository interface {
 	Count(ctx context.Context, twin Twin) (int64, error)
 
 	// RetrieveAll retrieves the subset of states related to twin specified by id
-	RetrieveAll(ctx context.Context, offset uint64, limit uint64, twinID string) (StatesPage, error)
+	// which satisfy the query
+	RetrieveAll(ctx context.Context, offset uint64, limit uint64, twinID string, query StateQuery) (StatesPage, error)
+
+	// RetrieveByID retrieves the state of the twin specified by id
+	RetrieveByID(ctx context.Context, twinID string, id int64) (State, error)
 
 	// RetrieveLast retrieves the last saved state
 	RetrieveLast(ctx context.Context, twinID string) (State, error)
+
+	// Aggregate groups the states of the twin specified by id into buckets
+	// as specified by the query
+	Aggregate(ctx context.Context, twinID string, query AggregationQuery) ([]Bucket, error)
+
+	// Purge removes the states of the twin specified by id which exceed the
+	// retention policy, and returns the number of removed states.
+	Purge(ctx context.Context, twinID string, retention Retention) (int64, error)
 }
@@ -0,0 +1,21 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package twins
+
+// Thing represents the Mainflux thing the twin is bound to.
+type Thing struct {
+	ID   string
+	Name string
+}
+
+// ThingsService specifies an API for retrieving the things owned by the
+// user identified by the provided key.
+type ThingsService interface {
+	// Thing retrieves the thing identified by the provided ID.
+	Thing(token, id string) (Thing, error)
+
+	// ChannelThings retrieves all things connected to the channel
+	// identified by the provided ID.
+	ChannelThings(token, chanID string) ([]Thing, error)
+}
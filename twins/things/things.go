@@ -0,0 +1,58 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package things contains the things service client used by the twins
+// service, based on the Mainflux SDK.
+package things
+
+import (
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/twins"
+)
+
+const pageSize = 100
+
+var _ twins.ThingsService = (*thingsService)(nil)
+
+type thingsService struct {
+	sdk mfsdk.SDK
+}
+
+// New returns the things service client using the provided SDK.
+func New(sdk mfsdk.SDK) twins.ThingsService {
+	return thingsService{sdk: sdk}
+}
+
+func (ts thingsService) Thing(token, id string) (twins.Thing, error) {
+	th, err := ts.sdk.Thing(id, token)
+	if err != nil {
+		if errors.Contains(err, mfsdk.ErrFailedFetch) {
+			return twins.Thing{}, twins.ErrNotFound
+		}
+		return twins.Thing{}, err
+	}
+
+	return twins.Thing{ID: th.ID, Name: th.Name}, nil
+}
+
+func (ts thingsService) ChannelThings(token, chanID string) ([]twins.Thing, error) {
+	var ths []twins.Thing
+	for offset := uint64(0); ; offset += pageSize {
+		page, err := ts.sdk.ThingsByChannel(token, chanID, offset, pageSize, false)
+		if err != nil {
+			if errors.Contains(err, mfsdk.ErrFailedFetch) {
+				return nil, twins.ErrNotFound
+			}
+			return nil, err
+		}
+
+		for _, th := range page.Things {
+			ths = append(ths, twins.Thing{ID: th.ID, Name: th.Name})
+		}
+
+		if len(page.Things) == 0 || offset+pageSize >= page.Total {
+			return ths, nil
+		}
+	}
+}
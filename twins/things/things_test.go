@@ -0,0 +1,142 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package things_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/pkg/uuid"
+	"github.com/mainflux/mainflux/things"
+	httpapi "github.com/mainflux/mainflux/things/api/things/http"
+	"github.com/mainflux/mainflux/things/mocks"
+	"github.com/mainflux/mainflux/twins"
+	twthings "github.com/mainflux/mainflux/twins/things"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	email      = "user@example.com"
+	token      = "token"
+	wrongValue = "wrong-value"
+	numThings  = 120
+)
+
+func newThingsServer() *httptest.Server {
+	auth := mocks.NewAuthService(map[string]string{token: email})
+	conns := make(chan mocks.Connection)
+	thingsRepo := mocks.NewThingRepository(conns)
+	channelsRepo := mocks.NewChannelRepository(thingsRepo, conns)
+	svc := things.New(auth, thingsRepo, channelsRepo, mocks.NewChannelCache(), mocks.NewThingCache(), uuid.NewMock())
+
+	return httptest.NewServer(httpapi.MakeHandler(mocktracer.New(), svc))
+}
+
+func TestThing(t *testing.T) {
+	ts := newThingsServer()
+	defer ts.Close()
+	sdk := mfsdk.NewSDK(mfsdk.Config{BaseURL: ts.URL})
+	svc := twthings.New(sdk)
+
+	id, err := sdk.CreateThing(mfsdk.Thing{Name: "thing"}, token)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc  string
+		id    string
+		token string
+		thing twins.Thing
+		err   error
+	}{
+		{
+			desc:  "retrieve existing thing",
+			id:    id,
+			token: token,
+			thing: twins.Thing{ID: id, Name: "thing"},
+			err:   nil,
+		},
+		{
+			desc:  "retrieve non-existing thing",
+			id:    wrongValue,
+			token: token,
+			thing: twins.Thing{},
+			err:   twins.ErrNotFound,
+		},
+		{
+			desc:  "retrieve thing with wrong credentials",
+			id:    id,
+			token: wrongValue,
+			thing: twins.Thing{},
+			err:   twins.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		th, err := svc.Thing(tc.token, tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.thing, th, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.thing, th))
+	}
+}
+
+func TestChannelThings(t *testing.T) {
+	ts := newThingsServer()
+	defer ts.Close()
+	sdk := mfsdk.NewSDK(mfsdk.Config{BaseURL: ts.URL})
+	svc := twthings.New(sdk)
+
+	chID, err := sdk.CreateChannel(mfsdk.Channel{Name: "channel"}, token)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	emptyChID, err := sdk.CreateChannel(mfsdk.Channel{Name: "empty"}, token)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	var ids []string
+	for i := 0; i < numThings; i++ {
+		id, err := sdk.CreateThing(mfsdk.Thing{Name: fmt.Sprintf("thing_%d", i)}, token)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		ids = append(ids, id)
+	}
+	conns := mfsdk.ConnectionIDs{ChannelIDs: []string{chID}, ThingIDs: ids}
+	err = sdk.Connect(conns, token)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc   string
+		chanID string
+		token  string
+		size   int
+		err    error
+	}{
+		{
+			desc:   "retrieve things of channel with multiple pages of things",
+			chanID: chID,
+			token:  token,
+			size:   numThings,
+			err:    nil,
+		},
+		{
+			desc:   "retrieve things of channel without things",
+			chanID: emptyChID,
+			token:  token,
+			size:   0,
+			err:    nil,
+		},
+		{
+			desc:   "retrieve things with wrong credentials",
+			chanID: chID,
+			token:  wrongValue,
+			size:   0,
+			err:    twins.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		ths, err := svc.ChannelThings(tc.token, tc.chanID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Len(t, ths, tc.size, fmt.Sprintf("%s: expected %d things got %d\n", tc.desc, tc.size, len(ths)))
+	}
+}
@@ -16,6 +16,9 @@ const (
 	countStatesOp       = "count_states"
 	retrieveAllStatesOp = "retrieve_all_states"
 	retrieveLastStateOp = "retrieve_states_by_attribute"
+	retrieveStateByIDOp = "retrieve_state_by_id"
+	aggregateStatesOp   = "aggregate_states"
+	purgeStatesOp       = "purge_states"
 )
 
 var _ twins.StateRepository = (*stateRepositoryMiddleware)(nil)
@@ -58,12 +61,20 @@ func (trm stateRepositoryMiddleware) Count(ctx context.Context, tw twins.Twin) (
 	return trm.repo.Count(ctx, tw)
 }
 
-func (trm stateRepositoryMiddleware) RetrieveAll(ctx context.Context, offset, limit uint64, twinID string) (twins.StatesPage, error) {
+func (trm stateRepositoryMiddleware) RetrieveAll(ctx context.Context, offset, limit uint64, twinID string, query twins.StateQuery) (twins.StatesPage, error) {
 	span := createSpan(ctx, trm.tracer, retrieveAllStatesOp)
 	defer span.Finish()
 	ctx = opentracing.ContextWithSpan(ctx, span)
 
-	return trm.repo.RetrieveAll(ctx, offset, limit, twinID)
+	return trm.repo.RetrieveAll(ctx, offset, limit, twinID, query)
+}
+
+func (trm stateRepositoryMiddleware) RetrieveByID(ctx context.Context, twinID string, id int64) (twins.State, error) {
+	span := createSpan(ctx, trm.tracer, retrieveStateByIDOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return trm.repo.RetrieveByID(ctx, twinID, id)
 }
 
 func (trm stateRepositoryMiddleware) RetrieveLast(ctx context.Context, twinID string) (twins.State, error) {
@@ -73,3 +84,19 @@ func (trm stateRepositoryMiddleware) RetrieveLast(ctx context.Context, twinID st
 
 	return trm.repo.RetrieveLast(ctx, twinID)
 }
+
+func (trm stateRepositoryMiddleware) Aggregate(ctx context.Context, twinID string, query twins.AggregationQuery) ([]twins.Bucket, error) {
+	span := createSpan(ctx, trm.tracer, aggregateStatesOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return trm.repo.Aggregate(ctx, twinID, query)
+}
+
+func (trm stateRepositoryMiddleware) Purge(ctx context.Context, twinID string, retention twins.Retention) (int64, error) {
+	span := createSpan(ctx, trm.tracer, purgeStatesOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return trm.repo.Purge(ctx, twinID, retention)
+}
@@ -27,17 +27,33 @@ type Definition struct {
 	Delta      int64       `json:"delta"`
 }
 
+// Desired stores the desired attribute values of the twin, keyed by the
+// attribute name.
+type Desired map[string]interface{}
+
+// Retention limits the states stored for the twin. Zero values mean no
+// limit. The last state is always kept, since it holds the reported state.
+type Retention struct {
+	// MaxStates is the maximum number of stored states.
+	MaxStates int64 `json:"max_states"`
+	// MaxAge is the maximum age of stored states in seconds.
+	MaxAge int64 `json:"max_age"`
+}
+
 // Twin is a Mainflux data system representation. Each twin is owned
 // by a single user, and is assigned with the unique identifier.
 type Twin struct {
 	Owner       string
 	ID          string
+	ThingID     string
 	Name        string
 	Created     time.Time
 	Updated     time.Time
 	Revision    int
 	Definitions []Definition
 	Metadata    Metadata
+	Desired     Desired
+	Retention   *Retention
 }
 
 // PageMetadata contains page metadata that helps navigation.